@@ -0,0 +1,16 @@
+package eventsv1
+
+import "embed"
+
+// Proto holds the .proto files of the event data, as registered with the
+// schema registry for events published in protobuf
+//
+//go:embed *.proto
+var Proto embed.FS
+
+// Avro holds the Avro schemas of the event data, one record per file, in
+// the avro directory. Each record has the name of the protobuf message of
+// the same data.
+//
+//go:embed avro/*.avsc
+var Avro embed.FS
@@ -0,0 +1,9 @@
+package orderv1
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI v2 spec of the REST gateway, generated from
+// order.proto along with the gateway itself
+//
+//go:embed order.swagger.json
+var OpenAPI []byte
@@ -1,129 +1,232 @@
 package main
 
 import (
-	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
+	"context"
+	"encoding/base64"
 
+	_ "external-apis/docs/customer"
+	"external-apis/internal/customer/grpchandler"
 	"external-apis/internal/customer/handler"
 	"external-apis/internal/customer/repository"
 	"external-apis/internal/customer/service"
-	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/app"
+	"external-apis/internal/shared/changefeed"
+	"external-apis/internal/shared/config"
+	"external-apis/internal/shared/crypto"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/phone"
+	"external-apis/internal/shared/sandbox"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/versioning"
+	"external-apis/internal/shared/webhook"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
+var log = logger.New("main")
+
+// @title Customer Service API
+// @version 1.0.0
+// @description Manages customers, their addresses and customer search.
+// @description Success responses are wrapped in {"data", "message", "code"}; send X-Response-Envelope: false to get the data alone.
+// @BasePath /
 func main() {
-	// Initialize logger
-	initLogger()
+	a := app.New(app.Service{
+		Name:       "customer-service",
+		Title:      "Customer Service",
+		Version:    "1.0.0",
+		Defaults:   setDefaults,
+		Migrations: repository.Migrations(),
+		Swagger:    true,
+		Endpoints: map[string]string{
+			"customers": "/api/v1/customers",
+			"search":    "/api/v1/customers/search",
+			"changes":   "/api/v1/customers/changes",
+			"webhooks":  "/api/v1/webhooks",
+		},
+	})
+	cfg := a.Config
 
-	// Get port from environment or use default
-	port := getEnv("PORT", "3002")
+	// Parse phone numbers without a country calling code in the default
+	// region, which was validated with the configuration
+	_ = phone.SetDefaultRegion(cfg.Phone.DefaultRegion)
 
-	logrus.WithField("port", port).Info("Starting Customer Service")
+	// Park events whose delivery failed for good so they can be replayed
+	deadLetters := a.NewDeadLetterQueue()
 
-	// Initialize dependencies
-	customerRepo := repository.NewMemoryCustomerRepository()
-	customerService := service.NewCustomerService(customerRepo)
-	customerHandler := handler.NewCustomerHandler(customerService)
+	// Initialize webhook delivery and event streaming
+	webhooks := a.NewWebhookDispatcher(deadLetters, events.CustomerEvents)
+	publisher := a.NewEventPublisher(deadLetters, webhooks)
 
-	// Setup Gin router
-	router := setupRouter(customerHandler)
+	// Record the changes read incrementally by downstream sync jobs
+	changes := changefeed.New(cfg.Changes.Retention)
+	publisher = events.Multi{publisher, changes}
 
-	// Setup graceful shutdown
-	setupGracefulShutdown()
+	// Keep customers in the configured storage backend
+	cluster := a.OpenDatabase()
 
-	logrus.Info("✅ Customer Service started successfully")
-	logrus.WithField("url", fmt.Sprintf("http://localhost:%s", port)).Info("Service is available")
+	// Give new customers IDs in the configured format
+	ids := a.NewIDGenerator("cus_")
+	idValidator := a.IDValidator(ids)
 
-	// Start server
-	if err := router.Run(":" + port); err != nil {
-		logrus.WithError(err).Fatal("Failed to start server")
+	// Initialize dependencies
+	customerRepo := repository.NewTenantCustomerRepository()
+	customerRepo.SetIDs(ids)
+	customers := newCustomerRepository(newCustomerStore(a, cluster, customerRepo, ids), cfg.PII)
+	historyRepo := repository.NewTenantHistoryRepository()
+	verificationRepo := repository.NewTenantVerificationRepository()
+	verificationService := newVerificationService(a, customers, historyRepo, verificationRepo, publisher)
+	customerService := service.NewCustomerService(customers, historyRepo, publisher, verificationService)
+	customerHandler := handler.NewCustomerHandler(customerService, idValidator)
+	var verificationHandler *handler.VerificationHandler
+	if verificationService != nil {
+		verificationHandler = handler.NewVerificationHandler(verificationService, idValidator)
 	}
-}
+	addressRepo := repository.NewTenantAddressRepository()
+	addressRepo.SetIDs(a.NewIDGenerator("addr_"))
+	addressHandler := handler.NewAddressHandler(service.NewAddressService(addressRepo, customers), idValidator)
+	mergeHandler := handler.NewMergeHandler(service.NewMergeService(customers, addressRepo, historyRepo, publisher), idValidator)
+	changeHandler := handler.NewChangeHandler(changes)
+
+	// Start background jobs once every job kind is registered
+	a.StartJobs()
+
+	// Initialize sandbox mode
+	a.StartSandbox(map[string]sandbox.Purgeable{
+		"customers":     customerRepo,
+		"addresses":     addressRepo,
+		"history":       historyRepo,
+		"verifications": verificationRepo,
+	})
+
+	// Setup Gin router
+	router := a.Router(func(router *gin.Engine, admin *gin.RouterGroup) {
+		// API routes, served under /api/v1 and the deprecated /api alias
+		for _, api := range versioning.Groups(router, "/api", a.APIMiddleware("customers", tenant.Middleware(a.Tenants))...) {
+			customerHandler.RegisterRoutes(api, a.RequireAuth)
+			addressHandler.RegisterRoutes(api, a.RequireAuth)
+			mergeHandler.RegisterRoutes(api, a.RequireAuth)
+			if verificationHandler != nil {
+				verificationHandler.RegisterRoutes(api, a.RequireAuth)
+			}
+			changeHandler.RegisterRoutes(api)
+		}
+
+		// Webhook subscriptions
+		for _, hooks := range versioning.Groups(router, "/api", a.APIMiddleware("webhooks", a.RequireAuth)...) {
+			webhook.NewHandler(webhooks).RegisterRoutes(hooks)
+		}
+
+		deadletter.NewAdminHandler(deadLetters).RegisterRoutes(admin)
+	})
 
-// initLogger configures the logger
-func initLogger() {
-	logrus.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
+	// Start gRPC server alongside the HTTP server
+	a.ServeGRPC(func(server *grpc.Server) {
+		grpchandler.NewCustomerServer(customerService).Register(server)
 	})
 
-	level := getEnv("LOG_LEVEL", "info")
-	logLevel, err := logrus.ParseLevel(level)
-	if err != nil {
-		logrus.WithError(err).Warn("Invalid log level, using info")
-		logLevel = logrus.InfoLevel
-	}
+	a.Run(router)
+}
 
-	logrus.SetLevel(logLevel)
-	logrus.Info("Logger initialized")
+// setDefaults sets the customer service defaults
+func setDefaults(defaults *config.Config) {
+	defaults.HTTP.Port = "3002"
+	defaults.GRPC.Port = "50052"
+	defaults.Jobs.StateFile = "customer-service.jobs.json"
+	defaults.Audit.File = "customer-service.audit.jsonl"
+	defaults.DeadLetters.StateFile = "customer-service.deadletters.json"
+	defaults.Kafka.Topic = "customer-events"
+	defaults.NATS.Stream = "customer-events"
+	defaults.NATS.Subject = "customer-events"
+	defaults.Events.Source = "/customer-service"
+	defaults.Database.Path = "data/customer-service.db"
 }
 
-// setupRouter configures the Gin router with middleware and routes
-func setupRouter(customerHandler *handler.CustomerHandler) *gin.Engine {
-	// Set Gin mode
-	if getEnv("GIN_MODE", "debug") == "release" {
-		gin.SetMode(gin.ReleaseMode)
+// newCustomerStore returns the repository customers are kept in: the SQLite
+// one, giving new customers IDs of ids, seeded with the sample customers on first
+// use and instrumented with the repository metrics, when a database is open,
+// and the memory one otherwise, recovered from and logged to its write-ahead
+// logs when persistence is enabled
+func newCustomerStore(a *app.App, cluster *database.Cluster, memory *repository.TenantCustomerRepository, ids idgen.Generator) repository.CustomerRepository {
+	if cluster != nil {
+		store := repository.NewReplicatedSQLiteCustomerRepository(cluster)
+		store.SetIDs(ids)
+		if err := store.Seed(context.Background()); err != nil {
+			log.WithError(err).Fatal("Failed to seed the database")
+		}
+		return repository.NewInstrumentedCustomerRepository(store, a.RepositoryMetrics)
+	}
+	persistence := a.Config.Persistence
+	if !persistence.Enabled {
+		return memory
 	}
 
-	router := gin.New()
-
-	// Add middleware
-	router.Use(middleware.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.CORS())
-	router.Use(middleware.RequestID())
-
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "healthy",
-			"service": "customer-service",
-			"version": "1.0.0",
-		})
-	})
-
-	// API routes
-	api := router.Group("/api")
-	{
-		customerHandler.RegisterRoutes(api)
+	store := repository.NewPersistentCustomerRepository(memory,
+		a.OpenLog("customers"),
+		a.OpenLog("customer-aliases"),
+	)
+	recovered, err := store.Recover(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to recover customers from the write-ahead log")
 	}
+	log.WithFields(logger.Fields{
+		"dir":       persistence.Dir,
+		"customers": recovered,
+	}).Info("Using persistent memory storage")
+	return store
+}
 
-	// Root endpoint
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "Customer Service API",
-			"version": "1.0.0",
-			"endpoints": gin.H{
-				"health":    "/health",
-				"customers": "/api/customers",
-			},
-		})
-	})
+// newCustomerRepository encrypts customer email and phone numbers at rest when
+// PII keys are configured. Keys are listed primary first, so rotating means
+// prepending a new key; customers under older keys are re-encrypted at startup.
+func newCustomerRepository(store repository.CustomerRepository, pii config.PII) repository.CustomerRepository {
+	if pii.Keys == "" {
+		log.Warn("No PII encryption key configured, customer email and phone are stored in plaintext")
+		return store
+	}
 
-	return router
-}
+	keys, err := crypto.ParseKeys(pii.Keys)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid PII encryption keys")
+	}
+	indexKey, err := base64.StdEncoding.DecodeString(pii.IndexKey)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid PII index key")
+	}
+	keyring, err := crypto.NewKeyring(keys, indexKey)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure PII encryption")
+	}
 
-// setupGracefulShutdown sets up graceful shutdown handling
-func setupGracefulShutdown() {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		<-c
-		logrus.Info("Received shutdown signal, shutting down gracefully...")
-		// Here you would close database connections, etc.
-		logrus.Info("Customer Service shutdown complete")
-		os.Exit(0)
-	}()
+	encrypted := repository.NewEncryptedCustomerRepository(store, keyring)
+	rotated, err := encrypted.Rotate(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to re-encrypt customer PII")
+	}
+	log.WithFields(logger.Fields{
+		"primary_key": keys[0].ID,
+		"rotated":     rotated,
+	}).Info("Customer PII encrypted at rest")
+	return encrypted
 }
 
-// getEnv gets an environment variable with a fallback value
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// newVerificationService creates the service keeping new customers pending
+// until they verify their email; it is nil when verification is disabled
+func newVerificationService(a *app.App, customers repository.CustomerRepository, history repository.HistoryRepository, verifications repository.VerificationRepository, publisher events.Publisher) service.VerificationService {
+	settings := a.Config.Verification
+	if !settings.Enabled {
+		log.Warn("Customer email verification disabled")
+		return nil
 	}
-	return fallback
+
+	return service.NewVerificationService(customers, history, publisher, verifications, a.NewEmailSender(), service.VerificationConfig{
+		TokenTTL:       settings.TokenTTL,
+		ResendInterval: settings.ResendInterval,
+		URL:            settings.URL,
+	})
 }
@@ -0,0 +1,453 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	orderv1 "external-apis/api/order/v1"
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/graph"
+	"external-apis/internal/order/grpchandler"
+	"external-apis/internal/order/handler"
+	"external-apis/internal/order/invoice"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/order/saga"
+	"external-apis/internal/order/service"
+	"external-apis/internal/order/shipping"
+	"external-apis/internal/order/tax"
+	"external-apis/internal/shared/app"
+	"external-apis/internal/shared/breaker"
+	"external-apis/internal/shared/config"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/healthcheck"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/sandbox"
+	"external-apis/internal/shared/storage"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/tlsconfig"
+	"external-apis/internal/shared/versioning"
+
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("main")
+
+func main() {
+	a := app.New(app.Service{
+		Name:       "order-service",
+		Title:      "Order Service",
+		Version:    "1.0.0",
+		Defaults:   setDefaults,
+		Migrations: repository.Migrations(),
+		Endpoints: map[string]string{
+			"orders":  "/api/v1/orders",
+			"graphql": "/graphql",
+			"gateway": "/gateway/v1/orders",
+		},
+	})
+	cfg := a.Config
+
+	// Initialize dependencies, calling the other services over TLS when
+	// configured
+	downstreamTimeout := cfg.Downstream.Timeout
+	customerURL := strings.TrimRight(cfg.Downstream.CustomerURL, "/")
+	productURL := strings.TrimRight(cfg.Downstream.ProductURL, "/")
+	transport := newDownstreamTransport(a.TLS, customerURL, productURL)
+	downstream := newDownstreamClients(customerURL, productURL, transport, cfg.Downstream)
+	customerClient, productClient := downstream.customers, downstream.products
+	registerDownstreamChecks(a.Health, customerURL, productURL, transport, downstreamTimeout)
+
+	// Keep orders in the configured storage backend
+	cluster := a.OpenDatabase()
+	ids := a.NewIDGenerator("ord_")
+	idValidator := a.IDValidator(ids)
+	orderRepo := repository.NewTenantOrderRepository()
+	orderRepo.SetIDs(ids)
+	orders := newOrderStore(a, cluster, orderRepo, ids)
+	sagaStore := saga.NewMemoryStore(cfg.Orders.SagaRetention)
+	orderService := service.NewOrderService(orders, customerClient, productClient, service.Options{
+		Reservations: newReservationClient(downstream.reservations, cfg.Orders),
+		Quotes:       newQuoteClient(downstream.quotes, cfg.Orders),
+		Taxes:        newTaxCalculator(cfg.Tax),
+		Shipping:     newShippingCalculator(cfg.Shipping),
+		Addresses:    downstream.addresses,
+		Sagas:        saga.NewCoordinator(sagaStore),
+		Enrichment:   newEnrichmentOptions(a.Jobs, cfg.Enrichment),
+		IDs:          ids,
+		RefundIDs:    a.NewIDGenerator("ref_"),
+	})
+	startEnrichmentSweep(a.Jobs, orderService, orders, cfg.Enrichment)
+	orderExpander := newOrderExpander(customerClient, productClient, cfg.Expand)
+	orderHandler := handler.NewOrderHandler(orderService, orderExpander, idValidator)
+	invoiceRepo := repository.NewTenantInvoiceRepository()
+	invoiceRepo.SetIDs(a.NewIDGenerator("inv_"))
+	invoiceStorage := newInvoiceStorage(cfg.Invoice, cfg.HTTP.Port)
+	invoiceService := service.NewInvoiceService(orders, invoiceRepo, newInvoiceRenderer(cfg.Invoice), newInvoiceBranding(cfg.Invoice), service.InvoiceOptions{
+		Jobs:    a.Jobs,
+		Storage: invoiceStorage,
+	})
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService, idValidator)
+	reportRepo := repository.NewTenantSalesReportRepository()
+	reportService := service.NewReportService(orders, reportRepo)
+	startSalesReportRefresh(a.Jobs, reportService, orders, cfg.Reports)
+	reportHandler := handler.NewReportHandler(reportService)
+
+	// Start background jobs once every job kind is registered
+	a.StartJobs()
+
+	// Initialize sandbox mode
+	a.StartSandbox(map[string]sandbox.Purgeable{
+		"orders":   orderRepo,
+		"invoices": invoiceRepo,
+		"reports":  reportRepo,
+	})
+
+	// Serve the order API over gRPC, and over REST through the gateway
+	// generated from the same definition
+	orderServer := grpchandler.NewOrderServer(orderService)
+	gatewayHandler, err := orderServer.Gateway(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create REST gateway")
+	}
+
+	// Setup Gin router
+	graphqlHandler := graph.NewHandler(customerClient, productClient, orderService)
+	router := a.Router(func(router *gin.Engine, admin *gin.RouterGroup) {
+		// Invoices generated in the background are served by the service itself.
+		// Each document gets a random key of its own, so the files never change.
+		router.Group("/documents", middleware.Cache(middleware.CachePolicy{MaxAge: 24 * time.Hour})).Static("", invoiceStorage.Dir())
+
+		// API routes, served under /api/v1 and the deprecated /api alias
+		for _, api := range versioning.Groups(router, "/api", a.APIMiddleware("orders", tenant.Middleware(a.Tenants))...) {
+			orderHandler.RegisterRoutes(api, a.RequireAuth)
+			invoiceHandler.RegisterRoutes(api, a.RequireAuth)
+			reportHandler.RegisterRoutes(api, a.RequireAuth)
+		}
+
+		// GraphQL gateway over customers, products and orders
+		graphql := router.Group("/graphql", a.APIMiddleware("orders", tenant.Middleware(a.Tenants))...)
+		{
+			graphql.GET("", gin.WrapH(graphqlHandler))
+			graphql.POST("", gin.WrapH(graphqlHandler))
+		}
+
+		// REST gateway generated from the gRPC definition of the order API.
+		// Reads are public; writes go through RequireAuth, as under /api.
+		gateway := router.Group("/gateway", a.APIMiddleware("orders", tenant.Middleware(a.Tenants))...)
+		{
+			gateway.GET("/openapi.json", func(c *gin.Context) {
+				c.Data(http.StatusOK, "application/json", orderv1.OpenAPI)
+			})
+			gateway.GET("/v1/*path", gin.WrapH(gatewayHandler))
+			gateway.POST("/v1/*path", a.RequireAuth, gin.WrapH(gatewayHandler))
+			gateway.DELETE("/v1/*path", a.RequireAuth, gin.WrapH(gatewayHandler))
+		}
+
+		saga.NewAdminHandler(sagaStore).RegisterRoutes(admin)
+	})
+
+	// Start gRPC server alongside the HTTP server
+	a.ServeGRPC(orderServer.Register)
+
+	a.Run(router)
+}
+
+// setDefaults sets the order service defaults
+func setDefaults(defaults *config.Config) {
+	defaults.HTTP.Port = "3003"
+	defaults.GRPC.Port = "50053"
+	defaults.Jobs.StateFile = "order-service.jobs.json"
+	defaults.Audit.File = "order-service.audit.jsonl"
+	defaults.Database.Path = "data/order-service.db"
+}
+
+// newDownstreamTransport returns the transport of the calls to the customer
+// and product services: mutual TLS when certs is set, the default transport
+// otherwise. Plaintext service URLs are refused while TLS is enabled.
+func newDownstreamTransport(certs *tlsconfig.Reloader, urls ...string) http.RoundTripper {
+	if certs == nil {
+		return nil
+	}
+
+	for _, serviceURL := range urls {
+		if !strings.HasPrefix(serviceURL, "https://") {
+			log.WithField("url", serviceURL).Fatal("Plaintext HTTP between services is not allowed while TLS is enabled")
+		}
+	}
+	return certs.Transport()
+}
+
+// downstreamClients are the clients of the customer and product services
+type downstreamClients struct {
+	customers    client.CustomerClient
+	addresses    client.AddressClient
+	products     client.ProductClient
+	reservations client.ReservationClient
+	quotes       client.QuoteClient
+}
+
+// newDownstreamClients creates the clients of the customer and product
+// services. Unless the breaker threshold is zero, the calls to each service go
+// through a circuit breaker, shared by the clients of the service.
+func newDownstreamClients(customerURL, productURL string, transport http.RoundTripper, settings config.Downstream) downstreamClients {
+	customers := client.NewHTTPClient(customerURL, settings.Timeout, transport)
+	products := client.NewHTTPClient(productURL, settings.Timeout, transport)
+	if settings.BreakerThreshold == 0 {
+		return downstreamClients{
+			customers:    customers,
+			addresses:    customers,
+			products:     products,
+			reservations: products,
+			quotes:       products,
+		}
+	}
+
+	breakerConfig := breaker.Config{
+		FailureThreshold: settings.BreakerThreshold,
+		Cooldown:         settings.BreakerCooldown,
+	}
+	customerBreaker := client.NewBreaker("customer-service", breakerConfig)
+	productBreaker := client.NewBreaker("product-service", breakerConfig)
+
+	return downstreamClients{
+		customers:    client.NewBreakingCustomerClient(customers, customerBreaker),
+		addresses:    client.NewBreakingAddressClient(customers, customerBreaker),
+		products:     client.NewBreakingProductClient(products, productBreaker),
+		reservations: client.NewBreakingReservationClient(products, productBreaker),
+		quotes:       client.NewBreakingQuoteClient(products, productBreaker),
+	}
+}
+
+// newReservationClient returns the client new orders reserve stock through,
+// or nil when orders do not reserve stock
+func newReservationClient(reservations client.ReservationClient, settings config.Orders) client.ReservationClient {
+	if !settings.ReserveStock {
+		log.Info("Stock reservation disabled, orders do not hold product stock")
+		return nil
+	}
+	return reservations
+}
+
+// newQuoteClient returns the client new orders are priced through, or nil
+// when orders are placed at list prices
+func newQuoteClient(quotes client.QuoteClient, settings config.Orders) client.QuoteClient {
+	if !settings.ApplyPromotions {
+		log.Info("Promotions disabled, orders are placed at list prices")
+		return nil
+	}
+	return quotes
+}
+
+// newTaxCalculator returns the calculator of the tax of new orders, or nil
+// when orders are not taxed
+func newTaxCalculator(settings config.Tax) tax.TaxCalculator {
+	switch settings.Provider {
+	case "flat":
+		rates, err := tax.ParseRates(settings.Rates)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid TAX_RATES")
+		}
+		log.WithField("jurisdictions", len(rates)).Info("Taxing orders at flat rates")
+		return tax.NewFlatRate(rates)
+	case "api":
+		log.WithField("url", settings.APIURL).Info("Taxing orders through the tax API")
+		return tax.NewAPI(settings.APIURL, settings.APIKey, settings.Timeout, nil)
+	default:
+		log.Info("Tax disabled, orders are not taxed")
+		return nil
+	}
+}
+
+// newShippingCalculator returns the calculator of the shipping options of
+// orders, or nil when no shipping rates are configured
+func newShippingCalculator(settings config.Shipping) shipping.ShippingCalculator {
+	if len(settings.Rates) == 0 {
+		log.Info("No shipping rates, shipping is not quoted")
+		return nil
+	}
+
+	rates, err := shipping.ParseRates(settings.Rates, settings.Currency)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid SHIPPING_RATES")
+	}
+	log.WithFields(logger.Fields{
+		"rates":    len(rates),
+		"currency": settings.Currency,
+	}).Info("Quoting shipping from the rate table")
+	return shipping.NewCalculator(shipping.NewTable(rates))
+}
+
+// newInvoiceRenderer creates the renderer of invoices with the configured
+// HTML template
+func newInvoiceRenderer(settings config.Invoice) *invoice.Renderer {
+	renderer, err := invoice.NewRenderer(settings.Template)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid INVOICE_TEMPLATE")
+	}
+	if settings.Template != "" {
+		log.WithField("template", settings.Template).Info("Rendering HTML invoices with a custom template")
+	}
+	return renderer
+}
+
+// newInvoiceBranding returns the company details invoices are branded with
+func newInvoiceBranding(settings config.Invoice) invoice.Branding {
+	return invoice.Branding{
+		CompanyName: settings.CompanyName,
+		Address:     settings.CompanyAddress,
+		Email:       settings.CompanyEmail,
+		TaxID:       settings.TaxID,
+		LogoURL:     settings.LogoURL,
+		AccentColor: settings.AccentColor,
+		Currency:    settings.Currency,
+		Footer:      settings.Footer,
+	}
+}
+
+// newInvoiceStorage creates the storage of invoices generated in the
+// background, served by this service under /documents unless a base URL is
+// configured
+func newInvoiceStorage(settings config.Invoice, port string) *storage.Local {
+	baseURL := settings.BaseURL
+	if baseURL == "" {
+		baseURL = "http://localhost:" + port + "/documents"
+	}
+	local, err := storage.NewLocal(settings.Dir, baseURL)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to create invoice directory")
+	}
+	return local
+}
+
+// newEnrichmentOptions configures how orders placed while the customer or
+// product service is unavailable are handled
+func newEnrichmentOptions(jobManager *jobs.Manager, settings config.Enrichment) service.EnrichmentOptions {
+	if !settings.Fallback {
+		log.Info("Enrichment fallback disabled, orders fail while a downstream service is unavailable")
+		return service.EnrichmentOptions{}
+	}
+
+	options := service.EnrichmentOptions{
+		Jobs: jobManager,
+		Retry: jobs.RetryPolicy{
+			MaxAttempts:    settings.MaxAttempts,
+			InitialBackoff: settings.InitialBackoff,
+			MaxBackoff:     settings.MaxBackoff,
+		},
+	}
+
+	log.WithFields(logger.Fields{
+		"max_attempts":    options.Retry.MaxAttempts,
+		"initial_backoff": options.Retry.InitialBackoff,
+		"max_backoff":     options.Retry.MaxBackoff,
+	}).Info("Enrichment fallback enabled")
+	return options
+}
+
+// orderStore is a repository orders are kept in, whose tenants the
+// background jobs run for
+type orderStore interface {
+	repository.OrderRepository
+	tenant.Lister
+}
+
+// newOrderStore returns the repository orders are kept in: the SQLite one,
+// giving new orders IDs of ids and instrumented with the repository metrics,
+// when a database is open, and the memory one otherwise, recovered from and
+// logged to its write-ahead log when persistence is enabled
+func newOrderStore(a *app.App, cluster *database.Cluster, memory *repository.TenantOrderRepository, ids idgen.Generator) orderStore {
+	if cluster != nil {
+		store := repository.NewReplicatedSQLiteOrderRepository(cluster)
+		store.SetIDs(ids)
+		return repository.NewInstrumentedOrderRepository(store, a.RepositoryMetrics)
+	}
+	persistence := a.Config.Persistence
+	if !persistence.Enabled {
+		return memory
+	}
+
+	store := repository.NewPersistentOrderRepository(memory, a.OpenLog("orders"))
+	recovered, err := store.Recover(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to recover orders from the write-ahead log")
+	}
+	log.WithFields(logger.Fields{
+		"dir":    persistence.Dir,
+		"orders": recovered,
+	}).Info("Using persistent memory storage")
+	return store
+}
+
+// startEnrichmentSweep schedules retrying the stale partially enriched
+// orders of every tenant every sweep interval, when the enrichment fallback
+// is enabled. A failing tenant does not hold up the others.
+func startEnrichmentSweep(jobManager *jobs.Manager, orders service.OrderService, repo tenant.Lister, settings config.Enrichment) {
+	if !settings.Fallback {
+		return
+	}
+
+	jobManager.Schedule("enrichment-sweep", jobs.Every(settings.SweepInterval), func(ctx context.Context) error {
+		var errs []error
+		for _, tenantID := range repo.Tenants() {
+			if _, err := orders.RetryStaleEnrichments(tenant.WithTenant(ctx, tenantID)); err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// startSalesReportRefresh schedules aggregating the orders of the lookback
+// of every tenant into its sales report tables every refresh interval. A
+// failing tenant does not hold up the others.
+func startSalesReportRefresh(jobManager *jobs.Manager, reports service.ReportService, repo tenant.Lister, settings config.Reports) {
+	jobManager.Schedule("sales-report-refresh", jobs.Every(settings.RefreshInterval), func(ctx context.Context) error {
+		now := time.Now()
+		var errs []error
+		for _, tenantID := range repo.Tenants() {
+			if _, err := reports.RefreshSalesReport(tenant.WithTenant(ctx, tenantID), now.Add(-settings.Lookback), now); err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// newOrderExpander creates the expander inlining customers and products into
+// order responses, each expansion with its own cache and failure policy
+func newOrderExpander(customers client.CustomerClient, products client.ProductClient, settings config.Expand) service.OrderExpander {
+	options := service.ExpanderOptions{
+		Customer: service.ExpansionOptions{
+			CacheTTL: settings.CustomerCacheTTL,
+			Policy:   model.ExpandPolicy(settings.CustomerOnError),
+		},
+		Products: service.ExpansionOptions{
+			CacheTTL: settings.ProductsCacheTTL,
+			Policy:   model.ExpandPolicy(settings.ProductsOnError),
+		},
+	}
+
+	log.WithFields(logger.Fields{
+		"customer_cache_ttl": options.Customer.CacheTTL,
+		"customer_on_error":  options.Customer.Policy,
+		"products_cache_ttl": options.Products.CacheTTL,
+		"products_on_error":  options.Products.Policy,
+	}).Info("Order expansions configured")
+	return service.NewOrderExpander(customers, products, options)
+}
+
+// registerDownstreamChecks makes readiness depend on the liveness probes of
+// the customer and product services, without which no order can be created
+func registerDownstreamChecks(health *healthcheck.Registry, customerURL, productURL string, transport http.RoundTripper, timeout time.Duration) {
+	httpClient := &http.Client{Timeout: timeout, Transport: transport}
+	health.Register("customer-service", healthcheck.HTTP(httpClient, customerURL+"/health/live"))
+	health.Register("product-service", healthcheck.HTTP(httpClient, productURL+"/health/live"))
+}
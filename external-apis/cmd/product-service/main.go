@@ -1,129 +1,397 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
+	_ "external-apis/docs/product"
+	"external-apis/internal/product/consumer"
+	"external-apis/internal/product/grpchandler"
 	"external-apis/internal/product/handler"
 	"external-apis/internal/product/repository"
 	"external-apis/internal/product/service"
+	"external-apis/internal/shared/app"
+	"external-apis/internal/shared/changefeed"
+	"external-apis/internal/shared/config"
+	"external-apis/internal/shared/currency"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/metrics"
 	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/rabbitmq"
+	"external-apis/internal/shared/sandbox"
+	"external-apis/internal/shared/shutdown"
+	"external-apis/internal/shared/storage"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/versioning"
+	"external-apis/internal/shared/webhook"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
+var log = logger.New("main")
+
+// @title Product Service API
+// @version 1.0.0
+// @description Manages the product catalogue, stock and full-text product search.
+// @description Success responses are wrapped in {"data", "message", "code"}; send X-Response-Envelope: false to get the data alone.
+// @BasePath /
 func main() {
-	// Initialize logger
-	initLogger()
+	a := app.New(app.Service{
+		Name:       "product-service",
+		Title:      "Product Service",
+		Version:    "1.0.0",
+		Defaults:   setDefaults,
+		Migrations: repository.Migrations(),
+		Swagger:    true,
+		Endpoints: map[string]string{
+			"products":   "/api/v1/products",
+			"search":     "/api/v1/products/search",
+			"changes":    "/api/v1/products/changes",
+			"categories": "/api/v1/categories",
+			"webhooks":   "/api/v1/webhooks",
+		},
+	})
+	cfg := a.Config
+
+	// Park events whose delivery failed for good so they can be replayed
+	deadLetters := a.NewDeadLetterQueue()
 
-	// Get port from environment or use default
-	port := getEnv("PORT", "3001")
+	// Initialize webhook delivery
+	webhooks := a.NewWebhookDispatcher(deadLetters, events.ProductEvents)
 
-	logrus.WithField("port", port).Info("Starting Product Service")
+	// Keep products in the configured storage backend
+	cluster := a.OpenDatabase()
+
+	// Give new products IDs in the configured format
+	ids := a.NewIDGenerator("prod_")
+	idValidator := a.IDValidator(ids)
 
 	// Initialize dependencies
-	productRepo := repository.NewMemoryProductRepository()
-	productService := service.NewProductService(productRepo)
-	productHandler := handler.NewProductHandler(productService)
+	productRepo := repository.NewTenantProductRepository()
+	productRepo.SetIDs(ids)
+	products := newProductStore(a, cluster, productRepo, ids)
+	categoryIDs := a.NewIDGenerator("cat_")
+	categoryRepo := repository.NewTenantCategoryRepository()
+	categoryRepo.SetIDs(categoryIDs)
+	searchRepo, searchIndex := newSearchRepository(a, products)
+	publisher := a.NewEventPublisher(deadLetters, webhooks, searchIndex)
+
+	// Record the changes read incrementally by downstream sync jobs
+	changes := changefeed.New(cfg.Changes.Retention)
+	publisher = events.Multi{publisher, changes}
+
+	// Notify back-in-stock subscribers when the events show a restock
+	stockSubscriptionRepo := repository.NewTenantStockSubscriptionRepository()
+	stockSubscriptionRepo.SetIDs(a.NewIDGenerator("sub_"))
+	stockSubscriptionService := service.NewStockSubscriptionService(stockSubscriptionRepo, products, a.Jobs, a.NewEmailSender(), publisher)
+	publisher = events.Multi{publisher, stockSubscriptionService}
+
+	defaultCurrency, err := money.NormalizeCurrency(cfg.Catalog.DefaultCurrency)
+	if err != nil {
+		log.WithError(err).Fatal("Invalid default currency")
+	}
+	productService := service.NewProductService(products, searchRepo, categoryRepo, defaultCurrency, publisher)
+	productHandler := handler.NewProductHandler(productService, newCurrencyConverter(cfg.Currency), idValidator)
+	categoryHandler := handler.NewCategoryHandler(service.NewCategoryService(categoryRepo, products, publisher), a.IDValidator(categoryIDs))
+	imageStorage, localImages := newImageStorage(a)
+	imageHandler := handler.NewImageHandler(service.NewImageService(products, imageStorage, int64(cfg.Storage.MaxImageSize), a.NewIDGenerator("img_"), publisher), idValidator)
+	variantHandler := handler.NewVariantHandler(service.NewVariantService(products, a.NewIDGenerator("var_"), publisher), idValidator)
+	reservationIDs := a.NewIDGenerator("res_")
+	reservationRepo := repository.NewTenantReservationRepository()
+	reservationRepo.SetIDs(reservationIDs)
+	reservationService := service.NewReservationService(reservationRepo, products, service.ReservationOptions{
+		TTL:    cfg.Reservations.TTL,
+		MaxTTL: cfg.Reservations.MaxTTL,
+	}, publisher)
+	reservationHandler := handler.NewReservationHandler(reservationService, a.IDValidator(reservationIDs), idValidator)
+	scheduledChangeRepo := repository.NewTenantScheduledChangeRepository()
+	scheduledChangeRepo.SetIDs(a.NewIDGenerator("chg_"))
+	scheduledChangeService := service.NewScheduledChangeService(scheduledChangeRepo, productService)
+	scheduledChangeHandler := handler.NewScheduledChangeHandler(scheduledChangeService, idValidator)
+	promotionIDs := a.NewIDGenerator("promo_")
+	promotionRepo := repository.NewTenantPromotionRepository()
+	promotionRepo.SetIDs(promotionIDs)
+	promotionHandler := handler.NewPromotionHandler(service.NewPromotionService(promotionRepo, products, categoryRepo, defaultCurrency), a.IDValidator(promotionIDs), idValidator)
+	reviewRepo := repository.NewTenantReviewRepository()
+	reviewRepo.SetIDs(a.NewIDGenerator("rev_"))
+	reviewHandler := handler.NewReviewHandler(service.NewReviewService(reviewRepo, products, publisher), idValidator)
+	supplierRepo := repository.NewTenantSupplierRepository()
+	supplierRepo.SetIDs(a.NewIDGenerator("sup_"))
+	supplierHandler := handler.NewSupplierHandler(service.NewSupplierService(supplierRepo, products, defaultCurrency), idValidator)
+	stockSubscriptionHandler := handler.NewStockSubscriptionHandler(stockSubscriptionService, idValidator)
+	changeHandler := handler.NewChangeHandler(changes)
+
+	// Start background jobs once every job kind is registered
+	a.StartJobs()
+
+	// Initialize sandbox mode
+	a.StartSandbox(map[string]sandbox.Purgeable{
+		"products":            productRepo,
+		"categories":          categoryRepo,
+		"reservations":        reservationRepo,
+		"scheduled-changes":   scheduledChangeRepo,
+		"promotions":          promotionRepo,
+		"reviews":             reviewRepo,
+		"suppliers":           supplierRepo,
+		"stock-subscriptions": stockSubscriptionRepo,
+	})
+
+	// Release expired stock reservations in the background
+	startReservationExpiry(a.Jobs, reservationService, reservationRepo, cfg.Reservations.ReleaseInterval)
+
+	// Apply scheduled product changes once they are due
+	startScheduledChanges(a.Jobs, scheduledChangeService, scheduledChangeRepo, cfg.Scheduling.ApplyInterval)
 
 	// Setup Gin router
-	router := setupRouter(productHandler)
+	router := a.Router(func(router *gin.Engine, admin *gin.RouterGroup) {
+		// Product images kept on local disk are served by the service itself.
+		// Each upload gets a key of its own, so the files never change.
+		if localImages != nil {
+			router.Group("/media", middleware.Cache(middleware.CachePolicy{MaxAge: 24 * time.Hour})).Static("", localImages.Dir())
+		}
+
+		// API routes, served under /api/v1 and the deprecated /api alias
+		for _, api := range versioning.Groups(router, "/api", a.APIMiddleware("products", tenant.Middleware(a.Tenants))...) {
+			productHandler.RegisterRoutes(api, a.RequireAuth)
+			categoryHandler.RegisterRoutes(api, a.RequireAuth)
+			imageHandler.RegisterRoutes(api, a.RequireAuth)
+			variantHandler.RegisterRoutes(api, a.RequireAuth)
+			reservationHandler.RegisterRoutes(api, a.RequireAuth)
+			scheduledChangeHandler.RegisterRoutes(api, a.RequireAuth)
+			promotionHandler.RegisterRoutes(api, a.RequireAuth)
+			reviewHandler.RegisterRoutes(api, a.RequireAuth)
+			supplierHandler.RegisterRoutes(api, a.RequireAuth)
+			stockSubscriptionHandler.RegisterRoutes(api, a.RequireAuth)
+			changeHandler.RegisterRoutes(api)
+		}
+
+		// Webhook subscriptions
+		for _, hooks := range versioning.Groups(router, "/api", a.APIMiddleware("webhooks", a.RequireAuth)...) {
+			webhook.NewHandler(webhooks).RegisterRoutes(hooks)
+		}
 
-	// Setup graceful shutdown
-	setupGracefulShutdown()
+		deadletter.NewAdminHandler(deadLetters).RegisterRoutes(admin)
+	})
+
+	// Start gRPC server alongside the HTTP server
+	a.ServeGRPC(func(server *grpc.Server) {
+		grpchandler.NewProductServer(productService).Register(server)
+	})
 
-	logrus.Info("✅ Product Service started successfully")
-	logrus.WithField("url", fmt.Sprintf("http://localhost:%s", port)).Info("Service is available")
+	// Apply the catalog updates suppliers send over RabbitMQ
+	startCatalogConsumer(a, deadLetters, productService)
+
+	a.Run(router)
+}
 
-	// Start server
-	if err := router.Run(":" + port); err != nil {
-		logrus.WithError(err).Fatal("Failed to start server")
+// setDefaults sets the product service defaults
+func setDefaults(defaults *config.Config) {
+	defaults.HTTP.Port = "3001"
+	defaults.GRPC.Port = "50051"
+	defaults.Jobs.StateFile = "product-service.jobs.json"
+	defaults.Audit.File = "product-service.audit.jsonl"
+	defaults.DeadLetters.StateFile = "product-service.deadletters.json"
+	defaults.Kafka.Topic = "product-events"
+	defaults.NATS.Stream = "product-events"
+	defaults.NATS.Subject = "product-events"
+	defaults.Events.Source = "/product-service"
+	defaults.RabbitMQ.Queue = "catalog-updates"
+	defaults.Storage.MaxImageSize = service.DefaultMaxImageSize
+	defaults.Database.Path = "data/product-service.db"
+}
+
+// newCurrencyConverter creates the converter of product prices into the
+// currencies clients ask for, at the rates of the configured provider
+func newCurrencyConverter(settings config.Currency) *currency.Converter {
+	var provider currency.ExchangeRateProvider
+	switch settings.Provider {
+	case "ecb":
+		log.WithField("url", settings.ECBURL).Info("Converting prices at the ECB reference rates")
+		provider = currency.NewECB(settings.ECBURL, settings.Timeout, nil)
+	case "api":
+		log.WithField("url", settings.APIURL).Info("Converting prices at the exchange rate API rates")
+		provider = currency.NewAPI(settings.APIURL, settings.APIKey, settings.Base, settings.Timeout, nil)
+	default:
+		rates, err := currency.ParseRates(settings.Rates)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid CURRENCY_RATES")
+		}
+		log.WithFields(logger.Fields{
+			"base":       settings.Base,
+			"currencies": len(rates),
+		}).Info("Converting prices at static exchange rates")
+		provider = currency.NewStatic(settings.Base, rates)
 	}
+	return currency.NewConverter(provider, settings.RefreshInterval)
 }
 
-// initLogger configures the logger
-func initLogger() {
-	logrus.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
-	})
+// productStore is a repository products are kept in, which can search
+// them by itself
+type productStore interface {
+	repository.ProductRepository
+	repository.SearchRepository
+}
 
-	level := getEnv("LOG_LEVEL", "info")
-	logLevel, err := logrus.ParseLevel(level)
-	if err != nil {
-		logrus.WithError(err).Warn("Invalid log level, using info")
-		logLevel = logrus.InfoLevel
+// newProductStore returns the repository products are kept in: the SQLite
+// one, giving new products IDs of ids, seeded with the sample products on first
+// use and instrumented with the repository metrics, when a database is open,
+// and the memory one otherwise, recovered from and logged to its write-ahead
+// log when persistence is enabled
+func newProductStore(a *app.App, cluster *database.Cluster, memory *repository.TenantProductRepository, ids idgen.Generator) productStore {
+	if cluster != nil {
+		store := repository.NewReplicatedSQLiteProductRepository(cluster)
+		store.SetIDs(ids)
+		if err := store.Seed(context.Background()); err != nil {
+			log.WithError(err).Fatal("Failed to seed the database")
+		}
+		return repository.NewInstrumentedProductRepository(store, store, a.RepositoryMetrics)
+	}
+	persistence := a.Config.Persistence
+	if !persistence.Enabled {
+		return memory
 	}
 
-	logrus.SetLevel(logLevel)
-	logrus.Info("Logger initialized")
+	store := repository.NewPersistentProductRepository(memory, a.OpenLog("products"))
+	recovered, err := store.Recover(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to recover products from the write-ahead log")
+	}
+	log.WithFields(logger.Fields{
+		"dir":      persistence.Dir,
+		"products": recovered,
+	}).Info("Using persistent memory storage")
+	return store
 }
 
-// setupRouter configures the Gin router with middleware and routes
-func setupRouter(productHandler *handler.ProductHandler) *gin.Engine {
-	// Set Gin mode
-	if getEnv("GIN_MODE", "debug") == "release" {
-		gin.SetMode(gin.ReleaseMode)
+// newSearchRepository creates the full-text search backend. Without an
+// Elasticsearch URL the product repository's own search is used. With it, the
+// Elasticsearch repository is returned twice: as the search repository and as
+// the publisher that keeps its index in sync.
+func newSearchRepository(a *app.App, products productStore) (repository.SearchRepository, events.Publisher) {
+	search := a.Config.Search
+	esURL := search.URL
+	if esURL == "" {
+		return products, nil
 	}
 
-	router := gin.New()
+	searchRepo := repository.NewElasticsearchRepository(repository.ElasticsearchConfig{
+		URL:      esURL,
+		Index:    search.Index,
+		Username: search.Username,
+		Password: search.Password,
+		Timeout:  search.Timeout,
+	}, products, a.Jobs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := searchRepo.EnsureIndex(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to create search index")
+	}
+	if err := searchRepo.Reindex(ctx); err != nil {
+		log.WithError(err).Fatal("Failed to index products")
+	}
+	a.Health.Register("elasticsearch", searchRepo.Ping)
 
-	// Add middleware
-	router.Use(middleware.Recovery())
-	router.Use(middleware.Logger())
-	router.Use(middleware.CORS())
-	router.Use(middleware.RequestID())
+	log.WithField("url", esURL).Info("Using Elasticsearch for product search")
+	return searchRepo, searchRepo
+}
 
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status":  "healthy",
-			"service": "product-service",
-			"version": "1.0.0",
+// newImageStorage creates the product image storage. The s3 backend keeps
+// images in an S3-compatible bucket. Otherwise they are written to the image
+// directory and served by this service under /media, in which case the local
+// storage is returned as well.
+func newImageStorage(a *app.App) (storage.Storage, *storage.Local) {
+	settings := a.Config.Storage
+	switch settings.Backend {
+	case "local":
+		baseURL := settings.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:" + a.Config.HTTP.Port + "/media"
+		}
+		local, err := storage.NewLocal(settings.Dir, baseURL)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to create image directory")
+		}
+		return local, local
+	case "s3":
+		bucket := storage.NewS3(storage.S3Config{
+			Endpoint:        settings.S3.Endpoint,
+			Region:          settings.S3.Region,
+			Bucket:          settings.S3.Bucket,
+			AccessKeyID:     settings.S3.AccessKeyID,
+			SecretAccessKey: settings.S3.SecretAccessKey,
+			PublicURL:       settings.S3.PublicURL,
+			Timeout:         settings.S3.Timeout,
 		})
-	})
+		a.Health.Register("s3", bucket.Ping)
 
-	// API routes
-	api := router.Group("/api")
-	{
-		productHandler.RegisterRoutes(api)
+		log.WithField("bucket", settings.S3.Bucket).Info("Storing product images in S3")
+		return bucket, nil
+	default:
+		// The backend was validated when the configuration was loaded
+		log.WithField("storage", settings.Backend).Fatal("Unknown image storage, use local or s3")
+		return nil, nil
 	}
+}
 
-	// Root endpoint
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"message": "Product Service API",
-			"version": "1.0.0",
-			"endpoints": gin.H{
-				"health":   "/health",
-				"products": "/api/products",
-			},
-		})
+// startCatalogConsumer consumes the catalog update queue when RabbitMQ is
+// configured. Updates that are malformed or rejected are parked with the
+// dead letters, and every update is counted on /metrics by outcome.
+func startCatalogConsumer(a *app.App, deadLetters *deadletter.Queue, products service.ProductService) {
+	settings := a.Config.RabbitMQ
+	if settings.URL == "" {
+		return
+	}
+
+	catalogUpdates := rabbitmq.NewConsumer(rabbitmq.Config{
+		URL:             settings.URL,
+		Queue:           settings.Queue,
+		Prefetch:        settings.Prefetch,
+		ReconnectDelay:  settings.ReconnectDelay,
+		RedeliveryDelay: settings.RedeliveryDelay,
+		DeadLetters:     deadLetters,
+		Metrics:         metrics.NewConsumerMetrics(a.HTTPMetrics.Registry(), a.Service.Name),
 	})
+	catalogUpdates.Start(consumer.NewCatalogConsumer(products, a.Tenants).Handle)
 
-	return router
+	deadLetters.Register(catalogUpdates.Name(), catalogUpdates)
+	a.Hooks.Add(catalogUpdates.Name(), shutdown.Closer(catalogUpdates))
+	a.Health.Register(catalogUpdates.Name(), catalogUpdates.Ping)
 }
 
-// setupGracefulShutdown sets up graceful shutdown handling
-func setupGracefulShutdown() {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-
-	go func() {
-		<-c
-		logrus.Info("Received shutdown signal, shutting down gracefully...")
-		// Here you would close database connections, etc.
-		logrus.Info("Product Service shutdown complete")
-		os.Exit(0)
-	}()
+// startReservationExpiry schedules releasing the expired reservations of
+// every tenant every interval. A failing tenant does not hold up the others.
+func startReservationExpiry(jobManager *jobs.Manager, reservations service.ReservationService, repo *repository.TenantReservationRepository, interval time.Duration) {
+	jobManager.Schedule("reservation-expiry", jobs.Every(interval), func(ctx context.Context) error {
+		var errs []error
+		for _, tenantID := range repo.Tenants() {
+			if _, err := reservations.ReleaseExpired(tenant.WithTenant(ctx, tenantID)); err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
 }
 
-// getEnv gets an environment variable with a fallback value
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
+// startScheduledChanges schedules applying the due product changes of every
+// tenant every interval. A failing tenant does not hold up the others.
+func startScheduledChanges(jobManager *jobs.Manager, changes service.ScheduledChangeService, repo *repository.TenantScheduledChangeRepository, interval time.Duration) {
+	jobManager.Schedule("scheduled-changes", jobs.Every(interval), func(ctx context.Context) error {
+		var errs []error
+		for _, tenantID := range repo.Tenants() {
+			if _, err := changes.ApplyDue(tenant.WithTenant(ctx, tenantID)); err != nil {
+				errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
 }
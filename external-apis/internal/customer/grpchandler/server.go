@@ -0,0 +1,201 @@
+package grpchandler
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	customerv1 "external-apis/api/customer/v1"
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var log = logger.New("customer/grpchandler")
+
+// CustomerServer exposes the customer service over gRPC
+type CustomerServer struct {
+	customerv1.UnimplementedCustomerServiceServer
+	service service.CustomerService
+}
+
+// NewCustomerServer creates a new customer gRPC server
+func NewCustomerServer(service service.CustomerService) *CustomerServer {
+	return &CustomerServer{
+		service: service,
+	}
+}
+
+// Register registers the customer service on the gRPC server
+func (s *CustomerServer) Register(server *grpc.Server) {
+	customerv1.RegisterCustomerServiceServer(server, s)
+}
+
+// GetCustomer retrieves a customer by ID
+func (s *CustomerServer) GetCustomer(ctx context.Context, req *customerv1.GetCustomerRequest) (*customerv1.Customer, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Customer ID is required")
+	}
+
+	customer, err := s.service.GetCustomerByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(customer), nil
+}
+
+// GetCustomerByEmail retrieves a customer by email
+func (s *CustomerServer) GetCustomerByEmail(ctx context.Context, req *customerv1.GetCustomerByEmailRequest) (*customerv1.Customer, error) {
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Customer email is required")
+	}
+
+	customer, err := s.service.GetCustomerByEmail(ctx, req.GetEmail())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(customer), nil
+}
+
+// ListCustomers retrieves a filtered page of customers
+func (s *CustomerServer) ListCustomers(ctx context.Context, req *customerv1.ListCustomersRequest) (*customerv1.ListCustomersResponse, error) {
+	filter := model.CustomerFilter{
+		Sort: req.GetSort(),
+		Page: pagination.Params{
+			Limit:  int(req.GetLimit()),
+			Offset: int(req.GetOffset()),
+		},
+	}
+
+	if filter.Page.Limit < 0 || filter.Page.Limit > pagination.MaxLimit {
+		return nil, status.Error(codes.InvalidArgument, pagination.ErrInvalidLimit.Error())
+	}
+	if filter.Page.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, pagination.ErrInvalidOffset.Error())
+	}
+
+	if req.Status != nil {
+		customerStatus := model.CustomerStatus(strings.ToUpper(req.GetStatus()))
+		if !customerStatus.IsValid() {
+			return nil, status.Error(codes.InvalidArgument, "invalid customer status")
+		}
+		filter.Status = &customerStatus
+	}
+	if req.Active != nil {
+		active := req.GetActive()
+		filter.Active = &active
+	}
+
+	customers, meta, err := s.service.ListCustomers(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &customerv1.ListCustomersResponse{
+		Customers: make([]*customerv1.Customer, len(customers)),
+		Total:     int32(meta.Total),
+		Limit:     int32(meta.Limit),
+		Offset:    int32(meta.Offset),
+	}
+	for i, customer := range customers {
+		resp.Customers[i] = toProto(customer)
+	}
+
+	return resp, nil
+}
+
+// CreateCustomer creates a new customer
+func (s *CustomerServer) CreateCustomer(ctx context.Context, req *customerv1.CreateCustomerRequest) (*customerv1.Customer, error) {
+	if req.GetName() == "" || req.GetEmail() == "" || req.GetPhone() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name, email and phone are required")
+	}
+
+	customer, err := s.service.CreateCustomer(ctx, model.CreateCustomerRequest{
+		Name:  req.GetName(),
+		Email: req.GetEmail(),
+		Phone: req.GetPhone(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(customer), nil
+}
+
+// UpdateCustomer updates an existing customer
+func (s *CustomerServer) UpdateCustomer(ctx context.Context, req *customerv1.UpdateCustomerRequest) (*customerv1.Customer, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Customer ID is required")
+	}
+
+	update := model.UpdateCustomerRequest{
+		Name:   req.Name,
+		Email:  req.Email,
+		Phone:  req.Phone,
+		Active: req.Active,
+	}
+	if req.Status != nil {
+		customerStatus := model.CustomerStatus(req.GetStatus())
+		update.Status = &customerStatus
+	}
+
+	customer, err := s.service.UpdateCustomer(ctx, req.GetId(), update)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(customer), nil
+}
+
+// DeleteCustomer deletes a customer
+func (s *CustomerServer) DeleteCustomer(ctx context.Context, req *customerv1.DeleteCustomerRequest) (*customerv1.DeleteCustomerResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Customer ID is required")
+	}
+
+	if err := s.service.DeleteCustomer(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &customerv1.DeleteCustomerResponse{}, nil
+}
+
+// toProto converts a customer response into its protobuf representation
+func toProto(customer *model.CustomerResponse) *customerv1.Customer {
+	return &customerv1.Customer{
+		Id:     customer.ID,
+		Name:   customer.Name,
+		Email:  customer.Email,
+		Phone:  customer.Phone,
+		Active: customer.Active,
+		Status: string(customer.Status),
+	}
+}
+
+// toStatus maps service errors to gRPC status codes
+func toStatus(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		return status.Error(codes.NotFound, "Customer not found")
+	case errors.Is(err, model.ErrCustomerExists), errors.Is(err, model.ErrEmailTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, apperror.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, apperror.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Ctx(ctx).WithError(err).Error("Unexpected customer service error")
+	return status.Error(codes.Internal, "Internal server error occurred")
+}
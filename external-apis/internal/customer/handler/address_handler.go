@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHandler handles HTTP requests for customer addresses
+type AddressHandler struct {
+	service service.AddressService
+	ids     idgen.Generator
+}
+
+// NewAddressHandler creates a new address handler. Customer IDs in request
+// paths are checked against ids.
+func NewAddressHandler(service service.AddressService, ids idgen.Generator) *AddressHandler {
+	return &AddressHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the customer address routes. Reads are public;
+// writes go through requireAuth.
+func (h *AddressHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	addresses := router.Group("/customers/:id/addresses", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		addresses.GET("", h.GetAddresses)
+		addresses.GET("/:addressId", h.GetAddress)
+		addresses.POST("", requireAuth, h.CreateAddress)
+		addresses.PUT("/:addressId", requireAuth, h.UpdateAddress)
+		addresses.DELETE("/:addressId", requireAuth, h.DeleteAddress)
+	}
+}
+
+// GetAddresses godoc
+// @Summary List customer addresses
+// @Description Get all shipping and billing addresses of a customer
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.AddressResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/addresses [get]
+func (h *AddressHandler) GetAddresses(c *gin.Context) {
+	addresses, err := h.service.GetAddresses(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.addressError(c, err)
+		return
+	}
+
+	response.OK(c, addresses)
+}
+
+// GetAddress godoc
+// @Summary Get customer address
+// @Description Get a single address of a customer
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param addressId path string true "Address ID"
+// @Success 200 {object} response.SuccessResponse{data=model.AddressResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/addresses/{addressId} [get]
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	address, err := h.service.GetAddress(c.Request.Context(), c.Param("id"), c.Param("addressId"))
+	if err != nil {
+		h.addressError(c, err)
+		return
+	}
+
+	response.OK(c, address)
+}
+
+// CreateAddress godoc
+// @Summary Add customer address
+// @Description Add a shipping or billing address to a customer's address book
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param address body model.CreateAddressRequest true "Address data"
+// @Success 201 {object} response.SuccessResponse{data=model.AddressResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/addresses [post]
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req model.CreateAddressRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create address")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	address, err := h.service.CreateAddress(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.addressError(c, err)
+		return
+	}
+
+	response.Created(c, address)
+}
+
+// UpdateAddress godoc
+// @Summary Update customer address
+// @Description Update an address in a customer's address book
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param addressId path string true "Address ID"
+// @Param address body model.UpdateAddressRequest true "Address data"
+// @Success 200 {object} response.SuccessResponse{data=model.AddressResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/addresses/{addressId} [put]
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	var req model.UpdateAddressRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update address")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	address, err := h.service.UpdateAddress(c.Request.Context(), c.Param("id"), c.Param("addressId"), req)
+	if err != nil {
+		h.addressError(c, err)
+		return
+	}
+
+	response.OK(c, address)
+}
+
+// DeleteAddress godoc
+// @Summary Delete customer address
+// @Description Remove an address from a customer's address book
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param addressId path string true "Address ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/addresses/{addressId} [delete]
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	if err := h.service.DeleteAddress(c.Request.Context(), c.Param("id"), c.Param("addressId")); err != nil {
+		h.addressError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Address deleted successfully")
+}
+
+// addressError maps address service errors to responses
+func (h *AddressHandler) addressError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrCustomerNotFound):
+		response.NotFound(c, "Customer not found")
+	case errors.Is(err, model.ErrAddressNotFound):
+		response.NotFound(c, "Address not found")
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"customer_id": c.Param("id"),
+			"request_id":  c.GetString("request_id"),
+		}).Error("Failed to process customer address")
+		response.InternalServerError(c, "Failed to process customer address")
+	}
+}
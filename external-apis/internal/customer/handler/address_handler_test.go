@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/service"
+	"external-apis/internal/shared/idgen"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAddressService answers address listings with no addresses
+type stubAddressService struct {
+	service.AddressService
+	listed []string
+}
+
+func (s *stubAddressService) GetAddresses(ctx context.Context, customerID string) ([]*model.AddressResponse, error) {
+	s.listed = append(s.listed, customerID)
+	return []*model.AddressResponse{}, nil
+}
+
+func TestAddressHandler_ValidID(t *testing.T) {
+	ids := idgen.Prefixed("cus_", idgen.UUIDv7())
+	id := ids.New()
+	tests := []struct {
+		name       string
+		path       string
+		want       int
+		wantListed []string
+	}{
+		{"Well-formed customer ID", "/customers/" + id + "/addresses", http.StatusOK, []string{id}},
+		{"Malformed customer ID", "/customers/customer-456/addresses", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			addresses := &stubAddressService{}
+			router := gin.New()
+			NewAddressHandler(addresses, ids).RegisterRoutes(router.Group(""), func(c *gin.Context) { c.Next() })
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.wantListed, addresses.listed)
+		})
+	}
+}
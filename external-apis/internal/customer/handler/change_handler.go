@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/shared/changefeed"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeHandler serves the change feed of customers
+type ChangeHandler struct {
+	feed *changefeed.Feed
+}
+
+// NewChangeHandler creates a new customer change feed handler
+func NewChangeHandler(feed *changefeed.Feed) *ChangeHandler {
+	return &ChangeHandler{
+		feed: feed,
+	}
+}
+
+// RegisterRoutes registers the customer change feed route
+func (h *ChangeHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/customers/changes", h.GetChanges)
+}
+
+// GetChanges godoc
+// @Summary Get customer changes
+// @Description Read the customers created, updated and deleted since a cursor, in the order the changes happened. Creates and updates carry the customer as it was after the change; deletes are tombstones with the ID only. Start without since to read every retained change, then pass the cursor of each page to resume. A cursor older than the retained changes, or issued before the service restarted, answers 410, after which clients resync with a full export.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param since query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Success 200 {object} response.SuccessResponse{data=changefeed.Page}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 410 {object} response.ErrorResponse
+// @Router /api/v1/customers/changes [get]
+func (h *ChangeHandler) GetChanges(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	changes, err := h.feed.Changes(c.Request.Context(), c.Query("since"), page.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, changefeed.ErrInvalidCursor):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, changefeed.ErrCursorExpired):
+			response.Error(c, http.StatusGone, "cursor_expired", err.Error())
+		default:
+			log.Ctx(c.Request.Context()).WithError(err).Error("Failed to read customer changes")
+			response.InternalServerError(c, "Failed to read customer changes")
+		}
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"since":      c.Query("since"),
+		"changes":    len(changes.Changes),
+		"request_id": c.GetString("request_id"),
+	}).Debug("Read customer changes")
+
+	response.OK(c, changes)
+}
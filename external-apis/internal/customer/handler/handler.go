@@ -1,50 +1,86 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
 	"external-apis/internal/customer/model"
 	"external-apis/internal/customer/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/conditional"
+	"external-apis/internal/shared/exporter"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/patch"
+	"external-apis/internal/shared/rbac"
 	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/timerange"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
+var log = logger.New("customer/handler")
+
 // CustomerHandler handles HTTP requests for customers
 type CustomerHandler struct {
 	service service.CustomerService
+	ids     idgen.Generator
 }
 
-// NewCustomerHandler creates a new customer handler
-func NewCustomerHandler(service service.CustomerService) *CustomerHandler {
+// NewCustomerHandler creates a new customer handler. Customer IDs in request
+// paths are checked against ids.
+func NewCustomerHandler(service service.CustomerService, ids idgen.Generator) *CustomerHandler {
 	return &CustomerHandler{
 		service: service,
+		ids:     ids,
 	}
 }
 
-// RegisterRoutes registers all customer routes
-func (h *CustomerHandler) RegisterRoutes(router *gin.RouterGroup) {
-	customers := router.Group("/customers")
+// RegisterRoutes registers all customer routes. Reads are public; writes go
+// through requireAuth.
+func (h *CustomerHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	customers := router.Group("/customers", middleware.ValidID(h.ids.Valid, "id"))
 	{
 		customers.GET("", h.GetAllCustomers)
+		customers.GET("/search", h.SearchCustomers)
+		customers.GET("/export", middleware.RaiseTimeout(0), h.ExportCustomers)
+		customers.GET("/stats", h.GetCustomerStats)
 		customers.GET("/:id", h.GetCustomerByID)
+		customers.POST("/batch-get", h.BatchGetCustomers)
 		customers.GET("/email/:email", h.GetCustomerByEmail)
-		customers.POST("", h.CreateCustomer)
-		customers.PUT("/:id", h.UpdateCustomer)
-		customers.DELETE("/:id", h.DeleteCustomer)
+		customers.POST("", requireAuth, h.CreateCustomer)
+		customers.POST("/bulk", requireAuth, rbac.Require(rbac.PermissionDelete), middleware.RaiseBodyLimit(bulk.MaxBodySize), middleware.RaiseTimeout(bulk.Timeout), h.BulkCustomers)
+		customers.PUT("/:id", requireAuth, h.UpdateCustomer)
+		customers.PATCH("/:id", requireAuth, h.PatchCustomer)
+		customers.DELETE("/:id", requireAuth, h.DeleteCustomer)
+		customers.POST("/:id/restore", requireAuth, h.RestoreCustomer)
+		customers.POST("/:id/status", requireAuth, h.ChangeCustomerStatus)
+		customers.GET("/:id/history", h.GetCustomerHistory)
 	}
 }
 
 // GetCustomerByID godoc
 // @Summary Get customer by ID
-// @Description Get a customer by its ID
+// @Description Get a customer by its ID. The response carries ETag and Last-Modified headers for conditional requests.
 // @Tags customers
 // @Accept json
 // @Produce json
 // @Param id path string true "Customer ID"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is current"
+// @Param If-Modified-Since header string false "Time of a cached copy; answered with 304 if it is current"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email; all fields if omitted"
 // @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/customers/{id} [get]
+// @Router /api/v1/customers/{id} [get]
 func (h *CustomerHandler) GetCustomerByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -53,46 +89,276 @@ func (h *CustomerHandler) GetCustomerByID(c *gin.Context) {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	fields, err := response.ParseFields(c, model.CustomerResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"customer_id": id,
 		"request_id":  c.GetString("request_id"),
 	}).Info("Getting customer by ID")
 
-	customer, err := h.service.GetCustomerByID(id)
+	customer, err := h.service.GetCustomerByID(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "customer not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Customer not found")
 			return
 		}
 
-		logrus.WithError(err).WithField("customer_id", id).Error("Failed to get customer")
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to get customer")
 		response.InternalServerError(c, "Failed to retrieve customer")
 		return
 	}
 
-	response.OK(c, customer)
+	conditional.OK(c, response.Project(customer, fields), customer.UpdatedAt)
+}
+
+// BatchGetCustomers godoc
+// @Summary Get several customers by ID
+// @Description Get up to 500 customers in one round trip. Every distinct ID is reported, in request order, as found or not_found.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param ids body batch.Request true "Customer IDs"
+// @Success 200 {object} response.SuccessResponse{data=batch.Response}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/customers/batch-get [post]
+func (h *CustomerHandler) BatchGetCustomers(c *gin.Context) {
+	var req batch.Request
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for batch get customers")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"ids":        len(req.IDs),
+		"request_id": c.GetString("request_id"),
+	}).Info("Batch getting customers")
+
+	result, err := h.service.BatchGetCustomers(c.Request.Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to batch get customers")
+		response.InternalServerError(c, "Failed to retrieve customers")
+		return
+	}
+
+	response.OK(c, result)
 }
 
 // GetAllCustomers godoc
 // @Summary Get all customers
-// @Description Get a list of all customers
+// @Description Get a paginated list of customers
 // @Tags customers
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.SuccessResponse{data=[]model.CustomerResponse}
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of customers to skip"
+// @Param status query string false "Filter by status (ACTIVE, INACTIVE, BLOCKED, PENDING)"
+// @Param active query bool false "Filter by active flag"
+// @Param created_since query string false "Only entries created at or after this RFC 3339 time"
+// @Param created_before query string false "Only entries created before this RFC 3339 time"
+// @Param updated_since query string false "Only entries updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only entries updated before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft-deleted entries"
+// @Param sort query string false "Sort order (id, id_desc, name, name_desc)"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email; all fields if omitted"
+// @Success 200 {object} response.PagedResponse{data=[]model.CustomerResponse}
+// @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/customers [get]
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/customers [get]
 func (h *CustomerHandler) GetAllCustomers(c *gin.Context) {
-	logrus.WithField("request_id", c.GetString("request_id")).Info("Getting all customers")
+	filter, err := parseCustomerFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	fields, err := response.ParseFields(c, model.CustomerResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
-	customers, err := h.service.GetAllCustomers()
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"limit":      filter.Page.Limit,
+		"offset":     filter.Page.Offset,
+		"sort":       filter.Sort,
+		"request_id": c.GetString("request_id"),
+	}).Info("Getting all customers")
+
+	customers, meta, err := h.service.ListCustomers(c.Request.Context(), filter)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get all customers")
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to get all customers")
 		response.InternalServerError(c, "Failed to retrieve customers")
 		return
 	}
 
-	response.OK(c, customers)
+	response.Paged(c, response.Project(customers, fields), meta)
+}
+
+// SearchCustomers godoc
+// @Summary Search customers
+// @Description Search customers by any combination of criteria; a customer must match all of them
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param name query string false "Part of the name (case-insensitive)"
+// @Param email_domain query string false "Email domain, e.g. example.com (case-insensitive)"
+// @Param phone_prefix query string false "Start of the phone number; formatting is ignored"
+// @Param status query string false "Filter by status (ACTIVE, INACTIVE, BLOCKED, PENDING)"
+// @Param active query bool false "Filter by active flag"
+// @Param created_since query string false "Only entries created at or after this RFC 3339 time"
+// @Param created_before query string false "Only entries created before this RFC 3339 time"
+// @Param updated_since query string false "Only entries updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only entries updated before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft-deleted entries"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of customers to skip"
+// @Param sort query string false "Sort order (id, id_desc, name, name_desc)"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email; all fields if omitted"
+// @Success 200 {object} response.PagedResponse{data=[]model.CustomerResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/customers/search [get]
+func (h *CustomerHandler) SearchCustomers(c *gin.Context) {
+	filter, err := parseCustomerFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	fields, err := response.ParseFields(c, model.CustomerResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter.Name = c.Query("name")
+	filter.EmailDomain = c.Query("email_domain")
+	filter.PhonePrefix = c.Query("phone_prefix")
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"limit":      filter.Page.Limit,
+		"offset":     filter.Page.Offset,
+		"sort":       filter.Sort,
+		"request_id": c.GetString("request_id"),
+	}).Info("Searching customers")
+
+	customers, meta, err := h.service.SearchCustomers(c.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to search customers")
+		response.InternalServerError(c, "Failed to search customers")
+		return
+	}
+
+	response.Paged(c, response.Project(customers, fields), meta)
+}
+
+// ExportCustomers godoc
+// @Summary Export customers
+// @Description Stream every customer matching the list filters as a CSV or NDJSON file. The body is sent in chunks as it is read and gzipped when the client accepts it. CSV columns are id, name, email, phone, active, status, createdAt, updatedAt and deletedAt.
+// @Tags customers
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "File format" Enums(csv, ndjson) default(csv)
+// @Param status query string false "Filter by status (ACTIVE, INACTIVE, BLOCKED, PENDING)"
+// @Param active query bool false "Filter by active flag"
+// @Param created_since query string false "Only entries created at or after this RFC 3339 time"
+// @Param created_before query string false "Only entries created before this RFC 3339 time"
+// @Param updated_since query string false "Only entries updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only entries updated before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft-deleted entries"
+// @Param sort query string false "Sort order (id, id_desc, name, name_desc)"
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/customers/export [get]
+func (h *CustomerHandler) ExportCustomers(c *gin.Context) {
+	format, err := exporter.ParseFormat(c.Query("format"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter, err := parseCustomerFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"format": format,
+		"sort":   filter.Sort,
+	}).Info("Exporting customers")
+
+	writer := exporter.NewWriter(c, format, "customers", model.CustomerCSVHeader)
+	err = h.service.ExportCustomers(c.Request.Context(), filter, func(customer *model.CustomerResponse) error {
+		return writer.Write(customer)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Once rows have been sent the status cannot change; the transfer is cut
+	// short so the client does not mistake the rows for the full export
+	if writer.Started() {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("rows", writer.Rows()).Warn("Customer export interrupted")
+		writer.Abort()
+		return
+	}
+
+	if errors.Is(err, apperror.ErrValidation) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.GatewayTimeout(c, "Request deadline exceeded")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).Error("Failed to export customers")
+	response.InternalServerError(c, "Failed to export customers")
 }
 
 // GetCustomerByEmail godoc
@@ -102,11 +368,15 @@ func (h *CustomerHandler) GetAllCustomers(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param email path string true "Customer Email"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is current"
+// @Param If-Modified-Since header string false "Time of a cached copy; answered with 304 if it is current"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,email; all fields if omitted"
 // @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/customers/email/{email} [get]
+// @Router /api/v1/customers/email/{email} [get]
 func (h *CustomerHandler) GetCustomerByEmail(c *gin.Context) {
 	email := c.Param("email")
 
@@ -115,29 +385,35 @@ func (h *CustomerHandler) GetCustomerByEmail(c *gin.Context) {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	fields, err := response.ParseFields(c, model.CustomerResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"email":      email,
 		"request_id": c.GetString("request_id"),
 	}).Info("Getting customer by email")
 
-	customer, err := h.service.GetCustomerByEmail(email)
+	customer, err := h.service.GetCustomerByEmail(c.Request.Context(), email)
 	if err != nil {
-		if err.Error() == "customer not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Customer not found")
 			return
 		}
 
-		logrus.WithError(err).WithField("email", email).Error("Failed to get customer by email")
+		log.Ctx(c.Request.Context()).WithError(err).WithField("email", email).Error("Failed to get customer by email")
 		response.InternalServerError(c, "Failed to retrieve customer")
 		return
 	}
 
-	response.OK(c, customer)
+	conditional.OK(c, response.Project(customer, fields), customer.UpdatedAt)
 }
 
 // CreateCustomer godoc
 // @Summary Create a new customer
-// @Description Create a new customer
+// @Description Create a new customer. When email verification is enabled the customer is created PENDING and inactive, and a verification token is emailed to them.
 // @Tags customers
 // @Accept json
 // @Produce json
@@ -145,32 +421,32 @@ func (h *CustomerHandler) GetCustomerByEmail(c *gin.Context) {
 // @Success 201 {object} response.SuccessResponse{data=model.CustomerResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/customers [post]
+// @Router /api/v1/customers [post]
 func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
 	var req model.CreateCustomerRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logrus.WithError(err).Error("Invalid request body for create customer")
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create customer")
+		response.InvalidRequest(c, err)
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"name":       req.Name,
 		"email":      req.Email,
 		"request_id": c.GetString("request_id"),
 	}).Info("Creating new customer")
 
-	customer, err := h.service.CreateCustomer(req)
+	customer, err := h.service.CreateCustomer(c.Request.Context(), req)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create customer")
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to create customer")
 
-		if err.Error() == "customer already exists" || err.Error() == "customer with this email already exists" {
+		if errors.Is(err, apperror.ErrConflict) {
 			response.Conflict(c, err.Error())
 			return
 		}
 
-		if err.Error() == "invalid email format" || err.Error() == "invalid phone format" {
+		if errors.Is(err, apperror.ErrValidation) {
 			response.BadRequest(c, err.Error())
 			return
 		}
@@ -184,7 +460,7 @@ func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
 
 // UpdateCustomer godoc
 // @Summary Update a customer
-// @Description Update an existing customer
+// @Description Update an existing customer. A new status must be reachable from the current one; see the status endpoint for the allowed transitions.
 // @Tags customers
 // @Accept json
 // @Produce json
@@ -193,8 +469,9 @@ func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/customers/{id} [put]
+// @Router /api/v1/customers/{id} [put]
 func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 	id := c.Param("id")
 
@@ -206,34 +483,34 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 	var req model.UpdateCustomerRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logrus.WithError(err).Error("Invalid request body for update customer")
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update customer")
+		response.InvalidRequest(c, err)
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"customer_id": id,
 		"request_id":  c.GetString("request_id"),
 	}).Info("Updating customer")
 
-	customer, err := h.service.UpdateCustomer(id, req)
+	customer, err := h.service.UpdateCustomer(c.Request.Context(), id, req)
 	if err != nil {
-		if err.Error() == "customer not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Customer not found")
 			return
 		}
 
-		if err.Error() == "customer with this email already exists" {
+		if errors.Is(err, apperror.ErrConflict) {
 			response.Conflict(c, err.Error())
 			return
 		}
 
-		if err.Error() == "invalid email format" || err.Error() == "invalid phone format" || err.Error() == "invalid customer status" {
+		if errors.Is(err, apperror.ErrValidation) {
 			response.BadRequest(c, err.Error())
 			return
 		}
 
-		logrus.WithError(err).WithField("customer_id", id).Error("Failed to update customer")
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to update customer")
 		response.InternalServerError(c, "Failed to update customer")
 		return
 	}
@@ -241,6 +518,126 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 	response.OK(c, customer)
 }
 
+// PatchCustomer godoc
+// @Summary Patch a customer
+// @Description Change some fields of a customer with an RFC 7386 merge patch (application/merge-patch+json, or application/json) or an RFC 6902 JSON Patch (application/json-patch+json). Fields the patch leaves out stay as they are; every field is required, so one set to null or removed is rejected. A failed test operation gets 409 Conflict, and a patch whose If-Match header names a stale version gets 412 Precondition Failed.
+// @Tags customers
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param patch body model.CustomerDocument true "Merge patch, or an array of patch.Operation"
+// @Param If-Match header string false "ETag of the version the patch was made against; answered with 412 if it is stale"
+// @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 415 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id} [patch]
+func (h *CustomerHandler) PatchCustomer(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		response.BadRequest(c, "Customer ID is required")
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+	p, err := patch.Parse(c.ContentType(), body)
+	if err != nil {
+		if errors.Is(err, patch.ErrUnsupportedMediaType) {
+			response.UnsupportedMediaType(c, err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"customer_id": id,
+		"request_id":  c.GetString("request_id"),
+	}).Info("Patching customer")
+
+	if !h.matchesCurrent(c, id) {
+		return
+	}
+
+	customer, err := h.service.PatchCustomer(c.Request.Context(), id, p)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Customer not found")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrConflict) {
+			response.Conflict(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrUnprocessable) {
+			response.UnprocessableEntity(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, patch.ErrInvalidDocument) {
+			response.InvalidRequest(c, err)
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to patch customer")
+		response.InternalServerError(c, "Failed to patch customer")
+		return
+	}
+
+	response.OK(c, customer)
+}
+
+// matchesCurrent checks the If-Match header of a request changing a customer
+// against the ETag of its current version, answering the request itself
+// when the customer cannot be changed
+func (h *CustomerHandler) matchesCurrent(c *gin.Context, id string) bool {
+	if c.GetHeader("If-Match") == "" {
+		return true
+	}
+
+	current, err := h.service.GetCustomerByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Customer not found")
+			return false
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to get customer")
+		response.InternalServerError(c, "Failed to retrieve customer")
+		return false
+	}
+
+	matches, err := conditional.Matches(c, current)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to encode customer")
+		response.InternalServerError(c, "Failed to retrieve customer")
+		return false
+	}
+	if !matches {
+		response.PreconditionFailed(c, "Customer has changed since it was read")
+		return false
+	}
+	return true
+}
+
 // DeleteCustomer godoc
 // @Summary Delete a customer
 // @Description Delete a customer by ID
@@ -252,7 +649,7 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/customers/{id} [delete]
+// @Router /api/v1/customers/{id} [delete]
 func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 	id := c.Param("id")
 
@@ -261,22 +658,272 @@ func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"customer_id": id,
 		"request_id":  c.GetString("request_id"),
 	}).Info("Deleting customer")
 
-	err := h.service.DeleteCustomer(id)
+	err := h.service.DeleteCustomer(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "customer not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Customer not found")
 			return
 		}
 
-		logrus.WithError(err).WithField("customer_id", id).Error("Failed to delete customer")
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to delete customer")
 		response.InternalServerError(c, "Failed to delete customer")
 		return
 	}
 
-	response.OK(c, gin.H{"message": "Customer deleted successfully"})
+	response.Message(c, http.StatusOK, "Customer deleted successfully")
+}
+
+// BulkCustomers godoc
+// @Summary Bulk create, update and delete customers
+// @Description Apply a batch of customer operations all-or-nothing. Every operation is reported; if any fails none are applied.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param operations body model.BulkCustomerRequest true "Customer operations"
+// @Success 200 {object} response.SuccessResponse{data=bulk.Response}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 422 {object} bulk.Response
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/customers/bulk [post]
+func (h *CustomerHandler) BulkCustomers(c *gin.Context) {
+	var req model.BulkCustomerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for bulk customers")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"operations": len(req.Operations),
+		"request_id": c.GetString("request_id"),
+	}).Info("Applying bulk customer operations")
+
+	result, err := h.service.BulkCustomers(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to apply bulk customer operations")
+		response.InternalServerError(c, "Failed to apply bulk customer operations")
+		return
+	}
+
+	if !result.Committed {
+		response.JSON(c, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	response.OK(c, result)
+}
+
+// RestoreCustomer godoc
+// @Summary Restore a customer
+// @Description Restore a soft-deleted customer
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/restore [post]
+func (h *CustomerHandler) RestoreCustomer(c *gin.Context) {
+	id := c.Param("id")
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"customer_id": id,
+		"request_id":  c.GetString("request_id"),
+	}).Info("Restoring customer")
+
+	customer, err := h.service.RestoreCustomer(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Customer not found")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrConflict) {
+			response.Conflict(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to restore customer")
+		response.InternalServerError(c, "Failed to restore customer")
+		return
+	}
+
+	response.OK(c, customer)
+}
+
+// ChangeCustomerStatus godoc
+// @Summary Change a customer's status
+// @Description Move a customer to another status and record who did it and why in its history. PENDING customers can become ACTIVE, INACTIVE or BLOCKED; ACTIVE, INACTIVE and BLOCKED customers can move between each other; no customer goes back to PENDING. Only ACTIVE customers are left active.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param status body model.ChangeStatusRequest true "New status and reason"
+// @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/status [post]
+func (h *CustomerHandler) ChangeCustomerStatus(c *gin.Context) {
+	id := c.Param("id")
+
+	var req model.ChangeStatusRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for change customer status")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"customer_id": id,
+		"status":      req.Status,
+		"request_id":  c.GetString("request_id"),
+	}).Info("Changing customer status")
+
+	customer, err := h.service.ChangeCustomerStatus(c.Request.Context(), id, req)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to change customer status")
+		response.InternalServerError(c, "Failed to change customer status")
+		return
+	}
+
+	response.OK(c, customer)
+}
+
+// GetCustomerHistory godoc
+// @Summary Get customer history
+// @Description Get the audit history of a customer, oldest first: who created, updated, deleted or restored it, when, and which fields changed, and why its status was changed. Emails and phone numbers in the changes are redacted.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of entries to skip"
+// @Success 200 {object} response.PagedResponse{data=[]model.HistoryEntry}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/history [get]
+func (h *CustomerHandler) GetCustomerHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	entries, meta, err := h.service.GetCustomerHistory(c.Request.Context(), id, page)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Customer not found")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to get customer history")
+		response.InternalServerError(c, "Failed to retrieve customer history")
+		return
+	}
+
+	response.Paged(c, entries, meta)
+}
+
+// GetCustomerStats godoc
+// @Summary Get customer statistics
+// @Description Count the customers that are not deleted, in total and by status. Statuses without customers are counted as zero.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=model.CustomerStats}
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/customers/stats [get]
+func (h *CustomerHandler) GetCustomerStats(c *gin.Context) {
+	stats, err := h.service.GetCustomerStats(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to get customer stats")
+		response.InternalServerError(c, "Failed to retrieve customer statistics")
+		return
+	}
+
+	response.OK(c, stats)
+}
+
+// parseCustomerFilter builds a customer filter from the query parameters
+func parseCustomerFilter(c *gin.Context) (model.CustomerFilter, error) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		return model.CustomerFilter{}, err
+	}
+
+	filter := model.CustomerFilter{
+		Sort: c.Query("sort"),
+		Page: page,
+	}
+
+	if value := c.Query("status"); value != "" {
+		status := model.CustomerStatus(strings.ToUpper(value))
+		if !status.IsValid() {
+			return model.CustomerFilter{}, model.ErrInvalidStatus
+		}
+		filter.Status = &status
+	}
+
+	if value := c.Query("include_deleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.CustomerFilter{}, errors.New("include_deleted must be true or false")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	if value := c.Query("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.CustomerFilter{}, errors.New("active must be true or false")
+		}
+		filter.Active = &active
+	}
+
+	if filter.Created, err = timerange.FromQuery(c, "created"); err != nil {
+		return model.CustomerFilter{}, err
+	}
+	if filter.Updated, err = timerange.FromQuery(c, "updated"); err != nil {
+		return model.CustomerFilter{}, err
+	}
+
+	if !model.IsValidCustomerSort(filter.Sort) {
+		return model.CustomerFilter{}, model.ErrInvalidSort
+	}
+
+	return filter, nil
 }
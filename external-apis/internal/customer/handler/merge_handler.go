@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/rbac"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// MergeHandler handles HTTP requests for merging duplicate customers
+type MergeHandler struct {
+	service service.MergeService
+	ids     idgen.Generator
+}
+
+// NewMergeHandler creates a new merge handler. The customer IDs of merge
+// requests are checked against ids.
+func NewMergeHandler(service service.MergeService, ids idgen.Generator) *MergeHandler {
+	return &MergeHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the customer merge routes. The duplicate report is
+// public; merging goes through requireAuth.
+func (h *MergeHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	customers := router.Group("/customers")
+	{
+		customers.GET("/duplicates", h.FindDuplicates)
+		customers.POST("/merge", requireAuth, rbac.Require(rbac.PermissionDelete), h.MergeCustomers)
+	}
+}
+
+// MergeCustomers godoc
+// @Summary Merge customers
+// @Description Merge a duplicate customer into the surviving one. The survivor keeps its fields except those listed in take, and empty ones are filled from the merged customer. The merged customer is deleted, its addresses move to the survivor and its ID becomes an alias of the survivor; orders are repointed on the customer.merged event.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param merge body model.MergeCustomersRequest true "Customers to merge"
+// @Success 200 {object} response.SuccessResponse{data=model.MergeResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/merge [post]
+func (h *MergeHandler) MergeCustomers(c *gin.Context) {
+	var req model.MergeCustomersRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for merge customers")
+		response.InvalidRequest(c, err)
+		return
+	}
+	if !h.ids.Valid(req.SurvivorID) {
+		response.BadRequest(c, "Malformed survivorId")
+		return
+	}
+	if !h.ids.Valid(req.MergedID) {
+		response.BadRequest(c, "Malformed mergedId")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"survivor_id": req.SurvivorID,
+		"merged_id":   req.MergedID,
+		"request_id":  c.GetString("request_id"),
+	}).Info("Merging customers")
+
+	merged, err := h.service.MergeCustomers(c.Request.Context(), req)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to merge customers")
+		response.InternalServerError(c, "Failed to merge customers")
+		return
+	}
+
+	response.OK(c, merged)
+}
+
+// FindDuplicates godoc
+// @Summary Find duplicate customers
+// @Description Report pairs of customers that are likely the same person, most likely first. Emails are compared ignoring case and +tags, phone numbers by their digits and names by edit distance; the matching fields add up to a score from 0 to 1.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param min_score query number false "Lowest score reported (0-1, default 0.75)"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of pairs to skip"
+// @Success 200 {object} response.PagedResponse{data=[]model.DuplicatePair}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/duplicates [get]
+func (h *MergeHandler) FindDuplicates(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	minScore := model.DefaultDuplicateScore
+	if value := c.Query("min_score"); value != "" {
+		if minScore, err = strconv.ParseFloat(value, 64); err != nil {
+			response.BadRequest(c, model.ErrInvalidMinScore.Error())
+			return
+		}
+	}
+
+	pairs, meta, err := h.service.FindDuplicates(c.Request.Context(), minScore, page)
+	if err != nil {
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to find duplicate customers")
+		response.InternalServerError(c, "Failed to find duplicate customers")
+		return
+	}
+
+	response.Paged(c, pairs, meta)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// resendMessage answers every resend by email, whether or not a token was
+// sent, so the endpoint does not reveal which emails are registered
+const resendMessage = "If the email belongs to a customer awaiting verification, a new token has been sent"
+
+// VerificationHandler handles HTTP requests for verifying customer emails
+type VerificationHandler struct {
+	service service.VerificationService
+	ids     idgen.Generator
+}
+
+// NewVerificationHandler creates a new verification handler. Customer IDs in request
+// paths are checked against ids.
+func NewVerificationHandler(service service.VerificationService, ids idgen.Generator) *VerificationHandler {
+	return &VerificationHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the verification routes. Verifying and resending
+// by email are public, as pending customers have no credentials; resending by
+// customer ID goes through requireAuth.
+func (h *VerificationHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	customers := router.Group("/customers", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		customers.POST("/verify", h.VerifyEmail)
+		customers.POST("/verify/resend", h.ResendVerificationByEmail)
+		customers.POST("/:id/verification", requireAuth, h.ResendVerification)
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify a customer email
+// @Description Confirm the email of a pending customer with the token emailed to them, making the customer ACTIVE. Tokens are single-use and stop working once they expire or the customer's email changes.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param verification body model.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} response.SuccessResponse{data=model.CustomerResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/verify [post]
+func (h *VerificationHandler) VerifyEmail(c *gin.Context) {
+	var req model.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for verify email")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	customer, err := h.service.VerifyEmail(c.Request.Context(), req)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to verify customer email")
+		response.InternalServerError(c, "Failed to verify customer email")
+		return
+	}
+
+	response.OK(c, customer)
+}
+
+// ResendVerificationByEmail godoc
+// @Summary Resend a verification token
+// @Description Email a new verification token to the pending customer with the given email, invalidating the previous one. The answer is the same whether or not a token was sent, so it does not reveal which emails are registered; tokens are sent at most once per resend interval.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param resend body model.ResendVerificationRequest true "Customer email"
+// @Success 202 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/verify/resend [post]
+func (h *VerificationHandler) ResendVerificationByEmail(c *gin.Context) {
+	var req model.ResendVerificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for resend verification")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	if err := h.service.ResendVerificationByEmail(c.Request.Context(), req.Email); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to resend verification email")
+		response.InternalServerError(c, "Failed to resend verification email")
+		return
+	}
+
+	response.Message(c, http.StatusAccepted, resendMessage)
+}
+
+// ResendVerification godoc
+// @Summary Resend a customer's verification token
+// @Description Email a new verification token to a pending customer, invalidating the previous one. Tokens are sent at most once per resend interval.
+// @Tags customers
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 202 {object} response.SuccessResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/customers/{id}/verification [post]
+func (h *VerificationHandler) ResendVerification(c *gin.Context) {
+	id := c.Param("id")
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"customer_id": id,
+		"request_id":  c.GetString("request_id"),
+	}).Info("Resending verification email")
+
+	if err := h.service.ResendVerification(c.Request.Context(), id); err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", id).Error("Failed to resend verification email")
+		response.InternalServerError(c, "Failed to resend verification email")
+		return
+	}
+
+	response.Message(c, http.StatusAccepted, "Verification email sent")
+}
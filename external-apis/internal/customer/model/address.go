@@ -0,0 +1,105 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// AddressType distinguishes shipping from billing addresses
+type AddressType string
+
+const (
+	AddressTypeShipping AddressType = "SHIPPING"
+	AddressTypeBilling  AddressType = "BILLING"
+)
+
+// IsValid checks if the address type is valid
+func (t AddressType) IsValid() bool {
+	switch t {
+	case AddressTypeShipping, AddressTypeBilling:
+		return true
+	default:
+		return false
+	}
+}
+
+// Address represents a postal address in a customer's address book
+type Address struct {
+	ID         string      `json:"id"`
+	CustomerID string      `json:"customerId"`
+	Type       AddressType `json:"type"`
+	Line1      string      `json:"line1"`
+	Line2      string      `json:"line2,omitempty"`
+	City       string      `json:"city"`
+	Region     string      `json:"region,omitempty"`
+	PostalCode string      `json:"postalCode"`
+	Country    string      `json:"country"`
+	IsDefault  bool        `json:"isDefault"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// AddressResponse represents the API response for an address
+type AddressResponse struct {
+	ID         string      `json:"id"`
+	CustomerID string      `json:"customerId"`
+	Type       AddressType `json:"type"`
+	Line1      string      `json:"line1"`
+	Line2      string      `json:"line2,omitempty"`
+	City       string      `json:"city"`
+	Region     string      `json:"region,omitempty"`
+	PostalCode string      `json:"postalCode"`
+	Country    string      `json:"country"`
+	IsDefault  bool        `json:"isDefault"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// ToResponse converts an Address to AddressResponse
+func (a *Address) ToResponse() AddressResponse {
+	return AddressResponse{
+		ID:         a.ID,
+		CustomerID: a.CustomerID,
+		Type:       a.Type,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		IsDefault:  a.IsDefault,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+// Normalize trims whitespace and upper-cases the country and postal code
+func (a *Address) Normalize() {
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.TrimSpace(a.Region)
+	a.PostalCode = strings.ToUpper(strings.TrimSpace(a.PostalCode))
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+}
+
+// CreateAddressRequest represents the request to add an address
+type CreateAddressRequest struct {
+	Type       AddressType `json:"type" binding:"required,enum"`
+	Line1      string      `json:"line1" binding:"required"`
+	Line2      string      `json:"line2,omitempty"`
+	City       string      `json:"city" binding:"required"`
+	Region     string      `json:"region,omitempty"`
+	PostalCode string      `json:"postalCode"`
+	Country    string      `json:"country" binding:"required,len=2"`
+	IsDefault  bool        `json:"isDefault"`
+}
+
+// UpdateAddressRequest represents the request to update an address
+type UpdateAddressRequest struct {
+	Type       *AddressType `json:"type,omitempty" binding:"omitempty,enum"`
+	Line1      *string      `json:"line1,omitempty"`
+	Line2      *string      `json:"line2,omitempty"`
+	City       *string      `json:"city,omitempty"`
+	Region     *string      `json:"region,omitempty"`
+	PostalCode *string      `json:"postalCode,omitempty"`
+	Country    *string      `json:"country,omitempty"`
+	IsDefault  *bool        `json:"isDefault,omitempty"`
+}
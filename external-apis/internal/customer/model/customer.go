@@ -1,5 +1,15 @@
 package model
 
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/phone"
+	"external-apis/internal/shared/timerange"
+)
+
 // CustomerStatus represents the status of a customer
 type CustomerStatus string
 
@@ -18,27 +28,93 @@ type Customer struct {
 	Phone  string         `json:"phone"`
 	Active bool           `json:"active"`
 	Status CustomerStatus `json:"status"`
+	// CreatedAt is the time the customer was created
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time of the last change to the customer
+	UpdatedAt time.Time `json:"updatedAt"`
+	// DeletedAt is set when the customer has been soft-deleted
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// EmailIndex is the blind index of Email when it is stored encrypted
+	EmailIndex string `json:"-"`
+}
+
+// IsDeleted checks if the customer has been soft-deleted
+func (c *Customer) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// Clone returns a deep copy of the customer
+func (c *Customer) Clone() *Customer {
+	clone := *c
+	if c.DeletedAt != nil {
+		deletedAt := *c.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
+
+// EmailKey returns the value email lookups match: the blind index of an
+// encrypted email, or the email itself
+func (c *Customer) EmailKey() string {
+	if c.EmailIndex != "" {
+		return c.EmailIndex
+	}
+	return c.Email
 }
 
 // CustomerResponse represents the API response for a customer
 type CustomerResponse struct {
-	ID     string         `json:"id"`
-	Name   string         `json:"name"`
-	Email  string         `json:"email"`
-	Phone  string         `json:"phone"`
-	Active bool           `json:"active"`
-	Status CustomerStatus `json:"status"`
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	// PhoneRegion is the ISO 3166-1 alpha-2 region of the phone number, e.g.
+	// US; it is omitted when the region cannot be told
+	PhoneRegion string         `json:"phoneRegion,omitempty"`
+	Active      bool           `json:"active"`
+	Status      CustomerStatus `json:"status"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   *time.Time     `json:"deletedAt,omitempty"`
 }
 
 // ToResponse converts a Customer to CustomerResponse
 func (c *Customer) ToResponse() CustomerResponse {
 	return CustomerResponse{
-		ID:     c.ID,
-		Name:   c.Name,
-		Email:  c.Email,
-		Phone:  c.Phone,
-		Active: c.Active,
-		Status: c.Status,
+		ID:          c.ID,
+		Name:        c.Name,
+		Email:       c.Email,
+		Phone:       c.Phone,
+		PhoneRegion: phone.Region(c.Phone),
+		Active:      c.Active,
+		Status:      c.Status,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		DeletedAt:   c.DeletedAt,
+	}
+}
+
+// CustomerCSVHeader holds the columns of a customer CSV export
+var CustomerCSVHeader = []string{"id", "name", "email", "phone", "active", "status", "createdAt", "updatedAt", "deletedAt"}
+
+// CSVRecord returns the cells of the customer in a CSV export, in the order
+// of CustomerCSVHeader
+func (r CustomerResponse) CSVRecord() []string {
+	deletedAt := ""
+	if r.DeletedAt != nil {
+		deletedAt = r.DeletedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		r.ID,
+		r.Name,
+		r.Email,
+		r.Phone,
+		strconv.FormatBool(r.Active),
+		string(r.Status),
+		r.CreatedAt.UTC().Format(time.RFC3339),
+		r.UpdatedAt.UTC().Format(time.RFC3339),
+		deletedAt,
 	}
 }
 
@@ -46,16 +122,84 @@ func (c *Customer) ToResponse() CustomerResponse {
 type CreateCustomerRequest struct {
 	Name  string `json:"name" binding:"required"`
 	Email string `json:"email" binding:"required,email"`
-	Phone string `json:"phone" binding:"required"`
+	// Phone may be formatted, e.g. +1 (202) 555-0123, and is stored in E.164
+	// format. Numbers without a country calling code are in the default
+	// region of the service.
+	Phone string `json:"phone" binding:"required,phone"`
 }
 
 // UpdateCustomerRequest represents the request to update a customer
 type UpdateCustomerRequest struct {
 	Name   *string         `json:"name,omitempty"`
-	Email  *string         `json:"email,omitempty"`
-	Phone  *string         `json:"phone,omitempty"`
+	Email  *string         `json:"email,omitempty" binding:"omitempty,email"`
+	Phone  *string         `json:"phone,omitempty" binding:"omitempty,phone"`
 	Active *bool           `json:"active,omitempty"`
-	Status *CustomerStatus `json:"status,omitempty"`
+	Status *CustomerStatus `json:"status,omitempty" binding:"omitempty,enum"`
+}
+
+// CustomerDocument is the document PATCH requests change: the fields of a
+// customer clients can update. A field the patch removed or set to null is
+// nil; every field of a customer is required, so none can be removed.
+type CustomerDocument struct {
+	Name   *string         `json:"name" binding:"required"`
+	Email  *string         `json:"email" binding:"required"`
+	Phone  *string         `json:"phone" binding:"required"`
+	Active *bool           `json:"active" binding:"required"`
+	Status *CustomerStatus `json:"status" binding:"required"`
+}
+
+// Document returns the fields of the customer PATCH requests change
+func (c *Customer) Document() CustomerDocument {
+	return CustomerDocument{
+		Name:   &c.Name,
+		Email:  &c.Email,
+		Phone:  &c.Phone,
+		Active: &c.Active,
+		Status: &c.Status,
+	}
+}
+
+// UpdateRequest returns the update setting the fields of current whose
+// values differ in the document, leaving the others alone
+func (d CustomerDocument) UpdateRequest(current *Customer) UpdateCustomerRequest {
+	var req UpdateCustomerRequest
+	if *d.Name != current.Name {
+		req.Name = d.Name
+	}
+	if *d.Email != current.Email {
+		req.Email = d.Email
+	}
+	if *d.Phone != current.Phone {
+		req.Phone = d.Phone
+	}
+	if *d.Active != current.Active {
+		req.Active = d.Active
+	}
+	if *d.Status != current.Status {
+		req.Status = d.Status
+	}
+	return req
+}
+
+// ChangeStatusRequest represents the request to move a customer to another status
+type ChangeStatusRequest struct {
+	Status CustomerStatus `json:"status" binding:"required,enum"`
+	// Reason explains the change; it is recorded in the customer's history
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// BulkCustomerOperation is a single create, update or delete in a bulk request.
+// Create carries the new customer; ID identifies the customer to update or delete.
+type BulkCustomerOperation struct {
+	Op     string                 `json:"op" binding:"required,oneof=create update delete"`
+	ID     string                 `json:"id,omitempty" binding:"required_unless=Op create"`
+	Create *CreateCustomerRequest `json:"create,omitempty" binding:"required_if=Op create"`
+	Update *UpdateCustomerRequest `json:"update,omitempty" binding:"required_if=Op update"`
+}
+
+// BulkCustomerRequest represents a batch of customer operations applied all-or-nothing
+type BulkCustomerRequest struct {
+	Operations []BulkCustomerOperation `json:"operations" binding:"required,min=1,max=5000,dive"`
 }
 
 // IsValid checks if the customer status is valid
@@ -67,3 +211,112 @@ func (s CustomerStatus) IsValid() bool {
 		return false
 	}
 }
+
+// statusTransitions lists the statuses a customer can move to from each
+// status. Pending customers await verification, so no customer goes back to
+// PENDING.
+var statusTransitions = map[CustomerStatus][]CustomerStatus{
+	StatusPending:  {StatusActive, StatusInactive, StatusBlocked},
+	StatusActive:   {StatusInactive, StatusBlocked},
+	StatusInactive: {StatusActive, StatusBlocked},
+	StatusBlocked:  {StatusActive, StatusInactive},
+}
+
+// CanTransitionTo checks if a customer with status s can move to next.
+// Staying in the same status is not a transition.
+func (s CustomerStatus) CanTransitionTo(next CustomerStatus) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Customer sort options accepted by the list endpoint
+const (
+	SortByID       = "id"
+	SortByIDDesc   = "id_desc"
+	SortByName     = "name"
+	SortByNameDesc = "name_desc"
+)
+
+// IsValidCustomerSort checks if the sort option is supported
+func IsValidCustomerSort(sort string) bool {
+	switch sort {
+	case "", SortByID, SortByIDDesc, SortByName, SortByNameDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// CustomerFilter represents the criteria used to list and search customers.
+// All criteria must match.
+type CustomerFilter struct {
+	// Name matches customers whose name contains it, ignoring case
+	Name string
+	// EmailDomain matches the part of the email after the @, ignoring case
+	EmailDomain string
+	// PhonePrefix matches the start of the phone number, comparing digits only
+	PhonePrefix string
+	Status      *CustomerStatus
+	Active      *bool
+	// Created and Updated match customers created or last changed within
+	// the ranges, so sync jobs can ask for the customers changed since their
+	// last run
+	Created timerange.Range
+	Updated timerange.Range
+	// IncludeDeleted also matches soft-deleted customers
+	IncludeDeleted bool
+	Sort           string
+	Page           pagination.Params
+}
+
+// Matches checks if the customer satisfies every filter criterion
+func (f CustomerFilter) Matches(c *Customer) bool {
+	if !f.IncludeDeleted && c.IsDeleted() {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.EmailDomain != "" && !strings.EqualFold(emailDomain(c.Email), f.EmailDomain) {
+		return false
+	}
+	if f.PhonePrefix != "" && !strings.HasPrefix(phoneDigits(c.Phone), phoneDigits(f.PhonePrefix)) {
+		return false
+	}
+	if f.Status != nil && c.Status != *f.Status {
+		return false
+	}
+	if f.Active != nil && c.Active != *f.Active {
+		return false
+	}
+	if !f.Created.Contains(c.CreatedAt) || !f.Updated.Contains(c.UpdatedAt) {
+		return false
+	}
+	return true
+}
+
+// HasSearchCriteria checks if the filter narrows the result by any customer attribute
+func (f CustomerFilter) HasSearchCriteria() bool {
+	return f.Name != "" || f.EmailDomain != "" || f.PhonePrefix != "" || f.Status != nil || f.Active != nil ||
+		!f.Created.IsZero() || !f.Updated.IsZero()
+}
+
+// emailDomain returns the part of an email address after the last @
+func emailDomain(email string) string {
+	return email[strings.LastIndex(email, "@")+1:]
+}
+
+// phoneDigits strips everything but digits from a phone number so formatted
+// and unformatted numbers compare equal
+func phoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, phone)
+}
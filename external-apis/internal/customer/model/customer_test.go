@@ -2,7 +2,9 @@ package model
 
 import (
 	"testing"
+	"time"
 
+	"external-apis/internal/shared/timerange"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,6 +54,32 @@ func TestCustomerStatus_IsValid(t *testing.T) {
 	}
 }
 
+func TestCustomerStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     CustomerStatus
+		to       CustomerStatus
+		expected bool
+	}{
+		{StatusPending, StatusActive, true},
+		{StatusPending, StatusBlocked, true},
+		{StatusActive, StatusBlocked, true},
+		{StatusActive, StatusInactive, true},
+		{StatusInactive, StatusActive, true},
+		{StatusBlocked, StatusActive, true},
+		{StatusActive, StatusPending, false},
+		{StatusBlocked, StatusPending, false},
+		{StatusActive, StatusActive, false},
+		{StatusActive, CustomerStatus("INVALID"), false},
+		{CustomerStatus(""), StatusActive, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
 func TestCustomer_ToResponse(t *testing.T) {
 	// Arrange
 	customer := &Customer{
@@ -183,3 +211,80 @@ func TestUpdateCustomerRequest(t *testing.T) {
 		assert.Equal(t, StatusInactive, *request.Status)
 	})
 }
+
+func TestCustomerFilter_Matches(t *testing.T) {
+	customer := &Customer{
+		ID:        "customer-123",
+		Name:      "John Doe",
+		Email:     "john.doe@Example.com",
+		Phone:     "+1-555-0123",
+		Active:    true,
+		Status:    StatusActive,
+		CreatedAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+	active := true
+	inactive := false
+	blocked := StatusBlocked
+	activeStatus := StatusActive
+
+	tests := []struct {
+		name   string
+		filter CustomerFilter
+		want   bool
+	}{
+		{"Empty filter", CustomerFilter{}, true},
+		{"Matching status and flag", CustomerFilter{Status: &activeStatus, Active: &active}, true},
+		{"Status mismatch", CustomerFilter{Status: &blocked}, false},
+		{"Active mismatch", CustomerFilter{Active: &inactive}, false},
+		{"Partial name ignoring case", CustomerFilter{Name: "DOE"}, true},
+		{"Name mismatch", CustomerFilter{Name: "smith"}, false},
+		{"Email domain ignoring case", CustomerFilter{EmailDomain: "example.COM"}, true},
+		{"Email domain is not a suffix match", CustomerFilter{EmailDomain: "ample.com"}, false},
+		{"Formatted phone prefix", CustomerFilter{PhonePrefix: "+1 (555)"}, true},
+		{"Unformatted phone prefix", CustomerFilter{PhonePrefix: "1555"}, true},
+		{"Phone prefix mismatch", CustomerFilter{PhonePrefix: "+44"}, false},
+		{"All criteria combined", CustomerFilter{Name: "john", EmailDomain: "example.com", PhonePrefix: "+1555", Status: &activeStatus, Active: &active}, true},
+		{"One criterion failing", CustomerFilter{Name: "john", EmailDomain: "other.com"}, false},
+		{"Updated since inclusive", CustomerFilter{Updated: timerange.Range{Since: customer.UpdatedAt}}, true},
+		{"Updated before exclusive", CustomerFilter{Updated: timerange.Range{Before: customer.UpdatedAt}}, false},
+		{"Created within range", CustomerFilter{Created: timerange.Range{Since: customer.CreatedAt.Add(-time.Hour), Before: customer.CreatedAt.Add(time.Hour)}}, true},
+		{"Created after range", CustomerFilter{Created: timerange.Range{Before: customer.CreatedAt}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(customer))
+		})
+	}
+}
+
+func TestCustomer_Clone(t *testing.T) {
+	// Arrange
+	deletedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	customer := &Customer{ID: "customer-456", Name: "John Doe", DeletedAt: &deletedAt}
+
+	// Act
+	clone := customer.Clone()
+	clone.Name = "Changed"
+	*clone.DeletedAt = deletedAt.Add(time.Hour)
+
+	// Assert
+	assert.Equal(t, "John Doe", customer.Name)
+	assert.Equal(t, deletedAt, *customer.DeletedAt)
+}
+
+func TestCustomerDocument_UpdateRequest(t *testing.T) {
+	// Arrange
+	customer := &Customer{ID: "customer-456", Name: "John Doe", Email: "john.doe@example.com", Phone: "+1-555-0123", Active: true, Status: StatusActive}
+	doc := customer.Document()
+	name, active := "Jane Doe", false
+	doc.Name, doc.Active = &name, &active
+
+	// Act
+	request := doc.UpdateRequest(customer)
+
+	// Assert
+	assert.Equal(t, UpdateCustomerRequest{Name: &name, Active: &active}, request)
+	assert.Equal(t, "John Doe", customer.Name)
+}
@@ -0,0 +1,40 @@
+package model
+
+import "external-apis/internal/shared/apperror"
+
+// Customer errors
+var (
+	ErrCustomerNotFound        = apperror.NotFound("customer not found")
+	ErrCustomerExists          = apperror.Conflict("customer already exists")
+	ErrEmailTaken              = apperror.Conflict("customer with this email already exists")
+	ErrCustomerNotDeleted      = apperror.Conflict("customer is not deleted")
+	ErrInvalidEmail            = apperror.Validation("invalid email format")
+	ErrInvalidPhone            = apperror.Validation("invalid phone format")
+	ErrInvalidPhoneCountryCode = apperror.Validation("invalid phone country calling code")
+	ErrInvalidStatus           = apperror.Validation("invalid customer status")
+	ErrInvalidStatusTransition = apperror.Conflict("customer cannot move from its current status to the requested one")
+	ErrInvalidSort             = apperror.Validation("invalid sort option")
+	ErrNoSearchCriteria        = apperror.Validation("at least one search criterion is required")
+	ErrInvalidPhonePrefix      = apperror.Validation("phone prefix must contain digits")
+	ErrMergeSameCustomer       = apperror.Validation("cannot merge a customer into itself")
+	ErrInvalidMinScore         = apperror.Validation("min_score must be between 0 and 1")
+)
+
+// Verification errors
+var (
+	ErrVerificationNotFound     = apperror.NotFound("verification not found")
+	ErrInvalidVerificationToken = apperror.Validation("invalid verification token")
+	ErrVerificationExpired      = apperror.Validation("verification token has expired")
+	ErrAlreadyVerified          = apperror.Conflict("customer email is already verified")
+	ErrResendTooSoon            = apperror.Conflict("a verification email was sent recently; try again later")
+)
+
+// Address errors
+var (
+	ErrAddressNotFound     = apperror.NotFound("address not found")
+	ErrAddressExists       = apperror.Conflict("address already exists")
+	ErrInvalidAddressType  = apperror.Validation("invalid address type")
+	ErrAddressLinesMissing = apperror.Validation("line1 and city are required")
+	ErrInvalidCountry      = apperror.Validation("invalid country code")
+	ErrInvalidPostalCode   = apperror.Validation("invalid postal code")
+)
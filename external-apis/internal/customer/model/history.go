@@ -0,0 +1,99 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// HistoryAction is the kind of change recorded in a customer's history
+type HistoryAction string
+
+const (
+	HistoryCreated  HistoryAction = "CREATED"
+	HistoryUpdated  HistoryAction = "UPDATED"
+	HistoryDeleted  HistoryAction = "DELETED"
+	HistoryRestored HistoryAction = "RESTORED"
+	HistoryMerged   HistoryAction = "MERGED"
+	HistoryVerified HistoryAction = "VERIFIED"
+	// HistoryStatusChanged records a status change made through the status
+	// endpoint, which carries a reason
+	HistoryStatusChanged HistoryAction = "STATUS_CHANGED"
+)
+
+// FieldChange is the change of a single customer field. Field is the JSON
+// name of the field; From is nil for the initial values of a new customer.
+// Emails and phone numbers are redacted, so the history never holds the
+// contact details encrypted at rest in plaintext.
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// HistoryEntry records who changed a customer, when and how. Deletes and
+// restores carry no field changes; a merge records the changes to the
+// survivor, and none for the customer merged into it. Status changes carry
+// the reason given for them.
+type HistoryEntry struct {
+	ID         string        `json:"id"`
+	CustomerID string        `json:"customerId"`
+	Action     HistoryAction `json:"action"`
+	Actor      string        `json:"actor"`
+	Timestamp  time.Time     `json:"timestamp"`
+	Changes    []FieldChange `json:"changes"`
+	Reason     string        `json:"reason,omitempty"`
+}
+
+// DiffCustomers lists the fields that differ between two versions of a
+// customer. A nil before lists every field of after as initially set. Email
+// and phone values are redacted.
+func DiffCustomers(before, after *Customer) []FieldChange {
+	changes := make([]FieldChange, 0)
+	add := func(field string, from, to interface{}, changed bool) {
+		if before == nil {
+			changes = append(changes, FieldChange{Field: field, To: to})
+		} else if changed {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+
+	previous := before
+	if previous == nil {
+		previous = &Customer{}
+	}
+
+	add("name", previous.Name, after.Name, previous.Name != after.Name)
+	add("email", redactEmail(previous.Email), redactEmail(after.Email), previous.Email != after.Email)
+	add("phone", redactPhone(previous.Phone), redactPhone(after.Phone), previous.Phone != after.Phone)
+	add("active", previous.Active, after.Active, previous.Active != after.Active)
+	add("status", previous.Status, after.Status, previous.Status != after.Status)
+
+	return changes
+}
+
+// redactEmail keeps the first character of the local part and the domain of
+// an email, e.g. j***@example.com, so changes stay recognisable
+func redactEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return redact(email, 0)
+	}
+	return local[:1] + "***@" + domain
+}
+
+// redactPhone keeps the last two digits of a phone number, e.g. ***23
+func redactPhone(phone string) string {
+	return redact(phone, 2)
+}
+
+// redact replaces all but the last keep characters of s, or all of them
+// when s is too short to hide anything
+func redact(s string, keep int) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= keep*2 {
+		keep = 0
+	}
+	return "***" + s[len(s)-keep:]
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCustomers(t *testing.T) {
+	customer := &Customer{Name: "John Doe", Email: "john.doe@example.com", Phone: "+15550123", Active: true, Status: StatusActive}
+
+	t.Run("New customer lists every field", func(t *testing.T) {
+		// Act
+		changes := DiffCustomers(nil, customer)
+
+		// Assert
+		assert.Len(t, changes, 5)
+		assert.Equal(t, FieldChange{Field: "name", To: "John Doe"}, changes[0])
+	})
+
+	t.Run("Only changed fields are listed", func(t *testing.T) {
+		// Arrange
+		after := *customer
+		after.Active = false
+		after.Status = StatusInactive
+
+		// Act
+		changes := DiffCustomers(customer, &after)
+
+		// Assert
+		assert.Equal(t, []FieldChange{
+			{Field: "active", From: true, To: false},
+			{Field: "status", From: StatusActive, To: StatusInactive},
+		}, changes)
+	})
+
+	t.Run("Contact details are redacted", func(t *testing.T) {
+		// Arrange
+		after := *customer
+		after.Email = "jane.doe@example.org"
+		after.Phone = "+15550199"
+
+		// Act
+		changes := DiffCustomers(customer, &after)
+
+		// Assert
+		assert.Equal(t, []FieldChange{
+			{Field: "email", From: "j***@example.com", To: "j***@example.org"},
+			{Field: "phone", From: "***23", To: "***99"},
+		}, changes)
+	})
+
+	t.Run("Unchanged customer has no changes", func(t *testing.T) {
+		// Act
+		changes := DiffCustomers(customer, customer)
+
+		// Assert
+		assert.NotNil(t, changes)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "j***@example.com", redactEmail("john.doe@example.com"))
+	assert.Equal(t, "***", redactEmail("not-an-email"))
+	assert.Equal(t, "***", redactEmail("@example.com"))
+	assert.Equal(t, "***67", redactPhone("+1 555 0100 4567"))
+	assert.Equal(t, "***", redactPhone("123"))
+	assert.Empty(t, redactPhone(""))
+}
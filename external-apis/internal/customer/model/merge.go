@@ -0,0 +1,224 @@
+package model
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Fields a merge can take from the merged customer
+const (
+	MergeFieldName   = "name"
+	MergeFieldEmail  = "email"
+	MergeFieldPhone  = "phone"
+	MergeFieldStatus = "status"
+)
+
+// MergeCustomersRequest represents the request to merge a duplicate customer
+// into the one that survives. The survivor keeps its own fields except those
+// listed in Take, which are copied from the merged customer; empty fields of
+// the survivor are always filled from it.
+type MergeCustomersRequest struct {
+	SurvivorID string   `json:"survivorId" binding:"required"`
+	MergedID   string   `json:"mergedId" binding:"required,nefield=SurvivorID"`
+	Take       []string `json:"take,omitempty" binding:"dive,oneof=name email phone status"`
+}
+
+// MergeResponse represents the API response for a merge
+type MergeResponse struct {
+	// Customer is the survivor after the merge
+	Customer CustomerResponse `json:"customer"`
+	// MergedID is now an alias of the survivor
+	MergedID       string `json:"mergedId"`
+	AddressesMoved int    `json:"addressesMoved"`
+}
+
+// Merge returns the survivor with the fields taken from merged, and with its
+// empty fields filled from merged. The status carries the active flag along.
+func Merge(survivor, merged *Customer, take []string) *Customer {
+	result := *survivor
+	taken := make(map[string]bool, len(take))
+	for _, field := range take {
+		taken[field] = true
+	}
+
+	if taken[MergeFieldName] || result.Name == "" {
+		result.Name = merged.Name
+	}
+	if taken[MergeFieldEmail] || result.Email == "" {
+		result.Email = merged.Email
+		result.EmailIndex = merged.EmailIndex
+	}
+	if taken[MergeFieldPhone] || result.Phone == "" {
+		result.Phone = merged.Phone
+	}
+	if taken[MergeFieldStatus] {
+		result.Status = merged.Status
+		result.Active = merged.Active
+	}
+	return &result
+}
+
+// DefaultDuplicateScore is the lowest score reported as a likely duplicate
+const DefaultDuplicateScore = 0.75
+
+// Weights of the evidence each field gives that two customers are the same
+const (
+	emailWeight = 0.95
+	phoneWeight = 0.9
+	nameWeight  = 0.8
+)
+
+// Similarities below these count as no evidence at all
+const (
+	minNameSimilarity  = 0.85
+	minEmailSimilarity = 0.9
+)
+
+// FieldMatch is a field two customers have in common
+type FieldMatch struct {
+	Field      string  `json:"field"`
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicatePair represents two customers that are likely the same person
+type DuplicatePair struct {
+	Customers []CustomerResponse `json:"customers"`
+	// Score is the likelihood the customers are duplicates, from 0 to 1
+	Score   float64      `json:"score"`
+	Matches []FieldMatch `json:"matches"`
+}
+
+// CompareCustomers scores how likely two customers are the same person from
+// their names, emails and phone numbers. Every matching field adds its
+// weighted similarity as independent evidence, so a shared email alone
+// scores high and a similar name needs another match to stand out.
+func CompareCustomers(a, b *Customer) (float64, []FieldMatch) {
+	similarities := []struct {
+		field      string
+		similarity float64
+		minimum    float64
+		weight     float64
+	}{
+		{MergeFieldEmail, emailSimilarity(a.Email, b.Email), minEmailSimilarity, emailWeight},
+		{MergeFieldPhone, phoneSimilarity(a.Phone, b.Phone), 1, phoneWeight},
+		{MergeFieldName, similarity(normalizeName(a.Name), normalizeName(b.Name)), minNameSimilarity, nameWeight},
+	}
+
+	unlikely := 1.0
+	matches := make([]FieldMatch, 0, len(similarities))
+	for _, s := range similarities {
+		if s.similarity < s.minimum {
+			continue
+		}
+		unlikely *= 1 - s.similarity*s.weight
+		matches = append(matches, FieldMatch{Field: s.field, Similarity: round(s.similarity)})
+	}
+
+	return round(1 - unlikely), matches
+}
+
+// SortDuplicates orders pairs by descending score, then by customer IDs
+func SortDuplicates(pairs []DuplicatePair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		if pairs[i].Customers[0].ID != pairs[j].Customers[0].ID {
+			return pairs[i].Customers[0].ID < pairs[j].Customers[0].ID
+		}
+		return pairs[i].Customers[1].ID < pairs[j].Customers[1].ID
+	})
+}
+
+// emailSimilarity compares emails ignoring case and +tags. Different domains
+// never match; local parts are compared for typos.
+func emailSimilarity(a, b string) float64 {
+	localA, domainA := splitEmail(a)
+	localB, domainB := splitEmail(b)
+	if localA == "" || localB == "" || domainA != domainB {
+		return 0
+	}
+	return similarity(localA, localB)
+}
+
+// splitEmail returns the normalized local part and domain of an email
+func splitEmail(email string) (string, string) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return "", ""
+	}
+
+	local, _, _ := strings.Cut(email[:at], "+")
+	return local, email[at+1:]
+}
+
+// phoneSimilarity matches phone numbers whose national digits are equal, so
+// formatting and a missing country code do not matter
+func phoneSimilarity(a, b string) float64 {
+	digitsA, digitsB := phoneDigits(a), phoneDigits(b)
+	if len(digitsA) < 7 || len(digitsB) < 7 {
+		return 0
+	}
+	if strings.HasSuffix(digitsA, lastDigits(digitsB, 10)) || strings.HasSuffix(digitsB, lastDigits(digitsA, 10)) {
+		return 1
+	}
+	return 0
+}
+
+// lastDigits returns at most the last n digits of a number
+func lastDigits(digits string, n int) string {
+	if len(digits) <= n {
+		return digits
+	}
+	return digits[len(digits)-n:]
+}
+
+// normalizeName lowercases a name, drops punctuation and sorts its words, so
+// "Doe, John" and "john doe" compare equal
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// similarity returns 1 minus the edit distance of two strings relative to the
+// longer one
+func similarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	return 1 - float64(levenshtein(ra, rb))/float64(max(len(ra), len(rb)))
+}
+
+// levenshtein returns the number of single character edits turning a into b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// round keeps two decimals of a score
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
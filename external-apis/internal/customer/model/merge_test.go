@@ -0,0 +1,117 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	survivor := &Customer{ID: "customer-456", Name: "John Doe", Email: "john.doe@example.com", Active: true, Status: StatusActive}
+	merged := &Customer{ID: "customer-001", Name: "Johnny Doe", Email: "johnny@example.com", Phone: "+15550123", Status: StatusInactive}
+
+	t.Run("Survivor keeps its fields and fills empty ones", func(t *testing.T) {
+		// Act
+		result := Merge(survivor, merged, nil)
+
+		// Assert
+		assert.Equal(t, "customer-456", result.ID)
+		assert.Equal(t, "John Doe", result.Name)
+		assert.Equal(t, "john.doe@example.com", result.Email)
+		assert.Equal(t, "+15550123", result.Phone)
+		assert.Equal(t, StatusActive, result.Status)
+		assert.Empty(t, survivor.Phone, "survivor is left unchanged")
+	})
+
+	t.Run("Taken fields come from the merged customer", func(t *testing.T) {
+		// Act
+		result := Merge(survivor, merged, []string{MergeFieldEmail, MergeFieldStatus})
+
+		// Assert
+		assert.Equal(t, "John Doe", result.Name)
+		assert.Equal(t, "johnny@example.com", result.Email)
+		assert.Equal(t, StatusInactive, result.Status)
+		assert.False(t, result.Active)
+	})
+}
+
+func TestCompareCustomers(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Customer
+		score   float64
+		matches []string
+	}{
+		{
+			name:    "Same email ignoring case and tags",
+			a:       Customer{Name: "John Doe", Email: "John.Doe@example.com"},
+			b:       Customer{Name: "Jane Roe", Email: "john.doe+shop@example.com"},
+			score:   0.95,
+			matches: []string{MergeFieldEmail},
+		},
+		{
+			name:    "Email on another domain",
+			a:       Customer{Email: "john.doe@example.com"},
+			b:       Customer{Email: "john.doe@example.org"},
+			score:   0,
+			matches: []string{},
+		},
+		{
+			name:    "Same phone in another format",
+			a:       Customer{Phone: "+1 (555) 012-3456"},
+			b:       Customer{Phone: "555.012.3456"},
+			score:   0.9,
+			matches: []string{MergeFieldPhone},
+		},
+		{
+			name:    "Name with words swapped and a phone",
+			a:       Customer{Name: "Doe, John", Phone: "+15550123456"},
+			b:       Customer{Name: "john doe", Phone: "5550123456"},
+			score:   0.98,
+			matches: []string{MergeFieldPhone, MergeFieldName},
+		},
+		{
+			name:    "Misspelled name alone",
+			a:       Customer{Name: "Jon Doe"},
+			b:       Customer{Name: "John Doe"},
+			score:   0.7,
+			matches: []string{MergeFieldName},
+		},
+		{
+			name:    "Different customers",
+			a:       Customer{Name: "John Doe", Email: "john.doe@example.com", Phone: "+15550123456"},
+			b:       Customer{Name: "Alice Brown", Email: "alice.brown@example.com", Phone: "+15559876543"},
+			score:   0,
+			matches: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			score, matches := CompareCustomers(&tt.a, &tt.b)
+
+			// Assert
+			assert.Equal(t, tt.score, score)
+			fields := make([]string, 0, len(matches))
+			for _, match := range matches {
+				fields = append(fields, match.Field)
+			}
+			assert.Equal(t, tt.matches, fields)
+		})
+	}
+}
+
+func TestSortDuplicates(t *testing.T) {
+	// Arrange
+	pair := func(a, b string, score float64) DuplicatePair {
+		return DuplicatePair{Customers: []CustomerResponse{{ID: a}, {ID: b}}, Score: score}
+	}
+	pairs := []DuplicatePair{pair("c", "d", 0.8), pair("a", "b", 0.95), pair("a", "c", 0.8)}
+
+	// Act
+	SortDuplicates(pairs)
+
+	// Assert
+	assert.Equal(t, []DuplicatePair{pair("a", "b", 0.95), pair("a", "c", 0.8), pair("c", "d", 0.8)}, pairs)
+}
@@ -0,0 +1,37 @@
+package model
+
+import "maps"
+
+// CustomerStats summarizes the customers that are not deleted
+type CustomerStats struct {
+	Total int `json:"total"`
+	// ByStatus counts the customers of every status, including those with none
+	ByStatus map[CustomerStatus]int `json:"byStatus"`
+}
+
+// NewCustomerStats summarizes customers, skipping soft-deleted ones
+func NewCustomerStats(customers []*Customer) *CustomerStats {
+	stats := &CustomerStats{
+		ByStatus: map[CustomerStatus]int{
+			StatusActive:   0,
+			StatusInactive: 0,
+			StatusBlocked:  0,
+			StatusPending:  0,
+		},
+	}
+	for _, customer := range customers {
+		if customer.IsDeleted() {
+			continue
+		}
+		stats.Total++
+		stats.ByStatus[customer.Status]++
+	}
+	return stats
+}
+
+// Clone returns a copy of the stats that shares nothing with them
+func (s *CustomerStats) Clone() *CustomerStats {
+	clone := *s
+	clone.ByStatus = maps.Clone(s.ByStatus)
+	return &clone
+}
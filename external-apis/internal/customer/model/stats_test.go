@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCustomerStats(t *testing.T) {
+	// Arrange
+	deletedAt := time.Now()
+	customers := []*Customer{
+		{ID: "customer-1", Status: StatusActive},
+		{ID: "customer-2", Status: StatusActive},
+		{ID: "customer-3", Status: StatusBlocked},
+		{ID: "customer-4", Status: StatusBlocked, DeletedAt: &deletedAt},
+	}
+
+	// Act
+	stats := NewCustomerStats(customers)
+
+	// Assert
+	assert.Equal(t, 3, stats.Total, "deleted customers are not counted")
+	assert.Equal(t, map[CustomerStatus]int{
+		StatusActive:   2,
+		StatusInactive: 0,
+		StatusBlocked:  1,
+		StatusPending:  0,
+	}, stats.ByStatus)
+}
+
+func TestCustomerStats_Clone(t *testing.T) {
+	// Arrange
+	stats := NewCustomerStats([]*Customer{{ID: "customer-1", Status: StatusActive}})
+
+	// Act
+	clone := stats.Clone()
+	clone.ByStatus[StatusActive] = 10
+
+	// Assert
+	assert.Equal(t, 1, stats.ByStatus[StatusActive])
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// Verification is the outstanding email verification of a pending customer.
+// A customer has at most one: sending a new token replaces the previous one.
+type Verification struct {
+	CustomerID string
+	// EmailHash is the hex SHA-256 of the address the token was sent to,
+	// kept instead of the address so verifications hold no PII. The token
+	// stops working once the customer's email changes.
+	EmailHash string
+	// TokenHash is the hex SHA-256 of the token; the token itself is only
+	// sent to the customer
+	TokenHash string
+	SentAt    time.Time
+	ExpiresAt time.Time
+}
+
+// IsExpired checks if the token can no longer be used at now
+func (v *Verification) IsExpired(now time.Time) bool {
+	return !now.Before(v.ExpiresAt)
+}
+
+// VerifyEmailRequest represents the request confirming a customer's email
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest represents the request for a new verification
+// token
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// AddressRepository defines the interface for customer address operations
+type AddressRepository interface {
+	GetByCustomerID(ctx context.Context, customerID string) ([]*model.Address, error)
+	GetByID(ctx context.Context, customerID, id string) (*model.Address, error)
+	Create(ctx context.Context, address *model.Address) (*model.Address, error)
+	Update(ctx context.Context, address *model.Address) (*model.Address, error)
+	Delete(ctx context.Context, customerID, id string) error
+	Reassign(ctx context.Context, fromCustomerID, toCustomerID string) (int, error)
+}
+
+// MemoryAddressRepository implements AddressRepository using in-memory storage.
+// Each customer has at most one default address per address type; the oldest
+// remaining address is promoted when the default one is removed.
+type MemoryAddressRepository struct {
+	addresses map[string]*model.Address
+	seed      map[string]model.Address
+	touched   map[string]time.Time
+	// ids generates the IDs of new addresses
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryAddressRepository creates a new in-memory address repository with sample data
+func NewMemoryAddressRepository() *MemoryAddressRepository {
+	repo := newEmptyMemoryAddressRepository()
+
+	// Initialize with sample data
+	repo.initSampleData()
+
+	return repo
+}
+
+// newEmptyMemoryAddressRepository creates an in-memory address repository without sample data
+func newEmptyMemoryAddressRepository() *MemoryAddressRepository {
+	return &MemoryAddressRepository{
+		addresses: make(map[string]*model.Address),
+		seed:      make(map[string]model.Address),
+		touched:   make(map[string]time.Time),
+		ids:       idgen.Default(),
+	}
+}
+
+// GetByCustomerID retrieves all addresses of a customer, oldest first
+func (r *MemoryAddressRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Address, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	addresses := make([]*model.Address, 0)
+	for _, address := range r.byCustomerUnsafe(customerID) {
+		copied := *address
+		addresses = append(addresses, &copied)
+	}
+
+	return addresses, nil
+}
+
+// GetByID retrieves an address belonging to a customer
+func (r *MemoryAddressRepository) GetByID(ctx context.Context, customerID, id string) (*model.Address, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	address, exists := r.addresses[id]
+	if !exists || address.CustomerID != customerID {
+		return nil, model.ErrAddressNotFound
+	}
+
+	copied := *address
+	return &copied, nil
+}
+
+// Create adds an address to a customer's address book
+func (r *MemoryAddressRepository) Create(ctx context.Context, address *model.Address) (*model.Address, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if address.ID == "" {
+		address.ID = r.ids.New()
+	}
+
+	if _, exists := r.addresses[address.ID]; exists {
+		return nil, model.ErrAddressExists
+	}
+
+	if address.CreatedAt.IsZero() {
+		address.CreatedAt = time.Now().UTC()
+	}
+
+	stored := *address
+	r.addresses[stored.ID] = &stored
+	r.touched[stored.ID] = time.Now()
+	r.applyDefaultUnsafe(&stored)
+
+	copied := stored
+	return &copied, nil
+}
+
+// Update replaces an existing address
+func (r *MemoryAddressRepository) Update(ctx context.Context, address *model.Address) (*model.Address, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.addresses[address.ID]
+	if !exists || existing.CustomerID != address.CustomerID {
+		return nil, model.ErrAddressNotFound
+	}
+
+	previousType := existing.Type
+	stored := *address
+	stored.CreatedAt = existing.CreatedAt
+	r.addresses[stored.ID] = &stored
+	r.touched[stored.ID] = time.Now()
+	r.applyDefaultUnsafe(&stored)
+
+	if previousType != stored.Type {
+		r.ensureDefaultUnsafe(stored.CustomerID, previousType)
+	}
+
+	copied := *r.addresses[stored.ID]
+	return &copied, nil
+}
+
+// Delete removes an address from a customer's address book
+func (r *MemoryAddressRepository) Delete(ctx context.Context, customerID, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	address, exists := r.addresses[id]
+	if !exists || address.CustomerID != customerID {
+		return model.ErrAddressNotFound
+	}
+
+	delete(r.addresses, id)
+	r.touched[id] = time.Now()
+	r.ensureDefaultUnsafe(customerID, address.Type)
+	return nil
+}
+
+// Reassign moves every address of one customer to another and returns how
+// many were moved. The defaults of the receiving customer stay its defaults;
+// a moved default only remains one for types the customer had none of.
+func (r *MemoryAddressRepository) Reassign(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	hasDefault := make(map[model.AddressType]bool)
+	for _, address := range r.byCustomerUnsafe(toCustomerID) {
+		if address.IsDefault {
+			hasDefault[address.Type] = true
+		}
+	}
+
+	moved := r.byCustomerUnsafe(fromCustomerID)
+	for _, address := range moved {
+		address.CustomerID = toCustomerID
+		if address.IsDefault && hasDefault[address.Type] {
+			address.IsDefault = false
+		}
+		r.touched[address.ID] = time.Now()
+	}
+
+	return len(moved), nil
+}
+
+// PurgeExpired reverts addresses written before cutoff to their seed state,
+// removing addresses that were not part of the seed data
+func (r *MemoryAddressRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if !writtenAt.Before(cutoff) {
+			continue
+		}
+
+		if seeded, exists := r.seed[id]; exists {
+			address := seeded
+			r.addresses[id] = &address
+		} else {
+			delete(r.addresses, id)
+		}
+
+		delete(r.touched, id)
+		purged++
+	}
+
+	if purged > 0 {
+		r.ensureAllDefaultsUnsafe()
+	}
+
+	return purged
+}
+
+// Reset restores the repository to its seed data
+func (r *MemoryAddressRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.addresses = make(map[string]*model.Address, len(r.seed))
+	for id, seeded := range r.seed {
+		address := seeded
+		r.addresses[id] = &address
+	}
+	r.touched = make(map[string]time.Time)
+}
+
+// applyDefaultUnsafe makes address the only default of its type when it is
+// flagged as default, or when the customer has no other default of that type
+func (r *MemoryAddressRepository) applyDefaultUnsafe(address *model.Address) {
+	if !address.IsDefault {
+		r.ensureDefaultUnsafe(address.CustomerID, address.Type)
+		return
+	}
+
+	for _, other := range r.byCustomerUnsafe(address.CustomerID) {
+		if other.ID != address.ID && other.Type == address.Type && other.IsDefault {
+			other.IsDefault = false
+			r.touched[other.ID] = time.Now()
+		}
+	}
+}
+
+// ensureDefaultUnsafe promotes the oldest address of a type to default when
+// the customer has addresses of that type but none is the default
+func (r *MemoryAddressRepository) ensureDefaultUnsafe(customerID string, addressType model.AddressType) {
+	var oldest *model.Address
+	for _, address := range r.byCustomerUnsafe(customerID) {
+		if address.Type != addressType {
+			continue
+		}
+		if address.IsDefault {
+			return
+		}
+		if oldest == nil {
+			oldest = address
+		}
+	}
+
+	if oldest != nil {
+		oldest.IsDefault = true
+		r.touched[oldest.ID] = time.Now()
+	}
+}
+
+// ensureAllDefaultsUnsafe restores the default invariant for every customer
+func (r *MemoryAddressRepository) ensureAllDefaultsUnsafe() {
+	type key struct {
+		customerID  string
+		addressType model.AddressType
+	}
+
+	defaults := make(map[key]int)
+	for _, address := range r.addresses {
+		k := key{address.CustomerID, address.Type}
+		if address.IsDefault {
+			defaults[k]++
+		} else if _, seen := defaults[k]; !seen {
+			defaults[k] = 0
+		}
+	}
+
+	for k, count := range defaults {
+		if count > 1 {
+			// Keep the oldest default and clear the rest
+			kept := false
+			for _, address := range r.byCustomerUnsafe(k.customerID) {
+				if address.Type == k.addressType && address.IsDefault {
+					address.IsDefault = !kept
+					kept = true
+				}
+			}
+		}
+		r.ensureDefaultUnsafe(k.customerID, k.addressType)
+	}
+}
+
+// byCustomerUnsafe returns a customer's stored addresses ordered by creation
+// time (without locking)
+func (r *MemoryAddressRepository) byCustomerUnsafe(customerID string) []*model.Address {
+	addresses := make([]*model.Address, 0)
+	for _, address := range r.addresses {
+		if address.CustomerID == customerID {
+			addresses = append(addresses, address)
+		}
+	}
+
+	sort.Slice(addresses, func(i, j int) bool {
+		if !addresses[i].CreatedAt.Equal(addresses[j].CreatedAt) {
+			return addresses[i].CreatedAt.Before(addresses[j].CreatedAt)
+		}
+		return addresses[i].ID < addresses[j].ID
+	})
+
+	return addresses
+}
+
+// initSampleData initializes the repository with sample data
+func (r *MemoryAddressRepository) initSampleData() {
+	createdAt := time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC)
+	sampleAddresses := []*model.Address{
+		{
+			ID:         "address-001",
+			CustomerID: "customer-456",
+			Type:       model.AddressTypeShipping,
+			Line1:      "123 Main Street",
+			Line2:      "Apt 4B",
+			City:       "Springfield",
+			Region:     "IL",
+			PostalCode: "62701",
+			Country:    "US",
+			IsDefault:  true,
+			CreatedAt:  createdAt,
+		},
+		{
+			ID:         "address-002",
+			CustomerID: "customer-456",
+			Type:       model.AddressTypeBilling,
+			Line1:      "500 Market Street",
+			City:       "Springfield",
+			Region:     "IL",
+			PostalCode: "62702",
+			Country:    "US",
+			IsDefault:  true,
+			CreatedAt:  createdAt.Add(time.Minute),
+		},
+	}
+
+	for _, address := range sampleAddresses {
+		r.addresses[address.ID] = address
+		r.seed[address.ID] = *address
+	}
+}
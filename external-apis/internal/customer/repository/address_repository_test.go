@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultIDs returns the IDs of a customer's default addresses by type
+func defaultIDs(t *testing.T, repo *MemoryAddressRepository, customerID string) map[model.AddressType]string {
+	t.Helper()
+
+	addresses, err := repo.GetByCustomerID(context.Background(), customerID)
+	require.NoError(t, err)
+
+	defaults := make(map[model.AddressType]string)
+	for _, address := range addresses {
+		if address.IsDefault {
+			_, duplicate := defaults[address.Type]
+			require.False(t, duplicate, "more than one default %s address", address.Type)
+			defaults[address.Type] = address.ID
+		}
+	}
+	return defaults
+}
+
+func TestMemoryAddressRepository_GetByCustomerID(t *testing.T) {
+	// Arrange
+	repo := NewMemoryAddressRepository()
+
+	// Act
+	addresses, err := repo.GetByCustomerID(context.Background(), "customer-456")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, addresses, 2)
+	assert.Equal(t, "address-001", addresses[0].ID)
+	assert.Equal(t, "address-002", addresses[1].ID)
+
+	t.Run("Customer without addresses", func(t *testing.T) {
+		// Act
+		addresses, err := repo.GetByCustomerID(context.Background(), "customer-001")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, addresses)
+	})
+
+	t.Run("Address of another customer", func(t *testing.T) {
+		// Act
+		address, err := repo.GetByID(context.Background(), "customer-001", "address-001")
+
+		// Assert
+		assert.Nil(t, address)
+		assert.EqualError(t, err, "address not found")
+	})
+}
+
+func TestMemoryAddressRepository_Defaults(t *testing.T) {
+	// Arrange
+	repo := NewMemoryAddressRepository()
+
+	t.Run("First address of a type becomes default", func(t *testing.T) {
+		// Act
+		created, err := repo.Create(context.Background(), &model.Address{CustomerID: "customer-001", Type: model.AddressTypeShipping})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, created.IsDefault)
+	})
+
+	t.Run("New default replaces the previous one", func(t *testing.T) {
+		// Act
+		created, err := repo.Create(context.Background(), &model.Address{
+			CustomerID: "customer-456",
+			Type:       model.AddressTypeShipping,
+			IsDefault:  true,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		defaults := defaultIDs(t, repo, "customer-456")
+		assert.Equal(t, created.ID, defaults[model.AddressTypeShipping])
+		assert.Equal(t, "address-002", defaults[model.AddressTypeBilling])
+	})
+
+	t.Run("Deleting the default promotes the oldest", func(t *testing.T) {
+		// Arrange
+		defaults := defaultIDs(t, repo, "customer-456")
+
+		// Act
+		err := repo.Delete(context.Background(), "customer-456", defaults[model.AddressTypeShipping])
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "address-001", defaultIDs(t, repo, "customer-456")[model.AddressTypeShipping])
+	})
+
+	t.Run("Changing type keeps a default for both types", func(t *testing.T) {
+		// Arrange
+		address, err := repo.GetByID(context.Background(), "customer-456", "address-002")
+		require.NoError(t, err)
+		address.Type = model.AddressTypeShipping
+		address.IsDefault = false
+
+		// Act
+		updated, err := repo.Update(context.Background(), address)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, updated.IsDefault)
+		defaults := defaultIDs(t, repo, "customer-456")
+		assert.Equal(t, "address-001", defaults[model.AddressTypeShipping])
+		assert.Empty(t, defaults[model.AddressTypeBilling])
+	})
+}
+
+func TestMemoryAddressRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryAddressRepository()
+	_, err := repo.Create(context.Background(), &model.Address{
+		CustomerID: "customer-456",
+		Type:       model.AddressTypeShipping,
+		IsDefault:  true,
+	})
+	require.NoError(t, err)
+
+	// Act
+	purged := repo.PurgeExpired(time.Now().Add(time.Second))
+
+	// Assert
+	assert.Equal(t, 2, purged) // the new address and the seed default it replaced
+	addresses, err := repo.GetByCustomerID(context.Background(), "customer-456")
+	require.NoError(t, err)
+	assert.Len(t, addresses, 2)
+	assert.Equal(t, "address-001", defaultIDs(t, repo, "customer-456")[model.AddressTypeShipping])
+}
+
+func TestMemoryAddressRepository_Reassign(t *testing.T) {
+	// Arrange
+	repo := NewMemoryAddressRepository()
+	ctx := context.Background()
+	own, err := repo.Create(ctx, &model.Address{CustomerID: "customer-001", Type: model.AddressTypeShipping})
+	require.NoError(t, err)
+
+	// Act
+	moved, err := repo.Reassign(ctx, "customer-456", "customer-001")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	remaining, _ := repo.GetByCustomerID(ctx, "customer-456")
+	assert.Empty(t, remaining)
+	defaults := defaultIDs(t, repo, "customer-001")
+	assert.Equal(t, own.ID, defaults[model.AddressTypeShipping], "the receiving customer's default wins")
+	assert.Equal(t, "address-002", defaults[model.AddressTypeBilling])
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"math"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/crypto"
+	"external-apis/internal/shared/pagination"
+)
+
+// EncryptedCustomerRepository encrypts the email and phone of customers
+// before they reach the wrapped repository and decrypts them on the way
+// back, so callers only ever see plaintext. Emails are looked up through
+// their blind index. Customers stored before encryption was enabled stay
+// readable and are encrypted by Rotate or their next update.
+type EncryptedCustomerRepository struct {
+	inner   CustomerRepository
+	keyring *crypto.Keyring
+}
+
+// NewEncryptedCustomerRepository wraps inner so that customer PII is stored
+// encrypted with keyring
+func NewEncryptedCustomerRepository(inner CustomerRepository, keyring *crypto.Keyring) *EncryptedCustomerRepository {
+	return &EncryptedCustomerRepository{
+		inner:   inner,
+		keyring: keyring,
+	}
+}
+
+// GetByID retrieves a customer by ID
+func (r *EncryptedCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
+	customer, err := r.inner.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(customer)
+}
+
+// GetAll retrieves all customers that have not been deleted
+func (r *EncryptedCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
+	customers, err := r.inner.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.openAll(customers)
+}
+
+// GetByIDs retrieves the customers with the given IDs that exist and have
+// not been deleted
+func (r *EncryptedCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	customers, err := r.inner.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return r.openAll(customers)
+}
+
+// Find retrieves a page of customers matching the filter along with the
+// total number of matches. The wrapped repository cannot match encrypted
+// fields, so filters on the email domain or phone prefix are applied here
+// to every customer matching the remaining criteria.
+func (r *EncryptedCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	if filter.EmailDomain == "" && filter.PhonePrefix == "" {
+		customers, total, err := r.inner.Find(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		opened, err := r.openAll(customers)
+		return opened, total, err
+	}
+
+	candidates := filter
+	candidates.EmailDomain = ""
+	candidates.PhonePrefix = ""
+	candidates.Page = pagination.Params{Limit: math.MaxInt}
+
+	customers, _, err := r.inner.Find(ctx, candidates)
+	if err != nil {
+		return nil, 0, err
+	}
+	opened, err := r.openAll(customers)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]*model.Customer, 0, len(opened))
+	for _, customer := range opened {
+		if filter.Matches(customer) {
+			matches = append(matches, customer)
+		}
+	}
+
+	start, end := filter.Page.Bounds(len(matches))
+	return matches[start:end], len(matches), nil
+}
+
+// Create encrypts and stores a new customer
+func (r *EncryptedCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
+	if r.emailTakenByPlaintext(ctx, customer.Email, customer.ID) {
+		return nil, model.ErrEmailTaken
+	}
+
+	sealed, err := r.seal(customer)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := r.inner.Create(ctx, sealed)
+	if err != nil {
+		return nil, err
+	}
+	customer.ID = created.ID
+	return r.open(created)
+}
+
+// Update encrypts and stores an existing customer
+func (r *EncryptedCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
+	if r.emailTakenByPlaintext(ctx, customer.Email, id) {
+		return nil, model.ErrEmailTaken
+	}
+
+	sealed, err := r.seal(customer)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := r.inner.Update(ctx, id, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(updated)
+}
+
+// Delete soft-deletes a customer by ID
+func (r *EncryptedCustomerRepository) Delete(ctx context.Context, id string) error {
+	return r.inner.Delete(ctx, id)
+}
+
+// Restore undoes the soft delete of a customer
+func (r *EncryptedCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	customer, err := r.inner.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.open(customer)
+}
+
+// ExistsByID checks if a customer exists by ID
+func (r *EncryptedCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.inner.ExistsByID(ctx, id)
+}
+
+// GetByEmail retrieves a customer by email through its blind index, falling
+// back to the plaintext email for customers stored before encryption
+func (r *EncryptedCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
+	customer, err := r.inner.GetByEmail(ctx, r.keyring.BlindIndex(email))
+	if err != nil {
+		customer, err = r.inner.GetByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.open(customer)
+}
+
+// AddAlias makes the ID of a merged customer refer to the survivor
+func (r *EncryptedCustomerRepository) AddAlias(ctx context.Context, alias, customerID string) error {
+	return r.inner.AddAlias(ctx, alias, customerID)
+}
+
+// ResolveAlias returns the customer a merged customer's ID refers to
+func (r *EncryptedCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	return r.inner.ResolveAlias(ctx, id)
+}
+
+// Stats summarizes the customers of the wrapped repository. Statuses are not
+// encrypted, so nothing needs decrypting.
+func (r *EncryptedCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	return r.inner.Stats(ctx)
+}
+
+// Transaction runs fn against a transaction of the wrapped repository that
+// encrypts the same way
+func (r *EncryptedCustomerRepository) Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error {
+	return r.inner.Transaction(ctx, func(tx CustomerRepository) error {
+		return fn(NewEncryptedCustomerRepository(tx, r.keyring))
+	})
+}
+
+// Rotate re-encrypts with the primary key every customer stored in
+// plaintext or under an older key and returns how many were rewritten.
+// Soft-deleted customers are re-encrypted once they are restored and updated.
+func (r *EncryptedCustomerRepository) Rotate(ctx context.Context) (int, error) {
+	customers, err := r.inner.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, customer := range customers {
+		if !r.keyring.NeedsRotation(customer.Email) && !r.keyring.NeedsRotation(customer.Phone) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return rotated, err
+		}
+
+		opened, err := r.open(customer)
+		if err != nil {
+			return rotated, err
+		}
+		if _, err := r.Update(ctx, customer.ID, opened); err != nil {
+			return rotated, err
+		}
+		rotated++
+	}
+
+	return rotated, nil
+}
+
+// emailTakenByPlaintext checks if a customer other than id holds email in
+// plaintext. The wrapped repository only detects duplicates among encrypted
+// customers, since it compares blind indexes.
+func (r *EncryptedCustomerRepository) emailTakenByPlaintext(ctx context.Context, email, id string) bool {
+	existing, err := r.inner.GetByEmail(ctx, email)
+	return err == nil && existing.ID != id
+}
+
+// seal returns a copy of customer with its PII encrypted
+func (r *EncryptedCustomerRepository) seal(customer *model.Customer) (*model.Customer, error) {
+	sealed := *customer
+
+	email, err := r.keyring.Encrypt(customer.Email)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := r.keyring.Encrypt(customer.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed.Email = email
+	sealed.Phone = phone
+	sealed.EmailIndex = r.keyring.BlindIndex(customer.Email)
+	return &sealed, nil
+}
+
+// open returns a copy of customer with its PII decrypted
+func (r *EncryptedCustomerRepository) open(customer *model.Customer) (*model.Customer, error) {
+	opened := *customer
+
+	email, err := r.keyring.Decrypt(customer.Email)
+	if err != nil {
+		return nil, err
+	}
+	phone, err := r.keyring.Decrypt(customer.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	opened.Email = email
+	opened.Phone = phone
+	opened.EmailIndex = ""
+	return &opened, nil
+}
+
+// openAll decrypts the PII of every customer
+func (r *EncryptedCustomerRepository) openAll(customers []*model.Customer) ([]*model.Customer, error) {
+	opened := make([]*model.Customer, len(customers))
+	for i, customer := range customers {
+		decrypted, err := r.open(customer)
+		if err != nil {
+			return nil, err
+		}
+		opened[i] = decrypted
+	}
+	return opened, nil
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/crypto"
+	"external-apis/internal/shared/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, ids ...string) *crypto.Keyring {
+	t.Helper()
+	keys := make([]crypto.Key, len(ids))
+	for i, id := range ids {
+		keys[i] = crypto.Key{ID: id, Secret: bytes.Repeat([]byte(id[len(id)-1:]), crypto.KeySize)}
+	}
+	keyring, err := crypto.NewKeyring(keys, bytes.Repeat([]byte{0xff}, crypto.KeySize))
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestEncryptedCustomerRepository_Create(t *testing.T) {
+	// Arrange
+	store := NewMemoryCustomerRepository()
+	repo := NewEncryptedCustomerRepository(store, newTestKeyring(t, "k1"))
+
+	// Act
+	created, err := repo.Create(context.Background(), &model.Customer{
+		ID:    "customer-new",
+		Name:  "New Customer",
+		Email: "new@example.com",
+		Phone: "+15550199",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", created.Email)
+	assert.Equal(t, "+15550199", created.Phone)
+
+	stored, err := store.GetByID(context.Background(), "customer-new")
+	require.NoError(t, err)
+	assert.True(t, crypto.IsEncrypted(stored.Email))
+	assert.True(t, crypto.IsEncrypted(stored.Phone))
+	assert.NotEmpty(t, stored.EmailIndex)
+	assert.Equal(t, "New Customer", stored.Name)
+
+	found, err := repo.GetByEmail(context.Background(), "new@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "customer-new", found.ID)
+	assert.Equal(t, "+15550199", found.Phone)
+}
+
+func TestEncryptedCustomerRepository_DuplicateEmail(t *testing.T) {
+	// Arrange
+	repo := NewEncryptedCustomerRepository(NewMemoryCustomerRepository(), newTestKeyring(t, "k1"))
+	_, err := repo.Create(context.Background(), &model.Customer{ID: "customer-new", Email: "new@example.com"})
+	require.NoError(t, err)
+
+	t.Run("Email of an encrypted customer", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Customer{ID: "customer-other", Email: "new@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrEmailTaken)
+	})
+
+	t.Run("Email of a customer stored in plaintext", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Customer{ID: "customer-other", Email: "john.doe@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrEmailTaken)
+	})
+
+	t.Run("Customer keeps its own email on update", func(t *testing.T) {
+		// Arrange
+		customer, err := repo.GetByID(context.Background(), "customer-456")
+		require.NoError(t, err)
+		customer.Name = "John Updated"
+
+		// Act
+		updated, err := repo.Update(context.Background(), customer.ID, customer)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "john.doe@example.com", updated.Email)
+	})
+}
+
+func TestEncryptedCustomerRepository_Find(t *testing.T) {
+	// Arrange
+	repo := NewEncryptedCustomerRepository(NewMemoryCustomerRepository(), newTestKeyring(t, "k1"))
+	_, err := repo.Rotate(context.Background())
+	require.NoError(t, err)
+	_, err = repo.Create(context.Background(), &model.Customer{ID: "customer-uk", Email: "uk@example.co.uk", Phone: "+44 20 7946 0000"})
+	require.NoError(t, err)
+
+	t.Run("Filter by email domain", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{
+			EmailDomain: "example.co.uk",
+			Page:        pagination.Params{Limit: 10},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, customers, 1)
+		assert.Equal(t, "uk@example.co.uk", customers[0].Email)
+	})
+
+	t.Run("Filter by phone prefix is paginated after decryption", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{
+			PhonePrefix: "+1555",
+			Page:        pagination.Params{Limit: 2},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 8, total)
+		require.Len(t, customers, 2)
+		assert.Equal(t, "+1-555-0124", customers[0].Phone)
+	})
+}
+
+func TestEncryptedCustomerRepository_Rotate(t *testing.T) {
+	// Arrange
+	store := NewMemoryCustomerRepository()
+	ctx := context.Background()
+
+	t.Run("Encrypt customers stored in plaintext", func(t *testing.T) {
+		// Arrange
+		repo := NewEncryptedCustomerRepository(store, newTestKeyring(t, "k1"))
+
+		// Act
+		rotated, err := repo.Rotate(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 8, rotated)
+		stored, err := store.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+		assert.Contains(t, stored.Email, "enc:v1:k1:")
+	})
+
+	t.Run("Re-encrypt with a new primary key", func(t *testing.T) {
+		// Arrange
+		repo := NewEncryptedCustomerRepository(store, newTestKeyring(t, "k2", "k1"))
+
+		// Act
+		rotated, err := repo.Rotate(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 8, rotated)
+		stored, err := store.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+		assert.Contains(t, stored.Email, "enc:v1:k2:")
+
+		again, err := repo.Rotate(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, again)
+	})
+
+	t.Run("Old key can be retired after rotation", func(t *testing.T) {
+		// Arrange
+		repo := NewEncryptedCustomerRepository(store, newTestKeyring(t, "k2"))
+
+		// Act
+		customer, err := repo.GetByEmail(ctx, "john.doe@example.com")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "+1-555-0123", customer.Phone)
+	})
+}
+
+func TestEncryptedCustomerRepository_Transaction(t *testing.T) {
+	// Arrange
+	store := NewMemoryCustomerRepository()
+	repo := NewEncryptedCustomerRepository(store, newTestKeyring(t, "k1"))
+
+	// Act
+	err := repo.Transaction(context.Background(), func(tx CustomerRepository) error {
+		_, err := tx.Create(context.Background(), &model.Customer{ID: "customer-tx", Email: "tx@example.com", Phone: "+15550100"})
+		return err
+	})
+
+	// Assert
+	require.NoError(t, err)
+	stored, err := store.GetByID(context.Background(), "customer-tx")
+	require.NoError(t, err)
+	assert.True(t, crypto.IsEncrypted(stored.Email))
+
+	customer, err := repo.GetByEmail(context.Background(), "tx@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "+15550100", customer.Phone)
+}
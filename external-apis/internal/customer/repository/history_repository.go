@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/pagination"
+	"github.com/google/uuid"
+)
+
+// HistoryRepository defines the interface for the customer audit history.
+// Entries are append-only.
+type HistoryRepository interface {
+	Append(ctx context.Context, entry *model.HistoryEntry) error
+	FindByCustomerID(ctx context.Context, customerID string, page pagination.Params) ([]*model.HistoryEntry, int, error)
+}
+
+// MemoryHistoryRepository implements HistoryRepository using in-memory
+// storage. Entries of a customer are kept in the order they were appended.
+type MemoryHistoryRepository struct {
+	entries map[string][]*model.HistoryEntry
+	mutex   sync.RWMutex
+}
+
+// NewMemoryHistoryRepository creates a new in-memory history repository
+func NewMemoryHistoryRepository() *MemoryHistoryRepository {
+	return &MemoryHistoryRepository{
+		entries: make(map[string][]*model.HistoryEntry),
+	}
+}
+
+// Append records a history entry
+func (r *MemoryHistoryRepository) Append(ctx context.Context, entry *model.HistoryEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+
+	stored := *entry
+	r.entries[stored.CustomerID] = append(r.entries[stored.CustomerID], &stored)
+	return nil
+}
+
+// FindByCustomerID retrieves a page of a customer's history, oldest first,
+// along with the total number of entries
+func (r *MemoryHistoryRepository) FindByCustomerID(ctx context.Context, customerID string, page pagination.Params) ([]*model.HistoryEntry, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	entries := r.entries[customerID]
+	start, end := page.Bounds(len(entries))
+
+	result := make([]*model.HistoryEntry, 0, end-start)
+	for _, entry := range entries[start:end] {
+		copied := *entry
+		result = append(result, &copied)
+	}
+
+	return result, len(entries), nil
+}
+
+// PurgeExpired removes the entries recorded before cutoff. There is no seed
+// history, so every entry stems from a write.
+func (r *MemoryHistoryRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for customerID, entries := range r.entries {
+		kept := make([]*model.HistoryEntry, 0, len(entries))
+		for _, entry := range entries {
+			if entry.Timestamp.Before(cutoff) {
+				purged++
+				continue
+			}
+			kept = append(kept, entry)
+		}
+
+		if len(kept) == 0 {
+			delete(r.entries, customerID)
+		} else {
+			r.entries[customerID] = kept
+		}
+	}
+
+	return purged
+}
+
+// Reset removes every entry
+func (r *MemoryHistoryRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.entries = make(map[string][]*model.HistoryEntry)
+}
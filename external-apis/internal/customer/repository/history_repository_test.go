@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryHistoryRepository_FindByCustomerID(t *testing.T) {
+	// Arrange
+	repo := NewMemoryHistoryRepository()
+	ctx := context.Background()
+	for _, action := range []model.HistoryAction{model.HistoryCreated, model.HistoryUpdated, model.HistoryDeleted} {
+		require.NoError(t, repo.Append(ctx, &model.HistoryEntry{CustomerID: "customer-123", Action: action}))
+	}
+	require.NoError(t, repo.Append(ctx, &model.HistoryEntry{CustomerID: "customer-456", Action: model.HistoryCreated}))
+
+	t.Run("Return a page oldest first", func(t *testing.T) {
+		// Act
+		entries, total, err := repo.FindByCustomerID(ctx, "customer-123", pagination.Params{Limit: 2, Offset: 1})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		require.Len(t, entries, 2)
+		assert.Equal(t, model.HistoryUpdated, entries[0].Action)
+		assert.Equal(t, model.HistoryDeleted, entries[1].Action)
+		assert.NotEmpty(t, entries[0].ID)
+		assert.False(t, entries[0].Timestamp.IsZero())
+	})
+
+	t.Run("Unknown customer has no history", func(t *testing.T) {
+		// Act
+		entries, total, err := repo.FindByCustomerID(ctx, "customer-999", pagination.Params{Limit: 50})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestMemoryHistoryRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryHistoryRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	require.NoError(t, repo.Append(ctx, &model.HistoryEntry{CustomerID: "customer-123", Timestamp: now.Add(-2 * time.Hour)}))
+	require.NoError(t, repo.Append(ctx, &model.HistoryEntry{CustomerID: "customer-123", Timestamp: now}))
+	require.NoError(t, repo.Append(ctx, &model.HistoryEntry{CustomerID: "customer-456", Timestamp: now.Add(-2 * time.Hour)}))
+
+	// Act
+	purged := repo.PurgeExpired(now.Add(-time.Hour))
+
+	// Assert
+	assert.Equal(t, 2, purged)
+	_, total, err := repo.FindByCustomerID(ctx, "customer-123", pagination.Params{Limit: 50})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	repo.Reset()
+	_, total, err = repo.FindByCustomerID(ctx, "customer-123", pagination.Params{Limit: 50})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/metrics"
+)
+
+// instrumentedName labels the metrics of the customer repository
+const instrumentedName = "customers"
+
+// InstrumentedCustomerRepository records the latency and outcome of every
+// operation of the wrapped repository in repository metrics
+type InstrumentedCustomerRepository struct {
+	inner   CustomerRepository
+	metrics *metrics.RepositoryMetrics
+}
+
+// NewInstrumentedCustomerRepository wraps inner so its operations are
+// recorded in m
+func NewInstrumentedCustomerRepository(inner CustomerRepository, m *metrics.RepositoryMetrics) *InstrumentedCustomerRepository {
+	return &InstrumentedCustomerRepository{
+		inner:   inner,
+		metrics: m,
+	}
+}
+
+// GetByID retrieves a customer by ID
+func (r *InstrumentedCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "GetByID")
+	customer, err := r.inner.GetByID(ctx, id)
+	done(err)
+	return customer, err
+}
+
+// GetAll retrieves all customers that have not been deleted
+func (r *InstrumentedCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "GetAll")
+	customers, err := r.inner.GetAll(ctx)
+	done(err)
+	return customers, err
+}
+
+// GetByIDs retrieves the customers with the given IDs that exist and have
+// not been deleted
+func (r *InstrumentedCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "GetByIDs")
+	customers, err := r.inner.GetByIDs(ctx, ids)
+	done(err)
+	return customers, err
+}
+
+// Find retrieves a page of customers matching the filter along with the
+// total number of matches
+func (r *InstrumentedCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	done := r.metrics.Started(instrumentedName, "Find")
+	customers, total, err := r.inner.Find(ctx, filter)
+	done(err)
+	return customers, total, err
+}
+
+// Create stores a new customer
+func (r *InstrumentedCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "Create")
+	created, err := r.inner.Create(ctx, customer)
+	done(err)
+	return created, err
+}
+
+// Update replaces an existing customer
+func (r *InstrumentedCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "Update")
+	updated, err := r.inner.Update(ctx, id, customer)
+	done(err)
+	return updated, err
+}
+
+// Delete soft-deletes a customer
+func (r *InstrumentedCustomerRepository) Delete(ctx context.Context, id string) error {
+	done := r.metrics.Started(instrumentedName, "Delete")
+	err := r.inner.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+// Restore brings back a soft-deleted customer
+func (r *InstrumentedCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "Restore")
+	restored, err := r.inner.Restore(ctx, id)
+	done(err)
+	return restored, err
+}
+
+// ExistsByID reports whether a customer that has not been deleted exists
+func (r *InstrumentedCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
+	done := r.metrics.Started(instrumentedName, "ExistsByID")
+	exists := r.inner.ExistsByID(ctx, id)
+	done(nil)
+	return exists
+}
+
+// GetByEmail retrieves the customer with an email address
+func (r *InstrumentedCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
+	done := r.metrics.Started(instrumentedName, "GetByEmail")
+	customer, err := r.inner.GetByEmail(ctx, email)
+	done(err)
+	return customer, err
+}
+
+// AddAlias makes alias resolve to the customer with customerID
+func (r *InstrumentedCustomerRepository) AddAlias(ctx context.Context, alias, customerID string) error {
+	done := r.metrics.Started(instrumentedName, "AddAlias")
+	err := r.inner.AddAlias(ctx, alias, customerID)
+	done(err)
+	return err
+}
+
+// ResolveAlias returns the customer an ID was merged into
+func (r *InstrumentedCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	done := r.metrics.Started(instrumentedName, "ResolveAlias")
+	target, ok := r.inner.ResolveAlias(ctx, id)
+	done(nil)
+	return target, ok
+}
+
+// Stats summarizes the stored customers
+func (r *InstrumentedCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	done := r.metrics.Started(instrumentedName, "Stats")
+	stats, err := r.inner.Stats(ctx)
+	done(err)
+	return stats, err
+}
+
+// Transaction runs fn against a transaction of the wrapped repository whose
+// operations are recorded too. The transaction as a whole is recorded as
+// well, so its latency includes the wait for the write lock.
+func (r *InstrumentedCustomerRepository) Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error {
+	done := r.metrics.Started(instrumentedName, "Transaction")
+	err := r.inner.Transaction(ctx, func(tx CustomerRepository) error {
+		return fn(NewInstrumentedCustomerRepository(tx, r.metrics))
+	})
+	done(err)
+	return err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedCustomerRepository(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	repo := NewInstrumentedCustomerRepository(NewMemoryCustomerRepository(), metrics.NewRepositoryMetrics(registry, "test-service"))
+
+	// Act
+	_, err := repo.GetByID(ctx, "customer-001")
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, "customer-missing")
+	require.ErrorIs(t, err, model.ErrCustomerNotFound)
+	err = repo.Transaction(ctx, func(tx CustomerRepository) error {
+		_, err := tx.Create(ctx, &model.Customer{ID: "customer-new", Name: "New Customer", Email: "new@example.com"})
+		return err
+	})
+	require.NoError(t, err)
+
+	// Assert
+	expected := `
+# HELP repository_operations_total Total number of repository operations, by outcome.
+# TYPE repository_operations_total counter
+repository_operations_total{operation="Create",outcome="ok",repository="customers",service="test-service"} 1
+repository_operations_total{operation="GetByID",outcome="ok",repository="customers",service="test-service"} 1
+repository_operations_total{operation="GetByID",outcome="rejected",repository="customers",service="test-service"} 1
+repository_operations_total{operation="Transaction",outcome="ok",repository="customers",service="test-service"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "repository_operations_total"))
+}
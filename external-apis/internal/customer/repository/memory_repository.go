@@ -1,35 +1,77 @@
 package repository
 
 import (
-	"errors"
+	"context"
+	"maps"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"external-apis/internal/customer/model"
-	"github.com/google/uuid"
+	"external-apis/internal/shared/clock"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/store"
 )
 
 // CustomerRepository defines the interface for customer operations
 type CustomerRepository interface {
-	GetByID(id string) (*model.Customer, error)
-	GetAll() ([]*model.Customer, error)
-	Create(customer *model.Customer) (*model.Customer, error)
-	Update(id string, customer *model.Customer) (*model.Customer, error)
-	Delete(id string) error
-	ExistsByID(id string) bool
-	GetByEmail(email string) (*model.Customer, error)
+	GetByID(ctx context.Context, id string) (*model.Customer, error)
+	GetAll(ctx context.Context) ([]*model.Customer, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error)
+	Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error)
+	Create(ctx context.Context, customer *model.Customer) (*model.Customer, error)
+	Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*model.Customer, error)
+	ExistsByID(ctx context.Context, id string) bool
+	GetByEmail(ctx context.Context, email string) (*model.Customer, error)
+	AddAlias(ctx context.Context, alias, customerID string) error
+	ResolveAlias(ctx context.Context, id string) (string, bool)
+	Stats(ctx context.Context) (*model.CustomerStats, error)
+	Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error
 }
 
-// MemoryCustomerRepository implements CustomerRepository using in-memory storage
+// MemoryCustomerRepository implements CustomerRepository using in-memory
+// storage. It stores copies of the customers it is given and returns copies
+// of the stored ones, so callers never share a customer with the repository
+// or with each other. Reads hold the repository lock for reading and writes
+// hold it for writing, as the store requires.
 type MemoryCustomerRepository struct {
-	customers map[string]*model.Customer
-	mutex     sync.RWMutex
+	// customers holds the customers, deleted ones included, and files the
+	// ones that are not deleted by email key
+	customers *store.Store[*model.Customer]
+	// aliases maps the IDs of merged customers to the customers they were
+	// merged into
+	aliases map[string]alias
+	// stats summarizes the customers until the next write drops it
+	stats atomic.Pointer[model.CustomerStats]
+	// clock stamps UpdatedAt, which grows with every write to a customer
+	clock *clock.Clock
+	// ids generates the IDs of new customers
+	ids   idgen.Generator
+	mutex sync.RWMutex
 }
 
-// NewMemoryCustomerRepository creates a new in-memory customer repository
-func NewMemoryCustomerRepository() *MemoryCustomerRepository {
-	repo := &MemoryCustomerRepository{
-		customers: make(map[string]*model.Customer),
+// alias points the ID of a merged customer at the survivor
+type alias struct {
+	customerID string
+	createdAt  time.Time
+}
+
+// emailIndex keeps the emails of the customers that are not deleted unique.
+// Deleted customers give up their email until they are restored.
+var emailIndex = store.NewUnique(func(customer *model.Customer) []string {
+	if customer.IsDeleted() {
+		return nil
 	}
+	return []string{customer.EmailKey()}
+})
+
+// NewMemoryCustomerRepository creates a new in-memory customer repository with sample data
+func NewMemoryCustomerRepository() *MemoryCustomerRepository {
+	repo := newEmptyMemoryCustomerRepository()
 
 	// Initialize with sample data
 	repo.initSampleData()
@@ -37,131 +79,432 @@ func NewMemoryCustomerRepository() *MemoryCustomerRepository {
 	return repo
 }
 
+// newEmptyMemoryCustomerRepository creates an in-memory customer repository without sample data
+func newEmptyMemoryCustomerRepository() *MemoryCustomerRepository {
+	return &MemoryCustomerRepository{
+		customers: newCustomerStore(),
+		aliases:   make(map[string]alias),
+		clock:     clock.New(),
+		ids:       idgen.Default(),
+	}
+}
+
+// newCustomerStore creates an empty store of customers
+func newCustomerStore() *store.Store[*model.Customer] {
+	return store.New(
+		func(customer *model.Customer) string { return customer.ID },
+		(*model.Customer).Clone,
+		emailIndex,
+	)
+}
+
 // GetByID retrieves a customer by ID
-func (r *MemoryCustomerRepository) GetByID(id string) (*model.Customer, error) {
+func (r *MemoryCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	customer, exists := r.customers[id]
-	if !exists {
-		return nil, errors.New("customer not found")
+	customer, exists := r.customers.Get(id)
+	if !exists || customer.IsDeleted() {
+		return nil, model.ErrCustomerNotFound
+	}
+
+	return customer.Clone(), nil
+}
+
+// GetByIDs retrieves the customers with the given IDs that exist and have not
+// been deleted. Unknown IDs are skipped; the order of the result is undefined.
+func (r *MemoryCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	customers := make([]*model.Customer, 0, len(ids))
+	for _, id := range ids {
+		if customer, exists := r.customers.Get(id); exists && !customer.IsDeleted() {
+			customers = append(customers, customer.Clone())
+		}
 	}
 
-	return customer, nil
+	return customers, nil
 }
 
-// GetAll retrieves all customers
-func (r *MemoryCustomerRepository) GetAll() ([]*model.Customer, error) {
+// GetAll retrieves all customers that have not been deleted, ordered by ID
+func (r *MemoryCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	customers := make([]*model.Customer, 0, len(r.customers))
-	for _, customer := range r.customers {
-		customers = append(customers, customer)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	customers := make([]*model.Customer, 0, r.customers.Len())
+	for customer := range r.customers.All() {
+		if !customer.IsDeleted() {
+			customers = append(customers, customer.Clone())
+		}
 	}
 
 	return customers, nil
 }
 
+// Find retrieves a page of customers matching the filter along with the total number of matches
+func (r *MemoryCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]*model.Customer, 0, r.customers.Len())
+	for customer := range r.customers.All() {
+		if filter.Matches(customer) {
+			matches = append(matches, customer)
+		}
+	}
+	sortCustomers(matches, filter.Sort)
+
+	start, end := filter.Page.Bounds(len(matches))
+	page := matches[start:end]
+	for i, customer := range page {
+		page[i] = customer.Clone()
+	}
+	return page, len(matches), nil
+}
+
+// Stats summarizes the customers that are not deleted. The summary is
+// computed on the first call after a write and reused until the next one, so
+// polling dashboards do not rescan the customers.
+func (r *MemoryCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Writes wait for the read lock, so the summary cannot miss one
+	stats := r.stats.Load()
+	if stats == nil {
+		stats = model.NewCustomerStats(slices.Collect(r.customers.All()))
+		r.stats.Store(stats)
+	}
+	return stats.Clone(), nil
+}
+
 // Create creates a new customer
-func (r *MemoryCustomerRepository) Create(customer *model.Customer) (*model.Customer, error) {
+func (r *MemoryCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	customer = customer.Clone()
 	if customer.ID == "" {
-		customer.ID = uuid.New().String()
+		customer.ID = r.ids.New()
 	}
 
-	if r.existsByIDUnsafe(customer.ID) {
-		return nil, errors.New("customer already exists")
+	// Soft-deleted customers keep their ID reserved until they are purged
+	if _, exists := r.customers.Get(customer.ID); exists {
+		return nil, model.ErrCustomerExists
 	}
 
 	// Check for duplicate email
-	if r.existsByEmailUnsafe(customer.Email) {
-		return nil, errors.New("customer with this email already exists")
+	if r.existsByEmailUnsafe(customer.EmailKey()) {
+		return nil, model.ErrEmailTaken
 	}
 
-	r.customers[customer.ID] = customer
-	return customer, nil
+	customer.UpdatedAt = r.clock.Now()
+	if customer.CreatedAt.IsZero() {
+		customer.CreatedAt = customer.UpdatedAt
+	}
+	r.storeUnsafe(customer)
+	return customer.Clone(), nil
 }
 
 // Update updates an existing customer
-func (r *MemoryCustomerRepository) Update(id string, customer *model.Customer) (*model.Customer, error) {
+func (r *MemoryCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if !r.existsByIDUnsafe(id) {
-		return nil, errors.New("customer not found")
+	current, exists := r.customers.Get(id)
+	if !exists || current.IsDeleted() {
+		return nil, model.ErrCustomerNotFound
 	}
 
 	// Check for duplicate email (excluding current customer)
-	if existing := r.getByEmailUnsafe(customer.Email); existing != nil && existing.ID != id {
-		return nil, errors.New("customer with this email already exists")
+	if existing := r.getByEmailUnsafe(customer.EmailKey()); existing != nil && existing.ID != id {
+		return nil, model.ErrEmailTaken
 	}
 
+	customer = customer.Clone()
 	customer.ID = id
-	r.customers[id] = customer
-	return customer, nil
+	customer.CreatedAt = current.CreatedAt
+	customer.UpdatedAt = r.clock.After(current.UpdatedAt)
+	r.storeUnsafe(customer)
+	return customer.Clone(), nil
 }
 
-// Delete deletes a customer by ID
-func (r *MemoryCustomerRepository) Delete(id string) error {
+// Delete soft-deletes a customer by ID so it can be restored later
+func (r *MemoryCustomerRepository) Delete(ctx context.Context, id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if !r.existsByIDUnsafe(id) {
-		return errors.New("customer not found")
+	current, exists := r.customers.Get(id)
+	if !exists || current.IsDeleted() {
+		return model.ErrCustomerNotFound
 	}
 
-	delete(r.customers, id)
+	deletedAt := r.clock.After(current.UpdatedAt)
+	deleted := current.Clone()
+	deleted.DeletedAt = &deletedAt
+	deleted.UpdatedAt = deletedAt
+	r.storeUnsafe(deleted)
 	return nil
 }
 
+// Restore undoes the soft delete of a customer
+func (r *MemoryCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	customer, exists := r.customers.Get(id)
+	if !exists {
+		return nil, model.ErrCustomerNotFound
+	}
+	if !customer.IsDeleted() {
+		return nil, model.ErrCustomerNotDeleted
+	}
+
+	// Another customer may have taken the email while this one was deleted
+	if r.existsByEmailUnsafe(customer.EmailKey()) {
+		return nil, model.ErrEmailTaken
+	}
+
+	restored := customer.Clone()
+	restored.DeletedAt = nil
+	restored.UpdatedAt = r.clock.After(customer.UpdatedAt)
+	r.storeUnsafe(restored)
+	// A restored customer answers for its own ID again
+	delete(r.aliases, id)
+	return restored.Clone(), nil
+}
+
 // ExistsByID checks if a customer exists by ID
-func (r *MemoryCustomerRepository) ExistsByID(id string) bool {
+func (r *MemoryCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	return r.existsByIDUnsafe(id)
 }
 
-// GetByEmail retrieves a customer by email
-func (r *MemoryCustomerRepository) GetByEmail(email string) (*model.Customer, error) {
+// GetByEmail retrieves a customer by email. The email is matched against
+// Customer.EmailKey, so callers storing encrypted emails pass the blind index.
+func (r *MemoryCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
 	customer := r.getByEmailUnsafe(email)
 	if customer == nil {
-		return nil, errors.New("customer not found")
+		return nil, model.ErrCustomerNotFound
 	}
 
-	return customer, nil
+	return customer.Clone(), nil
 }
 
-// existsByIDUnsafe checks if a customer exists by ID (without locking)
-func (r *MemoryCustomerRepository) existsByIDUnsafe(id string) bool {
-	_, exists := r.customers[id]
-	return exists
+// AddAlias makes alias, the ID of a customer merged into another, refer to
+// customerID. Aliases of the merged customer follow it to customerID.
+func (r *MemoryCustomerRepository) AddAlias(ctx context.Context, aliasID, customerID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.existsByIDUnsafe(aliasID) {
+		return model.ErrCustomerExists
+	}
+	if !r.existsByIDUnsafe(customerID) {
+		return model.ErrCustomerNotFound
+	}
+
+	now := time.Now()
+	for id, existing := range r.aliases {
+		if existing.customerID == aliasID {
+			r.aliases[id] = alias{customerID: customerID, createdAt: now}
+		}
+	}
+	r.aliases[aliasID] = alias{customerID: customerID, createdAt: now}
+	return nil
 }
 
-// existsByEmailUnsafe checks if a customer exists by email (without locking)
-func (r *MemoryCustomerRepository) existsByEmailUnsafe(email string) bool {
-	return r.getByEmailUnsafe(email) != nil
+// ResolveAlias returns the customer a merged customer's ID refers to
+func (r *MemoryCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	existing, ok := r.aliases[id]
+	return existing.customerID, ok
 }
 
-// getByEmailUnsafe retrieves a customer by email (without locking)
-func (r *MemoryCustomerRepository) getByEmailUnsafe(email string) *model.Customer {
-	for _, customer := range r.customers {
-		if customer.Email == email {
-			return customer
-		}
+// Transaction runs fn against a private copy of the repository and commits
+// its writes only if fn returns nil before ctx is done. Other callers are
+// blocked until the transaction finishes.
+func (r *MemoryCustomerRepository) Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := &MemoryCustomerRepository{
+		customers: r.customers.Clone(),
+		aliases:   maps.Clone(r.aliases),
+		clock:     r.clock,
+		ids:       r.ids,
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// Do not commit writes the caller has given up on
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.customers.Commit(tx.customers)
+	r.aliases = tx.aliases
+	r.stats.Store(nil)
+	return nil
+}
+
+// Load stores customers as they are, replacing customers with the same ID,
+// so the repository can be recovered from a copy kept elsewhere. Unlike
+// Create it neither stamps nor checks them.
+func (r *MemoryCustomerRepository) Load(ctx context.Context, customers []*model.Customer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, customer := range customers {
+		r.customers.Load(customer.Clone())
 	}
+	r.stats.Store(nil)
 	return nil
 }
 
+// PurgeExpired reverts customers written before cutoff to their seed state,
+// removing customers that were not part of the seed data
+func (r *MemoryCustomerRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := r.customers.PurgeExpired(cutoff, r.revert)
+
+	// There are no seed aliases, so every alias stems from a merge
+	for id, existing := range r.aliases {
+		if existing.createdAt.Before(cutoff) {
+			delete(r.aliases, id)
+			purged++
+		}
+	}
+
+	r.stats.Store(nil)
+	return purged
+}
+
+// Reset restores the repository to its seed data
+func (r *MemoryCustomerRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.customers.Reset(r.revert)
+	r.aliases = make(map[string]alias)
+	r.stats.Store(nil)
+}
+
+// storeUnsafe stores a written customer and drops the stats (without locking)
+func (r *MemoryCustomerRepository) storeUnsafe(customer *model.Customer) {
+	r.customers.Put(customer)
+	r.stats.Store(nil)
+}
+
+// existsByIDUnsafe checks if a customer that has not been deleted exists by ID (without locking)
+func (r *MemoryCustomerRepository) existsByIDUnsafe(id string) bool {
+	customer, exists := r.customers.Get(id)
+	return exists && !customer.IsDeleted()
+}
+
+// existsByEmailUnsafe checks if a customer exists by email key (without locking)
+func (r *MemoryCustomerRepository) existsByEmailUnsafe(key string) bool {
+	return r.getByEmailUnsafe(key) != nil
+}
+
+// getByEmailUnsafe retrieves a customer that has not been deleted by email key (without locking)
+func (r *MemoryCustomerRepository) getByEmailUnsafe(key string) *model.Customer {
+	id, exists := r.customers.Lookup(emailIndex, key)
+	if !exists {
+		return nil
+	}
+	customer, _ := r.customers.Get(id)
+	return customer
+}
+
+// revert returns a copy of a seeded customer to store in its place. The copy
+// counts as changed after the customer it replaces, if any, so clients
+// holding the replaced version refetch it.
+func (r *MemoryCustomerRepository) revert(seeded, current *model.Customer) *model.Customer {
+	reverted := seeded.Clone()
+	reverted.UpdatedAt = r.clock.Now()
+	if current != nil {
+		reverted.UpdatedAt = r.clock.After(current.UpdatedAt)
+	}
+	return reverted
+}
+
+// sortCustomers orders customers listed by ascending ID by the requested
+// sort option, falling back to ID
+func sortCustomers(customers []*model.Customer, option string) {
+	switch option {
+	case "", model.SortByID:
+		return
+	case model.SortByIDDesc:
+		slices.Reverse(customers)
+		return
+	}
+
+	sort.SliceStable(customers, func(i, j int) bool {
+		a, b := customers[i], customers[j]
+		switch option {
+		case model.SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case model.SortByNameDesc:
+			if a.Name != b.Name {
+				return a.Name > b.Name
+			}
+		}
+		return a.ID < b.ID
+	})
+}
+
 // initSampleData initializes the repository with sample data
 func (r *MemoryCustomerRepository) initSampleData() {
-	sampleCustomers := []*model.Customer{
+	seededAt := r.clock.Now()
+	for _, customer := range sampleCustomers() {
+		customer.CreatedAt = seededAt
+		customer.UpdatedAt = seededAt
+		r.customers.Seed(customer)
+	}
+}
+
+// sampleCustomers returns the customers the default tenant is seeded with
+func sampleCustomers() []*model.Customer {
+	return []*model.Customer{
 		{
 			ID:     "customer-456",
 			Name:   "John Doe",
@@ -227,8 +570,4 @@ func (r *MemoryCustomerRepository) initSampleData() {
 			Status: model.StatusPending,
 		},
 	}
-
-	for _, customer := range sampleCustomers {
-		r.customers[customer.ID] = customer
-	}
 }
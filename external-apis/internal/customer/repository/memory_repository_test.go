@@ -1,9 +1,17 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/timerange"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -14,7 +22,7 @@ func TestMemoryCustomerRepository_GetByID(t *testing.T) {
 
 	t.Run("Get existing customer", func(t *testing.T) {
 		// Act
-		customer, err := repo.GetByID("customer-456")
+		customer, err := repo.GetByID(context.Background(), "customer-456")
 
 		// Assert
 		require.NoError(t, err)
@@ -27,7 +35,7 @@ func TestMemoryCustomerRepository_GetByID(t *testing.T) {
 
 	t.Run("Get non-existing customer", func(t *testing.T) {
 		// Act
-		customer, err := repo.GetByID("non-existing")
+		customer, err := repo.GetByID(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -42,7 +50,7 @@ func TestMemoryCustomerRepository_GetByEmail(t *testing.T) {
 
 	t.Run("Get customer by existing email", func(t *testing.T) {
 		// Act
-		customer, err := repo.GetByEmail("john.doe@example.com")
+		customer, err := repo.GetByEmail(context.Background(), "john.doe@example.com")
 
 		// Assert
 		require.NoError(t, err)
@@ -53,7 +61,7 @@ func TestMemoryCustomerRepository_GetByEmail(t *testing.T) {
 
 	t.Run("Get customer by non-existing email", func(t *testing.T) {
 		// Act
-		customer, err := repo.GetByEmail("nonexisting@example.com")
+		customer, err := repo.GetByEmail(context.Background(), "nonexisting@example.com")
 
 		// Assert
 		assert.Error(t, err)
@@ -67,7 +75,7 @@ func TestMemoryCustomerRepository_GetAll(t *testing.T) {
 	repo := NewMemoryCustomerRepository()
 
 	// Act
-	customers, err := repo.GetAll()
+	customers, err := repo.GetAll(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -85,6 +93,67 @@ func TestMemoryCustomerRepository_GetAll(t *testing.T) {
 	assert.Greater(t, statuses[model.StatusPending], 0)
 }
 
+func TestMemoryCustomerRepository_Ordering(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := NewMemoryCustomerRepository()
+
+	// ids returns the IDs of customers in the order they are listed
+	ids := func(customers []*model.Customer) []string {
+		result := make([]string, 0, len(customers))
+		for _, customer := range customers {
+			result = append(result, customer.ID)
+		}
+		return result
+	}
+
+	t.Run("List customers by ID", func(t *testing.T) {
+		// Arrange
+		_, err := repo.Create(ctx, &model.Customer{ID: "customer-zzz", Name: "Last", Email: "last@example.com"})
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, &model.Customer{ID: "customer-000", Name: "First", Email: "first@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		customers, err := repo.GetAll(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, slices.IsSorted(ids(customers)))
+		assert.Equal(t, "customer-000", customers[0].ID)
+		assert.Equal(t, "customer-zzz", customers[len(customers)-1].ID)
+	})
+
+	t.Run("List customers by descending ID", func(t *testing.T) {
+		// Act
+		customers, _, err := repo.Find(ctx, model.CustomerFilter{Sort: model.SortByIDDesc, Page: pagination.Params{Limit: 100}})
+
+		// Assert
+		require.NoError(t, err)
+		listed := ids(customers)
+		slices.Reverse(listed)
+		assert.True(t, slices.IsSorted(listed))
+	})
+
+	t.Run("Keep the order through transactions and purges", func(t *testing.T) {
+		// Arrange
+		err := repo.Transaction(ctx, func(tx CustomerRepository) error {
+			_, err := tx.Create(ctx, &model.Customer{ID: "customer-mmm", Name: "Middle", Email: "middle@example.com"})
+			return err
+		})
+		require.NoError(t, err)
+		repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Act
+		customers, err := repo.GetAll(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, customers, 8)
+		assert.True(t, slices.IsSorted(ids(customers)))
+	})
+}
+
 func TestMemoryCustomerRepository_Create(t *testing.T) {
 	// Arrange
 	repo := NewMemoryCustomerRepository()
@@ -100,16 +169,18 @@ func TestMemoryCustomerRepository_Create(t *testing.T) {
 		}
 
 		// Act
-		created, err := repo.Create(newCustomer)
+		created, err := repo.Create(context.Background(), newCustomer)
 
 		// Assert
 		require.NoError(t, err)
 		assert.NotEmpty(t, created.ID)
 		assert.Equal(t, "New Customer", created.Name)
 		assert.Equal(t, "new@example.com", created.Email)
+		assert.False(t, created.CreatedAt.IsZero())
+		assert.Equal(t, created.UpdatedAt, created.CreatedAt)
 
 		// Verify it was actually stored
-		retrieved, err := repo.GetByID(created.ID)
+		retrieved, err := repo.GetByID(context.Background(), created.ID)
 		require.NoError(t, err)
 		assert.Equal(t, created.ID, retrieved.ID)
 	})
@@ -125,7 +196,7 @@ func TestMemoryCustomerRepository_Create(t *testing.T) {
 		}
 
 		// Act
-		created, err := repo.Create(duplicateCustomer)
+		created, err := repo.Create(context.Background(), duplicateCustomer)
 
 		// Assert
 		assert.Error(t, err)
@@ -145,7 +216,7 @@ func TestMemoryCustomerRepository_Create(t *testing.T) {
 		}
 
 		// Act
-		created, err := repo.Create(existingCustomer)
+		created, err := repo.Create(context.Background(), existingCustomer)
 
 		// Assert
 		assert.Error(t, err)
@@ -160,6 +231,8 @@ func TestMemoryCustomerRepository_Update(t *testing.T) {
 
 	t.Run("Update existing customer", func(t *testing.T) {
 		// Arrange
+		before, err := repo.GetByID(context.Background(), "customer-456")
+		require.NoError(t, err)
 		updatedCustomer := &model.Customer{
 			ID:     "customer-456",
 			Name:   "Updated John Doe",
@@ -170,7 +243,7 @@ func TestMemoryCustomerRepository_Update(t *testing.T) {
 		}
 
 		// Act
-		result, err := repo.Update("customer-456", updatedCustomer)
+		result, err := repo.Update(context.Background(), "customer-456", updatedCustomer)
 
 		// Assert
 		require.NoError(t, err)
@@ -178,9 +251,11 @@ func TestMemoryCustomerRepository_Update(t *testing.T) {
 		assert.Equal(t, "updated.john@example.com", result.Email)
 		assert.False(t, result.Active)
 		assert.Equal(t, model.StatusInactive, result.Status)
+		assert.True(t, result.UpdatedAt.After(before.UpdatedAt), "the change is timestamped")
+		assert.Equal(t, before.CreatedAt, result.CreatedAt, "the creation time is kept")
 
 		// Verify the update was persisted
-		retrieved, err := repo.GetByID("customer-456")
+		retrieved, err := repo.GetByID(context.Background(), "customer-456")
 		require.NoError(t, err)
 		assert.Equal(t, "Updated John Doe", retrieved.Name)
 	})
@@ -197,7 +272,7 @@ func TestMemoryCustomerRepository_Update(t *testing.T) {
 		}
 
 		// Act
-		result, err := repo.Update("customer-001", updatedCustomer)
+		result, err := repo.Update(context.Background(), "customer-001", updatedCustomer)
 
 		// Assert
 		assert.Error(t, err)
@@ -216,7 +291,7 @@ func TestMemoryCustomerRepository_Update(t *testing.T) {
 		}
 
 		// Act
-		result, err := repo.Update("non-existing", customer)
+		result, err := repo.Update(context.Background(), "non-existing", customer)
 
 		// Assert
 		assert.Error(t, err)
@@ -231,20 +306,20 @@ func TestMemoryCustomerRepository_Delete(t *testing.T) {
 
 	t.Run("Delete existing customer", func(t *testing.T) {
 		// Act
-		err := repo.Delete("customer-001")
+		err := repo.Delete(context.Background(), "customer-001")
 
 		// Assert
 		require.NoError(t, err)
 
 		// Verify it was deleted
-		customer, err := repo.GetByID("customer-001")
+		customer, err := repo.GetByID(context.Background(), "customer-001")
 		assert.Error(t, err)
 		assert.Nil(t, customer)
 	})
 
 	t.Run("Delete non-existing customer", func(t *testing.T) {
 		// Act
-		err := repo.Delete("non-existing")
+		err := repo.Delete(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -258,7 +333,7 @@ func TestMemoryCustomerRepository_ExistsByID(t *testing.T) {
 
 	t.Run("Existing customer", func(t *testing.T) {
 		// Act
-		exists := repo.ExistsByID("customer-456")
+		exists := repo.ExistsByID(context.Background(), "customer-456")
 
 		// Assert
 		assert.True(t, exists)
@@ -266,7 +341,7 @@ func TestMemoryCustomerRepository_ExistsByID(t *testing.T) {
 
 	t.Run("Non-existing customer", func(t *testing.T) {
 		// Act
-		exists := repo.ExistsByID("non-existing")
+		exists := repo.ExistsByID(context.Background(), "non-existing")
 
 		// Assert
 		assert.False(t, exists)
@@ -282,7 +357,7 @@ func TestMemoryCustomerRepository_ConcurrentAccess(t *testing.T) {
 		done := make(chan bool, 10)
 		for i := 0; i < 10; i++ {
 			go func() {
-				_, _ = repo.GetByID("customer-456")
+				_, _ = repo.GetByID(context.Background(), "customer-456")
 				done <- true
 			}()
 		}
@@ -305,7 +380,7 @@ func TestMemoryCustomerRepository_ConcurrentAccess(t *testing.T) {
 					Active: true,
 					Status: model.StatusActive,
 				}
-				_, _ = repo.Create(customer)
+				_, _ = repo.Create(context.Background(), customer)
 				done <- true
 			}(i)
 		}
@@ -316,3 +391,716 @@ func TestMemoryCustomerRepository_ConcurrentAccess(t *testing.T) {
 		}
 	})
 }
+
+func TestMemoryCustomerRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+
+	created, err := repo.Create(context.Background(), &model.Customer{
+		Name:   "Sandbox User",
+		Email:  "sandbox@example.com",
+		Phone:  "+15550199",
+		Active: true,
+		Status: model.StatusActive,
+	})
+	require.NoError(t, err)
+
+	seeded, err := repo.GetByID(context.Background(), "customer-456")
+	require.NoError(t, err)
+	seeded.Name = "Changed Name"
+	_, err = repo.Update(context.Background(), "customer-456", seeded)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(context.Background(), "customer-001"))
+
+	t.Run("Writes newer than cutoff are kept", func(t *testing.T) {
+		// Act
+		purged := repo.PurgeExpired(time.Now().Add(-time.Hour))
+
+		// Assert
+		assert.Equal(t, 0, purged)
+		assert.True(t, repo.ExistsByID(context.Background(), created.ID))
+	})
+
+	t.Run("Expired writes are reverted to seed data", func(t *testing.T) {
+		// Act
+		purged := repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Assert
+		assert.Equal(t, 3, purged)
+		assert.False(t, repo.ExistsByID(context.Background(), created.ID))
+		assert.True(t, repo.ExistsByID(context.Background(), "customer-001"))
+
+		restored, err := repo.GetByID(context.Background(), "customer-456")
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", restored.Name)
+	})
+}
+
+func TestMemoryCustomerRepository_Reset(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+	_, err := repo.Create(context.Background(), &model.Customer{Name: "Extra", Email: "extra@example.com"})
+	require.NoError(t, err)
+
+	// Act
+	repo.Reset()
+
+	// Assert
+	customers, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, customers, 8)
+}
+
+func TestMemoryCustomerRepository_Find(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+
+	t.Run("First page is ordered by ID", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{Page: pagination.Params{Limit: 3}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 8, total)
+		require.Len(t, customers, 3)
+		assert.Less(t, customers[0].ID, customers[1].ID)
+		assert.Less(t, customers[1].ID, customers[2].ID)
+	})
+
+	t.Run("Pages do not overlap", func(t *testing.T) {
+		// Act
+		first, _, err := repo.Find(context.Background(), model.CustomerFilter{Page: pagination.Params{Limit: 3}})
+		require.NoError(t, err)
+		second, _, err := repo.Find(context.Background(), model.CustomerFilter{Page: pagination.Params{Limit: 3, Offset: 3}})
+		require.NoError(t, err)
+
+		// Assert
+		require.Len(t, second, 3)
+		assert.Less(t, first[2].ID, second[0].ID)
+	})
+
+	t.Run("Offset past the end", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{Page: pagination.Params{Limit: 3, Offset: 100}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 8, total)
+		assert.Empty(t, customers)
+	})
+}
+
+func TestMemoryCustomerRepository_FindWithFilters(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+	active := false
+	status := model.StatusBlocked
+
+	t.Run("Filter by active flag", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{Active: &active, Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+		for _, customer := range customers {
+			assert.False(t, customer.Active)
+		}
+	})
+
+	t.Run("Filter by status", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{Status: &status, Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "customer-blocked", customers[0].ID)
+	})
+
+	t.Run("Combine search criteria", func(t *testing.T) {
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{
+			Name:        "user",
+			EmailDomain: "example.com",
+			PhonePrefix: "+1555",
+			Status:      &status,
+			Page:        pagination.DefaultParams(),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "Blocked User", customers[0].Name)
+	})
+
+	t.Run("Filter by update time", func(t *testing.T) {
+		// Arrange
+		seeded, err := repo.GetByID(context.Background(), "customer-456")
+		require.NoError(t, err)
+		changed, err := repo.Update(context.Background(), "customer-456", seeded)
+		require.NoError(t, err)
+
+		// Act
+		customers, total, err := repo.Find(context.Background(), model.CustomerFilter{
+			Updated: timerange.Range{Since: changed.UpdatedAt},
+			Page:    pagination.DefaultParams(),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "customer-456", customers[0].ID)
+	})
+
+	t.Run("Sort by name descending", func(t *testing.T) {
+		// Act
+		customers, _, err := repo.Find(context.Background(), model.CustomerFilter{Sort: model.SortByNameDesc, Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		for i := 1; i < len(customers); i++ {
+			assert.GreaterOrEqual(t, customers[i-1].Name, customers[i].Name)
+		}
+	})
+}
+
+func TestMemoryCustomerRepository_SoftDeleteAndRestore(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+	require.NoError(t, repo.Delete(context.Background(), "customer-456"))
+
+	t.Run("Deleted customer is hidden", func(t *testing.T) {
+		// Act
+		_, total, err := repo.Find(context.Background(), model.CustomerFilter{Page: pagination.DefaultParams()})
+		_, totalWithDeleted, _ := repo.Find(context.Background(), model.CustomerFilter{IncludeDeleted: true, Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, totalWithDeleted-1, total)
+		assert.False(t, repo.ExistsByID(context.Background(), "customer-456"))
+		_, err = repo.GetByEmail(context.Background(), "john.doe@example.com")
+		assert.EqualError(t, err, "customer not found")
+		assert.EqualError(t, repo.Delete(context.Background(), "customer-456"), "customer not found")
+	})
+
+	t.Run("Restore deleted customer", func(t *testing.T) {
+		// Act
+		restored, err := repo.Restore(context.Background(), "customer-456")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.True(t, repo.ExistsByID(context.Background(), "customer-456"))
+	})
+
+	t.Run("Restore customer that is not deleted", func(t *testing.T) {
+		// Act
+		_, err := repo.Restore(context.Background(), "customer-456")
+
+		// Assert
+		assert.EqualError(t, err, "customer is not deleted")
+	})
+
+	t.Run("Restore when email was taken", func(t *testing.T) {
+		// Arrange
+		require.NoError(t, repo.Delete(context.Background(), "customer-456"))
+		_, err := repo.Create(context.Background(), &model.Customer{Name: "New John", Email: "john.doe@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		_, err = repo.Restore(context.Background(), "customer-456")
+
+		// Assert
+		assert.EqualError(t, err, "customer with this email already exists")
+	})
+}
+
+func TestMemoryCustomerRepository_Transaction(t *testing.T) {
+	t.Run("Commit writes when fn succeeds", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+
+		// Act
+		err := repo.Transaction(context.Background(), func(tx CustomerRepository) error {
+			_, err := tx.Create(context.Background(), &model.Customer{ID: "customer-tx", Name: "Tx", Email: "tx@example.com"})
+			return err
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, repo.ExistsByID(context.Background(), "customer-tx"))
+	})
+
+	t.Run("Discard writes when fn fails", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		original, _ := repo.GetByID(context.Background(), "customer-456")
+		originalName := original.Name
+		failure := errors.New("boom")
+
+		// Act
+		err := repo.Transaction(context.Background(), func(tx CustomerRepository) error {
+			existing, _ := tx.GetByID(context.Background(), "customer-456")
+			existing.Name = "Changed"
+			if _, err := tx.Update(context.Background(), "customer-456", existing); err != nil {
+				return err
+			}
+			if err := tx.Delete(context.Background(), "customer-456"); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+		customer, err := repo.GetByID(context.Background(), "customer-456")
+		require.NoError(t, err)
+		assert.Equal(t, originalName, customer.Name)
+	})
+
+	t.Run("Discard writes when ctx is cancelled", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Act
+		err := repo.Transaction(ctx, func(tx CustomerRepository) error {
+			cancel()
+			return tx.Delete(ctx, "customer-001")
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, repo.ExistsByID(context.Background(), "customer-001"))
+	})
+}
+
+func TestMemoryCustomerRepository_CancelledContext(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("GetAll", func(t *testing.T) {
+		// Act
+		customers, err := repo.GetAll(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, customers)
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		// Act
+		_, _, err := repo.Find(ctx, model.CustomerFilter{Page: pagination.Params{Limit: 10}})
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMemoryCustomerRepository_Aliases(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.Delete(ctx, "customer-001"))
+
+	t.Run("Alias of a merged customer", func(t *testing.T) {
+		// Act
+		err := repo.AddAlias(ctx, "customer-001", "customer-456")
+
+		// Assert
+		require.NoError(t, err)
+		survivorID, ok := repo.ResolveAlias(ctx, "customer-001")
+		assert.True(t, ok)
+		assert.Equal(t, "customer-456", survivorID)
+	})
+
+	t.Run("Aliases follow a later merge", func(t *testing.T) {
+		// Arrange
+		require.NoError(t, repo.Delete(ctx, "customer-456"))
+
+		// Act
+		err := repo.AddAlias(ctx, "customer-456", "customer-002")
+
+		// Assert
+		require.NoError(t, err)
+		survivorID, _ := repo.ResolveAlias(ctx, "customer-001")
+		assert.Equal(t, "customer-002", survivorID)
+	})
+
+	t.Run("Alias of a live customer", func(t *testing.T) {
+		// Act
+		err := repo.AddAlias(ctx, "customer-003", "customer-002")
+
+		// Assert
+		assert.EqualError(t, err, "customer already exists")
+	})
+
+	t.Run("Alias of a missing customer", func(t *testing.T) {
+		// Act
+		err := repo.AddAlias(ctx, "customer-999", "customer-456")
+
+		// Assert
+		assert.EqualError(t, err, "customer not found")
+	})
+
+	t.Run("Restore drops the alias", func(t *testing.T) {
+		// Act
+		_, err := repo.Restore(ctx, "customer-001")
+
+		// Assert
+		require.NoError(t, err)
+		_, ok := repo.ResolveAlias(ctx, "customer-001")
+		assert.False(t, ok)
+	})
+
+	t.Run("Unknown ID", func(t *testing.T) {
+		// Act
+		_, ok := repo.ResolveAlias(ctx, "customer-999")
+
+		// Assert
+		assert.False(t, ok)
+	})
+}
+
+func TestMemoryCustomerRepository_EmailIndex(t *testing.T) {
+	ctx := context.Background()
+
+	// assertIndexed checks that the email index matches a scan of the customers
+	assertIndexed := func(t *testing.T, repo *MemoryCustomerRepository) {
+		t.Helper()
+		for customer := range repo.customers.All() {
+			id, indexed := repo.customers.Lookup(emailIndex, customer.EmailKey())
+			if customer.IsDeleted() {
+				assert.False(t, indexed && id == customer.ID, "deleted %s is indexed", customer.ID)
+			} else {
+				assert.True(t, indexed, "%s is not indexed", customer.ID)
+				assert.Equal(t, customer.ID, id)
+			}
+		}
+	}
+
+	t.Run("Seed data is indexed", func(t *testing.T) {
+		// Act
+		repo := NewMemoryCustomerRepository()
+
+		// Assert
+		assertIndexed(t, repo)
+	})
+
+	t.Run("Find created customers by email", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+
+		// Act
+		created, err := repo.Create(ctx, &model.Customer{Name: "Indexed", Email: "indexed@example.com"})
+		require.NoError(t, err)
+
+		// Assert
+		found, err := repo.GetByEmail(ctx, "indexed@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, found.ID)
+		assertIndexed(t, repo)
+	})
+
+	t.Run("Follow email changes", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		existing, err := repo.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+
+		// Act
+		existing.Email = "changed.in.place@example.com"
+		_, err = repo.Update(ctx, "customer-456", existing)
+		require.NoError(t, err)
+		_, err = repo.Update(ctx, "customer-001", &model.Customer{Name: "Jane Smith", Email: "replaced@example.com"})
+		require.NoError(t, err)
+
+		// Assert
+		_, err = repo.GetByEmail(ctx, "john.doe@example.com")
+		assert.EqualError(t, err, "customer not found")
+		found, err := repo.GetByEmail(ctx, "changed.in.place@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", found.ID)
+		found, err = repo.GetByEmail(ctx, "replaced@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "customer-001", found.ID)
+		_, err = repo.Create(ctx, &model.Customer{Name: "New John", Email: "john.doe@example.com"})
+		assert.NoError(t, err, "the previous email is free again")
+		assertIndexed(t, repo)
+	})
+
+	t.Run("Ignore changes to customers that were not saved", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		existing, err := repo.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+
+		// Act
+		existing.Email = "unsaved@example.com"
+
+		// Assert
+		found, err := repo.GetByEmail(ctx, "john.doe@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", found.ID)
+		_, err = repo.GetByEmail(ctx, "unsaved@example.com")
+		assert.EqualError(t, err, "customer not found")
+	})
+
+	t.Run("Drop deleted customers and index restored ones", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+
+		// Act
+		require.NoError(t, repo.Delete(ctx, "customer-456"))
+		assertIndexed(t, repo)
+		_, err := repo.Restore(ctx, "customer-456")
+		require.NoError(t, err)
+
+		// Assert
+		found, err := repo.GetByEmail(ctx, "john.doe@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", found.ID)
+		assertIndexed(t, repo)
+	})
+
+	t.Run("Rebuild on purge and reset", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		_, err := repo.Create(ctx, &model.Customer{Name: "Sandbox", Email: "sandbox@example.com"})
+		require.NoError(t, err)
+		_, err = repo.Update(ctx, "customer-001", &model.Customer{Name: "Jane Smith", Email: "replaced@example.com"})
+		require.NoError(t, err)
+		require.NoError(t, repo.Delete(ctx, "customer-456"))
+
+		// Act
+		repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Assert
+		assertIndexed(t, repo)
+		_, err = repo.GetByEmail(ctx, "sandbox@example.com")
+		assert.EqualError(t, err, "customer not found")
+		_, err = repo.GetByEmail(ctx, "john.doe@example.com")
+		assert.NoError(t, err)
+
+		// Act
+		_, err = repo.Create(ctx, &model.Customer{Name: "Sandbox", Email: "sandbox@example.com"})
+		require.NoError(t, err)
+		repo.Reset()
+
+		// Assert
+		assertIndexed(t, repo)
+	})
+
+	t.Run("Keep the index when a transaction fails", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+
+		// Act
+		err := repo.Transaction(ctx, func(tx CustomerRepository) error {
+			if _, err := tx.Create(ctx, &model.Customer{Name: "Tx", Email: "tx@example.com"}); err != nil {
+				return err
+			}
+			if err := tx.Delete(ctx, "customer-456"); err != nil {
+				return err
+			}
+			return errors.New("boom")
+		})
+
+		// Assert
+		require.Error(t, err)
+		_, err = repo.GetByEmail(ctx, "tx@example.com")
+		assert.EqualError(t, err, "customer not found")
+		_, err = repo.GetByEmail(ctx, "john.doe@example.com")
+		assert.NoError(t, err)
+		assertIndexed(t, repo)
+	})
+}
+
+func TestMemoryCustomerRepository_DefensiveCopies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Changes to returned customers are not stored", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		byID, err := repo.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+		listed, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+
+		// Act
+		byID.Name = "Changed"
+		listed[0].Name = "Changed"
+
+		// Assert
+		stored, err := repo.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", stored.Name)
+		stored, err = repo.GetByID(ctx, listed[0].ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, "Changed", stored.Name)
+	})
+
+	t.Run("Changes to written customers are not stored", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		customer := &model.Customer{ID: "customer-copy", Name: "Original", Email: "copy@example.com"}
+
+		// Act
+		created, err := repo.Create(ctx, customer)
+		require.NoError(t, err)
+		customer.Name = "Changed input"
+		created.Name = "Changed result"
+
+		// Assert
+		stored, err := repo.GetByID(ctx, "customer-copy")
+		require.NoError(t, err)
+		assert.Equal(t, "Original", stored.Name)
+	})
+
+	t.Run("Callers change their copies while others read", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		active := model.StatusActive
+		var wg sync.WaitGroup
+
+		// Act
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				customer, err := repo.GetByID(ctx, "customer-456")
+				if err == nil {
+					customer.Name = fmt.Sprintf("Writer %d", i)
+					customer.Status = model.StatusInactive
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				_, _, _ = repo.Find(ctx, model.CustomerFilter{Status: &active, Page: pagination.DefaultParams()})
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		stored, err := repo.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", stored.Name)
+	})
+}
+
+func TestMemoryCustomerRepository_Stats(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCustomerRepository()
+	ctx := context.Background()
+	before, err := repo.Stats(ctx)
+	require.NoError(t, err)
+
+	t.Run("Count new customers", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(ctx, &model.Customer{Name: "Blocked Customer", Email: "stats.blocked@example.com", Status: model.StatusBlocked})
+		require.NoError(t, err)
+		stats, err := repo.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, before.Total+1, stats.Total)
+		assert.Equal(t, before.ByStatus[model.StatusBlocked]+1, stats.ByStatus[model.StatusBlocked])
+	})
+
+	t.Run("Leave deleted customers out", func(t *testing.T) {
+		// Act
+		require.NoError(t, repo.Delete(ctx, "customer-456"))
+		stats, err := repo.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, before.Total, stats.Total)
+	})
+
+	t.Run("Return copies of the cached stats", func(t *testing.T) {
+		// Arrange
+		stats, err := repo.Stats(ctx)
+		require.NoError(t, err)
+
+		// Act
+		stats.ByStatus[model.StatusActive] = 1000
+		again, err := repo.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEqual(t, 1000, again.ByStatus[model.StatusActive])
+	})
+}
+
+func TestMemoryCustomerRepository_UpdatedAt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Grow with every write", func(t *testing.T) {
+		// Arrange
+		repo := newEmptyMemoryCustomerRepository()
+		created, err := repo.Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com"})
+		require.NoError(t, err)
+		stamps := []time.Time{created.UpdatedAt}
+
+		// Act
+		for i := range 3 {
+			updated, err := repo.Update(ctx, "customer-1", &model.Customer{Name: fmt.Sprintf("Ann %d", i), Email: "ann@example.com"})
+			require.NoError(t, err)
+			stamps = append(stamps, updated.UpdatedAt)
+		}
+		require.NoError(t, repo.Delete(ctx, "customer-1"))
+		stored, _ := repo.customers.Get("customer-1")
+		deleted := stored.Clone()
+		stamps = append(stamps, deleted.UpdatedAt)
+		restored, err := repo.Restore(ctx, "customer-1")
+		require.NoError(t, err)
+		stamps = append(stamps, restored.UpdatedAt)
+
+		// Assert
+		for i := 1; i < len(stamps); i++ {
+			assert.True(t, stamps[i].After(stamps[i-1]), "write %d at %s is not after %s", i, stamps[i], stamps[i-1])
+		}
+		for _, stamp := range stamps {
+			assert.Equal(t, stamp, stamp.Truncate(time.Microsecond))
+		}
+		require.NotNil(t, deleted.DeletedAt)
+		assert.Equal(t, deleted.UpdatedAt, *deleted.DeletedAt)
+		assert.Equal(t, created.CreatedAt, restored.CreatedAt)
+	})
+
+	t.Run("Write after a customer stamped ahead of the clock", func(t *testing.T) {
+		// Arrange
+		repo := newEmptyMemoryCustomerRepository()
+		_, err := repo.Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com"})
+		require.NoError(t, err)
+		ahead := time.Now().UTC().Add(time.Hour)
+		stored, _ := repo.customers.Get("customer-1")
+		stored.UpdatedAt = ahead
+
+		// Act
+		updated, err := repo.Update(ctx, "customer-1", &model.Customer{Name: "Ann Lee", Email: "ann@example.com"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, updated.UpdatedAt.After(ahead))
+	})
+
+	t.Run("Revert on purge", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCustomerRepository()
+		updated, err := repo.Update(ctx, "customer-456", &model.Customer{Name: "Renamed", Email: "renamed@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		repo.PurgeExpired(time.Now().Add(time.Minute))
+
+		// Assert
+		reverted, err := repo.GetByID(ctx, "customer-456")
+		require.NoError(t, err)
+		assert.NotEqual(t, "Renamed", reverted.Name)
+		assert.True(t, reverted.UpdatedAt.After(updated.UpdatedAt))
+	})
+}
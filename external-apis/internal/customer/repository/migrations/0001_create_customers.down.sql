@@ -0,0 +1,3 @@
+-- Dropping the tables drops their indexes
+DROP TABLE customer_aliases;
+DROP TABLE customers;
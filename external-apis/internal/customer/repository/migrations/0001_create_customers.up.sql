@@ -0,0 +1,30 @@
+-- Customers keep their full representation in data, as JSON. The columns
+-- next to it are the ones lookups, unique indexes and change data capture
+-- need.
+CREATE TABLE customers (
+	tenant      TEXT NOT NULL,
+	id          TEXT NOT NULL,
+	email_key   TEXT NOT NULL,
+	email_index TEXT NOT NULL DEFAULT '',
+	data        TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL,
+	deleted_at  TEXT,
+	PRIMARY KEY (tenant, id)
+);
+
+-- Deleted customers give up their email
+CREATE UNIQUE INDEX customers_email_key ON customers (tenant, email_key) WHERE deleted_at IS NULL;
+
+CREATE INDEX customers_updated_at ON customers (updated_at);
+
+-- customer_aliases maps the IDs of merged customers to the survivors
+CREATE TABLE customer_aliases (
+	tenant      TEXT NOT NULL,
+	id          TEXT NOT NULL,
+	customer_id TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	PRIMARY KEY (tenant, id)
+);
+
+CREATE INDEX customer_aliases_customer_id ON customer_aliases (tenant, customer_id);
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/timerange"
+	"external-apis/internal/shared/wal"
+)
+
+// CustomerLoader is a CustomerRepository that recovered customers can be
+// loaded into
+type CustomerLoader interface {
+	CustomerRepository
+	Load(ctx context.Context, customers []*model.Customer) error
+}
+
+// PersistentCustomerRepository records every customer written to the
+// wrapped repository, and every alias, in write-ahead logs so an in-memory
+// repository survives restarts. Writes are serialized so the logs hold them
+// in the order they were applied; the writes of a transaction are logged
+// once it commits.
+type PersistentCustomerRepository struct {
+	inner CustomerRepository
+	// loader is the wrapped repository outside transactions, where
+	// customers are recovered into it
+	loader  CustomerLoader
+	log     *wal.Log
+	aliases *wal.Log
+	// pending collects the entries of a transaction until it commits; it is
+	// nil outside transactions
+	pending *[]pendingEntry
+	mutex   *sync.Mutex
+}
+
+// pendingEntry is an entry of a transaction waiting for it to commit
+type pendingEntry struct {
+	log   *wal.Log
+	entry wal.Entry
+}
+
+// customerRecord is the logged state of a customer. It keeps the blind
+// index, which the JSON form of a customer leaves out.
+type customerRecord struct {
+	*model.Customer
+	EmailIndex string `json:"emailIndex,omitempty"`
+}
+
+// NewPersistentCustomerRepository wraps inner so that its customers are
+// logged to log and its aliases to aliases. Call Recover before serving
+// requests to load what the logs hold.
+func NewPersistentCustomerRepository(inner CustomerLoader, log, aliases *wal.Log) *PersistentCustomerRepository {
+	return &PersistentCustomerRepository{
+		inner:   inner,
+		loader:  inner,
+		log:     log,
+		aliases: aliases,
+		mutex:   &sync.Mutex{},
+	}
+}
+
+// Recover loads the customers and aliases of every tenant held by the logs
+// into the wrapped repository and returns the number of customers loaded
+func (r *PersistentCustomerRepository) Recover(ctx context.Context) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recovered := 0
+	for _, id := range r.log.Tenants() {
+		states := r.log.States(id)
+		customers := make([]*model.Customer, 0, len(states))
+		for _, state := range states {
+			var record customerRecord
+			if err := json.Unmarshal(state, &record); err != nil {
+				return recovered, err
+			}
+			record.Customer.EmailIndex = record.EmailIndex
+			customers = append(customers, record.Customer)
+		}
+		if err := r.loader.Load(tenant.WithTenant(ctx, id), customers); err != nil {
+			return recovered, err
+		}
+		recovered += len(customers)
+	}
+
+	// Aliases are logged with their final target, so they can be added in
+	// any order
+	for _, id := range r.aliases.Tenants() {
+		tenantCtx := tenant.WithTenant(ctx, id)
+		for aliasID, state := range r.aliases.States(id) {
+			var customerID string
+			if err := json.Unmarshal(state, &customerID); err != nil {
+				return recovered, err
+			}
+			if err := r.inner.AddAlias(tenantCtx, aliasID, customerID); err != nil {
+				log.WithError(err).WithFields(logger.Fields{
+					"tenant":     id,
+					"alias":      aliasID,
+					"customerId": customerID,
+				}).Warn("Skipping unrecoverable customer alias")
+			}
+		}
+	}
+
+	return recovered, nil
+}
+
+// GetByID retrieves a customer by ID
+func (r *PersistentCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
+	return r.inner.GetByID(ctx, id)
+}
+
+// GetAll retrieves all customers that have not been deleted
+func (r *PersistentCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
+	return r.inner.GetAll(ctx)
+}
+
+// GetByIDs retrieves the customers with the given IDs that exist and have
+// not been deleted
+func (r *PersistentCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	return r.inner.GetByIDs(ctx, ids)
+}
+
+// Find retrieves a page of customers matching the filter along with the
+// total number of matches
+func (r *PersistentCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	return r.inner.Find(ctx, filter)
+}
+
+// Create stores and logs a new customer
+func (r *PersistentCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
+	defer r.lock()()
+
+	created, err := r.inner.Create(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.put(ctx, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update stores and logs an existing customer
+func (r *PersistentCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
+	defer r.lock()()
+
+	updated, err := r.inner.Update(ctx, id, customer)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.put(ctx, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete soft-deletes a customer by ID and logs the deleted customer
+func (r *PersistentCustomerRepository) Delete(ctx context.Context, id string) error {
+	defer r.lock()()
+
+	current, err := r.inner.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.inner.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	deleted, err := r.deleted(ctx, current)
+	if err != nil {
+		return err
+	}
+	return r.put(ctx, deleted)
+}
+
+// Restore undoes the soft delete of a customer, which then answers for its
+// own ID again, and logs both
+func (r *PersistentCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	defer r.lock()()
+
+	restored, err := r.inner.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.put(ctx, restored); err != nil {
+		return nil, err
+	}
+	if err := r.append(r.aliases, wal.Entry{Tenant: tenant.FromContext(ctx), ID: id}); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// ExistsByID checks if a customer exists by ID
+func (r *PersistentCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.inner.ExistsByID(ctx, id)
+}
+
+// GetByEmail retrieves a customer by email
+func (r *PersistentCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
+	return r.inner.GetByEmail(ctx, email)
+}
+
+// AddAlias makes the ID of a merged customer refer to the survivor and logs
+// the alias. Aliases of the merged customer are logged again with the
+// survivor, as they follow it there.
+func (r *PersistentCustomerRepository) AddAlias(ctx context.Context, alias, customerID string) error {
+	defer r.lock()()
+
+	if err := r.inner.AddAlias(ctx, alias, customerID); err != nil {
+		return err
+	}
+
+	id := tenant.FromContext(ctx)
+	value, err := json.Marshal(customerID)
+	if err != nil {
+		return err
+	}
+	entries := []wal.Entry{{Tenant: id, ID: alias, Value: value}}
+	for existing, state := range r.aliases.States(id) {
+		var target string
+		if json.Unmarshal(state, &target) == nil && target == alias {
+			entries = append(entries, wal.Entry{Tenant: id, ID: existing, Value: value})
+		}
+	}
+	return r.append(r.aliases, entries...)
+}
+
+// ResolveAlias returns the customer a merged customer's ID refers to
+func (r *PersistentCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	return r.inner.ResolveAlias(ctx, id)
+}
+
+// Stats summarizes the customers of the wrapped repository
+func (r *PersistentCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	return r.inner.Stats(ctx)
+}
+
+// Transaction runs fn against a transaction of the wrapped repository and
+// logs its writes once it commits
+func (r *PersistentCustomerRepository) Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error {
+	defer r.lock()()
+
+	var pending []pendingEntry
+	err := r.inner.Transaction(ctx, func(tx CustomerRepository) error {
+		return fn(&PersistentCustomerRepository{
+			inner:   tx,
+			log:     r.log,
+			aliases: r.aliases,
+			pending: &pending,
+			mutex:   r.mutex,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pending {
+		if err := r.append(p.log, p.entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lock serializes writes and returns the function releasing the lock.
+// Repositories of a transaction run under the lock of the transaction.
+func (r *PersistentCustomerRepository) lock() func() {
+	if r.pending != nil {
+		return func() {}
+	}
+	r.mutex.Lock()
+	return r.mutex.Unlock
+}
+
+// put logs the state of a customer of the tenant of ctx
+func (r *PersistentCustomerRepository) put(ctx context.Context, customer *model.Customer) error {
+	value, err := json.Marshal(customerRecord{Customer: customer, EmailIndex: customer.EmailIndex})
+	if err != nil {
+		return err
+	}
+	return r.append(r.log, wal.Entry{Tenant: tenant.FromContext(ctx), ID: customer.ID, Value: value})
+}
+
+// append writes entries to a log, or holds them back until the transaction
+// commits
+func (r *PersistentCustomerRepository) append(l *wal.Log, entries ...wal.Entry) error {
+	if r.pending != nil {
+		for _, entry := range entries {
+			*r.pending = append(*r.pending, pendingEntry{log: l, entry: entry})
+		}
+		return nil
+	}
+	return l.Append(entries...)
+}
+
+// deleted retrieves a customer after it was soft-deleted. Deleting stamps
+// UpdatedAt, so only customers changed since current was read are scanned.
+func (r *PersistentCustomerRepository) deleted(ctx context.Context, current *model.Customer) (*model.Customer, error) {
+	changed, _, err := r.inner.Find(ctx, model.CustomerFilter{
+		Updated:        timerange.Range{Since: current.UpdatedAt.Add(time.Nanosecond)},
+		IncludeDeleted: true,
+		Page:           pagination.Params{Limit: math.MaxInt},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(changed, func(customer *model.Customer) bool { return customer.ID == current.ID })
+	if i < 0 {
+		return nil, model.ErrCustomerNotFound
+	}
+	return changed[i], nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/wal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openPersistentCustomerRepository opens the logs in dir and recovers a
+// fresh tenant repository from them, as a restarted service would
+func openPersistentCustomerRepository(t *testing.T, dir string) *PersistentCustomerRepository {
+	t.Helper()
+	customers, err := wal.Open(filepath.Join(dir, "customers"), 0)
+	require.NoError(t, err)
+	aliases, err := wal.Open(filepath.Join(dir, "customer-aliases"), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		customers.Close()
+		aliases.Close()
+	})
+
+	repo := NewPersistentCustomerRepository(NewTenantCustomerRepository(), customers, aliases)
+	_, err = repo.Recover(context.Background())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestPersistentCustomerRepository_Recover(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	brandA := tenant.WithTenant(ctx, "brand-a")
+	dir := t.TempDir()
+	repo := openPersistentCustomerRepository(t, dir)
+
+	created, err := repo.Create(brandA, &model.Customer{ID: "customer-a", Name: "Brand A", Email: "a@example.com"})
+	require.NoError(t, err)
+	updated, err := repo.Update(ctx, "customer-001", &model.Customer{Name: "Jane Doe", Email: "jane.doe@example.com", Active: true, Status: model.StatusActive})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, "customer-002"))
+	require.NoError(t, repo.Delete(ctx, "customer-003"))
+	require.NoError(t, repo.AddAlias(ctx, "customer-003", "customer-004"))
+	require.NoError(t, repo.Delete(ctx, "customer-004"))
+	require.NoError(t, repo.AddAlias(ctx, "customer-004", "customer-456"))
+
+	// Act
+	recovered := openPersistentCustomerRepository(t, dir)
+
+	// Assert
+	t.Run("Customers of every tenant", func(t *testing.T) {
+		customer, err := recovered.GetByID(brandA, created.ID)
+		require.NoError(t, err)
+		assert.True(t, created.UpdatedAt.Equal(customer.UpdatedAt))
+		assert.False(t, recovered.ExistsByID(ctx, created.ID))
+	})
+
+	t.Run("Updates override the seed data", func(t *testing.T) {
+		customer, err := recovered.GetByEmail(ctx, "jane.doe@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, updated.Name, customer.Name)
+		_, err = recovered.GetByEmail(ctx, "jane.smith@example.com")
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+	})
+
+	t.Run("Deleted customers stay restorable", func(t *testing.T) {
+		assert.False(t, recovered.ExistsByID(ctx, "customer-002"))
+		restored, err := recovered.Restore(ctx, "customer-002")
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+	})
+
+	t.Run("Aliases follow merges", func(t *testing.T) {
+		target, ok := recovered.ResolveAlias(ctx, "customer-003")
+		assert.True(t, ok)
+		assert.Equal(t, "customer-456", target)
+	})
+}
+
+func TestPersistentCustomerRepository_Transaction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := openPersistentCustomerRepository(t, dir)
+	failure := errors.New("merge failed")
+
+	// Act
+	committed := repo.Transaction(ctx, func(tx CustomerRepository) error {
+		_, err := tx.Create(ctx, &model.Customer{ID: "customer-committed", Email: "committed@example.com"})
+		return err
+	})
+	rolledBack := repo.Transaction(ctx, func(tx CustomerRepository) error {
+		if _, err := tx.Create(ctx, &model.Customer{ID: "customer-rolled-back", Email: "rolled-back@example.com"}); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	// Assert
+	require.NoError(t, committed)
+	assert.ErrorIs(t, rolledBack, failure)
+	recovered := openPersistentCustomerRepository(t, dir)
+	assert.True(t, recovered.ExistsByID(ctx, "customer-committed"))
+	assert.False(t, recovered.ExistsByID(ctx, "customer-rolled-back"))
+}
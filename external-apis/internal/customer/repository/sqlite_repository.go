@@ -0,0 +1,443 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/clock"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+)
+
+var log = logger.New("customer/repository")
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations of SQLiteCustomerRepository
+func Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFiles, "migrations")
+	return sub
+}
+
+// SQLiteCustomerRepository implements CustomerRepository on an SQLite
+// database migrated with Migrations, so customers outlive restarts without
+// running a database server. Rows are partitioned by the tenant of the
+// request context. Listings and stats read every customer of the tenant and
+// filter them like the memory repository does, which suits the data sets of
+// local development and demos.
+type SQLiteCustomerRepository struct {
+	db *sql.DB
+	// q runs the statements: db, or the transaction of a repository
+	// handed to Transaction
+	q database.Querier
+	// cluster routes GetByID, GetAll and Find to its read replicas when
+	// their context tolerates stale reads
+	cluster *database.Cluster
+	clock   *clock.Clock
+	// ids generates the IDs of new customers
+	ids idgen.Generator
+}
+
+// NewSQLiteCustomerRepository creates a customer repository on db
+func NewSQLiteCustomerRepository(db *sql.DB) *SQLiteCustomerRepository {
+	return NewReplicatedSQLiteCustomerRepository(database.NewCluster(db, nil, 0))
+}
+
+// NewReplicatedSQLiteCustomerRepository creates a customer repository
+// writing to the primary of cluster and reading from its replicas
+func NewReplicatedSQLiteCustomerRepository(cluster *database.Cluster) *SQLiteCustomerRepository {
+	db := cluster.Primary()
+	return &SQLiteCustomerRepository{db: db, q: db, cluster: cluster, clock: clock.New(), ids: idgen.Default()}
+}
+
+// SetIDs sets the generator of the IDs of new customers, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *SQLiteCustomerRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// Seed stores the sample customers in the default tenant unless it already
+// has customers, deleted ones included
+func (r *SQLiteCustomerRepository) Seed(ctx context.Context) error {
+	ctx = tenant.WithTenant(ctx, tenant.Default)
+	return r.Transaction(ctx, func(tx CustomerRepository) error {
+		repo := tx.(*SQLiteCustomerRepository)
+		var count int
+		if err := repo.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM customers WHERE tenant = ?`, tenant.Default).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		seededAt := r.clock.Now()
+		for _, customer := range sampleCustomers() {
+			customer.CreatedAt = seededAt
+			customer.UpdatedAt = seededAt
+			if err := repo.insert(ctx, customer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a customer by ID
+func (r *SQLiteCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
+	var customer *model.Customer
+	err := r.read(ctx, func(repo *SQLiteCustomerRepository) error {
+		var err error
+		customer, err = repo.get(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if customer.IsDeleted() {
+		return nil, model.ErrCustomerNotFound
+	}
+	return customer, nil
+}
+
+// GetByIDs retrieves the customers with the given IDs that exist and have not
+// been deleted. Unknown IDs are skipped; the order of the result is undefined.
+func (r *SQLiteCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	customers := make([]*model.Customer, 0, len(ids))
+	for _, id := range ids {
+		customer, err := r.GetByID(ctx, id)
+		if errors.Is(err, model.ErrCustomerNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return customers, nil
+}
+
+// GetAll retrieves all customers that have not been deleted, ordered by ID
+func (r *SQLiteCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
+	var customers []*model.Customer
+	err := r.read(ctx, func(repo *SQLiteCustomerRepository) error {
+		var err error
+		customers, err = repo.list(ctx, `SELECT data, email_index FROM customers WHERE tenant = ? AND deleted_at IS NULL ORDER BY id`, tenant.FromContext(ctx))
+		return err
+	})
+	return customers, err
+}
+
+// Find retrieves a page of customers matching the filter along with the total number of matches
+func (r *SQLiteCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	var customers []*model.Customer
+	err := r.read(ctx, func(repo *SQLiteCustomerRepository) error {
+		var err error
+		customers, err = repo.list(ctx, `SELECT data, email_index FROM customers WHERE tenant = ? ORDER BY id`, tenant.FromContext(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]*model.Customer, 0, len(customers))
+	for _, customer := range customers {
+		if filter.Matches(customer) {
+			matches = append(matches, customer)
+		}
+	}
+	sortCustomers(matches, filter.Sort)
+
+	start, end := filter.Page.Bounds(len(matches))
+	return matches[start:end], len(matches), nil
+}
+
+// Stats summarizes the customers that are not deleted
+func (r *SQLiteCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	customers, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewCustomerStats(customers), nil
+}
+
+// Create creates a new customer
+func (r *SQLiteCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
+	customer = customer.Clone()
+	if customer.ID == "" {
+		customer.ID = r.ids.New()
+	}
+
+	err := r.Transaction(ctx, func(tx CustomerRepository) error {
+		repo := tx.(*SQLiteCustomerRepository)
+		// Soft-deleted customers keep their ID reserved
+		if _, err := repo.get(ctx, customer.ID); err == nil {
+			return model.ErrCustomerExists
+		} else if !errors.Is(err, model.ErrCustomerNotFound) {
+			return err
+		}
+		if err := repo.checkEmail(ctx, customer.EmailKey(), customer.ID); err != nil {
+			return err
+		}
+
+		customer.UpdatedAt = r.clock.Now()
+		if customer.CreatedAt.IsZero() {
+			customer.CreatedAt = customer.UpdatedAt
+		}
+		return repo.insert(ctx, customer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return customer.Clone(), nil
+}
+
+// Update updates an existing customer
+func (r *SQLiteCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
+	customer = customer.Clone()
+	err := r.Transaction(ctx, func(tx CustomerRepository) error {
+		repo := tx.(*SQLiteCustomerRepository)
+		current, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.checkEmail(ctx, customer.EmailKey(), id); err != nil {
+			return err
+		}
+
+		customer.ID = id
+		customer.CreatedAt = current.CreatedAt
+		customer.UpdatedAt = r.clock.After(current.UpdatedAt)
+		return repo.update(ctx, customer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return customer.Clone(), nil
+}
+
+// Delete soft-deletes a customer by ID so it can be restored later
+func (r *SQLiteCustomerRepository) Delete(ctx context.Context, id string) error {
+	return r.Transaction(ctx, func(tx CustomerRepository) error {
+		repo := tx.(*SQLiteCustomerRepository)
+		deleted, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		deletedAt := r.clock.After(deleted.UpdatedAt)
+		deleted.DeletedAt = &deletedAt
+		deleted.UpdatedAt = deletedAt
+		return repo.update(ctx, deleted)
+	})
+}
+
+// Restore undoes the soft delete of a customer
+func (r *SQLiteCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	var restored *model.Customer
+	err := r.Transaction(ctx, func(tx CustomerRepository) error {
+		repo := tx.(*SQLiteCustomerRepository)
+		customer, err := repo.get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !customer.IsDeleted() {
+			return model.ErrCustomerNotDeleted
+		}
+		// Another customer may have taken the email while this one was deleted
+		if err := repo.checkEmail(ctx, customer.EmailKey(), id); err != nil {
+			return err
+		}
+
+		customer.DeletedAt = nil
+		customer.UpdatedAt = r.clock.After(customer.UpdatedAt)
+		if err := repo.update(ctx, customer); err != nil {
+			return err
+		}
+		// A restored customer answers for its own ID again
+		_, err = repo.q.ExecContext(ctx, `DELETE FROM customer_aliases WHERE tenant = ? AND id = ?`, tenant.FromContext(ctx), id)
+		restored = customer
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// ExistsByID checks if a customer exists by ID
+func (r *SQLiteCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
+	_, err := r.GetByID(ctx, id)
+	return err == nil
+}
+
+// GetByEmail retrieves a customer by email. The email is matched against
+// Customer.EmailKey, so callers storing encrypted emails pass the blind index.
+func (r *SQLiteCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
+	return r.scan(r.q.QueryRowContext(ctx, `SELECT data, email_index FROM customers WHERE tenant = ? AND email_key = ? AND deleted_at IS NULL`,
+		tenant.FromContext(ctx), email))
+}
+
+// AddAlias makes alias, the ID of a customer merged into another, refer to
+// customerID. Aliases of the merged customer follow it to customerID.
+func (r *SQLiteCustomerRepository) AddAlias(ctx context.Context, aliasID, customerID string) error {
+	return r.Transaction(ctx, func(tx CustomerRepository) error {
+		repo := tx.(*SQLiteCustomerRepository)
+		if repo.ExistsByID(ctx, aliasID) {
+			return model.ErrCustomerExists
+		}
+		if !repo.ExistsByID(ctx, customerID) {
+			return model.ErrCustomerNotFound
+		}
+
+		id := tenant.FromContext(ctx)
+		now := database.FormatTime(time.Now())
+		if _, err := repo.q.ExecContext(ctx, `UPDATE customer_aliases SET customer_id = ?, created_at = ? WHERE tenant = ? AND customer_id = ?`,
+			customerID, now, id, aliasID); err != nil {
+			return err
+		}
+		_, err := repo.q.ExecContext(ctx, `INSERT INTO customer_aliases (tenant, id, customer_id, created_at) VALUES (?, ?, ?, ?)
+			ON CONFLICT (tenant, id) DO UPDATE SET customer_id = excluded.customer_id, created_at = excluded.created_at`,
+			id, aliasID, customerID, now)
+		return err
+	})
+}
+
+// ResolveAlias returns the customer a merged customer's ID refers to
+func (r *SQLiteCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	var customerID string
+	err := r.q.QueryRowContext(ctx, `SELECT customer_id FROM customer_aliases WHERE tenant = ? AND id = ?`,
+		tenant.FromContext(ctx), id).Scan(&customerID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Ctx(ctx).WithError(err).Error("Failed to resolve customer alias")
+		}
+		return "", false
+	}
+	return customerID, true
+}
+
+// Transaction runs fn against a repository on a database transaction and
+// commits its writes only if fn returns nil before ctx is done. A
+// transaction handed to fn runs nested transactions in itself.
+func (r *SQLiteCustomerRepository) Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error {
+	if r.q != r.db {
+		return fn(r)
+	}
+	return database.Transaction(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&SQLiteCustomerRepository{db: r.db, q: tx, cluster: r.cluster, clock: r.clock, ids: r.ids})
+	})
+}
+
+// read runs fn against a repository on the database of the cluster a read
+// with ctx is routed to. Reads in a transaction stay in it.
+func (r *SQLiteCustomerRepository) read(ctx context.Context, fn func(repo *SQLiteCustomerRepository) error) error {
+	if r.q != r.db {
+		return fn(r)
+	}
+	return r.cluster.Read(ctx, func(q database.Querier) error {
+		return fn(&SQLiteCustomerRepository{db: r.db, q: q, cluster: r.cluster, clock: r.clock, ids: r.ids})
+	})
+}
+
+// get retrieves a customer, deleted or not
+func (r *SQLiteCustomerRepository) get(ctx context.Context, id string) (*model.Customer, error) {
+	return r.scan(r.q.QueryRowContext(ctx, `SELECT data, email_index FROM customers WHERE tenant = ? AND id = ?`,
+		tenant.FromContext(ctx), id))
+}
+
+// list retrieves the customers a query selects
+func (r *SQLiteCustomerRepository) list(ctx context.Context, query string, args ...any) ([]*model.Customer, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	customers := make([]*model.Customer, 0)
+	for rows.Next() {
+		customer, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	return customers, rows.Err()
+}
+
+// scan decodes a customer from its data and email_index columns
+func (r *SQLiteCustomerRepository) scan(row interface{ Scan(dest ...any) error }) (*model.Customer, error) {
+	var data []byte
+	var emailIndex string
+	if err := row.Scan(&data, &emailIndex); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrCustomerNotFound
+		}
+		return nil, err
+	}
+
+	var customer model.Customer
+	if err := json.Unmarshal(data, &customer); err != nil {
+		return nil, err
+	}
+	customer.EmailIndex = emailIndex
+	return &customer, nil
+}
+
+// checkEmail checks that no customer other than id that is not deleted
+// uses the email key
+func (r *SQLiteCustomerRepository) checkEmail(ctx context.Context, key, id string) error {
+	var owner string
+	err := r.q.QueryRowContext(ctx, `SELECT id FROM customers WHERE tenant = ? AND email_key = ? AND deleted_at IS NULL`,
+		tenant.FromContext(ctx), key).Scan(&owner)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	case owner != id:
+		return model.ErrEmailTaken
+	default:
+		return nil
+	}
+}
+
+// insert stores a new customer
+func (r *SQLiteCustomerRepository) insert(ctx context.Context, customer *model.Customer) error {
+	data, err := json.Marshal(customer)
+	if err != nil {
+		return err
+	}
+	_, err = r.q.ExecContext(ctx, `INSERT INTO customers (tenant, id, email_key, email_index, data, created_at, updated_at, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		tenant.FromContext(ctx), customer.ID, customer.EmailKey(), customer.EmailIndex, data,
+		database.FormatTime(customer.CreatedAt), database.FormatTime(customer.UpdatedAt), database.FormatNullTime(customer.DeletedAt))
+	if database.IsUniqueViolation(err) {
+		return model.ErrEmailTaken
+	}
+	return err
+}
+
+// update replaces a stored customer
+func (r *SQLiteCustomerRepository) update(ctx context.Context, customer *model.Customer) error {
+	data, err := json.Marshal(customer)
+	if err != nil {
+		return err
+	}
+	_, err = r.q.ExecContext(ctx, `UPDATE customers SET email_key = ?, email_index = ?, data = ?, updated_at = ?, deleted_at = ?
+		WHERE tenant = ? AND id = ?`,
+		customer.EmailKey(), customer.EmailIndex, data, database.FormatTime(customer.UpdatedAt), database.FormatNullTime(customer.DeletedAt),
+		tenant.FromContext(ctx), customer.ID)
+	if database.IsUniqueViolation(err) {
+		return model.ErrEmailTaken
+	}
+	return err
+}
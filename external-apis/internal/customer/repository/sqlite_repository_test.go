@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/migrations"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSQLite opens and migrates an SQLite database in a temporary file
+func openSQLite(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := database.OpenSQLite(path, database.DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = migrations.Up(context.Background(), db, Migrations())
+	require.NoError(t, err)
+	return db
+}
+
+func newSQLiteCustomerRepository(t *testing.T) *SQLiteCustomerRepository {
+	t.Helper()
+	return NewSQLiteCustomerRepository(openSQLite(t, filepath.Join(t.TempDir(), "customers.db")))
+}
+
+func TestSQLiteCustomerRepository_Seed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+
+	// Act
+	require.NoError(t, repo.Seed(ctx))
+	require.NoError(t, repo.Delete(ctx, "customer-456"))
+	require.NoError(t, repo.Seed(ctx))
+
+	// Assert
+	customers, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, customers, len(sampleCustomers())-1, "seeding again does not bring back deleted customers")
+	other, err := repo.GetAll(tenant.WithTenant(ctx, "brand-a"))
+	require.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestSQLiteCustomerRepository_SetIDs(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+	ids := idgen.Prefixed("cus_", idgen.ULID())
+	repo.SetIDs(ids)
+
+	// Act
+	created, err := repo.Create(ctx, &model.Customer{Name: "Ann", Email: "ann@example.com"})
+	require.NoError(t, err)
+	retrieved, err := repo.GetByID(ctx, created.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ids.Valid(created.ID), created.ID)
+	assert.Equal(t, created.ID, retrieved.ID)
+}
+
+func TestSQLiteCustomerRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+
+	t.Run("Create and get", func(t *testing.T) {
+		// Act
+		created, err := repo.Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com", Status: model.StatusActive})
+		require.NoError(t, err)
+		retrieved, err := repo.GetByID(ctx, "customer-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, retrieved)
+		assert.False(t, created.CreatedAt.IsZero())
+		assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+	})
+
+	t.Run("Generate an ID", func(t *testing.T) {
+		// Act
+		created, err := repo.Create(ctx, &model.Customer{Name: "Bob", Email: "bob@example.com"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, idgen.Default().Valid(created.ID), created.ID)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "other@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerExists)
+	})
+
+	t.Run("Duplicate email", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(ctx, &model.Customer{Name: "Ann", Email: "ann@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrEmailTaken)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		// Act
+		updated, err := repo.Update(ctx, "customer-1", &model.Customer{Name: "Ann Lee", Email: "ann.lee@example.com"})
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, "Ann Lee", updated.Name)
+		assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+		byEmail, err := repo.GetByEmail(ctx, "ann.lee@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "customer-1", byEmail.ID)
+		_, err = repo.GetByEmail(ctx, "ann@example.com")
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+	})
+
+	t.Run("Update to a taken email", func(t *testing.T) {
+		// Act
+		_, err := repo.Update(ctx, "customer-1", &model.Customer{Name: "Ann", Email: "bob@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrEmailTaken)
+	})
+
+	t.Run("Update unknown customer", func(t *testing.T) {
+		// Act
+		_, err := repo.Update(ctx, "customer-9", &model.Customer{Name: "Nobody", Email: "nobody@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+	})
+
+	t.Run("Get by IDs", func(t *testing.T) {
+		// Act
+		customers, err := repo.GetByIDs(ctx, []string{"customer-1", "customer-9"})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, customers, 1)
+		assert.Equal(t, "customer-1", customers[0].ID)
+	})
+}
+
+func TestSQLiteCustomerRepository_SoftDeleteAndRestore(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+	_, err := repo.Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com"})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, repo.Delete(ctx, "customer-1"))
+
+	// Assert
+	assert.False(t, repo.ExistsByID(ctx, "customer-1"))
+	assert.ErrorIs(t, repo.Delete(ctx, "customer-1"), model.ErrCustomerNotFound)
+	_, total, err := repo.Find(ctx, model.CustomerFilter{IncludeDeleted: true, Page: pagination.DefaultParams()})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	t.Run("Email is free while deleted", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(ctx, &model.Customer{ID: "customer-2", Name: "Ann", Email: "ann@example.com"})
+		require.NoError(t, err)
+		_, restoreErr := repo.Restore(ctx, "customer-1")
+		require.NoError(t, repo.Delete(ctx, "customer-2"))
+
+		// Assert
+		assert.ErrorIs(t, restoreErr, model.ErrEmailTaken)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		// Act
+		restored, err := repo.Restore(ctx, "customer-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.True(t, repo.ExistsByID(ctx, "customer-1"))
+		_, err = repo.Restore(ctx, "customer-1")
+		assert.ErrorIs(t, err, model.ErrCustomerNotDeleted)
+	})
+}
+
+func TestSQLiteCustomerRepository_Find(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+	require.NoError(t, repo.Seed(ctx))
+
+	active := model.StatusActive
+	filter := model.CustomerFilter{Status: &active, Sort: model.SortByNameDesc, Page: pagination.Params{Limit: 1}}
+
+	// Act
+	page, total, err := repo.Find(ctx, filter)
+
+	// Assert
+	require.NoError(t, err)
+	memoryPage, memoryTotal, err := NewMemoryCustomerRepository().Find(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, memoryTotal, total)
+	require.Len(t, page, 1)
+	assert.Equal(t, memoryPage[0].ID, page[0].ID)
+
+	stats, err := repo.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(sampleCustomers()), stats.Total)
+}
+
+func TestSQLiteCustomerRepository_Aliases(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+	for _, customer := range []*model.Customer{
+		{ID: "customer-1", Name: "Ann", Email: "ann@example.com"},
+		{ID: "customer-2", Name: "Ann L", Email: "ann.l@example.com"},
+		{ID: "customer-3", Name: "A Lee", Email: "a.lee@example.com"},
+	} {
+		_, err := repo.Create(ctx, customer)
+		require.NoError(t, err)
+	}
+
+	// Act
+	require.NoError(t, repo.Delete(ctx, "customer-3"))
+	require.NoError(t, repo.AddAlias(ctx, "customer-3", "customer-2"))
+	require.NoError(t, repo.Delete(ctx, "customer-2"))
+	require.NoError(t, repo.AddAlias(ctx, "customer-2", "customer-1"))
+
+	// Assert
+	for _, id := range []string{"customer-2", "customer-3"} {
+		survivor, ok := repo.ResolveAlias(ctx, id)
+		assert.True(t, ok)
+		assert.Equal(t, "customer-1", survivor, "alias of %s", id)
+	}
+	_, ok := repo.ResolveAlias(ctx, "customer-1")
+	assert.False(t, ok)
+	assert.ErrorIs(t, repo.AddAlias(ctx, "customer-1", "customer-2"), model.ErrCustomerExists)
+
+	_, err := repo.Restore(ctx, "customer-2")
+	require.NoError(t, err)
+	_, ok = repo.ResolveAlias(ctx, "customer-2")
+	assert.False(t, ok)
+}
+
+func TestSQLiteCustomerRepository_Tenants(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+	brandA := tenant.WithTenant(ctx, "brand-a")
+	brandB := tenant.WithTenant(ctx, "brand-b")
+
+	// Act
+	_, errA := repo.Create(brandA, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com"})
+	_, errB := repo.Create(brandB, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com"})
+
+	// Assert
+	require.NoError(t, errA)
+	require.NoError(t, errB, "IDs and emails are unique per tenant")
+	require.NoError(t, repo.Delete(brandA, "customer-1"))
+	assert.False(t, repo.ExistsByID(brandA, "customer-1"))
+	assert.True(t, repo.ExistsByID(brandB, "customer-1"))
+}
+
+func TestSQLiteCustomerRepository_Transaction(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteCustomerRepository(t)
+
+	t.Run("Commit", func(t *testing.T) {
+		// Act
+		err := repo.Transaction(ctx, func(tx CustomerRepository) error {
+			_, err := tx.Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com"})
+			return err
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, repo.ExistsByID(ctx, "customer-1"))
+	})
+
+	t.Run("Roll back on error", func(t *testing.T) {
+		// Arrange
+		failure := errors.New("merge failed")
+
+		// Act
+		err := repo.Transaction(ctx, func(tx CustomerRepository) error {
+			if _, err := tx.Create(ctx, &model.Customer{ID: "customer-2", Name: "Bob", Email: "bob@example.com"}); err != nil {
+				return err
+			}
+			if err := tx.Delete(ctx, "customer-1"); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+		assert.False(t, repo.ExistsByID(ctx, "customer-2"))
+		assert.True(t, repo.ExistsByID(ctx, "customer-1"))
+	})
+}
+
+func TestSQLiteCustomerRepository_Persistence(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "customers.db")
+	db := openSQLite(t, path)
+	created, err := NewSQLiteCustomerRepository(db).Create(ctx, &model.Customer{ID: "customer-1", Name: "Ann", Email: "ann@example.com", EmailIndex: "blind-index"})
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// Act
+	reopened := NewSQLiteCustomerRepository(openSQLite(t, path))
+	retrieved, err := reopened.GetByEmail(ctx, "blind-index")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, created, retrieved)
+	assert.Equal(t, "blind-index", retrieved.EmailIndex)
+}
+
+func TestSQLiteCustomerRepository_Replicas(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	stale := database.WithStaleReads(ctx)
+	replica := openSQLite(t, filepath.Join(t.TempDir(), "replica.db"))
+	_, err := NewSQLiteCustomerRepository(replica).Create(ctx, &model.Customer{ID: "customer-replicated", Name: "Ann", Email: "ann@example.com"})
+	require.NoError(t, err)
+
+	cluster := database.NewCluster(openSQLite(t, filepath.Join(t.TempDir(), "customers.db")), []*sql.DB{replica}, time.Minute)
+	repo := NewReplicatedSQLiteCustomerRepository(cluster)
+	_, err = repo.Create(ctx, &model.Customer{ID: "customer-new", Name: "Bob", Email: "bob@example.com"})
+	require.NoError(t, err)
+
+	t.Run("Fresh reads see the writes", func(t *testing.T) {
+		// Act
+		customer, err := repo.GetByID(ctx, "customer-new")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Bob", customer.Name)
+	})
+
+	t.Run("Stale reads come from the replica", func(t *testing.T) {
+		// Act
+		_, err := repo.GetByID(stale, "customer-new")
+		customers, total, findErr := repo.Find(stale, model.CustomerFilter{Page: pagination.Params{Limit: 10}})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		require.NoError(t, findErr)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "customer-replicated", customers[0].ID)
+	})
+
+	t.Run("Reads in a transaction stay on the primary", func(t *testing.T) {
+		// Act
+		err := repo.Transaction(stale, func(tx CustomerRepository) error {
+			_, err := tx.GetByID(stale, "customer-new")
+			return err
+		})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+}
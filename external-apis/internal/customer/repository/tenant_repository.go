@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+)
+
+// TenantCustomerRepository implements CustomerRepository with a separate
+// in-memory repository per tenant, selected by the tenant of the request
+// context. Only the default tenant is seeded with sample data.
+type TenantCustomerRepository struct {
+	partitions *tenant.Partitions[*MemoryCustomerRepository]
+	// ids generates the IDs of new customers of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantCustomerRepository creates a new tenant-partitioned customer repository
+func NewTenantCustomerRepository() *TenantCustomerRepository {
+	r := &TenantCustomerRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(id string) *MemoryCustomerRepository {
+		var repo *MemoryCustomerRepository
+		if id == tenant.Default {
+			repo = NewMemoryCustomerRepository()
+		} else {
+			repo = newEmptyMemoryCustomerRepository()
+		}
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new customers, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantCustomerRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves a customer of the tenant by ID
+func (r *TenantCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// GetAll retrieves all customers of the tenant
+func (r *TenantCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
+	return r.partitions.View(ctx).GetAll(ctx)
+}
+
+// GetByIDs retrieves the customers of the tenant with the given IDs
+func (r *TenantCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	return r.partitions.View(ctx).GetByIDs(ctx, ids)
+}
+
+// Find retrieves a page of the customers of the tenant matching the filter
+func (r *TenantCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	return r.partitions.View(ctx).Find(ctx, filter)
+}
+
+// Create creates a customer for the tenant
+func (r *TenantCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
+	return r.partitions.For(ctx).Create(ctx, customer)
+}
+
+// Update updates a customer of the tenant
+func (r *TenantCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
+	return r.partitions.For(ctx).Update(ctx, id, customer)
+}
+
+// Delete soft-deletes a customer of the tenant
+func (r *TenantCustomerRepository) Delete(ctx context.Context, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, id)
+}
+
+// Restore undoes the soft delete of a customer of the tenant
+func (r *TenantCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	return r.partitions.For(ctx).Restore(ctx, id)
+}
+
+// ExistsByID checks if a customer exists for the tenant
+func (r *TenantCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.partitions.View(ctx).ExistsByID(ctx, id)
+}
+
+// GetByEmail retrieves a customer of the tenant by email. Emails only need
+// to be unique within a tenant.
+func (r *TenantCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
+	return r.partitions.View(ctx).GetByEmail(ctx, email)
+}
+
+// Transaction runs fn against a transaction of the tenant's repository
+func (r *TenantCustomerRepository) Transaction(ctx context.Context, fn func(tx CustomerRepository) error) error {
+	return r.partitions.For(ctx).Transaction(ctx, fn)
+}
+
+// AddAlias makes the ID of a customer of the tenant merged into another
+// refer to the survivor
+func (r *TenantCustomerRepository) AddAlias(ctx context.Context, alias, customerID string) error {
+	return r.partitions.For(ctx).AddAlias(ctx, alias, customerID)
+}
+
+// ResolveAlias returns the customer of the tenant a merged customer's ID
+// refers to
+func (r *TenantCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	return r.partitions.View(ctx).ResolveAlias(ctx, id)
+}
+
+// Stats summarizes the customers of the tenant
+func (r *TenantCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	return r.partitions.View(ctx).Stats(ctx)
+}
+
+// Load stores recovered customers of the tenant as they are
+func (r *TenantCustomerRepository) Load(ctx context.Context, customers []*model.Customer) error {
+	return r.partitions.For(ctx).Load(ctx, customers)
+}
+
+// PurgeExpired reverts the expired writes of every tenant
+func (r *TenantCustomerRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset restores the seed data of every tenant
+func (r *TenantCustomerRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantAddressRepository implements AddressRepository with a separate
+// in-memory repository per tenant
+type TenantAddressRepository struct {
+	partitions *tenant.Partitions[*MemoryAddressRepository]
+	// ids generates the IDs of new addresses of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantAddressRepository creates a new tenant-partitioned address repository
+func NewTenantAddressRepository() *TenantAddressRepository {
+	r := &TenantAddressRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(id string) *MemoryAddressRepository {
+		var repo *MemoryAddressRepository
+		if id == tenant.Default {
+			repo = NewMemoryAddressRepository()
+		} else {
+			repo = newEmptyMemoryAddressRepository()
+		}
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new addresses, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantAddressRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByCustomerID retrieves all addresses of a customer of the tenant
+func (r *TenantAddressRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Address, error) {
+	return r.partitions.View(ctx).GetByCustomerID(ctx, customerID)
+}
+
+// GetByID retrieves an address of a customer of the tenant
+func (r *TenantAddressRepository) GetByID(ctx context.Context, customerID, id string) (*model.Address, error) {
+	return r.partitions.View(ctx).GetByID(ctx, customerID, id)
+}
+
+// Create creates an address for the tenant
+func (r *TenantAddressRepository) Create(ctx context.Context, address *model.Address) (*model.Address, error) {
+	return r.partitions.For(ctx).Create(ctx, address)
+}
+
+// Update updates an address of the tenant
+func (r *TenantAddressRepository) Update(ctx context.Context, address *model.Address) (*model.Address, error) {
+	return r.partitions.For(ctx).Update(ctx, address)
+}
+
+// Delete deletes an address of the tenant
+func (r *TenantAddressRepository) Delete(ctx context.Context, customerID, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, customerID, id)
+}
+
+// Reassign moves every address of one customer of the tenant to another
+func (r *TenantAddressRepository) Reassign(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	return r.partitions.For(ctx).Reassign(ctx, fromCustomerID, toCustomerID)
+}
+
+// PurgeExpired reverts the expired writes of every tenant
+func (r *TenantAddressRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset restores the seed data of every tenant
+func (r *TenantAddressRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantHistoryRepository implements HistoryRepository with a separate
+// in-memory repository per tenant
+type TenantHistoryRepository struct {
+	partitions *tenant.Partitions[*MemoryHistoryRepository]
+}
+
+// NewTenantHistoryRepository creates a new tenant-partitioned history repository
+func NewTenantHistoryRepository() *TenantHistoryRepository {
+	return &TenantHistoryRepository{
+		partitions: tenant.NewPartitions(func(string) *MemoryHistoryRepository {
+			return NewMemoryHistoryRepository()
+		}),
+	}
+}
+
+// Append records a history entry for the tenant
+func (r *TenantHistoryRepository) Append(ctx context.Context, entry *model.HistoryEntry) error {
+	return r.partitions.For(ctx).Append(ctx, entry)
+}
+
+// FindByCustomerID retrieves a page of the history of a customer of the tenant
+func (r *TenantHistoryRepository) FindByCustomerID(ctx context.Context, customerID string, page pagination.Params) ([]*model.HistoryEntry, int, error) {
+	return r.partitions.View(ctx).FindByCustomerID(ctx, customerID, page)
+}
+
+// PurgeExpired drops the expired entries of every tenant
+func (r *TenantHistoryRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the history of every tenant
+func (r *TenantHistoryRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantVerificationRepository implements VerificationRepository with a
+// separate in-memory repository per tenant
+type TenantVerificationRepository struct {
+	partitions *tenant.Partitions[*MemoryVerificationRepository]
+}
+
+// NewTenantVerificationRepository creates a new tenant-partitioned verification repository
+func NewTenantVerificationRepository() *TenantVerificationRepository {
+	return &TenantVerificationRepository{
+		partitions: tenant.NewPartitions(func(string) *MemoryVerificationRepository {
+			return NewMemoryVerificationRepository()
+		}),
+	}
+}
+
+// Save stores the verification of a customer of the tenant
+func (r *TenantVerificationRepository) Save(ctx context.Context, verification *model.Verification) error {
+	return r.partitions.For(ctx).Save(ctx, verification)
+}
+
+// GetByCustomerID retrieves the verification of a customer of the tenant
+func (r *TenantVerificationRepository) GetByCustomerID(ctx context.Context, customerID string) (*model.Verification, error) {
+	return r.partitions.View(ctx).GetByCustomerID(ctx, customerID)
+}
+
+// GetByTokenHash retrieves the verification of the tenant whose token has
+// the given hash
+func (r *TenantVerificationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Verification, error) {
+	return r.partitions.View(ctx).GetByTokenHash(ctx, tokenHash)
+}
+
+// Delete removes the verification of a customer of the tenant
+func (r *TenantVerificationRepository) Delete(ctx context.Context, customerID string) error {
+	return r.partitions.For(ctx).Delete(ctx, customerID)
+}
+
+// PurgeExpired drops the expired verifications of every tenant
+func (r *TenantVerificationRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the verifications of every tenant
+func (r *TenantVerificationRepository) Reset() {
+	r.partitions.Reset()
+}
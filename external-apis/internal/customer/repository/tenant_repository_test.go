@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantCustomerRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantCustomerRepository()
+	defaultTenant := context.Background()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	brandB := tenant.WithTenant(context.Background(), "brand-b")
+
+	t.Run("Only the default tenant is seeded", func(t *testing.T) {
+		// Act
+		seeded, err := repo.GetAll(defaultTenant)
+		require.NoError(t, err)
+		empty, err := repo.GetAll(brandA)
+		require.NoError(t, err)
+
+		// Assert
+		assert.NotEmpty(t, seeded)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("Customers are invisible to other tenants", func(t *testing.T) {
+		// Arrange
+		created, err := repo.Create(brandA, &model.Customer{Name: "Brand A Customer", Email: "shared@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		_, errB := repo.GetByID(brandB, created.ID)
+		_, errDefault := repo.GetByEmail(defaultTenant, "shared@example.com")
+		found, total, err := repo.Find(brandA, model.CustomerFilter{Page: pagination.Params{Limit: 10}})
+
+		// Assert
+		assert.ErrorIs(t, errB, model.ErrCustomerNotFound)
+		assert.ErrorIs(t, errDefault, model.ErrCustomerNotFound)
+		assert.False(t, repo.ExistsByID(brandB, created.ID))
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, created.ID, found[0].ID)
+	})
+
+	t.Run("Emails are unique per tenant", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(brandB, &model.Customer{Name: "Brand B Customer", Email: "shared@example.com"})
+		_, duplicate := repo.Create(brandA, &model.Customer{Name: "Another", Email: "shared@example.com"})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, duplicate, model.ErrEmailTaken)
+	})
+
+	t.Run("Deletes stay within the tenant", func(t *testing.T) {
+		// Act
+		err := repo.Delete(brandA, "customer-456")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		assert.True(t, repo.ExistsByID(defaultTenant, "customer-456"))
+	})
+
+	t.Run("Reset clears every tenant back to its seed data", func(t *testing.T) {
+		// Act
+		repo.Reset()
+
+		// Assert
+		customers, err := repo.GetAll(brandA)
+		require.NoError(t, err)
+		assert.Empty(t, customers)
+		assert.True(t, repo.ExistsByID(defaultTenant, "customer-456"))
+	})
+}
+
+func TestTenantAddressRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantAddressRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	seeded, err := repo.GetByCustomerID(context.Background(), "customer-456")
+	require.NoError(t, err)
+	require.NotEmpty(t, seeded)
+
+	// Act
+	addresses, err := repo.GetByCustomerID(brandA, "customer-456")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+}
+
+func TestTenantHistoryRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantHistoryRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	require.NoError(t, repo.Append(brandA, &model.HistoryEntry{CustomerID: "customer-1", Action: model.HistoryCreated}))
+
+	// Act
+	own, ownTotal, err := repo.FindByCustomerID(brandA, "customer-1", pagination.Params{Limit: 10})
+	require.NoError(t, err)
+	other, otherTotal, err := repo.FindByCustomerID(context.Background(), "customer-1", pagination.Params{Limit: 10})
+	require.NoError(t, err)
+
+	// Assert
+	assert.Len(t, own, 1)
+	assert.Equal(t, 1, ownTotal)
+	assert.Empty(t, other)
+	assert.Zero(t, otherTotal)
+	assert.Equal(t, 1, repo.PurgeExpired(time.Now().Add(time.Minute)))
+}
+
+func TestTenantCustomerRepository_SetIDs(t *testing.T) {
+	// Arrange
+	repo := NewTenantCustomerRepository()
+	ids := idgen.Prefixed("cus_", idgen.ULID())
+	repo.SetIDs(ids)
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+
+	// Act
+	created, err := repo.Create(brandA, &model.Customer{Name: "Brand A Customer", Email: "ids@example.com"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ids.Valid(created.ID), created.ID)
+	assert.True(t, repo.ExistsByID(context.Background(), "customer-456"))
+}
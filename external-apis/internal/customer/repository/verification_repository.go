@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"external-apis/internal/customer/model"
+)
+
+// VerificationRepository defines the interface for the outstanding email
+// verifications of pending customers, at most one per customer
+type VerificationRepository interface {
+	// Save stores the verification, replacing the customer's previous one
+	Save(ctx context.Context, verification *model.Verification) error
+	GetByCustomerID(ctx context.Context, customerID string) (*model.Verification, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.Verification, error)
+	// Delete removes the customer's verification. Deleting a missing one is
+	// not an error.
+	Delete(ctx context.Context, customerID string) error
+}
+
+// MemoryVerificationRepository implements VerificationRepository using
+// in-memory storage
+type MemoryVerificationRepository struct {
+	verifications map[string]*model.Verification
+	// tokens maps token hashes to the customer they were sent to
+	tokens map[string]string
+	mutex  sync.RWMutex
+}
+
+// NewMemoryVerificationRepository creates a new in-memory verification repository
+func NewMemoryVerificationRepository() *MemoryVerificationRepository {
+	return &MemoryVerificationRepository{
+		verifications: make(map[string]*model.Verification),
+		tokens:        make(map[string]string),
+	}
+}
+
+// Save stores the verification, replacing the customer's previous one and
+// so invalidating its token
+func (r *MemoryVerificationRepository) Save(ctx context.Context, verification *model.Verification) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deleteUnsafe(verification.CustomerID)
+	stored := *verification
+	r.verifications[stored.CustomerID] = &stored
+	r.tokens[stored.TokenHash] = stored.CustomerID
+	return nil
+}
+
+// GetByCustomerID retrieves the verification of a customer
+func (r *MemoryVerificationRepository) GetByCustomerID(ctx context.Context, customerID string) (*model.Verification, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	verification, exists := r.verifications[customerID]
+	if !exists {
+		return nil, model.ErrVerificationNotFound
+	}
+
+	copied := *verification
+	return &copied, nil
+}
+
+// GetByTokenHash retrieves the verification whose token has the given hash
+func (r *MemoryVerificationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Verification, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	customerID, exists := r.tokens[tokenHash]
+	if !exists {
+		return nil, model.ErrVerificationNotFound
+	}
+
+	copied := *r.verifications[customerID]
+	return &copied, nil
+}
+
+// Delete removes the verification of a customer
+func (r *MemoryVerificationRepository) Delete(ctx context.Context, customerID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deleteUnsafe(customerID)
+	return nil
+}
+
+// PurgeExpired removes the verifications sent before cutoff
+func (r *MemoryVerificationRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for customerID, verification := range r.verifications {
+		if verification.SentAt.Before(cutoff) {
+			r.deleteUnsafe(customerID)
+			purged++
+		}
+	}
+	return purged
+}
+
+// Reset removes every verification
+func (r *MemoryVerificationRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.verifications = make(map[string]*model.Verification)
+	r.tokens = make(map[string]string)
+}
+
+// deleteUnsafe removes the verification of a customer and its token. The
+// caller must hold the write lock.
+func (r *MemoryVerificationRepository) deleteUnsafe(customerID string) {
+	if previous, exists := r.verifications[customerID]; exists {
+		delete(r.tokens, previous.TokenHash)
+		delete(r.verifications, customerID)
+	}
+}
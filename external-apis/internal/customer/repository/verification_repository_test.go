@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryVerificationRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	t.Run("Find by customer and token", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryVerificationRepository()
+		require.NoError(t, repo.Save(ctx, &model.Verification{CustomerID: "customer-123", TokenHash: "hash-1", SentAt: now}))
+
+		// Act
+		byCustomer, errCustomer := repo.GetByCustomerID(ctx, "customer-123")
+		byToken, errToken := repo.GetByTokenHash(ctx, "hash-1")
+
+		// Assert
+		require.NoError(t, errCustomer)
+		require.NoError(t, errToken)
+		assert.Equal(t, "hash-1", byCustomer.TokenHash)
+		assert.Equal(t, "customer-123", byToken.CustomerID)
+	})
+
+	t.Run("Saving again invalidates the previous token", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryVerificationRepository()
+		require.NoError(t, repo.Save(ctx, &model.Verification{CustomerID: "customer-123", TokenHash: "hash-1", SentAt: now}))
+
+		// Act
+		require.NoError(t, repo.Save(ctx, &model.Verification{CustomerID: "customer-123", TokenHash: "hash-2", SentAt: now}))
+
+		// Assert
+		_, err := repo.GetByTokenHash(ctx, "hash-1")
+		assert.ErrorIs(t, err, model.ErrVerificationNotFound)
+		verification, err := repo.GetByTokenHash(ctx, "hash-2")
+		require.NoError(t, err)
+		assert.Equal(t, "customer-123", verification.CustomerID)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryVerificationRepository()
+		require.NoError(t, repo.Save(ctx, &model.Verification{CustomerID: "customer-123", TokenHash: "hash-1", SentAt: now}))
+
+		// Act
+		require.NoError(t, repo.Delete(ctx, "customer-123"))
+
+		// Assert
+		_, err := repo.GetByCustomerID(ctx, "customer-123")
+		assert.ErrorIs(t, err, model.ErrVerificationNotFound)
+		_, err = repo.GetByTokenHash(ctx, "hash-1")
+		assert.ErrorIs(t, err, model.ErrVerificationNotFound)
+		assert.NoError(t, repo.Delete(ctx, "customer-123"))
+	})
+
+	t.Run("Purge expired", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryVerificationRepository()
+		require.NoError(t, repo.Save(ctx, &model.Verification{CustomerID: "customer-123", TokenHash: "hash-1", SentAt: now.Add(-2 * time.Hour)}))
+		require.NoError(t, repo.Save(ctx, &model.Verification{CustomerID: "customer-456", TokenHash: "hash-2", SentAt: now}))
+
+		// Act
+		purged := repo.PurgeExpired(now.Add(-time.Hour))
+
+		// Assert
+		assert.Equal(t, 1, purged)
+		_, err := repo.GetByTokenHash(ctx, "hash-1")
+		assert.ErrorIs(t, err, model.ErrVerificationNotFound)
+		_, err = repo.GetByTokenHash(ctx, "hash-2")
+		assert.NoError(t, err)
+	})
+}
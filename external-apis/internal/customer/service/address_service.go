@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/repository"
+	"external-apis/internal/shared/logger"
+)
+
+// AddressService defines the interface for customer address book logic
+type AddressService interface {
+	GetAddresses(ctx context.Context, customerID string) ([]*model.AddressResponse, error)
+	GetAddress(ctx context.Context, customerID, id string) (*model.AddressResponse, error)
+	CreateAddress(ctx context.Context, customerID string, req model.CreateAddressRequest) (*model.AddressResponse, error)
+	UpdateAddress(ctx context.Context, customerID, id string, req model.UpdateAddressRequest) (*model.AddressResponse, error)
+	DeleteAddress(ctx context.Context, customerID, id string) error
+}
+
+// addressService implements AddressService
+type addressService struct {
+	repo      repository.AddressRepository
+	customers repository.CustomerRepository
+}
+
+// NewAddressService creates a new address service
+func NewAddressService(repo repository.AddressRepository, customers repository.CustomerRepository) AddressService {
+	return &addressService{
+		repo:      repo,
+		customers: customers,
+	}
+}
+
+// GetAddresses retrieves all addresses of a customer
+func (s *addressService) GetAddresses(ctx context.Context, customerID string) ([]*model.AddressResponse, error) {
+	log.Ctx(ctx).WithField("customer_id", customerID).Debug("Getting customer addresses")
+
+	if !s.customers.ExistsByID(ctx, customerID) {
+		return nil, model.ErrCustomerNotFound
+	}
+
+	addresses, err := s.repo.GetByCustomerID(ctx, customerID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customerID).Error("Failed to get customer addresses")
+		return nil, err
+	}
+
+	responses := make([]*model.AddressResponse, len(addresses))
+	for i, address := range addresses {
+		response := address.ToResponse()
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+// GetAddress retrieves a single address of a customer
+func (s *addressService) GetAddress(ctx context.Context, customerID, id string) (*model.AddressResponse, error) {
+	if !s.customers.ExistsByID(ctx, customerID) {
+		return nil, model.ErrCustomerNotFound
+	}
+
+	address, err := s.repo.GetByID(ctx, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := address.ToResponse()
+	return &response, nil
+}
+
+// CreateAddress adds an address to a customer's address book
+func (s *addressService) CreateAddress(ctx context.Context, customerID string, req model.CreateAddressRequest) (*model.AddressResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"type":        req.Type,
+		"country":     req.Country,
+	}).Debug("Creating customer address")
+
+	if !s.customers.ExistsByID(ctx, customerID) {
+		return nil, model.ErrCustomerNotFound
+	}
+
+	address := &model.Address{
+		CustomerID: customerID,
+		Type:       req.Type,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		Region:     req.Region,
+		PostalCode: req.PostalCode,
+		Country:    req.Country,
+		IsDefault:  req.IsDefault,
+	}
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	createdAddress, err := s.repo.Create(ctx, address)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customerID).Error("Failed to create customer address")
+		return nil, err
+	}
+
+	response := createdAddress.ToResponse()
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"address_id":  createdAddress.ID,
+	}).Info("Successfully created customer address")
+
+	return &response, nil
+}
+
+// UpdateAddress updates an address in a customer's address book
+func (s *addressService) UpdateAddress(ctx context.Context, customerID, id string, req model.UpdateAddressRequest) (*model.AddressResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"address_id":  id,
+	}).Debug("Updating customer address")
+
+	if !s.customers.ExistsByID(ctx, customerID) {
+		return nil, model.ErrCustomerNotFound
+	}
+
+	address, err := s.repo.GetByID(ctx, customerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update fields if provided
+	if req.Type != nil {
+		address.Type = *req.Type
+	}
+	if req.Line1 != nil {
+		address.Line1 = *req.Line1
+	}
+	if req.Line2 != nil {
+		address.Line2 = *req.Line2
+	}
+	if req.City != nil {
+		address.City = *req.City
+	}
+	if req.Region != nil {
+		address.Region = *req.Region
+	}
+	if req.PostalCode != nil {
+		address.PostalCode = *req.PostalCode
+	}
+	if req.Country != nil {
+		address.Country = *req.Country
+	}
+	if req.IsDefault != nil {
+		address.IsDefault = *req.IsDefault
+	}
+
+	if err := validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	updatedAddress, err := s.repo.Update(ctx, address)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("address_id", id).Error("Failed to update customer address")
+		return nil, err
+	}
+
+	response := updatedAddress.ToResponse()
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"address_id":  id,
+	}).Info("Successfully updated customer address")
+
+	return &response, nil
+}
+
+// DeleteAddress removes an address from a customer's address book
+func (s *addressService) DeleteAddress(ctx context.Context, customerID, id string) error {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"address_id":  id,
+	}).Debug("Deleting customer address")
+
+	if !s.customers.ExistsByID(ctx, customerID) {
+		return model.ErrCustomerNotFound
+	}
+
+	if err := s.repo.Delete(ctx, customerID, id); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"address_id":  id,
+	}).Info("Successfully deleted customer address")
+	return nil
+}
+
+// validateAddress normalizes an address and checks its type, required lines,
+// country code and postal code format
+func validateAddress(address *model.Address) error {
+	address.Normalize()
+
+	if !address.Type.IsValid() {
+		return model.ErrInvalidAddressType
+	}
+	if address.Line1 == "" || address.City == "" {
+		return model.ErrAddressLinesMissing
+	}
+	if !isValidCountry(address.Country) {
+		return model.ErrInvalidCountry
+	}
+	if !isValidPostalCode(address.Country, address.PostalCode) {
+		return model.ErrInvalidPostalCode
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/customer/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAddressRepository is a mock implementation of AddressRepository
+type MockAddressRepository struct {
+	mock.Mock
+}
+
+func (m *MockAddressRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Address, error) {
+	args := m.Called(customerID)
+	return args.Get(0).([]*model.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) GetByID(ctx context.Context, customerID, id string) (*model.Address, error) {
+	args := m.Called(customerID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) Create(ctx context.Context, address *model.Address) (*model.Address, error) {
+	args := m.Called(address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) Update(ctx context.Context, address *model.Address) (*model.Address, error) {
+	args := m.Called(address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Address), args.Error(1)
+}
+
+func (m *MockAddressRepository) Delete(ctx context.Context, customerID, id string) error {
+	args := m.Called(customerID, id)
+	return args.Error(0)
+}
+
+func (m *MockAddressRepository) Reassign(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	args := m.Called(fromCustomerID, toCustomerID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestAddressService_GetAddresses(t *testing.T) {
+	t.Run("Existing customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockAddressRepository)
+		mockCustomers := new(MockCustomerRepository)
+		service := NewAddressService(mockRepo, mockCustomers)
+
+		mockCustomers.On("ExistsByID", "customer-123").Return(true)
+		mockRepo.On("GetByCustomerID", "customer-123").Return([]*model.Address{
+			{ID: "address-1", CustomerID: "customer-123", Type: model.AddressTypeShipping, IsDefault: true},
+		}, nil)
+
+		// Act
+		result, err := service.GetAddresses(context.Background(), "customer-123")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, "address-1", result[0].ID)
+		assert.True(t, result[0].IsDefault)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Non-existing customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockAddressRepository)
+		mockCustomers := new(MockCustomerRepository)
+		service := NewAddressService(mockRepo, mockCustomers)
+
+		mockCustomers.On("ExistsByID", "missing").Return(false)
+
+		// Act
+		result, err := service.GetAddresses(context.Background(), "missing")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "customer not found", err.Error())
+		mockRepo.AssertNotCalled(t, "GetByCustomerID", mock.Anything)
+	})
+}
+
+func TestAddressService_CreateAddress(t *testing.T) {
+	t.Run("Create valid address", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockAddressRepository)
+		mockCustomers := new(MockCustomerRepository)
+		service := NewAddressService(mockRepo, mockCustomers)
+
+		request := model.CreateAddressRequest{
+			Type:       model.AddressTypeShipping,
+			Line1:      " 10 Downing Street ",
+			City:       "London",
+			PostalCode: "sw1a 2aa",
+			Country:    "gb",
+		}
+
+		mockCustomers.On("ExistsByID", "customer-123").Return(true)
+		mockRepo.On("Create", mock.MatchedBy(func(a *model.Address) bool {
+			return a.CustomerID == "customer-123" && a.Line1 == "10 Downing Street" &&
+				a.PostalCode == "SW1A 2AA" && a.Country == "GB"
+		})).Return(&model.Address{
+			ID:         "address-1",
+			CustomerID: "customer-123",
+			Type:       model.AddressTypeShipping,
+			PostalCode: "SW1A 2AA",
+			Country:    "GB",
+			IsDefault:  true,
+		}, nil)
+
+		// Act
+		result, err := service.CreateAddress(context.Background(), "customer-123", request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "address-1", result.ID)
+		assert.True(t, result.IsDefault)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name        string
+		request     model.CreateAddressRequest
+		expectedErr string
+	}{
+		{
+			name:        "Invalid type",
+			request:     model.CreateAddressRequest{Type: "HOME", Line1: "1 Main St", City: "Austin", PostalCode: "73301", Country: "US"},
+			expectedErr: "invalid address type",
+		},
+		{
+			name:        "Unknown country",
+			request:     model.CreateAddressRequest{Type: model.AddressTypeBilling, Line1: "1 Main St", City: "Austin", PostalCode: "73301", Country: "XX"},
+			expectedErr: "invalid country code",
+		},
+		{
+			name:        "Postal code in wrong format",
+			request:     model.CreateAddressRequest{Type: model.AddressTypeBilling, Line1: "1 Main St", City: "Austin", PostalCode: "7330", Country: "US"},
+			expectedErr: "invalid postal code",
+		},
+		{
+			name:        "Missing postal code",
+			request:     model.CreateAddressRequest{Type: model.AddressTypeBilling, Line1: "1 Main St", City: "Berlin", Country: "DE"},
+			expectedErr: "invalid postal code",
+		},
+		{
+			name:        "Blank line1",
+			request:     model.CreateAddressRequest{Type: model.AddressTypeBilling, Line1: "  ", City: "Berlin", PostalCode: "10115", Country: "DE"},
+			expectedErr: "line1 and city are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockAddressRepository)
+			mockCustomers := new(MockCustomerRepository)
+			service := NewAddressService(mockRepo, mockCustomers)
+
+			mockCustomers.On("ExistsByID", "customer-123").Return(true)
+
+			// Act
+			result, err := service.CreateAddress(context.Background(), "customer-123", tt.request)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.Equal(t, tt.expectedErr, err.Error())
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+}
+
+func TestAddressService_UpdateAddress(t *testing.T) {
+	t.Run("Update existing address", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockAddressRepository)
+		mockCustomers := new(MockCustomerRepository)
+		service := NewAddressService(mockRepo, mockCustomers)
+
+		existing := &model.Address{
+			ID:         "address-1",
+			CustomerID: "customer-123",
+			Type:       model.AddressTypeShipping,
+			Line1:      "1 Main St",
+			City:       "Austin",
+			PostalCode: "73301",
+			Country:    "US",
+		}
+		city := "Toronto"
+		postalCode := "M5V 2T6"
+		country := "CA"
+
+		mockCustomers.On("ExistsByID", "customer-123").Return(true)
+		mockRepo.On("GetByID", "customer-123", "address-1").Return(existing, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(a *model.Address) bool {
+			return a.City == "Toronto" && a.Country == "CA"
+		})).Return(existing, nil)
+
+		// Act
+		result, err := service.UpdateAddress(context.Background(), "customer-123", "address-1", model.UpdateAddressRequest{
+			City:       &city,
+			PostalCode: &postalCode,
+			Country:    &country,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "address-1", result.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update non-existing address", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockAddressRepository)
+		mockCustomers := new(MockCustomerRepository)
+		service := NewAddressService(mockRepo, mockCustomers)
+
+		mockCustomers.On("ExistsByID", "customer-123").Return(true)
+		mockRepo.On("GetByID", "customer-123", "missing").Return(nil, model.ErrAddressNotFound)
+
+		// Act
+		result, err := service.UpdateAddress(context.Background(), "customer-123", "missing", model.UpdateAddressRequest{})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "address not found", err.Error())
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestAddressService_DeleteAddress(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAddressRepository)
+	mockCustomers := new(MockCustomerRepository)
+	service := NewAddressService(mockRepo, mockCustomers)
+
+	mockCustomers.On("ExistsByID", "customer-123").Return(true)
+	mockRepo.On("Delete", "customer-123", "address-1").Return(nil)
+
+	// Act
+	err := service.DeleteAddress(context.Background(), "customer-123", "address-1")
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestIsValidPostalCode(t *testing.T) {
+	tests := []struct {
+		country    string
+		postalCode string
+		expected   bool
+	}{
+		{"US", "94105", true},
+		{"US", "94105-1234", true},
+		{"US", "9410", false},
+		{"CA", "K1A 0B1", true},
+		{"GB", "EC1A 1BB", true},
+		{"NL", "1012 AB", true},
+		{"BR", "01310-100", true},
+		{"SE", "114 55", true},
+		{"HK", "", true},
+		{"SE", "", false},
+		{"SE", "!!", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country+" "+tt.postalCode, func(t *testing.T) {
+			// Act
+			result := isValidPostalCode(tt.country, tt.postalCode)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
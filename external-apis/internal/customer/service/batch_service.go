@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/logger"
+)
+
+// BatchGetCustomers retrieves several customers in one lookup. Every distinct ID
+// is reported, in the order it was requested, as found or not found.
+func (s *customerService) BatchGetCustomers(ctx context.Context, ids []string) (*batch.Response, error) {
+	ids = batch.UniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, batch.ErrIDsRequired
+	}
+
+	log.Ctx(ctx).WithField("ids", len(ids)).Debug("Batch getting customers")
+
+	customers, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to batch get customers")
+		return nil, err
+	}
+
+	found := make(map[string]interface{}, len(customers))
+	for _, customer := range customers {
+		response := customer.ToResponse()
+		found[customer.ID] = &response
+	}
+
+	if err := s.resolveAliases(ctx, ids, found); err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to batch get merged customers")
+		return nil, err
+	}
+
+	report := &batch.Response{Results: make([]batch.Result, 0, len(ids))}
+	for _, id := range ids {
+		if data, ok := found[id]; ok {
+			report.Found(id, data)
+		} else {
+			report.NotFound(id)
+		}
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"ids":   len(ids),
+		"found": len(found),
+	}).Debug("Successfully batch got customers")
+
+	return report, nil
+}
+
+// resolveAliases adds to found the customers that IDs of merged customers
+// among the missing ids refer to, reported under the requested ID
+func (s *customerService) resolveAliases(ctx context.Context, ids []string, found map[string]interface{}) error {
+	aliases := make(map[string]string)
+	var survivorIDs []string
+	for _, id := range ids {
+		if _, ok := found[id]; ok {
+			continue
+		}
+		if survivorID, ok := s.repo.ResolveAlias(ctx, id); ok {
+			aliases[id] = survivorID
+			survivorIDs = append(survivorIDs, survivorID)
+		}
+	}
+	if len(aliases) == 0 {
+		return nil
+	}
+
+	survivors, err := s.repo.GetByIDs(ctx, batch.UniqueIDs(survivorIDs))
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*model.Customer, len(survivors))
+	for _, survivor := range survivors {
+		byID[survivor.ID] = survivor
+	}
+	for id, survivorID := range aliases {
+		if survivor, ok := byID[survivorID]; ok {
+			response := survivor.ToResponse()
+			found[id] = &response
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/repository"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/events"
+)
+
+// BulkCustomers applies a batch of operations in a single transaction. Every
+// operation is attempted so the report covers the whole batch; if any of them
+// fails nothing is persisted and the successful ones are reported as rolled back.
+func (s *customerService) BulkCustomers(ctx context.Context, req model.BulkCustomerRequest) (*bulk.Response, error) {
+	log.Ctx(ctx).WithField("operations", len(req.Operations)).Debug("Applying bulk customer operations")
+
+	report := &bulk.Response{Results: make([]bulk.Result, 0, len(req.Operations))}
+	history := make([]*model.HistoryEntry, 0, len(req.Operations))
+
+	err := s.repo.Transaction(ctx, func(tx repository.CustomerRepository) error {
+		// Customers created in bulk are imported, so they are active without
+		// verifying their email
+		txService := &customerService{repo: tx, pending: &history}
+
+		for i, op := range req.Operations {
+			// Stop early if the caller gave up; nothing will be committed
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			customer, err := txService.applyBulkOperation(ctx, op)
+			if err != nil {
+				report.Failed(i, op.Op, op.ID, err)
+				continue
+			}
+
+			if customer == nil {
+				report.Succeeded(i, op.Op, op.ID, nil)
+				continue
+			}
+			report.Succeeded(i, op.Op, customer.ID, customer)
+		}
+
+		if report.HasFailures() {
+			return bulk.ErrRolledBack
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, bulk.ErrRolledBack) {
+			report.RollBack()
+			log.Ctx(ctx).WithField("operations", len(req.Operations)).Info("Rolled back bulk customer operations")
+			return report, nil
+		}
+
+		log.Ctx(ctx).WithError(err).Error("Failed to apply bulk customer operations")
+		return nil, err
+	}
+
+	report.Committed = true
+	s.recordBulk(ctx, history)
+	s.publishBulk(ctx, report)
+	log.Ctx(ctx).WithField("operations", len(req.Operations)).Info("Successfully applied bulk customer operations")
+
+	return report, nil
+}
+
+// applyBulkOperation runs a single bulk operation. Deletes return no customer.
+func (s *customerService) applyBulkOperation(ctx context.Context, op model.BulkCustomerOperation) (*model.CustomerResponse, error) {
+	switch op.Op {
+	case bulk.OpCreate:
+		if op.Create == nil {
+			return nil, bulk.ErrCreatePayloadRequired
+		}
+		return s.CreateCustomer(ctx, *op.Create)
+	case bulk.OpUpdate:
+		if op.ID == "" || op.Update == nil {
+			return nil, bulk.ErrUpdatePayloadRequired
+		}
+		return s.UpdateCustomer(ctx, op.ID, *op.Update)
+	case bulk.OpDelete:
+		if op.ID == "" {
+			return nil, bulk.ErrIDRequired
+		}
+		return nil, s.DeleteCustomer(ctx, op.ID)
+	}
+
+	return nil, bulk.ErrInvalidOperation
+}
+
+// recordBulk appends the history of a committed bulk request. Like events,
+// history is only recorded for changes that were not rolled back.
+func (s *customerService) recordBulk(ctx context.Context, history []*model.HistoryEntry) {
+	for _, entry := range history {
+		if err := s.history.Append(ctx, entry); err != nil {
+			log.Ctx(ctx).WithError(err).WithField("customer_id", entry.CustomerID).Error("Failed to record customer history")
+		}
+	}
+}
+
+// publishBulk sends change events for a committed bulk request. Events are
+// only published after the transaction commits so subscribers never see
+// changes that were rolled back.
+func (s *customerService) publishBulk(ctx context.Context, report *bulk.Response) {
+	for _, result := range report.Results {
+		switch result.Op {
+		case bulk.OpCreate:
+			s.publish(ctx, events.CustomerCreated, result.ID, result.Data)
+		case bulk.OpUpdate:
+			s.publish(ctx, events.CustomerUpdated, result.ID, result.Data)
+		case bulk.OpDelete:
+			s.publish(ctx, events.CustomerDeleted, result.ID, map[string]string{"id": result.ID})
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+)
+
+// ExportCustomers passes every customer matching the filter to fn, in the
+// order of the filter's sort. Customers are read a page at a time so the
+// export never holds them all; the page of the filter is ignored. It stops
+// at the first error fn returns.
+func (s *customerService) ExportCustomers(ctx context.Context, filter model.CustomerFilter, fn func(*model.CustomerResponse) error) error {
+	log.Ctx(ctx).WithField("sort", filter.Sort).Debug("Exporting customers")
+
+	if !model.IsValidCustomerSort(filter.Sort) {
+		return model.ErrInvalidSort
+	}
+
+	exported := 0
+	filter.Page = pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		customers, total, err := s.repo.Find(ctx, filter)
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithField("exported", exported).Error("Failed to export customers")
+			return err
+		}
+
+		for _, customer := range customers {
+			response := customer.ToResponse()
+			if err := fn(&response); err != nil {
+				return err
+			}
+		}
+		exported += len(customers)
+
+		filter.Page.Offset += filter.Page.Limit
+		if len(customers) == 0 || filter.Page.Offset >= total {
+			break
+		}
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"exported": exported,
+	}).Debug("Successfully exported customers")
+	return nil
+}
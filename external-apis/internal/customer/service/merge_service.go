@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/repository"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+)
+
+// MergeService defines the interface for merging duplicate customers
+type MergeService interface {
+	MergeCustomers(ctx context.Context, req model.MergeCustomersRequest) (*model.MergeResponse, error)
+	FindDuplicates(ctx context.Context, minScore float64, page pagination.Params) ([]model.DuplicatePair, pagination.Meta, error)
+}
+
+// mergeService implements MergeService
+type mergeService struct {
+	customers *customerService
+	addresses repository.AddressRepository
+}
+
+// NewMergeService creates a new merge service. Merges are recorded in
+// history and published to events, which may be nil.
+func NewMergeService(repo repository.CustomerRepository, addresses repository.AddressRepository, history repository.HistoryRepository, events events.Publisher) MergeService {
+	return &mergeService{
+		customers: &customerService{repo: repo, history: history, events: events},
+		addresses: addresses,
+	}
+}
+
+// MergeCustomers merges a duplicate customer into the survivor. The merged
+// customer is deleted, its addresses move to the survivor and its ID stays
+// an alias of the survivor, so existing references keep resolving. Orders
+// are repointed by the order service on the customer.merged event.
+func (s *mergeService) MergeCustomers(ctx context.Context, req model.MergeCustomersRequest) (*model.MergeResponse, error) {
+	fields := logger.Fields{
+		"survivor_id": req.SurvivorID,
+		"merged_id":   req.MergedID,
+	}
+	log.Ctx(ctx).WithFields(fields).Debug("Merging customers")
+
+	survivor, err := s.customers.getByIDOrAlias(ctx, req.SurvivorID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := s.customers.repo.GetByID(ctx, req.MergedID)
+	if err != nil {
+		return nil, err
+	}
+	if survivor.ID == merged.ID {
+		return nil, model.ErrMergeSameCustomer
+	}
+
+	before := *survivor
+	result := model.Merge(&before, merged, req.Take)
+
+	var updated *model.Customer
+	err = s.customers.repo.Transaction(ctx, func(tx repository.CustomerRepository) error {
+		// Deleting first frees the email for the survivor to take
+		if err := tx.Delete(ctx, merged.ID); err != nil {
+			return err
+		}
+		if updated, err = tx.Update(ctx, survivor.ID, result); err != nil {
+			return err
+		}
+		return tx.AddAlias(ctx, merged.ID, survivor.ID)
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to merge customers")
+		return nil, err
+	}
+
+	moved, err := s.addresses.Reassign(ctx, merged.ID, survivor.ID)
+	if err != nil {
+		// The customers are merged; the addresses can be moved by merging again
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to move addresses of merged customer")
+		return nil, err
+	}
+
+	s.customers.record(ctx, model.HistoryMerged, survivor.ID, model.DiffCustomers(&before, updated))
+	s.customers.record(ctx, model.HistoryMerged, merged.ID, nil)
+
+	response := updated.ToResponse()
+	s.customers.publish(ctx, events.CustomerUpdated, response.ID, response)
+	s.customers.publish(ctx, events.CustomerMerged, merged.ID, map[string]string{
+		"survivorId": survivor.ID,
+		"mergedId":   merged.ID,
+	})
+
+	log.Ctx(ctx).WithFields(fields).WithField("addresses_moved", moved).Info("Successfully merged customers")
+	return &model.MergeResponse{
+		Customer:       response,
+		MergedID:       merged.ID,
+		AddressesMoved: moved,
+	}, nil
+}
+
+// FindDuplicates retrieves a page of the customer pairs scoring at least
+// minScore as likely duplicates, most likely first
+func (s *mergeService) FindDuplicates(ctx context.Context, minScore float64, page pagination.Params) ([]model.DuplicatePair, pagination.Meta, error) {
+	log.Ctx(ctx).WithField("min_score", minScore).Debug("Finding duplicate customers")
+
+	if minScore < 0 || minScore > 1 {
+		return nil, pagination.Meta{}, model.ErrInvalidMinScore
+	}
+
+	customers, err := s.customers.repo.GetAll(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get customers for duplicate detection")
+		return nil, pagination.Meta{}, err
+	}
+
+	pairs := make([]model.DuplicatePair, 0)
+	for i, a := range customers {
+		for _, b := range customers[i+1:] {
+			score, matches := model.CompareCustomers(a, b)
+			if len(matches) == 0 || score < minScore {
+				continue
+			}
+
+			first, second := a, b
+			if second.ID < first.ID {
+				first, second = second, first
+			}
+			pairs = append(pairs, model.DuplicatePair{
+				Customers: []model.CustomerResponse{first.ToResponse(), second.ToResponse()},
+				Score:     score,
+				Matches:   matches,
+			})
+		}
+	}
+	model.SortDuplicates(pairs)
+
+	total := len(pairs)
+	start, end := page.Bounds(total)
+	log.Ctx(ctx).WithField("pairs", total).Debug("Found duplicate customers")
+
+	return pairs[start:end], page.Meta(total), nil
+}
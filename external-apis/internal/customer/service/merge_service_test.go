@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeService_MergeCustomers(t *testing.T) {
+	survivor := func() *model.Customer {
+		return &model.Customer{ID: "customer-456", Name: "John Doe", Email: "john.doe@example.com", Active: true, Status: model.StatusActive}
+	}
+	merged := func() *model.Customer {
+		return &model.Customer{ID: "customer-001", Name: "Johnny Doe", Email: "johnny@example.com", Phone: "+15550123", Active: true, Status: model.StatusActive}
+	}
+
+	t.Run("Merge customers", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		mockAddresses := new(MockAddressRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewMergeService(mockRepo, mockAddresses, newMockHistory(), publisher)
+
+		mockRepo.On("GetByID", "customer-456").Return(survivor(), nil)
+		mockRepo.On("GetByID", "customer-001").Return(merged(), nil)
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Delete", "customer-001").Return(nil)
+		updated := survivor()
+		updated.Email, updated.Phone = "johnny@example.com", "+15550123"
+		mockRepo.On("Update", "customer-456", mock.MatchedBy(func(c *model.Customer) bool {
+			return c.Email == updated.Email && c.Phone == updated.Phone && c.Name == updated.Name
+		})).Return(updated, nil)
+		mockRepo.On("AddAlias", "customer-001", "customer-456").Return(nil)
+		mockAddresses.On("Reassign", "customer-001", "customer-456").Return(1, nil)
+
+		// Act
+		result, err := service.MergeCustomers(context.Background(), model.MergeCustomersRequest{
+			SurvivorID: "customer-456",
+			MergedID:   "customer-001",
+			Take:       []string{model.MergeFieldEmail},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "customer-001", result.MergedID)
+		assert.Equal(t, "johnny@example.com", result.Customer.Email)
+		assert.Equal(t, 1, result.AddressesMoved)
+		assert.Equal(t, []string{events.CustomerUpdated, events.CustomerMerged}, publisher.Types())
+		mockRepo.AssertExpectations(t)
+		mockAddresses.AssertExpectations(t)
+	})
+
+	t.Run("Merged customer not found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewMergeService(mockRepo, new(MockAddressRepository), newMockHistory(), nil)
+
+		mockRepo.On("GetByID", "customer-456").Return(survivor(), nil)
+		mockRepo.On("GetByID", "customer-999").Return(nil, model.ErrCustomerNotFound)
+
+		// Act
+		result, err := service.MergeCustomers(context.Background(), model.MergeCustomersRequest{SurvivorID: "customer-456", MergedID: "customer-999"})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		mockRepo.AssertNotCalled(t, "Transaction")
+	})
+
+	t.Run("Survivor already merged into the other customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewMergeService(mockRepo, new(MockAddressRepository), newMockHistory(), nil)
+
+		mockRepo.On("GetByID", "customer-123").Return(nil, model.ErrCustomerNotFound)
+		mockRepo.On("ResolveAlias", "customer-123").Return("customer-456", true)
+		mockRepo.On("GetByID", "customer-456").Return(survivor(), nil)
+
+		// Act
+		_, err := service.MergeCustomers(context.Background(), model.MergeCustomersRequest{SurvivorID: "customer-123", MergedID: "customer-456"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrMergeSameCustomer)
+	})
+}
+
+func TestMergeService_FindDuplicates(t *testing.T) {
+	customers := []*model.Customer{
+		{ID: "customer-3", Name: "John Doe", Email: "john.doe@example.com"},
+		{ID: "customer-2", Name: "Alice Brown", Email: "alice@example.com"},
+		{ID: "customer-1", Name: "Jon Doe", Email: "John.Doe+shop@example.com"},
+	}
+
+	t.Run("Report likely duplicates", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewMergeService(mockRepo, new(MockAddressRepository), newMockHistory(), nil)
+		mockRepo.On("GetAll").Return(customers, nil)
+
+		// Act
+		pairs, meta, err := service.FindDuplicates(context.Background(), model.DefaultDuplicateScore, pagination.DefaultParams())
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, pairs, 1)
+		assert.Equal(t, 1, meta.Total)
+		assert.Equal(t, "customer-1", pairs[0].Customers[0].ID)
+		assert.Equal(t, "customer-3", pairs[0].Customers[1].ID)
+		assert.Equal(t, 0.99, pairs[0].Score)
+	})
+
+	t.Run("Invalid minimum score", func(t *testing.T) {
+		// Arrange
+		service := NewMergeService(new(MockCustomerRepository), new(MockAddressRepository), newMockHistory(), nil)
+
+		// Act
+		_, _, err := service.FindDuplicates(context.Background(), 1.5, pagination.DefaultParams())
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidMinScore)
+	})
+}
@@ -1,60 +1,98 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"regexp"
+	"strings"
+	"time"
 
 	"external-apis/internal/customer/model"
 	"external-apis/internal/customer/repository"
-	"github.com/sirupsen/logrus"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/patch"
+	"external-apis/internal/shared/phone"
 )
 
+var log = logger.New("customer/service")
+
 // CustomerService defines the interface for customer business logic
 type CustomerService interface {
-	GetCustomerByID(id string) (*model.CustomerResponse, error)
-	GetAllCustomers() ([]*model.CustomerResponse, error)
-	CreateCustomer(req model.CreateCustomerRequest) (*model.CustomerResponse, error)
-	UpdateCustomer(id string, req model.UpdateCustomerRequest) (*model.CustomerResponse, error)
-	DeleteCustomer(id string) error
-	CustomerExists(id string) bool
-	GetCustomerByEmail(email string) (*model.CustomerResponse, error)
+	GetCustomerByID(ctx context.Context, id string) (*model.CustomerResponse, error)
+	GetAllCustomers(ctx context.Context) ([]*model.CustomerResponse, error)
+	BatchGetCustomers(ctx context.Context, ids []string) (*batch.Response, error)
+	ListCustomers(ctx context.Context, filter model.CustomerFilter) ([]*model.CustomerResponse, pagination.Meta, error)
+	SearchCustomers(ctx context.Context, filter model.CustomerFilter) ([]*model.CustomerResponse, pagination.Meta, error)
+	ExportCustomers(ctx context.Context, filter model.CustomerFilter, fn func(*model.CustomerResponse) error) error
+	CreateCustomer(ctx context.Context, req model.CreateCustomerRequest) (*model.CustomerResponse, error)
+	UpdateCustomer(ctx context.Context, id string, req model.UpdateCustomerRequest) (*model.CustomerResponse, error)
+	PatchCustomer(ctx context.Context, id string, p patch.Patch) (*model.CustomerResponse, error)
+	DeleteCustomer(ctx context.Context, id string) error
+	RestoreCustomer(ctx context.Context, id string) (*model.CustomerResponse, error)
+	ChangeCustomerStatus(ctx context.Context, id string, req model.ChangeStatusRequest) (*model.CustomerResponse, error)
+	CustomerExists(ctx context.Context, id string) bool
+	GetCustomerByEmail(ctx context.Context, email string) (*model.CustomerResponse, error)
+	BulkCustomers(ctx context.Context, req model.BulkCustomerRequest) (*bulk.Response, error)
+	GetCustomerHistory(ctx context.Context, id string, page pagination.Params) ([]*model.HistoryEntry, pagination.Meta, error)
+	GetCustomerStats(ctx context.Context) (*model.CustomerStats, error)
+}
+
+// Verifier starts the email verification of new customers
+type Verifier interface {
+	StartVerification(ctx context.Context, customer *model.Customer) error
 }
 
 // customerService implements CustomerService
 type customerService struct {
-	repo repository.CustomerRepository
+	repo     repository.CustomerRepository
+	history  repository.HistoryRepository
+	events   events.Publisher
+	verifier Verifier
+	// pending collects the history of an uncommitted bulk transaction
+	pending *[]*model.HistoryEntry
 }
 
-// NewCustomerService creates a new customer service
-func NewCustomerService(repo repository.CustomerRepository) CustomerService {
+// NewCustomerService creates a new customer service. Every change is recorded
+// in history. Change events go to events, which may be nil. New customers
+// stay pending until verifier confirms their email; without a verifier they
+// are active right away.
+func NewCustomerService(repo repository.CustomerRepository, history repository.HistoryRepository, events events.Publisher, verifier Verifier) CustomerService {
 	return &customerService{
-		repo: repo,
+		repo:     repo,
+		history:  history,
+		events:   events,
+		verifier: verifier,
 	}
 }
 
 // GetCustomerByID retrieves a customer by ID
-func (s *customerService) GetCustomerByID(id string) (*model.CustomerResponse, error) {
-	logrus.WithField("customer_id", id).Debug("Getting customer by ID")
+func (s *customerService) GetCustomerByID(ctx context.Context, id string) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Getting customer by ID")
 
-	customer, err := s.repo.GetByID(id)
+	customer, err := s.getByIDOrAlias(ctx, id)
 	if err != nil {
-		logrus.WithError(err).WithField("customer_id", id).Error("Failed to get customer")
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Failed to get customer")
 		return nil, err
 	}
 
 	response := customer.ToResponse()
-	logrus.WithField("customer_id", id).Debug("Successfully retrieved customer")
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Successfully retrieved customer")
 
 	return &response, nil
 }
 
 // GetAllCustomers retrieves all customers
-func (s *customerService) GetAllCustomers() ([]*model.CustomerResponse, error) {
-	logrus.Debug("Getting all customers")
+func (s *customerService) GetAllCustomers(ctx context.Context) ([]*model.CustomerResponse, error) {
+	log.Ctx(ctx).Debug("Getting all customers")
 
-	customers, err := s.repo.GetAll()
+	customers, err := s.repo.GetAll(ctx)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get all customers")
+		log.Ctx(ctx).WithError(err).Error("Failed to get all customers")
 		return nil, err
 	}
 
@@ -64,13 +102,68 @@ func (s *customerService) GetAllCustomers() ([]*model.CustomerResponse, error) {
 		responses[i] = &response
 	}
 
-	logrus.WithField("count", len(responses)).Debug("Successfully retrieved all customers")
+	log.Ctx(ctx).WithField("count", len(responses)).Debug("Successfully retrieved all customers")
 	return responses, nil
 }
 
-// CreateCustomer creates a new customer
-func (s *customerService) CreateCustomer(req model.CreateCustomerRequest) (*model.CustomerResponse, error) {
-	logrus.WithFields(logrus.Fields{
+// ListCustomers retrieves a filtered, sorted page of customers
+func (s *customerService) ListCustomers(ctx context.Context, filter model.CustomerFilter) ([]*model.CustomerResponse, pagination.Meta, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"limit":  filter.Page.Limit,
+		"offset": filter.Page.Offset,
+		"sort":   filter.Sort,
+	}).Debug("Listing customers")
+
+	if !model.IsValidCustomerSort(filter.Sort) {
+		return nil, pagination.Meta{}, model.ErrInvalidSort
+	}
+
+	customers, total, err := s.repo.Find(ctx, filter)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to list customers")
+		return nil, pagination.Meta{}, err
+	}
+
+	responses := make([]*model.CustomerResponse, len(customers))
+	for i, customer := range customers {
+		response := customer.ToResponse()
+		responses[i] = &response
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"count": len(responses),
+		"total": total,
+	}).Debug("Successfully listed customers")
+	return responses, filter.Page.Meta(total), nil
+}
+
+// SearchCustomers retrieves a page of customers matching every given search
+// criterion. At least one criterion is required.
+func (s *customerService) SearchCustomers(ctx context.Context, filter model.CustomerFilter) ([]*model.CustomerResponse, pagination.Meta, error) {
+	filter.Name = strings.TrimSpace(filter.Name)
+	filter.EmailDomain = strings.TrimPrefix(strings.TrimSpace(filter.EmailDomain), "@")
+	filter.PhonePrefix = strings.TrimSpace(filter.PhonePrefix)
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"name":         filter.Name,
+		"email_domain": filter.EmailDomain,
+		"phone_prefix": filter.PhonePrefix,
+	}).Debug("Searching customers")
+
+	if !filter.HasSearchCriteria() {
+		return nil, pagination.Meta{}, model.ErrNoSearchCriteria
+	}
+	if filter.PhonePrefix != "" && !strings.ContainsAny(filter.PhonePrefix, "0123456789") {
+		return nil, pagination.Meta{}, model.ErrInvalidPhonePrefix
+	}
+
+	return s.ListCustomers(ctx, filter)
+}
+
+// CreateCustomer creates a new customer. With a verifier, the customer is
+// created pending and sent a verification token.
+func (s *customerService) CreateCustomer(ctx context.Context, req model.CreateCustomerRequest) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
 		"name":  req.Name,
 		"email": req.Email,
 		"phone": req.Phone,
@@ -78,46 +171,87 @@ func (s *customerService) CreateCustomer(req model.CreateCustomerRequest) (*mode
 
 	// Validate email format
 	if !isValidEmail(req.Email) {
-		return nil, errors.New("invalid email format")
+		return nil, model.ErrInvalidEmail
 	}
 
-	// Validate phone format
-	if !isValidPhone(req.Phone) {
-		return nil, errors.New("invalid phone format")
+	// Validate the phone number, storing it in E.164 format
+	normalizedPhone, err := normalizePhone(req.Phone)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create customer model
 	customer := &model.Customer{
 		Name:   req.Name,
 		Email:  req.Email,
-		Phone:  req.Phone,
+		Phone:  normalizedPhone,
 		Active: true,               // New customers are active by default
 		Status: model.StatusActive, // New customers start with active status
 	}
+	if s.verifier != nil {
+		// Pending customers cannot place orders until their email is verified
+		customer.Active = false
+		customer.Status = model.StatusPending
+	}
 
 	// Save customer
-	createdCustomer, err := s.repo.Create(customer)
+	createdCustomer, err := s.repo.Create(ctx, customer)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create customer")
+		log.Ctx(ctx).WithError(err).Error("Failed to create customer")
 		return nil, err
 	}
 
+	s.record(ctx, model.HistoryCreated, createdCustomer.ID, model.DiffCustomers(nil, createdCustomer))
+
 	response := createdCustomer.ToResponse()
-	logrus.WithField("customer_id", createdCustomer.ID).Info("Successfully created customer")
+	s.publish(ctx, events.CustomerCreated, response.ID, response)
+	log.Ctx(ctx).WithField("customer_id", createdCustomer.ID).Info("Successfully created customer")
+
+	if s.verifier != nil {
+		if err := s.verifier.StartVerification(ctx, createdCustomer); err != nil {
+			// The customer exists either way and can ask for another token
+			log.Ctx(ctx).WithError(err).WithField("customer_id", createdCustomer.ID).Error("Failed to start email verification")
+		}
+	}
 
 	return &response, nil
 }
 
 // UpdateCustomer updates an existing customer
-func (s *customerService) UpdateCustomer(id string, req model.UpdateCustomerRequest) (*model.CustomerResponse, error) {
-	logrus.WithField("customer_id", id).Debug("Updating customer")
+func (s *customerService) UpdateCustomer(ctx context.Context, id string, req model.UpdateCustomerRequest) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Updating customer")
 
 	// Get existing customer
-	existingCustomer, err := s.repo.GetByID(id)
+	existingCustomer, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		logrus.WithError(err).WithField("customer_id", id).Error("Customer not found for update")
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Customer not found for update")
 		return nil, err
 	}
+	return s.update(ctx, id, existingCustomer, req)
+}
+
+// PatchCustomer applies a merge patch or JSON Patch to the fields of an
+// existing customer. Fields the patch leaves as they are stay untouched;
+// the others are updated as UpdateCustomer updates them.
+func (s *customerService) PatchCustomer(ctx context.Context, id string, p patch.Patch) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Patching customer")
+
+	existingCustomer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Customer not found for patch")
+		return nil, err
+	}
+
+	var doc model.CustomerDocument
+	if err := patch.ApplyTo(p, existingCustomer.Document(), &doc); err != nil {
+		return nil, err
+	}
+	return s.update(ctx, id, existingCustomer, doc.UpdateRequest(existingCustomer))
+}
+
+// update applies the update to the existing customer and saves it
+func (s *customerService) update(ctx context.Context, id string, existingCustomer *model.Customer, req model.UpdateCustomerRequest) (*model.CustomerResponse, error) {
+	before := *existingCustomer
 
 	// Update fields if provided
 	if req.Name != nil {
@@ -125,82 +259,257 @@ func (s *customerService) UpdateCustomer(id string, req model.UpdateCustomerRequ
 	}
 	if req.Email != nil {
 		if !isValidEmail(*req.Email) {
-			return nil, errors.New("invalid email format")
+			return nil, model.ErrInvalidEmail
 		}
 		existingCustomer.Email = *req.Email
 	}
 	if req.Phone != nil {
-		if !isValidPhone(*req.Phone) {
-			return nil, errors.New("invalid phone format")
+		normalizedPhone, err := normalizePhone(*req.Phone)
+		if err != nil {
+			return nil, err
 		}
-		existingCustomer.Phone = *req.Phone
+		existingCustomer.Phone = normalizedPhone
 	}
 	if req.Active != nil {
 		existingCustomer.Active = *req.Active
 	}
 	if req.Status != nil {
 		if !req.Status.IsValid() {
-			return nil, errors.New("invalid customer status")
+			return nil, model.ErrInvalidStatus
+		}
+		if *req.Status != existingCustomer.Status && !existingCustomer.Status.CanTransitionTo(*req.Status) {
+			return nil, model.ErrInvalidStatusTransition
 		}
 		existingCustomer.Status = *req.Status
 	}
 
 	// Save updated customer
-	updatedCustomer, err := s.repo.Update(id, existingCustomer)
+	updatedCustomer, err := s.repo.Update(ctx, id, existingCustomer)
 	if err != nil {
-		logrus.WithError(err).WithField("customer_id", id).Error("Failed to update customer")
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Failed to update customer")
 		return nil, err
 	}
 
+	s.record(ctx, model.HistoryUpdated, id, model.DiffCustomers(&before, updatedCustomer))
+
 	response := updatedCustomer.ToResponse()
-	logrus.WithField("customer_id", id).Info("Successfully updated customer")
+	s.publish(ctx, events.CustomerUpdated, response.ID, response)
+	log.Ctx(ctx).WithField("customer_id", id).Info("Successfully updated customer")
 
 	return &response, nil
 }
 
 // DeleteCustomer deletes a customer
-func (s *customerService) DeleteCustomer(id string) error {
-	logrus.WithField("customer_id", id).Debug("Deleting customer")
+func (s *customerService) DeleteCustomer(ctx context.Context, id string) error {
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Deleting customer")
 
-	err := s.repo.Delete(id)
+	err := s.repo.Delete(ctx, id)
 	if err != nil {
-		logrus.WithError(err).WithField("customer_id", id).Error("Failed to delete customer")
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Failed to delete customer")
 		return err
 	}
 
-	logrus.WithField("customer_id", id).Info("Successfully deleted customer")
+	s.record(ctx, model.HistoryDeleted, id, nil)
+	s.publish(ctx, events.CustomerDeleted, id, map[string]string{"id": id})
+
+	log.Ctx(ctx).WithField("customer_id", id).Info("Successfully deleted customer")
 	return nil
 }
 
+// RestoreCustomer restores a soft-deleted customer
+func (s *customerService) RestoreCustomer(ctx context.Context, id string) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Restoring customer")
+
+	restoredCustomer, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Failed to restore customer")
+		return nil, err
+	}
+
+	s.record(ctx, model.HistoryRestored, id, nil)
+
+	response := restoredCustomer.ToResponse()
+	s.publish(ctx, events.CustomerRestored, response.ID, response)
+	log.Ctx(ctx).WithField("customer_id", id).Info("Successfully restored customer")
+
+	return &response, nil
+}
+
+// ChangeCustomerStatus moves a customer to another status along the allowed
+// transitions and records the reason in its history. Only ACTIVE customers
+// are left active.
+func (s *customerService) ChangeCustomerStatus(ctx context.Context, id string, req model.ChangeStatusRequest) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": id,
+		"status":      req.Status,
+	}).Debug("Changing customer status")
+
+	if !req.Status.IsValid() {
+		return nil, model.ErrInvalidStatus
+	}
+
+	customer, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Customer not found for status change")
+		return nil, err
+	}
+	if !customer.Status.CanTransitionTo(req.Status) {
+		return nil, model.ErrInvalidStatusTransition
+	}
+
+	before := *customer
+	customer.Status = req.Status
+	customer.Active = req.Status == model.StatusActive
+	updatedCustomer, err := s.repo.Update(ctx, id, customer)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Failed to change customer status")
+		return nil, err
+	}
+
+	s.recordEntry(ctx, &model.HistoryEntry{
+		CustomerID: id,
+		Action:     model.HistoryStatusChanged,
+		Changes:    model.DiffCustomers(&before, updatedCustomer),
+		Reason:     req.Reason,
+	})
+
+	response := updatedCustomer.ToResponse()
+	s.publish(ctx, events.CustomerUpdated, response.ID, response)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": id,
+		"from":        before.Status,
+		"to":          updatedCustomer.Status,
+	}).Info("Successfully changed customer status")
+
+	return &response, nil
+}
+
 // CustomerExists checks if a customer exists
-func (s *customerService) CustomerExists(id string) bool {
-	return s.repo.ExistsByID(id)
+func (s *customerService) CustomerExists(ctx context.Context, id string) bool {
+	return s.repo.ExistsByID(ctx, id)
 }
 
 // GetCustomerByEmail retrieves a customer by email
-func (s *customerService) GetCustomerByEmail(email string) (*model.CustomerResponse, error) {
-	logrus.WithField("email", email).Debug("Getting customer by email")
+func (s *customerService) GetCustomerByEmail(ctx context.Context, email string) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).WithField("email", email).Debug("Getting customer by email")
 
-	customer, err := s.repo.GetByEmail(email)
+	customer, err := s.repo.GetByEmail(ctx, email)
 	if err != nil {
-		logrus.WithError(err).WithField("email", email).Error("Failed to get customer by email")
+		log.Ctx(ctx).WithError(err).WithField("email", email).Error("Failed to get customer by email")
 		return nil, err
 	}
 
 	response := customer.ToResponse()
-	logrus.WithField("email", email).Debug("Successfully retrieved customer by email")
+	log.Ctx(ctx).WithField("email", email).Debug("Successfully retrieved customer by email")
 
 	return &response, nil
 }
 
+// GetCustomerHistory retrieves a page of the changes made to a customer,
+// oldest first. The history of deleted customers stays available.
+func (s *customerService) GetCustomerHistory(ctx context.Context, id string, page pagination.Params) ([]*model.HistoryEntry, pagination.Meta, error) {
+	log.Ctx(ctx).WithField("customer_id", id).Debug("Getting customer history")
+
+	entries, total, err := s.history.FindByCustomerID(ctx, id, page)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", id).Error("Failed to get customer history")
+		return nil, pagination.Meta{}, err
+	}
+
+	// Customers changed before history was recorded have an empty history
+	if total == 0 && !s.repo.ExistsByID(ctx, id) {
+		return nil, pagination.Meta{}, model.ErrCustomerNotFound
+	}
+
+	return entries, page.Meta(total), nil
+}
+
+// GetCustomerStats summarizes the customers that are not deleted
+func (s *customerService) GetCustomerStats(ctx context.Context) (*model.CustomerStats, error) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get customer stats")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// getByIDOrAlias retrieves a customer by ID. The ID of a merged customer
+// refers to the customer it was merged into.
+func (s *customerService) getByIDOrAlias(ctx context.Context, id string) (*model.Customer, error) {
+	customer, err := s.repo.GetByID(ctx, id)
+	if !errors.Is(err, model.ErrCustomerNotFound) {
+		return customer, err
+	}
+
+	survivorID, ok := s.repo.ResolveAlias(ctx, id)
+	if !ok {
+		return nil, err
+	}
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": id,
+		"survivor_id": survivorID,
+	}).Debug("Resolved merged customer")
+	return s.repo.GetByID(ctx, survivorID)
+}
+
+// record appends a change made by the actor of ctx to the customer's history.
+// Inside a bulk transaction the entry is held back until the commit.
+func (s *customerService) record(ctx context.Context, action model.HistoryAction, customerID string, changes []model.FieldChange) {
+	s.recordEntry(ctx, &model.HistoryEntry{
+		CustomerID: customerID,
+		Action:     action,
+		Changes:    changes,
+	})
+}
+
+// recordEntry stamps an entry with the actor of ctx and the current time and
+// appends it like record
+func (s *customerService) recordEntry(ctx context.Context, entry *model.HistoryEntry) {
+	if entry.Changes == nil {
+		entry.Changes = make([]model.FieldChange, 0)
+	}
+	entry.Actor = auth.Actor(ctx)
+	entry.Timestamp = time.Now().UTC()
+
+	if s.pending != nil {
+		*s.pending = append(*s.pending, entry)
+		return
+	}
+
+	if err := s.history.Append(ctx, entry); err != nil {
+		// The change itself has been made; a missing entry must not undo it
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"customer_id": entry.CustomerID,
+			"action":      entry.Action,
+		}).Error("Failed to record customer history")
+	}
+}
+
+// publish sends a customer lifecycle event if a publisher is configured
+func (s *customerService) publish(ctx context.Context, eventType string, customerID string, data interface{}) {
+	if s.events != nil {
+		s.events.Publish(events.New(eventType, customerID, data).In(ctx))
+	}
+}
+
 // isValidEmail validates email format
 func isValidEmail(email string) bool {
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 	return emailRegex.MatchString(email)
 }
 
-// isValidPhone validates phone format (basic validation)
-func isValidPhone(phone string) bool {
-	phoneRegex := regexp.MustCompile(`^\+?[1-9]\d{1,14}$`)
-	return phoneRegex.MatchString(phone)
+// normalizePhone validates a phone number and returns its E.164 form.
+// Numbers without a country calling code are in the default region of the
+// phone package.
+func normalizePhone(raw string) (string, error) {
+	normalized, err := phone.Normalize(raw)
+	if errors.Is(err, phone.ErrInvalidCountryCode) {
+		return "", model.ErrInvalidPhoneCountryCode
+	}
+	if err != nil {
+		return "", model.ErrInvalidPhone
+	}
+	return normalized, nil
 }
@@ -1,10 +1,17 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"testing"
 
 	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/repository"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/patch"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -15,7 +22,7 @@ type MockCustomerRepository struct {
 	mock.Mock
 }
 
-func (m *MockCustomerRepository) GetByID(id string) (*model.Customer, error) {
+func (m *MockCustomerRepository) GetByID(ctx context.Context, id string) (*model.Customer, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -23,12 +30,25 @@ func (m *MockCustomerRepository) GetByID(id string) (*model.Customer, error) {
 	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) GetAll() ([]*model.Customer, error) {
+func (m *MockCustomerRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Customer, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) GetAll(ctx context.Context) ([]*model.Customer, error) {
 	args := m.Called()
 	return args.Get(0).([]*model.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) Create(customer *model.Customer) (*model.Customer, error) {
+func (m *MockCustomerRepository) Find(ctx context.Context, filter model.CustomerFilter) ([]*model.Customer, int, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]*model.Customer), args.Int(1), args.Error(2)
+}
+
+func (m *MockCustomerRepository) Create(ctx context.Context, customer *model.Customer) (*model.Customer, error) {
 	args := m.Called(customer)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,7 +56,7 @@ func (m *MockCustomerRepository) Create(customer *model.Customer) (*model.Custom
 	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) Update(id string, customer *model.Customer) (*model.Customer, error) {
+func (m *MockCustomerRepository) Update(ctx context.Context, id string, customer *model.Customer) (*model.Customer, error) {
 	args := m.Called(id, customer)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,17 +64,25 @@ func (m *MockCustomerRepository) Update(id string, customer *model.Customer) (*m
 	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
-func (m *MockCustomerRepository) Delete(id string) error {
+func (m *MockCustomerRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockCustomerRepository) ExistsByID(id string) bool {
+func (m *MockCustomerRepository) Restore(ctx context.Context, id string) (*model.Customer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) ExistsByID(ctx context.Context, id string) bool {
 	args := m.Called(id)
 	return args.Bool(0)
 }
 
-func (m *MockCustomerRepository) GetByEmail(email string) (*model.Customer, error) {
+func (m *MockCustomerRepository) GetByEmail(ctx context.Context, email string) (*model.Customer, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -62,17 +90,62 @@ func (m *MockCustomerRepository) GetByEmail(email string) (*model.Customer, erro
 	return args.Get(0).(*model.Customer), args.Error(1)
 }
 
+func (m *MockCustomerRepository) AddAlias(ctx context.Context, alias, customerID string) error {
+	args := m.Called(alias, customerID)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) ResolveAlias(ctx context.Context, id string) (string, bool) {
+	args := m.Called(id)
+	return args.String(0), args.Bool(1)
+}
+
+func (m *MockCustomerRepository) Stats(ctx context.Context) (*model.CustomerStats, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomerStats), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Transaction(ctx context.Context, fn func(tx repository.CustomerRepository) error) error {
+	m.Called()
+	return fn(m)
+}
+
+// MockHistoryRepository is a mock implementation of HistoryRepository
+type MockHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockHistoryRepository) Append(ctx context.Context, entry *model.HistoryEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockHistoryRepository) FindByCustomerID(ctx context.Context, customerID string, page pagination.Params) ([]*model.HistoryEntry, int, error) {
+	args := m.Called(customerID, page)
+	return args.Get(0).([]*model.HistoryEntry), args.Int(1), args.Error(2)
+}
+
+// newMockHistory returns a history repository that accepts any entry
+func newMockHistory() *MockHistoryRepository {
+	history := new(MockHistoryRepository)
+	history.On("Append", mock.Anything).Return(nil).Maybe()
+	return history
+}
+
 func TestCustomerService_GetCustomerByID(t *testing.T) {
 	t.Run("Get existing customer", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		expectedCustomer := &model.Customer{
 			ID:     "customer-123",
 			Name:   "John Doe",
 			Email:  "john.doe@example.com",
-			Phone:  "+15550123",
+			Phone:  "+12025550123",
 			Active: true,
 			Status: model.StatusActive,
 		}
@@ -80,7 +153,7 @@ func TestCustomerService_GetCustomerByID(t *testing.T) {
 		mockRepo.On("GetByID", "customer-123").Return(expectedCustomer, nil)
 
 		// Act
-		result, err := service.GetCustomerByID("customer-123")
+		result, err := service.GetCustomerByID(context.Background(), "customer-123")
 
 		// Assert
 		require.NoError(t, err)
@@ -93,17 +166,37 @@ func TestCustomerService_GetCustomerByID(t *testing.T) {
 	t.Run("Get non-existing customer", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
-		mockRepo.On("GetByID", "non-existing").Return(nil, errors.New("customer not found"))
+		mockRepo.On("GetByID", "non-existing").Return(nil, model.ErrCustomerNotFound)
+		mockRepo.On("ResolveAlias", "non-existing").Return("", false)
 
 		// Act
-		result, err := service.GetCustomerByID("non-existing")
+		result, err := service.GetCustomerByID(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.Equal(t, "customer not found", err.Error())
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Get merged customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		survivor := &model.Customer{ID: "customer-123", Name: "John Doe", Status: model.StatusActive}
+		mockRepo.On("GetByID", "customer-merged").Return(nil, model.ErrCustomerNotFound)
+		mockRepo.On("ResolveAlias", "customer-merged").Return("customer-123", true)
+		mockRepo.On("GetByID", "customer-123").Return(survivor, nil)
+
+		// Act
+		result, err := service.GetCustomerByID(context.Background(), "customer-merged")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "customer-123", result.ID, "the ID of a merged customer refers to the survivor")
 		mockRepo.AssertExpectations(t)
 	})
 }
@@ -112,13 +205,13 @@ func TestCustomerService_GetCustomerByEmail(t *testing.T) {
 	t.Run("Get customer by existing email", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		expectedCustomer := &model.Customer{
 			ID:     "customer-123",
 			Name:   "John Doe",
 			Email:  "john.doe@example.com",
-			Phone:  "+15550123",
+			Phone:  "+12025550123",
 			Active: true,
 			Status: model.StatusActive,
 		}
@@ -126,7 +219,7 @@ func TestCustomerService_GetCustomerByEmail(t *testing.T) {
 		mockRepo.On("GetByEmail", "john.doe@example.com").Return(expectedCustomer, nil)
 
 		// Act
-		result, err := service.GetCustomerByEmail("john.doe@example.com")
+		result, err := service.GetCustomerByEmail(context.Background(), "john.doe@example.com")
 
 		// Assert
 		require.NoError(t, err)
@@ -138,12 +231,12 @@ func TestCustomerService_GetCustomerByEmail(t *testing.T) {
 	t.Run("Get customer by non-existing email", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
-		mockRepo.On("GetByEmail", "nonexisting@example.com").Return(nil, errors.New("customer not found"))
+		mockRepo.On("GetByEmail", "nonexisting@example.com").Return(nil, model.ErrCustomerNotFound)
 
 		// Act
-		result, err := service.GetCustomerByEmail("nonexisting@example.com")
+		result, err := service.GetCustomerByEmail(context.Background(), "nonexisting@example.com")
 
 		// Assert
 		assert.Error(t, err)
@@ -156,19 +249,19 @@ func TestCustomerService_CreateCustomer(t *testing.T) {
 	t.Run("Create valid customer", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		request := model.CreateCustomerRequest{
 			Name:  "John Doe",
 			Email: "john.doe@example.com",
-			Phone: "+15550123", // Sin guiones para coincidir con el regex
+			Phone: "+1 202-555-0123", // Stored in E.164 format
 		}
 
 		expectedCustomer := &model.Customer{
 			ID:     "generated-id",
 			Name:   "John Doe",
 			Email:  "john.doe@example.com",
-			Phone:  "+15550123",
+			Phone:  "+12025550123",
 			Active: true,
 			Status: model.StatusActive,
 		}
@@ -176,18 +269,20 @@ func TestCustomerService_CreateCustomer(t *testing.T) {
 		mockRepo.On("Create", mock.MatchedBy(func(c *model.Customer) bool {
 			return c.Name == "John Doe" &&
 				c.Email == "john.doe@example.com" &&
-				c.Phone == "+15550123" &&
+				c.Phone == "+12025550123" &&
 				c.Active == true &&
 				c.Status == model.StatusActive
 		})).Return(expectedCustomer, nil)
 
 		// Act
-		result, err := service.CreateCustomer(request)
+		result, err := service.CreateCustomer(context.Background(), request)
 
 		// Assert
 		require.NoError(t, err)
 		assert.Equal(t, "generated-id", result.ID)
 		assert.Equal(t, "John Doe", result.Name)
+		assert.Equal(t, "+12025550123", result.Phone)
+		assert.Equal(t, "US", result.PhoneRegion)
 		assert.True(t, result.Active)
 		assert.Equal(t, model.StatusActive, result.Status)
 		mockRepo.AssertExpectations(t)
@@ -196,16 +291,16 @@ func TestCustomerService_CreateCustomer(t *testing.T) {
 	t.Run("Create customer with invalid email", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		request := model.CreateCustomerRequest{
 			Name:  "John Doe",
 			Email: "invalid-email", // Invalid email format
-			Phone: "+15550123",
+			Phone: "+12025550123",
 		}
 
 		// Act
-		result, err := service.CreateCustomer(request)
+		result, err := service.CreateCustomer(context.Background(), request)
 
 		// Assert
 		assert.Error(t, err)
@@ -217,7 +312,7 @@ func TestCustomerService_CreateCustomer(t *testing.T) {
 	t.Run("Create customer with invalid phone", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		request := model.CreateCustomerRequest{
 			Name:  "John Doe",
@@ -226,7 +321,7 @@ func TestCustomerService_CreateCustomer(t *testing.T) {
 		}
 
 		// Act
-		result, err := service.CreateCustomer(request)
+		result, err := service.CreateCustomer(context.Background(), request)
 
 		// Assert
 		assert.Error(t, err)
@@ -240,13 +335,13 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 	t.Run("Update existing customer", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		existingCustomer := &model.Customer{
 			ID:     "customer-123",
 			Name:   "Old Name",
 			Email:  "old@example.com",
-			Phone:  "+15550000",
+			Phone:  "+12025550000",
 			Active: true,
 			Status: model.StatusActive,
 		}
@@ -262,7 +357,7 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 			ID:     "customer-123",
 			Name:   "New Name",
 			Email:  "old@example.com",
-			Phone:  "+15550000",
+			Phone:  "+12025550000",
 			Active: true,
 			Status: model.StatusInactive,
 		}
@@ -273,7 +368,7 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 		})).Return(updatedCustomer, nil)
 
 		// Act
-		result, err := service.UpdateCustomer("customer-123", updateRequest)
+		result, err := service.UpdateCustomer(context.Background(), "customer-123", updateRequest)
 
 		// Assert
 		require.NoError(t, err)
@@ -285,13 +380,13 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 	t.Run("Update with invalid email", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		existingCustomer := &model.Customer{
 			ID:     "customer-123",
 			Name:   "John Doe",
 			Email:  "john@example.com",
-			Phone:  "+15550123",
+			Phone:  "+12025550123",
 			Active: true,
 			Status: model.StatusActive,
 		}
@@ -304,7 +399,7 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 		mockRepo.On("GetByID", "customer-123").Return(existingCustomer, nil)
 
 		// Act
-		result, err := service.UpdateCustomer("customer-123", updateRequest)
+		result, err := service.UpdateCustomer(context.Background(), "customer-123", updateRequest)
 
 		// Assert
 		assert.Error(t, err)
@@ -316,13 +411,13 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 	t.Run("Update with invalid status", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		existingCustomer := &model.Customer{
 			ID:     "customer-123",
 			Name:   "John Doe",
 			Email:  "john@example.com",
-			Phone:  "+15550123",
+			Phone:  "+12025550123",
 			Active: true,
 			Status: model.StatusActive,
 		}
@@ -335,7 +430,7 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 		mockRepo.On("GetByID", "customer-123").Return(existingCustomer, nil)
 
 		// Act
-		result, err := service.UpdateCustomer("customer-123", updateRequest)
+		result, err := service.UpdateCustomer(context.Background(), "customer-123", updateRequest)
 
 		// Assert
 		assert.Error(t, err)
@@ -343,18 +438,227 @@ func TestCustomerService_UpdateCustomer(t *testing.T) {
 		assert.Equal(t, "invalid customer status", err.Error())
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("Update with a disallowed status transition", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		existingCustomer := &model.Customer{ID: "customer-123", Name: "John Doe", Status: model.StatusActive, Active: true}
+		pending := model.StatusPending
+		mockRepo.On("GetByID", "customer-123").Return(existingCustomer, nil)
+
+		// Act
+		result, err := service.UpdateCustomer(context.Background(), "customer-123", model.UpdateCustomerRequest{Status: &pending})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Update keeping the current status", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		existingCustomer := &model.Customer{ID: "customer-123", Name: "John Doe", Status: model.StatusPending}
+		pending := model.StatusPending
+		mockRepo.On("GetByID", "customer-123").Return(existingCustomer, nil)
+		mockRepo.On("Update", "customer-123", mock.AnythingOfType("*model.Customer")).Return(existingCustomer, nil)
+
+		// Act
+		_, err := service.UpdateCustomer(context.Background(), "customer-123", model.UpdateCustomerRequest{Status: &pending})
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCustomerService_PatchCustomer(t *testing.T) {
+	newExistingCustomer := func() *model.Customer {
+		return &model.Customer{
+			ID:     "customer-456",
+			Name:   "John Doe",
+			Email:  "john.doe@example.com",
+			Phone:  "+1-555-0123",
+			Active: true,
+			Status: model.StatusActive,
+		}
+	}
+
+	t.Run("Update only the fields the patch changes", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+		mockRepo.On("GetByID", "customer-456").Return(newExistingCustomer(), nil)
+		mockRepo.On("Update", "customer-456", mock.MatchedBy(func(c *model.Customer) bool {
+			// The sample phone number is kept as it is rather than normalized
+			return c.Name == "Jane Doe" && c.Phone == "+1-555-0123" && c.Status == model.StatusActive
+		})).Return(&model.Customer{ID: "customer-456", Name: "Jane Doe"}, nil)
+
+		// Act
+		result, err := service.PatchCustomer(context.Background(), "customer-456", patch.MergePatch(`{"name":"Jane Doe"}`))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", result.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Validate the changed fields as an update does", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+		mockRepo.On("GetByID", "customer-456").Return(newExistingCustomer(), nil)
+
+		// Act
+		_, err := service.PatchCustomer(context.Background(), "customer-456", patch.JSONPatch{
+			{Op: "replace", Path: "/status", Value: []byte(`"PENDING"`)},
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reject removing a field", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+		mockRepo.On("GetByID", "customer-456").Return(newExistingCustomer(), nil)
+
+		// Act
+		result, err := service.PatchCustomer(context.Background(), "customer-456", patch.MergePatch(`{"email":null}`))
+
+		// Assert
+		assert.ErrorIs(t, err, patch.ErrInvalidDocument)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fail on a failed test operation", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+		mockRepo.On("GetByID", "customer-456").Return(newExistingCustomer(), nil)
+
+		// Act
+		_, err := service.PatchCustomer(context.Background(), "customer-456", patch.JSONPatch{
+			{Op: "test", Path: "/email", Value: []byte(`"old@example.com"`)},
+			{Op: "replace", Path: "/email", Value: []byte(`"new@example.com"`)},
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, patch.ErrTestFailed)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCustomerService_ChangeCustomerStatus(t *testing.T) {
+	t.Run("Block a customer with a reason", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		history := new(MockHistoryRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewCustomerService(mockRepo, history, publisher, nil)
+		ctx := auth.WithActor(context.Background(), "user:admin")
+
+		existing := &model.Customer{ID: "customer-123", Name: "John Doe", Active: true, Status: model.StatusActive}
+		mockRepo.On("GetByID", "customer-123").Return(existing, nil)
+		mockRepo.On("Update", "customer-123", mock.MatchedBy(func(c *model.Customer) bool {
+			return c.Status == model.StatusBlocked && !c.Active
+		})).Return(&model.Customer{ID: "customer-123", Name: "John Doe", Active: false, Status: model.StatusBlocked}, nil)
+		history.On("Append", mock.AnythingOfType("*model.HistoryEntry")).Return(nil)
+
+		// Act
+		result, err := service.ChangeCustomerStatus(ctx, "customer-123", model.ChangeStatusRequest{
+			Status: model.StatusBlocked,
+			Reason: "Chargeback fraud",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusBlocked, result.Status)
+		assert.False(t, result.Active)
+		entry := history.Calls[0].Arguments.Get(0).(*model.HistoryEntry)
+		assert.Equal(t, model.HistoryStatusChanged, entry.Action)
+		assert.Equal(t, "user:admin", entry.Actor)
+		assert.Equal(t, "Chargeback fraud", entry.Reason)
+		assert.Equal(t, []model.FieldChange{
+			{Field: "active", From: true, To: false},
+			{Field: "status", From: model.StatusActive, To: model.StatusBlocked},
+		}, entry.Changes)
+		assert.Equal(t, []string{events.CustomerUpdated}, publisher.Types())
+	})
+
+	t.Run("Reject a disallowed transition", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		history := new(MockHistoryRepository)
+		service := NewCustomerService(mockRepo, history, nil, nil)
+
+		mockRepo.On("GetByID", "customer-123").Return(&model.Customer{ID: "customer-123", Status: model.StatusBlocked}, nil)
+
+		// Act
+		result, err := service.ChangeCustomerStatus(context.Background(), "customer-123", model.ChangeStatusRequest{
+			Status: model.StatusPending,
+			Reason: "Re-verify",
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		history.AssertNotCalled(t, "Append", mock.Anything)
+	})
+
+	t.Run("Reject the current status", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		mockRepo.On("GetByID", "customer-123").Return(&model.Customer{ID: "customer-123", Status: model.StatusActive}, nil)
+
+		// Act
+		_, err := service.ChangeCustomerStatus(context.Background(), "customer-123", model.ChangeStatusRequest{
+			Status: model.StatusActive,
+			Reason: "No-op",
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+	})
+
+	t.Run("Unknown customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		mockRepo.On("GetByID", "customer-999").Return(nil, model.ErrCustomerNotFound)
+
+		// Act
+		_, err := service.ChangeCustomerStatus(context.Background(), "customer-999", model.ChangeStatusRequest{
+			Status: model.StatusBlocked,
+			Reason: "Fraud",
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+	})
 }
 
 func TestCustomerService_DeleteCustomer(t *testing.T) {
 	t.Run("Delete existing customer", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 		mockRepo.On("Delete", "customer-123").Return(nil)
 
 		// Act
-		err := service.DeleteCustomer("customer-123")
+		err := service.DeleteCustomer(context.Background(), "customer-123")
 
 		// Assert
 		require.NoError(t, err)
@@ -364,12 +668,12 @@ func TestCustomerService_DeleteCustomer(t *testing.T) {
 	t.Run("Delete non-existing customer", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockCustomerRepository)
-		service := NewCustomerService(mockRepo)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
-		mockRepo.On("Delete", "non-existing").Return(errors.New("customer not found"))
+		mockRepo.On("Delete", "non-existing").Return(model.ErrCustomerNotFound)
 
 		// Act
-		err := service.DeleteCustomer("non-existing")
+		err := service.DeleteCustomer(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -381,13 +685,13 @@ func TestCustomerService_DeleteCustomer(t *testing.T) {
 func TestCustomerService_CustomerExists(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockCustomerRepository)
-	service := NewCustomerService(mockRepo)
+	service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 	t.Run("Customer exists", func(t *testing.T) {
 		mockRepo.On("ExistsByID", "existing-customer").Return(true)
 
 		// Act
-		exists := service.CustomerExists("existing-customer")
+		exists := service.CustomerExists(context.Background(), "existing-customer")
 
 		// Assert
 		assert.True(t, exists)
@@ -398,7 +702,7 @@ func TestCustomerService_CustomerExists(t *testing.T) {
 		mockRepo.On("ExistsByID", "non-existing-customer").Return(false)
 
 		// Act
-		exists := service.CustomerExists("non-existing-customer")
+		exists := service.CustomerExists(context.Background(), "non-existing-customer")
 
 		// Assert
 		assert.False(t, exists)
@@ -406,17 +710,45 @@ func TestCustomerService_CustomerExists(t *testing.T) {
 	})
 }
 
+func TestCustomerService_BatchGetCustomers(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCustomerRepository)
+	service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+	mockRepo.On("GetByIDs", []string{"customer-1", "customer-missing", "customer-merged"}).Return([]*model.Customer{
+		{ID: "customer-1", Name: "Customer 1", Email: "customer1@example.com", Active: true, Status: model.StatusActive},
+	}, nil)
+	mockRepo.On("ResolveAlias", "customer-missing").Return("", false)
+	mockRepo.On("ResolveAlias", "customer-merged").Return("customer-2", true)
+	mockRepo.On("GetByIDs", []string{"customer-2"}).Return([]*model.Customer{
+		{ID: "customer-2", Name: "Customer 2", Email: "customer2@example.com", Active: true, Status: model.StatusActive},
+	}, nil)
+
+	// Act
+	result, err := service.BatchGetCustomers(context.Background(), []string{"customer-1", "customer-missing", "customer-merged"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, result.Results, 3)
+	assert.Equal(t, batch.StatusFound, result.Results[0].Status)
+	assert.Equal(t, "Customer 1", result.Results[0].Data.(*model.CustomerResponse).Name)
+	assert.Equal(t, batch.Result{ID: "customer-missing", Status: batch.StatusNotFound}, result.Results[1])
+	assert.Equal(t, "customer-merged", result.Results[2].ID, "merged customers are reported under the requested ID")
+	assert.Equal(t, "customer-2", result.Results[2].Data.(*model.CustomerResponse).ID)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestCustomerService_GetAllCustomers(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockCustomerRepository)
-	service := NewCustomerService(mockRepo)
+	service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
 
 	expectedCustomers := []*model.Customer{
 		{
 			ID:     "customer-1",
 			Name:   "Customer 1",
 			Email:  "customer1@example.com",
-			Phone:  "+15550001",
+			Phone:  "+12025550001",
 			Active: true,
 			Status: model.StatusActive,
 		},
@@ -424,7 +756,7 @@ func TestCustomerService_GetAllCustomers(t *testing.T) {
 			ID:     "customer-2",
 			Name:   "Customer 2",
 			Email:  "customer2@example.com",
-			Phone:  "+15550002",
+			Phone:  "+12025550002",
 			Active: false,
 			Status: model.StatusInactive,
 		},
@@ -433,7 +765,7 @@ func TestCustomerService_GetAllCustomers(t *testing.T) {
 	mockRepo.On("GetAll").Return(expectedCustomers, nil)
 
 	// Act
-	result, err := service.GetAllCustomers()
+	result, err := service.GetAllCustomers(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -443,6 +775,140 @@ func TestCustomerService_GetAllCustomers(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCustomerService_ListCustomers(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCustomerRepository)
+	service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+	filter := model.CustomerFilter{Page: pagination.Params{Limit: 1, Offset: 1}}
+	page := []*model.Customer{
+		{
+			ID:     "customer-2",
+			Email:  "customer1@example.com",
+			Status: model.StatusActive,
+		},
+	}
+
+	mockRepo.On("Find", filter).Return(page, 3, nil)
+
+	// Act
+	result, meta, err := service.ListCustomers(context.Background(), filter)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "customer-2", result[0].ID)
+	assert.Equal(t, pagination.Meta{Total: 3, Limit: 1, Offset: 1}, meta)
+	mockRepo.AssertExpectations(t)
+
+	t.Run("Invalid sort option", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		// Act
+		result, _, err := service.ListCustomers(context.Background(), model.CustomerFilter{Sort: "unknown"})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "invalid sort option", err.Error())
+		mockRepo.AssertNotCalled(t, "Find", mock.Anything)
+	})
+}
+
+func TestCustomerService_ExportCustomers(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockCustomerRepository)
+	service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+	status := model.StatusActive
+	filter := model.CustomerFilter{Status: &status, Page: pagination.Params{Limit: 5}}
+	firstPage := make([]*model.Customer, pagination.MaxLimit)
+	for i := range firstPage {
+		firstPage[i] = &model.Customer{ID: "customer-1", Status: model.StatusActive}
+	}
+
+	firstFilter := model.CustomerFilter{Status: &status, Page: pagination.Params{Limit: pagination.MaxLimit}}
+	secondFilter := model.CustomerFilter{Status: &status, Page: pagination.Params{Limit: pagination.MaxLimit, Offset: pagination.MaxLimit}}
+	mockRepo.On("Find", firstFilter).Return(firstPage, pagination.MaxLimit+1, nil)
+	mockRepo.On("Find", secondFilter).Return([]*model.Customer{{ID: "customer-2", Status: model.StatusActive}}, pagination.MaxLimit+1, nil)
+
+	// Act
+	var exported []*model.CustomerResponse
+	err := service.ExportCustomers(context.Background(), filter, func(customer *model.CustomerResponse) error {
+		exported = append(exported, customer)
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, exported, pagination.MaxLimit+1)
+	assert.Equal(t, "customer-2", exported[pagination.MaxLimit].ID)
+	mockRepo.AssertExpectations(t)
+
+	t.Run("Invalid sort option", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		// Act
+		err := service.ExportCustomers(context.Background(), model.CustomerFilter{Sort: "unknown"}, func(*model.CustomerResponse) error {
+			return nil
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidSort)
+		mockRepo.AssertNotCalled(t, "Find", mock.Anything)
+	})
+}
+
+func TestCustomerService_SearchCustomers(t *testing.T) {
+	t.Run("Search with normalized criteria", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		expected := model.CustomerFilter{Name: "doe", EmailDomain: "example.com", Page: pagination.Params{Limit: 10}}
+		page := []*model.Customer{{ID: "customer-123", Name: "John Doe", Email: "john.doe@example.com"}}
+
+		mockRepo.On("Find", expected).Return(page, 1, nil)
+
+		// Act
+		result, meta, err := service.SearchCustomers(context.Background(), model.CustomerFilter{Name: " doe ", EmailDomain: "@example.com", Page: pagination.Params{Limit: 10}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, pagination.Meta{Total: 1, Limit: 10}, meta)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name   string
+		filter model.CustomerFilter
+		err    string
+	}{
+		{name: "No criteria", filter: model.CustomerFilter{Name: "  "}, err: "at least one search criterion is required"},
+		{name: "Phone prefix without digits", filter: model.CustomerFilter{PhonePrefix: "+"}, err: "phone prefix must contain digits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockCustomerRepository)
+			service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+			// Act
+			result, _, err := service.SearchCustomers(context.Background(), tt.filter)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tt.err)
+			mockRepo.AssertNotCalled(t, "Find", mock.Anything)
+		})
+	}
+}
+
 // Test email validation function
 func TestEmailValidation(t *testing.T) {
 	tests := []struct {
@@ -470,35 +936,277 @@ func TestEmailValidation(t *testing.T) {
 	}
 }
 
-// Test phone validation function
-func TestPhoneValidation(t *testing.T) {
+// Test phone normalization function
+func TestNormalizePhone(t *testing.T) {
 	tests := []struct {
 		name     string
 		phone    string
-		expected bool
+		expected string
+		err      error
 	}{
-		{"Valid US phone with +", "+15550123", true},
-		{"Valid international phone", "+442079460958", true},
-		{"Valid phone with more digits", "+123456789012345", true},
-		{"Valid phone without + (regex allows it)", "15550123", true}, // El regex \+? hace el + opcional
-		{"Valid short phone without +", "1555", true},                 // El regex permite \d{1,14}
-		{"Valid short phone with +", "+1555", true},                   // 3 dígitos adicionales es válido
-		{"Invalid phone - starts with 0", "+05550123", false},
-		{"Invalid phone - starts with 0 without +", "05550123", false},
-		{"Invalid phone - letters", "+1ABCDEFG", false},
-		{"Invalid phone - letters without +", "1ABCDEFG", false},
-		{"Invalid phone - empty", "", false},
-		{"Invalid phone - only +", "+", false},
-		{"Invalid phone - with dashes", "+1-555-0123", false},           // El regex no acepta guiones
-		{"Invalid phone - with spaces", "+1 555 0123", false},           // No acepta espacios
-		{"Invalid phone - with parentheses", "+1(555)0123", false},      // No acepta paréntesis
-		{"Invalid phone - too many digits", "+1234567890123456", false}, // Más de 15 dígitos total (1 + 14)
+		{"Valid E.164 phone", "+12025550123", "+12025550123", nil},
+		{"Valid international phone", "+442079460958", "+442079460958", nil},
+		{"Valid phone with dashes", "+1-202-555-0123", "+12025550123", nil},
+		{"Valid phone with spaces", "+44 20 7946 0958", "+442079460958", nil},
+		{"Valid phone with parentheses", "+1 (202) 555-0123", "+12025550123", nil},
+		{"Invalid phone - without country calling code", "2025550123", "", model.ErrInvalidPhoneCountryCode},
+		{"Invalid phone - unknown country calling code", "+999 1234 5678", "", model.ErrInvalidPhoneCountryCode},
+		{"Invalid phone - too short for its region", "+15550123", "", model.ErrInvalidPhone},
+		{"Invalid phone - letters", "+1ABCDEFG", "", model.ErrInvalidPhone},
+		{"Invalid phone - empty", "", "", model.ErrInvalidPhone},
+		{"Invalid phone - only +", "+", "", model.ErrInvalidPhone},
+		{"Invalid phone - too many digits", "+1234567890123456", "", model.ErrInvalidPhone},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := isValidPhone(tt.phone)
+			result, err := normalizePhone(tt.phone)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
+
+func TestCustomerService_RestoreCustomer(t *testing.T) {
+	t.Run("Restore deleted customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		restored := &model.Customer{
+			ID:     "customer-123",
+			Name:   "John Doe",
+			Email:  "john@example.com",
+			Phone:  "+12025550123",
+			Active: true,
+			Status: model.StatusActive,
+		}
+		mockRepo.On("Restore", "customer-123").Return(restored, nil)
+
+		// Act
+		result, err := service.RestoreCustomer(context.Background(), "customer-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "customer-123", result.ID)
+		assert.Nil(t, result.DeletedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Restore customer that is not deleted", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		mockRepo.On("Restore", "customer-123").Return(nil, model.ErrCustomerNotDeleted)
+
+		// Act
+		result, err := service.RestoreCustomer(context.Background(), "customer-123")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "customer is not deleted", err.Error())
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCustomerService_BulkCustomers(t *testing.T) {
+	t.Run("Commit when all operations succeed", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Create", mock.AnythingOfType("*model.Customer")).Return(&model.Customer{
+			ID:     "customer-new",
+			Name:   "Jane Doe",
+			Email:  "jane.doe@example.com",
+			Phone:  "+12025550124",
+			Active: true,
+			Status: model.StatusActive,
+		}, nil)
+
+		req := model.BulkCustomerRequest{
+			Operations: []model.BulkCustomerOperation{
+				{Op: "create", Create: &model.CreateCustomerRequest{Name: "Jane Doe", Email: "jane.doe@example.com", Phone: "+12025550124"}},
+			},
+		}
+
+		// Act
+		result, err := service.BulkCustomers(context.Background(), req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Committed)
+		require.Len(t, result.Results, 1)
+		assert.Equal(t, bulk.StatusSucceeded, result.Results[0].Status)
+		assert.Equal(t, "customer-new", result.Results[0].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Roll back when an operation fails", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Delete", "customer-123").Return(nil)
+
+		req := model.BulkCustomerRequest{
+			Operations: []model.BulkCustomerOperation{
+				{Op: "delete", ID: "customer-123"},
+				{Op: "create", Create: &model.CreateCustomerRequest{Name: "Jane Doe", Email: "not-an-email", Phone: "+12025550124"}},
+			},
+		}
+
+		// Act
+		result, err := service.BulkCustomers(context.Background(), req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, result.Committed)
+		require.Len(t, result.Results, 2)
+		assert.Equal(t, bulk.StatusRolledBack, result.Results[0].Status)
+		assert.Equal(t, bulk.StatusFailed, result.Results[1].Status)
+		assert.Equal(t, "invalid email format", result.Results[1].Error)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Abort when ctx is cancelled", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		service := NewCustomerService(mockRepo, newMockHistory(), nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		mockRepo.On("Transaction").Return()
+
+		req := model.BulkCustomerRequest{
+			Operations: []model.BulkCustomerOperation{
+				{Op: "delete", ID: "customer-123"},
+			},
+		}
+
+		// Act
+		result, err := service.BulkCustomers(ctx, req)
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Delete", "customer-123")
+	})
+}
+
+func TestCustomerService_Events(t *testing.T) {
+	t.Run("Publish event after delete", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewCustomerService(mockRepo, newMockHistory(), publisher, nil)
+
+		mockRepo.On("Delete", "customer-123").Return(nil)
+
+		// Act
+		err := service.DeleteCustomer(context.Background(), "customer-123")
+
+		// Assert
+		require.NoError(t, err)
+		published := publisher.Events()
+		require.Len(t, published, 1)
+		assert.Equal(t, events.CustomerDeleted, published[0].Type)
+		assert.Equal(t, "customer-123", published[0].Subject)
+		assert.Equal(t, map[string]string{"id": "customer-123"}, published[0].Data)
+	})
+
+	t.Run("Publish events after a committed bulk request", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewCustomerService(mockRepo, newMockHistory(), publisher, nil)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Delete", "customer-123").Return(nil)
+
+		// Act
+		result, err := service.BulkCustomers(context.Background(), model.BulkCustomerRequest{
+			Operations: []model.BulkCustomerOperation{{Op: "delete", ID: "customer-123"}},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Committed)
+		assert.Equal(t, []string{events.CustomerDeleted}, publisher.Types())
+	})
+}
+
+func TestCustomerService_History(t *testing.T) {
+	t.Run("Record changed fields and actor on update", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		history := new(MockHistoryRepository)
+		service := NewCustomerService(mockRepo, history, nil, nil)
+		ctx := auth.WithActor(context.Background(), "user:admin")
+		name := "Johnny Doe"
+
+		existing := &model.Customer{ID: "customer-123", Name: "John Doe", Email: "john.doe@example.com", Phone: "+12025550123", Active: true, Status: model.StatusActive}
+		mockRepo.On("GetByID", "customer-123").Return(existing, nil)
+		mockRepo.On("Update", "customer-123", mock.AnythingOfType("*model.Customer")).Return(&model.Customer{ID: "customer-123", Name: "Johnny Doe", Email: "john.doe@example.com", Phone: "+12025550123", Active: true, Status: model.StatusActive}, nil)
+		history.On("Append", mock.AnythingOfType("*model.HistoryEntry")).Return(nil)
+
+		// Act
+		_, err := service.UpdateCustomer(ctx, "customer-123", model.UpdateCustomerRequest{Name: &name})
+
+		// Assert
+		require.NoError(t, err)
+		entry := history.Calls[0].Arguments.Get(0).(*model.HistoryEntry)
+		assert.Equal(t, model.HistoryUpdated, entry.Action)
+		assert.Equal(t, "user:admin", entry.Actor)
+		assert.Equal(t, "customer-123", entry.CustomerID)
+		assert.Equal(t, []model.FieldChange{{Field: "name", From: "John Doe", To: "Johnny Doe"}}, entry.Changes)
+	})
+
+	t.Run("Record nothing when a bulk request rolls back", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		history := new(MockHistoryRepository)
+		service := NewCustomerService(mockRepo, history, nil, nil)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Delete", "customer-123").Return(nil)
+
+		// Act
+		result, err := service.BulkCustomers(context.Background(), model.BulkCustomerRequest{
+			Operations: []model.BulkCustomerOperation{
+				{Op: "delete", ID: "customer-123"},
+				{Op: "delete", ID: ""},
+			},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, result.Committed)
+		history.AssertNotCalled(t, "Append", mock.Anything)
+	})
+
+	t.Run("Unknown customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCustomerRepository)
+		history := new(MockHistoryRepository)
+		service := NewCustomerService(mockRepo, history, nil, nil)
+		page := pagination.Params{Limit: 50}
+
+		history.On("FindByCustomerID", "customer-999", page).Return([]*model.HistoryEntry{}, 0, nil)
+		mockRepo.On("ExistsByID", "customer-999").Return(false)
+
+		// Act
+		entries, _, err := service.GetCustomerHistory(context.Background(), "customer-999", page)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		assert.Nil(t, entries)
+	})
+}
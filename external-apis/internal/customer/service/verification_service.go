@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/repository"
+	"external-apis/internal/shared/email"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+)
+
+// VerificationService defines the interface for customer email verification.
+// It is the Verifier of the customer service.
+type VerificationService interface {
+	Verifier
+	VerifyEmail(ctx context.Context, req model.VerifyEmailRequest) (*model.CustomerResponse, error)
+	ResendVerification(ctx context.Context, customerID string) error
+	ResendVerificationByEmail(ctx context.Context, email string) error
+}
+
+// VerificationConfig configures email verification
+type VerificationConfig struct {
+	// TokenTTL is how long a token can be used
+	TokenTTL time.Duration
+	// ResendInterval is the least time between two tokens sent to a customer
+	ResendInterval time.Duration
+	// URL is the page customers confirm their email on; the token is added as
+	// its token query parameter. Without it the email only carries the token.
+	URL string
+}
+
+// verificationService implements VerificationService
+type verificationService struct {
+	customers *customerService
+	tokens    repository.VerificationRepository
+	sender    email.Sender
+	config    VerificationConfig
+	now       func() time.Time
+}
+
+// NewVerificationService creates a new verification service sending tokens
+// through sender. Verified customers are recorded in history and published
+// to events, which may be nil.
+func NewVerificationService(repo repository.CustomerRepository, history repository.HistoryRepository, events events.Publisher, tokens repository.VerificationRepository, sender email.Sender, config VerificationConfig) VerificationService {
+	return &verificationService{
+		customers: &customerService{repo: repo, history: history, events: events},
+		tokens:    tokens,
+		sender:    sender,
+		config:    config,
+		now:       time.Now,
+	}
+}
+
+// StartVerification sends a verification token to a new pending customer
+func (s *verificationService) StartVerification(ctx context.Context, customer *model.Customer) error {
+	return s.send(ctx, customer)
+}
+
+// VerifyEmail confirms the email of the customer the token was sent to,
+// making the customer active. Tokens are single-use and only work for the
+// email they were sent to.
+func (s *verificationService) VerifyEmail(ctx context.Context, req model.VerifyEmailRequest) (*model.CustomerResponse, error) {
+	log.Ctx(ctx).Debug("Verifying customer email")
+
+	verification, err := s.tokens.GetByTokenHash(ctx, digest(req.Token))
+	if err != nil {
+		if errors.Is(err, model.ErrVerificationNotFound) {
+			return nil, model.ErrInvalidVerificationToken
+		}
+		log.Ctx(ctx).WithError(err).Error("Failed to get verification")
+		return nil, err
+	}
+	if verification.IsExpired(s.now()) {
+		return nil, model.ErrVerificationExpired
+	}
+
+	customer, err := s.customers.repo.GetByID(ctx, verification.CustomerID)
+	if errors.Is(err, model.ErrCustomerNotFound) {
+		s.discard(ctx, verification.CustomerID)
+		return nil, model.ErrInvalidVerificationToken
+	}
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", verification.CustomerID).Error("Failed to get customer to verify")
+		return nil, err
+	}
+	if digest(normalizeEmail(customer.Email)) != verification.EmailHash {
+		// The token was sent to an email the customer no longer has
+		s.discard(ctx, customer.ID)
+		return nil, model.ErrInvalidVerificationToken
+	}
+	if customer.Status != model.StatusPending {
+		s.discard(ctx, customer.ID)
+		return nil, model.ErrAlreadyVerified
+	}
+
+	before := *customer
+	customer.Active = true
+	customer.Status = model.StatusActive
+	verified, err := s.customers.repo.Update(ctx, customer.ID, customer)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customer.ID).Error("Failed to activate verified customer")
+		return nil, err
+	}
+	s.discard(ctx, verified.ID)
+
+	s.customers.record(ctx, model.HistoryVerified, verified.ID, model.DiffCustomers(&before, verified))
+
+	response := verified.ToResponse()
+	s.customers.publish(ctx, events.CustomerVerified, response.ID, response)
+	log.Ctx(ctx).WithField("customer_id", verified.ID).Info("Successfully verified customer email")
+
+	return &response, nil
+}
+
+// ResendVerification sends a new token to a pending customer, invalidating
+// the previous one. Tokens are sent at most once per resend interval.
+func (s *verificationService) ResendVerification(ctx context.Context, customerID string) error {
+	log.Ctx(ctx).WithField("customer_id", customerID).Debug("Resending verification email")
+
+	customer, err := s.customers.repo.GetByID(ctx, customerID)
+	if err != nil {
+		return err
+	}
+	if customer.Status != model.StatusPending {
+		return model.ErrAlreadyVerified
+	}
+
+	previous, err := s.tokens.GetByCustomerID(ctx, customer.ID)
+	switch {
+	case err == nil:
+		if s.now().Before(previous.SentAt.Add(s.config.ResendInterval)) {
+			return model.ErrResendTooSoon
+		}
+	case !errors.Is(err, model.ErrVerificationNotFound):
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customer.ID).Error("Failed to get verification")
+		return err
+	}
+
+	return s.send(ctx, customer)
+}
+
+// ResendVerificationByEmail sends a new token to the pending customer with
+// the email. Unknown emails, verified customers and customers sent a token
+// too recently are skipped without an error, so callers cannot tell which
+// emails are registered.
+func (s *verificationService) ResendVerificationByEmail(ctx context.Context, email string) error {
+	customer, err := s.customers.repo.GetByEmail(ctx, email)
+	if errors.Is(err, model.ErrCustomerNotFound) {
+		log.Ctx(ctx).Debug("Skipped resending verification to unknown email")
+		return nil
+	}
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get customer by email")
+		return err
+	}
+
+	err = s.ResendVerification(ctx, customer.ID)
+	if errors.Is(err, model.ErrAlreadyVerified) || errors.Is(err, model.ErrResendTooSoon) {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customer.ID).Debug("Skipped resending verification")
+		return nil
+	}
+	return err
+}
+
+// send stores a new token for the customer, replacing any previous one, and
+// emails it. The token is dropped again if the email cannot be sent, so the
+// customer can ask for another right away.
+func (s *verificationService) send(ctx context.Context, customer *model.Customer) error {
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	now := s.now().UTC()
+	verification := &model.Verification{
+		CustomerID: customer.ID,
+		EmailHash:  digest(normalizeEmail(customer.Email)),
+		TokenHash:  digest(token),
+		SentAt:     now,
+		ExpiresAt:  now.Add(s.config.TokenTTL),
+	}
+	if err := s.tokens.Save(ctx, verification); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customer.ID).Error("Failed to save verification")
+		return err
+	}
+
+	if err := s.sender.Send(ctx, s.message(customer, token, verification.ExpiresAt)); err != nil {
+		s.discard(ctx, customer.ID)
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": customer.ID,
+		"expires_at":  verification.ExpiresAt,
+	}).Info("Sent verification email")
+	return nil
+}
+
+// message renders the verification email of a token
+func (s *verificationService) message(customer *model.Customer, token string, expiresAt time.Time) email.Message {
+	confirm := "use this verification token:\n\n" + token
+	if s.config.URL != "" {
+		link := s.config.URL + "?token=" + url.QueryEscape(token)
+		if strings.Contains(s.config.URL, "?") {
+			link = s.config.URL + "&token=" + url.QueryEscape(token)
+		}
+		confirm = "open this link:\n\n" + link
+	}
+
+	return email.Message{
+		To:      customer.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nTo confirm your email address, %s\n\nIt expires on %s.\n",
+			customer.Name, confirm, expiresAt.Format(time.RFC1123)),
+	}
+}
+
+// discard removes the verification of a customer. A leftover verification
+// only holds a token that no longer works, so failures are just logged.
+func (s *verificationService) discard(ctx context.Context, customerID string) {
+	if err := s.tokens.Delete(ctx, customerID); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customerID).Warn("Failed to delete verification")
+	}
+}
+
+// generateToken returns a new random verification token
+func generateToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// digest returns the hex-encoded SHA-256 of a value. Tokens are 256-bit
+// random values, so a fast hash is sufficient.
+func digest(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail returns the form of an email its hash is taken of
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"external-apis/internal/customer/model"
+	"external-apis/internal/customer/repository"
+	"external-apis/internal/shared/email"
+	"external-apis/internal/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenPattern matches the verification token in an email
+var tokenPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// failingSender is an email.Sender whose sends fail
+type failingSender struct{}
+
+func (failingSender) Send(context.Context, email.Message) error {
+	return errors.New("smtp unavailable")
+}
+
+// verificationFixture wires a customer service to a verification service
+// whose clock the test controls
+type verificationFixture struct {
+	customers     CustomerService
+	verification  *verificationService
+	verifications *repository.MemoryVerificationRepository
+	sender        *email.Memory
+	publisher     *events.MemoryPublisher
+	now           time.Time
+}
+
+func newVerificationFixture(config VerificationConfig) *verificationFixture {
+	f := &verificationFixture{
+		verifications: repository.NewMemoryVerificationRepository(),
+		sender:        email.NewMemory(),
+		publisher:     events.NewMemoryPublisher(),
+		now:           time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	repo := repository.NewMemoryCustomerRepository()
+	history := newMockHistory()
+	f.verification = NewVerificationService(repo, history, f.publisher, f.verifications, f.sender, config).(*verificationService)
+	f.verification.now = func() time.Time { return f.now }
+	f.customers = NewCustomerService(repo, history, f.publisher, f.verification)
+	return f
+}
+
+// create creates a pending customer and returns it with the token sent to it
+func (f *verificationFixture) create(t *testing.T, emailAddress string) (*model.CustomerResponse, string) {
+	t.Helper()
+	customer, err := f.customers.CreateCustomer(context.Background(), model.CreateCustomerRequest{
+		Name:  "Jane Roe",
+		Email: emailAddress,
+		Phone: "+12025550199",
+	})
+	require.NoError(t, err)
+	return customer, f.lastToken(t)
+}
+
+// lastToken returns the token of the last email sent
+func (f *verificationFixture) lastToken(t *testing.T) string {
+	t.Helper()
+	messages := f.sender.Messages()
+	require.NotEmpty(t, messages)
+	token := tokenPattern.FindString(messages[len(messages)-1].Body)
+	require.NotEmpty(t, token)
+	return token
+}
+
+func TestVerificationService_StartVerification(t *testing.T) {
+	t.Run("New customers are pending and sent a token", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+
+		// Act
+		customer, token := f.create(t, "jane.roe@example.com")
+
+		// Assert
+		assert.Equal(t, model.StatusPending, customer.Status)
+		assert.False(t, customer.Active)
+		messages := f.sender.Messages()
+		require.Len(t, messages, 1)
+		assert.Equal(t, "jane.roe@example.com", messages[0].To)
+		stored, err := f.verifications.GetByCustomerID(context.Background(), customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, digest(token), stored.TokenHash)
+		assert.NotContains(t, stored.TokenHash, token)
+		assert.Equal(t, f.now.Add(time.Hour), stored.ExpiresAt)
+	})
+
+	t.Run("Link to the verification page", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour, URL: "https://shop.example.com/verify"})
+
+		// Act
+		_, token := f.create(t, "jane.roe@example.com")
+
+		// Assert
+		assert.Contains(t, f.sender.Messages()[0].Body, "https://shop.example.com/verify?token="+token)
+	})
+
+	t.Run("Failed sends leave the customer pending without a token", func(t *testing.T) {
+		// Arrange
+		repo := repository.NewMemoryCustomerRepository()
+		verifications := repository.NewMemoryVerificationRepository()
+		verification := NewVerificationService(repo, newMockHistory(), nil, verifications, failingSender{}, VerificationConfig{TokenTTL: time.Hour})
+		customers := NewCustomerService(repo, newMockHistory(), nil, verification)
+
+		// Act
+		customer, err := customers.CreateCustomer(context.Background(), model.CreateCustomerRequest{
+			Name:  "Jane Roe",
+			Email: "jane.roe@example.com",
+			Phone: "+12025550199",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusPending, customer.Status)
+		_, err = verifications.GetByCustomerID(context.Background(), customer.ID)
+		assert.ErrorIs(t, err, model.ErrVerificationNotFound)
+	})
+}
+
+func TestVerificationService_VerifyEmail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Verify the email", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+		customer, token := f.create(t, "jane.roe@example.com")
+
+		// Act
+		verified, err := f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, customer.ID, verified.ID)
+		assert.Equal(t, model.StatusActive, verified.Status)
+		assert.True(t, verified.Active)
+		assert.Equal(t, []string{events.CustomerCreated, events.CustomerVerified}, f.publisher.Types())
+	})
+
+	t.Run("Tokens are single-use", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+		_, token := f.create(t, "jane.roe@example.com")
+		_, err := f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+		require.NoError(t, err)
+
+		// Act
+		_, err = f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+
+		// Act
+		_, err := f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: "not-a-token"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+		customer, token := f.create(t, "jane.roe@example.com")
+		f.now = f.now.Add(time.Hour)
+
+		// Act
+		_, err := f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrVerificationExpired)
+		stored, err := f.customers.GetCustomerByID(ctx, customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusPending, stored.Status)
+	})
+
+	t.Run("Token sent to a previous email", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+		customer, token := f.create(t, "jane.roe@example.com")
+		changed := "jane@example.org"
+		_, err := f.customers.UpdateCustomer(ctx, customer.ID, model.UpdateCustomerRequest{Email: &changed})
+		require.NoError(t, err)
+
+		// Act
+		_, err = f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Customer activated in the meantime", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(VerificationConfig{TokenTTL: time.Hour})
+		customer, token := f.create(t, "jane.roe@example.com")
+		active := model.StatusActive
+		_, err := f.customers.UpdateCustomer(ctx, customer.ID, model.UpdateCustomerRequest{Status: &active})
+		require.NoError(t, err)
+
+		// Act
+		_, err = f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrAlreadyVerified)
+	})
+}
+
+func TestVerificationService_ResendVerification(t *testing.T) {
+	ctx := context.Background()
+	config := VerificationConfig{TokenTTL: time.Hour, ResendInterval: time.Minute}
+
+	t.Run("Resend a new token", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(config)
+		customer, previous := f.create(t, "jane.roe@example.com")
+		f.now = f.now.Add(time.Minute)
+
+		// Act
+		err := f.verification.ResendVerification(ctx, customer.ID)
+
+		// Assert
+		require.NoError(t, err)
+		token := f.lastToken(t)
+		assert.NotEqual(t, previous, token)
+		_, err = f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: previous})
+		assert.ErrorIs(t, err, model.ErrInvalidVerificationToken)
+		_, err = f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Too soon after the last token", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(config)
+		customer, _ := f.create(t, "jane.roe@example.com")
+		f.now = f.now.Add(30 * time.Second)
+
+		// Act
+		err := f.verification.ResendVerification(ctx, customer.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrResendTooSoon)
+		assert.Len(t, f.sender.Messages(), 1)
+	})
+
+	t.Run("Verified customer", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(config)
+		customer, token := f.create(t, "jane.roe@example.com")
+		_, err := f.verification.VerifyEmail(ctx, model.VerifyEmailRequest{Token: token})
+		require.NoError(t, err)
+
+		// Act
+		err = f.verification.ResendVerification(ctx, customer.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrAlreadyVerified)
+	})
+
+	t.Run("Unknown customer", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(config)
+
+		// Act
+		err := f.verification.ResendVerification(ctx, "customer-999")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+	})
+}
+
+func TestVerificationService_ResendVerificationByEmail(t *testing.T) {
+	ctx := context.Background()
+	config := VerificationConfig{TokenTTL: time.Hour, ResendInterval: time.Minute}
+
+	t.Run("Resend to a pending customer", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(config)
+		f.create(t, "jane.roe@example.com")
+		f.now = f.now.Add(time.Minute)
+
+		// Act
+		err := f.verification.ResendVerificationByEmail(ctx, "jane.roe@example.com")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, f.sender.Messages(), 2)
+	})
+
+	t.Run("Skip without revealing the reason", func(t *testing.T) {
+		// Arrange
+		f := newVerificationFixture(config)
+		f.create(t, "jane.roe@example.com")
+
+		for _, emailAddress := range []string{"jane.roe@example.com", "nobody@example.com"} {
+			// Act
+			err := f.verification.ResendVerificationByEmail(ctx, emailAddress)
+
+			// Assert
+			assert.NoError(t, err, emailAddress)
+		}
+		assert.Len(t, f.sender.Messages(), 1)
+	})
+}
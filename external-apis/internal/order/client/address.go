@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// Address types of the customer service
+const (
+	AddressTypeShipping = "SHIPPING"
+	AddressTypeBilling  = "BILLING"
+)
+
+// AddressClient fetches the address book of customers from the customer
+// service
+type AddressClient interface {
+	GetAddresses(ctx context.Context, customerID string) ([]*Address, error)
+}
+
+// Address mirrors the customer address response
+type Address struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customerId"`
+	Type       string `json:"type"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"`
+	IsDefault  bool   `json:"isDefault"`
+}
+
+// NewAddressClient creates a new HTTP customer address client
+func NewAddressClient(baseURL string, timeout time.Duration) AddressClient {
+	return NewHTTPClient(baseURL, timeout, nil)
+}
+
+// GetAddresses retrieves the addresses of a customer, oldest first
+func (c *HTTPClient) GetAddresses(ctx context.Context, customerID string) ([]*Address, error) {
+	var addresses []*Address
+	if err := c.get(ctx, "/api/v1/customers/"+url.PathEscape(customerID)+"/addresses", &addresses); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// PreferredAddress returns the address an order ships to: the default
+// shipping address, else the first shipping address, else the default or
+// first billing address. It returns nil for an empty address book.
+func PreferredAddress(addresses []*Address) *Address {
+	var preferred *Address
+	rank := func(address *Address) int {
+		r := 0
+		if address.Type == AddressTypeShipping {
+			r += 2
+		}
+		if address.IsDefault {
+			r++
+		}
+		return r
+	}
+
+	for _, address := range addresses {
+		if preferred == nil || rank(address) > rank(preferred) {
+			preferred = address
+		}
+	}
+	return preferred
+}
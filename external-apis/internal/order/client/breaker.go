@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"external-apis/internal/shared/breaker"
+)
+
+// NewBreaker creates a breaker for a downstream service that only counts the
+// service being unavailable against it; entities that do not exist are a
+// regular answer
+func NewBreaker(name string, config breaker.Config) *breaker.Breaker {
+	config.IsFailure = func(err error) bool {
+		return errors.Is(err, ErrUnavailable)
+	}
+	return breaker.New(name, config)
+}
+
+// BreakingCustomerClient stops calling the customer service while its
+// breaker is open, failing with ErrUnavailable instead
+type BreakingCustomerClient struct {
+	next    CustomerClient
+	breaker *breaker.Breaker
+}
+
+// NewBreakingCustomerClient wraps a customer client with a circuit breaker
+func NewBreakingCustomerClient(next CustomerClient, b *breaker.Breaker) CustomerClient {
+	return &BreakingCustomerClient{next: next, breaker: b}
+}
+
+// GetCustomer retrieves a customer by ID
+func (c *BreakingCustomerClient) GetCustomer(ctx context.Context, id string) (*Customer, error) {
+	var customer *Customer
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		customer, err = c.next.GetCustomer(ctx, id)
+		return err
+	})
+	return customer, err
+}
+
+// GetCustomers retrieves the customers with the given IDs
+func (c *BreakingCustomerClient) GetCustomers(ctx context.Context, ids []string) (map[string]*Customer, error) {
+	var customers map[string]*Customer
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		customers, err = c.next.GetCustomers(ctx, ids)
+		return err
+	})
+	return customers, err
+}
+
+// BreakingAddressClient stops calling the customer service for addresses
+// while its breaker is open, failing with ErrUnavailable instead. It shares
+// the breaker of the customer client, as both call the same service.
+type BreakingAddressClient struct {
+	next    AddressClient
+	breaker *breaker.Breaker
+}
+
+// NewBreakingAddressClient wraps an address client with a circuit breaker
+func NewBreakingAddressClient(next AddressClient, b *breaker.Breaker) AddressClient {
+	return &BreakingAddressClient{next: next, breaker: b}
+}
+
+// GetAddresses retrieves the addresses of a customer
+func (c *BreakingAddressClient) GetAddresses(ctx context.Context, customerID string) ([]*Address, error) {
+	var addresses []*Address
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		addresses, err = c.next.GetAddresses(ctx, customerID)
+		return err
+	})
+	return addresses, err
+}
+
+// BreakingProductClient stops calling the product service while its breaker
+// is open, failing with ErrUnavailable instead
+type BreakingProductClient struct {
+	next    ProductClient
+	breaker *breaker.Breaker
+}
+
+// NewBreakingProductClient wraps a product client with a circuit breaker
+func NewBreakingProductClient(next ProductClient, b *breaker.Breaker) ProductClient {
+	return &BreakingProductClient{next: next, breaker: b}
+}
+
+// GetProduct retrieves a product by ID
+func (c *BreakingProductClient) GetProduct(ctx context.Context, id string) (*Product, error) {
+	var product *Product
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		product, err = c.next.GetProduct(ctx, id)
+		return err
+	})
+	return product, err
+}
+
+// GetProducts retrieves the products with the given IDs
+func (c *BreakingProductClient) GetProducts(ctx context.Context, ids []string) (map[string]*Product, error) {
+	var products map[string]*Product
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		products, err = c.next.GetProducts(ctx, ids)
+		return err
+	})
+	return products, err
+}
+
+// BreakingReservationClient stops calling the product service for stock
+// reservations while its breaker is open, failing with ErrUnavailable instead.
+// It shares the breaker of the product client, as both call the same service.
+type BreakingReservationClient struct {
+	next    ReservationClient
+	breaker *breaker.Breaker
+}
+
+// NewBreakingReservationClient wraps a reservation client with a circuit breaker
+func NewBreakingReservationClient(next ReservationClient, b *breaker.Breaker) ReservationClient {
+	return &BreakingReservationClient{next: next, breaker: b}
+}
+
+// ReserveStock holds units of a product's stock for an order
+func (c *BreakingReservationClient) ReserveStock(ctx context.Context, productID, orderID string, quantity int) (*Reservation, error) {
+	var reservation *Reservation
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		reservation, err = c.next.ReserveStock(ctx, productID, orderID, quantity)
+		return err
+	})
+	return reservation, err
+}
+
+// ConfirmReservation keeps the stock of a reservation for its order
+func (c *BreakingReservationClient) ConfirmReservation(ctx context.Context, id string) (*Reservation, error) {
+	var reservation *Reservation
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		reservation, err = c.next.ConfirmReservation(ctx, id)
+		return err
+	})
+	return reservation, err
+}
+
+// ReleaseReservation gives the stock of a reservation back
+func (c *BreakingReservationClient) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	var reservation *Reservation
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		reservation, err = c.next.ReleaseReservation(ctx, id)
+		return err
+	})
+	return reservation, err
+}
+
+// BreakingQuoteClient stops calling the product service for price quotes
+// while its breaker is open, failing with ErrUnavailable instead. It shares
+// the breaker of the product client, as both call the same service.
+type BreakingQuoteClient struct {
+	next    QuoteClient
+	breaker *breaker.Breaker
+}
+
+// NewBreakingQuoteClient wraps a quote client with a circuit breaker
+func NewBreakingQuoteClient(next QuoteClient, b *breaker.Breaker) QuoteClient {
+	return &BreakingQuoteClient{next: next, breaker: b}
+}
+
+// QuoteProducts prices the products for the customer with the coupon code
+func (c *BreakingQuoteClient) QuoteProducts(ctx context.Context, productIDs []string, customerID, couponCode string) (map[string]*Quote, error) {
+	var quotes map[string]*Quote
+	err := execute(ctx, c.breaker, func(ctx context.Context) (err error) {
+		quotes, err = c.next.QuoteProducts(ctx, productIDs, customerID, couponCode)
+		return err
+	})
+	return quotes, err
+}
+
+// execute runs a call through the breaker, reporting an open breaker as the
+// service being unavailable so callers need not tell the two apart
+func execute(ctx context.Context, b *breaker.Breaker, call func(ctx context.Context) error) error {
+	err := b.Execute(ctx, call)
+	if errors.Is(err, breaker.ErrOpen) {
+		return fmt.Errorf("%w: %s: %w", ErrUnavailable, b.Name(), err)
+	}
+	return err
+}
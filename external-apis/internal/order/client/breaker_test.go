@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/breaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingProductClient fails every lookup with the given error
+type failingProductClient struct {
+	err   error
+	calls int
+}
+
+func (c *failingProductClient) GetProduct(ctx context.Context, id string) (*Product, error) {
+	c.calls++
+	return nil, c.err
+}
+
+func (c *failingProductClient) GetProducts(ctx context.Context, ids []string) (map[string]*Product, error) {
+	c.calls++
+	return nil, c.err
+}
+
+func TestBreakingProductClient(t *testing.T) {
+	config := breaker.Config{FailureThreshold: 2, Cooldown: time.Minute}
+
+	t.Run("Stop calling an unavailable service", func(t *testing.T) {
+		// Arrange
+		next := &failingProductClient{err: fmt.Errorf("%w: connection refused", ErrUnavailable)}
+		products := NewBreakingProductClient(next, NewBreaker("product-service", config))
+		ctx := context.Background()
+
+		// Act
+		_, _ = products.GetProducts(ctx, []string{"product-001"})
+		_, _ = products.GetProduct(ctx, "product-001")
+		_, err := products.GetProducts(ctx, []string{"product-001"})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.ErrorIs(t, err, breaker.ErrOpen)
+		assert.Equal(t, 2, next.calls)
+	})
+
+	t.Run("Missing products do not open the breaker", func(t *testing.T) {
+		// Arrange
+		next := &failingProductClient{err: ErrNotFound}
+		products := NewBreakingProductClient(next, NewBreaker("product-service", config))
+		ctx := context.Background()
+
+		// Act
+		for range 3 {
+			_, err := products.GetProduct(ctx, "missing")
+			require.ErrorIs(t, err, ErrNotFound)
+		}
+
+		// Assert
+		assert.Equal(t, 3, next.calls)
+	})
+
+	t.Run("Pass results through", func(t *testing.T) {
+		// Arrange
+		next := &countingProductClient{products: map[string]*Product{
+			"product-001": {ID: "product-001", Name: "Mouse"},
+		}}
+		products := NewBreakingProductClient(next, NewBreaker("product-service", config))
+
+		// Act
+		found, err := products.GetProducts(context.Background(), []string{"product-001"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Mouse", found["product-001"].Name)
+	})
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/tenant"
+)
+
+// CachingCustomerClient serves customers fetched within the last TTL from
+// memory, falling back to the wrapped client for the others
+type CachingCustomerClient struct {
+	next  CustomerClient
+	cache *cache[Customer]
+}
+
+// NewCachingCustomerClient wraps a customer client with a cache. A TTL of
+// zero or less disables caching and returns next as it is.
+func NewCachingCustomerClient(next CustomerClient, ttl time.Duration) CustomerClient {
+	if ttl <= 0 {
+		return next
+	}
+	return &CachingCustomerClient{next: next, cache: newCache[Customer](ttl)}
+}
+
+// GetCustomer retrieves a customer by ID
+func (c *CachingCustomerClient) GetCustomer(ctx context.Context, id string) (*Customer, error) {
+	return c.cache.get(ctx, id, c.next.GetCustomer)
+}
+
+// GetCustomers retrieves the customers with the given IDs
+func (c *CachingCustomerClient) GetCustomers(ctx context.Context, ids []string) (map[string]*Customer, error) {
+	return c.cache.getMany(ctx, ids, c.next.GetCustomers)
+}
+
+// CachingProductClient serves products fetched within the last TTL from
+// memory, falling back to the wrapped client for the others
+type CachingProductClient struct {
+	next  ProductClient
+	cache *cache[Product]
+}
+
+// NewCachingProductClient wraps a product client with a cache. A TTL of zero
+// or less disables caching and returns next as it is.
+func NewCachingProductClient(next ProductClient, ttl time.Duration) ProductClient {
+	if ttl <= 0 {
+		return next
+	}
+	return &CachingProductClient{next: next, cache: newCache[Product](ttl)}
+}
+
+// GetProduct retrieves a product by ID
+func (c *CachingProductClient) GetProduct(ctx context.Context, id string) (*Product, error) {
+	return c.cache.get(ctx, id, c.next.GetProduct)
+}
+
+// GetProducts retrieves the products with the given IDs
+func (c *CachingProductClient) GetProducts(ctx context.Context, ids []string) (map[string]*Product, error) {
+	return c.cache.getMany(ctx, ids, c.next.GetProducts)
+}
+
+// cacheKey identifies a cached entity. Downstream services keep the data of
+// every tenant apart, and so does the cache.
+type cacheKey struct {
+	tenant string
+	id     string
+}
+
+// cacheEntry is a cached entity and when it goes stale
+type cacheEntry[V any] struct {
+	value     *V
+	expiresAt time.Time
+}
+
+// cache holds the entities fetched from a downstream service for ttl. Only
+// entities that exist are cached, so a new one is seen on its next lookup.
+type cache[V any] struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[cacheKey]cacheEntry[V]
+	lastSweep time.Time
+}
+
+func newCache[V any](ttl time.Duration) *cache[V] {
+	return &cache[V]{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[cacheKey]cacheEntry[V]),
+	}
+}
+
+// get returns the cached entity with the given ID or fetches it
+func (c *cache[V]) get(ctx context.Context, id string, fetch func(ctx context.Context, id string) (*V, error)) (*V, error) {
+	tenantID := tenant.FromContext(ctx)
+	if cached := c.lookup(tenantID, []string{id}); cached[id] != nil {
+		return cached[id], nil
+	}
+
+	value, err := fetch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.store(tenantID, map[string]*V{id: value})
+	return value, nil
+}
+
+// getMany returns the cached entities with the given IDs, fetching the
+// missing ones in one call
+func (c *cache[V]) getMany(ctx context.Context, ids []string, fetch func(ctx context.Context, ids []string) (map[string]*V, error)) (map[string]*V, error) {
+	tenantID := tenant.FromContext(ctx)
+	found := c.lookup(tenantID, ids)
+
+	var missing []string
+	for _, id := range ids {
+		if found[id] == nil {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	fetched, err := fetch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	c.store(tenantID, fetched)
+	for id, value := range fetched {
+		found[id] = value
+	}
+	return found, nil
+}
+
+// lookup returns the fresh cached entities among ids
+func (c *cache[V]) lookup(tenantID string, ids []string) map[string]*V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	found := make(map[string]*V, len(ids))
+	for _, id := range ids {
+		if entry, ok := c.entries[cacheKey{tenantID, id}]; ok && now.Before(entry.expiresAt) {
+			found[id] = entry.value
+		}
+	}
+	return found
+}
+
+// store caches fetched entities, dropping the stale ones at most once per TTL
+func (c *cache[V]) store(tenantID string, values map[string]*V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	for id, value := range values {
+		if value != nil {
+			c.entries[cacheKey{tenantID, id}] = cacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingProductClient serves fixed products, recording the IDs it is asked for
+type countingProductClient struct {
+	products map[string]*Product
+	requests [][]string
+}
+
+func (c *countingProductClient) GetProduct(ctx context.Context, id string) (*Product, error) {
+	c.requests = append(c.requests, []string{id})
+	if product, ok := c.products[id]; ok {
+		return product, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (c *countingProductClient) GetProducts(ctx context.Context, ids []string) (map[string]*Product, error) {
+	c.requests = append(c.requests, ids)
+	found := make(map[string]*Product)
+	for _, id := range ids {
+		if product, ok := c.products[id]; ok {
+			found[id] = product
+		}
+	}
+	return found, nil
+}
+
+func TestCachingProductClient(t *testing.T) {
+	newClient := func() (*countingProductClient, *CachingProductClient, *time.Time) {
+		next := &countingProductClient{products: map[string]*Product{
+			"product-001": {ID: "product-001", Name: "Mouse"},
+			"product-002": {ID: "product-002", Name: "Keyboard"},
+		}}
+		cached := NewCachingProductClient(next, time.Minute).(*CachingProductClient)
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		cached.cache.now = func() time.Time { return now }
+		return next, cached, &now
+	}
+
+	t.Run("Only missing products are fetched", func(t *testing.T) {
+		// Arrange
+		next, cached, _ := newClient()
+		ctx := context.Background()
+		_, err := cached.GetProducts(ctx, []string{"product-001"})
+		require.NoError(t, err)
+
+		// Act
+		products, err := cached.GetProducts(ctx, []string{"product-001", "product-002", "missing"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, products, 2)
+		assert.Equal(t, [][]string{{"product-001"}, {"product-002", "missing"}}, next.requests)
+	})
+
+	t.Run("Single lookups share the cache", func(t *testing.T) {
+		// Arrange
+		next, cached, _ := newClient()
+		_, err := cached.GetProducts(context.Background(), []string{"product-001"})
+		require.NoError(t, err)
+
+		// Act
+		product, err := cached.GetProduct(context.Background(), "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Mouse", product.Name)
+		assert.Len(t, next.requests, 1)
+	})
+
+	t.Run("Entries expire after the TTL", func(t *testing.T) {
+		// Arrange
+		next, cached, now := newClient()
+		_, err := cached.GetProducts(context.Background(), []string{"product-001"})
+		require.NoError(t, err)
+		*now = now.Add(time.Minute)
+
+		// Act
+		_, err = cached.GetProducts(context.Background(), []string{"product-001"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, next.requests, 2)
+	})
+
+	t.Run("Tenants are cached apart", func(t *testing.T) {
+		// Arrange
+		next, cached, _ := newClient()
+		_, err := cached.GetProducts(tenant.WithTenant(context.Background(), "brand-a"), []string{"product-001"})
+		require.NoError(t, err)
+
+		// Act
+		_, err = cached.GetProducts(tenant.WithTenant(context.Background(), "brand-b"), []string{"product-001"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, next.requests, 2)
+	})
+
+	t.Run("Zero TTL disables caching", func(t *testing.T) {
+		// Arrange
+		next := &countingProductClient{}
+
+		// Act
+		client := NewCachingProductClient(next, 0)
+
+		// Assert
+		assert.Same(t, next, client)
+	})
+}
@@ -0,0 +1,300 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/deadline"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/tenant"
+)
+
+var (
+	// ErrNotFound is returned when the downstream service has no such entity
+	ErrNotFound = errors.New("entity not found")
+	// ErrUnavailable is returned when the downstream service cannot be reached or fails
+	ErrUnavailable = errors.New("downstream service unavailable")
+	// ErrConflict is returned when the downstream service refuses a change
+	// that conflicts with the state of an entity, e.g. for lack of stock
+	ErrConflict = errors.New("downstream conflict")
+	// ErrRejected is returned when the downstream service rejects a request
+	// as invalid, e.g. for a coupon code it does not accept
+	ErrRejected = errors.New("downstream rejected request")
+)
+
+// CustomerClient fetches customers from the customer service. GetCustomers
+// looks up several customers at once; customers that do not exist are left
+// out of its result.
+type CustomerClient interface {
+	GetCustomer(ctx context.Context, id string) (*Customer, error)
+	GetCustomers(ctx context.Context, ids []string) (map[string]*Customer, error)
+}
+
+// ProductClient fetches products from the product service. GetProducts looks
+// up several products at once; products that do not exist are left out of
+// its result.
+type ProductClient interface {
+	GetProduct(ctx context.Context, id string) (*Product, error)
+	GetProducts(ctx context.Context, ids []string) (map[string]*Product, error)
+}
+
+// Customer mirrors the customer service response
+type Customer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Phone  string `json:"phone"`
+	Active bool   `json:"active"`
+	Status string `json:"status"`
+}
+
+// ToOrderCustomer converts the downstream customer into the order snapshot
+func (c *Customer) ToOrderCustomer() *model.OrderCustomer {
+	return &model.OrderCustomer{
+		ID:    c.ID,
+		Name:  c.Name,
+		Email: c.Email,
+		Phone: c.Phone,
+	}
+}
+
+// Product mirrors the product service response. Price is read from the price
+// and currency of the response.
+type Product struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Category    string      `json:"category"`
+	Active      bool        `json:"active"`
+	// AverageRating and ReviewCount aggregate the approved reviews
+	AverageRating float64 `json:"averageRating"`
+	ReviewCount   int     `json:"reviewCount"`
+	// WeightGrams and Dimensions are the shipping measurements
+	WeightGrams int               `json:"weightGrams"`
+	Dimensions  *model.Dimensions `json:"dimensions,omitempty"`
+}
+
+// UnmarshalJSON custom unmarshaling for Product. A missing currency falls
+// back to money.DefaultCurrency.
+func (p *Product) UnmarshalJSON(data []byte) error {
+	type Alias Product
+	aux := &struct {
+		*Alias
+		Price    json.Number `json:"price"`
+		Currency string      `json:"currency"`
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	price, err := money.Parse(aux.Price.String(), currency)
+	if err != nil {
+		return err
+	}
+	p.Price = price
+
+	return nil
+}
+
+// PriceAmount returns the price in major units, for the GraphQL schema
+func (p *Product) PriceAmount() float64 {
+	return p.Price.Float64()
+}
+
+// Currency returns the currency of the price, for the GraphQL schema
+func (p *Product) Currency() string {
+	return p.Price.Currency
+}
+
+// ToOrderProduct converts the downstream product into the order snapshot
+func (p *Product) ToOrderProduct() model.OrderProduct {
+	return model.OrderProduct{
+		ID:            p.ID,
+		Name:          p.Name,
+		Description:   p.Description,
+		Price:         p.Price,
+		Category:      p.Category,
+		AverageRating: p.AverageRating,
+		ReviewCount:   p.ReviewCount,
+		WeightGrams:   p.WeightGrams,
+		Dimensions:    p.Dimensions,
+	}
+}
+
+// HTTPClient calls the customer and product REST APIs
+type HTTPClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPClient creates a new REST client for the given base URL, sending
+// its requests through transport, or the default transport if it is nil.
+// Every call is bounded by timeout and by the remaining deadline budget of
+// its context.
+func NewHTTPClient(baseURL string, timeout time.Duration, transport http.RoundTripper) *HTTPClient {
+	return &HTTPClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: deadline.NewTransport(transport),
+		},
+	}
+}
+
+// NewCustomerClient creates a new HTTP customer client
+func NewCustomerClient(baseURL string, timeout time.Duration) CustomerClient {
+	return NewHTTPClient(baseURL, timeout, nil)
+}
+
+// NewProductClient creates a new HTTP product client
+func NewProductClient(baseURL string, timeout time.Duration) ProductClient {
+	return NewHTTPClient(baseURL, timeout, nil)
+}
+
+// GetCustomer retrieves a customer by ID
+func (c *HTTPClient) GetCustomer(ctx context.Context, id string) (*Customer, error) {
+	var customer Customer
+	if err := c.get(ctx, "/api/v1/customers/"+url.PathEscape(id), &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetProduct retrieves a product by ID
+func (c *HTTPClient) GetProduct(ctx context.Context, id string) (*Product, error) {
+	var product Product
+	if err := c.get(ctx, "/api/v1/products/"+url.PathEscape(id), &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// GetCustomers retrieves the customers with the given IDs
+func (c *HTTPClient) GetCustomers(ctx context.Context, ids []string) (map[string]*Customer, error) {
+	return batchGet[Customer](ctx, c, "/api/v1/customers/batch-get", ids)
+}
+
+// GetProducts retrieves the products with the given IDs
+func (c *HTTPClient) GetProducts(ctx context.Context, ids []string) (map[string]*Product, error) {
+	return batchGet[Product](ctx, c, "/api/v1/products/batch-get", ids)
+}
+
+// batchResponse mirrors the response of the batch get endpoints
+type batchResponse[V any] struct {
+	Results []struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Data   *V     `json:"data"`
+	} `json:"results"`
+}
+
+// batchGet looks up ids through a batch get endpoint, splitting them into as
+// many requests as the endpoint's limit requires
+func batchGet[V any](ctx context.Context, c *HTTPClient, path string, ids []string) (map[string]*V, error) {
+	ids = batch.UniqueIDs(ids)
+	found := make(map[string]*V, len(ids))
+
+	for start := 0; start < len(ids); start += batch.MaxIDs {
+		end := min(start+batch.MaxIDs, len(ids))
+
+		var resp batchResponse[V]
+		if err := c.post(ctx, path, batch.Request{IDs: ids[start:end]}, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, result := range resp.Results {
+			if result.Status == batch.StatusFound && result.Data != nil {
+				found[result.ID] = result.Data
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// get performs a GET request and decodes the JSON body into out
+func (c *HTTPClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.do(req, path, out)
+}
+
+// post performs a POST request with a JSON body and decodes the JSON response into out
+func (c *HTTPClient) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, path, out)
+}
+
+// do sends the request and decodes the JSON body into out
+func (c *HTTPClient) do(req *http.Request, path string, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	// The downstream types mirror the raw data, without the response envelope
+	req.Header.Set(response.EnvelopeHeader, "false")
+	tenant.Inject(req.Context(), req.Header)
+	logger.Inject(req.Context(), req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrConflict, errorMessage(resp))
+	case resp.StatusCode == http.StatusBadRequest:
+		return fmt.Errorf("%w: %s", ErrRejected, errorMessage(resp))
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated:
+		return fmt.Errorf("%w: unexpected status %d from %s", ErrUnavailable, resp.StatusCode, path)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%w: invalid response body: %v", ErrUnavailable, err)
+	}
+
+	return nil
+}
+
+// errorMessage reads the message of an error response
+func errorMessage(resp *http.Response) string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return body.Message
+}
@@ -0,0 +1,371 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClient_GetCustomer(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/customers/customer-456":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"customer-456","name":"John Doe","email":"john.doe@example.com","phone":"+1-555-0123","active":true,"status":"ACTIVE"}`))
+		case "/api/v1/customers/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewCustomerClient(server.URL, time.Second)
+
+	t.Run("Existing customer", func(t *testing.T) {
+		// Act
+		customer, err := client.GetCustomer(context.Background(), "customer-456")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", customer.Name)
+		assert.True(t, customer.Active)
+	})
+
+	t.Run("Missing customer", func(t *testing.T) {
+		// Act
+		customer, err := client.GetCustomer(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Nil(t, customer)
+	})
+
+	t.Run("Downstream failure", func(t *testing.T) {
+		// Act
+		customer, err := client.GetCustomer(context.Background(), "broken")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Nil(t, customer)
+	})
+}
+
+func TestHTTPClient_GetProduct(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"product-789","name":"Laptop","description":"High-performance laptop","price":999,"category":"Electronics","active":true,"averageRating":4.5,"reviewCount":2}`))
+	}))
+	defer server.Close()
+
+	client := NewProductClient(server.URL+"/", time.Second)
+
+	// Act
+	product, err := client.GetProduct(context.Background(), "product-789")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Laptop", product.Name)
+	assert.Equal(t, money.New(99900, "USD"), product.Price)
+	assert.Equal(t, "product-789", product.ToOrderProduct().ID)
+	assert.Equal(t, 4.5, product.ToOrderProduct().AverageRating)
+	assert.Equal(t, 2, product.ToOrderProduct().ReviewCount)
+}
+
+func TestHTTPClient_Unreachable(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	client := NewProductClient(server.URL, 100*time.Millisecond)
+
+	// Act
+	_, err := client.GetProduct(context.Background(), "product-789")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestHTTPClient_GetProducts(t *testing.T) {
+	// Arrange
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			IDs []string `json:"ids"`
+		}
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v1/products/batch-get", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requested = req.IDs
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"results":[{"id":"product-789","status":"found","data":{"id":"product-789","name":"Laptop","price":999,"active":true}},{"id":"missing","status":"not_found"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewProductClient(server.URL, time.Second)
+
+	// Act
+	products, err := client.GetProducts(context.Background(), []string{"product-789", "missing", "product-789"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"product-789", "missing"}, requested)
+	require.Len(t, products, 1)
+	assert.Equal(t, "Laptop", products["product-789"].Name)
+}
+
+func TestHTTPClient_Reservations(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/v1/products/product-001/reservations":
+			var req struct {
+				OrderID  string `json:"orderId"`
+				Quantity int    `json:"quantity"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": "reservation-1", "productId": "product-001", "orderId": req.OrderID, "quantity": req.Quantity, "status": "ACTIVE",
+			})
+		case "/api/v1/products/product-002/reservations":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":"conflict","message":"insufficient stock","code":409}`))
+		case "/api/v1/reservations/reservation-1/confirm":
+			w.Write([]byte(`{"id":"reservation-1","status":"CONFIRMED"}`))
+		case "/api/v1/reservations/reservation-1/release":
+			w.Write([]byte(`{"id":"reservation-1","status":"RELEASED"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewReservationClient(server.URL, time.Second)
+	ctx := context.Background()
+
+	t.Run("Reserve stock", func(t *testing.T) {
+		// Act
+		reservation, err := client.ReserveStock(ctx, "product-001", "order-123", 2)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "reservation-1", reservation.ID)
+		assert.Equal(t, "order-123", reservation.OrderID)
+		assert.Equal(t, 2, reservation.Quantity)
+	})
+
+	t.Run("Insufficient stock", func(t *testing.T) {
+		// Act
+		_, err := client.ReserveStock(ctx, "product-002", "order-123", 2)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Contains(t, err.Error(), "insufficient stock")
+	})
+
+	t.Run("Confirm and release", func(t *testing.T) {
+		// Act
+		confirmed, confirmErr := client.ConfirmReservation(ctx, "reservation-1")
+		released, releaseErr := client.ReleaseReservation(ctx, "reservation-1")
+		_, missingErr := client.ReleaseReservation(ctx, "missing")
+
+		// Assert
+		require.NoError(t, confirmErr)
+		require.NoError(t, releaseErr)
+		assert.Equal(t, "CONFIRMED", confirmed.Status)
+		assert.Equal(t, "RELEASED", released.Status)
+		assert.ErrorIs(t, missingErr, ErrNotFound)
+	})
+}
+
+func TestHTTPClient_QuoteProducts(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v1/products/quotes", r.URL.Path)
+
+		var req struct {
+			ProductIDs []string `json:"productIds"`
+			CustomerID string   `json:"customerId"`
+			CouponCode string   `json:"couponCode"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, []string{"product-001", "missing"}, req.ProductIDs)
+		assert.Equal(t, "customer-456", req.CustomerID)
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.CouponCode == "NOPE" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"bad_request","message":"coupon code is not valid","code":400}`))
+			return
+		}
+		w.Write([]byte(`[{"productId":"product-001","listPrice":29.99,"discount":3.00,"price":26.99,"currency":"EUR","promotion":{"id":"promotion-1","name":"Spring sale","couponCode":"SPRING"}}]`))
+	}))
+	defer server.Close()
+
+	client := NewQuoteClient(server.URL, time.Second)
+
+	t.Run("Quoted products", func(t *testing.T) {
+		// Act
+		quotes, err := client.QuoteProducts(context.Background(), []string{"product-001", "missing"}, "customer-456", "SPRING")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, quotes, 1)
+		assert.Equal(t, money.New(2699, "EUR"), quotes["product-001"].Price)
+		assert.Equal(t, "promotion-1", quotes["product-001"].Promotion.ID)
+	})
+
+	t.Run("Rejected coupon code", func(t *testing.T) {
+		// Act
+		_, err := client.QuoteProducts(context.Background(), []string{"product-001", "missing"}, "customer-456", "NOPE")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Contains(t, err.Error(), "coupon code is not valid")
+	})
+}
+
+func TestHTTPClient_GetAddresses(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/customers/customer-456/addresses":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[{"id":"address-1","customerId":"customer-456","type":"BILLING","line1":"1 Main St","city":"Springfield","region":"IL","postalCode":"62701","country":"US","isDefault":true}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAddressClient(server.URL, time.Second)
+
+	t.Run("Address book", func(t *testing.T) {
+		// Act
+		addresses, err := client.GetAddresses(context.Background(), "customer-456")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, addresses, 1)
+		assert.Equal(t, AddressTypeBilling, addresses[0].Type)
+		assert.Equal(t, "IL", addresses[0].Region)
+		assert.True(t, addresses[0].IsDefault)
+	})
+
+	t.Run("Customer not found", func(t *testing.T) {
+		// Act
+		_, err := client.GetAddresses(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestPreferredAddress(t *testing.T) {
+	billing := &Address{ID: "billing", Type: AddressTypeBilling, IsDefault: true}
+	shipping := &Address{ID: "shipping", Type: AddressTypeShipping}
+	defaultShipping := &Address{ID: "default-shipping", Type: AddressTypeShipping, IsDefault: true}
+
+	tests := []struct {
+		name      string
+		addresses []*Address
+		expected  *Address
+	}{
+		{name: "Empty address book", addresses: nil, expected: nil},
+		{name: "Only billing", addresses: []*Address{billing}, expected: billing},
+		{name: "Shipping over default billing", addresses: []*Address{billing, shipping}, expected: shipping},
+		{name: "Default shipping first", addresses: []*Address{shipping, billing, defaultShipping}, expected: defaultShipping},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := PreferredAddress(tt.addresses)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestHTTPClient_PropagatesTenant(t *testing.T) {
+	// Arrange
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(tenant.Header)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"product-789","name":"Laptop","price":999,"active":true}`))
+	}))
+	defer server.Close()
+
+	client := NewProductClient(server.URL, time.Second)
+
+	// Act
+	_, err := client.GetProduct(tenant.WithTenant(context.Background(), "brand-a"), "product-789")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "brand-a", received)
+}
+
+func TestHTTPClient_PropagatesCorrelationHeaders(t *testing.T) {
+	// Arrange
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"product-789","name":"Laptop","price":999,"active":true}`))
+	}))
+	defer server.Close()
+
+	client := NewProductClient(server.URL, time.Second)
+	trace, ok := logger.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	ctx := logger.WithTrace(logger.WithRequestID(context.Background(), "req-123"), trace)
+
+	// Act
+	_, err := client.GetProduct(ctx, "product-789")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "req-123", received.Get(logger.RequestIDHeader))
+	assert.Equal(t, trace.Header, received.Get(logger.TraceparentHeader))
+}
+
+func TestHTTPClient_RequestsRawData(t *testing.T) {
+	// Arrange
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(response.EnvelopeHeader)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"product-789","name":"Laptop","price":999,"active":true}`))
+	}))
+	defer server.Close()
+
+	client := NewProductClient(server.URL, time.Second)
+
+	// Act
+	product, err := client.GetProduct(context.Background(), "product-789")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "false", received)
+	assert.Equal(t, "Laptop", product.Name)
+}
@@ -0,0 +1,11 @@
+package client
+
+import "context"
+
+// LoyaltyClient adjusts the loyalty points customers collect with their
+// orders
+type LoyaltyClient interface {
+	// ReversePoints takes back the points a customer collected with an
+	// order. Orders without points need no reversal and succeed.
+	ReversePoints(ctx context.Context, customerID, orderID string) error
+}
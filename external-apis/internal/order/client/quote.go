@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// QuoteClient prices products for orders through the promotions of the
+// product service. Products that do not exist are left out of the quotes;
+// a coupon code the product service does not accept fails with ErrRejected.
+type QuoteClient interface {
+	QuoteProducts(ctx context.Context, productIDs []string, customerID, couponCode string) (map[string]*Quote, error)
+}
+
+// Quote mirrors the price quote response of the product service. The
+// amounts are read in the currency of the response.
+type Quote struct {
+	ProductID string          `json:"productId"`
+	ListPrice money.Money     `json:"listPrice"`
+	Discount  money.Money     `json:"discount"`
+	Price     money.Money     `json:"price"`
+	Promotion *QuotePromotion `json:"promotion"`
+}
+
+// UnmarshalJSON custom unmarshaling for Quote. A missing currency falls back
+// to money.DefaultCurrency.
+func (q *Quote) UnmarshalJSON(data []byte) error {
+	type Alias Quote
+	aux := &struct {
+		*Alias
+		ListPrice json.Number `json:"listPrice"`
+		Discount  json.Number `json:"discount"`
+		Price     json.Number `json:"price"`
+		Currency  string      `json:"currency"`
+	}{
+		Alias: (*Alias)(q),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	if q.ListPrice, err = money.Parse(aux.ListPrice.String(), currency); err != nil {
+		return err
+	}
+	if q.Discount, err = money.Parse(aux.Discount.String(), currency); err != nil {
+		return err
+	}
+	q.Price, err = money.Parse(aux.Price.String(), currency)
+	return err
+}
+
+// QuotePromotion mirrors the promotion a quote applied
+type QuotePromotion struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	CouponCode string `json:"couponCode"`
+}
+
+// quoteRequest mirrors the request to quote several products
+type quoteRequest struct {
+	ProductIDs []string `json:"productIds"`
+	CustomerID string   `json:"customerId,omitempty"`
+	CouponCode string   `json:"couponCode,omitempty"`
+}
+
+// NewQuoteClient creates a new HTTP price quote client
+func NewQuoteClient(baseURL string, timeout time.Duration) QuoteClient {
+	return NewHTTPClient(baseURL, timeout, nil)
+}
+
+// QuoteProducts prices the products for the customer with the coupon code,
+// if any, keyed by product ID
+func (c *HTTPClient) QuoteProducts(ctx context.Context, productIDs []string, customerID, couponCode string) (map[string]*Quote, error) {
+	var quotes []*Quote
+	req := quoteRequest{ProductIDs: productIDs, CustomerID: customerID, CouponCode: couponCode}
+	if err := c.post(ctx, "/api/v1/products/quotes", req, &quotes); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*Quote, len(quotes))
+	for _, quote := range quotes {
+		byID[quote.ProductID] = quote
+	}
+	return byID, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// ReservationClient holds product stock for orders through the stock
+// reservations of the product service
+type ReservationClient interface {
+	ReserveStock(ctx context.Context, productID, orderID string, quantity int) (*Reservation, error)
+	ConfirmReservation(ctx context.Context, id string) (*Reservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*Reservation, error)
+}
+
+// Reservation mirrors the stock reservation response of the product service
+type Reservation struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"productId"`
+	OrderID   string    `json:"orderId"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// reserveRequest mirrors the request to reserve product stock
+type reserveRequest struct {
+	OrderID  string `json:"orderId"`
+	Quantity int    `json:"quantity"`
+}
+
+// NewReservationClient creates a new HTTP stock reservation client
+func NewReservationClient(baseURL string, timeout time.Duration) ReservationClient {
+	return NewHTTPClient(baseURL, timeout, nil)
+}
+
+// ReserveStock holds units of a product's stock for an order
+func (c *HTTPClient) ReserveStock(ctx context.Context, productID, orderID string, quantity int) (*Reservation, error) {
+	var reservation Reservation
+	path := "/api/v1/products/" + url.PathEscape(productID) + "/reservations"
+	if err := c.post(ctx, path, reserveRequest{OrderID: orderID, Quantity: quantity}, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ConfirmReservation keeps the stock of a reservation for its order
+func (c *HTTPClient) ConfirmReservation(ctx context.Context, id string) (*Reservation, error) {
+	return c.transitionReservation(ctx, id, "confirm")
+}
+
+// ReleaseReservation gives the stock of a reservation back
+func (c *HTTPClient) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	return c.transitionReservation(ctx, id, "release")
+}
+
+// transitionReservation performs a status change of a reservation
+func (c *HTTPClient) transitionReservation(ctx context.Context, id, action string) (*Reservation, error) {
+	var reservation Reservation
+	if err := c.post(ctx, "/api/v1/reservations/"+url.PathEscape(id)+"/"+action, struct{}{}, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
@@ -0,0 +1,67 @@
+package graph
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/shared/batch"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// loaderWait is how long a loader collects keys before fetching a batch
+const loaderWait = 2 * time.Millisecond
+
+// loaderBatchCapacity bounds the number of keys fetched in one downstream request
+const loaderBatchCapacity = batch.MaxIDs
+
+type loadersKey struct{}
+
+// Loaders batch and cache the customer and product lookups made while
+// resolving a single request, so every entity is fetched at most once no
+// matter how often the query references it
+type Loaders struct {
+	customers *dataloadgen.Loader[string, *client.Customer]
+	products  *dataloadgen.Loader[string, *client.Product]
+}
+
+// NewLoaders creates loaders for one request
+func NewLoaders(customers client.CustomerClient, products client.ProductClient) *Loaders {
+	return &Loaders{
+		customers: dataloadgen.NewLoader(fetchAll(customers.GetCustomers),
+			dataloadgen.WithWait(loaderWait), dataloadgen.WithBatchCapacity(loaderBatchCapacity)),
+		products: dataloadgen.NewLoader(fetchAll(products.GetProducts),
+			dataloadgen.WithWait(loaderWait), dataloadgen.WithBatchCapacity(loaderBatchCapacity)),
+	}
+}
+
+// WithLoaders returns a context carrying the loaders
+func WithLoaders(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, loaders)
+}
+
+// loadersFor returns the loaders of the request
+func loadersFor(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey{}).(*Loaders)
+}
+
+// fetchAll adapts a batch client call to a loader batch function, fetching
+// the keys of a batch in one request. Entities that do not exist load as nil;
+// if the request fails every key fails with it.
+func fetchAll[V any](getMany func(ctx context.Context, ids []string) (map[string]*V, error)) func(ctx context.Context, ids []string) ([]*V, []error) {
+	return func(ctx context.Context, ids []string) ([]*V, []error) {
+		values := make([]*V, len(ids))
+		errs := make([]error, len(ids))
+
+		found, err := getMany(ctx, ids)
+		for i, id := range ids {
+			if err != nil {
+				errs[i] = err
+				continue
+			}
+			values[i] = found[id]
+		}
+
+		return values, errs
+	}
+}
@@ -0,0 +1,49 @@
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"net/http"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/service"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// MaxComplexity bounds the number of fields a single query may resolve
+const MaxComplexity = 500
+
+// Resolver resolves GraphQL queries against the order service and the
+// customer and product clients
+type Resolver struct {
+	customers client.CustomerClient
+	products  client.ProductClient
+	orders    service.OrderService
+}
+
+// NewHandler creates the HTTP handler serving GraphQL queries. Every request
+// gets its own loaders so lookups are batched within, but never cached
+// across, requests.
+func NewHandler(customers client.CustomerClient, products client.ProductClient, orders service.OrderService) http.Handler {
+	server := handler.New(NewExecutableSchema(Config{
+		Resolvers: &Resolver{
+			customers: customers,
+			products:  products,
+			orders:    orders,
+		},
+	}))
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	server.Use(extension.Introspection{})
+	server.Use(extension.FixedComplexityLimit(MaxComplexity))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithLoaders(r.Context(), NewLoaders(customers, products))
+		server.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,242 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/order/service"
+
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCustomerClient struct {
+	mutex     sync.Mutex
+	customers map[string]*client.Customer
+	calls     map[string]int
+	batches   int
+}
+
+func (f *fakeCustomerClient) GetCustomer(ctx context.Context, id string) (*client.Customer, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.calls[id]++
+	customer, ok := f.customers[id]
+	if !ok {
+		return nil, client.ErrNotFound
+	}
+	return customer, nil
+}
+
+func (f *fakeCustomerClient) GetCustomers(ctx context.Context, ids []string) (map[string]*client.Customer, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batches++
+	found := make(map[string]*client.Customer, len(ids))
+	for _, id := range ids {
+		f.calls[id]++
+		if customer, ok := f.customers[id]; ok {
+			found[id] = customer
+		}
+	}
+	return found, nil
+}
+
+type fakeProductClient struct {
+	mutex    sync.Mutex
+	products map[string]*client.Product
+	calls    map[string]int
+	batches  int
+}
+
+func (f *fakeProductClient) GetProduct(ctx context.Context, id string) (*client.Product, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.calls[id]++
+	product, ok := f.products[id]
+	if !ok {
+		return nil, client.ErrNotFound
+	}
+	return product, nil
+}
+
+func (f *fakeProductClient) GetProducts(ctx context.Context, ids []string) (map[string]*client.Product, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.batches++
+	found := make(map[string]*client.Product, len(ids))
+	for _, id := range ids {
+		f.calls[id]++
+		if product, ok := f.products[id]; ok {
+			found[id] = product
+		}
+	}
+	return found, nil
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func setupGateway(t *testing.T) (http.Handler, *fakeCustomerClient, *fakeProductClient) {
+	customers := &fakeCustomerClient{
+		customers: map[string]*client.Customer{
+			"customer-1": {ID: "customer-1", Name: "John Doe", Email: "john.doe@example.com", Active: true, Status: "ACTIVE"},
+		},
+		calls: map[string]int{},
+	}
+	products := &fakeProductClient{
+		products: map[string]*client.Product{
+			"product-1": {ID: "product-1", Name: "Laptop", Price: money.New(99999, "USD"), Category: "Electronics", Active: true},
+			"product-2": {ID: "product-2", Name: "Mouse", Price: money.New(1999, "USD"), Category: "Electronics", Active: true},
+		},
+		calls: map[string]int{},
+	}
+
+	repo := repository.NewMemoryOrderRepository()
+	for _, order := range []*model.Order{
+		{ID: "order-1", CustomerID: "customer-1", ProductIDs: []string{"product-1", "product-2"}, Total: money.New(101998, "USD")},
+		{ID: "order-2", CustomerID: "customer-1", ProductIDs: []string{"product-2", "product-missing"}},
+	} {
+		_, err := repo.Create(context.Background(), order)
+		require.NoError(t, err)
+	}
+
+	orders := service.NewOrderService(repo, customers, products, service.Options{})
+	return NewHandler(customers, products, orders), customers, products
+}
+
+func execute(t *testing.T, handler http.Handler, query string) graphqlResponse {
+	body, err := json.Marshal(map[string]string{"query": query})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	var resp graphqlResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestHandler_CustomerWithProducts(t *testing.T) {
+	// Arrange
+	handler, customers, products := setupGateway(t)
+
+	// Act
+	resp := execute(t, handler, `{
+		customer(id: "customer-1") {
+			name
+			products { id name }
+			orders { id products { id } customer { email } }
+		}
+	}`)
+
+	// Assert
+	require.Empty(t, resp.Errors)
+	var data struct {
+		Customer struct {
+			Name     string `json:"name"`
+			Products []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"products"`
+			Orders []struct {
+				ID       string `json:"id"`
+				Products []*struct {
+					ID string `json:"id"`
+				} `json:"products"`
+				Customer struct {
+					Email string `json:"email"`
+				} `json:"customer"`
+			} `json:"orders"`
+		} `json:"customer"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Data, &data))
+	assert.Equal(t, "John Doe", data.Customer.Name)
+	assert.Len(t, data.Customer.Products, 2)
+	require.Len(t, data.Customer.Orders, 2)
+	for _, order := range data.Customer.Orders {
+		assert.Equal(t, "john.doe@example.com", order.Customer.Email)
+	}
+
+	// Every entity is fetched once even though the query references it repeatedly
+	assert.Equal(t, 1, customers.calls["customer-1"])
+	assert.Equal(t, 1, products.calls["product-1"])
+	assert.Equal(t, 1, products.calls["product-2"])
+	assert.Equal(t, 1, products.calls["product-missing"])
+	assert.Equal(t, 1, products.batches)
+}
+
+func TestHandler_FieldSelection(t *testing.T) {
+	// Arrange
+	handler, _, products := setupGateway(t)
+
+	// Act
+	resp := execute(t, handler, `{ customer(id: "customer-1") { name } }`)
+
+	// Assert
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"customer":{"name":"John Doe"}}`, string(resp.Data))
+	assert.Empty(t, products.calls)
+}
+
+func TestHandler_Amounts(t *testing.T) {
+	// Arrange
+	handler, _, _ := setupGateway(t)
+
+	// Act
+	resp := execute(t, handler, `{
+		product(id: "product-1") { price currency }
+		order(id: "order-1") { total currency }
+	}`)
+
+	// Assert
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"product":{"price":999.99,"currency":"USD"},"order":{"total":1019.98,"currency":"USD"}}`, string(resp.Data))
+}
+
+func TestHandler_MissingEntities(t *testing.T) {
+	// Arrange
+	handler, _, _ := setupGateway(t)
+
+	// Act
+	resp := execute(t, handler, `{
+		customer(id: "non-existent") { id }
+		products(ids: ["product-1", "product-missing"]) { id }
+		order(id: "non-existent") { id }
+	}`)
+
+	// Assert
+	require.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"customer":null,"products":[{"id":"product-1"},null],"order":null}`, string(resp.Data))
+}
+
+func TestHandler_LoadersArePerRequest(t *testing.T) {
+	// Arrange
+	handler, customers, _ := setupGateway(t)
+
+	// Act
+	execute(t, handler, `{ customer(id: "customer-1") { id } }`)
+	execute(t, handler, `{ customer(id: "customer-1") { id } }`)
+
+	// Assert
+	assert.Equal(t, 2, customers.calls["customer-1"])
+}
@@ -0,0 +1,102 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver
+// implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.84
+
+import (
+	"context"
+	"errors"
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+)
+
+// Orders is the resolver for the orders field.
+func (r *customerResolver) Orders(ctx context.Context, obj *client.Customer) ([]*model.OrderResponse, error) {
+	return r.orders.GetOrdersByCustomerID(ctx, obj.ID)
+}
+
+// Products is the resolver for the products field.
+func (r *customerResolver) Products(ctx context.Context, obj *client.Customer) ([]*client.Product, error) {
+	orders, err := r.orders.GetOrdersByCustomerID(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, order := range orders {
+		for _, id := range order.ProductIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	products, err := loadersFor(ctx).products.LoadAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]*client.Product, 0, len(products))
+	for _, product := range products {
+		if product != nil {
+			existing = append(existing, product)
+		}
+	}
+	return existing, nil
+}
+
+// Customer is the resolver for the customer field.
+func (r *orderResolver) Customer(ctx context.Context, obj *model.OrderResponse) (*client.Customer, error) {
+	return loadersFor(ctx).customers.Load(ctx, obj.CustomerID)
+}
+
+// Products is the resolver for the products field.
+func (r *orderResolver) Products(ctx context.Context, obj *model.OrderResponse) ([]*client.Product, error) {
+	return loadersFor(ctx).products.LoadAll(ctx, obj.ProductIDs)
+}
+
+// Customer is the resolver for the customer field.
+func (r *queryResolver) Customer(ctx context.Context, id string) (*client.Customer, error) {
+	return loadersFor(ctx).customers.Load(ctx, id)
+}
+
+// Customers is the resolver for the customers field.
+func (r *queryResolver) Customers(ctx context.Context, ids []string) ([]*client.Customer, error) {
+	return loadersFor(ctx).customers.LoadAll(ctx, ids)
+}
+
+// Product is the resolver for the product field.
+func (r *queryResolver) Product(ctx context.Context, id string) (*client.Product, error) {
+	return loadersFor(ctx).products.Load(ctx, id)
+}
+
+// Products is the resolver for the products field.
+func (r *queryResolver) Products(ctx context.Context, ids []string) ([]*client.Product, error) {
+	return loadersFor(ctx).products.LoadAll(ctx, ids)
+}
+
+// Order is the resolver for the order field.
+func (r *queryResolver) Order(ctx context.Context, id string) (*model.OrderResponse, error) {
+	order, err := r.orders.GetOrderByID(ctx, id)
+	if errors.Is(err, model.ErrOrderNotFound) {
+		return nil, nil
+	}
+	return order, err
+}
+
+// Customer returns CustomerResolver implementation.
+func (r *Resolver) Customer() CustomerResolver { return &customerResolver{r} }
+
+// Order returns OrderResolver implementation.
+func (r *Resolver) Order() OrderResolver { return &orderResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type customerResolver struct{ *Resolver }
+type orderResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
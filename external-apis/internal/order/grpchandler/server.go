@@ -0,0 +1,313 @@
+package grpchandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"unicode/utf8"
+
+	orderv1 "external-apis/api/order/v1"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/logger"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var log = logger.New("order/grpchandler")
+
+// maxReasonLength is the longest reason a status change accepts, as on the
+// REST API
+const maxReasonLength = 500
+
+// OrderServer exposes the order service over gRPC and, through the gateway
+// generated from the same definition, over REST
+type OrderServer struct {
+	orderv1.UnimplementedOrderServiceServer
+	service service.OrderService
+}
+
+// NewOrderServer creates a new order gRPC server
+func NewOrderServer(service service.OrderService) *OrderServer {
+	return &OrderServer{
+		service: service,
+	}
+}
+
+// Register registers the order service on the gRPC server
+func (s *OrderServer) Register(server *grpc.Server) {
+	orderv1.RegisterOrderServiceServer(server, s)
+}
+
+// Gateway returns the REST gateway of the order service, routed by the http
+// options of the proto definition. It calls the server in process, so
+// requests go through the HTTP middleware instead of the gRPC interceptors.
+func (s *OrderServer) Gateway(ctx context.Context) (http.Handler, error) {
+	mux := runtime.NewServeMux()
+	if err := orderv1.RegisterOrderServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, err
+	}
+	return mux, nil
+}
+
+// GetOrder retrieves an order by ID
+func (s *OrderServer) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.Order, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Order ID is required")
+	}
+
+	order, err := s.service.GetOrderByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(order), nil
+}
+
+// ListOrders retrieves all orders, or those of a customer
+func (s *OrderServer) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	var orders []*model.OrderResponse
+	var err error
+	if req.GetCustomerId() != "" {
+		orders, err = s.service.GetOrdersByCustomerID(ctx, req.GetCustomerId())
+	} else {
+		orders, err = s.service.GetAllOrders(ctx, req.GetSort())
+	}
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &orderv1.ListOrdersResponse{
+		Orders: make([]*orderv1.Order, len(orders)),
+	}
+	for i, order := range orders {
+		resp.Orders[i] = toProto(order)
+	}
+
+	return resp, nil
+}
+
+// CreateOrder creates an order enriched with customer and product data
+func (s *OrderServer) CreateOrder(ctx context.Context, req *orderv1.CreateOrderRequest) (*orderv1.Order, error) {
+	if req.GetCustomerId() == "" || len(req.GetProductIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "customer_id and product_ids are required")
+	}
+	for _, id := range req.GetProductIds() {
+		if id == "" {
+			return nil, status.Error(codes.InvalidArgument, "product_ids cannot be empty")
+		}
+	}
+	if len(req.GetCouponCode()) > 32 {
+		return nil, status.Error(codes.InvalidArgument, "coupon_code must be at most 32 characters")
+	}
+
+	order, err := s.service.CreateOrder(ctx, model.CreateOrderRequest{
+		CustomerID: req.GetCustomerId(),
+		ProductIDs: req.GetProductIds(),
+		CouponCode: req.GetCouponCode(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(order), nil
+}
+
+// DeleteOrder deletes an order
+func (s *OrderServer) DeleteOrder(ctx context.Context, req *orderv1.DeleteOrderRequest) (*orderv1.DeleteOrderResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Order ID is required")
+	}
+
+	if err := s.service.DeleteOrder(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &orderv1.DeleteOrderResponse{}, nil
+}
+
+// GetOrderHistory retrieves the status changes of an order, oldest first
+func (s *OrderServer) GetOrderHistory(ctx context.Context, req *orderv1.GetOrderHistoryRequest) (*orderv1.GetOrderHistoryResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Order ID is required")
+	}
+
+	history, err := s.service.GetOrderHistory(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &orderv1.GetOrderHistoryResponse{
+		Transitions: make([]*orderv1.StatusTransition, len(history)),
+	}
+	for i, transition := range history {
+		resp.Transitions[i] = &orderv1.StatusTransition{
+			From:   string(transition.From),
+			To:     string(transition.To),
+			Actor:  transition.Actor,
+			Reason: transition.Reason,
+			At:     timestamppb.New(transition.At),
+		}
+	}
+
+	return resp, nil
+}
+
+// ConfirmOrder moves an order to CONFIRMED
+func (s *OrderServer) ConfirmOrder(ctx context.Context, req *orderv1.TransitionOrderRequest) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, req, model.StatusConfirmed)
+}
+
+// ShipOrder moves a CONFIRMED order to SHIPPED
+func (s *OrderServer) ShipOrder(ctx context.Context, req *orderv1.TransitionOrderRequest) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, req, model.StatusShipped)
+}
+
+// DeliverOrder moves a SHIPPED order to DELIVERED
+func (s *OrderServer) DeliverOrder(ctx context.Context, req *orderv1.TransitionOrderRequest) (*orderv1.Order, error) {
+	return s.transitionOrder(ctx, req, model.StatusDelivered)
+}
+
+// CancelOrder moves an order to CANCELLED, releasing its stock, reversing
+// its loyalty points and refunding its total
+func (s *OrderServer) CancelOrder(ctx context.Context, req *orderv1.TransitionOrderRequest) (*orderv1.Order, error) {
+	if err := validateTransition(req); err != nil {
+		return nil, err
+	}
+
+	order, err := s.service.CancelOrder(ctx, req.GetId(), model.TransitionRequest{Reason: req.GetReason()})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(order), nil
+}
+
+// ReEnrichOrder fetches the customer, products and tax a partially enriched
+// order is missing
+func (s *OrderServer) ReEnrichOrder(ctx context.Context, req *orderv1.ReEnrichOrderRequest) (*orderv1.Order, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Order ID is required")
+	}
+
+	order, err := s.service.ReEnrichOrder(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(order), nil
+}
+
+// transitionOrder moves the order of the request to a status
+func (s *OrderServer) transitionOrder(ctx context.Context, req *orderv1.TransitionOrderRequest, to model.OrderStatus) (*orderv1.Order, error) {
+	if err := validateTransition(req); err != nil {
+		return nil, err
+	}
+
+	order, err := s.service.TransitionOrder(ctx, req.GetId(), to, model.TransitionRequest{Reason: req.GetReason()})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(order), nil
+}
+
+// validateTransition checks the order ID and reason of a status change
+func validateTransition(req *orderv1.TransitionOrderRequest) error {
+	if req.GetId() == "" {
+		return status.Error(codes.InvalidArgument, "Order ID is required")
+	}
+	if utf8.RuneCountInString(req.GetReason()) > maxReasonLength {
+		return status.Error(codes.InvalidArgument, "reason must be at most 500 characters")
+	}
+	return nil
+}
+
+// toProto converts an order response into its protobuf representation
+func toProto(order *model.OrderResponse) *orderv1.Order {
+	products := make([]*orderv1.OrderProduct, len(order.Products))
+	for i, product := range order.Products {
+		products[i] = &orderv1.OrderProduct{
+			Id:          product.ID,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price.Float64(),
+			Category:    product.Category,
+			ListPrice:   product.ListPrice.Float64(),
+			Discount:    product.Discount.Float64(),
+			PromotionId: product.PromotionID,
+			Currency:    product.Price.Currency,
+		}
+	}
+	taxLines := make([]*orderv1.TaxLine, len(order.TaxLines))
+	for i, line := range order.TaxLines {
+		taxLines[i] = &orderv1.TaxLine{
+			Jurisdiction: line.Jurisdiction,
+			Rate:         line.Rate,
+			Taxable:      line.Taxable.Float64(),
+			Amount:       line.Amount.Float64(),
+		}
+	}
+
+	tax, _ := order.Tax.Float64()
+	total, _ := order.Total.Float64()
+	resp := &orderv1.Order{
+		Id:         order.ID,
+		CustomerId: order.CustomerID,
+		ProductIds: order.ProductIDs,
+		CouponCode: order.CouponCode,
+		Products:   products,
+		TaxLines:   taxLines,
+		Tax:        tax,
+		Total:      total,
+		Status:     string(order.Status),
+		CreatedAt:  timestamppb.New(order.CreatedAt),
+		Currency:   order.Currency,
+	}
+	if customer := order.Customer; customer != nil {
+		resp.Customer = &orderv1.OrderCustomer{
+			Id:    customer.ID,
+			Name:  customer.Name,
+			Email: customer.Email,
+			Phone: customer.Phone,
+		}
+	}
+	if enrichment := order.Enrichment; enrichment != nil {
+		resp.Enrichment = &orderv1.Enrichment{
+			Status:   enrichment.Status,
+			Missing:  enrichment.Missing,
+			Error:    enrichment.Error,
+			Attempts: int32(enrichment.Attempts),
+		}
+		if enrichment.NextAttemptAt != nil {
+			resp.Enrichment.NextAttemptAt = timestamppb.New(*enrichment.NextAttemptAt)
+		}
+	}
+
+	return resp
+}
+
+// toStatus maps service errors to gRPC status codes
+func toStatus(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, apperror.ErrConflict), errors.Is(err, apperror.ErrUnprocessable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, apperror.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, apperror.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	log.Ctx(ctx).WithError(err).Error("Unexpected order service error")
+	return status.Error(codes.Internal, "Internal server error occurred")
+}
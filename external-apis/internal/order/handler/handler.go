@@ -0,0 +1,600 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/rbac"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("order/handler")
+
+// OrderHandler handles HTTP requests for orders
+type OrderHandler struct {
+	service  service.OrderService
+	expander service.OrderExpander
+	ids      idgen.Generator
+}
+
+// NewOrderHandler creates a new order handler. Order IDs in request paths are
+// checked against ids.
+func NewOrderHandler(service service.OrderService, expander service.OrderExpander, ids idgen.Generator) *OrderHandler {
+	return &OrderHandler{
+		service:  service,
+		expander: expander,
+		ids:      ids,
+	}
+}
+
+// RegisterRoutes registers all order routes. Reads are public; writes go
+// through requireAuth.
+func (h *OrderHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	orders := router.Group("/orders", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		orders.GET("", h.GetAllOrders)
+		orders.GET("/:id", h.GetOrderByID)
+		orders.GET("/:id/saga", h.GetOrderSaga)
+		orders.GET("/:id/history", h.GetOrderHistory)
+		orders.GET("/customer/:customerId", h.GetOrdersByCustomerID)
+		orders.POST("", requireAuth, h.CreateOrder)
+		orders.POST("/reassign-customer", requireAuth, rbac.Require(rbac.PermissionDelete), h.ReassignCustomer)
+		orders.DELETE("/:id", requireAuth, h.DeleteOrder)
+		orders.POST("/:id/confirm", requireAuth, h.ConfirmOrder)
+		orders.POST("/:id/ship", requireAuth, h.ShipOrder)
+		orders.POST("/:id/deliver", requireAuth, h.DeliverOrder)
+		orders.POST("/:id/cancel", requireAuth, h.CancelOrder)
+		orders.POST("/:id/re-enrich", requireAuth, h.ReEnrichOrder)
+	}
+
+	router.POST("/shipping/rates", h.QuoteShipping)
+}
+
+// GetOrderByID godoc
+// @Summary Get order by ID
+// @Description Get an order by its ID. The customer and products are referred to by ID unless expanded; an expansion whose service is unavailable either fails the request with 502 or, when configured as partial, is left out and explained in expandErrors. Enriched orders also carry their snapshot: the customer, address and line items as they were when the order was enriched, which later catalog and customer changes do not rewrite; expanding returns the current values next to it.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param expand query string false "Relationships to inline with their current data (customer, products), comma-separated"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id} [get]
+func (h *OrderHandler) GetOrderByID(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		response.BadRequest(c, "Order ID is required")
+		return
+	}
+
+	expand, err := model.ParseExpand(c.Query("expand"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"order_id":   id,
+		"request_id": c.GetString("request_id"),
+	}).Info("Getting order by ID")
+
+	order, err := h.service.GetOrderByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Order not found")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("order_id", id).Error("Failed to get order")
+		response.InternalServerError(c, "Failed to retrieve order")
+		return
+	}
+
+	if !h.expandOrders(c, expand, order) {
+		return
+	}
+
+	response.OK(c, order)
+}
+
+// GetOrderSaga godoc
+// @Summary Get the creation saga of an order
+// @Description Get the progress of the saga that created an order: the status of every step (reserve-stock, validate-customer, persist-order, confirm-stock) and of their compensations. Meant for debugging orders whose creation got stuck.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.SuccessResponse{data=saga.Saga}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/saga [get]
+func (h *OrderHandler) GetOrderSaga(c *gin.Context) {
+	id := c.Param("id")
+
+	orderSaga, err := h.service.GetOrderSaga(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Order saga not found")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("order_id", id).Error("Failed to get order saga")
+		response.InternalServerError(c, "Failed to retrieve order saga")
+		return
+	}
+
+	response.OK(c, orderSaga)
+}
+
+// GetAllOrders godoc
+// @Summary Get all orders
+// @Description Get a list of all orders, oldest first unless sorted otherwise
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param expand query string false "Relationships to inline with their current data (customer, products), comma-separated"
+// @Param sort query string false "Sort order (created_at, created_at_desc)"
+// @Success 200 {object} response.SuccessResponse{data=[]model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/orders [get]
+func (h *OrderHandler) GetAllOrders(c *gin.Context) {
+	expand, err := model.ParseExpand(c.Query("expand"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	sort := c.Query("sort")
+	log.Ctx(c.Request.Context()).WithField("sort", sort).Info("Getting all orders")
+
+	orders, err := h.service.GetAllOrders(c.Request.Context(), sort)
+	if err != nil {
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to get all orders")
+		response.InternalServerError(c, "Failed to retrieve orders")
+		return
+	}
+
+	if !h.expandOrders(c, expand, orders...) {
+		return
+	}
+
+	response.OK(c, orders)
+}
+
+// GetOrdersByCustomerID godoc
+// @Summary Get orders by customer
+// @Description Get all orders placed by a customer
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param customerId path string true "Customer ID"
+// @Param expand query string false "Relationships to inline with their current data (customer, products), comma-separated"
+// @Success 200 {object} response.SuccessResponse{data=[]model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/orders/customer/{customerId} [get]
+func (h *OrderHandler) GetOrdersByCustomerID(c *gin.Context) {
+	customerID := c.Param("customerId")
+
+	if customerID == "" {
+		response.BadRequest(c, "Customer ID is required")
+		return
+	}
+
+	expand, err := model.ParseExpand(c.Query("expand"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"customer_id": customerID,
+		"request_id":  c.GetString("request_id"),
+	}).Info("Getting orders by customer")
+
+	orders, err := h.service.GetOrdersByCustomerID(c.Request.Context(), customerID)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("customer_id", customerID).Error("Failed to get orders by customer")
+		response.InternalServerError(c, "Failed to retrieve orders")
+		return
+	}
+
+	if !h.expandOrders(c, expand, orders...) {
+		return
+	}
+
+	response.OK(c, orders)
+}
+
+// CreateOrder godoc
+// @Summary Create a new order
+// @Description Create an order enriched with data from the customer and product services. Products are priced with the running promotions that apply to the customer, and the one unlocked by the coupon code, if any.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body model.CreateOrderRequest true "Order data"
+// @Success 201 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders [post]
+func (h *OrderHandler) CreateOrder(c *gin.Context) {
+	var req model.CreateOrderRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create order")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"customer_id": req.CustomerID,
+		"products":    len(req.ProductIDs),
+		"request_id":  c.GetString("request_id"),
+	}).Info("Creating new order")
+
+	order, err := h.service.CreateOrder(c.Request.Context(), req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to create order")
+
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		response.InternalServerError(c, "Failed to create order")
+		return
+	}
+
+	response.Created(c, order)
+}
+
+// DeleteOrder godoc
+// @Summary Delete an order
+// @Description Delete an order by ID
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id} [delete]
+func (h *OrderHandler) DeleteOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		response.BadRequest(c, "Order ID is required")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"order_id":   id,
+		"request_id": c.GetString("request_id"),
+	}).Info("Deleting order")
+
+	err := h.service.DeleteOrder(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Order not found")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("order_id", id).Error("Failed to delete order")
+		response.InternalServerError(c, "Failed to delete order")
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Order deleted successfully")
+}
+
+// ReassignCustomer godoc
+// @Summary Reassign customer orders
+// @Description Move every order of a customer to another, e.g. when the customer service publishes customer.merged. The target customer must exist.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param reassignment body model.ReassignCustomerRequest true "Customers to move the orders between"
+// @Success 200 {object} response.SuccessResponse{data=model.ReassignCustomerResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/reassign-customer [post]
+func (h *OrderHandler) ReassignCustomer(c *gin.Context) {
+	var req model.ReassignCustomerRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for reassign customer")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"from_customer_id": req.FromCustomerID,
+		"to_customer_id":   req.ToCustomerID,
+		"request_id":       c.GetString("request_id"),
+	}).Info("Reassigning customer orders")
+
+	reassigned, err := h.service.ReassignCustomer(c.Request.Context(), req)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to reassign customer orders")
+
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		response.InternalServerError(c, "Failed to reassign customer orders")
+		return
+	}
+
+	response.OK(c, reassigned)
+}
+
+// GetOrderHistory godoc
+// @Summary Get the status history of an order
+// @Description Get every status transition of an order, oldest first: the status it moved from and to, who moved it, when and why. Orders are placed CREATED and become ENRICHED once the customer, products and tax are fetched.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.StatusTransition}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/history [get]
+func (h *OrderHandler) GetOrderHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	history, err := h.service.GetOrderHistory(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Order not found")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("order_id", id).Error("Failed to get order history")
+		response.InternalServerError(c, "Failed to retrieve order history")
+		return
+	}
+
+	response.OK(c, history)
+}
+
+// ConfirmOrder godoc
+// @Summary Confirm an order
+// @Description Move an ENRICHED order to CONFIRMED. Orders still being enriched cannot be confirmed.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param transition body model.TransitionRequest false "Reason recorded in the order's history"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/confirm [post]
+func (h *OrderHandler) ConfirmOrder(c *gin.Context) {
+	h.transitionOrder(c, model.StatusConfirmed)
+}
+
+// ShipOrder godoc
+// @Summary Ship an order
+// @Description Move a CONFIRMED order to SHIPPED
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param transition body model.TransitionRequest false "Reason recorded in the order's history"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/ship [post]
+func (h *OrderHandler) ShipOrder(c *gin.Context) {
+	h.transitionOrder(c, model.StatusShipped)
+}
+
+// DeliverOrder godoc
+// @Summary Deliver an order
+// @Description Move a SHIPPED order to DELIVERED, its final status
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param transition body model.TransitionRequest false "Reason recorded in the order's history"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/deliver [post]
+func (h *OrderHandler) DeliverOrder(c *gin.Context) {
+	h.transitionOrder(c, model.StatusDelivered)
+}
+
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Description Move an order to CANCELLED, its final status. Orders can be cancelled until they are shipped. The stock reserved for the order is released, the loyalty points collected with it are reversed and its total is refunded; the refund is returned on the order, PENDING when it is to be paid out by hand and FAILED when the payment provider did not accept it.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param transition body model.TransitionRequest false "Reason recorded in the order's history and on its refund"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	h.changeStatus(c, model.StatusCancelled, h.service.CancelOrder)
+}
+
+// ReEnrichOrder godoc
+// @Summary Re-enrich an order
+// @Description Fetch the customer, products and tax an order was placed without because a downstream service was unavailable, instead of waiting for the next background attempt. An order whose enrichment failed, e.g. because its customer was inactive, is attempted again too. A fully enriched order is moved to ENRICHED; a part that still cannot be fetched fails the request, and stays missing on the order.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.SuccessResponse{data=model.OrderResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/re-enrich [post]
+func (h *OrderHandler) ReEnrichOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"order_id":   id,
+		"request_id": c.GetString("request_id"),
+	}).Info("Re-enriching order")
+
+	order, err := h.service.ReEnrichOrder(c.Request.Context(), id)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("order_id", id).Error("Failed to re-enrich order")
+		response.InternalServerError(c, "Failed to re-enrich order")
+		return
+	}
+
+	response.OK(c, order)
+}
+
+// transitionOrder moves the order of the request to a status, with the
+// reason of the optional request body
+func (h *OrderHandler) transitionOrder(c *gin.Context, to model.OrderStatus) {
+	h.changeStatus(c, to, func(ctx context.Context, id string, req model.TransitionRequest) (*model.OrderResponse, error) {
+		return h.service.TransitionOrder(ctx, id, to, req)
+	})
+}
+
+// changeStatus moves the order of the request to a status through change,
+// with the reason of the optional request body
+func (h *OrderHandler) changeStatus(c *gin.Context, to model.OrderStatus, change func(ctx context.Context, id string, req model.TransitionRequest) (*model.OrderResponse, error)) {
+	id := c.Param("id")
+
+	var req model.TransitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for order transition")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"order_id":   id,
+		"status":     to,
+		"request_id": c.GetString("request_id"),
+	}).Info("Changing order status")
+
+	order, err := change(c.Request.Context(), id, req)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("order_id", id).Error("Failed to change order status")
+		response.InternalServerError(c, "Failed to change order status")
+		return
+	}
+
+	response.OK(c, order)
+}
+
+// expandOrders inlines the relationships selected with ?expand into the
+// orders. It writes the error response and returns false if that fails.
+func (h *OrderHandler) expandOrders(c *gin.Context, expand model.Expand, orders ...*model.OrderResponse) bool {
+	if !expand.Any() {
+		return true
+	}
+
+	if err := h.expander.Expand(c.Request.Context(), orders, expand); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to expand orders")
+
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return false
+		}
+
+		response.InternalServerError(c, "Failed to expand orders")
+		return false
+	}
+	return true
+}
+
+// QuoteShipping godoc
+// @Summary Quote shipping rates
+// @Description Quote the ways products can be shipped to an address: the carrier, service level, cost and estimated delivery of each, cheapest first. Products are charged at the greater of their weight and volumetric weight; a product listed twice ships twice. The list is empty when no shipping rates are configured or no carrier ships to the address.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body model.ShippingRatesRequest true "Products and address"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ShippingRate}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 503 {object} response.ErrorResponse
+// @Router /api/v1/shipping/rates [post]
+func (h *OrderHandler) QuoteShipping(c *gin.Context) {
+	var req model.ShippingRatesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for shipping rates")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	rates, err := h.service.QuoteShipping(c.Request.Context(), req)
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to quote shipping")
+		response.InternalServerError(c, "Failed to quote shipping")
+		return
+	}
+
+	response.OK(c, rates)
+}
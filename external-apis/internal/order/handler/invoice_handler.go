@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"external-apis/internal/order/invoice"
+	"external-apis/internal/order/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// InvoiceHandler handles HTTP requests for the invoices and receipts of
+// orders
+type InvoiceHandler struct {
+	service service.InvoiceService
+	ids     idgen.Generator
+}
+
+// NewInvoiceHandler creates a new invoice handler. Order IDs in request
+// paths are checked against ids.
+func NewInvoiceHandler(service service.InvoiceService, ids idgen.Generator) *InvoiceHandler {
+	return &InvoiceHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the invoice routes. Invoices show the customer's
+// details, so every route goes through requireAuth.
+func (h *InvoiceHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	invoices := router.Group("/orders/:id/invoice", middleware.ValidID(h.ids.Valid, "id"), requireAuth)
+	{
+		invoices.GET("", h.GetInvoice)
+		invoices.GET("/documents/:documentId", h.GetInvoiceDocument)
+	}
+}
+
+// GetInvoice godoc
+// @Summary Get the invoice of an order
+// @Description Render the invoice or receipt of an enriched order as a PDF or HTML document, branded with the configured company details. With async=true the document is generated in the background instead: the response is 202 with a pending document to poll, whose URL downloads it once it is READY. Orders still being enriched have no invoice yet (409).
+// @Tags orders
+// @Produce application/pdf
+// @Produce text/html
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param format query string false "Document format: pdf (default) or html"
+// @Param type query string false "Document type: invoice (default) or receipt"
+// @Param async query bool false "Generate the document in the background and return where to download it"
+// @Success 200 {file} file
+// @Success 202 {object} response.SuccessResponse{data=model.InvoiceDocument}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/invoice [get]
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	format, err := invoice.ParseFormat(c.Query("format"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	kind, err := invoice.ParseKind(c.Query("type"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if c.Query("async") == "true" {
+		document, err := h.service.GenerateInvoice(c.Request.Context(), c.Param("id"), kind, format)
+		if err != nil {
+			h.invoiceError(c, err)
+			return
+		}
+
+		response.Accepted(c, document)
+		return
+	}
+
+	file, err := h.service.RenderInvoice(c.Request.Context(), c.Param("id"), kind, format)
+	if err != nil {
+		h.invoiceError(c, err)
+		return
+	}
+
+	// PDFs are downloaded; HTML documents open in the browser
+	disposition := "inline"
+	if format == invoice.FormatPDF {
+		disposition = "attachment"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, file.Name))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// GetInvoiceDocument godoc
+// @Summary Get an invoice document generated in the background
+// @Description Get the status of an invoice or receipt requested with async=true and, once it is READY, the URL it is downloaded from
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param documentId path string true "Document ID"
+// @Success 200 {object} response.SuccessResponse{data=model.InvoiceDocument}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/orders/{id}/invoice/documents/{documentId} [get]
+func (h *InvoiceHandler) GetInvoiceDocument(c *gin.Context) {
+	document, err := h.service.GetInvoiceDocument(c.Request.Context(), c.Param("id"), c.Param("documentId"))
+	if err != nil {
+		h.invoiceError(c, err)
+		return
+	}
+
+	response.OK(c, document)
+}
+
+// invoiceError maps invoice service errors to responses
+func (h *InvoiceHandler) invoiceError(c *gin.Context, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+		"order_id":    c.Param("id"),
+		"document_id": c.Param("documentId"),
+		"request_id":  c.GetString("request_id"),
+	}).Error("Failed to process invoice")
+	response.InternalServerError(c, "Failed to process invoice")
+}
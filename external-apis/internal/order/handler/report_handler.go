@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/exporter"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles HTTP requests for the sales reports
+type ReportHandler struct {
+	service service.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(service service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes registers the report routes. Sales figures are not public,
+// so every route goes through requireAuth.
+func (h *ReportHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	reports := router.Group("/reports", requireAuth)
+	{
+		reports.GET("/sales", h.GetSalesReport)
+		reports.GET("/sales/export", h.ExportSalesReport)
+	}
+}
+
+// GetSalesReport godoc
+// @Summary Get the daily sales report
+// @Description Get the sales of every day of a range by product, category or customer segment, with their totals. Customers are new on the day of their first order and returning after. Orders count once enriched and until cancelled, at the prices and tax of their snapshot. The report is read from tables aggregated in the background; refreshedAt is when they were last aggregated.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param from query string false "First day, YYYY-MM-DD in UTC; defaults to 29 days before to"
+// @Param to query string false "Last day, YYYY-MM-DD in UTC; defaults to today"
+// @Param group_by query string false "Dimension of the rows" Enums(product, category, customer_segment) default(product)
+// @Success 200 {object} response.SuccessResponse{data=model.SalesReport}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/reports/sales [get]
+func (h *ReportHandler) GetSalesReport(c *gin.Context) {
+	query, err := parseSalesReportQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.service.GetSalesReport(c.Request.Context(), query)
+	if err != nil {
+		h.reportError(c, err)
+		return
+	}
+
+	response.OK(c, report)
+}
+
+// ExportSalesReport godoc
+// @Summary Export the daily sales report
+// @Description Stream the rows of a sales report as a CSV or NDJSON file, gzipped when the client accepts it. CSV columns are date, groupBy, key, label, orders, units, revenue, tax and total.
+// @Tags reports
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "File format" Enums(csv, ndjson) default(csv)
+// @Param from query string false "First day, YYYY-MM-DD in UTC; defaults to 29 days before to"
+// @Param to query string false "Last day, YYYY-MM-DD in UTC; defaults to today"
+// @Param group_by query string false "Dimension of the rows" Enums(product, category, customer_segment) default(product)
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/reports/sales/export [get]
+func (h *ReportHandler) ExportSalesReport(c *gin.Context) {
+	format, err := exporter.ParseFormat(c.Query("format"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	query, err := parseSalesReportQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"format":   format,
+		"group_by": query.GroupBy,
+	}).Info("Exporting sales report")
+
+	writer := exporter.NewWriter(c, format, "sales-"+query.GroupBy, model.SalesReportCSVHeader)
+	err = h.service.ExportSalesReport(c.Request.Context(), query, func(row *model.SalesReportRow) error {
+		return writer.Write(row)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Once rows have been sent the status cannot change; the transfer is cut
+	// short so the client does not mistake the rows for the full export
+	if writer.Started() {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("rows", writer.Rows()).Warn("Sales report export interrupted")
+		writer.Abort()
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.GatewayTimeout(c, "Request deadline exceeded")
+		return
+	}
+	h.reportError(c, err)
+}
+
+// parseSalesReportQuery reads the days and dimension of a sales report from
+// the query string
+func parseSalesReportQuery(c *gin.Context) (model.SalesReportQuery, error) {
+	return model.ParseSalesReportQuery(c.Query("from"), c.Query("to"), c.Query("group_by"), time.Now())
+}
+
+// reportError maps report service errors to responses
+func (h *ReportHandler) reportError(c *gin.Context, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+		"group_by":   c.Query("group_by"),
+		"request_id": c.GetString("request_id"),
+	}).Error("Failed to get sales report")
+	response.InternalServerError(c, "Failed to get sales report")
+}
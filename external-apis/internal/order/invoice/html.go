@@ -0,0 +1,116 @@
+package invoice
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"regexp"
+	"time"
+)
+
+// defaultTemplate is the HTML template documents are rendered with unless
+// another one is configured
+//
+//go:embed invoice.html
+var defaultTemplate string
+
+// DefaultAccentColor is the accent color of documents without branding
+const DefaultAccentColor = "#1f4e79"
+
+// accentColor matches the #rrggbb colors allowed as accent color
+var accentColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Renderer renders documents as PDF or HTML
+type Renderer struct {
+	html *template.Template
+}
+
+// NewRenderer creates a renderer whose HTML documents use the template in
+// templateFile, or the built-in template when it is empty. The template is
+// executed with a Document and the functions money, date and accent. PDF
+// documents have a fixed layout and only take the branding.
+func NewRenderer(templateFile string) (*Renderer, error) {
+	text := defaultTemplate
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read invoice template: %w", err)
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New("invoice").Funcs(template.FuncMap{
+		// The functions are replaced per document in renderHTML
+		"money":  func(float64) string { return "" },
+		"date":   formatDate,
+		"accent": func() template.CSS { return template.CSS(DefaultAccentColor) },
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invoice template: %w", err)
+	}
+	return &Renderer{html: tmpl}, nil
+}
+
+// File is a rendered document
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Render renders the document in format, pdf or html
+func (r *Renderer) Render(doc Document, format string) (*File, error) {
+	var data []byte
+	switch format {
+	case FormatPDF:
+		data = renderPDF(doc)
+	case FormatHTML:
+		var err error
+		if data, err = r.renderHTML(doc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+
+	return &File{Name: doc.Filename(format), ContentType: ContentType(format), Data: data}, nil
+}
+
+// renderHTML executes the HTML template with the document
+func (r *Renderer) renderHTML(doc Document) ([]byte, error) {
+	tmpl, err := r.html.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"money": doc.Money,
+		"accent": func() template.CSS {
+			return template.CSS(doc.accent())
+		},
+	})
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, doc); err != nil {
+		return nil, fmt.Errorf("failed to render invoice: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// accent returns the accent color of the document's branding, or the
+// default one when it is not a #rrggbb color
+func (d Document) accent() string {
+	if accentColor.MatchString(d.Branding.AccentColor) {
+		return d.Branding.AccentColor
+	}
+	return DefaultAccentColor
+}
+
+// formatDate prints the day of a time, e.g. 2024-01-31
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,127 @@
+package invoice
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Render_HTML(t *testing.T) {
+	branding := Branding{
+		CompanyName: "Acme <GmbH>",
+		Address:     []string{"Hauptstraße 1", "10115 Berlin"},
+		TaxID:       "DE123456789",
+		AccentColor: "#ff6600",
+		Currency:    "EUR",
+		Footer:      "Thank you for your order",
+	}
+	doc := NewDocument(testOrder(), KindInvoice, branding, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC))
+
+	t.Run("Built-in template", func(t *testing.T) {
+		// Arrange
+		renderer, err := NewRenderer("")
+		require.NoError(t, err)
+
+		// Act
+		file, err := renderer.Render(doc, FormatHTML)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "INV-order-123.html", file.Name)
+		assert.Equal(t, "text/html; charset=utf-8", file.ContentType)
+		html := string(file.Data)
+		assert.Contains(t, html, "Acme &lt;GmbH&gt;", "branding is escaped")
+		assert.Contains(t, html, "Tax ID: DE123456789")
+		assert.Contains(t, html, "color: #ff6600")
+		assert.Contains(t, html, "USD 49.98", "amounts are printed in the currency of the order")
+		assert.Contains(t, html, "Tax DE (19%)")
+		assert.Contains(t, html, "USD 142.79")
+		assert.NotContains(t, html, "EUR")
+		assert.Contains(t, html, "Issued: 2024-01-16")
+		assert.Contains(t, html, "Thank you for your order")
+	})
+
+	t.Run("Accent color that is not a color", func(t *testing.T) {
+		// Arrange
+		renderer, err := NewRenderer("")
+		require.NoError(t, err)
+		doc := doc
+		doc.Branding.AccentColor = "red; background: url(evil)"
+
+		// Act
+		file, err := renderer.Render(doc, FormatHTML)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, string(file.Data), "color: "+DefaultAccentColor)
+		assert.NotContains(t, string(file.Data), "evil")
+	})
+
+	t.Run("Refunded order", func(t *testing.T) {
+		// Arrange
+		renderer, err := NewRenderer("")
+		require.NoError(t, err)
+		doc := doc
+		doc.Refund = &model.Refund{Amount: money.New(14279, "USD"), Status: model.RefundSucceeded, Reason: "changed my mind"}
+
+		// Act
+		file, err := renderer.Render(doc, FormatHTML)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, string(file.Data), "Refund of USD 142.79: REFUNDED (changed my mind)")
+	})
+
+	t.Run("Configured template", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "invoice.html")
+		require.NoError(t, os.WriteFile(path, []byte(`<h1 style="color: {{accent}}">{{.Title}} {{.Number}} for {{.Customer.Name}}: {{money .Total}}</h1>`), 0o644))
+		renderer, err := NewRenderer(path)
+		require.NoError(t, err)
+
+		// Act
+		file, err := renderer.Render(doc, FormatHTML)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, `<h1 style="color: #ff6600">Invoice INV-order-123 for John Doe: USD 142.79</h1>`, string(file.Data))
+	})
+
+	t.Run("Missing template", func(t *testing.T) {
+		// Act
+		_, err := NewRenderer(filepath.Join(t.TempDir(), "missing.html"))
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid template", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "invoice.html")
+		require.NoError(t, os.WriteFile(path, []byte(`{{.Title`), 0o644))
+
+		// Act
+		_, err := NewRenderer(path)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		// Arrange
+		renderer, err := NewRenderer("")
+		require.NoError(t, err)
+
+		// Act
+		_, err = renderer.Render(doc, "docx")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
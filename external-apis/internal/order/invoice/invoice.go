@@ -0,0 +1,205 @@
+// Package invoice renders the invoices and receipts of orders as PDF or HTML
+// documents, branded with the details of the selling company.
+package invoice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/money"
+)
+
+// Formats of invoice documents
+const (
+	FormatPDF  = "pdf"
+	FormatHTML = "html"
+)
+
+// Kinds of invoice documents. A receipt confirms an order has been paid.
+const (
+	KindInvoice = "invoice"
+	KindReceipt = "receipt"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for a format other than pdf or html
+	ErrUnsupportedFormat = apperror.Validation("invoice format must be pdf or html")
+	// ErrUnsupportedKind is returned for a kind other than invoice or receipt
+	ErrUnsupportedKind = apperror.Validation("invoice type must be invoice or receipt")
+)
+
+// contentTypes maps each format to the content type of its documents
+var contentTypes = map[string]string{
+	FormatPDF:  "application/pdf",
+	FormatHTML: "text/html; charset=utf-8",
+}
+
+// ParseFormat validates a document format, defaulting to PDF
+func ParseFormat(format string) (string, error) {
+	if format == "" {
+		return FormatPDF, nil
+	}
+
+	format = strings.ToLower(format)
+	if _, ok := contentTypes[format]; !ok {
+		return "", ErrUnsupportedFormat
+	}
+	return format, nil
+}
+
+// ParseKind validates a document kind, defaulting to an invoice
+func ParseKind(kind string) (string, error) {
+	switch kind = strings.ToLower(kind); kind {
+	case "":
+		return KindInvoice, nil
+	case KindInvoice, KindReceipt:
+		return kind, nil
+	default:
+		return "", ErrUnsupportedKind
+	}
+}
+
+// Branding is what documents show of the company selling the order
+type Branding struct {
+	CompanyName string
+	// Address is printed below the company name, one line per entry
+	Address []string
+	Email   string
+	TaxID   string
+	// LogoURL is shown in HTML documents only
+	LogoURL string
+	// AccentColor is the #rrggbb color of headings and rules
+	AccentColor string
+	// Currency is the ISO 4217 code amounts are printed with when the order
+	// has no currency yet, e.g. its products were never enriched; amounts of
+	// an order are printed in its own currency
+	Currency string
+	Footer   string
+}
+
+// Line is a product of the order with the number of times it was ordered
+type Line struct {
+	ProductID   string
+	Description string
+	Quantity    int
+	UnitPrice   money.Money
+	// Discount is the promotion discount per unit
+	Discount money.Money
+	Amount   money.Money
+}
+
+// Document is the data an invoice or receipt is rendered from
+type Document struct {
+	Kind      string
+	Number    string
+	OrderID   string
+	Status    model.OrderStatus
+	IssuedAt  time.Time
+	OrderedAt time.Time
+	Branding  Branding
+	Customer  model.OrderCustomer
+	Lines     []Line
+	Subtotal  money.Money
+	TaxLines  []model.TaxLine
+	Tax       money.Money
+	Total     money.Money
+	// Refund is set when the order was cancelled and refunded
+	Refund *model.Refund
+}
+
+// NewDocument builds the document of an enriched order. Products ordered
+// more than once at the same price make up a single line.
+func NewDocument(order *model.Order, kind string, branding Branding, issuedAt time.Time) Document {
+	doc := Document{
+		Kind:      kind,
+		Number:    documentNumber(kind, order.ID),
+		OrderID:   order.ID,
+		Status:    order.Status,
+		IssuedAt:  issuedAt,
+		OrderedAt: order.CreatedAt,
+		Branding:  branding,
+		TaxLines:  order.TaxLines,
+		Tax:       order.Tax,
+		Total:     order.Total,
+		Refund:    order.Refund,
+	}
+	if order.Customer != nil {
+		doc.Customer = *order.Customer
+	}
+
+	// The amounts are worked out in minor units of the order's currency;
+	// the total of the order was worked out from the same prices, so they
+	// stay within range
+	lines := make(map[string]int, len(order.Products))
+	prices := make([]money.Money, 0, len(order.Products))
+	for _, product := range order.Products {
+		key := fmt.Sprintf("%s@%s", product.ID, product.Price)
+		if i, ok := lines[key]; ok {
+			doc.Lines[i].Quantity++
+			continue
+		}
+
+		unitPrice := product.Price
+		if product.ListPrice.IsPositive() {
+			unitPrice = product.ListPrice
+		}
+		lines[key] = len(doc.Lines)
+		prices = append(prices, product.Price)
+		doc.Lines = append(doc.Lines, Line{
+			ProductID:   product.ID,
+			Description: product.Name,
+			Quantity:    1,
+			UnitPrice:   unitPrice,
+			Discount:    product.Discount,
+		})
+	}
+
+	doc.Subtotal = money.New(0, order.Total.Currency)
+	for i, price := range prices {
+		doc.Lines[i].Amount, _ = price.Multiply(int64(doc.Lines[i].Quantity))
+		doc.Subtotal, _ = doc.Subtotal.Add(doc.Lines[i].Amount)
+	}
+	return doc
+}
+
+// Title is the heading of the document, e.g. Invoice
+func (d Document) Title() string {
+	if d.Kind == KindReceipt {
+		return "Receipt"
+	}
+	return "Invoice"
+}
+
+// Filename is the name the document is downloaded as
+func (d Document) Filename(format string) string {
+	return d.Number + "." + format
+}
+
+// Money formats an amount in its currency with the decimal places of the
+// currency, e.g. EUR 1234.50 or JPY 1500. Amounts without a currency are
+// printed in the currency of the branding.
+func (d Document) Money(amount money.Money) string {
+	if amount.Currency == "" {
+		amount.Currency = d.Branding.Currency
+		if amount.Currency == "" {
+			amount.Currency = money.DefaultCurrency
+		}
+	}
+	return amount.Currency + " " + amount.Decimal()
+}
+
+// documentNumber numbers the document of an order, e.g. INV-<order id>
+func documentNumber(kind, orderID string) string {
+	if kind == KindReceipt {
+		return "RCT-" + orderID
+	}
+	return "INV-" + orderID
+}
+
+// ContentType returns the content type of documents of a format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
@@ -0,0 +1,131 @@
+package invoice
+
+import (
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOrder is an enriched order of two mice, one of them discounted, and a
+// keyboard
+func testOrder() *model.Order {
+	return &model.Order{
+		ID:         "order-123",
+		CustomerID: "customer-456",
+		Customer:   &model.OrderCustomer{ID: "customer-456", Name: "John Doe", Email: "john.doe@example.com"},
+		Products: []model.OrderProduct{
+			{ID: "product-001", Name: "Wireless Mouse", Price: money.New(2499, "USD"), ListPrice: money.New(2999, "USD"), Discount: money.New(500, "USD")},
+			{ID: "product-002", Name: "Mechanical Keyboard", Price: money.New(7001, "USD")},
+			{ID: "product-001", Name: "Wireless Mouse", Price: money.New(2499, "USD"), ListPrice: money.New(2999, "USD"), Discount: money.New(500, "USD")},
+		},
+		TaxLines:  []model.TaxLine{{Jurisdiction: "DE", Rate: 19, Taxable: money.New(11999, "USD"), Amount: money.New(2280, "USD")}},
+		Tax:       money.New(2280, "USD"),
+		Total:     money.New(14279, "USD"),
+		Status:    model.StatusConfirmed,
+		CreatedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNewDocument(t *testing.T) {
+	issuedAt := time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	t.Run("One line per product and price", func(t *testing.T) {
+		// Act
+		doc := NewDocument(testOrder(), KindInvoice, Branding{CompanyName: "Acme"}, issuedAt)
+
+		// Assert
+		assert.Equal(t, "INV-order-123", doc.Number)
+		assert.Equal(t, "Invoice", doc.Title())
+		assert.Equal(t, "John Doe", doc.Customer.Name)
+		require.Len(t, doc.Lines, 2)
+		assert.Equal(t, Line{
+			ProductID: "product-001", Description: "Wireless Mouse", Quantity: 2,
+			UnitPrice: money.New(2999, "USD"), Discount: money.New(500, "USD"), Amount: money.New(4998, "USD"),
+		}, doc.Lines[0])
+		assert.Equal(t, Line{
+			ProductID: "product-002", Description: "Mechanical Keyboard", Quantity: 1,
+			UnitPrice: money.New(7001, "USD"), Amount: money.New(7001, "USD"),
+		}, doc.Lines[1])
+		assert.Equal(t, money.New(11999, "USD"), doc.Subtotal)
+		assert.Equal(t, money.New(14279, "USD"), doc.Total)
+	})
+
+	t.Run("Currency without minor units", func(t *testing.T) {
+		// Arrange
+		order := testOrder()
+		order.Products = []model.OrderProduct{{ID: "product-001", Name: "Teapot", Price: money.New(1500, "JPY")}}
+		order.TaxLines = []model.TaxLine{{Jurisdiction: "JP", Rate: 10, Taxable: money.New(1500, "JPY"), Amount: money.New(150, "JPY")}}
+		order.Tax = money.New(150, "JPY")
+		order.Total = money.New(1650, "JPY")
+
+		// Act
+		doc := NewDocument(order, KindInvoice, Branding{Currency: "EUR"}, issuedAt)
+
+		// Assert
+		assert.Equal(t, "JPY 1500", doc.Money(doc.Subtotal))
+		assert.Equal(t, "JPY 1650", doc.Money(doc.Total))
+	})
+
+	t.Run("Receipt", func(t *testing.T) {
+		// Act
+		doc := NewDocument(testOrder(), KindReceipt, Branding{}, issuedAt)
+
+		// Assert
+		assert.Equal(t, "RCT-order-123", doc.Number)
+		assert.Equal(t, "Receipt", doc.Title())
+		assert.Equal(t, "RCT-order-123.pdf", doc.Filename(FormatPDF))
+	})
+
+	t.Run("Order without customer details", func(t *testing.T) {
+		// Arrange
+		order := testOrder()
+		order.Customer = nil
+
+		// Act
+		doc := NewDocument(order, KindInvoice, Branding{}, issuedAt)
+
+		// Assert
+		assert.Empty(t, doc.Customer.Name)
+	})
+}
+
+func TestDocument_Money(t *testing.T) {
+	doc := Document{Branding: Branding{Currency: "EUR"}}
+
+	assert.Equal(t, "EUR 1234.50", doc.Money(money.New(123450, "EUR")))
+	assert.Equal(t, "USD 29.99", doc.Money(money.New(2999, "USD")), "amounts keep their own currency")
+	assert.Equal(t, "JPY 1500", doc.Money(money.New(1500, "JPY")))
+	assert.Equal(t, "KWD 1.250", doc.Money(money.New(1250, "KWD")))
+	assert.Equal(t, "EUR 0.00", doc.Money(money.Money{}), "amounts without a currency are printed in the branding's")
+	assert.Equal(t, "USD 0.00", Document{}.Money(money.Money{}))
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatPDF, format)
+
+	format, err = ParseFormat("HTML")
+	require.NoError(t, err)
+	assert.Equal(t, FormatHTML, format)
+
+	_, err = ParseFormat("docx")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestParseKind(t *testing.T) {
+	kind, err := ParseKind("")
+	require.NoError(t, err)
+	assert.Equal(t, KindInvoice, kind)
+
+	kind, err = ParseKind("Receipt")
+	require.NoError(t, err)
+	assert.Equal(t, KindReceipt, kind)
+
+	_, err = ParseKind("quote")
+	assert.ErrorIs(t, err, ErrUnsupportedKind)
+}
@@ -0,0 +1,301 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"external-apis/internal/shared/money"
+)
+
+// PDF pages are A4 in points, with the same margin on every side
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+)
+
+// Fonts of PDF documents, the standard Helvetica faces every reader has
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size. Bold text is measured with
+// them too, which is close enough to right-align amounts.
+var helveticaWidths = [95]float64{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// rgb is a color with components between 0 and 1
+type rgb [3]float64
+
+var (
+	black = rgb{0.13, 0.13, 0.13}
+	grey  = rgb{0.47, 0.47, 0.47}
+)
+
+// parseColor converts a #rrggbb color
+func parseColor(hex string) rgb {
+	value, _ := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	return rgb{float64(value>>16&0xff) / 255, float64(value>>8&0xff) / 255, float64(value&0xff) / 255}
+}
+
+// pdfWriter lays out text and rules on PDF pages, top to bottom
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// newPage starts a page and moves to its top
+func (w *pdfWriter) newPage() {
+	w.pages = append(w.pages, &bytes.Buffer{})
+	w.y = pageHeight - margin
+}
+
+// page returns the content stream of the current page
+func (w *pdfWriter) page() *bytes.Buffer {
+	return w.pages[len(w.pages)-1]
+}
+
+// text writes s with its left edge at x and its baseline at y
+func (w *pdfWriter) text(x, y, size float64, font string, color rgb, s string) {
+	fmt.Fprintf(w.page(), "BT %.3f %.3f %.3f rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		color[0], color[1], color[2], font, size, x, y, encodeText(s))
+}
+
+// textRight writes s with its right edge at right
+func (w *pdfWriter) textRight(right, y, size float64, font string, color rgb, s string) {
+	w.text(right-textWidth(s, size), y, size, font, color, s)
+}
+
+// rule draws a horizontal line across the page at y
+func (w *pdfWriter) rule(y float64, color rgb) {
+	fmt.Fprintf(w.page(), "%.3f %.3f %.3f RG 0.75 w %.2f %.2f m %.2f %.2f l S\n",
+		color[0], color[1], color[2], margin, y, pageWidth-margin, y)
+}
+
+// fill paints a rectangle whose lower left corner is at x, y
+func (w *pdfWriter) fill(x, y, width, height float64, color rgb) {
+	fmt.Fprintf(w.page(), "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+		color[0], color[1], color[2], x, y, width, height)
+}
+
+// bytes assembles the pages into a PDF file
+func (w *pdfWriter) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// The catalog, page tree and fonts come first; each page is followed by
+	// its content stream
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range w.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, fontRegular, fontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// encodeText converts s to a PDF string in WinAnsiEncoding. Characters
+// outside Latin-1 print as ?.
+func encodeText(s string) string {
+	var out strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			out.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&out, "\\%03o", r)
+		default:
+			out.WriteByte('?')
+		}
+	}
+	return out.String()
+}
+
+// textWidth measures s in Helvetica at size
+func textWidth(s string, size float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7f {
+			width += helveticaWidths[r-0x20]
+		} else {
+			width += 556
+		}
+	}
+	return width * size / 1000
+}
+
+// truncate shortens s with an ellipsis to fit within width at size
+func truncate(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// Columns of the product table, by their right edge
+const (
+	columnQuantity = 330.0
+	columnUnit     = 410.0
+	columnDiscount = 480.0
+	columnAmount   = pageWidth - margin
+)
+
+// renderPDF lays out the document on as many A4 pages as its lines need
+func renderPDF(doc Document) []byte {
+	accent := parseColor(doc.accent())
+	w := &pdfWriter{}
+	w.newPage()
+
+	// Letterhead: the company on the left, the document on the right
+	w.fill(0, pageHeight-12, pageWidth, 12, accent)
+	top := w.y
+	w.text(margin, w.y, 16, fontBold, black, doc.Branding.CompanyName)
+	w.y -= 16
+	for _, line := range doc.Branding.Address {
+		w.text(margin, w.y, 9, fontRegular, black, line)
+		w.y -= 12
+	}
+	if doc.Branding.Email != "" {
+		w.text(margin, w.y, 9, fontRegular, black, doc.Branding.Email)
+		w.y -= 12
+	}
+	if doc.Branding.TaxID != "" {
+		w.text(margin, w.y, 9, fontRegular, black, "Tax ID: "+doc.Branding.TaxID)
+		w.y -= 12
+	}
+
+	right := top
+	w.textRight(columnAmount, right, 22, fontBold, accent, doc.Title())
+	right -= 20
+	for _, line := range []string{
+		"Number: " + doc.Number,
+		"Order: " + doc.OrderID,
+		"Ordered: " + formatDate(doc.OrderedAt),
+		"Issued: " + formatDate(doc.IssuedAt),
+	} {
+		w.textRight(columnAmount, right, 9, fontRegular, black, line)
+		right -= 12
+	}
+	w.y = min(w.y, right) - 16
+
+	w.text(margin, w.y, 11, fontBold, accent, "Billed to")
+	w.y -= 14
+	w.text(margin, w.y, 10, fontRegular, black, doc.Customer.Name)
+	w.y -= 12
+	if doc.Customer.Email != "" {
+		w.text(margin, w.y, 10, fontRegular, black, doc.Customer.Email)
+		w.y -= 12
+	}
+	w.y -= 20
+
+	tableHeader := func() {
+		w.text(margin, w.y, 10, fontBold, accent, "Product")
+		w.textRight(columnQuantity, w.y, 10, fontBold, accent, "Quantity")
+		w.textRight(columnUnit, w.y, 10, fontBold, accent, "Unit price")
+		w.textRight(columnDiscount, w.y, 10, fontBold, accent, "Discount")
+		w.textRight(columnAmount, w.y, 10, fontBold, accent, "Amount")
+		w.rule(w.y-5, accent)
+		w.y -= 20
+	}
+	// row moves to the next row, starting a page when the current one is full
+	row := func() {
+		if w.y < margin+40 {
+			w.newPage()
+			tableHeader()
+		}
+	}
+
+	tableHeader()
+	for _, line := range doc.Lines {
+		row()
+		w.text(margin, w.y, 10, fontRegular, black, truncate(line.Description, 10, columnQuantity-margin-60))
+		w.textRight(columnQuantity, w.y, 10, fontRegular, black, strconv.Itoa(line.Quantity))
+		w.textRight(columnUnit, w.y, 10, fontRegular, black, doc.Money(line.UnitPrice))
+		if line.Discount.IsPositive() {
+			w.textRight(columnDiscount, w.y, 10, fontRegular, black, "-"+doc.Money(line.Discount))
+		}
+		w.textRight(columnAmount, w.y, 10, fontRegular, black, doc.Money(line.Amount))
+		w.y -= 16
+	}
+
+	row()
+	w.rule(w.y+10, grey)
+	w.y -= 6
+	totals := []struct {
+		label  string
+		amount money.Money
+	}{{"Subtotal", doc.Subtotal}}
+	for _, line := range doc.TaxLines {
+		totals = append(totals, struct {
+			label  string
+			amount money.Money
+		}{fmt.Sprintf("Tax %s (%s%%)", line.Jurisdiction, strconv.FormatFloat(line.Rate, 'f', -1, 64)), line.Amount})
+	}
+	for _, total := range totals {
+		row()
+		w.text(columnUnit-80, w.y, 10, fontRegular, black, total.label)
+		w.textRight(columnAmount, w.y, 10, fontRegular, black, doc.Money(total.amount))
+		w.y -= 16
+	}
+	row()
+	w.text(columnUnit-80, w.y, 11, fontBold, black, "Total")
+	w.textRight(columnAmount, w.y, 11, fontBold, black, doc.Money(doc.Total))
+	w.y -= 28
+
+	if doc.Refund != nil {
+		row()
+		note := fmt.Sprintf("Refund of %s: %s", doc.Money(doc.Refund.Amount), doc.Refund.Status)
+		if doc.Refund.Reason != "" {
+			note += " (" + doc.Refund.Reason + ")"
+		}
+		w.text(margin, w.y, 10, fontBold, accent, truncate(note, 10, pageWidth-2*margin))
+	}
+
+	// The footer and page numbers go on every page once they are all laid out
+	for i, page := range w.pages {
+		footer := &pdfWriter{pages: []*bytes.Buffer{page}}
+		if doc.Branding.Footer != "" {
+			footer.text(margin, margin-20, 8, fontRegular, grey, truncate(doc.Branding.Footer, 8, pageWidth-2*margin-80))
+		}
+		footer.textRight(columnAmount, margin-20, 8, fontRegular, grey, fmt.Sprintf("Page %d of %d", i+1, len(w.pages)))
+	}
+	return w.bytes()
+}
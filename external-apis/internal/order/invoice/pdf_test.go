@@ -0,0 +1,93 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertValidPDF checks the structure of a PDF file: its header and trailer,
+// and that every cross-reference entry points at its object
+func assertValidPDF(t *testing.T, data []byte) {
+	t.Helper()
+
+	require.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	require.NotNil(t, match)
+	xref, err := strconv.Atoi(string(match[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+func TestRenderer_Render_PDF(t *testing.T) {
+	renderer, err := NewRenderer("")
+	require.NoError(t, err)
+	branding := Branding{CompanyName: "Acme (Europe)", Currency: "EUR", Footer: "Thank you for your order"}
+
+	t.Run("Render the document", func(t *testing.T) {
+		// Arrange
+		doc := NewDocument(testOrder(), KindInvoice, branding, time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC))
+
+		// Act
+		file, err := renderer.Render(doc, FormatPDF)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "INV-order-123.pdf", file.Name)
+		assert.Equal(t, "application/pdf", file.ContentType)
+		assertValidPDF(t, file.Data)
+		assert.Contains(t, string(file.Data), `(Acme \(Europe\))`)
+		assert.Contains(t, string(file.Data), "(Invoice)")
+		assert.Contains(t, string(file.Data), "(John Doe)")
+		assert.Contains(t, string(file.Data), "(USD 142.79)")
+		assert.Contains(t, string(file.Data), "(Page 1 of 1)")
+	})
+
+	t.Run("Lines continue on further pages", func(t *testing.T) {
+		// Arrange
+		order := testOrder()
+		order.Products = nil
+		for i := 0; i < 100; i++ {
+			order.Products = append(order.Products, model.OrderProduct{ID: fmt.Sprintf("product-%03d", i), Name: "Cable", Price: money.New(100, "USD")})
+		}
+
+		// Act
+		file, err := renderer.Render(NewDocument(order, KindInvoice, branding, time.Now()), FormatPDF)
+
+		// Assert
+		require.NoError(t, err)
+		assertValidPDF(t, file.Data)
+		assert.Contains(t, string(file.Data), "/Count 3")
+		assert.Contains(t, string(file.Data), "(Page 3 of 3)")
+	})
+}
+
+func TestEncodeText(t *testing.T) {
+	assert.Equal(t, `a\(b\)\\c`, encodeText(`a(b)\c`))
+	assert.Equal(t, `Stra\337e`, encodeText("Straße"))
+	assert.Equal(t, "??", encodeText("東京"))
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "Cable", truncate("Cable", 10, 100))
+	truncated := truncate("A very long product name that does not fit", 10, 100)
+	assert.True(t, textWidth(truncated, 10) <= 100)
+	assert.Regexp(t, `^A very .*\.\.\.$`, truncated)
+}
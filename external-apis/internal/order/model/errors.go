@@ -0,0 +1,46 @@
+package model
+
+import "external-apis/internal/shared/apperror"
+
+// Order errors
+var (
+	ErrOrderNotFound = apperror.NotFound("order not found")
+	ErrOrderExists   = apperror.Conflict("order already exists")
+	ErrSagaNotFound  = apperror.NotFound("order saga not found")
+	ErrInvalidSort   = apperror.Validation("invalid sort option")
+
+	ErrInvalidStatusTransition = apperror.Conflict("order cannot move from its current status to the requested one")
+	ErrOrderShipped            = apperror.Conflict("order cannot be cancelled once shipped")
+	ErrOrderCancelled          = apperror.Conflict("order is already cancelled")
+	ErrOrderEnriched           = apperror.Conflict("order is already fully enriched")
+)
+
+// Invoice errors
+var (
+	ErrOrderNotEnriched     = apperror.Conflict("order is not fully enriched yet")
+	ErrInvoiceNotFound      = apperror.NotFound("invoice document not found")
+	ErrInvoiceAsyncDisabled = apperror.Unprocessable("invoices cannot be generated in the background")
+)
+
+// Sales report errors
+var (
+	ErrInvalidReportGroup = apperror.Validation("group_by must be product, category or customer_segment")
+	ErrInvalidReportDate  = apperror.Validation("from and to must be dates formatted as YYYY-MM-DD")
+	ErrInvalidReportRange = apperror.Validation("from must not be after to, and the range must not exceed 366 days")
+)
+
+// Errors about the customer and products an order refers to
+var (
+	ErrCustomerNotFound     = apperror.Unprocessable("customer not found")
+	ErrCustomerInactive     = apperror.Unprocessable("customer is not active")
+	ErrProductNotFound      = apperror.Unprocessable("product not found")
+	ErrProductInactive      = apperror.Unprocessable("product is not active")
+	ErrStockNotReserved     = apperror.Conflict("product stock could not be reserved")
+	ErrInvalidCoupon        = apperror.Unprocessable("coupon code is not valid")
+	ErrMixedCurrencies      = apperror.Validation("products of an order must be priced in the same currency")
+	ErrCustomersUnavailable = apperror.Unavailable("customer service unavailable")
+	ErrProductsUnavailable  = apperror.Unavailable("product service unavailable")
+	ErrTaxRejected          = apperror.Unprocessable("order could not be taxed")
+	ErrTaxUnavailable       = apperror.Unavailable("tax provider unavailable")
+	ErrShippingUnavailable  = apperror.Unavailable("shipping rates unavailable")
+)
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"external-apis/internal/shared/apperror"
+)
+
+// Relationships an order response can inline with ?expand
+const (
+	ExpandCustomer = "customer"
+	ExpandProducts = "products"
+)
+
+// ExpandPolicy decides what an order read does when a relationship cannot
+// be fetched because its service is unavailable
+type ExpandPolicy string
+
+// Expand policies
+const (
+	// ExpandFail fails the whole read
+	ExpandFail ExpandPolicy = "fail"
+	// ExpandPartial leaves the relationship out, naming it in expandErrors
+	ExpandPartial ExpandPolicy = "partial"
+)
+
+// Expand selects the relationships inlined in order responses
+type Expand struct {
+	Customer bool
+	Products bool
+}
+
+// ParseExpand parses a comma-separated ?expand value, e.g. "customer,products"
+func ParseExpand(value string) (Expand, error) {
+	var expand Expand
+	for _, name := range strings.Split(value, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case ExpandCustomer:
+			expand.Customer = true
+		case ExpandProducts:
+			expand.Products = true
+		default:
+			return Expand{}, apperror.Validation(fmt.Sprintf("unknown expansion %q, expected %s or %s", strings.TrimSpace(name), ExpandCustomer, ExpandProducts))
+		}
+	}
+	return expand, nil
+}
+
+// Any reports whether any relationship is expanded
+func (e Expand) Any() bool {
+	return e.Customer || e.Products
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// InvoiceStatus is the state of an invoice document generated in the
+// background
+type InvoiceStatus string
+
+const (
+	// InvoicePending documents are still being generated
+	InvoicePending InvoiceStatus = "PENDING"
+	// InvoiceReady documents can be downloaded from their URL
+	InvoiceReady InvoiceStatus = "READY"
+	// InvoiceFailed documents could not be generated; Error explains why
+	InvoiceFailed InvoiceStatus = "FAILED"
+)
+
+// InvoiceDocument is an invoice or receipt of an order generated in the
+// background and stored for download
+type InvoiceDocument struct {
+	ID      string `json:"id"`
+	OrderID string `json:"orderId"`
+	// Kind is invoice or receipt and Format pdf or html
+	Kind   string        `json:"type"`
+	Format string        `json:"format"`
+	Status InvoiceStatus `json:"status"`
+	// URL is where the document is downloaded from once it is ready
+	URL         string     `json:"url,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// Clone returns a deep copy of the document
+func (d *InvoiceDocument) Clone() *InvoiceDocument {
+	clone := *d
+	if d.CompletedAt != nil {
+		completedAt := *d.CompletedAt
+		clone.CompletedAt = &completedAt
+	}
+	return &clone
+}
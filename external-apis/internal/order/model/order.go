@@ -0,0 +1,510 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// OrderCustomer holds the customer details an order is enriched with
+type OrderCustomer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// OrderProduct holds the product details an order is enriched with. Price is
+// what the customer pays; when a promotion lowered it, ListPrice, Discount
+// and PromotionID record the price before and the promotion applied. The
+// amounts are in the currency of Price.
+type OrderProduct struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Category    string      `json:"category"`
+	ListPrice   money.Money `json:"listPrice,omitempty"`
+	Discount    money.Money `json:"discount,omitempty"`
+	PromotionID string      `json:"promotionId,omitempty"`
+	// AverageRating and ReviewCount are the rating of the product when the
+	// order was placed, for order confirmations to show
+	AverageRating float64 `json:"averageRating,omitempty"`
+	ReviewCount   int     `json:"reviewCount,omitempty"`
+	// WeightGrams and Dimensions are what the product is shipped as; zero
+	// and nil when the product service does not know
+	WeightGrams int         `json:"weightGrams,omitempty"`
+	Dimensions  *Dimensions `json:"dimensions,omitempty"`
+}
+
+// MarshalJSON custom marshaling for OrderProduct. The amounts are written as
+// exact decimal numbers next to their currency.
+func (p OrderProduct) MarshalJSON() ([]byte, error) {
+	type Alias OrderProduct
+
+	aux := struct {
+		Alias
+		Price     json.Number `json:"price" swaggertype:"number"`
+		ListPrice json.Number `json:"listPrice,omitempty" swaggertype:"number"`
+		Discount  json.Number `json:"discount,omitempty" swaggertype:"number"`
+		Currency  string      `json:"currency"`
+	}{
+		Alias:    Alias(p),
+		Price:    p.Price.Number(),
+		Currency: p.Price.Currency,
+	}
+	if p.ListPrice.Amount != 0 {
+		aux.ListPrice = p.ListPrice.Number()
+	}
+	if p.Discount.Amount != 0 {
+		aux.Discount = p.Discount.Number()
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON custom unmarshaling for OrderProduct. Products of orders
+// placed before prices carried a currency are read in
+// money.DefaultCurrency.
+func (p *OrderProduct) UnmarshalJSON(data []byte) error {
+	type Alias OrderProduct
+	aux := &struct {
+		*Alias
+		Price     json.Number `json:"price"`
+		ListPrice json.Number `json:"listPrice"`
+		Discount  json.Number `json:"discount"`
+		Currency  string      `json:"currency"`
+	}{
+		Alias: (*Alias)(p),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	if p.Price, err = parseAmount(aux.Price, currency); err != nil {
+		return err
+	}
+	if p.ListPrice, err = parseAmount(aux.ListPrice, currency); err != nil {
+		return err
+	}
+	p.Discount, err = parseAmount(aux.Discount, currency)
+	return err
+}
+
+// numberOrZero returns the number, or zero when it was left out
+func numberOrZero(number json.Number) string {
+	if number == "" {
+		return "0"
+	}
+	return number.String()
+}
+
+// parseAmount reads a stored amount of a currency; an amount left out is
+// the zero Money. Amounts stored as floats are rounded to the minor unit.
+func parseAmount(number json.Number, currency string) (money.Money, error) {
+	if number == "" {
+		return money.Money{}, nil
+	}
+	return money.ParseRounded(number.String(), currency)
+}
+
+// TaxLine is the tax an order is charged in one jurisdiction: a country, as
+// its ISO 3166-1 alpha-2 code, or a region of it, e.g. US-CA. Rate is a
+// percentage of Taxable.
+type TaxLine struct {
+	Jurisdiction string      `json:"jurisdiction"`
+	Rate         float64     `json:"rate"`
+	Taxable      money.Money `json:"taxable"`
+	Amount       money.Money `json:"amount"`
+}
+
+// MarshalJSON custom marshaling for TaxLine. The amounts are written as exact
+// decimal numbers next to their currency.
+func (l TaxLine) MarshalJSON() ([]byte, error) {
+	type Alias TaxLine
+
+	return json.Marshal(struct {
+		Alias
+		Taxable  json.Number `json:"taxable" swaggertype:"number"`
+		Amount   json.Number `json:"amount" swaggertype:"number"`
+		Currency string      `json:"currency"`
+	}{
+		Alias:    Alias(l),
+		Taxable:  l.Taxable.Number(),
+		Amount:   l.Amount.Number(),
+		Currency: l.Amount.Currency,
+	})
+}
+
+// UnmarshalJSON custom unmarshaling for TaxLine. Lines of orders taxed
+// before amounts carried a currency are read in money.DefaultCurrency.
+func (l *TaxLine) UnmarshalJSON(data []byte) error {
+	type Alias TaxLine
+	aux := &struct {
+		*Alias
+		Taxable  json.Number `json:"taxable"`
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}{
+		Alias: (*Alias)(l),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	if l.Taxable, err = money.ParseRounded(numberOrZero(aux.Taxable), currency); err != nil {
+		return err
+	}
+	l.Amount, err = money.ParseRounded(numberOrZero(aux.Amount), currency)
+	return err
+}
+
+// Enrichment statuses of an order that could not be fully enriched when it
+// was placed
+const (
+	// EnrichmentPartial orders wait for the customer or products to be fetched
+	EnrichmentPartial = "partial"
+	// EnrichmentFailed orders turned out to refer to a customer or product
+	// that does not exist or is not active
+	EnrichmentFailed = "failed"
+)
+
+// Parts of an order that are enriched from downstream services
+const (
+	EnrichCustomer = "customer"
+	EnrichProducts = "products"
+	EnrichTax      = "tax"
+)
+
+// Enrichment records what is missing from an order accepted while the
+// customer or product service was unavailable
+type Enrichment struct {
+	Status string `json:"status"`
+	// Missing lists the parts still to be fetched: customer, products and/or tax
+	Missing []string `json:"missing"`
+	// Error explains why a failed enrichment cannot complete
+	Error string `json:"error,omitempty"`
+	// Attempts counts the attempts to complete a partial enrichment so far,
+	// and NextAttemptAt is when the next one is due
+	Attempts      int        `json:"attempts,omitempty"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+}
+
+// Due checks if the next attempt to complete a partial enrichment is due at
+// a time
+func (e *Enrichment) Due(at time.Time) bool {
+	return e.Status == EnrichmentPartial && (e.NextAttemptAt == nil || !e.NextAttemptAt.After(at))
+}
+
+// Order represents a customer order enriched with customer and product data.
+// Total is the price of the products plus Tax, the sum of the TaxLines
+// charged by jurisdiction, in the currency the products are priced in.
+type Order struct {
+	ID         string         `json:"id"`
+	CustomerID string         `json:"customerId"`
+	ProductIDs []string       `json:"productIds"`
+	CouponCode string         `json:"couponCode,omitempty"`
+	Customer   *OrderCustomer `json:"customer"`
+	Products   []OrderProduct `json:"products"`
+	TaxLines   []TaxLine      `json:"taxLines,omitempty"`
+	Tax        money.Money    `json:"tax,omitempty"`
+	Total      money.Money    `json:"total"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	// Enrichment is set while the order is not fully enriched
+	Enrichment *Enrichment `json:"enrichment,omitempty"`
+	// Status changes through Transition, which records every change in
+	// History, oldest first
+	Status  OrderStatus        `json:"status"`
+	History []StatusTransition `json:"history"`
+	// ReservationIDs are the stock reservations held for the order, released
+	// again when it is cancelled
+	ReservationIDs []string `json:"reservationIds,omitempty"`
+	// Refund is set once a cancelled order has been refunded
+	Refund *Refund `json:"refund,omitempty"`
+	// ShippingOptions are the ways the order can be shipped to the
+	// customer's preferred address, cheapest first, quoted when it was
+	// enriched
+	ShippingOptions []ShippingRate `json:"shippingOptions,omitempty"`
+	// Address is the customer's preferred address when the order was
+	// enriched, the one it is taxed and shipped to
+	Address *OrderAddress `json:"address,omitempty"`
+	// Snapshot is taken once the order is fully enriched and never changes
+	// afterwards
+	Snapshot *OrderSnapshot `json:"snapshot,omitempty"`
+}
+
+// OrderResponse represents the API response for an order. The customer and
+// products are referred to by ID and only inlined, with their current data,
+// when the request expands them; Snapshot holds them as they were when the
+// order was enriched.
+type OrderResponse struct {
+	ID         string         `json:"id"`
+	CustomerID string         `json:"customerId"`
+	ProductIDs []string       `json:"productIds"`
+	CouponCode string         `json:"couponCode,omitempty"`
+	Customer   *OrderCustomer `json:"customer,omitempty"`
+	Products   []OrderProduct `json:"products,omitempty"`
+	TaxLines   []TaxLine      `json:"taxLines,omitempty"`
+	Tax        json.Number    `json:"tax,omitempty" swaggertype:"number"`
+	Total      json.Number    `json:"total" swaggertype:"number"`
+	// Currency is the currency of the amounts of the order, left out until
+	// its products are enriched
+	Currency string `json:"currency,omitempty"`
+	// ShippingOptions are quoted when the order is enriched
+	ShippingOptions []ShippingRate `json:"shippingOptions,omitempty"`
+	Snapshot        *OrderSnapshot `json:"snapshot,omitempty"`
+	Status          OrderStatus    `json:"status"`
+	Refund          *Refund        `json:"refund,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	Enrichment      *Enrichment    `json:"enrichment,omitempty"`
+	// ExpandErrors explains, by expansion, why a requested relationship is
+	// missing or incomplete
+	ExpandErrors map[string]string `json:"expandErrors,omitempty"`
+}
+
+// TotalAmount returns the total in major units, for the GraphQL schema
+func (r *OrderResponse) TotalAmount() float64 {
+	total, _ := r.Total.Float64()
+	return total
+}
+
+// MarshalJSON custom marshaling for Order. The tax and total are written as
+// exact decimal numbers next to their currency.
+func (o Order) MarshalJSON() ([]byte, error) {
+	type Alias Order
+
+	aux := struct {
+		Alias
+		Tax      json.Number `json:"tax,omitempty"`
+		Total    json.Number `json:"total"`
+		Currency string      `json:"currency,omitempty"`
+	}{
+		Alias:    Alias(o),
+		Total:    o.Total.Number(),
+		Currency: o.Total.Currency,
+	}
+	if o.Tax.Amount != 0 {
+		aux.Tax = o.Tax.Number()
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON custom unmarshaling for Order. The tax and total of an order
+// placed before totals carried a currency are read in money.DefaultCurrency.
+func (o *Order) UnmarshalJSON(data []byte) error {
+	type Alias Order
+	aux := &struct {
+		*Alias
+		Tax      json.Number `json:"tax"`
+		Total    json.Number `json:"total"`
+		Currency string      `json:"currency"`
+	}{
+		Alias: (*Alias)(o),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		if (aux.Total == "" || aux.Total == "0") && (aux.Tax == "" || aux.Tax == "0") {
+			o.Tax, o.Total = money.Money{}, money.Money{}
+			return nil
+		}
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	if o.Tax, err = parseAmount(aux.Tax, currency); err != nil {
+		return err
+	}
+	o.Total, err = parseAmount(aux.Total, currency)
+	return err
+}
+
+// Clone returns a deep copy of the order
+func (o *Order) Clone() *Order {
+	clone := *o
+	clone.ProductIDs = slices.Clone(o.ProductIDs)
+	clone.Products = slices.Clone(o.Products)
+	clone.TaxLines = slices.Clone(o.TaxLines)
+	clone.History = slices.Clone(o.History)
+	clone.ReservationIDs = slices.Clone(o.ReservationIDs)
+	clone.ShippingOptions = slices.Clone(o.ShippingOptions)
+	for i, product := range clone.Products {
+		if product.Dimensions != nil {
+			dimensions := *product.Dimensions
+			clone.Products[i].Dimensions = &dimensions
+		}
+	}
+	if o.Customer != nil {
+		customer := *o.Customer
+		clone.Customer = &customer
+	}
+	if o.Refund != nil {
+		refund := *o.Refund
+		clone.Refund = &refund
+	}
+	if o.Address != nil {
+		address := *o.Address
+		clone.Address = &address
+	}
+	if o.Snapshot != nil {
+		clone.Snapshot = o.Snapshot.Clone()
+	}
+	if o.Enrichment != nil {
+		enrichment := *o.Enrichment
+		enrichment.Missing = slices.Clone(o.Enrichment.Missing)
+		if o.Enrichment.NextAttemptAt != nil {
+			next := *o.Enrichment.NextAttemptAt
+			enrichment.NextAttemptAt = &next
+		}
+		clone.Enrichment = &enrichment
+	}
+	return &clone
+}
+
+// ToResponse converts an Order to OrderResponse
+func (o *Order) ToResponse() OrderResponse {
+	return OrderResponse{
+		ID:              o.ID,
+		CustomerID:      o.CustomerID,
+		ProductIDs:      o.ProductIDs,
+		CouponCode:      o.CouponCode,
+		TaxLines:        o.TaxLines,
+		Tax:             o.taxNumber(),
+		Total:           o.Total.Number(),
+		Currency:        o.Total.Currency,
+		Status:          o.Status,
+		ShippingOptions: o.ShippingOptions,
+		Snapshot:        o.Snapshot,
+		Refund:          o.Refund,
+		CreatedAt:       o.CreatedAt,
+		Enrichment:      o.Enrichment,
+	}
+}
+
+// Order sort options accepted by the list endpoint. Orders placed at the
+// same time are ordered by ID.
+const (
+	SortByCreatedAt     = "created_at"
+	SortByCreatedAtDesc = "created_at_desc"
+)
+
+// IsValidOrderSort checks if the sort option is supported
+func IsValidOrderSort(sort string) bool {
+	switch sort {
+	case "", SortByCreatedAt, SortByCreatedAtDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// taxNumber returns the tax as a decimal number, empty when the order is
+// not taxed
+func (o *Order) taxNumber() json.Number {
+	if o.Tax.Amount == 0 {
+		return ""
+	}
+	return o.Tax.Number()
+}
+
+// CalculateTax sums the tax lines, which must all be in the same currency;
+// it is the zero Money without tax lines
+func (o *Order) CalculateTax() (money.Money, error) {
+	if len(o.TaxLines) == 0 {
+		return money.Money{}, nil
+	}
+
+	tax := money.New(0, o.TaxLines[0].Amount.Currency)
+	for _, line := range o.TaxLines {
+		var err error
+		if tax, err = tax.Add(line.Amount); err != nil {
+			if errors.Is(err, money.ErrCurrencyMismatch) {
+				return money.Money{}, ErrMixedCurrencies
+			}
+			return money.Money{}, err
+		}
+	}
+	return tax, nil
+}
+
+// CalculateSubtotal sums the prices of the enriched products, which must all
+// be in the same currency; it is the zero Money without products
+func (o *Order) CalculateSubtotal() (money.Money, error) {
+	if len(o.Products) == 0 {
+		return money.Money{}, nil
+	}
+
+	subtotal := money.New(0, o.Products[0].Price.Currency)
+	for _, product := range o.Products {
+		var err error
+		if subtotal, err = subtotal.Add(product.Price); err != nil {
+			if errors.Is(err, money.ErrCurrencyMismatch) {
+				return money.Money{}, ErrMixedCurrencies
+			}
+			return money.Money{}, err
+		}
+	}
+	return subtotal, nil
+}
+
+// CalculateTotal sums the prices of the enriched products and their tax, in
+// the currency of the products
+func (o *Order) CalculateTotal() (money.Money, error) {
+	subtotal, err := o.CalculateSubtotal()
+	if err != nil || len(o.Products) == 0 {
+		return subtotal, err
+	}
+
+	tax, err := o.CalculateTax()
+	if err != nil || tax.Amount == 0 {
+		return subtotal, err
+	}
+	total, err := subtotal.Add(tax)
+	if errors.Is(err, money.ErrCurrencyMismatch) {
+		return money.Money{}, ErrMixedCurrencies
+	}
+	return total, err
+}
+
+// CreateOrderRequest represents the request to create an order. The coupon
+// code unlocks its promotion on the products it covers; it is ignored when
+// the service does not apply promotions.
+type CreateOrderRequest struct {
+	CustomerID string   `json:"customerId" binding:"required"`
+	ProductIDs []string `json:"productIds" binding:"required,min=1,dive,required"`
+	CouponCode string   `json:"couponCode,omitempty" binding:"omitempty,max=32"`
+}
+
+// ReassignCustomerRequest represents the request to move the orders of a
+// customer to another, e.g. after the customers were merged
+type ReassignCustomerRequest struct {
+	FromCustomerID string `json:"fromCustomerId" binding:"required"`
+	ToCustomerID   string `json:"toCustomerId" binding:"required,nefield=FromCustomerID"`
+}
+
+// ReassignCustomerResponse represents the API response for a reassignment
+type ReassignCustomerResponse struct {
+	FromCustomerID string `json:"fromCustomerId"`
+	ToCustomerID   string `json:"toCustomerId"`
+	OrdersMoved    int    `json:"ordersMoved"`
+}
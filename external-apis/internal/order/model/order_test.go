@@ -0,0 +1,484 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrder_ToResponse(t *testing.T) {
+	// Arrange
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	order := &Order{
+		ID:         "order-123",
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-789"},
+		Customer: &OrderCustomer{
+			ID:    "customer-456",
+			Name:  "John Doe",
+			Email: "john.doe@example.com",
+		},
+		Products: []OrderProduct{
+			{ID: "product-789", Name: "Laptop", Price: money.New(99900, "USD")},
+		},
+		Total:     money.New(99900, "USD"),
+		CreatedAt: createdAt,
+	}
+
+	// Act
+	response := order.ToResponse()
+
+	// Assert
+	assert.Equal(t, "order-123", response.ID)
+	assert.Equal(t, "customer-456", response.CustomerID)
+	assert.Equal(t, []string{"product-789"}, response.ProductIDs)
+	assert.Nil(t, response.Customer, "relationships are only inlined when expanded")
+	assert.Empty(t, response.Products)
+	assert.Equal(t, json.Number("999.00"), response.Total)
+	assert.Equal(t, "USD", response.Currency)
+	assert.Equal(t, createdAt, response.CreatedAt)
+}
+
+func TestOrder_CalculateTotal(t *testing.T) {
+	t.Run("Multiple products", func(t *testing.T) {
+		order := &Order{
+			Products: []OrderProduct{
+				{ID: "product-001", Price: money.New(2999, "USD")},
+				{ID: "product-002", Price: money.New(12999, "USD")},
+			},
+		}
+
+		total, err := order.CalculateTotal()
+
+		require.NoError(t, err)
+		assert.Equal(t, money.New(15998, "USD"), total)
+	})
+
+	t.Run("No products", func(t *testing.T) {
+		order := &Order{}
+
+		total, err := order.CalculateTotal()
+
+		require.NoError(t, err)
+		assert.Equal(t, money.Money{}, total)
+	})
+
+	t.Run("Zero-decimal currency", func(t *testing.T) {
+		order := &Order{
+			Products: []OrderProduct{{ID: "product-001", Price: money.New(1500, "JPY")}},
+			TaxLines: []TaxLine{{Jurisdiction: "JP", Rate: 10, Taxable: money.New(1500, "JPY"), Amount: money.New(150, "JPY")}},
+		}
+
+		total, err := order.CalculateTotal()
+
+		require.NoError(t, err)
+		assert.Equal(t, money.New(1650, "JPY"), total)
+	})
+
+	t.Run("Mixed currencies", func(t *testing.T) {
+		order := &Order{
+			Products: []OrderProduct{
+				{ID: "product-001", Price: money.New(2999, "USD")},
+				{ID: "product-002", Price: money.New(2999, "EUR")},
+			},
+		}
+
+		_, err := order.CalculateTotal()
+
+		assert.ErrorIs(t, err, ErrMixedCurrencies)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+	})
+
+	t.Run("Products and tax", func(t *testing.T) {
+		order := &Order{
+			Products: []OrderProduct{{ID: "product-001", Price: money.New(10000, "USD")}},
+			TaxLines: []TaxLine{
+				{Jurisdiction: "US", Rate: 0, Taxable: money.New(10000, "USD"), Amount: money.New(0, "USD")},
+				{Jurisdiction: "US-CA", Rate: 7.25, Taxable: money.New(10000, "USD"), Amount: money.New(725, "USD")},
+			},
+		}
+
+		tax, err := order.CalculateTax()
+		require.NoError(t, err)
+		total, err := order.CalculateTotal()
+
+		require.NoError(t, err)
+		assert.Equal(t, money.New(725, "USD"), tax)
+		assert.Equal(t, money.New(10725, "USD"), total)
+	})
+
+	t.Run("Tax in another currency", func(t *testing.T) {
+		order := &Order{
+			Products: []OrderProduct{{ID: "product-001", Price: money.New(10000, "USD")}},
+			TaxLines: []TaxLine{{Jurisdiction: "DE", Rate: 19, Taxable: money.New(10000, "EUR"), Amount: money.New(1900, "EUR")}},
+		}
+
+		_, err := order.CalculateTotal()
+
+		assert.ErrorIs(t, err, ErrMixedCurrencies)
+	})
+}
+
+func TestOrder_JSON(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		// Arrange
+		order := Order{
+			ID: "order-123",
+			Products: []OrderProduct{
+				{ID: "product-001", Price: money.New(2500, "EUR"), ListPrice: money.New(3000, "EUR"), Discount: money.New(500, "EUR")},
+				{ID: "product-002", Price: money.New(1999, "EUR")},
+			},
+			TaxLines: []TaxLine{{Jurisdiction: "DE", Rate: 19, Taxable: money.New(4499, "EUR"), Amount: money.New(855, "EUR")}},
+			Tax:      money.New(855, "EUR"),
+			Total:    money.New(5354, "EUR"),
+			ShippingOptions: []ShippingRate{
+				{Carrier: "dhl", ServiceLevel: "express", Cost: money.New(1490, "EUR"), EstimatedDays: 1},
+			},
+			Refund: &Refund{ID: "refund-1", Amount: money.New(5354, "EUR"), Status: RefundPending},
+		}
+		order.TakeSnapshot(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+		// Act
+		data, err := json.Marshal(order)
+		require.NoError(t, err)
+		var decoded Order
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		// Assert
+		assert.Contains(t, string(data), `"tax":8.55,"total":53.54,"currency":"EUR"`)
+		assert.Contains(t, string(data), `"price":19.99,"currency":"EUR"`)
+		assert.Contains(t, string(data), `"taxable":44.99,"amount":8.55,"currency":"EUR"`)
+		assert.Contains(t, string(data), `"cost":14.90,"currency":"EUR"`)
+		assert.NotContains(t, string(data), `"listPrice":0`)
+		assert.Equal(t, order.Products, decoded.Products)
+		assert.Equal(t, order.TaxLines, decoded.TaxLines)
+		assert.Equal(t, order.Tax, decoded.Tax)
+		assert.Equal(t, order.Total, decoded.Total)
+		assert.Equal(t, order.ShippingOptions[0].Cost, decoded.ShippingOptions[0].Cost)
+		assert.Equal(t, order.Snapshot, decoded.Snapshot)
+		assert.Equal(t, order.Refund.Amount, decoded.Refund.Amount)
+	})
+
+	t.Run("Orders stored before amounts carried a currency", func(t *testing.T) {
+		// Arrange
+		data := `{"id":"order-123","products":[{"id":"product-001","price":29.99},{"id":"product-002","price":0.1}],` +
+			`"taxLines":[{"jurisdiction":"US-CA","rate":7.25,"taxable":30.09,"amount":2.18}],"tax":2.18,"total":32.269999999999996,` +
+			`"shippingOptions":[{"carrier":"ups","serviceLevel":"ground","cost":6.99,"estimatedDays":5}],` +
+			`"snapshot":{"lineItems":[{"productId":"product-001","quantity":1,"unitPrice":29.99,"tax":2.17,"total":32.16}],"subtotal":29.99,"tax":2.17,"total":32.16}}`
+
+		// Act
+		var order Order
+		err := json.Unmarshal([]byte(data), &order)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, money.New(2999, "USD"), order.Products[0].Price)
+		assert.Equal(t, money.New(10, "USD"), order.Products[1].Price)
+		assert.Equal(t, money.Money{}, order.Products[1].ListPrice)
+		assert.Equal(t, money.New(218, "USD"), order.TaxLines[0].Amount)
+		assert.Equal(t, money.New(218, "USD"), order.Tax)
+		assert.Equal(t, money.New(3227, "USD"), order.Total)
+		assert.Equal(t, money.New(699, "USD"), order.ShippingOptions[0].Cost)
+		assert.Equal(t, money.New(2999, "USD"), order.Snapshot.LineItems[0].UnitPrice)
+		assert.Equal(t, money.New(3216, "USD"), order.Snapshot.Total)
+	})
+
+	t.Run("Orders not enriched yet", func(t *testing.T) {
+		// Arrange
+		data, err := json.Marshal(Order{ID: "order-123"})
+		require.NoError(t, err)
+
+		// Act
+		var order Order
+		err = json.Unmarshal(data, &order)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "currency")
+		assert.Equal(t, money.Money{}, order.Total)
+	})
+}
+
+func TestOrderResponse_JSON(t *testing.T) {
+	// Arrange
+	response := OrderResponse{
+		ID:         "order-123",
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-789"},
+	}
+
+	// Act
+	data, err := json.Marshal(response)
+
+	// Assert
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "order-123", result["id"])
+	assert.Equal(t, "customer-456", result["customerId"])
+	assert.Equal(t, []interface{}{"product-789"}, result["productIds"])
+	assert.NotContains(t, result, "customer")
+	assert.NotContains(t, result, "products")
+	assert.NotContains(t, result, "expandErrors")
+}
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Expand
+		wantErr bool
+	}{
+		{name: "None", value: "", want: Expand{}},
+		{name: "Customer", value: "customer", want: Expand{Customer: true}},
+		{name: "Both", value: "customer, products", want: Expand{Customer: true, Products: true}},
+		{name: "Repeated and empty entries", value: "products,,products", want: Expand{Products: true}},
+		{name: "Unknown", value: "customer,invoices", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			expand, err := ParseExpand(tt.value)
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, apperror.ErrValidation)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expand)
+			assert.Equal(t, tt.want.Customer || tt.want.Products, expand.Any())
+		})
+	}
+}
+
+func TestOrder_Clone(t *testing.T) {
+	// Arrange
+	next := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	order := &Order{
+		ID:              "order-123",
+		ProductIDs:      []string{"product-789"},
+		Customer:        &OrderCustomer{ID: "customer-456", Name: "John Doe"},
+		Products:        []OrderProduct{{ID: "product-789", Name: "Laptop", Dimensions: &Dimensions{LengthMM: 400, WidthMM: 300, HeightMM: 50}}},
+		Enrichment:      &Enrichment{Status: EnrichmentPartial, Missing: []string{EnrichProducts}, NextAttemptAt: &next},
+		History:         []StatusTransition{{To: StatusCreated, Actor: "user:alice"}},
+		Refund:          &Refund{ID: "refund-1", Status: RefundPending},
+		ShippingOptions: []ShippingRate{{Carrier: "dhl", ServiceLevel: "express"}},
+		Address:         &OrderAddress{City: "Berlin", Country: "DE"},
+		Snapshot: &OrderSnapshot{
+			Customer:  &CustomerSnapshot{Name: "John Doe", Address: &OrderAddress{City: "Berlin", Country: "DE"}},
+			LineItems: []LineItem{{ProductID: "product-789", Name: "Laptop"}},
+		},
+	}
+
+	// Act
+	clone := order.Clone()
+	clone.ProductIDs[0] = "changed"
+	clone.Customer.Name = "Changed"
+	clone.Products[0].Name = "Changed"
+	clone.Enrichment.Missing[0] = "changed"
+	*clone.Enrichment.NextAttemptAt = time.Time{}
+	clone.History[0].Actor = "changed"
+	clone.Refund.Status = RefundFailed
+	clone.Products[0].Dimensions.HeightMM = 1
+	clone.ShippingOptions[0].Carrier = "changed"
+	clone.Address.City = "changed"
+	clone.Snapshot.Customer.Name = "Changed"
+	clone.Snapshot.Customer.Address.City = "changed"
+	clone.Snapshot.LineItems[0].Name = "Changed"
+
+	// Assert
+	assert.Equal(t, []string{"product-789"}, order.ProductIDs)
+	assert.Equal(t, "John Doe", order.Customer.Name)
+	assert.Equal(t, "Laptop", order.Products[0].Name)
+	assert.Equal(t, []string{EnrichProducts}, order.Enrichment.Missing)
+	assert.Equal(t, next, *order.Enrichment.NextAttemptAt)
+	assert.Equal(t, "user:alice", order.History[0].Actor)
+	assert.Equal(t, RefundPending, order.Refund.Status)
+	assert.Equal(t, 50, order.Products[0].Dimensions.HeightMM)
+	assert.Equal(t, "dhl", order.ShippingOptions[0].Carrier)
+	assert.Equal(t, "Berlin", order.Address.City)
+	assert.Equal(t, "John Doe", order.Snapshot.Customer.Name)
+	assert.Equal(t, "Berlin", order.Snapshot.Customer.Address.City)
+	assert.Equal(t, "Laptop", order.Snapshot.LineItems[0].Name)
+}
+
+func TestOrder_TakeSnapshot(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newOrder := func() *Order {
+		return &Order{
+			ID:         "order-123",
+			CustomerID: "customer-456",
+			Customer:   &OrderCustomer{ID: "customer-456", Name: "John Doe", Email: "john@example.com"},
+			Address:    &OrderAddress{Line1: "1 Main St", City: "Berlin", Country: "DE"},
+			Products: []OrderProduct{
+				{ID: "product-001", Name: "Wireless Mouse", Price: money.New(1000, "USD")},
+				{ID: "product-002", Name: "Keyboard", Price: money.New(2000, "USD"), ListPrice: money.New(2500, "USD"), Discount: money.New(500, "USD"), PromotionID: "promo-1"},
+				{ID: "product-001", Name: "Wireless Mouse", Price: money.New(1000, "USD")},
+			},
+			Tax:   money.New(399, "USD"),
+			Total: money.New(4399, "USD"),
+		}
+	}
+
+	t.Run("Freeze the customer and the line items", func(t *testing.T) {
+		// Arrange
+		order := newOrder()
+
+		// Act
+		order.TakeSnapshot(at)
+
+		// Assert
+		require.NotNil(t, order.Snapshot)
+		assert.Equal(t, at, order.Snapshot.TakenAt)
+		assert.Equal(t, &CustomerSnapshot{
+			ID:      "customer-456",
+			Name:    "John Doe",
+			Email:   "john@example.com",
+			Address: &OrderAddress{Line1: "1 Main St", City: "Berlin", Country: "DE"},
+		}, order.Snapshot.Customer)
+		require.Len(t, order.Snapshot.LineItems, 2)
+		assert.Equal(t, LineItem{
+			ProductID: "product-001", Name: "Wireless Mouse", Quantity: 2, UnitPrice: money.New(1000, "USD"), Tax: money.New(200, "USD"), Total: money.New(2200, "USD"),
+		}, order.Snapshot.LineItems[0])
+		assert.Equal(t, LineItem{
+			ProductID: "product-002", Name: "Keyboard", Quantity: 1, UnitPrice: money.New(2000, "USD"), ListPrice: money.New(2500, "USD"), Discount: money.New(500, "USD"),
+			PromotionID: "promo-1", Tax: money.New(199, "USD"), Total: money.New(2199, "USD"),
+		}, order.Snapshot.LineItems[1])
+		assert.Equal(t, money.New(4000, "USD"), order.Snapshot.Subtotal)
+		assert.Equal(t, money.New(399, "USD"), order.Snapshot.Tax)
+		assert.Equal(t, money.New(4399, "USD"), order.Snapshot.Total)
+	})
+
+	t.Run("Line item taxes add up to the order tax", func(t *testing.T) {
+		// Arrange
+		order := &Order{
+			Products: []OrderProduct{{ID: "a", Price: money.New(100, "USD")}, {ID: "b", Price: money.New(100, "USD")}, {ID: "c", Price: money.New(100, "USD")}},
+			Tax:      money.New(10, "USD"),
+		}
+
+		// Act
+		order.TakeSnapshot(at)
+
+		// Assert
+		var tax int64
+		for _, item := range order.Snapshot.LineItems {
+			tax += item.Tax.Amount
+		}
+		assert.Equal(t, int64(10), tax)
+		assert.Equal(t, money.New(3, "USD"), order.Snapshot.LineItems[0].Tax)
+		assert.Equal(t, money.New(4, "USD"), order.Snapshot.LineItems[2].Tax)
+	})
+
+	t.Run("Keep the first snapshot", func(t *testing.T) {
+		// Arrange
+		order := newOrder()
+		order.TakeSnapshot(at)
+
+		// Act
+		order.Products[1].Name = "Renamed Keyboard"
+		order.Customer.Name = "Jane Doe"
+		order.TakeSnapshot(at.Add(time.Hour))
+
+		// Assert
+		assert.Equal(t, at, order.Snapshot.TakenAt)
+		assert.Equal(t, "Keyboard", order.Snapshot.LineItems[1].Name)
+		assert.Equal(t, "John Doe", order.Snapshot.Customer.Name)
+	})
+}
+
+func TestEnrichment_Due(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Second), now.Add(time.Second)
+
+	tests := []struct {
+		name       string
+		enrichment Enrichment
+		want       bool
+	}{
+		{"Next attempt passed", Enrichment{Status: EnrichmentPartial, NextAttemptAt: &past}, true},
+		{"Next attempt now", Enrichment{Status: EnrichmentPartial, NextAttemptAt: &now}, true},
+		{"Never scheduled", Enrichment{Status: EnrichmentPartial}, true},
+		{"Next attempt ahead", Enrichment{Status: EnrichmentPartial, NextAttemptAt: &future}, false},
+		{"Failed for good", Enrichment{Status: EnrichmentFailed, NextAttemptAt: &past}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			due := tt.enrichment.Due(now)
+
+			// Assert
+			assert.Equal(t, tt.want, due)
+		})
+	}
+}
+
+func TestOrder_Transition(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Follow the lifecycle of an order", func(t *testing.T) {
+		// Arrange
+		order := &Order{ID: "order-123"}
+
+		// Act
+		for _, status := range []OrderStatus{StatusCreated, StatusEnriched, StatusConfirmed, StatusShipped, StatusDelivered} {
+			require.NoError(t, order.Transition(status, "user:alice", "", at))
+		}
+
+		// Assert
+		assert.Equal(t, StatusDelivered, order.Status)
+		require.Len(t, order.History, 5)
+		assert.Equal(t, StatusTransition{To: StatusCreated, Actor: "user:alice", At: at}, order.History[0])
+		assert.Equal(t, StatusShipped, order.History[4].From)
+		assert.Equal(t, StatusDelivered, order.History[4].To)
+	})
+
+	t.Run("Record the reason of a cancellation", func(t *testing.T) {
+		// Arrange
+		order := &Order{ID: "order-123", Status: StatusConfirmed}
+
+		// Act
+		err := order.Transition(StatusCancelled, "api-key:key-1", "customer changed their mind", at)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, StatusCancelled, order.Status)
+		assert.Equal(t, "customer changed their mind", order.History[0].Reason)
+	})
+
+	t.Run("Reject transitions outside the graph", func(t *testing.T) {
+		cases := []struct {
+			from OrderStatus
+			to   OrderStatus
+		}{
+			{"", StatusEnriched},
+			{StatusCreated, StatusConfirmed},
+			{StatusEnriched, StatusShipped},
+			{StatusShipped, StatusCancelled},
+			{StatusDelivered, StatusCancelled},
+			{StatusCancelled, StatusCreated},
+			{StatusConfirmed, StatusConfirmed},
+		}
+		for _, tc := range cases {
+			// Arrange
+			order := &Order{ID: "order-123", Status: tc.from}
+
+			// Act
+			err := order.Transition(tc.to, "user:alice", "", at)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidStatusTransition, "%s to %s", tc.from, tc.to)
+			assert.ErrorIs(t, err, apperror.ErrConflict)
+			assert.Equal(t, tc.from, order.Status)
+			assert.Empty(t, order.History)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// RefundStatus is the state of the refund of a cancelled order
+type RefundStatus string
+
+const (
+	// RefundPending refunds wait to be paid out by hand, as no payment
+	// provider is configured
+	RefundPending RefundStatus = "PENDING"
+	// RefundSucceeded refunds were accepted by the payment provider
+	RefundSucceeded RefundStatus = "REFUNDED"
+	// RefundFailed refunds were not accepted by the payment provider; Error
+	// explains why
+	RefundFailed RefundStatus = "FAILED"
+)
+
+// Refund records the money paid back for a cancelled order, in the currency
+// of the order
+type Refund struct {
+	ID     string       `json:"id"`
+	Amount money.Money  `json:"amount"`
+	Reason string       `json:"reason,omitempty"`
+	Status RefundStatus `json:"status"`
+	// Reference identifies the refund at the payment provider
+	Reference string    `json:"reference,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MarshalJSON custom marshaling for Refund. The amount is written as an
+// exact decimal number next to its currency.
+func (r Refund) MarshalJSON() ([]byte, error) {
+	type Alias Refund
+
+	return json.Marshal(struct {
+		Alias
+		Amount   json.Number `json:"amount" swaggertype:"number"`
+		Currency string      `json:"currency,omitempty"`
+	}{
+		Alias:    Alias(r),
+		Amount:   r.Amount.Number(),
+		Currency: r.Amount.Currency,
+	})
+}
+
+// UnmarshalJSON custom unmarshaling for Refund. Refunds recorded before
+// amounts carried a currency are read in money.DefaultCurrency.
+func (r *Refund) UnmarshalJSON(data []byte) error {
+	type Alias Refund
+	aux := &struct {
+		*Alias
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}{
+		Alias: (*Alias)(r),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	r.Amount, err = money.ParseRounded(numberOrZero(aux.Amount), currency)
+	return err
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// Dimensions the daily sales are aggregated by
+const (
+	GroupByProduct         = "product"
+	GroupByCategory        = "category"
+	GroupByCustomerSegment = "customer_segment"
+)
+
+// ReportGroups lists the dimensions of the sales report tables
+var ReportGroups = []string{GroupByProduct, GroupByCategory, GroupByCustomerSegment}
+
+// Customer segments. A customer is new on the day of their first order that
+// was not cancelled, and returning on the days after.
+const (
+	SegmentNew       = "new"
+	SegmentReturning = "returning"
+)
+
+// Uncategorized is the category key of products without a category
+const Uncategorized = "uncategorized"
+
+// ReportDateLayout is the layout of report days, in UTC
+const ReportDateLayout = "2006-01-02"
+
+// MaxReportDays is the longest range of days a sales report covers
+const MaxReportDays = 366
+
+// DefaultReportDays is the number of days, up to today, a sales report
+// covers when no range is given
+const DefaultReportDays = 30
+
+// SalesReportRow is a row of a materialized sales report table: the sales of
+// a day for one product, category or customer segment in one currency.
+// Revenue is what was charged before tax; an order with several products of
+// a category counts once in Orders. The amounts are in the currency of
+// Revenue.
+type SalesReportRow struct {
+	Date    string      `json:"date"`
+	GroupBy string      `json:"groupBy"`
+	Key     string      `json:"key"`
+	Label   string      `json:"label"`
+	Orders  int         `json:"orders"`
+	Units   int         `json:"units"`
+	Revenue money.Money `json:"revenue"`
+	Tax     money.Money `json:"tax"`
+	Total   money.Money `json:"total"`
+}
+
+// MarshalJSON custom marshaling for SalesReportRow. The amounts are written
+// as exact decimal numbers next to their currency.
+func (r SalesReportRow) MarshalJSON() ([]byte, error) {
+	type Alias SalesReportRow
+
+	return json.Marshal(struct {
+		Alias
+		Revenue  json.Number `json:"revenue" swaggertype:"number"`
+		Tax      json.Number `json:"tax" swaggertype:"number"`
+		Total    json.Number `json:"total" swaggertype:"number"`
+		Currency string      `json:"currency"`
+	}{
+		Alias:    Alias(r),
+		Revenue:  r.Revenue.Number(),
+		Tax:      r.Tax.Number(),
+		Total:    r.Total.Number(),
+		Currency: r.Revenue.Currency,
+	})
+}
+
+// SalesReportCSVHeader is the header row of sales report exports
+var SalesReportCSVHeader = []string{"date", "groupBy", "key", "label", "currency", "orders", "units", "revenue", "tax", "total"}
+
+// CSVRecord returns the cells of the row in the order of
+// SalesReportCSVHeader
+func (r *SalesReportRow) CSVRecord() []string {
+	return []string{
+		r.Date,
+		r.GroupBy,
+		r.Key,
+		r.Label,
+		r.Revenue.Currency,
+		strconv.Itoa(r.Orders),
+		strconv.Itoa(r.Units),
+		r.Revenue.Decimal(),
+		r.Tax.Decimal(),
+		r.Total.Decimal(),
+	}
+}
+
+// SalesReportQuery selects the days and dimension of a sales report. From
+// and To are UTC midnights; both days are included.
+type SalesReportQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy string
+}
+
+// ParseSalesReportQuery validates the from, to and group_by parameters of a
+// sales report. The report covers the DefaultReportDays up to today when
+// the days are left out, and is grouped by product by default.
+func ParseSalesReportQuery(from, to, groupBy string, now time.Time) (SalesReportQuery, error) {
+	query := SalesReportQuery{GroupBy: strings.ToLower(strings.TrimSpace(groupBy))}
+	if query.GroupBy == "" {
+		query.GroupBy = GroupByProduct
+	}
+	switch query.GroupBy {
+	case GroupByProduct, GroupByCategory, GroupByCustomerSegment:
+	default:
+		return SalesReportQuery{}, ErrInvalidReportGroup
+	}
+
+	var err error
+	query.To = ReportDay(now)
+	if to != "" {
+		if query.To, err = time.Parse(ReportDateLayout, to); err != nil {
+			return SalesReportQuery{}, ErrInvalidReportDate
+		}
+	}
+	query.From = query.To.AddDate(0, 0, 1-DefaultReportDays)
+	if from != "" {
+		if query.From, err = time.Parse(ReportDateLayout, from); err != nil {
+			return SalesReportQuery{}, ErrInvalidReportDate
+		}
+	}
+
+	if query.From.After(query.To) || query.To.Sub(query.From) >= MaxReportDays*24*time.Hour {
+		return SalesReportQuery{}, ErrInvalidReportRange
+	}
+	return query, nil
+}
+
+// ReportDay returns the UTC midnight of the day of a time
+func ReportDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// SalesTotals sums the rows of a sales report in a currency
+type SalesTotals struct {
+	Currency string      `json:"currency"`
+	Units    int         `json:"units"`
+	Revenue  json.Number `json:"revenue" swaggertype:"number"`
+	Tax      json.Number `json:"tax" swaggertype:"number"`
+	Total    json.Number `json:"total" swaggertype:"number"`
+}
+
+// SalesReport represents the API response of a sales report. Totals sums
+// the rows of each currency, since sales in different currencies cannot be
+// added up. RefreshedAt is when its tables were last aggregated; orders
+// placed or changed since are not in it yet.
+type SalesReport struct {
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	GroupBy     string           `json:"groupBy"`
+	Rows        []SalesReportRow `json:"rows"`
+	Totals      []SalesTotals    `json:"totals"`
+	RefreshedAt *time.Time       `json:"refreshedAt,omitempty"`
+}
+
+// salesTotals accumulates the rows of a currency
+type salesTotals struct {
+	units               int
+	revenue, tax, total money.Money
+}
+
+// NewSalesReport creates the report of the rows of a query, ordered by the
+// table they were read from. It fails with money.ErrOutOfRange when a total
+// does not fit an amount.
+func NewSalesReport(query SalesReportQuery, rows []SalesReportRow, refreshedAt *time.Time) (*SalesReport, error) {
+	report := &SalesReport{
+		From:        query.From.Format(ReportDateLayout),
+		To:          query.To.Format(ReportDateLayout),
+		GroupBy:     query.GroupBy,
+		Rows:        rows,
+		Totals:      []SalesTotals{},
+		RefreshedAt: refreshedAt,
+	}
+	if report.Rows == nil {
+		report.Rows = []SalesReportRow{}
+	}
+
+	totals := make(map[string]*salesTotals)
+	for _, row := range rows {
+		currency := row.Revenue.Currency
+		sums, ok := totals[currency]
+		if !ok {
+			sums = &salesTotals{
+				revenue: money.New(0, currency),
+				tax:     money.New(0, currency),
+				total:   money.New(0, currency),
+			}
+			totals[currency] = sums
+		}
+
+		var err error
+		sums.units += row.Units
+		if sums.revenue, err = sums.revenue.Add(row.Revenue); err != nil {
+			return nil, err
+		}
+		if sums.tax, err = sums.tax.Add(row.Tax); err != nil {
+			return nil, err
+		}
+		if sums.total, err = sums.total.Add(row.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	for currency, sums := range totals {
+		report.Totals = append(report.Totals, SalesTotals{
+			Currency: currency,
+			Units:    sums.units,
+			Revenue:  sums.revenue.Number(),
+			Tax:      sums.tax.Number(),
+			Total:    sums.total.Number(),
+		})
+	}
+	slices.SortFunc(report.Totals, func(a, b SalesTotals) int {
+		return strings.Compare(a.Currency, b.Currency)
+	})
+	return report, nil
+}
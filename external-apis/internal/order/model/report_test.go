@@ -0,0 +1,95 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSalesReportQuery(t *testing.T) {
+	now := time.Date(2024, 3, 15, 18, 30, 0, 0, time.UTC)
+	day := func(value string) time.Time {
+		parsed, err := time.Parse(ReportDateLayout, value)
+		require.NoError(t, err)
+		return parsed
+	}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		groupBy string
+		want    SalesReportQuery
+		wantErr error
+	}{
+		{"Defaults to the last 30 days by product", "", "", "", SalesReportQuery{From: day("2024-02-15"), To: day("2024-03-15"), GroupBy: GroupByProduct}, nil},
+		{"Range and dimension", "2024-01-01", "2024-01-31", "Customer_Segment", SalesReportQuery{From: day("2024-01-01"), To: day("2024-01-31"), GroupBy: GroupByCustomerSegment}, nil},
+		{"Single day", "2024-01-01", "2024-01-01", "category", SalesReportQuery{From: day("2024-01-01"), To: day("2024-01-01"), GroupBy: GroupByCategory}, nil},
+		{"Unknown dimension", "", "", "region", SalesReportQuery{}, ErrInvalidReportGroup},
+		{"Malformed day", "01/01/2024", "", "", SalesReportQuery{}, ErrInvalidReportDate},
+		{"From after to", "2024-02-01", "2024-01-01", "", SalesReportQuery{}, ErrInvalidReportRange},
+		{"Range too long", "2023-01-01", "2024-01-02", "", SalesReportQuery{}, ErrInvalidReportRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			query, err := ParseSalesReportQuery(tt.from, tt.to, tt.groupBy, now)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, apperror.ErrValidation)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, query)
+		})
+	}
+}
+
+func TestNewSalesReport(t *testing.T) {
+	// Arrange
+	query := SalesReportQuery{
+		From:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		GroupBy: GroupByProduct,
+	}
+	rows := []SalesReportRow{
+		{Date: "2024-01-01", GroupBy: GroupByProduct, Key: "product-001", Orders: 2, Units: 3, Revenue: money.New(10, "USD"), Tax: money.New(2, "USD"), Total: money.New(12, "USD")},
+		{Date: "2024-01-01", GroupBy: GroupByProduct, Key: "product-001", Orders: 1, Units: 2, Revenue: money.New(1000, "JPY"), Tax: money.New(100, "JPY"), Total: money.New(1100, "JPY")},
+		{Date: "2024-01-02", GroupBy: GroupByProduct, Key: "product-001", Orders: 1, Units: 1, Revenue: money.New(20, "USD"), Tax: money.New(4, "USD"), Total: money.New(24, "USD")},
+	}
+
+	// Act
+	report, err := NewSalesReport(query, rows, nil)
+	require.NoError(t, err)
+	empty, err := NewSalesReport(query, nil, nil)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "2024-01-01", report.From)
+	assert.Equal(t, "2024-01-02", report.To)
+	assert.Equal(t, []SalesTotals{
+		{Currency: "JPY", Units: 2, Revenue: "1000", Tax: "100", Total: "1100"},
+		{Currency: "USD", Units: 4, Revenue: "0.30", Tax: "0.06", Total: "0.36"},
+	}, report.Totals, "sales in different currencies are totalled apart")
+	assert.NotNil(t, empty.Rows, "an empty report has no rows rather than null")
+	assert.NotNil(t, empty.Totals)
+}
+
+func TestSalesReportRow_CSVRecord(t *testing.T) {
+	// Arrange
+	row := SalesReportRow{Date: "2024-01-01", GroupBy: GroupByCategory, Key: "electronics", Label: "Electronics", Orders: 2, Units: 3, Revenue: money.New(10000, "EUR"), Tax: money.New(1900, "EUR"), Total: money.New(11900, "EUR")}
+
+	// Act
+	record := row.CSVRecord()
+
+	// Assert
+	assert.Len(t, record, len(SalesReportCSVHeader))
+	assert.Equal(t, []string{"2024-01-01", "category", "electronics", "Electronics", "EUR", "2", "3", "100.00", "19.00", "119.00"}, record)
+}
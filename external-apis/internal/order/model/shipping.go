@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// Dimensions are the outer measurements of a product as it is packed for
+// shipping, in millimetres
+type Dimensions struct {
+	LengthMM int `json:"lengthMm"`
+	WidthMM  int `json:"widthMm"`
+	HeightMM int `json:"heightMm"`
+}
+
+// VolumeCM3 returns the volume the dimensions take up in cubic centimetres
+func (d Dimensions) VolumeCM3() float64 {
+	return float64(d.LengthMM) * float64(d.WidthMM) * float64(d.HeightMM) / 1000
+}
+
+// ShippingRate is a way of shipping an order: a service level of a carrier,
+// what it costs and when it is expected to arrive
+type ShippingRate struct {
+	Carrier      string      `json:"carrier"`
+	ServiceLevel string      `json:"serviceLevel"`
+	Cost         money.Money `json:"cost"`
+	// EstimatedDays is how many days delivery takes; EstimatedDelivery is
+	// the day it is expected when shipped at the time of the quote
+	EstimatedDays     int       `json:"estimatedDays"`
+	EstimatedDelivery time.Time `json:"estimatedDelivery"`
+}
+
+// MarshalJSON custom marshaling for ShippingRate. The cost is written as an
+// exact decimal number next to its currency.
+func (r ShippingRate) MarshalJSON() ([]byte, error) {
+	type Alias ShippingRate
+
+	return json.Marshal(struct {
+		Alias
+		Cost     json.Number `json:"cost" swaggertype:"number"`
+		Currency string      `json:"currency"`
+	}{
+		Alias:    Alias(r),
+		Cost:     r.Cost.Number(),
+		Currency: r.Cost.Currency,
+	})
+}
+
+// UnmarshalJSON custom unmarshaling for ShippingRate. Rates quoted before
+// costs carried a currency are read in money.DefaultCurrency.
+func (r *ShippingRate) UnmarshalJSON(data []byte) error {
+	type Alias ShippingRate
+	aux := &struct {
+		*Alias
+		Cost     json.Number `json:"cost"`
+		Currency string      `json:"currency"`
+	}{
+		Alias: (*Alias)(r),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	r.Cost, err = money.ParseRounded(numberOrZero(aux.Cost), currency)
+	return err
+}
+
+// ShippingAddress is where shipping is quoted to. Country is the ISO 3166-1
+// alpha-2 code, e.g. US.
+type ShippingAddress struct {
+	Country    string `json:"country" binding:"required,len=2"`
+	Region     string `json:"region,omitempty" binding:"max=64"`
+	PostalCode string `json:"postalCode,omitempty" binding:"max=16"`
+	City       string `json:"city,omitempty" binding:"max=100"`
+}
+
+// ShippingRatesRequest represents the request to quote the shipping of
+// products to an address. A product listed twice ships twice.
+type ShippingRatesRequest struct {
+	ProductIDs []string        `json:"productIds" binding:"required,min=1,max=100,dive,required"`
+	Address    ShippingAddress `json:"address" binding:"required"`
+}
@@ -0,0 +1,276 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// OrderAddress is the customer address an order ships to
+type OrderAddress struct {
+	Line1      string `json:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code, e.g. US
+	Country string `json:"country"`
+}
+
+// CustomerSnapshot is the customer of an order as they were when it was
+// enriched
+type CustomerSnapshot struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name"`
+	Email   string        `json:"email"`
+	Phone   string        `json:"phone,omitempty"`
+	Address *OrderAddress `json:"address,omitempty"`
+}
+
+// LineItem is a product of an order as it was sold: its name, the price paid
+// per unit and, when a promotion lowered it, the list price and discount per
+// unit. Tax is the share of the order's tax charged on the line. The amounts
+// are in the currency of UnitPrice.
+type LineItem struct {
+	ProductID   string      `json:"productId"`
+	Name        string      `json:"name"`
+	Category    string      `json:"category,omitempty"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unitPrice"`
+	ListPrice   money.Money `json:"listPrice,omitempty"`
+	Discount    money.Money `json:"discount,omitempty"`
+	PromotionID string      `json:"promotionId,omitempty"`
+	Tax         money.Money `json:"tax"`
+	Total       money.Money `json:"total"`
+}
+
+// MarshalJSON custom marshaling for LineItem. The amounts are written as
+// exact decimal numbers next to their currency.
+func (l LineItem) MarshalJSON() ([]byte, error) {
+	type Alias LineItem
+
+	aux := struct {
+		Alias
+		UnitPrice json.Number `json:"unitPrice" swaggertype:"number"`
+		ListPrice json.Number `json:"listPrice,omitempty" swaggertype:"number"`
+		Discount  json.Number `json:"discount,omitempty" swaggertype:"number"`
+		Tax       json.Number `json:"tax" swaggertype:"number"`
+		Total     json.Number `json:"total" swaggertype:"number"`
+		Currency  string      `json:"currency"`
+	}{
+		Alias:     Alias(l),
+		UnitPrice: l.UnitPrice.Number(),
+		Tax:       l.Tax.Number(),
+		Total:     l.Total.Number(),
+		Currency:  l.UnitPrice.Currency,
+	}
+	if l.ListPrice.Amount != 0 {
+		aux.ListPrice = l.ListPrice.Number()
+	}
+	if l.Discount.Amount != 0 {
+		aux.Discount = l.Discount.Number()
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON custom unmarshaling for LineItem. Line items snapshotted
+// before amounts carried a currency are read in money.DefaultCurrency.
+func (l *LineItem) UnmarshalJSON(data []byte) error {
+	type Alias LineItem
+	aux := &struct {
+		*Alias
+		UnitPrice json.Number `json:"unitPrice"`
+		ListPrice json.Number `json:"listPrice"`
+		Discount  json.Number `json:"discount"`
+		Tax       json.Number `json:"tax"`
+		Total     json.Number `json:"total"`
+		Currency  string      `json:"currency"`
+	}{
+		Alias: (*Alias)(l),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	if l.UnitPrice, err = money.ParseRounded(numberOrZero(aux.UnitPrice), currency); err != nil {
+		return err
+	}
+	if l.ListPrice, err = parseAmount(aux.ListPrice, currency); err != nil {
+		return err
+	}
+	if l.Discount, err = parseAmount(aux.Discount, currency); err != nil {
+		return err
+	}
+	if l.Tax, err = money.ParseRounded(numberOrZero(aux.Tax), currency); err != nil {
+		return err
+	}
+	l.Total, err = money.ParseRounded(numberOrZero(aux.Total), currency)
+	return err
+}
+
+// OrderSnapshot freezes what an order was placed with once it is enriched,
+// so later changes to the catalog or the customer do not rewrite it. The
+// amounts are in the currency of the order.
+type OrderSnapshot struct {
+	Customer  *CustomerSnapshot `json:"customer,omitempty"`
+	LineItems []LineItem        `json:"lineItems"`
+	Subtotal  money.Money       `json:"subtotal"`
+	Tax       money.Money       `json:"tax"`
+	Total     money.Money       `json:"total"`
+	TakenAt   time.Time         `json:"takenAt"`
+}
+
+// MarshalJSON custom marshaling for OrderSnapshot. The amounts are written
+// as exact decimal numbers next to their currency.
+func (s OrderSnapshot) MarshalJSON() ([]byte, error) {
+	type Alias OrderSnapshot
+
+	return json.Marshal(struct {
+		Alias
+		Subtotal json.Number `json:"subtotal" swaggertype:"number"`
+		Tax      json.Number `json:"tax" swaggertype:"number"`
+		Total    json.Number `json:"total" swaggertype:"number"`
+		Currency string      `json:"currency,omitempty"`
+	}{
+		Alias:    Alias(s),
+		Subtotal: s.Subtotal.Number(),
+		Tax:      s.Tax.Number(),
+		Total:    s.Total.Number(),
+		Currency: s.Total.Currency,
+	})
+}
+
+// UnmarshalJSON custom unmarshaling for OrderSnapshot. Snapshots taken
+// before amounts carried a currency are read in money.DefaultCurrency.
+func (s *OrderSnapshot) UnmarshalJSON(data []byte) error {
+	type Alias OrderSnapshot
+	aux := &struct {
+		*Alias
+		Subtotal json.Number `json:"subtotal"`
+		Tax      json.Number `json:"tax"`
+		Total    json.Number `json:"total"`
+		Currency string      `json:"currency"`
+	}{
+		Alias: (*Alias)(s),
+	}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	var err error
+	if s.Subtotal, err = money.ParseRounded(numberOrZero(aux.Subtotal), currency); err != nil {
+		return err
+	}
+	if s.Tax, err = money.ParseRounded(numberOrZero(aux.Tax), currency); err != nil {
+		return err
+	}
+	s.Total, err = money.ParseRounded(numberOrZero(aux.Total), currency)
+	return err
+}
+
+// Clone returns a deep copy of the snapshot
+func (s *OrderSnapshot) Clone() *OrderSnapshot {
+	clone := *s
+	clone.LineItems = slices.Clone(s.LineItems)
+	if s.Customer != nil {
+		customer := *s.Customer
+		if s.Customer.Address != nil {
+			address := *s.Customer.Address
+			customer.Address = &address
+		}
+		clone.Customer = &customer
+	}
+	return &clone
+}
+
+// TakeSnapshot freezes the enriched customer, products and tax of the order
+// into its snapshot. Products ordered more than once at the same price make
+// up a single line item, and the tax is shared out over the line items by
+// their amount. An order is only snapshotted once; later calls keep the
+// first snapshot.
+func (o *Order) TakeSnapshot(at time.Time) {
+	if o.Snapshot != nil {
+		return
+	}
+
+	snapshot := &OrderSnapshot{Total: o.Total, TakenAt: at}
+	if o.Customer != nil {
+		snapshot.Customer = &CustomerSnapshot{
+			ID:    o.Customer.ID,
+			Name:  o.Customer.Name,
+			Email: o.Customer.Email,
+			Phone: o.Customer.Phone,
+		}
+		if o.Address != nil {
+			address := *o.Address
+			snapshot.Customer.Address = &address
+		}
+	}
+
+	lines := make(map[string]int, len(o.Products))
+	for _, product := range o.Products {
+		key := fmt.Sprintf("%s@%s", product.ID, product.Price)
+		if i, ok := lines[key]; ok {
+			snapshot.LineItems[i].Quantity++
+			continue
+		}
+
+		lines[key] = len(snapshot.LineItems)
+		snapshot.LineItems = append(snapshot.LineItems, LineItem{
+			ProductID:   product.ID,
+			Name:        product.Name,
+			Category:    product.Category,
+			Quantity:    1,
+			UnitPrice:   product.Price,
+			ListPrice:   product.ListPrice,
+			Discount:    product.Discount,
+			PromotionID: product.PromotionID,
+		})
+	}
+
+	// The order total was worked out from the same prices and tax, so the
+	// amounts below stay within range and in a single currency
+	currency := o.Total.Currency
+	if len(snapshot.LineItems) > 0 {
+		currency = snapshot.LineItems[0].UnitPrice.Currency
+	}
+	snapshot.Tax = money.New(o.Tax.Amount, currency)
+	snapshot.Subtotal = money.New(0, currency)
+	amounts := make([]money.Money, len(snapshot.LineItems))
+	for i, item := range snapshot.LineItems {
+		amounts[i], _ = item.UnitPrice.Multiply(int64(item.Quantity))
+		snapshot.Subtotal, _ = snapshot.Subtotal.Add(amounts[i])
+	}
+
+	// The last line item takes what rounding leaves over, so the line item
+	// taxes add up to the tax of the order
+	remaining := snapshot.Tax
+	for i := range snapshot.LineItems {
+		tax := remaining
+		if i < len(snapshot.LineItems)-1 && snapshot.Subtotal.Amount > 0 {
+			tax, _ = snapshot.Tax.Scale(big.NewRat(amounts[i].Amount, snapshot.Subtotal.Amount))
+		}
+		remaining = money.New(remaining.Amount-tax.Amount, currency)
+		snapshot.LineItems[i].Tax = tax
+		snapshot.LineItems[i].Total, _ = amounts[i].Add(tax)
+	}
+
+	o.Snapshot = snapshot
+}
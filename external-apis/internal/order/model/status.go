@@ -0,0 +1,78 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// OrderStatus is where an order is in its lifecycle
+type OrderStatus string
+
+const (
+	// StatusCreated orders have been placed but are not fully enriched yet
+	StatusCreated OrderStatus = "CREATED"
+	// StatusEnriched orders carry their customer, products and tax
+	StatusEnriched  OrderStatus = "ENRICHED"
+	StatusConfirmed OrderStatus = "CONFIRMED"
+	StatusShipped   OrderStatus = "SHIPPED"
+	StatusDelivered OrderStatus = "DELIVERED"
+	StatusCancelled OrderStatus = "CANCELLED"
+)
+
+// SystemActor is the actor of the transitions the order service makes by
+// itself, such as enriching an order in the background
+const SystemActor = "system"
+
+// statusTransitions lists the statuses an order can move to from each
+// status. The empty status is that of an order being placed. Orders are
+// enriched by the service before they can be confirmed, and can be
+// cancelled until they are shipped; DELIVERED and CANCELLED are final.
+var statusTransitions = map[OrderStatus][]OrderStatus{
+	"":              {StatusCreated},
+	StatusCreated:   {StatusEnriched, StatusCancelled},
+	StatusEnriched:  {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusShipped, StatusCancelled},
+	StatusShipped:   {StatusDelivered},
+}
+
+// CanTransitionTo checks if an order with status s can move to next.
+// Staying in the same status is not a transition.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	return slices.Contains(statusTransitions[s], next)
+}
+
+// StatusTransition records a status change of an order: who made it, when
+// and why. From is empty for the transition placing the order.
+type StatusTransition struct {
+	From   OrderStatus `json:"from,omitempty"`
+	To     OrderStatus `json:"to"`
+	Actor  string      `json:"actor"`
+	Reason string      `json:"reason,omitempty"`
+	At     time.Time   `json:"at"`
+}
+
+// Transition moves the order to another status at a time and appends the
+// change to its history. It returns ErrInvalidStatusTransition if the
+// status cannot be reached from the current one.
+func (o *Order) Transition(to OrderStatus, actor, reason string, at time.Time) error {
+	if !o.Status.CanTransitionTo(to) {
+		return ErrInvalidStatusTransition
+	}
+
+	o.History = append(o.History, StatusTransition{
+		From:   o.Status,
+		To:     to,
+		Actor:  actor,
+		Reason: reason,
+		At:     at,
+	})
+	o.Status = to
+	return nil
+}
+
+// TransitionRequest represents the optional body of a request moving an
+// order to another status
+type TransitionRequest struct {
+	// Reason explains the change; it is recorded in the order's history
+	Reason string `json:"reason" binding:"max=500"`
+}
@@ -0,0 +1,41 @@
+// Package payment pays back cancelled orders through a payment provider.
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"external-apis/internal/shared/money"
+)
+
+var (
+	// ErrUnavailable is returned when the payment provider cannot be reached
+	// or fails
+	ErrUnavailable = errors.New("payment provider unavailable")
+	// ErrRejected is returned when the payment provider refuses a refund,
+	// e.g. for a payment it already refunded
+	ErrRejected = errors.New("payment provider rejected refund")
+)
+
+// PaymentRefunder refunds the payment of an order. Errors wrap ErrUnavailable
+// when the refund may succeed later and ErrRejected when it never will.
+// Implementations should treat RefundID as an idempotency key, so a refund
+// that is retried is paid out once.
+type PaymentRefunder interface {
+	Refund(ctx context.Context, req Request) (*Result, error)
+}
+
+// Request describes a refund of an order
+type Request struct {
+	RefundID   string
+	OrderID    string
+	CustomerID string
+	Amount     money.Money
+	Reason     string
+}
+
+// Result is a refund the payment provider accepted
+type Result struct {
+	// Reference identifies the refund at the payment provider
+	Reference string
+}
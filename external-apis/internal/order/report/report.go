@@ -0,0 +1,153 @@
+// Package report aggregates orders into the daily sales report tables, one
+// row per day and product, category or customer segment.
+package report
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+)
+
+// segmentLabels names the customer segments
+var segmentLabels = map[string]string{
+	model.SegmentNew:       "New customers",
+	model.SegmentReturning: "Returning customers",
+}
+
+// Counts checks if an order counts as a sale: it was not cancelled and its
+// snapshot was taken, so it is priced and taxed for good
+func Counts(order *model.Order) bool {
+	return order.Status != model.StatusCancelled && order.Snapshot != nil
+}
+
+// Aggregate builds the sales report rows, for every dimension, of the days
+// from and to, both included, out of the orders placed on them. Sales are
+// read from the order snapshots, so later catalog changes do not rewrite
+// them. orders must hold every order of the customers, not only those of the
+// days, as the customer segment of an order depends on the orders before it.
+// Sales in different currencies make up different rows. Rows are sorted by
+// day, dimension, currency, revenue from highest and key. It fails with
+// money.ErrOutOfRange when a row does not fit an amount.
+func Aggregate(orders []*model.Order, from, to time.Time) ([]model.SalesReportRow, error) {
+	firstOrders := make(map[string]time.Time)
+	for _, order := range orders {
+		if !Counts(order) {
+			continue
+		}
+		day := model.ReportDay(order.CreatedAt)
+		if first, ok := firstOrders[order.CustomerID]; !ok || day.Before(first) {
+			firstOrders[order.CustomerID] = day
+		}
+	}
+
+	rows := make(map[rowKey]*model.SalesReportRow)
+	for _, order := range orders {
+		day := model.ReportDay(order.CreatedAt)
+		if !Counts(order) || day.Before(from) || day.After(to) {
+			continue
+		}
+
+		date := day.Format(model.ReportDateLayout)
+		counted := make(map[rowKey]bool)
+		add := func(groupBy, key, label string, units int, revenue, tax, total money.Money) error {
+			k := rowKey{date: date, groupBy: groupBy, key: key, currency: revenue.Currency}
+			row, ok := rows[k]
+			if !ok {
+				row = &model.SalesReportRow{
+					Date:    date,
+					GroupBy: groupBy,
+					Key:     key,
+					Label:   label,
+					Revenue: money.New(0, revenue.Currency),
+					Tax:     money.New(0, revenue.Currency),
+					Total:   money.New(0, revenue.Currency),
+				}
+				rows[k] = row
+			}
+			if !counted[k] {
+				counted[k] = true
+				row.Orders++
+			}
+
+			var err error
+			row.Units += units
+			if row.Revenue, err = row.Revenue.Add(revenue); err != nil {
+				return err
+			}
+			if row.Tax, err = row.Tax.Add(tax); err != nil {
+				return err
+			}
+			row.Total, err = row.Total.Add(total)
+			return err
+		}
+
+		snapshot := order.Snapshot
+		units := 0
+		for _, item := range snapshot.LineItems {
+			revenue, err := item.UnitPrice.Multiply(int64(item.Quantity))
+			if err != nil {
+				return nil, err
+			}
+			if err := add(model.GroupByProduct, item.ProductID, item.Name, item.Quantity, revenue, item.Tax, item.Total); err != nil {
+				return nil, err
+			}
+			category, label := categoryOf(item.Category)
+			if err := add(model.GroupByCategory, category, label, item.Quantity, revenue, item.Tax, item.Total); err != nil {
+				return nil, err
+			}
+			units += item.Quantity
+		}
+
+		segment := model.SegmentReturning
+		if firstOrders[order.CustomerID].Equal(day) {
+			segment = model.SegmentNew
+		}
+		if err := add(model.GroupByCustomerSegment, segment, segmentLabels[segment], units, snapshot.Subtotal, snapshot.Tax, snapshot.Total); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]model.SalesReportRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	slices.SortFunc(result, Compare)
+	return result, nil
+}
+
+// Compare orders report rows by day, dimension, currency, revenue from
+// highest and key
+func Compare(a, b model.SalesReportRow) int {
+	if c := strings.Compare(a.Date, b.Date); c != 0 {
+		return c
+	}
+	if c := slices.Index(model.ReportGroups, a.GroupBy) - slices.Index(model.ReportGroups, b.GroupBy); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.Revenue.Currency, b.Revenue.Currency); c != 0 {
+		return c
+	}
+	if c := b.Revenue.Cmp(a.Revenue); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Key, b.Key)
+}
+
+// rowKey identifies a row of the report tables
+type rowKey struct {
+	date     string
+	groupBy  string
+	key      string
+	currency string
+}
+
+// categoryOf returns the key and label of the category of a product
+func categoryOf(category string) (string, string) {
+	if category == "" {
+		return model.Uncategorized, "Uncategorized"
+	}
+	return strings.ToLower(category), category
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	jan1 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2 = jan1.AddDate(0, 0, 1)
+)
+
+// snapshotted returns an enriched order of a customer placed at a time,
+// snapshotted with its products
+func snapshotted(id, customerID string, at time.Time, products ...model.OrderProduct) *model.Order {
+	order := &model.Order{
+		ID:         id,
+		CustomerID: customerID,
+		Products:   products,
+		Status:     model.StatusEnriched,
+		CreatedAt:  at,
+	}
+	order.Total, _ = order.CalculateTotal()
+	order.TakeSnapshot(at)
+	return order
+}
+
+// rowsOf returns the rows of a dimension
+func rowsOf(rows []model.SalesReportRow, groupBy string) []model.SalesReportRow {
+	var selected []model.SalesReportRow
+	for _, row := range rows {
+		if row.GroupBy == groupBy {
+			selected = append(selected, row)
+		}
+	}
+	return selected
+}
+
+func TestAggregate(t *testing.T) {
+	mouse := model.OrderProduct{ID: "product-001", Name: "Wireless Mouse", Price: money.New(2000, "USD"), Category: "Electronics"}
+	keyboard := model.OrderProduct{ID: "product-002", Name: "Keyboard", Price: money.New(5000, "USD"), Category: "Electronics"}
+	mug := model.OrderProduct{ID: "product-003", Name: "Mug", Price: money.New(800, "USD")}
+
+	t.Run("Aggregate the orders of a day by every dimension", func(t *testing.T) {
+		// Arrange
+		orders := []*model.Order{
+			snapshotted("order-1", "customer-1", jan1.Add(9*time.Hour), mouse, mouse, keyboard),
+			snapshotted("order-2", "customer-2", jan1.Add(15*time.Hour), mouse, mug),
+		}
+
+		// Act
+		rows, err := Aggregate(orders, jan1, jan1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.SalesReportRow{
+			{Date: "2024-01-01", GroupBy: model.GroupByProduct, Key: "product-001", Label: "Wireless Mouse", Orders: 2, Units: 3, Revenue: money.New(6000, "USD"), Tax: money.New(0, "USD"), Total: money.New(6000, "USD")},
+			{Date: "2024-01-01", GroupBy: model.GroupByProduct, Key: "product-002", Label: "Keyboard", Orders: 1, Units: 1, Revenue: money.New(5000, "USD"), Tax: money.New(0, "USD"), Total: money.New(5000, "USD")},
+			{Date: "2024-01-01", GroupBy: model.GroupByProduct, Key: "product-003", Label: "Mug", Orders: 1, Units: 1, Revenue: money.New(800, "USD"), Tax: money.New(0, "USD"), Total: money.New(800, "USD")},
+		}, rowsOf(rows, model.GroupByProduct))
+		assert.Equal(t, []model.SalesReportRow{
+			{Date: "2024-01-01", GroupBy: model.GroupByCategory, Key: "electronics", Label: "Electronics", Orders: 2, Units: 4, Revenue: money.New(11000, "USD"), Tax: money.New(0, "USD"), Total: money.New(11000, "USD")},
+			{Date: "2024-01-01", GroupBy: model.GroupByCategory, Key: model.Uncategorized, Label: "Uncategorized", Orders: 1, Units: 1, Revenue: money.New(800, "USD"), Tax: money.New(0, "USD"), Total: money.New(800, "USD")},
+		}, rowsOf(rows, model.GroupByCategory))
+		assert.Equal(t, []model.SalesReportRow{
+			{Date: "2024-01-01", GroupBy: model.GroupByCustomerSegment, Key: model.SegmentNew, Label: "New customers", Orders: 2, Units: 5, Revenue: money.New(11800, "USD"), Tax: money.New(0, "USD"), Total: money.New(11800, "USD")},
+		}, rowsOf(rows, model.GroupByCustomerSegment))
+	})
+
+	t.Run("Customers return after the day of their first order", func(t *testing.T) {
+		// Arrange
+		orders := []*model.Order{
+			snapshotted("order-1", "customer-1", jan1, mug),
+			snapshotted("order-2", "customer-1", jan1.Add(time.Hour), mug),
+			snapshotted("order-3", "customer-1", jan2, mouse),
+			snapshotted("order-4", "customer-2", jan2, keyboard),
+		}
+
+		// Act
+		rows, err := Aggregate(orders, jan2, jan2)
+
+		// Assert
+		require.NoError(t, err)
+		segments := rowsOf(rows, model.GroupByCustomerSegment)
+		require.Len(t, segments, 2)
+		assert.Equal(t, model.SegmentNew, segments[0].Key)
+		assert.Equal(t, money.New(5000, "USD"), segments[0].Revenue)
+		assert.Equal(t, model.SegmentReturning, segments[1].Key)
+		assert.Equal(t, money.New(2000, "USD"), segments[1].Revenue)
+		for _, row := range rows {
+			assert.Equal(t, "2024-01-02", row.Date, "orders of other days are left out")
+		}
+	})
+
+	t.Run("Only enriched orders that were not cancelled count", func(t *testing.T) {
+		// Arrange
+		cancelled := snapshotted("order-1", "customer-1", jan1, mouse)
+		cancelled.Status = model.StatusCancelled
+		partial := &model.Order{ID: "order-2", CustomerID: "customer-2", CreatedAt: jan1, Products: []model.OrderProduct{mouse}}
+
+		// Act
+		rows, err := Aggregate([]*model.Order{cancelled, partial, snapshotted("order-3", "customer-1", jan2, mug)}, jan1, jan2)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, "2024-01-02", rows[0].Date)
+		assert.Equal(t, model.SegmentNew, rows[2].Key, "a cancelled order does not make its customer returning")
+	})
+
+	t.Run("Sales are read from the snapshot", func(t *testing.T) {
+		// Arrange
+		order := snapshotted("order-1", "customer-1", jan1, mouse)
+		order.Products[0].Name = "Renamed Mouse"
+		order.Products[0].Price = money.New(9900, "USD")
+
+		// Act
+		rows, err := Aggregate([]*model.Order{order}, jan1, jan1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Wireless Mouse", rows[0].Label)
+		assert.Equal(t, money.New(2000, "USD"), rows[0].Revenue)
+	})
+
+	t.Run("Sales in different currencies make up different rows", func(t *testing.T) {
+		// Arrange
+		euroMouse := model.OrderProduct{ID: "product-001", Name: "Wireless Mouse", Price: money.New(1800, "EUR"), Category: "Electronics"}
+		orders := []*model.Order{
+			snapshotted("order-1", "customer-1", jan1, mouse),
+			snapshotted("order-2", "customer-2", jan1, euroMouse, euroMouse),
+		}
+
+		// Act
+		rows, err := Aggregate(orders, jan1, jan1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.SalesReportRow{
+			{Date: "2024-01-01", GroupBy: model.GroupByProduct, Key: "product-001", Label: "Wireless Mouse", Orders: 1, Units: 2, Revenue: money.New(3600, "EUR"), Tax: money.New(0, "EUR"), Total: money.New(3600, "EUR")},
+			{Date: "2024-01-01", GroupBy: model.GroupByProduct, Key: "product-001", Label: "Wireless Mouse", Orders: 1, Units: 1, Revenue: money.New(2000, "USD"), Tax: money.New(0, "USD"), Total: money.New(2000, "USD")},
+		}, rowsOf(rows, model.GroupByProduct))
+	})
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/metrics"
+)
+
+// instrumentedName labels the metrics of the order repository
+const instrumentedName = "orders"
+
+// InstrumentedOrderRepository records the latency and outcome of every
+// operation of the wrapped repository in repository metrics
+type InstrumentedOrderRepository struct {
+	inner   PartitionedOrderRepository
+	metrics *metrics.RepositoryMetrics
+}
+
+// NewInstrumentedOrderRepository wraps inner so its operations are recorded
+// in m
+func NewInstrumentedOrderRepository(inner PartitionedOrderRepository, m *metrics.RepositoryMetrics) *InstrumentedOrderRepository {
+	return &InstrumentedOrderRepository{
+		inner:   inner,
+		metrics: m,
+	}
+}
+
+// GetByID retrieves an order by ID
+func (r *InstrumentedOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "GetByID")
+	order, err := r.inner.GetByID(ctx, id)
+	done(err)
+	return order, err
+}
+
+// GetAll retrieves all orders in the requested sort order
+func (r *InstrumentedOrderRepository) GetAll(ctx context.Context, sort string) ([]*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "GetAll")
+	orders, err := r.inner.GetAll(ctx, sort)
+	done(err)
+	return orders, err
+}
+
+// GetByCustomerID retrieves all orders placed by a customer
+func (r *InstrumentedOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "GetByCustomerID")
+	orders, err := r.inner.GetByCustomerID(ctx, customerID)
+	done(err)
+	return orders, err
+}
+
+// GetDueForEnrichment retrieves the partially enriched orders whose next
+// enrichment attempt is due at the given time
+func (r *InstrumentedOrderRepository) GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "GetDueForEnrichment")
+	orders, err := r.inner.GetDueForEnrichment(ctx, at)
+	done(err)
+	return orders, err
+}
+
+// Create creates a new order
+func (r *InstrumentedOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "Create")
+	created, err := r.inner.Create(ctx, order)
+	done(err)
+	return created, err
+}
+
+// Update updates an existing order
+func (r *InstrumentedOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "Update")
+	updated, err := r.inner.Update(ctx, id, order)
+	done(err)
+	return updated, err
+}
+
+// Delete deletes an order by ID
+func (r *InstrumentedOrderRepository) Delete(ctx context.Context, id string) error {
+	done := r.metrics.Started(instrumentedName, "Delete")
+	err := r.inner.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+// ExistsByID checks if an order exists by ID
+func (r *InstrumentedOrderRepository) ExistsByID(ctx context.Context, id string) bool {
+	done := r.metrics.Started(instrumentedName, "ExistsByID")
+	exists := r.inner.ExistsByID(ctx, id)
+	done(nil)
+	return exists
+}
+
+// ReassignCustomer moves every order placed by one customer to another
+func (r *InstrumentedOrderRepository) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	done := r.metrics.Started(instrumentedName, "ReassignCustomer")
+	moved, err := r.inner.ReassignCustomer(ctx, fromCustomerID, toCustomerID)
+	done(err)
+	return moved, err
+}
+
+// Transition moves an order to another status and records the change in
+// its history
+func (r *InstrumentedOrderRepository) Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "Transition")
+	order, err := r.inner.Transition(ctx, id, to, actor, reason, at)
+	done(err)
+	return order, err
+}
+
+// SaveRefund records the refund of an order
+func (r *InstrumentedOrderRepository) SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error) {
+	done := r.metrics.Started(instrumentedName, "SaveRefund")
+	order, err := r.inner.SaveRefund(ctx, id, refund)
+	done(err)
+	return order, err
+}
+
+// Tenants lists the tenants the wrapped repository holds orders of
+func (r *InstrumentedOrderRepository) Tenants() []string {
+	return r.inner.Tenants()
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedOrderRepository(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	repo := NewInstrumentedOrderRepository(NewTenantOrderRepository(), metrics.NewRepositoryMetrics(registry, "test-service"))
+
+	// Act
+	created, err := repo.Create(ctx, newTestOrder("customer-001"))
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, "order-missing")
+	require.ErrorIs(t, err, model.ErrOrderNotFound)
+
+	// Assert
+	expected := `
+# HELP repository_operations_total Total number of repository operations, by outcome.
+# TYPE repository_operations_total counter
+repository_operations_total{operation="Create",outcome="ok",repository="orders",service="test-service"} 1
+repository_operations_total{operation="GetByID",outcome="ok",repository="orders",service="test-service"} 1
+repository_operations_total{operation="GetByID",outcome="rejected",repository="orders",service="test-service"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "repository_operations_total"))
+	assert.Equal(t, []string{"default"}, repo.Tenants())
+}
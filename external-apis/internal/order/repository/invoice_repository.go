@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// InvoiceRepository defines the interface for the invoice documents
+// generated in the background
+type InvoiceRepository interface {
+	GetByID(ctx context.Context, orderID, id string) (*model.InvoiceDocument, error)
+	Create(ctx context.Context, document *model.InvoiceDocument) (*model.InvoiceDocument, error)
+	Update(ctx context.Context, document *model.InvoiceDocument) (*model.InvoiceDocument, error)
+}
+
+// MemoryInvoiceRepository implements InvoiceRepository using in-memory
+// storage
+type MemoryInvoiceRepository struct {
+	documents map[string]*model.InvoiceDocument
+	touched   map[string]time.Time
+	// ids generates the IDs of new documents
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryInvoiceRepository creates a new in-memory invoice document
+// repository
+func NewMemoryInvoiceRepository() *MemoryInvoiceRepository {
+	return &MemoryInvoiceRepository{
+		documents: make(map[string]*model.InvoiceDocument),
+		touched:   make(map[string]time.Time),
+		ids:       idgen.Default(),
+	}
+}
+
+// GetByID retrieves a document of an order by ID
+func (r *MemoryInvoiceRepository) GetByID(ctx context.Context, orderID, id string) (*model.InvoiceDocument, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	document, exists := r.documents[id]
+	if !exists || document.OrderID != orderID {
+		return nil, model.ErrInvoiceNotFound
+	}
+	return document.Clone(), nil
+}
+
+// Create stores a new pending document
+func (r *MemoryInvoiceRepository) Create(ctx context.Context, document *model.InvoiceDocument) (*model.InvoiceDocument, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	document = document.Clone()
+	if document.ID == "" {
+		document.ID = r.ids.New()
+	}
+	document.Status = model.InvoicePending
+	document.CreatedAt = time.Now().UTC()
+
+	r.documents[document.ID] = document
+	r.touched[document.ID] = time.Now()
+	return document.Clone(), nil
+}
+
+// Update replaces a stored document
+func (r *MemoryInvoiceRepository) Update(ctx context.Context, document *model.InvoiceDocument) (*model.InvoiceDocument, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	current, exists := r.documents[document.ID]
+	if !exists || current.OrderID != document.OrderID {
+		return nil, model.ErrInvoiceNotFound
+	}
+
+	document = document.Clone()
+	document.CreatedAt = current.CreatedAt
+	r.documents[document.ID] = document
+	r.touched[document.ID] = time.Now()
+	return document.Clone(), nil
+}
+
+// PurgeExpired removes documents written before cutoff
+func (r *MemoryInvoiceRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			delete(r.documents, id)
+			delete(r.touched, id)
+			purged++
+		}
+	}
+	return purged
+}
+
+// Reset removes all documents
+func (r *MemoryInvoiceRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.documents = make(map[string]*model.InvoiceDocument)
+	r.touched = make(map[string]time.Time)
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryInvoiceRepository(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Create a pending document", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryInvoiceRepository()
+
+		// Act
+		created, err := repo.Create(ctx, &model.InvoiceDocument{OrderID: "order-123", Kind: "invoice", Format: "pdf", Status: model.InvoiceReady})
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEmpty(t, created.ID)
+		assert.Equal(t, model.InvoicePending, created.Status)
+		assert.False(t, created.CreatedAt.IsZero())
+
+		found, err := repo.GetByID(ctx, "order-123", created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
+	t.Run("Documents belong to their order", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryInvoiceRepository()
+		created, err := repo.Create(ctx, &model.InvoiceDocument{OrderID: "order-123"})
+		require.NoError(t, err)
+
+		// Act
+		_, getErr := repo.GetByID(ctx, "order-999", created.ID)
+		created.OrderID = "order-999"
+		_, updateErr := repo.Update(ctx, created)
+
+		// Assert
+		assert.ErrorIs(t, getErr, model.ErrInvoiceNotFound)
+		assert.ErrorIs(t, updateErr, model.ErrInvoiceNotFound)
+	})
+
+	t.Run("Complete a document", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryInvoiceRepository()
+		created, err := repo.Create(ctx, &model.InvoiceDocument{OrderID: "order-123"})
+		require.NoError(t, err)
+		completedAt := time.Now().UTC()
+
+		// Act
+		ready := created.Clone()
+		ready.Status = model.InvoiceReady
+		ready.URL = "http://localhost:8081/documents/invoice.pdf"
+		ready.CompletedAt = &completedAt
+		ready.CreatedAt = time.Time{}
+		updated, err := repo.Update(ctx, ready)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.InvoiceReady, updated.Status)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt, "the creation time is kept")
+		found, err := repo.GetByID(ctx, "order-123", created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ready.URL, found.URL)
+	})
+
+	t.Run("Purge expired documents", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryInvoiceRepository()
+		created, err := repo.Create(ctx, &model.InvoiceDocument{OrderID: "order-123"})
+		require.NoError(t, err)
+
+		// Act
+		purged := repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Assert
+		assert.Equal(t, 1, purged)
+		_, err = repo.GetByID(ctx, "order-123", created.ID)
+		assert.ErrorIs(t, err, model.ErrInvoiceNotFound)
+	})
+}
+
+func TestTenantInvoiceRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantInvoiceRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	brandB := tenant.WithTenant(context.Background(), "brand-b")
+	created, err := repo.Create(brandA, &model.InvoiceDocument{OrderID: "order-123"})
+	require.NoError(t, err)
+
+	// Act
+	_, err = repo.GetByID(brandB, "order-123", created.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, model.ErrInvoiceNotFound)
+}
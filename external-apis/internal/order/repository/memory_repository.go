@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/shard"
+)
+
+// OrderRepository defines the interface for order operations
+type OrderRepository interface {
+	GetByID(ctx context.Context, id string) (*model.Order, error)
+	GetAll(ctx context.Context, sort string) ([]*model.Order, error)
+	GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error)
+	GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error)
+	Create(ctx context.Context, order *model.Order) (*model.Order, error)
+	Update(ctx context.Context, id string, order *model.Order) (*model.Order, error)
+	Delete(ctx context.Context, id string) error
+	ExistsByID(ctx context.Context, id string) bool
+	ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error)
+	Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error)
+	SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error)
+}
+
+// MemoryOrderRepository implements OrderRepository using in-memory storage.
+// Orders are spread over shards by ID, each with its own lock and creation
+// time index, so orders placed concurrently are stored in parallel unless
+// their IDs share a shard. Listings merge the indexes of the shards. The
+// repository stores copies of the orders it is given and returns copies of
+// the stored ones.
+type MemoryOrderRepository struct {
+	shards [shard.Count]*orderShard
+	// ids generates the IDs of new orders
+	ids idgen.Generator
+}
+
+// orderShard holds the orders whose IDs hash to it
+type orderShard struct {
+	orders map[string]*model.Order
+	// ids lists the IDs of the orders by creation time, then ID, so listings
+	// need not sort them per request
+	ids     []string
+	touched map[string]time.Time
+	mutex   sync.RWMutex
+}
+
+// NewMemoryOrderRepository creates a new in-memory order repository
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	repo := &MemoryOrderRepository{ids: idgen.Default()}
+	for i := range repo.shards {
+		repo.shards[i] = &orderShard{
+			orders:  make(map[string]*model.Order),
+			touched: make(map[string]time.Time),
+		}
+	}
+	return repo
+}
+
+// GetByID retrieves an order by ID
+func (r *MemoryOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	s := r.shardOf(id)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	order, exists := s.orders[id]
+	if !exists {
+		return nil, model.ErrOrderNotFound
+	}
+
+	return order.Clone(), nil
+}
+
+// GetAll retrieves all orders in the requested sort order, oldest first by
+// default
+func (r *MemoryOrderRepository) GetAll(ctx context.Context, sort string) ([]*model.Order, error) {
+	orders := r.collect(func(*model.Order) bool { return true })
+	if sort == model.SortByCreatedAtDesc {
+		slices.Reverse(orders)
+	}
+
+	return orders, nil
+}
+
+// GetByCustomerID retrieves all orders placed by a customer, oldest first
+func (r *MemoryOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	return r.collect(func(order *model.Order) bool {
+		return order.CustomerID == customerID
+	}), nil
+}
+
+// GetDueForEnrichment retrieves the partially enriched orders whose next
+// enrichment attempt is due at a time, oldest first
+func (r *MemoryOrderRepository) GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error) {
+	return r.collect(func(order *model.Order) bool {
+		return order.Enrichment != nil && order.Enrichment.Due(at)
+	}), nil
+}
+
+// Create creates a new order
+func (r *MemoryOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	order = order.Clone()
+	if order.ID == "" {
+		order.ID = r.ids.New()
+	}
+
+	s := r.shardOf(order.ID)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.orders[order.ID]; exists {
+		return nil, model.ErrOrderExists
+	}
+
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now().UTC()
+	}
+
+	s.orders[order.ID] = order
+	s.touched[order.ID] = time.Now()
+	s.addIDUnsafe(order)
+	return order.Clone(), nil
+}
+
+// Update updates an existing order. The status, history and refund of the
+// order are kept; they only change through Transition and SaveRefund, so an
+// update cannot undo a concurrent cancellation. A snapshot, once stored, is
+// kept too.
+func (r *MemoryOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	s := r.shardOf(id)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, exists := s.orders[id]
+	if !exists {
+		return nil, model.ErrOrderNotFound
+	}
+
+	order = order.Clone()
+	order.ID = id
+	order.Status = current.Status
+	order.History = current.History
+	order.Refund = current.Refund
+	if current.Snapshot != nil {
+		order.Snapshot = current.Snapshot
+	}
+	s.removeIDUnsafe(current)
+	s.orders[id] = order
+	s.touched[id] = time.Now()
+	s.addIDUnsafe(order)
+	return order.Clone(), nil
+}
+
+// Delete deletes an order by ID
+func (r *MemoryOrderRepository) Delete(ctx context.Context, id string) error {
+	s := r.shardOf(id)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, exists := s.orders[id]
+	if !exists {
+		return model.ErrOrderNotFound
+	}
+
+	s.removeIDUnsafe(current)
+	delete(s.orders, id)
+	delete(s.touched, id)
+	return nil
+}
+
+// ExistsByID checks if an order exists by ID
+func (r *MemoryOrderRepository) ExistsByID(ctx context.Context, id string) bool {
+	s := r.shardOf(id)
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, exists := s.orders[id]
+	return exists
+}
+
+// ReassignCustomer moves every order placed by one customer to another and
+// returns how many orders were moved. Shards are reassigned one at a time,
+// so a concurrent listing may see some of the orders moved.
+func (r *MemoryOrderRepository) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	moved := 0
+	for _, s := range r.shards {
+		s.mutex.Lock()
+		for id, order := range s.orders {
+			if order.CustomerID != fromCustomerID {
+				continue
+			}
+			reassigned := order.Clone()
+			reassigned.CustomerID = toCustomerID
+			s.orders[id] = reassigned
+			s.touched[id] = time.Now()
+			moved++
+		}
+		s.mutex.Unlock()
+	}
+
+	return moved, nil
+}
+
+// Transition atomically moves an order to another status and records the
+// change in its history
+func (r *MemoryOrderRepository) Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error) {
+	s := r.shardOf(id)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, exists := s.orders[id]
+	if !exists {
+		return nil, model.ErrOrderNotFound
+	}
+
+	order := current.Clone()
+	if err := order.Transition(to, actor, reason, at); err != nil {
+		return nil, err
+	}
+	s.orders[id] = order
+	s.touched[id] = time.Now()
+	return order.Clone(), nil
+}
+
+// SaveRefund records the refund of an order
+func (r *MemoryOrderRepository) SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error) {
+	s := r.shardOf(id)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current, exists := s.orders[id]
+	if !exists {
+		return nil, model.ErrOrderNotFound
+	}
+
+	order := current.Clone()
+	stored := *refund
+	order.Refund = &stored
+	s.orders[id] = order
+	s.touched[id] = time.Now()
+	return order.Clone(), nil
+}
+
+// PurgeExpired removes orders written before cutoff. Orders have no seed
+// data, so every expired order is dropped.
+func (r *MemoryOrderRepository) PurgeExpired(cutoff time.Time) int {
+	purged := 0
+	for _, s := range r.shards {
+		s.mutex.Lock()
+		for id, writtenAt := range s.touched {
+			if writtenAt.Before(cutoff) {
+				s.removeIDUnsafe(s.orders[id])
+				delete(s.orders, id)
+				delete(s.touched, id)
+				purged++
+			}
+		}
+		s.mutex.Unlock()
+	}
+
+	return purged
+}
+
+// Reset removes all orders
+func (r *MemoryOrderRepository) Reset() {
+	for _, s := range r.shards {
+		s.mutex.Lock()
+		s.orders = make(map[string]*model.Order)
+		s.ids = nil
+		s.touched = make(map[string]time.Time)
+		s.mutex.Unlock()
+	}
+}
+
+// shardOf returns the shard holding the order with the ID
+func (r *MemoryOrderRepository) shardOf(id string) *orderShard {
+	return r.shards[shard.Of(id)]
+}
+
+// collect returns copies of the orders matching keep, oldest first, reading
+// one shard at a time and merging their creation time indexes
+func (r *MemoryOrderRepository) collect(keep func(order *model.Order) bool) []*model.Order {
+	lists := make([][]*model.Order, 0, len(r.shards))
+	for _, s := range r.shards {
+		s.mutex.RLock()
+		list := make([]*model.Order, 0, len(s.ids))
+		for _, id := range s.ids {
+			if order := s.orders[id]; keep(order) {
+				list = append(list, order.Clone())
+			}
+		}
+		s.mutex.RUnlock()
+		lists = append(lists, list)
+	}
+
+	// Merge the lists in pairs so every order is compared O(log shards) times
+	for len(lists) > 1 {
+		merged := make([][]*model.Order, 0, (len(lists)+1)/2)
+		for i := 0; i < len(lists); i += 2 {
+			if i+1 == len(lists) {
+				merged = append(merged, lists[i])
+				break
+			}
+			merged = append(merged, mergeOrders(lists[i], lists[i+1]))
+		}
+		lists = merged
+	}
+	return lists[0]
+}
+
+// addIDUnsafe adds the ID of a stored order to the sorted IDs (without locking)
+func (s *orderShard) addIDUnsafe(order *model.Order) {
+	if i, found := s.searchIDUnsafe(order); !found {
+		s.ids = slices.Insert(s.ids, i, order.ID)
+	}
+}
+
+// removeIDUnsafe removes the ID of an order from the sorted IDs (without locking)
+func (s *orderShard) removeIDUnsafe(order *model.Order) {
+	if i, found := s.searchIDUnsafe(order); found {
+		s.ids = slices.Delete(s.ids, i, i+1)
+	}
+}
+
+// searchIDUnsafe returns the position of the ID of order in the sorted IDs
+// and whether it is listed there (without locking)
+func (s *orderShard) searchIDUnsafe(order *model.Order) (int, bool) {
+	return slices.BinarySearchFunc(s.ids, order, func(id string, target *model.Order) int {
+		return compareOrders(s.orders[id], target)
+	})
+}
+
+// mergeOrders merges two lists of orders sorted by creation time
+func mergeOrders(a, b []*model.Order) []*model.Order {
+	merged := make([]*model.Order, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if compareOrders(a[0], b[0]) <= 0 {
+			merged, a = append(merged, a[0]), a[1:]
+		} else {
+			merged, b = append(merged, b[0]), b[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// compareOrders orders orders by creation time, then ID
+func compareOrders(a, b *model.Order) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
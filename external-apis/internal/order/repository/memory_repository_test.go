@@ -0,0 +1,581 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOrder(customerID string) *model.Order {
+	return &model.Order{
+		CustomerID: customerID,
+		ProductIDs: []string{"product-789"},
+		Products: []model.OrderProduct{
+			{ID: "product-789", Name: "Laptop", Price: money.New(99900, "USD")},
+		},
+		Total: money.New(99900, "USD"),
+	}
+}
+
+func TestMemoryOrderRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemoryOrderRepository()
+
+	t.Run("Create order with generated ID", func(t *testing.T) {
+		// Act
+		order, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEmpty(t, order.ID)
+		assert.False(t, order.CreatedAt.IsZero())
+		assert.True(t, repo.ExistsByID(context.Background(), order.ID))
+	})
+
+	t.Run("Create order with duplicate ID", func(t *testing.T) {
+		// Arrange
+		order := newTestOrder("customer-456")
+		order.ID = "order-duplicate"
+		_, err := repo.Create(context.Background(), order)
+		require.NoError(t, err)
+
+		// Act
+		duplicate := newTestOrder("customer-456")
+		duplicate.ID = "order-duplicate"
+		result, err := repo.Create(context.Background(), duplicate)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, "order already exists", err.Error())
+	})
+}
+
+func TestMemoryOrderRepository_GetByID(t *testing.T) {
+	// Arrange
+	repo := NewMemoryOrderRepository()
+	created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+	require.NoError(t, err)
+
+	t.Run("Get existing order", func(t *testing.T) {
+		// Act
+		order, err := repo.GetByID(context.Background(), created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", order.CustomerID)
+	})
+
+	t.Run("Get non-existing order", func(t *testing.T) {
+		// Act
+		order, err := repo.GetByID(context.Background(), "non-existing")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, order)
+		assert.Equal(t, "order not found", err.Error())
+	})
+}
+
+func TestMemoryOrderRepository_GetAll(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := NewMemoryOrderRepository()
+	placedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for _, order := range []struct {
+		id       string
+		placedAt time.Time
+	}{
+		{id: "order-c", placedAt: placedAt},
+		{id: "order-a", placedAt: placedAt.Add(time.Hour)},
+		{id: "order-b", placedAt: placedAt},
+	} {
+		created := newTestOrder("customer-456")
+		created.ID = order.id
+		created.CreatedAt = order.placedAt
+		_, err := repo.Create(ctx, created)
+		require.NoError(t, err)
+	}
+
+	// ids returns the IDs of orders in the order they are listed
+	ids := func(orders []*model.Order) []string {
+		result := make([]string, 0, len(orders))
+		for _, order := range orders {
+			result = append(result, order.ID)
+		}
+		return result
+	}
+
+	t.Run("List the oldest orders first", func(t *testing.T) {
+		// Act
+		orders, err := repo.GetAll(ctx, "")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order-b", "order-c", "order-a"}, ids(orders))
+	})
+
+	t.Run("List the newest orders first", func(t *testing.T) {
+		// Act
+		orders, err := repo.GetAll(ctx, model.SortByCreatedAtDesc)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order-a", "order-c", "order-b"}, ids(orders))
+	})
+
+	t.Run("Keep the order through updates and deletes", func(t *testing.T) {
+		// Arrange
+		moved := newTestOrder("customer-001")
+		moved.CreatedAt = placedAt.Add(-time.Hour)
+		_, err := repo.Update(ctx, "order-a", moved)
+		require.NoError(t, err)
+		require.NoError(t, repo.Delete(ctx, "order-c"))
+
+		// Act
+		orders, err := repo.GetAll(ctx, model.SortByCreatedAt)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"order-a", "order-b"}, ids(orders))
+	})
+}
+
+func TestMemoryOrderRepository_GetByCustomerID(t *testing.T) {
+	// Arrange
+	repo := NewMemoryOrderRepository()
+	_, _ = repo.Create(context.Background(), newTestOrder("customer-456"))
+	_, _ = repo.Create(context.Background(), newTestOrder("customer-456"))
+	_, _ = repo.Create(context.Background(), newTestOrder("customer-001"))
+
+	// Act
+	orders, err := repo.GetByCustomerID(context.Background(), "customer-456")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, orders, 2)
+}
+
+func TestMemoryOrderRepository_GetDueForEnrichment(t *testing.T) {
+	// Arrange
+	repo := NewMemoryOrderRepository()
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	partial := func(id string, next *time.Time) *model.Order {
+		order := newTestOrder("customer-456")
+		order.ID = id
+		order.Enrichment = &model.Enrichment{Status: model.EnrichmentPartial, Missing: []string{model.EnrichCustomer}, NextAttemptAt: next}
+		return order
+	}
+	failed := partial("failed", &past)
+	failed.Enrichment.Status = model.EnrichmentFailed
+	for _, order := range []*model.Order{partial("due", &past), partial("unscheduled", nil), partial("later", &future), failed, newTestOrder("customer-456")} {
+		_, err := repo.Create(context.Background(), order)
+		require.NoError(t, err)
+	}
+
+	// Act
+	orders, err := repo.GetDueForEnrichment(context.Background(), now)
+
+	// Assert
+	require.NoError(t, err)
+	ids := make([]string, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID
+	}
+	assert.ElementsMatch(t, []string{"due", "unscheduled"}, ids)
+}
+
+func TestMemoryOrderRepository_UpdateAndDelete(t *testing.T) {
+	// Arrange
+	repo := NewMemoryOrderRepository()
+	created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+	require.NoError(t, err)
+
+	t.Run("Update existing order", func(t *testing.T) {
+		// Arrange
+		created.Total = money.New(100, "USD")
+
+		// Act
+		updated, err := repo.Update(context.Background(), created.ID, created)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, money.New(100, "USD"), updated.Total)
+	})
+
+	t.Run("Updates keep the snapshot", func(t *testing.T) {
+		// Arrange
+		snapshotted := created.Clone()
+		snapshotted.Snapshot = &model.OrderSnapshot{LineItems: []model.LineItem{{ProductID: "product-789", Name: "Laptop"}}}
+		_, err := repo.Update(context.Background(), created.ID, snapshotted)
+		require.NoError(t, err)
+		snapshotted.Snapshot = &model.OrderSnapshot{LineItems: []model.LineItem{{ProductID: "product-789", Name: "Renamed"}}}
+
+		// Act
+		updated, err := repo.Update(context.Background(), created.ID, snapshotted)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Laptop", updated.Snapshot.LineItems[0].Name)
+	})
+
+	t.Run("Update non-existing order", func(t *testing.T) {
+		// Act
+		_, err := repo.Update(context.Background(), "non-existing", newTestOrder("customer-456"))
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Delete existing order", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, repo.ExistsByID(context.Background(), created.ID))
+	})
+
+	t.Run("Delete non-existing order", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), created.ID)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, "order not found", err.Error())
+	})
+}
+
+func TestMemoryOrderRepository_Transition(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Move an order to another status", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+		require.NoError(t, err)
+
+		// Act
+		order, err := repo.Transition(context.Background(), created.ID, model.StatusCreated, "user:alice", "", at)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCreated, order.Status)
+		stored, err := repo.GetByID(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []model.StatusTransition{{To: model.StatusCreated, Actor: "user:alice", At: at}}, stored.History)
+	})
+
+	t.Run("Reject a transition outside the graph", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+		require.NoError(t, err)
+
+		// Act
+		_, err = repo.Transition(context.Background(), created.ID, model.StatusShipped, "user:alice", "", at)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+
+		// Act
+		_, err := repo.Transition(context.Background(), "non-existing", model.StatusCreated, "user:alice", "", at)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+
+	t.Run("Updates keep the status", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+		require.NoError(t, err)
+		_, err = repo.Transition(context.Background(), created.ID, model.StatusCreated, "user:alice", "", at)
+		require.NoError(t, err)
+
+		// Act
+		updated, err := repo.Update(context.Background(), created.ID, created)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCreated, updated.Status)
+		assert.Len(t, updated.History, 1)
+	})
+}
+
+func TestMemoryOrderRepository_SaveRefund(t *testing.T) {
+	t.Run("Record the refund of an order", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+		require.NoError(t, err)
+		refund := &model.Refund{ID: "refund-1", Amount: money.New(99900, "USD"), Status: model.RefundSucceeded}
+
+		// Act
+		order, err := repo.SaveRefund(context.Background(), created.ID, refund)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, refund, order.Refund)
+		refund.Status = model.RefundFailed
+		stored, err := repo.GetByID(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.RefundSucceeded, stored.Refund.Status, "the repository keeps its own copy")
+	})
+
+	t.Run("Updates keep the refund", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(context.Background(), newTestOrder("customer-456"))
+		require.NoError(t, err)
+		_, err = repo.SaveRefund(context.Background(), created.ID, &model.Refund{ID: "refund-1", Status: model.RefundPending})
+		require.NoError(t, err)
+
+		// Act
+		updated, err := repo.Update(context.Background(), created.ID, created)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, updated.Refund)
+		assert.Equal(t, "refund-1", updated.Refund.ID)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+
+		// Act
+		_, err := repo.SaveRefund(context.Background(), "non-existing", &model.Refund{ID: "refund-1"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+}
+
+func TestMemoryOrderRepository_ReassignCustomer(t *testing.T) {
+	// Arrange
+	repo := NewMemoryOrderRepository()
+	ctx := context.Background()
+	for _, customerID := range []string{"customer-001", "customer-001", "customer-456"} {
+		_, err := repo.Create(ctx, newTestOrder(customerID))
+		require.NoError(t, err)
+	}
+
+	// Act
+	moved, err := repo.ReassignCustomer(ctx, "customer-001", "customer-456")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	remaining, _ := repo.GetByCustomerID(ctx, "customer-001")
+	assert.Empty(t, remaining)
+	reassigned, _ := repo.GetByCustomerID(ctx, "customer-456")
+	assert.Len(t, reassigned, 3)
+}
+
+func TestMemoryOrderRepository_ConcurrentAccess(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := NewMemoryOrderRepository()
+	placedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	const orders = 200
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < orders; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			order := newTestOrder("customer-456")
+			order.ID = fmt.Sprintf("order-%03d", i)
+			// Spread creation times so the shards interleave when merged
+			order.CreatedAt = placedAt.Add(time.Duration((i*37)%orders) * time.Minute)
+			_, _ = repo.Create(ctx, order)
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = repo.GetAll(ctx, "")
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	listed, err := repo.GetAll(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, listed, orders)
+	assert.True(t, slices.IsSortedFunc(listed, compareOrders), "orders are listed oldest first")
+	byCustomer, err := repo.GetByCustomerID(ctx, "customer-456")
+	require.NoError(t, err)
+	assert.Equal(t, listed, byCustomer)
+}
+
+func TestMemoryOrderRepository_DefensiveCopies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Changes to returned orders are not stored", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(ctx, newTestOrder("customer-456"))
+		require.NoError(t, err)
+		order, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		listed, err := repo.GetByCustomerID(ctx, "customer-456")
+		require.NoError(t, err)
+
+		// Act
+		order.Products[0].Name = "Changed"
+		order.ProductIDs[0] = "product-001"
+		listed[0].Total = money.Money{}
+
+		// Assert
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Laptop", stored.Products[0].Name)
+		assert.Equal(t, []string{"product-789"}, stored.ProductIDs)
+		assert.Equal(t, money.New(99900, "USD"), stored.Total)
+	})
+
+	t.Run("Changes to written orders are not stored", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		order := newTestOrder("customer-456")
+
+		// Act
+		created, err := repo.Create(ctx, order)
+		require.NoError(t, err)
+		order.CustomerID = "customer-001"
+		created.Products[0].Name = "Changed"
+
+		// Assert
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", stored.CustomerID)
+		assert.Equal(t, "Laptop", stored.Products[0].Name)
+	})
+
+	t.Run("Callers change their copies while others read", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryOrderRepository()
+		created, err := repo.Create(ctx, newTestOrder("customer-456"))
+		require.NoError(t, err)
+		var wg sync.WaitGroup
+
+		// Act
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				order, err := repo.GetByID(ctx, created.ID)
+				if err == nil {
+					order.CustomerID = fmt.Sprintf("customer-%d", i)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = repo.GetByCustomerID(ctx, "customer-456")
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", stored.CustomerID)
+	})
+}
+
+// serializedOrderRepository runs every call to an order repository under one
+// lock, as the repository did before it was sharded, to compare the
+// throughput of the two designs
+type serializedOrderRepository struct {
+	OrderRepository
+	mutex sync.RWMutex
+}
+
+func (r *serializedOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.OrderRepository.GetByID(ctx, id)
+}
+
+func (r *serializedOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.OrderRepository.GetByCustomerID(ctx, customerID)
+}
+
+func (r *serializedOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.OrderRepository.Create(ctx, order)
+}
+
+func (r *serializedOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.OrderRepository.Update(ctx, id, order)
+}
+
+// BenchmarkMemoryOrderRepository_MixedWorkload places, updates and reads
+// orders from parallel goroutines. Compare the ns/op of the two
+// sub-benchmarks with -cpu 1,4,8 to see the sharded repository scale.
+func BenchmarkMemoryOrderRepository_MixedWorkload(b *testing.B) {
+	ctx := context.Background()
+	const existing = 1024
+
+	for _, bench := range []struct {
+		name string
+		wrap func(repo *MemoryOrderRepository) OrderRepository
+	}{
+		{name: "sharded", wrap: func(repo *MemoryOrderRepository) OrderRepository { return repo }},
+		{name: "single lock", wrap: func(repo *MemoryOrderRepository) OrderRepository {
+			return &serializedOrderRepository{OrderRepository: repo}
+		}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			repo := NewMemoryOrderRepository()
+			ids := make([]string, 0, existing)
+			for i := 0; i < existing; i++ {
+				order, err := repo.Create(ctx, newTestOrder(fmt.Sprintf("customer-%d", i%64)))
+				require.NoError(b, err)
+				ids = append(ids, order.ID)
+			}
+			orders := bench.wrap(repo)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					id := ids[i%existing]
+					switch percentile := i % 100; {
+					case percentile == 0:
+						_, _ = orders.GetByCustomerID(ctx, "customer-1")
+					case percentile <= 20:
+						_, _ = orders.Create(ctx, newTestOrder("customer-1"))
+					case percentile <= 30:
+						current, err := orders.GetByID(ctx, id)
+						if err == nil {
+							updated := *current
+							_, _ = orders.Update(ctx, id, &updated)
+						}
+					default:
+						_, _ = orders.GetByID(ctx, id)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,21 @@
+-- Orders keep their full representation in data, as JSON. The columns next
+-- to it are the ones listings and change data capture need. Orders have no
+-- updated_at of their own, so the row records when it was last written.
+CREATE TABLE orders (
+	tenant            TEXT NOT NULL,
+	id                TEXT NOT NULL,
+	customer_id       TEXT NOT NULL,
+	enrichment_status TEXT NOT NULL DEFAULT '',
+	data              TEXT NOT NULL,
+	created_at        TEXT NOT NULL,
+	updated_at        TEXT NOT NULL,
+	PRIMARY KEY (tenant, id)
+);
+
+CREATE INDEX orders_created_at ON orders (tenant, created_at, id);
+
+CREATE INDEX orders_customer_id ON orders (tenant, customer_id);
+
+CREATE INDEX orders_enrichment_status ON orders (tenant, enrichment_status) WHERE enrichment_status <> '';
+
+CREATE INDEX orders_updated_at ON orders (updated_at);
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/wal"
+)
+
+// PartitionedOrderRepository is an OrderRepository that lists the tenants
+// it holds orders of
+type PartitionedOrderRepository interface {
+	OrderRepository
+	tenant.Lister
+}
+
+// PersistentOrderRepository records every order written to the wrapped
+// repository in a write-ahead log so an in-memory repository survives
+// restarts. Writes are serialized so the log holds them in the order they
+// were applied.
+type PersistentOrderRepository struct {
+	inner PartitionedOrderRepository
+	log   *wal.Log
+	mutex sync.Mutex
+}
+
+// NewPersistentOrderRepository wraps inner so that its orders are logged to
+// log. Call Recover before serving requests to load what the log holds.
+func NewPersistentOrderRepository(inner PartitionedOrderRepository, log *wal.Log) *PersistentOrderRepository {
+	return &PersistentOrderRepository{
+		inner: inner,
+		log:   log,
+	}
+}
+
+// Recover creates the orders of every tenant held by the log in the wrapped
+// repository and returns the number of orders created. Orders are created
+// as they were logged, status, history and timestamps included.
+func (r *PersistentOrderRepository) Recover(ctx context.Context) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recovered := 0
+	for _, id := range r.log.Tenants() {
+		tenantCtx := tenant.WithTenant(ctx, id)
+		for _, state := range r.log.States(id) {
+			var order model.Order
+			if err := json.Unmarshal(state, &order); err != nil {
+				return recovered, err
+			}
+			if _, err := r.inner.Create(tenantCtx, &order); err != nil {
+				return recovered, err
+			}
+			recovered++
+		}
+	}
+	return recovered, nil
+}
+
+// GetByID retrieves an order by ID
+func (r *PersistentOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	return r.inner.GetByID(ctx, id)
+}
+
+// GetAll retrieves all orders in the requested sort order
+func (r *PersistentOrderRepository) GetAll(ctx context.Context, sort string) ([]*model.Order, error) {
+	return r.inner.GetAll(ctx, sort)
+}
+
+// GetByCustomerID retrieves all orders placed by a customer
+func (r *PersistentOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	return r.inner.GetByCustomerID(ctx, customerID)
+}
+
+// GetDueForEnrichment retrieves the partially enriched orders whose next
+// enrichment attempt is due at a time
+func (r *PersistentOrderRepository) GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error) {
+	return r.inner.GetDueForEnrichment(ctx, at)
+}
+
+// Create stores and logs a new order
+func (r *PersistentOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	return r.write(ctx, func() (*model.Order, error) {
+		return r.inner.Create(ctx, order)
+	})
+}
+
+// Update stores and logs an existing order
+func (r *PersistentOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	return r.write(ctx, func() (*model.Order, error) {
+		return r.inner.Update(ctx, id, order)
+	})
+}
+
+// Delete deletes an order by ID and logs its removal
+func (r *PersistentOrderRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := r.inner.Delete(ctx, id); err != nil {
+		return err
+	}
+	return r.log.Remove(tenant.FromContext(ctx), id)
+}
+
+// ExistsByID checks if an order exists by ID
+func (r *PersistentOrderRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.inner.ExistsByID(ctx, id)
+}
+
+// ReassignCustomer moves the orders of a merged customer to the survivor
+// and logs the orders of the survivor
+func (r *PersistentOrderRepository) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	moved, err := r.inner.ReassignCustomer(ctx, fromCustomerID, toCustomerID)
+	if err != nil || moved == 0 {
+		return moved, err
+	}
+
+	orders, err := r.inner.GetByCustomerID(ctx, toCustomerID)
+	if err != nil {
+		return moved, err
+	}
+	entries := make([]wal.Entry, len(orders))
+	for i, order := range orders {
+		if entries[i], err = entry(ctx, order); err != nil {
+			return moved, err
+		}
+	}
+	return moved, r.log.Append(entries...)
+}
+
+// Transition moves an order to another status and logs the order
+func (r *PersistentOrderRepository) Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error) {
+	return r.write(ctx, func() (*model.Order, error) {
+		return r.inner.Transition(ctx, id, to, actor, reason, at)
+	})
+}
+
+// SaveRefund stores the refund of an order and logs the order
+func (r *PersistentOrderRepository) SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error) {
+	return r.write(ctx, func() (*model.Order, error) {
+		return r.inner.SaveRefund(ctx, id, refund)
+	})
+}
+
+// Tenants returns the tenants of the wrapped repository
+func (r *PersistentOrderRepository) Tenants() []string {
+	return r.inner.Tenants()
+}
+
+// write serializes a write returning the written order and logs the order
+func (r *PersistentOrderRepository) write(ctx context.Context, fn func() (*model.Order, error)) (*model.Order, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	order, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	logged, err := entry(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.log.Append(logged); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// entry returns the log entry holding the state of an order of the tenant
+// of ctx
+func entry(ctx context.Context, order *model.Order) (wal.Entry, error) {
+	value, err := json.Marshal(order)
+	if err != nil {
+		return wal.Entry{}, err
+	}
+	return wal.Entry{Tenant: tenant.FromContext(ctx), ID: order.ID, Value: value}, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/wal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openPersistentOrderRepository opens the log in dir and recovers a fresh
+// tenant repository from it, as a restarted service would
+func openPersistentOrderRepository(t *testing.T, dir string) *PersistentOrderRepository {
+	t.Helper()
+	orders, err := wal.Open(filepath.Join(dir, "orders"), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { orders.Close() })
+
+	repo := NewPersistentOrderRepository(NewTenantOrderRepository(), orders)
+	_, err = repo.Recover(context.Background())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestPersistentOrderRepository_Recover(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	brandA := tenant.WithTenant(ctx, "brand-a")
+	dir := t.TempDir()
+	repo := openPersistentOrderRepository(t, dir)
+
+	transitioned, err := repo.Create(ctx, newTestOrder("customer-001"))
+	require.NoError(t, err)
+	transitioned, err = repo.Transition(ctx, transitioned.ID, model.StatusCreated, "user:alice", "", time.Now().UTC())
+	require.NoError(t, err)
+	deleted, err := repo.Create(ctx, newTestOrder("customer-001"))
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, deleted.ID))
+	other, err := repo.Create(brandA, newTestOrder("customer-456"))
+	require.NoError(t, err)
+	_, err = repo.ReassignCustomer(ctx, "customer-001", "customer-456")
+	require.NoError(t, err)
+
+	// Act
+	recovered := openPersistentOrderRepository(t, dir)
+
+	// Assert
+	t.Run("Orders of every tenant", func(t *testing.T) {
+		assert.Equal(t, []string{"brand-a", "default"}, recovered.Tenants())
+		assert.True(t, recovered.ExistsByID(brandA, other.ID))
+		assert.False(t, recovered.ExistsByID(ctx, other.ID))
+	})
+
+	t.Run("Orders keep their status and history", func(t *testing.T) {
+		order, err := recovered.GetByID(ctx, transitioned.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCreated, order.Status)
+		assert.Len(t, order.History, len(transitioned.History))
+		assert.True(t, transitioned.CreatedAt.Equal(order.CreatedAt))
+		assert.Equal(t, "customer-456", order.CustomerID)
+	})
+
+	t.Run("Deleted orders stay deleted", func(t *testing.T) {
+		assert.False(t, recovered.ExistsByID(ctx, deleted.ID))
+	})
+}
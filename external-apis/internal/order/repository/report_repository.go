@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"external-apis/internal/order/model"
+)
+
+// SalesReportRepository defines the interface for the materialized daily
+// sales report tables
+type SalesReportRepository interface {
+	Replace(ctx context.Context, from, to time.Time, rows []model.SalesReportRow, refreshedAt time.Time) error
+	Query(ctx context.Context, query model.SalesReportQuery) ([]model.SalesReportRow, error)
+	RefreshedAt(ctx context.Context) *time.Time
+}
+
+// MemorySalesReportRepository implements SalesReportRepository using
+// in-memory storage. Rows are kept by day, in the order they were stored.
+type MemorySalesReportRepository struct {
+	days        map[string][]model.SalesReportRow
+	touched     map[string]time.Time
+	refreshedAt *time.Time
+	mutex       sync.RWMutex
+}
+
+// NewMemorySalesReportRepository creates a new in-memory sales report
+// repository
+func NewMemorySalesReportRepository() *MemorySalesReportRepository {
+	return &MemorySalesReportRepository{
+		days:    make(map[string][]model.SalesReportRow),
+		touched: make(map[string]time.Time),
+	}
+}
+
+// Replace stores the rows of the days from and to, both included, in place
+// of the rows stored for them before, and records when they were aggregated
+func (r *MemorySalesReportRepository) Replace(ctx context.Context, from, to time.Time, rows []model.SalesReportRow, refreshedAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(model.ReportDateLayout)
+		delete(r.days, date)
+		delete(r.touched, date)
+	}
+	for _, row := range rows {
+		r.days[row.Date] = append(r.days[row.Date], row)
+		r.touched[row.Date] = time.Now()
+	}
+
+	refreshedAt = refreshedAt.UTC()
+	r.refreshedAt = &refreshedAt
+	return nil
+}
+
+// Query retrieves the rows of a dimension for the days of the query, oldest
+// day first
+func (r *MemorySalesReportRepository) Query(ctx context.Context, query model.SalesReportQuery) ([]model.SalesReportRow, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var rows []model.SalesReportRow
+	for day := query.From; !day.After(query.To); day = day.AddDate(0, 0, 1) {
+		for _, row := range r.days[day.Format(model.ReportDateLayout)] {
+			if row.GroupBy == query.GroupBy {
+				rows = append(rows, row)
+			}
+		}
+	}
+	return slices.Clip(rows), nil
+}
+
+// RefreshedAt returns when the tables were last aggregated, or nil if they
+// never were
+func (r *MemorySalesReportRepository) RefreshedAt(ctx context.Context) *time.Time {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.refreshedAt == nil {
+		return nil
+	}
+	refreshedAt := *r.refreshedAt
+	return &refreshedAt
+}
+
+// PurgeExpired removes the rows of days written before cutoff
+func (r *MemorySalesReportRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for date, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			purged += len(r.days[date])
+			delete(r.days, date)
+			delete(r.touched, date)
+		}
+	}
+	return purged
+}
+
+// Reset removes all rows
+func (r *MemorySalesReportRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.days = make(map[string][]model.SalesReportRow)
+	r.touched = make(map[string]time.Time)
+	r.refreshedAt = nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySalesReportRepository(t *testing.T) {
+	ctx := context.Background()
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2, jan3 := jan1.AddDate(0, 0, 1), jan1.AddDate(0, 0, 2)
+	row := func(date, groupBy, key string, revenue int64) model.SalesReportRow {
+		return model.SalesReportRow{Date: date, GroupBy: groupBy, Key: key, Orders: 1, Units: 1, Revenue: money.New(revenue, "USD"), Total: money.New(revenue, "USD")}
+	}
+
+	t.Run("Query the rows of a dimension by day", func(t *testing.T) {
+		// Arrange
+		repo := NewMemorySalesReportRepository()
+		refreshedAt := time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC)
+		err := repo.Replace(ctx, jan1, jan3, []model.SalesReportRow{
+			row("2024-01-01", model.GroupByProduct, "product-002", 50),
+			row("2024-01-01", model.GroupByProduct, "product-001", 20),
+			row("2024-01-01", model.GroupByCategory, "electronics", 70),
+			row("2024-01-03", model.GroupByProduct, "product-001", 20),
+		}, refreshedAt)
+		require.NoError(t, err)
+
+		// Act
+		rows, err := repo.Query(ctx, model.SalesReportQuery{From: jan1, To: jan2, GroupBy: model.GroupByProduct})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, "product-002", rows[0].Key, "rows keep the order they were stored in")
+		assert.Equal(t, "product-001", rows[1].Key)
+		assert.Equal(t, &refreshedAt, repo.RefreshedAt(ctx))
+	})
+
+	t.Run("Replace the rows of the refreshed days only", func(t *testing.T) {
+		// Arrange
+		repo := NewMemorySalesReportRepository()
+		require.NoError(t, repo.Replace(ctx, jan1, jan2, []model.SalesReportRow{
+			row("2024-01-01", model.GroupByProduct, "product-001", 20),
+			row("2024-01-02", model.GroupByProduct, "product-001", 20),
+		}, time.Now()))
+
+		// Act
+		err := repo.Replace(ctx, jan2, jan3, []model.SalesReportRow{
+			row("2024-01-03", model.GroupByProduct, "product-001", 40),
+		}, time.Now())
+
+		// Assert
+		require.NoError(t, err)
+		rows, err := repo.Query(ctx, model.SalesReportQuery{From: jan1, To: jan3, GroupBy: model.GroupByProduct})
+		require.NoError(t, err)
+		require.Len(t, rows, 2, "the day without sales left is emptied")
+		assert.Equal(t, "2024-01-01", rows[0].Date)
+		assert.Equal(t, "2024-01-03", rows[1].Date)
+	})
+
+	t.Run("Never refreshed", func(t *testing.T) {
+		// Arrange
+		repo := NewMemorySalesReportRepository()
+
+		// Act
+		rows, err := repo.Query(ctx, model.SalesReportQuery{From: jan1, To: jan3, GroupBy: model.GroupByProduct})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+		assert.Nil(t, repo.RefreshedAt(ctx))
+	})
+
+	t.Run("Purge expired rows", func(t *testing.T) {
+		// Arrange
+		repo := NewMemorySalesReportRepository()
+		require.NoError(t, repo.Replace(ctx, jan1, jan1, []model.SalesReportRow{
+			row("2024-01-01", model.GroupByProduct, "product-001", 20),
+			row("2024-01-01", model.GroupByCategory, "electronics", 20),
+		}, time.Now()))
+
+		// Act
+		purged := repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Assert
+		assert.Equal(t, 2, purged)
+		rows, err := repo.Query(ctx, model.SalesReportQuery{From: jan1, To: jan1, GroupBy: model.GroupByProduct})
+		require.NoError(t, err)
+		assert.Empty(t, rows)
+	})
+}
+
+func TestTenantSalesReportRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantSalesReportRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	brandB := tenant.WithTenant(context.Background(), "brand-b")
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := repo.Replace(brandA, day, day, []model.SalesReportRow{
+		{Date: "2024-01-01", GroupBy: model.GroupByProduct, Key: "product-001", Orders: 1},
+	}, time.Now())
+	require.NoError(t, err)
+
+	// Act
+	rows, err := repo.Query(brandB, model.SalesReportQuery{From: day, To: day, GroupBy: model.GroupByProduct})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, rows)
+	assert.Nil(t, repo.RefreshedAt(brandB))
+}
@@ -0,0 +1,346 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"slices"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/clock"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+)
+
+var log = logger.New("order/repository")
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations of SQLiteOrderRepository
+func Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFiles, "migrations")
+	return sub
+}
+
+// SQLiteOrderRepository implements OrderRepository on an SQLite database
+// migrated with Migrations, so orders outlive restarts without running a
+// database server. Rows are partitioned by the tenant of the request
+// context.
+type SQLiteOrderRepository struct {
+	db *sql.DB
+	// cluster routes GetByID, GetAll and GetByCustomerID to its read
+	// replicas when their context tolerates stale reads
+	cluster *database.Cluster
+	// clock stamps the updated_at column of the rows
+	clock *clock.Clock
+	// ids generates the IDs of new orders
+	ids idgen.Generator
+}
+
+// NewSQLiteOrderRepository creates an order repository on db
+func NewSQLiteOrderRepository(db *sql.DB) *SQLiteOrderRepository {
+	return NewReplicatedSQLiteOrderRepository(database.NewCluster(db, nil, 0))
+}
+
+// NewReplicatedSQLiteOrderRepository creates an order repository writing to
+// the primary of cluster and reading from its replicas
+func NewReplicatedSQLiteOrderRepository(cluster *database.Cluster) *SQLiteOrderRepository {
+	return &SQLiteOrderRepository{db: cluster.Primary(), cluster: cluster, clock: clock.New(), ids: idgen.Default()}
+}
+
+// SetIDs sets the generator of the IDs of new orders, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *SQLiteOrderRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves an order by ID
+func (r *SQLiteOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	var order *model.Order
+	err := r.cluster.Read(ctx, func(q database.Querier) error {
+		var err error
+		order, err = r.get(ctx, q, id)
+		return err
+	})
+	return order, err
+}
+
+// GetAll retrieves all orders in the requested sort order, oldest first by
+// default
+func (r *SQLiteOrderRepository) GetAll(ctx context.Context, sort string) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.cluster.Read(ctx, func(q database.Querier) error {
+		var err error
+		orders, err = r.listIn(ctx, q, `SELECT data FROM orders WHERE tenant = ? ORDER BY created_at, id`, tenant.FromContext(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sort == model.SortByCreatedAtDesc {
+		slices.Reverse(orders)
+	}
+	return orders, nil
+}
+
+// GetByCustomerID retrieves all orders placed by a customer, oldest first
+func (r *SQLiteOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := r.cluster.Read(ctx, func(q database.Querier) error {
+		var err error
+		orders, err = r.listIn(ctx, q, `SELECT data FROM orders WHERE tenant = ? AND customer_id = ? ORDER BY created_at, id`,
+			tenant.FromContext(ctx), customerID)
+		return err
+	})
+	return orders, err
+}
+
+// GetDueForEnrichment retrieves the partially enriched orders whose next
+// enrichment attempt is due at a time, oldest first
+func (r *SQLiteOrderRepository) GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error) {
+	orders, err := r.list(ctx, `SELECT data FROM orders WHERE tenant = ? AND enrichment_status = ? ORDER BY created_at, id`,
+		tenant.FromContext(ctx), model.EnrichmentPartial)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(orders, func(order *model.Order) bool {
+		return !order.Enrichment.Due(at)
+	}), nil
+}
+
+// Create creates a new order
+func (r *SQLiteOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	order = order.Clone()
+	if order.ID == "" {
+		order.ID = r.ids.New()
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now().UTC()
+	}
+
+	err := r.write(ctx, func(tx *sql.Tx) error {
+		if _, err := r.get(ctx, tx, order.ID); err == nil {
+			return model.ErrOrderExists
+		} else if !errors.Is(err, model.ErrOrderNotFound) {
+			return err
+		}
+
+		data, err := json.Marshal(order)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO orders (tenant, id, customer_id, enrichment_status, data, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			tenant.FromContext(ctx), order.ID, order.CustomerID, enrichmentStatus(order), data,
+			database.FormatTime(order.CreatedAt), database.FormatTime(r.clock.Now()))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order.Clone(), nil
+}
+
+// Update updates an existing order. The status, history and refund of the
+// order are kept; they only change through Transition and SaveRefund, so an
+// update cannot undo a concurrent cancellation. A snapshot, once stored, is
+// kept too.
+func (r *SQLiteOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	order = order.Clone()
+	return r.update(ctx, id, func(current *model.Order) (*model.Order, error) {
+		order.ID = id
+		order.Status = current.Status
+		order.History = current.History
+		order.Refund = current.Refund
+		if current.Snapshot != nil {
+			order.Snapshot = current.Snapshot
+		}
+		return order, nil
+	})
+}
+
+// Delete deletes an order by ID
+func (r *SQLiteOrderRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM orders WHERE tenant = ? AND id = ?`, tenant.FromContext(ctx), id)
+	if err != nil {
+		return err
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if deleted == 0 {
+		return model.ErrOrderNotFound
+	}
+	return nil
+}
+
+// ExistsByID checks if an order exists by ID
+func (r *SQLiteOrderRepository) ExistsByID(ctx context.Context, id string) bool {
+	_, err := r.GetByID(ctx, id)
+	return err == nil
+}
+
+// ReassignCustomer moves every order placed by one customer to another and
+// returns how many orders were moved, all at once
+func (r *SQLiteOrderRepository) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	moved := 0
+	err := r.write(ctx, func(tx *sql.Tx) error {
+		orders, err := r.listIn(ctx, tx, `SELECT data FROM orders WHERE tenant = ? AND customer_id = ?`, tenant.FromContext(ctx), fromCustomerID)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			order.CustomerID = toCustomerID
+			if err := r.store(ctx, tx, order); err != nil {
+				return err
+			}
+		}
+		moved = len(orders)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// Transition atomically moves an order to another status and records the
+// change in its history
+func (r *SQLiteOrderRepository) Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error) {
+	return r.update(ctx, id, func(order *model.Order) (*model.Order, error) {
+		if err := order.Transition(to, actor, reason, at); err != nil {
+			return nil, err
+		}
+		return order, nil
+	})
+}
+
+// SaveRefund records the refund of an order
+func (r *SQLiteOrderRepository) SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error) {
+	return r.update(ctx, id, func(order *model.Order) (*model.Order, error) {
+		stored := *refund
+		order.Refund = &stored
+		return order, nil
+	})
+}
+
+// Tenants returns the tenants that have placed orders
+func (r *SQLiteOrderRepository) Tenants() []string {
+	rows, err := r.db.Query(`SELECT DISTINCT tenant FROM orders ORDER BY tenant`)
+	if err != nil {
+		log.WithError(err).Error("Failed to list the tenants of the orders")
+		return nil
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.WithError(err).Error("Failed to list the tenants of the orders")
+			return tenants
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants
+}
+
+// update applies a change to the stored order with the ID in a
+// transaction. change receives a copy of the order and returns the order to
+// store in its place.
+func (r *SQLiteOrderRepository) update(ctx context.Context, id string, change func(order *model.Order) (*model.Order, error)) (*model.Order, error) {
+	var updated *model.Order
+	err := r.write(ctx, func(tx *sql.Tx) error {
+		current, err := r.get(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if updated, err = change(current); err != nil {
+			return err
+		}
+		return r.store(ctx, tx, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated.Clone(), nil
+}
+
+// write runs fn in a transaction
+func (r *SQLiteOrderRepository) write(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return database.Transaction(ctx, r.db, fn)
+}
+
+// store replaces a stored order
+func (r *SQLiteOrderRepository) store(ctx context.Context, q database.Querier, order *model.Order) error {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `UPDATE orders SET customer_id = ?, enrichment_status = ?, data = ?, created_at = ?, updated_at = ?
+		WHERE tenant = ? AND id = ?`,
+		order.CustomerID, enrichmentStatus(order), data, database.FormatTime(order.CreatedAt), database.FormatTime(r.clock.Now()),
+		tenant.FromContext(ctx), order.ID)
+	return err
+}
+
+// get retrieves an order through q
+func (r *SQLiteOrderRepository) get(ctx context.Context, q database.Querier, id string) (*model.Order, error) {
+	var data []byte
+	err := q.QueryRowContext(ctx, `SELECT data FROM orders WHERE tenant = ? AND id = ?`, tenant.FromContext(ctx), id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var order model.Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// list retrieves the orders a query selects
+func (r *SQLiteOrderRepository) list(ctx context.Context, query string, args ...any) ([]*model.Order, error) {
+	return r.listIn(ctx, r.db, query, args...)
+}
+
+// listIn retrieves the orders a query selects through q
+func (r *SQLiteOrderRepository) listIn(ctx context.Context, q database.Querier, query string, args ...any) ([]*model.Order, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*model.Order, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var order model.Order
+		if err := json.Unmarshal(data, &order); err != nil {
+			return nil, err
+		}
+		orders = append(orders, &order)
+	}
+	return orders, rows.Err()
+}
+
+// enrichmentStatus is the enrichment_status column of an order: the status
+// of its enrichment while it is not complete
+func enrichmentStatus(order *model.Order) string {
+	if order.Enrichment == nil {
+		return ""
+	}
+	return string(order.Enrichment.Status)
+}
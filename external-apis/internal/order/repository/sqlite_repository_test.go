@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/migrations"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteOrderRepository(t *testing.T, path string) *SQLiteOrderRepository {
+	t.Helper()
+	db, err := database.OpenSQLite(path, database.DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = migrations.Up(context.Background(), db, Migrations())
+	require.NoError(t, err)
+	return NewSQLiteOrderRepository(db)
+}
+
+func TestSQLiteOrderRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteOrderRepository(t, filepath.Join(t.TempDir(), "orders.db"))
+	var created *model.Order
+
+	t.Run("Create and get", func(t *testing.T) {
+		// Act
+		var err error
+		created, err = repo.Create(ctx, newTestOrder("customer-456"))
+		require.NoError(t, err)
+		retrieved, err := repo.GetByID(ctx, created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEmpty(t, created.ID)
+		assert.False(t, created.CreatedAt.IsZero())
+		assert.Equal(t, created, retrieved)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		duplicate := newTestOrder("customer-456")
+		duplicate.ID = created.ID
+		_, err := repo.Create(ctx, duplicate)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderExists)
+	})
+
+	t.Run("Updates keep the status and refund", func(t *testing.T) {
+		// Arrange
+		_, err := repo.Transition(ctx, created.ID, model.StatusCreated, "user:alice", "", time.Now().UTC())
+		require.NoError(t, err)
+		_, err = repo.SaveRefund(ctx, created.ID, &model.Refund{ID: "refund-1", Status: model.RefundPending})
+		require.NoError(t, err)
+		update := newTestOrder("customer-456")
+		update.Total = money.New(1000, "USD")
+
+		// Act
+		updated, err := repo.Update(ctx, created.ID, update)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, money.New(1000, "USD"), updated.Total)
+		assert.Equal(t, model.StatusCreated, updated.Status)
+		require.NotNil(t, updated.Refund)
+		assert.Equal(t, "refund-1", updated.Refund.ID)
+	})
+
+	t.Run("Reject a transition outside the graph", func(t *testing.T) {
+		// Act
+		_, err := repo.Transition(ctx, created.ID, model.StatusDelivered, "user:alice", "", time.Now().UTC())
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+	})
+
+	t.Run("Update non-existing order", func(t *testing.T) {
+		// Act
+		_, err := repo.Update(ctx, "non-existing", newTestOrder("customer-456"))
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// Act
+		err := repo.Delete(ctx, created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, repo.ExistsByID(ctx, created.ID))
+		assert.ErrorIs(t, repo.Delete(ctx, created.ID), model.ErrOrderNotFound)
+	})
+}
+
+func TestSQLiteOrderRepository_Queries(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteOrderRepository(t, filepath.Join(t.TempDir(), "orders.db"))
+	now := time.Now().UTC()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+	for i, order := range []struct {
+		id, customerID string
+		next           *time.Time
+	}{
+		{"due", "customer-001", &past},
+		{"later", "customer-001", &future},
+		{"enriched", "customer-456", nil},
+	} {
+		created := newTestOrder(order.customerID)
+		created.ID = order.id
+		created.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		if order.next != nil {
+			created.Enrichment = &model.Enrichment{Status: model.EnrichmentPartial, Missing: []string{model.EnrichCustomer}, NextAttemptAt: order.next}
+		}
+		_, err := repo.Create(ctx, created)
+		require.NoError(t, err)
+	}
+	ids := func(orders []*model.Order) []string {
+		ids := make([]string, len(orders))
+		for i, order := range orders {
+			ids[i] = order.ID
+		}
+		return ids
+	}
+
+	t.Run("List in creation order", func(t *testing.T) {
+		// Act
+		oldest, err := repo.GetAll(ctx, model.SortByCreatedAt)
+		require.NoError(t, err)
+		newest, err := repo.GetAll(ctx, model.SortByCreatedAtDesc)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"due", "later", "enriched"}, ids(oldest))
+		assert.Equal(t, []string{"enriched", "later", "due"}, ids(newest))
+	})
+
+	t.Run("Orders of a customer", func(t *testing.T) {
+		// Act
+		orders, err := repo.GetByCustomerID(ctx, "customer-001")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"due", "later"}, ids(orders))
+	})
+
+	t.Run("Orders due for enrichment", func(t *testing.T) {
+		// Act
+		orders, err := repo.GetDueForEnrichment(ctx, now)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"due"}, ids(orders))
+	})
+
+	t.Run("Reassign a customer", func(t *testing.T) {
+		// Act
+		moved, err := repo.ReassignCustomer(ctx, "customer-001", "customer-456")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, moved)
+		orders, err := repo.GetByCustomerID(ctx, "customer-456")
+		require.NoError(t, err)
+		assert.Len(t, orders, 3)
+		for _, order := range orders {
+			assert.Equal(t, "customer-456", order.CustomerID)
+		}
+	})
+}
+
+func TestSQLiteOrderRepository_Tenants(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "orders.db")
+	repo := newSQLiteOrderRepository(t, path)
+	order := newTestOrder("customer-456")
+	order.ID = "order-1"
+
+	// Act
+	_, errA := repo.Create(tenant.WithTenant(ctx, "brand-a"), order)
+	_, errB := repo.Create(tenant.WithTenant(ctx, "brand-b"), order)
+
+	// Assert
+	require.NoError(t, errA)
+	require.NoError(t, errB, "IDs are unique per tenant")
+	assert.False(t, repo.ExistsByID(ctx, "order-1"))
+	assert.Equal(t, []string{"brand-a", "brand-b"}, repo.Tenants())
+	reopened := newSQLiteOrderRepository(t, path)
+	assert.True(t, reopened.ExistsByID(tenant.WithTenant(ctx, "brand-a"), "order-1"), "orders outlive the connection")
+}
+
+func TestSQLiteOrderRepository_Replicas(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	stale := database.WithStaleReads(ctx)
+	primary := newSQLiteOrderRepository(t, filepath.Join(t.TempDir(), "orders.db"))
+	replica := newSQLiteOrderRepository(t, filepath.Join(t.TempDir(), "replica.db"))
+	created, err := primary.Create(ctx, newTestOrder("customer-456"))
+	require.NoError(t, err)
+
+	t.Run("Stale reads come from the replica", func(t *testing.T) {
+		// Arrange
+		repo := NewReplicatedSQLiteOrderRepository(database.NewCluster(primary.db, []*sql.DB{replica.db}, time.Minute))
+
+		// Act
+		_, err := repo.GetByID(stale, created.ID)
+		orders, allErr := repo.GetAll(stale, "")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderNotFound)
+		require.NoError(t, allErr)
+		assert.Empty(t, orders)
+	})
+
+	t.Run("Fall back to the primary when the replica is down", func(t *testing.T) {
+		// Arrange
+		down, err := database.OpenSQLiteReplica(filepath.Join(t.TempDir(), "missing.db"), database.DefaultOptions())
+		require.NoError(t, err)
+		defer down.Close()
+		repo := NewReplicatedSQLiteOrderRepository(database.NewCluster(primary.db, []*sql.DB{down}, time.Minute))
+
+		// Act
+		order, err := repo.GetByID(stale, created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, order.ID)
+	})
+}
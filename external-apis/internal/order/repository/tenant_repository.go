@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/tenant"
+)
+
+// TenantOrderRepository implements OrderRepository with a separate in-memory
+// repository per tenant, selected by the tenant of the request context
+type TenantOrderRepository struct {
+	partitions *tenant.Partitions[*MemoryOrderRepository]
+	// ids generates the IDs of new orders of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantOrderRepository creates a new tenant-partitioned order repository
+func NewTenantOrderRepository() *TenantOrderRepository {
+	r := &TenantOrderRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryOrderRepository {
+		repo := NewMemoryOrderRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new orders, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantOrderRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves an order of the tenant by ID
+func (r *TenantOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// GetAll retrieves all orders of the tenant in the requested sort order
+func (r *TenantOrderRepository) GetAll(ctx context.Context, sort string) ([]*model.Order, error) {
+	return r.partitions.View(ctx).GetAll(ctx, sort)
+}
+
+// GetByCustomerID retrieves all orders of the tenant placed by a customer
+func (r *TenantOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	return r.partitions.View(ctx).GetByCustomerID(ctx, customerID)
+}
+
+// GetDueForEnrichment retrieves the partially enriched orders of the tenant
+// whose next enrichment attempt is due
+func (r *TenantOrderRepository) GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error) {
+	return r.partitions.View(ctx).GetDueForEnrichment(ctx, at)
+}
+
+// Create creates an order for the tenant
+func (r *TenantOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	return r.partitions.For(ctx).Create(ctx, order)
+}
+
+// Update updates an order of the tenant
+func (r *TenantOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	return r.partitions.For(ctx).Update(ctx, id, order)
+}
+
+// Delete deletes an order of the tenant
+func (r *TenantOrderRepository) Delete(ctx context.Context, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, id)
+}
+
+// ExistsByID checks if an order exists for the tenant
+func (r *TenantOrderRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.partitions.View(ctx).ExistsByID(ctx, id)
+}
+
+// ReassignCustomer moves the orders of the tenant from one customer to another
+func (r *TenantOrderRepository) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	return r.partitions.For(ctx).ReassignCustomer(ctx, fromCustomerID, toCustomerID)
+}
+
+// Transition moves an order of the tenant to another status
+func (r *TenantOrderRepository) Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error) {
+	return r.partitions.For(ctx).Transition(ctx, id, to, actor, reason, at)
+}
+
+// SaveRefund records the refund of an order of the tenant
+func (r *TenantOrderRepository) SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error) {
+	return r.partitions.For(ctx).SaveRefund(ctx, id, refund)
+}
+
+// Tenants returns the tenants that have placed orders
+func (r *TenantOrderRepository) Tenants() []string {
+	return r.partitions.Tenants()
+}
+
+// PurgeExpired removes the expired orders of every tenant
+func (r *TenantOrderRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the orders of every tenant
+func (r *TenantOrderRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantInvoiceRepository implements InvoiceRepository with a separate
+// in-memory repository per tenant, selected by the tenant of the request
+// context
+type TenantInvoiceRepository struct {
+	partitions *tenant.Partitions[*MemoryInvoiceRepository]
+	// ids generates the IDs of new documents of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantInvoiceRepository creates a new tenant-partitioned invoice
+// document repository
+func NewTenantInvoiceRepository() *TenantInvoiceRepository {
+	r := &TenantInvoiceRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryInvoiceRepository {
+		repo := NewMemoryInvoiceRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new documents, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantInvoiceRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves a document of an order of the tenant by ID
+func (r *TenantInvoiceRepository) GetByID(ctx context.Context, orderID, id string) (*model.InvoiceDocument, error) {
+	return r.partitions.View(ctx).GetByID(ctx, orderID, id)
+}
+
+// Create stores a new pending document for the tenant
+func (r *TenantInvoiceRepository) Create(ctx context.Context, document *model.InvoiceDocument) (*model.InvoiceDocument, error) {
+	return r.partitions.For(ctx).Create(ctx, document)
+}
+
+// Update replaces a stored document of the tenant
+func (r *TenantInvoiceRepository) Update(ctx context.Context, document *model.InvoiceDocument) (*model.InvoiceDocument, error) {
+	return r.partitions.For(ctx).Update(ctx, document)
+}
+
+// PurgeExpired removes the expired documents of every tenant
+func (r *TenantInvoiceRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the documents of every tenant
+func (r *TenantInvoiceRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantSalesReportRepository implements SalesReportRepository with separate
+// in-memory tables per tenant, selected by the tenant of the request context
+type TenantSalesReportRepository struct {
+	partitions *tenant.Partitions[*MemorySalesReportRepository]
+}
+
+// NewTenantSalesReportRepository creates a new tenant-partitioned sales
+// report repository
+func NewTenantSalesReportRepository() *TenantSalesReportRepository {
+	return &TenantSalesReportRepository{
+		partitions: tenant.NewPartitions(func(string) *MemorySalesReportRepository {
+			return NewMemorySalesReportRepository()
+		}),
+	}
+}
+
+// Replace stores the rows of some days of the tenant
+func (r *TenantSalesReportRepository) Replace(ctx context.Context, from, to time.Time, rows []model.SalesReportRow, refreshedAt time.Time) error {
+	return r.partitions.For(ctx).Replace(ctx, from, to, rows, refreshedAt)
+}
+
+// Query retrieves the rows of a dimension for some days of the tenant
+func (r *TenantSalesReportRepository) Query(ctx context.Context, query model.SalesReportQuery) ([]model.SalesReportRow, error) {
+	return r.partitions.View(ctx).Query(ctx, query)
+}
+
+// RefreshedAt returns when the tables of the tenant were last aggregated
+func (r *TenantSalesReportRepository) RefreshedAt(ctx context.Context) *time.Time {
+	return r.partitions.View(ctx).RefreshedAt(ctx)
+}
+
+// PurgeExpired removes the expired rows of every tenant
+func (r *TenantSalesReportRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the rows of every tenant
+func (r *TenantSalesReportRepository) Reset() {
+	r.partitions.Reset()
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantOrderRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantOrderRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	brandB := tenant.WithTenant(context.Background(), "brand-b")
+	created, err := repo.Create(brandA, newTestOrder("customer-456"))
+	require.NoError(t, err)
+
+	// Act
+	_, errB := repo.GetByID(brandB, created.ID)
+	byCustomer, err := repo.GetByCustomerID(brandB, "customer-456")
+	require.NoError(t, err)
+	deleteErr := repo.Delete(brandB, created.ID)
+
+	// Assert
+	assert.ErrorIs(t, errB, model.ErrOrderNotFound)
+	assert.Empty(t, byCustomer)
+	assert.ErrorIs(t, deleteErr, model.ErrOrderNotFound)
+	assert.True(t, repo.ExistsByID(brandA, created.ID))
+}
+
+func TestTenantOrderRepository_SetIDs(t *testing.T) {
+	// Arrange
+	repo := NewTenantOrderRepository()
+	ids := idgen.Prefixed("ord_", idgen.UUIDv7())
+	repo.SetIDs(ids)
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+
+	// Act
+	first, err := repo.Create(brandA, newTestOrder("customer-456"))
+	require.NoError(t, err)
+	second, err := repo.Create(brandA, newTestOrder("customer-456"))
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, ids.Valid(first.ID), first.ID)
+	assert.Less(t, first.ID, second.ID)
+}
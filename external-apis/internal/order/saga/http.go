@@ -0,0 +1,75 @@
+package saga
+
+import (
+	"errors"
+
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the admin endpoints for finding sagas that got stuck
+// or could not be compensated, across tenants
+type AdminHandler struct {
+	store Store
+}
+
+// NewAdminHandler creates a new saga admin handler
+func NewAdminHandler(store Store) *AdminHandler {
+	return &AdminHandler{store: store}
+}
+
+// RegisterRoutes registers the saga admin routes
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	sagas := router.Group("/sagas")
+	{
+		sagas.GET("", h.ListSagas)
+		sagas.GET("/:id", h.GetSaga)
+	}
+}
+
+// ListSagas returns a page of sagas, most recently started first, optionally
+// filtered by tenant and status
+func (h *AdminHandler) ListSagas(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	sagas, err := h.store.List(Filter{
+		Tenant: c.Query("tenant"),
+		Status: Status(c.Query("status")),
+	})
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to list sagas")
+		response.InternalServerError(c, "Failed to list sagas")
+		return
+	}
+
+	start, end := page.Bounds(len(sagas))
+	response.Paged(c, sagas[start:end], page.Meta(len(sagas)))
+}
+
+// GetSaga returns a saga of the tenant named by the tenant query parameter,
+// the default tenant when it is missing
+func (h *AdminHandler) GetSaga(c *gin.Context) {
+	owner := c.Query("tenant")
+	if owner == "" {
+		owner = tenant.Default
+	}
+
+	saga, err := h.store.Get(owner, c.Param("id"))
+	if errors.Is(err, ErrSagaNotFound) {
+		response.NotFound(c, "Saga not found")
+		return
+	}
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("saga_id", c.Param("id")).Error("Failed to get saga")
+		response.InternalServerError(c, "Failed to get saga")
+		return
+	}
+
+	response.OK(c, saga)
+}
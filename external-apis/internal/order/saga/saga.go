@@ -0,0 +1,194 @@
+// Package saga runs multi-step operations that span services as sagas: the
+// steps run in order and, when one fails, the steps that completed are
+// compensated in reverse. Every saga's progress is recorded in a Store, so
+// operations that got stuck halfway can be inspected.
+package saga
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+)
+
+var log = logger.New("order/saga")
+
+// ErrSagaNotFound is returned when no saga has the given ID
+var ErrSagaNotFound = errors.New("saga not found")
+
+// Status is the state of a saga
+type Status string
+
+// Saga statuses
+const (
+	// StatusRunning sagas are executing their steps
+	StatusRunning Status = "RUNNING"
+	// StatusCompleted sagas ran every step
+	StatusCompleted Status = "COMPLETED"
+	// StatusCompensating sagas had a step fail and are undoing the others
+	StatusCompensating Status = "COMPENSATING"
+	// StatusCompensated sagas failed and undid every completed step
+	StatusCompensated Status = "COMPENSATED"
+	// StatusFailed sagas failed and could not undo a completed step; they
+	// need manual attention
+	StatusFailed Status = "FAILED"
+)
+
+// Finished checks if the saga has stopped changing
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusCompensated || s == StatusFailed
+}
+
+// StepStatus is the state of a saga step
+type StepStatus string
+
+// Step statuses
+const (
+	StepPending            StepStatus = "PENDING"
+	StepRunning            StepStatus = "RUNNING"
+	StepCompleted          StepStatus = "COMPLETED"
+	StepFailed             StepStatus = "FAILED"
+	StepCompensated        StepStatus = "COMPENSATED"
+	StepCompensationFailed StepStatus = "COMPENSATION_FAILED"
+)
+
+// Step is a step of a saga. Compensate undoes a completed Action; steps
+// without it have nothing to undo.
+type Step struct {
+	Name       string
+	Action     func(ctx context.Context) error
+	Compensate func(ctx context.Context) error
+}
+
+// StepState records the progress of a saga step
+type StepState struct {
+	Name      string     `json:"name"`
+	Status    StepStatus `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt,omitzero"`
+}
+
+// Saga records the progress of a saga
+type Saga struct {
+	ID     string      `json:"id"`
+	Name   string      `json:"name"`
+	Tenant string      `json:"tenant"`
+	Status Status      `json:"status"`
+	Steps  []StepState `json:"steps"`
+	// Error is the error of the step that failed the saga
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Coordinator runs sagas, recording their progress in a Store
+type Coordinator struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewCoordinator creates a saga coordinator recording progress in store
+func NewCoordinator(store Store) *Coordinator {
+	return &Coordinator{store: store, now: time.Now}
+}
+
+// Store returns the store the coordinator records progress in
+func (c *Coordinator) Store() Store {
+	return c.store
+}
+
+// Run runs the steps of the saga with the given ID and name, returning the
+// error of the step that failed. Compensation runs even when ctx has been
+// cancelled, since the completed steps must be undone either way.
+func (c *Coordinator) Run(ctx context.Context, id, name string, steps []Step) error {
+	now := c.now().UTC()
+	saga := &Saga{
+		ID:        id,
+		Name:      name,
+		Tenant:    tenant.FromContext(ctx),
+		Status:    StatusRunning,
+		Steps:     make([]StepState, len(steps)),
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	for i, step := range steps {
+		saga.Steps[i] = StepState{Name: step.Name, Status: StepPending}
+	}
+	c.save(ctx, saga)
+
+	for i, step := range steps {
+		c.setStep(ctx, saga, i, StepRunning, nil)
+
+		if err := step.Action(ctx); err != nil {
+			c.setStep(ctx, saga, i, StepFailed, err)
+			c.compensate(context.WithoutCancel(ctx), saga, steps[:i], err)
+			return err
+		}
+
+		c.setStep(ctx, saga, i, StepCompleted, nil)
+	}
+
+	saga.Status = StatusCompleted
+	c.save(ctx, saga)
+	return nil
+}
+
+// compensate undoes the completed steps in reverse order
+func (c *Coordinator) compensate(ctx context.Context, saga *Saga, completed []Step, cause error) {
+	saga.Status = StatusCompensating
+	saga.Error = cause.Error()
+	c.save(ctx, saga)
+
+	fields := logger.Fields{"saga_id": saga.ID, "saga": saga.Name}
+	status := StatusCompensated
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		if step.Compensate == nil {
+			continue
+		}
+
+		if err := step.Compensate(ctx); err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(fields).WithField("step", step.Name).Error("Failed to compensate saga step")
+			c.setStep(ctx, saga, i, StepCompensationFailed, err)
+			status = StatusFailed
+			continue
+		}
+
+		c.setStep(ctx, saga, i, StepCompensated, nil)
+	}
+
+	saga.Status = status
+	c.save(ctx, saga)
+
+	if status == StatusFailed {
+		log.Ctx(ctx).WithFields(fields).WithField("error", saga.Error).Error("Saga failed and could not be fully compensated")
+		return
+	}
+	log.Ctx(ctx).WithFields(fields).WithField("error", saga.Error).Warn("Saga compensated")
+}
+
+// setStep records the status of a step
+func (c *Coordinator) setStep(ctx context.Context, saga *Saga, index int, status StepStatus, err error) {
+	step := &saga.Steps[index]
+	step.Status = status
+	step.UpdatedAt = c.now().UTC()
+	if err != nil {
+		step.Error = err.Error()
+	}
+	c.save(ctx, saga)
+}
+
+// save records the saga. The state store only serves inspection, so a store
+// error is logged instead of failing the saga.
+func (c *Coordinator) save(ctx context.Context, saga *Saga) {
+	saga.UpdatedAt = c.now().UTC()
+	if err := c.store.Save(saga); err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"saga_id": saga.ID,
+			"saga":    saga.Name,
+			"status":  saga.Status,
+		}).Error("Failed to record saga state")
+	}
+}
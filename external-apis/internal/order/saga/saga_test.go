@@ -0,0 +1,207 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingStep returns a step that appends its name to calls when it runs
+// and name+" undone" when it is compensated
+func recordingStep(name string, calls *[]string, err error) Step {
+	return Step{
+		Name: name,
+		Action: func(context.Context) error {
+			*calls = append(*calls, name)
+			return err
+		},
+		Compensate: func(context.Context) error {
+			*calls = append(*calls, name+" undone")
+			return nil
+		},
+	}
+}
+
+func TestCoordinator_Run(t *testing.T) {
+	ctx := tenant.WithTenant(context.Background(), "acme")
+
+	t.Run("Run every step", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore(0)
+		coordinator := NewCoordinator(store)
+		var calls []string
+
+		// Act
+		err := coordinator.Run(ctx, "order-1", "create-order", []Step{
+			recordingStep("first", &calls, nil),
+			recordingStep("second", &calls, nil),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first", "second"}, calls)
+
+		saga, err := store.Get("acme", "order-1")
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompleted, saga.Status)
+		assert.Equal(t, "create-order", saga.Name)
+		assert.Equal(t, StepCompleted, saga.Steps[0].Status)
+		assert.Equal(t, StepCompleted, saga.Steps[1].Status)
+	})
+
+	t.Run("Compensate completed steps in reverse", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore(0)
+		coordinator := NewCoordinator(store)
+		failure := errors.New("customer not found")
+		var calls []string
+
+		// Act
+		err := coordinator.Run(ctx, "order-1", "create-order", []Step{
+			recordingStep("first", &calls, nil),
+			{Name: "second", Action: func(context.Context) error {
+				calls = append(calls, "second")
+				return nil
+			}},
+			recordingStep("third", &calls, failure),
+			recordingStep("fourth", &calls, nil),
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, []string{"first", "second", "third", "first undone"}, calls)
+
+		saga, err := store.Get("acme", "order-1")
+		require.NoError(t, err)
+		assert.Equal(t, StatusCompensated, saga.Status)
+		assert.Equal(t, "customer not found", saga.Error)
+		assert.Equal(t, []StepStatus{StepCompensated, StepCompleted, StepFailed, StepPending}, stepStatuses(saga))
+		assert.Equal(t, "customer not found", saga.Steps[2].Error)
+	})
+
+	t.Run("Record compensation failures", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore(0)
+		coordinator := NewCoordinator(store)
+
+		// Act
+		err := coordinator.Run(ctx, "order-1", "create-order", []Step{
+			{
+				Name:       "reserve",
+				Action:     func(context.Context) error { return nil },
+				Compensate: func(context.Context) error { return errors.New("release failed") },
+			},
+			{Name: "persist", Action: func(context.Context) error { return errors.New("storage down") }},
+		})
+
+		// Assert
+		assert.EqualError(t, err, "storage down")
+
+		saga, err := store.Get("acme", "order-1")
+		require.NoError(t, err)
+		assert.Equal(t, StatusFailed, saga.Status)
+		assert.Equal(t, StepCompensationFailed, saga.Steps[0].Status)
+		assert.Equal(t, "release failed", saga.Steps[0].Error)
+	})
+
+	t.Run("Compensate after the caller gave up", func(t *testing.T) {
+		// Arrange
+		coordinator := NewCoordinator(NewMemoryStore(0))
+		cancelled, cancel := context.WithCancel(ctx)
+		var compensationErr error
+
+		// Act
+		_ = coordinator.Run(cancelled, "order-1", "create-order", []Step{
+			{
+				Name:   "reserve",
+				Action: func(context.Context) error { return nil },
+				Compensate: func(ctx context.Context) error {
+					compensationErr = ctx.Err()
+					return nil
+				},
+			},
+			{Name: "persist", Action: func(context.Context) error {
+				cancel()
+				return context.Canceled
+			}},
+		})
+
+		// Assert
+		assert.NoError(t, compensationErr)
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	newSaga := func(id string, status Status, started time.Time) *Saga {
+		return &Saga{ID: id, Tenant: "acme", Status: status, StartedAt: started, UpdatedAt: started}
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Scope sagas to their tenant", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore(0)
+		require.NoError(t, store.Save(newSaga("order-1", StatusRunning, start)))
+
+		// Act
+		_, err := store.Get("other", "order-1")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrSagaNotFound)
+	})
+
+	t.Run("List newest first by filter", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore(0)
+		require.NoError(t, store.Save(newSaga("order-1", StatusRunning, start)))
+		require.NoError(t, store.Save(newSaga("order-2", StatusCompleted, start.Add(time.Minute))))
+		require.NoError(t, store.Save(newSaga("order-3", StatusRunning, start.Add(2*time.Minute))))
+
+		// Act
+		all, err := store.List(Filter{})
+		require.NoError(t, err)
+		running, err := store.List(Filter{Status: StatusRunning})
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"order-3", "order-2", "order-1"}, sagaIDs(all))
+		assert.Equal(t, []string{"order-3", "order-1"}, sagaIDs(running))
+	})
+
+	t.Run("Forget the oldest finished sagas over the limit", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore(2)
+		require.NoError(t, store.Save(newSaga("stuck", StatusRunning, start)))
+		require.NoError(t, store.Save(newSaga("done-1", StatusCompleted, start.Add(time.Minute))))
+
+		// Act
+		require.NoError(t, store.Save(newSaga("done-2", StatusCompensated, start.Add(2*time.Minute))))
+
+		// Assert
+		sagas, err := store.List(Filter{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"done-2", "stuck"}, sagaIDs(sagas))
+	})
+}
+
+// stepStatuses returns the status of every step of a saga
+func stepStatuses(saga *Saga) []StepStatus {
+	statuses := make([]StepStatus, len(saga.Steps))
+	for i, step := range saga.Steps {
+		statuses[i] = step.Status
+	}
+	return statuses
+}
+
+// sagaIDs returns the IDs of the sagas
+func sagaIDs(sagas []*Saga) []string {
+	ids := make([]string, len(sagas))
+	for i, saga := range sagas {
+		ids[i] = saga.ID
+	}
+	return ids
+}
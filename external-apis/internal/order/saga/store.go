@@ -0,0 +1,122 @@
+package saga
+
+import (
+	"sort"
+	"sync"
+)
+
+// Filter selects sagas; empty fields match everything
+type Filter struct {
+	Tenant string
+	Status Status
+}
+
+// Matches checks if the saga passes the filter
+func (f Filter) Matches(saga *Saga) bool {
+	return (f.Tenant == "" || saga.Tenant == f.Tenant) &&
+		(f.Status == "" || saga.Status == f.Status)
+}
+
+// Store records the progress of sagas
+type Store interface {
+	Save(saga *Saga) error
+	Get(tenant, id string) (*Saga, error)
+	List(filter Filter) ([]*Saga, error)
+}
+
+// sagaKey identifies a saga. IDs are only unique within a tenant.
+type sagaKey struct {
+	tenant string
+	id     string
+}
+
+// MemoryStore keeps sagas in memory. Once it holds more than its limit, the
+// sagas that finished first are forgotten; running ones are always kept.
+type MemoryStore struct {
+	sagas map[sagaKey]*Saga
+	limit int
+	mutex sync.RWMutex
+}
+
+// NewMemoryStore creates an in-memory saga store keeping up to limit sagas
+func NewMemoryStore(limit int) *MemoryStore {
+	return &MemoryStore{
+		sagas: make(map[sagaKey]*Saga),
+		limit: limit,
+	}
+}
+
+// Save records the saga
+func (s *MemoryStore) Save(saga *Saga) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.sagas[sagaKey{saga.Tenant, saga.ID}] = copySaga(saga)
+	s.evict()
+	return nil
+}
+
+// Get returns the saga of the tenant with the given ID
+func (s *MemoryStore) Get(tenant, id string) (*Saga, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	saga, exists := s.sagas[sagaKey{tenant, id}]
+	if !exists {
+		return nil, ErrSagaNotFound
+	}
+	return copySaga(saga), nil
+}
+
+// List returns the sagas matching the filter, most recently started first
+func (s *MemoryStore) List(filter Filter) ([]*Saga, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sagas := make([]*Saga, 0, len(s.sagas))
+	for _, saga := range s.sagas {
+		if filter.Matches(saga) {
+			sagas = append(sagas, copySaga(saga))
+		}
+	}
+
+	sort.Slice(sagas, func(i, j int) bool {
+		if !sagas[i].StartedAt.Equal(sagas[j].StartedAt) {
+			return sagas[i].StartedAt.After(sagas[j].StartedAt)
+		}
+		return sagas[i].ID < sagas[j].ID
+	})
+	return sagas, nil
+}
+
+// evict forgets the sagas that finished first while the store is over its
+// limit. The caller must hold the mutex.
+func (s *MemoryStore) evict() {
+	if s.limit <= 0 || len(s.sagas) <= s.limit {
+		return
+	}
+
+	finished := make([]sagaKey, 0, len(s.sagas))
+	for key, saga := range s.sagas {
+		if saga.Status.Finished() {
+			finished = append(finished, key)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return s.sagas[finished[i]].UpdatedAt.Before(s.sagas[finished[j]].UpdatedAt)
+	})
+
+	for _, key := range finished {
+		if len(s.sagas) <= s.limit {
+			return
+		}
+		delete(s.sagas, key)
+	}
+}
+
+// copySaga copies a saga so callers cannot change stored state
+func copySaga(saga *Saga) *Saga {
+	copied := *saga
+	copied.Steps = append([]StepState(nil), saga.Steps...)
+	return &copied
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/payment"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+)
+
+// CancelOrder cancels an order that has not been shipped yet, recording the
+// actor of ctx and the reason in its history. The cancellation is undone
+// downstream: the stock reserved for the order is released, the loyalty
+// points collected with it are reversed and its total is refunded. These
+// compensations run once the order is cancelled; one that fails is logged
+// and does not undo the cancellation, and a refund that fails is recorded
+// as FAILED on the order.
+func (s *orderService) CancelOrder(ctx context.Context, id string, req model.TransitionRequest) (*model.OrderResponse, error) {
+	log.Ctx(ctx).WithField("order_id", id).Debug("Cancelling order")
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", id).Error("Order not found for cancellation")
+		return nil, err
+	}
+
+	switch order.Status {
+	case model.StatusShipped, model.StatusDelivered:
+		return nil, model.ErrOrderShipped
+	case model.StatusCancelled:
+		return nil, model.ErrOrderCancelled
+	}
+
+	// The transition is checked again atomically, in case the order was
+	// shipped in the meantime
+	order, err = s.repo.Transition(ctx, id, model.StatusCancelled, auth.Actor(ctx), req.Reason, time.Now().UTC())
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", id).Error("Failed to cancel order")
+		return nil, err
+	}
+
+	// The order is cancelled; the compensations must finish even if the
+	// caller goes away
+	ctx = context.WithoutCancel(ctx)
+	s.releaseOrderStock(ctx, order)
+	s.reversePoints(ctx, order)
+
+	if refund := s.refundOrder(ctx, order, req.Reason); refund != nil {
+		refunded, err := s.repo.SaveRefund(ctx, id, refund)
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+				"order_id":  id,
+				"refund_id": refund.ID,
+			}).Error("Failed to save order refund")
+			return nil, err
+		}
+		order = refunded
+	}
+
+	log.Ctx(ctx).WithField("order_id", id).Info("Successfully cancelled order")
+	response := order.ToResponse()
+	return &response, nil
+}
+
+// releaseOrderStock gives the stock reserved for a cancelled order back
+func (s *orderService) releaseOrderStock(ctx context.Context, order *model.Order) {
+	if s.reservations == nil || len(order.ReservationIDs) == 0 {
+		return
+	}
+
+	if err := s.releaseStock(ctx, order.ID, order.ReservationIDs); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", order.ID).Error("Failed to release stock of cancelled order")
+	}
+}
+
+// reversePoints takes back the loyalty points collected with a cancelled
+// order
+func (s *orderService) reversePoints(ctx context.Context, order *model.Order) {
+	if s.loyalty == nil {
+		return
+	}
+
+	if err := s.loyalty.ReversePoints(ctx, order.CustomerID, order.ID); err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"order_id":    order.ID,
+			"customer_id": order.CustomerID,
+		}).Error("Failed to reverse loyalty points of cancelled order")
+	}
+}
+
+// refundOrder pays back the total of a cancelled order and returns the
+// refund to record. Orders that charged nothing, e.g. because their products
+// were never enriched, need no refund and get none.
+func (s *orderService) refundOrder(ctx context.Context, order *model.Order, reason string) *model.Refund {
+	if !order.Total.IsPositive() {
+		return nil
+	}
+
+	refund := &model.Refund{
+		ID:        s.refundIDs.New(),
+		Amount:    order.Total,
+		Reason:    reason,
+		Status:    model.RefundPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	if s.refunds == nil {
+		return refund
+	}
+
+	result, err := s.refunds.Refund(ctx, payment.Request{
+		RefundID:   refund.ID,
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Amount:     order.Total,
+		Reason:     reason,
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"order_id":  order.ID,
+			"refund_id": refund.ID,
+			"rejected":  errors.Is(err, payment.ErrRejected),
+		}).Error("Failed to refund cancelled order")
+		refund.Status = model.RefundFailed
+		refund.Error = err.Error()
+		return refund
+	}
+
+	refund.Status = model.RefundSucceeded
+	refund.Reference = result.Reference
+	return refund
+}
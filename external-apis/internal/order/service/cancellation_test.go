@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/payment"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockLoyaltyClient is a mock implementation of LoyaltyClient
+type MockLoyaltyClient struct {
+	mock.Mock
+}
+
+func (m *MockLoyaltyClient) ReversePoints(ctx context.Context, customerID, orderID string) error {
+	args := m.Called(customerID, orderID)
+	return args.Error(0)
+}
+
+// MockPaymentRefunder is a mock implementation of PaymentRefunder
+type MockPaymentRefunder struct {
+	mock.Mock
+}
+
+func (m *MockPaymentRefunder) Refund(ctx context.Context, req payment.Request) (*payment.Result, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*payment.Result), args.Error(1)
+}
+
+func TestOrderService_CancelOrder(t *testing.T) {
+	confirmedOrder := func() *model.Order {
+		return &model.Order{
+			ID:             "order-123",
+			CustomerID:     "customer-456",
+			Total:          money.New(5998, "EUR"),
+			Status:         model.StatusConfirmed,
+			ReservationIDs: []string{"reservation-1", "reservation-2"},
+		}
+	}
+	cancelled := func(order *model.Order) *model.Order {
+		order.Status = model.StatusCancelled
+		return order
+	}
+
+	type fixture struct {
+		repo         *MockOrderRepository
+		reservations *MockReservationClient
+		loyalty      *MockLoyaltyClient
+		refunds      *MockPaymentRefunder
+		service      OrderService
+	}
+	newFixture := func() fixture {
+		f := fixture{
+			repo:         new(MockOrderRepository),
+			reservations: new(MockReservationClient),
+			loyalty:      new(MockLoyaltyClient),
+			refunds:      new(MockPaymentRefunder),
+		}
+		f.service = NewOrderService(f.repo, nil, nil, Options{
+			Reservations: f.reservations,
+			Loyalty:      f.loyalty,
+			Refunds:      f.refunds,
+		})
+		return f
+	}
+	saveRefund := func(f fixture) {
+		f.repo.On("SaveRefund", "order-123", mock.AnythingOfType("*model.Refund")).Return(func(refund *model.Refund) *model.Order {
+			order := cancelled(confirmedOrder())
+			order.Refund = refund
+			return order
+		}, nil)
+	}
+
+	t.Run("Cancel, compensate and refund the order", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		ctx := auth.WithActor(context.Background(), "user:alice")
+		f.repo.On("GetByID", "order-123").Return(confirmedOrder(), nil)
+		f.repo.On("Transition", "order-123", model.StatusCancelled, "user:alice", "changed my mind").Return(cancelled(confirmedOrder()), nil)
+		f.reservations.On("ReleaseReservation", "reservation-1").Return(&client.Reservation{ID: "reservation-1"}, nil)
+		f.reservations.On("ReleaseReservation", "reservation-2").Return(&client.Reservation{ID: "reservation-2"}, nil)
+		f.loyalty.On("ReversePoints", "customer-456", "order-123").Return(nil)
+		f.refunds.On("Refund", mock.MatchedBy(func(req payment.Request) bool {
+			return req.OrderID == "order-123" && req.CustomerID == "customer-456" && req.Amount == money.New(5998, "EUR") && req.RefundID != ""
+		})).Return(&payment.Result{Reference: "re_123"}, nil)
+		saveRefund(f)
+
+		// Act
+		result, err := f.service.CancelOrder(ctx, "order-123", model.TransitionRequest{Reason: "changed my mind"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCancelled, result.Status)
+		require.NotNil(t, result.Refund)
+		assert.Equal(t, model.RefundSucceeded, result.Refund.Status)
+		assert.Equal(t, money.New(5998, "EUR"), result.Refund.Amount)
+		assert.Equal(t, "re_123", result.Refund.Reference)
+		assert.Equal(t, "changed my mind", result.Refund.Reason)
+		f.repo.AssertExpectations(t)
+		f.reservations.AssertExpectations(t)
+		f.loyalty.AssertExpectations(t)
+		f.refunds.AssertExpectations(t)
+	})
+
+	t.Run("Record a refund the payment provider rejects", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.repo.On("GetByID", "order-123").Return(confirmedOrder(), nil)
+		f.repo.On("Transition", "order-123", model.StatusCancelled, auth.AnonymousActor, "").Return(cancelled(confirmedOrder()), nil)
+		f.reservations.On("ReleaseReservation", mock.Anything).Return(&client.Reservation{}, nil)
+		f.loyalty.On("ReversePoints", "customer-456", "order-123").Return(nil)
+		f.refunds.On("Refund", mock.Anything).Return(nil, fmt.Errorf("payment already refunded: %w", payment.ErrRejected))
+		saveRefund(f)
+
+		// Act
+		result, err := f.service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+		// Assert
+		require.NoError(t, err, "the order is cancelled even if the refund fails")
+		require.NotNil(t, result.Refund)
+		assert.Equal(t, model.RefundFailed, result.Refund.Status)
+		assert.Contains(t, result.Refund.Error, "payment already refunded")
+	})
+
+	t.Run("Leave the refund pending without a payment provider", func(t *testing.T) {
+		// Arrange
+		repo := new(MockOrderRepository)
+		service := NewOrderService(repo, nil, nil, Options{})
+		order := confirmedOrder()
+		repo.On("GetByID", "order-123").Return(order, nil)
+		repo.On("Transition", "order-123", model.StatusCancelled, auth.AnonymousActor, "").Return(cancelled(confirmedOrder()), nil)
+		repo.On("SaveRefund", "order-123", mock.AnythingOfType("*model.Refund")).Return(func(refund *model.Refund) *model.Order {
+			order := cancelled(confirmedOrder())
+			order.Refund = refund
+			return order
+		}, nil)
+
+		// Act
+		result, err := service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, result.Refund)
+		assert.Equal(t, model.RefundPending, result.Refund.Status)
+	})
+
+	t.Run("Compensation failures do not undo the cancellation", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.repo.On("GetByID", "order-123").Return(confirmedOrder(), nil)
+		f.repo.On("Transition", "order-123", model.StatusCancelled, auth.AnonymousActor, "").Return(cancelled(confirmedOrder()), nil)
+		f.reservations.On("ReleaseReservation", mock.Anything).Return(nil, client.ErrUnavailable)
+		f.loyalty.On("ReversePoints", "customer-456", "order-123").Return(errors.New("loyalty program down"))
+		f.refunds.On("Refund", mock.Anything).Return(&payment.Result{Reference: "re_123"}, nil)
+		saveRefund(f)
+
+		// Act
+		result, err := f.service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCancelled, result.Status)
+		assert.Equal(t, model.RefundSucceeded, result.Refund.Status)
+	})
+
+	t.Run("No refund for an order that charged nothing", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		order := confirmedOrder()
+		order.Status = model.StatusCreated
+		order.Total = money.Money{}
+		order.ReservationIDs = nil
+		f.repo.On("GetByID", "order-123").Return(order, nil)
+		f.repo.On("Transition", "order-123", model.StatusCancelled, auth.AnonymousActor, "").Return(cancelled(order.Clone()), nil)
+		f.loyalty.On("ReversePoints", "customer-456", "order-123").Return(nil)
+
+		// Act
+		result, err := f.service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, result.Refund)
+		f.refunds.AssertNotCalled(t, "Refund", mock.Anything)
+		f.reservations.AssertNotCalled(t, "ReleaseReservation", mock.Anything)
+		f.repo.AssertNotCalled(t, "SaveRefund", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Shipped and delivered orders cannot be cancelled", func(t *testing.T) {
+		for _, status := range []model.OrderStatus{model.StatusShipped, model.StatusDelivered} {
+			// Arrange
+			f := newFixture()
+			order := confirmedOrder()
+			order.Status = status
+			f.repo.On("GetByID", "order-123").Return(order, nil)
+
+			// Act
+			result, err := f.service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, model.ErrOrderShipped, string(status))
+			f.repo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			f.reservations.AssertNotCalled(t, "ReleaseReservation", mock.Anything)
+			f.refunds.AssertNotCalled(t, "Refund", mock.Anything)
+		}
+	})
+
+	t.Run("Cancelled orders are not cancelled again", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.repo.On("GetByID", "order-123").Return(cancelled(confirmedOrder()), nil)
+
+		// Act
+		_, err := f.service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderCancelled)
+		f.refunds.AssertNotCalled(t, "Refund", mock.Anything)
+	})
+
+	t.Run("Order shipped while it was cancelled", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.repo.On("GetByID", "order-123").Return(confirmedOrder(), nil)
+		f.repo.On("Transition", "order-123", model.StatusCancelled, auth.AnonymousActor, "").Return(nil, model.ErrInvalidStatusTransition)
+
+		// Act
+		_, err := f.service.CancelOrder(context.Background(), "order-123", model.TransitionRequest{})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+		f.reservations.AssertNotCalled(t, "ReleaseReservation", mock.Anything)
+		f.refunds.AssertNotCalled(t, "Refund", mock.Anything)
+	})
+
+	t.Run("Cancelling through a transition compensates too", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.repo.On("GetByID", "order-123").Return(confirmedOrder(), nil)
+		f.repo.On("Transition", "order-123", model.StatusCancelled, auth.AnonymousActor, "").Return(cancelled(confirmedOrder()), nil)
+		f.reservations.On("ReleaseReservation", mock.Anything).Return(&client.Reservation{}, nil)
+		f.loyalty.On("ReversePoints", "customer-456", "order-123").Return(nil)
+		f.refunds.On("Refund", mock.Anything).Return(&payment.Result{Reference: "re_123"}, nil)
+		saveRefund(f)
+
+		// Act
+		result, err := f.service.TransitionOrder(context.Background(), "order-123", model.StatusCancelled, model.TransitionRequest{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.RefundSucceeded, result.Refund.Status)
+		f.reservations.AssertNumberOfCalls(t, "ReleaseReservation", 2)
+	})
+}
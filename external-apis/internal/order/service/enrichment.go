@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+)
+
+// EnrichJobKind is the job kind completing partially enriched orders
+const EnrichJobKind = "order-enrichment"
+
+// EnrichmentOptions configures the completion of orders accepted while the
+// customer or product service was unavailable
+type EnrichmentOptions struct {
+	// Jobs runs the re-enrichment in the background. Orders are rejected
+	// when a downstream service is unavailable while it is nil.
+	Jobs *jobs.Manager
+	// Retry decides how often and how far apart re-enrichment is attempted.
+	// An order whose attempts are exhausted stays partially enriched until
+	// RetryStaleEnrichments completes it, still backing off up to
+	// MaxBackoff.
+	Retry jobs.RetryPolicy
+}
+
+// enrichJob is the job payload for completing a single order
+type enrichJob struct {
+	OrderID string `json:"orderId"`
+	Tenant  string `json:"tenant,omitempty"`
+}
+
+// scheduleEnrichment queues the completion of a partially enriched order,
+// giving the downstream service its initial backoff to recover first. The
+// order was accepted either way, so a failure is only logged.
+func (s *orderService) scheduleEnrichment(ctx context.Context, order *model.Order) {
+	fields := logger.Fields{
+		"order_id": order.ID,
+		"missing":  order.Enrichment.Missing,
+	}
+
+	job := enrichJob{OrderID: order.ID, Tenant: tenant.FromContext(ctx)}
+	if _, err := s.enrichment.Jobs.SubmitAfter(EnrichJobKind, job, s.enrichment.Retry.InitialBackoff); err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to schedule order enrichment")
+		return
+	}
+
+	log.Ctx(ctx).WithFields(fields).Warn("Accepted partially enriched order")
+}
+
+// handleEnrichJob completes the order named in the job payload
+func (s *orderService) handleEnrichJob(ctx context.Context, payload json.RawMessage) error {
+	var job enrichJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid order enrichment payload: %w", err))
+	}
+
+	if job.Tenant != "" {
+		ctx = tenant.WithTenant(ctx, job.Tenant)
+	}
+	return s.completeEnrichment(ctx, job.OrderID)
+}
+
+// ReEnrichOrder fetches the parts an order could not be enriched with when it
+// was placed, without waiting for the next background attempt. A failed
+// enrichment is attempted again too, in case its customer or product was
+// fixed since. It returns the error of a part that still cannot be fetched.
+func (s *orderService) ReEnrichOrder(ctx context.Context, id string) (*model.OrderResponse, error) {
+	log.Ctx(ctx).WithField("order_id", id).Debug("Re-enriching order")
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", id).Error("Order not found for re-enrichment")
+		return nil, err
+	}
+	if order.Enrichment == nil {
+		return nil, model.ErrOrderEnriched
+	}
+	if order.Status == model.StatusCancelled {
+		return nil, model.ErrOrderCancelled
+	}
+
+	order.Enrichment.Status = model.EnrichmentPartial
+	order.Enrichment.Error = ""
+	enriched, err := s.enrichMissing(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	response := enriched.ToResponse()
+	return &response, nil
+}
+
+// RetryStaleEnrichments attempts to complete the partially enriched orders
+// whose background retries are exhausted, each once its next attempt is due,
+// and returns how many were completed. Attempts keep backing off
+// exponentially up to the retry policy's maximum backoff; an order whose
+// parts are still unavailable is left for a later sweep.
+func (s *orderService) RetryStaleEnrichments(ctx context.Context) (int, error) {
+	orders, err := s.repo.GetDueForEnrichment(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, order := range orders {
+		// Orders within their retries are left to their enrichment job
+		if order.Enrichment.Attempts < s.enrichment.Retry.MaxAttempts {
+			continue
+		}
+
+		if _, err := s.enrichMissing(ctx, order); err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+				"order_id": order.ID,
+				"attempts": order.Enrichment.Attempts + 1,
+			}).Warn("Stale order is still not fully enriched")
+			continue
+		}
+		completed++
+	}
+
+	if completed > 0 {
+		log.Ctx(ctx).WithField("completed", completed).Info("Completed enrichment of stale orders")
+	}
+	return completed, nil
+}
+
+// completeEnrichment completes the order named in an enrichment job, unless
+// it was completed or deleted in the meantime
+func (s *orderService) completeEnrichment(ctx context.Context, id string) error {
+	order, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, model.ErrOrderNotFound) {
+		// The order was deleted in the meantime
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if order.Enrichment == nil || order.Enrichment.Status != model.EnrichmentPartial {
+		return nil
+	}
+
+	_, err = s.enrichMissing(ctx, order)
+	return err
+}
+
+// enrichMissing fetches the parts a partially enriched order is missing and
+// returns the order as it was saved. Parts whose service is still
+// unavailable stay missing and fail the attempt so it is retried, with the
+// next attempt backed off by the retry policy; a customer or product that
+// turns out not to exist or to be inactive fails the enrichment for good.
+func (s *orderService) enrichMissing(ctx context.Context, order *model.Order) (*model.Order, error) {
+	id := order.ID
+	enriched := *order
+	var missing []string
+	var failure, unavailable error
+
+	for _, part := range order.Enrichment.Missing {
+		if failure != nil {
+			missing = append(missing, part)
+			continue
+		}
+
+		var err error
+		switch part {
+		case model.EnrichCustomer:
+			var customer *model.OrderCustomer
+			if customer, err = s.enrichCustomer(ctx, order.CustomerID); err == nil {
+				enriched.Customer = customer
+			}
+		case model.EnrichProducts:
+			var products []model.OrderProduct
+			if products, err = s.enrichProducts(ctx, order); err == nil {
+				enriched.Products = products
+			}
+		case model.EnrichTax:
+			// The tax is on the products, which come first
+			if slices.Contains(missing, model.EnrichProducts) {
+				err = model.ErrProductsUnavailable
+				break
+			}
+			var lines []model.TaxLine
+			if lines, err = s.taxOrder(ctx, &enriched); err == nil {
+				enriched.TaxLines = lines
+			}
+		}
+
+		if err != nil {
+			missing = append(missing, part)
+			switch {
+			case !errors.Is(err, apperror.ErrUnavailable):
+				failure = err
+			case unavailable == nil:
+				unavailable = err
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	attempts := order.Enrichment.Attempts + 1
+	tax, err := enriched.CalculateTax()
+	if err != nil && failure == nil {
+		failure = err
+	}
+	enriched.Tax = tax
+	total, err := enriched.CalculateTotal()
+	if err != nil && failure == nil {
+		failure = err
+	}
+	enriched.Total = total
+	enriched.Enrichment = nil
+	switch {
+	case failure != nil:
+		enriched.Enrichment = &model.Enrichment{Status: model.EnrichmentFailed, Missing: missing, Error: failure.Error(), Attempts: attempts}
+	case len(missing) > 0:
+		next := now.Add(s.enrichment.Retry.Backoff(attempts))
+		enriched.Enrichment = &model.Enrichment{Status: model.EnrichmentPartial, Missing: missing, Attempts: attempts, NextAttemptAt: &next}
+	default:
+		enriched.ShippingOptions = s.shippingOptions(ctx, &enriched)
+		s.snapshotOrder(ctx, &enriched)
+	}
+
+	fields := logger.Fields{"order_id": id, "missing": missing, "attempts": attempts}
+	saved, err := s.repo.Update(ctx, id, &enriched)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to save order enrichment")
+		return nil, err
+	}
+
+	switch {
+	case failure != nil:
+		log.Ctx(ctx).WithError(failure).WithFields(fields).Error("Order enrichment failed")
+		return saved, jobs.Permanent(failure)
+	case len(missing) > 0:
+		return saved, fmt.Errorf("order %s is still missing %v: %w", id, missing, unavailable)
+	}
+
+	// An order cancelled or deleted while it was enriched is left as it is
+	transitioned, err := s.repo.Transition(ctx, id, model.StatusEnriched, model.SystemActor, "", now)
+	switch {
+	case err == nil:
+		saved = transitioned
+	case !errors.Is(err, model.ErrInvalidStatusTransition) && !errors.Is(err, model.ErrOrderNotFound):
+		log.Ctx(ctx).WithError(err).WithField("order_id", id).Error("Failed to mark order enriched")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithField("order_id", id).Info("Completed order enrichment")
+	return saved, nil
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/tax"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newEnrichmentOptions returns options whose re-enrichment stays pending for
+// the duration of a test
+func newEnrichmentOptions() EnrichmentOptions {
+	return EnrichmentOptions{
+		Jobs:  jobs.NewManager(nil, jobs.DefaultOptions()),
+		Retry: jobs.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour},
+	}
+}
+
+func TestOrderService_CreateOrder_Fallback(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001"},
+	}
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+	}
+
+	t.Run("Accept the order partially enriched", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		options := newEnrichmentOptions()
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Enrichment: options})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrUnavailable)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			order.ID = "order-123"
+			return order
+		}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, result.Customer)
+		assert.Len(t, result.Products, 1)
+		assert.Equal(t, json.Number("29.99"), result.Total)
+		require.NotNil(t, result.Enrichment)
+		assert.Equal(t, model.EnrichmentPartial, result.Enrichment.Status)
+		assert.Equal(t, []string{model.EnrichCustomer}, result.Enrichment.Missing)
+		require.NotNil(t, result.Enrichment.NextAttemptAt)
+		assert.Equal(t, result.CreatedAt.Add(time.Hour), *result.Enrichment.NextAttemptAt, "the first attempt is due after the initial backoff")
+		assert.Equal(t, 1, options.Jobs.Pending())
+	})
+
+	t.Run("Reject orders that cannot be placed", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		options := newEnrichmentOptions()
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Enrichment: options})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrUnavailable)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{}, nil)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		assert.Zero(t, options.Jobs.Pending())
+	})
+}
+
+func TestOrderService_CompleteEnrichment(t *testing.T) {
+	partialOrder := func(missing ...string) *model.Order {
+		return &model.Order{
+			ID:         "order-123",
+			CustomerID: "customer-456",
+			ProductIDs: []string{"product-001", "product-001"},
+			Enrichment: &model.Enrichment{Status: model.EnrichmentPartial, Missing: missing},
+		}
+	}
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+	}
+
+	t.Run("Complete the missing parts", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Enrichment: newEnrichmentOptions()}).(*orderService)
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichCustomer, model.EnrichProducts), nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", []string{"product-001", "product-001"}).Return(products, nil)
+		var saved *model.Order
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*model.Order)
+		}).Return(&model.Order{}, nil)
+		mockRepo.On("Transition", "order-123", model.StatusEnriched, model.SystemActor, "").Return(&model.Order{}, nil)
+
+		// Act
+		err := service.completeEnrichment(context.Background(), "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.Nil(t, saved.Enrichment)
+		assert.Equal(t, "John Doe", saved.Customer.Name)
+		assert.Len(t, saved.Products, 2)
+		assert.Equal(t, money.New(5998, "USD"), saved.Total)
+		require.NotNil(t, saved.Snapshot, "the order is snapshotted once fully enriched")
+		assert.Equal(t, 2, saved.Snapshot.LineItems[0].Quantity)
+		mockRepo.AssertCalled(t, "Transition", "order-123", model.StatusEnriched, model.SystemActor, "")
+	})
+
+	t.Run("Leave an order cancelled while it was enriched", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()}).(*orderService)
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichCustomer), nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Return(&model.Order{}, nil)
+		mockRepo.On("Transition", "order-123", model.StatusEnriched, model.SystemActor, "").Return(nil, model.ErrInvalidStatusTransition)
+
+		// Act
+		err := service.completeEnrichment(context.Background(), "order-123")
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Keep parts whose service is still unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Enrichment: newEnrichmentOptions()}).(*orderService)
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichCustomer, model.EnrichProducts), nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", mock.Anything).Return(nil, client.ErrUnavailable)
+		var saved *model.Order
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*model.Order)
+		}).Return(&model.Order{}, nil)
+
+		// Act
+		err := service.completeEnrichment(context.Background(), "order-123")
+
+		// Assert
+		assert.ErrorIs(t, err, apperror.ErrUnavailable)
+		assert.False(t, jobs.IsPermanent(err))
+		require.NotNil(t, saved.Enrichment)
+		assert.Equal(t, model.EnrichmentPartial, saved.Enrichment.Status)
+		assert.Equal(t, []string{model.EnrichProducts}, saved.Enrichment.Missing)
+		assert.Equal(t, "John Doe", saved.Customer.Name)
+		assert.Equal(t, 1, saved.Enrichment.Attempts)
+		require.NotNil(t, saved.Enrichment.NextAttemptAt)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *saved.Enrichment.NextAttemptAt, time.Minute)
+	})
+
+	t.Run("Tax the order once its products are enriched", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockProducts := new(MockProductClient)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		service := NewOrderService(mockRepo, nil, mockProducts, Options{
+			Enrichment: newEnrichmentOptions(),
+			Taxes:      mockTaxes,
+			Addresses:  mockAddresses,
+		}).(*orderService)
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichProducts, model.EnrichTax), nil)
+		mockProducts.On("GetProducts", []string{"product-001", "product-001"}).Return(products, nil)
+		mockAddresses.On("GetAddresses", "customer-456").Return([]*client.Address{{Type: client.AddressTypeShipping, Country: "DE"}}, nil)
+		mockTaxes.On("Calculate", mock.MatchedBy(func(req tax.Request) bool {
+			return len(req.Items) == 2
+		})).Return([]model.TaxLine{{Jurisdiction: "DE", Rate: 19, Taxable: money.New(5998, "USD"), Amount: money.New(1140, "USD")}}, nil)
+		var saved *model.Order
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*model.Order)
+		}).Return(&model.Order{}, nil)
+		mockRepo.On("Transition", "order-123", model.StatusEnriched, model.SystemActor, "").Return(&model.Order{}, nil)
+
+		// Act
+		err := service.completeEnrichment(context.Background(), "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, saved.Enrichment)
+		assert.Equal(t, money.New(1140, "USD"), saved.Tax)
+		assert.Equal(t, money.New(7138, "USD"), saved.Total)
+	})
+
+	t.Run("Fail for good when the customer does not exist", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()}).(*orderService)
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichCustomer), nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrNotFound)
+		var saved *model.Order
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*model.Order)
+		}).Return(&model.Order{}, nil)
+
+		// Act
+		err := service.completeEnrichment(context.Background(), "order-123")
+
+		// Assert
+		assert.True(t, jobs.IsPermanent(err))
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		require.NotNil(t, saved.Enrichment)
+		assert.Equal(t, model.EnrichmentFailed, saved.Enrichment.Status)
+		assert.Equal(t, "customer not found", saved.Enrichment.Error)
+	})
+
+	t.Run("Skip deleted and complete orders", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{Enrichment: newEnrichmentOptions()}).(*orderService)
+
+		mockRepo.On("GetByID", "deleted").Return(nil, model.ErrOrderNotFound)
+		mockRepo.On("GetByID", "complete").Return(&model.Order{ID: "complete"}, nil)
+
+		// Act
+		deletedErr := service.completeEnrichment(context.Background(), "deleted")
+		completeErr := service.completeEnrichment(context.Background(), "complete")
+
+		// Assert
+		assert.NoError(t, deletedErr)
+		assert.NoError(t, completeErr)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Repository errors are retried", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{Enrichment: newEnrichmentOptions()}).(*orderService)
+		failure := errors.New("storage down")
+
+		mockRepo.On("GetByID", "order-123").Return(nil, failure)
+
+		// Act
+		err := service.completeEnrichment(context.Background(), "order-123")
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+		assert.False(t, jobs.IsPermanent(err))
+	})
+}
+
+func TestOrderService_ReEnrichOrder(t *testing.T) {
+	partialOrder := func(status string, missing ...string) *model.Order {
+		return &model.Order{
+			ID:         "order-123",
+			CustomerID: "customer-456",
+			ProductIDs: []string{"product-001"},
+			Status:     model.StatusCreated,
+			Enrichment: &model.Enrichment{Status: status, Missing: missing, Attempts: 2},
+		}
+	}
+
+	t.Run("Complete the order at once", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()})
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichmentPartial, model.EnrichCustomer), nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Return(&model.Order{}, nil)
+		mockRepo.On("Transition", "order-123", model.StatusEnriched, model.SystemActor, "").Return(&model.Order{
+			ID:       "order-123",
+			Customer: activeCustomer().ToOrderCustomer(),
+			Status:   model.StatusEnriched,
+		}, nil)
+
+		// Act
+		result, err := service.ReEnrichOrder(context.Background(), "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusEnriched, result.Status)
+		assert.Nil(t, result.Enrichment)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Fail while a service is still unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()})
+
+		mockRepo.On("GetByID", "order-123").Return(partialOrder(model.EnrichmentPartial, model.EnrichCustomer), nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrUnavailable)
+		var saved *model.Order
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*model.Order)
+		}).Return(&model.Order{}, nil)
+
+		// Act
+		result, err := service.ReEnrichOrder(context.Background(), "order-123")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrCustomersUnavailable)
+		assert.Equal(t, 3, saved.Enrichment.Attempts)
+		assert.Equal(t, []string{model.EnrichCustomer}, saved.Enrichment.Missing)
+	})
+
+	t.Run("Attempt a failed enrichment again", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()})
+		order := partialOrder(model.EnrichmentFailed, model.EnrichCustomer)
+		order.Enrichment.Error = "customer is not active"
+
+		mockRepo.On("GetByID", "order-123").Return(order, nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockRepo.On("Update", "order-123", mock.AnythingOfType("*model.Order")).Return(&model.Order{}, nil)
+		mockRepo.On("Transition", "order-123", model.StatusEnriched, model.SystemActor, "").Return(&model.Order{Status: model.StatusEnriched}, nil)
+
+		// Act
+		result, err := service.ReEnrichOrder(context.Background(), "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusEnriched, result.Status)
+	})
+
+	t.Run("Reject orders that need no enrichment", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+		cancelled := partialOrder(model.EnrichmentPartial, model.EnrichCustomer)
+		cancelled.ID = "cancelled"
+		cancelled.Status = model.StatusCancelled
+
+		mockRepo.On("GetByID", "enriched").Return(&model.Order{ID: "enriched", Status: model.StatusEnriched}, nil)
+		mockRepo.On("GetByID", "cancelled").Return(cancelled, nil)
+		mockRepo.On("GetByID", "missing").Return(nil, model.ErrOrderNotFound)
+
+		// Act
+		_, enrichedErr := service.ReEnrichOrder(context.Background(), "enriched")
+		_, cancelledErr := service.ReEnrichOrder(context.Background(), "cancelled")
+		_, missingErr := service.ReEnrichOrder(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, enrichedErr, model.ErrOrderEnriched)
+		assert.ErrorIs(t, cancelledErr, model.ErrOrderCancelled)
+		assert.ErrorIs(t, missingErr, model.ErrOrderNotFound)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOrderService_RetryStaleEnrichments(t *testing.T) {
+	staleOrder := func(id string, attempts int) *model.Order {
+		return &model.Order{
+			ID:         id,
+			CustomerID: "customer-456",
+			Enrichment: &model.Enrichment{Status: model.EnrichmentPartial, Missing: []string{model.EnrichCustomer}, Attempts: attempts},
+		}
+	}
+
+	t.Run("Retry orders whose retries are exhausted", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()})
+
+		mockRepo.On("GetDueForEnrichment", mock.AnythingOfType("time.Time")).Return([]*model.Order{
+			staleOrder("stale", 3),
+			staleOrder("retrying", 2),
+		}, nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockRepo.On("Update", "stale", mock.AnythingOfType("*model.Order")).Return(&model.Order{}, nil)
+		mockRepo.On("Transition", "stale", model.StatusEnriched, model.SystemActor, "").Return(&model.Order{}, nil)
+
+		// Act
+		completed, err := service.RetryStaleEnrichments(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		mockRepo.AssertNotCalled(t, "Update", "retrying", mock.Anything)
+		mockCustomers.AssertNumberOfCalls(t, "GetCustomer", 1)
+	})
+
+	t.Run("Back off orders that are still missing parts", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{Enrichment: newEnrichmentOptions()})
+
+		mockRepo.On("GetDueForEnrichment", mock.AnythingOfType("time.Time")).Return([]*model.Order{staleOrder("stale", 5)}, nil)
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrUnavailable)
+		var saved *model.Order
+		mockRepo.On("Update", "stale", mock.AnythingOfType("*model.Order")).Run(func(args mock.Arguments) {
+			saved = args.Get(1).(*model.Order)
+		}).Return(&model.Order{}, nil)
+
+		// Act
+		completed, err := service.RetryStaleEnrichments(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, completed)
+		assert.Equal(t, 6, saved.Enrichment.Attempts)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *saved.Enrichment.NextAttemptAt, time.Minute, "backed off up to the maximum backoff")
+	})
+
+	t.Run("Repository errors fail the sweep", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{Enrichment: newEnrichmentOptions()})
+		failure := errors.New("storage down")
+
+		mockRepo.On("GetDueForEnrichment", mock.AnythingOfType("time.Time")).Return([]*model.Order(nil), failure)
+
+		// Act
+		_, err := service.RetryStaleEnrichments(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+	})
+}
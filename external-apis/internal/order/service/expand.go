@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/batch"
+)
+
+// OrderExpander inlines the customer and products of order responses
+type OrderExpander interface {
+	Expand(ctx context.Context, orders []*model.OrderResponse, expand model.Expand) error
+}
+
+// ExpansionOptions configures one relationship expansion
+type ExpansionOptions struct {
+	// CacheTTL is how long fetched entities are reused; zero disables caching
+	CacheTTL time.Duration
+	// Policy decides what happens when the entities cannot be fetched
+	Policy model.ExpandPolicy
+}
+
+// ExpanderOptions configures the customer and products expansions
+type ExpanderOptions struct {
+	Customer ExpansionOptions
+	Products ExpansionOptions
+}
+
+// orderExpander implements OrderExpander
+type orderExpander struct {
+	customers client.CustomerClient
+	products  client.ProductClient
+	options   ExpanderOptions
+}
+
+// NewOrderExpander creates an expander fetching the current customers and
+// products of orders, each through its own cache
+func NewOrderExpander(customers client.CustomerClient, products client.ProductClient, options ExpanderOptions) OrderExpander {
+	return &orderExpander{
+		customers: client.NewCachingCustomerClient(customers, options.Customer.CacheTTL),
+		products:  client.NewCachingProductClient(products, options.Products.CacheTTL),
+		options:   options,
+	}
+}
+
+// Expand inlines the selected relationships into the orders, fetching every
+// distinct customer and product once for the whole list. Entities that no
+// longer exist are left out and named in expandErrors. When a service is
+// unavailable the expansion's policy either fails the call or leaves the
+// relationship out of every order.
+func (e *orderExpander) Expand(ctx context.Context, orders []*model.OrderResponse, expand model.Expand) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	if expand.Customer {
+		if err := e.expandCustomers(ctx, orders); err != nil {
+			return err
+		}
+	}
+	if expand.Products {
+		if err := e.expandProducts(ctx, orders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandCustomers inlines the customer of every order
+func (e *orderExpander) expandCustomers(ctx context.Context, orders []*model.OrderResponse) error {
+	ids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, order.CustomerID)
+	}
+
+	found, err := e.customers.GetCustomers(ctx, batch.UniqueIDs(ids))
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customers", len(ids)).Warn("Failed to expand order customers")
+		return skipExpansion(orders, model.ExpandCustomer, e.options.Customer.Policy, model.ErrCustomersUnavailable)
+	}
+
+	for _, order := range orders {
+		customer, ok := found[order.CustomerID]
+		if !ok {
+			setExpandError(order, model.ExpandCustomer, "customer not found")
+			continue
+		}
+		order.Customer = customer.ToOrderCustomer()
+	}
+	return nil
+}
+
+// expandProducts inlines the products of every order, in the order of its
+// product IDs
+func (e *orderExpander) expandProducts(ctx context.Context, orders []*model.OrderResponse) error {
+	var ids []string
+	for _, order := range orders {
+		ids = append(ids, order.ProductIDs...)
+	}
+
+	found, err := e.products.GetProducts(ctx, batch.UniqueIDs(ids))
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("products", len(ids)).Warn("Failed to expand order products")
+		return skipExpansion(orders, model.ExpandProducts, e.options.Products.Policy, model.ErrProductsUnavailable)
+	}
+
+	for _, order := range orders {
+		products := make([]model.OrderProduct, 0, len(order.ProductIDs))
+		var missing []string
+		for _, id := range order.ProductIDs {
+			product, ok := found[id]
+			if !ok {
+				missing = append(missing, id)
+				continue
+			}
+			products = append(products, product.ToOrderProduct())
+		}
+
+		order.Products = products
+		if len(missing) > 0 {
+			setExpandError(order, model.ExpandProducts, "products not found: "+strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// skipExpansion applies the failure policy of an expansion whose service is
+// unavailable
+func skipExpansion(orders []*model.OrderResponse, expansion string, policy model.ExpandPolicy, unavailable error) error {
+	if policy != model.ExpandPartial {
+		return unavailable
+	}
+	for _, order := range orders {
+		setExpandError(order, expansion, unavailable.Error())
+	}
+	return nil
+}
+
+// setExpandError records why an expansion of the order is incomplete
+func setExpandError(order *model.OrderResponse, expansion, message string) {
+	if order.ExpandErrors == nil {
+		order.ExpandErrors = make(map[string]string)
+	}
+	order.ExpandErrors[expansion] = message
+}
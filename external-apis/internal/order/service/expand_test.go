@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func expandableOrders() []*model.OrderResponse {
+	return []*model.OrderResponse{
+		{ID: "order-1", CustomerID: "customer-456", ProductIDs: []string{"product-001", "product-002"}},
+		{ID: "order-2", CustomerID: "customer-456", ProductIDs: []string{"product-002", "product-gone"}},
+	}
+}
+
+func TestOrderExpander_Expand(t *testing.T) {
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Mouse", Price: money.New(2999, "USD"), Active: true},
+		"product-002": {ID: "product-002", Name: "Keyboard", Price: money.New(12999, "USD"), Active: true},
+	}
+
+	t.Run("Customer and products", func(t *testing.T) {
+		// Arrange
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		expander := NewOrderExpander(mockCustomers, mockProducts, ExpanderOptions{})
+		orders := expandableOrders()
+
+		mockCustomers.On("GetCustomers", []string{"customer-456"}).
+			Return(map[string]*client.Customer{"customer-456": activeCustomer()}, nil)
+		mockProducts.On("GetProducts", []string{"product-001", "product-002", "product-gone"}).Return(products, nil)
+
+		// Act
+		err := expander.Expand(context.Background(), orders, model.Expand{Customer: true, Products: true})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", orders[0].Customer.Name)
+		assert.Equal(t, "John Doe", orders[1].Customer.Name)
+		require.Len(t, orders[0].Products, 2)
+		assert.Equal(t, "Keyboard", orders[0].Products[1].Name)
+		assert.Empty(t, orders[0].ExpandErrors)
+		require.Len(t, orders[1].Products, 1, "products that no longer exist are left out")
+		assert.Equal(t, "products not found: product-gone", orders[1].ExpandErrors[model.ExpandProducts])
+		mockCustomers.AssertExpectations(t)
+		mockProducts.AssertExpectations(t)
+	})
+
+	t.Run("Current products next to the snapshot", func(t *testing.T) {
+		// Arrange
+		mockProducts := new(MockProductClient)
+		expander := NewOrderExpander(new(MockCustomerClient), mockProducts, ExpanderOptions{})
+		orders := expandableOrders()[:1]
+		orders[0].Snapshot = &model.OrderSnapshot{LineItems: []model.LineItem{
+			{ProductID: "product-001", Name: "Old Mouse", Quantity: 1, UnitPrice: money.New(1999, "USD")},
+		}}
+
+		mockProducts.On("GetProducts", mock.Anything).Return(products, nil)
+
+		// Act
+		err := expander.Expand(context.Background(), orders, model.Expand{Products: true})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Mouse", orders[0].Products[0].Name)
+		assert.Equal(t, "Old Mouse", orders[0].Snapshot.LineItems[0].Name, "the snapshot is not rewritten")
+		assert.Equal(t, money.New(1999, "USD"), orders[0].Snapshot.LineItems[0].UnitPrice)
+	})
+
+	t.Run("Only the selected relationships", func(t *testing.T) {
+		// Arrange
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		expander := NewOrderExpander(mockCustomers, mockProducts, ExpanderOptions{})
+		orders := expandableOrders()
+
+		mockProducts.On("GetProducts", mock.Anything).Return(products, nil)
+
+		// Act
+		err := expander.Expand(context.Background(), orders, model.Expand{Products: true})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, orders[0].Customer)
+		assert.Len(t, orders[0].Products, 2)
+		mockCustomers.AssertNotCalled(t, "GetCustomers", mock.Anything)
+	})
+
+	t.Run("Unavailable service fails the read", func(t *testing.T) {
+		// Arrange
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		expander := NewOrderExpander(mockCustomers, mockProducts, ExpanderOptions{
+			Customer: ExpansionOptions{Policy: model.ExpandFail},
+		})
+
+		mockCustomers.On("GetCustomers", mock.Anything).Return(nil, client.ErrUnavailable)
+
+		// Act
+		err := expander.Expand(context.Background(), expandableOrders(), model.Expand{Customer: true, Products: true})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomersUnavailable)
+		mockProducts.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("Unavailable service with the partial policy", func(t *testing.T) {
+		// Arrange
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		expander := NewOrderExpander(mockCustomers, mockProducts, ExpanderOptions{
+			Customer: ExpansionOptions{Policy: model.ExpandFail},
+			Products: ExpansionOptions{Policy: model.ExpandPartial},
+		})
+		orders := expandableOrders()
+
+		mockCustomers.On("GetCustomers", mock.Anything).
+			Return(map[string]*client.Customer{"customer-456": activeCustomer()}, nil)
+		mockProducts.On("GetProducts", mock.Anything).Return(nil, client.ErrUnavailable)
+
+		// Act
+		err := expander.Expand(context.Background(), orders, model.Expand{Customer: true, Products: true})
+
+		// Assert
+		require.NoError(t, err)
+		for _, order := range orders {
+			assert.Equal(t, "John Doe", order.Customer.Name)
+			assert.Nil(t, order.Products)
+			assert.Equal(t, "product service unavailable", order.ExpandErrors[model.ExpandProducts])
+		}
+	})
+
+	t.Run("Cached entities are fetched once", func(t *testing.T) {
+		// Arrange
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		expander := NewOrderExpander(mockCustomers, mockProducts, ExpanderOptions{
+			Customer: ExpansionOptions{CacheTTL: time.Minute},
+		})
+
+		mockCustomers.On("GetCustomers", []string{"customer-456"}).
+			Return(map[string]*client.Customer{"customer-456": activeCustomer()}, nil).Once()
+
+		// Act
+		for i := 0; i < 3; i++ {
+			orders := expandableOrders()
+			require.NoError(t, expander.Expand(context.Background(), orders, model.Expand{Customer: true}))
+			assert.Equal(t, "John Doe", orders[0].Customer.Name)
+		}
+
+		// Assert
+		mockCustomers.AssertNumberOfCalls(t, "GetCustomers", 1)
+	})
+}
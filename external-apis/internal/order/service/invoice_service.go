@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"external-apis/internal/order/invoice"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/storage"
+	"external-apis/internal/shared/tenant"
+)
+
+// InvoiceJobKind is the job kind generating an invoice document in the
+// background
+const InvoiceJobKind = "invoice-generation"
+
+// InvoiceService defines the interface for the invoices and receipts of
+// orders
+type InvoiceService interface {
+	RenderInvoice(ctx context.Context, orderID, kind, format string) (*invoice.File, error)
+	GenerateInvoice(ctx context.Context, orderID, kind, format string) (*model.InvoiceDocument, error)
+	GetInvoiceDocument(ctx context.Context, orderID, id string) (*model.InvoiceDocument, error)
+}
+
+// InvoiceOptions configures generating invoices in the background. Both
+// are needed; without them invoices are only rendered on request.
+type InvoiceOptions struct {
+	// Jobs runs the generation
+	Jobs *jobs.Manager
+	// Storage keeps the generated documents for download
+	Storage storage.Storage
+}
+
+// invoiceJob is the job payload for generating a single document
+type invoiceJob struct {
+	OrderID    string `json:"orderId"`
+	DocumentID string `json:"documentId"`
+	Tenant     string `json:"tenant,omitempty"`
+}
+
+// invoiceService implements InvoiceService
+type invoiceService struct {
+	orders    repository.OrderRepository
+	documents repository.InvoiceRepository
+	renderer  *invoice.Renderer
+	branding  invoice.Branding
+	options   InvoiceOptions
+	now       func() time.Time
+}
+
+// NewInvoiceService creates a new invoice service rendering the orders of
+// orders with renderer, branded with branding. When the options name a job
+// manager, the service registers its generation job with it, so it must be
+// created before the manager is started.
+func NewInvoiceService(orders repository.OrderRepository, documents repository.InvoiceRepository, renderer *invoice.Renderer, branding invoice.Branding, options InvoiceOptions) InvoiceService {
+	s := &invoiceService{
+		orders:    orders,
+		documents: documents,
+		renderer:  renderer,
+		branding:  branding,
+		options:   options,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+	if options.Jobs != nil && options.Storage != nil {
+		options.Jobs.Register(InvoiceJobKind, s.handleInvoiceJob)
+	}
+	return s
+}
+
+// RenderInvoice renders the invoice or receipt of an order. Orders still
+// being enriched lack the products and tax an invoice lists, so they have
+// none yet.
+func (s *invoiceService) RenderInvoice(ctx context.Context, orderID, kind, format string) (*invoice.File, error) {
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Enrichment != nil {
+		return nil, model.ErrOrderNotEnriched
+	}
+
+	file, err := s.renderer.Render(invoice.NewDocument(order, kind, s.branding, s.now()), format)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"order_id": orderID,
+			"format":   format,
+		}).Error("Failed to render invoice")
+		return nil, err
+	}
+	return file, nil
+}
+
+// GenerateInvoice queues the generation of the invoice or receipt of an
+// order and returns the pending document, whose status tells when it can be
+// downloaded
+func (s *invoiceService) GenerateInvoice(ctx context.Context, orderID, kind, format string) (*model.InvoiceDocument, error) {
+	if s.options.Jobs == nil || s.options.Storage == nil {
+		return nil, model.ErrInvoiceAsyncDisabled
+	}
+
+	order, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Enrichment != nil {
+		return nil, model.ErrOrderNotEnriched
+	}
+
+	document, err := s.documents.Create(ctx, &model.InvoiceDocument{OrderID: orderID, Kind: kind, Format: format})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := logger.Fields{"order_id": orderID, "document_id": document.ID}
+	job := invoiceJob{OrderID: orderID, DocumentID: document.ID, Tenant: tenant.FromContext(ctx)}
+	if _, err := s.options.Jobs.Submit(InvoiceJobKind, job); err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to queue invoice generation")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithFields(fields).Info("Queued invoice generation")
+	return document, nil
+}
+
+// GetInvoiceDocument retrieves a document generated for an order
+func (s *invoiceService) GetInvoiceDocument(ctx context.Context, orderID, id string) (*model.InvoiceDocument, error) {
+	return s.documents.GetByID(ctx, orderID, id)
+}
+
+// handleInvoiceJob generates the document named in the job payload
+func (s *invoiceService) handleInvoiceJob(ctx context.Context, payload json.RawMessage) error {
+	var job invoiceJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid invoice generation payload: %w", err))
+	}
+
+	if job.Tenant != "" {
+		ctx = tenant.WithTenant(ctx, job.Tenant)
+	}
+	return s.generate(ctx, job.OrderID, job.DocumentID)
+}
+
+// generate renders a pending document and stores it. A document that cannot
+// be generated is marked FAILED rather than retried.
+func (s *invoiceService) generate(ctx context.Context, orderID, id string) error {
+	document, err := s.documents.GetByID(ctx, orderID, id)
+	if err != nil {
+		// The document expired in the meantime
+		return jobs.Permanent(err)
+	}
+	if document.Status != model.InvoicePending {
+		return nil
+	}
+
+	fields := logger.Fields{"order_id": orderID, "document_id": id}
+	url, err := s.store(ctx, document)
+	completedAt := s.now()
+	document.CompletedAt = &completedAt
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to generate invoice")
+		document.Status = model.InvoiceFailed
+		document.Error = err.Error()
+	} else {
+		document.Status = model.InvoiceReady
+		document.URL = url
+	}
+
+	if _, err := s.documents.Update(ctx, document); err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to save invoice document")
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(fields).WithField("status", document.Status).Info("Generated invoice")
+	return nil
+}
+
+// store renders a document and puts it in the storage under a key of its
+// own, returning the URL it is downloaded from
+func (s *invoiceService) store(ctx context.Context, document *model.InvoiceDocument) (string, error) {
+	file, err := s.RenderInvoice(ctx, document.OrderID, document.Kind, document.Format)
+	if err != nil {
+		return "", err
+	}
+
+	key := "invoices/" + document.ID + "/" + file.Name
+	if err := s.options.Storage.Put(ctx, key, file.ContentType, file.Data); err != nil {
+		return "", fmt.Errorf("failed to store invoice: %w", err)
+	}
+	return s.options.Storage.URL(key), nil
+}
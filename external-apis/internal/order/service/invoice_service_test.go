@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/invoice"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStorage is a storage whose writes fail
+type failingStorage struct {
+	storage.Storage
+}
+
+func (failingStorage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	return errors.New("disk full")
+}
+
+func TestInvoiceService(t *testing.T) {
+	ctx := context.Background()
+	renderer, err := invoice.NewRenderer("")
+	require.NoError(t, err)
+	branding := invoice.Branding{CompanyName: "Acme", Currency: "USD"}
+
+	newOrders := func(t *testing.T) *repository.MemoryOrderRepository {
+		orders := repository.NewMemoryOrderRepository()
+		_, err := orders.Create(ctx, &model.Order{
+			ID:         "order-123",
+			CustomerID: "customer-456",
+			Customer:   &model.OrderCustomer{ID: "customer-456", Name: "John Doe"},
+			Products:   []model.OrderProduct{{ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "EUR")}},
+			Total:      money.New(2999, "EUR"),
+		})
+		require.NoError(t, err)
+		_, err = orders.Create(ctx, &model.Order{
+			ID:         "order-partial",
+			CustomerID: "customer-456",
+			Enrichment: &model.Enrichment{Status: model.EnrichmentPartial, Missing: []string{model.EnrichProducts}},
+		})
+		require.NoError(t, err)
+		return orders
+	}
+	newService := func(t *testing.T, store storage.Storage) *invoiceService {
+		return NewInvoiceService(newOrders(t), repository.NewMemoryInvoiceRepository(), renderer, branding, InvoiceOptions{
+			Jobs:    jobs.NewManager(nil, jobs.DefaultOptions()),
+			Storage: store,
+		}).(*invoiceService)
+	}
+
+	t.Run("Render the invoice of an order", func(t *testing.T) {
+		// Arrange
+		service := newService(t, nil)
+
+		// Act
+		file, err := service.RenderInvoice(ctx, "order-123", invoice.KindInvoice, invoice.FormatHTML)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "INV-order-123.html", file.Name)
+		assert.Contains(t, string(file.Data), "EUR 29.99")
+	})
+
+	t.Run("No invoice for an order being enriched", func(t *testing.T) {
+		// Arrange
+		local, err := storage.NewLocal(t.TempDir(), "http://localhost:8081/documents")
+		require.NoError(t, err)
+		service := newService(t, local)
+
+		// Act
+		_, renderErr := service.RenderInvoice(ctx, "order-partial", invoice.KindInvoice, invoice.FormatPDF)
+		_, generateErr := service.GenerateInvoice(ctx, "order-partial", invoice.KindInvoice, invoice.FormatPDF)
+
+		// Assert
+		assert.ErrorIs(t, renderErr, model.ErrOrderNotEnriched)
+		assert.ErrorIs(t, generateErr, model.ErrOrderNotEnriched)
+	})
+
+	t.Run("Unknown order", func(t *testing.T) {
+		// Arrange
+		service := newService(t, nil)
+
+		// Act
+		_, err := service.RenderInvoice(ctx, "order-999", invoice.KindInvoice, invoice.FormatPDF)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+
+	t.Run("Generate and store a document in the background", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		local, err := storage.NewLocal(dir, "http://localhost:8081/documents")
+		require.NoError(t, err)
+		service := newService(t, local)
+
+		// Act
+		pending, err := service.GenerateInvoice(ctx, "order-123", invoice.KindReceipt, invoice.FormatPDF)
+		require.NoError(t, err)
+		require.NoError(t, service.generate(ctx, "order-123", pending.ID))
+		document, err := service.GetInvoiceDocument(ctx, "order-123", pending.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.InvoicePending, pending.Status)
+		assert.Equal(t, model.InvoiceReady, document.Status)
+		assert.Equal(t, "http://localhost:8081/documents/invoices/"+pending.ID+"/RCT-order-123.pdf", document.URL)
+		require.NotNil(t, document.CompletedAt)
+
+		data, err := os.ReadFile(filepath.Join(dir, "invoices", pending.ID, "RCT-order-123.pdf"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "%PDF-"))
+	})
+
+	t.Run("Record a document that cannot be stored", func(t *testing.T) {
+		// Arrange
+		service := newService(t, failingStorage{})
+		pending, err := service.GenerateInvoice(ctx, "order-123", invoice.KindInvoice, invoice.FormatPDF)
+		require.NoError(t, err)
+
+		// Act
+		err = service.generate(ctx, "order-123", pending.ID)
+
+		// Assert
+		require.NoError(t, err)
+		document, err := service.GetInvoiceDocument(ctx, "order-123", pending.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.InvoiceFailed, document.Status)
+		assert.Contains(t, document.Error, "disk full")
+		assert.Empty(t, document.URL)
+	})
+
+	t.Run("No background generation without storage", func(t *testing.T) {
+		// Arrange
+		service := NewInvoiceService(newOrders(t), repository.NewMemoryInvoiceRepository(), renderer, branding, InvoiceOptions{})
+
+		// Act
+		_, err := service.GenerateInvoice(ctx, "order-123", invoice.KindInvoice, invoice.FormatPDF)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvoiceAsyncDisabled)
+	})
+
+	t.Run("Unknown document", func(t *testing.T) {
+		// Arrange
+		service := newService(t, nil)
+
+		// Act
+		_, err := service.GetInvoiceDocument(ctx, "order-123", "document-999")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvoiceNotFound)
+	})
+
+	t.Run("Generation is not repeated", func(t *testing.T) {
+		// Arrange
+		local, err := storage.NewLocal(t.TempDir(), "http://localhost:8081/documents")
+		require.NoError(t, err)
+		service := newService(t, local)
+		pending, err := service.GenerateInvoice(ctx, "order-123", invoice.KindInvoice, invoice.FormatHTML)
+		require.NoError(t, err)
+		require.NoError(t, service.generate(ctx, "order-123", pending.ID))
+		first, err := service.GetInvoiceDocument(ctx, "order-123", pending.ID)
+		require.NoError(t, err)
+
+		// Act
+		service.now = func() time.Time { return first.CompletedAt.Add(time.Hour) }
+		err = service.generate(ctx, "order-123", pending.ID)
+
+		// Assert
+		require.NoError(t, err)
+		second, err := service.GetInvoiceDocument(ctx, "order-123", pending.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first.CompletedAt, second.CompletedAt)
+	})
+}
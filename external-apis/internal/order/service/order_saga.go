@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/saga"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+)
+
+// CreateOrderSaga names the saga placing an order
+const CreateOrderSaga = "create-order"
+
+// DefaultSagaRetention is how many sagas are kept in memory by default
+const DefaultSagaRetention = 1000
+
+// Steps of the create-order saga
+const (
+	StepReserveStock     = "reserve-stock"
+	StepValidateCustomer = "validate-customer"
+	StepPersistOrder     = "persist-order"
+	StepConfirmStock     = "confirm-stock"
+)
+
+// createOrderSteps returns the steps of the create-order saga for the order,
+// which they fill in as they go. Stock is only reserved and confirmed when the
+// service has a reservation client.
+func (s *orderService) createOrderSteps(order *model.Order) []saga.Step {
+	var reservations []*client.Reservation
+	var steps []saga.Step
+
+	if s.reservations != nil {
+		steps = append(steps, saga.Step{
+			Name: StepReserveStock,
+			Action: func(ctx context.Context) (err error) {
+				reservations, err = s.reserveStock(ctx, order)
+				order.ReservationIDs = reservationIDs(reservations)
+				return err
+			},
+			Compensate: func(ctx context.Context) error {
+				return s.releaseStock(ctx, order.ID, order.ReservationIDs)
+			},
+		})
+	}
+
+	steps = append(steps,
+		saga.Step{
+			Name: StepValidateCustomer,
+			Action: func(ctx context.Context) error {
+				return s.validateCustomer(ctx, order)
+			},
+		},
+		saga.Step{
+			Name: StepPersistOrder,
+			Action: func(ctx context.Context) error {
+				return s.persistOrder(ctx, order)
+			},
+			Compensate: func(ctx context.Context) error {
+				if err := s.repo.Delete(ctx, order.ID); err != nil && !errors.Is(err, model.ErrOrderNotFound) {
+					return err
+				}
+				return nil
+			},
+		},
+	)
+
+	if s.reservations != nil {
+		steps = append(steps, saga.Step{
+			Name: StepConfirmStock,
+			Action: func(ctx context.Context) error {
+				return s.confirmStock(ctx, order.ID, reservations)
+			},
+		})
+	}
+
+	return steps
+}
+
+// reserveStock reserves the ordered quantity of every product. Should one
+// product fail, the stock already reserved is released again, so the step
+// either holds all of the stock or none of it.
+func (s *orderService) reserveStock(ctx context.Context, order *model.Order) ([]*client.Reservation, error) {
+	productIDs, quantities := countProducts(order.ProductIDs)
+
+	reservations := make([]*client.Reservation, 0, len(productIDs))
+	for _, productID := range productIDs {
+		reservation, err := s.reservations.ReserveStock(ctx, productID, order.ID, quantities[productID])
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+				"order_id":   order.ID,
+				"product_id": productID,
+				"quantity":   quantities[productID],
+			}).Error("Failed to reserve product stock")
+
+			if releaseErr := s.releaseStock(context.WithoutCancel(ctx), order.ID, reservationIDs(reservations)); releaseErr != nil {
+				log.Ctx(ctx).WithError(releaseErr).WithField("order_id", order.ID).Error("Failed to release stock of failed reservation")
+			}
+			return nil, reservationError(err)
+		}
+
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, nil
+}
+
+// releaseStock gives the stock of the reservations back. Reservations that
+// no longer hold stock, e.g. because they expired, need no release.
+func (s *orderService) releaseStock(ctx context.Context, orderID string, ids []string) error {
+	var errs []error
+	for _, id := range ids {
+		_, err := s.reservations.ReleaseReservation(ctx, id)
+		if err != nil && !errors.Is(err, client.ErrNotFound) && !errors.Is(err, client.ErrConflict) {
+			errs = append(errs, fmt.Errorf("failed to release reservation %s: %w", id, err))
+		}
+	}
+
+	if len(errs) == 0 && len(ids) > 0 {
+		log.Ctx(ctx).WithFields(logger.Fields{
+			"order_id":     orderID,
+			"reservations": len(ids),
+		}).Info("Released order stock")
+	}
+	return errors.Join(errs...)
+}
+
+// confirmStock keeps the reserved stock for the persisted order, so the
+// reservations no longer expire
+func (s *orderService) confirmStock(ctx context.Context, orderID string, reservations []*client.Reservation) error {
+	for _, reservation := range reservations {
+		if _, err := s.reservations.ConfirmReservation(ctx, reservation.ID); err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+				"order_id":       orderID,
+				"reservation_id": reservation.ID,
+			}).Error("Failed to confirm stock reservation")
+			return reservationError(err)
+		}
+	}
+	return nil
+}
+
+// validateCustomer enriches the order with its customer, which must be able
+// to place orders
+func (s *orderService) validateCustomer(ctx context.Context, order *model.Order) error {
+	customer, err := s.enrichCustomer(ctx, order.CustomerID)
+	if err != nil {
+		if !s.canDefer(err) {
+			return err
+		}
+		markMissing(order, model.EnrichCustomer)
+		return nil
+	}
+
+	order.Customer = customer
+	return nil
+}
+
+// persistOrder enriches the order with its products and their tax, quotes
+// the shipping of a fully enriched order and snapshots it, and stores it
+func (s *orderService) persistOrder(ctx context.Context, order *model.Order) error {
+	products, err := s.enrichProducts(ctx, order)
+	if err != nil {
+		if !s.canDefer(err) {
+			return err
+		}
+		markMissing(order, model.EnrichProducts)
+	}
+
+	order.Products = products
+
+	if err := s.applyTax(ctx, order); err != nil {
+		return err
+	}
+	if order.Total, err = order.CalculateTotal(); err != nil {
+		return err
+	}
+
+	// A fully enriched order is placed and enriched at once
+	actor := auth.Actor(ctx)
+	order.CreatedAt = time.Now().UTC()
+	order.Status = ""
+	order.History = nil
+	_ = order.Transition(model.StatusCreated, actor, "", order.CreatedAt)
+	if order.Enrichment == nil {
+		_ = order.Transition(model.StatusEnriched, actor, "", order.CreatedAt)
+		order.ShippingOptions = s.shippingOptions(ctx, order)
+		s.snapshotOrder(ctx, order)
+	} else {
+		// The first attempt to complete the order is made after the
+		// initial backoff
+		next := order.CreatedAt.Add(s.enrichment.Retry.InitialBackoff)
+		order.Enrichment.NextAttemptAt = &next
+	}
+
+	created, err := s.repo.Create(ctx, order)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", order.ID).Error("Failed to persist order")
+		return err
+	}
+	// The repository keeps its own copy; the response needs the creation time
+	order.CreatedAt = created.CreatedAt
+	return nil
+}
+
+// applyTax charges the order the tax of its products. The tax is deferred
+// with products still missing, or while the customer service or the tax
+// provider is unavailable.
+func (s *orderService) applyTax(ctx context.Context, order *model.Order) error {
+	if s.taxes == nil {
+		return nil
+	}
+	if order.Enrichment != nil && slices.Contains(order.Enrichment.Missing, model.EnrichProducts) {
+		markMissing(order, model.EnrichTax)
+		return nil
+	}
+
+	lines, err := s.taxOrder(ctx, order)
+	if err != nil {
+		if !s.canDefer(err) {
+			return err
+		}
+		markMissing(order, model.EnrichTax)
+		return nil
+	}
+
+	order.TaxLines = lines
+	order.Tax, err = order.CalculateTax()
+	return err
+}
+
+// markMissing records a part of the order that could not be enriched
+func markMissing(order *model.Order, part string) {
+	if order.Enrichment == nil {
+		order.Enrichment = &model.Enrichment{Status: model.EnrichmentPartial}
+	}
+	order.Enrichment.Missing = append(order.Enrichment.Missing, part)
+}
+
+// reservationError maps a reservation client error to an order error
+func reservationError(err error) error {
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		return model.ErrProductNotFound
+	case errors.Is(err, client.ErrConflict):
+		return model.ErrStockNotReserved
+	default:
+		return model.ErrProductsUnavailable
+	}
+}
+
+// reservationIDs returns the IDs of the reservations
+func reservationIDs(reservations []*client.Reservation) []string {
+	ids := make([]string, len(reservations))
+	for i, reservation := range reservations {
+		ids[i] = reservation.ID
+	}
+	return ids
+}
+
+// countProducts returns the distinct product IDs in order of first appearance
+// and how many times each was ordered
+func countProducts(productIDs []string) ([]string, map[string]int) {
+	quantities := make(map[string]int, len(productIDs))
+	distinct := make([]string, 0, len(productIDs))
+	for _, id := range productIDs {
+		if quantities[id] == 0 {
+			distinct = append(distinct, id)
+		}
+		quantities[id]++
+	}
+	return distinct, quantities
+}
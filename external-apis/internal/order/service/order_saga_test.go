@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/saga"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReservationClient is a mock implementation of ReservationClient
+type MockReservationClient struct {
+	mock.Mock
+}
+
+func (m *MockReservationClient) ReserveStock(ctx context.Context, productID, orderID string, quantity int) (*client.Reservation, error) {
+	args := m.Called(productID, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*client.Reservation), args.Error(1)
+}
+
+func (m *MockReservationClient) ConfirmReservation(ctx context.Context, id string) (*client.Reservation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*client.Reservation), args.Error(1)
+}
+
+func (m *MockReservationClient) ReleaseReservation(ctx context.Context, id string) (*client.Reservation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*client.Reservation), args.Error(1)
+}
+
+func TestOrderService_CreateOrder_Saga(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001", "product-002", "product-001"},
+	}
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+		"product-002": {ID: "product-002", Name: "Mechanical Keyboard", Price: money.New(12999, "USD"), Active: true},
+	}
+	reservation := func(id string) *client.Reservation {
+		return &client.Reservation{ID: id}
+	}
+
+	type fixture struct {
+		repo         *MockOrderRepository
+		customers    *MockCustomerClient
+		products     *MockProductClient
+		reservations *MockReservationClient
+		sagas        *saga.MemoryStore
+		service      OrderService
+	}
+	newFixture := func() fixture {
+		f := fixture{
+			repo:         new(MockOrderRepository),
+			customers:    new(MockCustomerClient),
+			products:     new(MockProductClient),
+			reservations: new(MockReservationClient),
+			sagas:        saga.NewMemoryStore(0),
+		}
+		f.service = NewOrderService(f.repo, f.customers, f.products, Options{
+			Reservations: f.reservations,
+			Sagas:        saga.NewCoordinator(f.sagas),
+		})
+		return f
+	}
+
+	t.Run("Reserve and confirm the stock of the order", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.reservations.On("ReserveStock", "product-001", 2).Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReserveStock", "product-002", 1).Return(reservation("reservation-2"), nil)
+		f.customers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		f.products.On("GetProducts", request.ProductIDs).Return(products, nil)
+		f.repo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+		f.reservations.On("ConfirmReservation", "reservation-1").Return(reservation("reservation-1"), nil)
+		f.reservations.On("ConfirmReservation", "reservation-2").Return(reservation("reservation-2"), nil)
+
+		// Act
+		result, err := f.service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("189.97"), result.Total)
+		f.reservations.AssertExpectations(t)
+		f.reservations.AssertNotCalled(t, "ReleaseReservation", mock.Anything)
+		f.repo.AssertCalled(t, "Create", mock.MatchedBy(func(order *model.Order) bool {
+			return slices.Equal(order.ReservationIDs, []string{"reservation-1", "reservation-2"})
+		}))
+
+		orderSaga, err := f.service.GetOrderSaga(context.Background(), result.ID)
+		require.NoError(t, err)
+		assert.Equal(t, saga.StatusCompleted, orderSaga.Status)
+		assert.Equal(t, []string{StepReserveStock, StepValidateCustomer, StepPersistOrder, StepConfirmStock}, stepNames(orderSaga))
+	})
+
+	t.Run("Release the reservations when the customer is invalid", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.reservations.On("ReserveStock", "product-001", 2).Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReserveStock", "product-002", 1).Return(reservation("reservation-2"), nil)
+		f.customers.On("GetCustomer", "customer-456").Return(nil, client.ErrNotFound)
+		f.reservations.On("ReleaseReservation", "reservation-1").Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReleaseReservation", "reservation-2").Return(reservation("reservation-2"), nil)
+
+		// Act
+		_, err := f.service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		f.reservations.AssertExpectations(t)
+		f.repo.AssertNotCalled(t, "Create", mock.Anything)
+
+		sagas, err := f.sagas.List(saga.Filter{})
+		require.NoError(t, err)
+		require.Len(t, sagas, 1)
+		assert.Equal(t, saga.StatusCompensated, sagas[0].Status)
+		assert.Equal(t, saga.StepCompensated, sagas[0].Steps[0].Status)
+		assert.Equal(t, saga.StepFailed, sagas[0].Steps[1].Status)
+	})
+
+	t.Run("Release what was reserved when a product lacks stock", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.reservations.On("ReserveStock", "product-001", 2).Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReserveStock", "product-002", 1).Return(nil, client.ErrConflict)
+		f.reservations.On("ReleaseReservation", "reservation-1").Return(reservation("reservation-1"), nil)
+
+		// Act
+		_, err := f.service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrStockNotReserved)
+		f.reservations.AssertExpectations(t)
+		f.customers.AssertNotCalled(t, "GetCustomer", mock.Anything)
+	})
+
+	t.Run("Undo the order when the stock cannot be confirmed", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.reservations.On("ReserveStock", "product-001", 2).Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReserveStock", "product-002", 1).Return(reservation("reservation-2"), nil)
+		f.customers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		f.products.On("GetProducts", request.ProductIDs).Return(products, nil)
+		f.repo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+		f.reservations.On("ConfirmReservation", "reservation-1").Return(nil, client.ErrConflict)
+		f.repo.On("Delete", mock.AnythingOfType("string")).Return(nil)
+		f.reservations.On("ReleaseReservation", "reservation-1").Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReleaseReservation", "reservation-2").Return(reservation("reservation-2"), nil)
+
+		// Act
+		_, err := f.service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrStockNotReserved)
+		f.repo.AssertCalled(t, "Delete", mock.AnythingOfType("string"))
+		f.reservations.AssertExpectations(t)
+	})
+
+	t.Run("Record compensations that failed", func(t *testing.T) {
+		// Arrange
+		f := newFixture()
+		f.reservations.On("ReserveStock", "product-001", 2).Return(reservation("reservation-1"), nil)
+		f.reservations.On("ReserveStock", "product-002", 1).Return(reservation("reservation-2"), nil)
+		f.customers.On("GetCustomer", "customer-456").Return(nil, client.ErrNotFound)
+		f.reservations.On("ReleaseReservation", "reservation-1").Return(nil, client.ErrUnavailable)
+		f.reservations.On("ReleaseReservation", "reservation-2").Return(nil, client.ErrConflict)
+
+		// Act
+		_, err := f.service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+
+		sagas, err := f.sagas.List(saga.Filter{Status: saga.StatusFailed})
+		require.NoError(t, err)
+		require.Len(t, sagas, 1)
+		assert.Equal(t, saga.StepCompensationFailed, sagas[0].Steps[0].Status)
+		assert.Contains(t, sagas[0].Steps[0].Error, "reservation-1")
+	})
+}
+
+func TestOrderService_GetOrderSaga(t *testing.T) {
+	// Arrange
+	service := NewOrderService(new(MockOrderRepository), nil, nil, Options{})
+
+	// Act
+	_, err := service.GetOrderSaga(context.Background(), "missing")
+
+	// Assert
+	assert.ErrorIs(t, err, model.ErrSagaNotFound)
+}
+
+// stepNames returns the names of the steps of a saga
+func stepNames(s *saga.Saga) []string {
+	names := make([]string, len(s.Steps))
+	for i, step := range s.Steps {
+		names[i] = step.Name
+	}
+	return names
+}
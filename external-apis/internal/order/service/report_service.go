@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/report"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/shared/logger"
+)
+
+// ReportService defines the interface for the daily sales reports
+type ReportService interface {
+	GetSalesReport(ctx context.Context, query model.SalesReportQuery) (*model.SalesReport, error)
+	ExportSalesReport(ctx context.Context, query model.SalesReportQuery, fn func(*model.SalesReportRow) error) error
+	RefreshSalesReport(ctx context.Context, from, to time.Time) (int, error)
+}
+
+// reportService implements ReportService
+type reportService struct {
+	orders  repository.OrderRepository
+	reports repository.SalesReportRepository
+	now     func() time.Time
+}
+
+// NewReportService creates a new report service aggregating the orders of
+// orders into the tables of reports. Reports are read from the tables, which
+// RefreshSalesReport keeps up to date.
+func NewReportService(orders repository.OrderRepository, reports repository.SalesReportRepository) ReportService {
+	return &reportService{
+		orders:  orders,
+		reports: reports,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetSalesReport retrieves the daily sales of a dimension with their totals
+func (s *reportService) GetSalesReport(ctx context.Context, query model.SalesReportQuery) (*model.SalesReport, error) {
+	rows, err := s.reports.Query(ctx, query)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("group_by", query.GroupBy).Error("Failed to query sales report")
+		return nil, err
+	}
+
+	sales, err := model.NewSalesReport(query, rows, s.reports.RefreshedAt(ctx))
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("group_by", query.GroupBy).Error("Failed to total sales report")
+		return nil, err
+	}
+	return sales, nil
+}
+
+// ExportSalesReport streams the daily sales of a dimension to fn, row by
+// row, stopping at the first error fn returns
+func (s *reportService) ExportSalesReport(ctx context.Context, query model.SalesReportQuery, fn func(*model.SalesReportRow) error) error {
+	rows, err := s.reports.Query(ctx, query)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("group_by", query.GroupBy).Error("Failed to query sales report")
+		return err
+	}
+
+	for i := range rows {
+		if err := fn(&rows[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RefreshSalesReport aggregates the orders placed from one day to another,
+// both included, into the report tables, replacing the rows of those days,
+// and returns the number of rows stored. Orders changed since their day was
+// last aggregated, e.g. cancelled, are accounted for once it is aggregated
+// again.
+func (s *reportService) RefreshSalesReport(ctx context.Context, from, to time.Time) (int, error) {
+	from, to = model.ReportDay(from), model.ReportDay(to)
+	fields := logger.Fields{
+		"from": from.Format(model.ReportDateLayout),
+		"to":   to.Format(model.ReportDateLayout),
+	}
+
+	// The customer segments depend on the orders before the days, so every
+	// order is read
+	orders, err := s.orders.GetAll(ctx, model.SortByCreatedAt)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to read orders for sales report")
+		return 0, err
+	}
+
+	rows, err := report.Aggregate(orders, from, to)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to aggregate sales report")
+		return 0, err
+	}
+	if err := s.reports.Replace(ctx, from, to, rows, s.now()); err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to store sales report")
+		return 0, err
+	}
+
+	fields["rows"] = len(rows)
+	log.Ctx(ctx).WithFields(fields).Debug("Refreshed sales report")
+	return len(rows), nil
+}
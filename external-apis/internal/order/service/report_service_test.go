@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportService(t *testing.T) {
+	ctx := context.Background()
+	jan1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jan2 := jan1.AddDate(0, 0, 1)
+	refreshedAt := time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC)
+	orders := []*model.Order{
+		{
+			ID:         "order-1",
+			CustomerID: "customer-456",
+			CreatedAt:  jan1.Add(10 * time.Hour),
+			Snapshot: &model.OrderSnapshot{
+				LineItems: []model.LineItem{{ProductID: "product-001", Name: "Wireless Mouse", Category: "Electronics", Quantity: 2, UnitPrice: money.New(2500, "USD"), Tax: money.New(500, "USD"), Total: money.New(5500, "USD")}},
+				Subtotal:  money.New(5000, "USD"), Tax: money.New(500, "USD"), Total: money.New(5500, "USD"),
+			},
+		},
+		{
+			ID:         "order-2",
+			CustomerID: "customer-789",
+			CreatedAt:  jan2.Add(9 * time.Hour),
+			Snapshot: &model.OrderSnapshot{
+				LineItems: []model.LineItem{{ProductID: "product-002", Name: "USB Cable", Quantity: 1, UnitPrice: money.New(1000, "USD"), Tax: money.New(100, "USD"), Total: money.New(1100, "USD")}},
+				Subtotal:  money.New(1000, "USD"), Tax: money.New(100, "USD"), Total: money.New(1100, "USD"),
+			},
+		},
+	}
+	newService := func(mockRepo *MockOrderRepository, reports repository.SalesReportRepository) *reportService {
+		service := NewReportService(mockRepo, reports).(*reportService)
+		service.now = func() time.Time { return refreshedAt }
+		return service
+	}
+
+	t.Run("Refresh and read the report", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := newService(mockRepo, repository.NewMemorySalesReportRepository())
+		mockRepo.On("GetAll", model.SortByCreatedAt).Return(orders, nil)
+
+		// Act
+		stored, err := service.RefreshSalesReport(ctx, jan1.Add(15*time.Hour), jan2)
+		require.NoError(t, err)
+		report, err := service.GetSalesReport(ctx, model.SalesReportQuery{From: jan1, To: jan2, GroupBy: model.GroupByProduct})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 6, stored, "one row per day of every dimension")
+		require.Len(t, report.Rows, 2)
+		assert.Equal(t, "product-001", report.Rows[0].Key)
+		assert.Equal(t, "product-002", report.Rows[1].Key)
+		assert.Equal(t, []model.SalesTotals{{Currency: "USD", Units: 3, Revenue: "60.00", Tax: "6.00", Total: "66.00"}}, report.Totals)
+		assert.Equal(t, &refreshedAt, report.RefreshedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refreshing leaves the other days alone", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := newService(mockRepo, repository.NewMemorySalesReportRepository())
+		mockRepo.On("GetAll", model.SortByCreatedAt).Return(orders, nil)
+		_, err := service.RefreshSalesReport(ctx, jan1, jan2)
+		require.NoError(t, err)
+
+		// Act
+		stored, err := service.RefreshSalesReport(ctx, jan2, jan2)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, stored)
+		report, err := service.GetSalesReport(ctx, model.SalesReportQuery{From: jan1, To: jan2, GroupBy: model.GroupByCategory})
+		require.NoError(t, err)
+		require.Len(t, report.Rows, 2)
+		assert.Equal(t, "electronics", report.Rows[0].Key)
+		assert.Equal(t, model.Uncategorized, report.Rows[1].Key)
+	})
+
+	t.Run("Orders unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		reports := repository.NewMemorySalesReportRepository()
+		service := newService(mockRepo, reports)
+		mockRepo.On("GetAll", model.SortByCreatedAt).Return([]*model.Order(nil), errors.New("database error"))
+
+		// Act
+		stored, err := service.RefreshSalesReport(ctx, jan1, jan2)
+
+		// Assert
+		require.Error(t, err)
+		assert.Zero(t, stored)
+		assert.Nil(t, reports.RefreshedAt(ctx), "the tables are left as they were")
+	})
+
+	t.Run("Export stops at the first error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := newService(mockRepo, repository.NewMemorySalesReportRepository())
+		mockRepo.On("GetAll", model.SortByCreatedAt).Return(orders, nil)
+		_, err := service.RefreshSalesReport(ctx, jan1, jan2)
+		require.NoError(t, err)
+		writeErr := errors.New("client gone")
+		var written []string
+
+		// Act
+		err = service.ExportSalesReport(ctx, model.SalesReportQuery{From: jan1, To: jan2, GroupBy: model.GroupByProduct}, func(row *model.SalesReportRow) error {
+			written = append(written, row.Key)
+			return writeErr
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, writeErr)
+		assert.Equal(t, []string{"product-001"}, written)
+	})
+}
@@ -0,0 +1,514 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/payment"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/order/saga"
+	"external-apis/internal/order/shipping"
+	"external-apis/internal/order/tax"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+)
+
+var log = logger.New("order/service")
+
+// OrderService defines the interface for order business logic
+type OrderService interface {
+	GetOrderByID(ctx context.Context, id string) (*model.OrderResponse, error)
+	GetAllOrders(ctx context.Context, sort string) ([]*model.OrderResponse, error)
+	GetOrdersByCustomerID(ctx context.Context, customerID string) ([]*model.OrderResponse, error)
+	CreateOrder(ctx context.Context, req model.CreateOrderRequest) (*model.OrderResponse, error)
+	GetOrderSaga(ctx context.Context, id string) (*saga.Saga, error)
+	DeleteOrder(ctx context.Context, id string) error
+	ReassignCustomer(ctx context.Context, req model.ReassignCustomerRequest) (*model.ReassignCustomerResponse, error)
+	TransitionOrder(ctx context.Context, id string, to model.OrderStatus, req model.TransitionRequest) (*model.OrderResponse, error)
+	CancelOrder(ctx context.Context, id string, req model.TransitionRequest) (*model.OrderResponse, error)
+	GetOrderHistory(ctx context.Context, id string) ([]model.StatusTransition, error)
+	QuoteShipping(ctx context.Context, req model.ShippingRatesRequest) ([]model.ShippingRate, error)
+	ReEnrichOrder(ctx context.Context, id string) (*model.OrderResponse, error)
+	RetryStaleEnrichments(ctx context.Context) (int, error)
+}
+
+// Options configures the optional collaborators of the order service
+type Options struct {
+	// Reservations holds product stock for new orders; stock is not
+	// reserved while it is nil
+	Reservations client.ReservationClient
+	// Quotes prices the products of new orders with the promotions that
+	// apply; orders are placed at list prices while it is nil
+	Quotes client.QuoteClient
+	// Taxes works out the tax of new orders from the address of the
+	// customer, found through Addresses; orders are not taxed while it is nil
+	Taxes     tax.TaxCalculator
+	Addresses client.AddressClient
+	// Shipping quotes the shipping options of enriched orders to the
+	// address of the customer, found through Addresses; no shipping is
+	// quoted while it is nil
+	Shipping shipping.ShippingCalculator
+	// Sagas runs order creation; sagas are only kept in memory while it is nil
+	Sagas *saga.Coordinator
+	// Enrichment configures accepting orders while the customer or product
+	// service is unavailable
+	Enrichment EnrichmentOptions
+	// Loyalty takes back the loyalty points of cancelled orders; no points
+	// are reversed while it is nil
+	Loyalty client.LoyaltyClient
+	// Refunds pays back cancelled orders; refunds are left pending, to be
+	// paid out by hand, while it is nil
+	Refunds payment.PaymentRefunder
+	// IDs generates the IDs of new orders, which the creation saga needs
+	// before the order is stored; idgen.Default() is used while it is nil
+	IDs idgen.Generator
+	// RefundIDs generates the IDs of the refunds of cancelled orders;
+	// idgen.Default() is used while it is nil
+	RefundIDs idgen.Generator
+}
+
+// orderService implements OrderService
+type orderService struct {
+	repo         repository.OrderRepository
+	customers    client.CustomerClient
+	products     client.ProductClient
+	reservations client.ReservationClient
+	quotes       client.QuoteClient
+	taxes        tax.TaxCalculator
+	addresses    client.AddressClient
+	shipping     shipping.ShippingCalculator
+	sagas        *saga.Coordinator
+	enrichment   EnrichmentOptions
+	loyalty      client.LoyaltyClient
+	refunds      payment.PaymentRefunder
+	ids          idgen.Generator
+	refundIDs    idgen.Generator
+}
+
+// NewOrderService creates a new order service. When the enrichment options
+// name a job manager, the service registers its re-enrichment job with it, so
+// it must be created before the manager is started.
+func NewOrderService(repo repository.OrderRepository, customers client.CustomerClient, products client.ProductClient, options Options) OrderService {
+	if options.Sagas == nil {
+		options.Sagas = saga.NewCoordinator(saga.NewMemoryStore(DefaultSagaRetention))
+	}
+	if options.IDs == nil {
+		options.IDs = idgen.Default()
+	}
+	if options.RefundIDs == nil {
+		options.RefundIDs = idgen.Default()
+	}
+
+	s := &orderService{
+		repo:         repo,
+		customers:    customers,
+		products:     products,
+		reservations: options.Reservations,
+		quotes:       options.Quotes,
+		taxes:        options.Taxes,
+		addresses:    options.Addresses,
+		shipping:     options.Shipping,
+		sagas:        options.Sagas,
+		enrichment:   options.Enrichment,
+		loyalty:      options.Loyalty,
+		refunds:      options.Refunds,
+		ids:          options.IDs,
+		refundIDs:    options.RefundIDs,
+	}
+	if options.Enrichment.Jobs != nil {
+		options.Enrichment.Jobs.RegisterWithRetry(EnrichJobKind, s.handleEnrichJob, options.Enrichment.Retry)
+	}
+
+	return s
+}
+
+// GetOrderByID retrieves an order by ID
+func (s *orderService) GetOrderByID(ctx context.Context, id string) (*model.OrderResponse, error) {
+	log.Ctx(ctx).WithField("order_id", id).Debug("Getting order by ID")
+
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", id).Error("Failed to get order")
+		return nil, err
+	}
+
+	response := order.ToResponse()
+	log.Ctx(ctx).WithField("order_id", id).Debug("Successfully retrieved order")
+
+	return &response, nil
+}
+
+// GetAllOrders retrieves all orders in the requested sort order
+func (s *orderService) GetAllOrders(ctx context.Context, sort string) ([]*model.OrderResponse, error) {
+	log.Ctx(ctx).WithField("sort", sort).Debug("Getting all orders")
+
+	if !model.IsValidOrderSort(sort) {
+		return nil, model.ErrInvalidSort
+	}
+
+	orders, err := s.repo.GetAll(ctx, sort)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get all orders")
+		return nil, err
+	}
+
+	responses := toResponses(orders)
+	log.Ctx(ctx).WithField("count", len(responses)).Debug("Successfully retrieved all orders")
+	return responses, nil
+}
+
+// GetOrdersByCustomerID retrieves the orders placed by a customer
+func (s *orderService) GetOrdersByCustomerID(ctx context.Context, customerID string) ([]*model.OrderResponse, error) {
+	log.Ctx(ctx).WithField("customer_id", customerID).Debug("Getting orders by customer")
+
+	orders, err := s.repo.GetByCustomerID(ctx, customerID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customerID).Error("Failed to get orders by customer")
+		return nil, err
+	}
+
+	return toResponses(orders), nil
+}
+
+// CreateOrder places an order through the create-order saga: it reserves the
+// stock of the products, validates the customer, persists the order enriched
+// with customer and product data and confirms the reservations, undoing the
+// completed steps when a later one fails. With the enrichment fallback, an
+// unavailable customer or product service does not fail the order: it is
+// accepted partially enriched and completed in the background.
+func (s *orderService) CreateOrder(ctx context.Context, req model.CreateOrderRequest) (*model.OrderResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"customer_id": req.CustomerID,
+		"products":    len(req.ProductIDs),
+	}).Debug("Creating new order")
+
+	order := &model.Order{
+		ID:         s.ids.New(),
+		CustomerID: req.CustomerID,
+		ProductIDs: req.ProductIDs,
+		CouponCode: strings.ToUpper(strings.TrimSpace(req.CouponCode)),
+	}
+	if err := s.sagas.Run(ctx, order.ID, CreateOrderSaga, s.createOrderSteps(order)); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", order.ID).Error("Failed to create order")
+		return nil, err
+	}
+
+	if order.Enrichment != nil {
+		s.scheduleEnrichment(ctx, order)
+	}
+
+	// The customer and products were fetched just now, so the new order comes
+	// back expanded
+	response := order.ToResponse()
+	response.Customer = order.Customer
+	response.Products = order.Products
+	log.Ctx(ctx).WithField("order_id", order.ID).Info("Successfully created order")
+
+	return &response, nil
+}
+
+// GetOrderSaga retrieves the create-order saga of an order, which explains
+// where the creation of an order stopped
+func (s *orderService) GetOrderSaga(ctx context.Context, id string) (*saga.Saga, error) {
+	orderSaga, err := s.sagas.Store().Get(tenant.FromContext(ctx), id)
+	if errors.Is(err, saga.ErrSagaNotFound) {
+		return nil, model.ErrSagaNotFound
+	}
+	return orderSaga, err
+}
+
+// DeleteOrder deletes an order
+func (s *orderService) DeleteOrder(ctx context.Context, id string) error {
+	log.Ctx(ctx).WithField("order_id", id).Debug("Deleting order")
+
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", id).Error("Failed to delete order")
+		return err
+	}
+
+	log.Ctx(ctx).WithField("order_id", id).Info("Successfully deleted order")
+	return nil
+}
+
+// ReassignCustomer moves the orders of a customer to another. The target must
+// exist; a merged customer's ID resolves to the customer it was merged into.
+func (s *orderService) ReassignCustomer(ctx context.Context, req model.ReassignCustomerRequest) (*model.ReassignCustomerResponse, error) {
+	fields := logger.Fields{
+		"from_customer_id": req.FromCustomerID,
+		"to_customer_id":   req.ToCustomerID,
+	}
+	log.Ctx(ctx).WithFields(fields).Debug("Reassigning customer orders")
+
+	customer, err := s.customers.GetCustomer(ctx, req.ToCustomerID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to get customer for reassignment")
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, model.ErrCustomerNotFound
+		}
+		return nil, model.ErrCustomersUnavailable
+	}
+
+	moved, err := s.repo.ReassignCustomer(ctx, req.FromCustomerID, customer.ID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to reassign customer orders")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithFields(fields).WithField("orders_moved", moved).Info("Successfully reassigned customer orders")
+	return &model.ReassignCustomerResponse{
+		FromCustomerID: req.FromCustomerID,
+		ToCustomerID:   customer.ID,
+		OrdersMoved:    moved,
+	}, nil
+}
+
+// TransitionOrder moves an order to another status along the allowed
+// transitions, recording the actor of ctx and the reason in its history.
+// ENRICHED is reached by the service itself, once the order is enriched;
+// cancellations go through CancelOrder.
+func (s *orderService) TransitionOrder(ctx context.Context, id string, to model.OrderStatus, req model.TransitionRequest) (*model.OrderResponse, error) {
+	fields := logger.Fields{
+		"order_id": id,
+		"status":   to,
+	}
+	log.Ctx(ctx).WithFields(fields).Debug("Changing order status")
+
+	switch to {
+	case model.StatusEnriched:
+		return nil, model.ErrInvalidStatusTransition
+	case model.StatusCancelled:
+		return s.CancelOrder(ctx, id, req)
+	}
+
+	order, err := s.repo.Transition(ctx, id, to, auth.Actor(ctx), req.Reason, time.Now().UTC())
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to change order status")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithFields(fields).Info("Successfully changed order status")
+	response := order.ToResponse()
+	return &response, nil
+}
+
+// GetOrderHistory retrieves the status transitions of an order, oldest first
+func (s *orderService) GetOrderHistory(ctx context.Context, id string) ([]model.StatusTransition, error) {
+	order, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := order.History
+	if history == nil {
+		history = make([]model.StatusTransition, 0)
+	}
+	return history, nil
+}
+
+// enrichCustomer fetches the customer and verifies it can place orders
+func (s *orderService) enrichCustomer(ctx context.Context, customerID string) (*model.OrderCustomer, error) {
+	customer, err := s.customers.GetCustomer(ctx, customerID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", customerID).Error("Failed to enrich order with customer")
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, model.ErrCustomerNotFound
+		}
+		return nil, model.ErrCustomersUnavailable
+	}
+
+	if !customer.Active {
+		return nil, model.ErrCustomerInactive
+	}
+
+	return customer.ToOrderCustomer(), nil
+}
+
+// enrichProducts fetches every distinct product of the order in one batch,
+// preserving request order, priced with the promotions that apply to the
+// customer and coupon code of the order. Products priced in different
+// currencies cannot be ordered together.
+func (s *orderService) enrichProducts(ctx context.Context, order *model.Order) ([]model.OrderProduct, error) {
+	productIDs := order.ProductIDs
+	found, err := s.products.GetProducts(ctx, productIDs)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_ids", productIDs).Error("Failed to enrich order with products")
+		return nil, model.ErrProductsUnavailable
+	}
+
+	quotes, err := s.quoteProducts(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]model.OrderProduct, 0, len(productIDs))
+	for _, id := range productIDs {
+		product, ok := found[id]
+		if !ok {
+			log.Ctx(ctx).WithField("product_id", id).Error("Failed to enrich order with product")
+			return nil, model.ErrProductNotFound
+		}
+
+		if !product.Active {
+			return nil, model.ErrProductInactive
+		}
+
+		orderProduct := product.ToOrderProduct()
+		if quote, ok := quotes[id]; ok && quote.Promotion != nil {
+			orderProduct.ListPrice = quote.ListPrice
+			orderProduct.Discount = quote.Discount
+			orderProduct.Price = quote.Price
+			orderProduct.PromotionID = quote.Promotion.ID
+		}
+		if len(products) > 0 && orderProduct.Price.Currency != products[0].Price.Currency {
+			log.Ctx(ctx).WithFields(logger.Fields{
+				"order_id":   order.ID,
+				"product_id": id,
+				"currency":   orderProduct.Price.Currency,
+			}).Warn("Order products are priced in different currencies")
+			return nil, model.ErrMixedCurrencies
+		}
+		products = append(products, orderProduct)
+	}
+
+	return products, nil
+}
+
+// quoteProducts prices the products of the order with the promotions that
+// apply, keyed by product ID. Without a quote client there are no quotes and
+// products keep their list prices.
+func (s *orderService) quoteProducts(ctx context.Context, order *model.Order) (map[string]*client.Quote, error) {
+	if s.quotes == nil {
+		return nil, nil
+	}
+
+	productIDs, _ := countProducts(order.ProductIDs)
+	quotes, err := s.quotes.QuoteProducts(ctx, productIDs, order.CustomerID, order.CouponCode)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"order_id":    order.ID,
+			"coupon_code": order.CouponCode,
+		}).Error("Failed to quote order products")
+		if errors.Is(err, client.ErrRejected) {
+			return nil, model.ErrInvalidCoupon
+		}
+		return nil, model.ErrProductsUnavailable
+	}
+	return quotes, nil
+}
+
+// taxOrder works out the tax lines of the enriched products of the order,
+// taxed where the customer's preferred address is. Orders of customers
+// without an address are not taxed.
+func (s *orderService) taxOrder(ctx context.Context, order *model.Order) ([]model.TaxLine, error) {
+	if s.taxes == nil || s.addresses == nil {
+		return nil, nil
+	}
+
+	address, err := s.resolveAddress(ctx, order)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("customer_id", order.CustomerID).Error("Failed to get customer addresses to tax order")
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, model.ErrCustomerNotFound
+		}
+		return nil, model.ErrCustomersUnavailable
+	}
+	if address == nil {
+		log.Ctx(ctx).WithFields(logger.Fields{
+			"order_id":    order.ID,
+			"customer_id": order.CustomerID,
+		}).Warn("Customer has no address, order is not taxed")
+		return nil, nil
+	}
+
+	subtotal, err := order.CalculateSubtotal()
+	if err != nil {
+		return nil, err
+	}
+
+	req := tax.Request{
+		OrderID:    order.ID,
+		CustomerID: order.CustomerID,
+		Currency:   subtotal.Currency,
+		Address: tax.Address{
+			Country:    address.Country,
+			Region:     address.Region,
+			PostalCode: address.PostalCode,
+			City:       address.City,
+		},
+		Items: make([]tax.Item, len(order.Products)),
+	}
+	for i, product := range order.Products {
+		req.Items[i] = tax.Item{ProductID: product.ID, Category: product.Category, Amount: product.Price}
+	}
+
+	lines, err := s.taxes.Calculate(ctx, req)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", order.ID).Error("Failed to tax order")
+		if errors.Is(err, tax.ErrRejected) {
+			return nil, model.ErrTaxRejected
+		}
+		return nil, model.ErrTaxUnavailable
+	}
+	return lines, nil
+}
+
+// resolveAddress returns the customer's preferred address the order is
+// taxed and shipped to, fetching it once per enrichment. It is nil when the
+// customer has no address, or addresses are not looked up.
+func (s *orderService) resolveAddress(ctx context.Context, order *model.Order) (*model.OrderAddress, error) {
+	if order.Address != nil || s.addresses == nil {
+		return order.Address, nil
+	}
+
+	addresses, err := s.addresses.GetAddresses(ctx, order.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	address := client.PreferredAddress(addresses)
+	if address == nil {
+		return nil, nil
+	}
+	order.Address = &model.OrderAddress{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+	return order.Address, nil
+}
+
+// snapshotOrder freezes a fully enriched order, with the address it ships
+// to when it can be found
+func (s *orderService) snapshotOrder(ctx context.Context, order *model.Order) {
+	if _, err := s.resolveAddress(ctx, order); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("order_id", order.ID).Warn("Failed to get customer address, order is snapshotted without it")
+	}
+	order.TakeSnapshot(time.Now().UTC())
+}
+
+// canDefer checks if an order can be accepted without the enrichment that
+// failed with err, to be completed once the downstream service is back
+func (s *orderService) canDefer(err error) bool {
+	return s.enrichment.Jobs != nil && errors.Is(err, apperror.ErrUnavailable)
+}
+
+// toResponses converts orders into API responses
+func toResponses(orders []*model.Order) []*model.OrderResponse {
+	responses := make([]*model.OrderResponse, len(orders))
+	for i, order := range orders {
+		response := order.ToResponse()
+		responses[i] = &response
+	}
+	return responses
+}
@@ -0,0 +1,883 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/repository"
+	"external-apis/internal/order/tax"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOrderRepository is a mock implementation of OrderRepository
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) GetByID(ctx context.Context, id string) (*model.Order, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetAll(ctx context.Context, sort string) ([]*model.Order, error) {
+	args := m.Called(sort)
+	return args.Get(0).([]*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetByCustomerID(ctx context.Context, customerID string) ([]*model.Order, error) {
+	args := m.Called(customerID)
+	return args.Get(0).([]*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetDueForEnrichment(ctx context.Context, at time.Time) ([]*model.Order, error) {
+	args := m.Called(at)
+	return args.Get(0).([]*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Create(ctx context.Context, order *model.Order) (*model.Order, error) {
+	args := m.Called(order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(*model.Order) *model.Order); ok {
+		return fn(order), args.Error(1)
+	}
+	return args.Get(0).(*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Update(ctx context.Context, id string, order *model.Order) (*model.Order, error) {
+	args := m.Called(id, order)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) ExistsByID(ctx context.Context, id string) bool {
+	args := m.Called(id)
+	return args.Bool(0)
+}
+
+func (m *MockOrderRepository) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (int, error) {
+	args := m.Called(fromCustomerID, toCustomerID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockOrderRepository) Transition(ctx context.Context, id string, to model.OrderStatus, actor, reason string, at time.Time) (*model.Order, error) {
+	args := m.Called(id, to, actor, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) SaveRefund(ctx context.Context, id string, refund *model.Refund) (*model.Order, error) {
+	args := m.Called(id, refund)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	if fn, ok := args.Get(0).(func(*model.Refund) *model.Order); ok {
+		return fn(refund), args.Error(1)
+	}
+	return args.Get(0).(*model.Order), args.Error(1)
+}
+
+// MockCustomerClient is a mock implementation of CustomerClient
+type MockCustomerClient struct {
+	mock.Mock
+}
+
+func (m *MockCustomerClient) GetCustomer(ctx context.Context, id string) (*client.Customer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*client.Customer), args.Error(1)
+}
+
+func (m *MockCustomerClient) GetCustomers(ctx context.Context, ids []string) (map[string]*client.Customer, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*client.Customer), args.Error(1)
+}
+
+// MockProductClient is a mock implementation of ProductClient
+type MockProductClient struct {
+	mock.Mock
+}
+
+func (m *MockProductClient) GetProduct(ctx context.Context, id string) (*client.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*client.Product), args.Error(1)
+}
+
+func (m *MockProductClient) GetProducts(ctx context.Context, ids []string) (map[string]*client.Product, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*client.Product), args.Error(1)
+}
+
+// MockQuoteClient is a mock implementation of QuoteClient
+type MockQuoteClient struct {
+	mock.Mock
+}
+
+func (m *MockQuoteClient) QuoteProducts(ctx context.Context, productIDs []string, customerID, couponCode string) (map[string]*client.Quote, error) {
+	args := m.Called(productIDs, customerID, couponCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*client.Quote), args.Error(1)
+}
+
+// MockAddressClient is a mock implementation of AddressClient
+type MockAddressClient struct {
+	mock.Mock
+}
+
+func (m *MockAddressClient) GetAddresses(ctx context.Context, customerID string) ([]*client.Address, error) {
+	args := m.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*client.Address), args.Error(1)
+}
+
+// MockTaxCalculator is a mock implementation of TaxCalculator
+type MockTaxCalculator struct {
+	mock.Mock
+}
+
+func (m *MockTaxCalculator) Calculate(ctx context.Context, req tax.Request) ([]model.TaxLine, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TaxLine), args.Error(1)
+}
+
+func activeCustomer() *client.Customer {
+	return &client.Customer{
+		ID:     "customer-456",
+		Name:   "John Doe",
+		Email:  "john.doe@example.com",
+		Phone:  "+15550123",
+		Active: true,
+		Status: "ACTIVE",
+	}
+}
+
+func TestOrderService_CreateOrder(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001", "product-002", "product-001"},
+	}
+
+	t.Run("Create enriched order", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{
+			"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+			"product-002": {ID: "product-002", Name: "Mechanical Keyboard", Price: money.New(12999, "USD"), Active: true},
+		}, nil).Once()
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			order.ID = "order-123"
+			return order
+		}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "order-123", result.ID)
+		assert.Equal(t, "John Doe", result.Customer.Name)
+		require.Len(t, result.Products, 3)
+		assert.Equal(t, "product-001", result.Products[0].ID)
+		assert.Equal(t, "product-002", result.Products[1].ID)
+		assert.Equal(t, "product-001", result.Products[2].ID)
+		assert.Equal(t, json.Number("189.97"), result.Total)
+		assert.Equal(t, model.StatusEnriched, result.Status, "a fully enriched order is enriched when placed")
+		mockRepo.AssertCalled(t, "Create", mock.MatchedBy(func(order *model.Order) bool {
+			return len(order.History) == 2 && order.History[0].To == model.StatusCreated &&
+				order.History[1].To == model.StatusEnriched && order.History[1].Actor == auth.AnonymousActor
+		}))
+		mockCustomers.AssertExpectations(t)
+		mockProducts.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Customer not found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrNotFound)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "customer not found", err.Error())
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Inactive customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		customer := activeCustomer()
+		customer.Active = false
+		mockCustomers.On("GetCustomer", "customer-456").Return(customer, nil)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.Equal(t, "customer is not active", err.Error())
+	})
+
+	t.Run("Product service unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(nil, client.ErrUnavailable)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.Equal(t, "product service unavailable", err.Error())
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Products priced in different currencies", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{
+			"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+			"product-002": {ID: "product-002", Name: "Mechanical Keyboard", Price: money.New(12999, "EUR"), Active: true},
+		}, nil)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrMixedCurrencies)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Inactive product", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{
+			"product-001": {ID: "product-001", Active: false},
+			"product-002": {ID: "product-002", Active: true},
+		}, nil)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.Equal(t, "product is not active", err.Error())
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{
+			"product-001": {ID: "product-001", Active: true},
+		}, nil)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestOrderService_CreateOrder_IDs(t *testing.T) {
+	// Arrange
+	ids := idgen.Prefixed("ord_", idgen.UUIDv7())
+	mockCustomers := new(MockCustomerClient)
+	mockProducts := new(MockProductClient)
+	service := NewOrderService(repository.NewMemoryOrderRepository(), mockCustomers, mockProducts, Options{IDs: ids})
+
+	mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+	mockProducts.On("GetProducts", []string{"product-001"}).Return(map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+	}, nil)
+
+	// Act
+	created, err := service.CreateOrder(context.Background(), model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001"},
+	})
+	require.NoError(t, err)
+	fetched, err := service.GetOrderByID(context.Background(), created.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ids.Valid(created.ID), "the order ID %q is not one of the configured generator", created.ID)
+	assert.Equal(t, created.ID, fetched.ID)
+}
+
+func TestOrderService_CreateOrder_Promotions(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001", "product-002", "product-001"},
+		CouponCode: " spring ",
+	}
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+		"product-002": {ID: "product-002", Name: "Mechanical Keyboard", Price: money.New(12999, "USD"), Active: true},
+	}
+
+	t.Run("Price products with their promotions", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		mockQuotes := new(MockQuoteClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Quotes: mockQuotes})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockQuotes.On("QuoteProducts", []string{"product-001", "product-002"}, "customer-456", "SPRING").Return(map[string]*client.Quote{
+			"product-001": {ProductID: "product-001", ListPrice: money.New(2999, "USD"), Discount: money.New(300, "USD"), Price: money.New(2699, "USD"), Promotion: &client.QuotePromotion{ID: "promotion-1"}},
+			"product-002": {ProductID: "product-002", ListPrice: money.New(12999, "USD"), Price: money.New(12999, "USD")},
+		}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "SPRING", result.CouponCode)
+		require.Len(t, result.Products, 3)
+		assert.Equal(t, money.New(2699, "USD"), result.Products[0].Price)
+		assert.Equal(t, money.New(2999, "USD"), result.Products[0].ListPrice)
+		assert.Equal(t, "promotion-1", result.Products[0].PromotionID)
+		assert.Equal(t, money.New(12999, "USD"), result.Products[1].Price)
+		assert.Empty(t, result.Products[1].PromotionID)
+		assert.Equal(t, json.Number("183.97"), result.Total)
+		mockQuotes.AssertExpectations(t)
+	})
+
+	t.Run("Coupon code rejected", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		mockQuotes := new(MockQuoteClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Quotes: mockQuotes})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockQuotes.On("QuoteProducts", mock.Anything, mock.Anything, mock.Anything).Return(nil, client.ErrRejected)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidCoupon)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Quotes unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		mockQuotes := new(MockQuoteClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Quotes: mockQuotes})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockQuotes.On("QuoteProducts", mock.Anything, mock.Anything, mock.Anything).Return(nil, client.ErrUnavailable)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductsUnavailable)
+	})
+}
+
+func TestOrderService_CreateOrder_Tax(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001", "product-002"},
+	}
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+		"product-002": {ID: "product-002", Name: "Mechanical Keyboard", Price: money.New(7001, "USD"), Category: "electronics", Active: true},
+	}
+	addresses := []*client.Address{
+		{ID: "address-1", Type: client.AddressTypeBilling, Country: "US", Region: "NY", IsDefault: true},
+		{ID: "address-2", Type: client.AddressTypeShipping, Country: "US", Region: "CA", PostalCode: "94103"},
+	}
+
+	newService := func(mockRepo *MockOrderRepository, mockAddresses *MockAddressClient, mockTaxes *MockTaxCalculator, options Options) OrderService {
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+		options.Taxes = mockTaxes
+		options.Addresses = mockAddresses
+		return NewOrderService(mockRepo, mockCustomers, mockProducts, options)
+	}
+
+	t.Run("Tax the order at the shipping address", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		service := newService(mockRepo, mockAddresses, mockTaxes, Options{})
+
+		mockAddresses.On("GetAddresses", "customer-456").Return(addresses, nil)
+		mockTaxes.On("Calculate", mock.MatchedBy(func(req tax.Request) bool {
+			return req.CustomerID == "customer-456" &&
+				req.Address == tax.Address{Country: "US", Region: "CA", PostalCode: "94103"} &&
+				req.Currency == "USD" && len(req.Items) == 2 && req.Items[1].Category == "electronics"
+		})).Return([]model.TaxLine{
+			{Jurisdiction: "US-CA", Rate: 7.25, Taxable: money.New(10000, "USD"), Amount: money.New(725, "USD")},
+		}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result.TaxLines, 1)
+		assert.Equal(t, json.Number("7.25"), result.Tax)
+		assert.Equal(t, json.Number("107.25"), result.Total)
+		mockTaxes.AssertExpectations(t)
+	})
+
+	t.Run("Customer without an address", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		service := newService(mockRepo, mockAddresses, mockTaxes, Options{})
+
+		mockAddresses.On("GetAddresses", "customer-456").Return([]*client.Address{}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, result.TaxLines)
+		assert.Zero(t, result.Tax)
+		assert.Equal(t, json.Number("100.00"), result.Total)
+		mockTaxes.AssertNotCalled(t, "Calculate", mock.Anything)
+	})
+
+	t.Run("Order rejected by the tax provider", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		service := newService(mockRepo, mockAddresses, mockTaxes, Options{Enrichment: newEnrichmentOptions()})
+
+		mockAddresses.On("GetAddresses", "customer-456").Return(addresses, nil)
+		mockTaxes.On("Calculate", mock.Anything).Return(nil, tax.ErrRejected)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrTaxRejected)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Tax provider unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		service := newService(mockRepo, mockAddresses, mockTaxes, Options{})
+
+		mockAddresses.On("GetAddresses", "customer-456").Return(addresses, nil)
+		mockTaxes.On("Calculate", mock.Anything).Return(nil, tax.ErrUnavailable)
+
+		// Act
+		_, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrTaxUnavailable)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Defer the tax while the provider is unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		options := newEnrichmentOptions()
+		service := newService(mockRepo, mockAddresses, mockTaxes, Options{Enrichment: options})
+
+		mockAddresses.On("GetAddresses", "customer-456").Return(addresses, nil)
+		mockTaxes.On("Calculate", mock.Anything).Return(nil, tax.ErrUnavailable)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, result.Tax)
+		assert.Equal(t, json.Number("100.00"), result.Total)
+		require.NotNil(t, result.Enrichment)
+		assert.Equal(t, []string{model.EnrichTax}, result.Enrichment.Missing)
+		assert.Equal(t, 1, options.Jobs.Pending())
+	})
+}
+
+func TestOrderService_CreateOrder_Snapshot(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001", "product-001"},
+	}
+
+	t.Run("Snapshot the enriched order with its address", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		mockAddresses := new(MockAddressClient)
+		mockTaxes := new(MockTaxCalculator)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Addresses: mockAddresses, Taxes: mockTaxes})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{
+			"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), Active: true},
+		}, nil)
+		mockAddresses.On("GetAddresses", "customer-456").Return([]*client.Address{
+			{ID: "address-1", Type: client.AddressTypeShipping, Line1: "1 Main St", City: "Berlin", Country: "DE", IsDefault: true},
+		}, nil).Once()
+		mockTaxes.On("Calculate", mock.Anything).Return([]model.TaxLine{{Jurisdiction: "DE", Rate: 19, Taxable: money.New(5998, "USD"), Amount: money.New(1140, "USD")}}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, result.Snapshot)
+		assert.Equal(t, "John Doe", result.Snapshot.Customer.Name)
+		assert.Equal(t, &model.OrderAddress{Line1: "1 Main St", City: "Berlin", Country: "DE"}, result.Snapshot.Customer.Address)
+		require.Len(t, result.Snapshot.LineItems, 1)
+		assert.Equal(t, 2, result.Snapshot.LineItems[0].Quantity)
+		assert.Equal(t, money.New(1140, "USD"), result.Snapshot.LineItems[0].Tax)
+		assert.Equal(t, money.New(7138, "USD"), result.Snapshot.Total)
+		mockAddresses.AssertExpectations(t)
+	})
+
+	t.Run("Partially enriched orders are not snapshotted", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(mockRepo, mockCustomers, mockProducts, Options{Enrichment: newEnrichmentOptions()})
+
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(nil, client.ErrUnavailable)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, result.Snapshot)
+	})
+}
+
+func TestOrderService_GetOrderByID(t *testing.T) {
+	t.Run("Get existing order", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+		mockRepo.On("GetByID", "order-123").Return(&model.Order{ID: "order-123", CustomerID: "customer-456"}, nil)
+
+		// Act
+		result, err := service.GetOrderByID(context.Background(), "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "customer-456", result.CustomerID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Get non-existing order", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+		mockRepo.On("GetByID", "non-existing").Return(nil, errors.New("order not found"))
+
+		// Act
+		result, err := service.GetOrderByID(context.Background(), "non-existing")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "order not found", err.Error())
+	})
+}
+
+func TestOrderService_GetAllOrders(t *testing.T) {
+	t.Run("Pass the sort order to the repository", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+		mockRepo.On("GetAll", model.SortByCreatedAtDesc).Return([]*model.Order{{ID: "order-2"}, {ID: "order-1"}}, nil)
+
+		// Act
+		result, err := service.GetAllOrders(context.Background(), model.SortByCreatedAtDesc)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "order-2", result[0].ID)
+	})
+
+	t.Run("Reject unknown sort orders", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+
+		// Act
+		result, err := service.GetAllOrders(context.Background(), "total")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrInvalidSort)
+		mockRepo.AssertNotCalled(t, "GetAll", mock.Anything)
+	})
+}
+
+func TestOrderService_DeleteOrder(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo, nil, nil, Options{})
+	mockRepo.On("Delete", "order-123").Return(nil)
+
+	// Act
+	err := service.DeleteOrder(context.Background(), "order-123")
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_TransitionOrder(t *testing.T) {
+	t.Run("Record the actor and reason", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+		ctx := auth.WithActor(context.Background(), "user:alice")
+		mockRepo.On("Transition", "order-123", model.StatusConfirmed, "user:alice", "paid by bank transfer").
+			Return(&model.Order{ID: "order-123", Status: model.StatusConfirmed}, nil)
+
+		// Act
+		result, err := service.TransitionOrder(ctx, "order-123", model.StatusConfirmed, model.TransitionRequest{Reason: "paid by bank transfer"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusConfirmed, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Orders are only enriched by the service", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+
+		// Act
+		result, err := service.TransitionOrder(context.Background(), "order-123", model.StatusEnriched, model.TransitionRequest{})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+		mockRepo.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Transition outside the graph", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		service := NewOrderService(mockRepo, nil, nil, Options{})
+		mockRepo.On("Transition", "order-123", model.StatusShipped, auth.AnonymousActor, "").Return(nil, model.ErrInvalidStatusTransition)
+
+		// Act
+		result, err := service.TransitionOrder(context.Background(), "order-123", model.StatusShipped, model.TransitionRequest{})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+	})
+}
+
+func TestOrderService_GetOrderHistory(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo, nil, nil, Options{})
+	history := []model.StatusTransition{
+		{To: model.StatusCreated, Actor: "user:alice"},
+		{From: model.StatusCreated, To: model.StatusEnriched, Actor: model.SystemActor},
+	}
+	mockRepo.On("GetByID", "order-123").Return(&model.Order{ID: "order-123", History: history}, nil)
+	mockRepo.On("GetByID", "order-456").Return(&model.Order{ID: "order-456"}, nil)
+	mockRepo.On("GetByID", "missing").Return(nil, model.ErrOrderNotFound)
+
+	t.Run("Every transition, oldest first", func(t *testing.T) {
+		// Act
+		result, err := service.GetOrderHistory(context.Background(), "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, history, result)
+	})
+
+	t.Run("Empty history", func(t *testing.T) {
+		// Act
+		result, err := service.GetOrderHistory(context.Background(), "order-456")
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotNil(t, result)
+		assert.Empty(t, result)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		// Act
+		_, err := service.GetOrderHistory(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrOrderNotFound)
+	})
+}
+
+func TestOrderService_ReassignCustomer(t *testing.T) {
+	request := model.ReassignCustomerRequest{FromCustomerID: "customer-001", ToCustomerID: "customer-456"}
+
+	t.Run("Move orders to the target customer", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{})
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockRepo.On("ReassignCustomer", "customer-001", "customer-456").Return(2, nil)
+
+		// Act
+		result, err := service.ReassignCustomer(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &model.ReassignCustomerResponse{
+			FromCustomerID: "customer-001",
+			ToCustomerID:   "customer-456",
+			OrdersMoved:    2,
+		}, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Target customer not found", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{})
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, client.ErrNotFound)
+
+		// Act
+		result, err := service.ReassignCustomer(context.Background(), request)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrCustomerNotFound)
+		mockRepo.AssertNotCalled(t, "ReassignCustomer", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Customer service unavailable", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		service := NewOrderService(mockRepo, mockCustomers, nil, Options{})
+		mockCustomers.On("GetCustomer", "customer-456").Return(nil, errors.New("connection refused"))
+
+		// Act
+		_, err := service.ReassignCustomer(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCustomersUnavailable)
+	})
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/shipping"
+	"external-apis/internal/shared/logger"
+)
+
+// QuoteShipping quotes the ways the products can be shipped to the address,
+// cheapest first. Without a shipping calculator there are none.
+func (s *orderService) QuoteShipping(ctx context.Context, req model.ShippingRatesRequest) ([]model.ShippingRate, error) {
+	log.Ctx(ctx).WithField("product_ids", req.ProductIDs).Debug("Quoting shipping")
+
+	if s.shipping == nil {
+		return []model.ShippingRate{}, nil
+	}
+
+	found, err := s.products.GetProducts(ctx, req.ProductIDs)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_ids", req.ProductIDs).Error("Failed to get products to quote shipping")
+		return nil, model.ErrProductsUnavailable
+	}
+
+	items := make([]shipping.Item, len(req.ProductIDs))
+	for i, id := range req.ProductIDs {
+		product, ok := found[id]
+		if !ok {
+			return nil, model.ErrProductNotFound
+		}
+		items[i] = shipping.Item{ProductID: id, WeightGrams: product.WeightGrams, Dimensions: product.Dimensions}
+	}
+
+	return s.quoteShipment(ctx, shipping.Shipment{Address: req.Address, Items: items})
+}
+
+// shippingOptions quotes the shipping of the enriched products of an order
+// to the customer's preferred address. Shipping options are a convenience:
+// when they cannot be quoted the order goes without them.
+func (s *orderService) shippingOptions(ctx context.Context, order *model.Order) []model.ShippingRate {
+	if s.shipping == nil || s.addresses == nil {
+		return nil
+	}
+
+	fields := logger.Fields{"order_id": order.ID, "customer_id": order.CustomerID}
+	address, err := s.resolveAddress(ctx, order)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Warn("Failed to get customer addresses to quote shipping, order has no shipping options")
+		return nil
+	}
+	if address == nil {
+		return nil
+	}
+
+	shipment := shipping.Shipment{
+		Address: model.ShippingAddress{
+			Country:    address.Country,
+			Region:     address.Region,
+			PostalCode: address.PostalCode,
+			City:       address.City,
+		},
+		Items: make([]shipping.Item, len(order.Products)),
+	}
+	for i, product := range order.Products {
+		shipment.Items[i] = shipping.Item{ProductID: product.ID, WeightGrams: product.WeightGrams, Dimensions: product.Dimensions}
+	}
+
+	rates, err := s.quoteShipment(ctx, shipment)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Warn("Order has no shipping options")
+		return nil
+	}
+	return rates
+}
+
+// quoteShipment asks the shipping calculator for the rates of a shipment
+func (s *orderService) quoteShipment(ctx context.Context, shipment shipping.Shipment) ([]model.ShippingRate, error) {
+	rates, err := s.shipping.Rates(ctx, shipment)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("country", shipment.Address.Country).Error("Failed to quote shipping")
+		if errors.Is(err, shipping.ErrUnavailable) {
+			return nil, model.ErrShippingUnavailable
+		}
+		return nil, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"external-apis/internal/order/client"
+	"external-apis/internal/order/model"
+	"external-apis/internal/order/shipping"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockShippingCalculator is a mock implementation of ShippingCalculator
+type MockShippingCalculator struct {
+	mock.Mock
+}
+
+func (m *MockShippingCalculator) Rates(ctx context.Context, shipment shipping.Shipment) ([]model.ShippingRate, error) {
+	args := m.Called(shipment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ShippingRate), args.Error(1)
+}
+
+func TestOrderService_QuoteShipping(t *testing.T) {
+	box := &model.Dimensions{LengthMM: 400, WidthMM: 300, HeightMM: 200}
+	products := map[string]*client.Product{
+		"product-001": {ID: "product-001", Name: "Wireless Mouse", WeightGrams: 120, Active: true},
+		"product-002": {ID: "product-002", Name: "Monitor", WeightGrams: 5200, Dimensions: box, Active: true},
+	}
+	request := model.ShippingRatesRequest{
+		ProductIDs: []string{"product-001", "product-002", "product-001"},
+		Address:    model.ShippingAddress{Country: "DE", PostalCode: "10115"},
+	}
+	rates := []model.ShippingRate{
+		{Carrier: "ups", ServiceLevel: "ground", Cost: money.New(1099, "USD"), EstimatedDays: 5},
+		{Carrier: "dhl", ServiceLevel: "express", Cost: money.New(2490, "USD"), EstimatedDays: 1},
+	}
+
+	t.Run("Quote every product at the address", func(t *testing.T) {
+		// Arrange
+		mockProducts := new(MockProductClient)
+		mockShipping := new(MockShippingCalculator)
+		service := NewOrderService(new(MockOrderRepository), nil, mockProducts, Options{Shipping: mockShipping})
+
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockShipping.On("Rates", shipping.Shipment{
+			Address: request.Address,
+			Items: []shipping.Item{
+				{ProductID: "product-001", WeightGrams: 120},
+				{ProductID: "product-002", WeightGrams: 5200, Dimensions: box},
+				{ProductID: "product-001", WeightGrams: 120},
+			},
+		}).Return(rates, nil)
+
+		// Act
+		result, err := service.QuoteShipping(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, rates, result)
+		mockShipping.AssertExpectations(t)
+	})
+
+	t.Run("No rates without a shipping calculator", func(t *testing.T) {
+		// Arrange
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(new(MockOrderRepository), nil, mockProducts, Options{})
+
+		// Act
+		result, err := service.QuoteShipping(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, result)
+		assert.NotNil(t, result)
+		mockProducts.AssertNotCalled(t, "GetProducts", mock.Anything)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		// Arrange
+		mockProducts := new(MockProductClient)
+		mockShipping := new(MockShippingCalculator)
+		service := NewOrderService(new(MockOrderRepository), nil, mockProducts, Options{Shipping: mockShipping})
+
+		mockProducts.On("GetProducts", []string{"product-999"}).Return(map[string]*client.Product{}, nil)
+
+		// Act
+		_, err := service.QuoteShipping(context.Background(), model.ShippingRatesRequest{
+			ProductIDs: []string{"product-999"},
+			Address:    request.Address,
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+		mockShipping.AssertNotCalled(t, "Rates", mock.Anything)
+	})
+
+	t.Run("Product service unavailable", func(t *testing.T) {
+		// Arrange
+		mockProducts := new(MockProductClient)
+		service := NewOrderService(new(MockOrderRepository), nil, mockProducts, Options{Shipping: new(MockShippingCalculator)})
+
+		mockProducts.On("GetProducts", request.ProductIDs).Return(nil, client.ErrUnavailable)
+
+		// Act
+		_, err := service.QuoteShipping(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductsUnavailable)
+	})
+
+	t.Run("Every carrier unavailable", func(t *testing.T) {
+		// Arrange
+		mockProducts := new(MockProductClient)
+		mockShipping := new(MockShippingCalculator)
+		service := NewOrderService(new(MockOrderRepository), nil, mockProducts, Options{Shipping: mockShipping})
+
+		mockProducts.On("GetProducts", request.ProductIDs).Return(products, nil)
+		mockShipping.On("Rates", mock.Anything).Return(nil, fmt.Errorf("carrier API down: %w", shipping.ErrUnavailable))
+
+		// Act
+		_, err := service.QuoteShipping(context.Background(), request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrShippingUnavailable)
+	})
+}
+
+func TestOrderService_CreateOrder_ShippingOptions(t *testing.T) {
+	request := model.CreateOrderRequest{
+		CustomerID: "customer-456",
+		ProductIDs: []string{"product-001"},
+	}
+	addresses := []*client.Address{
+		{ID: "address-1", Type: client.AddressTypeShipping, Country: "DE", City: "Berlin", IsDefault: true},
+	}
+
+	newService := func(mockAddresses *MockAddressClient, mockShipping *MockShippingCalculator) OrderService {
+		mockRepo := new(MockOrderRepository)
+		mockCustomers := new(MockCustomerClient)
+		mockProducts := new(MockProductClient)
+		mockCustomers.On("GetCustomer", "customer-456").Return(activeCustomer(), nil)
+		mockProducts.On("GetProducts", request.ProductIDs).Return(map[string]*client.Product{
+			"product-001": {ID: "product-001", Name: "Wireless Mouse", Price: money.New(2999, "USD"), WeightGrams: 120, Active: true},
+		}, nil)
+		mockRepo.On("Create", mock.AnythingOfType("*model.Order")).Return(func(order *model.Order) *model.Order {
+			return order
+		}, nil)
+		return NewOrderService(mockRepo, mockCustomers, mockProducts, Options{
+			Addresses: mockAddresses,
+			Shipping:  mockShipping,
+		})
+	}
+
+	t.Run("Quote shipping to the customer's address", func(t *testing.T) {
+		// Arrange
+		mockAddresses := new(MockAddressClient)
+		mockShipping := new(MockShippingCalculator)
+		service := newService(mockAddresses, mockShipping)
+
+		mockAddresses.On("GetAddresses", "customer-456").Return(addresses, nil)
+		mockShipping.On("Rates", shipping.Shipment{
+			Address: model.ShippingAddress{Country: "DE", City: "Berlin"},
+			Items:   []shipping.Item{{ProductID: "product-001", WeightGrams: 120}},
+		}).Return([]model.ShippingRate{{Carrier: "dhl", ServiceLevel: "standard", Cost: money.New(490, "USD"), EstimatedDays: 2}}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result.ShippingOptions, 1)
+		assert.Equal(t, "dhl", result.ShippingOptions[0].Carrier)
+		assert.Equal(t, json.Number("29.99"), result.Total, "shipping is a choice, not part of the total")
+	})
+
+	t.Run("Order without shipping options when they cannot be quoted", func(t *testing.T) {
+		// Arrange
+		mockAddresses := new(MockAddressClient)
+		mockShipping := new(MockShippingCalculator)
+		service := newService(mockAddresses, mockShipping)
+
+		mockAddresses.On("GetAddresses", "customer-456").Return(addresses, nil)
+		mockShipping.On("Rates", mock.Anything).Return(nil, shipping.ErrUnavailable)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusEnriched, result.Status)
+		assert.Empty(t, result.ShippingOptions)
+	})
+
+	t.Run("Customer without an address", func(t *testing.T) {
+		// Arrange
+		mockAddresses := new(MockAddressClient)
+		mockShipping := new(MockShippingCalculator)
+		service := newService(mockAddresses, mockShipping)
+
+		mockAddresses.On("GetAddresses", "customer-456").Return([]*client.Address{}, nil)
+
+		// Act
+		result, err := service.CreateOrder(context.Background(), request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, result.ShippingOptions)
+		mockShipping.AssertNotCalled(t, "Rates", mock.Anything)
+	})
+}
@@ -0,0 +1,112 @@
+// Package shipping quotes the ways orders can be shipped, merging the rates
+// of the carriers it is configured with.
+package shipping
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("order/shipping")
+
+// ErrUnavailable is returned when no carrier could quote a shipment
+var ErrUnavailable = errors.New("shipping rates unavailable")
+
+// volumetricDivisor is how many cubic centimetres ship as a kilogram. Bulky,
+// light parcels are charged at their volumetric weight.
+const volumetricDivisor = 5000
+
+// ShippingCalculator quotes the ways a shipment can be shipped, cheapest
+// first. Errors wrap ErrUnavailable.
+type ShippingCalculator interface {
+	Rates(ctx context.Context, shipment Shipment) ([]model.ShippingRate, error)
+}
+
+// Carrier is the adapter to a carrier, or a table of carrier rates. Quote
+// returns the service levels that ship the shipment; none when the carrier
+// does not serve its address.
+type Carrier interface {
+	Name() string
+	Quote(ctx context.Context, shipment Shipment) ([]model.ShippingRate, error)
+}
+
+// Shipment describes what is shipped and where
+type Shipment struct {
+	Address model.ShippingAddress
+	Items   []Item
+}
+
+// Item is a product of a shipment. Products without measurements weigh
+// nothing.
+type Item struct {
+	ProductID   string
+	WeightGrams int
+	Dimensions  *model.Dimensions
+}
+
+// BillableGrams sums what the items are charged as: the greater of their
+// actual and volumetric weight
+func (s Shipment) BillableGrams() int {
+	total := 0
+	for _, item := range s.Items {
+		grams := item.WeightGrams
+		if item.Dimensions != nil {
+			volumetric := int(math.Ceil(item.Dimensions.VolumeCM3() * 1000 / volumetricDivisor))
+			grams = max(grams, volumetric)
+		}
+		total += grams
+	}
+	return total
+}
+
+// Calculator quotes shipments with every carrier it is given
+type Calculator struct {
+	carriers []Carrier
+	now      func() time.Time
+}
+
+// NewCalculator creates a calculator merging the rates of carriers
+func NewCalculator(carriers ...Carrier) *Calculator {
+	return &Calculator{
+		carriers: carriers,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Rates asks every carrier for its rates and returns them by cost, then
+// speed, with their estimated delivery day. A carrier that fails is left
+// out; the shipment is unavailable only if all of them fail.
+func (c *Calculator) Rates(ctx context.Context, shipment Shipment) ([]model.ShippingRate, error) {
+	today := c.now().Truncate(24 * time.Hour)
+
+	rates := []model.ShippingRate{}
+	failed := 0
+	for _, carrier := range c.carriers {
+		quoted, err := carrier.Quote(ctx, shipment)
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithField("carrier", carrier.Name()).Warn("Failed to quote shipment")
+			failed++
+			continue
+		}
+		for _, rate := range quoted {
+			rate.EstimatedDelivery = today.AddDate(0, 0, rate.EstimatedDays)
+			rates = append(rates, rate)
+		}
+	}
+	if len(c.carriers) > 0 && failed == len(c.carriers) {
+		return nil, fmt.Errorf("every carrier failed to quote the shipment: %w", ErrUnavailable)
+	}
+
+	slices.SortStableFunc(rates, func(a, b model.ShippingRate) int {
+		return cmp.Or(a.Cost.Cmp(b.Cost), cmp.Compare(a.EstimatedDays, b.EstimatedDays))
+	})
+	return rates, nil
+}
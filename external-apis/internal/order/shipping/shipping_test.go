@@ -0,0 +1,102 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCarrier is a carrier returning fixed rates or an error
+type stubCarrier struct {
+	rates []model.ShippingRate
+	err   error
+}
+
+func (c stubCarrier) Name() string {
+	return "stub"
+}
+
+func (c stubCarrier) Quote(ctx context.Context, shipment Shipment) ([]model.ShippingRate, error) {
+	return c.rates, c.err
+}
+
+func TestCalculator_Rates(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+	newCalculator := func(carriers ...Carrier) *Calculator {
+		calculator := NewCalculator(carriers...)
+		calculator.now = func() time.Time { return now }
+		return calculator
+	}
+	shipment := Shipment{Address: model.ShippingAddress{Country: "DE"}}
+
+	t.Run("Merge carriers cheapest first", func(t *testing.T) {
+		// Arrange
+		calculator := newCalculator(
+			stubCarrier{rates: []model.ShippingRate{
+				{Carrier: "dhl", ServiceLevel: "express", Cost: money.New(1490, "EUR"), EstimatedDays: 1},
+				{Carrier: "dhl", ServiceLevel: "standard", Cost: money.New(590, "EUR"), EstimatedDays: 3},
+			}},
+			stubCarrier{rates: []model.ShippingRate{
+				{Carrier: "ups", ServiceLevel: "ground", Cost: money.New(590, "EUR"), EstimatedDays: 2},
+			}},
+		)
+
+		// Act
+		rates, err := calculator.Rates(context.Background(), shipment)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, rates, 3)
+		assert.Equal(t, "ups", rates[0].Carrier, "equal costs go by speed")
+		assert.Equal(t, "standard", rates[1].ServiceLevel)
+		assert.Equal(t, "express", rates[2].ServiceLevel)
+		assert.Equal(t, time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC), rates[0].EstimatedDelivery)
+		assert.Equal(t, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), rates[2].EstimatedDelivery)
+	})
+
+	t.Run("Leave out a failing carrier", func(t *testing.T) {
+		// Arrange
+		calculator := newCalculator(
+			stubCarrier{err: errors.New("carrier API down")},
+			stubCarrier{rates: []model.ShippingRate{{Carrier: "ups", ServiceLevel: "ground", Cost: money.New(590, "EUR"), EstimatedDays: 2}}},
+		)
+
+		// Act
+		rates, err := calculator.Rates(context.Background(), shipment)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, rates, 1)
+	})
+
+	t.Run("Unavailable when every carrier fails", func(t *testing.T) {
+		// Arrange
+		calculator := newCalculator(stubCarrier{err: errors.New("carrier API down")})
+
+		// Act
+		rates, err := calculator.Rates(context.Background(), shipment)
+
+		// Assert
+		assert.Nil(t, rates)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("No carrier serves the address", func(t *testing.T) {
+		// Arrange
+		calculator := newCalculator(stubCarrier{})
+
+		// Act
+		rates, err := calculator.Rates(context.Background(), shipment)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, rates)
+		assert.NotNil(t, rates)
+	})
+}
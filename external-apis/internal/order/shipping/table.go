@@ -0,0 +1,129 @@
+package shipping
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+)
+
+// Rate is a service level of a carrier in a rate table: a base cost plus a
+// cost per started kilogram of billable weight, delivered in Days. It ships
+// to Countries, or anywhere when there are none. The costs are in the same
+// currency.
+type Rate struct {
+	Carrier      string
+	ServiceLevel string
+	Base         money.Money
+	PerKg        money.Money
+	Days         int
+	Countries    []string
+}
+
+// Table is a carrier quoting from fixed rates, for carriers without an API
+type Table struct {
+	rates []Rate
+}
+
+// NewTable creates a carrier quoting rates
+func NewTable(rates []Rate) *Table {
+	return &Table{rates: rates}
+}
+
+// ParseRates parses "<carrier>/<level>=<base>+<per kg>/<days>[@<countries>]"
+// entries, such as "dhl/express=9.90+2.50/1@DE|AT" or "ups/ground=4.99+1/5",
+// into the rates of a Table, with costs in currency. Countries are separated
+// by |.
+func ParseRates(entries []string, currency string) ([]Rate, error) {
+	rates := make([]Rate, 0, len(entries))
+	for _, entry := range entries {
+		rate, err := parseRate(entry, currency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shipping rate %q: %w", entry, err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// parseRate parses one entry of ParseRates
+func parseRate(entry, currency string) (Rate, error) {
+	service, cost, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	carrier, level, hasLevel := strings.Cut(service, "/")
+	if !ok || !hasLevel || strings.TrimSpace(carrier) == "" || strings.TrimSpace(level) == "" {
+		return Rate{}, fmt.Errorf("use carrier/level=base+perkg/days")
+	}
+
+	cost, countries, _ := strings.Cut(cost, "@")
+	cost, days, ok := strings.Cut(cost, "/")
+	if !ok {
+		return Rate{}, fmt.Errorf("missing the delivery days")
+	}
+	base, perKg, ok := strings.Cut(cost, "+")
+	if !ok {
+		return Rate{}, fmt.Errorf("missing the cost per kg")
+	}
+
+	rate := Rate{
+		Carrier:      strings.TrimSpace(carrier),
+		ServiceLevel: strings.TrimSpace(level),
+	}
+	var err error
+	if rate.Base, err = money.Parse(base, currency); err != nil || rate.Base.Sign() < 0 {
+		return Rate{}, fmt.Errorf("the base cost must be a non-negative amount of %s", currency)
+	}
+	if rate.PerKg, err = money.Parse(perKg, currency); err != nil || rate.PerKg.Sign() < 0 {
+		return Rate{}, fmt.Errorf("the cost per kg must be a non-negative amount of %s", currency)
+	}
+	if rate.Days, err = strconv.Atoi(strings.TrimSpace(days)); err != nil || rate.Days < 0 {
+		return Rate{}, fmt.Errorf("the delivery days must be a non-negative whole number")
+	}
+	for _, country := range strings.Split(countries, "|") {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if country == "" {
+			continue
+		}
+		if len(country) != 2 {
+			return Rate{}, fmt.Errorf("%q is not a country code", country)
+		}
+		rate.Countries = append(rate.Countries, country)
+	}
+	return rate, nil
+}
+
+// Name identifies the table in logs
+func (t *Table) Name() string {
+	return "table"
+}
+
+// Quote prices the shipment with every rate that ships to its country
+func (t *Table) Quote(ctx context.Context, shipment Shipment) ([]model.ShippingRate, error) {
+	country := strings.ToUpper(strings.TrimSpace(shipment.Address.Country))
+	kilograms := (int64(shipment.BillableGrams()) + 999) / 1000
+
+	var quoted []model.ShippingRate
+	for _, rate := range t.rates {
+		if len(rate.Countries) > 0 && !slices.Contains(rate.Countries, country) {
+			continue
+		}
+		weight, err := rate.PerKg.Multiply(kilograms)
+		if err != nil {
+			return nil, err
+		}
+		cost, err := rate.Base.Add(weight)
+		if err != nil {
+			return nil, err
+		}
+		quoted = append(quoted, model.ShippingRate{
+			Carrier:       rate.Carrier,
+			ServiceLevel:  rate.ServiceLevel,
+			Cost:          cost,
+			EstimatedDays: rate.Days,
+		})
+	}
+	return quoted, nil
+}
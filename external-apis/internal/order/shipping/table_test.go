@@ -0,0 +1,99 @@
+package shipping
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRates(t *testing.T) {
+	t.Run("Rates with and without countries", func(t *testing.T) {
+		// Act
+		rates, err := ParseRates([]string{"dhl/express=9.90+2.50/1@de|at", " ups / ground = 4.99 + 1 / 5 "}, "EUR")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []Rate{
+			{Carrier: "dhl", ServiceLevel: "express", Base: money.New(990, "EUR"), PerKg: money.New(250, "EUR"), Days: 1, Countries: []string{"DE", "AT"}},
+			{Carrier: "ups", ServiceLevel: "ground", Base: money.New(499, "EUR"), PerKg: money.New(100, "EUR"), Days: 5},
+		}, rates)
+	})
+
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{name: "Missing service level", entry: "dhl=9.90+2.50/1"},
+		{name: "Missing cost", entry: "dhl/express"},
+		{name: "Missing days", entry: "dhl/express=9.90+2.50"},
+		{name: "Missing cost per kg", entry: "dhl/express=9.90/1"},
+		{name: "Negative base cost", entry: "dhl/express=-1+2.50/1"},
+		{name: "Not a number", entry: "dhl/express=9.90+two/1"},
+		{name: "More decimals than the currency has", entry: "dhl/express=9.999+2.50/1"},
+		{name: "Fractional days", entry: "dhl/express=9.90+2.50/1.5"},
+		{name: "Not a country code", entry: "dhl/express=9.90+2.50/1@DEU"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := ParseRates([]string{tt.entry}, "EUR")
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTable_Quote(t *testing.T) {
+	table := NewTable([]Rate{
+		{Carrier: "dhl", ServiceLevel: "express", Base: money.New(990, "EUR"), PerKg: money.New(250, "EUR"), Days: 1, Countries: []string{"DE"}},
+		{Carrier: "ups", ServiceLevel: "ground", Base: money.New(499, "EUR"), PerKg: money.New(100, "EUR"), Days: 5},
+	})
+	items := []Item{
+		{ProductID: "product-001", WeightGrams: 1200},
+		{ProductID: "product-002", WeightGrams: 300},
+	}
+
+	t.Run("Charge every started kilogram", func(t *testing.T) {
+		// Act
+		rates, err := table.Quote(context.Background(), Shipment{
+			Address: model.ShippingAddress{Country: "de"},
+			Items:   items,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.ShippingRate{
+			{Carrier: "dhl", ServiceLevel: "express", Cost: money.New(1490, "EUR"), EstimatedDays: 1},
+			{Carrier: "ups", ServiceLevel: "ground", Cost: money.New(699, "EUR"), EstimatedDays: 5},
+		}, rates)
+	})
+
+	t.Run("Only rates shipping to the country", func(t *testing.T) {
+		// Act
+		rates, err := table.Quote(context.Background(), Shipment{
+			Address: model.ShippingAddress{Country: "US"},
+			Items:   items,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "ups", rates[0].Carrier)
+	})
+}
+
+func TestShipment_BillableGrams(t *testing.T) {
+	// A 40x30x20 cm box weighs 4.8 kg volumetrically
+	box := &model.Dimensions{LengthMM: 400, WidthMM: 300, HeightMM: 200}
+
+	assert.Equal(t, 4800, Shipment{Items: []Item{{WeightGrams: 1000, Dimensions: box}}}.BillableGrams())
+	assert.Equal(t, 6000, Shipment{Items: []Item{{WeightGrams: 6000, Dimensions: box}}}.BillableGrams())
+	assert.Equal(t, 1500, Shipment{Items: []Item{{WeightGrams: 1000}, {WeightGrams: 500}}}.BillableGrams())
+	assert.Zero(t, Shipment{Items: []Item{{ProductID: "product-001"}}}.BillableGrams())
+}
@@ -0,0 +1,128 @@
+package tax
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/deadline"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+)
+
+// API adapts an external tax API to TaxCalculator. It posts the order to
+// <baseURL>/v1/calculate, authenticated with a bearer API key, and expects
+// the tax lines back:
+//
+//	{"lines": [{"jurisdiction": "US-CA", "rate": 7.25, "taxable": 100, "amount": 7.25}]}
+//
+// The amounts of the items and lines are decimal numbers in the currency of
+// the request; line amounts with more decimals than the currency has are
+// rounded.
+//
+// A 400 or 422 answer rejects the order; any other failure leaves the tax
+// provider unavailable.
+type API struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// apiRequest is the body posted to the tax API
+type apiRequest struct {
+	OrderID    string  `json:"orderId"`
+	CustomerID string  `json:"customerId"`
+	Currency   string  `json:"currency"`
+	Address    Address `json:"address"`
+	Items      []Item  `json:"items"`
+}
+
+// apiResponse is the body the tax API answers with, its amounts in the
+// currency of the request
+type apiResponse struct {
+	Lines []struct {
+		Jurisdiction string      `json:"jurisdiction"`
+		Rate         float64     `json:"rate"`
+		Taxable      json.Number `json:"taxable"`
+		Amount       json.Number `json:"amount"`
+	} `json:"lines"`
+}
+
+// NewAPI creates a tax API adapter for the given base URL, sending its
+// requests through transport, or the default transport if it is nil. Every
+// call is bounded by timeout and by the remaining deadline budget of its
+// context.
+func NewAPI(baseURL, apiKey string, timeout time.Duration, transport http.RoundTripper) *API {
+	return &API{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: deadline.NewTransport(transport),
+		},
+	}
+}
+
+// Calculate asks the tax API for the tax lines of the order
+func (a *API) Calculate(ctx context.Context, req Request) ([]model.TaxLine, error) {
+	payload, err := json.Marshal(apiRequest{
+		OrderID:    req.OrderID,
+		CustomerID: req.CustomerID,
+		Currency:   req.Currency,
+		Address:    req.Address,
+		Items:      req.Items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/v1/calculate", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	if a.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+	logger.Inject(ctx, httpReq.Header)
+
+	resp, err := a.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity:
+		log.Ctx(ctx).WithFields(logger.Fields{
+			"order_id": req.OrderID,
+			"status":   resp.StatusCode,
+		}).Warn("Tax API rejected order")
+		return nil, fmt.Errorf("%w: status %d", ErrRejected, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var body apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: invalid response body: %v", ErrUnavailable, err)
+	}
+
+	lines := make([]model.TaxLine, len(body.Lines))
+	for i, line := range body.Lines {
+		lines[i] = model.TaxLine{Jurisdiction: line.Jurisdiction, Rate: line.Rate}
+		if lines[i].Taxable, err = money.ParseRounded(line.Taxable.String(), req.Currency); err != nil {
+			return nil, fmt.Errorf("%w: invalid taxable amount %q", ErrUnavailable, line.Taxable)
+		}
+		if lines[i].Amount, err = money.ParseRounded(line.Amount.String(), req.Currency); err != nil {
+			return nil, fmt.Errorf("%w: invalid tax amount %q", ErrUnavailable, line.Amount)
+		}
+	}
+	return lines, nil
+}
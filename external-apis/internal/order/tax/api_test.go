@@ -0,0 +1,112 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Calculate(t *testing.T) {
+	// Arrange
+	var authorization string
+	var currency string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/v1/calculate", r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+
+		var req struct {
+			Currency string  `json:"currency"`
+			Address  Address `json:"address"`
+			Items    []struct {
+				Amount json.Number `json:"amount"`
+			} `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Items, 1)
+		require.Equal(t, json.Number("999.99"), req.Items[0].Amount)
+		currency = req.Currency
+
+		w.Header().Set("Content-Type", "application/json")
+		switch req.Address.Country {
+		case "JP":
+			w.Write([]byte(`{"lines":[{"jurisdiction":"JP","rate":10,"taxable":999.99,"amount":99.999}]}`))
+		case "XX":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case "ZZ":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte(`{"lines":[{"jurisdiction":"US-CA","rate":7.25,"taxable":100,"amount":7.25}]}`))
+		}
+	}))
+	defer server.Close()
+
+	api := NewAPI(server.URL+"/", "secret", time.Second, nil)
+	request := func(country string) Request {
+		return Request{
+			OrderID:    "order-123",
+			CustomerID: "customer-456",
+			Currency:   "USD",
+			Address:    Address{Country: country, Region: "CA"},
+			Items:      []Item{{ProductID: "product-001", Amount: money.New(99999, "USD")}},
+		}
+	}
+
+	t.Run("Tax lines", func(t *testing.T) {
+		// Act
+		lines, err := api.Calculate(context.Background(), request("US"))
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, "US-CA", lines[0].Jurisdiction)
+		assert.Equal(t, money.New(10000, "USD"), lines[0].Taxable)
+		assert.Equal(t, money.New(725, "USD"), lines[0].Amount)
+		assert.Equal(t, "Bearer secret", authorization)
+		assert.Equal(t, "USD", currency)
+	})
+
+	t.Run("Amounts rounded to the minor unit", func(t *testing.T) {
+		// Act
+		lines, err := api.Calculate(context.Background(), request("JP"))
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, lines, 1)
+		assert.Equal(t, money.New(10000, "USD"), lines[0].Amount)
+	})
+
+	t.Run("Rejected order", func(t *testing.T) {
+		// Act
+		_, err := api.Calculate(context.Background(), request("XX"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRejected)
+	})
+
+	t.Run("Provider failure", func(t *testing.T) {
+		// Act
+		_, err := api.Calculate(context.Background(), request("ZZ"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
+
+func TestAPI_Unreachable(t *testing.T) {
+	// Arrange
+	api := NewAPI("http://127.0.0.1:1", "", time.Second, nil)
+
+	// Act
+	_, err := api.Calculate(context.Background(), Request{Address: Address{Country: "US"}})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
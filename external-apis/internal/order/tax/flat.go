@@ -0,0 +1,79 @@
+package tax
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"external-apis/internal/order/model"
+)
+
+// FlatRate charges a fixed percentage of the order in every jurisdiction of
+// its address that has a rate. A region's rate adds to its country's, so an
+// order to US-CA with rates for US and US-CA gets a line for each.
+type FlatRate struct {
+	rates map[string]float64
+}
+
+// NewFlatRate creates a flat-rate calculator with percentages keyed by
+// jurisdiction, e.g. DE or US-CA
+func NewFlatRate(rates map[string]float64) *FlatRate {
+	return &FlatRate{rates: rates}
+}
+
+// ParseRates parses "<jurisdiction>=<percent>" entries, such as "DE=19" or
+// "US-CA=7.25", into the rates of a FlatRate
+func ParseRates(entries []string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(entries))
+	for _, entry := range entries {
+		jurisdiction, value, ok := strings.Cut(entry, "=")
+		jurisdiction = strings.ToUpper(strings.TrimSpace(jurisdiction))
+		country, _, _ := strings.Cut(jurisdiction, "-")
+		if !ok || len(country) != 2 {
+			return nil, fmt.Errorf("invalid tax rate %q, use country=percent or country-region=percent", entry)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate < 0 || rate > 100 {
+			return nil, fmt.Errorf("invalid tax rate %q: the rate must be a percentage between 0 and 100", entry)
+		}
+		rates[jurisdiction] = rate
+	}
+	return rates, nil
+}
+
+// Calculate charges the rates of the jurisdictions of the address, country
+// first. Jurisdictions without a rate, or with a zero rate, charge nothing.
+func (f *FlatRate) Calculate(ctx context.Context, req Request) ([]model.TaxLine, error) {
+	taxable, err := req.Taxable()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+
+	var lines []model.TaxLine
+	for _, jurisdiction := range req.Address.Jurisdictions() {
+		rate := f.rates[jurisdiction]
+		if rate == 0 {
+			continue
+		}
+		amount, err := taxable.Scale(percent(rate))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrRejected, err)
+		}
+		lines = append(lines, model.TaxLine{
+			Jurisdiction: jurisdiction,
+			Rate:         rate,
+			Taxable:      taxable,
+			Amount:       amount,
+		})
+	}
+	return lines, nil
+}
+
+// percent returns a rate as the exact fraction it stands for, reading the
+// float as the decimal it was parsed from, so 7.25 is 725/10000
+func percent(rate float64) *big.Rat {
+	fraction, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	return fraction.Quo(fraction, big.NewRat(100, 1))
+}
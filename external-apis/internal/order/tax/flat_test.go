@@ -0,0 +1,127 @@
+package tax
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRates(t *testing.T) {
+	t.Run("Country and region rates", func(t *testing.T) {
+		// Act
+		rates, err := ParseRates([]string{"de=19", " US-CA = 7.25", "US=0"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{"DE": 19, "US-CA": 7.25, "US": 0}, rates)
+	})
+
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{name: "Missing rate", entry: "DE"},
+		{name: "Not a country code", entry: "DEU=19"},
+		{name: "Not a number", entry: "DE=nineteen"},
+		{name: "Negative rate", entry: "DE=-1"},
+		{name: "Rate above 100", entry: "DE=101"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := ParseRates([]string{tt.entry})
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAddress_Jurisdictions(t *testing.T) {
+	assert.Equal(t, []string{"US", "US-CA"}, Address{Country: "us", Region: "ca"}.Jurisdictions())
+	assert.Equal(t, []string{"DE"}, Address{Country: "DE"}.Jurisdictions())
+	assert.Nil(t, Address{Region: "CA"}.Jurisdictions())
+}
+
+func TestFlatRate_Calculate(t *testing.T) {
+	calculator := NewFlatRate(map[string]float64{"DE": 19, "US": 0, "US-CA": 7.25})
+	items := []Item{
+		{ProductID: "product-001", Amount: money.New(2999, "USD")},
+		{ProductID: "product-002", Amount: money.New(12999, "USD")},
+	}
+
+	t.Run("Region rate", func(t *testing.T) {
+		// Act
+		lines, err := calculator.Calculate(context.Background(), Request{
+			Currency: "USD",
+			Address:  Address{Country: "US", Region: "CA"},
+			Items:    items,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.TaxLine{
+			{Jurisdiction: "US-CA", Rate: 7.25, Taxable: money.New(15998, "USD"), Amount: money.New(1160, "USD")},
+		}, lines)
+	})
+
+	t.Run("Country rate", func(t *testing.T) {
+		// Act
+		lines, err := calculator.Calculate(context.Background(), Request{
+			Currency: "USD",
+			Address:  Address{Country: "DE", Region: "BE"},
+			Items:    items,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.TaxLine{
+			{Jurisdiction: "DE", Rate: 19, Taxable: money.New(15998, "USD"), Amount: money.New(3040, "USD")},
+		}, lines)
+	})
+
+	t.Run("Currency without minor units", func(t *testing.T) {
+		// Act
+		lines, err := calculator.Calculate(context.Background(), Request{
+			Currency: "JPY",
+			Address:  Address{Country: "US", Region: "CA"},
+			Items:    []Item{{ProductID: "product-001", Amount: money.New(1999, "JPY")}},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.TaxLine{
+			{Jurisdiction: "US-CA", Rate: 7.25, Taxable: money.New(1999, "JPY"), Amount: money.New(145, "JPY")},
+		}, lines)
+	})
+
+	t.Run("Items in another currency", func(t *testing.T) {
+		// Act
+		_, err := calculator.Calculate(context.Background(), Request{
+			Currency: "EUR",
+			Address:  Address{Country: "DE"},
+			Items:    items,
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrRejected)
+	})
+
+	t.Run("Untaxed jurisdiction", func(t *testing.T) {
+		// Act
+		lines, err := calculator.Calculate(context.Background(), Request{
+			Currency: "USD",
+			Address:  Address{Country: "FR"},
+			Items:    items,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+}
@@ -0,0 +1,99 @@
+// Package tax works out the taxes of orders by jurisdiction, from a flat rate
+// per jurisdiction or an external tax API.
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"external-apis/internal/order/model"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+)
+
+var log = logger.New("order/tax")
+
+var (
+	// ErrUnavailable is returned when the tax provider cannot be reached or fails
+	ErrUnavailable = errors.New("tax provider unavailable")
+	// ErrRejected is returned when the tax provider refuses to tax an order,
+	// e.g. for an address it does not know
+	ErrRejected = errors.New("tax provider rejected order")
+)
+
+// TaxCalculator works out the taxes of an order, one line per jurisdiction
+// that charges any. Errors wrap ErrUnavailable when the calculation may
+// succeed later and ErrRejected when it never will.
+type TaxCalculator interface {
+	Calculate(ctx context.Context, req Request) ([]model.TaxLine, error)
+}
+
+// Request describes what an order is taxed on and where. The amounts of its
+// items, and so its tax, are in Currency.
+type Request struct {
+	OrderID    string
+	CustomerID string
+	Currency   string
+	Address    Address
+	Items      []Item
+}
+
+// Address is where an order is taxed
+type Address struct {
+	// Country is the ISO 3166-1 alpha-2 code, e.g. US
+	Country string `json:"country"`
+	// Region is the state, province or similar subdivision, e.g. CA
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	City       string `json:"city,omitempty"`
+}
+
+// Jurisdictions returns the jurisdictions that may tax the address: its
+// country and, with a region, the region as <country>-<region>
+func (a Address) Jurisdictions() []string {
+	country := strings.ToUpper(strings.TrimSpace(a.Country))
+	if country == "" {
+		return nil
+	}
+
+	jurisdictions := []string{country}
+	if region := strings.ToUpper(strings.TrimSpace(a.Region)); region != "" {
+		jurisdictions = append(jurisdictions, country+"-"+region)
+	}
+	return jurisdictions
+}
+
+// Item is a product of an order at the price the customer pays
+type Item struct {
+	ProductID string      `json:"productId"`
+	Category  string      `json:"category,omitempty"`
+	Amount    money.Money `json:"amount"`
+}
+
+// MarshalJSON custom marshaling for Item. The amount is written as an exact
+// decimal number; its currency is the one of the request.
+func (i Item) MarshalJSON() ([]byte, error) {
+	type Alias Item
+
+	return json.Marshal(struct {
+		Alias
+		Amount json.Number `json:"amount"`
+	}{
+		Alias:  Alias(i),
+		Amount: i.Amount.Number(),
+	})
+}
+
+// Taxable sums the amounts of the items
+func (r Request) Taxable() (money.Money, error) {
+	taxable := money.New(0, r.Currency)
+	for _, item := range r.Items {
+		var err error
+		if taxable, err = taxable.Add(item.Amount); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return taxable, nil
+}
@@ -0,0 +1,61 @@
+// Package consumer applies the messages the product service consumes from
+// queues, as the handlers apply HTTP requests.
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/rabbitmq"
+	"external-apis/internal/shared/tenant"
+)
+
+var log = logger.New("product/consumer")
+
+// CatalogConsumer applies the catalog updates suppliers send to the catalog
+// update queue
+type CatalogConsumer struct {
+	service service.ProductService
+	tenants tenant.Config
+}
+
+// NewCatalogConsumer creates a consumer of catalog updates. The tenant named
+// by an update is resolved like the tenant header of a request.
+func NewCatalogConsumer(service service.ProductService, tenants tenant.Config) *CatalogConsumer {
+	return &CatalogConsumer{
+		service: service,
+		tenants: tenants,
+	}
+}
+
+// Handle validates a catalog update against its schema and applies it for
+// its tenant. It implements rabbitmq.Handler; updates that do not follow
+// the schema or name an unknown tenant are reported as malformed.
+func (c *CatalogConsumer) Handle(ctx context.Context, message rabbitmq.Message) error {
+	update, err := model.ParseCatalogUpdate(message.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", rabbitmq.ErrMalformed, err)
+	}
+
+	tenantID, err := c.tenants.Resolve(update.Tenant)
+	if err != nil {
+		return fmt.Errorf("%w: %w", rabbitmq.ErrMalformed, err)
+	}
+	ctx = tenant.WithTenant(ctx, tenantID)
+
+	result, err := c.service.ApplyCatalogUpdate(ctx, update)
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"message_id": message.ID,
+		"supplier":   update.Supplier,
+		"sku":        update.SKU,
+		"result":     result,
+	}).Info("Applied catalog update")
+	return nil
+}
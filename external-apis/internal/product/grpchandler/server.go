@@ -0,0 +1,228 @@
+package grpchandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+
+	productv1 "external-apis/api/product/v1"
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var log = logger.New("product/grpchandler")
+
+// ProductServer exposes the product service over gRPC
+type ProductServer struct {
+	productv1.UnimplementedProductServiceServer
+	service service.ProductService
+}
+
+// NewProductServer creates a new product gRPC server
+func NewProductServer(service service.ProductService) *ProductServer {
+	return &ProductServer{
+		service: service,
+	}
+}
+
+// Register registers the product service on the gRPC server
+func (s *ProductServer) Register(server *grpc.Server) {
+	productv1.RegisterProductServiceServer(server, s)
+}
+
+// GetProduct retrieves a product by ID
+func (s *ProductServer) GetProduct(ctx context.Context, req *productv1.GetProductRequest) (*productv1.Product, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Product ID is required")
+	}
+
+	product, err := s.service.GetProductByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(product), nil
+}
+
+// ListProducts retrieves a filtered page of products
+func (s *ProductServer) ListProducts(ctx context.Context, req *productv1.ListProductsRequest) (*productv1.ListProductsResponse, error) {
+	filter := model.ProductFilter{
+		Category: req.GetCategory(),
+		Currency: strings.ToUpper(req.GetCurrency()),
+		Sort:     req.GetSort(),
+		Page: pagination.Params{
+			Limit:  int(req.GetLimit()),
+			Offset: int(req.GetOffset()),
+		},
+	}
+
+	if filter.Page.Limit < 0 || filter.Page.Limit > pagination.MaxLimit {
+		return nil, status.Error(codes.InvalidArgument, pagination.ErrInvalidLimit.Error())
+	}
+	if filter.Page.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, pagination.ErrInvalidOffset.Error())
+	}
+
+	if req.MinPrice != nil {
+		filter.MinPrice = new(big.Rat).SetFloat64(req.GetMinPrice())
+	}
+	if req.MaxPrice != nil {
+		filter.MaxPrice = new(big.Rat).SetFloat64(req.GetMaxPrice())
+	}
+	if req.Active != nil {
+		active := req.GetActive()
+		filter.Active = &active
+	}
+	if req.GetCategoryId() != "" {
+		filter.CategoryIDs = []string{req.GetCategoryId()}
+	}
+
+	products, meta, err := s.service.ListProducts(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &productv1.ListProductsResponse{
+		Products: make([]*productv1.Product, len(products)),
+		Total:    int32(meta.Total),
+		Limit:    int32(meta.Limit),
+		Offset:   int32(meta.Offset),
+	}
+	for i, product := range products {
+		resp.Products[i] = toProto(product)
+	}
+
+	return resp, nil
+}
+
+// CreateProduct creates a new product
+func (s *ProductServer) CreateProduct(ctx context.Context, req *productv1.CreateProductRequest) (*productv1.Product, error) {
+	if req.GetName() == "" || req.GetDescription() == "" || req.GetCategoryId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name, description and category_id are required")
+	}
+
+	product, err := s.service.CreateProduct(ctx, model.CreateProductRequest{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Price:       priceAmount(req.GetPriceAmount(), req.GetPrice()),
+		Currency:    req.GetCurrency(),
+		CategoryID:  req.GetCategoryId(),
+
+		StockQuantity: int(req.GetStockQuantity()),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(product), nil
+}
+
+// UpdateProduct updates an existing product
+func (s *ProductServer) UpdateProduct(ctx context.Context, req *productv1.UpdateProductRequest) (*productv1.Product, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Product ID is required")
+	}
+
+	update := model.UpdateProductRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Currency:    req.Currency,
+		CategoryID:  req.CategoryId,
+		Active:      req.Active,
+	}
+	if req.PriceAmount != nil || req.Price != nil {
+		price := priceAmount(req.GetPriceAmount(), req.GetPrice())
+		update.Price = &price
+	}
+
+	product, err := s.service.UpdateProduct(ctx, req.GetId(), update)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toProto(product), nil
+}
+
+// DeleteProduct deletes a product
+func (s *ProductServer) DeleteProduct(ctx context.Context, req *productv1.DeleteProductRequest) (*productv1.DeleteProductResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Product ID is required")
+	}
+
+	if err := s.service.DeleteProduct(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &productv1.DeleteProductResponse{}, nil
+}
+
+// priceAmount prefers the exact decimal amount, falling back to the double
+// price for older clients
+func priceAmount(amount string, price float64) json.Number {
+	if amount != "" {
+		return json.Number(amount)
+	}
+	return json.Number(strconv.FormatFloat(price, 'f', -1, 64))
+}
+
+// toProto converts a product response into its protobuf representation
+func toProto(product *model.ProductResponse) *productv1.Product {
+	price, _ := product.Price.Float64()
+	images := make([]*productv1.ProductImage, len(product.Images))
+	for i, image := range product.Images {
+		images[i] = &productv1.ProductImage{
+			Id:          image.ID,
+			Url:         image.URL,
+			ContentType: image.ContentType,
+			Size:        image.Size,
+		}
+	}
+
+	return &productv1.Product{
+		Id:          product.ID,
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       price,
+		PriceAmount: product.Price.String(),
+		Currency:    product.Currency,
+		CategoryId:  product.CategoryID,
+		Category:    product.Category,
+		Active:      product.Active,
+
+		StockQuantity:     int32(product.StockQuantity),
+		ReservedQuantity:  int32(product.ReservedQuantity),
+		AvailableQuantity: int32(product.AvailableQuantity),
+
+		Images: images,
+	}
+}
+
+// toStatus maps service errors to gRPC status codes
+func toStatus(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		return status.Error(codes.NotFound, "Product not found")
+	case errors.Is(err, model.ErrProductExists):
+		return status.Error(codes.AlreadyExists, "Product already exists")
+	case errors.Is(err, apperror.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, apperror.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	log.Ctx(ctx).WithError(err).Error("Unexpected product service error")
+	return status.Error(codes.Internal, "Internal server error occurred")
+}
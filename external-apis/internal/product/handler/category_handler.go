@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler handles HTTP requests for product categories
+type CategoryHandler struct {
+	service service.CategoryService
+	ids     idgen.Generator
+}
+
+// NewCategoryHandler creates a new category handler. Category IDs in request
+// paths are checked against ids.
+func NewCategoryHandler(service service.CategoryService, ids idgen.Generator) *CategoryHandler {
+	return &CategoryHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the category routes. Reads are public; writes go
+// through requireAuth.
+func (h *CategoryHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	categories := router.Group("/categories", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		categories.GET("", middleware.Cache(catalogCache), h.GetCategories)
+		categories.GET("/:id", middleware.Cache(catalogCache), h.GetCategory)
+		categories.POST("", requireAuth, h.CreateCategory)
+		categories.PUT("/:id", requireAuth, h.UpdateCategory)
+		categories.DELETE("/:id", requireAuth, h.DeleteCategory)
+	}
+}
+
+// GetCategories godoc
+// @Summary List categories
+// @Description Get all product categories with their position in the category tree
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]model.CategoryResponse}
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.service.GetCategories(c.Request.Context())
+	if err != nil {
+		h.categoryError(c, err)
+		return
+	}
+
+	response.OK(c, categories)
+}
+
+// GetCategory godoc
+// @Summary Get category by ID
+// @Description Get a product category by its ID
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.SuccessResponse{data=model.CategoryResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/categories/{id} [get]
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	category, err := h.service.GetCategory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.categoryError(c, err)
+		return
+	}
+
+	response.OK(c, category)
+}
+
+// CreateCategory godoc
+// @Summary Create a category
+// @Description Create a top-level category or, with a parent ID, a subcategory. Its attribute schema declares the typed attributes of its products, on top of those inherited from its ancestors.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param category body model.CreateCategoryRequest true "Category data"
+// @Success 201 {object} response.SuccessResponse{data=model.CategoryResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/categories [post]
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req model.CreateCategoryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create category")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Request.Context(), req)
+	if err != nil {
+		h.categoryError(c, err)
+		return
+	}
+
+	response.Created(c, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update a category
+// @Description Rename, describe or move a category, or replace its attribute schema. Renaming a category renames it on its products.
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param category body model.UpdateCategoryRequest true "Category data"
+// @Success 200 {object} response.SuccessResponse{data=model.CategoryResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/categories/{id} [put]
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req model.UpdateCategoryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update category")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	category, err := h.service.UpdateCategory(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.categoryError(c, err)
+		return
+	}
+
+	response.OK(c, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete a category that has no subcategories and no products
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/categories/{id} [delete]
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.service.DeleteCategory(c.Request.Context(), c.Param("id")); err != nil {
+		h.categoryError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Category deleted successfully")
+}
+
+// categoryError maps category service errors to responses
+func (h *CategoryHandler) categoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Category not found")
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, apperror.ErrConflict):
+		response.Conflict(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"category_id": c.Param("id"),
+			"request_id":  c.GetString("request_id"),
+		}).Error("Failed to process category")
+		response.InternalServerError(c, "Failed to process category")
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/shared/changefeed"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ChangeHandler serves the change feed of products
+type ChangeHandler struct {
+	feed *changefeed.Feed
+}
+
+// NewChangeHandler creates a new product change feed handler
+func NewChangeHandler(feed *changefeed.Feed) *ChangeHandler {
+	return &ChangeHandler{
+		feed: feed,
+	}
+}
+
+// RegisterRoutes registers the product change feed route
+func (h *ChangeHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/products/changes", h.GetChanges)
+}
+
+// GetChanges godoc
+// @Summary Get product changes
+// @Description Read the products created, updated and deleted since a cursor, in the order the changes happened. Creates and updates carry the product as it was after the change; deletes are tombstones with the ID only. Start without since to read every retained change, then pass the cursor of each page to resume. A cursor older than the retained changes, or issued before the service restarted, answers 410, after which clients resync with a full export.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param since query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Success 200 {object} response.SuccessResponse{data=changefeed.Page}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 410 {object} response.ErrorResponse
+// @Router /api/v1/products/changes [get]
+func (h *ChangeHandler) GetChanges(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	changes, err := h.feed.Changes(c.Request.Context(), c.Query("since"), page.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, changefeed.ErrInvalidCursor):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, changefeed.ErrCursorExpired):
+			response.Error(c, http.StatusGone, "cursor_expired", err.Error())
+		default:
+			log.Ctx(c.Request.Context()).WithError(err).Error("Failed to read product changes")
+			response.InternalServerError(c, "Failed to read product changes")
+		}
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"since":      c.Query("since"),
+		"changes":    len(changes.Changes),
+		"request_id": c.GetString("request_id"),
+	}).Debug("Read product changes")
+
+	response.OK(c, changes)
+}
@@ -1,49 +1,107 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
 	"external-apis/internal/product/model"
 	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/conditional"
+	"external-apis/internal/shared/currency"
+	"external-apis/internal/shared/exporter"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/importer"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/patch"
+	"external-apis/internal/shared/rbac"
 	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/timerange"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
+var log = logger.New("product/handler")
+
+// catalogCache lets clients and a CDN in front of the API keep catalog reads
+// for a minute. The catalog of each tenant differs, so copies are kept per
+// tenant.
+var catalogCache = middleware.CachePolicy{MaxAge: time.Minute, Vary: []string{tenant.Header}}
+
 // ProductHandler handles HTTP requests for products
 type ProductHandler struct {
-	service service.ProductService
+	service   service.ProductService
+	converter *currency.Converter
+	ids       idgen.Generator
 }
 
-// NewProductHandler creates a new product handler
-func NewProductHandler(service service.ProductService) *ProductHandler {
+// NewProductHandler creates a new product handler. Prices are converted into
+// the currencies clients ask for through converter; product IDs in request
+// paths are checked against ids.
+func NewProductHandler(service service.ProductService, converter *currency.Converter, ids idgen.Generator) *ProductHandler {
 	return &ProductHandler{
-		service: service,
+		service:   service,
+		converter: converter,
+		ids:       ids,
 	}
 }
 
-// RegisterRoutes registers all product routes
-func (h *ProductHandler) RegisterRoutes(router *gin.RouterGroup) {
-	products := router.Group("/products")
+// RegisterRoutes registers all product routes. Reads are public; writes go
+// through requireAuth.
+func (h *ProductHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	products := router.Group("/products", middleware.ValidID(h.ids.Valid, "id"))
 	{
-		products.GET("", h.GetAllProducts)
-		products.GET("/:id", h.GetProductByID)
-		products.POST("", h.CreateProduct)
-		products.PUT("/:id", h.UpdateProduct)
-		products.DELETE("/:id", h.DeleteProduct)
+		products.GET("", middleware.Cache(catalogCache), h.GetAllProducts)
+		products.GET("/search", middleware.Cache(catalogCache), h.SearchProducts)
+		products.GET("/export", middleware.RaiseTimeout(0), h.ExportProducts)
+		products.GET("/stats", middleware.Cache(catalogCache), h.GetProductStats)
+		products.GET("/sku/:sku", middleware.Cache(catalogCache), h.GetProductBySKU)
+		products.GET("/barcode/:code", middleware.Cache(catalogCache), h.GetProductByBarcode)
+		products.GET("/:id", middleware.Cache(catalogCache), h.GetProductByID)
+		products.POST("/batch-get", h.BatchGetProducts)
+		products.POST("", requireAuth, h.CreateProduct)
+		products.POST("/bulk", requireAuth, rbac.Require(rbac.PermissionDelete), middleware.RaiseBodyLimit(bulk.MaxBodySize), middleware.RaiseTimeout(bulk.Timeout), h.BulkProducts)
+		products.POST("/import", requireAuth, middleware.RaiseBodyLimit(0), middleware.RaiseTimeout(0), h.ImportProducts)
+		products.PUT("/:id", requireAuth, h.UpdateProduct)
+		products.PATCH("/:id", requireAuth, h.PatchProduct)
+		products.DELETE("/:id", requireAuth, h.DeleteProduct)
+		products.POST("/:id/restore", requireAuth, h.RestoreProduct)
+		products.POST("/:id/stock/reserve", requireAuth, h.ReserveStock)
+		products.POST("/:id/stock/release", requireAuth, h.ReleaseStock)
+		products.POST("/:id/stock/adjust", requireAuth, h.AdjustStock)
 	}
 }
 
 // GetProductByID godoc
 // @Summary Get product by ID
-// @Description Get a product by its ID
+// @Description Get a product by its ID. The response carries ETag and Last-Modified headers for conditional requests. With currency, the prices are also converted into that currency at the latest exchange rate, reported with the rate and its timestamp.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is current"
+// @Param If-Modified-Since header string false "Time of a cached copy; answered with 304 if it is current"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,price; all fields if omitted"
+// @Param currency query string false "ISO 4217 currency code to convert prices into"
 // @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/products/{id} [get]
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/products/{id} [get]
 func (h *ProductHandler) GetProductByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -52,46 +110,354 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	fields, err := response.ParseFields(c, model.ProductResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	convertTo, err := parseConvertTo(c, "currency")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"product_id": id,
 		"request_id": c.GetString("request_id"),
 	}).Info("Getting product by ID")
 
-	product, err := h.service.GetProductByID(id)
+	product, err := h.service.GetProductByID(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "product not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Product not found")
 			return
 		}
 
-		logrus.WithError(err).WithField("product_id", id).Error("Failed to get product")
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to get product")
 		response.InternalServerError(c, "Failed to retrieve product")
 		return
 	}
 
-	response.OK(c, product)
+	h.writeProduct(c, product, fields, convertTo)
+}
+
+// GetProductBySKU godoc
+// @Summary Get product by SKU
+// @Description Get the product with a SKU, ignoring case, for warehouse and supplier systems that key on it. Variant SKUs do not match. Answers like the lookup by ID.
+// @Tags products
+// @Produce json
+// @Param sku path string true "Product SKU"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is current"
+// @Param If-Modified-Since header string false "Time of a cached copy; answered with 304 if it is current"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,price; all fields if omitted"
+// @Param currency query string false "ISO 4217 currency code to convert prices into"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Success 304 "Not Modified"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/products/sku/{sku} [get]
+func (h *ProductHandler) GetProductBySKU(c *gin.Context) {
+	sku := c.Param("sku")
+	h.lookupProduct(c, logger.Fields{"sku": sku}, func(ctx context.Context) (*model.ProductResponse, error) {
+		return h.service.GetProductBySKU(ctx, sku)
+	})
+}
+
+// GetProductByBarcode godoc
+// @Summary Get product by barcode
+// @Description Get the product with a GTIN barcode: an EAN-8, UPC-A, EAN-13 or GTIN-14 number with a valid check digit. A code matches in any of its lengths, so a UPC-A also finds the product recorded with the same EAN-13. Answers like the lookup by ID.
+// @Tags products
+// @Produce json
+// @Param code path string true "GTIN"
+// @Param If-None-Match header string false "ETag of a cached copy; answered with 304 if it is current"
+// @Param If-Modified-Since header string false "Time of a cached copy; answered with 304 if it is current"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,price; all fields if omitted"
+// @Param currency query string false "ISO 4217 currency code to convert prices into"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Success 304 "Not Modified"
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Router /api/v1/products/barcode/{code} [get]
+func (h *ProductHandler) GetProductByBarcode(c *gin.Context) {
+	code := c.Param("code")
+	h.lookupProduct(c, logger.Fields{"gtin": code}, func(ctx context.Context) (*model.ProductResponse, error) {
+		return h.service.GetProductByGTIN(ctx, code)
+	})
+}
+
+// lookupProduct answers a lookup of a single product by one of its codes
+func (h *ProductHandler) lookupProduct(c *gin.Context, fields logger.Fields, lookup func(ctx context.Context) (*model.ProductResponse, error)) {
+	projection, err := response.ParseFields(c, model.ProductResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	convertTo, err := parseConvertTo(c, "currency")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	fields["request_id"] = c.GetString("request_id")
+	log.Ctx(c.Request.Context()).WithFields(fields).Info("Looking up product")
+
+	product, err := lookup(c.Request.Context())
+	if err != nil {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) {
+			response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(fields).Error("Failed to look up product")
+		response.InternalServerError(c, "Failed to retrieve product")
+		return
+	}
+
+	h.writeProduct(c, product, projection, convertTo)
+}
+
+// writeProduct answers with a product, its prices converted into convertTo
+// if it is set, for conditional requests
+func (h *ProductHandler) writeProduct(c *gin.Context, product *model.ProductResponse, fields []string, convertTo string) {
+	lastModified := product.UpdatedAt
+	if convertTo != "" {
+		asOf, err := h.convertPrices(c.Request.Context(), convertTo, product)
+		if err != nil {
+			h.conversionError(c, err)
+			return
+		}
+		// The converted price changes with the rate as well as the product
+		if asOf.After(lastModified) {
+			lastModified = asOf
+		}
+	}
+
+	conditional.OK(c, response.Project(product, fields), lastModified)
+}
+
+// BatchGetProducts godoc
+// @Summary Get several products by ID
+// @Description Get up to 500 products in one round trip. Every distinct ID is reported, in request order, as found or not_found.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param ids body batch.Request true "Product IDs"
+// @Success 200 {object} response.SuccessResponse{data=batch.Response}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products/batch-get [post]
+func (h *ProductHandler) BatchGetProducts(c *gin.Context) {
+	var req batch.Request
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for batch get products")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"ids":        len(req.IDs),
+		"request_id": c.GetString("request_id"),
+	}).Info("Batch getting products")
+
+	result, err := h.service.BatchGetProducts(c.Request.Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to batch get products")
+		response.InternalServerError(c, "Failed to retrieve products")
+		return
+	}
+
+	response.OK(c, result)
 }
 
 // GetAllProducts godoc
 // @Summary Get all products
-// @Description Get a list of all products
+// @Description Get a paginated list of products. With convert_to, the prices are also converted into that currency at the latest exchange rate, reported with the rate and its timestamp.
 // @Tags products
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.SuccessResponse{data=[]model.ProductResponse}
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of products to skip"
+// @Param category query string false "Filter by category name (case-insensitive)"
+// @Param category_id query string false "Filter by category ID, including its subcategories"
+// @Param min_price query number false "Minimum price (inclusive)"
+// @Param max_price query number false "Maximum price (inclusive)"
+// @Param currency query string false "Filter by ISO 4217 currency code"
+// @Param active query bool false "Filter by active flag"
+// @Param tag query []string false "Only products with every one of the tags" collectionFormat(multi)
+// @Param attribute query []string false "Only products with every one of the attribute values, as key:value, e.g. color:red" collectionFormat(multi)
+// @Param created_since query string false "Only entries created at or after this RFC 3339 time"
+// @Param created_before query string false "Only entries created before this RFC 3339 time"
+// @Param updated_since query string false "Only entries updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only entries updated before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft-deleted entries"
+// @Param sort query string false "Sort order (id, id_desc, name, name_desc, price_asc, price_desc)"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,price; all fields if omitted"
+// @Param convert_to query string false "ISO 4217 currency code to convert prices into"
+// @Success 200 {object} response.PagedResponse{data=[]model.ProductResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/products [get]
+// @Failure 502 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products [get]
 func (h *ProductHandler) GetAllProducts(c *gin.Context) {
-	logrus.WithField("request_id", c.GetString("request_id")).Info("Getting all products")
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	fields, err := response.ParseFields(c, model.ProductResponse{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	convertTo, err := parseConvertTo(c, "convert_to")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
 
-	products, err := h.service.GetAllProducts()
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"limit":      filter.Page.Limit,
+		"offset":     filter.Page.Offset,
+		"sort":       filter.Sort,
+		"request_id": c.GetString("request_id"),
+	}).Info("Getting all products")
+
+	products, meta, err := h.service.ListProducts(c.Request.Context(), filter)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get all products")
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to get all products")
 		response.InternalServerError(c, "Failed to retrieve products")
 		return
 	}
 
-	response.OK(c, products)
+	if convertTo != "" {
+		if _, err := h.convertPrices(c.Request.Context(), convertTo, products...); err != nil {
+			h.conversionError(c, err)
+			return
+		}
+	}
+
+	response.Paged(c, response.Project(products, fields), meta)
+}
+
+// SearchProducts godoc
+// @Summary Search products
+// @Description Full-text search over product names, descriptions and categories, best match first. With convert_to, the prices are also converted into that currency at the latest exchange rate, reported with the rate and its timestamp.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param q query string true "Search terms"
+// @Param limit query int false "Page size (1-500, default 50)"
+// @Param offset query int false "Number of results to skip"
+// @Param category query string false "Filter by category name (case-insensitive)"
+// @Param category_id query string false "Filter by category ID, including its subcategories"
+// @Param min_price query number false "Minimum price (inclusive)"
+// @Param max_price query number false "Maximum price (inclusive)"
+// @Param currency query string false "Filter by ISO 4217 currency code"
+// @Param active query bool false "Filter by active flag"
+// @Param tag query []string false "Only products with every one of the tags" collectionFormat(multi)
+// @Param attribute query []string false "Only products with every one of the attribute values, as key:value, e.g. color:red" collectionFormat(multi)
+// @Param created_since query string false "Only entries created at or after this RFC 3339 time"
+// @Param created_before query string false "Only entries created before this RFC 3339 time"
+// @Param updated_since query string false "Only entries updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only entries updated before this RFC 3339 time"
+// @Param fields query string false "Comma-separated fields to return, e.g. id,name,price; all fields if omitted"
+// @Param convert_to query string false "ISO 4217 currency code to convert prices into"
+// @Success 200 {object} response.PagedResponse{data=[]model.ProductSearchResult}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 502 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products/search [get]
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	fields, err := response.ParseFields(c, model.ProductSearchResult{})
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	convertTo, err := parseConvertTo(c, "convert_to")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	// Deleted products are never indexed and results are ranked by relevance
+	filter.IncludeDeleted = false
+	filter.Sort = ""
+
+	query := model.ProductSearch{Query: c.Query("q"), Filter: filter}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"query":      query.Query,
+		"limit":      filter.Page.Limit,
+		"offset":     filter.Page.Offset,
+		"request_id": c.GetString("request_id"),
+	}).Info("Searching products")
+
+	results, meta, err := h.service.SearchProducts(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to search products")
+		response.InternalServerError(c, "Failed to search products")
+		return
+	}
+
+	if convertTo != "" {
+		products := make([]*model.ProductResponse, len(results))
+		for i, result := range results {
+			products[i] = &result.ProductResponse
+		}
+		if _, err := h.convertPrices(c.Request.Context(), convertTo, products...); err != nil {
+			h.conversionError(c, err)
+			return
+		}
+	}
+
+	response.Paged(c, response.Project(results, fields), meta)
 }
 
 // CreateProduct godoc
@@ -103,32 +469,43 @@ func (h *ProductHandler) GetAllProducts(c *gin.Context) {
 // @Param product body model.CreateProductRequest true "Product data"
 // @Success 201 {object} response.SuccessResponse{data=model.ProductResponse}
 // @Failure 400 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/products [post]
+// @Router /api/v1/products [post]
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req model.CreateProductRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logrus.WithError(err).Error("Invalid request body for create product")
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create product")
+		response.InvalidRequest(c, err)
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
-		"name":       req.Name,
-		"category":   req.Category,
-		"request_id": c.GetString("request_id"),
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"name":        req.Name,
+		"category_id": req.CategoryID,
+		"request_id":  c.GetString("request_id"),
 	}).Info("Creating new product")
 
-	product, err := h.service.CreateProduct(req)
+	product, err := h.service.CreateProduct(c.Request.Context(), req)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create product")
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to create product")
 
-		if err.Error() == "product already exists" {
+		if errors.Is(err, model.ErrSKUExists) || errors.Is(err, model.ErrGTINExists) {
+			response.Conflict(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrConflict) {
 			response.Conflict(c, "Product already exists")
 			return
 		}
 
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
 		response.InternalServerError(c, "Failed to create product")
 		return
 	}
@@ -147,8 +524,9 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 // @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/products/{id} [put]
+// @Router /api/v1/products/{id} [put]
 func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	id := c.Param("id")
 
@@ -160,24 +538,34 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	var req model.UpdateProductRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		logrus.WithError(err).Error("Invalid request body for update product")
-		response.BadRequest(c, "Invalid request body: "+err.Error())
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update product")
+		response.InvalidRequest(c, err)
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"product_id": id,
 		"request_id": c.GetString("request_id"),
 	}).Info("Updating product")
 
-	product, err := h.service.UpdateProduct(id, req)
+	product, err := h.service.UpdateProduct(c.Request.Context(), id, req)
 	if err != nil {
-		if err.Error() == "product not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Product not found")
 			return
 		}
 
-		logrus.WithError(err).WithField("product_id", id).Error("Failed to update product")
+		if errors.Is(err, model.ErrSKUExists) || errors.Is(err, model.ErrGTINExists) {
+			response.Conflict(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to update product")
 		response.InternalServerError(c, "Failed to update product")
 		return
 	}
@@ -185,6 +573,126 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	response.OK(c, product)
 }
 
+// PatchProduct godoc
+// @Summary Patch a product
+// @Description Change some fields of a product with an RFC 7386 merge patch (application/merge-patch+json, or application/json) or an RFC 6902 JSON Patch (application/json-patch+json). Fields the patch leaves out stay as they are. Setting sku, gtin, tags, attributes, weightGrams or dimensions to null, or removing them, clears them; the other fields are required. A failed test operation gets 409 Conflict, and a patch whose If-Match header names a stale version gets 412 Precondition Failed.
+// @Tags products
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param patch body model.ProductDocument true "Merge patch, or an array of patch.Operation"
+// @Param If-Match header string false "ETag of the version the patch was made against; answered with 412 if it is stale"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 412 {object} response.ErrorResponse
+// @Failure 415 {object} response.ErrorResponse
+// @Failure 422 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id} [patch]
+func (h *ProductHandler) PatchProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	if id == "" {
+		response.BadRequest(c, "Product ID is required")
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+	p, err := patch.Parse(c.ContentType(), body)
+	if err != nil {
+		if errors.Is(err, patch.ErrUnsupportedMediaType) {
+			response.UnsupportedMediaType(c, err.Error())
+			return
+		}
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"product_id": id,
+		"request_id": c.GetString("request_id"),
+	}).Info("Patching product")
+
+	if !h.matchesCurrent(c, id) {
+		return
+	}
+
+	product, err := h.service.PatchProduct(c.Request.Context(), id, p)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Product not found")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrConflict) {
+			response.Conflict(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, apperror.ErrUnprocessable) {
+			response.UnprocessableEntity(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, patch.ErrInvalidDocument) {
+			response.InvalidRequest(c, err)
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to patch product")
+		response.InternalServerError(c, "Failed to patch product")
+		return
+	}
+
+	response.OK(c, product)
+}
+
+// matchesCurrent checks the If-Match header of a request changing a product
+// against the ETag of its current version, answering the request itself
+// when the product cannot be changed
+func (h *ProductHandler) matchesCurrent(c *gin.Context, id string) bool {
+	if c.GetHeader("If-Match") == "" {
+		return true
+	}
+
+	current, err := h.service.GetProductByID(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Product not found")
+			return false
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to get product")
+		response.InternalServerError(c, "Failed to retrieve product")
+		return false
+	}
+
+	matches, err := conditional.Matches(c, current)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to encode product")
+		response.InternalServerError(c, "Failed to retrieve product")
+		return false
+	}
+	if !matches {
+		response.PreconditionFailed(c, "Product has changed since it was read")
+		return false
+	}
+	return true
+}
+
 // DeleteProduct godoc
 // @Summary Delete a product
 // @Description Delete a product by ID
@@ -196,7 +704,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Failure 500 {object} response.ErrorResponse
-// @Router /api/products/{id} [delete]
+// @Router /api/v1/products/{id} [delete]
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id := c.Param("id")
 
@@ -205,22 +713,533 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
 		"product_id": id,
 		"request_id": c.GetString("request_id"),
 	}).Info("Deleting product")
 
-	err := h.service.DeleteProduct(id)
+	err := h.service.DeleteProduct(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "product not found" {
+		if errors.Is(err, apperror.ErrNotFound) {
 			response.NotFound(c, "Product not found")
 			return
 		}
 
-		logrus.WithError(err).WithField("product_id", id).Error("Failed to delete product")
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to delete product")
 		response.InternalServerError(c, "Failed to delete product")
 		return
 	}
 
-	response.OK(c, gin.H{"message": "Product deleted successfully"})
+	response.Message(c, http.StatusOK, "Product deleted successfully")
+}
+
+// ReserveStock godoc
+// @Summary Reserve product stock
+// @Description Atomically reserve units of a product's available stock
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param stock body model.StockRequest true "Units to reserve"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/stock/reserve [post]
+func (h *ProductHandler) ReserveStock(c *gin.Context) {
+	var req model.StockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	product, err := h.service.ReserveStock(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.stockError(c, err)
+		return
+	}
+
+	response.OK(c, product)
+}
+
+// ReleaseStock godoc
+// @Summary Release product stock
+// @Description Atomically return reserved units of a product to available stock
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param stock body model.StockRequest true "Units to release"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/stock/release [post]
+func (h *ProductHandler) ReleaseStock(c *gin.Context) {
+	var req model.StockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	product, err := h.service.ReleaseStock(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.stockError(c, err)
+		return
+	}
+
+	response.OK(c, product)
+}
+
+// AdjustStock godoc
+// @Summary Adjust product stock
+// @Description Atomically change the units of a product on hand by a positive or negative delta
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param stock body model.AdjustStockRequest true "Stock adjustment"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/stock/adjust [post]
+func (h *ProductHandler) AdjustStock(c *gin.Context) {
+	var req model.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	product, err := h.service.AdjustStock(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.stockError(c, err)
+		return
+	}
+
+	response.OK(c, product)
+}
+
+// stockError maps stock operation errors to responses
+func (h *ProductHandler) stockError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Product not found")
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, apperror.ErrConflict):
+		response.Conflict(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", c.Param("id")).Error("Failed to update product stock")
+		response.InternalServerError(c, "Failed to update product stock")
+	}
+}
+
+// BulkProducts godoc
+// @Summary Bulk create, update and delete products
+// @Description Apply a batch of product operations all-or-nothing. Every operation is reported; if any fails none are applied.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param operations body model.BulkProductRequest true "Product operations"
+// @Success 200 {object} response.SuccessResponse{data=bulk.Response}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 403 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 422 {object} bulk.Response
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products/bulk [post]
+func (h *ProductHandler) BulkProducts(c *gin.Context) {
+	var req model.BulkProductRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for bulk products")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"operations": len(req.Operations),
+		"request_id": c.GetString("request_id"),
+	}).Info("Applying bulk product operations")
+
+	result, err := h.service.BulkProducts(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to apply bulk product operations")
+		response.InternalServerError(c, "Failed to apply bulk product operations")
+		return
+	}
+
+	if !result.Committed {
+		response.JSON(c, http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	response.OK(c, result)
+}
+
+// ImportProducts godoc
+// @Summary Import products from a file
+// @Description Stream a CSV (with a header row) or NDJSON file, uploaded as the multipart form field "file", and import it row by row. Columns and keys are sku, gtin, name, description, price, currency, categoryId and stockQuantity. Rejected rows are reported by line and do not stop the import. In upsert mode rows update the product with the same SKU and create the others.
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or NDJSON file"
+// @Param format query string false "File format; detected from the file name or content type if omitted" Enums(csv, ndjson)
+// @Param mode query string false "Import mode" Enums(create, upsert) default(create)
+// @Success 200 {object} response.SuccessResponse{data=importer.Report}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products/import [post]
+func (h *ProductHandler) ImportProducts(c *gin.Context) {
+	// Read the multipart body as a stream so large files are never buffered
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		response.BadRequest(c, "Import file is required as a multipart upload in the \"file\" form field")
+		return
+	}
+
+	var file *multipart.Part
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			response.BadRequest(c, "Import file is required as a multipart upload in the \"file\" form field")
+			return
+		}
+		if part.FormName() == "file" {
+			file = part
+			break
+		}
+	}
+	defer file.Close()
+
+	format := c.Query("format")
+	if format == "" {
+		format, err = importer.DetectFormat(file.FileName(), file.Header.Get("Content-Type"))
+		if err != nil {
+			response.BadRequest(c, "Unable to detect the import format, pass format=csv or format=ndjson")
+			return
+		}
+	}
+	mode := c.DefaultQuery("mode", importer.ModeCreate)
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"format": format,
+		"mode":   mode,
+		"file":   file.FileName(),
+	}).Info("Importing products")
+
+	report, err := h.service.ImportProducts(c.Request.Context(), format, mode, file)
+	if err != nil {
+		if errors.Is(err, apperror.ErrValidation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to import products")
+		response.InternalServerError(c, "Failed to import products")
+		return
+	}
+
+	response.OK(c, report)
+}
+
+// ExportProducts godoc
+// @Summary Export products
+// @Description Stream every product matching the list filters as a CSV or NDJSON file. The body is sent in chunks as it is read and gzipped when the client accepts it. CSV columns are id, sku, gtin, name, description, price, currency, categoryId, category, active, stockQuantity, reservedQuantity, availableQuantity, createdAt, updatedAt and deletedAt; NDJSON lines hold the full product with its tags, attributes, images and variants.
+// @Tags products
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string false "File format" Enums(csv, ndjson) default(csv)
+// @Param category query string false "Filter by category name (case-insensitive)"
+// @Param category_id query string false "Filter by category ID, including its subcategories"
+// @Param min_price query number false "Minimum price (inclusive)"
+// @Param max_price query number false "Maximum price (inclusive)"
+// @Param currency query string false "Filter by ISO 4217 currency code"
+// @Param active query bool false "Filter by active flag"
+// @Param tag query []string false "Only products with every one of the tags" collectionFormat(multi)
+// @Param attribute query []string false "Only products with every one of the attribute values, as key:value, e.g. color:red" collectionFormat(multi)
+// @Param created_since query string false "Only entries created at or after this RFC 3339 time"
+// @Param created_before query string false "Only entries created before this RFC 3339 time"
+// @Param updated_since query string false "Only entries updated at or after this RFC 3339 time"
+// @Param updated_before query string false "Only entries updated before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft-deleted entries"
+// @Param sort query string false "Sort order (id, id_desc, name, name_desc, price_asc, price_desc)"
+// @Success 200 {file} file
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products/export [get]
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	format, err := exporter.ParseFormat(c.Query("format"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"format": format,
+		"sort":   filter.Sort,
+	}).Info("Exporting products")
+
+	writer := exporter.NewWriter(c, format, "products", model.ProductCSVHeader)
+	err = h.service.ExportProducts(c.Request.Context(), filter, func(product *model.ProductResponse) error {
+		return writer.Write(product)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Once rows have been sent the status cannot change; the transfer is cut
+	// short so the client does not mistake the rows for the full export
+	if writer.Started() {
+		log.Ctx(c.Request.Context()).WithError(err).WithField("rows", writer.Rows()).Warn("Product export interrupted")
+		writer.Abort()
+		return
+	}
+
+	if errors.Is(err, apperror.ErrValidation) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		response.GatewayTimeout(c, "Request deadline exceeded")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).Error("Failed to export products")
+	response.InternalServerError(c, "Failed to export products")
+}
+
+// RestoreProduct godoc
+// @Summary Restore a product
+// @Description Restore a soft-deleted product
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/restore [post]
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"product_id": id,
+		"request_id": c.GetString("request_id"),
+	}).Info("Restoring product")
+
+	product, err := h.service.RestoreProduct(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			response.NotFound(c, "Product not found")
+			return
+		}
+
+		if errors.Is(err, apperror.ErrConflict) {
+			response.Conflict(c, err.Error())
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).WithField("product_id", id).Error("Failed to restore product")
+		response.InternalServerError(c, "Failed to restore product")
+		return
+	}
+
+	response.OK(c, product)
+}
+
+// GetProductStats godoc
+// @Summary Get product statistics
+// @Description Summarize the products that are not deleted: how many there are, how many are active, how many each category holds and, per currency, the lowest, average and highest price. Averages are rounded half up to the minor unit.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=model.ProductStats}
+// @Failure 500 {object} response.ErrorResponse
+// @Failure 504 {object} response.ErrorResponse
+// @Router /api/v1/products/stats [get]
+func (h *ProductHandler) GetProductStats(c *gin.Context) {
+	stats, err := h.service.GetProductStats(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			response.GatewayTimeout(c, "Request deadline exceeded")
+			return
+		}
+
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to get product stats")
+		response.InternalServerError(c, "Failed to retrieve product statistics")
+		return
+	}
+
+	response.OK(c, stats)
+}
+
+// parseConvertTo reads the currency the client asks prices to be converted
+// into from a query parameter, or "" if it asks for none
+func parseConvertTo(c *gin.Context, param string) (string, error) {
+	value := c.Query(param)
+	if value == "" {
+		return "", nil
+	}
+	code, err := money.NormalizeCurrency(value)
+	if err != nil {
+		return "", model.ErrUnsupportedCurrency
+	}
+	return code, nil
+}
+
+// convertPrices converts the prices of products into a currency, returning
+// when the latest rate it used was published
+func (h *ProductHandler) convertPrices(ctx context.Context, to string, products ...*model.ProductResponse) (time.Time, error) {
+	var asOf time.Time
+	for _, product := range products {
+		rate, err := h.converter.Rate(ctx, product.Currency, to)
+		if err != nil {
+			switch {
+			case errors.Is(err, currency.ErrNoRate):
+				return time.Time{}, model.ErrNoExchangeRate
+			case errors.Is(err, currency.ErrUnavailable):
+				return time.Time{}, model.ErrExchangeRatesUnavailable
+			}
+			return time.Time{}, err
+		}
+		if err := product.ConvertPrices(rate); err != nil {
+			return time.Time{}, err
+		}
+		if rate.AsOf.After(asOf) {
+			asOf = rate.AsOf
+		}
+	}
+	return asOf, nil
+}
+
+// conversionError sends the response for a failed price conversion
+func (h *ProductHandler) conversionError(c *gin.Context, err error) {
+	log.Ctx(c.Request.Context()).WithError(err).Error("Failed to convert prices")
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+		return
+	}
+	response.InternalServerError(c, "Failed to convert prices")
+}
+
+// parseProductFilter builds a product filter from the query parameters
+func parseProductFilter(c *gin.Context) (model.ProductFilter, error) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		return model.ProductFilter{}, err
+	}
+
+	filter := model.ProductFilter{
+		Category: c.Query("category"),
+		Sort:     c.Query("sort"),
+		Page:     page,
+	}
+
+	if value := c.Query("category_id"); value != "" {
+		filter.CategoryIDs = []string{value}
+	}
+
+	if value := c.Query("currency"); value != "" {
+		currency, err := money.NormalizeCurrency(value)
+		if err != nil {
+			return model.ProductFilter{}, model.ErrUnsupportedCurrency
+		}
+		filter.Currency = currency
+	}
+
+	if value := c.Query("min_price"); value != "" {
+		minPrice, ok := new(big.Rat).SetString(value)
+		if !ok || minPrice.Sign() < 0 {
+			return model.ProductFilter{}, errors.New("min_price must be a non-negative number")
+		}
+		filter.MinPrice = minPrice
+	}
+
+	if value := c.Query("max_price"); value != "" {
+		maxPrice, ok := new(big.Rat).SetString(value)
+		if !ok || maxPrice.Sign() < 0 {
+			return model.ProductFilter{}, errors.New("max_price must be a non-negative number")
+		}
+		filter.MaxPrice = maxPrice
+	}
+
+	if filter.MinPrice != nil && filter.MaxPrice != nil && filter.MinPrice.Cmp(filter.MaxPrice) > 0 {
+		return model.ProductFilter{}, errors.New("min_price must not be greater than max_price")
+	}
+
+	if value := c.Query("include_deleted"); value != "" {
+		includeDeleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.ProductFilter{}, errors.New("include_deleted must be true or false")
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	if value := c.Query("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			return model.ProductFilter{}, errors.New("active must be true or false")
+		}
+		filter.Active = &active
+	}
+
+	for _, value := range c.QueryArray("tag") {
+		tag := strings.ToLower(strings.TrimSpace(value))
+		if tag == "" {
+			return model.ProductFilter{}, errors.New("tag must not be empty")
+		}
+		filter.Tags = append(filter.Tags, tag)
+	}
+
+	for _, value := range c.QueryArray("attribute") {
+		attribute, err := model.ParseAttributeFilter(value)
+		if err != nil {
+			return model.ProductFilter{}, err
+		}
+		filter.Attributes = append(filter.Attributes, attribute)
+	}
+
+	if filter.Created, err = timerange.FromQuery(c, "created"); err != nil {
+		return model.ProductFilter{}, err
+	}
+	if filter.Updated, err = timerange.FromQuery(c, "updated"); err != nil {
+		return model.ProductFilter{}, err
+	}
+
+	if !model.IsValidProductSort(filter.Sort) {
+		return model.ProductFilter{}, model.ErrInvalidSort
+	}
+
+	return filter, nil
 }
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left in the body limit of an upload for the
+// multipart framing around the image
+const multipartOverhead = 64 << 10
+
+// uploadTimeout is the handler timeout of uploads, which stream the image
+// from the client to storage
+const uploadTimeout = 2 * time.Minute
+
+// ImageHandler handles HTTP requests for product images
+type ImageHandler struct {
+	service service.ImageService
+	ids     idgen.Generator
+}
+
+// NewImageHandler creates a new image handler. Product IDs in request
+// paths are checked against ids.
+func NewImageHandler(service service.ImageService, ids idgen.Generator) *ImageHandler {
+	return &ImageHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the product image routes. Reads are public; writes
+// go through requireAuth.
+func (h *ImageHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	images := router.Group("/products/:id/images", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		images.GET("", middleware.Cache(catalogCache), h.GetImages)
+		images.POST("", requireAuth, middleware.RaiseBodyLimit(h.service.MaxSize()+multipartOverhead), middleware.RaiseTimeout(uploadTimeout), h.UploadImage)
+		images.DELETE("/:imageId", requireAuth, h.DeleteImage)
+	}
+}
+
+// GetImages godoc
+// @Summary List product images
+// @Description Get the images of a product in upload order
+// @Tags images
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ProductImageResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/images [get]
+func (h *ImageHandler) GetImages(c *gin.Context) {
+	images, err := h.service.GetImages(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.imageError(c, err)
+		return
+	}
+
+	response.OK(c, images)
+}
+
+// UploadImage godoc
+// @Summary Upload a product image
+// @Description Upload a JPEG, PNG, GIF or WebP image as the multipart form field "image". The type is detected from the file content.
+// @Tags images
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param image formData file true "Image file"
+// @Success 201 {object} response.SuccessResponse{data=model.ProductImageResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 413 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/images [post]
+func (h *ImageHandler) UploadImage(c *gin.Context) {
+	header, err := c.FormFile("image")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			response.BadRequest(c, "Image file is required in the \"image\" form field")
+			return
+		}
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for upload image")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to open uploaded image")
+		response.InternalServerError(c, "Failed to process image")
+		return
+	}
+	defer file.Close()
+
+	image, err := h.service.UploadImage(c.Request.Context(), c.Param("id"), file)
+	if err != nil {
+		h.imageError(c, err)
+		return
+	}
+
+	response.Created(c, image)
+}
+
+// DeleteImage godoc
+// @Summary Delete a product image
+// @Description Detach an image from a product and delete its file
+// @Tags images
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/images/{imageId} [delete]
+func (h *ImageHandler) DeleteImage(c *gin.Context) {
+	if err := h.service.DeleteImage(c.Request.Context(), c.Param("id"), c.Param("imageId")); err != nil {
+		h.imageError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Image deleted successfully")
+}
+
+// imageError maps image service errors to responses
+func (h *ImageHandler) imageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrImageNotFound):
+		response.NotFound(c, "Image not found")
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Product not found")
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"product_id": c.Param("id"),
+			"request_id": c.GetString("request_id"),
+		}).Error("Failed to process product image")
+		response.InternalServerError(c, "Failed to process image")
+	}
+}
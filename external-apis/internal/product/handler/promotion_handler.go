@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles HTTP requests for promotions and price quotes
+type PromotionHandler struct {
+	service    service.PromotionService
+	ids        idgen.Generator
+	productIDs idgen.Generator
+}
+
+// NewPromotionHandler creates a new promotion handler. Promotion IDs in
+// request paths are checked against ids and product IDs against productIDs.
+func NewPromotionHandler(service service.PromotionService, ids, productIDs idgen.Generator) *PromotionHandler {
+	return &PromotionHandler{
+		service:    service,
+		ids:        ids,
+		productIDs: productIDs,
+	}
+}
+
+// RegisterRoutes registers the promotion and quote routes. Quotes are public;
+// promotions, which reveal coupon codes, go through requireAuth.
+func (h *PromotionHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	promotions := router.Group("/promotions", middleware.ValidID(h.ids.Valid, "id"), requireAuth)
+	{
+		promotions.GET("", h.GetPromotions)
+		promotions.GET("/:id", h.GetPromotion)
+		promotions.POST("", h.CreatePromotion)
+		promotions.PUT("/:id", h.UpdatePromotion)
+		promotions.DELETE("/:id", h.DeletePromotion)
+	}
+
+	router.POST("/products/:id/quote", middleware.ValidID(h.productIDs.Valid, "id"), h.QuoteProduct)
+	router.POST("/products/quotes", h.QuoteProducts)
+}
+
+// GetPromotions godoc
+// @Summary List promotions
+// @Description Get all promotions, oldest first, whether running or not
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]model.PromotionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/promotions [get]
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	promotions, err := h.service.GetPromotions(c.Request.Context())
+	if err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.OK(c, promotions)
+}
+
+// GetPromotion godoc
+// @Summary Get promotion by ID
+// @Description Get a promotion by its ID
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} response.SuccessResponse{data=model.PromotionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/promotions/{id} [get]
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	promotion, err := h.service.GetPromotion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.OK(c, promotion)
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion
+// @Description Create a percentage or fixed discount, optionally scoped to products, categories and their subcategories, or customers, limited to a date window, or unlocked by a coupon code
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param promotion body model.PromotionRequest true "Promotion data"
+// @Success 201 {object} response.SuccessResponse{data=model.PromotionResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/promotions [post]
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req model.PromotionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create promotion")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	promotion, err := h.service.CreatePromotion(c.Request.Context(), req)
+	if err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.Created(c, promotion)
+}
+
+// UpdatePromotion godoc
+// @Summary Replace a promotion
+// @Description Replace every field of a promotion
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Param promotion body model.PromotionRequest true "Promotion data"
+// @Success 200 {object} response.SuccessResponse{data=model.PromotionResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/promotions/{id} [put]
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	var req model.PromotionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update promotion")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	promotion, err := h.service.UpdatePromotion(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.OK(c, promotion)
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion
+// @Description Delete a promotion; prices already quoted are not affected
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/promotions/{id} [delete]
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	if err := h.service.DeletePromotion(c.Request.Context(), c.Param("id")); err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Promotion deleted successfully")
+}
+
+// QuoteProduct godoc
+// @Summary Quote a product's price
+// @Description Get the price a customer pays for a product: its list price less the largest discount of the running promotions that apply. A coupon code unlocks its promotion and must belong to a running one. The body may be omitted.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param quote body model.QuoteRequest false "Customer and coupon code"
+// @Success 200 {object} response.SuccessResponse{data=model.Quote}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/quote [post]
+func (h *PromotionHandler) QuoteProduct(c *gin.Context) {
+	var req model.QuoteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for quote product")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	quote, err := h.service.QuoteProduct(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.OK(c, quote)
+}
+
+// QuoteProducts godoc
+// @Summary Quote several products' prices
+// @Description Get the prices a customer pays for up to 500 products in one round trip, as for a single quote. Unknown products are left out.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param quote body model.BatchQuoteRequest true "Product IDs, customer and coupon code"
+// @Success 200 {object} response.SuccessResponse{data=[]model.Quote}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/quotes [post]
+func (h *PromotionHandler) QuoteProducts(c *gin.Context) {
+	var req model.BatchQuoteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for quote products")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	quotes, err := h.service.QuoteProducts(c.Request.Context(), req)
+	if err != nil {
+		h.promotionError(c, err)
+		return
+	}
+
+	response.OK(c, quotes)
+}
+
+// promotionError maps promotion service errors to responses
+func (h *PromotionHandler) promotionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrProductNotFound):
+		response.NotFound(c, "Product not found")
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Promotion not found")
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	case errors.Is(err, apperror.ErrConflict):
+		response.Conflict(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"id":         c.Param("id"),
+			"request_id": c.GetString("request_id"),
+		}).Error("Failed to process promotion")
+		response.InternalServerError(c, "Failed to process promotion")
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ReservationHandler handles HTTP requests for stock reservations
+type ReservationHandler struct {
+	service    service.ReservationService
+	ids        idgen.Generator
+	productIDs idgen.Generator
+}
+
+// NewReservationHandler creates a new reservation handler. Reservation IDs
+// in request paths are checked against ids and product IDs against
+// productIDs.
+func NewReservationHandler(service service.ReservationService, ids, productIDs idgen.Generator) *ReservationHandler {
+	return &ReservationHandler{
+		service:    service,
+		ids:        ids,
+		productIDs: productIDs,
+	}
+}
+
+// RegisterRoutes registers the stock reservation routes. Reads are public;
+// writes go through requireAuth.
+func (h *ReservationHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	router.POST("/products/:id/reservations", middleware.ValidID(h.productIDs.Valid, "id"), requireAuth, h.CreateReservation)
+
+	reservations := router.Group("/reservations", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		reservations.GET("", h.GetOrderReservations)
+		reservations.GET("/:id", h.GetReservation)
+		reservations.POST("/:id/confirm", requireAuth, h.ConfirmReservation)
+		reservations.POST("/:id/release", requireAuth, h.ReleaseReservation)
+	}
+}
+
+// CreateReservation godoc
+// @Summary Reserve product stock for an order
+// @Description Atomically reserve units of a product's available stock for an order. The reservation holds the stock until it is confirmed or released; unconfirmed reservations are released automatically when they expire.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param reservation body model.CreateReservationRequest true "Units to reserve and the order they are for"
+// @Success 201 {object} response.SuccessResponse{data=model.Reservation}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reservations [post]
+func (h *ReservationHandler) CreateReservation(c *gin.Context) {
+	var req model.CreateReservationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create reservation")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	reservation, err := h.service.CreateReservation(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.reservationError(c, err)
+		return
+	}
+
+	response.Created(c, reservation)
+}
+
+// GetOrderReservations godoc
+// @Summary List order reservations
+// @Description Get the stock reservations made for an order, oldest first
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param orderId query string true "Order ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.Reservation}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/reservations [get]
+func (h *ReservationHandler) GetOrderReservations(c *gin.Context) {
+	orderID := c.Query("orderId")
+	if orderID == "" {
+		response.BadRequest(c, "orderId is required")
+		return
+	}
+
+	reservations, err := h.service.GetOrderReservations(c.Request.Context(), orderID)
+	if err != nil {
+		h.reservationError(c, err)
+		return
+	}
+
+	response.OK(c, reservations)
+}
+
+// GetReservation godoc
+// @Summary Get a reservation
+// @Description Get a stock reservation by its ID
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} response.SuccessResponse{data=model.Reservation}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/reservations/{id} [get]
+func (h *ReservationHandler) GetReservation(c *gin.Context) {
+	reservation, err := h.service.GetReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.reservationError(c, err)
+		return
+	}
+
+	response.OK(c, reservation)
+}
+
+// ConfirmReservation godoc
+// @Summary Confirm a reservation
+// @Description Keep the stock of an active reservation for its order. Confirmed reservations no longer expire; expired ones can no longer be confirmed.
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} response.SuccessResponse{data=model.Reservation}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/reservations/{id}/confirm [post]
+func (h *ReservationHandler) ConfirmReservation(c *gin.Context) {
+	reservation, err := h.service.ConfirmReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.reservationError(c, err)
+		return
+	}
+
+	response.OK(c, reservation)
+}
+
+// ReleaseReservation godoc
+// @Summary Release a reservation
+// @Description Return the stock of an active or confirmed reservation to available stock
+// @Tags reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} response.SuccessResponse{data=model.Reservation}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/reservations/{id}/release [post]
+func (h *ReservationHandler) ReleaseReservation(c *gin.Context) {
+	reservation, err := h.service.ReleaseReservation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.reservationError(c, err)
+		return
+	}
+
+	response.OK(c, reservation)
+}
+
+// reservationError maps reservation service errors to responses
+func (h *ReservationHandler) reservationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrReservationNotFound):
+		response.NotFound(c, "Reservation not found")
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Product not found")
+	case errors.Is(err, apperror.ErrConflict):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"id":         c.Param("id"),
+			"request_id": c.GetString("request_id"),
+		}).Error("Failed to process stock reservation")
+		response.InternalServerError(c, "Failed to process reservation")
+	}
+}
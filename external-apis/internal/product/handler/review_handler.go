@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler handles HTTP requests for product reviews
+type ReviewHandler struct {
+	service service.ReviewService
+	ids     idgen.Generator
+}
+
+// NewReviewHandler creates a new review handler. Product IDs in request
+// paths are checked against ids.
+func NewReviewHandler(service service.ReviewService, ids idgen.Generator) *ReviewHandler {
+	return &ReviewHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the review routes. Approved reviews are public;
+// writes and the moderation queue go through requireAuth.
+func (h *ReviewHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	reviews := router.Group("/products/:id/reviews", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		reviews.GET("", middleware.Cache(catalogCache), h.GetReviews)
+		reviews.GET("/:reviewId", middleware.Cache(catalogCache), h.GetReview)
+		reviews.POST("", requireAuth, h.CreateReview)
+		reviews.PUT("/:reviewId", requireAuth, h.UpdateReview)
+		reviews.DELETE("/:reviewId", requireAuth, h.DeleteReview)
+		reviews.POST("/:reviewId/status", requireAuth, h.ModerateReview)
+	}
+
+	router.GET("/reviews", requireAuth, h.GetReviewsByStatus)
+}
+
+// GetReviews godoc
+// @Summary List product reviews
+// @Description Get the approved reviews of a product, oldest first
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ReviewResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reviews [get]
+func (h *ReviewHandler) GetReviews(c *gin.Context) {
+	reviews, err := h.service.GetReviews(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.OK(c, reviews)
+}
+
+// GetReview godoc
+// @Summary Get a product review
+// @Description Get an approved review of a product by its ID
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} response.SuccessResponse{data=model.ReviewResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/{reviewId} [get]
+func (h *ReviewHandler) GetReview(c *gin.Context) {
+	review, err := h.service.GetReview(c.Request.Context(), c.Param("id"), c.Param("reviewId"))
+	if err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.OK(c, review)
+}
+
+// GetReviewsByStatus godoc
+// @Summary List reviews by moderation status
+// @Description Get the reviews of every product with a moderation status, oldest first. Without a status, the reviews waiting for moderation are listed.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param status query string false "PENDING (default), APPROVED or REJECTED"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ReviewResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/reviews [get]
+func (h *ReviewHandler) GetReviewsByStatus(c *gin.Context) {
+	status := model.ReviewStatus(c.DefaultQuery("status", string(model.ReviewPending)))
+
+	reviews, err := h.service.GetReviewsByStatus(c.Request.Context(), status)
+	if err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.OK(c, reviews)
+}
+
+// CreateReview godoc
+// @Summary Review a product
+// @Description Rate a product from 1 to 5 on behalf of a customer, with an optional text. A customer reviews a product once. The review is shown and counted in the product's rating once approved.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param review body model.CreateReviewRequest true "Review data"
+// @Success 201 {object} response.SuccessResponse{data=model.ReviewResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reviews [post]
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	var req model.CreateReviewRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create review")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	review, err := h.service.CreateReview(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.Created(c, review)
+}
+
+// UpdateReview godoc
+// @Summary Update a product review
+// @Description Change the rating or text of a review. The review goes back to moderation and leaves the product's rating until approved again.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Param review body model.UpdateReviewRequest true "Review changes"
+// @Success 200 {object} response.SuccessResponse{data=model.ReviewResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/{reviewId} [put]
+func (h *ReviewHandler) UpdateReview(c *gin.Context) {
+	var req model.UpdateReviewRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update review")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	review, err := h.service.UpdateReview(c.Request.Context(), c.Param("id"), c.Param("reviewId"), req)
+	if err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.OK(c, review)
+}
+
+// DeleteReview godoc
+// @Summary Delete a product review
+// @Description Delete a review, taking it out of the product's rating
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/{reviewId} [delete]
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	if err := h.service.DeleteReview(c.Request.Context(), c.Param("id"), c.Param("reviewId")); err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Review deleted successfully")
+}
+
+// ModerateReview godoc
+// @Summary Moderate a product review
+// @Description Approve or reject a review, recording the reason. Approved reviews are shown and counted in the product's rating.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Param status body model.ModerateReviewRequest true "APPROVED or REJECTED, and the reason"
+// @Success 200 {object} response.SuccessResponse{data=model.ReviewResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/{reviewId}/status [post]
+func (h *ReviewHandler) ModerateReview(c *gin.Context) {
+	var req model.ModerateReviewRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for moderate review")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"product_id": c.Param("id"),
+		"review_id":  c.Param("reviewId"),
+		"status":     req.Status,
+		"request_id": c.GetString("request_id"),
+	}).Info("Moderating review")
+
+	review, err := h.service.ModerateReview(c.Request.Context(), c.Param("id"), c.Param("reviewId"), req)
+	if err != nil {
+		h.reviewError(c, err)
+		return
+	}
+
+	response.OK(c, review)
+}
+
+// reviewError maps review service errors to responses
+func (h *ReviewHandler) reviewError(c *gin.Context, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+		"product_id": c.Param("id"),
+		"review_id":  c.Param("reviewId"),
+		"request_id": c.GetString("request_id"),
+	}).Error("Failed to process review")
+	response.InternalServerError(c, "Failed to process review")
+}
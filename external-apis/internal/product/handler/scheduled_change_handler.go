@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"errors"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduledChangeHandler handles HTTP requests for scheduled product changes
+type ScheduledChangeHandler struct {
+	service service.ScheduledChangeService
+	ids     idgen.Generator
+}
+
+// NewScheduledChangeHandler creates a new scheduled change handler. Product IDs in request
+// paths are checked against ids.
+func NewScheduledChangeHandler(service service.ScheduledChangeService, ids idgen.Generator) *ScheduledChangeHandler {
+	return &ScheduledChangeHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the scheduled change routes. Reads are public;
+// writes go through requireAuth.
+func (h *ScheduledChangeHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	changes := router.Group("/products/:id/scheduled-changes", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		changes.GET("", h.GetScheduledChanges)
+		changes.POST("", requireAuth, h.ScheduleChange)
+		changes.DELETE("/:changeId", requireAuth, h.CancelScheduledChange)
+	}
+}
+
+// ScheduleChange godoc
+// @Summary Schedule a product change
+// @Description Schedule a change to a product's price, currency or active status, made by the scheduler once effectiveAt has passed. The price is validated now against the product's currency, or the new one.
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param change body model.ScheduleChangeRequest true "Changes and the time they take effect"
+// @Success 201 {object} response.SuccessResponse{data=model.ScheduledChange}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/scheduled-changes [post]
+func (h *ScheduledChangeHandler) ScheduleChange(c *gin.Context) {
+	var req model.ScheduleChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for schedule product change")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	change, err := h.service.ScheduleChange(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.scheduledChangeError(c, err)
+		return
+	}
+
+	response.Created(c, change)
+}
+
+// GetScheduledChanges godoc
+// @Summary List scheduled product changes
+// @Description Preview the changes scheduled for a product in the order they take effect, along with those already applied, failed or cancelled
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ScheduledChange}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/scheduled-changes [get]
+func (h *ScheduledChangeHandler) GetScheduledChanges(c *gin.Context) {
+	changes, err := h.service.GetScheduledChanges(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.scheduledChangeError(c, err)
+		return
+	}
+
+	response.OK(c, changes)
+}
+
+// CancelScheduledChange godoc
+// @Summary Cancel a scheduled product change
+// @Description Cancel a change that has not taken effect yet
+// @Tags products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param changeId path string true "Scheduled change ID"
+// @Success 200 {object} response.SuccessResponse{data=model.ScheduledChange}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/scheduled-changes/{changeId} [delete]
+func (h *ScheduledChangeHandler) CancelScheduledChange(c *gin.Context) {
+	change, err := h.service.CancelScheduledChange(c.Request.Context(), c.Param("id"), c.Param("changeId"))
+	if err != nil {
+		h.scheduledChangeError(c, err)
+		return
+	}
+
+	response.OK(c, change)
+}
+
+// scheduledChangeError maps scheduled change service errors to responses
+func (h *ScheduledChangeHandler) scheduledChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrScheduledChangeNotFound):
+		response.NotFound(c, "Scheduled change not found")
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Product not found")
+	case errors.Is(err, apperror.ErrConflict):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"product_id": c.Param("id"),
+			"request_id": c.GetString("request_id"),
+		}).Error("Failed to process scheduled product change")
+		response.InternalServerError(c, "Failed to process scheduled change")
+	}
+}
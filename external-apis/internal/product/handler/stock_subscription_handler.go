@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// StockSubscriptionHandler handles HTTP requests for back-in-stock
+// subscriptions
+type StockSubscriptionHandler struct {
+	service service.StockSubscriptionService
+	ids     idgen.Generator
+}
+
+// NewStockSubscriptionHandler creates a new back-in-stock subscription handler. Product IDs in request
+// paths are checked against ids.
+func NewStockSubscriptionHandler(service service.StockSubscriptionService, ids idgen.Generator) *StockSubscriptionHandler {
+	return &StockSubscriptionHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the back-in-stock subscription routes. They hold
+// subscriber emails, so every route goes through requireAuth.
+func (h *StockSubscriptionHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	subscriptions := router.Group("/products/:id/notify-me", middleware.ValidID(h.ids.Valid, "id"), requireAuth)
+	{
+		subscriptions.GET("", h.GetSubscriptions)
+		subscriptions.POST("", h.Subscribe)
+		subscriptions.DELETE("/:subscriptionId", h.Unsubscribe)
+	}
+}
+
+// GetSubscriptions godoc
+// @Summary List back-in-stock subscriptions
+// @Description Get the back-in-stock subscriptions to a product, pending and notified, oldest first
+// @Tags stock-subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.StockSubscriptionResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/notify-me [get]
+func (h *StockSubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	subscriptions, err := h.service.GetSubscriptions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.subscriptionError(c, err)
+		return
+	}
+
+	response.OK(c, subscriptions)
+}
+
+// Subscribe godoc
+// @Summary Subscribe to a product restock
+// @Description Ask for an email to be notified once when an out-of-stock product is back in stock. The subscriber gets an email and a product.back_in_stock event is sent to webhook subscribers. Subscribing again while a subscription is pending returns it with 200.
+// @Tags stock-subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param subscription body model.StockSubscriptionRequest true "Subscriber"
+// @Success 200 {object} response.SuccessResponse{data=model.StockSubscriptionResponse}
+// @Success 201 {object} response.SuccessResponse{data=model.StockSubscriptionResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/notify-me [post]
+func (h *StockSubscriptionHandler) Subscribe(c *gin.Context) {
+	var req model.StockSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for stock subscription")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	subscription, created, err := h.service.Subscribe(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.subscriptionError(c, err)
+		return
+	}
+
+	if created {
+		response.Created(c, subscription)
+		return
+	}
+	response.OK(c, subscription)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from a product restock
+// @Description Remove a back-in-stock subscription
+// @Tags stock-subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param subscriptionId path string true "Subscription ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/notify-me/{subscriptionId} [delete]
+func (h *StockSubscriptionHandler) Unsubscribe(c *gin.Context) {
+	if err := h.service.Unsubscribe(c.Request.Context(), c.Param("id"), c.Param("subscriptionId")); err != nil {
+		h.subscriptionError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Unsubscribed successfully")
+}
+
+// subscriptionError maps stock subscription service errors to responses
+func (h *StockSubscriptionHandler) subscriptionError(c *gin.Context, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+		"product_id":      c.Param("id"),
+		"subscription_id": c.Param("subscriptionId"),
+		"request_id":      c.GetString("request_id"),
+	}).Error("Failed to process stock subscription")
+	response.InternalServerError(c, "Failed to process stock subscription")
+}
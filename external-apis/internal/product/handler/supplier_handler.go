@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler handles HTTP requests for suppliers and the products they
+// supply
+type SupplierHandler struct {
+	service service.SupplierService
+	ids     idgen.Generator
+}
+
+// NewSupplierHandler creates a new supplier handler. Product IDs in the paths
+// of product supplier links are checked against ids.
+func NewSupplierHandler(service service.SupplierService, ids idgen.Generator) *SupplierHandler {
+	return &SupplierHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the supplier routes. Suppliers and their cost
+// prices are internal purchasing data, so every route goes through
+// requireAuth.
+func (h *SupplierHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	suppliers := router.Group("/suppliers", requireAuth)
+	{
+		suppliers.GET("", h.GetSuppliers)
+		suppliers.GET("/:id", h.GetSupplier)
+		suppliers.POST("", h.CreateSupplier)
+		suppliers.PUT("/:id", h.UpdateSupplier)
+		suppliers.DELETE("/:id", h.DeleteSupplier)
+		suppliers.GET("/:id/products", h.GetSupplierProducts)
+	}
+
+	links := router.Group("/products/:id/suppliers", middleware.ValidID(h.ids.Valid, "id"), requireAuth)
+	{
+		links.GET("", h.GetProductSuppliers)
+		links.PUT("/:supplierId", h.LinkProduct)
+		links.DELETE("/:supplierId", h.UnlinkProduct)
+	}
+}
+
+// GetSuppliers godoc
+// @Summary List suppliers
+// @Description Get all suppliers, oldest first
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.SuccessResponse{data=[]model.SupplierResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/suppliers [get]
+func (h *SupplierHandler) GetSuppliers(c *gin.Context) {
+	suppliers, err := h.service.GetSuppliers(c.Request.Context())
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.OK(c, suppliers)
+}
+
+// GetSupplier godoc
+// @Summary Get supplier by ID
+// @Description Get a supplier by its ID
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} response.SuccessResponse{data=model.SupplierResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/suppliers/{id} [get]
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	supplier, err := h.service.GetSupplier(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.OK(c, supplier)
+}
+
+// CreateSupplier godoc
+// @Summary Create a supplier
+// @Description Create a supplier with its contact details and the lead time of its deliveries in days. Supplier names are unique, ignoring case.
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param supplier body model.SupplierRequest true "Supplier data"
+// @Success 201 {object} response.SuccessResponse{data=model.SupplierResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/suppliers [post]
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req model.SupplierRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create supplier")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	supplier, err := h.service.CreateSupplier(c.Request.Context(), req)
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.Created(c, supplier)
+}
+
+// UpdateSupplier godoc
+// @Summary Replace a supplier
+// @Description Replace every field of a supplier
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Param supplier body model.SupplierRequest true "Supplier data"
+// @Success 200 {object} response.SuccessResponse{data=model.SupplierResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/suppliers/{id} [put]
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	var req model.SupplierRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update supplier")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	supplier, err := h.service.UpdateSupplier(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.OK(c, supplier)
+}
+
+// DeleteSupplier godoc
+// @Summary Delete a supplier
+// @Description Delete a supplier and unlink it from the products it supplies
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/suppliers/{id} [delete]
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	if err := h.service.DeleteSupplier(c.Request.Context(), c.Param("id")); err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Supplier deleted successfully")
+}
+
+// GetSupplierProducts godoc
+// @Summary List a supplier's products
+// @Description Get the products a supplier supplies with their cost prices, oldest link first
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Supplier ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ProductSupplierResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/suppliers/{id}/products [get]
+func (h *SupplierHandler) GetSupplierProducts(c *gin.Context) {
+	products, err := h.service.GetSupplierProducts(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.OK(c, products)
+}
+
+// GetProductSuppliers godoc
+// @Summary List a product's suppliers
+// @Description Get the suppliers a product can be purchased from with their cost prices and lead times, oldest link first
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ProductSupplierResponse}
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/suppliers [get]
+func (h *SupplierHandler) GetProductSuppliers(c *gin.Context) {
+	suppliers, err := h.service.GetProductSuppliers(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.OK(c, suppliers)
+}
+
+// LinkProduct godoc
+// @Summary Link a product to a supplier
+// @Description Record that a product can be purchased from a supplier at a cost price, or change the cost price and supplier SKU of an existing link
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param supplierId path string true "Supplier ID"
+// @Param link body model.ProductSupplierRequest true "Cost price and supplier SKU"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductSupplierResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/suppliers/{supplierId} [put]
+func (h *SupplierHandler) LinkProduct(c *gin.Context) {
+	var req model.ProductSupplierRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for link product supplier")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	link, err := h.service.LinkProduct(c.Request.Context(), c.Param("id"), c.Param("supplierId"), req)
+	if err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.OK(c, link)
+}
+
+// UnlinkProduct godoc
+// @Summary Unlink a product from a supplier
+// @Description Remove the link between a product and a supplier
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param supplierId path string true "Supplier ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 401 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/suppliers/{supplierId} [delete]
+func (h *SupplierHandler) UnlinkProduct(c *gin.Context) {
+	if err := h.service.UnlinkProduct(c.Request.Context(), c.Param("id"), c.Param("supplierId")); err != nil {
+		h.supplierError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Product unlinked from supplier successfully")
+}
+
+// supplierError maps supplier service errors to responses
+func (h *SupplierHandler) supplierError(c *gin.Context, err error) {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		response.Error(c, appErr.Status(), appErr.Code(), appErr.Error())
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+		"id":          c.Param("id"),
+		"supplier_id": c.Param("supplierId"),
+		"request_id":  c.GetString("request_id"),
+	}).Error("Failed to process supplier")
+	response.InternalServerError(c, "Failed to process supplier")
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/idgen"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubCategoryService records the categories it is asked for
+type stubCategoryService struct {
+	service.CategoryService
+	looked []string
+}
+
+func (s *stubCategoryService) GetCategory(ctx context.Context, id string) (*model.CategoryResponse, error) {
+	s.looked = append(s.looked, id)
+	return &model.CategoryResponse{ID: id}, nil
+}
+
+// stubPromotionService records the promotions it is asked for
+type stubPromotionService struct {
+	service.PromotionService
+	looked []string
+}
+
+func (s *stubPromotionService) GetPromotion(ctx context.Context, id string) (*model.PromotionResponse, error) {
+	s.looked = append(s.looked, id)
+	return &model.PromotionResponse{ID: id}, nil
+}
+
+// stubReservationService records the reservations it is asked for
+type stubReservationService struct {
+	service.ReservationService
+	looked []string
+}
+
+func (s *stubReservationService) GetReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	s.looked = append(s.looked, id)
+	return &model.Reservation{ID: id}, nil
+}
+
+// serve sends a request without a body through routes registered by register
+func serve(register func(router *gin.RouterGroup, requireAuth gin.HandlerFunc), method, path string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	register(router.Group(""), func(c *gin.Context) { c.Next() })
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestHandlers_ValidID(t *testing.T) {
+	// Strict validators, as a.IDValidator returns them with strict IDs
+	categoryIDs := idgen.Prefixed("cat_", idgen.UUIDv7())
+	promotionIDs := idgen.Prefixed("promo_", idgen.UUIDv7())
+	reservationIDs := idgen.Prefixed("res_", idgen.UUIDv7())
+	productIDs := idgen.Prefixed("prod_", idgen.UUIDv7())
+
+	t.Run("Categories", func(t *testing.T) {
+		// Arrange
+		categories := &stubCategoryService{}
+		h := NewCategoryHandler(categories, categoryIDs)
+		id := categoryIDs.New()
+
+		// Act
+		wellFormed := serve(h.RegisterRoutes, http.MethodGet, "/categories/"+id)
+		malformed := serve(h.RegisterRoutes, http.MethodGet, "/categories/category-electronics")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, wellFormed.Code)
+		assert.Equal(t, http.StatusBadRequest, malformed.Code)
+		assert.Equal(t, []string{id}, categories.looked)
+	})
+
+	t.Run("Promotions", func(t *testing.T) {
+		// Arrange
+		promotions := &stubPromotionService{}
+		h := NewPromotionHandler(promotions, promotionIDs, productIDs)
+		id := promotionIDs.New()
+
+		// Act
+		wellFormed := serve(h.RegisterRoutes, http.MethodGet, "/promotions/"+id)
+		malformed := serve(h.RegisterRoutes, http.MethodGet, "/promotions/"+productIDs.New())
+		malformedProduct := serve(h.RegisterRoutes, http.MethodPost, "/products/product-001/quote")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, wellFormed.Code)
+		assert.Equal(t, http.StatusBadRequest, malformed.Code)
+		assert.Equal(t, http.StatusBadRequest, malformedProduct.Code)
+		assert.Contains(t, malformedProduct.Body.String(), "Malformed id")
+		assert.Equal(t, []string{id}, promotions.looked)
+	})
+
+	t.Run("Reservations", func(t *testing.T) {
+		// Arrange
+		reservations := &stubReservationService{}
+		h := NewReservationHandler(reservations, reservationIDs, productIDs)
+		id := reservationIDs.New()
+
+		// Act
+		wellFormed := serve(h.RegisterRoutes, http.MethodGet, "/reservations/"+id)
+		malformed := serve(h.RegisterRoutes, http.MethodGet, "/reservations/not-an-id")
+		malformedProduct := serve(h.RegisterRoutes, http.MethodPost, "/products/product-001/reservations")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, wellFormed.Code)
+		assert.Equal(t, http.StatusBadRequest, malformed.Code)
+		assert.Equal(t, http.StatusBadRequest, malformedProduct.Code)
+		assert.Contains(t, malformedProduct.Body.String(), "Malformed id")
+		assert.Equal(t, []string{id}, reservations.looked)
+	})
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/service"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// VariantHandler handles HTTP requests for product variants
+type VariantHandler struct {
+	service service.VariantService
+	ids     idgen.Generator
+}
+
+// NewVariantHandler creates a new variant handler. Product IDs in request
+// paths are checked against ids.
+func NewVariantHandler(service service.VariantService, ids idgen.Generator) *VariantHandler {
+	return &VariantHandler{
+		service: service,
+		ids:     ids,
+	}
+}
+
+// RegisterRoutes registers the product variant routes. Reads are public;
+// writes go through requireAuth.
+func (h *VariantHandler) RegisterRoutes(router *gin.RouterGroup, requireAuth gin.HandlerFunc) {
+	variants := router.Group("/products/:id/variants", middleware.ValidID(h.ids.Valid, "id"))
+	{
+		variants.GET("", middleware.Cache(catalogCache), h.GetVariants)
+		variants.GET("/:variantId", middleware.Cache(catalogCache), h.GetVariant)
+		variants.POST("", requireAuth, h.CreateVariant)
+		variants.PUT("/:variantId", requireAuth, h.UpdateVariant)
+		variants.DELETE("/:variantId", requireAuth, h.DeleteVariant)
+	}
+}
+
+// GetVariants godoc
+// @Summary List product variants
+// @Description Get the variants of a product in creation order. Each variant reports the price it sells at.
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} response.SuccessResponse{data=[]model.ProductVariantResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/variants [get]
+func (h *VariantHandler) GetVariants(c *gin.Context) {
+	variants, err := h.service.GetVariants(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.variantError(c, err)
+		return
+	}
+
+	response.OK(c, variants)
+}
+
+// GetVariant godoc
+// @Summary Get a product variant
+// @Description Get a single variant of a product
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductVariantResponse}
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/variants/{variantId} [get]
+func (h *VariantHandler) GetVariant(c *gin.Context) {
+	variant, err := h.service.GetVariant(c.Request.Context(), c.Param("id"), c.Param("variantId"))
+	if err != nil {
+		h.variantError(c, err)
+		return
+	}
+
+	response.OK(c, variant)
+}
+
+// CreateVariant godoc
+// @Summary Add a product variant
+// @Description Add a variant with its own SKU, attributes and stock. SKUs are unique across the catalog and attributes are unique within a product. Without a price the variant sells at the product price.
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variant body model.CreateVariantRequest true "Variant data"
+// @Success 201 {object} response.SuccessResponse{data=model.ProductVariantResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/variants [post]
+func (h *VariantHandler) CreateVariant(c *gin.Context) {
+	var req model.CreateVariantRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for create variant")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	variant, err := h.service.CreateVariant(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		h.variantError(c, err)
+		return
+	}
+
+	response.Created(c, variant)
+}
+
+// UpdateVariant godoc
+// @Summary Update a product variant
+// @Description Update the SKU, attributes, price override or stock of a variant. Attributes are replaced as a whole; inheritPrice removes the price override.
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param variant body model.UpdateVariantRequest true "Variant changes"
+// @Success 200 {object} response.SuccessResponse{data=model.ProductVariantResponse}
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 409 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/variants/{variantId} [put]
+func (h *VariantHandler) UpdateVariant(c *gin.Context) {
+	var req model.UpdateVariantRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Invalid request body for update variant")
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(c.Request.Context(), c.Param("id"), c.Param("variantId"), req)
+	if err != nil {
+		h.variantError(c, err)
+		return
+	}
+
+	response.OK(c, variant)
+}
+
+// DeleteVariant godoc
+// @Summary Delete a product variant
+// @Description Remove a variant from a product
+// @Tags variants
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /api/v1/products/{id}/variants/{variantId} [delete]
+func (h *VariantHandler) DeleteVariant(c *gin.Context) {
+	if err := h.service.DeleteVariant(c.Request.Context(), c.Param("id"), c.Param("variantId")); err != nil {
+		h.variantError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusOK, "Variant deleted successfully")
+}
+
+// variantError maps variant service errors to responses
+func (h *VariantHandler) variantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrVariantNotFound):
+		response.NotFound(c, "Variant not found")
+	case errors.Is(err, apperror.ErrNotFound):
+		response.NotFound(c, "Product not found")
+	case errors.Is(err, apperror.ErrConflict):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, apperror.ErrValidation):
+		response.BadRequest(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithFields(logger.Fields{
+			"product_id": c.Param("id"),
+			"variant_id": c.Param("variantId"),
+		}).Error("Failed to process product variant")
+		response.InternalServerError(c, "Failed to process variant")
+	}
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"external-apis/internal/shared/apperror"
+)
+
+// AttributeType is the type of the value of a product attribute
+type AttributeType string
+
+// Attribute types
+const (
+	AttributeString  AttributeType = "STRING"
+	AttributeNumber  AttributeType = "NUMBER"
+	AttributeBoolean AttributeType = "BOOLEAN"
+)
+
+// IsValid checks if the attribute type is known
+func (t AttributeType) IsValid() bool {
+	switch t {
+	case AttributeString, AttributeNumber, AttributeBoolean:
+		return true
+	default:
+		return false
+	}
+}
+
+// Limits of the tags of a product
+const (
+	MaxTags      = 20
+	MaxTagLength = 50
+)
+
+// Attribute is a typed, queryable property of a product, e.g. a screen size
+// of 15.6. Value holds the canonical text of the value: numbers without
+// trailing zeros and booleans as true or false.
+type Attribute struct {
+	Key string `json:"key" binding:"required,max=64"`
+	// Type may be left out of requests; it is taken from the category schema
+	Type  AttributeType `json:"type,omitempty"`
+	Value string        `json:"value" binding:"required,max=256"`
+}
+
+// Matches checks if the attribute has the value, compared as a number or
+// boolean for attributes of those types and case-insensitively otherwise
+func (a Attribute) Matches(value string) bool {
+	switch a.Type {
+	case AttributeNumber:
+		want, err := parseNumber(value)
+		return err == nil && want == a.Value
+	case AttributeBoolean:
+		want, err := strconv.ParseBool(strings.TrimSpace(value))
+		return err == nil && strconv.FormatBool(want) == a.Value
+	default:
+		return strings.EqualFold(a.Value, strings.TrimSpace(value))
+	}
+}
+
+// AttributeSchema declares an attribute the products of a category may
+// carry. Subcategories inherit the schema of their ancestors and may
+// redeclare an attribute to change it.
+type AttributeSchema struct {
+	Key  string        `json:"key" binding:"required,max=64"`
+	Type AttributeType `json:"type" binding:"required"`
+	// Required attributes must be set on every product of the category
+	Required bool `json:"required,omitempty"`
+	// Values restricts a STRING attribute to a set of values, e.g. colors
+	Values []string `json:"values,omitempty" binding:"omitempty,max=100,dive,max=256"`
+	// Unit documents the unit of a NUMBER attribute, e.g. in or kg
+	Unit string `json:"unit,omitempty" binding:"omitempty,max=16"`
+}
+
+// NormalizeAttributeSchema trims and lower-cases attribute keys, like
+// variant attribute names, and checks that every attribute has a known type,
+// a unique key, and allowed values only if it is a STRING
+func NormalizeAttributeSchema(schema []AttributeSchema) ([]AttributeSchema, error) {
+	normalized := make([]AttributeSchema, 0, len(schema))
+	seen := make(map[string]bool, len(schema))
+	for _, attribute := range schema {
+		attribute.Key = normalizeKey(attribute.Key)
+		attribute.Unit = strings.TrimSpace(attribute.Unit)
+		if attribute.Key == "" {
+			return nil, ErrAttributeKeyRequired
+		}
+		if seen[attribute.Key] {
+			return nil, attributeError("attribute %q is declared more than once", attribute.Key)
+		}
+		seen[attribute.Key] = true
+		if !attribute.Type.IsValid() {
+			return nil, attributeError("attribute %q must have type STRING, NUMBER or BOOLEAN", attribute.Key)
+		}
+
+		if len(attribute.Values) > 0 {
+			if attribute.Type != AttributeString {
+				return nil, attributeError("attribute %q can only restrict its values if it is a STRING", attribute.Key)
+			}
+			values := make([]string, 0, len(attribute.Values))
+			for _, value := range attribute.Values {
+				value = strings.TrimSpace(value)
+				if value == "" {
+					return nil, attributeError("attribute %q has an empty allowed value", attribute.Key)
+				}
+				values = append(values, value)
+			}
+			attribute.Values = values
+		}
+		normalized = append(normalized, attribute)
+	}
+	return normalized, nil
+}
+
+// InheritAttributeSchema merges the schemas of a category's lineage, top
+// level first, into the schema its products follow. A subcategory's
+// declaration replaces an inherited one with the same key.
+func InheritAttributeSchema(lineage []*Category) []AttributeSchema {
+	var schema []AttributeSchema
+	for _, category := range lineage {
+		for _, attribute := range category.Attributes {
+			i := slices.IndexFunc(schema, func(declared AttributeSchema) bool { return declared.Key == attribute.Key })
+			if i >= 0 {
+				schema[i] = attribute
+			} else {
+				schema = append(schema, attribute)
+			}
+		}
+	}
+	return schema
+}
+
+// ValidateAttributes checks product attributes against the schema of its
+// category and returns them in canonical form, sorted by key. Every
+// attribute must be declared by the schema, with a value of its type, and
+// every required attribute must be set.
+func ValidateAttributes(schema []AttributeSchema, attributes []Attribute) ([]Attribute, error) {
+	declared := make(map[string]AttributeSchema, len(schema))
+	for _, attribute := range schema {
+		declared[attribute.Key] = attribute
+	}
+
+	validated := make([]Attribute, 0, len(attributes))
+	seen := make(map[string]bool, len(attributes))
+	for _, attribute := range attributes {
+		key := normalizeKey(attribute.Key)
+		if key == "" {
+			return nil, ErrAttributeKeyRequired
+		}
+		if seen[key] {
+			return nil, attributeError("attribute %q is set more than once", key)
+		}
+		seen[key] = true
+
+		declaration, ok := declared[key]
+		if !ok {
+			return nil, attributeError("attribute %q is not defined for the category", key)
+		}
+		if attribute.Type != "" && attribute.Type != declaration.Type {
+			return nil, attributeError("attribute %q must be a %s", key, declaration.Type)
+		}
+		value, err := declaration.canonical(attribute.Value)
+		if err != nil {
+			return nil, err
+		}
+		validated = append(validated, Attribute{Key: key, Type: declaration.Type, Value: value})
+	}
+
+	for _, attribute := range schema {
+		if attribute.Required && !seen[attribute.Key] {
+			return nil, attributeError("attribute %q is required by the category", attribute.Key)
+		}
+	}
+
+	slices.SortFunc(validated, func(a, b Attribute) int { return strings.Compare(a.Key, b.Key) })
+	return validated, nil
+}
+
+// canonical checks that a value has the type of the attribute and is allowed
+// by it, and returns its canonical text
+func (s AttributeSchema) canonical(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch s.Type {
+	case AttributeNumber:
+		number, err := parseNumber(value)
+		if err != nil {
+			return "", attributeError("attribute %q must be a NUMBER", s.Key)
+		}
+		return number, nil
+	case AttributeBoolean:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", attributeError("attribute %q must be a BOOLEAN", s.Key)
+		}
+		return strconv.FormatBool(boolean), nil
+	}
+
+	if value == "" {
+		return "", attributeError("attribute %q must not be empty", s.Key)
+	}
+	if len(s.Values) > 0 {
+		i := slices.IndexFunc(s.Values, func(allowed string) bool { return strings.EqualFold(allowed, value) })
+		if i < 0 {
+			return "", attributeError("attribute %q must be one of %s", s.Key, strings.Join(s.Values, ", "))
+		}
+		return s.Values[i], nil
+	}
+	return value, nil
+}
+
+// AttributeFilter matches products whose attribute Key has Value
+type AttributeFilter struct {
+	Key   string
+	Value string
+}
+
+// ParseAttributeFilter parses a "<key>:<value>" attribute filter, such as
+// "color:red" or "screen_size:15.6"
+func ParseAttributeFilter(text string) (AttributeFilter, error) {
+	key, value, ok := strings.Cut(text, ":")
+	filter := AttributeFilter{Key: normalizeKey(key), Value: strings.TrimSpace(value)}
+	if !ok || filter.Key == "" || filter.Value == "" {
+		return AttributeFilter{}, fmt.Errorf("invalid attribute filter %q, use key:value", text)
+	}
+	return filter, nil
+}
+
+// Values returns the stored forms the value of the filter may take: its
+// lower-cased text and, if it is a number or boolean, its canonical text
+func (f AttributeFilter) Values() []string {
+	values := []string{strings.ToLower(f.Value)}
+	if number, err := parseNumber(f.Value); err == nil && number != values[0] {
+		values = append(values, number)
+	}
+	if boolean, err := strconv.ParseBool(f.Value); err == nil && strconv.FormatBool(boolean) != values[0] {
+		values = append(values, strconv.FormatBool(boolean))
+	}
+	return values
+}
+
+// Matches checks if the product has the attribute with the value
+func (f AttributeFilter) Matches(p *Product) bool {
+	for _, attribute := range p.Attributes {
+		if attribute.Key == f.Key {
+			return attribute.Matches(f.Value)
+		}
+	}
+	return false
+}
+
+// NormalizeTags trims and lower-cases tags and drops duplicates, keeping
+// the first occurrence of each
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, ErrTagEmpty
+		}
+		if len(tag) > MaxTagLength {
+			return nil, ErrTagTooLong
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxTags {
+		return nil, ErrTooManyTags
+	}
+	return normalized, nil
+}
+
+// normalizeKey trims and lower-cases an attribute key
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// parseNumber parses a finite decimal number into its canonical text
+func parseNumber(value string) (string, error) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+		return "", strconv.ErrSyntax
+	}
+	return strconv.FormatFloat(number, 'f', -1, 64), nil
+}
+
+// attributeError creates a validation error naming the offending attribute
+func attributeError(format string, args ...interface{}) error {
+	return apperror.Validation(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,187 @@
+package model
+
+import (
+	"testing"
+
+	"external-apis/internal/shared/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeAttributeSchema(t *testing.T) {
+	t.Run("Normalize keys and allowed values", func(t *testing.T) {
+		// Act
+		schema, err := NormalizeAttributeSchema([]AttributeSchema{
+			{Key: " Color ", Type: AttributeString, Values: []string{" red ", "blue"}},
+			{Key: "Weight", Type: AttributeNumber, Unit: " kg ", Required: true},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []AttributeSchema{
+			{Key: "color", Type: AttributeString, Values: []string{"red", "blue"}},
+			{Key: "weight", Type: AttributeNumber, Unit: "kg", Required: true},
+		}, schema)
+	})
+
+	tests := []struct {
+		name   string
+		schema []AttributeSchema
+		err    string
+	}{
+		{"Missing key", []AttributeSchema{{Key: " ", Type: AttributeString}}, "attribute key is required"},
+		{"Duplicate key", []AttributeSchema{{Key: "color", Type: AttributeString}, {Key: "COLOR", Type: AttributeString}}, `attribute "color" is declared more than once`},
+		{"Unknown type", []AttributeSchema{{Key: "color", Type: "COLOUR"}}, `attribute "color" must have type STRING, NUMBER or BOOLEAN`},
+		{"Values of a number", []AttributeSchema{{Key: "weight", Type: AttributeNumber, Values: []string{"1"}}}, `attribute "weight" can only restrict its values if it is a STRING`},
+		{"Empty allowed value", []AttributeSchema{{Key: "color", Type: AttributeString, Values: []string{""}}}, `attribute "color" has an empty allowed value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := NormalizeAttributeSchema(tt.schema)
+
+			// Assert
+			assert.EqualError(t, err, tt.err)
+			assert.ErrorIs(t, err, apperror.ErrValidation)
+		})
+	}
+}
+
+func TestInheritAttributeSchema(t *testing.T) {
+	// Arrange
+	lineage := []*Category{
+		{ID: "category-electronics", Attributes: []AttributeSchema{
+			{Key: "color", Type: AttributeString},
+			{Key: "weight", Type: AttributeNumber},
+		}},
+		{ID: "category-computers", Attributes: []AttributeSchema{
+			{Key: "weight", Type: AttributeNumber, Required: true},
+			{Key: "screen_size", Type: AttributeNumber},
+		}},
+	}
+
+	// Act
+	schema := InheritAttributeSchema(lineage)
+
+	// Assert
+	assert.Equal(t, []AttributeSchema{
+		{Key: "color", Type: AttributeString},
+		{Key: "weight", Type: AttributeNumber, Required: true},
+		{Key: "screen_size", Type: AttributeNumber},
+	}, schema)
+}
+
+func TestValidateAttributes(t *testing.T) {
+	schema := []AttributeSchema{
+		{Key: "color", Type: AttributeString, Values: []string{"Red", "Blue"}},
+		{Key: "screen_size", Type: AttributeNumber, Required: true},
+		{Key: "wireless", Type: AttributeBoolean},
+		{Key: "model", Type: AttributeString},
+	}
+
+	t.Run("Canonical values sorted by key", func(t *testing.T) {
+		// Act
+		attributes, err := ValidateAttributes(schema, []Attribute{
+			{Key: "Wireless", Value: "TRUE"},
+			{Key: "screen_size", Type: AttributeNumber, Value: " 15.60 "},
+			{Key: "color", Value: "red"},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []Attribute{
+			{Key: "color", Type: AttributeString, Value: "Red"},
+			{Key: "screen_size", Type: AttributeNumber, Value: "15.6"},
+			{Key: "wireless", Type: AttributeBoolean, Value: "true"},
+		}, attributes)
+	})
+
+	tests := []struct {
+		name       string
+		attributes []Attribute
+		err        string
+	}{
+		{"Undeclared attribute", []Attribute{{Key: "screen_size", Value: "15"}, {Key: "weight", Value: "2"}}, `attribute "weight" is not defined for the category`},
+		{"Set twice", []Attribute{{Key: "screen_size", Value: "15"}, {Key: "Screen_Size", Value: "16"}}, `attribute "screen_size" is set more than once`},
+		{"Other type", []Attribute{{Key: "screen_size", Type: AttributeString, Value: "15"}}, `attribute "screen_size" must be a NUMBER`},
+		{"Not a number", []Attribute{{Key: "screen_size", Value: "large"}}, `attribute "screen_size" must be a NUMBER`},
+		{"Not a boolean", []Attribute{{Key: "screen_size", Value: "15"}, {Key: "wireless", Value: "maybe"}}, `attribute "wireless" must be a BOOLEAN`},
+		{"Value not allowed", []Attribute{{Key: "screen_size", Value: "15"}, {Key: "color", Value: "green"}}, `attribute "color" must be one of Red, Blue`},
+		{"Empty string", []Attribute{{Key: "screen_size", Value: "15"}, {Key: "model", Value: " "}}, `attribute "model" must not be empty`},
+		{"Missing required attribute", nil, `attribute "screen_size" is required by the category`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := ValidateAttributes(schema, tt.attributes)
+
+			// Assert
+			assert.EqualError(t, err, tt.err)
+			assert.ErrorIs(t, err, apperror.ErrValidation)
+		})
+	}
+}
+
+func TestParseAttributeFilter(t *testing.T) {
+	t.Run("Key and value", func(t *testing.T) {
+		// Act
+		filter, err := ParseAttributeFilter(" Screen_Size : 15.6")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, AttributeFilter{Key: "screen_size", Value: "15.6"}, filter)
+		assert.Equal(t, []string{"15.6"}, filter.Values())
+	})
+
+	t.Run("Values of a number and boolean", func(t *testing.T) {
+		// Act
+		filter, err := ParseAttributeFilter("wireless:1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1", "true"}, filter.Values())
+	})
+
+	for _, text := range []string{"color", "color:", ":red"} {
+		t.Run("Invalid "+text, func(t *testing.T) {
+			// Act
+			_, err := ParseAttributeFilter(text)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("Lower-case without duplicates", func(t *testing.T) {
+		// Act
+		tags, err := NormalizeTags([]string{" Sale", "eco", "SALE"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sale", "eco"}, tags)
+	})
+
+	t.Run("Empty tag", func(t *testing.T) {
+		// Act
+		_, err := NormalizeTags([]string{"sale", " "})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrTagEmpty)
+	})
+
+	t.Run("Too many tags", func(t *testing.T) {
+		// Arrange
+		tags := make([]string, MaxTags+1)
+		for i := range tags {
+			tags[i] = string(rune('a' + i))
+		}
+
+		// Act
+		_, err := NormalizeTags(tags)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrTooManyTags)
+	})
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// catalogUpdateSchemaJSON is the JSON schema catalog updates are validated
+// against
+//
+//go:embed catalog_update.schema.json
+var catalogUpdateSchemaJSON string
+
+// catalogUpdateSchema is catalogUpdateSchemaJSON compiled
+var catalogUpdateSchema = jsonschema.MustCompileString("catalog_update.schema.json", catalogUpdateSchemaJSON)
+
+// CatalogUpdate is a change to a product sent by a supplier. The product is
+// matched by SKU; fields left out are kept unchanged, and StockQuantity sets
+// the units on hand.
+type CatalogUpdate struct {
+	// Supplier identifies the sender, for tracing the update back
+	Supplier string `json:"supplier"`
+	// Tenant is the tenant whose catalog is updated, or the default tenant
+	Tenant        string       `json:"tenant,omitempty"`
+	SKU           string       `json:"sku"`
+	GTIN          *string      `json:"gtin,omitempty"`
+	Name          *string      `json:"name,omitempty"`
+	Description   *string      `json:"description,omitempty"`
+	Price         *json.Number `json:"price,omitempty"`
+	Currency      *string      `json:"currency,omitempty"`
+	CategoryID    *string      `json:"categoryId,omitempty"`
+	StockQuantity *int         `json:"stockQuantity,omitempty"`
+}
+
+// ParseCatalogUpdate validates a message against the catalog update schema and
+// decodes it. Errors wrap ErrInvalidCatalogUpdate and name the first field
+// at fault.
+func ParseCatalogUpdate(body []byte) (CatalogUpdate, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return CatalogUpdate{}, fmt.Errorf("%w: %v", ErrInvalidCatalogUpdate, err)
+	}
+	if err := catalogUpdateSchema.Validate(document); err != nil {
+		return CatalogUpdate{}, fmt.Errorf("%w: %s", ErrInvalidCatalogUpdate, schemaViolation(err))
+	}
+
+	var update CatalogUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		return CatalogUpdate{}, fmt.Errorf("%w: %v", ErrInvalidCatalogUpdate, err)
+	}
+	return update, nil
+}
+
+// CanCreate checks if the update has every field needed to create a product
+func (u CatalogUpdate) CanCreate() bool {
+	return u.Name != nil && u.Description != nil && u.Price != nil && u.CategoryID != nil
+}
+
+// CreateRequest converts the update into a request creating the product.
+// It must only be called when CanCreate is true.
+func (u CatalogUpdate) CreateRequest() CreateProductRequest {
+	req := CreateProductRequest{
+		SKU:         u.SKU,
+		Name:        *u.Name,
+		Description: *u.Description,
+		Price:       *u.Price,
+		CategoryID:  *u.CategoryID,
+	}
+	if u.GTIN != nil {
+		req.GTIN = *u.GTIN
+	}
+	if u.Currency != nil {
+		req.Currency = *u.Currency
+	}
+	if u.StockQuantity != nil {
+		req.StockQuantity = *u.StockQuantity
+	}
+	return req
+}
+
+// UpdateRequest converts the update into a request updating the existing
+// product. The stock is left to AdjustStock, so reservations are respected.
+func (u CatalogUpdate) UpdateRequest() UpdateProductRequest {
+	return UpdateProductRequest{
+		GTIN:        u.GTIN,
+		Name:        u.Name,
+		Description: u.Description,
+		Price:       u.Price,
+		Currency:    u.Currency,
+		CategoryID:  u.CategoryID,
+	}
+}
+
+// schemaViolation describes the first violation of a schema validation
+// error, e.g. "/price: must be > 0"
+func schemaViolation(err error) string {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err.Error()
+	}
+
+	leaf := validationErr
+	for len(leaf.Causes) > 0 {
+		leaf = leaf.Causes[0]
+	}
+	location := leaf.InstanceLocation
+	if location == "" {
+		location = "message"
+	}
+	return strings.TrimSpace(location + ": " + leaf.Message)
+}
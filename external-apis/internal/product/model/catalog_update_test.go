@@ -0,0 +1,105 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCatalogUpdate(t *testing.T) {
+	t.Run("Full update", func(t *testing.T) {
+		// Act
+		update, err := ParseCatalogUpdate([]byte(`{
+			"supplier": "acme",
+			"tenant": "brand-a",
+			"sku": "MOUSE-1",
+			"gtin": "4006381333931",
+			"name": "Wireless Mouse",
+			"description": "Ergonomic wireless mouse",
+			"price": 29.99,
+			"currency": "EUR",
+			"categoryId": "electronics",
+			"stockQuantity": 40
+		}`))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "acme", update.Supplier)
+		assert.Equal(t, "brand-a", update.Tenant)
+		assert.Equal(t, "MOUSE-1", update.SKU)
+		assert.Equal(t, json.Number("29.99"), *update.Price)
+		assert.Equal(t, 40, *update.StockQuantity)
+		assert.True(t, update.CanCreate())
+	})
+
+	t.Run("Partial update", func(t *testing.T) {
+		// Act
+		update, err := ParseCatalogUpdate([]byte(`{"supplier":"acme","sku":"MOUSE-1","price":24.5}`))
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, update.CanCreate())
+		req := update.UpdateRequest()
+		assert.Equal(t, json.Number("24.5"), *req.Price)
+		assert.Nil(t, req.Name)
+		assert.Nil(t, req.CategoryID)
+	})
+
+	invalid := []struct {
+		name    string
+		body    string
+		message string
+	}{
+		{"Not JSON", `sku=MOUSE-1`, "invalid character"},
+		{"Not an object", `["MOUSE-1"]`, "message: "},
+		{"Missing SKU", `{"supplier":"acme"}`, `missing properties: 'sku'`},
+		{"Missing supplier", `{"sku":"MOUSE-1"}`, `missing properties: 'supplier'`},
+		{"Negative price", `{"supplier":"acme","sku":"MOUSE-1","price":-1}`, "/price: "},
+		{"Price as text", `{"supplier":"acme","sku":"MOUSE-1","price":"cheap"}`, "/price: "},
+		{"Fractional stock", `{"supplier":"acme","sku":"MOUSE-1","stockQuantity":1.5}`, "/stockQuantity: "},
+		{"Lowercase currency", `{"supplier":"acme","sku":"MOUSE-1","currency":"eur"}`, "/currency: "},
+		{"Invalid tenant", `{"supplier":"acme","sku":"MOUSE-1","tenant":"Brand A"}`, "/tenant: "},
+		{"Unknown field", `{"supplier":"acme","sku":"MOUSE-1","colour":"black"}`, "additionalProperties 'colour' not allowed"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := ParseCatalogUpdate([]byte(tt.body))
+
+			// Assert
+			require.ErrorIs(t, err, ErrInvalidCatalogUpdate)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestCatalogUpdate_CreateRequest(t *testing.T) {
+	// Arrange
+	name, description, category := "Wireless Mouse", "Ergonomic wireless mouse", "electronics"
+	price := json.Number("29.99")
+	stock := 40
+	update := CatalogUpdate{
+		Supplier:      "acme",
+		SKU:           "MOUSE-1",
+		Name:          &name,
+		Description:   &description,
+		Price:         &price,
+		CategoryID:    &category,
+		StockQuantity: &stock,
+	}
+
+	// Act
+	req := update.CreateRequest()
+
+	// Assert
+	assert.Equal(t, CreateProductRequest{
+		SKU:           "MOUSE-1",
+		Name:          name,
+		Description:   description,
+		Price:         price,
+		CategoryID:    category,
+		StockQuantity: 40,
+	}, req)
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Category groups products in the catalog. Categories form a tree: a category
+// without a parent is a top-level category.
+type Category struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ParentID    string `json:"parentId,omitempty"`
+	// Attributes declares the attributes of the category's products, on top
+	// of those its ancestors declare
+	Attributes []AttributeSchema `json:"attributes,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// Clone returns a deep copy of the category and its attribute schema
+func (c *Category) Clone() *Category {
+	clone := *c
+	if c.Attributes != nil {
+		clone.Attributes = make([]AttributeSchema, len(c.Attributes))
+		for i, attribute := range c.Attributes {
+			attribute.Values = slices.Clone(attribute.Values)
+			clone.Attributes[i] = attribute
+		}
+	}
+	return &clone
+}
+
+// Normalize trims whitespace from the category fields
+func (c *Category) Normalize() {
+	c.Name = strings.TrimSpace(c.Name)
+	c.Description = strings.TrimSpace(c.Description)
+	c.ParentID = strings.TrimSpace(c.ParentID)
+}
+
+// CategoryResponse represents the API response for a category. Path lists the
+// names of the category's ancestors from the top level down, followed by its
+// own name. Attributes only lists the attributes the category declares
+// itself.
+type CategoryResponse struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	ParentID    string            `json:"parentId,omitempty"`
+	Path        []string          `json:"path"`
+	Attributes  []AttributeSchema `json:"attributes"`
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+// ToResponse converts a Category to CategoryResponse with the given path
+func (c *Category) ToResponse(path []string) CategoryResponse {
+	return CategoryResponse{
+		ID:          c.ID,
+		Name:        c.Name,
+		Description: c.Description,
+		ParentID:    c.ParentID,
+		Path:        path,
+		Attributes:  append(make([]AttributeSchema, 0, len(c.Attributes)), c.Attributes...),
+		CreatedAt:   c.CreatedAt,
+	}
+}
+
+// CreateCategoryRequest represents the request to create a category
+type CreateCategoryRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	// ParentID makes the new category a subcategory of an existing one
+	ParentID string `json:"parentId,omitempty"`
+	// Attributes declares the attributes of the category's products
+	Attributes []AttributeSchema `json:"attributes,omitempty" binding:"omitempty,max=50,dive"`
+}
+
+// UpdateCategoryRequest represents the request to update a category. An
+// empty parent ID moves the category to the top level. Attributes replaces
+// the whole attribute schema; products already in the category are only
+// checked against it when their attributes or category change.
+type UpdateCategoryRequest struct {
+	Name        *string            `json:"name,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	ParentID    *string            `json:"parentId,omitempty"`
+	Attributes  *[]AttributeSchema `json:"attributes,omitempty" binding:"omitempty,max=50,dive"`
+}
+
+// CategoryTree indexes categories by ID to walk their hierarchy
+type CategoryTree map[string]*Category
+
+// NewCategoryTree indexes the given categories
+func NewCategoryTree(categories []*Category) CategoryTree {
+	tree := make(CategoryTree, len(categories))
+	for _, category := range categories {
+		tree[category.ID] = category
+	}
+	return tree
+}
+
+// Path returns the names from the top-level ancestor down to the category
+func (t CategoryTree) Path(id string) []string {
+	// The length check stops at corrupted, cyclic parent links
+	path := make([]string, 0)
+	for category := t[id]; category != nil && len(path) <= len(t); category = t[category.ParentID] {
+		path = append([]string{category.Name}, path...)
+	}
+	return path
+}
+
+// Descendants returns the ID of the category followed by the IDs of all of
+// its subcategories, at any depth
+func (t CategoryTree) Descendants(id string) []string {
+	children := make(map[string][]string, len(t))
+	for _, category := range t {
+		if category.ParentID != "" {
+			children[category.ParentID] = append(children[category.ParentID], category.ID)
+		}
+	}
+
+	ids := []string{id}
+	seen := map[string]bool{id: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+// IsAncestor checks if ancestorID is id itself or one of its ancestors
+func (t CategoryTree) IsAncestor(ancestorID, id string) bool {
+	for category, depth := t[id], 0; category != nil && depth <= len(t); category, depth = t[category.ParentID], depth+1 {
+		if category.ID == ancestorID {
+			return true
+		}
+	}
+	return false
+}
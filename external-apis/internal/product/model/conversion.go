@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"external-apis/internal/shared/currency"
+	"external-apis/internal/shared/money"
+)
+
+// ConvertedPrice is a price converted into the currency the client asked
+// for, with the exchange rate used and when it was published. Price is an
+// exact decimal number in units of Currency.
+type ConvertedPrice struct {
+	Price         json.Number `json:"price" swaggertype:"number"`
+	Currency      string      `json:"currency"`
+	Rate          json.Number `json:"rate" swaggertype:"number"`
+	RateTimestamp time.Time   `json:"rateTimestamp"`
+	RateSource    string      `json:"rateSource"`
+}
+
+// NewConvertedPrice converts a decimal price of a currency at rate
+func NewConvertedPrice(price json.Number, code string, rate currency.Rate) (*ConvertedPrice, error) {
+	amount, err := money.Parse(price.String(), code)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := rate.Convert(amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConvertedPrice{
+		Price:         converted.Number(),
+		Currency:      converted.Currency,
+		Rate:          rate.Number(),
+		RateTimestamp: rate.AsOf,
+		RateSource:    rate.Source,
+	}, nil
+}
+
+// ConvertPrices sets the converted prices of the product and its variants.
+// The rate must be from the currency of the product.
+func (r *ProductResponse) ConvertPrices(rate currency.Rate) error {
+	converted, err := NewConvertedPrice(r.Price, r.Currency, rate)
+	if err != nil {
+		return err
+	}
+	r.ConvertedPrice = converted
+
+	for i := range r.Variants {
+		variant := &r.Variants[i]
+		if variant.ConvertedPrice, err = NewConvertedPrice(variant.Price, variant.Currency, rate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
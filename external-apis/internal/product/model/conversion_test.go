@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/currency"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductResponse_ConvertPrices(t *testing.T) {
+	asOf := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	rate := currency.Rate{From: "USD", To: "EUR", Value: big.NewRat(92, 100), AsOf: asOf, Source: "static"}
+	override := money.New(3499, "USD")
+	product := &Product{
+		ID:    "product-123",
+		Price: money.New(2999, "USD"),
+		Variants: []ProductVariant{
+			{ID: "variant-1", SKU: "MOUSE-BLACK"},
+			{ID: "variant-2", SKU: "MOUSE-GOLD", Price: &override},
+		},
+	}
+
+	t.Run("Product and variant prices", func(t *testing.T) {
+		// Arrange
+		response := product.ToResponse()
+
+		// Act
+		err := response.ConvertPrices(rate)
+
+		// Assert
+		require.NoError(t, err)
+		require.NotNil(t, response.ConvertedPrice)
+		assert.Equal(t, &ConvertedPrice{
+			Price:         json.Number("27.59"),
+			Currency:      "EUR",
+			Rate:          json.Number("0.92"),
+			RateTimestamp: asOf,
+			RateSource:    "static",
+		}, response.ConvertedPrice)
+		assert.Equal(t, json.Number("27.59"), response.Variants[0].ConvertedPrice.Price)
+		assert.Equal(t, json.Number("32.19"), response.Variants[1].ConvertedPrice.Price)
+		assert.Equal(t, json.Number("29.99"), response.Price, "the price itself is kept")
+	})
+
+	t.Run("Rate from another currency", func(t *testing.T) {
+		// Arrange
+		response := product.ToResponse()
+		response.Currency = "GBP"
+
+		// Act
+		err := response.ConvertPrices(rate)
+
+		// Assert
+		assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	})
+}
@@ -0,0 +1,149 @@
+package model
+
+import "external-apis/internal/shared/apperror"
+
+// Product errors
+var (
+	ErrProductNotFound   = apperror.NotFound("product not found")
+	ErrProductExists     = apperror.Conflict("product already exists")
+	ErrProductNotDeleted = apperror.Conflict("product is not deleted")
+	ErrInvalidSort       = apperror.Validation("invalid sort option")
+	ErrSearchQueryEmpty  = apperror.Validation("search query is required")
+	ErrSearchQueryLong   = apperror.Validation("search query is too long")
+)
+
+// Catalog update errors
+var (
+	ErrInvalidCatalogUpdate    = apperror.Validation("catalog update does not follow its schema")
+	ErrIncompleteCatalogUpdate = apperror.Validation("name, description, price and categoryId are required to create a product")
+)
+
+// Barcode errors
+var (
+	ErrGTINExists  = apperror.Conflict("GTIN is already in use")
+	ErrInvalidGTIN = apperror.Validation("gtin must be an 8, 12, 13 or 14 digit GTIN with a valid check digit")
+)
+
+// Shipping measurement errors
+var (
+	ErrInvalidWeight     = apperror.Validation("weight must be between 0 and 1000000 grams")
+	ErrInvalidDimensions = apperror.Validation("every dimension must be between 1 and 10000 millimetres")
+	ErrClearDimensions   = apperror.Validation("dimensions cannot be set and cleared at once")
+)
+
+// Category errors
+var (
+	ErrCategoryNotFound       = apperror.NotFound("category not found")
+	ErrCategoryExists         = apperror.Conflict("category already exists")
+	ErrCategoryHasChildren    = apperror.Conflict("category has subcategories")
+	ErrCategoryInUse          = apperror.Conflict("category has products")
+	ErrCategoryNameRequired   = apperror.Validation("category name is required")
+	ErrParentCategoryNotFound = apperror.Validation("parent category does not exist")
+	ErrCategoryCycle          = apperror.Validation("category cannot be moved below itself")
+	ErrUnknownCategory        = apperror.Validation("category does not exist")
+)
+
+// Price errors
+var (
+	ErrUnsupportedCurrency = apperror.Validation("unsupported currency")
+	ErrPriceTooPrecise     = apperror.Validation("price has too many decimal places for currency")
+	ErrInvalidPrice        = apperror.Validation("invalid price")
+	ErrPriceNotPositive    = apperror.Validation("price must be greater than 0")
+)
+
+// Stock errors
+var (
+	ErrNegativeStock       = apperror.Validation("stock quantity must not be negative")
+	ErrInvalidQuantity     = apperror.Validation("quantity must be greater than 0")
+	ErrZeroDelta           = apperror.Validation("delta must not be zero")
+	ErrProductInactive     = apperror.Conflict("product is not active")
+	ErrInsufficientStock   = apperror.Conflict("insufficient stock")
+	ErrReleaseExceedsStock = apperror.Conflict("release exceeds reserved stock")
+	ErrBelowReserved       = apperror.Conflict("stock cannot drop below reserved quantity")
+)
+
+// Reservation errors
+var (
+	ErrReservationNotFound  = apperror.NotFound("reservation not found")
+	ErrReservationExists    = apperror.Conflict("reservation already exists")
+	ErrReservationNotActive = apperror.Conflict("reservation is no longer active")
+	ErrReservationExpired   = apperror.Conflict("reservation has expired")
+	ErrReservationTTL       = apperror.Validation("reservation TTL exceeds the maximum")
+)
+
+// Scheduled change errors
+var (
+	ErrScheduledChangeNotFound   = apperror.NotFound("scheduled change not found")
+	ErrScheduledChangeExists     = apperror.Conflict("scheduled change already exists")
+	ErrScheduledChangeNotPending = apperror.Conflict("scheduled change is no longer pending")
+	ErrScheduledChangeEmpty      = apperror.Validation("scheduled change must set a price, currency or active status")
+	ErrEffectiveAtNotFuture      = apperror.Validation("effectiveAt must be in the future")
+)
+
+// Promotion errors
+var (
+	ErrPromotionNotFound     = apperror.NotFound("promotion not found")
+	ErrPromotionExists       = apperror.Conflict("promotion already exists")
+	ErrCouponCodeTaken       = apperror.Conflict("coupon code is already in use")
+	ErrInvalidPercentage     = apperror.Validation("percentage must be greater than 0 and at most 100, with at most two decimals")
+	ErrInvalidDiscountAmount = apperror.Validation("discount amount must be greater than 0, with no more decimals than its currency")
+	ErrInvalidPromotionDates = apperror.Validation("endsAt must be after startsAt")
+	ErrInvalidCoupon         = apperror.Validation("coupon code is not valid")
+)
+
+// Tag and attribute errors
+var (
+	ErrTagEmpty             = apperror.Validation("tags must not be empty")
+	ErrTagTooLong           = apperror.Validation("tags must be at most 50 characters")
+	ErrTooManyTags          = apperror.Validation("a product can have at most 20 tags")
+	ErrAttributeKeyRequired = apperror.Validation("attribute key is required")
+)
+
+// Review errors
+var (
+	ErrReviewNotFound      = apperror.NotFound("review not found")
+	ErrReviewExists        = apperror.Conflict("customer has already reviewed this product")
+	ErrInvalidRating       = apperror.Validation("rating must be between 1 and 5")
+	ErrInvalidReviewStatus = apperror.Validation("review status must be APPROVED or REJECTED")
+	ErrInvalidStatusFilter = apperror.Validation("status must be PENDING, APPROVED or REJECTED")
+)
+
+// Supplier errors
+var (
+	ErrSupplierNotFound        = apperror.NotFound("supplier not found")
+	ErrSupplierExists          = apperror.Conflict("supplier already exists")
+	ErrSupplierNameTaken       = apperror.Conflict("supplier name is already in use")
+	ErrProductSupplierNotFound = apperror.NotFound("product is not linked to the supplier")
+	ErrInvalidCostPrice        = apperror.Validation("cost price must be greater than 0, with no more decimals than its currency")
+)
+
+// Back-in-stock subscription errors
+var (
+	ErrStockSubscriptionNotFound = apperror.NotFound("subscription not found")
+	ErrSubscriptionNotPending    = apperror.Conflict("subscription has already been notified")
+	ErrProductInStock            = apperror.Conflict("product is in stock")
+)
+
+// Currency conversion errors
+var (
+	ErrNoExchangeRate           = apperror.Unprocessable("no exchange rate for the requested currency")
+	ErrExchangeRatesUnavailable = apperror.Unavailable("exchange rates unavailable")
+)
+
+// Image errors
+var (
+	ErrImageNotFound        = apperror.NotFound("image not found")
+	ErrImageRequired        = apperror.Validation("image is required")
+	ErrImageTooLarge        = apperror.Validation("image is too large")
+	ErrUnsupportedImageType = apperror.Validation("image type is not supported, use JPEG, PNG, GIF or WebP")
+)
+
+// Variant errors
+var (
+	ErrVariantNotFound           = apperror.NotFound("variant not found")
+	ErrSKUExists                 = apperror.Conflict("SKU is already in use")
+	ErrVariantExists             = apperror.Conflict("product already has a variant with these attributes")
+	ErrSKURequired               = apperror.Validation("SKU is required")
+	ErrVariantAttributesRequired = apperror.Validation("variant attributes are required")
+	ErrInvalidVariantAttribute   = apperror.Validation("variant attribute names and values must not be empty")
+)
@@ -0,0 +1,46 @@
+package model
+
+import "strings"
+
+// gtinLength is the length of the longest GTIN, GTIN-14. Shorter GTINs are
+// the same code padded with leading zeros.
+const gtinLength = 14
+
+// NormalizeGTIN trims a GTIN barcode and checks that it is a GTIN-8 (EAN-8),
+// GTIN-12 (UPC-A), GTIN-13 (EAN-13) or GTIN-14 with a valid check digit
+func NormalizeGTIN(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return "", ErrInvalidGTIN
+	}
+
+	// The check digit makes the weighted sum of the digits a multiple of
+	// ten, weighing the digits 3 and 1 alternately from the right
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if digit < 0 || digit > 9 {
+			return "", ErrInvalidGTIN
+		}
+		if (len(code)-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	if sum%10 != 0 {
+		return "", ErrInvalidGTIN
+	}
+	return code, nil
+}
+
+// GTINKey returns the GTIN-14 form of a GTIN, so a UPC-A and the EAN-13 with
+// a leading zero identify the same trade item
+func GTINKey(code string) string {
+	code = strings.TrimSpace(code)
+	if len(code) >= gtinLength {
+		return code
+	}
+	return strings.Repeat("0", gtinLength-len(code)) + code
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeGTIN(t *testing.T) {
+	valid := []struct {
+		name string
+		code string
+	}{
+		{"EAN-8", "96385074"},
+		{"UPC-A", "036000291452"},
+		{"EAN-13", "4006381333931"},
+		{"GTIN-14", "10036000291459"},
+	}
+	for _, tt := range valid {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			code, err := NormalizeGTIN(" " + tt.code + " ")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, code)
+		})
+	}
+
+	invalid := []struct {
+		name string
+		code string
+	}{
+		{"Wrong check digit", "4006381333932"},
+		{"Letters", "40063813339A1"},
+		{"Too short", "1234567"},
+		{"Unsupported length", "12345678901"},
+		{"Too long", "123456789012345"},
+		{"Empty", ""},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := NormalizeGTIN(tt.code)
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidGTIN)
+		})
+	}
+}
+
+func TestGTINKey(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, "00036000291452", GTINKey("036000291452"))
+	assert.Equal(t, GTINKey("036000291452"), GTINKey("0036000291452"))
+	assert.Equal(t, "00000096385074", GTINKey("96385074"))
+	assert.Equal(t, "10036000291459", GTINKey("10036000291459"))
+}
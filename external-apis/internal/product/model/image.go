@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// ImageExtensions maps the supported image content types to the file
+// extension their objects are stored with
+var ImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ProductImage is an image attached to a product. Key locates the image in
+// media storage and URL is where clients can fetch it.
+type ProductImage struct {
+	ID          string    `json:"id"`
+	Key         string    `json:"key"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ProductImageResponse represents the API response for a product image
+type ProductImageResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ToResponse converts a ProductImage to ProductImageResponse
+func (i ProductImage) ToResponse() ProductImageResponse {
+	return ProductImageResponse{
+		ID:          i.ID,
+		URL:         i.URL,
+		ContentType: i.ContentType,
+		Size:        i.Size,
+		CreatedAt:   i.CreatedAt,
+	}
+}
@@ -3,61 +3,242 @@ package model
 import (
 	"encoding/json"
 	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/timerange"
 )
 
 // Product represents a product in the catalog
 type Product struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Price       *big.Rat `json:"price"`
-	Category    string   `json:"category"`
-	Active      bool     `json:"active"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// SKU optionally identifies the product in supplier and warehouse systems
+	SKU string `json:"sku,omitempty"`
+	// GTIN is the optional barcode of the product: an EAN-8, UPC-A, EAN-13
+	// or GTIN-14 number
+	GTIN string `json:"gtin,omitempty"`
+	// Price is held in minor units of the product's currency
+	Price money.Money `json:"price"`
+	// CategoryID references the product's category; Category holds its name
+	CategoryID string `json:"categoryId"`
+	Category   string `json:"category"`
+	Active     bool   `json:"active"`
+	// StockQuantity is the number of units on hand, including reserved ones
+	StockQuantity    int `json:"stockQuantity"`
+	ReservedQuantity int `json:"reservedQuantity"`
+	// Images are kept in upload order
+	Images []ProductImage `json:"images,omitempty"`
+	// Variants are kept in creation order
+	Variants []ProductVariant `json:"variants,omitempty"`
+	// Tags are free-form, lower-case labels such as "sale" or "eco"
+	Tags []string `json:"tags,omitempty"`
+	// Attributes follow the schema of the category, sorted by key
+	Attributes []Attribute `json:"attributes,omitempty"`
+	// Rating aggregates the approved reviews of the product
+	Rating ProductRating `json:"rating"`
+	// WeightGrams and Dimensions describe the product as it is packed for
+	// shipping; zero and nil when unknown
+	WeightGrams int         `json:"weightGrams,omitempty"`
+	Dimensions  *Dimensions `json:"dimensions,omitempty"`
+	// CreatedAt is the time the product was created
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time of the last change to the product, its stock,
+	// images, variants or rating
+	UpdatedAt time.Time `json:"updatedAt"`
+	// DeletedAt is set when the product has been soft-deleted
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// IsDeleted checks if the product has been soft-deleted
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// Clone returns a deep copy of the product, its images, variants, tags and
+// attributes
+func (p *Product) Clone() *Product {
+	clone := *p
+	clone.Images = slices.Clone(p.Images)
+	clone.Tags = slices.Clone(p.Tags)
+	clone.Attributes = slices.Clone(p.Attributes)
+	if p.Variants != nil {
+		clone.Variants = make([]ProductVariant, len(p.Variants))
+		for i, variant := range p.Variants {
+			clone.Variants[i] = variant.Clone()
+		}
+	}
+	if p.Dimensions != nil {
+		dimensions := *p.Dimensions
+		clone.Dimensions = &dimensions
+	}
+	if p.DeletedAt != nil {
+		deletedAt := *p.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
+
+// AvailableQuantity returns the number of units that can still be reserved
+func (p *Product) AvailableQuantity() int {
+	return p.StockQuantity - p.ReservedQuantity
+}
+
+// FindImage returns the product image with the given ID
+func (p *Product) FindImage(id string) (ProductImage, bool) {
+	for _, image := range p.Images {
+		if image.ID == id {
+			return image, true
+		}
+	}
+	return ProductImage{}, false
 }
 
-// ProductResponse represents the API response for a product
+// ProductResponse represents the API response for a product. Price is an
+// exact decimal number in units of Currency.
 type ProductResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Category    string  `json:"category"`
-	Active      bool    `json:"active"`
+	ID          string      `json:"id"`
+	SKU         string      `json:"sku,omitempty"`
+	GTIN        string      `json:"gtin,omitempty"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       json.Number `json:"price" swaggertype:"number"`
+	Currency    string      `json:"currency"`
+	CategoryID  string      `json:"categoryId"`
+	Category    string      `json:"category"`
+	Active      bool        `json:"active"`
+	// Stock levels
+	StockQuantity     int `json:"stockQuantity"`
+	ReservedQuantity  int `json:"reservedQuantity"`
+	AvailableQuantity int `json:"availableQuantity"`
+	// AverageRating is the mean rating of the approved reviews, zero
+	// without any
+	AverageRating float64 `json:"averageRating"`
+	ReviewCount   int     `json:"reviewCount"`
+	// Shipping measurements, zero and absent when unknown
+	WeightGrams int         `json:"weightGrams"`
+	Dimensions  *Dimensions `json:"dimensions,omitempty"`
+
+	Tags       []string                 `json:"tags"`
+	Attributes []Attribute              `json:"attributes"`
+	Images     []ProductImageResponse   `json:"images"`
+	Variants   []ProductVariantResponse `json:"variants"`
+	CreatedAt  time.Time                `json:"createdAt"`
+	UpdatedAt  time.Time                `json:"updatedAt"`
+	DeletedAt  *time.Time               `json:"deletedAt,omitempty"`
+	// ConvertedPrice is only set when the client asks for prices in another
+	// currency
+	ConvertedPrice *ConvertedPrice `json:"convertedPrice,omitempty"`
 }
 
 // ToResponse converts a Product to ProductResponse
 func (p *Product) ToResponse() ProductResponse {
-	priceFloat, _ := p.Price.Float64()
+	images := make([]ProductImageResponse, len(p.Images))
+	for i, image := range p.Images {
+		images[i] = image.ToResponse()
+	}
+	variants := make([]ProductVariantResponse, len(p.Variants))
+	for i, variant := range p.Variants {
+		variants[i] = variant.ToResponse(p.Price)
+	}
+
 	return ProductResponse{
 		ID:          p.ID,
+		SKU:         p.SKU,
+		GTIN:        p.GTIN,
 		Name:        p.Name,
 		Description: p.Description,
-		Price:       priceFloat,
+		Price:       p.Price.Number(),
+		Currency:    p.Price.Currency,
+		CategoryID:  p.CategoryID,
 		Category:    p.Category,
 		Active:      p.Active,
+
+		StockQuantity:     p.StockQuantity,
+		ReservedQuantity:  p.ReservedQuantity,
+		AvailableQuantity: p.AvailableQuantity(),
+		AverageRating:     p.Rating.Average(),
+		ReviewCount:       p.Rating.Count,
+		WeightGrams:       p.WeightGrams,
+		Dimensions:        p.Dimensions,
+
+		Tags:       append(make([]string, 0, len(p.Tags)), p.Tags...),
+		Attributes: append(make([]Attribute, 0, len(p.Attributes)), p.Attributes...),
+		Images:     images,
+		Variants:   variants,
+		CreatedAt:  p.CreatedAt,
+		UpdatedAt:  p.UpdatedAt,
+		DeletedAt:  p.DeletedAt,
 	}
 }
 
-// MarshalJSON custom marshaling for Product
+// ProductCSVHeader holds the columns of a product CSV export
+var ProductCSVHeader = []string{
+	"id", "sku", "gtin", "name", "description", "price", "currency", "categoryId", "category",
+	"active", "stockQuantity", "reservedQuantity", "availableQuantity", "createdAt", "updatedAt", "deletedAt",
+}
+
+// CSVRecord returns the cells of the product in a CSV export, in the order
+// of ProductCSVHeader. Tags, attributes, images, variants and shipping
+// measurements are only exported as NDJSON.
+func (r ProductResponse) CSVRecord() []string {
+	return []string{
+		r.ID,
+		r.SKU,
+		r.GTIN,
+		r.Name,
+		r.Description,
+		r.Price.String(),
+		r.Currency,
+		r.CategoryID,
+		r.Category,
+		strconv.FormatBool(r.Active),
+		strconv.Itoa(r.StockQuantity),
+		strconv.Itoa(r.ReservedQuantity),
+		strconv.Itoa(r.AvailableQuantity),
+		formatTime(&r.CreatedAt),
+		formatTime(&r.UpdatedAt),
+		formatTime(r.DeletedAt),
+	}
+}
+
+// formatTime formats an optional timestamp for a CSV cell
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// MarshalJSON custom marshaling for Product. The price is written as an exact
+// decimal number next to its currency, matching ProductResponse.
 func (p *Product) MarshalJSON() ([]byte, error) {
 	type Alias Product
-	priceFloat, _ := p.Price.Float64()
 
 	return json.Marshal(&struct {
 		*Alias
-		Price float64 `json:"price"`
+		Price    json.Number `json:"price" swaggertype:"number"`
+		Currency string      `json:"currency"`
 	}{
-		Alias: (*Alias)(p),
-		Price: priceFloat,
+		Alias:    (*Alias)(p),
+		Price:    p.Price.Number(),
+		Currency: p.Price.Currency,
 	})
 }
 
-// UnmarshalJSON custom unmarshaling for Product
+// UnmarshalJSON custom unmarshaling for Product. A missing currency falls
+// back to money.DefaultCurrency.
 func (p *Product) UnmarshalJSON(data []byte) error {
 	type Alias Product
 	aux := &struct {
 		*Alias
-		Price float64 `json:"price"`
+		Price    json.Number `json:"price" swaggertype:"number"`
+		Currency string      `json:"currency"`
 	}{
 		Alias: (*Alias)(p),
 	}
@@ -66,25 +247,348 @@ func (p *Product) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	p.Price = big.NewRat(1, 1)
-	p.Price.SetFloat64(aux.Price)
+	currency := aux.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+
+	price, err := money.Parse(aux.Price.String(), currency)
+	if err != nil {
+		return err
+	}
+	p.Price = price
 
 	return nil
 }
 
-// CreateProductRequest represents the request to create a product
+// CreateProductRequest represents the request to create a product. Price is
+// a decimal amount in Currency, which defaults to the service's configured
+// currency.
 type CreateProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description" binding:"required"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Category    string  `json:"category" binding:"required"`
+	// SKU is optional; it must be unique across products and variants
+	SKU string `json:"sku,omitempty" binding:"omitempty,max=64"`
+	// GTIN is optional; it must be a valid barcode number unique across
+	// products
+	GTIN        string      `json:"gtin,omitempty" binding:"omitempty,max=14"`
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description" binding:"required"`
+	Price       json.Number `json:"price" binding:"required" swaggertype:"number"`
+	Currency    string      `json:"currency,omitempty" binding:"omitempty,currency"`
+	// CategoryID must reference an existing category
+	CategoryID string `json:"categoryId" binding:"required"`
+	// StockQuantity is the initial number of units on hand
+	StockQuantity int      `json:"stockQuantity" binding:"gte=0"`
+	Tags          []string `json:"tags,omitempty" binding:"omitempty,max=20"`
+	// Attributes must follow the attribute schema of the category
+	Attributes []Attribute `json:"attributes,omitempty" binding:"omitempty,max=50,dive"`
+	// WeightGrams and Dimensions are the shipping measurements, optional
+	WeightGrams int         `json:"weightGrams,omitempty" binding:"gte=0,max=1000000"`
+	Dimensions  *Dimensions `json:"dimensions,omitempty"`
 }
 
-// UpdateProductRequest represents the request to update a product
+// UpdateProductRequest represents the request to update a product. Changing
+// only the currency keeps the existing amount. Tags and Attributes replace
+// the existing ones; attributes are checked against the schema of the
+// category whenever they or the category change.
 type UpdateProductRequest struct {
-	Name        *string  `json:"name,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Price       *float64 `json:"price,omitempty"`
-	Category    *string  `json:"category,omitempty"`
-	Active      *bool    `json:"active,omitempty"`
+	SKU         *string      `json:"sku,omitempty" binding:"omitempty,max=64"`
+	GTIN        *string      `json:"gtin,omitempty" binding:"omitempty,max=14"`
+	Name        *string      `json:"name,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	Price       *json.Number `json:"price,omitempty" swaggertype:"number"`
+	Currency    *string      `json:"currency,omitempty" binding:"omitempty,currency"`
+	CategoryID  *string      `json:"categoryId,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Tags        *[]string    `json:"tags,omitempty" binding:"omitempty,max=20"`
+	Attributes  *[]Attribute `json:"attributes,omitempty" binding:"omitempty,max=50,dive"`
+	WeightGrams *int         `json:"weightGrams,omitempty" binding:"omitempty,gte=0,max=1000000"`
+	Dimensions  *Dimensions  `json:"dimensions,omitempty"`
+	// ClearDimensions removes the dimensions; it cannot be combined with Dimensions
+	ClearDimensions bool `json:"clearDimensions,omitempty"`
+}
+
+// ProductDocument is the document PATCH requests change: the fields of a
+// product clients can update. A field the patch removed or set to null is
+// nil, which clears the optional ones and is rejected for the required ones.
+type ProductDocument struct {
+	SKU         *string      `json:"sku" binding:"omitempty,max=64"`
+	GTIN        *string      `json:"gtin" binding:"omitempty,max=14"`
+	Name        *string      `json:"name" binding:"required"`
+	Description *string      `json:"description" binding:"required"`
+	Price       *json.Number `json:"price" binding:"required" swaggertype:"number"`
+	Currency    *string      `json:"currency" binding:"required,currency"`
+	CategoryID  *string      `json:"categoryId" binding:"required"`
+	Active      *bool        `json:"active" binding:"required"`
+	Tags        []string     `json:"tags" binding:"max=20"`
+	Attributes  []Attribute  `json:"attributes" binding:"max=50,dive"`
+	WeightGrams *int         `json:"weightGrams" binding:"omitempty,gte=0,max=1000000"`
+	Dimensions  *Dimensions  `json:"dimensions"`
+}
+
+// Document returns the fields of the product PATCH requests change. Tags
+// and attributes are empty arrays rather than null, so JSON Patch operations
+// can add to them.
+func (p *Product) Document() ProductDocument {
+	price := p.Price.Number()
+	tags, attributes := p.Tags, p.Attributes
+	if tags == nil {
+		tags = []string{}
+	}
+	if attributes == nil {
+		attributes = []Attribute{}
+	}
+
+	return ProductDocument{
+		SKU:         &p.SKU,
+		GTIN:        &p.GTIN,
+		Name:        &p.Name,
+		Description: &p.Description,
+		Price:       &price,
+		Currency:    &p.Price.Currency,
+		CategoryID:  &p.CategoryID,
+		Active:      &p.Active,
+		Tags:        tags,
+		Attributes:  attributes,
+		WeightGrams: &p.WeightGrams,
+		Dimensions:  p.Dimensions,
+	}
+}
+
+// UpdateRequest returns the update setting the fields of current whose
+// values differ in the document, leaving the others alone. Removed optional
+// fields are set to their zero value; removed dimensions are cleared.
+func (d ProductDocument) UpdateRequest(current *Product) UpdateProductRequest {
+	var req UpdateProductRequest
+	if sku := valueOf(d.SKU); sku != current.SKU {
+		req.SKU = &sku
+	}
+	if gtin := valueOf(d.GTIN); gtin != current.GTIN {
+		req.GTIN = &gtin
+	}
+	if *d.Name != current.Name {
+		req.Name = d.Name
+	}
+	if *d.Description != current.Description {
+		req.Description = d.Description
+	}
+	if *d.Price != current.Price.Number() {
+		req.Price = d.Price
+	}
+	if *d.Currency != current.Price.Currency {
+		req.Currency = d.Currency
+	}
+	if *d.CategoryID != current.CategoryID {
+		req.CategoryID = d.CategoryID
+	}
+	if *d.Active != current.Active {
+		req.Active = d.Active
+	}
+	if !slices.Equal(d.Tags, current.Tags) {
+		tags := append([]string{}, d.Tags...)
+		req.Tags = &tags
+	}
+	if !slices.Equal(d.Attributes, current.Attributes) {
+		attributes := append([]Attribute{}, d.Attributes...)
+		req.Attributes = &attributes
+	}
+	if weight := valueOf(d.WeightGrams); weight != current.WeightGrams {
+		req.WeightGrams = &weight
+	}
+	switch {
+	case d.Dimensions == nil:
+		req.ClearDimensions = current.Dimensions != nil
+	case current.Dimensions == nil || *d.Dimensions != *current.Dimensions:
+		req.Dimensions = d.Dimensions
+	}
+	return req
+}
+
+// valueOf returns the value v points to, or the zero value if v is nil
+func valueOf[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
+
+// StockRequest represents a request to reserve or release units of stock
+type StockRequest struct {
+	Quantity int `json:"quantity" binding:"required,gt=0"`
+}
+
+// AdjustStockRequest represents a correction of the units on hand, e.g. after
+// a delivery (positive delta) or a stock count (negative delta)
+type AdjustStockRequest struct {
+	Delta  int    `json:"delta" binding:"required"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// BulkProductOperation is a single create, update or delete in a bulk request.
+// Create carries the new product; ID identifies the product to update or delete.
+type BulkProductOperation struct {
+	Op     string                `json:"op" binding:"required,oneof=create update delete"`
+	ID     string                `json:"id,omitempty" binding:"required_unless=Op create"`
+	Create *CreateProductRequest `json:"create,omitempty" binding:"required_if=Op create"`
+	Update *UpdateProductRequest `json:"update,omitempty" binding:"required_if=Op update"`
+}
+
+// BulkProductRequest represents a batch of product operations applied all-or-nothing
+type BulkProductRequest struct {
+	Operations []BulkProductOperation `json:"operations" binding:"required,min=1,max=5000,dive"`
+}
+
+// Product sort options accepted by the list endpoint
+const (
+	SortByID        = "id"
+	SortByIDDesc    = "id_desc"
+	SortByName      = "name"
+	SortByNameDesc  = "name_desc"
+	SortByPriceAsc  = "price_asc"
+	SortByPriceDesc = "price_desc"
+)
+
+// IsValidProductSort checks if the sort option is supported
+func IsValidProductSort(sort string) bool {
+	switch sort {
+	case "", SortByID, SortByIDDesc, SortByName, SortByNameDesc, SortByPriceAsc, SortByPriceDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProductFilter represents the criteria used to list products
+type ProductFilter struct {
+	// Category matches the category name case-insensitively
+	Category string
+	// CategoryIDs matches products in any of the categories
+	CategoryIDs []string
+	MinPrice    *big.Rat
+	MaxPrice    *big.Rat
+	// Currency restricts matches to prices in one currency, which makes the
+	// price bounds meaningful when the catalog mixes currencies
+	Currency string
+	Active   *bool
+	// Tags matches products carrying every one of the tags
+	Tags []string
+	// Attributes matches products having every one of the attribute values
+	Attributes []AttributeFilter
+	// Created and Updated match products created or last changed within the
+	// ranges; stock, image and variant changes count as changes
+	Created timerange.Range
+	Updated timerange.Range
+	// IncludeDeleted also matches soft-deleted products
+	IncludeDeleted bool
+	Sort           string
+	Page           pagination.Params
+}
+
+// Matches checks if the product satisfies every filter criterion
+func (f ProductFilter) Matches(p *Product) bool {
+	if !f.IncludeDeleted && p.IsDeleted() {
+		return false
+	}
+	if f.Category != "" && !strings.EqualFold(p.Category, f.Category) {
+		return false
+	}
+	if len(f.CategoryIDs) > 0 && !slices.Contains(f.CategoryIDs, p.CategoryID) {
+		return false
+	}
+	if f.Currency != "" && p.Price.Currency != f.Currency {
+		return false
+	}
+	if f.MinPrice != nil && p.Price.Rat().Cmp(f.MinPrice) < 0 {
+		return false
+	}
+	if f.MaxPrice != nil && p.Price.Rat().Cmp(f.MaxPrice) > 0 {
+		return false
+	}
+	if f.Active != nil && p.Active != *f.Active {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(p.Tags, tag) {
+			return false
+		}
+	}
+	for _, attribute := range f.Attributes {
+		if !attribute.Matches(p) {
+			return false
+		}
+	}
+	if !f.Created.Contains(p.CreatedAt) || !f.Updated.Contains(p.UpdatedAt) {
+		return false
+	}
+	return true
+}
+
+// ProductSearch represents a full-text search over product names,
+// descriptions and categories. Matches are ranked by relevance, so the sort
+// option of the filter is ignored.
+type ProductSearch struct {
+	Query  string
+	Filter ProductFilter
+}
+
+// ProductSearchHit is a product matching a search with its relevance score
+type ProductSearchHit struct {
+	Product *Product
+	Score   float64
+}
+
+// ProductSearchResult represents a search match in API responses
+type ProductSearchResult struct {
+	ProductResponse
+	Score float64 `json:"score"`
+}
+
+// ImportProductRow is a product read from an import file. CSV columns and
+// NDJSON keys are named like the JSON fields. Every row describes the whole
+// product; StockQuantity may be left out to keep the stock of an existing
+// product unchanged.
+type ImportProductRow struct {
+	SKU           string      `json:"sku" binding:"omitempty,max=64"`
+	GTIN          string      `json:"gtin" binding:"omitempty,max=14"`
+	Name          string      `json:"name" binding:"required"`
+	Description   string      `json:"description" binding:"required"`
+	Price         json.Number `json:"price" binding:"required" swaggertype:"number"`
+	Currency      string      `json:"currency" binding:"omitempty,currency"`
+	CategoryID    string      `json:"categoryId" binding:"required"`
+	StockQuantity *int        `json:"stockQuantity" binding:"omitempty,gte=0"`
+}
+
+// CreateRequest converts the row into a request creating the product
+func (r ImportProductRow) CreateRequest() CreateProductRequest {
+	req := CreateProductRequest{
+		SKU:         r.SKU,
+		GTIN:        r.GTIN,
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       r.Price,
+		Currency:    r.Currency,
+		CategoryID:  r.CategoryID,
+	}
+	if r.StockQuantity != nil {
+		req.StockQuantity = *r.StockQuantity
+	}
+	return req
+}
+
+// UpdateRequest converts the row into a request updating an existing
+// product. The currency and GTIN are kept unless the row names them.
+func (r ImportProductRow) UpdateRequest() UpdateProductRequest {
+	req := UpdateProductRequest{
+		Name:        &r.Name,
+		Description: &r.Description,
+		Price:       &r.Price,
+		CategoryID:  &r.CategoryID,
+	}
+	if r.Currency != "" {
+		req.Currency = &r.Currency
+	}
+	if r.GTIN != "" {
+		req.GTIN = &r.GTIN
+	}
+	return req
 }
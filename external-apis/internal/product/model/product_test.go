@@ -4,14 +4,17 @@ import (
 	"encoding/json"
 	"math/big"
 	"testing"
+	"time"
 
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/timerange"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestProduct_ToResponse(t *testing.T) {
 	// Arrange
-	price := big.NewRat(99900, 100) // 999.00
+	price := money.New(99900, "USD") // 999.00
 	product := &Product{
 		ID:          "product-123",
 		Name:        "Test Laptop",
@@ -19,6 +22,9 @@ func TestProduct_ToResponse(t *testing.T) {
 		Price:       price,
 		Category:    "Electronics",
 		Active:      true,
+
+		StockQuantity:    10,
+		ReservedQuantity: 4,
 	}
 
 	// Act
@@ -28,14 +34,18 @@ func TestProduct_ToResponse(t *testing.T) {
 	assert.Equal(t, "product-123", response.ID)
 	assert.Equal(t, "Test Laptop", response.Name)
 	assert.Equal(t, "A test laptop", response.Description)
-	assert.Equal(t, 999.0, response.Price)
+	assert.Equal(t, json.Number("999.00"), response.Price)
+	assert.Equal(t, "USD", response.Currency)
 	assert.Equal(t, "Electronics", response.Category)
 	assert.True(t, response.Active)
+	assert.Equal(t, 10, response.StockQuantity)
+	assert.Equal(t, 4, response.ReservedQuantity)
+	assert.Equal(t, 6, response.AvailableQuantity)
 }
 
 func TestProduct_MarshalJSON(t *testing.T) {
 	// Arrange
-	price := big.NewRat(99900, 100) // 999.00
+	price := money.New(99900, "USD") // 999.00
 	product := &Product{
 		ID:          "product-123",
 		Name:        "Test Laptop",
@@ -58,6 +68,8 @@ func TestProduct_MarshalJSON(t *testing.T) {
 	assert.Equal(t, "product-123", result["id"])
 	assert.Equal(t, "Test Laptop", result["name"])
 	assert.Equal(t, 999.0, result["price"])
+	assert.Equal(t, "USD", result["currency"])
+	assert.Contains(t, string(jsonData), `"price":999.00`)
 }
 
 func TestProduct_UnmarshalJSON(t *testing.T) {
@@ -80,9 +92,7 @@ func TestProduct_UnmarshalJSON(t *testing.T) {
 	assert.Equal(t, "product-123", product.ID)
 	assert.Equal(t, "Test Laptop", product.Name)
 	assert.Equal(t, "A test laptop", product.Description)
-
-	expectedPrice, _ := product.Price.Float64()
-	assert.Equal(t, 999.0, expectedPrice)
+	assert.Equal(t, money.New(99900, "USD"), product.Price)
 	assert.Equal(t, "Electronics", product.Category)
 	assert.True(t, product.Active)
 }
@@ -98,8 +108,8 @@ func TestCreateProductRequest_Validation(t *testing.T) {
 			request: CreateProductRequest{
 				Name:        "Test Product",
 				Description: "Test Description",
-				Price:       99.99,
-				Category:    "Electronics",
+				Price:       "99.99",
+				CategoryID:  "category-electronics",
 			},
 			expectValid: true,
 		},
@@ -108,8 +118,8 @@ func TestCreateProductRequest_Validation(t *testing.T) {
 			request: CreateProductRequest{
 				Name:        "Test Product",
 				Description: "Test Description",
-				Price:       0,
-				Category:    "Electronics",
+				Price:       "0",
+				CategoryID:  "category-electronics",
 			},
 			expectValid: false,
 		},
@@ -118,8 +128,18 @@ func TestCreateProductRequest_Validation(t *testing.T) {
 			request: CreateProductRequest{
 				Name:        "Test Product",
 				Description: "Test Description",
-				Price:       -10.0,
-				Category:    "Electronics",
+				Price:       "-10.0",
+				CategoryID:  "category-electronics",
+			},
+			expectValid: false,
+		},
+		{
+			name: "Invalid price - fraction of a cent",
+			request: CreateProductRequest{
+				Name:        "Test Product",
+				Description: "Test Description",
+				Price:       "9.999",
+				CategoryID:  "category-electronics",
 			},
 			expectValid: false,
 		},
@@ -127,13 +147,154 @@ func TestCreateProductRequest_Validation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Simulate validation that would happen in handler
+			// Simulate validation that would happen in handler and service
+			price, err := money.Parse(tt.request.Price.String(), money.DefaultCurrency)
 			isValid := tt.request.Name != "" &&
 				tt.request.Description != "" &&
-				tt.request.Price > 0 &&
-				tt.request.Category != ""
+				err == nil && price.IsPositive() &&
+				tt.request.CategoryID != ""
 
 			assert.Equal(t, tt.expectValid, isValid)
 		})
 	}
 }
+
+func TestProductFilter_Matches(t *testing.T) {
+	product := &Product{
+		ID:         "product-123",
+		Price:      money.New(2999, "USD"),
+		CategoryID: "category-electronics",
+		Category:   "Electronics",
+		Active:     true,
+		Tags:       []string{"sale", "eco"},
+		Attributes: []Attribute{
+			{Key: "color", Type: AttributeString, Value: "Red"},
+			{Key: "screen_size", Type: AttributeNumber, Value: "15.6"},
+			{Key: "wireless", Type: AttributeBoolean, Value: "true"},
+		},
+		CreatedAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC),
+	}
+	inactive := false
+
+	tests := []struct {
+		name   string
+		filter ProductFilter
+		want   bool
+	}{
+		{"Empty filter", ProductFilter{}, true},
+		{"Matching category", ProductFilter{Category: "electronics"}, true},
+		{"Other category", ProductFilter{Category: "Books"}, false},
+		{"Matching category ID", ProductFilter{CategoryIDs: []string{"category-books", "category-electronics"}}, true},
+		{"Other category ID", ProductFilter{CategoryIDs: []string{"category-books"}}, false},
+		{"Within price range", ProductFilter{MinPrice: big.NewRat(10, 1), MaxPrice: big.NewRat(30, 1)}, true},
+		{"Below minimum price", ProductFilter{MinPrice: big.NewRat(30, 1)}, false},
+		{"Above maximum price", ProductFilter{MaxPrice: big.NewRat(29, 1)}, false},
+		{"Active mismatch", ProductFilter{Active: &inactive}, false},
+		{"Every tag", ProductFilter{Tags: []string{"eco", "sale"}}, true},
+		{"Missing tag", ProductFilter{Tags: []string{"sale", "new"}}, false},
+		{"String attribute", ProductFilter{Attributes: []AttributeFilter{{Key: "color", Value: "red"}}}, true},
+		{"Number attribute", ProductFilter{Attributes: []AttributeFilter{{Key: "screen_size", Value: "15.60"}}}, true},
+		{"Boolean attribute", ProductFilter{Attributes: []AttributeFilter{{Key: "wireless", Value: "1"}}}, true},
+		{"Attribute mismatch", ProductFilter{Attributes: []AttributeFilter{{Key: "screen_size", Value: "14"}}}, false},
+		{"Missing attribute", ProductFilter{Attributes: []AttributeFilter{{Key: "weight", Value: "1"}}}, false},
+		{"Updated since", ProductFilter{Updated: timerange.Range{Since: product.UpdatedAt}}, true},
+		{"Updated before", ProductFilter{Updated: timerange.Range{Before: product.UpdatedAt}}, false},
+		{"Created since", ProductFilter{Created: timerange.Range{Since: product.CreatedAt.Add(time.Second)}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(product))
+		})
+	}
+}
+
+func TestProduct_Clone(t *testing.T) {
+	// Arrange
+	price := money.New(109900, "USD")
+	deletedAt := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	product := &Product{
+		ID:         "product-789",
+		Images:     []ProductImage{{ID: "image-1"}},
+		Variants:   []ProductVariant{{ID: "variant-1", Attributes: map[string]string{"memory": "16gb"}, Price: &price}},
+		Tags:       []string{"sale"},
+		Attributes: []Attribute{{Key: "color", Type: AttributeString, Value: "red"}},
+		DeletedAt:  &deletedAt,
+	}
+
+	// Act
+	clone := product.Clone()
+	clone.Images[0].ID = "changed"
+	clone.Variants[0].Attributes["memory"] = "32gb"
+	clone.Variants[0].Price.Amount = 0
+	clone.Tags[0] = "changed"
+	clone.Attributes[0].Value = "blue"
+	*clone.DeletedAt = deletedAt.Add(time.Hour)
+
+	// Assert
+	assert.Equal(t, "image-1", product.Images[0].ID)
+	assert.Equal(t, "16gb", product.Variants[0].Attributes["memory"])
+	assert.Equal(t, int64(109900), product.Variants[0].Price.Amount)
+	assert.Equal(t, "sale", product.Tags[0])
+	assert.Equal(t, "red", product.Attributes[0].Value)
+	assert.Equal(t, deletedAt, *product.DeletedAt)
+	assert.Nil(t, (&Product{}).Clone().Variants, "products without variants keep none")
+}
+
+func TestProduct_Document(t *testing.T) {
+	t.Run("Empty tags and attributes are arrays", func(t *testing.T) {
+		// Act
+		encoded, err := json.Marshal((&Product{Price: money.New(999, "USD")}).Document())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, string(encoded), `"tags":[]`)
+		assert.Contains(t, string(encoded), `"attributes":[]`)
+		assert.Contains(t, string(encoded), `"price":9.99`)
+	})
+}
+
+func TestProductDocument_UpdateRequest(t *testing.T) {
+	product := &Product{
+		SKU:         "MUG-1",
+		Name:        "Mug",
+		Price:       money.New(999, "USD"),
+		Tags:        []string{"sale"},
+		WeightGrams: 350,
+		Dimensions:  &Dimensions{LengthMM: 120, WidthMM: 90, HeightMM: 100},
+	}
+
+	t.Run("Set only the changed fields", func(t *testing.T) {
+		// Arrange
+		doc := product.Document()
+		price := json.Number("12.50")
+		doc.Price = &price
+
+		// Act
+		request := doc.UpdateRequest(product)
+
+		// Assert
+		assert.Equal(t, UpdateProductRequest{Price: &price}, request)
+	})
+
+	t.Run("Clear the removed optional fields", func(t *testing.T) {
+		// Arrange
+		doc := product.Document()
+		doc.SKU, doc.Tags, doc.WeightGrams, doc.Dimensions = nil, nil, nil, nil
+
+		// Act
+		request := doc.UpdateRequest(product)
+
+		// Assert
+		require.NotNil(t, request.SKU)
+		assert.Empty(t, *request.SKU)
+		require.NotNil(t, request.Tags)
+		assert.Empty(t, *request.Tags)
+		require.NotNil(t, request.WeightGrams)
+		assert.Zero(t, *request.WeightGrams)
+		assert.Nil(t, request.Dimensions)
+		assert.True(t, request.ClearDimensions)
+		assert.Nil(t, request.Name)
+	})
+}
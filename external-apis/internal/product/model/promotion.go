@@ -0,0 +1,238 @@
+package model
+
+import (
+	"encoding/json"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// DiscountType is how a promotion lowers the price of a product
+type DiscountType string
+
+const (
+	// DiscountPercentage takes a percentage off the price
+	DiscountPercentage DiscountType = "PERCENTAGE"
+	// DiscountFixed takes a fixed amount off prices in the same currency
+	DiscountFixed DiscountType = "FIXED"
+)
+
+// basisPointsPerWhole is the number of basis points in 100%
+const basisPointsPerWhole = 10000
+
+// Promotion is a discount on the products it is scoped to. A promotion with a
+// coupon code only applies to quotes that present the code. Promotions do not
+// stack: a price gets the largest discount of the promotions that apply.
+type Promotion struct {
+	ID   string
+	Name string
+	Type DiscountType
+	// BasisPoints is the percentage off of PERCENTAGE promotions in hundredths
+	// of a percent, e.g. 1250 for 12.5%
+	BasisPoints int64
+	// Amount is the amount off of FIXED promotions
+	Amount money.Money
+	// CouponCode is kept upper-case; empty promotions apply automatically
+	CouponCode string
+	// ProductIDs and CategoryIDs scope the promotion to the products, and the
+	// products in the categories or their subcategories; a promotion scoped to
+	// neither applies to every product
+	ProductIDs  []string
+	CategoryIDs []string
+	// CustomerIDs limits the promotion to the customers; empty means anyone
+	CustomerIDs []string
+	// StartsAt and EndsAt bound the window the promotion runs in; EndsAt is
+	// exclusive and either may be nil for an open end
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a deep copy of the promotion
+func (p *Promotion) Clone() *Promotion {
+	clone := *p
+	clone.ProductIDs = slices.Clone(p.ProductIDs)
+	clone.CategoryIDs = slices.Clone(p.CategoryIDs)
+	clone.CustomerIDs = slices.Clone(p.CustomerIDs)
+	if p.StartsAt != nil {
+		startsAt := *p.StartsAt
+		clone.StartsAt = &startsAt
+	}
+	if p.EndsAt != nil {
+		endsAt := *p.EndsAt
+		clone.EndsAt = &endsAt
+	}
+	return &clone
+}
+
+// IsRunning checks if the promotion is active and within its window at now
+func (p *Promotion) IsRunning(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	return p.EndsAt == nil || now.Before(*p.EndsAt)
+}
+
+// Covers checks if the promotion is scoped to the product for the customer.
+// inCategory reports whether the product belongs to a category or one of its
+// subcategories.
+func (p *Promotion) Covers(product *Product, customerID string, inCategory func(categoryID string) bool) bool {
+	if len(p.CustomerIDs) > 0 && !slices.Contains(p.CustomerIDs, customerID) {
+		return false
+	}
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return true
+	}
+	if slices.Contains(p.ProductIDs, product.ID) {
+		return true
+	}
+	return slices.ContainsFunc(p.CategoryIDs, inCategory)
+}
+
+// Discount returns the amount the promotion takes off price, which is never
+// more than the price itself. Percentages are rounded half up to the minor
+// unit; fixed amounts in another currency take nothing off.
+func (p *Promotion) Discount(price money.Money) money.Money {
+	discount := money.New(0, price.Currency)
+	switch p.Type {
+	case DiscountPercentage:
+		amount := new(big.Int).Mul(big.NewInt(price.Amount), big.NewInt(p.BasisPoints))
+		amount.Add(amount, big.NewInt(basisPointsPerWhole/2))
+		amount.Quo(amount, big.NewInt(basisPointsPerWhole))
+		discount.Amount = amount.Int64()
+	case DiscountFixed:
+		if p.Amount.Currency == price.Currency {
+			discount.Amount = p.Amount.Amount
+		}
+	}
+	if discount.Amount > price.Amount {
+		discount.Amount = price.Amount
+	}
+	return discount
+}
+
+// Value returns the discount as a decimal number: the percentage off, or the
+// amount off in Currency
+func (p *Promotion) Value() json.Number {
+	if p.Type == DiscountFixed {
+		return p.Amount.Number()
+	}
+	return json.Number(new(big.Rat).SetFrac64(p.BasisPoints, 100).FloatString(2))
+}
+
+// PromotionResponse represents the API response for a promotion. Value is the
+// percentage off, or the amount off in Currency for fixed discounts.
+type PromotionResponse struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Type        DiscountType `json:"type"`
+	Value       json.Number  `json:"value" swaggertype:"number"`
+	Currency    string       `json:"currency,omitempty"`
+	CouponCode  string       `json:"couponCode,omitempty"`
+	ProductIDs  []string     `json:"productIds"`
+	CategoryIDs []string     `json:"categoryIds"`
+	CustomerIDs []string     `json:"customerIds"`
+	StartsAt    *time.Time   `json:"startsAt,omitempty"`
+	EndsAt      *time.Time   `json:"endsAt,omitempty"`
+	Active      bool         `json:"active"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// ToResponse converts a Promotion to PromotionResponse
+func (p *Promotion) ToResponse() PromotionResponse {
+	response := PromotionResponse{
+		ID:          p.ID,
+		Name:        p.Name,
+		Type:        p.Type,
+		Value:       p.Value(),
+		CouponCode:  p.CouponCode,
+		ProductIDs:  nonNil(p.ProductIDs),
+		CategoryIDs: nonNil(p.CategoryIDs),
+		CustomerIDs: nonNil(p.CustomerIDs),
+		StartsAt:    p.StartsAt,
+		EndsAt:      p.EndsAt,
+		Active:      p.Active,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+	if p.Type == DiscountFixed {
+		response.Currency = p.Amount.Currency
+	}
+	return response
+}
+
+// nonNil returns ids, or an empty slice so it is encoded as [] rather than null
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
+
+// PromotionRequest represents the request to create or replace a promotion.
+// Value is the percentage off, at most 100 with two decimals, or the amount
+// off in Currency, which defaults to the service's configured currency.
+type PromotionRequest struct {
+	Name     string       `json:"name" binding:"required"`
+	Type     DiscountType `json:"type" binding:"required,oneof=PERCENTAGE FIXED"`
+	Value    json.Number  `json:"value" binding:"required" swaggertype:"number"`
+	Currency string       `json:"currency,omitempty" binding:"omitempty,currency"`
+	// CouponCode is matched case-insensitively and must be unique
+	CouponCode  string     `json:"couponCode,omitempty" binding:"omitempty,max=32,alphanum"`
+	ProductIDs  []string   `json:"productIds,omitempty" binding:"omitempty,dive,required"`
+	CategoryIDs []string   `json:"categoryIds,omitempty" binding:"omitempty,dive,required"`
+	CustomerIDs []string   `json:"customerIds,omitempty" binding:"omitempty,dive,required"`
+	StartsAt    *time.Time `json:"startsAt,omitempty"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+	// Active defaults to true
+	Active *bool `json:"active,omitempty"`
+}
+
+// NormalizeCouponCode returns the form coupon codes are stored and matched in
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// QuoteRequest represents the request for the price a customer pays for a
+// product, optionally with a coupon code
+type QuoteRequest struct {
+	CustomerID string `json:"customerId,omitempty"`
+	CouponCode string `json:"couponCode,omitempty" binding:"omitempty,max=32"`
+}
+
+// BatchQuoteRequest represents the request for the prices a customer pays for
+// several products at once, up to batch.MaxIDs. Unknown products are left
+// out of the quotes.
+type BatchQuoteRequest struct {
+	ProductIDs []string `json:"productIds" binding:"required,min=1,max=500,dive,required"`
+	CustomerID string   `json:"customerId,omitempty"`
+	CouponCode string   `json:"couponCode,omitempty" binding:"omitempty,max=32"`
+}
+
+// Quote is the effective price of a product: its list price less the discount
+// of the best promotion that applies. Amounts are exact decimal numbers in
+// units of Currency.
+type Quote struct {
+	ProductID string            `json:"productId"`
+	ListPrice json.Number       `json:"listPrice" swaggertype:"number"`
+	Discount  json.Number       `json:"discount" swaggertype:"number"`
+	Price     json.Number       `json:"price" swaggertype:"number"`
+	Currency  string            `json:"currency"`
+	Promotion *AppliedPromotion `json:"promotion,omitempty"`
+}
+
+// AppliedPromotion identifies the promotion a quote applied
+type AppliedPromotion struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	CouponCode string `json:"couponCode,omitempty"`
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotion_Discount(t *testing.T) {
+	tests := []struct {
+		name      string
+		promotion Promotion
+		price     money.Money
+		expected  money.Money
+	}{
+		{"Percentage", Promotion{Type: DiscountPercentage, BasisPoints: 1000}, money.New(2999, "USD"), money.New(300, "USD")},
+		{"Percentage rounds half up", Promotion{Type: DiscountPercentage, BasisPoints: 1250}, money.New(100, "USD"), money.New(13, "USD")},
+		{"Whole price", Promotion{Type: DiscountPercentage, BasisPoints: 10000}, money.New(2999, "USD"), money.New(2999, "USD")},
+		{"Fixed", Promotion{Type: DiscountFixed, Amount: money.New(500, "USD")}, money.New(2999, "USD"), money.New(500, "USD")},
+		{"Fixed capped at the price", Promotion{Type: DiscountFixed, Amount: money.New(5000, "USD")}, money.New(2999, "USD"), money.New(2999, "USD")},
+		{"Fixed in another currency", Promotion{Type: DiscountFixed, Amount: money.New(500, "EUR")}, money.New(2999, "USD"), money.New(0, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			discount := tt.promotion.Discount(tt.price)
+
+			// Assert
+			assert.Equal(t, tt.expected, discount)
+		})
+	}
+}
+
+func TestPromotion_Covers(t *testing.T) {
+	product := &Product{ID: "product-001", CategoryID: "category-computers"}
+	inCategory := func(categoryID string) bool {
+		return categoryID == "category-computers" || categoryID == "category-electronics"
+	}
+
+	tests := []struct {
+		name       string
+		promotion  Promotion
+		customerID string
+		expected   bool
+	}{
+		{"Unscoped", Promotion{}, "", true},
+		{"Product", Promotion{ProductIDs: []string{"product-001"}}, "", true},
+		{"Other product", Promotion{ProductIDs: []string{"product-002"}}, "", false},
+		{"Parent category", Promotion{CategoryIDs: []string{"category-electronics"}}, "", true},
+		{"Other category", Promotion{ProductIDs: []string{"product-002"}, CategoryIDs: []string{"category-accessories"}}, "", false},
+		{"Customer", Promotion{CustomerIDs: []string{"customer-123"}}, "customer-123", true},
+		{"Other customer", Promotion{CustomerIDs: []string{"customer-123"}}, "customer-456", false},
+		{"Anonymous customer", Promotion{CustomerIDs: []string{"customer-123"}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			covers := tt.promotion.Covers(product, tt.customerID, inCategory)
+
+			// Assert
+			assert.Equal(t, tt.expected, covers)
+		})
+	}
+}
+
+func TestPromotion_IsRunning(t *testing.T) {
+	startsAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	promotion := Promotion{Active: true, StartsAt: &startsAt, EndsAt: &endsAt}
+
+	assert.False(t, promotion.IsRunning(startsAt.Add(-time.Second)))
+	assert.True(t, promotion.IsRunning(startsAt))
+	assert.True(t, promotion.IsRunning(endsAt.Add(-time.Second)))
+	assert.False(t, promotion.IsRunning(endsAt))
+
+	promotion.Active = false
+	assert.False(t, promotion.IsRunning(startsAt))
+}
+
+func TestPromotion_ToResponse(t *testing.T) {
+	t.Run("Percentage", func(t *testing.T) {
+		// Arrange
+		promotion := &Promotion{ID: "promotion-1", Type: DiscountPercentage, BasisPoints: 1250}
+
+		// Act
+		response := promotion.ToResponse()
+
+		// Assert
+		assert.Equal(t, json.Number("12.50"), response.Value)
+		assert.Empty(t, response.Currency)
+		assert.Equal(t, []string{}, response.ProductIDs)
+	})
+
+	t.Run("Fixed", func(t *testing.T) {
+		// Arrange
+		promotion := &Promotion{ID: "promotion-1", Type: DiscountFixed, Amount: money.New(500, "EUR")}
+
+		// Act
+		response := promotion.ToResponse()
+
+		// Assert
+		assert.Equal(t, json.Number("5.00"), response.Value)
+		assert.Equal(t, "EUR", response.Currency)
+	})
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// ReservationStatus is the state of a stock reservation
+type ReservationStatus string
+
+const (
+	// ReservationActive holds stock until it is confirmed, released or expires
+	ReservationActive ReservationStatus = "ACTIVE"
+	// ReservationConfirmed holds stock for a placed order; it no longer expires
+	ReservationConfirmed ReservationStatus = "CONFIRMED"
+	// ReservationReleased has returned its stock on request
+	ReservationReleased ReservationStatus = "RELEASED"
+	// ReservationExpired has returned its stock after it expired
+	ReservationExpired ReservationStatus = "EXPIRED"
+)
+
+// Reservation holds units of a product's stock for an order during checkout.
+// Active reservations that are not confirmed before ExpiresAt are released
+// automatically, so abandoned checkouts do not keep stock out of sale.
+type Reservation struct {
+	ID        string            `json:"id"`
+	ProductID string            `json:"productId"`
+	OrderID   string            `json:"orderId"`
+	Quantity  int               `json:"quantity"`
+	Status    ReservationStatus `json:"status"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// IsExpired checks if an active reservation has outlived its expiry at now
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.Status == ReservationActive && !now.Before(r.ExpiresAt)
+}
+
+// HoldsStock checks if the reservation still holds its units
+func (r *Reservation) HoldsStock() bool {
+	return r.Status == ReservationActive || r.Status == ReservationConfirmed
+}
+
+// Transition moves the reservation to another status at now. Active
+// reservations can be confirmed until they expire; both active and confirmed
+// ones can be released; only expired active ones can expire.
+func (r *Reservation) Transition(to ReservationStatus, now time.Time) error {
+	allowed := false
+	switch to {
+	case ReservationConfirmed:
+		if r.IsExpired(now) {
+			return ErrReservationExpired
+		}
+		allowed = r.Status == ReservationActive
+	case ReservationReleased:
+		allowed = r.HoldsStock()
+	case ReservationExpired:
+		allowed = r.IsExpired(now)
+	}
+	if !allowed {
+		return ErrReservationNotActive
+	}
+
+	r.Status = to
+	r.UpdatedAt = now
+	return nil
+}
+
+// CreateReservationRequest represents the request to reserve stock of a
+// product for an order
+type CreateReservationRequest struct {
+	OrderID  string `json:"orderId" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,gt=0"`
+	// TTLSeconds is how long the reservation holds the stock unless it is
+	// confirmed; the configured default applies when it is zero
+	TTLSeconds int `json:"ttlSeconds,omitempty" binding:"gte=0"`
+}
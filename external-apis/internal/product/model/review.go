@@ -0,0 +1,141 @@
+package model
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// ReviewStatus is the moderation status of a review. Only approved reviews
+// are shown publicly and count towards the rating of their product.
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "PENDING"
+	ReviewApproved ReviewStatus = "APPROVED"
+	ReviewRejected ReviewStatus = "REJECTED"
+)
+
+// Bounds of review ratings
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// IsValid checks if the status is a known review status
+func (s ReviewStatus) IsValid() bool {
+	switch s {
+	case ReviewPending, ReviewApproved, ReviewRejected:
+		return true
+	}
+	return false
+}
+
+// Review is a customer's rating of a product, with an optional text
+type Review struct {
+	ID         string       `json:"id"`
+	ProductID  string       `json:"productId"`
+	CustomerID string       `json:"customerId"`
+	Rating     int          `json:"rating"`
+	Text       string       `json:"text"`
+	Status     ReviewStatus `json:"status"`
+	// ModerationReason explains the last moderation decision
+	ModerationReason string     `json:"moderationReason,omitempty"`
+	ModeratedAt      *time.Time `json:"moderatedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// Clone returns a deep copy of the review
+func (r *Review) Clone() *Review {
+	clone := *r
+	if r.ModeratedAt != nil {
+		moderatedAt := *r.ModeratedAt
+		clone.ModeratedAt = &moderatedAt
+	}
+	return &clone
+}
+
+// ReviewResponse represents the API response for a review
+type ReviewResponse struct {
+	ID               string       `json:"id"`
+	ProductID        string       `json:"productId"`
+	CustomerID       string       `json:"customerId"`
+	Rating           int          `json:"rating"`
+	Text             string       `json:"text"`
+	Status           ReviewStatus `json:"status"`
+	ModerationReason string       `json:"moderationReason,omitempty"`
+	ModeratedAt      *time.Time   `json:"moderatedAt,omitempty"`
+	CreatedAt        time.Time    `json:"createdAt"`
+	UpdatedAt        time.Time    `json:"updatedAt"`
+}
+
+// ToResponse converts a Review to ReviewResponse
+func (r *Review) ToResponse() ReviewResponse {
+	return ReviewResponse{
+		ID:               r.ID,
+		ProductID:        r.ProductID,
+		CustomerID:       r.CustomerID,
+		Rating:           r.Rating,
+		Text:             r.Text,
+		Status:           r.Status,
+		ModerationReason: r.ModerationReason,
+		ModeratedAt:      r.ModeratedAt,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+	}
+}
+
+// CreateReviewRequest represents the request to review a product. A customer
+// reviews a product at most once.
+type CreateReviewRequest struct {
+	CustomerID string `json:"customerId" binding:"required,max=64"`
+	Rating     int    `json:"rating" binding:"required,min=1,max=5"`
+	Text       string `json:"text" binding:"max=2000"`
+}
+
+// UpdateReviewRequest represents the request to change a review. Only
+// provided fields are changed, and the review goes back to moderation.
+type UpdateReviewRequest struct {
+	Rating *int    `json:"rating,omitempty" binding:"omitempty,min=1,max=5"`
+	Text   *string `json:"text,omitempty" binding:"omitempty,max=2000"`
+}
+
+// ModerateReviewRequest represents the request to approve or reject a review
+type ModerateReviewRequest struct {
+	Status ReviewStatus `json:"status" binding:"required,enum"`
+	// Reason explains the decision; it is kept on the review
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// Normalize trims the text of the review
+func (r *Review) Normalize() {
+	r.Text = strings.TrimSpace(r.Text)
+}
+
+// ProductRating aggregates the ratings of the approved reviews of a product
+type ProductRating struct {
+	Count int `json:"count"`
+	Total int `json:"total"`
+}
+
+// Average returns the mean rating rounded to two decimals, or zero without
+// reviews
+func (r ProductRating) Average() float64 {
+	if r.Count == 0 {
+		return 0
+	}
+	return math.Round(float64(r.Total)/float64(r.Count)*100) / 100
+}
+
+// RatingOf aggregates the ratings of the approved reviews among reviews
+func RatingOf(reviews []*Review) ProductRating {
+	var rating ProductRating
+	for _, review := range reviews {
+		if review.Status == ReviewApproved {
+			rating.Count++
+			rating.Total += review.Rating
+		}
+	}
+	return rating
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewStatus_IsValid(t *testing.T) {
+	assert.True(t, ReviewPending.IsValid())
+	assert.True(t, ReviewApproved.IsValid())
+	assert.True(t, ReviewRejected.IsValid())
+	assert.False(t, ReviewStatus("approved").IsValid())
+}
+
+func TestRatingOf(t *testing.T) {
+	t.Run("Approved reviews only", func(t *testing.T) {
+		// Arrange
+		reviews := []*Review{
+			{Rating: 5, Status: ReviewApproved},
+			{Rating: 4, Status: ReviewApproved},
+			{Rating: 4, Status: ReviewApproved},
+			{Rating: 1, Status: ReviewPending},
+			{Rating: 1, Status: ReviewRejected},
+		}
+
+		// Act
+		rating := RatingOf(reviews)
+
+		// Assert
+		assert.Equal(t, ProductRating{Count: 3, Total: 13}, rating)
+		assert.Equal(t, 4.33, rating.Average())
+	})
+
+	t.Run("No reviews", func(t *testing.T) {
+		// Act
+		rating := RatingOf(nil)
+
+		// Assert
+		assert.Zero(t, rating.Count)
+		assert.Zero(t, rating.Average())
+	})
+}
+
+func TestProduct_ToResponse_Rating(t *testing.T) {
+	// Arrange
+	product := &Product{ID: "product-123", Rating: ProductRating{Count: 2, Total: 9}}
+
+	// Act
+	response := product.ToResponse()
+
+	// Assert
+	assert.Equal(t, 4.5, response.AverageRating)
+	assert.Equal(t, 2, response.ReviewCount)
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ScheduledChangeStatus is the state of a scheduled product change
+type ScheduledChangeStatus string
+
+const (
+	// ScheduledChangePending waits for its effective time
+	ScheduledChangePending ScheduledChangeStatus = "PENDING"
+	// ScheduledChangeApplying has been picked up by the scheduler
+	ScheduledChangeApplying ScheduledChangeStatus = "APPLYING"
+	// ScheduledChangeApplied has been made to the product
+	ScheduledChangeApplied ScheduledChangeStatus = "APPLIED"
+	// ScheduledChangeFailed was rejected when it was applied, e.g. because the
+	// product had been deleted; Failure tells why
+	ScheduledChangeFailed ScheduledChangeStatus = "FAILED"
+	// ScheduledChangeCancelled was cancelled before it took effect
+	ScheduledChangeCancelled ScheduledChangeStatus = "CANCELLED"
+)
+
+// ScheduledChange is a change to a product's price or active status that the
+// scheduler makes once EffectiveAt has passed. Price is a decimal amount in
+// Currency; either may be left out to keep the product's own.
+type ScheduledChange struct {
+	ID          string                `json:"id"`
+	ProductID   string                `json:"productId"`
+	Price       *json.Number          `json:"price,omitempty" swaggertype:"number"`
+	Currency    *string               `json:"currency,omitempty"`
+	Active      *bool                 `json:"active,omitempty"`
+	EffectiveAt time.Time             `json:"effectiveAt"`
+	Status      ScheduledChangeStatus `json:"status"`
+	Failure     string                `json:"failure,omitempty"`
+	CreatedAt   time.Time             `json:"createdAt"`
+	UpdatedAt   time.Time             `json:"updatedAt"`
+}
+
+// IsDue checks if a pending change has reached its effective time at now
+func (c *ScheduledChange) IsDue(now time.Time) bool {
+	return c.Status == ScheduledChangePending && !now.Before(c.EffectiveAt)
+}
+
+// UpdateRequest returns the product update the change makes
+func (c *ScheduledChange) UpdateRequest() UpdateProductRequest {
+	return UpdateProductRequest{
+		Price:    c.Price,
+		Currency: c.Currency,
+		Active:   c.Active,
+	}
+}
+
+// Transition moves the change to another status at now. Pending changes can
+// be cancelled, or picked up once due; changes being applied end up applied
+// or failed.
+func (c *ScheduledChange) Transition(to ScheduledChangeStatus, now time.Time) error {
+	allowed := false
+	switch to {
+	case ScheduledChangeApplying:
+		allowed = c.IsDue(now)
+	case ScheduledChangeCancelled:
+		allowed = c.Status == ScheduledChangePending
+	case ScheduledChangeApplied, ScheduledChangeFailed:
+		allowed = c.Status == ScheduledChangeApplying
+	}
+	if !allowed {
+		return ErrScheduledChangeNotPending
+	}
+
+	c.Status = to
+	c.UpdatedAt = now
+	return nil
+}
+
+// ScheduleChangeRequest represents the request to change a product's price,
+// currency or active status at a future time
+type ScheduleChangeRequest struct {
+	Price    *json.Number `json:"price,omitempty" swaggertype:"number"`
+	Currency *string      `json:"currency,omitempty" binding:"omitempty,currency"`
+	// Active publishes (true) or unpublishes (false) the product
+	Active      *bool     `json:"active,omitempty"`
+	EffectiveAt time.Time `json:"effectiveAt" binding:"required"`
+}
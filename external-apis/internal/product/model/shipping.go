@@ -0,0 +1,34 @@
+package model
+
+// Shipping measurements are bounded so a typo cannot turn a parcel into a
+// freight shipment
+const (
+	// MaxWeightGrams is the heaviest weight a product can have, 1000 kg
+	MaxWeightGrams = 1_000_000
+	// MaxDimensionMM is the longest side a product can have, 10 m
+	MaxDimensionMM = 10_000
+)
+
+// Dimensions are the outer measurements of a product as it is packed for
+// shipping, in millimetres
+type Dimensions struct {
+	LengthMM int `json:"lengthMm" binding:"gt=0,max=10000"`
+	WidthMM  int `json:"widthMm" binding:"gt=0,max=10000"`
+	HeightMM int `json:"heightMm" binding:"gt=0,max=10000"`
+}
+
+// Valid checks if every side is positive and within MaxDimensionMM
+func (d Dimensions) Valid() bool {
+	for _, side := range []int{d.LengthMM, d.WidthMM, d.HeightMM} {
+		if side <= 0 || side > MaxDimensionMM {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidWeight checks if a weight in grams is zero, for an unknown weight, or
+// within MaxWeightGrams
+func ValidWeight(grams int) bool {
+	return grams >= 0 && grams <= MaxWeightGrams
+}
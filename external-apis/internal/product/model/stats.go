@@ -0,0 +1,130 @@
+package model
+
+import (
+	"encoding/json"
+	"math/big"
+	"slices"
+	"strings"
+
+	"external-apis/internal/shared/money"
+)
+
+// ProductStats summarizes the products that are not deleted
+type ProductStats struct {
+	Total    int `json:"total"`
+	Active   int `json:"active"`
+	Inactive int `json:"inactive"`
+	// Categories counts the products of each category, largest first
+	Categories []CategoryCount `json:"categories"`
+	// Prices summarizes the prices of each currency, since prices in
+	// different currencies cannot be compared
+	Prices []PriceStats `json:"prices"`
+}
+
+// CategoryCount is the number of products in a category. Products without
+// a category are counted under empty ones.
+type CategoryCount struct {
+	CategoryID string `json:"categoryId"`
+	Category   string `json:"category"`
+	Count      int    `json:"count"`
+}
+
+// PriceStats summarizes the prices of the products in a currency. The
+// average is rounded half up to the minor unit of the currency.
+type PriceStats struct {
+	Currency string      `json:"currency"`
+	Count    int         `json:"count"`
+	Min      json.Number `json:"min" swaggertype:"number"`
+	Average  json.Number `json:"average" swaggertype:"number"`
+	Max      json.Number `json:"max" swaggertype:"number"`
+}
+
+// priceTotals accumulates the prices of a currency
+type priceTotals struct {
+	count    int
+	min, max money.Money
+	sum      big.Int
+}
+
+// NewProductStats summarizes products, skipping soft-deleted ones
+func NewProductStats(products []*Product) *ProductStats {
+	stats := &ProductStats{
+		Categories: []CategoryCount{},
+		Prices:     []PriceStats{},
+	}
+	categories := make(map[CategoryCount]int)
+	prices := make(map[string]*priceTotals)
+
+	for _, product := range products {
+		if product.IsDeleted() {
+			continue
+		}
+
+		stats.Total++
+		if product.Active {
+			stats.Active++
+		} else {
+			stats.Inactive++
+		}
+		categories[CategoryCount{CategoryID: product.CategoryID, Category: product.Category}]++
+
+		totals, exists := prices[product.Price.Currency]
+		if !exists {
+			totals = &priceTotals{min: product.Price, max: product.Price}
+			prices[product.Price.Currency] = totals
+		}
+		totals.count++
+		totals.sum.Add(&totals.sum, big.NewInt(product.Price.Amount))
+		if product.Price.Cmp(totals.min) < 0 {
+			totals.min = product.Price
+		}
+		if product.Price.Cmp(totals.max) > 0 {
+			totals.max = product.Price
+		}
+	}
+
+	for category, count := range categories {
+		category.Count = count
+		stats.Categories = append(stats.Categories, category)
+	}
+	slices.SortFunc(stats.Categories, func(a, b CategoryCount) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Category+"\x00"+a.CategoryID, b.Category+"\x00"+b.CategoryID)
+	})
+
+	for currency, totals := range prices {
+		stats.Prices = append(stats.Prices, PriceStats{
+			Currency: currency,
+			Count:    totals.count,
+			Min:      totals.min.Number(),
+			Average:  totals.average(currency).Number(),
+			Max:      totals.max.Number(),
+		})
+	}
+	slices.SortFunc(stats.Prices, func(a, b PriceStats) int {
+		return strings.Compare(a.Currency, b.Currency)
+	})
+
+	return stats
+}
+
+// average returns the mean of the prices rounded half up to the minor unit
+func (t *priceTotals) average(currency string) money.Money {
+	count := big.NewInt(int64(t.count))
+	// floor((2 * sum + count) / (2 * count)) rounds half up for any sign
+	numerator := new(big.Int).Lsh(&t.sum, 1)
+	numerator.Add(numerator, count)
+	denominator := new(big.Int).Lsh(count, 1)
+	mean := new(big.Int).Div(numerator, denominator)
+	return money.Money{Amount: mean.Int64(), Currency: currency}
+}
+
+// Clone returns a copy of the stats that shares nothing with them
+func (s *ProductStats) Clone() *ProductStats {
+	clone := *s
+	clone.Categories = slices.Clone(s.Categories)
+	clone.Prices = slices.Clone(s.Prices)
+	return &clone
+}
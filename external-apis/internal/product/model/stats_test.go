@@ -0,0 +1,77 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProductStats(t *testing.T) {
+	// Arrange
+	deletedAt := time.Now()
+	products := []*Product{
+		{ID: "product-1", Price: money.New(1000, "USD"), CategoryID: "category-books", Category: "Books", Active: true},
+		{ID: "product-2", Price: money.New(2001, "USD"), CategoryID: "category-books", Category: "Books", Active: true},
+		{ID: "product-3", Price: money.New(1500, "JPY"), CategoryID: "category-toys", Category: "Toys"},
+		{ID: "product-4", Price: money.New(2000, "USD"), Category: "Legacy", Active: true},
+		{ID: "product-5", Price: money.New(99900, "USD"), CategoryID: "category-toys", Category: "Toys", DeletedAt: &deletedAt},
+	}
+
+	// Act
+	stats := NewProductStats(products)
+
+	// Assert
+	assert.Equal(t, 4, stats.Total, "deleted products are not counted")
+	assert.Equal(t, 3, stats.Active)
+	assert.Equal(t, 1, stats.Inactive)
+	assert.Equal(t, []CategoryCount{
+		{CategoryID: "category-books", Category: "Books", Count: 2},
+		{Category: "Legacy", Count: 1},
+		{CategoryID: "category-toys", Category: "Toys", Count: 1},
+	}, stats.Categories)
+	assert.Equal(t, []PriceStats{
+		{Currency: "JPY", Count: 1, Min: "1500", Average: "1500", Max: "1500"},
+		{Currency: "USD", Count: 3, Min: json.Number("10.00"), Average: json.Number("16.67"), Max: json.Number("20.01")},
+	}, stats.Prices)
+}
+
+func TestNewProductStats_Empty(t *testing.T) {
+	// Act
+	stats := NewProductStats(nil)
+
+	// Assert
+	encoded, err := json.Marshal(stats)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"total":0,"active":0,"inactive":0,"categories":[],"prices":[]}`, string(encoded))
+}
+
+func TestPriceTotals_Average(t *testing.T) {
+	tests := []struct {
+		name   string
+		prices []int64
+		want   string
+	}{
+		{"Exact", []int64{1000, 3000}, "20.00"},
+		{"Round half up", []int64{1000, 1001}, "10.01"},
+		{"Round down", []int64{1000, 1000, 1001}, "10.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			products := make([]*Product, len(tt.prices))
+			for i, amount := range tt.prices {
+				products[i] = &Product{Price: money.New(amount, "EUR")}
+			}
+
+			// Act
+			stats := NewProductStats(products)
+
+			// Assert
+			assert.Equal(t, json.Number(tt.want), stats.Prices[0].Average)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// StockSubscriptionStatus is the status of a back-in-stock subscription
+type StockSubscriptionStatus string
+
+const (
+	// SubscriptionPending subscriptions wait for their product to be restocked
+	SubscriptionPending StockSubscriptionStatus = "PENDING"
+	// SubscriptionNotified subscriptions have been told of a restock
+	SubscriptionNotified StockSubscriptionStatus = "NOTIFIED"
+)
+
+// StockSubscription asks to be notified once when an out-of-stock product
+// is back in stock. An email has at most one pending subscription to a
+// product; it may subscribe again once notified.
+type StockSubscription struct {
+	ID        string
+	ProductID string
+	// CustomerID is set when a known customer subscribed
+	CustomerID string
+	// Email is kept lower-case
+	Email      string
+	Status     StockSubscriptionStatus
+	NotifiedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Clone returns a deep copy of the subscription
+func (s *StockSubscription) Clone() *StockSubscription {
+	clone := *s
+	if s.NotifiedAt != nil {
+		notifiedAt := *s.NotifiedAt
+		clone.NotifiedAt = &notifiedAt
+	}
+	return &clone
+}
+
+// StockSubscriptionResponse represents the API response for a back-in-stock
+// subscription
+type StockSubscriptionResponse struct {
+	ID         string                  `json:"id"`
+	ProductID  string                  `json:"productId"`
+	CustomerID string                  `json:"customerId,omitempty"`
+	Email      string                  `json:"email"`
+	Status     StockSubscriptionStatus `json:"status"`
+	NotifiedAt *time.Time              `json:"notifiedAt,omitempty"`
+	CreatedAt  time.Time               `json:"createdAt"`
+}
+
+// ToResponse converts a StockSubscription to StockSubscriptionResponse
+func (s *StockSubscription) ToResponse() StockSubscriptionResponse {
+	return StockSubscriptionResponse{
+		ID:         s.ID,
+		ProductID:  s.ProductID,
+		CustomerID: s.CustomerID,
+		Email:      s.Email,
+		Status:     s.Status,
+		NotifiedAt: s.NotifiedAt,
+		CreatedAt:  s.CreatedAt,
+	}
+}
+
+// StockSubscriptionRequest represents the request to be notified when a
+// product is back in stock
+type StockSubscriptionRequest struct {
+	Email      string `json:"email" binding:"required,email,max=254"`
+	CustomerID string `json:"customerId,omitempty" binding:"max=64"`
+}
+
+// NormalizeSubscriberEmail returns the form subscription emails are stored
+// and compared in
+func NormalizeSubscriberEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// BackInStockNotification is the data of the event published for each
+// subscription when its product is back in stock
+type BackInStockNotification struct {
+	SubscriptionID    string `json:"subscriptionId"`
+	ProductID         string `json:"productId"`
+	ProductName       string `json:"productName"`
+	SKU               string `json:"sku,omitempty"`
+	CustomerID        string `json:"customerId,omitempty"`
+	Email             string `json:"email"`
+	AvailableQuantity int    `json:"availableQuantity"`
+}
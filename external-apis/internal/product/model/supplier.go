@@ -0,0 +1,129 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// Supplier is a vendor products are purchased from. Supplier names are
+// unique, ignoring case.
+type Supplier struct {
+	ID   string
+	Name string
+	// ContactName, Email and Phone reach the supplier's sales contact
+	ContactName string
+	Email       string
+	Phone       string
+	// LeadTimeDays is how many days the supplier takes to deliver an order
+	LeadTimeDays int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Clone returns a copy of the supplier
+func (s *Supplier) Clone() *Supplier {
+	clone := *s
+	return &clone
+}
+
+// SupplierResponse represents the API response for a supplier
+type SupplierResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	ContactName  string    `json:"contactName,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	Phone        string    `json:"phone,omitempty"`
+	LeadTimeDays int       `json:"leadTimeDays"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ToResponse converts a Supplier to SupplierResponse
+func (s *Supplier) ToResponse() SupplierResponse {
+	return SupplierResponse{
+		ID:           s.ID,
+		Name:         s.Name,
+		ContactName:  s.ContactName,
+		Email:        s.Email,
+		Phone:        s.Phone,
+		LeadTimeDays: s.LeadTimeDays,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+}
+
+// SupplierRequest represents the request to create or replace a supplier
+type SupplierRequest struct {
+	Name         string `json:"name" binding:"required,max=200"`
+	ContactName  string `json:"contactName,omitempty" binding:"max=200"`
+	Email        string `json:"email,omitempty" binding:"omitempty,email"`
+	Phone        string `json:"phone,omitempty" binding:"max=32"`
+	LeadTimeDays int    `json:"leadTimeDays" binding:"min=0,max=365"`
+}
+
+// NormalizeSupplierName returns the form supplier names are compared in
+func NormalizeSupplierName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ProductSupplier links a product to a supplier it can be purchased from. A
+// product may have several suppliers and a supplier may supply many products.
+type ProductSupplier struct {
+	ProductID  string
+	SupplierID string
+	// SupplierSKU is the supplier's own code for the product
+	SupplierSKU string
+	// CostPrice is the price the supplier charges per unit
+	CostPrice money.Money
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a copy of the product supplier link
+func (l *ProductSupplier) Clone() *ProductSupplier {
+	clone := *l
+	return &clone
+}
+
+// ProductSupplierResponse represents the API response for a product supplier
+// link. SupplierName and LeadTimeDays are taken from the supplier, so a
+// purchase can be planned from the link alone.
+type ProductSupplierResponse struct {
+	ProductID    string      `json:"productId"`
+	SupplierID   string      `json:"supplierId"`
+	SupplierName string      `json:"supplierName"`
+	SupplierSKU  string      `json:"supplierSku,omitempty"`
+	CostPrice    json.Number `json:"costPrice" swaggertype:"number"`
+	Currency     string      `json:"currency"`
+	LeadTimeDays int         `json:"leadTimeDays"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+}
+
+// ToResponse converts a ProductSupplier of the supplier to
+// ProductSupplierResponse
+func (l *ProductSupplier) ToResponse(supplier *Supplier) ProductSupplierResponse {
+	return ProductSupplierResponse{
+		ProductID:    l.ProductID,
+		SupplierID:   l.SupplierID,
+		SupplierName: supplier.Name,
+		SupplierSKU:  l.SupplierSKU,
+		CostPrice:    l.CostPrice.Number(),
+		Currency:     l.CostPrice.Currency,
+		LeadTimeDays: supplier.LeadTimeDays,
+		CreatedAt:    l.CreatedAt,
+		UpdatedAt:    l.UpdatedAt,
+	}
+}
+
+// ProductSupplierRequest represents the request to link a product to a
+// supplier, or change the link. Currency defaults to the service's
+// configured currency.
+type ProductSupplierRequest struct {
+	CostPrice   json.Number `json:"costPrice" binding:"required" swaggertype:"number"`
+	Currency    string      `json:"currency,omitempty" binding:"omitempty,currency"`
+	SupplierSKU string      `json:"supplierSku,omitempty" binding:"max=64"`
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"encoding/json"
+	"maps"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// ProductVariant is a sellable version of a product that differs from its
+// siblings only in attributes such as size or color. Variants share the
+// product's name, description and category; each has its own SKU and stock.
+type ProductVariant struct {
+	ID  string `json:"id"`
+	SKU string `json:"sku"`
+	// Attributes distinguish the variant, e.g. {"size": "m", "color": "red"}
+	Attributes map[string]string `json:"attributes"`
+	// Price overrides the product price; nil means the variant sells at it
+	Price         *money.Money `json:"price,omitempty"`
+	StockQuantity int          `json:"stockQuantity"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+}
+
+// Clone returns a deep copy of the variant
+func (v ProductVariant) Clone() ProductVariant {
+	v.Attributes = maps.Clone(v.Attributes)
+	if v.Price != nil {
+		price := *v.Price
+		v.Price = &price
+	}
+	return v
+}
+
+// Normalize trims the SKU and attributes and lower-cases attribute names, so
+// "Size" and "size " name the same attribute
+func (v *ProductVariant) Normalize() {
+	v.SKU = strings.TrimSpace(v.SKU)
+
+	attributes := make(map[string]string, len(v.Attributes))
+	for name, value := range v.Attributes {
+		attributes[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	v.Attributes = attributes
+}
+
+// Validate checks that the variant has a SKU and non-empty attributes
+func (v *ProductVariant) Validate() error {
+	if v.SKU == "" {
+		return ErrSKURequired
+	}
+	if len(v.Attributes) == 0 {
+		return ErrVariantAttributesRequired
+	}
+	for name, value := range v.Attributes {
+		if name == "" || value == "" {
+			return ErrInvalidVariantAttribute
+		}
+	}
+	if v.StockQuantity < 0 {
+		return ErrNegativeStock
+	}
+	return nil
+}
+
+// FindVariant returns the product variant with the given ID
+func (p *Product) FindVariant(id string) (ProductVariant, bool) {
+	for _, variant := range p.Variants {
+		if variant.ID == id {
+			return variant, true
+		}
+	}
+	return ProductVariant{}, false
+}
+
+// CheckVariant reports whether another variant of the product already uses
+// the SKU or the attributes of the given one
+func (p *Product) CheckVariant(variant ProductVariant) error {
+	for _, existing := range p.Variants {
+		if existing.ID == variant.ID {
+			continue
+		}
+		if strings.EqualFold(existing.SKU, variant.SKU) {
+			return ErrSKUExists
+		}
+		if maps.Equal(existing.Attributes, variant.Attributes) {
+			return ErrVariantExists
+		}
+	}
+	return nil
+}
+
+// ProductVariantResponse represents the API response for a product variant.
+// Price is the price the variant sells at: its override if it has one, the
+// product price otherwise.
+type ProductVariantResponse struct {
+	ID            string            `json:"id"`
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+	Price         json.Number       `json:"price" swaggertype:"number"`
+	Currency      string            `json:"currency"`
+	PriceOverride bool              `json:"priceOverride"`
+	StockQuantity int               `json:"stockQuantity"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+	// ConvertedPrice is only set when the client asks for prices in another
+	// currency
+	ConvertedPrice *ConvertedPrice `json:"convertedPrice,omitempty"`
+}
+
+// ToResponse converts a ProductVariant to ProductVariantResponse, falling back
+// to the price of its product
+func (v ProductVariant) ToResponse(productPrice money.Money) ProductVariantResponse {
+	price := productPrice
+	if v.Price != nil {
+		price = *v.Price
+	}
+
+	return ProductVariantResponse{
+		ID:            v.ID,
+		SKU:           v.SKU,
+		Attributes:    v.Attributes,
+		Price:         price.Number(),
+		Currency:      price.Currency,
+		PriceOverride: v.Price != nil,
+		StockQuantity: v.StockQuantity,
+		CreatedAt:     v.CreatedAt,
+		UpdatedAt:     v.UpdatedAt,
+	}
+}
+
+// CreateVariantRequest represents the request to add a variant to a product.
+// Without a price the variant sells at the product price; a price without a
+// currency is in the product's currency.
+type CreateVariantRequest struct {
+	SKU        string            `json:"sku" binding:"required,max=64"`
+	Attributes map[string]string `json:"attributes" binding:"required,min=1"`
+	Price      *json.Number      `json:"price,omitempty" swaggertype:"number"`
+	Currency   string            `json:"currency,omitempty" binding:"omitempty,currency"`
+	// StockQuantity is the initial number of units on hand
+	StockQuantity int `json:"stockQuantity" binding:"gte=0"`
+}
+
+// UpdateVariantRequest represents the request to update a variant. Attributes
+// replace the existing ones as a whole. InheritPrice removes the price
+// override so the variant sells at the product price again.
+type UpdateVariantRequest struct {
+	SKU           *string           `json:"sku,omitempty" binding:"omitempty,max=64"`
+	Attributes    map[string]string `json:"attributes,omitempty" binding:"omitempty,min=1"`
+	Price         *json.Number      `json:"price,omitempty" swaggertype:"number"`
+	Currency      *string           `json:"currency,omitempty" binding:"omitempty,currency"`
+	InheritPrice  bool              `json:"inheritPrice,omitempty"`
+	StockQuantity *int              `json:"stockQuantity,omitempty" binding:"omitempty,gte=0"`
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// CategoryRepository defines the interface for product category operations
+type CategoryRepository interface {
+	GetAll(ctx context.Context) ([]*model.Category, error)
+	GetByID(ctx context.Context, id string) (*model.Category, error)
+	Create(ctx context.Context, category *model.Category) (*model.Category, error)
+	Update(ctx context.Context, category *model.Category) (*model.Category, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryCategoryRepository implements CategoryRepository using in-memory
+// storage. It keeps the category tree consistent: parents must exist, a
+// category cannot be moved below itself, names are unique among siblings and
+// only categories without subcategories can be deleted.
+type MemoryCategoryRepository struct {
+	categories map[string]*model.Category
+	seed       map[string]model.Category
+	touched    map[string]time.Time
+	// ids generates the IDs of new categories
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryCategoryRepository creates a new in-memory category repository with sample data
+func NewMemoryCategoryRepository() *MemoryCategoryRepository {
+	repo := newEmptyMemoryCategoryRepository()
+
+	// Initialize with sample data
+	repo.initSampleData()
+
+	return repo
+}
+
+// newEmptyMemoryCategoryRepository creates an in-memory category repository without sample data
+func newEmptyMemoryCategoryRepository() *MemoryCategoryRepository {
+	return &MemoryCategoryRepository{
+		categories: make(map[string]*model.Category),
+		seed:       make(map[string]model.Category),
+		touched:    make(map[string]time.Time),
+		ids:        idgen.Default(),
+	}
+}
+
+// GetAll retrieves all categories ordered by name
+func (r *MemoryCategoryRepository) GetAll(ctx context.Context) ([]*model.Category, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	categories := make([]*model.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		categories = append(categories, category.Clone())
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Name != categories[j].Name {
+			return categories[i].Name < categories[j].Name
+		}
+		return categories[i].ID < categories[j].ID
+	})
+
+	return categories, nil
+}
+
+// GetByID retrieves a category by ID
+func (r *MemoryCategoryRepository) GetByID(ctx context.Context, id string) (*model.Category, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	category, exists := r.categories[id]
+	if !exists {
+		return nil, model.ErrCategoryNotFound
+	}
+
+	return category.Clone(), nil
+}
+
+// Create adds a category below its parent, or at the top level
+func (r *MemoryCategoryRepository) Create(ctx context.Context, category *model.Category) (*model.Category, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if category.ID == "" {
+		category.ID = r.ids.New()
+	}
+
+	if _, exists := r.categories[category.ID]; exists {
+		return nil, model.ErrCategoryExists
+	}
+	if err := r.validatePlacementUnsafe(category); err != nil {
+		return nil, err
+	}
+
+	if category.CreatedAt.IsZero() {
+		category.CreatedAt = time.Now().UTC()
+	}
+
+	stored := category.Clone()
+	r.categories[stored.ID] = stored
+	r.touched[stored.ID] = time.Now()
+
+	return stored.Clone(), nil
+}
+
+// Update replaces an existing category, possibly moving it to another parent
+func (r *MemoryCategoryRepository) Update(ctx context.Context, category *model.Category) (*model.Category, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.categories[category.ID]
+	if !exists {
+		return nil, model.ErrCategoryNotFound
+	}
+	if err := r.validatePlacementUnsafe(category); err != nil {
+		return nil, err
+	}
+
+	stored := category.Clone()
+	stored.CreatedAt = existing.CreatedAt
+	r.categories[stored.ID] = stored
+	r.touched[stored.ID] = time.Now()
+
+	return stored.Clone(), nil
+}
+
+// Delete removes a category that has no subcategories
+func (r *MemoryCategoryRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.categories[id]; !exists {
+		return model.ErrCategoryNotFound
+	}
+	for _, other := range r.categories {
+		if other.ParentID == id {
+			return model.ErrCategoryHasChildren
+		}
+	}
+
+	delete(r.categories, id)
+	r.touched[id] = time.Now()
+	return nil
+}
+
+// PurgeExpired reverts categories written before cutoff to their seed state,
+// removing categories that were not part of the seed data. Subcategories whose
+// parent was removed are moved to the top level.
+func (r *MemoryCategoryRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if !writtenAt.Before(cutoff) {
+			continue
+		}
+
+		if seeded, exists := r.seed[id]; exists {
+			category := seeded
+			r.categories[id] = &category
+		} else {
+			delete(r.categories, id)
+		}
+
+		delete(r.touched, id)
+		purged++
+	}
+
+	if purged > 0 {
+		tree := model.CategoryTree(r.categories)
+		for _, category := range r.categories {
+			if category.ParentID == "" {
+				continue
+			}
+			if _, exists := r.categories[category.ParentID]; !exists || tree.IsAncestor(category.ID, category.ParentID) {
+				category.ParentID = ""
+			}
+		}
+	}
+
+	return purged
+}
+
+// Reset restores the repository to its seed data
+func (r *MemoryCategoryRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.categories = make(map[string]*model.Category, len(r.seed))
+	for id, seeded := range r.seed {
+		r.categories[id] = seeded.Clone()
+	}
+	r.touched = make(map[string]time.Time)
+}
+
+// validatePlacementUnsafe checks that the category's parent exists, is not
+// the category itself or one of its subcategories, and has no other child
+// with the same name (without locking)
+func (r *MemoryCategoryRepository) validatePlacementUnsafe(category *model.Category) error {
+	if category.ParentID != "" {
+		if _, exists := r.categories[category.ParentID]; !exists {
+			return model.ErrParentCategoryNotFound
+		}
+
+		tree := model.CategoryTree(r.categories)
+		if tree.IsAncestor(category.ID, category.ParentID) {
+			return model.ErrCategoryCycle
+		}
+	}
+
+	for _, other := range r.categories {
+		if other.ID != category.ID && other.ParentID == category.ParentID && strings.EqualFold(other.Name, category.Name) {
+			return model.ErrCategoryExists
+		}
+	}
+
+	return nil
+}
+
+// initSampleData initializes the repository with sample data
+func (r *MemoryCategoryRepository) initSampleData() {
+	createdAt := time.Date(2024, time.January, 15, 9, 0, 0, 0, time.UTC)
+	sampleCategories := []*model.Category{
+		{
+			ID:          "category-electronics",
+			Name:        "Electronics",
+			Description: "Consumer electronics and devices",
+			Attributes: []model.AttributeSchema{
+				{Key: "color", Type: model.AttributeString},
+				{Key: "weight", Type: model.AttributeNumber, Unit: "kg"},
+			},
+			CreatedAt: createdAt,
+		},
+		{
+			ID:          "category-computers",
+			Name:        "Computers",
+			Description: "Laptops, desktops and monitors",
+			ParentID:    "category-electronics",
+			Attributes: []model.AttributeSchema{
+				{Key: "screen_size", Type: model.AttributeNumber, Unit: "in"},
+				{Key: "memory_gb", Type: model.AttributeNumber},
+			},
+			CreatedAt: createdAt,
+		},
+		{
+			ID:          "category-accessories",
+			Name:        "Accessories",
+			Description: "Mice, keyboards, hubs and other peripherals",
+			ParentID:    "category-electronics",
+			Attributes: []model.AttributeSchema{
+				{Key: "wireless", Type: model.AttributeBoolean},
+			},
+			CreatedAt: createdAt,
+		},
+	}
+
+	for _, category := range sampleCategories {
+		r.categories[category.ID] = category
+		r.seed[category.ID] = *category.Clone()
+	}
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCategoryRepository_Create(t *testing.T) {
+	t.Run("Create subcategory", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCategoryRepository()
+
+		// Act
+		created, err := repo.Create(context.Background(), &model.Category{Name: "Laptops", ParentID: "category-computers"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEmpty(t, created.ID)
+		assert.False(t, created.CreatedAt.IsZero())
+	})
+
+	tests := []struct {
+		name     string
+		category *model.Category
+		err      error
+	}{
+		{name: "Missing parent", category: &model.Category{Name: "Laptops", ParentID: "category-missing"}, err: model.ErrParentCategoryNotFound},
+		{name: "Duplicate sibling name", category: &model.Category{Name: "computers", ParentID: "category-electronics"}, err: model.ErrCategoryExists},
+		{name: "Duplicate ID", category: &model.Category{ID: "category-computers", Name: "Desktops"}, err: model.ErrCategoryExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := NewMemoryCategoryRepository()
+
+			// Act
+			created, err := repo.Create(context.Background(), tt.category)
+
+			// Assert
+			assert.Nil(t, created)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestMemoryCategoryRepository_Update(t *testing.T) {
+	t.Run("Move category to the top level", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCategoryRepository()
+
+		// Act
+		updated, err := repo.Update(context.Background(), &model.Category{ID: "category-computers", Name: "Computers"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, updated.ParentID)
+		assert.False(t, updated.CreatedAt.IsZero())
+	})
+
+	t.Run("Move category below its own subcategory", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCategoryRepository()
+
+		// Act
+		updated, err := repo.Update(context.Background(), &model.Category{ID: "category-electronics", Name: "Electronics", ParentID: "category-computers"})
+
+		// Assert
+		assert.Nil(t, updated)
+		assert.ErrorIs(t, err, model.ErrCategoryCycle)
+	})
+
+	t.Run("Stored attribute schema is not shared", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCategoryRepository()
+		category, err := repo.GetByID(context.Background(), "category-electronics")
+		require.NoError(t, err)
+		category.Attributes = append(category.Attributes, model.AttributeSchema{Key: "finish", Type: model.AttributeString, Values: []string{"matte"}})
+
+		// Act
+		updated, err := repo.Update(context.Background(), category)
+		require.NoError(t, err)
+		updated.Attributes[len(updated.Attributes)-1].Values[0] = "glossy"
+		category.Attributes[0].Key = "changed"
+
+		// Assert
+		stored, err := repo.GetByID(context.Background(), "category-electronics")
+		require.NoError(t, err)
+		assert.Equal(t, "color", stored.Attributes[0].Key)
+		assert.Equal(t, []string{"matte"}, stored.Attributes[len(stored.Attributes)-1].Values)
+	})
+
+	t.Run("Update non-existing category", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryCategoryRepository()
+
+		// Act
+		updated, err := repo.Update(context.Background(), &model.Category{ID: "category-missing", Name: "Missing"})
+
+		// Assert
+		assert.Nil(t, updated)
+		assert.ErrorIs(t, err, model.ErrCategoryNotFound)
+	})
+}
+
+func TestMemoryCategoryRepository_Delete(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCategoryRepository()
+
+	t.Run("Delete category with subcategories", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), "category-electronics")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCategoryHasChildren)
+	})
+
+	t.Run("Delete leaf category", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), "category-accessories")
+
+		// Assert
+		require.NoError(t, err)
+		_, err = repo.GetByID(context.Background(), "category-accessories")
+		assert.ErrorIs(t, err, model.ErrCategoryNotFound)
+	})
+}
+
+func TestMemoryCategoryRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCategoryRepository()
+
+	created, err := repo.Create(context.Background(), &model.Category{Name: "Garden"})
+	require.NoError(t, err)
+	_, err = repo.Create(context.Background(), &model.Category{ID: "category-tools", Name: "Tools", ParentID: created.ID})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(context.Background(), "category-accessories"))
+
+	// Act
+	repo.touched["category-tools"] = time.Now().Add(time.Hour)
+	purged := repo.PurgeExpired(time.Now().Add(time.Second))
+
+	// Assert
+	assert.Equal(t, 2, purged)
+	_, err = repo.GetByID(context.Background(), created.ID)
+	assert.ErrorIs(t, err, model.ErrCategoryNotFound)
+	_, err = repo.GetByID(context.Background(), "category-accessories")
+	assert.NoError(t, err)
+
+	orphan, err := repo.GetByID(context.Background(), "category-tools")
+	require.NoError(t, err)
+	assert.Empty(t, orphan.ParentID)
+}
+
+func TestMemoryCategoryRepository_Reset(t *testing.T) {
+	// Arrange
+	repo := NewMemoryCategoryRepository()
+	_, err := repo.Create(context.Background(), &model.Category{Name: "Garden"})
+	require.NoError(t, err)
+
+	// Act
+	repo.Reset()
+
+	// Assert
+	categories, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, categories, 3)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"strings"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/store"
+)
+
+// skuIndex and gtinIndex keep the SKUs and GTINs of products unique, so
+// uniqueness checks and lookups by code need not scan the catalog. Products
+// and variants share one SKU namespace, keyed case-insensitively; GTINs are
+// keyed by their GTIN-14 form. Soft-deleted products stay indexed since they
+// may be restored.
+var (
+	skuIndex = store.NewUnique(func(product *model.Product) []string {
+		keys := make([]string, 0, len(product.Variants)+1)
+		if product.SKU != "" {
+			keys = append(keys, skuKey(product.SKU))
+		}
+		for _, variant := range product.Variants {
+			keys = append(keys, skuKey(variant.SKU))
+		}
+		return keys
+	})
+	gtinIndex = store.NewUnique(func(product *model.Product) []string {
+		if product.GTIN == "" {
+			return nil
+		}
+		return []string{model.GTINKey(product.GTIN)}
+	})
+)
+
+// skuOwner identifies the product or variant using a SKU. VariantID is
+// empty for the SKU of the product itself.
+type skuOwner struct {
+	ProductID string
+	VariantID string
+}
+
+// skuOwnerUnsafe returns the owner of a SKU, ignoring case. The caller
+// holds the repository lock.
+func (r *MemoryProductRepository) skuOwnerUnsafe(sku string) (skuOwner, bool) {
+	key := skuKey(sku)
+	id, exists := r.products.Lookup(skuIndex, key)
+	if !exists {
+		return skuOwner{}, false
+	}
+
+	product, _ := r.products.Get(id)
+	owner := skuOwner{ProductID: id}
+	if product.SKU == "" || skuKey(product.SKU) != key {
+		for _, variant := range product.Variants {
+			if skuKey(variant.SKU) == key {
+				owner.VariantID = variant.ID
+				break
+			}
+		}
+	}
+	return owner, true
+}
+
+// skuTakenUnsafe reports whether a product or variant other than the one
+// being written uses the SKU. variantID is empty when the SKU of the
+// product itself is written. The caller holds the repository lock.
+func (r *MemoryProductRepository) skuTakenUnsafe(sku, productID, variantID string) bool {
+	owner, exists := r.skuOwnerUnsafe(sku)
+	return exists && owner != skuOwner{ProductID: productID, VariantID: variantID}
+}
+
+// gtinTakenUnsafe reports whether a product other than productID uses the
+// GTIN. The caller holds the repository lock.
+func (r *MemoryProductRepository) gtinTakenUnsafe(gtin, productID string) bool {
+	id, exists := r.products.Lookup(gtinIndex, model.GTINKey(gtin))
+	return exists && id != productID
+}
+
+// skuKey returns the index key of a SKU
+func skuKey(sku string) string {
+	return strings.ToLower(sku)
+}
@@ -0,0 +1,482 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/timerange"
+)
+
+var log = logger.New("product/repository")
+
+// IndexJobKind is the background job kind used to sync products to Elasticsearch
+const IndexJobKind = "search.index"
+
+// ElasticsearchConfig configures the Elasticsearch/OpenSearch search backend
+type ElasticsearchConfig struct {
+	// URL is the base URL of the cluster, e.g. http://localhost:9200
+	URL      string
+	Index    string
+	Username string
+	Password string
+	// Timeout bounds a single request to the cluster
+	Timeout time.Duration
+}
+
+// ElasticsearchRepository implements SearchRepository on top of an
+// Elasticsearch or OpenSearch index. Products are read from the source
+// repository and synced to the index in the background whenever a product
+// event is published to it. All tenants share the index; every document
+// records its tenant and searches only match documents of the caller's.
+type ElasticsearchRepository struct {
+	config ElasticsearchConfig
+	source ProductRepository
+	jobs   *jobs.Manager
+	client *http.Client
+}
+
+// indexJob is the job payload for syncing a single product
+type indexJob struct {
+	ProductID string `json:"productId"`
+	Tenant    string `json:"tenant,omitempty"`
+}
+
+// indexMapping is the mapping created for the product index. Category has a
+// lower-cased keyword field so filters match case-insensitively like the
+// memory repository. Attributes are nested so a filter matches the key and
+// value of the same attribute; their values are lower-cased keywords.
+const indexMapping = `{
+  "settings": {
+    "analysis": {
+      "normalizer": {
+        "lowercase": {"type": "custom", "filter": ["lowercase"]}
+      }
+    }
+  },
+  "mappings": {
+    "properties": {
+      "id": {"type": "keyword"},
+      "tenant": {"type": "keyword"},
+      "name": {"type": "text"},
+      "description": {"type": "text"},
+      "categoryId": {"type": "keyword"},
+      "category": {
+        "type": "text",
+        "fields": {"keyword": {"type": "keyword", "normalizer": "lowercase"}}
+      },
+      "price": {"type": "scaled_float", "scaling_factor": 1000},
+      "currency": {"type": "keyword"},
+      "active": {"type": "boolean"},
+      "tags": {"type": "keyword"},
+      "attributes": {
+        "type": "nested",
+        "properties": {
+          "key": {"type": "keyword"},
+          "type": {"type": "keyword"},
+          "value": {"type": "keyword", "normalizer": "lowercase"}
+        }
+      },
+      "stockQuantity": {"type": "integer"},
+      "reservedQuantity": {"type": "integer"},
+      "createdAt": {"type": "date"},
+      "updatedAt": {"type": "date"}
+    }
+  }
+}`
+
+// NewElasticsearchRepository creates a search repository backed by the given
+// index and registers its sync job with the job manager. It must be created
+// before the manager is started so persisted syncs can be resumed.
+func NewElasticsearchRepository(config ElasticsearchConfig, source ProductRepository, manager *jobs.Manager) *ElasticsearchRepository {
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+
+	r := &ElasticsearchRepository{
+		config: config,
+		source: source,
+		jobs:   manager,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+	manager.Register(IndexJobKind, r.handleIndexJob)
+
+	return r
+}
+
+// EnsureIndex creates the product index with its mapping unless it already exists
+func (r *ElasticsearchRepository) EnsureIndex(ctx context.Context) error {
+	status, _, err := r.do(ctx, http.MethodHead, r.indexPath(""), nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+
+	status, body, err := r.do(ctx, http.MethodPut, r.indexPath(""), []byte(indexMapping))
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return responseError(status, body)
+	}
+
+	log.Ctx(ctx).WithField("index", r.config.Index).Info("Created search index")
+	return nil
+}
+
+// Ping checks that the cluster is reachable and serves the product index
+func (r *ElasticsearchRepository) Ping(ctx context.Context) error {
+	status, body, err := r.do(ctx, http.MethodHead, r.indexPath(""), nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return responseError(status, body)
+	}
+	return nil
+}
+
+// Reindex writes every product of the tenant of ctx to the index using the bulk API
+func (r *ElasticsearchRepository) Reindex(ctx context.Context) error {
+	owner := tenant.FromContext(ctx)
+	products, err := r.source.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	for _, product := range products {
+		action := map[string]interface{}{"index": map[string]string{"_id": documentID(owner, product.ID)}}
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+		doc, err := marshalDocument(owner, product)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+	}
+
+	status, body, err := r.do(ctx, http.MethodPost, r.indexPath("/_bulk"), payload.Bytes())
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return responseError(status, body)
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("invalid bulk response: %w", err)
+	}
+	if result.Errors {
+		return fmt.Errorf("bulk indexing of %d products reported errors", len(products))
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"index":  r.config.Index,
+		"tenant": owner,
+		"count":  len(products),
+	}).Info("Reindexed products")
+	return nil
+}
+
+// Publish queues a sync of the product an event is about, so the index
+// follows product changes when the repository is registered as an
+// events.Publisher
+func (r *ElasticsearchRepository) Publish(event events.Event) {
+	if !strings.HasPrefix(event.Type, "product.") || event.Subject == "" {
+		return
+	}
+
+	if _, err := r.jobs.Submit(IndexJobKind, indexJob{ProductID: event.Subject, Tenant: event.Tenant}); err != nil {
+		log.WithError(err).WithField("product_id", event.Subject).Error("Failed to queue search index sync")
+	}
+}
+
+// Sync writes the current state of a product of the tenant of ctx to the
+// index, removing it when the product no longer exists or has been deleted
+func (r *ElasticsearchRepository) Sync(ctx context.Context, id string) error {
+	owner := tenant.FromContext(ctx)
+	path := r.indexPath("/_doc/" + url.PathEscape(documentID(owner, id)))
+
+	product, err := r.source.GetByID(ctx, id)
+	if err != nil {
+		status, body, err := r.do(ctx, http.MethodDelete, path, nil)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK && status != http.StatusNotFound {
+			return responseError(status, body)
+		}
+		return nil
+	}
+
+	doc, err := marshalDocument(owner, product)
+	if err != nil {
+		return err
+	}
+
+	status, body, err := r.do(ctx, http.MethodPut, path, doc)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return responseError(status, body)
+	}
+	return nil
+}
+
+// Search ranks the products of the tenant of ctx matching the query and
+// filter by relevance and returns a page of hits along with the total number
+// of matches
+func (r *ElasticsearchRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	request, err := json.Marshal(buildSearchQuery(tenant.FromContext(ctx), query))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	status, body, err := r.do(ctx, http.MethodPost, r.indexPath("/_search"), request)
+	if err != nil {
+		return nil, 0, err
+	}
+	if status != http.StatusOK {
+		return nil, 0, responseError(status, body)
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Score  float64       `json:"_score"`
+				Source model.Product `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, 0, fmt.Errorf("invalid search response: %w", err)
+	}
+
+	hits := make([]model.ProductSearchHit, len(result.Hits.Hits))
+	for i, hit := range result.Hits.Hits {
+		product := hit.Source
+		hits[i] = model.ProductSearchHit{Product: &product, Score: hit.Score}
+	}
+
+	return hits, result.Hits.Total.Value, nil
+}
+
+// handleIndexJob syncs the product named in the job payload
+func (r *ElasticsearchRepository) handleIndexJob(ctx context.Context, payload json.RawMessage) error {
+	var job indexJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return fmt.Errorf("invalid search index payload: %w", err)
+	}
+
+	if job.Tenant != "" {
+		ctx = tenant.WithTenant(ctx, job.Tenant)
+	}
+	return r.Sync(ctx, job.ProductID)
+}
+
+// marshalDocument encodes a product as stored in the index, tagged with its tenant
+func marshalDocument(owner string, product *model.Product) (json.RawMessage, error) {
+	encoded, err := json.Marshal(product)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	fields["tenant"], _ = json.Marshal(owner)
+	return json.Marshal(fields)
+}
+
+// documentID returns the ID of the document of a product. Products of the
+// default tenant keep their own ID, so indexes created before tenants were
+// introduced stay valid.
+func documentID(owner, id string) string {
+	if owner == tenant.Default {
+		return id
+	}
+	return owner + ":" + id
+}
+
+// buildSearchQuery translates a product search into an Elasticsearch query.
+// Name matches weigh most and description matches least, mirroring the
+// memory repository.
+func buildSearchQuery(owner string, query model.ProductSearch) map[string]interface{} {
+	filter := query.Filter
+
+	filters := []interface{}{term("tenant", owner)}
+	if filter.Category != "" {
+		filters = append(filters, term("category.keyword", strings.ToLower(filter.Category)))
+	}
+	if len(filter.CategoryIDs) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"categoryId": filter.CategoryIDs}})
+	}
+	if filter.Currency != "" {
+		filters = append(filters, term("currency", filter.Currency))
+	}
+	if filter.Active != nil {
+		filters = append(filters, term("active", *filter.Active))
+	}
+	for _, tag := range filter.Tags {
+		filters = append(filters, term("tags", tag))
+	}
+	for _, attribute := range filter.Attributes {
+		filters = append(filters, attributeFilter(attribute))
+	}
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		bounds := map[string]interface{}{}
+		if filter.MinPrice != nil {
+			bounds["gte"] = ratNumber(filter.MinPrice)
+		}
+		if filter.MaxPrice != nil {
+			bounds["lte"] = ratNumber(filter.MaxPrice)
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"price": bounds}})
+	}
+	if !filter.Created.IsZero() {
+		filters = append(filters, timeRange("createdAt", filter.Created))
+	}
+	if !filter.Updated.IsZero() {
+		filters = append(filters, timeRange("updatedAt", filter.Updated))
+	}
+
+	limit := filter.Page.Limit
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	return map[string]interface{}{
+		"from":             filter.Page.Offset,
+		"size":             limit,
+		"track_total_hits": true,
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": map[string]interface{}{
+					"multi_match": map[string]interface{}{
+						"query":  query.Query,
+						"fields": []string{fmt.Sprintf("name^%d", nameWeight), fmt.Sprintf("category^%d", categoryWeight), "description"},
+					},
+				},
+				"filter": filters,
+			},
+		},
+	}
+}
+
+// term builds an exact-match filter clause
+func term(field string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{field: value}}
+}
+
+// attributeFilter builds a filter clause matching products with the
+// attribute value
+func attributeFilter(filter model.AttributeFilter) map[string]interface{} {
+	return map[string]interface{}{
+		"nested": map[string]interface{}{
+			"path": "attributes",
+			"query": map[string]interface{}{
+				"bool": map[string]interface{}{
+					"filter": []interface{}{
+						term("attributes.key", filter.Key),
+						map[string]interface{}{"terms": map[string]interface{}{"attributes.value": filter.Values()}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// timeRange builds a filter clause matching the times of field within r
+func timeRange(field string, r timerange.Range) map[string]interface{} {
+	bounds := map[string]interface{}{}
+	if !r.Since.IsZero() {
+		bounds["gte"] = r.Since.UTC().Format(time.RFC3339Nano)
+	}
+	if !r.Before.IsZero() {
+		bounds["lt"] = r.Before.UTC().Format(time.RFC3339Nano)
+	}
+	return map[string]interface{}{"range": map[string]interface{}{field: bounds}}
+}
+
+// ratNumber converts a price bound to a JSON number with enough precision for
+// every supported currency
+func ratNumber(value *big.Rat) json.Number {
+	return json.Number(value.FloatString(3))
+}
+
+// indexPath returns the path of an index endpoint
+func (r *ElasticsearchRepository) indexPath(endpoint string) string {
+	return "/" + url.PathEscape(r.config.Index) + endpoint
+}
+
+// do sends a request to the cluster and returns the response status and body
+func (r *ElasticsearchRepository) do(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.config.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		contentType := "application/json"
+		if strings.HasSuffix(path, "/_bulk") {
+			contentType = "application/x-ndjson"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	if r.config.Username != "" {
+		req.SetBasicAuth(r.config.Username, r.config.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("search backend request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, data, nil
+}
+
+// responseError describes an unexpected response from the cluster
+func responseError(status int, body []byte) error {
+	const maxLength = 200
+	if len(body) > maxLength {
+		body = body[:maxLength]
+	}
+	return fmt.Errorf("search backend responded with status %d: %s", status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/timerange"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is a request received by the fake cluster
+type recordedRequest struct {
+	method string
+	path   string
+	body   []byte
+}
+
+// fakeCluster records requests and answers them with canned responses keyed
+// by "METHOD path"
+type fakeCluster struct {
+	responses map[string]string
+	statuses  map[string]int
+	requests  []recordedRequest
+	mutex     sync.Mutex
+}
+
+func newFakeCluster(t *testing.T) (*fakeCluster, *httptest.Server) {
+	cluster := &fakeCluster{responses: map[string]string{}, statuses: map[string]int{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		key := r.Method + " " + r.URL.Path
+
+		cluster.mutex.Lock()
+		cluster.requests = append(cluster.requests, recordedRequest{method: r.Method, path: r.URL.Path, body: body})
+		status, hasStatus := cluster.statuses[key]
+		response := cluster.responses[key]
+		cluster.mutex.Unlock()
+
+		if !hasStatus {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return cluster, server
+}
+
+func (c *fakeCluster) recorded() []recordedRequest {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]recordedRequest(nil), c.requests...)
+}
+
+func newTestElasticsearchRepository(url string) *ElasticsearchRepository {
+	return NewElasticsearchRepository(ElasticsearchConfig{URL: url, Index: "products"}, NewMemoryProductRepository(), jobs.NewManager(nil, jobs.DefaultOptions()))
+}
+
+func TestElasticsearchRepository_Search(t *testing.T) {
+	// Arrange
+	cluster, server := newFakeCluster(t)
+	cluster.responses["POST /products/_search"] = `{
+		"hits": {
+			"total": {"value": 7},
+			"hits": [
+				{"_id": "product-001", "_score": 3.5, "_source": {"id": "product-001", "name": "Wireless Mouse", "price": 29.99, "currency": "USD", "active": true}}
+			]
+		}
+	}`
+	repo := newTestElasticsearchRepository(server.URL)
+	active := true
+
+	// Act
+	hits, total, err := repo.Search(tenant.WithTenant(context.Background(), "brand-a"), model.ProductSearch{
+		Query: "wireless",
+		Filter: model.ProductFilter{
+			Category:    "Electronics",
+			CategoryIDs: []string{"category-electronics", "category-accessories"},
+			MinPrice:    big.NewRat(10, 1),
+			Active:      &active,
+			Tags:        []string{"sale"},
+			Attributes:  []model.AttributeFilter{{Key: "screen_size", Value: "15.60"}},
+			Updated:     timerange.Range{Since: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+			Page:        pagination.Params{Limit: 1, Offset: 2},
+		},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 7, total)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "product-001", hits[0].Product.ID)
+	assert.Equal(t, "29.99", hits[0].Product.Price.Decimal())
+	assert.Equal(t, 3.5, hits[0].Score)
+
+	requests := cluster.recorded()
+	require.Len(t, requests, 1)
+	var query map[string]interface{}
+	require.NoError(t, json.Unmarshal(requests[0].body, &query))
+	assert.Equal(t, float64(2), query["from"])
+	assert.Equal(t, float64(1), query["size"])
+
+	boolQuery := query["query"].(map[string]interface{})["bool"].(map[string]interface{})
+	multiMatch := boolQuery["must"].(map[string]interface{})["multi_match"].(map[string]interface{})
+	assert.Equal(t, "wireless", multiMatch["query"])
+	assert.Equal(t, []interface{}{"name^3", "category^2", "description"}, multiMatch["fields"])
+	assert.ElementsMatch(t, []interface{}{
+		map[string]interface{}{"term": map[string]interface{}{"tenant": "brand-a"}},
+		map[string]interface{}{"term": map[string]interface{}{"category.keyword": "electronics"}},
+		map[string]interface{}{"terms": map[string]interface{}{"categoryId": []interface{}{"category-electronics", "category-accessories"}}},
+		map[string]interface{}{"term": map[string]interface{}{"active": true}},
+		map[string]interface{}{"term": map[string]interface{}{"tags": "sale"}},
+		map[string]interface{}{"nested": map[string]interface{}{
+			"path": "attributes",
+			"query": map[string]interface{}{"bool": map[string]interface{}{"filter": []interface{}{
+				map[string]interface{}{"term": map[string]interface{}{"attributes.key": "screen_size"}},
+				map[string]interface{}{"terms": map[string]interface{}{"attributes.value": []interface{}{"15.60", "15.6"}}},
+			}}},
+		}},
+		map[string]interface{}{"range": map[string]interface{}{"price": map[string]interface{}{"gte": 10.0}}},
+		map[string]interface{}{"range": map[string]interface{}{"updatedAt": map[string]interface{}{"gte": "2024-01-15T10:00:00Z"}}},
+	}, boolQuery["filter"])
+}
+
+func TestElasticsearchRepository_SearchError(t *testing.T) {
+	// Arrange
+	cluster, server := newFakeCluster(t)
+	cluster.statuses["POST /products/_search"] = http.StatusServiceUnavailable
+	repo := newTestElasticsearchRepository(server.URL)
+
+	// Act
+	hits, _, err := repo.Search(context.Background(), model.ProductSearch{Query: "wireless"})
+
+	// Assert
+	assert.Nil(t, hits)
+	assert.ErrorContains(t, err, "status 503")
+}
+
+func TestElasticsearchRepository_EnsureIndex(t *testing.T) {
+	t.Run("Create missing index", func(t *testing.T) {
+		// Arrange
+		cluster, server := newFakeCluster(t)
+		cluster.statuses["HEAD /products"] = http.StatusNotFound
+		repo := newTestElasticsearchRepository(server.URL)
+
+		// Act
+		err := repo.EnsureIndex(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		requests := cluster.recorded()
+		require.Len(t, requests, 2)
+		assert.Equal(t, http.MethodPut, requests[1].method)
+		assert.JSONEq(t, indexMapping, string(requests[1].body))
+	})
+
+	t.Run("Keep existing index", func(t *testing.T) {
+		// Arrange
+		cluster, server := newFakeCluster(t)
+		repo := newTestElasticsearchRepository(server.URL)
+
+		// Act
+		err := repo.EnsureIndex(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, cluster.recorded(), 1)
+	})
+}
+
+func TestElasticsearchRepository_Ping(t *testing.T) {
+	t.Run("Index available", func(t *testing.T) {
+		// Arrange
+		_, server := newFakeCluster(t)
+		repo := newTestElasticsearchRepository(server.URL)
+
+		// Act
+		err := repo.Ping(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Index missing", func(t *testing.T) {
+		// Arrange
+		cluster, server := newFakeCluster(t)
+		cluster.statuses["HEAD /products"] = http.StatusNotFound
+		repo := newTestElasticsearchRepository(server.URL)
+
+		// Act
+		err := repo.Ping(context.Background())
+
+		// Assert
+		assert.EqualError(t, err, "search backend responded with status 404: ")
+	})
+}
+
+func TestElasticsearchRepository_Sync(t *testing.T) {
+	t.Run("Index existing product", func(t *testing.T) {
+		// Arrange
+		cluster, server := newFakeCluster(t)
+		repo := newTestElasticsearchRepository(server.URL)
+
+		// Act
+		err := repo.Sync(context.Background(), "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		requests := cluster.recorded()
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPut, requests[0].method)
+		assert.Equal(t, "/products/_doc/product-001", requests[0].path)
+
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(requests[0].body, &document))
+		assert.Equal(t, "Wireless Mouse", document["name"])
+		assert.Equal(t, 29.99, document["price"])
+		assert.Equal(t, tenant.Default, document["tenant"])
+	})
+
+	t.Run("Index product of another tenant", func(t *testing.T) {
+		// Arrange
+		cluster, server := newFakeCluster(t)
+		source := NewTenantProductRepository()
+		repo := NewElasticsearchRepository(ElasticsearchConfig{URL: server.URL, Index: "products"}, source, jobs.NewManager(nil, jobs.DefaultOptions()))
+		ctx := tenant.WithTenant(context.Background(), "brand-a")
+		product, err := source.Create(ctx, &model.Product{Name: "Brand A Mug", Price: money.New(999, "USD")})
+		require.NoError(t, err)
+
+		// Act
+		err = repo.Sync(ctx, product.ID)
+
+		// Assert
+		require.NoError(t, err)
+		requests := cluster.recorded()
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPut, requests[0].method)
+		assert.Equal(t, "/products/_doc/brand-a:"+product.ID, requests[0].path)
+
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(requests[0].body, &document))
+		assert.Equal(t, "brand-a", document["tenant"])
+	})
+
+	t.Run("Remove deleted product", func(t *testing.T) {
+		// Arrange
+		cluster, server := newFakeCluster(t)
+		cluster.statuses["DELETE /products/_doc/product-001"] = http.StatusNotFound
+		repo := newTestElasticsearchRepository(server.URL)
+		require.NoError(t, repo.source.Delete(context.Background(), "product-001"))
+
+		// Act
+		err := repo.Sync(context.Background(), "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		requests := cluster.recorded()
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodDelete, requests[0].method)
+	})
+}
+
+func TestElasticsearchRepository_Publish(t *testing.T) {
+	// Arrange
+	cluster, server := newFakeCluster(t)
+	manager := jobs.NewManager(nil, jobs.DefaultOptions())
+	repo := NewElasticsearchRepository(ElasticsearchConfig{URL: server.URL, Index: "products"}, NewTenantProductRepository(), manager)
+	require.NoError(t, manager.Start())
+
+	// Act
+	repo.Publish(events.New(events.ProductUpdated, "product-002", nil))
+	repo.Publish(events.New(events.ProductUpdated, "product-002", nil).For("brand-a"))
+	repo.Publish(events.New(events.CustomerUpdated, "customer-123", nil))
+	require.NoError(t, manager.Drain(time.Second))
+
+	// Assert
+	requests := cluster.recorded()
+	require.Len(t, requests, 2)
+	paths := []string{requests[0].path, requests[1].path}
+	assert.ElementsMatch(t, []string{"/products/_doc/product-002", "/products/_doc/brand-a:product-002"}, paths)
+	for _, request := range requests {
+		if request.path == "/products/_doc/brand-a:product-002" {
+			assert.Equal(t, http.MethodDelete, request.method, "brand-a has no such product")
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/metrics"
+)
+
+// instrumentedName labels the metrics of the product repository
+const instrumentedName = "products"
+
+// InstrumentedProductRepository records the latency and outcome of every
+// operation of the wrapped repositories in repository metrics
+type InstrumentedProductRepository struct {
+	inner   ProductRepository
+	search  SearchRepository
+	metrics *metrics.RepositoryMetrics
+}
+
+// NewInstrumentedProductRepository wraps inner and search, usually the same
+// repository, so their operations are recorded in m
+func NewInstrumentedProductRepository(inner ProductRepository, search SearchRepository, m *metrics.RepositoryMetrics) *InstrumentedProductRepository {
+	return &InstrumentedProductRepository{
+		inner:   inner,
+		search:  search,
+		metrics: m,
+	}
+}
+
+// GetByID retrieves a product by ID
+func (r *InstrumentedProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "GetByID")
+	product, err := r.inner.GetByID(ctx, id)
+	done(err)
+	return product, err
+}
+
+// GetAll retrieves all products that have not been deleted
+func (r *InstrumentedProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "GetAll")
+	products, err := r.inner.GetAll(ctx)
+	done(err)
+	return products, err
+}
+
+// GetByIDs retrieves the products with the given IDs that exist and have
+// not been deleted
+func (r *InstrumentedProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "GetByIDs")
+	products, err := r.inner.GetByIDs(ctx, ids)
+	done(err)
+	return products, err
+}
+
+// GetBySKU retrieves the product with a SKU
+func (r *InstrumentedProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "GetBySKU")
+	product, err := r.inner.GetBySKU(ctx, sku)
+	done(err)
+	return product, err
+}
+
+// GetByGTIN retrieves the product with a GTIN
+func (r *InstrumentedProductRepository) GetByGTIN(ctx context.Context, gtin string) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "GetByGTIN")
+	product, err := r.inner.GetByGTIN(ctx, gtin)
+	done(err)
+	return product, err
+}
+
+// Find retrieves a page of products matching the filter along with the
+// total number of matches
+func (r *InstrumentedProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	done := r.metrics.Started(instrumentedName, "Find")
+	products, total, err := r.inner.Find(ctx, filter)
+	done(err)
+	return products, total, err
+}
+
+// Search ranks the products matching a full-text query
+func (r *InstrumentedProductRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	done := r.metrics.Started(instrumentedName, "Search")
+	hits, total, err := r.search.Search(ctx, query)
+	done(err)
+	return hits, total, err
+}
+
+// Create stores a new product
+func (r *InstrumentedProductRepository) Create(ctx context.Context, product *model.Product) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "Create")
+	created, err := r.inner.Create(ctx, product)
+	done(err)
+	return created, err
+}
+
+// Update replaces an existing product
+func (r *InstrumentedProductRepository) Update(ctx context.Context, id string, product *model.Product) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "Update")
+	updated, err := r.inner.Update(ctx, id, product)
+	done(err)
+	return updated, err
+}
+
+// Delete soft-deletes a product
+func (r *InstrumentedProductRepository) Delete(ctx context.Context, id string) error {
+	done := r.metrics.Started(instrumentedName, "Delete")
+	err := r.inner.Delete(ctx, id)
+	done(err)
+	return err
+}
+
+// Restore brings back a soft-deleted product
+func (r *InstrumentedProductRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "Restore")
+	restored, err := r.inner.Restore(ctx, id)
+	done(err)
+	return restored, err
+}
+
+// ExistsByID reports whether a product that has not been deleted exists
+func (r *InstrumentedProductRepository) ExistsByID(ctx context.Context, id string) bool {
+	done := r.metrics.Started(instrumentedName, "ExistsByID")
+	exists := r.inner.ExistsByID(ctx, id)
+	done(nil)
+	return exists
+}
+
+// ReserveStock takes quantity units out of the stock of a product
+func (r *InstrumentedProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "ReserveStock")
+	product, err := r.inner.ReserveStock(ctx, id, quantity)
+	done(err)
+	return product, err
+}
+
+// ReleaseStock puts quantity reserved units back into the stock of a product
+func (r *InstrumentedProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "ReleaseStock")
+	product, err := r.inner.ReleaseStock(ctx, id, quantity)
+	done(err)
+	return product, err
+}
+
+// AdjustStock changes the stock of a product by delta
+func (r *InstrumentedProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "AdjustStock")
+	product, err := r.inner.AdjustStock(ctx, id, delta)
+	done(err)
+	return product, err
+}
+
+// RenameCategory renames the category of the products in it
+func (r *InstrumentedProductRepository) RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "RenameCategory")
+	products, err := r.inner.RenameCategory(ctx, categoryID, name)
+	done(err)
+	return products, err
+}
+
+// AddImage adds an image to a product
+func (r *InstrumentedProductRepository) AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "AddImage")
+	product, err := r.inner.AddImage(ctx, id, image)
+	done(err)
+	return product, err
+}
+
+// RemoveImage removes an image from a product
+func (r *InstrumentedProductRepository) RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "RemoveImage")
+	product, err := r.inner.RemoveImage(ctx, id, imageID)
+	done(err)
+	return product, err
+}
+
+// AddVariant adds a variant to a product
+func (r *InstrumentedProductRepository) AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "AddVariant")
+	product, err := r.inner.AddVariant(ctx, id, variant)
+	done(err)
+	return product, err
+}
+
+// UpdateVariant replaces a variant of a product
+func (r *InstrumentedProductRepository) UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "UpdateVariant")
+	product, err := r.inner.UpdateVariant(ctx, id, variant)
+	done(err)
+	return product, err
+}
+
+// RemoveVariant removes a variant from a product
+func (r *InstrumentedProductRepository) RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "RemoveVariant")
+	product, err := r.inner.RemoveVariant(ctx, id, variantID)
+	done(err)
+	return product, err
+}
+
+// SetRating stores the rating of a product
+func (r *InstrumentedProductRepository) SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error) {
+	done := r.metrics.Started(instrumentedName, "SetRating")
+	product, err := r.inner.SetRating(ctx, id, rating)
+	done(err)
+	return product, err
+}
+
+// Stats summarizes the stored products
+func (r *InstrumentedProductRepository) Stats(ctx context.Context) (*model.ProductStats, error) {
+	done := r.metrics.Started(instrumentedName, "Stats")
+	stats, err := r.inner.Stats(ctx)
+	done(err)
+	return stats, err
+}
+
+// Transaction runs fn against a transaction of the wrapped repository whose
+// operations are recorded too. The transaction as a whole is recorded as
+// well, so its latency includes the wait for the write lock.
+func (r *InstrumentedProductRepository) Transaction(ctx context.Context, fn func(tx ProductRepository) error) error {
+	done := r.metrics.Started(instrumentedName, "Transaction")
+	err := r.inner.Transaction(ctx, func(tx ProductRepository) error {
+		return fn(NewInstrumentedProductRepository(tx, r.search, r.metrics))
+	})
+	done(err)
+	return err
+}
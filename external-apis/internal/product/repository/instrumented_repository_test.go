@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentedProductRepository(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	registry := prometheus.NewRegistry()
+	store := NewMemoryProductRepository()
+	repo := NewInstrumentedProductRepository(store, store, metrics.NewRepositoryMetrics(registry, "test-service"))
+
+	// Act
+	_, err := repo.GetByID(ctx, "product-789")
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, "product-missing")
+	require.ErrorIs(t, err, model.ErrProductNotFound)
+	_, _, err = repo.Search(ctx, model.ProductSearch{Query: "laptop"})
+	require.NoError(t, err)
+	err = repo.Transaction(ctx, func(tx ProductRepository) error {
+		_, err := tx.AdjustStock(ctx, "product-789", 1)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Assert
+	expected := `
+# HELP repository_operations_total Total number of repository operations, by outcome.
+# TYPE repository_operations_total counter
+repository_operations_total{operation="AdjustStock",outcome="ok",repository="products",service="test-service"} 1
+repository_operations_total{operation="GetByID",outcome="ok",repository="products",service="test-service"} 1
+repository_operations_total{operation="GetByID",outcome="rejected",repository="products",service="test-service"} 1
+repository_operations_total{operation="Search",outcome="ok",repository="products",service="test-service"} 1
+repository_operations_total{operation="Transaction",outcome="ok",repository="products",service="test-service"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "repository_operations_total"))
+}
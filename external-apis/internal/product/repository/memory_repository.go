@@ -1,35 +1,98 @@
 package repository
 
 import (
-	"errors"
-	"math/big"
+	"context"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"external-apis/internal/product/model"
-	"github.com/google/uuid"
+	"external-apis/internal/shared/clock"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/search"
+	"external-apis/internal/shared/store"
 )
 
 // ProductRepository defines the interface for product operations
 type ProductRepository interface {
-	GetByID(id string) (*model.Product, error)
-	GetAll() ([]*model.Product, error)
-	Create(product *model.Product) (*model.Product, error)
-	Update(id string, product *model.Product) (*model.Product, error)
-	Delete(id string) error
-	ExistsByID(id string) bool
+	GetByID(ctx context.Context, id string) (*model.Product, error)
+	GetAll(ctx context.Context) ([]*model.Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*model.Product, error)
+	GetByGTIN(ctx context.Context, gtin string) (*model.Product, error)
+	Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error)
+	Create(ctx context.Context, product *model.Product) (*model.Product, error)
+	Update(ctx context.Context, id string, product *model.Product) (*model.Product, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*model.Product, error)
+	ExistsByID(ctx context.Context, id string) bool
+	ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error)
+	ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error)
+	AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error)
+	RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error)
+	AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error)
+	RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error)
+	AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error)
+	UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error)
+	RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error)
+	SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error)
+	Stats(ctx context.Context) (*model.ProductStats, error)
+	Transaction(ctx context.Context, fn func(tx ProductRepository) error) error
 }
 
-// MemoryProductRepository implements ProductRepository using in-memory storage
+// SearchRepository ranks products by relevance to a full-text query. The
+// memory repository implements it with an inverted index; Elasticsearch or
+// OpenSearch can back it instead through ElasticsearchRepository.
+type SearchRepository interface {
+	Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error)
+}
+
+// Relevance weights of the searchable product fields
+const (
+	nameWeight        = 3
+	categoryWeight    = 2
+	descriptionWeight = 1
+)
+
+// MemoryProductRepository implements ProductRepository and SearchRepository
+// using in-memory storage. It stores copies of the products it is given and
+// returns copies of the stored ones, so callers never share a product with
+// the repository or with each other.
+//
+// Products are kept in a store that spreads them over shards by ID, each
+// with its own lock, so stock and image changes, the writes placing orders
+// contend on, run in parallel for products in different shards. The
+// repository lock guards what spans shards: the sorted IDs, the search index
+// and the index of SKUs and GTINs that keeps them unique.
+//
+//   - Reads of a product by ID lock its shard for reading only.
+//   - Listings and searches hold the repository lock for reading and lock
+//     each shard for reading while they read its products.
+//   - Stock and image changes hold the repository lock for reading and lock
+//     the shard of the product.
+//   - Every other write holds the repository lock and locks each shard it
+//     writes.
 type MemoryProductRepository struct {
-	products map[string]*model.Product
-	mutex    sync.RWMutex
+	// products holds the products, deleted ones included, and files them by
+	// SKU and GTIN
+	products *store.Store[*model.Product]
+	index    *search.Index
+	// stats summarizes the products until the next write holding the
+	// repository lock drops it. Stock and image changes do not affect it.
+	stats atomic.Pointer[model.ProductStats]
+	// clock stamps UpdatedAt, which grows with every write to a product
+	clock *clock.Clock
+	// ids generates the IDs of new products
+	ids   idgen.Generator
+	mutex sync.RWMutex
 }
 
-// NewMemoryProductRepository creates a new in-memory product repository
+// NewMemoryProductRepository creates a new in-memory product repository with sample data
 func NewMemoryProductRepository() *MemoryProductRepository {
-	repo := &MemoryProductRepository{
-		products: make(map[string]*model.Product),
-	}
+	repo := newEmptyMemoryProductRepository()
 
 	// Initialize with sample data
 	repo.initSampleData()
@@ -37,176 +100,761 @@ func NewMemoryProductRepository() *MemoryProductRepository {
 	return repo
 }
 
+// newEmptyMemoryProductRepository creates an in-memory product repository without sample data
+func newEmptyMemoryProductRepository() *MemoryProductRepository {
+	return &MemoryProductRepository{
+		products: newProductStore(),
+		index:    search.NewIndex(),
+		clock:    clock.New(),
+		ids:      idgen.Default(),
+	}
+}
+
+// newProductStore creates an empty store of products
+func newProductStore() *store.Store[*model.Product] {
+	return store.New(
+		func(product *model.Product) string { return product.ID },
+		(*model.Product).Clone,
+		skuIndex, gtinIndex,
+	)
+}
+
 // GetByID retrieves a product by ID
-func (r *MemoryProductRepository) GetByID(id string) (*model.Product, error) {
+func (r *MemoryProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	product, exists := r.products.Get(id)
+	if !exists || product.IsDeleted() {
+		return nil, model.ErrProductNotFound
+	}
+
+	return product.Clone(), nil
+}
+
+// GetByIDs retrieves the products with the given IDs that exist and have not
+// been deleted. Unknown IDs are skipped; the order of the result is undefined.
+func (r *MemoryProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, exists := r.products.Get(id); exists && !product.IsDeleted() {
+			products = append(products, product.Clone())
+		}
+	}
+
+	return products, nil
+}
+
+// GetBySKU retrieves the product with the given SKU, ignoring case. Variant
+// SKUs do not match.
+func (r *MemoryProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	product, exists := r.products[id]
-	if !exists {
-		return nil, errors.New("product not found")
+	owner, exists := r.skuOwnerUnsafe(sku)
+	if !exists || owner.VariantID != "" {
+		return nil, model.ErrProductNotFound
 	}
+	return r.GetByID(ctx, owner.ProductID)
+}
 
-	return product, nil
+// GetByGTIN retrieves the product with the given GTIN, in any of its lengths
+func (r *MemoryProductRepository) GetByGTIN(ctx context.Context, gtin string) (*model.Product, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	id, exists := r.products.Lookup(gtinIndex, model.GTINKey(gtin))
+	if !exists {
+		return nil, model.ErrProductNotFound
+	}
+	return r.GetByID(ctx, id)
 }
 
-// GetAll retrieves all products
-func (r *MemoryProductRepository) GetAll() ([]*model.Product, error) {
+// GetAll retrieves all products that have not been deleted, ordered by ID
+func (r *MemoryProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	products := make([]*model.Product, 0, len(r.products))
-	for _, product := range r.products {
-		products = append(products, product)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	products := make([]*model.Product, 0, r.products.Len())
+	for product := range r.products.All() {
+		if !product.IsDeleted() {
+			products = append(products, product.Clone())
+		}
 	}
 
 	return products, nil
 }
 
+// Find retrieves a page of products matching the filter along with the total number of matches
+func (r *MemoryProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]*model.Product, 0, r.products.Len())
+	for product := range r.products.All() {
+		if filter.Matches(product) {
+			matches = append(matches, product)
+		}
+	}
+	sortProducts(matches, filter.Sort)
+
+	start, end := filter.Page.Bounds(len(matches))
+	page := matches[start:end]
+	for i, product := range page {
+		page[i] = product.Clone()
+	}
+	return page, len(matches), nil
+}
+
+// Search ranks the products matching the query and filter by relevance and
+// returns a page of hits along with the total number of matches
+func (r *MemoryProductRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]model.ProductSearchHit, 0)
+	for _, hit := range r.index.Search(query.Query) {
+		product, exists := r.products.Get(hit.ID)
+		if !exists || !query.Filter.Matches(product) {
+			continue
+		}
+		hits = append(hits, model.ProductSearchHit{Product: product, Score: hit.Score})
+	}
+
+	start, end := query.Filter.Page.Bounds(len(hits))
+	page := hits[start:end]
+	for i := range page {
+		page[i].Product = page[i].Product.Clone()
+	}
+	return page, len(hits), nil
+}
+
 // Create creates a new product
-func (r *MemoryProductRepository) Create(product *model.Product) (*model.Product, error) {
+func (r *MemoryProductRepository) Create(ctx context.Context, product *model.Product) (*model.Product, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	product = product.Clone()
 	if product.ID == "" {
-		product.ID = uuid.New().String()
+		product.ID = r.ids.New()
 	}
 
-	if r.existsByIDUnsafe(product.ID) {
-		return nil, errors.New("product already exists")
+	// Soft-deleted products keep their ID reserved until they are purged
+	if _, exists := r.products.Get(product.ID); exists {
+		return nil, model.ErrProductExists
+	}
+	if err := r.checkCodesUnsafe(product, product.ID); err != nil {
+		return nil, err
 	}
 
-	r.products[product.ID] = product
-	return product, nil
+	product.UpdatedAt = r.clock.Now()
+	if product.CreatedAt.IsZero() {
+		product.CreatedAt = product.UpdatedAt
+	}
+	r.storeUnsafe(product)
+	r.indexProduct(product)
+	return product.Clone(), nil
 }
 
 // Update updates an existing product
-func (r *MemoryProductRepository) Update(id string, product *model.Product) (*model.Product, error) {
+func (r *MemoryProductRepository) Update(ctx context.Context, id string, product *model.Product) (*model.Product, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	if !r.existsByIDUnsafe(id) {
-		return nil, errors.New("product not found")
+		return nil, model.ErrProductNotFound
+	}
+	if err := r.checkCodesUnsafe(product, id); err != nil {
+		return nil, err
 	}
 
+	// Stock levels, images, variants and ratings only change through their
+	// own operations so a concurrent reservation or upload is never
+	// overwritten by a stale copy of the product
+	current, _ := r.products.Get(id)
+	product = product.Clone()
+	product.CreatedAt = current.CreatedAt
+	product.StockQuantity = current.StockQuantity
+	product.ReservedQuantity = current.ReservedQuantity
+	product.Images = current.Images
+	product.Variants = current.Variants
+	product.Rating = current.Rating
+
 	product.ID = id
-	r.products[id] = product
-	return product, nil
+	product.UpdatedAt = r.clock.After(current.UpdatedAt)
+	r.storeUnsafe(product)
+	r.indexProduct(product)
+	return product.Clone(), nil
 }
 
-// Delete deletes a product by ID
-func (r *MemoryProductRepository) Delete(id string) error {
+// Delete soft-deletes a product by ID so it can be restored later
+func (r *MemoryProductRepository) Delete(ctx context.Context, id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	if !r.existsByIDUnsafe(id) {
-		return errors.New("product not found")
+		return model.ErrProductNotFound
 	}
 
-	delete(r.products, id)
+	current, _ := r.products.Get(id)
+	deletedAt := r.clock.After(current.UpdatedAt)
+	deleted := current.Clone()
+	deleted.DeletedAt = &deletedAt
+	deleted.UpdatedAt = deletedAt
+	r.storeUnsafe(deleted)
+	r.indexProduct(deleted)
 	return nil
 }
 
+// Restore undoes the soft delete of a product
+func (r *MemoryProductRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	product, exists := r.products.Get(id)
+	if !exists {
+		return nil, model.ErrProductNotFound
+	}
+	if !product.IsDeleted() {
+		return nil, model.ErrProductNotDeleted
+	}
+
+	restored := product.Clone()
+	restored.DeletedAt = nil
+	restored.UpdatedAt = r.clock.After(product.UpdatedAt)
+	r.storeUnsafe(restored)
+	r.indexProduct(restored)
+	return restored.Clone(), nil
+}
+
 // ExistsByID checks if a product exists by ID
-func (r *MemoryProductRepository) ExistsByID(id string) bool {
+func (r *MemoryProductRepository) ExistsByID(ctx context.Context, id string) bool {
+	product, exists := r.products.Get(id)
+	return exists && !product.IsDeleted()
+}
+
+// ReserveStock atomically reserves units of an active product's available stock
+func (r *MemoryProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.updateRecord(id, func(product *model.Product) error {
+		if !product.Active {
+			return model.ErrProductInactive
+		}
+		if product.AvailableQuantity() < quantity {
+			return model.ErrInsufficientStock
+		}
+		product.ReservedQuantity += quantity
+		return nil
+	})
+}
+
+// ReleaseStock atomically returns previously reserved units to available stock
+func (r *MemoryProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.updateRecord(id, func(product *model.Product) error {
+		if product.ReservedQuantity < quantity {
+			return model.ErrReleaseExceedsStock
+		}
+		product.ReservedQuantity -= quantity
+		return nil
+	})
+}
+
+// AdjustStock atomically changes the units on hand by delta. Stock may not
+// drop below zero or below the units already reserved.
+func (r *MemoryProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error) {
+	return r.updateRecord(id, func(product *model.Product) error {
+		if product.StockQuantity+delta < product.ReservedQuantity {
+			return model.ErrBelowReserved
+		}
+		product.StockQuantity += delta
+		return nil
+	})
+}
+
+// RenameCategory updates the category name of every product in the category,
+// including soft-deleted ones, and returns the products that are not deleted
+func (r *MemoryProductRepository) RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	renamed := make([]*model.Product, 0)
+	for existing := range r.products.All() {
+		if existing.CategoryID != categoryID || existing.Category == name {
+			continue
+		}
+
+		product := existing.Clone()
+		product.Category = name
+		product.UpdatedAt = r.clock.After(existing.UpdatedAt)
+		r.storeUnsafe(product)
+		r.indexProduct(product)
+
+		if !product.IsDeleted() {
+			renamed = append(renamed, product.Clone())
+		}
+	}
+
+	return renamed, nil
+}
+
+// AddImage appends an image to a product
+func (r *MemoryProductRepository) AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error) {
+	return r.updateRecord(id, func(product *model.Product) error {
+		product.Images = append(product.Images, image)
+		return nil
+	})
+}
+
+// RemoveImage detaches an image from a product
+func (r *MemoryProductRepository) RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error) {
+	return r.updateRecord(id, func(product *model.Product) error {
+		index := slices.IndexFunc(product.Images, func(image model.ProductImage) bool {
+			return image.ID == imageID
+		})
+		if index < 0 {
+			return model.ErrImageNotFound
+		}
+		product.Images = slices.Delete(product.Images, index, index+1)
+		return nil
+	})
+}
+
+// AddVariant appends a variant to a product. SKUs are unique across the
+// products of the repository and attributes are unique within a product.
+func (r *MemoryProductRepository) AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.updateProduct(id, func(product *model.Product) error {
+		if err := r.checkVariantUnsafe(product, variant); err != nil {
+			return err
+		}
+		product.Variants = append(product.Variants, variant)
+		return nil
+	})
+}
+
+// UpdateVariant replaces the variant of a product with the same ID
+func (r *MemoryProductRepository) UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.updateProduct(id, func(product *model.Product) error {
+		index := slices.IndexFunc(product.Variants, func(existing model.ProductVariant) bool {
+			return existing.ID == variant.ID
+		})
+		if index < 0 {
+			return model.ErrVariantNotFound
+		}
+		if err := r.checkVariantUnsafe(product, variant); err != nil {
+			return err
+		}
+		product.Variants[index] = variant
+		return nil
+	})
+}
+
+// RemoveVariant detaches a variant from a product
+func (r *MemoryProductRepository) RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error) {
+	return r.updateProduct(id, func(product *model.Product) error {
+		index := slices.IndexFunc(product.Variants, func(variant model.ProductVariant) bool {
+			return variant.ID == variantID
+		})
+		if index < 0 {
+			return model.ErrVariantNotFound
+		}
+		product.Variants = slices.Delete(product.Variants, index, index+1)
+		return nil
+	})
+}
+
+// checkVariantUnsafe checks that the attributes of the variant are unique
+// within the product and that its SKU is not used elsewhere (without locking)
+func (r *MemoryProductRepository) checkVariantUnsafe(product *model.Product, variant model.ProductVariant) error {
+	if err := product.CheckVariant(variant); err != nil {
+		return err
+	}
+	if r.skuTakenUnsafe(variant.SKU, product.ID, variant.ID) {
+		return model.ErrSKUExists
+	}
+	return nil
+}
+
+// checkCodesUnsafe checks that no other product or variant uses the SKU or
+// GTIN of the product written as id (without locking). Soft-deleted
+// products keep their codes reserved since they may be restored.
+func (r *MemoryProductRepository) checkCodesUnsafe(product *model.Product, id string) error {
+	if product.SKU != "" && r.skuTakenUnsafe(product.SKU, id, "") {
+		return model.ErrSKUExists
+	}
+	if product.GTIN != "" && r.gtinTakenUnsafe(product.GTIN, id) {
+		return model.ErrGTINExists
+	}
+	return nil
+}
+
+// SetRating replaces the aggregated rating of a product
+func (r *MemoryProductRepository) SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error) {
+	return r.updateRecord(id, func(product *model.Product) error {
+		product.Rating = rating
+		return nil
+	})
+}
+
+// updateProduct applies a variant change to a copy of the product under the
+// repository lock, since variant SKUs are checked across products, storing
+// it only if the change succeeds
+func (r *MemoryProductRepository) updateProduct(id string, apply func(product *model.Product) error) (*model.Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.products.Get(id)
+	if !exists || existing.IsDeleted() {
+		return nil, model.ErrProductNotFound
+	}
+
+	product := existing.Clone()
+	if err := apply(product); err != nil {
+		return nil, err
+	}
+
+	product.UpdatedAt = r.clock.After(existing.UpdatedAt)
+	r.storeUnsafe(product)
+	return product.Clone(), nil
+}
+
+// updateRecord applies a stock, image or rating change to a copy of the product
+// under the lock of its shard only, storing it only if the change succeeds.
+// apply may not look at other products.
+func (r *MemoryProductRepository) updateRecord(id string, apply func(product *model.Product) error) (*model.Product, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	return r.existsByIDUnsafe(id)
+	product, err := r.products.Update(id, func(existing *model.Product, exists bool) (*model.Product, error) {
+		if !exists || existing.IsDeleted() {
+			return nil, model.ErrProductNotFound
+		}
+
+		product := existing.Clone()
+		if err := apply(product); err != nil {
+			return nil, err
+		}
+
+		product.UpdatedAt = r.clock.After(existing.UpdatedAt)
+		return product, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product.Clone(), nil
+}
+
+// Stats summarizes the products that are not deleted. The summary is
+// computed on the first call after a write and reused until the next one, so
+// polling dashboards do not rescan the catalog.
+func (r *MemoryProductRepository) Stats(ctx context.Context) (*model.ProductStats, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Writes that change the summary wait for the read lock, so it cannot
+	// miss one
+	stats := r.stats.Load()
+	if stats == nil {
+		stats = model.NewProductStats(slices.Collect(r.products.All()))
+		r.stats.Store(stats)
+	}
+	return stats.Clone(), nil
 }
 
-// existsByIDUnsafe checks if a product exists by ID (without locking)
+// Transaction runs fn against a private copy of the repository and commits
+// its writes only if fn returns nil before ctx is done. Other callers are
+// blocked until the transaction finishes.
+func (r *MemoryProductRepository) Transaction(ctx context.Context, fn func(tx ProductRepository) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := &MemoryProductRepository{
+		products: r.products.Clone(),
+		index:    search.NewIndex(),
+		clock:    r.clock,
+		ids:      r.ids,
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// Do not commit writes the caller has given up on
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.products.Commit(tx.products)
+	r.rebuildIndex()
+	r.stats.Store(nil)
+	return nil
+}
+
+// Load stores products as they are, replacing products with the same ID, so
+// the repository can be recovered from a copy kept elsewhere. Unlike Create
+// it neither stamps nor checks them.
+func (r *MemoryProductRepository) Load(ctx context.Context, products []*model.Product) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		product = product.Clone()
+		r.products.Load(product)
+		r.indexProduct(product)
+	}
+	r.stats.Store(nil)
+	return nil
+}
+
+// PurgeExpired reverts products written before cutoff to their seed state,
+// removing products that were not part of the seed data
+func (r *MemoryProductRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := r.products.PurgeExpired(cutoff, r.revert)
+	if purged > 0 {
+		r.rebuildIndex()
+	}
+	r.stats.Store(nil)
+	return purged
+}
+
+// Reset restores the repository to its seed data
+func (r *MemoryProductRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.products.Reset(r.revert)
+	r.rebuildIndex()
+	r.stats.Store(nil)
+}
+
+// storeUnsafe stores a written product and drops the stats. The caller holds
+// the repository lock.
+func (r *MemoryProductRepository) storeUnsafe(product *model.Product) {
+	r.products.Put(product)
+	r.stats.Store(nil)
+}
+
+// indexProduct updates the search index entry of a product (without locking).
+// Soft-deleted products are removed from the index.
+func (r *MemoryProductRepository) indexProduct(product *model.Product) {
+	if product.IsDeleted() {
+		r.index.Remove(product.ID)
+		return
+	}
+
+	r.index.Add(product.ID,
+		search.Field{Text: product.Name, Weight: nameWeight},
+		search.Field{Text: product.Category, Weight: categoryWeight},
+		search.Field{Text: product.Description, Weight: descriptionWeight},
+	)
+}
+
+// rebuildIndex indexes the text of every product from scratch (without
+// locking)
+func (r *MemoryProductRepository) rebuildIndex() {
+	r.index = search.NewIndex()
+	for product := range r.products.All() {
+		r.indexProduct(product)
+	}
+}
+
+// existsByIDUnsafe checks if a product that has not been deleted exists by
+// ID. The caller holds the repository lock.
 func (r *MemoryProductRepository) existsByIDUnsafe(id string) bool {
-	_, exists := r.products[id]
-	return exists
+	product, exists := r.products.Get(id)
+	return exists && !product.IsDeleted()
+}
+
+// revert returns a copy of a seeded product to store in its place. The copy
+// counts as changed after the product it replaces, if any, so clients
+// holding the replaced version refetch it.
+func (r *MemoryProductRepository) revert(seeded, current *model.Product) *model.Product {
+	product := seeded.Clone()
+	product.UpdatedAt = r.clock.Now()
+	if current != nil {
+		product.UpdatedAt = r.clock.After(current.UpdatedAt)
+	}
+	return product
+}
+
+// sortProducts orders products listed by ascending ID by the requested sort
+// option, falling back to ID
+func sortProducts(products []*model.Product, option string) {
+	switch option {
+	case "", model.SortByID:
+		return
+	case model.SortByIDDesc:
+		slices.Reverse(products)
+		return
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		switch option {
+		case model.SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case model.SortByNameDesc:
+			if a.Name != b.Name {
+				return a.Name > b.Name
+			}
+		case model.SortByPriceAsc:
+			if cmp := a.Price.Cmp(b.Price); cmp != 0 {
+				return cmp < 0
+			}
+		case model.SortByPriceDesc:
+			if cmp := a.Price.Cmp(b.Price); cmp != 0 {
+				return cmp > 0
+			}
+		}
+		return a.ID < b.ID
+	})
 }
 
 // initSampleData initializes the repository with sample data
 func (r *MemoryProductRepository) initSampleData() {
-	sampleProducts := []*model.Product{
+	seededAt := r.clock.Now()
+	for _, product := range sampleProducts() {
+		product.CreatedAt = seededAt
+		product.UpdatedAt = seededAt
+		r.products.Seed(product)
+		r.indexProduct(product)
+	}
+}
+
+// sampleProducts returns the sample products repositories are seeded with
+func sampleProducts() []*model.Product {
+	return []*model.Product{
 		{
-			ID:          "product-789",
-			Name:        "Laptop",
-			Description: "High-performance laptop for professional use",
-			Price:       big.NewRat(99900, 100), // 999.00
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-789",
+			Name:          "Laptop",
+			Description:   "High-performance laptop for professional use",
+			Price:         money.New(99900, "USD"), // 999.00
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 25,
 		},
 		{
-			ID:          "product-001",
-			Name:        "Wireless Mouse",
-			Description: "Ergonomic wireless mouse with precision tracking",
-			Price:       big.NewRat(2999, 100), // 29.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-001",
+			Name:          "Wireless Mouse",
+			Description:   "Ergonomic wireless mouse with precision tracking",
+			Price:         money.New(2999, "USD"), // 29.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 150,
 		},
 		{
-			ID:          "product-002",
-			Name:        "Mechanical Keyboard",
-			Description: "RGB mechanical keyboard with Cherry MX switches",
-			Price:       big.NewRat(12999, 100), // 129.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-002",
+			Name:          "Mechanical Keyboard",
+			Description:   "RGB mechanical keyboard with Cherry MX switches",
+			Price:         money.New(12999, "USD"), // 129.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 60,
 		},
 		{
-			ID:          "product-003",
-			Name:        "4K Monitor",
-			Description: "27-inch 4K UHD monitor with HDR support",
-			Price:       big.NewRat(39999, 100), // 399.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-003",
+			Name:          "4K Monitor",
+			Description:   "27-inch 4K UHD monitor with HDR support",
+			Price:         money.New(39999, "USD"), // 399.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 40,
 		},
 		{
-			ID:          "product-004",
-			Name:        "USB-C Hub",
-			Description: "Multi-port USB-C hub with HDMI and Ethernet",
-			Price:       big.NewRat(7999, 100), // 79.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-004",
+			Name:          "USB-C Hub",
+			Description:   "Multi-port USB-C hub with HDMI and Ethernet",
+			Price:         money.New(7999, "USD"), // 79.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 120,
 		},
 		{
-			ID:          "product-005",
-			Name:        "Bluetooth Headphones",
-			Description: "Noise-cancelling wireless headphones",
-			Price:       big.NewRat(19999, 100), // 199.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-005",
+			Name:          "Bluetooth Headphones",
+			Description:   "Noise-cancelling wireless headphones",
+			Price:         money.New(19999, "USD"), // 199.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 75,
 		},
 		{
-			ID:          "product-006",
-			Name:        "Smartphone",
-			Description: "Latest smartphone with advanced camera",
-			Price:       big.NewRat(79999, 100), // 799.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-006",
+			Name:          "Smartphone",
+			Description:   "Latest smartphone with advanced camera",
+			Price:         money.New(79999, "USD"), // 799.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 30,
 		},
 		{
-			ID:          "product-007",
-			Name:        "Tablet",
-			Description: "10-inch tablet with stylus support",
-			Price:       big.NewRat(49999, 100), // 499.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-007",
+			Name:          "Tablet",
+			Description:   "10-inch tablet with stylus support",
+			Price:         money.New(49999, "USD"), // 499.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 45,
 		},
 		{
-			ID:          "product-008",
-			Name:        "Smartwatch",
-			Description: "Fitness tracking smartwatch with GPS",
-			Price:       big.NewRat(29999, 100), // 299.99
-			Category:    "Electronics",
-			Active:      true,
+			ID:            "product-008",
+			Name:          "Smartwatch",
+			Description:   "Fitness tracking smartwatch with GPS",
+			Price:         money.New(29999, "USD"), // 299.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        true,
+			StockQuantity: 80,
 		},
 		{
-			ID:          "product-inactive",
-			Name:        "Discontinued Product",
-			Description: "This product is no longer available",
-			Price:       big.NewRat(9999, 100), // 99.99
-			Category:    "Electronics",
-			Active:      false,
+			ID:            "product-inactive",
+			Name:          "Discontinued Product",
+			Description:   "This product is no longer available",
+			Price:         money.New(9999, "USD"), // 99.99
+			CategoryID:    "category-electronics",
+			Category:      "Electronics",
+			Active:        false,
+			StockQuantity: 0,
 		},
 	}
-
-	for _, product := range sampleProducts {
-		r.products[product.ID] = product
-	}
 }
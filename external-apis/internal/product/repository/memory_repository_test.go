@@ -1,10 +1,19 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"external-apis/internal/product/model"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/timerange"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,7 +24,7 @@ func TestMemoryProductRepository_GetByID(t *testing.T) {
 
 	t.Run("Get existing product", func(t *testing.T) {
 		// Act
-		product, err := repo.GetByID("product-789")
+		product, err := repo.GetByID(context.Background(), "product-789")
 
 		// Assert
 		require.NoError(t, err)
@@ -26,7 +35,7 @@ func TestMemoryProductRepository_GetByID(t *testing.T) {
 
 	t.Run("Get non-existing product", func(t *testing.T) {
 		// Act
-		product, err := repo.GetByID("non-existing")
+		product, err := repo.GetByID(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -35,12 +44,27 @@ func TestMemoryProductRepository_GetByID(t *testing.T) {
 	})
 }
 
+func TestMemoryProductRepository_GetByIDs(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.Delete(ctx, "product-001"))
+
+	// Act
+	products, err := repo.GetByIDs(ctx, []string{"product-789", "non-existing", "product-001"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, "product-789", products[0].ID)
+}
+
 func TestMemoryProductRepository_GetAll(t *testing.T) {
 	// Arrange
 	repo := NewMemoryProductRepository()
 
 	// Act
-	products, err := repo.GetAll()
+	products, err := repo.GetAll(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -57,28 +81,89 @@ func TestMemoryProductRepository_GetAll(t *testing.T) {
 	assert.True(t, foundLaptop, "Should contain the sample laptop product")
 }
 
+func TestMemoryProductRepository_Ordering(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := NewMemoryProductRepository()
+
+	// ids returns the IDs of products in the order they are listed
+	ids := func(products []*model.Product) []string {
+		result := make([]string, 0, len(products))
+		for _, product := range products {
+			result = append(result, product.ID)
+		}
+		return result
+	}
+
+	t.Run("List products by ID", func(t *testing.T) {
+		// Arrange
+		_, err := repo.Create(ctx, &model.Product{ID: "product-zzz", Name: "Last", Price: money.New(100, "USD")})
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, &model.Product{ID: "product-000", Name: "First", Price: money.New(100, "USD")})
+		require.NoError(t, err)
+
+		// Act
+		products, err := repo.GetAll(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, slices.IsSorted(ids(products)))
+		assert.Equal(t, "product-000", products[0].ID)
+		assert.Equal(t, "product-zzz", products[len(products)-1].ID)
+	})
+
+	t.Run("Break ties of other sort orders by ID", func(t *testing.T) {
+		// Act
+		products, _, err := repo.Find(ctx, model.ProductFilter{Sort: model.SortByPriceAsc, Page: pagination.Params{Limit: 2}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"product-000", "product-zzz"}, ids(products))
+	})
+
+	t.Run("Keep the order through transactions and purges", func(t *testing.T) {
+		// Arrange
+		err := repo.Transaction(ctx, func(tx ProductRepository) error {
+			_, err := tx.Create(ctx, &model.Product{ID: "product-mmm", Name: "Middle", Price: money.New(100, "USD")})
+			return err
+		})
+		require.NoError(t, err)
+		repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Act
+		products, err := repo.GetAll(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotContains(t, ids(products), "product-mmm")
+		assert.True(t, slices.IsSorted(ids(products)))
+	})
+}
+
 func TestMemoryProductRepository_Create(t *testing.T) {
 	// Arrange
 	repo := NewMemoryProductRepository()
 	newProduct := &model.Product{
 		Name:        "New Product",
 		Description: "A new test product",
-		Price:       big.NewRat(4999, 100), // 49.99
+		Price:       money.New(4999, "USD"), // 49.99
 		Category:    "Test",
 		Active:      true,
 	}
 
 	t.Run("Create new product", func(t *testing.T) {
 		// Act
-		created, err := repo.Create(newProduct)
+		created, err := repo.Create(context.Background(), newProduct)
 
 		// Assert
 		require.NoError(t, err)
 		assert.NotEmpty(t, created.ID)
 		assert.Equal(t, "New Product", created.Name)
+		assert.False(t, created.CreatedAt.IsZero())
+		assert.Equal(t, created.UpdatedAt, created.CreatedAt)
 
 		// Verify it was actually stored
-		retrieved, err := repo.GetByID(created.ID)
+		retrieved, err := repo.GetByID(context.Background(), created.ID)
 		require.NoError(t, err)
 		assert.Equal(t, created.ID, retrieved.ID)
 	})
@@ -89,13 +174,13 @@ func TestMemoryProductRepository_Create(t *testing.T) {
 			ID:          "product-789", // This ID already exists
 			Name:        "Duplicate",
 			Description: "Duplicate product",
-			Price:       big.NewRat(1000, 100),
+			Price:       money.New(1000, "USD"),
 			Category:    "Test",
 			Active:      true,
 		}
 
 		// Act
-		created, err := repo.Create(existingProduct)
+		created, err := repo.Create(context.Background(), existingProduct)
 
 		// Assert
 		assert.Error(t, err)
@@ -110,25 +195,29 @@ func TestMemoryProductRepository_Update(t *testing.T) {
 
 	t.Run("Update existing product", func(t *testing.T) {
 		// Arrange
+		before, err := repo.GetByID(context.Background(), "product-789")
+		require.NoError(t, err)
 		updatedProduct := &model.Product{
 			ID:          "product-789",
 			Name:        "Updated Laptop",
 			Description: "Updated description",
-			Price:       big.NewRat(119900, 100), // 1199.00
+			Price:       money.New(119900, "USD"), // 1199.00
 			Category:    "Electronics",
 			Active:      true,
 		}
 
 		// Act
-		result, err := repo.Update("product-789", updatedProduct)
+		result, err := repo.Update(context.Background(), "product-789", updatedProduct)
 
 		// Assert
 		require.NoError(t, err)
 		assert.Equal(t, "Updated Laptop", result.Name)
 		assert.Equal(t, "Updated description", result.Description)
+		assert.True(t, result.UpdatedAt.After(before.UpdatedAt), "the change is timestamped")
+		assert.Equal(t, before.CreatedAt, result.CreatedAt, "the creation time is kept")
 
 		// Verify the update was persisted
-		retrieved, err := repo.GetByID("product-789")
+		retrieved, err := repo.GetByID(context.Background(), "product-789")
 		require.NoError(t, err)
 		assert.Equal(t, "Updated Laptop", retrieved.Name)
 	})
@@ -138,13 +227,13 @@ func TestMemoryProductRepository_Update(t *testing.T) {
 		product := &model.Product{
 			Name:        "Non-existing",
 			Description: "Does not exist",
-			Price:       big.NewRat(1000, 100),
+			Price:       money.New(1000, "USD"),
 			Category:    "Test",
 			Active:      true,
 		}
 
 		// Act
-		result, err := repo.Update("non-existing", product)
+		result, err := repo.Update(context.Background(), "non-existing", product)
 
 		// Assert
 		assert.Error(t, err)
@@ -153,26 +242,61 @@ func TestMemoryProductRepository_Update(t *testing.T) {
 	})
 }
 
+func TestMemoryProductRepository_SetRating(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	rating := model.ProductRating{Count: 2, Total: 9}
+
+	// Act
+	result, err := repo.SetRating(context.Background(), "product-789", rating)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, rating, result.Rating)
+
+	t.Run("Updates keep the rating", func(t *testing.T) {
+		// Act
+		updated, err := repo.Update(context.Background(), "product-789", &model.Product{
+			Name:     "Updated Laptop",
+			Price:    money.New(119900, "USD"),
+			Category: "Electronics",
+			Active:   true,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, rating, updated.Rating)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		// Act
+		_, err := repo.SetRating(context.Background(), "non-existing", rating)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
 func TestMemoryProductRepository_Delete(t *testing.T) {
 	// Arrange
 	repo := NewMemoryProductRepository()
 
 	t.Run("Delete existing product", func(t *testing.T) {
 		// Act
-		err := repo.Delete("product-001")
+		err := repo.Delete(context.Background(), "product-001")
 
 		// Assert
 		require.NoError(t, err)
 
 		// Verify it was deleted
-		product, err := repo.GetByID("product-001")
+		product, err := repo.GetByID(context.Background(), "product-001")
 		assert.Error(t, err)
 		assert.Nil(t, product)
 	})
 
 	t.Run("Delete non-existing product", func(t *testing.T) {
 		// Act
-		err := repo.Delete("non-existing")
+		err := repo.Delete(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -186,7 +310,7 @@ func TestMemoryProductRepository_ExistsByID(t *testing.T) {
 
 	t.Run("Existing product", func(t *testing.T) {
 		// Act
-		exists := repo.ExistsByID("product-789")
+		exists := repo.ExistsByID(context.Background(), "product-789")
 
 		// Assert
 		assert.True(t, exists)
@@ -194,7 +318,7 @@ func TestMemoryProductRepository_ExistsByID(t *testing.T) {
 
 	t.Run("Non-existing product", func(t *testing.T) {
 		// Act
-		exists := repo.ExistsByID("non-existing")
+		exists := repo.ExistsByID(context.Background(), "non-existing")
 
 		// Assert
 		assert.False(t, exists)
@@ -209,7 +333,7 @@ func TestMemoryProductRepository_ConcurrentAccess(t *testing.T) {
 		// Act & Assert - should not panic
 		for i := 0; i < 10; i++ {
 			go func() {
-				_, _ = repo.GetByID("product-789")
+				_, _ = repo.GetByID(context.Background(), "product-789")
 			}()
 		}
 	})
@@ -221,12 +345,1195 @@ func TestMemoryProductRepository_ConcurrentAccess(t *testing.T) {
 				product := &model.Product{
 					Name:        "Concurrent Product",
 					Description: "Test concurrent access",
-					Price:       big.NewRat(1000, 100),
+					Price:       money.New(1000, "USD"),
 					Category:    "Test",
 					Active:      true,
 				}
-				_, _ = repo.Create(product)
+				_, _ = repo.Create(context.Background(), product)
 			}(i)
 		}
 	})
 }
+
+func TestMemoryProductRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+
+	created, err := repo.Create(context.Background(), &model.Product{
+		Name:        "Sandbox Product",
+		Description: "Created in sandbox",
+		Price:       money.New(1000, "USD"),
+		Category:    "Test",
+		Active:      true,
+	})
+	require.NoError(t, err)
+
+	seeded, err := repo.GetByID(context.Background(), "product-789")
+	require.NoError(t, err)
+	seeded.Price = money.New(100, "USD")
+	_, err = repo.Update(context.Background(), "product-789", seeded)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(context.Background(), "product-001"))
+
+	t.Run("Writes newer than cutoff are kept", func(t *testing.T) {
+		// Act
+		purged := repo.PurgeExpired(time.Now().Add(-time.Hour))
+
+		// Assert
+		assert.Equal(t, 0, purged)
+		assert.True(t, repo.ExistsByID(context.Background(), created.ID))
+	})
+
+	t.Run("Expired writes are reverted to seed data", func(t *testing.T) {
+		// Act
+		purged := repo.PurgeExpired(time.Now().Add(time.Second))
+
+		// Assert
+		assert.Equal(t, 3, purged)
+		assert.False(t, repo.ExistsByID(context.Background(), created.ID))
+		assert.True(t, repo.ExistsByID(context.Background(), "product-001"))
+
+		restored, err := repo.GetByID(context.Background(), "product-789")
+		require.NoError(t, err)
+		assert.Equal(t, money.New(99900, "USD"), restored.Price)
+	})
+}
+
+func TestMemoryProductRepository_Reset(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	_, err := repo.Create(context.Background(), &model.Product{Name: "Extra", Price: money.New(100, "USD")})
+	require.NoError(t, err)
+
+	// Act
+	repo.Reset()
+
+	// Assert
+	products, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, products, 10)
+}
+
+func TestMemoryProductRepository_Find(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+
+	t.Run("First page is ordered by ID", func(t *testing.T) {
+		// Act
+		products, total, err := repo.Find(context.Background(), model.ProductFilter{Page: pagination.Params{Limit: 3}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 10, total)
+		require.Len(t, products, 3)
+		assert.Less(t, products[0].ID, products[1].ID)
+		assert.Less(t, products[1].ID, products[2].ID)
+	})
+
+	t.Run("Pages do not overlap", func(t *testing.T) {
+		// Act
+		first, _, err := repo.Find(context.Background(), model.ProductFilter{Page: pagination.Params{Limit: 3}})
+		require.NoError(t, err)
+		second, _, err := repo.Find(context.Background(), model.ProductFilter{Page: pagination.Params{Limit: 3, Offset: 3}})
+		require.NoError(t, err)
+
+		// Assert
+		require.Len(t, second, 3)
+		assert.Less(t, first[2].ID, second[0].ID)
+	})
+
+	t.Run("Offset past the end", func(t *testing.T) {
+		// Act
+		products, total, err := repo.Find(context.Background(), model.ProductFilter{Page: pagination.Params{Limit: 3, Offset: 100}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 10, total)
+		assert.Empty(t, products)
+	})
+}
+
+func TestMemoryProductRepository_FindWithFilters(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+
+	t.Run("Filter by price range", func(t *testing.T) {
+		// Act
+		products, total, err := repo.Find(context.Background(), model.ProductFilter{
+			MinPrice: big.NewRat(100, 1),
+			MaxPrice: big.NewRat(500, 1),
+			Page:     pagination.DefaultParams(),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 5, total)
+		for _, product := range products {
+			assert.True(t, product.Price.Rat().Cmp(big.NewRat(100, 1)) >= 0)
+			assert.True(t, product.Price.Rat().Cmp(big.NewRat(500, 1)) <= 0)
+		}
+	})
+
+	t.Run("Filter by category is case-insensitive", func(t *testing.T) {
+		// Act
+		_, total, err := repo.Find(context.Background(), model.ProductFilter{Category: "electronics", Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 10, total)
+	})
+
+	t.Run("Filter by update time", func(t *testing.T) {
+		// Arrange
+		seeded, err := repo.GetByID(context.Background(), "product-789")
+		require.NoError(t, err)
+		changed, err := repo.Update(context.Background(), "product-789", seeded)
+		require.NoError(t, err)
+
+		// Act
+		products, total, err := repo.Find(context.Background(), model.ProductFilter{
+			Updated: timerange.Range{Since: changed.UpdatedAt},
+			Page:    pagination.DefaultParams(),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "product-789", products[0].ID)
+	})
+
+	t.Run("Sort by price descending", func(t *testing.T) {
+		// Act
+		products, _, err := repo.Find(context.Background(), model.ProductFilter{Sort: model.SortByPriceDesc, Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "product-789", products[0].ID)
+		for i := 1; i < len(products); i++ {
+			assert.True(t, products[i-1].Price.Cmp(products[i].Price) >= 0)
+		}
+	})
+}
+
+func TestMemoryProductRepository_Stock(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	_, err := repo.AdjustStock(context.Background(), "product-789", -15) // 25 -> 10 on hand
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		apply            func() (*model.Product, error)
+		expectedErr      string
+		expectedStock    int
+		expectedReserved int
+	}{
+		{
+			name:             "Reserve available stock",
+			apply:            func() (*model.Product, error) { return repo.ReserveStock(context.Background(), "product-789", 4) },
+			expectedStock:    10,
+			expectedReserved: 4,
+		},
+		{
+			name:             "Reserve more than available",
+			apply:            func() (*model.Product, error) { return repo.ReserveStock(context.Background(), "product-789", 7) },
+			expectedErr:      "insufficient stock",
+			expectedStock:    10,
+			expectedReserved: 4,
+		},
+		{
+			name:             "Release reserved stock",
+			apply:            func() (*model.Product, error) { return repo.ReleaseStock(context.Background(), "product-789", 1) },
+			expectedStock:    10,
+			expectedReserved: 3,
+		},
+		{
+			name:             "Release more than reserved",
+			apply:            func() (*model.Product, error) { return repo.ReleaseStock(context.Background(), "product-789", 4) },
+			expectedErr:      "release exceeds reserved stock",
+			expectedStock:    10,
+			expectedReserved: 3,
+		},
+		{
+			name:             "Adjust below reserved",
+			apply:            func() (*model.Product, error) { return repo.AdjustStock(context.Background(), "product-789", -8) },
+			expectedErr:      "stock cannot drop below reserved quantity",
+			expectedStock:    10,
+			expectedReserved: 3,
+		},
+		{
+			name:             "Adjust stock up",
+			apply:            func() (*model.Product, error) { return repo.AdjustStock(context.Background(), "product-789", 5) },
+			expectedStock:    15,
+			expectedReserved: 3,
+		},
+		{
+			name:        "Reserve inactive product",
+			apply:       func() (*model.Product, error) { return repo.ReserveStock(context.Background(), "product-inactive", 1) },
+			expectedErr: "product is not active",
+		},
+		{
+			name:        "Reserve unknown product",
+			apply:       func() (*model.Product, error) { return repo.ReserveStock(context.Background(), "non-existing", 1) },
+			expectedErr: "product not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			product, err := tt.apply()
+
+			// Assert
+			if tt.expectedErr != "" {
+				assert.Nil(t, product)
+				assert.EqualError(t, err, tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.expectedStock > 0 {
+				stored, err := repo.GetByID(context.Background(), "product-789")
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedStock, stored.StockQuantity)
+				assert.Equal(t, tt.expectedReserved, stored.ReservedQuantity)
+			}
+		})
+	}
+
+	t.Run("Update keeps stock levels", func(t *testing.T) {
+		// Arrange
+		product, err := repo.GetByID(context.Background(), "product-789")
+		require.NoError(t, err)
+		stale := *product
+		stale.ReservedQuantity = 0
+
+		// Act
+		updated, err := repo.Update(context.Background(), "product-789", &stale)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, updated.ReservedQuantity)
+	})
+}
+
+func TestMemoryProductRepository_ConcurrentReservations(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	product, err := repo.GetByID(context.Background(), "product-006")
+	require.NoError(t, err)
+	available := product.AvailableQuantity()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	succeeded := 0
+
+	// Act
+	for i := 0; i < available+10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.ReserveStock(context.Background(), "product-006", 1); err == nil {
+				mutex.Lock()
+				succeeded++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, available, succeeded)
+	product, err = repo.GetByID(context.Background(), "product-006")
+	require.NoError(t, err)
+	assert.Equal(t, 0, product.AvailableQuantity())
+}
+
+func TestMemoryProductRepository_SoftDeleteAndRestore(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	require.NoError(t, repo.Delete(context.Background(), "product-001"))
+
+	t.Run("Deleted product is hidden", func(t *testing.T) {
+		// Act
+		products, err := repo.GetAll(context.Background())
+		_, totalWithDeleted, _ := repo.Find(context.Background(), model.ProductFilter{IncludeDeleted: true, Page: pagination.DefaultParams()})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, totalWithDeleted-1, len(products))
+		assert.False(t, repo.ExistsByID(context.Background(), "product-001"))
+		_, err = repo.ReserveStock(context.Background(), "product-001", 1)
+		assert.EqualError(t, err, "product not found")
+	})
+
+	t.Run("Deleted product keeps its ID reserved", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Product{ID: "product-001", Price: money.New(100, "USD")})
+
+		// Assert
+		assert.EqualError(t, err, "product already exists")
+	})
+
+	t.Run("Restore deleted product", func(t *testing.T) {
+		// Act
+		restored, err := repo.Restore(context.Background(), "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.True(t, repo.ExistsByID(context.Background(), "product-001"))
+	})
+
+	t.Run("Restore product that is not deleted", func(t *testing.T) {
+		// Act
+		_, err := repo.Restore(context.Background(), "product-001")
+
+		// Assert
+		assert.EqualError(t, err, "product is not deleted")
+	})
+}
+
+func TestMemoryProductRepository_RenameCategory(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	require.NoError(t, repo.Delete(context.Background(), "product-001"))
+
+	// Act
+	renamed, err := repo.RenameCategory(context.Background(), "category-electronics", "Gadgets")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, renamed, 9)
+
+	_, total, err := repo.Find(context.Background(), model.ProductFilter{Category: "Gadgets", IncludeDeleted: true, Page: pagination.DefaultParams()})
+	require.NoError(t, err)
+	assert.Equal(t, 10, total)
+
+	hits, _, err := repo.Search(context.Background(), model.ProductSearch{Query: "gadgets", Filter: model.ProductFilter{Page: pagination.DefaultParams()}})
+	require.NoError(t, err)
+	assert.Len(t, hits, 9)
+}
+
+func TestMemoryProductRepository_Images(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	image := model.ProductImage{ID: "image-1", Key: "products/product-789/image-1.png", ContentType: "image/png"}
+
+	t.Run("Add image", func(t *testing.T) {
+		// Act
+		product, err := repo.AddImage(context.Background(), "product-789", image)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.ProductImage{image}, product.Images)
+	})
+
+	t.Run("Update keeps images", func(t *testing.T) {
+		// Arrange
+		product, err := repo.GetByID(context.Background(), "product-789")
+		require.NoError(t, err)
+		stale := *product
+		stale.Images = nil
+
+		// Act
+		updated, err := repo.Update(context.Background(), "product-789", &stale)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, updated.Images, 1)
+	})
+
+	t.Run("Remove image", func(t *testing.T) {
+		// Act
+		product, err := repo.RemoveImage(context.Background(), "product-789", "image-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, product.Images)
+	})
+
+	t.Run("Remove unknown image", func(t *testing.T) {
+		// Act
+		_, err := repo.RemoveImage(context.Background(), "product-789", "image-1")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrImageNotFound)
+	})
+
+	t.Run("Add image to missing product", func(t *testing.T) {
+		// Act
+		_, err := repo.AddImage(context.Background(), "non-existing", image)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
+func TestMemoryProductRepository_Variants(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	variant := model.ProductVariant{ID: "variant-1", SKU: "TSHIRT-M-RED", Attributes: map[string]string{"size": "m", "color": "red"}}
+
+	t.Run("Add variant", func(t *testing.T) {
+		// Act
+		product, err := repo.AddVariant(context.Background(), "product-789", variant)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.ProductVariant{variant}, product.Variants)
+	})
+
+	t.Run("Reject duplicate attributes", func(t *testing.T) {
+		// Act
+		_, err := repo.AddVariant(context.Background(), "product-789", model.ProductVariant{ID: "variant-2", SKU: "TSHIRT-M-RED-2", Attributes: map[string]string{"color": "red", "size": "m"}})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrVariantExists)
+	})
+
+	t.Run("Reject SKU of another product", func(t *testing.T) {
+		// Act
+		_, err := repo.AddVariant(context.Background(), "product-001", model.ProductVariant{ID: "variant-3", SKU: "tshirt-m-red", Attributes: map[string]string{"size": "m"}})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSKUExists)
+	})
+
+	t.Run("Update keeps variants", func(t *testing.T) {
+		// Arrange
+		product, err := repo.GetByID(context.Background(), "product-789")
+		require.NoError(t, err)
+		stale := *product
+		stale.Variants = nil
+
+		// Act
+		updated, err := repo.Update(context.Background(), "product-789", &stale)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, updated.Variants, 1)
+	})
+
+	t.Run("Update variant", func(t *testing.T) {
+		// Arrange
+		changed := variant
+		changed.StockQuantity = 7
+
+		// Act
+		product, err := repo.UpdateVariant(context.Background(), "product-789", changed)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.ProductVariant{changed}, product.Variants)
+	})
+
+	t.Run("Remove variant", func(t *testing.T) {
+		// Act
+		product, err := repo.RemoveVariant(context.Background(), "product-789", "variant-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, product.Variants)
+	})
+
+	t.Run("Update unknown variant", func(t *testing.T) {
+		// Act
+		_, err := repo.UpdateVariant(context.Background(), "product-789", variant)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrVariantNotFound)
+	})
+}
+
+func TestMemoryProductRepository_SKU(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	_, err := repo.AddVariant(context.Background(), "product-789", model.ProductVariant{ID: "variant-1", SKU: "LAP-15", Attributes: map[string]string{"screen": "15"}})
+	require.NoError(t, err)
+	created, err := repo.Create(context.Background(), &model.Product{SKU: "DOCK-1", Name: "Dock", Price: money.New(8950, "USD")})
+	require.NoError(t, err)
+
+	t.Run("Get by SKU ignoring case", func(t *testing.T) {
+		// Act
+		product, err := repo.GetBySKU(context.Background(), "dock-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, product.ID)
+	})
+
+	t.Run("Variant SKUs do not match", func(t *testing.T) {
+		// Act
+		_, err := repo.GetBySKU(context.Background(), "LAP-15")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+
+	t.Run("Reject SKU of a variant", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Product{SKU: "lap-15", Name: "Laptop", Price: money.New(100, "USD")})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSKUExists)
+	})
+
+	t.Run("Reject SKU of another product on update", func(t *testing.T) {
+		// Arrange
+		product, err := repo.GetByID(context.Background(), "product-001")
+		require.NoError(t, err)
+		changed := *product
+		changed.SKU = "DOCK-1"
+
+		// Act
+		_, err = repo.Update(context.Background(), "product-001", &changed)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSKUExists)
+	})
+
+	t.Run("Keep own SKU on update", func(t *testing.T) {
+		// Arrange
+		changed := *created
+		changed.Name = "USB-C Dock"
+
+		// Act
+		updated, err := repo.Update(context.Background(), created.ID, &changed)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "DOCK-1", updated.SKU)
+	})
+}
+
+func TestMemoryProductRepository_GTIN(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	created, err := repo.Create(context.Background(), &model.Product{SKU: "SODA-12", GTIN: "036000291452", Name: "Soda", Price: money.New(499, "USD")})
+	require.NoError(t, err)
+
+	t.Run("Get by GTIN", func(t *testing.T) {
+		// Act
+		product, err := repo.GetByGTIN(context.Background(), "036000291452")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, product.ID)
+	})
+
+	t.Run("Get UPC-A by its EAN-13 form", func(t *testing.T) {
+		// Act
+		product, err := repo.GetByGTIN(context.Background(), "0036000291452")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, product.ID)
+	})
+
+	t.Run("Unknown GTIN", func(t *testing.T) {
+		// Act
+		_, err := repo.GetByGTIN(context.Background(), "4006381333931")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+
+	t.Run("Reject GTIN of another product", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Product{SKU: "SODA-12B", GTIN: "0036000291452", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrGTINExists)
+	})
+
+	t.Run("Free GTIN and SKU on update", func(t *testing.T) {
+		// Arrange
+		changed := *created
+		changed.SKU = "SODA-24"
+		changed.GTIN = "4006381333931"
+
+		// Act
+		_, err := repo.Update(context.Background(), created.ID, &changed)
+
+		// Assert
+		require.NoError(t, err)
+		_, err = repo.GetByGTIN(context.Background(), "036000291452")
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+		_, err = repo.GetBySKU(context.Background(), "SODA-12")
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+		product, err := repo.GetBySKU(context.Background(), "soda-24")
+		require.NoError(t, err)
+		assert.Equal(t, "4006381333931", product.GTIN)
+	})
+
+	t.Run("Soft-deleted products keep their GTIN", func(t *testing.T) {
+		// Arrange
+		require.NoError(t, repo.Delete(context.Background(), created.ID))
+
+		// Act
+		_, err := repo.Create(context.Background(), &model.Product{SKU: "SODA-6", GTIN: "4006381333931", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrGTINExists)
+	})
+
+	t.Run("Reset frees the codes", func(t *testing.T) {
+		// Arrange
+		repo.Reset()
+
+		// Act
+		_, err := repo.Create(context.Background(), &model.Product{SKU: "SODA-24", GTIN: "4006381333931", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+}
+
+func TestMemoryProductRepository_Transaction(t *testing.T) {
+	t.Run("Commit writes when fn succeeds", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+
+		// Act
+		err := repo.Transaction(context.Background(), func(tx ProductRepository) error {
+			if _, err := tx.Create(context.Background(), &model.Product{ID: "product-tx", Name: "Tx", Price: money.New(100, "USD")}); err != nil {
+				return err
+			}
+			return tx.Delete(context.Background(), "product-001")
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, repo.ExistsByID(context.Background(), "product-tx"))
+		assert.False(t, repo.ExistsByID(context.Background(), "product-001"))
+	})
+
+	t.Run("Discard writes when fn fails", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		failure := errors.New("boom")
+
+		// Act
+		err := repo.Transaction(context.Background(), func(tx ProductRepository) error {
+			existing, _ := tx.GetByID(context.Background(), "product-002")
+			existing.Name = "Changed"
+			if _, err := tx.Update(context.Background(), "product-002", existing); err != nil {
+				return err
+			}
+			if _, err := tx.Create(context.Background(), &model.Product{ID: "product-tx", Price: money.New(100, "USD")}); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+		assert.False(t, repo.ExistsByID(context.Background(), "product-tx"))
+		product, _ := repo.GetByID(context.Background(), "product-002")
+		assert.Equal(t, "Mechanical Keyboard", product.Name)
+	})
+
+	t.Run("Discard writes when ctx is cancelled", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Act
+		err := repo.Transaction(ctx, func(tx ProductRepository) error {
+			cancel()
+			return tx.Delete(ctx, "product-001")
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, repo.ExistsByID(context.Background(), "product-001"))
+	})
+}
+
+func TestMemoryProductRepository_Search(t *testing.T) {
+	t.Run("Rank name matches first", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+
+		// Act
+		hits, total, err := repo.Search(context.Background(), model.ProductSearch{Query: "wireless"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		require.Len(t, hits, 2)
+		assert.Equal(t, "product-001", hits[0].Product.ID)
+		assert.Equal(t, "product-005", hits[1].Product.ID)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("Apply filters and pagination", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		active := true
+
+		// Act
+		hits, total, err := repo.Search(context.Background(), model.ProductSearch{
+			Query: "electronics",
+			Filter: model.ProductFilter{
+				Active: &active,
+				Page:   pagination.Params{Limit: 2, Offset: 1},
+			},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 9, total)
+		assert.Len(t, hits, 2)
+	})
+
+	t.Run("Follow writes to the repository", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		_, err := repo.Create(context.Background(), &model.Product{ID: "product-trackball", Name: "Trackball", Description: "Wireless trackball", Category: "Accessories", Price: money.New(4999, "USD")})
+		require.NoError(t, err)
+
+		// Act
+		require.NoError(t, repo.Delete(context.Background(), "product-001"))
+		hits, _, err := repo.Search(context.Background(), model.ProductSearch{Query: "wireless"})
+
+		// Assert
+		require.NoError(t, err)
+		ids := make([]string, len(hits))
+		for i, hit := range hits {
+			ids[i] = hit.Product.ID
+		}
+		assert.ElementsMatch(t, []string{"product-005", "product-trackball"}, ids)
+
+		_, err = repo.Restore(context.Background(), "product-001")
+		require.NoError(t, err)
+		hits, _, err = repo.Search(context.Background(), model.ProductSearch{Query: "wireless"})
+		require.NoError(t, err)
+		assert.Len(t, hits, 3)
+	})
+
+	t.Run("Reindex after a committed transaction", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+
+		// Act
+		err := repo.Transaction(context.Background(), func(tx ProductRepository) error {
+			_, err := tx.Create(context.Background(), &model.Product{ID: "product-tx", Name: "Gaming Chair", Price: money.New(100, "USD")})
+			return err
+		})
+
+		// Assert
+		require.NoError(t, err)
+		hits, total, err := repo.Search(context.Background(), model.ProductSearch{Query: "chair"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, "product-tx", hits[0].Product.ID)
+	})
+
+	t.Run("No matches", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+
+		// Act
+		hits, total, err := repo.Search(context.Background(), model.ProductSearch{Query: "bicycle"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, hits)
+	})
+}
+
+func TestMemoryProductRepository_CancelledContext(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("GetAll", func(t *testing.T) {
+		// Act
+		products, err := repo.GetAll(ctx)
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, products)
+	})
+
+	t.Run("Find", func(t *testing.T) {
+		// Act
+		_, _, err := repo.Find(ctx, model.ProductFilter{Page: pagination.Params{Limit: 10}})
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		// Act
+		_, _, err := repo.Search(ctx, model.ProductSearch{Query: "laptop", Filter: model.ProductFilter{Page: pagination.Params{Limit: 10}}})
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestMemoryProductRepository_ConcurrentStockChanges(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := NewMemoryProductRepository()
+	products, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	const reservations = 10
+
+	// Act
+	var wg sync.WaitGroup
+	for _, product := range products {
+		for i := 0; i < reservations; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = repo.ReserveStock(ctx, product.ID, 1)
+			}()
+		}
+	}
+	// Writes spanning products and listings run alongside the reservations
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		existing, _ := repo.GetByID(ctx, "product-001")
+		existing.Name = "Renamed Mouse"
+		_, _ = repo.Update(ctx, "product-001", existing)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = repo.RenameCategory(ctx, "category-electronics", "Gadgets")
+	}()
+	go func() {
+		defer wg.Done()
+		_, _, _ = repo.Find(ctx, model.ProductFilter{Page: pagination.DefaultParams()})
+	}()
+	wg.Wait()
+
+	// Assert
+	for _, product := range products {
+		stored, err := repo.GetByID(ctx, product.ID)
+		require.NoError(t, err)
+		if product.Active {
+			assert.Equal(t, reservations, stored.ReservedQuantity, product.ID)
+		}
+		assert.Equal(t, "Gadgets", stored.Category, product.ID)
+	}
+	renamed, err := repo.GetByID(ctx, "product-001")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed Mouse", renamed.Name)
+}
+
+func TestMemoryProductRepository_DefensiveCopies(t *testing.T) {
+	ctx := context.Background()
+	variant := model.ProductVariant{ID: "variant-1", SKU: "LAPTOP-16GB", Attributes: map[string]string{"memory": "16gb"}}
+
+	t.Run("Changes to returned products are not stored", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		_, err := repo.AddVariant(ctx, "product-789", variant)
+		require.NoError(t, err)
+		product, err := repo.GetByID(ctx, "product-789")
+		require.NoError(t, err)
+
+		// Act
+		product.Name = "Changed"
+		product.Variants[0].Attributes["memory"] = "32gb"
+		product.Variants = append(product.Variants, model.ProductVariant{ID: "variant-2"})
+
+		// Assert
+		stored, err := repo.GetByID(ctx, "product-789")
+		require.NoError(t, err)
+		assert.Equal(t, "Laptop", stored.Name)
+		assert.Equal(t, []model.ProductVariant{variant}, stored.Variants)
+	})
+
+	t.Run("Changes to written products are not stored", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		product := &model.Product{ID: "product-copy", Name: "Original", Price: money.New(1000, "USD"), Active: true}
+
+		// Act
+		created, err := repo.Create(ctx, product)
+		require.NoError(t, err)
+		product.Name = "Changed input"
+		created.Name = "Changed result"
+
+		// Assert
+		stored, err := repo.GetByID(ctx, "product-copy")
+		require.NoError(t, err)
+		assert.Equal(t, "Original", stored.Name)
+	})
+
+	t.Run("Callers change their copies while stock changes", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryProductRepository()
+		var wg sync.WaitGroup
+
+		// Act
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				product, err := repo.GetByID(ctx, "product-789")
+				if err == nil {
+					product.Name = fmt.Sprintf("Writer %d", i)
+					product.ReservedQuantity = 0
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				_, _ = repo.ReserveStock(ctx, "product-789", 1)
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		stored, err := repo.GetByID(ctx, "product-789")
+		require.NoError(t, err)
+		assert.Equal(t, "Laptop", stored.Name)
+		assert.Equal(t, 10, stored.ReservedQuantity)
+	})
+}
+
+// serializedProductRepository runs every call to a product repository under
+// one lock, as the repository did before it was sharded, to compare the
+// throughput of the two designs
+type serializedProductRepository struct {
+	ProductRepository
+	mutex sync.RWMutex
+}
+
+func (r *serializedProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ProductRepository.GetByID(ctx, id)
+}
+
+func (r *serializedProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.ProductRepository.Find(ctx, filter)
+}
+
+func (r *serializedProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ProductRepository.ReserveStock(ctx, id, quantity)
+}
+
+func (r *serializedProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.ProductRepository.ReleaseStock(ctx, id, quantity)
+}
+
+// BenchmarkMemoryProductRepository_MixedWorkload runs the calls placing
+// orders, mostly reads by ID and stock reservations and releases with a few
+// listings, from parallel goroutines. Compare the ns/op of the two
+// sub-benchmarks with -cpu 1,4,8 to see the sharded repository scale.
+func BenchmarkMemoryProductRepository_MixedWorkload(b *testing.B) {
+	ctx := context.Background()
+	const catalog = 256
+
+	newRepository := func(b *testing.B) (*MemoryProductRepository, []string) {
+		repo := newEmptyMemoryProductRepository()
+		ids := make([]string, 0, catalog)
+		for i := 0; i < catalog; i++ {
+			product, err := repo.Create(ctx, &model.Product{
+				Name:          "Benchmark Product",
+				Price:         money.New(1000, "USD"),
+				Active:        true,
+				StockQuantity: 1 << 30,
+			})
+			require.NoError(b, err)
+			ids = append(ids, product.ID)
+		}
+		return repo, ids
+	}
+
+	for _, bench := range []struct {
+		name string
+		wrap func(repo *MemoryProductRepository) ProductRepository
+	}{
+		{name: "sharded", wrap: func(repo *MemoryProductRepository) ProductRepository { return repo }},
+		{name: "single lock", wrap: func(repo *MemoryProductRepository) ProductRepository {
+			return &serializedProductRepository{ProductRepository: repo}
+		}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			repo, ids := newRepository(b)
+			products := bench.wrap(repo)
+			filter := model.ProductFilter{Page: pagination.DefaultParams()}
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					id := ids[i%catalog]
+					switch percentile := i % 100; {
+					case percentile == 0:
+						_, _, _ = products.Find(ctx, filter)
+					case percentile <= 15:
+						_, _ = products.ReserveStock(ctx, id, 1)
+					case percentile <= 30:
+						_, _ = products.ReleaseStock(ctx, id, 1)
+					default:
+						_, _ = products.GetByID(ctx, id)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestMemoryProductRepository_Stats(t *testing.T) {
+	// Arrange
+	repo := NewMemoryProductRepository()
+	ctx := context.Background()
+	before, err := repo.Stats(ctx)
+	require.NoError(t, err)
+
+	t.Run("Count new products", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(ctx, &model.Product{Name: "Board Game", Price: money.New(100, "NOK"), Category: "Games"})
+		require.NoError(t, err)
+		stats, err := repo.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, before.Total+1, stats.Total)
+		assert.Equal(t, before.Inactive+1, stats.Inactive)
+		assert.Contains(t, stats.Categories, model.CategoryCount{Category: "Games", Count: 1})
+		assert.Contains(t, stats.Prices, model.PriceStats{Currency: "NOK", Count: 1, Min: "1.00", Average: "1.00", Max: "1.00"})
+	})
+
+	t.Run("Leave deleted products out", func(t *testing.T) {
+		// Act
+		require.NoError(t, repo.Delete(ctx, "product-789"))
+		stats, err := repo.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, before.Total, stats.Total)
+		assert.Equal(t, before.Active-1, stats.Active)
+	})
+
+	t.Run("Return copies of the cached stats", func(t *testing.T) {
+		// Arrange
+		stats, err := repo.Stats(ctx)
+		require.NoError(t, err)
+
+		// Act
+		stats.Prices[0].Count = 1000
+		again, err := repo.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotEqual(t, 1000, again.Prices[0].Count)
+	})
+}
+
+func TestMemoryProductRepository_UpdatedAt(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Grow with every write", func(t *testing.T) {
+		// Arrange
+		repo := newEmptyMemoryProductRepository()
+		created, err := repo.Create(ctx, &model.Product{ID: "product-1", Name: "Mouse", Price: money.New(1999, "USD"), CategoryID: "category-1", Active: true, StockQuantity: 10})
+		require.NoError(t, err)
+		stamps := []time.Time{created.UpdatedAt}
+		writes := []func() (*model.Product, error){
+			func() (*model.Product, error) {
+				return repo.Update(ctx, "product-1", &model.Product{Name: "Wireless Mouse", Price: money.New(1999, "USD"), CategoryID: "category-1", Active: true})
+			},
+			func() (*model.Product, error) { return repo.ReserveStock(ctx, "product-1", 2) },
+			func() (*model.Product, error) { return repo.ReleaseStock(ctx, "product-1", 1) },
+			func() (*model.Product, error) { return repo.AdjustStock(ctx, "product-1", 5) },
+			func() (*model.Product, error) {
+				renamed, err := repo.RenameCategory(ctx, "category-1", "Accessories")
+				if err != nil {
+					return nil, err
+				}
+				return renamed[0], nil
+			},
+		}
+
+		// Act
+		for _, write := range writes {
+			product, err := write()
+			require.NoError(t, err)
+			stamps = append(stamps, product.UpdatedAt)
+		}
+		require.NoError(t, repo.Delete(ctx, "product-1"))
+		deleted, _ := repo.products.Get("product-1")
+		stamps = append(stamps, deleted.UpdatedAt)
+		restored, err := repo.Restore(ctx, "product-1")
+		require.NoError(t, err)
+		stamps = append(stamps, restored.UpdatedAt)
+
+		// Assert
+		for i := 1; i < len(stamps); i++ {
+			assert.True(t, stamps[i].After(stamps[i-1]), "write %d at %s is not after %s", i, stamps[i], stamps[i-1])
+		}
+		for _, stamp := range stamps {
+			assert.Equal(t, stamp, stamp.Truncate(time.Microsecond))
+		}
+		require.NotNil(t, deleted.DeletedAt)
+		assert.Equal(t, deleted.UpdatedAt, *deleted.DeletedAt)
+	})
+
+	t.Run("Concurrent stock changes", func(t *testing.T) {
+		// Arrange
+		repo := newEmptyMemoryProductRepository()
+		_, err := repo.Create(ctx, &model.Product{ID: "product-1", Name: "Mouse", Price: money.New(1999, "USD"), Active: true, StockQuantity: 1000})
+		require.NoError(t, err)
+		const writers, writes = 8, 50
+		stamps := make(chan time.Time, writers*writes)
+		var wg sync.WaitGroup
+
+		// Act
+		for range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range writes {
+					product, err := repo.ReserveStock(ctx, "product-1", 1)
+					if err == nil {
+						stamps <- product.UpdatedAt
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(stamps)
+
+		// Assert
+		seen := make(map[time.Time]bool, writers*writes)
+		for stamp := range stamps {
+			assert.False(t, seen[stamp], "two writes stamped %s", stamp)
+			seen[stamp] = true
+		}
+		assert.Len(t, seen, writers*writes)
+	})
+
+	t.Run("Write after a product stamped ahead of the clock", func(t *testing.T) {
+		// Arrange
+		repo := newEmptyMemoryProductRepository()
+		_, err := repo.Create(ctx, &model.Product{ID: "product-1", Name: "Mouse", Price: money.New(1999, "USD"), Active: true, StockQuantity: 10})
+		require.NoError(t, err)
+		ahead := time.Now().UTC().Add(time.Hour)
+		stored, _ := repo.products.Get("product-1")
+		stored.UpdatedAt = ahead
+
+		// Act
+		updated, err := repo.AdjustStock(ctx, "product-1", 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, updated.UpdatedAt.After(ahead))
+	})
+}
@@ -0,0 +1,2 @@
+DROP TABLE product_codes;
+DROP TABLE products;
@@ -0,0 +1,32 @@
+-- Products keep their full representation in data, as JSON. The columns
+-- next to it are the ones lookups and change data capture need.
+CREATE TABLE products (
+	tenant      TEXT NOT NULL,
+	id          TEXT NOT NULL,
+	category_id TEXT NOT NULL DEFAULT '',
+	data        TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL,
+	deleted_at  TEXT,
+	PRIMARY KEY (tenant, id)
+);
+
+CREATE INDEX products_category_id ON products (tenant, category_id);
+
+CREATE INDEX products_updated_at ON products (updated_at);
+
+-- product_codes maps the SKUs and GTINs of the products to their owners,
+-- keeping them unique per tenant. Products and variants share one SKU
+-- namespace, keyed in lower case; GTINs are keyed by their GTIN-14 form.
+-- variant_id is empty for the codes of the product itself. Soft-deleted
+-- products keep their codes since they may be restored.
+CREATE TABLE product_codes (
+	tenant     TEXT NOT NULL,
+	kind       TEXT NOT NULL,
+	code       TEXT NOT NULL,
+	product_id TEXT NOT NULL,
+	variant_id TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (tenant, kind, code)
+);
+
+CREATE INDEX product_codes_product_id ON product_codes (tenant, product_id);
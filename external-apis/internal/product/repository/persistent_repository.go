@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/timerange"
+	"external-apis/internal/shared/wal"
+)
+
+// ProductLoader is a searchable ProductRepository that recovered products
+// can be loaded into
+type ProductLoader interface {
+	ProductRepository
+	SearchRepository
+	Load(ctx context.Context, products []*model.Product) error
+}
+
+// PersistentProductRepository records every product written to the wrapped
+// repository in a write-ahead log so an in-memory repository survives
+// restarts. Writes are serialized so the log holds them in the order they
+// were applied; the writes of a transaction are logged once it commits.
+type PersistentProductRepository struct {
+	inner ProductRepository
+	// loader is the wrapped repository outside transactions, where products
+	// are recovered into it and searched
+	loader ProductLoader
+	log    *wal.Log
+	// pending collects the entries of a transaction until it commits; it is
+	// nil outside transactions
+	pending *[]wal.Entry
+	mutex   *sync.Mutex
+}
+
+// NewPersistentProductRepository wraps inner so that its products are logged
+// to log. Call Recover before serving requests to load what the log holds.
+func NewPersistentProductRepository(inner ProductLoader, log *wal.Log) *PersistentProductRepository {
+	return &PersistentProductRepository{
+		inner:  inner,
+		loader: inner,
+		log:    log,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+// Recover loads the products of every tenant held by the log into the
+// wrapped repository and returns the number of products loaded
+func (r *PersistentProductRepository) Recover(ctx context.Context) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	recovered := 0
+	for _, id := range r.log.Tenants() {
+		states := r.log.States(id)
+		products := make([]*model.Product, 0, len(states))
+		for _, state := range states {
+			var product model.Product
+			if err := json.Unmarshal(state, &product); err != nil {
+				return recovered, err
+			}
+			products = append(products, &product)
+		}
+		if err := r.loader.Load(tenant.WithTenant(ctx, id), products); err != nil {
+			return recovered, err
+		}
+		recovered += len(products)
+	}
+	return recovered, nil
+}
+
+// GetByID retrieves a product by ID
+func (r *PersistentProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	return r.inner.GetByID(ctx, id)
+}
+
+// GetAll retrieves all products that have not been deleted
+func (r *PersistentProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
+	return r.inner.GetAll(ctx)
+}
+
+// GetByIDs retrieves the products with the given IDs that exist and have not
+// been deleted
+func (r *PersistentProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	return r.inner.GetByIDs(ctx, ids)
+}
+
+// GetBySKU retrieves a product by SKU
+func (r *PersistentProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	return r.inner.GetBySKU(ctx, sku)
+}
+
+// GetByGTIN retrieves a product by GTIN
+func (r *PersistentProductRepository) GetByGTIN(ctx context.Context, gtin string) (*model.Product, error) {
+	return r.inner.GetByGTIN(ctx, gtin)
+}
+
+// Find retrieves a page of products matching the filter along with the total
+// number of matches
+func (r *PersistentProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	return r.inner.Find(ctx, filter)
+}
+
+// Search ranks the products of the wrapped repository by relevance to the query
+func (r *PersistentProductRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	return r.loader.Search(ctx, query)
+}
+
+// Create stores and logs a new product
+func (r *PersistentProductRepository) Create(ctx context.Context, product *model.Product) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.Create(ctx, product)
+	})
+}
+
+// Update stores and logs an existing product
+func (r *PersistentProductRepository) Update(ctx context.Context, id string, product *model.Product) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.Update(ctx, id, product)
+	})
+}
+
+// Delete soft-deletes a product by ID and logs the deleted product
+func (r *PersistentProductRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.write(ctx, func() (*model.Product, error) {
+		current, err := r.inner.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.inner.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return r.deleted(ctx, current)
+	})
+	return err
+}
+
+// Restore undoes the soft delete of a product and logs it
+func (r *PersistentProductRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.Restore(ctx, id)
+	})
+}
+
+// ExistsByID checks if a product exists by ID
+func (r *PersistentProductRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.inner.ExistsByID(ctx, id)
+}
+
+// ReserveStock reserves units of a product's available stock and logs the product
+func (r *PersistentProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.ReserveStock(ctx, id, quantity)
+	})
+}
+
+// ReleaseStock releases reserved units of a product and logs the product
+func (r *PersistentProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.ReleaseStock(ctx, id, quantity)
+	})
+}
+
+// AdjustStock changes the stock of a product and logs the product
+func (r *PersistentProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.AdjustStock(ctx, id, delta)
+	})
+}
+
+// RenameCategory renames the category of its products and logs them
+func (r *PersistentProductRepository) RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error) {
+	defer r.lock()()
+
+	renamed, err := r.inner.RenameCategory(ctx, categoryID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.put(ctx, renamed...); err != nil {
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// AddImage attaches an image to a product and logs the product
+func (r *PersistentProductRepository) AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.AddImage(ctx, id, image)
+	})
+}
+
+// RemoveImage detaches an image from a product and logs the product
+func (r *PersistentProductRepository) RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.RemoveImage(ctx, id, imageID)
+	})
+}
+
+// AddVariant adds a variant to a product and logs the product
+func (r *PersistentProductRepository) AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.AddVariant(ctx, id, variant)
+	})
+}
+
+// UpdateVariant replaces a variant of a product and logs the product
+func (r *PersistentProductRepository) UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.UpdateVariant(ctx, id, variant)
+	})
+}
+
+// RemoveVariant removes a variant from a product and logs the product
+func (r *PersistentProductRepository) RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.RemoveVariant(ctx, id, variantID)
+	})
+}
+
+// SetRating stores the rating of a product and logs the product
+func (r *PersistentProductRepository) SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error) {
+	return r.write(ctx, func() (*model.Product, error) {
+		return r.inner.SetRating(ctx, id, rating)
+	})
+}
+
+// Stats summarizes the products of the wrapped repository
+func (r *PersistentProductRepository) Stats(ctx context.Context) (*model.ProductStats, error) {
+	return r.inner.Stats(ctx)
+}
+
+// Transaction runs fn against a transaction of the wrapped repository and
+// logs its writes once it commits
+func (r *PersistentProductRepository) Transaction(ctx context.Context, fn func(tx ProductRepository) error) error {
+	defer r.lock()()
+
+	var pending []wal.Entry
+	err := r.inner.Transaction(ctx, func(tx ProductRepository) error {
+		return fn(&PersistentProductRepository{
+			inner:   tx,
+			log:     r.log,
+			pending: &pending,
+			mutex:   r.mutex,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return r.log.Append(pending...)
+}
+
+// write serializes a write returning the written product and logs the product
+func (r *PersistentProductRepository) write(ctx context.Context, fn func() (*model.Product, error)) (*model.Product, error) {
+	defer r.lock()()
+
+	product, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.put(ctx, product); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// lock serializes writes and returns the function releasing the lock.
+// Repositories of a transaction run under the lock of the transaction.
+func (r *PersistentProductRepository) lock() func() {
+	if r.pending != nil {
+		return func() {}
+	}
+	r.mutex.Lock()
+	return r.mutex.Unlock
+}
+
+// put logs the state of products of the tenant of ctx, or holds the entries
+// back until the transaction commits
+func (r *PersistentProductRepository) put(ctx context.Context, products ...*model.Product) error {
+	entries := make([]wal.Entry, len(products))
+	for i, product := range products {
+		value, err := json.Marshal(product)
+		if err != nil {
+			return err
+		}
+		entries[i] = wal.Entry{Tenant: tenant.FromContext(ctx), ID: product.ID, Value: value}
+	}
+
+	if r.pending != nil {
+		*r.pending = append(*r.pending, entries...)
+		return nil
+	}
+	return r.log.Append(entries...)
+}
+
+// deleted retrieves a product after it was soft-deleted. Deleting stamps
+// UpdatedAt, so only products changed since current was read are scanned.
+func (r *PersistentProductRepository) deleted(ctx context.Context, current *model.Product) (*model.Product, error) {
+	changed, _, err := r.inner.Find(ctx, model.ProductFilter{
+		Updated:        timerange.Range{Since: current.UpdatedAt.Add(time.Nanosecond)},
+		IncludeDeleted: true,
+		Page:           pagination.Params{Limit: math.MaxInt},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(changed, func(product *model.Product) bool { return product.ID == current.ID })
+	if i < 0 {
+		return nil, model.ErrProductNotFound
+	}
+	return changed[i], nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/wal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openPersistentProductRepository opens the log in dir and recovers a fresh
+// tenant repository from it, as a restarted service would
+func openPersistentProductRepository(t *testing.T, dir string) *PersistentProductRepository {
+	t.Helper()
+	products, err := wal.Open(filepath.Join(dir, "products"), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { products.Close() })
+
+	repo := NewPersistentProductRepository(NewTenantProductRepository(), products)
+	_, err = repo.Recover(context.Background())
+	require.NoError(t, err)
+	return repo
+}
+
+func TestPersistentProductRepository_Recover(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	brandA := tenant.WithTenant(ctx, "brand-a")
+	dir := t.TempDir()
+	repo := openPersistentProductRepository(t, dir)
+
+	created, err := repo.Create(brandA, &model.Product{ID: "product-a", SKU: "SODA-12", Name: "Soda", Price: money.New(499, "USD"), Active: true, StockQuantity: 10})
+	require.NoError(t, err)
+	_, err = repo.ReserveStock(brandA, "product-a", 4)
+	require.NoError(t, err)
+	_, err = repo.AddVariant(brandA, "product-a", model.ProductVariant{ID: "variant-1", SKU: "SODA-12-CHERRY", Attributes: map[string]string{"flavor": "cherry"}})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, "product-789"))
+
+	// Act
+	recovered := openPersistentProductRepository(t, dir)
+
+	// Assert
+	t.Run("Products of every tenant", func(t *testing.T) {
+		product, err := recovered.GetBySKU(brandA, "soda-12")
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, product.ID)
+		assert.Equal(t, 4, product.ReservedQuantity)
+		assert.Len(t, product.Variants, 1)
+		assert.Equal(t, 0, created.Price.Cmp(product.Price))
+	})
+
+	t.Run("Codes stay unique", func(t *testing.T) {
+		_, err := recovered.Create(brandA, &model.Product{SKU: "SODA-12-CHERRY", Name: "Cherry soda", Price: money.New(499, "USD")})
+		assert.ErrorIs(t, err, model.ErrSKUExists)
+	})
+
+	t.Run("Deleted products stay deleted", func(t *testing.T) {
+		assert.False(t, recovered.ExistsByID(ctx, "product-789"))
+		hits, _, err := recovered.Search(ctx, model.ProductSearch{Query: "laptop", Filter: model.ProductFilter{Page: pagination.DefaultParams()}})
+		require.NoError(t, err)
+		assert.Empty(t, hits)
+	})
+}
+
+func TestPersistentProductRepository_Transaction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := openPersistentProductRepository(t, dir)
+	failure := errors.New("order failed")
+
+	// Act
+	committed := repo.Transaction(ctx, func(tx ProductRepository) error {
+		_, err := tx.ReserveStock(ctx, "product-789", 2)
+		return err
+	})
+	rolledBack := repo.Transaction(ctx, func(tx ProductRepository) error {
+		if _, err := tx.ReserveStock(ctx, "product-789", 3); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	// Assert
+	require.NoError(t, committed)
+	assert.ErrorIs(t, rolledBack, failure)
+	product, err := openPersistentProductRepository(t, dir).GetByID(ctx, "product-789")
+	require.NoError(t, err)
+	assert.Equal(t, 2, product.ReservedQuantity)
+}
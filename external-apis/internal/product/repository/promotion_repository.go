@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// PromotionRepository defines the interface for promotion operations
+type PromotionRepository interface {
+	GetAll(ctx context.Context) ([]*model.Promotion, error)
+	GetByID(ctx context.Context, id string) (*model.Promotion, error)
+	Create(ctx context.Context, promotion *model.Promotion) (*model.Promotion, error)
+	Update(ctx context.Context, promotion *model.Promotion) (*model.Promotion, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryPromotionRepository implements PromotionRepository using in-memory
+// storage. Coupon codes are unique across promotions.
+type MemoryPromotionRepository struct {
+	promotions map[string]*model.Promotion
+	touched    map[string]time.Time
+	// ids generates the IDs of new promotions
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryPromotionRepository creates a new in-memory promotion repository
+func NewMemoryPromotionRepository() *MemoryPromotionRepository {
+	return &MemoryPromotionRepository{
+		promotions: make(map[string]*model.Promotion),
+		touched:    make(map[string]time.Time),
+		ids:        idgen.Default(),
+	}
+}
+
+// GetAll retrieves all promotions, oldest first
+func (r *MemoryPromotionRepository) GetAll(ctx context.Context) ([]*model.Promotion, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	promotions := make([]*model.Promotion, 0, len(r.promotions))
+	for _, promotion := range r.promotions {
+		promotions = append(promotions, promotion.Clone())
+	}
+
+	sort.Slice(promotions, func(i, j int) bool {
+		if !promotions[i].CreatedAt.Equal(promotions[j].CreatedAt) {
+			return promotions[i].CreatedAt.Before(promotions[j].CreatedAt)
+		}
+		return promotions[i].ID < promotions[j].ID
+	})
+	return promotions, nil
+}
+
+// GetByID retrieves a promotion by ID
+func (r *MemoryPromotionRepository) GetByID(ctx context.Context, id string) (*model.Promotion, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	promotion, exists := r.promotions[id]
+	if !exists {
+		return nil, model.ErrPromotionNotFound
+	}
+	return promotion.Clone(), nil
+}
+
+// Create stores a new promotion
+func (r *MemoryPromotionRepository) Create(ctx context.Context, promotion *model.Promotion) (*model.Promotion, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if promotion.ID == "" {
+		promotion.ID = r.ids.New()
+	}
+	if _, exists := r.promotions[promotion.ID]; exists {
+		return nil, model.ErrPromotionExists
+	}
+	if r.couponTaken(promotion) {
+		return nil, model.ErrCouponCodeTaken
+	}
+
+	now := time.Now().UTC()
+	promotion.CreatedAt = now
+	promotion.UpdatedAt = now
+
+	r.promotions[promotion.ID] = promotion.Clone()
+	r.touched[promotion.ID] = time.Now()
+	return promotion.Clone(), nil
+}
+
+// Update replaces an existing promotion, keeping its creation time
+func (r *MemoryPromotionRepository) Update(ctx context.Context, promotion *model.Promotion) (*model.Promotion, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.promotions[promotion.ID]
+	if !exists {
+		return nil, model.ErrPromotionNotFound
+	}
+	if r.couponTaken(promotion) {
+		return nil, model.ErrCouponCodeTaken
+	}
+
+	promotion.CreatedAt = existing.CreatedAt
+	promotion.UpdatedAt = time.Now().UTC()
+
+	r.promotions[promotion.ID] = promotion.Clone()
+	r.touched[promotion.ID] = time.Now()
+	return promotion.Clone(), nil
+}
+
+// Delete removes a promotion
+func (r *MemoryPromotionRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.promotions[id]; !exists {
+		return model.ErrPromotionNotFound
+	}
+	delete(r.promotions, id)
+	delete(r.touched, id)
+	return nil
+}
+
+// PurgeExpired removes promotions written before cutoff. Promotions have no
+// seed data, so every expired promotion is dropped.
+func (r *MemoryPromotionRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			delete(r.promotions, id)
+			delete(r.touched, id)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// Reset removes all promotions
+func (r *MemoryPromotionRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.promotions = make(map[string]*model.Promotion)
+	r.touched = make(map[string]time.Time)
+}
+
+// couponTaken checks if another promotion has the coupon code of promotion.
+// Callers must hold the lock.
+func (r *MemoryPromotionRepository) couponTaken(promotion *model.Promotion) bool {
+	if promotion.CouponCode == "" {
+		return false
+	}
+	for id, other := range r.promotions {
+		if id != promotion.ID && other.CouponCode == promotion.CouponCode {
+			return true
+		}
+	}
+	return false
+}
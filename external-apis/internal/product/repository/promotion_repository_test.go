@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPromotionRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemoryPromotionRepository()
+
+	// Act
+	created, err := repo.Create(context.Background(), &model.Promotion{Name: "Spring sale", Type: model.DiscountPercentage, BasisPoints: 1000, CouponCode: "SPRING", Active: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	t.Run("Get by ID", func(t *testing.T) {
+		// Act
+		found, err := repo.GetByID(context.Background(), created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
+	t.Run("Coupon code taken", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Promotion{Name: "Copy", CouponCode: "SPRING"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCouponCodeTaken)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Promotion{ID: created.ID})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrPromotionExists)
+	})
+}
+
+func TestMemoryPromotionRepository_Update(t *testing.T) {
+	t.Run("Replace keeping the creation time and coupon", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryPromotionRepository()
+		created, err := repo.Create(context.Background(), &model.Promotion{Name: "Spring sale", CouponCode: "SPRING"})
+		require.NoError(t, err)
+
+		// Act
+		updated, err := repo.Update(context.Background(), &model.Promotion{ID: created.ID, Name: "Summer sale", CouponCode: "SPRING"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Summer sale", updated.Name)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	})
+
+	t.Run("Coupon code of another promotion", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryPromotionRepository()
+		_, err := repo.Create(context.Background(), &model.Promotion{Name: "Spring sale", CouponCode: "SPRING"})
+		require.NoError(t, err)
+		other, err := repo.Create(context.Background(), &model.Promotion{Name: "Summer sale", CouponCode: "SUMMER"})
+		require.NoError(t, err)
+		other.CouponCode = "SPRING"
+
+		// Act
+		_, err = repo.Update(context.Background(), other)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCouponCodeTaken)
+	})
+
+	t.Run("Unknown promotion", func(t *testing.T) {
+		// Arrange
+		repo := NewMemoryPromotionRepository()
+
+		// Act
+		_, err := repo.Update(context.Background(), &model.Promotion{ID: "promotion-999"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrPromotionNotFound)
+	})
+}
+
+func TestMemoryPromotionRepository_GetAll(t *testing.T) {
+	// Arrange
+	repo := NewMemoryPromotionRepository()
+	first, err := repo.Create(context.Background(), &model.Promotion{Name: "First", ProductIDs: []string{"product-001"}})
+	require.NoError(t, err)
+	second, err := repo.Create(context.Background(), &model.Promotion{Name: "Second"})
+	require.NoError(t, err)
+
+	// Act
+	promotions, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	promotions[0].ProductIDs[0] = "product-002"
+
+	// Assert
+	require.Len(t, promotions, 2)
+	assert.Equal(t, first.ID, promotions[0].ID)
+	assert.Equal(t, second.ID, promotions[1].ID)
+	stored, err := repo.GetByID(context.Background(), first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"product-001"}, stored.ProductIDs)
+}
+
+func TestMemoryPromotionRepository_Delete(t *testing.T) {
+	// Arrange
+	repo := NewMemoryPromotionRepository()
+	created, err := repo.Create(context.Background(), &model.Promotion{Name: "Spring sale"})
+	require.NoError(t, err)
+
+	// Act
+	err = repo.Delete(context.Background(), created.ID)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = repo.GetByID(context.Background(), created.ID)
+	assert.ErrorIs(t, err, model.ErrPromotionNotFound)
+	assert.ErrorIs(t, repo.Delete(context.Background(), created.ID), model.ErrPromotionNotFound)
+}
+
+func TestMemoryPromotionRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryPromotionRepository()
+	_, err := repo.Create(context.Background(), &model.Promotion{Name: "Spring sale"})
+	require.NoError(t, err)
+
+	// Act
+	purged := repo.PurgeExpired(time.Now().Add(time.Minute))
+
+	// Assert
+	assert.Equal(t, 1, purged)
+	promotions, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, promotions)
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// ReservationRepository defines the interface for stock reservation operations
+type ReservationRepository interface {
+	GetByID(ctx context.Context, id string) (*model.Reservation, error)
+	GetByOrderID(ctx context.Context, orderID string) ([]*model.Reservation, error)
+	GetExpired(ctx context.Context, now time.Time) ([]*model.Reservation, error)
+	Create(ctx context.Context, reservation *model.Reservation) (*model.Reservation, error)
+	Transition(ctx context.Context, id string, to model.ReservationStatus, now time.Time) (*model.Reservation, error)
+}
+
+// MemoryReservationRepository implements ReservationRepository using
+// in-memory storage. Status changes are atomic, so a reservation is released
+// at most once even when it expires while being released.
+type MemoryReservationRepository struct {
+	reservations map[string]*model.Reservation
+	touched      map[string]time.Time
+	// ids generates the IDs of new reservations
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryReservationRepository creates a new in-memory reservation repository
+func NewMemoryReservationRepository() *MemoryReservationRepository {
+	return &MemoryReservationRepository{
+		reservations: make(map[string]*model.Reservation),
+		touched:      make(map[string]time.Time),
+		ids:          idgen.Default(),
+	}
+}
+
+// GetByID retrieves a reservation by ID
+func (r *MemoryReservationRepository) GetByID(ctx context.Context, id string) (*model.Reservation, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, model.ErrReservationNotFound
+	}
+
+	copied := *reservation
+	return &copied, nil
+}
+
+// GetByOrderID retrieves the reservations of an order, oldest first
+func (r *MemoryReservationRepository) GetByOrderID(ctx context.Context, orderID string) ([]*model.Reservation, error) {
+	return r.filter(func(reservation *model.Reservation) bool {
+		return reservation.OrderID == orderID
+	}), nil
+}
+
+// GetExpired retrieves the active reservations that expired by now
+func (r *MemoryReservationRepository) GetExpired(ctx context.Context, now time.Time) ([]*model.Reservation, error) {
+	return r.filter(func(reservation *model.Reservation) bool {
+		return reservation.IsExpired(now)
+	}), nil
+}
+
+// Create stores a new reservation
+func (r *MemoryReservationRepository) Create(ctx context.Context, reservation *model.Reservation) (*model.Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if reservation.ID == "" {
+		reservation.ID = r.ids.New()
+	}
+
+	if _, exists := r.reservations[reservation.ID]; exists {
+		return nil, model.ErrReservationExists
+	}
+
+	now := time.Now().UTC()
+	if reservation.CreatedAt.IsZero() {
+		reservation.CreatedAt = now
+	}
+	reservation.UpdatedAt = reservation.CreatedAt
+
+	stored := *reservation
+	r.reservations[stored.ID] = &stored
+	r.touched[stored.ID] = time.Now()
+
+	copied := stored
+	return &copied, nil
+}
+
+// Transition atomically moves a reservation to another status
+func (r *MemoryReservationRepository) Transition(ctx context.Context, id string, to model.ReservationStatus, now time.Time) (*model.Reservation, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	reservation, exists := r.reservations[id]
+	if !exists {
+		return nil, model.ErrReservationNotFound
+	}
+
+	updated := *reservation
+	if err := updated.Transition(to, now); err != nil {
+		return nil, err
+	}
+	r.reservations[id] = &updated
+	r.touched[id] = time.Now()
+
+	copied := updated
+	return &copied, nil
+}
+
+// PurgeExpired removes reservations written before cutoff. Reservations have
+// no seed data, so every expired reservation is dropped.
+func (r *MemoryReservationRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			delete(r.reservations, id)
+			delete(r.touched, id)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// Reset removes all reservations
+func (r *MemoryReservationRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.reservations = make(map[string]*model.Reservation)
+	r.touched = make(map[string]time.Time)
+}
+
+// filter returns copies of the matching reservations, oldest first
+func (r *MemoryReservationRepository) filter(match func(*model.Reservation) bool) []*model.Reservation {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reservations := make([]*model.Reservation, 0)
+	for _, reservation := range r.reservations {
+		if match(reservation) {
+			copied := *reservation
+			reservations = append(reservations, &copied)
+		}
+	}
+
+	sort.Slice(reservations, func(i, j int) bool {
+		if !reservations[i].CreatedAt.Equal(reservations[j].CreatedAt) {
+			return reservations[i].CreatedAt.Before(reservations[j].CreatedAt)
+		}
+		return reservations[i].ID < reservations[j].ID
+	})
+	return reservations
+}
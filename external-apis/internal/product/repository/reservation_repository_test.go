@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReservation(orderID string, expiresAt time.Time) *model.Reservation {
+	return &model.Reservation{
+		ProductID: "product-001",
+		OrderID:   orderID,
+		Quantity:  2,
+		Status:    model.ReservationActive,
+		ExpiresAt: expiresAt,
+	}
+}
+
+func TestMemoryReservationRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReservationRepository()
+	expiresAt := time.Now().Add(time.Minute)
+
+	// Act
+	created, err := repo.Create(context.Background(), newTestReservation("order-1", expiresAt))
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	t.Run("Get by ID", func(t *testing.T) {
+		// Act
+		found, err := repo.GetByID(context.Background(), created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
+	t.Run("Get by order ID", func(t *testing.T) {
+		// Arrange
+		_, err := repo.Create(context.Background(), newTestReservation("order-2", expiresAt))
+		require.NoError(t, err)
+
+		// Act
+		found, err := repo.GetByOrderID(context.Background(), "order-1")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, created.ID, found[0].ID)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Reservation{ID: created.ID})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReservationExists)
+	})
+}
+
+func TestMemoryReservationRepository_Transition(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		status    model.ReservationStatus
+		expiresAt time.Time
+		to        model.ReservationStatus
+		err       error
+	}{
+		{name: "Confirm active", status: model.ReservationActive, expiresAt: now.Add(time.Minute), to: model.ReservationConfirmed},
+		{name: "Confirm expired", status: model.ReservationActive, expiresAt: now, to: model.ReservationConfirmed, err: model.ErrReservationExpired},
+		{name: "Confirm released", status: model.ReservationReleased, expiresAt: now.Add(time.Minute), to: model.ReservationConfirmed, err: model.ErrReservationNotActive},
+		{name: "Release confirmed", status: model.ReservationConfirmed, expiresAt: now, to: model.ReservationReleased},
+		{name: "Release expired", status: model.ReservationExpired, expiresAt: now, to: model.ReservationReleased, err: model.ErrReservationNotActive},
+		{name: "Expire active past expiry", status: model.ReservationActive, expiresAt: now, to: model.ReservationExpired},
+		{name: "Expire active before expiry", status: model.ReservationActive, expiresAt: now.Add(time.Minute), to: model.ReservationExpired, err: model.ErrReservationNotActive},
+		{name: "Expire confirmed", status: model.ReservationConfirmed, expiresAt: now, to: model.ReservationExpired, err: model.ErrReservationNotActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := NewMemoryReservationRepository()
+			reservation := newTestReservation("order-1", tt.expiresAt)
+			reservation.Status = tt.status
+			created, err := repo.Create(context.Background(), reservation)
+			require.NoError(t, err)
+
+			// Act
+			updated, err := repo.Transition(context.Background(), created.ID, tt.to, now)
+
+			// Assert
+			stored, _ := repo.GetByID(context.Background(), created.ID)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, tt.status, stored.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, updated.Status)
+			assert.Equal(t, tt.to, stored.Status)
+		})
+	}
+
+	t.Run("Missing reservation", func(t *testing.T) {
+		// Act
+		_, err := NewMemoryReservationRepository().Transition(context.Background(), "missing", model.ReservationReleased, now)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReservationNotFound)
+	})
+}
+
+func TestMemoryReservationRepository_GetExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReservationRepository()
+	now := time.Now()
+	expired, err := repo.Create(context.Background(), newTestReservation("order-1", now.Add(-time.Second)))
+	require.NoError(t, err)
+	_, err = repo.Create(context.Background(), newTestReservation("order-2", now.Add(time.Minute)))
+	require.NoError(t, err)
+	confirmed := newTestReservation("order-3", now.Add(-time.Second))
+	confirmed.Status = model.ReservationConfirmed
+	_, err = repo.Create(context.Background(), confirmed)
+	require.NoError(t, err)
+
+	// Act
+	found, err := repo.GetExpired(context.Background(), now)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, expired.ID, found[0].ID)
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// ReviewRepository defines the interface for product review operations
+type ReviewRepository interface {
+	GetByProduct(ctx context.Context, productID string) ([]*model.Review, error)
+	GetByStatus(ctx context.Context, status model.ReviewStatus) ([]*model.Review, error)
+	GetByID(ctx context.Context, productID, id string) (*model.Review, error)
+	Create(ctx context.Context, review *model.Review) (*model.Review, error)
+	Update(ctx context.Context, review *model.Review) (*model.Review, error)
+	Delete(ctx context.Context, productID, id string) error
+}
+
+// MemoryReviewRepository implements ReviewRepository using in-memory
+// storage. A customer reviews a product at most once.
+type MemoryReviewRepository struct {
+	reviews map[string]*model.Review
+	touched map[string]time.Time
+	// ids generates the IDs of new reviews
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryReviewRepository creates a new in-memory review repository
+func NewMemoryReviewRepository() *MemoryReviewRepository {
+	return &MemoryReviewRepository{
+		reviews: make(map[string]*model.Review),
+		touched: make(map[string]time.Time),
+		ids:     idgen.Default(),
+	}
+}
+
+// GetByProduct retrieves the reviews of a product, oldest first
+func (r *MemoryReviewRepository) GetByProduct(ctx context.Context, productID string) ([]*model.Review, error) {
+	return r.filter(func(review *model.Review) bool {
+		return review.ProductID == productID
+	}), nil
+}
+
+// GetByStatus retrieves the reviews of every product with a status, oldest
+// first
+func (r *MemoryReviewRepository) GetByStatus(ctx context.Context, status model.ReviewStatus) ([]*model.Review, error) {
+	return r.filter(func(review *model.Review) bool {
+		return review.Status == status
+	}), nil
+}
+
+// GetByID retrieves a review of a product by ID
+func (r *MemoryReviewRepository) GetByID(ctx context.Context, productID, id string) (*model.Review, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	review, exists := r.reviews[id]
+	if !exists || review.ProductID != productID {
+		return nil, model.ErrReviewNotFound
+	}
+	return review.Clone(), nil
+}
+
+// Create stores a new review
+func (r *MemoryReviewRepository) Create(ctx context.Context, review *model.Review) (*model.Review, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if review.ID == "" {
+		review.ID = r.ids.New()
+	}
+	for _, other := range r.reviews {
+		if other.ID == review.ID || (other.ProductID == review.ProductID && other.CustomerID == review.CustomerID) {
+			return nil, model.ErrReviewExists
+		}
+	}
+
+	now := time.Now().UTC()
+	review.CreatedAt = now
+	review.UpdatedAt = now
+
+	r.reviews[review.ID] = review.Clone()
+	r.touched[review.ID] = time.Now()
+	return review.Clone(), nil
+}
+
+// Update replaces an existing review, keeping its product, customer and
+// creation time
+func (r *MemoryReviewRepository) Update(ctx context.Context, review *model.Review) (*model.Review, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.reviews[review.ID]
+	if !exists || existing.ProductID != review.ProductID {
+		return nil, model.ErrReviewNotFound
+	}
+
+	review = review.Clone()
+	review.CustomerID = existing.CustomerID
+	review.CreatedAt = existing.CreatedAt
+	review.UpdatedAt = time.Now().UTC()
+
+	r.reviews[review.ID] = review
+	r.touched[review.ID] = time.Now()
+	return review.Clone(), nil
+}
+
+// Delete removes a review of a product
+func (r *MemoryReviewRepository) Delete(ctx context.Context, productID, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	review, exists := r.reviews[id]
+	if !exists || review.ProductID != productID {
+		return model.ErrReviewNotFound
+	}
+	delete(r.reviews, id)
+	delete(r.touched, id)
+	return nil
+}
+
+// PurgeExpired removes reviews written before cutoff. Reviews have no seed
+// data, so every expired review is dropped.
+func (r *MemoryReviewRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			delete(r.reviews, id)
+			delete(r.touched, id)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// Reset removes all reviews
+func (r *MemoryReviewRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.reviews = make(map[string]*model.Review)
+	r.touched = make(map[string]time.Time)
+}
+
+// filter returns copies of the reviews that match, oldest first
+func (r *MemoryReviewRepository) filter(match func(review *model.Review) bool) []*model.Review {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	reviews := make([]*model.Review, 0)
+	for _, review := range r.reviews {
+		if match(review) {
+			reviews = append(reviews, review.Clone())
+		}
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		if !reviews[i].CreatedAt.Equal(reviews[j].CreatedAt) {
+			return reviews[i].CreatedAt.Before(reviews[j].CreatedAt)
+		}
+		return reviews[i].ID < reviews[j].ID
+	})
+	return reviews
+}
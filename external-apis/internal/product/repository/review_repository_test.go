@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryReviewRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReviewRepository()
+
+	// Act
+	created, err := repo.Create(context.Background(), &model.Review{ProductID: "product-001", CustomerID: "customer-001", Rating: 5, Status: model.ReviewPending})
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	t.Run("Get by ID", func(t *testing.T) {
+		// Act
+		found, err := repo.GetByID(context.Background(), "product-001", created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
+	t.Run("Review of another product", func(t *testing.T) {
+		// Act
+		_, err := repo.GetByID(context.Background(), "product-002", created.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReviewNotFound)
+	})
+
+	t.Run("Customer reviews a product once", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Review{ProductID: "product-001", CustomerID: "customer-001", Rating: 1})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReviewExists)
+	})
+}
+
+func TestMemoryReviewRepository_Queries(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReviewRepository()
+	ctx := context.Background()
+	first, err := repo.Create(ctx, &model.Review{ProductID: "product-001", CustomerID: "customer-001", Rating: 5, Status: model.ReviewApproved})
+	require.NoError(t, err)
+	second, err := repo.Create(ctx, &model.Review{ProductID: "product-001", CustomerID: "customer-002", Rating: 3, Status: model.ReviewPending})
+	require.NoError(t, err)
+	other, err := repo.Create(ctx, &model.Review{ProductID: "product-002", CustomerID: "customer-001", Rating: 2, Status: model.ReviewPending})
+	require.NoError(t, err)
+
+	t.Run("By product", func(t *testing.T) {
+		// Act
+		reviews, err := repo.GetByProduct(ctx, "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Review{first, second}, reviews)
+	})
+
+	t.Run("By status", func(t *testing.T) {
+		// Act
+		reviews, err := repo.GetByStatus(ctx, model.ReviewPending)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Review{second, other}, reviews)
+	})
+}
+
+func TestMemoryReviewRepository_Update(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReviewRepository()
+	ctx := context.Background()
+	created, err := repo.Create(ctx, &model.Review{ProductID: "product-001", CustomerID: "customer-001", Rating: 5, Status: model.ReviewPending})
+	require.NoError(t, err)
+
+	t.Run("Keep the customer", func(t *testing.T) {
+		// Arrange
+		changed := created.Clone()
+		changed.Status = model.ReviewApproved
+		changed.CustomerID = "customer-999"
+
+		// Act
+		updated, err := repo.Update(ctx, changed)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.ReviewApproved, updated.Status)
+		assert.Equal(t, "customer-001", updated.CustomerID)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	})
+
+	t.Run("Review of another product", func(t *testing.T) {
+		// Arrange
+		changed := created.Clone()
+		changed.ProductID = "product-002"
+
+		// Act
+		_, err := repo.Update(ctx, changed)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReviewNotFound)
+	})
+}
+
+func TestMemoryReviewRepository_Delete(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReviewRepository()
+	ctx := context.Background()
+	created, err := repo.Create(ctx, &model.Review{ProductID: "product-001", CustomerID: "customer-001", Rating: 5})
+	require.NoError(t, err)
+
+	// Act
+	wrongProduct := repo.Delete(ctx, "product-002", created.ID)
+	deleted := repo.Delete(ctx, "product-001", created.ID)
+	again := repo.Delete(ctx, "product-001", created.ID)
+
+	// Assert
+	assert.ErrorIs(t, wrongProduct, model.ErrReviewNotFound)
+	assert.NoError(t, deleted)
+	assert.ErrorIs(t, again, model.ErrReviewNotFound)
+}
+
+func TestMemoryReviewRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryReviewRepository()
+	_, err := repo.Create(context.Background(), &model.Review{ProductID: "product-001", CustomerID: "customer-001", Rating: 5})
+	require.NoError(t, err)
+
+	// Act
+	purged := repo.PurgeExpired(time.Now().Add(time.Minute))
+
+	// Assert
+	assert.Equal(t, 1, purged)
+	reviews, _ := repo.GetByProduct(context.Background(), "product-001")
+	assert.Empty(t, reviews)
+}
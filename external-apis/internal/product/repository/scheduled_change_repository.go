@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// ScheduledChangeRepository defines the interface for scheduled product change operations
+type ScheduledChangeRepository interface {
+	GetByID(ctx context.Context, id string) (*model.ScheduledChange, error)
+	GetByProductID(ctx context.Context, productID string) ([]*model.ScheduledChange, error)
+	GetDue(ctx context.Context, now time.Time) ([]*model.ScheduledChange, error)
+	Create(ctx context.Context, change *model.ScheduledChange) (*model.ScheduledChange, error)
+	Transition(ctx context.Context, id string, to model.ScheduledChangeStatus, now time.Time, failure string) (*model.ScheduledChange, error)
+}
+
+// MemoryScheduledChangeRepository implements ScheduledChangeRepository using
+// in-memory storage. Status changes are atomic, so a change cancelled while
+// the scheduler picks it up is either cancelled or applied, never both.
+type MemoryScheduledChangeRepository struct {
+	changes map[string]*model.ScheduledChange
+	touched map[string]time.Time
+	// ids generates the IDs of new scheduled changes
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryScheduledChangeRepository creates a new in-memory scheduled change repository
+func NewMemoryScheduledChangeRepository() *MemoryScheduledChangeRepository {
+	return &MemoryScheduledChangeRepository{
+		changes: make(map[string]*model.ScheduledChange),
+		touched: make(map[string]time.Time),
+		ids:     idgen.Default(),
+	}
+}
+
+// GetByID retrieves a scheduled change by ID
+func (r *MemoryScheduledChangeRepository) GetByID(ctx context.Context, id string) (*model.ScheduledChange, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	change, exists := r.changes[id]
+	if !exists {
+		return nil, model.ErrScheduledChangeNotFound
+	}
+
+	copied := *change
+	return &copied, nil
+}
+
+// GetByProductID retrieves the changes scheduled for a product, in the order
+// they take effect
+func (r *MemoryScheduledChangeRepository) GetByProductID(ctx context.Context, productID string) ([]*model.ScheduledChange, error) {
+	return r.filter(func(change *model.ScheduledChange) bool {
+		return change.ProductID == productID
+	}), nil
+}
+
+// GetDue retrieves the pending changes that are due by now, in the order they
+// take effect
+func (r *MemoryScheduledChangeRepository) GetDue(ctx context.Context, now time.Time) ([]*model.ScheduledChange, error) {
+	return r.filter(func(change *model.ScheduledChange) bool {
+		return change.IsDue(now)
+	}), nil
+}
+
+// Create stores a new scheduled change
+func (r *MemoryScheduledChangeRepository) Create(ctx context.Context, change *model.ScheduledChange) (*model.ScheduledChange, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if change.ID == "" {
+		change.ID = r.ids.New()
+	}
+
+	if _, exists := r.changes[change.ID]; exists {
+		return nil, model.ErrScheduledChangeExists
+	}
+
+	if change.CreatedAt.IsZero() {
+		change.CreatedAt = time.Now().UTC()
+	}
+	change.UpdatedAt = change.CreatedAt
+
+	stored := *change
+	r.changes[stored.ID] = &stored
+	r.touched[stored.ID] = time.Now()
+
+	copied := stored
+	return &copied, nil
+}
+
+// Transition atomically moves a scheduled change to another status. Failure
+// is recorded for failed changes.
+func (r *MemoryScheduledChangeRepository) Transition(ctx context.Context, id string, to model.ScheduledChangeStatus, now time.Time, failure string) (*model.ScheduledChange, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	change, exists := r.changes[id]
+	if !exists {
+		return nil, model.ErrScheduledChangeNotFound
+	}
+
+	updated := *change
+	if err := updated.Transition(to, now); err != nil {
+		return nil, err
+	}
+	if to == model.ScheduledChangeFailed {
+		updated.Failure = failure
+	}
+	r.changes[id] = &updated
+	r.touched[id] = time.Now()
+
+	copied := updated
+	return &copied, nil
+}
+
+// PurgeExpired removes scheduled changes written before cutoff. Scheduled
+// changes have no seed data, so every expired change is dropped.
+func (r *MemoryScheduledChangeRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			delete(r.changes, id)
+			delete(r.touched, id)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// Reset removes all scheduled changes
+func (r *MemoryScheduledChangeRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.changes = make(map[string]*model.ScheduledChange)
+	r.touched = make(map[string]time.Time)
+}
+
+// filter returns copies of the matching changes, ordered by effective time
+func (r *MemoryScheduledChangeRepository) filter(match func(*model.ScheduledChange) bool) []*model.ScheduledChange {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	changes := make([]*model.ScheduledChange, 0)
+	for _, change := range r.changes {
+		if match(change) {
+			copied := *change
+			changes = append(changes, &copied)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].EffectiveAt.Equal(changes[j].EffectiveAt) {
+			return changes[i].EffectiveAt.Before(changes[j].EffectiveAt)
+		}
+		if !changes[i].CreatedAt.Equal(changes[j].CreatedAt) {
+			return changes[i].CreatedAt.Before(changes[j].CreatedAt)
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
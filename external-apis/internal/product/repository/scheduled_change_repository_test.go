@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduledChange(productID string, effectiveAt time.Time) *model.ScheduledChange {
+	price := json.Number("19.99")
+	return &model.ScheduledChange{
+		ProductID:   productID,
+		Price:       &price,
+		EffectiveAt: effectiveAt,
+		Status:      model.ScheduledChangePending,
+	}
+}
+
+func TestMemoryScheduledChangeRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemoryScheduledChangeRepository()
+	effectiveAt := time.Now().Add(time.Hour)
+
+	// Act
+	created, err := repo.Create(context.Background(), newTestScheduledChange("product-001", effectiveAt))
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	t.Run("Get by ID", func(t *testing.T) {
+		// Act
+		found, err := repo.GetByID(context.Background(), created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
+	t.Run("Get by product ID in effective order", func(t *testing.T) {
+		// Arrange
+		earlier, err := repo.Create(context.Background(), newTestScheduledChange("product-001", effectiveAt.Add(-time.Minute)))
+		require.NoError(t, err)
+		_, err = repo.Create(context.Background(), newTestScheduledChange("product-002", effectiveAt))
+		require.NoError(t, err)
+
+		// Act
+		found, err := repo.GetByProductID(context.Background(), "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, earlier.ID, found[0].ID)
+		assert.Equal(t, created.ID, found[1].ID)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.ScheduledChange{ID: created.ID})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrScheduledChangeExists)
+	})
+}
+
+func TestMemoryScheduledChangeRepository_Transition(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		status      model.ScheduledChangeStatus
+		effectiveAt time.Time
+		to          model.ScheduledChangeStatus
+		err         error
+	}{
+		{name: "Pick up due", status: model.ScheduledChangePending, effectiveAt: now, to: model.ScheduledChangeApplying},
+		{name: "Pick up before effective time", status: model.ScheduledChangePending, effectiveAt: now.Add(time.Minute), to: model.ScheduledChangeApplying, err: model.ErrScheduledChangeNotPending},
+		{name: "Cancel pending", status: model.ScheduledChangePending, effectiveAt: now.Add(time.Minute), to: model.ScheduledChangeCancelled},
+		{name: "Cancel applying", status: model.ScheduledChangeApplying, effectiveAt: now, to: model.ScheduledChangeCancelled, err: model.ErrScheduledChangeNotPending},
+		{name: "Apply applying", status: model.ScheduledChangeApplying, effectiveAt: now, to: model.ScheduledChangeApplied},
+		{name: "Apply pending", status: model.ScheduledChangePending, effectiveAt: now, to: model.ScheduledChangeApplied, err: model.ErrScheduledChangeNotPending},
+		{name: "Fail applying", status: model.ScheduledChangeApplying, effectiveAt: now, to: model.ScheduledChangeFailed},
+		{name: "Pick up cancelled", status: model.ScheduledChangeCancelled, effectiveAt: now, to: model.ScheduledChangeApplying, err: model.ErrScheduledChangeNotPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := NewMemoryScheduledChangeRepository()
+			change := newTestScheduledChange("product-001", tt.effectiveAt)
+			change.Status = tt.status
+			created, err := repo.Create(context.Background(), change)
+			require.NoError(t, err)
+
+			// Act
+			updated, err := repo.Transition(context.Background(), created.ID, tt.to, now, "product not found")
+
+			// Assert
+			stored, _ := repo.GetByID(context.Background(), created.ID)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, tt.status, stored.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, updated.Status)
+			assert.Equal(t, tt.to, stored.Status)
+			if tt.to == model.ScheduledChangeFailed {
+				assert.Equal(t, "product not found", stored.Failure)
+			} else {
+				assert.Empty(t, stored.Failure)
+			}
+		})
+	}
+
+	t.Run("Missing change", func(t *testing.T) {
+		// Act
+		_, err := NewMemoryScheduledChangeRepository().Transition(context.Background(), "missing", model.ScheduledChangeCancelled, now, "")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrScheduledChangeNotFound)
+	})
+}
+
+func TestMemoryScheduledChangeRepository_GetDue(t *testing.T) {
+	// Arrange
+	repo := NewMemoryScheduledChangeRepository()
+	now := time.Now()
+	due, err := repo.Create(context.Background(), newTestScheduledChange("product-001", now.Add(-time.Second)))
+	require.NoError(t, err)
+	_, err = repo.Create(context.Background(), newTestScheduledChange("product-002", now.Add(time.Minute)))
+	require.NoError(t, err)
+	cancelled := newTestScheduledChange("product-003", now.Add(-time.Second))
+	cancelled.Status = model.ScheduledChangeCancelled
+	_, err = repo.Create(context.Background(), cancelled)
+	require.NoError(t, err)
+
+	// Act
+	found, err := repo.GetDue(context.Background(), now)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, due.ID, found[0].ID)
+}
@@ -0,0 +1,699 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"slices"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/clock"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/search"
+	"external-apis/internal/shared/tenant"
+)
+
+// Kinds of the codes in product_codes
+const (
+	codeSKU  = "sku"
+	codeGTIN = "gtin"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the schema migrations of SQLiteProductRepository
+func Migrations() fs.FS {
+	sub, _ := fs.Sub(migrationFiles, "migrations")
+	return sub
+}
+
+// SQLiteProductRepository implements ProductRepository and SearchRepository
+// on an SQLite database migrated with Migrations, so products outlive
+// restarts without running a database server. Rows are partitioned by the
+// tenant of the request context. Listings, stats and searches read every
+// product of the tenant and filter them like the memory repository does,
+// which suits the catalogs of local development and demos.
+type SQLiteProductRepository struct {
+	db *sql.DB
+	// q runs the statements: db, or the transaction of a repository
+	// handed to Transaction
+	q database.Querier
+	// cluster routes GetByID, GetAll and Find to its read replicas when
+	// their context tolerates stale reads
+	cluster *database.Cluster
+	clock   *clock.Clock
+	// ids generates the IDs of new products
+	ids idgen.Generator
+}
+
+// NewSQLiteProductRepository creates a product repository on db
+func NewSQLiteProductRepository(db *sql.DB) *SQLiteProductRepository {
+	return NewReplicatedSQLiteProductRepository(database.NewCluster(db, nil, 0))
+}
+
+// NewReplicatedSQLiteProductRepository creates a product repository writing
+// to the primary of cluster and reading from its replicas
+func NewReplicatedSQLiteProductRepository(cluster *database.Cluster) *SQLiteProductRepository {
+	db := cluster.Primary()
+	return &SQLiteProductRepository{db: db, q: db, cluster: cluster, clock: clock.New(), ids: idgen.Default()}
+}
+
+// SetIDs sets the generator of the IDs of new products, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *SQLiteProductRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// Seed stores the sample products in the default tenant unless it already
+// has products, deleted ones included
+func (r *SQLiteProductRepository) Seed(ctx context.Context) error {
+	ctx = tenant.WithTenant(ctx, tenant.Default)
+	return r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		var count int
+		if err := repo.q.QueryRowContext(ctx, `SELECT COUNT(*) FROM products WHERE tenant = ?`, tenant.Default).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		seededAt := r.clock.Now()
+		for _, product := range sampleProducts() {
+			product.CreatedAt = seededAt
+			product.UpdatedAt = seededAt
+			if err := repo.insert(ctx, product); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a product by ID
+func (r *SQLiteProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	var product *model.Product
+	err := r.read(ctx, func(repo *SQLiteProductRepository) error {
+		var err error
+		product, err = repo.get(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if product.IsDeleted() {
+		return nil, model.ErrProductNotFound
+	}
+	return product, nil
+}
+
+// GetByIDs retrieves the products with the given IDs that exist and have not
+// been deleted. Unknown IDs are skipped; the order of the result is undefined.
+func (r *SQLiteProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	products := make([]*model.Product, 0, len(ids))
+	for _, id := range ids {
+		product, err := r.GetByID(ctx, id)
+		if errors.Is(err, model.ErrProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+// GetBySKU retrieves the product with the given SKU, ignoring case. Variant
+// SKUs do not match.
+func (r *SQLiteProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	owner, exists, err := r.codeOwner(ctx, codeSKU, skuKey(sku))
+	if err != nil {
+		return nil, err
+	}
+	if !exists || owner.VariantID != "" {
+		return nil, model.ErrProductNotFound
+	}
+	return r.GetByID(ctx, owner.ProductID)
+}
+
+// GetByGTIN retrieves the product with the given GTIN, in any of its lengths
+func (r *SQLiteProductRepository) GetByGTIN(ctx context.Context, gtin string) (*model.Product, error) {
+	owner, exists, err := r.codeOwner(ctx, codeGTIN, model.GTINKey(gtin))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, model.ErrProductNotFound
+	}
+	return r.GetByID(ctx, owner.ProductID)
+}
+
+// GetAll retrieves all products that have not been deleted, ordered by ID
+func (r *SQLiteProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
+	var products []*model.Product
+	err := r.read(ctx, func(repo *SQLiteProductRepository) error {
+		var err error
+		products, err = repo.list(ctx, `SELECT data FROM products WHERE tenant = ? AND deleted_at IS NULL ORDER BY id`, tenant.FromContext(ctx))
+		return err
+	})
+	return products, err
+}
+
+// Find retrieves a page of products matching the filter along with the total number of matches
+func (r *SQLiteProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	var products []*model.Product
+	err := r.read(ctx, func(repo *SQLiteProductRepository) error {
+		var err error
+		products, err = repo.list(ctx, `SELECT data FROM products WHERE tenant = ? ORDER BY id`, tenant.FromContext(ctx))
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]*model.Product, 0, len(products))
+	for _, product := range products {
+		if filter.Matches(product) {
+			matches = append(matches, product)
+		}
+	}
+	sortProducts(matches, filter.Sort)
+
+	start, end := filter.Page.Bounds(len(matches))
+	return matches[start:end], len(matches), nil
+}
+
+// Search ranks the products matching the query and filter by relevance and
+// returns a page of hits along with the total number of matches. The
+// products of the tenant are indexed per search.
+func (r *SQLiteProductRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	products, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	index := search.NewIndex()
+	byID := make(map[string]*model.Product, len(products))
+	for _, product := range products {
+		index.Add(product.ID,
+			search.Field{Text: product.Name, Weight: nameWeight},
+			search.Field{Text: product.Category, Weight: categoryWeight},
+			search.Field{Text: product.Description, Weight: descriptionWeight},
+		)
+		byID[product.ID] = product
+	}
+
+	hits := make([]model.ProductSearchHit, 0)
+	for _, hit := range index.Search(query.Query) {
+		if product := byID[hit.ID]; query.Filter.Matches(product) {
+			hits = append(hits, model.ProductSearchHit{Product: product, Score: hit.Score})
+		}
+	}
+
+	start, end := query.Filter.Page.Bounds(len(hits))
+	return hits[start:end], len(hits), nil
+}
+
+// Create creates a new product
+func (r *SQLiteProductRepository) Create(ctx context.Context, product *model.Product) (*model.Product, error) {
+	product = product.Clone()
+	if product.ID == "" {
+		product.ID = r.ids.New()
+	}
+
+	err := r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		// Soft-deleted products keep their ID reserved
+		if _, err := repo.get(ctx, product.ID); err == nil {
+			return model.ErrProductExists
+		} else if !errors.Is(err, model.ErrProductNotFound) {
+			return err
+		}
+		if err := repo.checkCodes(ctx, product, product.ID); err != nil {
+			return err
+		}
+
+		product.UpdatedAt = r.clock.Now()
+		if product.CreatedAt.IsZero() {
+			product.CreatedAt = product.UpdatedAt
+		}
+		return repo.insert(ctx, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product.Clone(), nil
+}
+
+// Update updates an existing product
+func (r *SQLiteProductRepository) Update(ctx context.Context, id string, product *model.Product) (*model.Product, error) {
+	product = product.Clone()
+	err := r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		current, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := repo.checkCodes(ctx, product, id); err != nil {
+			return err
+		}
+
+		// Stock levels, images, variants and ratings only change through
+		// their own operations, like in the memory repository
+		product.ID = id
+		product.CreatedAt = current.CreatedAt
+		product.StockQuantity = current.StockQuantity
+		product.ReservedQuantity = current.ReservedQuantity
+		product.Images = current.Images
+		product.Variants = current.Variants
+		product.Rating = current.Rating
+		product.UpdatedAt = r.clock.After(current.UpdatedAt)
+		return repo.update(ctx, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product.Clone(), nil
+}
+
+// Delete soft-deletes a product by ID so it can be restored later
+func (r *SQLiteProductRepository) Delete(ctx context.Context, id string) error {
+	return r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		deleted, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		deletedAt := r.clock.After(deleted.UpdatedAt)
+		deleted.DeletedAt = &deletedAt
+		deleted.UpdatedAt = deletedAt
+		return repo.update(ctx, deleted)
+	})
+}
+
+// Restore undoes the soft delete of a product
+func (r *SQLiteProductRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	var restored *model.Product
+	err := r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		product, err := repo.get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !product.IsDeleted() {
+			return model.ErrProductNotDeleted
+		}
+
+		product.DeletedAt = nil
+		product.UpdatedAt = r.clock.After(product.UpdatedAt)
+		restored = product
+		return repo.update(ctx, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// ExistsByID checks if a product exists by ID
+func (r *SQLiteProductRepository) ExistsByID(ctx context.Context, id string) bool {
+	_, err := r.GetByID(ctx, id)
+	return err == nil
+}
+
+// ReserveStock atomically reserves units of an active product's available stock
+func (r *SQLiteProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		if !product.Active {
+			return model.ErrProductInactive
+		}
+		if product.AvailableQuantity() < quantity {
+			return model.ErrInsufficientStock
+		}
+		product.ReservedQuantity += quantity
+		return nil
+	})
+}
+
+// ReleaseStock atomically returns previously reserved units to available stock
+func (r *SQLiteProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		if product.ReservedQuantity < quantity {
+			return model.ErrReleaseExceedsStock
+		}
+		product.ReservedQuantity -= quantity
+		return nil
+	})
+}
+
+// AdjustStock atomically changes the units on hand by delta. Stock may not
+// drop below zero or below the units already reserved.
+func (r *SQLiteProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		if product.StockQuantity+delta < product.ReservedQuantity {
+			return model.ErrBelowReserved
+		}
+		product.StockQuantity += delta
+		return nil
+	})
+}
+
+// RenameCategory updates the category name of every product in the category,
+// including soft-deleted ones, and returns the products that are not deleted
+func (r *SQLiteProductRepository) RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error) {
+	renamed := make([]*model.Product, 0)
+	err := r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		products, err := repo.list(ctx, `SELECT data FROM products WHERE tenant = ? AND category_id = ? ORDER BY id`,
+			tenant.FromContext(ctx), categoryID)
+		if err != nil {
+			return err
+		}
+
+		for _, product := range products {
+			if product.Category == name {
+				continue
+			}
+			product.Category = name
+			product.UpdatedAt = r.clock.After(product.UpdatedAt)
+			if err := repo.update(ctx, product); err != nil {
+				return err
+			}
+			if !product.IsDeleted() {
+				renamed = append(renamed, product)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return renamed, nil
+}
+
+// AddImage appends an image to a product
+func (r *SQLiteProductRepository) AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		product.Images = append(product.Images, image)
+		return nil
+	})
+}
+
+// RemoveImage detaches an image from a product
+func (r *SQLiteProductRepository) RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		index := slices.IndexFunc(product.Images, func(image model.ProductImage) bool {
+			return image.ID == imageID
+		})
+		if index < 0 {
+			return model.ErrImageNotFound
+		}
+		product.Images = slices.Delete(product.Images, index, index+1)
+		return nil
+	})
+}
+
+// AddVariant appends a variant to a product. SKUs are unique across the
+// products of the tenant and attributes are unique within a product.
+func (r *SQLiteProductRepository) AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		if err := repo.checkVariant(ctx, product, variant); err != nil {
+			return err
+		}
+		product.Variants = append(product.Variants, variant)
+		return nil
+	})
+}
+
+// UpdateVariant replaces the variant of a product with the same ID
+func (r *SQLiteProductRepository) UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		index := slices.IndexFunc(product.Variants, func(existing model.ProductVariant) bool {
+			return existing.ID == variant.ID
+		})
+		if index < 0 {
+			return model.ErrVariantNotFound
+		}
+		if err := repo.checkVariant(ctx, product, variant); err != nil {
+			return err
+		}
+		product.Variants[index] = variant
+		return nil
+	})
+}
+
+// RemoveVariant detaches a variant from a product
+func (r *SQLiteProductRepository) RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		index := slices.IndexFunc(product.Variants, func(variant model.ProductVariant) bool {
+			return variant.ID == variantID
+		})
+		if index < 0 {
+			return model.ErrVariantNotFound
+		}
+		product.Variants = slices.Delete(product.Variants, index, index+1)
+		return nil
+	})
+}
+
+// SetRating replaces the aggregated rating of a product
+func (r *SQLiteProductRepository) SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error) {
+	return r.updateProduct(ctx, id, func(repo *SQLiteProductRepository, product *model.Product) error {
+		product.Rating = rating
+		return nil
+	})
+}
+
+// Stats summarizes the products that are not deleted
+func (r *SQLiteProductRepository) Stats(ctx context.Context) (*model.ProductStats, error) {
+	products, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewProductStats(products), nil
+}
+
+// Transaction runs fn against a repository on a database transaction and
+// commits its writes only if fn returns nil before ctx is done. A
+// transaction handed to fn runs nested transactions in itself.
+func (r *SQLiteProductRepository) Transaction(ctx context.Context, fn func(tx ProductRepository) error) error {
+	if r.q != r.db {
+		return fn(r)
+	}
+	return database.Transaction(ctx, r.db, func(tx *sql.Tx) error {
+		return fn(&SQLiteProductRepository{db: r.db, q: tx, cluster: r.cluster, clock: r.clock, ids: r.ids})
+	})
+}
+
+// read runs fn against a repository on the database of the cluster a read
+// with ctx is routed to. Reads in a transaction stay in it.
+func (r *SQLiteProductRepository) read(ctx context.Context, fn func(repo *SQLiteProductRepository) error) error {
+	if r.q != r.db {
+		return fn(r)
+	}
+	return r.cluster.Read(ctx, func(q database.Querier) error {
+		return fn(&SQLiteProductRepository{db: r.db, q: q, cluster: r.cluster, clock: r.clock, ids: r.ids})
+	})
+}
+
+// updateProduct applies a change to a copy of a product that is not
+// deleted in a transaction, storing it only if the change succeeds
+func (r *SQLiteProductRepository) updateProduct(ctx context.Context, id string, apply func(repo *SQLiteProductRepository, product *model.Product) error) (*model.Product, error) {
+	var updated *model.Product
+	err := r.Transaction(ctx, func(tx ProductRepository) error {
+		repo := tx.(*SQLiteProductRepository)
+		product, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := apply(repo, product); err != nil {
+			return err
+		}
+
+		product.UpdatedAt = r.clock.After(product.UpdatedAt)
+		updated = product
+		return repo.update(ctx, product)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// checkVariant checks that the attributes of the variant are unique within
+// the product and that its SKU is not used elsewhere
+func (r *SQLiteProductRepository) checkVariant(ctx context.Context, product *model.Product, variant model.ProductVariant) error {
+	if err := product.CheckVariant(variant); err != nil {
+		return err
+	}
+	taken, err := r.codeTaken(ctx, codeSKU, skuKey(variant.SKU), skuOwner{ProductID: product.ID, VariantID: variant.ID})
+	if err != nil {
+		return err
+	}
+	if taken {
+		return model.ErrSKUExists
+	}
+	return nil
+}
+
+// checkCodes checks that no other product or variant uses the SKU or GTIN
+// of the product written as id. Soft-deleted products keep their codes
+// reserved since they may be restored.
+func (r *SQLiteProductRepository) checkCodes(ctx context.Context, product *model.Product, id string) error {
+	if product.SKU != "" {
+		taken, err := r.codeTaken(ctx, codeSKU, skuKey(product.SKU), skuOwner{ProductID: id})
+		if err != nil {
+			return err
+		}
+		if taken {
+			return model.ErrSKUExists
+		}
+	}
+	if product.GTIN != "" {
+		taken, err := r.codeTaken(ctx, codeGTIN, model.GTINKey(product.GTIN), skuOwner{ProductID: id})
+		if err != nil {
+			return err
+		}
+		if taken {
+			return model.ErrGTINExists
+		}
+	}
+	return nil
+}
+
+// codeTaken reports whether an owner other than the one being written uses
+// a code
+func (r *SQLiteProductRepository) codeTaken(ctx context.Context, kind, code string, writer skuOwner) (bool, error) {
+	owner, exists, err := r.codeOwner(ctx, kind, code)
+	if err != nil {
+		return false, err
+	}
+	return exists && owner != writer, nil
+}
+
+// codeOwner returns the owner of a code
+func (r *SQLiteProductRepository) codeOwner(ctx context.Context, kind, code string) (skuOwner, bool, error) {
+	var owner skuOwner
+	err := r.q.QueryRowContext(ctx, `SELECT product_id, variant_id FROM product_codes WHERE tenant = ? AND kind = ? AND code = ?`,
+		tenant.FromContext(ctx), kind, code).Scan(&owner.ProductID, &owner.VariantID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return skuOwner{}, false, nil
+	}
+	if err != nil {
+		return skuOwner{}, false, err
+	}
+	return owner, true, nil
+}
+
+// indexCodes replaces the codes of a product and its variants in
+// product_codes
+func (r *SQLiteProductRepository) indexCodes(ctx context.Context, product *model.Product) error {
+	tenantID := tenant.FromContext(ctx)
+	if _, err := r.q.ExecContext(ctx, `DELETE FROM product_codes WHERE tenant = ? AND product_id = ?`, tenantID, product.ID); err != nil {
+		return err
+	}
+
+	type code struct{ kind, code, variantID string }
+	codes := make([]code, 0, len(product.Variants)+2)
+	if product.SKU != "" {
+		codes = append(codes, code{codeSKU, skuKey(product.SKU), ""})
+	}
+	for _, variant := range product.Variants {
+		codes = append(codes, code{codeSKU, skuKey(variant.SKU), variant.ID})
+	}
+	if product.GTIN != "" {
+		codes = append(codes, code{codeGTIN, model.GTINKey(product.GTIN), ""})
+	}
+	for _, c := range codes {
+		_, err := r.q.ExecContext(ctx, `INSERT INTO product_codes (tenant, kind, code, product_id, variant_id) VALUES (?, ?, ?, ?, ?)`,
+			tenantID, c.kind, c.code, product.ID, c.variantID)
+		if database.IsUniqueViolation(err) {
+			if c.kind == codeGTIN {
+				return model.ErrGTINExists
+			}
+			return model.ErrSKUExists
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get retrieves a product, deleted or not
+func (r *SQLiteProductRepository) get(ctx context.Context, id string) (*model.Product, error) {
+	var data []byte
+	err := r.q.QueryRowContext(ctx, `SELECT data FROM products WHERE tenant = ? AND id = ?`, tenant.FromContext(ctx), id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrProductNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var product model.Product
+	if err := json.Unmarshal(data, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// list retrieves the products a query selects
+func (r *SQLiteProductRepository) list(ctx context.Context, query string, args ...any) ([]*model.Product, error) {
+	rows, err := r.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make([]*model.Product, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var product model.Product
+		if err := json.Unmarshal(data, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, &product)
+	}
+	return products, rows.Err()
+}
+
+// insert stores a new product and its codes
+func (r *SQLiteProductRepository) insert(ctx context.Context, product *model.Product) error {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return err
+	}
+	if _, err := r.q.ExecContext(ctx, `INSERT INTO products (tenant, id, category_id, data, created_at, updated_at, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tenant.FromContext(ctx), product.ID, product.CategoryID, data,
+		database.FormatTime(product.CreatedAt), database.FormatTime(product.UpdatedAt), database.FormatNullTime(product.DeletedAt)); err != nil {
+		return err
+	}
+	return r.indexCodes(ctx, product)
+}
+
+// update replaces a stored product and its codes
+func (r *SQLiteProductRepository) update(ctx context.Context, product *model.Product) error {
+	data, err := json.Marshal(product)
+	if err != nil {
+		return err
+	}
+	if _, err := r.q.ExecContext(ctx, `UPDATE products SET category_id = ?, data = ?, updated_at = ?, deleted_at = ?
+		WHERE tenant = ? AND id = ?`,
+		product.CategoryID, data, database.FormatTime(product.UpdatedAt), database.FormatNullTime(product.DeletedAt),
+		tenant.FromContext(ctx), product.ID); err != nil {
+		return err
+	}
+	return r.indexCodes(ctx, product)
+}
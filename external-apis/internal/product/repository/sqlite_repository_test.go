@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/migrations"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteProductRepository(t *testing.T, path string) *SQLiteProductRepository {
+	t.Helper()
+	db, err := database.OpenSQLite(path, database.DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = migrations.Up(context.Background(), db, Migrations())
+	require.NoError(t, err)
+	return NewSQLiteProductRepository(db)
+}
+
+func TestSQLiteProductRepository_Seed(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "products.db")
+	repo := newSQLiteProductRepository(t, path)
+
+	// Act
+	require.NoError(t, repo.Seed(ctx))
+	require.NoError(t, repo.Delete(ctx, "product-789"))
+	require.NoError(t, newSQLiteProductRepository(t, path).Seed(ctx))
+
+	// Assert
+	products, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, products, len(sampleProducts())-1, "seeding again does not bring back deleted products")
+	other, err := repo.GetAll(tenant.WithTenant(ctx, "brand-a"))
+	require.NoError(t, err)
+	assert.Empty(t, other)
+}
+
+func TestSQLiteProductRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteProductRepository(t, filepath.Join(t.TempDir(), "products.db"))
+
+	t.Run("Create and get", func(t *testing.T) {
+		// Act
+		created, err := repo.Create(ctx, &model.Product{ID: "product-1", SKU: "SODA-12", Name: "Soda", Price: money.New(499, "USD"), Active: true, StockQuantity: 10})
+		require.NoError(t, err)
+		retrieved, err := repo.GetByID(ctx, "product-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, retrieved)
+		assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(ctx, &model.Product{ID: "product-1", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductExists)
+	})
+
+	t.Run("Updates keep the stock", func(t *testing.T) {
+		// Arrange
+		_, err := repo.ReserveStock(ctx, "product-1", 4)
+		require.NoError(t, err)
+
+		// Act
+		updated, err := repo.Update(ctx, "product-1", &model.Product{SKU: "SODA-12", Name: "Cola", Price: money.New(499, "USD"), Active: true})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Cola", updated.Name)
+		assert.Equal(t, 10, updated.StockQuantity)
+		assert.Equal(t, 4, updated.ReservedQuantity)
+		assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+	})
+
+	t.Run("Reject reserving more than is available", func(t *testing.T) {
+		// Act
+		_, err := repo.ReserveStock(ctx, "product-1", 7)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInsufficientStock)
+	})
+
+	t.Run("Soft delete and restore", func(t *testing.T) {
+		// Act
+		require.NoError(t, repo.Delete(ctx, "product-1"))
+		deleted := repo.ExistsByID(ctx, "product-1")
+		restored, err := repo.Restore(ctx, "product-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		assert.Nil(t, restored.DeletedAt)
+		_, err = repo.Restore(ctx, "product-1")
+		assert.ErrorIs(t, err, model.ErrProductNotDeleted)
+	})
+}
+
+func TestSQLiteProductRepository_Codes(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteProductRepository(t, filepath.Join(t.TempDir(), "products.db"))
+	created, err := repo.Create(ctx, &model.Product{ID: "product-1", SKU: "SODA-12", GTIN: "036000291452", Name: "Soda", Price: money.New(499, "USD")})
+	require.NoError(t, err)
+
+	t.Run("Look up by code", func(t *testing.T) {
+		// Act
+		bySKU, skuErr := repo.GetBySKU(ctx, "soda-12")
+		byGTIN, gtinErr := repo.GetByGTIN(ctx, "0036000291452")
+
+		// Assert
+		require.NoError(t, skuErr)
+		require.NoError(t, gtinErr)
+		assert.Equal(t, created.ID, bySKU.ID)
+		assert.Equal(t, created.ID, byGTIN.ID)
+	})
+
+	t.Run("Codes are unique", func(t *testing.T) {
+		// Act
+		_, skuErr := repo.Create(ctx, &model.Product{SKU: "Soda-12", Name: "Soda", Price: money.New(499, "USD")})
+		_, gtinErr := repo.Create(ctx, &model.Product{SKU: "SODA-6", GTIN: "00036000291452", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.ErrorIs(t, skuErr, model.ErrSKUExists)
+		assert.ErrorIs(t, gtinErr, model.ErrGTINExists)
+	})
+
+	t.Run("Variants share the SKU namespace", func(t *testing.T) {
+		// Act
+		_, err := repo.AddVariant(ctx, "product-1", model.ProductVariant{ID: "variant-1", SKU: "SODA-12-CHERRY", Attributes: map[string]string{"flavor": "cherry"}})
+		require.NoError(t, err)
+		_, createErr := repo.Create(ctx, &model.Product{SKU: "soda-12-cherry", Name: "Cherry soda", Price: money.New(499, "USD")})
+		_, lookupErr := repo.GetBySKU(ctx, "SODA-12-CHERRY")
+
+		// Assert
+		assert.ErrorIs(t, createErr, model.ErrSKUExists)
+		assert.ErrorIs(t, lookupErr, model.ErrProductNotFound, "variant SKUs do not match products")
+	})
+
+	t.Run("Deleted products keep their codes", func(t *testing.T) {
+		// Act
+		require.NoError(t, repo.Delete(ctx, "product-1"))
+		_, err := repo.Create(ctx, &model.Product{SKU: "SODA-12", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSKUExists)
+	})
+
+	t.Run("Codes are unique per tenant", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(tenant.WithTenant(ctx, "brand-a"), &model.Product{SKU: "SODA-12", GTIN: "036000291452", Name: "Soda", Price: money.New(499, "USD")})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+}
+
+func TestSQLiteProductRepository_FindAndSearch(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteProductRepository(t, filepath.Join(t.TempDir(), "products.db"))
+	require.NoError(t, repo.Seed(ctx))
+	memory := NewMemoryProductRepository()
+
+	t.Run("Find like the memory repository", func(t *testing.T) {
+		// Arrange
+		filter := model.ProductFilter{Sort: model.SortByPriceDesc, Page: pagination.Params{Limit: 3}}
+
+		// Act
+		page, total, err := repo.Find(ctx, filter)
+
+		// Assert
+		require.NoError(t, err)
+		memoryPage, memoryTotal, err := memory.Find(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, memoryTotal, total)
+		require.Len(t, page, len(memoryPage))
+		for i := range page {
+			assert.Equal(t, memoryPage[i].ID, page[i].ID)
+		}
+	})
+
+	t.Run("Search like the memory repository", func(t *testing.T) {
+		// Arrange
+		query := model.ProductSearch{Query: "wireless", Filter: model.ProductFilter{Page: pagination.DefaultParams()}}
+
+		// Act
+		hits, total, err := repo.Search(ctx, query)
+
+		// Assert
+		require.NoError(t, err)
+		memoryHits, memoryTotal, err := memory.Search(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, memoryTotal, total)
+		require.Len(t, hits, len(memoryHits))
+		for i := range hits {
+			assert.Equal(t, memoryHits[i].Product.ID, hits[i].Product.ID)
+			assert.Equal(t, memoryHits[i].Score, hits[i].Score)
+		}
+	})
+
+	t.Run("Rename a category", func(t *testing.T) {
+		// Act
+		renamed, err := repo.RenameCategory(ctx, "category-electronics", "Gadgets")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, renamed, len(sampleProducts()))
+		stats, err := repo.Stats(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, len(sampleProducts()), stats.Total)
+	})
+}
+
+func TestSQLiteProductRepository_Transaction(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteProductRepository(t, filepath.Join(t.TempDir(), "products.db"))
+	_, err := repo.Create(ctx, &model.Product{ID: "product-1", Name: "Soda", Price: money.New(499, "USD"), Active: true, StockQuantity: 10})
+	require.NoError(t, err)
+	failure := errors.New("order failed")
+
+	// Act
+	err = repo.Transaction(ctx, func(tx ProductRepository) error {
+		if _, err := tx.ReserveStock(ctx, "product-1", 5); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, failure)
+	product, err := repo.GetByID(ctx, "product-1")
+	require.NoError(t, err)
+	assert.Zero(t, product.ReservedQuantity)
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// StockSubscriptionRepository defines the interface for back-in-stock
+// subscription operations
+type StockSubscriptionRepository interface {
+	GetByProduct(ctx context.Context, productID string) ([]*model.StockSubscription, error)
+	GetPending(ctx context.Context, productID string) ([]*model.StockSubscription, error)
+	HasPending(ctx context.Context, productID string) bool
+	Create(ctx context.Context, subscription *model.StockSubscription) (*model.StockSubscription, bool, error)
+	MarkNotified(ctx context.Context, productID, id string, at time.Time) (*model.StockSubscription, error)
+	Delete(ctx context.Context, productID, id string) error
+}
+
+// MemoryStockSubscriptionRepository implements StockSubscriptionRepository
+// using in-memory storage
+type MemoryStockSubscriptionRepository struct {
+	subscriptions map[string]*model.StockSubscription
+	touched       map[string]time.Time
+	// ids generates the IDs of new stock subscriptions
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemoryStockSubscriptionRepository creates a new in-memory back-in-stock
+// subscription repository
+func NewMemoryStockSubscriptionRepository() *MemoryStockSubscriptionRepository {
+	return &MemoryStockSubscriptionRepository{
+		subscriptions: make(map[string]*model.StockSubscription),
+		touched:       make(map[string]time.Time),
+		ids:           idgen.Default(),
+	}
+}
+
+// GetByProduct retrieves the subscriptions to a product, oldest first
+func (r *MemoryStockSubscriptionRepository) GetByProduct(ctx context.Context, productID string) ([]*model.StockSubscription, error) {
+	return r.filter(func(subscription *model.StockSubscription) bool {
+		return subscription.ProductID == productID
+	}), nil
+}
+
+// GetPending retrieves the subscriptions to a product that wait for a
+// restock, oldest first
+func (r *MemoryStockSubscriptionRepository) GetPending(ctx context.Context, productID string) ([]*model.StockSubscription, error) {
+	return r.filter(func(subscription *model.StockSubscription) bool {
+		return subscription.ProductID == productID && subscription.Status == model.SubscriptionPending
+	}), nil
+}
+
+// HasPending checks if any subscription to a product waits for a restock
+func (r *MemoryStockSubscriptionRepository) HasPending(ctx context.Context, productID string) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, subscription := range r.subscriptions {
+		if subscription.ProductID == productID && subscription.Status == model.SubscriptionPending {
+			return true
+		}
+	}
+	return false
+}
+
+// Create stores a new pending subscription. If the email already has a
+// pending subscription to the product, that subscription is returned
+// instead and created is false.
+func (r *MemoryStockSubscriptionRepository) Create(ctx context.Context, subscription *model.StockSubscription) (*model.StockSubscription, bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, other := range r.subscriptions {
+		if other.ProductID == subscription.ProductID && other.Email == subscription.Email && other.Status == model.SubscriptionPending {
+			return other.Clone(), false, nil
+		}
+	}
+
+	subscription = subscription.Clone()
+	if subscription.ID == "" {
+		subscription.ID = r.ids.New()
+	}
+	subscription.Status = model.SubscriptionPending
+	subscription.NotifiedAt = nil
+	subscription.CreatedAt = time.Now().UTC()
+
+	r.subscriptions[subscription.ID] = subscription
+	r.touched[subscription.ID] = time.Now()
+	return subscription.Clone(), true, nil
+}
+
+// MarkNotified records that a pending subscription to a product was notified
+// at a time. Only one caller can mark a subscription; the others get
+// ErrSubscriptionNotPending, so a restock is announced once.
+func (r *MemoryStockSubscriptionRepository) MarkNotified(ctx context.Context, productID, id string, at time.Time) (*model.StockSubscription, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists || subscription.ProductID != productID {
+		return nil, model.ErrStockSubscriptionNotFound
+	}
+	if subscription.Status != model.SubscriptionPending {
+		return nil, model.ErrSubscriptionNotPending
+	}
+
+	subscription = subscription.Clone()
+	subscription.Status = model.SubscriptionNotified
+	subscription.NotifiedAt = &at
+
+	r.subscriptions[id] = subscription
+	r.touched[id] = time.Now()
+	return subscription.Clone(), nil
+}
+
+// Delete removes a subscription to a product
+func (r *MemoryStockSubscriptionRepository) Delete(ctx context.Context, productID, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists || subscription.ProductID != productID {
+		return model.ErrStockSubscriptionNotFound
+	}
+	delete(r.subscriptions, id)
+	delete(r.touched, id)
+	return nil
+}
+
+// PurgeExpired removes subscriptions written before cutoff. Subscriptions
+// have no seed data, so every expired subscription is dropped.
+func (r *MemoryStockSubscriptionRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			delete(r.subscriptions, id)
+			delete(r.touched, id)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// Reset removes all subscriptions
+func (r *MemoryStockSubscriptionRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.subscriptions = make(map[string]*model.StockSubscription)
+	r.touched = make(map[string]time.Time)
+}
+
+// filter returns copies of the subscriptions that match, oldest first
+func (r *MemoryStockSubscriptionRepository) filter(match func(subscription *model.StockSubscription) bool) []*model.StockSubscription {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscriptions := make([]*model.StockSubscription, 0)
+	for _, subscription := range r.subscriptions {
+		if match(subscription) {
+			subscriptions = append(subscriptions, subscription.Clone())
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions
+}
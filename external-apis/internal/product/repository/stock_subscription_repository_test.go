@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStockSubscriptionRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemoryStockSubscriptionRepository()
+
+	// Act
+	created, isNew, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-001", Email: "jane@example.com"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, model.SubscriptionPending, created.Status)
+	assert.True(t, repo.HasPending(context.Background(), "product-001"))
+
+	t.Run("Return the pending subscription of the email", func(t *testing.T) {
+		// Act
+		existing, isNew, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-001", Email: "jane@example.com"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, isNew)
+		assert.Equal(t, created.ID, existing.ID)
+	})
+
+	t.Run("Another product", func(t *testing.T) {
+		// Act
+		_, isNew, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-002", Email: "jane@example.com"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, isNew)
+	})
+}
+
+func TestMemoryStockSubscriptionRepository_MarkNotified(t *testing.T) {
+	// Arrange
+	repo := NewMemoryStockSubscriptionRepository()
+	created, _, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-001", Email: "jane@example.com"})
+	require.NoError(t, err)
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	notified, err := repo.MarkNotified(context.Background(), "product-001", created.ID, at)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, model.SubscriptionNotified, notified.Status)
+	assert.Equal(t, at, *notified.NotifiedAt)
+	assert.False(t, repo.HasPending(context.Background(), "product-001"))
+
+	t.Run("Only once", func(t *testing.T) {
+		// Act
+		_, err := repo.MarkNotified(context.Background(), "product-001", created.ID, at)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSubscriptionNotPending)
+	})
+
+	t.Run("Subscription of another product", func(t *testing.T) {
+		// Act
+		_, err := repo.MarkNotified(context.Background(), "product-002", created.ID, at)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrStockSubscriptionNotFound)
+	})
+
+	t.Run("Subscribe again once notified", func(t *testing.T) {
+		// Act
+		resubscribed, isNew, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-001", Email: "jane@example.com"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, isNew)
+		assert.NotEqual(t, created.ID, resubscribed.ID)
+		pending, err := repo.GetPending(context.Background(), "product-001")
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, resubscribed.ID, pending[0].ID)
+		all, err := repo.GetByProduct(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+}
+
+func TestMemoryStockSubscriptionRepository_Delete(t *testing.T) {
+	// Arrange
+	repo := NewMemoryStockSubscriptionRepository()
+	created, _, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-001", Email: "jane@example.com"})
+	require.NoError(t, err)
+
+	t.Run("Subscription of another product", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), "product-002", created.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrStockSubscriptionNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), "product-001", created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, repo.HasPending(context.Background(), "product-001"))
+	})
+}
+
+func TestMemoryStockSubscriptionRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemoryStockSubscriptionRepository()
+	_, _, err := repo.Create(context.Background(), &model.StockSubscription{ProductID: "product-001", Email: "jane@example.com"})
+	require.NoError(t, err)
+
+	// Act
+	purged := repo.PurgeExpired(time.Now().Add(time.Minute))
+
+	// Assert
+	assert.Equal(t, 1, purged)
+	assert.False(t, repo.HasPending(context.Background(), "product-001"))
+}
@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+)
+
+// SupplierRepository defines the interface for supplier operations and the
+// links between products and their suppliers
+type SupplierRepository interface {
+	GetAll(ctx context.Context) ([]*model.Supplier, error)
+	GetByID(ctx context.Context, id string) (*model.Supplier, error)
+	Create(ctx context.Context, supplier *model.Supplier) (*model.Supplier, error)
+	Update(ctx context.Context, supplier *model.Supplier) (*model.Supplier, error)
+	Delete(ctx context.Context, id string) error
+	GetByProduct(ctx context.Context, productID string) ([]*model.ProductSupplier, error)
+	GetBySupplier(ctx context.Context, supplierID string) ([]*model.ProductSupplier, error)
+	SaveLink(ctx context.Context, link *model.ProductSupplier) (*model.ProductSupplier, error)
+	DeleteLink(ctx context.Context, productID, supplierID string) error
+}
+
+// linkKey identifies the link between a product and a supplier
+type linkKey struct {
+	productID  string
+	supplierID string
+}
+
+// MemorySupplierRepository implements SupplierRepository using in-memory
+// storage. Deleting a supplier removes its links to products.
+type MemorySupplierRepository struct {
+	suppliers    map[string]*model.Supplier
+	links        map[linkKey]*model.ProductSupplier
+	touched      map[string]time.Time
+	linksTouched map[linkKey]time.Time
+	// ids generates the IDs of new suppliers
+	ids   idgen.Generator
+	mutex sync.RWMutex
+}
+
+// NewMemorySupplierRepository creates a new in-memory supplier repository
+func NewMemorySupplierRepository() *MemorySupplierRepository {
+	return &MemorySupplierRepository{
+		suppliers:    make(map[string]*model.Supplier),
+		links:        make(map[linkKey]*model.ProductSupplier),
+		touched:      make(map[string]time.Time),
+		linksTouched: make(map[linkKey]time.Time),
+		ids:          idgen.Default(),
+	}
+}
+
+// GetAll retrieves all suppliers, oldest first
+func (r *MemorySupplierRepository) GetAll(ctx context.Context) ([]*model.Supplier, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	suppliers := make([]*model.Supplier, 0, len(r.suppliers))
+	for _, supplier := range r.suppliers {
+		suppliers = append(suppliers, supplier.Clone())
+	}
+
+	sort.Slice(suppliers, func(i, j int) bool {
+		if !suppliers[i].CreatedAt.Equal(suppliers[j].CreatedAt) {
+			return suppliers[i].CreatedAt.Before(suppliers[j].CreatedAt)
+		}
+		return suppliers[i].ID < suppliers[j].ID
+	})
+	return suppliers, nil
+}
+
+// GetByID retrieves a supplier by ID
+func (r *MemorySupplierRepository) GetByID(ctx context.Context, id string) (*model.Supplier, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	supplier, exists := r.suppliers[id]
+	if !exists {
+		return nil, model.ErrSupplierNotFound
+	}
+	return supplier.Clone(), nil
+}
+
+// Create stores a new supplier
+func (r *MemorySupplierRepository) Create(ctx context.Context, supplier *model.Supplier) (*model.Supplier, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if supplier.ID == "" {
+		supplier.ID = r.ids.New()
+	}
+	if _, exists := r.suppliers[supplier.ID]; exists {
+		return nil, model.ErrSupplierExists
+	}
+	if r.nameTaken(supplier) {
+		return nil, model.ErrSupplierNameTaken
+	}
+
+	now := time.Now().UTC()
+	supplier.CreatedAt = now
+	supplier.UpdatedAt = now
+
+	r.suppliers[supplier.ID] = supplier.Clone()
+	r.touched[supplier.ID] = time.Now()
+	return supplier.Clone(), nil
+}
+
+// Update replaces an existing supplier, keeping its creation time
+func (r *MemorySupplierRepository) Update(ctx context.Context, supplier *model.Supplier) (*model.Supplier, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.suppliers[supplier.ID]
+	if !exists {
+		return nil, model.ErrSupplierNotFound
+	}
+	if r.nameTaken(supplier) {
+		return nil, model.ErrSupplierNameTaken
+	}
+
+	supplier.CreatedAt = existing.CreatedAt
+	supplier.UpdatedAt = time.Now().UTC()
+
+	r.suppliers[supplier.ID] = supplier.Clone()
+	r.touched[supplier.ID] = time.Now()
+	return supplier.Clone(), nil
+}
+
+// Delete removes a supplier and its links to products
+func (r *MemorySupplierRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.suppliers[id]; !exists {
+		return model.ErrSupplierNotFound
+	}
+	r.deleteUnsafe(id)
+	return nil
+}
+
+// GetByProduct retrieves the links of a product to its suppliers, oldest
+// first
+func (r *MemorySupplierRepository) GetByProduct(ctx context.Context, productID string) ([]*model.ProductSupplier, error) {
+	return r.filterLinks(func(key linkKey) bool {
+		return key.productID == productID
+	}), nil
+}
+
+// GetBySupplier retrieves the links of a supplier to the products it
+// supplies, oldest first
+func (r *MemorySupplierRepository) GetBySupplier(ctx context.Context, supplierID string) ([]*model.ProductSupplier, error) {
+	return r.filterLinks(func(key linkKey) bool {
+		return key.supplierID == supplierID
+	}), nil
+}
+
+// SaveLink links a product to a supplier, or replaces their link, keeping
+// its creation time. The supplier must exist.
+func (r *MemorySupplierRepository) SaveLink(ctx context.Context, link *model.ProductSupplier) (*model.ProductSupplier, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.suppliers[link.SupplierID]; !exists {
+		return nil, model.ErrSupplierNotFound
+	}
+
+	link = link.Clone()
+	key := linkKey{productID: link.ProductID, supplierID: link.SupplierID}
+	now := time.Now().UTC()
+	link.CreatedAt = now
+	if existing, exists := r.links[key]; exists {
+		link.CreatedAt = existing.CreatedAt
+	}
+	link.UpdatedAt = now
+
+	r.links[key] = link
+	r.linksTouched[key] = time.Now()
+	return link.Clone(), nil
+}
+
+// DeleteLink removes the link between a product and a supplier
+func (r *MemorySupplierRepository) DeleteLink(ctx context.Context, productID, supplierID string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := linkKey{productID: productID, supplierID: supplierID}
+	if _, exists := r.links[key]; !exists {
+		return model.ErrProductSupplierNotFound
+	}
+	delete(r.links, key)
+	delete(r.linksTouched, key)
+	return nil
+}
+
+// PurgeExpired removes suppliers and links written before cutoff, along with
+// the links of purged suppliers. Suppliers have no seed data, so everything
+// expired is dropped.
+func (r *MemorySupplierRepository) PurgeExpired(cutoff time.Time) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, writtenAt := range r.touched {
+		if writtenAt.Before(cutoff) {
+			r.deleteUnsafe(id)
+			purged++
+		}
+	}
+	for key, writtenAt := range r.linksTouched {
+		if writtenAt.Before(cutoff) {
+			delete(r.links, key)
+			delete(r.linksTouched, key)
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// Reset removes all suppliers and links
+func (r *MemorySupplierRepository) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.suppliers = make(map[string]*model.Supplier)
+	r.links = make(map[linkKey]*model.ProductSupplier)
+	r.touched = make(map[string]time.Time)
+	r.linksTouched = make(map[linkKey]time.Time)
+}
+
+// deleteUnsafe removes a supplier and its links. Callers must hold the lock.
+func (r *MemorySupplierRepository) deleteUnsafe(id string) {
+	delete(r.suppliers, id)
+	delete(r.touched, id)
+	for key := range r.links {
+		if key.supplierID == id {
+			delete(r.links, key)
+			delete(r.linksTouched, key)
+		}
+	}
+}
+
+// nameTaken checks if another supplier has the name of supplier. Callers
+// must hold the lock.
+func (r *MemorySupplierRepository) nameTaken(supplier *model.Supplier) bool {
+	name := model.NormalizeSupplierName(supplier.Name)
+	for id, other := range r.suppliers {
+		if id != supplier.ID && model.NormalizeSupplierName(other.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLinks returns copies of the links that match, oldest first
+func (r *MemorySupplierRepository) filterLinks(match func(key linkKey) bool) []*model.ProductSupplier {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	links := make([]*model.ProductSupplier, 0)
+	for key, link := range r.links {
+		if match(key) {
+			links = append(links, link.Clone())
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].CreatedAt.Before(links[j].CreatedAt)
+		}
+		if links[i].ProductID != links[j].ProductID {
+			return links[i].ProductID < links[j].ProductID
+		}
+		return links[i].SupplierID < links[j].SupplierID
+	})
+	return links
+}
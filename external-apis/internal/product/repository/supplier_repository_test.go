@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySupplierRepository_Create(t *testing.T) {
+	// Arrange
+	repo := NewMemorySupplierRepository()
+
+	// Act
+	created, err := repo.Create(context.Background(), &model.Supplier{Name: "Acme Parts", LeadTimeDays: 7})
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+
+	t.Run("Get by ID", func(t *testing.T) {
+		// Act
+		found, err := repo.GetByID(context.Background(), created.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
+	t.Run("Name taken ignoring case", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Supplier{Name: "ACME parts"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierNameTaken)
+	})
+
+	t.Run("Duplicate ID", func(t *testing.T) {
+		// Act
+		_, err := repo.Create(context.Background(), &model.Supplier{ID: created.ID, Name: "Globex"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierExists)
+	})
+}
+
+func TestMemorySupplierRepository_Update(t *testing.T) {
+	t.Run("Replace keeping the creation time and name", func(t *testing.T) {
+		// Arrange
+		repo := NewMemorySupplierRepository()
+		created, err := repo.Create(context.Background(), &model.Supplier{Name: "Acme Parts", LeadTimeDays: 7})
+		require.NoError(t, err)
+
+		// Act
+		updated, err := repo.Update(context.Background(), &model.Supplier{ID: created.ID, Name: "Acme Parts", LeadTimeDays: 10})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 10, updated.LeadTimeDays)
+		assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	})
+
+	t.Run("Name of another supplier", func(t *testing.T) {
+		// Arrange
+		repo := NewMemorySupplierRepository()
+		_, err := repo.Create(context.Background(), &model.Supplier{Name: "Acme Parts"})
+		require.NoError(t, err)
+		globex, err := repo.Create(context.Background(), &model.Supplier{Name: "Globex"})
+		require.NoError(t, err)
+
+		// Act
+		_, err = repo.Update(context.Background(), &model.Supplier{ID: globex.ID, Name: "acme parts"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierNameTaken)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		// Act
+		_, err := NewMemorySupplierRepository().Update(context.Background(), &model.Supplier{ID: "missing", Name: "Acme Parts"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierNotFound)
+	})
+}
+
+func TestMemorySupplierRepository_Links(t *testing.T) {
+	// Arrange
+	repo := NewMemorySupplierRepository()
+	acme, err := repo.Create(context.Background(), &model.Supplier{Name: "Acme Parts"})
+	require.NoError(t, err)
+	globex, err := repo.Create(context.Background(), &model.Supplier{Name: "Globex"})
+	require.NoError(t, err)
+	first, err := repo.SaveLink(context.Background(), &model.ProductSupplier{ProductID: "product-001", SupplierID: acme.ID, CostPrice: money.New(50000, "USD")})
+	require.NoError(t, err)
+	_, err = repo.SaveLink(context.Background(), &model.ProductSupplier{ProductID: "product-001", SupplierID: globex.ID, CostPrice: money.New(52000, "USD")})
+	require.NoError(t, err)
+	_, err = repo.SaveLink(context.Background(), &model.ProductSupplier{ProductID: "product-002", SupplierID: acme.ID, CostPrice: money.New(1500, "USD")})
+	require.NoError(t, err)
+
+	t.Run("Get by product", func(t *testing.T) {
+		// Act
+		links, err := repo.GetByProduct(context.Background(), "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, links, 2)
+		assert.ElementsMatch(t, []string{acme.ID, globex.ID}, []string{links[0].SupplierID, links[1].SupplierID})
+	})
+
+	t.Run("Get by supplier", func(t *testing.T) {
+		// Act
+		links, err := repo.GetBySupplier(context.Background(), acme.ID)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, links, 2)
+		assert.Equal(t, "product-001", links[0].ProductID)
+		assert.Equal(t, "product-002", links[1].ProductID)
+	})
+
+	t.Run("Replace a link keeping its creation time", func(t *testing.T) {
+		// Act
+		updated, err := repo.SaveLink(context.Background(), &model.ProductSupplier{ProductID: "product-001", SupplierID: acme.ID, SupplierSKU: "AC-1", CostPrice: money.New(48000, "USD")})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, first.CreatedAt, updated.CreatedAt)
+		assert.Equal(t, money.New(48000, "USD"), updated.CostPrice)
+		links, err := repo.GetByProduct(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Len(t, links, 2)
+	})
+
+	t.Run("Link to an unknown supplier", func(t *testing.T) {
+		// Act
+		_, err := repo.SaveLink(context.Background(), &model.ProductSupplier{ProductID: "product-001", SupplierID: "missing"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierNotFound)
+	})
+
+	t.Run("Delete a link", func(t *testing.T) {
+		// Act
+		err := repo.DeleteLink(context.Background(), "product-001", globex.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.ErrorIs(t, repo.DeleteLink(context.Background(), "product-001", globex.ID), model.ErrProductSupplierNotFound)
+	})
+
+	t.Run("Deleting a supplier removes its links", func(t *testing.T) {
+		// Act
+		err := repo.Delete(context.Background(), acme.ID)
+
+		// Assert
+		require.NoError(t, err)
+		links, err := repo.GetByProduct(context.Background(), "product-002")
+		require.NoError(t, err)
+		assert.Empty(t, links)
+	})
+}
+
+func TestMemorySupplierRepository_PurgeExpired(t *testing.T) {
+	// Arrange
+	repo := NewMemorySupplierRepository()
+	supplier, err := repo.Create(context.Background(), &model.Supplier{Name: "Acme Parts"})
+	require.NoError(t, err)
+	_, err = repo.SaveLink(context.Background(), &model.ProductSupplier{ProductID: "product-001", SupplierID: supplier.ID, CostPrice: money.New(50000, "USD")})
+	require.NoError(t, err)
+
+	// Act
+	purged := repo.PurgeExpired(time.Now().Add(time.Minute))
+
+	// Assert
+	assert.Equal(t, 1, purged)
+	suppliers, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, suppliers)
+	links, err := repo.GetByProduct(context.Background(), "product-001")
+	require.NoError(t, err)
+	assert.Empty(t, links)
+}
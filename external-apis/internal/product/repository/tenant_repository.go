@@ -0,0 +1,642 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/tenant"
+)
+
+// TenantProductRepository implements ProductRepository and SearchRepository
+// with a separate in-memory repository per tenant, selected by the tenant of
+// the request context. Only the default tenant is seeded with sample data.
+type TenantProductRepository struct {
+	partitions *tenant.Partitions[*MemoryProductRepository]
+	// ids generates the IDs of new products of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantProductRepository creates a new tenant-partitioned product repository
+func NewTenantProductRepository() *TenantProductRepository {
+	r := &TenantProductRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(id string) *MemoryProductRepository {
+		var repo *MemoryProductRepository
+		if id == tenant.Default {
+			repo = NewMemoryProductRepository()
+		} else {
+			repo = newEmptyMemoryProductRepository()
+		}
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new products, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantProductRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves a product of the tenant by ID
+func (r *TenantProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// GetBySKU retrieves a product of the tenant by SKU
+func (r *TenantProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	return r.partitions.View(ctx).GetBySKU(ctx, sku)
+}
+
+// GetByGTIN retrieves a product of the tenant by GTIN
+func (r *TenantProductRepository) GetByGTIN(ctx context.Context, gtin string) (*model.Product, error) {
+	return r.partitions.View(ctx).GetByGTIN(ctx, gtin)
+}
+
+// GetAll retrieves all products of the tenant
+func (r *TenantProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
+	return r.partitions.View(ctx).GetAll(ctx)
+}
+
+// GetByIDs retrieves the products of the tenant with the given IDs
+func (r *TenantProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	return r.partitions.View(ctx).GetByIDs(ctx, ids)
+}
+
+// Find retrieves a page of the products of the tenant matching the filter
+func (r *TenantProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	return r.partitions.View(ctx).Find(ctx, filter)
+}
+
+// Search ranks the products of the tenant by relevance to the query
+func (r *TenantProductRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	return r.partitions.View(ctx).Search(ctx, query)
+}
+
+// Create creates a product for the tenant
+func (r *TenantProductRepository) Create(ctx context.Context, product *model.Product) (*model.Product, error) {
+	return r.partitions.For(ctx).Create(ctx, product)
+}
+
+// Update updates a product of the tenant
+func (r *TenantProductRepository) Update(ctx context.Context, id string, product *model.Product) (*model.Product, error) {
+	return r.partitions.For(ctx).Update(ctx, id, product)
+}
+
+// Delete soft-deletes a product of the tenant
+func (r *TenantProductRepository) Delete(ctx context.Context, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, id)
+}
+
+// Restore undoes the soft delete of a product of the tenant
+func (r *TenantProductRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	return r.partitions.For(ctx).Restore(ctx, id)
+}
+
+// ExistsByID checks if a product exists for the tenant
+func (r *TenantProductRepository) ExistsByID(ctx context.Context, id string) bool {
+	return r.partitions.View(ctx).ExistsByID(ctx, id)
+}
+
+// ReserveStock reserves stock of a product of the tenant
+func (r *TenantProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.partitions.For(ctx).ReserveStock(ctx, id, quantity)
+}
+
+// ReleaseStock releases reserved stock of a product of the tenant
+func (r *TenantProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	return r.partitions.For(ctx).ReleaseStock(ctx, id, quantity)
+}
+
+// AdjustStock changes the stock of a product of the tenant
+func (r *TenantProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error) {
+	return r.partitions.For(ctx).AdjustStock(ctx, id, delta)
+}
+
+// RenameCategory copies a category name to the products of the tenant in it
+func (r *TenantProductRepository) RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error) {
+	return r.partitions.For(ctx).RenameCategory(ctx, categoryID, name)
+}
+
+// AddImage attaches an image to a product of the tenant
+func (r *TenantProductRepository) AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error) {
+	return r.partitions.For(ctx).AddImage(ctx, id, image)
+}
+
+// RemoveImage detaches an image from a product of the tenant
+func (r *TenantProductRepository) RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error) {
+	return r.partitions.For(ctx).RemoveImage(ctx, id, imageID)
+}
+
+// AddVariant attaches a variant to a product of the tenant
+func (r *TenantProductRepository) AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.partitions.For(ctx).AddVariant(ctx, id, variant)
+}
+
+// UpdateVariant replaces a variant of a product of the tenant
+func (r *TenantProductRepository) UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	return r.partitions.For(ctx).UpdateVariant(ctx, id, variant)
+}
+
+// RemoveVariant detaches a variant from a product of the tenant
+func (r *TenantProductRepository) RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error) {
+	return r.partitions.For(ctx).RemoveVariant(ctx, id, variantID)
+}
+
+// SetRating replaces the aggregated rating of a product of the tenant
+func (r *TenantProductRepository) SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error) {
+	return r.partitions.For(ctx).SetRating(ctx, id, rating)
+}
+
+// Stats summarizes the products of the tenant
+func (r *TenantProductRepository) Stats(ctx context.Context) (*model.ProductStats, error) {
+	return r.partitions.View(ctx).Stats(ctx)
+}
+
+// Transaction runs fn against a transaction of the tenant's repository
+func (r *TenantProductRepository) Transaction(ctx context.Context, fn func(tx ProductRepository) error) error {
+	return r.partitions.For(ctx).Transaction(ctx, fn)
+}
+
+// Load stores recovered products of the tenant as they are
+func (r *TenantProductRepository) Load(ctx context.Context, products []*model.Product) error {
+	return r.partitions.For(ctx).Load(ctx, products)
+}
+
+// PurgeExpired reverts the expired writes of every tenant
+func (r *TenantProductRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset restores the seed data of every tenant
+func (r *TenantProductRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantCategoryRepository implements CategoryRepository with a separate
+// in-memory repository per tenant, so every tenant has its own category tree
+type TenantCategoryRepository struct {
+	partitions *tenant.Partitions[*MemoryCategoryRepository]
+	// ids generates the IDs of new categories of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantCategoryRepository creates a new tenant-partitioned category repository
+func NewTenantCategoryRepository() *TenantCategoryRepository {
+	r := &TenantCategoryRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(id string) *MemoryCategoryRepository {
+		var repo *MemoryCategoryRepository
+		if id == tenant.Default {
+			repo = NewMemoryCategoryRepository()
+		} else {
+			repo = newEmptyMemoryCategoryRepository()
+		}
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new categories, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantCategoryRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetAll retrieves all categories of the tenant
+func (r *TenantCategoryRepository) GetAll(ctx context.Context) ([]*model.Category, error) {
+	return r.partitions.View(ctx).GetAll(ctx)
+}
+
+// GetByID retrieves a category of the tenant by ID
+func (r *TenantCategoryRepository) GetByID(ctx context.Context, id string) (*model.Category, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// Create creates a category for the tenant
+func (r *TenantCategoryRepository) Create(ctx context.Context, category *model.Category) (*model.Category, error) {
+	return r.partitions.For(ctx).Create(ctx, category)
+}
+
+// Update updates a category of the tenant
+func (r *TenantCategoryRepository) Update(ctx context.Context, category *model.Category) (*model.Category, error) {
+	return r.partitions.For(ctx).Update(ctx, category)
+}
+
+// Delete deletes a category of the tenant
+func (r *TenantCategoryRepository) Delete(ctx context.Context, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, id)
+}
+
+// PurgeExpired reverts the expired writes of every tenant
+func (r *TenantCategoryRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset restores the seed data of every tenant
+func (r *TenantCategoryRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantReservationRepository implements ReservationRepository with a
+// separate in-memory repository per tenant
+type TenantReservationRepository struct {
+	partitions *tenant.Partitions[*MemoryReservationRepository]
+	// ids generates the IDs of new reservations of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantReservationRepository creates a new tenant-partitioned reservation repository
+func NewTenantReservationRepository() *TenantReservationRepository {
+	r := &TenantReservationRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryReservationRepository {
+		repo := NewMemoryReservationRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new reservations, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantReservationRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves a reservation of the tenant by ID
+func (r *TenantReservationRepository) GetByID(ctx context.Context, id string) (*model.Reservation, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// GetByOrderID retrieves the reservations of an order of the tenant
+func (r *TenantReservationRepository) GetByOrderID(ctx context.Context, orderID string) ([]*model.Reservation, error) {
+	return r.partitions.View(ctx).GetByOrderID(ctx, orderID)
+}
+
+// GetExpired retrieves the expired active reservations of the tenant
+func (r *TenantReservationRepository) GetExpired(ctx context.Context, now time.Time) ([]*model.Reservation, error) {
+	return r.partitions.View(ctx).GetExpired(ctx, now)
+}
+
+// Create creates a reservation for the tenant
+func (r *TenantReservationRepository) Create(ctx context.Context, reservation *model.Reservation) (*model.Reservation, error) {
+	return r.partitions.For(ctx).Create(ctx, reservation)
+}
+
+// Transition moves a reservation of the tenant to another status
+func (r *TenantReservationRepository) Transition(ctx context.Context, id string, to model.ReservationStatus, now time.Time) (*model.Reservation, error) {
+	return r.partitions.For(ctx).Transition(ctx, id, to, now)
+}
+
+// Tenants returns the tenants that have made reservations
+func (r *TenantReservationRepository) Tenants() []string {
+	return r.partitions.Tenants()
+}
+
+// PurgeExpired removes the expired writes of every tenant
+func (r *TenantReservationRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the reservations of every tenant
+func (r *TenantReservationRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantScheduledChangeRepository implements ScheduledChangeRepository with a
+// separate in-memory repository per tenant
+type TenantScheduledChangeRepository struct {
+	partitions *tenant.Partitions[*MemoryScheduledChangeRepository]
+	// ids generates the IDs of new scheduled changes of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantScheduledChangeRepository creates a new tenant-partitioned scheduled change repository
+func NewTenantScheduledChangeRepository() *TenantScheduledChangeRepository {
+	r := &TenantScheduledChangeRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryScheduledChangeRepository {
+		repo := NewMemoryScheduledChangeRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new scheduled changes, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantScheduledChangeRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByID retrieves a scheduled change of the tenant by ID
+func (r *TenantScheduledChangeRepository) GetByID(ctx context.Context, id string) (*model.ScheduledChange, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// GetByProductID retrieves the changes scheduled for a product of the tenant
+func (r *TenantScheduledChangeRepository) GetByProductID(ctx context.Context, productID string) ([]*model.ScheduledChange, error) {
+	return r.partitions.View(ctx).GetByProductID(ctx, productID)
+}
+
+// GetDue retrieves the due pending changes of the tenant
+func (r *TenantScheduledChangeRepository) GetDue(ctx context.Context, now time.Time) ([]*model.ScheduledChange, error) {
+	return r.partitions.View(ctx).GetDue(ctx, now)
+}
+
+// Create schedules a change for the tenant
+func (r *TenantScheduledChangeRepository) Create(ctx context.Context, change *model.ScheduledChange) (*model.ScheduledChange, error) {
+	return r.partitions.For(ctx).Create(ctx, change)
+}
+
+// Transition moves a scheduled change of the tenant to another status
+func (r *TenantScheduledChangeRepository) Transition(ctx context.Context, id string, to model.ScheduledChangeStatus, now time.Time, failure string) (*model.ScheduledChange, error) {
+	return r.partitions.For(ctx).Transition(ctx, id, to, now, failure)
+}
+
+// Tenants returns the tenants that have scheduled changes
+func (r *TenantScheduledChangeRepository) Tenants() []string {
+	return r.partitions.Tenants()
+}
+
+// PurgeExpired removes the expired writes of every tenant
+func (r *TenantScheduledChangeRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the scheduled changes of every tenant
+func (r *TenantScheduledChangeRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantPromotionRepository implements PromotionRepository with a separate
+// in-memory repository per tenant
+type TenantPromotionRepository struct {
+	partitions *tenant.Partitions[*MemoryPromotionRepository]
+	// ids generates the IDs of new promotions of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantPromotionRepository creates a new tenant-partitioned promotion repository
+func NewTenantPromotionRepository() *TenantPromotionRepository {
+	r := &TenantPromotionRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryPromotionRepository {
+		repo := NewMemoryPromotionRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new promotions, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantPromotionRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetAll retrieves all promotions of the tenant
+func (r *TenantPromotionRepository) GetAll(ctx context.Context) ([]*model.Promotion, error) {
+	return r.partitions.View(ctx).GetAll(ctx)
+}
+
+// GetByID retrieves a promotion of the tenant by ID
+func (r *TenantPromotionRepository) GetByID(ctx context.Context, id string) (*model.Promotion, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// Create creates a promotion for the tenant
+func (r *TenantPromotionRepository) Create(ctx context.Context, promotion *model.Promotion) (*model.Promotion, error) {
+	return r.partitions.For(ctx).Create(ctx, promotion)
+}
+
+// Update replaces a promotion of the tenant
+func (r *TenantPromotionRepository) Update(ctx context.Context, promotion *model.Promotion) (*model.Promotion, error) {
+	return r.partitions.For(ctx).Update(ctx, promotion)
+}
+
+// Delete removes a promotion of the tenant
+func (r *TenantPromotionRepository) Delete(ctx context.Context, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, id)
+}
+
+// PurgeExpired removes the expired writes of every tenant
+func (r *TenantPromotionRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the promotions of every tenant
+func (r *TenantPromotionRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantReviewRepository implements ReviewRepository with a separate
+// in-memory repository per tenant
+type TenantReviewRepository struct {
+	partitions *tenant.Partitions[*MemoryReviewRepository]
+	// ids generates the IDs of new reviews of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantReviewRepository creates a new tenant-partitioned review repository
+func NewTenantReviewRepository() *TenantReviewRepository {
+	r := &TenantReviewRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryReviewRepository {
+		repo := NewMemoryReviewRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new reviews, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantReviewRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByProduct retrieves the reviews of a product of the tenant
+func (r *TenantReviewRepository) GetByProduct(ctx context.Context, productID string) ([]*model.Review, error) {
+	return r.partitions.View(ctx).GetByProduct(ctx, productID)
+}
+
+// GetByStatus retrieves the reviews of the tenant with a status
+func (r *TenantReviewRepository) GetByStatus(ctx context.Context, status model.ReviewStatus) ([]*model.Review, error) {
+	return r.partitions.View(ctx).GetByStatus(ctx, status)
+}
+
+// GetByID retrieves a review of the tenant by ID
+func (r *TenantReviewRepository) GetByID(ctx context.Context, productID, id string) (*model.Review, error) {
+	return r.partitions.View(ctx).GetByID(ctx, productID, id)
+}
+
+// Create creates a review for the tenant
+func (r *TenantReviewRepository) Create(ctx context.Context, review *model.Review) (*model.Review, error) {
+	return r.partitions.For(ctx).Create(ctx, review)
+}
+
+// Update replaces a review of the tenant
+func (r *TenantReviewRepository) Update(ctx context.Context, review *model.Review) (*model.Review, error) {
+	return r.partitions.For(ctx).Update(ctx, review)
+}
+
+// Delete removes a review of the tenant
+func (r *TenantReviewRepository) Delete(ctx context.Context, productID, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, productID, id)
+}
+
+// PurgeExpired removes the expired writes of every tenant
+func (r *TenantReviewRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the reviews of every tenant
+func (r *TenantReviewRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantSupplierRepository implements SupplierRepository with a separate
+// in-memory repository per tenant
+type TenantSupplierRepository struct {
+	partitions *tenant.Partitions[*MemorySupplierRepository]
+	// ids generates the IDs of new suppliers of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantSupplierRepository creates a new tenant-partitioned supplier repository
+func NewTenantSupplierRepository() *TenantSupplierRepository {
+	r := &TenantSupplierRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemorySupplierRepository {
+		repo := NewMemorySupplierRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new suppliers, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantSupplierRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetAll retrieves all suppliers of the tenant
+func (r *TenantSupplierRepository) GetAll(ctx context.Context) ([]*model.Supplier, error) {
+	return r.partitions.View(ctx).GetAll(ctx)
+}
+
+// GetByID retrieves a supplier of the tenant by ID
+func (r *TenantSupplierRepository) GetByID(ctx context.Context, id string) (*model.Supplier, error) {
+	return r.partitions.View(ctx).GetByID(ctx, id)
+}
+
+// Create creates a supplier for the tenant
+func (r *TenantSupplierRepository) Create(ctx context.Context, supplier *model.Supplier) (*model.Supplier, error) {
+	return r.partitions.For(ctx).Create(ctx, supplier)
+}
+
+// Update replaces a supplier of the tenant
+func (r *TenantSupplierRepository) Update(ctx context.Context, supplier *model.Supplier) (*model.Supplier, error) {
+	return r.partitions.For(ctx).Update(ctx, supplier)
+}
+
+// Delete removes a supplier of the tenant
+func (r *TenantSupplierRepository) Delete(ctx context.Context, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, id)
+}
+
+// GetByProduct retrieves the supplier links of a product of the tenant
+func (r *TenantSupplierRepository) GetByProduct(ctx context.Context, productID string) ([]*model.ProductSupplier, error) {
+	return r.partitions.View(ctx).GetByProduct(ctx, productID)
+}
+
+// GetBySupplier retrieves the product links of a supplier of the tenant
+func (r *TenantSupplierRepository) GetBySupplier(ctx context.Context, supplierID string) ([]*model.ProductSupplier, error) {
+	return r.partitions.View(ctx).GetBySupplier(ctx, supplierID)
+}
+
+// SaveLink links a product of the tenant to a supplier
+func (r *TenantSupplierRepository) SaveLink(ctx context.Context, link *model.ProductSupplier) (*model.ProductSupplier, error) {
+	return r.partitions.For(ctx).SaveLink(ctx, link)
+}
+
+// DeleteLink unlinks a product of the tenant from a supplier
+func (r *TenantSupplierRepository) DeleteLink(ctx context.Context, productID, supplierID string) error {
+	return r.partitions.For(ctx).DeleteLink(ctx, productID, supplierID)
+}
+
+// PurgeExpired removes the expired writes of every tenant
+func (r *TenantSupplierRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the suppliers of every tenant
+func (r *TenantSupplierRepository) Reset() {
+	r.partitions.Reset()
+}
+
+// TenantStockSubscriptionRepository implements StockSubscriptionRepository
+// with a separate in-memory repository per tenant
+type TenantStockSubscriptionRepository struct {
+	partitions *tenant.Partitions[*MemoryStockSubscriptionRepository]
+	// ids generates the IDs of new stock subscriptions of every tenant
+	ids idgen.Generator
+}
+
+// NewTenantStockSubscriptionRepository creates a new tenant-partitioned
+// back-in-stock subscription repository
+func NewTenantStockSubscriptionRepository() *TenantStockSubscriptionRepository {
+	r := &TenantStockSubscriptionRepository{ids: idgen.Default()}
+	r.partitions = tenant.NewPartitions(func(string) *MemoryStockSubscriptionRepository {
+		repo := NewMemoryStockSubscriptionRepository()
+		repo.ids = r.ids
+		return repo
+	})
+	return r
+}
+
+// SetIDs sets the generator of the IDs of new stock subscriptions, UUIDv7s by default.
+// It must be called before the repository is used.
+func (r *TenantStockSubscriptionRepository) SetIDs(ids idgen.Generator) {
+	r.ids = ids
+}
+
+// GetByProduct retrieves the subscriptions to a product of the tenant
+func (r *TenantStockSubscriptionRepository) GetByProduct(ctx context.Context, productID string) ([]*model.StockSubscription, error) {
+	return r.partitions.View(ctx).GetByProduct(ctx, productID)
+}
+
+// GetPending retrieves the pending subscriptions to a product of the tenant
+func (r *TenantStockSubscriptionRepository) GetPending(ctx context.Context, productID string) ([]*model.StockSubscription, error) {
+	return r.partitions.View(ctx).GetPending(ctx, productID)
+}
+
+// HasPending checks if a product of the tenant has pending subscriptions
+func (r *TenantStockSubscriptionRepository) HasPending(ctx context.Context, productID string) bool {
+	return r.partitions.View(ctx).HasPending(ctx, productID)
+}
+
+// Create creates a subscription for the tenant
+func (r *TenantStockSubscriptionRepository) Create(ctx context.Context, subscription *model.StockSubscription) (*model.StockSubscription, bool, error) {
+	return r.partitions.For(ctx).Create(ctx, subscription)
+}
+
+// MarkNotified marks a subscription of the tenant as notified
+func (r *TenantStockSubscriptionRepository) MarkNotified(ctx context.Context, productID, id string, at time.Time) (*model.StockSubscription, error) {
+	return r.partitions.For(ctx).MarkNotified(ctx, productID, id, at)
+}
+
+// Delete removes a subscription of the tenant
+func (r *TenantStockSubscriptionRepository) Delete(ctx context.Context, productID, id string) error {
+	return r.partitions.For(ctx).Delete(ctx, productID, id)
+}
+
+// PurgeExpired removes the expired writes of every tenant
+func (r *TenantStockSubscriptionRepository) PurgeExpired(cutoff time.Time) int {
+	return r.partitions.PurgeExpired(cutoff)
+}
+
+// Reset removes the subscriptions of every tenant
+func (r *TenantStockSubscriptionRepository) Reset() {
+	r.partitions.Reset()
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantProductRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantProductRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+	brandB := tenant.WithTenant(context.Background(), "brand-b")
+	created, err := repo.Create(brandA, &model.Product{
+		Name:          "Brand A Wireless Mug",
+		Price:         money.New(999, "USD"),
+		StockQuantity: 5,
+		Active:        true,
+	})
+	require.NoError(t, err)
+
+	t.Run("Catalogs are separate", func(t *testing.T) {
+		// Act
+		own, err := repo.GetAll(brandA)
+		require.NoError(t, err)
+		other, err := repo.GetAll(brandB)
+		require.NoError(t, err)
+		seeded, err := repo.GetAll(context.Background())
+		require.NoError(t, err)
+
+		// Assert
+		require.Len(t, own, 1)
+		assert.Equal(t, created.ID, own[0].ID)
+		assert.Empty(t, other)
+		assert.NotEmpty(t, seeded)
+		assert.NotContains(t, seeded, created)
+	})
+
+	t.Run("Search only ranks products of the tenant", func(t *testing.T) {
+		// Act
+		hits, total, err := repo.Search(brandB, model.ProductSearch{Query: "wireless", Filter: model.ProductFilter{Page: pagination.Params{Limit: 10}}})
+		require.NoError(t, err)
+		own, ownTotal, err := repo.Search(brandA, model.ProductSearch{Query: "wireless", Filter: model.ProductFilter{Page: pagination.Params{Limit: 10}}})
+		require.NoError(t, err)
+
+		// Assert
+		assert.Empty(t, hits)
+		assert.Zero(t, total)
+		assert.Equal(t, 1, ownTotal)
+		assert.Equal(t, created.ID, own[0].Product.ID)
+	})
+
+	t.Run("Stock of another tenant cannot be reserved", func(t *testing.T) {
+		// Act
+		_, err := repo.ReserveStock(brandB, created.ID, 1)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
+func TestTenantCategoryRepository_Isolation(t *testing.T) {
+	// Arrange
+	repo := NewTenantCategoryRepository()
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+
+	// Act
+	seeded, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	own, err := repo.GetAll(brandA)
+	require.NoError(t, err)
+	created, err := repo.Create(brandA, &model.Category{Name: seeded[0].Name})
+
+	// Assert
+	assert.NotEmpty(t, seeded)
+	assert.Empty(t, own)
+	require.NoError(t, err, "names only need to be unique within a tenant")
+	_, err = repo.GetByID(context.Background(), created.ID)
+	assert.ErrorIs(t, err, model.ErrCategoryNotFound)
+}
+
+func TestTenantProductRepository_SetIDs(t *testing.T) {
+	// Arrange
+	repo := NewTenantProductRepository()
+	ids := idgen.Prefixed("prod_", idgen.ULID())
+	repo.SetIDs(ids)
+	brandA := tenant.WithTenant(context.Background(), "brand-a")
+
+	// Act
+	created, err := repo.Create(brandA, &model.Product{
+		Name:          "Brand A Wireless Mug",
+		Price:         money.New(999, "USD"),
+		StockQuantity: 5,
+		Active:        true,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, ids.Valid(created.ID), created.ID)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/logger"
+)
+
+// BatchGetProducts retrieves several products in one lookup. Every distinct ID
+// is reported, in the order it was requested, as found or not found.
+func (s *productService) BatchGetProducts(ctx context.Context, ids []string) (*batch.Response, error) {
+	ids = batch.UniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, batch.ErrIDsRequired
+	}
+
+	log.Ctx(ctx).WithField("ids", len(ids)).Debug("Batch getting products")
+
+	products, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to batch get products")
+		return nil, err
+	}
+
+	found := make(map[string]interface{}, len(products))
+	for _, product := range products {
+		response := product.ToResponse()
+		found[product.ID] = &response
+	}
+
+	report := &batch.Response{Results: make([]batch.Result, 0, len(ids))}
+	for _, id := range ids {
+		if data, ok := found[id]; ok {
+			report.Found(id, data)
+		} else {
+			report.NotFound(id)
+		}
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"ids":   len(ids),
+		"found": len(found),
+	}).Debug("Successfully batch got products")
+
+	return report, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/events"
+)
+
+// BulkProducts applies a batch of operations in a single transaction. Every
+// operation is attempted so the report covers the whole batch; if any of them
+// fails nothing is persisted and the successful ones are reported as rolled back.
+func (s *productService) BulkProducts(ctx context.Context, req model.BulkProductRequest) (*bulk.Response, error) {
+	log.Ctx(ctx).WithField("operations", len(req.Operations)).Debug("Applying bulk product operations")
+
+	report := &bulk.Response{Results: make([]bulk.Result, 0, len(req.Operations))}
+
+	err := s.repo.Transaction(ctx, func(tx repository.ProductRepository) error {
+		txService := &productService{repo: tx, categories: s.categories, defaultCurrency: s.defaultCurrency}
+
+		for i, op := range req.Operations {
+			// Stop early if the caller gave up; nothing will be committed
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			product, err := txService.applyBulkOperation(ctx, op)
+			if err != nil {
+				report.Failed(i, op.Op, op.ID, err)
+				continue
+			}
+
+			if product == nil {
+				report.Succeeded(i, op.Op, op.ID, nil)
+				continue
+			}
+			report.Succeeded(i, op.Op, product.ID, product)
+		}
+
+		if report.HasFailures() {
+			return bulk.ErrRolledBack
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, bulk.ErrRolledBack) {
+			report.RollBack()
+			log.Ctx(ctx).WithField("operations", len(req.Operations)).Info("Rolled back bulk product operations")
+			return report, nil
+		}
+
+		log.Ctx(ctx).WithError(err).Error("Failed to apply bulk product operations")
+		return nil, err
+	}
+
+	report.Committed = true
+	s.publishBulk(ctx, report)
+	log.Ctx(ctx).WithField("operations", len(req.Operations)).Info("Successfully applied bulk product operations")
+
+	return report, nil
+}
+
+// applyBulkOperation runs a single bulk operation. Deletes return no product.
+func (s *productService) applyBulkOperation(ctx context.Context, op model.BulkProductOperation) (*model.ProductResponse, error) {
+	switch op.Op {
+	case bulk.OpCreate:
+		if op.Create == nil {
+			return nil, bulk.ErrCreatePayloadRequired
+		}
+		return s.CreateProduct(ctx, *op.Create)
+	case bulk.OpUpdate:
+		if op.ID == "" || op.Update == nil {
+			return nil, bulk.ErrUpdatePayloadRequired
+		}
+		return s.UpdateProduct(ctx, op.ID, *op.Update)
+	case bulk.OpDelete:
+		if op.ID == "" {
+			return nil, bulk.ErrIDRequired
+		}
+		return nil, s.DeleteProduct(ctx, op.ID)
+	}
+
+	return nil, bulk.ErrInvalidOperation
+}
+
+// publishBulk sends change events for a committed bulk request. Events are
+// only published after the transaction commits so subscribers never see
+// changes that were rolled back.
+func (s *productService) publishBulk(ctx context.Context, report *bulk.Response) {
+	for _, result := range report.Results {
+		switch result.Op {
+		case bulk.OpCreate:
+			s.publish(ctx, events.ProductCreated, result.ID, result.Data)
+		case bulk.OpUpdate:
+			s.publish(ctx, events.ProductUpdated, result.ID, result.Data)
+		case bulk.OpDelete:
+			s.publish(ctx, events.ProductDeleted, result.ID, map[string]string{"id": result.ID})
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/importer"
+	"external-apis/internal/shared/logger"
+)
+
+// ApplyCatalogUpdate applies a supplier's change to the product with its
+// SKU, creating the product if there is none. It returns whether the
+// product was created or updated, as importer.ResultCreated or
+// importer.ResultUpdated.
+func (s *productService) ApplyCatalogUpdate(ctx context.Context, update model.CatalogUpdate) (string, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"supplier": update.Supplier,
+		"sku":      update.SKU,
+	}).Debug("Applying catalog update")
+
+	existing, err := s.repo.GetBySKU(ctx, update.SKU)
+	if errors.Is(err, model.ErrProductNotFound) {
+		if !update.CanCreate() {
+			return "", model.ErrIncompleteCatalogUpdate
+		}
+		if _, err := s.CreateProduct(ctx, update.CreateRequest()); err != nil {
+			return "", err
+		}
+		return importer.ResultCreated, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	updated, err := s.UpdateProduct(ctx, existing.ID, update.UpdateRequest())
+	if err != nil {
+		return "", err
+	}
+	if update.StockQuantity != nil && *update.StockQuantity != updated.StockQuantity {
+		_, err = s.AdjustStock(ctx, existing.ID, model.AdjustStockRequest{
+			Delta:  *update.StockQuantity - updated.StockQuantity,
+			Reason: "catalog update from " + update.Supplier,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return importer.ResultUpdated, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/importer"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductService_ApplyCatalogUpdate(t *testing.T) {
+	t.Run("Create product missing from the catalog", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		update, err := model.ParseCatalogUpdate([]byte(`{"supplier":"acme","sku":"LAP-1","name":"Laptop","description":"Fast laptop","price":999.99,"categoryId":"category-electronics","stockQuantity":5}`))
+		require.NoError(t, err)
+
+		mockRepo.On("GetBySKU", "LAP-1").Return(nil, model.ErrProductNotFound)
+		mockRepo.On("Create", mock.MatchedBy(func(p *model.Product) bool {
+			return p.SKU == "LAP-1" && p.StockQuantity == 5 && p.Price == money.New(99999, "USD")
+		})).Return(&model.Product{ID: "product-1", SKU: "LAP-1"}, nil)
+
+		// Act
+		result, err := service.ApplyCatalogUpdate(context.Background(), update)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, importer.ResultCreated, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update price and stock of existing product", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		price := json.Number("1299")
+		stock := 8
+		update := model.CatalogUpdate{Supplier: "acme", SKU: "LAP-1", Price: &price, StockQuantity: &stock}
+
+		existing := &model.Product{ID: "product-1", SKU: "LAP-1", Name: "Laptop", Description: "Fast laptop", CategoryID: "category-electronics", Price: money.New(99999, "USD"), StockQuantity: 5}
+		mockRepo.On("GetBySKU", "LAP-1").Return(existing, nil)
+		mockRepo.On("GetByID", "product-1").Return(existing, nil)
+		mockRepo.On("Update", "product-1", mock.MatchedBy(func(p *model.Product) bool {
+			return p.Name == "Laptop" && p.Price == money.New(129900, "USD")
+		})).Return(existing, nil)
+		mockRepo.On("AdjustStock", "product-1", 3).Return(existing, nil)
+
+		// Act
+		result, err := service.ApplyCatalogUpdate(context.Background(), update)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, importer.ResultUpdated, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Partial update of unknown product", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		price := json.Number("10")
+		mockRepo.On("GetBySKU", "LAP-9").Return(nil, model.ErrProductNotFound)
+
+		// Act
+		result, err := service.ApplyCatalogUpdate(context.Background(), model.CatalogUpdate{Supplier: "acme", SKU: "LAP-9", Price: &price})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrIncompleteCatalogUpdate)
+		assert.Empty(t, result)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+)
+
+// CategoryService defines the interface for product category business logic
+type CategoryService interface {
+	GetCategories(ctx context.Context) ([]*model.CategoryResponse, error)
+	GetCategory(ctx context.Context, id string) (*model.CategoryResponse, error)
+	CreateCategory(ctx context.Context, req model.CreateCategoryRequest) (*model.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, id string, req model.UpdateCategoryRequest) (*model.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id string) error
+}
+
+// categoryService implements CategoryService
+type categoryService struct {
+	repo     repository.CategoryRepository
+	products repository.ProductRepository
+	events   events.Publisher
+}
+
+// NewCategoryService creates a new category service. Renaming a category
+// renames it on its products, whose update events go to events, which may be
+// nil.
+func NewCategoryService(repo repository.CategoryRepository, products repository.ProductRepository, events events.Publisher) CategoryService {
+	return &categoryService{
+		repo:     repo,
+		products: products,
+		events:   events,
+	}
+}
+
+// GetCategories retrieves all categories
+func (s *categoryService) GetCategories(ctx context.Context) ([]*model.CategoryResponse, error) {
+	log.Ctx(ctx).Debug("Getting all categories")
+
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get categories")
+		return nil, err
+	}
+
+	tree := model.NewCategoryTree(categories)
+	responses := make([]*model.CategoryResponse, len(categories))
+	for i, category := range categories {
+		response := category.ToResponse(tree.Path(category.ID))
+		responses[i] = &response
+	}
+
+	return responses, nil
+}
+
+// GetCategory retrieves a category by ID
+func (s *categoryService) GetCategory(ctx context.Context, id string) (*model.CategoryResponse, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toResponse(ctx, category)
+}
+
+// CreateCategory creates a new category
+func (s *categoryService) CreateCategory(ctx context.Context, req model.CreateCategoryRequest) (*model.CategoryResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"name":      req.Name,
+		"parent_id": req.ParentID,
+	}).Debug("Creating category")
+
+	category := &model.Category{
+		Name:        req.Name,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	}
+	category.Normalize()
+
+	if category.Name == "" {
+		return nil, model.ErrCategoryNameRequired
+	}
+	attributes, err := model.NormalizeAttributeSchema(req.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	category.Attributes = attributes
+
+	createdCategory, err := s.repo.Create(ctx, category)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to create category")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithField("category_id", createdCategory.ID).Info("Successfully created category")
+	return s.toResponse(ctx, createdCategory)
+}
+
+// UpdateCategory updates an existing category. A new name is copied to the
+// category's products so they keep showing and matching it.
+func (s *categoryService) UpdateCategory(ctx context.Context, id string, req model.UpdateCategoryRequest) (*model.CategoryResponse, error) {
+	log.Ctx(ctx).WithField("category_id", id).Debug("Updating category")
+
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previousName := category.Name
+
+	if req.Name != nil {
+		category.Name = *req.Name
+	}
+	if req.Description != nil {
+		category.Description = *req.Description
+	}
+	if req.ParentID != nil {
+		category.ParentID = *req.ParentID
+	}
+	if req.Attributes != nil {
+		attributes, err := model.NormalizeAttributeSchema(*req.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		category.Attributes = attributes
+	}
+	category.Normalize()
+
+	if category.Name == "" {
+		return nil, model.ErrCategoryNameRequired
+	}
+
+	updatedCategory, err := s.repo.Update(ctx, category)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("category_id", id).Error("Failed to update category")
+		return nil, err
+	}
+
+	if updatedCategory.Name != previousName {
+		if err := s.renameProducts(ctx, updatedCategory); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Ctx(ctx).WithField("category_id", id).Info("Successfully updated category")
+	return s.toResponse(ctx, updatedCategory)
+}
+
+// DeleteCategory deletes a category that has neither subcategories nor
+// products, including soft-deleted products that could be restored
+func (s *categoryService) DeleteCategory(ctx context.Context, id string) error {
+	log.Ctx(ctx).WithField("category_id", id).Debug("Deleting category")
+
+	_, total, err := s.products.Find(ctx, model.ProductFilter{
+		CategoryIDs:    []string{id},
+		IncludeDeleted: true,
+		Page:           pagination.Params{Limit: 1},
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("category_id", id).Error("Failed to count category products")
+		return err
+	}
+	if total > 0 {
+		return model.ErrCategoryInUse
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("category_id", id).Error("Failed to delete category")
+		return err
+	}
+
+	log.Ctx(ctx).WithField("category_id", id).Info("Successfully deleted category")
+	return nil
+}
+
+// renameProducts copies the category name to its products and publishes
+// their updates
+func (s *categoryService) renameProducts(ctx context.Context, category *model.Category) error {
+	products, err := s.products.RenameCategory(ctx, category.ID, category.Name)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("category_id", category.ID).Error("Failed to rename category on products")
+		return err
+	}
+
+	if s.events != nil {
+		for _, product := range products {
+			s.events.Publish(events.New(events.ProductUpdated, product.ID, product.ToResponse()).In(ctx))
+		}
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"category_id": category.ID,
+		"products":    len(products),
+	}).Info("Renamed category on products")
+	return nil
+}
+
+// toResponse converts a category to its response including its path
+func (s *categoryService) toResponse(ctx context.Context, category *model.Category) (*model.CategoryResponse, error) {
+	path := []string{category.Name}
+	if category.ParentID != "" {
+		categories, err := s.repo.GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		path = model.NewCategoryTree(categories).Path(category.ID)
+	}
+
+	response := category.ToResponse(path)
+	return &response, nil
+}
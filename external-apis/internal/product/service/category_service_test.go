@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCategoryRepository is a mock implementation of CategoryRepository
+type MockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockCategoryRepository) GetAll(ctx context.Context) ([]*model.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]*model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) GetByID(ctx context.Context, id string) (*model.Category, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Create(ctx context.Context, category *model.Category) (*model.Category, error) {
+	args := m.Called(category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Update(ctx context.Context, category *model.Category) (*model.Category, error) {
+	args := m.Called(category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Category), args.Error(1)
+}
+
+func (m *MockCategoryRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// newMockCategories returns a category repository that knows only the
+// Electronics category
+func newMockCategories() *MockCategoryRepository {
+	categories := new(MockCategoryRepository)
+	categories.On("GetByID", "category-electronics").Return(&model.Category{ID: "category-electronics", Name: "Electronics"}, nil).Maybe()
+	categories.On("GetByID", mock.Anything).Return(nil, model.ErrCategoryNotFound).Maybe()
+	return categories
+}
+
+func TestCategoryService_CreateCategory(t *testing.T) {
+	t.Run("Create subcategory with its path", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		service := NewCategoryService(mockRepo, new(MockProductRepository), nil)
+
+		created := &model.Category{ID: "category-laptops", Name: "Laptops", ParentID: "category-computers"}
+		mockRepo.On("Create", mock.MatchedBy(func(c *model.Category) bool {
+			return c.Name == "Laptops" && c.ParentID == "category-computers"
+		})).Return(created, nil)
+		mockRepo.On("GetAll").Return([]*model.Category{
+			{ID: "category-electronics", Name: "Electronics"},
+			{ID: "category-computers", Name: "Computers", ParentID: "category-electronics"},
+			created,
+		}, nil)
+
+		// Act
+		result, err := service.CreateCategory(context.Background(), model.CreateCategoryRequest{Name: " Laptops ", ParentID: "category-computers"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "category-laptops", result.ID)
+		assert.Equal(t, []string{"Electronics", "Computers", "Laptops"}, result.Path)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Blank name", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		service := NewCategoryService(mockRepo, new(MockProductRepository), nil)
+
+		// Act
+		result, err := service.CreateCategory(context.Background(), model.CreateCategoryRequest{Name: "   "})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Invalid attribute schema", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		service := NewCategoryService(mockRepo, new(MockProductRepository), nil)
+
+		// Act
+		result, err := service.CreateCategory(context.Background(), model.CreateCategoryRequest{
+			Name:       "Laptops",
+			Attributes: []model.AttributeSchema{{Key: "ram", Type: model.AttributeNumber}, {Key: "RAM", Type: model.AttributeNumber}},
+		})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.EqualError(t, err, `attribute "ram" is declared more than once`)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestCategoryService_UpdateCategory(t *testing.T) {
+	t.Run("Rename category on its products", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		mockProducts := new(MockProductRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewCategoryService(mockRepo, mockProducts, publisher)
+
+		name := "Consumer Electronics"
+		renamed := &model.Category{ID: "category-electronics", Name: name}
+		mockRepo.On("GetByID", "category-electronics").Return(&model.Category{ID: "category-electronics", Name: "Electronics"}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(c *model.Category) bool {
+			return c.Name == name
+		})).Return(renamed, nil)
+		mockProducts.On("RenameCategory", "category-electronics", name).Return([]*model.Product{
+			{ID: "product-123", CategoryID: "category-electronics", Category: name, Price: money.New(1000, "USD")},
+		}, nil)
+
+		// Act
+		result, err := service.UpdateCategory(context.Background(), "category-electronics", model.UpdateCategoryRequest{Name: &name})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{name}, result.Path)
+		published := publisher.Events()
+		require.Len(t, published, 1)
+		assert.Equal(t, events.ProductUpdated, published[0].Type)
+		assert.Equal(t, "product-123", published[0].Subject)
+		mockRepo.AssertExpectations(t)
+		mockProducts.AssertExpectations(t)
+	})
+
+	t.Run("Leave products alone when the name is unchanged", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		mockProducts := new(MockProductRepository)
+		service := NewCategoryService(mockRepo, mockProducts, nil)
+
+		description := "Gadgets"
+		category := &model.Category{ID: "category-electronics", Name: "Electronics", Description: description}
+		mockRepo.On("GetByID", "category-electronics").Return(&model.Category{ID: "category-electronics", Name: "Electronics"}, nil)
+		mockRepo.On("Update", mock.AnythingOfType("*model.Category")).Return(category, nil)
+
+		// Act
+		result, err := service.UpdateCategory(context.Background(), "category-electronics", model.UpdateCategoryRequest{Description: &description})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, description, result.Description)
+		mockProducts.AssertNotCalled(t, "RenameCategory", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Replace the attribute schema", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		service := NewCategoryService(mockRepo, new(MockProductRepository), nil)
+
+		attributes := []model.AttributeSchema{{Key: " Wireless ", Type: model.AttributeBoolean}}
+		mockRepo.On("GetByID", "category-accessories").Return(&model.Category{
+			ID: "category-accessories", Name: "Accessories",
+			Attributes: []model.AttributeSchema{{Key: "color", Type: model.AttributeString}},
+		}, nil)
+		mockRepo.On("Update", mock.MatchedBy(func(c *model.Category) bool {
+			return len(c.Attributes) == 1 && c.Attributes[0].Key == "wireless"
+		})).Return(&model.Category{ID: "category-accessories", Name: "Accessories", Attributes: []model.AttributeSchema{{Key: "wireless", Type: model.AttributeBoolean}}}, nil)
+
+		// Act
+		result, err := service.UpdateCategory(context.Background(), "category-accessories", model.UpdateCategoryRequest{Attributes: &attributes})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []model.AttributeSchema{{Key: "wireless", Type: model.AttributeBoolean}}, result.Attributes)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCategoryService_DeleteCategory(t *testing.T) {
+	filter := model.ProductFilter{
+		CategoryIDs:    []string{"category-electronics"},
+		IncludeDeleted: true,
+		Page:           pagination.Params{Limit: 1},
+	}
+
+	t.Run("Delete unused category", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		mockProducts := new(MockProductRepository)
+		service := NewCategoryService(mockRepo, mockProducts, nil)
+
+		mockProducts.On("Find", filter).Return([]*model.Product{}, 0, nil)
+		mockRepo.On("Delete", "category-electronics").Return(nil)
+
+		// Act
+		err := service.DeleteCategory(context.Background(), "category-electronics")
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockProducts.AssertExpectations(t)
+	})
+
+	t.Run("Category with products", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockCategoryRepository)
+		mockProducts := new(MockProductRepository)
+		service := NewCategoryService(mockRepo, mockProducts, nil)
+
+		mockProducts.On("Find", filter).Return([]*model.Product{{ID: "product-123"}}, 4, nil)
+
+		// Act
+		err := service.DeleteCategory(context.Background(), "category-electronics")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrCategoryInUse)
+		assert.ErrorIs(t, err, apperror.ErrConflict)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
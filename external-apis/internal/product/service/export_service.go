@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/pagination"
+)
+
+// ExportProducts passes every product matching the filter to fn, in the
+// order of the filter's sort. Products are read a page at a time so the
+// export never holds the whole catalog; the page of the filter is ignored.
+// It stops at the first error fn returns.
+func (s *productService) ExportProducts(ctx context.Context, filter model.ProductFilter, fn func(*model.ProductResponse) error) error {
+	log.Ctx(ctx).WithField("sort", filter.Sort).Debug("Exporting products")
+
+	if !model.IsValidProductSort(filter.Sort) {
+		return model.ErrInvalidSort
+	}
+
+	filter, err := s.withSubcategories(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	exported := 0
+	filter.Page = pagination.Params{Limit: pagination.MaxLimit}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		products, total, err := s.repo.Find(ctx, filter)
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithField("exported", exported).Error("Failed to export products")
+			return err
+		}
+
+		for _, product := range products {
+			response := product.ToResponse()
+			if err := fn(&response); err != nil {
+				return err
+			}
+		}
+		exported += len(products)
+
+		filter.Page.Offset += filter.Page.Limit
+		if len(products) == 0 || filter.Page.Offset >= total {
+			break
+		}
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"exported": exported,
+	}).Debug("Successfully exported products")
+	return nil
+}
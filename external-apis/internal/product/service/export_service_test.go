@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductService_ExportProducts(t *testing.T) {
+	t.Run("Read every page of matching products", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		active := true
+		filter := model.ProductFilter{Active: &active, Sort: "name", Page: pagination.Params{Limit: 10, Offset: 20}}
+		firstPage := make([]*model.Product, pagination.MaxLimit)
+		for i := range firstPage {
+			firstPage[i] = &model.Product{ID: "product-1", Price: money.New(1000, "USD")}
+		}
+
+		firstFilter := filter
+		firstFilter.Page = pagination.Params{Limit: pagination.MaxLimit}
+		secondFilter := filter
+		secondFilter.Page = pagination.Params{Limit: pagination.MaxLimit, Offset: pagination.MaxLimit}
+		mockRepo.On("Find", firstFilter).Return(firstPage, pagination.MaxLimit+1, nil)
+		mockRepo.On("Find", secondFilter).Return([]*model.Product{{ID: "product-2", Price: money.New(250, "USD")}}, pagination.MaxLimit+1, nil)
+
+		// Act
+		var exported []*model.ProductResponse
+		err := service.ExportProducts(context.Background(), filter, func(product *model.ProductResponse) error {
+			exported = append(exported, product)
+			return nil
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, exported, pagination.MaxLimit+1)
+		assert.Equal(t, "product-2", exported[pagination.MaxLimit].ID)
+		assert.Equal(t, "2.50", exported[pagination.MaxLimit].Price.String())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stop at the first write error", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		writeErr := errors.New("connection reset")
+
+		mockRepo.On("Find", mock.Anything).Return([]*model.Product{
+			{ID: "product-1", Price: money.New(1000, "USD")},
+			{ID: "product-2", Price: money.New(1000, "USD")},
+		}, 2, nil)
+
+		// Act
+		calls := 0
+		err := service.ExportProducts(context.Background(), model.ProductFilter{}, func(*model.ProductResponse) error {
+			calls++
+			return writeErr
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, writeErr)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Invalid sort option", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		// Act
+		err := service.ExportProducts(context.Background(), model.ProductFilter{Sort: "unknown"}, func(*model.ProductResponse) error {
+			return nil
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidSort)
+		mockRepo.AssertNotCalled(t, "Find", mock.Anything)
+	})
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/storage"
+)
+
+// DefaultMaxImageSize is the largest image accepted when no limit is configured
+const DefaultMaxImageSize = 5 << 20
+
+// ImageService defines the interface for product image business logic
+type ImageService interface {
+	GetImages(ctx context.Context, productID string) ([]model.ProductImageResponse, error)
+	UploadImage(ctx context.Context, productID string, file io.Reader) (*model.ProductImageResponse, error)
+	DeleteImage(ctx context.Context, productID, imageID string) error
+	MaxSize() int64
+}
+
+// imageService implements ImageService
+type imageService struct {
+	repo    repository.ProductRepository
+	storage storage.Storage
+	maxSize int64
+	ids     idgen.Generator
+	events  events.Publisher
+}
+
+// NewImageService creates a new image service storing image files in storage
+// and giving new images IDs of ids. Images larger than maxSize bytes are
+// rejected; a limit of zero falls back to DefaultMaxImageSize. Product update
+// events go to events, which may be nil.
+func NewImageService(repo repository.ProductRepository, storage storage.Storage, maxSize int64, ids idgen.Generator, events events.Publisher) ImageService {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxImageSize
+	}
+
+	return &imageService{
+		repo:    repo,
+		storage: storage,
+		maxSize: maxSize,
+		ids:     ids,
+		events:  events,
+	}
+}
+
+// MaxSize returns the size of the largest image accepted, in bytes
+func (s *imageService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// GetImages retrieves the images of a product
+func (s *imageService) GetImages(ctx context.Context, productID string) ([]model.ProductImageResponse, error) {
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return product.ToResponse().Images, nil
+}
+
+// UploadImage validates an image, stores it and attaches it to the product.
+// The content type is detected from the image data rather than trusted from
+// the client.
+func (s *imageService) UploadImage(ctx context.Context, productID string, file io.Reader) (*model.ProductImageResponse, error) {
+	log.Ctx(ctx).WithField("product_id", productID).Debug("Uploading product image")
+
+	if !s.repo.ExistsByID(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+
+	// Read one byte past the limit to tell a full-size image from a larger one
+	data, err := io.ReadAll(io.LimitReader(file, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, model.ErrImageRequired
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, model.ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, supported := model.ImageExtensions[contentType]
+	if !supported {
+		return nil, model.ErrUnsupportedImageType
+	}
+
+	image := model.ProductImage{
+		ID:          s.ids.New(),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now().UTC(),
+	}
+	image.Key = "products/" + productID + "/" + image.ID + extension
+	image.URL = s.storage.URL(image.Key)
+
+	if err := s.storage.Put(ctx, image.Key, contentType, data); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", productID).Error("Failed to store product image")
+		return nil, err
+	}
+
+	product, err := s.repo.AddImage(ctx, productID, image)
+	if err != nil {
+		// The product may have been deleted while the image was uploading
+		s.deleteFile(ctx, image.Key)
+		return nil, err
+	}
+
+	s.publish(ctx, product)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"image_id":   image.ID,
+		"size":       image.Size,
+	}).Info("Successfully uploaded product image")
+
+	response := image.ToResponse()
+	return &response, nil
+}
+
+// DeleteImage detaches an image from the product and removes its file
+func (s *imageService) DeleteImage(ctx context.Context, productID, imageID string) error {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"image_id":   imageID,
+	}).Debug("Deleting product image")
+
+	existing, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return err
+	}
+	image, found := existing.FindImage(imageID)
+	if !found {
+		return model.ErrImageNotFound
+	}
+
+	product, err := s.repo.RemoveImage(ctx, productID, imageID)
+	if err != nil {
+		return err
+	}
+
+	// The image is already detached, so a leftover file only wastes space
+	s.deleteFile(ctx, image.Key)
+
+	s.publish(ctx, product)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"image_id":   imageID,
+	}).Info("Successfully deleted product image")
+	return nil
+}
+
+// deleteFile removes an image file, logging rather than returning failures
+func (s *imageService) deleteFile(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("key", key).Warn("Failed to delete image file")
+	}
+}
+
+// publish announces the product with its changed images
+func (s *imageService) publish(ctx context.Context, product *model.Product) {
+	if s.events != nil {
+		s.events.Publish(events.New(events.ProductUpdated, product.ID, product.ToResponse()).In(ctx))
+	}
+}
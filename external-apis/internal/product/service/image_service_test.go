@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader is enough of a PNG file for its content type to be detected
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newTestStorage(t *testing.T) (*storage.Local, string) {
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir, "http://localhost:3001/media")
+	require.NoError(t, err)
+	return store, dir
+}
+
+func TestImageService_UploadImage(t *testing.T) {
+	t.Run("Store and attach a PNG image", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		store, dir := newTestStorage(t)
+		publisher := events.NewMemoryPublisher()
+		service := NewImageService(mockRepo, store, 1024, idgen.Default(), publisher)
+
+		mockRepo.On("ExistsByID", "product-123").Return(true)
+		mockRepo.On("AddImage", "product-123", mock.MatchedBy(func(image model.ProductImage) bool {
+			return image.ContentType == "image/png" && image.Size == int64(len(pngHeader))
+		})).Return(&model.Product{ID: "product-123", Price: money.New(1000, "USD")}, nil)
+
+		// Act
+		result, err := service.UploadImage(context.Background(), "product-123", bytes.NewReader(pngHeader))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", result.ContentType)
+		assert.Equal(t, "http://localhost:3001/media/products/product-123/"+result.ID+".png", result.URL)
+
+		data, err := os.ReadFile(filepath.Join(dir, "products", "product-123", result.ID+".png"))
+		require.NoError(t, err)
+		assert.Equal(t, pngHeader, data)
+
+		published := publisher.Events()
+		require.Len(t, published, 1)
+		assert.Equal(t, events.ProductUpdated, published[0].Type)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "Empty file", data: nil, err: model.ErrImageRequired},
+		{name: "Image too large", data: append(append([]byte{}, pngHeader...), make([]byte, 1024)...), err: model.ErrImageTooLarge},
+		{name: "Unsupported type", data: []byte("%PDF-1.7 not an image"), err: model.ErrUnsupportedImageType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockProductRepository)
+			store, dir := newTestStorage(t)
+			service := NewImageService(mockRepo, store, 1024, idgen.Default(), nil)
+
+			mockRepo.On("ExistsByID", "product-123").Return(true)
+
+			// Act
+			result, err := service.UploadImage(context.Background(), "product-123", bytes.NewReader(tt.data))
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, tt.err)
+			entries, _ := os.ReadDir(dir)
+			assert.Empty(t, entries)
+			mockRepo.AssertNotCalled(t, "AddImage", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Remove the file when the product disappears", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		store, dir := newTestStorage(t)
+		service := NewImageService(mockRepo, store, 1024, idgen.Default(), nil)
+
+		mockRepo.On("ExistsByID", "product-123").Return(true)
+		mockRepo.On("AddImage", "product-123", mock.AnythingOfType("model.ProductImage")).Return(nil, model.ErrProductNotFound)
+
+		// Act
+		result, err := service.UploadImage(context.Background(), "product-123", bytes.NewReader(pngHeader))
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+		entries, err := os.ReadDir(filepath.Join(dir, "products", "product-123"))
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestImageService_DeleteImage(t *testing.T) {
+	t.Run("Detach the image and delete its file", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		store, dir := newTestStorage(t)
+		service := NewImageService(mockRepo, store, 1024, idgen.Default(), nil)
+
+		image := model.ProductImage{ID: "image-1", Key: "products/product-123/image-1.png"}
+		require.NoError(t, store.Put(context.Background(), image.Key, "image/png", pngHeader))
+		mockRepo.On("GetByID", "product-123").Return(&model.Product{ID: "product-123", Images: []model.ProductImage{image}}, nil)
+		mockRepo.On("RemoveImage", "product-123", "image-1").Return(&model.Product{ID: "product-123"}, nil)
+
+		// Act
+		err := service.DeleteImage(context.Background(), "product-123", "image-1")
+
+		// Assert
+		require.NoError(t, err)
+		_, err = os.Stat(filepath.Join(dir, "products", "product-123", "image-1.png"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown image", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		store, _ := newTestStorage(t)
+		service := NewImageService(mockRepo, store, 1024, idgen.Default(), nil)
+
+		mockRepo.On("GetByID", "product-123").Return(&model.Product{ID: "product-123"}, nil)
+
+		// Act
+		err := service.DeleteImage(context.Background(), "product-123", "image-1")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrImageNotFound)
+		mockRepo.AssertNotCalled(t, "RemoveImage", mock.Anything, mock.Anything)
+	})
+}
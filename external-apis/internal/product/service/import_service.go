@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/importer"
+	"external-apis/internal/shared/logger"
+)
+
+// ImportProducts reads products from a CSV or NDJSON file as it arrives and
+// imports them row by row. In create mode every row creates a product; in
+// upsert mode a row updates the product with its SKU, creating it if there
+// is none. Rows are independent: a rejected row is reported and the import
+// carries on, keeping the rows imported before and after it.
+func (s *productService) ImportProducts(ctx context.Context, format, mode string, file io.Reader) (*importer.Report, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"format": format,
+		"mode":   mode,
+	}).Debug("Importing products")
+
+	if !importer.IsValidMode(mode) {
+		return nil, importer.ErrInvalidMode
+	}
+
+	reader, err := importer.NewReader(format, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := importer.CheckColumns(reader.Header(), model.ImportProductRow{}); err != nil {
+		return nil, err
+	}
+
+	report := importer.NewReport()
+	for {
+		// Rows imported so far are kept; the caller is told the import stopped
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *importer.RowError
+		if errors.As(err, &rowErr) {
+			report.Fail(rowErr.Line, err)
+			continue
+		}
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithField("rows", report.Rows).Warn("Failed to read product import file")
+			return nil, err
+		}
+
+		var product model.ImportProductRow
+		if err := row.Decode(&product); err != nil {
+			report.Fail(row.Line, err)
+			continue
+		}
+
+		result, err := s.importProduct(ctx, mode, product)
+		if err != nil {
+			report.Fail(row.Line, err)
+			continue
+		}
+		report.Succeeded(result)
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"rows":    report.Rows,
+		"created": report.Created,
+		"updated": report.Updated,
+		"failed":  report.Failed,
+	}).Info("Successfully imported products")
+	return report, nil
+}
+
+// importProduct creates or, in upsert mode, updates the product of a row
+func (s *productService) importProduct(ctx context.Context, mode string, row model.ImportProductRow) (string, error) {
+	row.SKU = strings.TrimSpace(row.SKU)
+
+	if mode == importer.ModeUpsert {
+		if row.SKU == "" {
+			return "", model.ErrSKURequired
+		}
+
+		existing, err := s.repo.GetBySKU(ctx, row.SKU)
+		if err == nil {
+			return importer.ResultUpdated, s.updateImportedProduct(ctx, existing, row)
+		}
+		if !errors.Is(err, model.ErrProductNotFound) {
+			return "", err
+		}
+	}
+
+	if _, err := s.CreateProduct(ctx, row.CreateRequest()); err != nil {
+		return "", err
+	}
+	return importer.ResultCreated, nil
+}
+
+// updateImportedProduct applies a row to an existing product. A stock
+// quantity is reached by adjusting the stock, so reservations are respected.
+func (s *productService) updateImportedProduct(ctx context.Context, existing *model.Product, row model.ImportProductRow) error {
+	updated, err := s.UpdateProduct(ctx, existing.ID, row.UpdateRequest())
+	if err != nil {
+		return err
+	}
+
+	if row.StockQuantity == nil || *row.StockQuantity == updated.StockQuantity {
+		return nil
+	}
+	_, err = s.AdjustStock(ctx, existing.ID, model.AdjustStockRequest{
+		Delta:  *row.StockQuantity - updated.StockQuantity,
+		Reason: "import",
+	})
+	return err
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/importer"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductService_ImportProducts(t *testing.T) {
+	validation.Register()
+
+	t.Run("Create products from CSV and report rejected rows", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		file := "sku,name,description,price,categoryId,stockQuantity\n" +
+			"LAP-1,Laptop,Fast laptop,999.99,category-electronics,5\n" +
+			"LAP-2,,Nameless,10,category-electronics,\n" +
+			"LAP-3,Tablet,Slim tablet,299,category-unknown,\n"
+
+		mockRepo.On("Create", mock.MatchedBy(func(p *model.Product) bool {
+			return p.SKU == "LAP-1" && p.StockQuantity == 5 && p.Price == money.New(99999, "USD")
+		})).Return(&model.Product{ID: "product-1", SKU: "LAP-1"}, nil)
+
+		// Act
+		report, err := service.ImportProducts(context.Background(), importer.FormatCSV, importer.ModeCreate, strings.NewReader(file))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Rows)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 2, report.Failed)
+		require.Len(t, report.Errors, 2)
+		assert.Equal(t, 3, report.Errors[0].Line)
+		assert.Equal(t, "name", report.Errors[0].Fields[0].Field)
+		assert.Equal(t, importer.RowError{Line: 4, Message: model.ErrUnknownCategory.Error()}, report.Errors[1])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Upsert products from NDJSON by SKU", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		file := `{"sku":"LAP-1","name":"Laptop Pro","description":"Faster laptop","price":1299,"categoryId":"category-electronics","stockQuantity":8}` + "\n" +
+			`{"sku":"LAP-2","name":"Dock","description":"USB-C dock","price":"89.50","categoryId":"category-electronics"}` + "\n" +
+			`{"name":"Cable","description":"No SKU","price":5,"categoryId":"category-electronics"}` + "\n"
+
+		existing := &model.Product{ID: "product-1", SKU: "LAP-1", Name: "Laptop", Price: money.New(99999, "USD"), StockQuantity: 5, ReservedQuantity: 2}
+		mockRepo.On("GetBySKU", "LAP-1").Return(existing, nil)
+		mockRepo.On("GetBySKU", "LAP-2").Return(nil, model.ErrProductNotFound)
+		mockRepo.On("GetByID", "product-1").Return(existing, nil)
+		mockRepo.On("Update", "product-1", mock.MatchedBy(func(p *model.Product) bool {
+			return p.Name == "Laptop Pro" && p.Price == money.New(129900, "USD")
+		})).Return(existing, nil)
+		mockRepo.On("AdjustStock", "product-1", 3).Return(existing, nil)
+		mockRepo.On("Create", mock.MatchedBy(func(p *model.Product) bool {
+			return p.SKU == "LAP-2" && p.Price == money.New(8950, "USD")
+		})).Return(&model.Product{ID: "product-2", SKU: "LAP-2"}, nil)
+
+		// Act
+		report, err := service.ImportProducts(context.Background(), importer.FormatNDJSON, importer.ModeUpsert, strings.NewReader(file))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, []importer.RowError{{Line: 3, Message: model.ErrSKURequired.Error()}}, report.Errors)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name   string
+		format string
+		mode   string
+		file   string
+		err    string
+	}{
+		{name: "Unknown column", format: importer.FormatCSV, mode: importer.ModeCreate, file: "sku,name,colour\n", err: `unknown column "colour"`},
+		{name: "Invalid mode", format: importer.FormatCSV, mode: "replace", file: "sku\n", err: importer.ErrInvalidMode.Error()},
+		{name: "Unsupported format", format: "xml", mode: importer.ModeCreate, file: "<products/>", err: importer.ErrUnsupportedFormat.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockProductRepository)
+			service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+			// Act
+			report, err := service.ImportProducts(context.Background(), tt.format, tt.mode, strings.NewReader(tt.file))
+
+			// Assert
+			assert.Nil(t, report)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
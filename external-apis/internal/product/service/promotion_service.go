@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+)
+
+// PromotionService defines the interface for promotions and price quotes
+type PromotionService interface {
+	GetPromotions(ctx context.Context) ([]*model.PromotionResponse, error)
+	GetPromotion(ctx context.Context, id string) (*model.PromotionResponse, error)
+	CreatePromotion(ctx context.Context, req model.PromotionRequest) (*model.PromotionResponse, error)
+	UpdatePromotion(ctx context.Context, id string, req model.PromotionRequest) (*model.PromotionResponse, error)
+	DeletePromotion(ctx context.Context, id string) error
+	QuoteProduct(ctx context.Context, productID string, req model.QuoteRequest) (*model.Quote, error)
+	QuoteProducts(ctx context.Context, req model.BatchQuoteRequest) ([]*model.Quote, error)
+}
+
+// promotionService implements PromotionService
+type promotionService struct {
+	repo            repository.PromotionRepository
+	products        repository.ProductRepository
+	categories      repository.CategoryRepository
+	defaultCurrency string
+	now             func() time.Time
+}
+
+// NewPromotionService creates a new promotion service quoting the products of
+// products. Promotions scoped to a category cover its subcategories, as found
+// in categories. Fixed discounts without a currency are in defaultCurrency, or
+// money.DefaultCurrency if it is empty.
+func NewPromotionService(repo repository.PromotionRepository, products repository.ProductRepository, categories repository.CategoryRepository, defaultCurrency string) PromotionService {
+	if defaultCurrency == "" {
+		defaultCurrency = money.DefaultCurrency
+	}
+
+	return &promotionService{
+		repo:            repo,
+		products:        products,
+		categories:      categories,
+		defaultCurrency: defaultCurrency,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetPromotions retrieves all promotions, oldest first
+func (s *promotionService) GetPromotions(ctx context.Context) ([]*model.PromotionResponse, error) {
+	promotions, err := s.repo.GetAll(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get promotions")
+		return nil, err
+	}
+
+	responses := make([]*model.PromotionResponse, len(promotions))
+	for i, promotion := range promotions {
+		response := promotion.ToResponse()
+		responses[i] = &response
+	}
+	return responses, nil
+}
+
+// GetPromotion retrieves a promotion by ID
+func (s *promotionService) GetPromotion(ctx context.Context, id string) (*model.PromotionResponse, error) {
+	promotion, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := promotion.ToResponse()
+	return &response, nil
+}
+
+// CreatePromotion creates a new promotion
+func (s *promotionService) CreatePromotion(ctx context.Context, req model.PromotionRequest) (*model.PromotionResponse, error) {
+	log.Ctx(ctx).WithField("name", req.Name).Debug("Creating promotion")
+
+	promotion, err := s.newPromotion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, promotion)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to create promotion")
+		return nil, err
+	}
+
+	response := created.ToResponse()
+	log.Ctx(ctx).WithField("promotion_id", created.ID).Info("Successfully created promotion")
+	return &response, nil
+}
+
+// UpdatePromotion replaces an existing promotion
+func (s *promotionService) UpdatePromotion(ctx context.Context, id string, req model.PromotionRequest) (*model.PromotionResponse, error) {
+	log.Ctx(ctx).WithField("promotion_id", id).Debug("Updating promotion")
+
+	promotion, err := s.newPromotion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	promotion.ID = id
+
+	updated, err := s.repo.Update(ctx, promotion)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("promotion_id", id).Error("Failed to update promotion")
+		return nil, err
+	}
+
+	response := updated.ToResponse()
+	log.Ctx(ctx).WithField("promotion_id", id).Info("Successfully updated promotion")
+	return &response, nil
+}
+
+// DeletePromotion deletes a promotion
+func (s *promotionService) DeletePromotion(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("promotion_id", id).Error("Failed to delete promotion")
+		return err
+	}
+
+	log.Ctx(ctx).WithField("promotion_id", id).Info("Successfully deleted promotion")
+	return nil
+}
+
+// QuoteProduct returns the price the customer pays for a product with the
+// coupon code, if any
+func (s *promotionService) QuoteProduct(ctx context.Context, productID string, req model.QuoteRequest) (*model.Quote, error) {
+	quotes, err := s.quote(ctx, []string{productID}, req.CustomerID, req.CouponCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, model.ErrProductNotFound
+	}
+	return quotes[0], nil
+}
+
+// QuoteProducts returns the prices the customer pays for several products
+// with the coupon code, if any, in the order of the first request for each
+// product. Unknown products are left out.
+func (s *promotionService) QuoteProducts(ctx context.Context, req model.BatchQuoteRequest) ([]*model.Quote, error) {
+	return s.quote(ctx, batch.UniqueIDs(req.ProductIDs), req.CustomerID, req.CouponCode)
+}
+
+// quote prices the products for the customer. Each product gets the largest
+// discount of the running promotions that cover it; a coupon code must
+// belong to a running promotion, though that promotion need not cover every
+// product.
+func (s *promotionService) quote(ctx context.Context, productIDs []string, customerID, couponCode string) ([]*model.Quote, error) {
+	couponCode = model.NormalizeCouponCode(couponCode)
+	promotions, err := s.running(ctx, couponCode)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.products.GetByIDs(ctx, productIDs)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get products to quote")
+		return nil, err
+	}
+	byID := make(map[string]*model.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	tree, err := s.categoryTree(ctx, promotions)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]*model.Quote, 0, len(products))
+	for _, id := range productIDs {
+		product, ok := byID[id]
+		if !ok {
+			continue
+		}
+		inCategory := func(categoryID string) bool {
+			return tree.IsAncestor(categoryID, product.CategoryID)
+		}
+
+		discount := money.New(0, product.Price.Currency)
+		var best *model.Promotion
+		for _, promotion := range promotions {
+			if !promotion.Covers(product, customerID, inCategory) {
+				continue
+			}
+			if candidate := promotion.Discount(product.Price); candidate.Amount > discount.Amount {
+				discount, best = candidate, promotion
+			}
+		}
+
+		quotes = append(quotes, newQuote(product, discount, best))
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"products":    len(quotes),
+		"customer_id": customerID,
+		"coupon":      couponCode != "",
+	}).Debug("Quoted product prices")
+	return quotes, nil
+}
+
+// running returns the promotions running now that apply without a coupon or
+// with couponCode. A coupon code that belongs to no running promotion is
+// invalid.
+func (s *promotionService) running(ctx context.Context, couponCode string) ([]*model.Promotion, error) {
+	promotions, err := s.repo.GetAll(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get promotions")
+		return nil, err
+	}
+
+	now := s.now()
+	running := make([]*model.Promotion, 0, len(promotions))
+	couponFound := false
+	for _, promotion := range promotions {
+		if !promotion.IsRunning(now) {
+			continue
+		}
+		switch promotion.CouponCode {
+		case "":
+			running = append(running, promotion)
+		case couponCode:
+			running = append(running, promotion)
+			couponFound = true
+		}
+	}
+
+	if couponCode != "" && !couponFound {
+		return nil, model.ErrInvalidCoupon
+	}
+	return running, nil
+}
+
+// categoryTree loads the category tree when a promotion is scoped to a
+// category
+func (s *promotionService) categoryTree(ctx context.Context, promotions []*model.Promotion) (model.CategoryTree, error) {
+	for _, promotion := range promotions {
+		if len(promotion.CategoryIDs) == 0 {
+			continue
+		}
+
+		categories, err := s.categories.GetAll(ctx)
+		if err != nil {
+			log.Ctx(ctx).WithError(err).Error("Failed to get categories")
+			return nil, err
+		}
+		return model.NewCategoryTree(categories), nil
+	}
+	return model.CategoryTree{}, nil
+}
+
+// newPromotion validates a request and converts it into a promotion
+func (s *promotionService) newPromotion(ctx context.Context, req model.PromotionRequest) (*model.Promotion, error) {
+	promotion := &model.Promotion{
+		Name:        strings.TrimSpace(req.Name),
+		Type:        req.Type,
+		CouponCode:  model.NormalizeCouponCode(req.CouponCode),
+		ProductIDs:  req.ProductIDs,
+		CategoryIDs: req.CategoryIDs,
+		CustomerIDs: req.CustomerIDs,
+		Active:      req.Active == nil || *req.Active,
+	}
+
+	switch req.Type {
+	case model.DiscountPercentage:
+		basisPoints, err := parsePercentage(req.Value.String())
+		if err != nil {
+			return nil, err
+		}
+		promotion.BasisPoints = basisPoints
+	case model.DiscountFixed:
+		currency := req.Currency
+		if currency == "" {
+			currency = s.defaultCurrency
+		}
+		amount, err := money.Parse(req.Value.String(), currency)
+		switch {
+		case errors.Is(err, money.ErrUnknownCurrency):
+			return nil, model.ErrUnsupportedCurrency
+		case err != nil || !amount.IsPositive():
+			return nil, model.ErrInvalidDiscountAmount
+		}
+		promotion.Amount = amount
+	}
+
+	if req.StartsAt != nil {
+		startsAt := req.StartsAt.UTC()
+		promotion.StartsAt = &startsAt
+	}
+	if req.EndsAt != nil {
+		endsAt := req.EndsAt.UTC()
+		promotion.EndsAt = &endsAt
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return nil, model.ErrInvalidPromotionDates
+	}
+
+	for _, id := range promotion.CategoryIDs {
+		if _, err := s.lookupCategory(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return promotion, nil
+}
+
+// lookupCategory resolves a category a promotion is scoped to
+func (s *promotionService) lookupCategory(ctx context.Context, id string) (*model.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if errors.Is(err, model.ErrCategoryNotFound) {
+		return nil, model.ErrUnknownCategory
+	}
+	return category, err
+}
+
+// newQuote prices a product with the discount of the promotion applied, if any
+func newQuote(product *model.Product, discount money.Money, promotion *model.Promotion) *model.Quote {
+	quote := &model.Quote{
+		ProductID: product.ID,
+		ListPrice: product.Price.Number(),
+		Discount:  discount.Number(),
+		Price:     money.New(product.Price.Amount-discount.Amount, product.Price.Currency).Number(),
+		Currency:  product.Price.Currency,
+	}
+	if promotion != nil {
+		quote.Promotion = &model.AppliedPromotion{
+			ID:         promotion.ID,
+			Name:       promotion.Name,
+			CouponCode: promotion.CouponCode,
+		}
+	}
+	return quote
+}
+
+// parsePercentage converts a percentage with at most two decimals into basis
+// points between 1 and 10000
+func parsePercentage(value string) (int64, error) {
+	if strings.ContainsAny(value, "eE/") {
+		return 0, model.ErrInvalidPercentage
+	}
+	percentage, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return 0, model.ErrInvalidPercentage
+	}
+
+	basisPoints := percentage.Mul(percentage, big.NewRat(100, 1))
+	if !basisPoints.IsInt() || basisPoints.Sign() <= 0 || basisPoints.Cmp(big.NewRat(10000, 1)) > 0 {
+		return 0, model.ErrInvalidPercentage
+	}
+	return basisPoints.Num().Int64(), nil
+}
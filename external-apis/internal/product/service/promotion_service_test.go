@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPromotionService returns a promotion service over the sample
+// products and categories whose clock is read from now
+func newTestPromotionService(now *time.Time) *promotionService {
+	service := NewPromotionService(repository.NewMemoryPromotionRepository(), repository.NewMemoryProductRepository(), repository.NewMemoryCategoryRepository(), "USD").(*promotionService)
+	service.now = func() time.Time { return *now }
+	return service
+}
+
+func TestPromotionService_CreatePromotion(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Percentage with a coupon code", func(t *testing.T) {
+		// Arrange
+		service := newTestPromotionService(&now)
+
+		// Act
+		promotion, err := service.CreatePromotion(context.Background(), model.PromotionRequest{
+			Name: " Spring sale ", Type: model.DiscountPercentage, Value: "12.5", CouponCode: "spring24",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Spring sale", promotion.Name)
+		assert.Equal(t, json.Number("12.50"), promotion.Value)
+		assert.Equal(t, "SPRING24", promotion.CouponCode)
+		assert.True(t, promotion.Active)
+	})
+
+	t.Run("Fixed in the default currency", func(t *testing.T) {
+		// Arrange
+		service := newTestPromotionService(&now)
+
+		// Act
+		promotion, err := service.CreatePromotion(context.Background(), model.PromotionRequest{
+			Name: "Five off", Type: model.DiscountFixed, Value: "5",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("5.00"), promotion.Value)
+		assert.Equal(t, "USD", promotion.Currency)
+	})
+
+	tests := []struct {
+		name string
+		req  model.PromotionRequest
+		err  error
+	}{
+		{"Percentage above 100", model.PromotionRequest{Type: model.DiscountPercentage, Value: "100.01"}, model.ErrInvalidPercentage},
+		{"Percentage too precise", model.PromotionRequest{Type: model.DiscountPercentage, Value: "12.345"}, model.ErrInvalidPercentage},
+		{"Zero percentage", model.PromotionRequest{Type: model.DiscountPercentage, Value: "0"}, model.ErrInvalidPercentage},
+		{"Percentage in exponent form", model.PromotionRequest{Type: model.DiscountPercentage, Value: "1e1"}, model.ErrInvalidPercentage},
+		{"Negative amount", model.PromotionRequest{Type: model.DiscountFixed, Value: "-5"}, model.ErrInvalidDiscountAmount},
+		{"Amount too precise", model.PromotionRequest{Type: model.DiscountFixed, Value: "5.5", Currency: "JPY"}, model.ErrInvalidDiscountAmount},
+		{"Unknown currency", model.PromotionRequest{Type: model.DiscountFixed, Value: "5", Currency: "XXX"}, model.ErrUnsupportedCurrency},
+		{"Ends before it starts", model.PromotionRequest{Type: model.DiscountPercentage, Value: "10", StartsAt: &now, EndsAt: &now}, model.ErrInvalidPromotionDates},
+		{"Unknown category", model.PromotionRequest{Type: model.DiscountPercentage, Value: "10", CategoryIDs: []string{"category-999"}}, model.ErrUnknownCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service := newTestPromotionService(&now)
+			tt.req.Name = "Sale"
+
+			// Act
+			_, err := service.CreatePromotion(context.Background(), tt.req)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestPromotionService_QuoteProduct(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tomorrow := now.Add(24 * time.Hour)
+	inactive := false
+
+	newService := func(t *testing.T, reqs ...model.PromotionRequest) *promotionService {
+		service := newTestPromotionService(&now)
+		for _, req := range reqs {
+			_, err := service.CreatePromotion(context.Background(), req)
+			require.NoError(t, err)
+		}
+		return service
+	}
+
+	t.Run("List price without promotions", func(t *testing.T) {
+		// Arrange
+		service := newService(t)
+
+		// Act
+		quote, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("29.99"), quote.ListPrice)
+		assert.Equal(t, json.Number("0.00"), quote.Discount)
+		assert.Equal(t, json.Number("29.99"), quote.Price)
+		assert.Nil(t, quote.Promotion)
+	})
+
+	t.Run("Best of the running promotions", func(t *testing.T) {
+		// Arrange
+		service := newService(t,
+			model.PromotionRequest{Name: "Ten percent", Type: model.DiscountPercentage, Value: "10"},
+			model.PromotionRequest{Name: "Five off electronics", Type: model.DiscountFixed, Value: "5", CategoryIDs: []string{"category-electronics"}},
+			model.PromotionRequest{Name: "Half off tomorrow", Type: model.DiscountPercentage, Value: "50", StartsAt: &tomorrow},
+			model.PromotionRequest{Name: "Inactive", Type: model.DiscountPercentage, Value: "90", Active: &inactive},
+			model.PromotionRequest{Name: "Other product", Type: model.DiscountPercentage, Value: "90", ProductIDs: []string{"product-002"}},
+		)
+
+		// Act
+		quote, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("5.00"), quote.Discount)
+		assert.Equal(t, json.Number("24.99"), quote.Price)
+		require.NotNil(t, quote.Promotion)
+		assert.Equal(t, "Five off electronics", quote.Promotion.Name)
+	})
+
+	t.Run("Coupon code", func(t *testing.T) {
+		// Arrange
+		service := newService(t,
+			model.PromotionRequest{Name: "Ten percent", Type: model.DiscountPercentage, Value: "10"},
+			model.PromotionRequest{Name: "Twenty percent", Type: model.DiscountPercentage, Value: "20", CouponCode: "TWENTY"},
+		)
+
+		// Act
+		without, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{})
+		require.NoError(t, err)
+		with, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{CouponCode: " twenty "})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("3.00"), without.Discount)
+		assert.Equal(t, json.Number("6.00"), with.Discount)
+		assert.Equal(t, "TWENTY", with.Promotion.CouponCode)
+	})
+
+	t.Run("Customer promotion", func(t *testing.T) {
+		// Arrange
+		service := newService(t,
+			model.PromotionRequest{Name: "Loyalty", Type: model.DiscountPercentage, Value: "10", CustomerIDs: []string{"customer-123"}},
+		)
+
+		// Act
+		anonymous, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{})
+		require.NoError(t, err)
+		customer, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{CustomerID: "customer-123"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, anonymous.Promotion)
+		assert.Equal(t, json.Number("3.00"), customer.Discount)
+	})
+
+	t.Run("Unknown coupon code", func(t *testing.T) {
+		// Arrange
+		service := newService(t)
+
+		// Act
+		_, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{CouponCode: "NOPE"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidCoupon)
+	})
+
+	t.Run("Expired coupon code", func(t *testing.T) {
+		// Arrange
+		yesterday := now.Add(-24 * time.Hour)
+		service := newService(t,
+			model.PromotionRequest{Name: "Expired", Type: model.DiscountPercentage, Value: "10", CouponCode: "OLD", StartsAt: &yesterday, EndsAt: &now},
+		)
+
+		// Act
+		_, err := service.QuoteProduct(context.Background(), "product-001", model.QuoteRequest{CouponCode: "OLD"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidCoupon)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		// Arrange
+		service := newService(t)
+
+		// Act
+		_, err := service.QuoteProduct(context.Background(), "product-999", model.QuoteRequest{})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
+func TestPromotionService_QuoteProducts(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := newTestPromotionService(&now)
+	_, err := service.CreatePromotion(context.Background(), model.PromotionRequest{
+		Name: "Ten percent", Type: model.DiscountPercentage, Value: "10", ProductIDs: []string{"product-002"},
+	})
+	require.NoError(t, err)
+
+	// Act
+	quotes, err := service.QuoteProducts(context.Background(), model.BatchQuoteRequest{
+		ProductIDs: []string{"product-002", "product-999", "product-001", "product-002"},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, quotes, 2)
+	assert.Equal(t, "product-002", quotes[0].ProductID)
+	assert.Equal(t, json.Number("13.00"), quotes[0].Discount)
+	assert.Equal(t, "product-001", quotes[1].ProductID)
+	assert.Nil(t, quotes[1].Promotion)
+}
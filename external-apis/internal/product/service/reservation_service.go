@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+)
+
+// ReservationService defines the interface for stock reservation logic
+type ReservationService interface {
+	CreateReservation(ctx context.Context, productID string, req model.CreateReservationRequest) (*model.Reservation, error)
+	GetReservation(ctx context.Context, id string) (*model.Reservation, error)
+	GetOrderReservations(ctx context.Context, orderID string) ([]*model.Reservation, error)
+	ConfirmReservation(ctx context.Context, id string) (*model.Reservation, error)
+	ReleaseReservation(ctx context.Context, id string) (*model.Reservation, error)
+	ReleaseExpired(ctx context.Context) (int, error)
+}
+
+// ReservationOptions configures how long reservations hold stock
+type ReservationOptions struct {
+	// TTL applies to reservations that do not ask for their own
+	TTL time.Duration
+	// MaxTTL is the longest TTL a reservation may ask for
+	MaxTTL time.Duration
+}
+
+// reservationService implements ReservationService
+type reservationService struct {
+	repo     repository.ReservationRepository
+	products repository.ProductRepository
+	options  ReservationOptions
+	events   events.Publisher
+	now      func() time.Time
+}
+
+// NewReservationService creates a new reservation service. Stock changes are
+// published to events, which may be nil.
+func NewReservationService(repo repository.ReservationRepository, products repository.ProductRepository, options ReservationOptions, events events.Publisher) ReservationService {
+	return &reservationService{
+		repo:     repo,
+		products: products,
+		options:  options,
+		events:   events,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// CreateReservation reserves units of an active product's available stock for
+// an order until the reservation expires
+func (s *reservationService) CreateReservation(ctx context.Context, productID string, req model.CreateReservationRequest) (*model.Reservation, error) {
+	fields := logger.Fields{
+		"product_id": productID,
+		"order_id":   req.OrderID,
+		"quantity":   req.Quantity,
+	}
+	log.Ctx(ctx).WithFields(fields).Debug("Creating stock reservation")
+
+	if req.Quantity <= 0 {
+		return nil, model.ErrInvalidQuantity
+	}
+	ttl := s.options.TTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if s.options.MaxTTL > 0 && ttl > s.options.MaxTTL {
+		return nil, model.ErrReservationTTL
+	}
+
+	product, err := s.products.ReserveStock(ctx, productID, req.Quantity)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Warn("Failed to reserve product stock")
+		return nil, err
+	}
+
+	now := s.now()
+	reservation, err := s.repo.Create(ctx, &model.Reservation{
+		ProductID: productID,
+		OrderID:   req.OrderID,
+		Quantity:  req.Quantity,
+		Status:    model.ReservationActive,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to create stock reservation")
+		// Give the stock back, nothing holds it
+		if _, releaseErr := s.products.ReleaseStock(ctx, productID, req.Quantity); releaseErr != nil {
+			log.Ctx(ctx).WithError(releaseErr).WithFields(fields).Error("Failed to release stock of failed reservation")
+		}
+		return nil, err
+	}
+
+	s.publishStock(ctx, product)
+	log.Ctx(ctx).WithFields(fields).WithField("reservation_id", reservation.ID).Info("Successfully reserved product stock")
+
+	return reservation, nil
+}
+
+// GetReservation retrieves a reservation by ID
+func (s *reservationService) GetReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetOrderReservations retrieves the reservations made for an order
+func (s *reservationService) GetOrderReservations(ctx context.Context, orderID string) ([]*model.Reservation, error) {
+	return s.repo.GetByOrderID(ctx, orderID)
+}
+
+// ConfirmReservation keeps the stock of an active reservation for its order;
+// a confirmed reservation no longer expires
+func (s *reservationService) ConfirmReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	log.Ctx(ctx).WithField("reservation_id", id).Debug("Confirming stock reservation")
+
+	reservation, err := s.repo.Transition(ctx, id, model.ReservationConfirmed, s.now())
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("reservation_id", id).Warn("Failed to confirm stock reservation")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithField("reservation_id", id).Info("Successfully confirmed stock reservation")
+	return reservation, nil
+}
+
+// ReleaseReservation returns the stock of an active or confirmed reservation
+func (s *reservationService) ReleaseReservation(ctx context.Context, id string) (*model.Reservation, error) {
+	log.Ctx(ctx).WithField("reservation_id", id).Debug("Releasing stock reservation")
+
+	reservation, err := s.repo.Transition(ctx, id, model.ReservationReleased, s.now())
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("reservation_id", id).Warn("Failed to release stock reservation")
+		return nil, err
+	}
+
+	s.releaseStock(ctx, reservation)
+	log.Ctx(ctx).WithField("reservation_id", id).Info("Successfully released stock reservation")
+	return reservation, nil
+}
+
+// ReleaseExpired returns the stock of the tenant's active reservations that
+// have expired and reports how many were released
+func (s *reservationService) ReleaseExpired(ctx context.Context) (int, error) {
+	now := s.now()
+	expired, err := s.repo.GetExpired(ctx, now)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get expired stock reservations")
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		// A reservation confirmed or released meanwhile keeps its status
+		reservation, err := s.repo.Transition(ctx, reservation.ID, model.ReservationExpired, now)
+		if err != nil {
+			continue
+		}
+		s.releaseStock(ctx, reservation)
+		released++
+	}
+
+	if released > 0 {
+		log.Ctx(ctx).WithField("released", released).Info("Released expired stock reservations")
+	}
+	return released, nil
+}
+
+// releaseStock returns the units of a reservation that stopped holding them.
+// The reservation stays released if the product is gone; there is no stock
+// left to return.
+func (s *reservationService) releaseStock(ctx context.Context, reservation *model.Reservation) {
+	product, err := s.products.ReleaseStock(ctx, reservation.ProductID, reservation.Quantity)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"reservation_id": reservation.ID,
+			"product_id":     reservation.ProductID,
+		}).Error("Failed to release reserved product stock")
+		return
+	}
+	s.publishStock(ctx, product)
+}
+
+// publishStock sends a stock change event if a publisher is configured
+func (s *reservationService) publishStock(ctx context.Context, product *model.Product) {
+	if s.events != nil {
+		s.events.Publish(events.New(events.ProductStockChanged, product.ID, product.ToResponse()).In(ctx))
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReservationService returns a reservation service with a 15 minute
+// TTL whose clock is read from now
+func newTestReservationService(products *MockProductRepository, now *time.Time, publisher events.Publisher) *reservationService {
+	service := NewReservationService(repository.NewMemoryReservationRepository(), products, ReservationOptions{
+		TTL:    15 * time.Minute,
+		MaxTTL: time.Hour,
+	}, publisher).(*reservationService)
+	service.now = func() time.Time { return *now }
+	return service
+}
+
+func TestReservationService_CreateReservation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	request := model.CreateReservationRequest{OrderID: "order-1", Quantity: 3}
+
+	t.Run("Reserve stock until the TTL", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		publisher := events.NewMemoryPublisher()
+		service := newTestReservationService(mockRepo, &now, publisher)
+		mockRepo.On("ReserveStock", "product-123", 3).Return(&model.Product{ID: "product-123", StockQuantity: 10, ReservedQuantity: 3}, nil)
+
+		// Act
+		reservation, err := service.CreateReservation(context.Background(), "product-123", request)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.ReservationActive, reservation.Status)
+		assert.Equal(t, "order-1", reservation.OrderID)
+		assert.Equal(t, now.Add(15*time.Minute), reservation.ExpiresAt)
+		assert.Equal(t, []string{events.ProductStockChanged}, publisher.Types())
+	})
+
+	t.Run("Requested TTL", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := newTestReservationService(mockRepo, &now, nil)
+		mockRepo.On("ReserveStock", "product-123", 3).Return(&model.Product{ID: "product-123"}, nil)
+
+		// Act
+		reservation, err := service.CreateReservation(context.Background(), "product-123", model.CreateReservationRequest{OrderID: "order-1", Quantity: 3, TTLSeconds: 60})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Minute), reservation.ExpiresAt)
+	})
+
+	t.Run("TTL over the maximum", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := newTestReservationService(mockRepo, &now, nil)
+
+		// Act
+		_, err := service.CreateReservation(context.Background(), "product-123", model.CreateReservationRequest{OrderID: "order-1", Quantity: 3, TTLSeconds: 7200})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReservationTTL)
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Insufficient stock", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := newTestReservationService(mockRepo, &now, nil)
+		mockRepo.On("ReserveStock", "product-123", 3).Return(nil, model.ErrInsufficientStock)
+
+		// Act
+		_, err := service.CreateReservation(context.Background(), "product-123", request)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInsufficientStock)
+		reservations, _ := service.GetOrderReservations(context.Background(), "order-1")
+		assert.Empty(t, reservations)
+	})
+}
+
+func TestReservationService_ReleaseReservation(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo := new(MockProductRepository)
+	service := newTestReservationService(mockRepo, &now, nil)
+	mockRepo.On("ReserveStock", "product-123", 2).Return(&model.Product{ID: "product-123"}, nil)
+	mockRepo.On("ReleaseStock", "product-123", 2).Return(&model.Product{ID: "product-123"}, nil).Once()
+	reservation, err := service.CreateReservation(context.Background(), "product-123", model.CreateReservationRequest{OrderID: "order-1", Quantity: 2})
+	require.NoError(t, err)
+
+	t.Run("Release returns the stock", func(t *testing.T) {
+		// Act
+		released, err := service.ReleaseReservation(context.Background(), reservation.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.ReservationReleased, released.Status)
+		mockRepo.AssertNumberOfCalls(t, "ReleaseStock", 1)
+	})
+
+	t.Run("Release twice", func(t *testing.T) {
+		// Act
+		_, err := service.ReleaseReservation(context.Background(), reservation.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReservationNotActive)
+		mockRepo.AssertNumberOfCalls(t, "ReleaseStock", 1)
+	})
+}
+
+func TestReservationService_ReleaseExpired(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo := new(MockProductRepository)
+	service := newTestReservationService(mockRepo, &now, nil)
+	mockRepo.On("ReserveStock", mock.Anything, 1).Return(&model.Product{ID: "product-123"}, nil)
+	mockRepo.On("ReleaseStock", "product-123", 1).Return(&model.Product{ID: "product-123"}, nil)
+
+	abandoned, err := service.CreateReservation(context.Background(), "product-123", model.CreateReservationRequest{OrderID: "order-1", Quantity: 1})
+	require.NoError(t, err)
+	placed, err := service.CreateReservation(context.Background(), "product-456", model.CreateReservationRequest{OrderID: "order-2", Quantity: 1})
+	require.NoError(t, err)
+	_, err = service.ConfirmReservation(context.Background(), placed.ID)
+	require.NoError(t, err)
+	now = now.Add(15 * time.Minute)
+
+	// Act
+	released, err := service.ReleaseExpired(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	expired, _ := service.GetReservation(context.Background(), abandoned.ID)
+	assert.Equal(t, model.ReservationExpired, expired.Status)
+	confirmed, _ := service.GetReservation(context.Background(), placed.ID)
+	assert.Equal(t, model.ReservationConfirmed, confirmed.Status)
+	mockRepo.AssertCalled(t, "ReleaseStock", "product-123", 1)
+	mockRepo.AssertNotCalled(t, "ReleaseStock", "product-456", 1)
+
+	t.Run("Expired reservation cannot be confirmed", func(t *testing.T) {
+		// Act
+		_, err := service.ConfirmReservation(context.Background(), abandoned.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReservationNotActive)
+	})
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+)
+
+// ReviewService defines the interface for product review business logic
+type ReviewService interface {
+	GetReviews(ctx context.Context, productID string) ([]*model.ReviewResponse, error)
+	GetReviewsByStatus(ctx context.Context, status model.ReviewStatus) ([]*model.ReviewResponse, error)
+	GetReview(ctx context.Context, productID, id string) (*model.ReviewResponse, error)
+	CreateReview(ctx context.Context, productID string, req model.CreateReviewRequest) (*model.ReviewResponse, error)
+	UpdateReview(ctx context.Context, productID, id string, req model.UpdateReviewRequest) (*model.ReviewResponse, error)
+	DeleteReview(ctx context.Context, productID, id string) error
+	ModerateReview(ctx context.Context, productID, id string, req model.ModerateReviewRequest) (*model.ReviewResponse, error)
+}
+
+// reviewService implements ReviewService
+type reviewService struct {
+	repo     repository.ReviewRepository
+	products repository.ProductRepository
+	events   events.Publisher
+	now      func() time.Time
+
+	// ratingMu serializes the aggregation of ratings so a slower
+	// aggregation never overwrites a newer one
+	ratingMu sync.Mutex
+}
+
+// NewReviewService creates a new review service for the products of
+// products. Reviews wait for moderation before they are shown and counted in
+// the rating of their product. Product update events go to events, which may
+// be nil.
+func NewReviewService(repo repository.ReviewRepository, products repository.ProductRepository, events events.Publisher) ReviewService {
+	return &reviewService{
+		repo:     repo,
+		products: products,
+		events:   events,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetReviews retrieves the approved reviews of a product, oldest first
+func (s *reviewService) GetReviews(ctx context.Context, productID string) ([]*model.ReviewResponse, error) {
+	if !s.products.ExistsByID(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+
+	reviews, err := s.repo.GetByProduct(ctx, productID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", productID).Error("Failed to get reviews")
+		return nil, err
+	}
+
+	responses := make([]*model.ReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		if review.Status == model.ReviewApproved {
+			response := review.ToResponse()
+			responses = append(responses, &response)
+		}
+	}
+	return responses, nil
+}
+
+// GetReviewsByStatus retrieves the reviews of every product with a status,
+// oldest first, e.g. those waiting for moderation
+func (s *reviewService) GetReviewsByStatus(ctx context.Context, status model.ReviewStatus) ([]*model.ReviewResponse, error) {
+	if !status.IsValid() {
+		return nil, model.ErrInvalidStatusFilter
+	}
+
+	reviews, err := s.repo.GetByStatus(ctx, status)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("status", status).Error("Failed to get reviews")
+		return nil, err
+	}
+	return toReviewResponses(reviews), nil
+}
+
+// GetReview retrieves a review of a product. Reviews that are not approved
+// are not found.
+func (s *reviewService) GetReview(ctx context.Context, productID, id string) (*model.ReviewResponse, error) {
+	review, err := s.repo.GetByID(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != model.ReviewApproved {
+		return nil, model.ErrReviewNotFound
+	}
+
+	response := review.ToResponse()
+	return &response, nil
+}
+
+// CreateReview reviews a product on behalf of a customer. The review waits
+// for moderation.
+func (s *reviewService) CreateReview(ctx context.Context, productID string, req model.CreateReviewRequest) (*model.ReviewResponse, error) {
+	if !s.products.ExistsByID(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+	if req.Rating < model.MinRating || req.Rating > model.MaxRating {
+		return nil, model.ErrInvalidRating
+	}
+
+	review := &model.Review{
+		ProductID:  productID,
+		CustomerID: req.CustomerID,
+		Rating:     req.Rating,
+		Text:       req.Text,
+		Status:     model.ReviewPending,
+	}
+	review.Normalize()
+
+	created, err := s.repo.Create(ctx, review)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"product_id":  productID,
+			"customer_id": req.CustomerID,
+		}).Warn("Failed to create review")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"review_id":  created.ID,
+	}).Info("Review created, waiting for moderation")
+
+	response := created.ToResponse()
+	return &response, nil
+}
+
+// UpdateReview applies the provided changes to a review and sends it back to
+// moderation, taking it out of the rating of its product meanwhile
+func (s *reviewService) UpdateReview(ctx context.Context, productID, id string, req model.UpdateReviewRequest) (*model.ReviewResponse, error) {
+	review, err := s.repo.GetByID(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Rating != nil {
+		if *req.Rating < model.MinRating || *req.Rating > model.MaxRating {
+			return nil, model.ErrInvalidRating
+		}
+		review.Rating = *req.Rating
+	}
+	if req.Text != nil {
+		review.Text = *req.Text
+	}
+	review.Normalize()
+
+	wasApproved := review.Status == model.ReviewApproved
+	review.Status = model.ReviewPending
+	review.ModerationReason = ""
+	review.ModeratedAt = nil
+
+	updated, err := s.repo.Update(ctx, review)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("review_id", id).Error("Failed to update review")
+		return nil, err
+	}
+	if wasApproved {
+		s.updateRating(ctx, productID)
+	}
+
+	response := updated.ToResponse()
+	return &response, nil
+}
+
+// DeleteReview removes a review of a product
+func (s *reviewService) DeleteReview(ctx context.Context, productID, id string) error {
+	review, err := s.repo.GetByID(ctx, productID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, productID, id); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("review_id", id).Error("Failed to delete review")
+		return err
+	}
+	if review.Status == model.ReviewApproved {
+		s.updateRating(ctx, productID)
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"review_id":  id,
+	}).Info("Review deleted")
+	return nil
+}
+
+// ModerateReview approves or rejects a review, recording the reason, and
+// updates the rating of its product
+func (s *reviewService) ModerateReview(ctx context.Context, productID, id string, req model.ModerateReviewRequest) (*model.ReviewResponse, error) {
+	if req.Status != model.ReviewApproved && req.Status != model.ReviewRejected {
+		return nil, model.ErrInvalidReviewStatus
+	}
+
+	review, err := s.repo.GetByID(ctx, productID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := review.Status != req.Status
+	moderatedAt := s.now()
+	review.Status = req.Status
+	review.ModerationReason = req.Reason
+	review.ModeratedAt = &moderatedAt
+
+	updated, err := s.repo.Update(ctx, review)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("review_id", id).Error("Failed to moderate review")
+		return nil, err
+	}
+	if changed {
+		s.updateRating(ctx, productID)
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"review_id":  id,
+		"status":     req.Status,
+	}).Info("Review moderated")
+
+	response := updated.ToResponse()
+	return &response, nil
+}
+
+// updateRating aggregates the approved reviews of a product into its
+// rating. The review change already happened, so a failure is logged rather
+// than returned; the next change of a review of the product fixes it.
+func (s *reviewService) updateRating(ctx context.Context, productID string) {
+	s.ratingMu.Lock()
+	defer s.ratingMu.Unlock()
+
+	fields := logger.Fields{"product_id": productID}
+	reviews, err := s.repo.GetByProduct(ctx, productID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to aggregate product rating")
+		return
+	}
+
+	product, err := s.products.SetRating(ctx, productID, model.RatingOf(reviews))
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to update product rating")
+		return
+	}
+
+	if s.events != nil {
+		s.events.Publish(events.New(events.ProductUpdated, product.ID, product.ToResponse()).In(ctx))
+	}
+}
+
+// toReviewResponses converts reviews to API responses
+func toReviewResponses(reviews []*model.Review) []*model.ReviewResponse {
+	responses := make([]*model.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		response := review.ToResponse()
+		responses[i] = &response
+	}
+	return responses
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestReviewService returns a review service over the sample products,
+// along with the product repository to check their ratings
+func newTestReviewService(publisher events.Publisher) (*reviewService, *repository.MemoryProductRepository) {
+	products := repository.NewMemoryProductRepository()
+	service := NewReviewService(repository.NewMemoryReviewRepository(), products, publisher).(*reviewService)
+	service.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return service, products
+}
+
+// approvedReview creates a review of product-001 and approves it
+func approvedReview(t *testing.T, service *reviewService, customerID string, rating int) *model.ReviewResponse {
+	t.Helper()
+	created, err := service.CreateReview(context.Background(), "product-001", model.CreateReviewRequest{CustomerID: customerID, Rating: rating})
+	require.NoError(t, err)
+	approved, err := service.ModerateReview(context.Background(), "product-001", created.ID, model.ModerateReviewRequest{Status: model.ReviewApproved})
+	require.NoError(t, err)
+	return approved
+}
+
+func TestReviewService_CreateReview(t *testing.T) {
+	t.Run("Wait for moderation", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestReviewService(nil)
+
+		// Act
+		review, err := service.CreateReview(context.Background(), "product-001", model.CreateReviewRequest{
+			CustomerID: "customer-001", Rating: 4, Text: "  Does the job  ",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.ReviewPending, review.Status)
+		assert.Equal(t, "Does the job", review.Text)
+		_, err = service.GetReview(context.Background(), "product-001", review.ID)
+		assert.ErrorIs(t, err, model.ErrReviewNotFound)
+		public, err := service.GetReviews(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Empty(t, public)
+		pending, err := service.GetReviewsByStatus(context.Background(), model.ReviewPending)
+		require.NoError(t, err)
+		assert.Len(t, pending, 1)
+	})
+
+	tests := []struct {
+		name      string
+		productID string
+		rating    int
+		wantErr   error
+	}{
+		{name: "Unknown product", productID: "non-existing", rating: 4, wantErr: model.ErrProductNotFound},
+		{name: "Rating too low", productID: "product-001", rating: 0, wantErr: model.ErrInvalidRating},
+		{name: "Rating too high", productID: "product-001", rating: 6, wantErr: model.ErrInvalidRating},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, _ := newTestReviewService(nil)
+
+			// Act
+			_, err := service.CreateReview(context.Background(), tt.productID, model.CreateReviewRequest{CustomerID: "customer-001", Rating: tt.rating})
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReviewService_ModerateReview(t *testing.T) {
+	t.Run("Approved reviews count in the rating", func(t *testing.T) {
+		// Arrange
+		publisher := events.NewMemoryPublisher()
+		service, products := newTestReviewService(publisher)
+
+		// Act
+		first := approvedReview(t, service, "customer-001", 5)
+		approvedReview(t, service, "customer-002", 4)
+
+		// Assert
+		assert.Equal(t, model.ReviewApproved, first.Status)
+		assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), *first.ModeratedAt)
+		product, err := products.GetByID(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Equal(t, 4.5, product.ToResponse().AverageRating)
+		assert.Equal(t, 2, product.ToResponse().ReviewCount)
+		assert.Equal(t, []string{events.ProductUpdated, events.ProductUpdated}, publisher.Types())
+		public, err := service.GetReviews(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Len(t, public, 2)
+	})
+
+	t.Run("Rejecting an approved review drops it from the rating", func(t *testing.T) {
+		// Arrange
+		service, products := newTestReviewService(nil)
+		approvedReview(t, service, "customer-001", 5)
+		review := approvedReview(t, service, "customer-002", 1)
+
+		// Act
+		rejected, err := service.ModerateReview(context.Background(), "product-001", review.ID, model.ModerateReviewRequest{
+			Status: model.ReviewRejected, Reason: "Off topic",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Off topic", rejected.ModerationReason)
+		product, err := products.GetByID(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Equal(t, model.ProductRating{Count: 1, Total: 5}, product.Rating)
+	})
+
+	t.Run("Back to pending", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestReviewService(nil)
+		review := approvedReview(t, service, "customer-001", 5)
+
+		// Act
+		_, err := service.ModerateReview(context.Background(), "product-001", review.ID, model.ModerateReviewRequest{Status: model.ReviewPending})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidReviewStatus)
+	})
+
+	t.Run("Unknown review", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestReviewService(nil)
+
+		// Act
+		_, err := service.ModerateReview(context.Background(), "product-001", "non-existing", model.ModerateReviewRequest{Status: model.ReviewApproved})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrReviewNotFound)
+	})
+}
+
+func TestReviewService_UpdateReview(t *testing.T) {
+	// Arrange
+	service, products := newTestReviewService(nil)
+	review := approvedReview(t, service, "customer-001", 5)
+	rating := 2
+
+	// Act
+	updated, err := service.UpdateReview(context.Background(), "product-001", review.ID, model.UpdateReviewRequest{Rating: &rating})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Rating)
+	assert.Equal(t, model.ReviewPending, updated.Status)
+	assert.Nil(t, updated.ModeratedAt)
+	product, err := products.GetByID(context.Background(), "product-001")
+	require.NoError(t, err)
+	assert.Zero(t, product.Rating.Count, "the review waits for moderation again")
+
+	t.Run("Invalid rating", func(t *testing.T) {
+		// Arrange
+		rating := 9
+
+		// Act
+		_, err := service.UpdateReview(context.Background(), "product-001", review.ID, model.UpdateReviewRequest{Rating: &rating})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidRating)
+	})
+}
+
+func TestReviewService_DeleteReview(t *testing.T) {
+	// Arrange
+	service, products := newTestReviewService(nil)
+	review := approvedReview(t, service, "customer-001", 3)
+
+	// Act
+	err := service.DeleteReview(context.Background(), "product-001", review.ID)
+
+	// Assert
+	require.NoError(t, err)
+	product, err := products.GetByID(context.Background(), "product-001")
+	require.NoError(t, err)
+	assert.Equal(t, model.ProductRating{}, product.Rating)
+	assert.ErrorIs(t, service.DeleteReview(context.Background(), "product-001", review.ID), model.ErrReviewNotFound)
+}
+
+func TestReviewService_GetReviewsByStatus(t *testing.T) {
+	// Arrange
+	service, _ := newTestReviewService(nil)
+
+	// Act
+	_, err := service.GetReviewsByStatus(context.Background(), "LOST")
+
+	// Assert
+	assert.ErrorIs(t, err, model.ErrInvalidStatusFilter)
+}
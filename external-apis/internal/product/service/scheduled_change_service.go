@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/logger"
+)
+
+// ScheduledChangeService defines the interface for scheduling product changes
+type ScheduledChangeService interface {
+	ScheduleChange(ctx context.Context, productID string, req model.ScheduleChangeRequest) (*model.ScheduledChange, error)
+	GetScheduledChanges(ctx context.Context, productID string) ([]*model.ScheduledChange, error)
+	CancelScheduledChange(ctx context.Context, productID, id string) (*model.ScheduledChange, error)
+	ApplyDue(ctx context.Context) (int, error)
+}
+
+// scheduledChangeService implements ScheduledChangeService
+type scheduledChangeService struct {
+	repo     repository.ScheduledChangeRepository
+	products ProductService
+	now      func() time.Time
+}
+
+// NewScheduledChangeService creates a new scheduled change service. Due
+// changes are made through products, so they are validated and published
+// like any other product update.
+func NewScheduledChangeService(repo repository.ScheduledChangeRepository, products ProductService) ScheduledChangeService {
+	return &scheduledChangeService{
+		repo:     repo,
+		products: products,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// ScheduleChange schedules a change to a product's price, currency or active
+// status. The new price is validated against the product as it is now, but
+// only takes effect at EffectiveAt.
+func (s *scheduledChangeService) ScheduleChange(ctx context.Context, productID string, req model.ScheduleChangeRequest) (*model.ScheduledChange, error) {
+	fields := logger.Fields{
+		"product_id":   productID,
+		"effective_at": req.EffectiveAt,
+	}
+	log.Ctx(ctx).WithFields(fields).Debug("Scheduling product change")
+
+	if req.Price == nil && req.Currency == nil && req.Active == nil {
+		return nil, model.ErrScheduledChangeEmpty
+	}
+	now := s.now()
+	if !req.EffectiveAt.After(now) {
+		return nil, model.ErrEffectiveAtNotFuture
+	}
+
+	product, err := s.products.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := &model.ScheduledChange{
+		ProductID:   product.ID,
+		Active:      req.Active,
+		EffectiveAt: req.EffectiveAt.UTC(),
+		Status:      model.ScheduledChangePending,
+		CreatedAt:   now,
+	}
+	if req.Price != nil || req.Currency != nil {
+		amount := product.Price.String()
+		if req.Price != nil {
+			amount = req.Price.String()
+		}
+		currency := product.Currency
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
+
+		price, err := parsePrice(amount, currency)
+		if err != nil {
+			return nil, err
+		}
+		if req.Price != nil {
+			normalized := json.Number(price.Decimal())
+			change.Price = &normalized
+		}
+		if req.Currency != nil {
+			change.Currency = &price.Currency
+		}
+	}
+
+	created, err := s.repo.Create(ctx, change)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Error("Failed to schedule product change")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithFields(fields).WithField("change_id", created.ID).Info("Successfully scheduled product change")
+	return created, nil
+}
+
+// GetScheduledChanges retrieves the changes scheduled for a product in the
+// order they take effect, including those already applied, failed or
+// cancelled
+func (s *scheduledChangeService) GetScheduledChanges(ctx context.Context, productID string) ([]*model.ScheduledChange, error) {
+	if !s.products.ProductExists(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+	return s.repo.GetByProductID(ctx, productID)
+}
+
+// CancelScheduledChange cancels a pending change of a product
+func (s *scheduledChangeService) CancelScheduledChange(ctx context.Context, productID, id string) (*model.ScheduledChange, error) {
+	fields := logger.Fields{
+		"product_id": productID,
+		"change_id":  id,
+	}
+	log.Ctx(ctx).WithFields(fields).Debug("Cancelling scheduled product change")
+
+	change, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if change.ProductID != productID {
+		return nil, model.ErrScheduledChangeNotFound
+	}
+
+	cancelled, err := s.repo.Transition(ctx, id, model.ScheduledChangeCancelled, s.now(), "")
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(fields).Warn("Failed to cancel scheduled product change")
+		return nil, err
+	}
+
+	log.Ctx(ctx).WithFields(fields).Info("Successfully cancelled scheduled product change")
+	return cancelled, nil
+}
+
+// ApplyDue makes the tenant's pending changes that are due, oldest effective
+// time first, and reports how many were applied. Changes the product update
+// rejects are marked failed with the reason.
+func (s *scheduledChangeService) ApplyDue(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.repo.GetDue(ctx, now)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get due scheduled product changes")
+		return 0, err
+	}
+
+	applied := 0
+	for _, change := range due {
+		// A change cancelled meanwhile is left alone
+		change, err := s.repo.Transition(ctx, change.ID, model.ScheduledChangeApplying, now, "")
+		if err != nil {
+			continue
+		}
+
+		fields := logger.Fields{
+			"product_id": change.ProductID,
+			"change_id":  change.ID,
+		}
+		if _, err := s.products.UpdateProduct(ctx, change.ProductID, change.UpdateRequest()); err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(fields).Warn("Failed to apply scheduled product change")
+			s.finish(ctx, change.ID, model.ScheduledChangeFailed, err.Error())
+			continue
+		}
+		s.finish(ctx, change.ID, model.ScheduledChangeApplied, "")
+		applied++
+	}
+
+	if applied > 0 {
+		log.Ctx(ctx).WithField("applied", applied).Info("Applied scheduled product changes")
+	}
+	return applied, nil
+}
+
+// finish records the outcome of a change being applied. The product has
+// already been updated or not, so a failure is just logged.
+func (s *scheduledChangeService) finish(ctx context.Context, id string, to model.ScheduledChangeStatus, failure string) {
+	if _, err := s.repo.Transition(ctx, id, to, s.now(), failure); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("change_id", id).Error("Failed to record outcome of scheduled product change")
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestScheduledChangeService returns a scheduled change service over the
+// sample products whose clock is read from now
+func newTestScheduledChangeService(now *time.Time, publisher events.Publisher) (*scheduledChangeService, ProductService) {
+	repo := repository.NewMemoryProductRepository()
+	products := NewProductService(repo, repo, newMockCategories(), "USD", publisher)
+	service := NewScheduledChangeService(repository.NewMemoryScheduledChangeRepository(), products).(*scheduledChangeService)
+	service.now = func() time.Time { return *now }
+	return service, products
+}
+
+func TestScheduledChangeService_ScheduleChange(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	price := json.Number("24.5")
+
+	t.Run("Schedule a price change", func(t *testing.T) {
+		// Arrange
+		service, products := newTestScheduledChangeService(&now, nil)
+
+		// Act
+		change, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: midnight})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.ScheduledChangePending, change.Status)
+		assert.Equal(t, midnight, change.EffectiveAt)
+		assert.Equal(t, json.Number("24.50"), *change.Price)
+		assert.Nil(t, change.Currency)
+		product, err := products.GetProductByID(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("29.99"), product.Price)
+	})
+
+	t.Run("Nothing to change", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestScheduledChangeService(&now, nil)
+
+		// Act
+		_, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{EffectiveAt: midnight})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrScheduledChangeEmpty)
+	})
+
+	t.Run("Effective time in the past", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestScheduledChangeService(&now, nil)
+
+		// Act
+		_, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: now})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrEffectiveAtNotFuture)
+	})
+
+	t.Run("Price too precise for the currency", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestScheduledChangeService(&now, nil)
+		yen := "JPY"
+
+		// Act
+		_, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Currency: &yen, EffectiveAt: midnight})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrPriceTooPrecise)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestScheduledChangeService(&now, nil)
+
+		// Act
+		_, err := service.ScheduleChange(context.Background(), "product-999", model.ScheduleChangeRequest{Price: &price, EffectiveAt: midnight})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
+func TestScheduledChangeService_ApplyDue(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	price := json.Number("24.50")
+	inactive := false
+
+	t.Run("Apply changes once they are due", func(t *testing.T) {
+		// Arrange
+		now := start
+		publisher := events.NewMemoryPublisher()
+		service, products := newTestScheduledChangeService(&now, publisher)
+		scheduled, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: midnight})
+		require.NoError(t, err)
+		_, err = service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Active: &inactive, EffectiveAt: midnight.Add(time.Hour)})
+		require.NoError(t, err)
+
+		// Act
+		beforeMidnight, err := service.ApplyDue(context.Background())
+		require.NoError(t, err)
+		now = midnight
+		atMidnight, err := service.ApplyDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 0, beforeMidnight)
+		assert.Equal(t, 1, atMidnight)
+		product, err := products.GetProductByID(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("24.50"), product.Price)
+		assert.True(t, product.Active)
+		assert.Equal(t, []string{events.ProductUpdated}, publisher.Types())
+		changes, err := service.GetScheduledChanges(context.Background(), "product-001")
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		assert.Equal(t, scheduled.ID, changes[0].ID)
+		assert.Equal(t, model.ScheduledChangeApplied, changes[0].Status)
+		assert.Equal(t, model.ScheduledChangePending, changes[1].Status)
+	})
+
+	t.Run("Skip cancelled changes", func(t *testing.T) {
+		// Arrange
+		now := start
+		service, products := newTestScheduledChangeService(&now, nil)
+		scheduled, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: midnight})
+		require.NoError(t, err)
+		_, err = service.CancelScheduledChange(context.Background(), "product-001", scheduled.ID)
+		require.NoError(t, err)
+		now = midnight
+
+		// Act
+		applied, err := service.ApplyDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+		product, err := products.GetProductByID(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("29.99"), product.Price)
+	})
+
+	t.Run("Fail changes to deleted products", func(t *testing.T) {
+		// Arrange
+		now := start
+		service, products := newTestScheduledChangeService(&now, nil)
+		scheduled, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: midnight})
+		require.NoError(t, err)
+		require.NoError(t, products.DeleteProduct(context.Background(), "product-001"))
+		now = midnight
+
+		// Act
+		applied, err := service.ApplyDue(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+		change, err := service.repo.GetByID(context.Background(), scheduled.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.ScheduledChangeFailed, change.Status)
+		assert.Equal(t, model.ErrProductNotFound.Error(), change.Failure)
+	})
+}
+
+func TestScheduledChangeService_CancelScheduledChange(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	price := json.Number("24.50")
+
+	t.Run("Cancel a pending change", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestScheduledChangeService(&now, nil)
+		scheduled, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+
+		// Act
+		cancelled, err := service.CancelScheduledChange(context.Background(), "product-001", scheduled.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, model.ScheduledChangeCancelled, cancelled.Status)
+
+		t.Run("Twice", func(t *testing.T) {
+			// Act
+			_, err := service.CancelScheduledChange(context.Background(), "product-001", scheduled.ID)
+
+			// Assert
+			assert.ErrorIs(t, err, model.ErrScheduledChangeNotPending)
+		})
+	})
+
+	t.Run("Change of another product", func(t *testing.T) {
+		// Arrange
+		service, _ := newTestScheduledChangeService(&now, nil)
+		scheduled, err := service.ScheduleChange(context.Background(), "product-001", model.ScheduleChangeRequest{Price: &price, EffectiveAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+
+		// Act
+		_, err = service.CancelScheduledChange(context.Background(), "product-002", scheduled.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrScheduledChangeNotFound)
+	})
+}
@@ -1,59 +1,135 @@
 package service
 
 import (
+	"context"
 	"errors"
-	"math/big"
+	"io"
+	"strings"
 
 	"external-apis/internal/product/model"
 	"external-apis/internal/product/repository"
-	"github.com/sirupsen/logrus"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/importer"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/patch"
 )
 
+var log = logger.New("product/service")
+
 // ProductService defines the interface for product business logic
 type ProductService interface {
-	GetProductByID(id string) (*model.ProductResponse, error)
-	GetAllProducts() ([]*model.ProductResponse, error)
-	CreateProduct(req model.CreateProductRequest) (*model.ProductResponse, error)
-	UpdateProduct(id string, req model.UpdateProductRequest) (*model.ProductResponse, error)
-	DeleteProduct(id string) error
-	ProductExists(id string) bool
+	GetProductByID(ctx context.Context, id string) (*model.ProductResponse, error)
+	GetProductBySKU(ctx context.Context, sku string) (*model.ProductResponse, error)
+	GetProductByGTIN(ctx context.Context, gtin string) (*model.ProductResponse, error)
+	GetAllProducts(ctx context.Context) ([]*model.ProductResponse, error)
+	BatchGetProducts(ctx context.Context, ids []string) (*batch.Response, error)
+	ListProducts(ctx context.Context, filter model.ProductFilter) ([]*model.ProductResponse, pagination.Meta, error)
+	SearchProducts(ctx context.Context, query model.ProductSearch) ([]*model.ProductSearchResult, pagination.Meta, error)
+	CreateProduct(ctx context.Context, req model.CreateProductRequest) (*model.ProductResponse, error)
+	UpdateProduct(ctx context.Context, id string, req model.UpdateProductRequest) (*model.ProductResponse, error)
+	PatchProduct(ctx context.Context, id string, p patch.Patch) (*model.ProductResponse, error)
+	DeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*model.ProductResponse, error)
+	ProductExists(ctx context.Context, id string) bool
+	ReserveStock(ctx context.Context, id string, req model.StockRequest) (*model.ProductResponse, error)
+	ReleaseStock(ctx context.Context, id string, req model.StockRequest) (*model.ProductResponse, error)
+	AdjustStock(ctx context.Context, id string, req model.AdjustStockRequest) (*model.ProductResponse, error)
+	BulkProducts(ctx context.Context, req model.BulkProductRequest) (*bulk.Response, error)
+	ImportProducts(ctx context.Context, format, mode string, file io.Reader) (*importer.Report, error)
+	ApplyCatalogUpdate(ctx context.Context, update model.CatalogUpdate) (string, error)
+	ExportProducts(ctx context.Context, filter model.ProductFilter, fn func(*model.ProductResponse) error) error
+	GetProductStats(ctx context.Context) (*model.ProductStats, error)
 }
 
+// MaxSearchQueryLength is the longest search query accepted, in bytes
+const MaxSearchQueryLength = 256
+
+// maxCategoryDepth bounds the walk up the category tree for the attribute
+// schema of a product
+const maxCategoryDepth = 32
+
 // productService implements ProductService
 type productService struct {
-	repo repository.ProductRepository
+	repo            repository.ProductRepository
+	search          repository.SearchRepository
+	categories      repository.CategoryRepository
+	defaultCurrency string
+	events          events.Publisher
 }
 
-// NewProductService creates a new product service
-func NewProductService(repo repository.ProductRepository) ProductService {
+// NewProductService creates a new product service. Full-text searches go to
+// search and products must belong to one of categories. Products created without a currency are priced in defaultCurrency,
+// or money.DefaultCurrency if it is empty. Change events go to events, which
+// may be nil.
+func NewProductService(repo repository.ProductRepository, search repository.SearchRepository, categories repository.CategoryRepository, defaultCurrency string, events events.Publisher) ProductService {
+	if defaultCurrency == "" {
+		defaultCurrency = money.DefaultCurrency
+	}
+
 	return &productService{
-		repo: repo,
+		repo:            repo,
+		search:          search,
+		categories:      categories,
+		defaultCurrency: defaultCurrency,
+		events:          events,
 	}
 }
 
 // GetProductByID retrieves a product by ID
-func (s *productService) GetProductByID(id string) (*model.ProductResponse, error) {
-	logrus.WithField("product_id", id).Debug("Getting product by ID")
+func (s *productService) GetProductByID(ctx context.Context, id string) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithField("product_id", id).Debug("Getting product by ID")
 
-	product, err := s.repo.GetByID(id)
+	product, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		logrus.WithError(err).WithField("product_id", id).Error("Failed to get product")
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Error("Failed to get product")
 		return nil, err
 	}
 
 	response := product.ToResponse()
-	logrus.WithField("product_id", id).Debug("Successfully retrieved product")
+	log.Ctx(ctx).WithField("product_id", id).Debug("Successfully retrieved product")
 
 	return &response, nil
 }
 
+// GetProductBySKU retrieves a product by its SKU, ignoring case
+func (s *productService) GetProductBySKU(ctx context.Context, sku string) (*model.ProductResponse, error) {
+	product, err := s.repo.GetBySKU(ctx, strings.TrimSpace(sku))
+	if err != nil {
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
+// GetProductByGTIN retrieves a product by its barcode. A GTIN matches in any
+// of its lengths, e.g. a UPC-A also finds the product with the same EAN-13.
+func (s *productService) GetProductByGTIN(ctx context.Context, gtin string) (*model.ProductResponse, error) {
+	gtin, err := model.NormalizeGTIN(gtin)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.GetByGTIN(ctx, gtin)
+	if err != nil {
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
 // GetAllProducts retrieves all products
-func (s *productService) GetAllProducts() ([]*model.ProductResponse, error) {
-	logrus.Debug("Getting all products")
+func (s *productService) GetAllProducts(ctx context.Context) ([]*model.ProductResponse, error) {
+	log.Ctx(ctx).Debug("Getting all products")
 
-	products, err := s.repo.GetAll()
+	products, err := s.repo.GetAll(ctx)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to get all products")
+		log.Ctx(ctx).WithError(err).Error("Failed to get all products")
 		return nil, err
 	}
 
@@ -63,107 +139,528 @@ func (s *productService) GetAllProducts() ([]*model.ProductResponse, error) {
 		responses[i] = &response
 	}
 
-	logrus.WithField("count", len(responses)).Debug("Successfully retrieved all products")
+	log.Ctx(ctx).WithField("count", len(responses)).Debug("Successfully retrieved all products")
 	return responses, nil
 }
 
+// ListProducts retrieves a filtered, sorted page of products
+func (s *productService) ListProducts(ctx context.Context, filter model.ProductFilter) ([]*model.ProductResponse, pagination.Meta, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"limit":  filter.Page.Limit,
+		"offset": filter.Page.Offset,
+		"sort":   filter.Sort,
+	}).Debug("Listing products")
+
+	if !model.IsValidProductSort(filter.Sort) {
+		return nil, pagination.Meta{}, model.ErrInvalidSort
+	}
+
+	filter, err := s.withSubcategories(ctx, filter)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	products, total, err := s.repo.Find(ctx, filter)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to list products")
+		return nil, pagination.Meta{}, err
+	}
+
+	responses := make([]*model.ProductResponse, len(products))
+	for i, product := range products {
+		response := product.ToResponse()
+		responses[i] = &response
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"count": len(responses),
+		"total": total,
+	}).Debug("Successfully listed products")
+	return responses, filter.Page.Meta(total), nil
+}
+
+// SearchProducts ranks products matching a full-text query by relevance and
+// returns a page of results
+func (s *productService) SearchProducts(ctx context.Context, query model.ProductSearch) ([]*model.ProductSearchResult, pagination.Meta, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"query":  query.Query,
+		"limit":  query.Filter.Page.Limit,
+		"offset": query.Filter.Page.Offset,
+	}).Debug("Searching products")
+
+	query.Query = strings.TrimSpace(query.Query)
+	if query.Query == "" {
+		return nil, pagination.Meta{}, model.ErrSearchQueryEmpty
+	}
+	if len(query.Query) > MaxSearchQueryLength {
+		return nil, pagination.Meta{}, model.ErrSearchQueryLong
+	}
+
+	filter, err := s.withSubcategories(ctx, query.Filter)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	query.Filter = filter
+
+	hits, total, err := s.search.Search(ctx, query)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to search products")
+		return nil, pagination.Meta{}, err
+	}
+
+	results := make([]*model.ProductSearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = &model.ProductSearchResult{
+			ProductResponse: hit.Product.ToResponse(),
+			Score:           hit.Score,
+		}
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"count": len(results),
+		"total": total,
+	}).Debug("Successfully searched products")
+	return results, query.Filter.Page.Meta(total), nil
+}
+
 // CreateProduct creates a new product
-func (s *productService) CreateProduct(req model.CreateProductRequest) (*model.ProductResponse, error) {
-	logrus.WithFields(logrus.Fields{
-		"name":     req.Name,
-		"category": req.Category,
-		"price":    req.Price,
+func (s *productService) CreateProduct(ctx context.Context, req model.CreateProductRequest) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"name":        req.Name,
+		"category_id": req.CategoryID,
+		"price":       req.Price,
+		"currency":    req.Currency,
 	}).Debug("Creating new product")
 
 	// Validate price
-	if req.Price <= 0 {
-		return nil, errors.New("price must be greater than 0")
+	currency := req.Currency
+	if currency == "" {
+		currency = s.defaultCurrency
+	}
+	price, err := parsePrice(req.Price.String(), currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate stock
+	if req.StockQuantity < 0 {
+		return nil, model.ErrNegativeStock
+	}
+
+	// Validate barcode
+	gtin, err := normalizeGTIN(req.GTIN)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate shipping measurements
+	if err := validateMeasurements(req.WeightGrams, req.Dimensions); err != nil {
+		return nil, err
+	}
+
+	// Validate category
+	category, err := s.lookupCategory(ctx, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate tags and attributes
+	tags, err := model.NormalizeTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := s.validateAttributes(ctx, category, req.Attributes)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create product model
 	product := &model.Product{
+		SKU:         strings.TrimSpace(req.SKU),
+		GTIN:        gtin,
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       big.NewRat(1, 1),
-		Category:    req.Category,
+		Price:       price,
+		CategoryID:  category.ID,
+		Category:    category.Name,
 		Active:      true, // New products are active by default
-	}
+		Tags:        tags,
+		Attributes:  attributes,
+		WeightGrams: req.WeightGrams,
+		Dimensions:  req.Dimensions,
 
-	// Set price as rational number
-	product.Price.SetFloat64(req.Price)
+		StockQuantity: req.StockQuantity,
+	}
 
 	// Save product
-	createdProduct, err := s.repo.Create(product)
+	createdProduct, err := s.repo.Create(ctx, product)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create product")
+		log.Ctx(ctx).WithError(err).Error("Failed to create product")
 		return nil, err
 	}
 
 	response := createdProduct.ToResponse()
-	logrus.WithField("product_id", createdProduct.ID).Info("Successfully created product")
+	s.publish(ctx, events.ProductCreated, response.ID, response)
+	log.Ctx(ctx).WithField("product_id", createdProduct.ID).Info("Successfully created product")
 
 	return &response, nil
 }
 
 // UpdateProduct updates an existing product
-func (s *productService) UpdateProduct(id string, req model.UpdateProductRequest) (*model.ProductResponse, error) {
-	logrus.WithField("product_id", id).Debug("Updating product")
+func (s *productService) UpdateProduct(ctx context.Context, id string, req model.UpdateProductRequest) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithField("product_id", id).Debug("Updating product")
 
 	// Get existing product
-	existingProduct, err := s.repo.GetByID(id)
+	existingProduct, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Error("Product not found for update")
+		return nil, err
+	}
+	return s.update(ctx, id, existingProduct, req)
+}
+
+// PatchProduct applies a merge patch or JSON Patch to the fields of an
+// existing product. Fields the patch leaves as they are stay untouched;
+// removed optional fields are cleared and the others are updated as
+// UpdateProduct updates them.
+func (s *productService) PatchProduct(ctx context.Context, id string, p patch.Patch) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithField("product_id", id).Debug("Patching product")
+
+	existingProduct, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		logrus.WithError(err).WithField("product_id", id).Error("Product not found for update")
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Error("Product not found for patch")
+		return nil, err
+	}
+
+	var doc model.ProductDocument
+	if err := patch.ApplyTo(p, existingProduct.Document(), &doc); err != nil {
 		return nil, err
 	}
+	return s.update(ctx, id, existingProduct, doc.UpdateRequest(existingProduct))
+}
 
+// update applies the update to the existing product and saves it
+func (s *productService) update(ctx context.Context, id string, existingProduct *model.Product, req model.UpdateProductRequest) (*model.ProductResponse, error) {
 	// Update fields if provided
+	if req.SKU != nil {
+		existingProduct.SKU = strings.TrimSpace(*req.SKU)
+	}
+	if req.GTIN != nil {
+		gtin, err := normalizeGTIN(*req.GTIN)
+		if err != nil {
+			return nil, err
+		}
+		existingProduct.GTIN = gtin
+	}
 	if req.Name != nil {
 		existingProduct.Name = *req.Name
 	}
 	if req.Description != nil {
 		existingProduct.Description = *req.Description
 	}
-	if req.Price != nil {
-		if *req.Price <= 0 {
-			return nil, errors.New("price must be greater than 0")
+	if req.Price != nil || req.Currency != nil {
+		amount := existingProduct.Price.Decimal()
+		if req.Price != nil {
+			amount = req.Price.String()
 		}
-		existingProduct.Price.SetFloat64(*req.Price)
-	}
-	if req.Category != nil {
-		existingProduct.Category = *req.Category
+		currency := existingProduct.Price.Currency
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
+
+		price, err := parsePrice(amount, currency)
+		if err != nil {
+			return nil, err
+		}
+		existingProduct.Price = price
 	}
 	if req.Active != nil {
 		existingProduct.Active = *req.Active
 	}
+	if req.ClearDimensions && req.Dimensions != nil {
+		return nil, model.ErrClearDimensions
+	}
+	if req.WeightGrams != nil || req.Dimensions != nil || req.ClearDimensions {
+		weight, dimensions := existingProduct.WeightGrams, existingProduct.Dimensions
+		if req.WeightGrams != nil {
+			weight = *req.WeightGrams
+		}
+		if req.Dimensions != nil {
+			dimensions = req.Dimensions
+		}
+		if req.ClearDimensions {
+			dimensions = nil
+		}
+		if err := validateMeasurements(weight, dimensions); err != nil {
+			return nil, err
+		}
+		existingProduct.WeightGrams, existingProduct.Dimensions = weight, dimensions
+	}
+	if req.Tags != nil {
+		tags, err := model.NormalizeTags(*req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		existingProduct.Tags = tags
+	}
+	if req.CategoryID != nil || req.Attributes != nil {
+		categoryID, attributes := existingProduct.CategoryID, existingProduct.Attributes
+		if req.CategoryID != nil {
+			categoryID = *req.CategoryID
+		}
+		if req.Attributes != nil {
+			attributes = *req.Attributes
+		}
+
+		category, err := s.lookupCategory(ctx, categoryID)
+		if err != nil {
+			return nil, err
+		}
+		if existingProduct.Attributes, err = s.validateAttributes(ctx, category, attributes); err != nil {
+			return nil, err
+		}
+		existingProduct.CategoryID = category.ID
+		existingProduct.Category = category.Name
+	}
 
 	// Save updated product
-	updatedProduct, err := s.repo.Update(id, existingProduct)
+	updatedProduct, err := s.repo.Update(ctx, id, existingProduct)
 	if err != nil {
-		logrus.WithError(err).WithField("product_id", id).Error("Failed to update product")
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Error("Failed to update product")
 		return nil, err
 	}
 
 	response := updatedProduct.ToResponse()
-	logrus.WithField("product_id", id).Info("Successfully updated product")
+	s.publish(ctx, events.ProductUpdated, response.ID, response)
+	log.Ctx(ctx).WithField("product_id", id).Info("Successfully updated product")
 
 	return &response, nil
 }
 
 // DeleteProduct deletes a product
-func (s *productService) DeleteProduct(id string) error {
-	logrus.WithField("product_id", id).Debug("Deleting product")
+func (s *productService) DeleteProduct(ctx context.Context, id string) error {
+	log.Ctx(ctx).WithField("product_id", id).Debug("Deleting product")
 
-	err := s.repo.Delete(id)
+	err := s.repo.Delete(ctx, id)
 	if err != nil {
-		logrus.WithError(err).WithField("product_id", id).Error("Failed to delete product")
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Error("Failed to delete product")
 		return err
 	}
 
-	logrus.WithField("product_id", id).Info("Successfully deleted product")
+	s.publish(ctx, events.ProductDeleted, id, map[string]string{"id": id})
+
+	log.Ctx(ctx).WithField("product_id", id).Info("Successfully deleted product")
 	return nil
 }
 
+// RestoreProduct restores a soft-deleted product
+func (s *productService) RestoreProduct(ctx context.Context, id string) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithField("product_id", id).Debug("Restoring product")
+
+	restoredProduct, err := s.repo.Restore(ctx, id)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Error("Failed to restore product")
+		return nil, err
+	}
+
+	response := restoredProduct.ToResponse()
+	s.publish(ctx, events.ProductRestored, response.ID, response)
+	log.Ctx(ctx).WithField("product_id", id).Info("Successfully restored product")
+
+	return &response, nil
+}
+
 // ProductExists checks if a product exists
-func (s *productService) ProductExists(id string) bool {
-	return s.repo.ExistsByID(id)
+func (s *productService) ProductExists(ctx context.Context, id string) bool {
+	return s.repo.ExistsByID(ctx, id)
+}
+
+// GetProductStats summarizes the products that are not deleted
+func (s *productService) GetProductStats(ctx context.Context) (*model.ProductStats, error) {
+	stats, err := s.repo.Stats(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get product stats")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ReserveStock reserves units of a product for an order
+func (s *productService) ReserveStock(ctx context.Context, id string, req model.StockRequest) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": id,
+		"quantity":   req.Quantity,
+	}).Debug("Reserving product stock")
+
+	if req.Quantity <= 0 {
+		return nil, model.ErrInvalidQuantity
+	}
+
+	product, err := s.repo.ReserveStock(ctx, id, req.Quantity)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Warn("Failed to reserve product stock")
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	s.publish(ctx, events.ProductStockChanged, response.ID, response)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": id,
+		"available":  response.AvailableQuantity,
+	}).Info("Successfully reserved product stock")
+
+	return &response, nil
+}
+
+// ReleaseStock returns previously reserved units of a product
+func (s *productService) ReleaseStock(ctx context.Context, id string, req model.StockRequest) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": id,
+		"quantity":   req.Quantity,
+	}).Debug("Releasing product stock")
+
+	if req.Quantity <= 0 {
+		return nil, model.ErrInvalidQuantity
+	}
+
+	product, err := s.repo.ReleaseStock(ctx, id, req.Quantity)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Warn("Failed to release product stock")
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	s.publish(ctx, events.ProductStockChanged, response.ID, response)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": id,
+		"available":  response.AvailableQuantity,
+	}).Info("Successfully released product stock")
+
+	return &response, nil
+}
+
+// AdjustStock corrects the units of a product on hand
+func (s *productService) AdjustStock(ctx context.Context, id string, req model.AdjustStockRequest) (*model.ProductResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": id,
+		"delta":      req.Delta,
+		"reason":     req.Reason,
+	}).Debug("Adjusting product stock")
+
+	if req.Delta == 0 {
+		return nil, model.ErrZeroDelta
+	}
+
+	product, err := s.repo.AdjustStock(ctx, id, req.Delta)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", id).Warn("Failed to adjust product stock")
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	s.publish(ctx, events.ProductStockChanged, response.ID, response)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": id,
+		"stock":      response.StockQuantity,
+		"reason":     req.Reason,
+	}).Info("Successfully adjusted product stock")
+
+	return &response, nil
+}
+
+// parsePrice converts a decimal amount into a positive price in the currency
+func parsePrice(amount string, currency string) (money.Money, error) {
+	price, err := money.Parse(amount, currency)
+	switch {
+	case errors.Is(err, money.ErrUnknownCurrency):
+		return money.Money{}, model.ErrUnsupportedCurrency
+	case errors.Is(err, money.ErrTooPrecise):
+		return money.Money{}, model.ErrPriceTooPrecise
+	case err != nil:
+		return money.Money{}, model.ErrInvalidPrice
+	case !price.IsPositive():
+		return money.Money{}, model.ErrPriceNotPositive
+	}
+	return price, nil
+}
+
+// validateMeasurements checks the shipping weight and optional dimensions
+// of a product
+func validateMeasurements(weightGrams int, dimensions *model.Dimensions) error {
+	if !model.ValidWeight(weightGrams) {
+		return model.ErrInvalidWeight
+	}
+	if dimensions != nil && !dimensions.Valid() {
+		return model.ErrInvalidDimensions
+	}
+	return nil
+}
+
+// normalizeGTIN validates an optional GTIN; an empty one removes the barcode
+func normalizeGTIN(gtin string) (string, error) {
+	if strings.TrimSpace(gtin) == "" {
+		return "", nil
+	}
+	return model.NormalizeGTIN(gtin)
+}
+
+// lookupCategory retrieves the category a product is assigned to
+func (s *productService) lookupCategory(ctx context.Context, id string) (*model.Category, error) {
+	category, err := s.categories.GetByID(ctx, id)
+	if errors.Is(err, model.ErrCategoryNotFound) {
+		return nil, model.ErrUnknownCategory
+	}
+	return category, err
+}
+
+// validateAttributes checks product attributes against the attribute schema
+// the category declares or inherits from its ancestors
+func (s *productService) validateAttributes(ctx context.Context, category *model.Category, attributes []model.Attribute) ([]model.Attribute, error) {
+	lineage := []*model.Category{category}
+	for parentID := category.ParentID; parentID != "" && len(lineage) <= maxCategoryDepth; {
+		parent, err := s.categories.GetByID(ctx, parentID)
+		if errors.Is(err, model.ErrCategoryNotFound) {
+			break
+		}
+		if err != nil {
+			log.Ctx(ctx).WithError(err).WithField("category_id", parentID).Error("Failed to get parent category")
+			return nil, err
+		}
+		lineage = append([]*model.Category{parent}, lineage...)
+		parentID = parent.ParentID
+	}
+
+	return model.ValidateAttributes(model.InheritAttributeSchema(lineage), attributes)
+}
+
+// withSubcategories widens the category IDs of a filter to their
+// subcategories, so filtering by a category also matches products filed
+// further down the tree
+func (s *productService) withSubcategories(ctx context.Context, filter model.ProductFilter) (model.ProductFilter, error) {
+	if len(filter.CategoryIDs) == 0 {
+		return filter, nil
+	}
+
+	categories, err := s.categories.GetAll(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get categories")
+		return model.ProductFilter{}, err
+	}
+
+	tree := model.NewCategoryTree(categories)
+	ids := make([]string, 0, len(filter.CategoryIDs))
+	for _, id := range filter.CategoryIDs {
+		ids = append(ids, tree.Descendants(id)...)
+	}
+	filter.CategoryIDs = ids
+
+	return filter, nil
+}
+
+// publish sends a product lifecycle event if a publisher is configured
+func (s *productService) publish(ctx context.Context, eventType string, productID string, data interface{}) {
+	if s.events != nil {
+		s.events.Publish(events.New(eventType, productID, data).In(ctx))
+	}
 }
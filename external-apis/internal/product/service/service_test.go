@@ -1,11 +1,21 @@
 package service
 
 import (
-	"errors"
-	"math/big"
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/batch"
+	"external-apis/internal/shared/bulk"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/patch"
+	"external-apis/internal/shared/validation"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -16,7 +26,7 @@ type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) GetByID(id string) (*model.Product, error) {
+func (m *MockProductRepository) GetByID(ctx context.Context, id string) (*model.Product, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -24,12 +34,41 @@ func (m *MockProductRepository) GetByID(id string) (*model.Product, error) {
 	return args.Get(0).(*model.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) GetAll() ([]*model.Product, error) {
+func (m *MockProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*model.Product, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	args := m.Called(sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByGTIN(ctx context.Context, gtin string) (*model.Product, error) {
+	args := m.Called(gtin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetAll(ctx context.Context) ([]*model.Product, error) {
 	args := m.Called()
 	return args.Get(0).([]*model.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Create(product *model.Product) (*model.Product, error) {
+func (m *MockProductRepository) Find(ctx context.Context, filter model.ProductFilter) ([]*model.Product, int, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]*model.Product), args.Int(1), args.Error(2)
+}
+
+func (m *MockProductRepository) Create(ctx context.Context, product *model.Product) (*model.Product, error) {
 	args := m.Called(product)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +76,7 @@ func (m *MockProductRepository) Create(product *model.Product) (*model.Product,
 	return args.Get(0).(*model.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Update(id string, product *model.Product) (*model.Product, error) {
+func (m *MockProductRepository) Update(ctx context.Context, id string, product *model.Product) (*model.Product, error) {
 	args := m.Called(id, product)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -45,27 +84,133 @@ func (m *MockProductRepository) Update(id string, product *model.Product) (*mode
 	return args.Get(0).(*model.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Delete(id string) error {
+func (m *MockProductRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) ExistsByID(id string) bool {
+func (m *MockProductRepository) Restore(ctx context.Context, id string) (*model.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) ExistsByID(ctx context.Context, id string) bool {
 	args := m.Called(id)
 	return args.Bool(0)
 }
 
+func (m *MockProductRepository) ReserveStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	args := m.Called(id, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) ReleaseStock(ctx context.Context, id string, quantity int) (*model.Product, error) {
+	args := m.Called(id, quantity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AdjustStock(ctx context.Context, id string, delta int) (*model.Product, error) {
+	args := m.Called(id, delta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) RenameCategory(ctx context.Context, categoryID, name string) ([]*model.Product, error) {
+	args := m.Called(categoryID, name)
+	return args.Get(0).([]*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AddImage(ctx context.Context, id string, image model.ProductImage) (*model.Product, error) {
+	args := m.Called(id, image)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) RemoveImage(ctx context.Context, id, imageID string) (*model.Product, error) {
+	args := m.Called(id, imageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) AddVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	args := m.Called(id, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) UpdateVariant(ctx context.Context, id string, variant model.ProductVariant) (*model.Product, error) {
+	args := m.Called(id, variant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) RemoveVariant(ctx context.Context, id, variantID string) (*model.Product, error) {
+	args := m.Called(id, variantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) SetRating(ctx context.Context, id string, rating model.ProductRating) (*model.Product, error) {
+	args := m.Called(id, rating)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Stats(ctx context.Context) (*model.ProductStats, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ProductStats), args.Error(1)
+}
+
+func (m *MockProductRepository) Transaction(ctx context.Context, fn func(tx repository.ProductRepository) error) error {
+	m.Called()
+	return fn(m)
+}
+
+func (m *MockProductRepository) Search(ctx context.Context, query model.ProductSearch) ([]model.ProductSearchHit, int, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]model.ProductSearchHit), args.Int(1), args.Error(2)
+}
+
 func TestProductService_GetProductByID(t *testing.T) {
 	t.Run("Get existing product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		expectedProduct := &model.Product{
 			ID:          "product-123",
 			Name:        "Test Product",
 			Description: "Test Description",
-			Price:       big.NewRat(9999, 100),
+			Price:       money.New(9999, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		}
@@ -73,25 +218,25 @@ func TestProductService_GetProductByID(t *testing.T) {
 		mockRepo.On("GetByID", "product-123").Return(expectedProduct, nil)
 
 		// Act
-		result, err := service.GetProductByID("product-123")
+		result, err := service.GetProductByID(context.Background(), "product-123")
 
 		// Assert
 		require.NoError(t, err)
 		assert.Equal(t, "product-123", result.ID)
 		assert.Equal(t, "Test Product", result.Name)
-		assert.Equal(t, 99.99, result.Price)
+		assert.Equal(t, json.Number("99.99"), result.Price)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Get non-existing product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
-		mockRepo.On("GetByID", "non-existing").Return(nil, errors.New("product not found"))
+		mockRepo.On("GetByID", "non-existing").Return(nil, model.ErrProductNotFound)
 
 		// Act
-		result, err := service.GetProductByID("non-existing")
+		result, err := service.GetProductByID(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -104,14 +249,14 @@ func TestProductService_GetProductByID(t *testing.T) {
 func TestProductService_GetAllProducts(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 	expectedProducts := []*model.Product{
 		{
 			ID:          "product-1",
 			Name:        "Product 1",
 			Description: "Description 1",
-			Price:       big.NewRat(1000, 100),
+			Price:       money.New(1000, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		},
@@ -119,7 +264,7 @@ func TestProductService_GetAllProducts(t *testing.T) {
 			ID:          "product-2",
 			Name:        "Product 2",
 			Description: "Description 2",
-			Price:       big.NewRat(2000, 100),
+			Price:       money.New(2000, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		},
@@ -128,7 +273,7 @@ func TestProductService_GetAllProducts(t *testing.T) {
 	mockRepo.On("GetAll").Return(expectedProducts, nil)
 
 	// Act
-	result, err := service.GetAllProducts()
+	result, err := service.GetAllProducts(context.Background())
 
 	// Assert
 	require.NoError(t, err)
@@ -138,34 +283,191 @@ func TestProductService_GetAllProducts(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_BatchGetProducts(t *testing.T) {
+	t.Run("Report every requested ID in request order", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		mockRepo.On("GetByIDs", []string{"product-2", "product-missing", "product-1"}).Return([]*model.Product{
+			{ID: "product-1", Name: "Product 1", Price: money.New(1000, "USD")},
+			{ID: "product-2", Name: "Product 2", Price: money.New(2000, "USD")},
+		}, nil)
+
+		// Act
+		result, err := service.BatchGetProducts(context.Background(), []string{"product-2", "product-missing", "product-2", "product-1"})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result.Results, 3)
+		assert.Equal(t, "product-2", result.Results[0].ID)
+		assert.Equal(t, batch.StatusFound, result.Results[0].Status)
+		assert.Equal(t, "Product 2", result.Results[0].Data.(*model.ProductResponse).Name)
+		assert.Equal(t, batch.Result{ID: "product-missing", Status: batch.StatusNotFound}, result.Results[1])
+		assert.Equal(t, "product-1", result.Results[2].ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject a request without IDs", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		// Act
+		result, err := service.BatchGetProducts(context.Background(), []string{" ", ""})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, batch.ErrIDsRequired)
+		mockRepo.AssertNotCalled(t, "GetByIDs", mock.Anything)
+	})
+}
+
+func TestProductService_ListProducts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+	filter := model.ProductFilter{Page: pagination.Params{Limit: 1, Offset: 1}}
+	page := []*model.Product{
+		{
+			ID:    "product-2",
+			Price: money.New(1000, "USD"),
+		},
+	}
+
+	mockRepo.On("Find", filter).Return(page, 3, nil)
+
+	// Act
+	result, meta, err := service.ListProducts(context.Background(), filter)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "product-2", result[0].ID)
+	assert.Equal(t, pagination.Meta{Total: 3, Limit: 1, Offset: 1}, meta)
+	mockRepo.AssertExpectations(t)
+
+	t.Run("Invalid sort option", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		// Act
+		result, _, err := service.ListProducts(context.Background(), model.ProductFilter{Sort: "unknown"})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "invalid sort option", err.Error())
+		mockRepo.AssertNotCalled(t, "Find", mock.Anything)
+	})
+
+	t.Run("Category filter includes subcategories", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		categories := new(MockCategoryRepository)
+		service := NewProductService(mockRepo, mockRepo, categories, "USD", nil)
+
+		categories.On("GetAll").Return([]*model.Category{
+			{ID: "category-electronics", Name: "Electronics"},
+			{ID: "category-computers", Name: "Computers", ParentID: "category-electronics"},
+			{ID: "category-garden", Name: "Garden"},
+		}, nil)
+		mockRepo.On("Find", model.ProductFilter{
+			CategoryIDs: []string{"category-electronics", "category-computers"},
+		}).Return([]*model.Product{}, 0, nil)
+
+		// Act
+		_, _, err := service.ListProducts(context.Background(), model.ProductFilter{CategoryIDs: []string{"category-electronics"}})
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProductService_SearchProducts(t *testing.T) {
+	t.Run("Return ranked results with scores", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		query := model.ProductSearch{Query: "wireless", Filter: model.ProductFilter{Page: pagination.Params{Limit: 10}}}
+		hits := []model.ProductSearchHit{
+			{Product: &model.Product{ID: "product-001", Price: money.New(2999, "USD")}, Score: 4.2},
+			{Product: &model.Product{ID: "product-005", Price: money.New(19999, "USD")}, Score: 1.3},
+		}
+
+		mockRepo.On("Search", query).Return(hits, 2, nil)
+
+		// Act
+		result, meta, err := service.SearchProducts(context.Background(), model.ProductSearch{Query: "  wireless ", Filter: query.Filter})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.Equal(t, "product-001", result[0].ID)
+		assert.Equal(t, 4.2, result[0].Score)
+		assert.Equal(t, pagination.Meta{Total: 2, Limit: 10}, meta)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name  string
+		query string
+		err   string
+	}{
+		{name: "Empty query", query: "   ", err: "search query is required"},
+		{name: "Query too long", query: strings.Repeat("a", MaxSearchQueryLength+1), err: "search query is too long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockProductRepository)
+			service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+			// Act
+			result, _, err := service.SearchProducts(context.Background(), model.ProductSearch{Query: tt.query})
+
+			// Assert
+			assert.Nil(t, result)
+			assert.EqualError(t, err, tt.err)
+			assert.ErrorIs(t, err, apperror.ErrValidation)
+			mockRepo.AssertNotCalled(t, "Search", mock.Anything)
+		})
+	}
+}
+
 func TestProductService_CreateProduct(t *testing.T) {
 	t.Run("Create valid product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		request := model.CreateProductRequest{
 			Name:        "New Product",
 			Description: "New Description",
-			Price:       99.99,
-			Category:    "Electronics",
+			Price:       "99.99",
+			CategoryID:  "category-electronics",
 		}
 
 		expectedProduct := &model.Product{
 			ID:          "generated-id",
 			Name:        "New Product",
 			Description: "New Description",
-			Price:       big.NewRat(9999, 100),
+			Price:       money.New(9999, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		}
 
 		mockRepo.On("Create", mock.MatchedBy(func(p *model.Product) bool {
-			return p.Name == "New Product" && p.Active == true
+			return p.Name == "New Product" && p.Active == true &&
+				p.CategoryID == "category-electronics" && p.Category == "Electronics"
 		})).Return(expectedProduct, nil)
 
 		// Act
-		result, err := service.CreateProduct(request)
+		result, err := service.CreateProduct(context.Background(), request)
 
 		// Assert
 		require.NoError(t, err)
@@ -178,17 +480,17 @@ func TestProductService_CreateProduct(t *testing.T) {
 	t.Run("Create product with invalid price", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		request := model.CreateProductRequest{
 			Name:        "Invalid Product",
 			Description: "Invalid Description",
-			Price:       -10.0, // Invalid price
-			Category:    "Electronics",
+			Price:       "-10.0", // Invalid price
+			CategoryID:  "category-electronics",
 		}
 
 		// Act
-		result, err := service.CreateProduct(request)
+		result, err := service.CreateProduct(context.Background(), request)
 
 		// Assert
 		assert.Error(t, err)
@@ -197,20 +499,42 @@ func TestProductService_CreateProduct(t *testing.T) {
 		mockRepo.AssertNotCalled(t, "Create")
 	})
 
+	t.Run("Create product in unknown category", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		request := model.CreateProductRequest{
+			Name:        "New Product",
+			Description: "New Description",
+			Price:       "99.99",
+			CategoryID:  "Electronics",
+		}
+
+		// Act
+		result, err := service.CreateProduct(context.Background(), request)
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrUnknownCategory)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
+
 	t.Run("Create product with zero price", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		request := model.CreateProductRequest{
 			Name:        "Zero Price Product",
 			Description: "Zero Price Description",
-			Price:       0.0, // Invalid price
-			Category:    "Electronics",
+			Price:       "0.0", // Invalid price
+			CategoryID:  "category-electronics",
 		}
 
 		// Act
-		result, err := service.CreateProduct(request)
+		result, err := service.CreateProduct(context.Background(), request)
 
 		// Assert
 		assert.Error(t, err)
@@ -220,23 +544,72 @@ func TestProductService_CreateProduct(t *testing.T) {
 	})
 }
 
+func TestProductService_CreateProduct_Currency(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    json.Number
+		currency string
+		expected money.Money
+		err      string
+	}{
+		{name: "Configured default currency", price: "19.90", currency: "", expected: money.New(1990, "EUR")},
+		{name: "Explicit currency", price: "1500", currency: "jpy", expected: money.New(1500, "JPY")},
+		{name: "Too many decimal places", price: "19.999", currency: "USD", err: "price has too many decimal places for currency"},
+		{name: "Unsupported currency", price: "19.99", currency: "XYZ", err: "unsupported currency"},
+		{name: "Not a number", price: "cheap", currency: "USD", err: "invalid price"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockProductRepository)
+			service := NewProductService(mockRepo, mockRepo, newMockCategories(), "EUR", nil)
+
+			mockRepo.On("Create", mock.MatchedBy(func(p *model.Product) bool {
+				return p.Price == tt.expected
+			})).Return(&model.Product{ID: "generated-id", Price: tt.expected}, nil).Maybe()
+
+			request := model.CreateProductRequest{
+				Name:        "Priced Product",
+				Description: "Priced Description",
+				Price:       tt.price,
+				Currency:    tt.currency,
+				CategoryID:  "category-electronics",
+			}
+
+			// Act
+			result, err := service.CreateProduct(context.Background(), request)
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Number(), result.Price)
+			assert.Equal(t, tt.expected.Currency, result.Currency)
+		})
+	}
+}
+
 func TestProductService_UpdateProduct(t *testing.T) {
 	t.Run("Update existing product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		existingProduct := &model.Product{
 			ID:          "product-123",
 			Name:        "Old Name",
 			Description: "Old Description",
-			Price:       big.NewRat(5000, 100),
+			Price:       money.New(5000, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		}
 
 		newName := "New Name"
-		newPrice := 199.99
+		newPrice := json.Number("199.99")
 		updateRequest := model.UpdateProductRequest{
 			Name:  &newName,
 			Price: &newPrice,
@@ -246,7 +619,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 			ID:          "product-123",
 			Name:        "New Name",
 			Description: "Old Description",
-			Price:       big.NewRat(19999, 100),
+			Price:       money.New(19999, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		}
@@ -257,30 +630,30 @@ func TestProductService_UpdateProduct(t *testing.T) {
 		})).Return(updatedProduct, nil)
 
 		// Act
-		result, err := service.UpdateProduct("product-123", updateRequest)
+		result, err := service.UpdateProduct(context.Background(), "product-123", updateRequest)
 
 		// Assert
 		require.NoError(t, err)
 		assert.Equal(t, "New Name", result.Name)
-		assert.Equal(t, 199.99, result.Price)
+		assert.Equal(t, json.Number("199.99"), result.Price)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("Update with invalid price", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		existingProduct := &model.Product{
 			ID:          "product-123",
 			Name:        "Test Product",
 			Description: "Test Description",
-			Price:       big.NewRat(5000, 100),
+			Price:       money.New(5000, "USD"),
 			Category:    "Electronics",
 			Active:      true,
 		}
 
-		invalidPrice := -50.0
+		invalidPrice := json.Number("-50.0")
 		updateRequest := model.UpdateProductRequest{
 			Price: &invalidPrice,
 		}
@@ -288,7 +661,7 @@ func TestProductService_UpdateProduct(t *testing.T) {
 		mockRepo.On("GetByID", "product-123").Return(existingProduct, nil)
 
 		// Act
-		result, err := service.UpdateProduct("product-123", updateRequest)
+		result, err := service.UpdateProduct(context.Background(), "product-123", updateRequest)
 
 		// Assert
 		assert.Error(t, err)
@@ -300,17 +673,17 @@ func TestProductService_UpdateProduct(t *testing.T) {
 	t.Run("Update non-existing product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		newName := "New Name"
 		updateRequest := model.UpdateProductRequest{
 			Name: &newName,
 		}
 
-		mockRepo.On("GetByID", "non-existing").Return(nil, errors.New("product not found"))
+		mockRepo.On("GetByID", "non-existing").Return(nil, model.ErrProductNotFound)
 
 		// Act
-		result, err := service.UpdateProduct("non-existing", updateRequest)
+		result, err := service.UpdateProduct(context.Background(), "non-existing", updateRequest)
 
 		// Assert
 		assert.Error(t, err)
@@ -320,16 +693,117 @@ func TestProductService_UpdateProduct(t *testing.T) {
 	})
 }
 
+func TestProductService_PatchProduct(t *testing.T) {
+	validation.Register()
+	newExistingProduct := func() *model.Product {
+		return &model.Product{
+			ID:          "product-123",
+			SKU:         "MUG-1",
+			Name:        "Mug",
+			Description: "A mug",
+			Price:       money.New(999, "USD"),
+			CategoryID:  "category-electronics",
+			Category:    "Electronics",
+			Active:      true,
+			Tags:        []string{"sale"},
+			WeightGrams: 350,
+			Dimensions:  &model.Dimensions{LengthMM: 120, WidthMM: 90, HeightMM: 100},
+		}
+	}
+
+	t.Run("Clear the fields set to null and keep the omitted ones", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		mockRepo.On("GetByID", "product-123").Return(newExistingProduct(), nil)
+		mockRepo.On("Update", "product-123", mock.MatchedBy(func(p *model.Product) bool {
+			return p.Name == "Large Mug" && p.SKU == "" && len(p.Tags) == 0 && p.Dimensions == nil &&
+				p.Description == "A mug" && p.WeightGrams == 350 && p.Price == money.New(999, "USD")
+		})).Return(newExistingProduct(), nil)
+
+		// Act
+		_, err := service.PatchProduct(context.Background(), "product-123",
+			patch.MergePatch(`{"name":"Large Mug","sku":null,"tags":null,"dimensions":null}`))
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Apply JSON Patch operations", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		mockRepo.On("GetByID", "product-123").Return(newExistingProduct(), nil)
+		mockRepo.On("Update", "product-123", mock.MatchedBy(func(p *model.Product) bool {
+			return assert.ObjectsAreEqual([]string{"sale", "eco"}, p.Tags) && p.Price == money.New(1250, "USD")
+		})).Return(newExistingProduct(), nil)
+
+		// Act
+		_, err := service.PatchProduct(context.Background(), "product-123", patch.JSONPatch{
+			{Op: "test", Path: "/price", Value: json.RawMessage(`9.99`)},
+			{Op: "replace", Path: "/price", Value: json.RawMessage(`12.50`)},
+			{Op: "add", Path: "/tags/-", Value: json.RawMessage(`"eco"`)},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Reject removing a required field", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		mockRepo.On("GetByID", "product-123").Return(newExistingProduct(), nil)
+
+		// Act
+		result, err := service.PatchProduct(context.Background(), "product-123", patch.MergePatch(`{"price":null}`))
+
+		// Assert
+		assert.ErrorIs(t, err, patch.ErrInvalidDocument)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reject read-only fields", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		mockRepo.On("GetByID", "product-123").Return(newExistingProduct(), nil)
+
+		// Act
+		_, err := service.PatchProduct(context.Background(), "product-123", patch.MergePatch(`{"stockQuantity":100}`))
+
+		// Assert
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Patch non-existing product", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		mockRepo.On("GetByID", "non-existing").Return(nil, model.ErrProductNotFound)
+
+		// Act
+		_, err := service.PatchProduct(context.Background(), "non-existing", patch.MergePatch(`{"name":"Mug"}`))
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
 func TestProductService_DeleteProduct(t *testing.T) {
 	t.Run("Delete existing product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 		mockRepo.On("Delete", "product-123").Return(nil)
 
 		// Act
-		err := service.DeleteProduct("product-123")
+		err := service.DeleteProduct(context.Background(), "product-123")
 
 		// Assert
 		require.NoError(t, err)
@@ -339,12 +813,12 @@ func TestProductService_DeleteProduct(t *testing.T) {
 	t.Run("Delete non-existing product", func(t *testing.T) {
 		// Arrange
 		mockRepo := new(MockProductRepository)
-		service := NewProductService(mockRepo)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
-		mockRepo.On("Delete", "non-existing").Return(errors.New("product not found"))
+		mockRepo.On("Delete", "non-existing").Return(model.ErrProductNotFound)
 
 		// Act
-		err := service.DeleteProduct("non-existing")
+		err := service.DeleteProduct(context.Background(), "non-existing")
 
 		// Assert
 		assert.Error(t, err)
@@ -356,13 +830,13 @@ func TestProductService_DeleteProduct(t *testing.T) {
 func TestProductService_ProductExists(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockProductRepository)
-	service := NewProductService(mockRepo)
+	service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
 
 	t.Run("Product exists", func(t *testing.T) {
 		mockRepo.On("ExistsByID", "existing-product").Return(true)
 
 		// Act
-		exists := service.ProductExists("existing-product")
+		exists := service.ProductExists(context.Background(), "existing-product")
 
 		// Assert
 		assert.True(t, exists)
@@ -373,10 +847,560 @@ func TestProductService_ProductExists(t *testing.T) {
 		mockRepo.On("ExistsByID", "non-existing-product").Return(false)
 
 		// Act
-		exists := service.ProductExists("non-existing-product")
+		exists := service.ProductExists(context.Background(), "non-existing-product")
 
 		// Assert
 		assert.False(t, exists)
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestProductService_ReserveStock(t *testing.T) {
+	t.Run("Reserve available stock", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		reserved := &model.Product{
+			ID:               "product-123",
+			Price:            money.New(1000, "USD"),
+			StockQuantity:    10,
+			ReservedQuantity: 3,
+		}
+		mockRepo.On("ReserveStock", "product-123", 3).Return(reserved, nil)
+
+		// Act
+		result, err := service.ReserveStock(context.Background(), "product-123", model.StockRequest{Quantity: 3})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.ReservedQuantity)
+		assert.Equal(t, 7, result.AvailableQuantity)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Insufficient stock", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		mockRepo.On("ReserveStock", "product-123", 50).Return(nil, model.ErrInsufficientStock)
+
+		// Act
+		result, err := service.ReserveStock(context.Background(), "product-123", model.StockRequest{Quantity: 50})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "insufficient stock", err.Error())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid quantity", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		// Act
+		result, err := service.ReserveStock(context.Background(), "product-123", model.StockRequest{Quantity: 0})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "quantity must be greater than 0", err.Error())
+		mockRepo.AssertNotCalled(t, "ReserveStock", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProductService_ReleaseStock(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+	released := &model.Product{
+		ID:            "product-123",
+		Price:         money.New(1000, "USD"),
+		StockQuantity: 10,
+	}
+	mockRepo.On("ReleaseStock", "product-123", 2).Return(released, nil)
+
+	// Act
+	result, err := service.ReleaseStock(context.Background(), "product-123", model.StockRequest{Quantity: 2})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 10, result.AvailableQuantity)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_AdjustStock(t *testing.T) {
+	t.Run("Adjust stock", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		adjusted := &model.Product{
+			ID:            "product-123",
+			Price:         money.New(1000, "USD"),
+			StockQuantity: 15,
+		}
+		mockRepo.On("AdjustStock", "product-123", 5).Return(adjusted, nil)
+
+		// Act
+		result, err := service.AdjustStock(context.Background(), "product-123", model.AdjustStockRequest{Delta: 5, Reason: "delivery"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 15, result.StockQuantity)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Zero delta", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		// Act
+		result, err := service.AdjustStock(context.Background(), "product-123", model.AdjustStockRequest{})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "delta must not be zero", err.Error())
+		mockRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything)
+	})
+}
+
+func TestProductService_RestoreProduct(t *testing.T) {
+	t.Run("Restore deleted product", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		restored := &model.Product{
+			ID:     "product-123",
+			Name:   "Test Product",
+			Price:  money.New(1000, "USD"),
+			Active: true,
+		}
+		mockRepo.On("Restore", "product-123").Return(restored, nil)
+
+		// Act
+		result, err := service.RestoreProduct(context.Background(), "product-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "product-123", result.ID)
+		assert.Nil(t, result.DeletedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Restore product that is not deleted", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		mockRepo.On("Restore", "product-123").Return(nil, model.ErrProductNotDeleted)
+
+		// Act
+		result, err := service.RestoreProduct(context.Background(), "product-123")
+
+		// Assert
+		assert.Nil(t, result)
+		assert.Equal(t, "product is not deleted", err.Error())
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestProductService_BulkProducts(t *testing.T) {
+	t.Run("Commit when all operations succeed", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Create", mock.AnythingOfType("*model.Product")).Return(&model.Product{
+			ID:     "product-new",
+			Name:   "New Product",
+			Price:  money.New(1000, "USD"),
+			Active: true,
+		}, nil)
+		mockRepo.On("Delete", "product-123").Return(nil)
+
+		req := model.BulkProductRequest{
+			Operations: []model.BulkProductOperation{
+				{Op: "create", Create: &model.CreateProductRequest{Name: "New Product", Description: "New", Price: "10.00", CategoryID: "category-electronics"}},
+				{Op: "delete", ID: "product-123"},
+			},
+		}
+
+		// Act
+		result, err := service.BulkProducts(context.Background(), req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Committed)
+		require.Len(t, result.Results, 2)
+		assert.Equal(t, bulk.StatusSucceeded, result.Results[0].Status)
+		assert.Equal(t, "product-new", result.Results[0].ID)
+		assert.Equal(t, bulk.StatusSucceeded, result.Results[1].Status)
+		assert.Nil(t, result.Results[1].Data)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Roll back when an operation fails", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Delete", "product-123").Return(nil)
+		mockRepo.On("Delete", "missing").Return(model.ErrProductNotFound)
+
+		req := model.BulkProductRequest{
+			Operations: []model.BulkProductOperation{
+				{Op: "delete", ID: "product-123"},
+				{Op: "delete", ID: "missing"},
+				{Op: "create"},
+			},
+		}
+
+		// Act
+		result, err := service.BulkProducts(context.Background(), req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, result.Committed)
+		require.Len(t, result.Results, 3)
+		assert.Equal(t, bulk.StatusRolledBack, result.Results[0].Status)
+		assert.Equal(t, bulk.StatusFailed, result.Results[1].Status)
+		assert.Equal(t, "product not found", result.Results[1].Error)
+		assert.Equal(t, bulk.StatusFailed, result.Results[2].Status)
+		assert.Equal(t, "create payload is required", result.Results[2].Error)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Abort when ctx is cancelled", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		mockRepo.On("Transaction").Return()
+
+		req := model.BulkProductRequest{
+			Operations: []model.BulkProductOperation{
+				{Op: "delete", ID: "product-123"},
+			},
+		}
+
+		// Act
+		result, err := service.BulkProducts(ctx, req)
+
+		// Assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Delete", "product-123")
+	})
+}
+
+func TestProductService_Events(t *testing.T) {
+	t.Run("Publish event after create", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", publisher)
+
+		mockRepo.On("Create", mock.AnythingOfType("*model.Product")).Return(&model.Product{
+			ID:    "product-new",
+			Price: money.New(1000, "USD"),
+		}, nil)
+
+		// Act
+		_, err := service.CreateProduct(context.Background(), model.CreateProductRequest{Name: "New", Description: "New", Price: "10.00", CategoryID: "category-electronics"})
+
+		// Assert
+		require.NoError(t, err)
+		published := publisher.Events()
+		require.Len(t, published, 1)
+		assert.Equal(t, events.ProductCreated, published[0].Type)
+		assert.Equal(t, "product-new", published[0].Subject)
+		assert.Equal(t, "product-new", published[0].Data.(model.ProductResponse).ID)
+	})
+
+	t.Run("Publish nothing when the change fails", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", publisher)
+
+		mockRepo.On("ReserveStock", "product-123", 5).Return(nil, model.ErrInsufficientStock)
+
+		// Act
+		_, err := service.ReserveStock(context.Background(), "product-123", model.StockRequest{Quantity: 5})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Empty(t, publisher.Events())
+	})
+
+	t.Run("Publish nothing for a rolled back bulk request", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewProductService(mockRepo, mockRepo, newMockCategories(), "USD", publisher)
+
+		mockRepo.On("Transaction").Return()
+		mockRepo.On("Delete", "product-123").Return(nil)
+		mockRepo.On("Delete", "missing").Return(model.ErrProductNotFound)
+
+		// Act
+		result, err := service.BulkProducts(context.Background(), model.BulkProductRequest{
+			Operations: []model.BulkProductOperation{
+				{Op: "delete", ID: "product-123"},
+				{Op: "delete", ID: "missing"},
+			},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, result.Committed)
+		assert.Empty(t, publisher.Events())
+	})
+}
+
+func TestProductService_GTIN(t *testing.T) {
+	newService := func() ProductService {
+		repo := repository.NewMemoryProductRepository()
+		return NewProductService(repo, repo, repository.NewMemoryCategoryRepository(), "USD", nil)
+	}
+
+	t.Run("Create and look up by barcode", func(t *testing.T) {
+		// Arrange
+		service := newService()
+
+		// Act
+		product, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			SKU: "SODA-12", GTIN: " 036000291452 ", Name: "Soda", Description: "Soda", Price: "4.99", CategoryID: "category-electronics",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "036000291452", product.GTIN)
+
+		found, err := service.GetProductByGTIN(context.Background(), "0036000291452")
+		require.NoError(t, err)
+		assert.Equal(t, product.ID, found.ID)
+
+		found, err = service.GetProductBySKU(context.Background(), " soda-12 ")
+		require.NoError(t, err)
+		assert.Equal(t, product.ID, found.ID)
+	})
+
+	t.Run("Create with an invalid barcode", func(t *testing.T) {
+		// Arrange
+		service := newService()
+
+		// Act
+		_, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			GTIN: "036000291453", Name: "Soda", Description: "Soda", Price: "4.99", CategoryID: "category-electronics",
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidGTIN)
+	})
+
+	t.Run("Clear the barcode on update", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		product, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			GTIN: "4006381333931", Name: "Pen", Description: "Pen", Price: "1.99", CategoryID: "category-electronics",
+		})
+		require.NoError(t, err)
+		empty := ""
+
+		// Act
+		updated, err := service.UpdateProduct(context.Background(), product.ID, model.UpdateProductRequest{GTIN: &empty})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, updated.GTIN)
+		_, err = service.GetProductByGTIN(context.Background(), "4006381333931")
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+
+	t.Run("Look up an invalid barcode", func(t *testing.T) {
+		// Act
+		_, err := newService().GetProductByGTIN(context.Background(), "12345")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrInvalidGTIN)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+	})
+}
+
+func TestProductService_TagsAndAttributes(t *testing.T) {
+	newService := func() ProductService {
+		repo := repository.NewMemoryProductRepository()
+		return NewProductService(repo, repo, repository.NewMemoryCategoryRepository(), "USD", nil)
+	}
+
+	t.Run("Create with inherited attributes", func(t *testing.T) {
+		// Arrange
+		service := newService()
+
+		// Act
+		product, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			Name: "Laptop Pro", Description: "Laptop", Price: "1499.00", CategoryID: "category-computers",
+			Tags: []string{"New", "sale", "NEW"},
+			Attributes: []model.Attribute{
+				{Key: "screen_size", Value: "16.0"},
+				{Key: "Color", Value: "silver"},
+			},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"new", "sale"}, product.Tags)
+		assert.Equal(t, []model.Attribute{
+			{Key: "color", Type: model.AttributeString, Value: "silver"},
+			{Key: "screen_size", Type: model.AttributeNumber, Value: "16"},
+		}, product.Attributes)
+
+		products, _, err := service.ListProducts(context.Background(), model.ProductFilter{
+			Tags:       []string{"sale"},
+			Attributes: []model.AttributeFilter{{Key: "screen_size", Value: "16"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, product.ID, products[0].ID)
+	})
+
+	t.Run("Create with an attribute of another category", func(t *testing.T) {
+		// Arrange
+		service := newService()
+
+		// Act
+		_, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			Name: "Mouse", Description: "Mouse", Price: "19.99", CategoryID: "category-electronics",
+			Attributes: []model.Attribute{{Key: "screen_size", Value: "16"}},
+		})
+
+		// Assert
+		assert.EqualError(t, err, `attribute "screen_size" is not defined for the category`)
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+	})
+
+	t.Run("Moving a product checks its attributes against the new category", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		created, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			Name: "Laptop Pro", Description: "Laptop", Price: "1499.00", CategoryID: "category-computers",
+			Attributes: []model.Attribute{{Key: "screen_size", Value: "16"}},
+		})
+		require.NoError(t, err)
+		accessories := "category-accessories"
+
+		// Act
+		_, err = service.UpdateProduct(context.Background(), created.ID, model.UpdateProductRequest{CategoryID: &accessories})
+
+		// Assert
+		assert.EqualError(t, err, `attribute "screen_size" is not defined for the category`)
+	})
+
+	t.Run("Replace tags and attributes", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		tags := []string{"clearance"}
+		attributes := []model.Attribute{{Key: "weight", Value: "0.1"}}
+
+		// Act
+		product, err := service.UpdateProduct(context.Background(), "product-001", model.UpdateProductRequest{Tags: &tags, Attributes: &attributes})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"clearance"}, product.Tags)
+		assert.Equal(t, []model.Attribute{{Key: "weight", Type: model.AttributeNumber, Value: "0.1"}}, product.Attributes)
+	})
+}
+
+func TestProductService_ShippingMeasurements(t *testing.T) {
+	newService := func() ProductService {
+		repo := repository.NewMemoryProductRepository()
+		return NewProductService(repo, repo, repository.NewMemoryCategoryRepository(), "USD", nil)
+	}
+
+	t.Run("Create with weight and dimensions", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		dimensions := &model.Dimensions{LengthMM: 300, WidthMM: 200, HeightMM: 100}
+
+		// Act
+		product, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			Name: "Kettle", Description: "Kettle", Price: "39.99", CategoryID: "category-electronics",
+			WeightGrams: 1200, Dimensions: dimensions,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1200, product.WeightGrams)
+		assert.Equal(t, dimensions, product.Dimensions)
+	})
+
+	t.Run("Update only the weight", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		product, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			Name: "Kettle", Description: "Kettle", Price: "39.99", CategoryID: "category-electronics",
+			WeightGrams: 1200, Dimensions: &model.Dimensions{LengthMM: 300, WidthMM: 200, HeightMM: 100},
+		})
+		require.NoError(t, err)
+		weight := 1500
+
+		// Act
+		updated, err := service.UpdateProduct(context.Background(), product.ID, model.UpdateProductRequest{WeightGrams: &weight})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1500, updated.WeightGrams)
+		assert.Equal(t, product.Dimensions, updated.Dimensions)
+	})
+
+	t.Run("Clear the dimensions", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		product, err := service.CreateProduct(context.Background(), model.CreateProductRequest{
+			Name: "Kettle", Description: "Kettle", Price: "39.99", CategoryID: "category-electronics",
+			WeightGrams: 1200, Dimensions: &model.Dimensions{LengthMM: 300, WidthMM: 200, HeightMM: 100},
+		})
+		require.NoError(t, err)
+
+		// Act
+		updated, err := service.UpdateProduct(context.Background(), product.ID, model.UpdateProductRequest{ClearDimensions: true})
+		_, conflictErr := service.UpdateProduct(context.Background(), product.ID, model.UpdateProductRequest{
+			Dimensions: &model.Dimensions{LengthMM: 300, WidthMM: 200, HeightMM: 100}, ClearDimensions: true,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Nil(t, updated.Dimensions)
+		assert.Equal(t, 1200, updated.WeightGrams)
+		assert.ErrorIs(t, conflictErr, model.ErrClearDimensions)
+	})
+
+	t.Run("Reject invalid measurements", func(t *testing.T) {
+		// Arrange
+		service := newService()
+		base := model.CreateProductRequest{Name: "Kettle", Description: "Kettle", Price: "39.99", CategoryID: "category-electronics"}
+
+		// Act
+		heavy := base
+		heavy.WeightGrams = model.MaxWeightGrams + 1
+		_, weightErr := service.CreateProduct(context.Background(), heavy)
+		flat := base
+		flat.Dimensions = &model.Dimensions{LengthMM: 300, WidthMM: 200}
+		_, dimensionsErr := service.CreateProduct(context.Background(), flat)
+
+		// Assert
+		assert.ErrorIs(t, weightErr, model.ErrInvalidWeight)
+		assert.ErrorIs(t, dimensionsErr, model.ErrInvalidDimensions)
+	})
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/email"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+)
+
+// RestockJobKind is the job kind notifying the subscribers of a product that
+// may be back in stock
+const RestockJobKind = "back-in-stock"
+
+// StockSubscriptionService defines the interface for back-in-stock
+// subscriptions. It is an events.Publisher so it can watch the stock
+// changes of products.
+type StockSubscriptionService interface {
+	GetSubscriptions(ctx context.Context, productID string) ([]*model.StockSubscriptionResponse, error)
+	Subscribe(ctx context.Context, productID string, req model.StockSubscriptionRequest) (*model.StockSubscriptionResponse, bool, error)
+	Unsubscribe(ctx context.Context, productID, id string) error
+	NotifyRestock(ctx context.Context, productID string) (int, error)
+	Publish(event events.Event)
+}
+
+// restockJob is the job payload for notifying the subscribers of a product
+type restockJob struct {
+	ProductID string `json:"productId"`
+	Tenant    string `json:"tenant,omitempty"`
+}
+
+// stockSubscriptionService implements StockSubscriptionService
+type stockSubscriptionService struct {
+	repo     repository.StockSubscriptionRepository
+	products repository.ProductRepository
+	jobs     *jobs.Manager
+	sender   email.Sender
+	events   events.Publisher
+	now      func() time.Time
+}
+
+// NewStockSubscriptionService creates a new back-in-stock subscription
+// service for the products of products. Restocks are picked up from the
+// product events it is published, and their subscribers notified in jobs
+// run by manager. Each subscriber is notified once, with a
+// product.back_in_stock event sent to events and an email sent through
+// sender; either may be nil.
+func NewStockSubscriptionService(repo repository.StockSubscriptionRepository, products repository.ProductRepository, manager *jobs.Manager, sender email.Sender, events events.Publisher) StockSubscriptionService {
+	s := &stockSubscriptionService{
+		repo:     repo,
+		products: products,
+		jobs:     manager,
+		sender:   sender,
+		events:   events,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+	manager.Register(RestockJobKind, s.handleRestockJob)
+	return s
+}
+
+// GetSubscriptions retrieves the subscriptions to a product, oldest first
+func (s *stockSubscriptionService) GetSubscriptions(ctx context.Context, productID string) ([]*model.StockSubscriptionResponse, error) {
+	if !s.products.ExistsByID(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+
+	subscriptions, err := s.repo.GetByProduct(ctx, productID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", productID).Error("Failed to get stock subscriptions")
+		return nil, err
+	}
+
+	responses := make([]*model.StockSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		response := subscription.ToResponse()
+		responses[i] = &response
+	}
+	return responses, nil
+}
+
+// Subscribe asks for an email to be notified when an out-of-stock product is
+// back in stock. Subscribing again while the subscription is pending returns
+// it unchanged, with created false.
+func (s *stockSubscriptionService) Subscribe(ctx context.Context, productID string, req model.StockSubscriptionRequest) (*model.StockSubscriptionResponse, bool, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if err != nil {
+		return nil, false, err
+	}
+	if product.AvailableQuantity() > 0 {
+		return nil, false, model.ErrProductInStock
+	}
+
+	subscription, created, err := s.repo.Create(ctx, &model.StockSubscription{
+		ProductID:  productID,
+		CustomerID: req.CustomerID,
+		Email:      model.NormalizeSubscriberEmail(req.Email),
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", productID).Error("Failed to create stock subscription")
+		return nil, false, err
+	}
+
+	if created {
+		log.Ctx(ctx).WithFields(logger.Fields{
+			"product_id":      productID,
+			"subscription_id": subscription.ID,
+		}).Info("Successfully subscribed to product restock")
+	}
+	response := subscription.ToResponse()
+	return &response, created, nil
+}
+
+// Unsubscribe removes a subscription to a product
+func (s *stockSubscriptionService) Unsubscribe(ctx context.Context, productID, id string) error {
+	if err := s.repo.Delete(ctx, productID, id); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id":      productID,
+		"subscription_id": id,
+	}).Info("Successfully unsubscribed from product restock")
+	return nil
+}
+
+// NotifyRestock notifies the pending subscribers of a product if it is
+// active and has stock available, and returns how many were notified. A
+// subscriber whose email cannot be sent still counts as notified, so no one
+// is notified twice.
+func (s *stockSubscriptionService) NotifyRestock(ctx context.Context, productID string) (int, error) {
+	product, err := s.products.GetByID(ctx, productID)
+	if errors.Is(err, model.ErrProductNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !product.Active || product.AvailableQuantity() <= 0 {
+		return 0, nil
+	}
+
+	pending, err := s.repo.GetPending(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, subscription := range pending {
+		subscription, err := s.repo.MarkNotified(ctx, productID, subscription.ID, s.now())
+		if errors.Is(err, model.ErrSubscriptionNotPending) || errors.Is(err, model.ErrStockSubscriptionNotFound) {
+			// Notified by a concurrent job, or unsubscribed meanwhile
+			continue
+		}
+		if err != nil {
+			return notified, err
+		}
+
+		s.notify(ctx, product, subscription)
+		notified++
+	}
+
+	if notified > 0 {
+		log.Ctx(ctx).WithFields(logger.Fields{
+			"product_id": productID,
+			"notified":   notified,
+		}).Info("Notified subscribers of product restock")
+	}
+	return notified, nil
+}
+
+// Publish queues a restock notification for product events that may have
+// brought stock back, if the product has pending subscriptions
+func (s *stockSubscriptionService) Publish(event events.Event) {
+	switch event.Type {
+	case events.ProductStockChanged, events.ProductUpdated, events.ProductRestored:
+	default:
+		return
+	}
+
+	ctx := context.Background()
+	if event.Tenant != "" {
+		ctx = tenant.WithTenant(ctx, event.Tenant)
+	}
+	if !s.repo.HasPending(ctx, event.Subject) {
+		return
+	}
+
+	if _, err := s.jobs.Submit(RestockJobKind, restockJob{ProductID: event.Subject, Tenant: event.Tenant}); err != nil {
+		log.WithError(err).WithField("product_id", event.Subject).Error("Failed to queue restock notification")
+	}
+}
+
+// handleRestockJob notifies the subscribers of the product named in the job
+// payload
+func (s *stockSubscriptionService) handleRestockJob(ctx context.Context, payload json.RawMessage) error {
+	var job restockJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid restock notification payload: %w", err))
+	}
+
+	if job.Tenant != "" {
+		ctx = tenant.WithTenant(ctx, job.Tenant)
+	}
+	_, err := s.NotifyRestock(ctx, job.ProductID)
+	return err
+}
+
+// notify publishes the back-in-stock event of a subscription and emails its
+// subscriber. Email failures are logged; the event is still delivered.
+func (s *stockSubscriptionService) notify(ctx context.Context, product *model.Product, subscription *model.StockSubscription) {
+	notification := model.BackInStockNotification{
+		SubscriptionID:    subscription.ID,
+		ProductID:         product.ID,
+		ProductName:       product.Name,
+		SKU:               product.SKU,
+		CustomerID:        subscription.CustomerID,
+		Email:             subscription.Email,
+		AvailableQuantity: product.AvailableQuantity(),
+	}
+	if s.events != nil {
+		s.events.Publish(events.New(events.ProductBackInStock, product.ID, notification).In(ctx))
+	}
+
+	if s.sender == nil {
+		return
+	}
+	err := s.sender.Send(ctx, email.Message{
+		To:      subscription.Email,
+		Subject: product.Name + " is back in stock",
+		Body: fmt.Sprintf("Hello,\n\n%s is back in stock.\n\nYou asked to be told when it was available again; "+
+			"this is the only notification you will get for it.\n", product.Name),
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"product_id":      product.ID,
+			"subscription_id": subscription.ID,
+		}).Warn("Failed to send back-in-stock email")
+	}
+}
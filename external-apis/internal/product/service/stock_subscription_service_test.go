@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/email"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stockSubscriptionFixture wires a product service to a back-in-stock
+// subscription service that watches its events
+type stockSubscriptionFixture struct {
+	products      ProductService
+	subscriptions StockSubscriptionService
+	manager       *jobs.Manager
+	sender        *email.Memory
+	publisher     *events.MemoryPublisher
+	// productID is an active product with no stock
+	productID string
+}
+
+func newStockSubscriptionFixture(t *testing.T) *stockSubscriptionFixture {
+	t.Helper()
+	f := &stockSubscriptionFixture{
+		manager:   jobs.NewManager(nil, jobs.DefaultOptions()),
+		sender:    email.NewMemory(),
+		publisher: events.NewMemoryPublisher(),
+	}
+	repo := repository.NewMemoryProductRepository()
+	product, err := repo.Create(context.Background(), &model.Product{Name: "Desk Lamp", SKU: "LAMP-1", Price: money.New(2999, "USD"), CategoryID: "category-electronics", Active: true})
+	require.NoError(t, err)
+	f.productID = product.ID
+
+	f.subscriptions = NewStockSubscriptionService(repository.NewMemoryStockSubscriptionRepository(), repo, f.manager, f.sender, f.publisher)
+	f.products = NewProductService(repo, repo, newMockCategories(), "USD", events.Multi{f.publisher, f.subscriptions})
+	require.NoError(t, f.manager.Start())
+	return f
+}
+
+// restock adds units to the product and waits for the notifications
+func (f *stockSubscriptionFixture) restock(t *testing.T, delta int) {
+	t.Helper()
+	_, err := f.products.AdjustStock(context.Background(), f.productID, model.AdjustStockRequest{Delta: delta})
+	require.NoError(t, err)
+	require.NoError(t, f.manager.Drain(time.Second))
+}
+
+// notifications returns the back-in-stock events published so far
+func (f *stockSubscriptionFixture) notifications() []model.BackInStockNotification {
+	var notifications []model.BackInStockNotification
+	for _, event := range f.publisher.Events() {
+		if event.Type == events.ProductBackInStock {
+			notifications = append(notifications, event.Data.(model.BackInStockNotification))
+		}
+	}
+	return notifications
+}
+
+func TestStockSubscriptionService_Subscribe(t *testing.T) {
+	t.Run("Subscribe once per email", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+
+		// Act
+		first, created, err := f.subscriptions.Subscribe(context.Background(), f.productID, model.StockSubscriptionRequest{Email: " Jane@Example.com ", CustomerID: "customer-1"})
+		require.NoError(t, err)
+		again, createdAgain, err := f.subscriptions.Subscribe(context.Background(), f.productID, model.StockSubscriptionRequest{Email: "jane@example.com"})
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, created)
+		assert.Equal(t, "jane@example.com", first.Email)
+		assert.Equal(t, model.SubscriptionPending, first.Status)
+		assert.False(t, createdAgain)
+		assert.Equal(t, first.ID, again.ID)
+	})
+
+	t.Run("Product in stock", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+
+		// Act
+		_, _, err := f.subscriptions.Subscribe(context.Background(), "product-001", model.StockSubscriptionRequest{Email: "jane@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductInStock)
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+
+		// Act
+		_, _, err := f.subscriptions.Subscribe(context.Background(), "missing", model.StockSubscriptionRequest{Email: "jane@example.com"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+}
+
+func TestStockSubscriptionService_NotifyRestock(t *testing.T) {
+	t.Run("Notify each subscriber once when stock comes back", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+		jane, _, err := f.subscriptions.Subscribe(context.Background(), f.productID, model.StockSubscriptionRequest{Email: "jane@example.com", CustomerID: "customer-1"})
+		require.NoError(t, err)
+		_, _, err = f.subscriptions.Subscribe(context.Background(), f.productID, model.StockSubscriptionRequest{Email: "joe@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		f.restock(t, 5)
+		f.restock(t, 5)
+
+		// Assert
+		notifications := f.notifications()
+		require.Len(t, notifications, 2)
+		assert.Equal(t, model.BackInStockNotification{
+			SubscriptionID:    jane.ID,
+			ProductID:         f.productID,
+			ProductName:       "Desk Lamp",
+			SKU:               "LAMP-1",
+			CustomerID:        "customer-1",
+			Email:             "jane@example.com",
+			AvailableQuantity: 5,
+		}, notifications[0])
+
+		messages := f.sender.Messages()
+		require.Len(t, messages, 2)
+		assert.Equal(t, "jane@example.com", messages[0].To)
+		assert.Equal(t, "Desk Lamp is back in stock", messages[0].Subject)
+
+		subscriptions, err := f.subscriptions.GetSubscriptions(context.Background(), f.productID)
+		require.NoError(t, err)
+		for _, subscription := range subscriptions {
+			assert.Equal(t, model.SubscriptionNotified, subscription.Status)
+			assert.NotNil(t, subscription.NotifiedAt)
+		}
+	})
+
+	t.Run("Stock still out", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+		_, _, err := f.subscriptions.Subscribe(context.Background(), f.productID, model.StockSubscriptionRequest{Email: "jane@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		notified, err := f.subscriptions.NotifyRestock(context.Background(), f.productID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, notified)
+		assert.Empty(t, f.sender.Messages())
+	})
+
+	t.Run("Inactive product", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+		_, _, err := f.subscriptions.Subscribe(context.Background(), "product-inactive", model.StockSubscriptionRequest{Email: "jane@example.com"})
+		require.NoError(t, err)
+
+		// Act
+		_, err = f.products.AdjustStock(context.Background(), "product-inactive", model.AdjustStockRequest{Delta: 5})
+		require.NoError(t, err)
+		require.NoError(t, f.manager.Drain(time.Second))
+
+		// Assert
+		assert.Empty(t, f.notifications())
+	})
+
+	t.Run("Unsubscribed before the restock", func(t *testing.T) {
+		// Arrange
+		f := newStockSubscriptionFixture(t)
+		subscription, _, err := f.subscriptions.Subscribe(context.Background(), f.productID, model.StockSubscriptionRequest{Email: "jane@example.com"})
+		require.NoError(t, err)
+		require.NoError(t, f.subscriptions.Unsubscribe(context.Background(), f.productID, subscription.ID))
+
+		// Act
+		f.restock(t, 5)
+
+		// Assert
+		assert.Empty(t, f.notifications())
+		assert.ErrorIs(t, f.subscriptions.Unsubscribe(context.Background(), f.productID, subscription.ID), model.ErrStockSubscriptionNotFound)
+	})
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+)
+
+// SupplierService defines the interface for suppliers and the products they
+// supply
+type SupplierService interface {
+	GetSuppliers(ctx context.Context) ([]*model.SupplierResponse, error)
+	GetSupplier(ctx context.Context, id string) (*model.SupplierResponse, error)
+	CreateSupplier(ctx context.Context, req model.SupplierRequest) (*model.SupplierResponse, error)
+	UpdateSupplier(ctx context.Context, id string, req model.SupplierRequest) (*model.SupplierResponse, error)
+	DeleteSupplier(ctx context.Context, id string) error
+	GetProductSuppliers(ctx context.Context, productID string) ([]*model.ProductSupplierResponse, error)
+	GetSupplierProducts(ctx context.Context, supplierID string) ([]*model.ProductSupplierResponse, error)
+	LinkProduct(ctx context.Context, productID, supplierID string, req model.ProductSupplierRequest) (*model.ProductSupplierResponse, error)
+	UnlinkProduct(ctx context.Context, productID, supplierID string) error
+}
+
+// supplierService implements SupplierService
+type supplierService struct {
+	repo            repository.SupplierRepository
+	products        repository.ProductRepository
+	defaultCurrency string
+}
+
+// NewSupplierService creates a new supplier service linking suppliers to the
+// products of products. Cost prices without a currency are in
+// defaultCurrency, or money.DefaultCurrency if it is empty.
+func NewSupplierService(repo repository.SupplierRepository, products repository.ProductRepository, defaultCurrency string) SupplierService {
+	if defaultCurrency == "" {
+		defaultCurrency = money.DefaultCurrency
+	}
+
+	return &supplierService{
+		repo:            repo,
+		products:        products,
+		defaultCurrency: defaultCurrency,
+	}
+}
+
+// GetSuppliers retrieves all suppliers, oldest first
+func (s *supplierService) GetSuppliers(ctx context.Context) ([]*model.SupplierResponse, error) {
+	suppliers, err := s.repo.GetAll(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to get suppliers")
+		return nil, err
+	}
+
+	responses := make([]*model.SupplierResponse, len(suppliers))
+	for i, supplier := range suppliers {
+		response := supplier.ToResponse()
+		responses[i] = &response
+	}
+	return responses, nil
+}
+
+// GetSupplier retrieves a supplier by ID
+func (s *supplierService) GetSupplier(ctx context.Context, id string) (*model.SupplierResponse, error) {
+	supplier, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := supplier.ToResponse()
+	return &response, nil
+}
+
+// CreateSupplier creates a new supplier
+func (s *supplierService) CreateSupplier(ctx context.Context, req model.SupplierRequest) (*model.SupplierResponse, error) {
+	log.Ctx(ctx).WithField("name", req.Name).Debug("Creating supplier")
+
+	created, err := s.repo.Create(ctx, newSupplier(req))
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("Failed to create supplier")
+		return nil, err
+	}
+
+	response := created.ToResponse()
+	log.Ctx(ctx).WithField("supplier_id", created.ID).Info("Successfully created supplier")
+	return &response, nil
+}
+
+// UpdateSupplier replaces an existing supplier
+func (s *supplierService) UpdateSupplier(ctx context.Context, id string, req model.SupplierRequest) (*model.SupplierResponse, error) {
+	log.Ctx(ctx).WithField("supplier_id", id).Debug("Updating supplier")
+
+	supplier := newSupplier(req)
+	supplier.ID = id
+
+	updated, err := s.repo.Update(ctx, supplier)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("supplier_id", id).Error("Failed to update supplier")
+		return nil, err
+	}
+
+	response := updated.ToResponse()
+	log.Ctx(ctx).WithField("supplier_id", id).Info("Successfully updated supplier")
+	return &response, nil
+}
+
+// DeleteSupplier deletes a supplier along with its links to products
+func (s *supplierService) DeleteSupplier(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("supplier_id", id).Error("Failed to delete supplier")
+		return err
+	}
+
+	log.Ctx(ctx).WithField("supplier_id", id).Info("Successfully deleted supplier")
+	return nil
+}
+
+// GetProductSuppliers retrieves the suppliers of a product with their cost
+// prices, oldest link first
+func (s *supplierService) GetProductSuppliers(ctx context.Context, productID string) ([]*model.ProductSupplierResponse, error) {
+	if !s.products.ExistsByID(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+
+	links, err := s.repo.GetByProduct(ctx, productID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", productID).Error("Failed to get product suppliers")
+		return nil, err
+	}
+
+	responses := make([]*model.ProductSupplierResponse, 0, len(links))
+	for _, link := range links {
+		supplier, err := s.repo.GetByID(ctx, link.SupplierID)
+		if errors.Is(err, model.ErrSupplierNotFound) {
+			// The supplier was deleted after the links were read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		response := link.ToResponse(supplier)
+		responses = append(responses, &response)
+	}
+	return responses, nil
+}
+
+// GetSupplierProducts retrieves the links of a supplier to the products it
+// supplies, oldest first
+func (s *supplierService) GetSupplierProducts(ctx context.Context, supplierID string) ([]*model.ProductSupplierResponse, error) {
+	supplier, err := s.repo.GetByID(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.repo.GetBySupplier(ctx, supplierID)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("supplier_id", supplierID).Error("Failed to get supplier products")
+		return nil, err
+	}
+
+	responses := make([]*model.ProductSupplierResponse, len(links))
+	for i, link := range links {
+		response := link.ToResponse(supplier)
+		responses[i] = &response
+	}
+	return responses, nil
+}
+
+// LinkProduct links a product to a supplier at a cost price, or changes the
+// cost price and supplier SKU of their link
+func (s *supplierService) LinkProduct(ctx context.Context, productID, supplierID string, req model.ProductSupplierRequest) (*model.ProductSupplierResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id":  productID,
+		"supplier_id": supplierID,
+	}).Debug("Linking product to supplier")
+
+	if !s.products.ExistsByID(ctx, productID) {
+		return nil, model.ErrProductNotFound
+	}
+	supplier, err := s.repo.GetByID(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = s.defaultCurrency
+	}
+	costPrice, err := money.Parse(req.CostPrice.String(), currency)
+	switch {
+	case errors.Is(err, money.ErrUnknownCurrency):
+		return nil, model.ErrUnsupportedCurrency
+	case err != nil || !costPrice.IsPositive():
+		return nil, model.ErrInvalidCostPrice
+	}
+
+	link, err := s.repo.SaveLink(ctx, &model.ProductSupplier{
+		ProductID:   productID,
+		SupplierID:  supplierID,
+		SupplierSKU: strings.TrimSpace(req.SupplierSKU),
+		CostPrice:   costPrice,
+	})
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"product_id":  productID,
+			"supplier_id": supplierID,
+		}).Error("Failed to link product to supplier")
+		return nil, err
+	}
+
+	response := link.ToResponse(supplier)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id":  productID,
+		"supplier_id": supplierID,
+	}).Info("Successfully linked product to supplier")
+	return &response, nil
+}
+
+// UnlinkProduct removes the link between a product and a supplier
+func (s *supplierService) UnlinkProduct(ctx context.Context, productID, supplierID string) error {
+	if err := s.repo.DeleteLink(ctx, productID, supplierID); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id":  productID,
+		"supplier_id": supplierID,
+	}).Info("Successfully unlinked product from supplier")
+	return nil
+}
+
+// newSupplier builds a supplier from a request
+func newSupplier(req model.SupplierRequest) *model.Supplier {
+	return &model.Supplier{
+		Name:         strings.TrimSpace(req.Name),
+		ContactName:  strings.TrimSpace(req.ContactName),
+		Email:        strings.TrimSpace(req.Email),
+		Phone:        strings.TrimSpace(req.Phone),
+		LeadTimeDays: req.LeadTimeDays,
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSupplierService returns a supplier service over the sample products
+func newTestSupplierService() SupplierService {
+	return NewSupplierService(repository.NewMemorySupplierRepository(), repository.NewMemoryProductRepository(), "USD")
+}
+
+func TestSupplierService_CreateSupplier(t *testing.T) {
+	t.Run("Trim the fields", func(t *testing.T) {
+		// Arrange
+		service := newTestSupplierService()
+
+		// Act
+		supplier, err := service.CreateSupplier(context.Background(), model.SupplierRequest{
+			Name: " Acme Parts ", ContactName: " Jo Smith ", Email: "sales@acme.example", LeadTimeDays: 7,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Acme Parts", supplier.Name)
+		assert.Equal(t, "Jo Smith", supplier.ContactName)
+		assert.Equal(t, 7, supplier.LeadTimeDays)
+	})
+
+	t.Run("Name taken", func(t *testing.T) {
+		// Arrange
+		service := newTestSupplierService()
+		_, err := service.CreateSupplier(context.Background(), model.SupplierRequest{Name: "Acme Parts"})
+		require.NoError(t, err)
+
+		// Act
+		_, err = service.CreateSupplier(context.Background(), model.SupplierRequest{Name: " acme parts"})
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierNameTaken)
+	})
+}
+
+func TestSupplierService_UpdateSupplier(t *testing.T) {
+	// Arrange
+	service := newTestSupplierService()
+	created, err := service.CreateSupplier(context.Background(), model.SupplierRequest{Name: "Acme Parts", Phone: "+1 555 0100", LeadTimeDays: 7})
+	require.NoError(t, err)
+
+	// Act
+	updated, err := service.UpdateSupplier(context.Background(), created.ID, model.SupplierRequest{Name: "Acme Parts", LeadTimeDays: 14})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 14, updated.LeadTimeDays)
+	assert.Empty(t, updated.Phone)
+}
+
+func TestSupplierService_LinkProduct(t *testing.T) {
+	newService := func(t *testing.T) (SupplierService, *model.SupplierResponse) {
+		service := newTestSupplierService()
+		supplier, err := service.CreateSupplier(context.Background(), model.SupplierRequest{Name: "Acme Parts", LeadTimeDays: 7})
+		require.NoError(t, err)
+		return service, supplier
+	}
+
+	t.Run("Link at a cost price in the default currency", func(t *testing.T) {
+		// Arrange
+		service, supplier := newService(t)
+
+		// Act
+		link, err := service.LinkProduct(context.Background(), "product-001", supplier.ID, model.ProductSupplierRequest{
+			CostPrice: "849.5", SupplierSKU: " AC-LAP-1 ",
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("849.50"), link.CostPrice)
+		assert.Equal(t, "USD", link.Currency)
+		assert.Equal(t, "AC-LAP-1", link.SupplierSKU)
+		assert.Equal(t, "Acme Parts", link.SupplierName)
+		assert.Equal(t, 7, link.LeadTimeDays)
+
+		suppliers, err := service.GetProductSuppliers(context.Background(), "product-001")
+		require.NoError(t, err)
+		require.Len(t, suppliers, 1)
+		assert.Equal(t, supplier.ID, suppliers[0].SupplierID)
+
+		products, err := service.GetSupplierProducts(context.Background(), supplier.ID)
+		require.NoError(t, err)
+		require.Len(t, products, 1)
+		assert.Equal(t, "product-001", products[0].ProductID)
+	})
+
+	t.Run("Change the cost price", func(t *testing.T) {
+		// Arrange
+		service, supplier := newService(t)
+		_, err := service.LinkProduct(context.Background(), "product-001", supplier.ID, model.ProductSupplierRequest{CostPrice: "849.50"})
+		require.NoError(t, err)
+
+		// Act
+		link, err := service.LinkProduct(context.Background(), "product-001", supplier.ID, model.ProductSupplierRequest{CostPrice: "799", Currency: "EUR"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("799.00"), link.CostPrice)
+		assert.Equal(t, "EUR", link.Currency)
+		suppliers, err := service.GetProductSuppliers(context.Background(), "product-001")
+		require.NoError(t, err)
+		assert.Len(t, suppliers, 1)
+	})
+
+	tests := []struct {
+		name      string
+		productID string
+		supplier  string
+		req       model.ProductSupplierRequest
+		err       error
+	}{
+		{"Unknown product", "missing", "", model.ProductSupplierRequest{CostPrice: "10"}, model.ErrProductNotFound},
+		{"Unknown supplier", "product-001", "missing", model.ProductSupplierRequest{CostPrice: "10"}, model.ErrSupplierNotFound},
+		{"Zero cost price", "product-001", "", model.ProductSupplierRequest{CostPrice: "0"}, model.ErrInvalidCostPrice},
+		{"Too precise cost price", "product-001", "", model.ProductSupplierRequest{CostPrice: "1.005"}, model.ErrInvalidCostPrice},
+		{"Unknown currency", "product-001", "", model.ProductSupplierRequest{CostPrice: "10", Currency: "XXZ"}, model.ErrUnsupportedCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			service, supplier := newService(t)
+			supplierID := tt.supplier
+			if supplierID == "" {
+				supplierID = supplier.ID
+			}
+
+			// Act
+			_, err := service.LinkProduct(context.Background(), tt.productID, supplierID, tt.req)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestSupplierService_UnlinkProduct(t *testing.T) {
+	// Arrange
+	service := newTestSupplierService()
+	supplier, err := service.CreateSupplier(context.Background(), model.SupplierRequest{Name: "Acme Parts"})
+	require.NoError(t, err)
+	_, err = service.LinkProduct(context.Background(), "product-001", supplier.ID, model.ProductSupplierRequest{CostPrice: "10"})
+	require.NoError(t, err)
+
+	// Act
+	err = service.UnlinkProduct(context.Background(), "product-001", supplier.ID)
+
+	// Assert
+	require.NoError(t, err)
+	suppliers, err := service.GetProductSuppliers(context.Background(), "product-001")
+	require.NoError(t, err)
+	assert.Empty(t, suppliers)
+	assert.ErrorIs(t, service.UnlinkProduct(context.Background(), "product-001", supplier.ID), model.ErrProductSupplierNotFound)
+}
+
+func TestSupplierService_GetProductSuppliers(t *testing.T) {
+	t.Run("Unknown product", func(t *testing.T) {
+		// Act
+		_, err := newTestSupplierService().GetProductSuppliers(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrProductNotFound)
+	})
+
+	t.Run("Unknown supplier", func(t *testing.T) {
+		// Act
+		_, err := newTestSupplierService().GetSupplierProducts(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, err, model.ErrSupplierNotFound)
+	})
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/product/repository"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+)
+
+// VariantService defines the interface for product variant business logic
+type VariantService interface {
+	GetVariants(ctx context.Context, productID string) ([]model.ProductVariantResponse, error)
+	GetVariant(ctx context.Context, productID, variantID string) (*model.ProductVariantResponse, error)
+	CreateVariant(ctx context.Context, productID string, req model.CreateVariantRequest) (*model.ProductVariantResponse, error)
+	UpdateVariant(ctx context.Context, productID, variantID string, req model.UpdateVariantRequest) (*model.ProductVariantResponse, error)
+	DeleteVariant(ctx context.Context, productID, variantID string) error
+}
+
+// variantService implements VariantService
+type variantService struct {
+	repo   repository.ProductRepository
+	ids    idgen.Generator
+	events events.Publisher
+}
+
+// NewVariantService creates a new variant service giving new variants IDs
+// of ids. Product update events go to events, which may be nil.
+func NewVariantService(repo repository.ProductRepository, ids idgen.Generator, events events.Publisher) VariantService {
+	return &variantService{
+		repo:   repo,
+		ids:    ids,
+		events: events,
+	}
+}
+
+// GetVariants retrieves the variants of a product
+func (s *variantService) GetVariants(ctx context.Context, productID string) ([]model.ProductVariantResponse, error) {
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return product.ToResponse().Variants, nil
+}
+
+// GetVariant retrieves a single variant of a product
+func (s *variantService) GetVariant(ctx context.Context, productID, variantID string) (*model.ProductVariantResponse, error) {
+	product, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, found := product.FindVariant(variantID)
+	if !found {
+		return nil, model.ErrVariantNotFound
+	}
+
+	response := variant.ToResponse(product.Price)
+	return &response, nil
+}
+
+// CreateVariant validates a variant and adds it to the product
+func (s *variantService) CreateVariant(ctx context.Context, productID string, req model.CreateVariantRequest) (*model.ProductVariantResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"sku":        req.SKU,
+	}).Debug("Creating product variant")
+
+	existing, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	variant := model.ProductVariant{
+		ID:            s.ids.New(),
+		SKU:           req.SKU,
+		Attributes:    req.Attributes,
+		StockQuantity: req.StockQuantity,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if req.Price != nil {
+		currency := req.Currency
+		if currency == "" {
+			currency = existing.Price.Currency
+		}
+		price, err := parsePrice(req.Price.String(), currency)
+		if err != nil {
+			return nil, err
+		}
+		variant.Price = &price
+	}
+
+	variant.Normalize()
+	if err := variant.Validate(); err != nil {
+		return nil, err
+	}
+
+	product, err := s.repo.AddVariant(ctx, productID, variant)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("product_id", productID).Warn("Failed to create product variant")
+		return nil, err
+	}
+
+	s.publish(ctx, product)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"variant_id": variant.ID,
+	}).Info("Successfully created product variant")
+
+	response := variant.ToResponse(product.Price)
+	return &response, nil
+}
+
+// UpdateVariant applies the provided changes to a variant
+func (s *variantService) UpdateVariant(ctx context.Context, productID, variantID string, req model.UpdateVariantRequest) (*model.ProductVariantResponse, error) {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"variant_id": variantID,
+	}).Debug("Updating product variant")
+
+	existing, err := s.repo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	variant, found := existing.FindVariant(variantID)
+	if !found {
+		return nil, model.ErrVariantNotFound
+	}
+
+	if req.SKU != nil {
+		variant.SKU = *req.SKU
+	}
+	if req.Attributes != nil {
+		variant.Attributes = req.Attributes
+	}
+	if req.StockQuantity != nil {
+		variant.StockQuantity = *req.StockQuantity
+	}
+	if req.InheritPrice {
+		variant.Price = nil
+	}
+	if req.Price != nil || req.Currency != nil {
+		price, err := variantPrice(existing, variant, req)
+		if err != nil {
+			return nil, err
+		}
+		variant.Price = &price
+	}
+
+	variant.Normalize()
+	if err := variant.Validate(); err != nil {
+		return nil, err
+	}
+	variant.UpdatedAt = time.Now().UTC()
+
+	product, err := s.repo.UpdateVariant(ctx, productID, variant)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"product_id": productID,
+			"variant_id": variantID,
+		}).Warn("Failed to update product variant")
+		return nil, err
+	}
+
+	s.publish(ctx, product)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"variant_id": variantID,
+	}).Info("Successfully updated product variant")
+
+	response := variant.ToResponse(product.Price)
+	return &response, nil
+}
+
+// DeleteVariant removes a variant from its product
+func (s *variantService) DeleteVariant(ctx context.Context, productID, variantID string) error {
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"variant_id": variantID,
+	}).Debug("Deleting product variant")
+
+	product, err := s.repo.RemoveVariant(ctx, productID, variantID)
+	if err != nil {
+		return err
+	}
+
+	s.publish(ctx, product)
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"product_id": productID,
+		"variant_id": variantID,
+	}).Info("Successfully deleted product variant")
+	return nil
+}
+
+// variantPrice works out the price override of an update. Amount and
+// currency default to the current override, or to the product price if the
+// variant has none.
+func variantPrice(product *model.Product, variant model.ProductVariant, req model.UpdateVariantRequest) (money.Money, error) {
+	current := product.Price
+	if variant.Price != nil {
+		current = *variant.Price
+	}
+
+	amount := current.Decimal()
+	if req.Price != nil {
+		amount = req.Price.String()
+	}
+	currency := current.Currency
+	if req.Currency != nil {
+		currency = *req.Currency
+	}
+
+	return parsePrice(amount, currency)
+}
+
+// publish announces the product with its changed variants
+func (s *variantService) publish(ctx context.Context, product *model.Product) {
+	if s.events != nil {
+		s.events.Publish(events.New(events.ProductUpdated, product.ID, product.ToResponse()).In(ctx))
+	}
+}
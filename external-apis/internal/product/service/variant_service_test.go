@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"external-apis/internal/product/model"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantService_CreateVariant(t *testing.T) {
+	t.Run("Normalize and add the variant", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		publisher := events.NewMemoryPublisher()
+		service := NewVariantService(mockRepo, idgen.Default(), publisher)
+		product := &model.Product{ID: "product-123", Price: money.New(2000, "EUR")}
+		price := json.Number("24.50")
+
+		mockRepo.On("GetByID", "product-123").Return(product, nil)
+		mockRepo.On("AddVariant", "product-123", mock.MatchedBy(func(variant model.ProductVariant) bool {
+			return variant.SKU == "TSHIRT-L" && variant.Attributes["size"] == "l" && *variant.Price == money.New(2450, "EUR")
+		})).Return(product, nil)
+
+		// Act
+		result, err := service.CreateVariant(context.Background(), "product-123", model.CreateVariantRequest{
+			SKU:           " TSHIRT-L ",
+			Attributes:    map[string]string{"Size ": " l"},
+			Price:         &price,
+			StockQuantity: 4,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"size": "l"}, result.Attributes)
+		assert.Equal(t, json.Number("24.50"), result.Price)
+		assert.Equal(t, "EUR", result.Currency)
+		assert.True(t, result.PriceOverride)
+		assert.Equal(t, 4, result.StockQuantity)
+
+		published := publisher.Events()
+		require.Len(t, published, 1)
+		assert.Equal(t, events.ProductUpdated, published[0].Type)
+		mockRepo.AssertExpectations(t)
+	})
+
+	zero := json.Number("0")
+	tests := []struct {
+		name string
+		req  model.CreateVariantRequest
+		err  error
+	}{
+		{name: "Blank SKU", req: model.CreateVariantRequest{SKU: " ", Attributes: map[string]string{"size": "m"}}, err: model.ErrSKURequired},
+		{name: "Blank attribute", req: model.CreateVariantRequest{SKU: "SKU-1", Attributes: map[string]string{"size": " "}}, err: model.ErrInvalidVariantAttribute},
+		{name: "Negative stock", req: model.CreateVariantRequest{SKU: "SKU-1", Attributes: map[string]string{"size": "m"}, StockQuantity: -1}, err: model.ErrNegativeStock},
+		{name: "Invalid price", req: model.CreateVariantRequest{SKU: "SKU-1", Attributes: map[string]string{"size": "m"}, Price: &zero}, err: model.ErrPriceNotPositive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockProductRepository)
+			service := NewVariantService(mockRepo, idgen.Default(), nil)
+
+			mockRepo.On("GetByID", "product-123").Return(&model.Product{ID: "product-123", Price: money.New(2000, "USD")}, nil)
+
+			// Act
+			result, err := service.CreateVariant(context.Background(), "product-123", tt.req)
+
+			// Assert
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, tt.err)
+			mockRepo.AssertNotCalled(t, "AddVariant", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestVariantService_UpdateVariant(t *testing.T) {
+	override := money.New(2500, "USD")
+	variant := model.ProductVariant{
+		ID:         "variant-1",
+		SKU:        "TSHIRT-M",
+		Attributes: map[string]string{"size": "m"},
+		Price:      &override,
+	}
+	product := &model.Product{ID: "product-123", Price: money.New(2000, "USD"), Variants: []model.ProductVariant{variant}}
+
+	t.Run("Change stock and inherit the product price", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewVariantService(mockRepo, idgen.Default(), nil)
+		stock := 9
+
+		mockRepo.On("GetByID", "product-123").Return(product, nil)
+		mockRepo.On("UpdateVariant", "product-123", mock.MatchedBy(func(updated model.ProductVariant) bool {
+			return updated.ID == "variant-1" && updated.Price == nil && updated.StockQuantity == 9
+		})).Return(product, nil)
+
+		// Act
+		result, err := service.UpdateVariant(context.Background(), "product-123", "variant-1", model.UpdateVariantRequest{
+			StockQuantity: &stock,
+			InheritPrice:  true,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("20.00"), result.Price)
+		assert.False(t, result.PriceOverride)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Change the currency of the override", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewVariantService(mockRepo, idgen.Default(), nil)
+		currency := "EUR"
+
+		mockRepo.On("GetByID", "product-123").Return(product, nil)
+		mockRepo.On("UpdateVariant", "product-123", mock.AnythingOfType("model.ProductVariant")).Return(product, nil)
+
+		// Act
+		result, err := service.UpdateVariant(context.Background(), "product-123", "variant-1", model.UpdateVariantRequest{
+			Currency: &currency,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("25.00"), result.Price)
+		assert.Equal(t, "EUR", result.Currency)
+	})
+
+	t.Run("Unknown variant", func(t *testing.T) {
+		// Arrange
+		mockRepo := new(MockProductRepository)
+		service := NewVariantService(mockRepo, idgen.Default(), nil)
+
+		mockRepo.On("GetByID", "product-123").Return(product, nil)
+
+		// Act
+		result, err := service.UpdateVariant(context.Background(), "product-123", "variant-2", model.UpdateVariantRequest{})
+
+		// Assert
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, model.ErrVariantNotFound)
+		mockRepo.AssertNotCalled(t, "UpdateVariant", mock.Anything, mock.Anything)
+	})
+}
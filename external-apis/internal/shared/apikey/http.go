@@ -0,0 +1,158 @@
+package apikey
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("shared/apikey")
+
+// ContextKey is the Gin context key holding the authenticated *Key
+const ContextKey = "api_key"
+
+// CreateKeyRequest represents the request to create an API key
+type CreateKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+}
+
+// CreateKeyResponse is returned once when a key is created. It is the only
+// response that ever contains the plaintext key.
+type CreateKeyResponse struct {
+	*Key
+	Token string `json:"key"`
+}
+
+// Middleware authenticates requests carrying an X-API-Key header and checks
+// that the key grants the read (safe methods) or write scope on resource.
+// Requests without the header pass through untouched so JWT or public access
+// still applies; requests authenticated here skip the JWT check.
+func Middleware(manager *Manager, resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(middleware.APIKeyHeader)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		key, err := manager.Authenticate(token)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidKey) {
+				log.Ctx(c.Request.Context()).WithError(err).Error("Failed to authenticate api key")
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, response.ErrorResponse{
+				Error:   "unauthorized",
+				Message: "Invalid API key",
+				Code:    http.StatusUnauthorized,
+			})
+			return
+		}
+
+		action := ActionWrite
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			action = ActionRead
+		}
+
+		if !key.Allows(resource, action) {
+			c.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
+				Error:   "forbidden",
+				Message: "API key lacks the " + resource + ":" + action + " scope",
+				Code:    http.StatusForbidden,
+			})
+			return
+		}
+
+		c.Set(ContextKey, key)
+		c.Set(middleware.AuthenticatedKey, true)
+		c.Set(middleware.APIKeyIDKey, key.ID)
+		c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), "api-key:"+key.ID))
+		c.Next()
+	}
+}
+
+// Handler serves the admin endpoints for managing API keys
+type Handler struct {
+	manager *Manager
+}
+
+// NewHandler creates a new API key admin handler
+func NewHandler(manager *Manager) *Handler {
+	return &Handler{manager: manager}
+}
+
+// RegisterRoutes registers the API key admin routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	keys := router.Group("/api-keys")
+	{
+		keys.GET("", h.ListKeys)
+		keys.POST("", h.CreateKey)
+		keys.DELETE("/:id", h.RevokeKey)
+	}
+}
+
+// ListKeys returns all API keys without their secrets
+func (h *Handler) ListKeys(c *gin.Context) {
+	keys, err := h.manager.List()
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to list api keys")
+		response.InternalServerError(c, "Failed to list API keys")
+		return
+	}
+
+	response.OK(c, keys)
+}
+
+// CreateKey issues a new API key
+func (h *Handler) CreateKey(c *gin.Context) {
+	var req CreateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	key, token, err := h.manager.Create(req.Name, req.Scopes)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNameRequired), errors.Is(err, ErrScopesRequired), errors.Is(err, ErrInvalidScope):
+			response.BadRequest(c, err.Error())
+		default:
+			log.Ctx(c.Request.Context()).WithError(err).Error("Failed to create api key")
+			response.InternalServerError(c, "Failed to create API key")
+		}
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"key_id": key.ID,
+		"name":   key.Name,
+		"scopes": key.Scopes,
+	}).Info("API key created")
+
+	response.Created(c, CreateKeyResponse{Key: key, Token: token})
+}
+
+// RevokeKey revokes an API key so it can no longer authenticate
+func (h *Handler) RevokeKey(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.manager.Revoke(id); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			response.NotFound(c, "API key not found")
+			return
+		}
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to revoke api key")
+		response.InternalServerError(c, "Failed to revoke API key")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithField("key_id", id).Info("API key revoked")
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,126 @@
+package apikey
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	manager := NewManager(NewMemoryStore())
+	reader, readToken, err := manager.Create("reader", []string{"products:read"})
+	require.NoError(t, err)
+	writer, writeToken, err := manager.Create("writer", []string{"products:read", "products:write"})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(Middleware(manager, "products"))
+	handle := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"authenticated": c.GetBool(middleware.AuthenticatedKey),
+			"actor":         auth.Actor(c.Request.Context()),
+		})
+	}
+	router.GET("/products", handle)
+	router.POST("/products", handle)
+
+	tests := []struct {
+		name          string
+		method        string
+		token         string
+		expectedCode  int
+		authenticated bool
+		actor         string
+	}{
+		{"No key passes through", http.MethodPost, "", http.StatusOK, false, auth.AnonymousActor},
+		{"Read scope allows GET", http.MethodGet, readToken, http.StatusOK, true, "api-key:" + reader.ID},
+		{"Read scope rejects POST", http.MethodPost, readToken, http.StatusForbidden, false, ""},
+		{"Write scope allows POST", http.MethodPost, writeToken, http.StatusOK, true, "api-key:" + writer.ID},
+		{"Unknown key is rejected", http.MethodGet, TokenPrefix + "unknown", http.StatusUnauthorized, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			req := httptest.NewRequest(tt.method, "/products", nil)
+			if tt.token != "" {
+				req.Header.Set(middleware.APIKeyHeader, tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, `{"authenticated":`+strconv.FormatBool(tt.authenticated)+`,"actor":"`+tt.actor+`"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CreateAndRevoke(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	manager := NewManager(NewMemoryStore())
+	router := gin.New()
+	NewHandler(manager).RegisterRoutes(router.Group("/admin"))
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/api-keys",
+		strings.NewReader(`{"name":"partner","scopes":["products:read"]}`)))
+
+	// Assert
+	require.Equal(t, http.StatusCreated, w.Code)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	created := body.Data
+	assert.Equal(t, "partner", created["name"])
+	assert.NotContains(t, created, "hash")
+	token, _ := created["key"].(string)
+	assert.True(t, strings.HasPrefix(token, TokenPrefix))
+
+	t.Run("List omits secrets", func(t *testing.T) {
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), token)
+	})
+
+	t.Run("Revoke key", func(t *testing.T) {
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+created["id"].(string), nil))
+
+		// Assert
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		_, err := manager.Authenticate(token)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/api-keys",
+			strings.NewReader(`{"name":"partner","scopes":["products"]}`)))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,103 @@
+package apikey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidKey is returned when a presented key is unknown or revoked
+	ErrInvalidKey = errors.New("invalid api key")
+	// ErrNameRequired is returned when a key is created without a name
+	ErrNameRequired = errors.New("api key name is required")
+	// ErrScopesRequired is returned when a key is created without scopes
+	ErrScopesRequired = errors.New("at least one scope is required")
+)
+
+// Manager issues, authenticates and revokes API keys
+type Manager struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewManager creates a new API key manager backed by the given store
+func NewManager(store Store) *Manager {
+	return &Manager{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Create issues a new key and returns it together with the plaintext token.
+// The plaintext is never stored and cannot be retrieved again.
+func (m *Manager) Create(name string, scopes []string) (*Key, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrNameRequired
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrScopesRequired
+	}
+	for _, scope := range scopes {
+		if err := ValidateScope(scope); err != nil {
+			return nil, "", err
+		}
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &Key{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Prefix:    token[:len(TokenPrefix)+8],
+		Hash:      hashToken(token),
+		Scopes:    append([]string(nil), scopes...),
+		CreatedAt: m.now().UTC(),
+	}
+
+	if err := m.store.Create(key); err != nil {
+		return nil, "", fmt.Errorf("failed to store api key: %w", err)
+	}
+
+	return key, token, nil
+}
+
+// Authenticate returns the active key matching the plaintext token
+func (m *Manager) Authenticate(token string) (*Key, error) {
+	key, err := m.store.GetByHash(hashToken(token))
+	if errors.Is(err, ErrKeyNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Revoked() {
+		return nil, ErrInvalidKey
+	}
+
+	now := m.now().UTC()
+	if err := m.store.Touch(key.ID, now); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+
+	return key, nil
+}
+
+// List returns all issued keys, including revoked ones
+func (m *Manager) List() ([]*Key, error) {
+	return m.store.GetAll()
+}
+
+// Revoke revokes the key with the given ID
+func (m *Manager) Revoke(id string) error {
+	return m.store.Revoke(id, m.now().UTC())
+}
@@ -0,0 +1,106 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Create(t *testing.T) {
+	t.Run("Create valid key", func(t *testing.T) {
+		// Arrange
+		store := NewMemoryStore()
+		manager := NewManager(store)
+
+		// Act
+		key, token, err := manager.Create("partner", []string{"products:read", "products:write"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(token, TokenPrefix))
+		assert.True(t, strings.HasPrefix(token, key.Prefix))
+		assert.Equal(t, hashToken(token), key.Hash)
+		assert.NotContains(t, key.Hash, token)
+
+		stored, err := store.GetByHash(hashToken(token))
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, stored.ID)
+	})
+
+	tests := []struct {
+		name    string
+		keyName string
+		scopes  []string
+		err     error
+	}{
+		{"Missing name", " ", []string{"products:read"}, ErrNameRequired},
+		{"Missing scopes", "partner", nil, ErrScopesRequired},
+		{"Scope without action", "partner", []string{"products"}, ErrInvalidScope},
+		{"Unknown action", "partner", []string{"products:delete"}, ErrInvalidScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			manager := NewManager(NewMemoryStore())
+
+			// Act
+			key, token, err := manager.Create(tt.keyName, tt.scopes)
+
+			// Assert
+			assert.Nil(t, key)
+			assert.Empty(t, token)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestManager_Authenticate(t *testing.T) {
+	// Arrange
+	manager := NewManager(NewMemoryStore())
+	key, token, err := manager.Create("partner", []string{"products:*"})
+	require.NoError(t, err)
+
+	t.Run("Valid key", func(t *testing.T) {
+		// Act
+		authenticated, err := manager.Authenticate(token)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, key.ID, authenticated.ID)
+		assert.NotNil(t, authenticated.LastUsedAt)
+		assert.True(t, authenticated.Allows("products", ActionWrite))
+		assert.False(t, authenticated.Allows("customers", ActionRead))
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		// Act
+		authenticated, err := manager.Authenticate(TokenPrefix + "unknown")
+
+		// Assert
+		assert.Nil(t, authenticated)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		// Arrange
+		require.NoError(t, manager.Revoke(key.ID))
+
+		// Act
+		authenticated, err := manager.Authenticate(token)
+
+		// Assert
+		assert.Nil(t, authenticated)
+		assert.ErrorIs(t, err, ErrInvalidKey)
+	})
+
+	t.Run("Revoke unknown key", func(t *testing.T) {
+		// Act
+		err := manager.Revoke("missing")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
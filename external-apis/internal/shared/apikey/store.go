@@ -0,0 +1,121 @@
+package apikey
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrKeyNotFound is returned when no key matches the lookup
+var ErrKeyNotFound = errors.New("api key not found")
+
+// Store persists API keys
+type Store interface {
+	Create(key *Key) error
+	GetByHash(hash string) (*Key, error)
+	GetAll() ([]*Key, error)
+	Revoke(id string, at time.Time) error
+	Touch(id string, at time.Time) error
+}
+
+// MemoryStore keeps API keys in memory
+type MemoryStore struct {
+	keys   map[string]*Key
+	hashes map[string]string
+	mutex  sync.RWMutex
+}
+
+// NewMemoryStore creates a new in-memory key store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		keys:   make(map[string]*Key),
+		hashes: make(map[string]string),
+	}
+}
+
+// Create stores a new key
+func (s *MemoryStore) Create(key *Key) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := copyKey(key)
+	s.keys[key.ID] = stored
+	s.hashes[key.Hash] = key.ID
+	return nil
+}
+
+// GetByHash returns the key with the given hash
+func (s *MemoryStore) GetByHash(hash string) (*Key, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	id, exists := s.hashes[hash]
+	if !exists {
+		return nil, ErrKeyNotFound
+	}
+
+	return copyKey(s.keys[id]), nil
+}
+
+// GetAll returns all keys ordered by creation time
+func (s *MemoryStore) GetAll() ([]*Key, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, copyKey(key))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// Revoke marks a key as revoked
+func (s *MemoryStore) Revoke(id string, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return ErrKeyNotFound
+	}
+
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+	}
+	return nil
+}
+
+// Touch records the last time a key was used
+func (s *MemoryStore) Touch(id string, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, exists := s.keys[id]
+	if !exists {
+		return ErrKeyNotFound
+	}
+
+	key.LastUsedAt = &at
+	return nil
+}
+
+// copyKey returns a copy of key that shares no mutable state with it
+func copyKey(key *Key) *Key {
+	copied := *key
+	copied.Scopes = append([]string(nil), key.Scopes...)
+	if key.LastUsedAt != nil {
+		lastUsed := *key.LastUsedAt
+		copied.LastUsedAt = &lastUsed
+	}
+	if key.RevokedAt != nil {
+		revoked := *key.RevokedAt
+		copied.RevokedAt = &revoked
+	}
+	return &copied
+}
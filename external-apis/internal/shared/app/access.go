@@ -0,0 +1,258 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"external-apis/internal/shared/audit"
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/quota"
+	"external-apis/internal/shared/ratelimit"
+	"external-apis/internal/shared/rbac"
+	"external-apis/internal/shared/shutdown"
+	"external-apis/internal/shared/tlsconfig"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTLS loads the certificate the servers present and reloads it when the
+// files are rotated, or returns nil when TLS is disabled
+func (a *App) newTLS() *tlsconfig.Reloader {
+	settings := a.Config.TLS
+	if !settings.Enabled {
+		log.Warn("TLS disabled, serving plaintext HTTP and gRPC")
+		return nil
+	}
+
+	certs, err := tlsconfig.New(tlsconfig.Config{
+		CertFile:     settings.CertFile,
+		KeyFile:      settings.KeyFile,
+		ClientCAFile: settings.ClientCAFile,
+		ClientAuth:   settings.ClientAuth,
+		CAFile:       settings.CAFile,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load TLS certificate")
+	}
+	a.Jobs.Schedule("tls-reload", jobs.Every(settings.ReloadInterval), func(context.Context) error {
+		_, err := certs.Reload()
+		return err
+	})
+
+	log.WithField("client_auth", settings.ClientAuth).Info("Serving over TLS")
+	return certs
+}
+
+// newRateLimiter creates the API rate limiter. A Redis URL switches to a shared
+// limiter so all instances enforce a single budget.
+func (a *App) newRateLimiter() ratelimit.Limiter {
+	settings := a.Config.RateLimit
+	if !settings.Enabled {
+		log.Info("Rate limiting disabled")
+		return nil
+	}
+
+	config := ratelimit.Config{
+		Rate:  settings.RPS,
+		Burst: settings.Burst,
+	}
+
+	if redisURL := settings.RedisURL; redisURL != "" {
+		options, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid rate limit Redis URL")
+		}
+
+		client := redis.NewClient(options)
+		a.Hooks.Add("redis", shutdown.Closer(client))
+		a.Health.Register("redis", func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		})
+
+		log.Info("Using Redis-backed rate limiter")
+		return ratelimit.NewRedisLimiter(client, config, "ratelimit:"+a.Service.Name+":")
+	}
+
+	limiter := ratelimit.NewMemoryLimiter(config)
+	a.Jobs.Schedule("ratelimit-cleanup", jobs.Every(time.Minute), func(ctx context.Context) error {
+		limiter.Cleanup()
+		return nil
+	})
+
+	return limiter
+}
+
+// newQuotaManager creates the daily request quotas. A Redis URL switches to
+// counters shared by all instances.
+func (a *App) newQuotaManager() *quota.Manager {
+	settings := a.Config.Quota
+	if !settings.Enabled {
+		log.Info("Request quotas disabled")
+		return nil
+	}
+
+	// The routes were validated when the configuration was loaded
+	routes, _ := quota.ParseRoutes(settings.Routes)
+	config := quota.Config{
+		Read:   int64(settings.ReadPerDay),
+		Write:  int64(settings.WritePerDay),
+		Routes: routes,
+	}
+
+	if redisURL := settings.RedisURL; redisURL != "" {
+		options, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid quota Redis URL")
+		}
+
+		client := redis.NewClient(options)
+		a.Hooks.Add("quota-redis", shutdown.Closer(client))
+		a.Health.Register("quota-redis", func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		})
+
+		log.Info("Using Redis-backed request quotas")
+		return quota.NewManager(quota.NewRedisStore(client, "quota:"+a.Service.Name+":"), config)
+	}
+
+	store := quota.NewMemoryStore()
+	a.Jobs.Schedule("quota-cleanup", jobs.Every(time.Hour), func(ctx context.Context) error {
+		store.Cleanup()
+		return nil
+	})
+
+	return quota.NewManager(store, config)
+}
+
+// newRoles creates the authorizer checking the roles of callers and assigns
+// the admin role to the configured principals
+func (a *App) newRoles() *rbac.Authorizer {
+	settings := a.Config.RBAC
+	// Mappings were validated with the configuration
+	claimRoles, _ := rbac.ParseClaimRoles(settings.RoleMappings)
+	roles := rbac.NewAuthorizer(rbac.NewMemoryStore(), rbac.Config{
+		DefaultRole:   settings.DefaultRole,
+		AnonymousRole: settings.AnonymousRole,
+		RoleClaims:    settings.RoleClaims,
+		ClaimRoles:    claimRoles,
+	})
+	for _, principal := range settings.Admins {
+		if _, err := roles.Assign(principal, rbac.RoleAdmin, "config"); err != nil {
+			log.WithError(err).Fatal("Failed to assign admin role")
+		}
+	}
+	return roles
+}
+
+// newAuthMiddleware creates the JWT middleware protecting write routes,
+// which then checks the role of the caller. Authentication stays disabled
+// until a key or OIDC provider is configured.
+func (a *App) newAuthMiddleware() gin.HandlerFunc {
+	settings := a.Config.Auth
+	config := auth.Config{
+		HMACSecret: []byte(settings.HMACSecret),
+		Issuer:     settings.Issuer,
+		Audience:   settings.Audience,
+		Leeway:     settings.Leeway,
+	}
+
+	publicKey := []byte(settings.RSAPublicKey)
+	if path := settings.RSAPublicKeyFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to read JWT RSA public key")
+		}
+		publicKey = data
+	}
+	if len(publicKey) > 0 {
+		key, err := auth.ParseRSAPublicKey(publicKey)
+		if err != nil {
+			log.WithError(err).Fatal("Invalid JWT RSA public key")
+		}
+		config.RSAPublicKey = key
+	}
+	if settings.OIDCIssuer != "" || settings.OIDCJWKSURL != "" {
+		config.KeySet = a.newKeySet()
+		if config.Issuer == "" {
+			config.Issuer = settings.OIDCIssuer
+		}
+	}
+
+	validator, err := auth.NewValidator(config)
+	if errors.Is(err, auth.ErrNoKeys) {
+		log.Warn("No JWT key configured, write routes are unauthenticated")
+		return middleware.NoAuth(a.Roles.Authorize)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure JWT authentication")
+	}
+
+	return middleware.JWTAuth(validator, a.Roles.Authorize)
+}
+
+// newKeySet creates the cache of the signing keys of the OIDC provider and
+// refreshes it in the background. A provider unreachable at startup only
+// delays authentication until the keys are fetched.
+func (a *App) newKeySet() *auth.KeySet {
+	settings := a.Config.Auth
+	keySet, err := auth.NewKeySet(auth.KeySetConfig{
+		Issuer:          settings.OIDCIssuer,
+		JWKSURL:         settings.OIDCJWKSURL,
+		RefreshInterval: settings.OIDCRefreshInterval,
+		Timeout:         settings.OIDCTimeout,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("Failed to configure OIDC authentication")
+	}
+	if err := keySet.Refresh(); err != nil {
+		log.WithError(err).Warn("Failed to fetch OIDC signing keys, retrying on use")
+	}
+	a.Jobs.Schedule("jwks-refresh", jobs.Every(settings.OIDCRefreshInterval), func(context.Context) error {
+		return keySet.Refresh()
+	})
+
+	log.WithField("issuer", settings.OIDCIssuer).Info("Accepting OIDC access tokens")
+	return keySet
+}
+
+// newAuditLog creates the sink recording mutating calls and the log the
+// admin endpoint queries; both are nil when auditing is disabled
+func (a *App) newAuditLog() (audit.Sink, audit.Querier) {
+	settings := a.Config.Audit
+	if !settings.Enabled {
+		log.Warn("Audit log disabled")
+		return nil, nil
+	}
+
+	switch settings.Sink {
+	case "file":
+		sink, err := audit.NewFileSink(settings.File)
+		if err != nil {
+			log.WithError(err).WithField("file", settings.File).Fatal("Failed to open audit log")
+		}
+		a.Hooks.Add("audit", func(context.Context) error {
+			return sink.Close()
+		})
+		return sink, sink
+	case "kafka":
+		recent := audit.NewMemorySink(settings.MemoryCapacity)
+		sink := audit.NewKafkaSink(events.NewKafkaWriter(events.KafkaConfig{
+			Brokers:      a.Config.Kafka.Brokers,
+			Topic:        settings.Topic,
+			BatchTimeout: a.Config.Kafka.BatchTimeout,
+		}))
+		a.Hooks.Add("audit", func(context.Context) error {
+			return sink.Close()
+		})
+		return audit.Multi{recent, sink}, recent
+	default:
+		sink := audit.NewMemorySink(settings.MemoryCapacity)
+		return sink, sink
+	}
+}
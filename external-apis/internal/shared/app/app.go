@@ -0,0 +1,317 @@
+// Package app bootstraps the services: it loads the configuration,
+// configures the logger and wires what every service shares, from the
+// middleware stack, health probes and access control to background jobs,
+// storage and graceful shutdown, so a main only builds its repositories,
+// services and handlers and registers their routes.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"external-apis/internal/shared/apikey"
+	"external-apis/internal/shared/audit"
+	"external-apis/internal/shared/config"
+	"external-apis/internal/shared/grpcserver"
+	"external-apis/internal/shared/healthcheck"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/metrics"
+	"external-apis/internal/shared/quota"
+	"external-apis/internal/shared/ratelimit"
+	"external-apis/internal/shared/rbac"
+	"external-apis/internal/shared/sandbox"
+	"external-apis/internal/shared/shutdown"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/tlsconfig"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+var log = logger.New("shared/app")
+
+// Service describes a service built on the shared bootstrap
+type Service struct {
+	// Name identifies the service in metrics, health reports, audit
+	// records and Redis keys, e.g. "customer-service"
+	Name string
+	// Title names the service in logs and on the root endpoint, e.g.
+	// "Customer Service"
+	Title   string
+	Version string
+	// Defaults sets the defaults of the service, such as its ports and
+	// state files, over the shared ones before the configuration is loaded
+	Defaults func(defaults *config.Config)
+	// Migrations is the schema of the repositories the sqlite storage
+	// backend keeps, applied by OpenDatabase and the migrate subcommand
+	Migrations fs.FS
+	// Swagger serves the API documentation registered by the docs package
+	// of the service under /swagger
+	Swagger bool
+	// Endpoints lists the endpoints of the service the root endpoint points
+	// to besides the shared ones, by name
+	Endpoints map[string]string
+}
+
+// App holds what the services share. Its fields are set up by New; the
+// sandbox is set once the service registers its stores with StartSandbox.
+type App struct {
+	Service Service
+	Config  config.Config
+	// Hooks release resources on shutdown, in reverse order of registration
+	Hooks *shutdown.Coordinator
+	// Health collects the dependency checks behind the readiness probe
+	Health  *healthcheck.Registry
+	Tenants tenant.Config
+	Jobs    *jobs.Manager
+	// HTTPMetrics and RepositoryMetrics are served on /metrics along with
+	// the connection pool metrics of the databases opened by OpenDatabase
+	HTTPMetrics       *metrics.HTTPMetrics
+	RepositoryMetrics *metrics.RepositoryMetrics
+	// TLS is the certificate the servers present, or nil when TLS is
+	// disabled
+	TLS     *tlsconfig.Reloader
+	Limiter ratelimit.Limiter
+	Quotas  *quota.Manager
+	APIKeys *apikey.Manager
+	Roles   *rbac.Authorizer
+	// RequireAuth authenticates the caller and checks their role; write
+	// routes are registered behind it
+	RequireAuth gin.HandlerFunc
+	AuditSink   audit.Sink
+	AuditLog    audit.Querier
+	Sandbox     *sandbox.Sandbox
+}
+
+// New loads the configuration of the service, configures the logger and
+// sets up what the services share. When the service is run with the migrate
+// subcommand it carries it out and exits instead.
+func New(svc Service) *App {
+	// Load configuration from the config file and the environment
+	cfg := loadConfig(svc)
+
+	// Initialize logger
+	initLogger(cfg.Logging)
+
+	// Apply or roll back the schema migrations by hand instead of serving,
+	// e.g. with "migrate down 1"
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrations(cfg.Database, svc.Migrations, os.Args[2:]))
+	}
+
+	log.WithField("port", cfg.HTTP.Port).Info("Starting " + svc.Title)
+
+	a := &App{
+		Service: svc,
+		Config:  cfg,
+		Hooks:   shutdown.New(cfg.HTTP.ShutdownTimeout),
+		Health:  healthcheck.NewRegistry(svc.Name, svc.Version, cfg.HTTP.HealthCheckTimeout),
+		// Resolve the tenant of every request
+		Tenants: newTenantConfig(cfg.Tenants),
+		// Job handlers are registered by the services before StartJobs
+		Jobs: jobs.NewManager(
+			jobs.NewFileStore(cfg.Jobs.StateFile),
+			jobs.DefaultOptions(),
+		),
+		HTTPMetrics: metrics.NewHTTPMetrics(svc.Name),
+	}
+	a.RepositoryMetrics = metrics.NewRepositoryMetrics(a.HTTPMetrics.Registry(), svc.Name)
+
+	// Serve HTTPS and gRPC over TLS when configured
+	a.TLS = a.newTLS()
+
+	// Control who may call the API and how often
+	a.Limiter = a.newRateLimiter()
+	a.Quotas = a.newQuotaManager()
+	a.APIKeys = apikey.NewManager(apikey.NewMemoryStore())
+	a.Roles = a.newRoles()
+	a.RequireAuth = a.newAuthMiddleware()
+
+	// Record mutating calls in the audit log
+	a.AuditSink, a.AuditLog = a.newAuditLog()
+	return a
+}
+
+// StartJobs starts the background jobs once every job kind is registered.
+// Unfinished work is persisted on shutdown for the next start.
+func (a *App) StartJobs() {
+	if err := a.Jobs.Start(); err != nil {
+		log.WithError(err).Fatal("Failed to start background jobs")
+	}
+	a.Hooks.Add("jobs", func(ctx context.Context) error {
+		return a.Jobs.Drain(shutdown.Remaining(ctx, 0))
+	})
+}
+
+// StartSandbox sets up sandbox mode over the memory stores of the service,
+// by name, and starts purging their expired writes
+func (a *App) StartSandbox(stores map[string]sandbox.Purgeable) {
+	a.Sandbox = sandbox.New(sandbox.Config{
+		Enabled:       a.Config.Sandbox.Enabled,
+		TTL:           a.Config.Sandbox.TTL,
+		PurgeInterval: a.Config.Sandbox.PurgeInterval,
+	}, stores)
+	a.Sandbox.Start(a.Jobs)
+}
+
+// ServeGRPC starts the gRPC server, over TLS when configured, with the
+// services register adds, and stops it on shutdown
+func (a *App) ServeGRPC(register func(server *grpc.Server)) {
+	opts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(grpcserver.TenantInterceptor(a.Tenants))}
+	if a.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(a.TLS.ServerConfig("h2"))))
+	}
+	server := grpcserver.New(opts...)
+	register(server)
+
+	port := a.Config.GRPC.Port
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.WithError(err).WithField("grpc_port", port).Fatal("Failed to listen for gRPC")
+	}
+	go func() {
+		log.WithField("grpc_port", port).Info("gRPC server listening")
+		if err := server.Serve(listener); err != nil {
+			log.WithError(err).Error("gRPC server stopped")
+		}
+	}()
+
+	a.Hooks.Add("grpc", func(ctx context.Context) error {
+		return grpcserver.Shutdown(ctx, server)
+	})
+}
+
+// Run serves HTTP with handler, over TLS when configured, until a shutdown
+// signal arrives, then fails the readiness probe, drains the server and
+// releases the resources registered with the shutdown hooks
+func (a *App) Run(handler http.Handler) {
+	log.Info("✅ " + a.Service.Title + " started successfully")
+	log.WithField("url", a.URL()).Info("Service is available")
+
+	server := a.newHTTPServer(handler)
+	a.addReadinessHook()
+	go func() {
+		if err := a.serve(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Fatal("Failed to start server")
+		}
+	}()
+
+	// Block until a shutdown signal arrives, then drain and release resources
+	if err := a.Hooks.Wait(); err != nil {
+		log.WithError(err).Warn(a.Service.Title + " did not shut down cleanly")
+	}
+	log.Info(a.Service.Title + " shutdown complete")
+}
+
+// URL returns the local URL of the HTTP server
+func (a *App) URL() string {
+	scheme := "http"
+	if a.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://localhost:%s", scheme, a.Config.HTTP.Port)
+}
+
+// loadConfig loads the configuration over the defaults of the service
+func loadConfig(svc Service) config.Config {
+	defaults := config.Defaults()
+	if svc.Defaults != nil {
+		svc.Defaults(&defaults)
+	}
+
+	cfg, err := config.Load(defaults)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load configuration")
+	}
+	return cfg
+}
+
+// initLogger configures the logger
+func initLogger(logging config.Logging) {
+	// The settings were validated when the configuration was loaded
+	level, _ := logger.ParseLevel(logging.Level)
+	packages, _ := logger.ParseOverrides(logging.Packages)
+	if err := logger.Configure(logger.Config{
+		Backend:  logging.Backend,
+		Level:    level,
+		Packages: packages,
+	}); err != nil {
+		log.WithError(err).Fatal("Failed to configure logger")
+	}
+
+	log.WithField("backend", logging.Backend).Info("Logger initialized")
+}
+
+// newTenantConfig configures how requests select their tenant. Allowed
+// restricts the served tenants; without it every valid X-Tenant-ID gets its
+// own storage on first use.
+func newTenantConfig(tenants config.Tenants) tenant.Config {
+	config := tenant.Config{Required: tenants.Required, Allowed: tenants.Allowed}
+
+	log.WithFields(logger.Fields{
+		"required": config.Required,
+		"allowed":  config.Allowed,
+	}).Info("Serving tenants selected by the " + tenant.Header + " header")
+	return config
+}
+
+// newHTTPServer creates the HTTP server and registers its shutdown hook, which
+// stops accepting connections and waits up to the drain timeout for in-flight
+// requests before closing the remaining connections
+func (a *App) newHTTPServer(handler http.Handler) *http.Server {
+	settings := a.Config.HTTP
+	server := &http.Server{
+		Addr:              ":" + settings.Port,
+		Handler:           handler,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+	}
+
+	a.Hooks.Add("http", func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, settings.DrainTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+			return err
+		}
+		return nil
+	})
+
+	return server
+}
+
+// serve serves HTTP until the server shuts down, over TLS when configured
+func (a *App) serve(server *http.Server) error {
+	if a.TLS == nil {
+		return server.ListenAndServe()
+	}
+	server.TLSConfig = a.TLS.ServerConfig("h2", "http/1.1")
+	return server.ListenAndServeTLS("", "")
+}
+
+// addReadinessHook registers the hook that fails the readiness probe when
+// shutdown starts. It runs before the HTTP server drains and waits for the
+// readiness drain delay, so load balancers stop routing new requests first.
+func (a *App) addReadinessHook() {
+	delay := a.Config.HTTP.ReadinessDrainDelay
+	a.Hooks.Add("readiness", func(ctx context.Context) error {
+		a.Health.Drain()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
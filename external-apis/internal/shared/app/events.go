@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/email"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/schemaregistry"
+	"external-apis/internal/shared/shutdown"
+	"external-apis/internal/shared/webhook"
+)
+
+// NewDeadLetterQueue creates the queue parking events whose delivery failed
+// for good so they can be replayed, persisted to the state file when one is
+// configured
+func (a *App) NewDeadLetterQueue() *deadletter.Queue {
+	settings := a.Config.DeadLetters
+	if settings.StateFile == "" {
+		return deadletter.NewQueue(deadletter.NewMemoryStore())
+	}
+
+	store, err := deadletter.NewFileStore(settings.StateFile)
+	if err != nil {
+		log.WithError(err).WithField("state_file", settings.StateFile).Fatal("Failed to load dead letters")
+	}
+	return deadletter.NewQueue(store)
+}
+
+// NewWebhookDispatcher creates the dispatcher delivering the given event
+// types to webhook subscribers as CloudEvents identifying the service
+func (a *App) NewWebhookDispatcher(deadLetters *deadletter.Queue, eventTypes []string) *webhook.Dispatcher {
+	settings := a.Config.Webhooks
+	dispatcher := webhook.NewDispatcher(webhook.NewMemoryStore(), a.Jobs, webhook.Config{
+		MaxAttempts:    settings.MaxAttempts,
+		InitialBackoff: settings.InitialBackoff,
+		MaxBackoff:     settings.MaxBackoff,
+		Timeout:        settings.Timeout,
+		Encoder:        a.encoder(),
+		DeadLetters:    deadLetters,
+	}, eventTypes)
+	deadLetters.Register(deadletter.SourceWebhook, dispatcher)
+	return dispatcher
+}
+
+// NewEventPublisher fans lifecycle events out to the publishers that are not
+// nil and, when one is configured, to the event broker, whose buffered
+// events are flushed on shutdown
+func (a *App) NewEventPublisher(deadLetters *deadletter.Queue, publishers ...events.Publisher) events.Publisher {
+	var publisher events.Multi
+	for _, p := range publishers {
+		if p != nil {
+			publisher = append(publisher, p)
+		}
+	}
+
+	broker := a.newEventBroker(deadLetters)
+	if broker == nil {
+		return publisher
+	}
+
+	deadLetters.Register(broker.Name(), broker)
+	a.Hooks.Add(broker.Name(), shutdown.Closer(broker))
+	a.Health.Register(broker.Name(), broker.Ping)
+	return append(publisher, broker)
+}
+
+// NewEmailSender creates the sender of outgoing email
+func (a *App) NewEmailSender() email.Sender {
+	settings := a.Config.Email
+	if settings.Backend != "smtp" {
+		log.Warn("Emails are logged instead of sent; set EMAIL_BACKEND=smtp to send them")
+		return email.Log{}
+	}
+
+	log.WithFields(logger.Fields{
+		"host": settings.SMTP.Host,
+		"port": settings.SMTP.Port,
+	}).Info("Sending email through SMTP")
+	return email.NewSMTP(email.SMTPConfig{
+		Host:     settings.SMTP.Host,
+		Port:     settings.SMTP.Port,
+		Username: settings.SMTP.Username,
+		Password: settings.SMTP.Password,
+		From:     settings.From,
+	})
+}
+
+// encoder encodes the events of the service as CloudEvents identifying it
+func (a *App) encoder() events.Encoder {
+	return events.Encoder{Source: a.Config.Events.Source}
+}
+
+// newEventBroker connects to the broker lifecycle events are published to,
+// Kafka or NATS JetStream as configured. It is nil when the selected broker
+// is not configured.
+func (a *App) newEventBroker(deadLetters *deadletter.Queue) events.Broker {
+	cfg := a.Config
+	switch cfg.Events.Broker {
+	case "nats":
+		if cfg.NATS.URL == "" {
+			return nil
+		}
+
+		log.WithFields(logger.Fields{
+			"url":    cfg.NATS.URL,
+			"stream": cfg.NATS.Stream,
+		}).Info("Publishing lifecycle events to NATS JetStream")
+
+		broker, err := events.NewNATSBroker(context.Background(), events.NATSConfig{
+			URL:             cfg.NATS.URL,
+			Stream:          cfg.NATS.Stream,
+			Subject:         cfg.NATS.Subject,
+			AckWait:         cfg.NATS.AckWait,
+			Encoder:         a.encoder(),
+			DeadLetters:     deadLetters,
+			MaxDeliveries:   cfg.Events.MaxDeliveries,
+			RedeliveryDelay: cfg.Events.RedeliveryDelay,
+		})
+		if err != nil {
+			log.WithError(err).Fatal("Failed to connect to NATS")
+		}
+		return broker
+	default:
+		if len(cfg.Kafka.Brokers) == 0 {
+			return nil
+		}
+
+		log.WithFields(logger.Fields{
+			"brokers": cfg.Kafka.Brokers,
+			"topic":   cfg.Kafka.Topic,
+			"format":  cfg.Kafka.Format,
+		}).Info("Publishing lifecycle events to Kafka")
+
+		return events.NewKafkaBroker(events.KafkaConfig{
+			Brokers:         cfg.Kafka.Brokers,
+			Topic:           cfg.Kafka.Topic,
+			BatchTimeout:    cfg.Kafka.BatchTimeout,
+			Encoder:         a.encoder(),
+			Serializer:      a.newKafkaSerializer(),
+			DeadLetters:     deadLetters,
+			Tombstones:      cfg.Kafka.Tombstones,
+			MaxDeliveries:   cfg.Events.MaxDeliveries,
+			RedeliveryDelay: cfg.Events.RedeliveryDelay,
+		})
+	}
+}
+
+// newKafkaSerializer selects how events are serialized to Kafka. Events in
+// Avro or protobuf have their schemas registered with the schema registry,
+// which is health checked; it is nil for structured JSON CloudEvents.
+func (a *App) newKafkaSerializer() events.Serializer {
+	cfg := a.Config
+	if cfg.Kafka.Format == events.FormatJSON {
+		return nil
+	}
+
+	registry := schemaregistry.NewClient(schemaregistry.Config{
+		URL:      cfg.SchemaRegistry.URL,
+		Username: cfg.SchemaRegistry.Username,
+		Password: cfg.SchemaRegistry.Password,
+		Timeout:  cfg.SchemaRegistry.Timeout,
+	}, nil)
+	a.Health.Register("schema-registry", registry.Ping)
+
+	var codec events.DataCodec = events.NewProtobufCodec(registry)
+	if cfg.Kafka.Format == events.FormatAvro {
+		avroCodec, err := events.NewAvroCodec(registry)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to load the Avro event schemas")
+		}
+		codec = avroCodec
+	}
+	return events.BinarySerializer{Encoder: a.encoder(), Codec: codec}
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"external-apis/internal/shared/apikey"
+	"external-apis/internal/shared/audit"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/quota"
+	"external-apis/internal/shared/rbac"
+	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// Router configures the Gin router with the shared middleware stack and
+// endpoints. register adds the routes of the service; admin is the group of
+// the admin routes, which require the admin role.
+func (a *App) Router(register func(router *gin.Engine, admin *gin.RouterGroup)) *gin.Engine {
+	cfg := a.Config
+
+	// Set Gin mode
+	if cfg.HTTP.GinMode == "release" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	validation.Register()
+
+	router := gin.New()
+
+	// Add middleware
+	router.Use(middleware.Metrics(a.HTTPMetrics))
+	router.Use(middleware.Recovery())
+	router.Use(middleware.Logger())
+	router.Use(middleware.CORS())
+	router.Use(middleware.RequestID(cfg.HTTP.RequestIDFormat))
+	router.Use(middleware.StaleReads())
+	router.Use(response.Envelope(cfg.HTTP.ResponseEnvelope))
+	if a.AuditSink != nil {
+		router.Use(audit.Middleware(a.AuditSink, a.Service.Name))
+	}
+	router.Use(middleware.BodyLimit(int64(cfg.HTTP.MaxBodySize)))
+	router.Use(middleware.CacheControl())
+	if cfg.HTTP.Compression.Enabled {
+		router.Use(middleware.Compress(cfg.HTTP.Compression.MinSize, cfg.HTTP.Compression.Types))
+	}
+	router.Use(middleware.Deadline(cfg.HTTP.MaxRequestDeadline))
+	router.Use(middleware.Timeout(cfg.HTTP.HandlerTimeout))
+	if a.Sandbox != nil {
+		router.Use(a.Sandbox.Middleware())
+	}
+
+	// Metrics endpoint
+	router.GET("/metrics", a.HTTPMetrics.Handler())
+
+	// API documentation, generated with `make swagger`
+	if a.Service.Swagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
+	// Liveness and readiness probes
+	a.Health.RegisterRoutes(router.Group("/health"))
+
+	// Admin routes
+	admin := router.Group("/admin", apikey.Middleware(a.APIKeys, "admin"), a.RequireAuth, rbac.Require(rbac.PermissionAdmin))
+	{
+		apikey.NewHandler(a.APIKeys).RegisterRoutes(admin)
+		rbac.NewHandler(a.Roles).RegisterRoutes(admin)
+		if a.AuditLog != nil {
+			audit.NewAdminHandler(a.AuditLog).RegisterRoutes(admin)
+		}
+		logger.NewHandler().RegisterRoutes(admin)
+		if a.Sandbox != nil {
+			a.Sandbox.RegisterRoutes(admin)
+		}
+		jobs.NewAdminHandler(a.Jobs).RegisterRoutes(admin)
+		if a.Quotas != nil {
+			quota.NewAdminHandler(a.Quotas).RegisterRoutes(admin)
+		}
+	}
+
+	// Service routes
+	if register != nil {
+		register(router, admin)
+	}
+
+	// Root endpoint
+	endpoints := gin.H{
+		"health":    "/health",
+		"liveness":  "/health/live",
+		"readiness": "/health/ready",
+		"metrics":   "/metrics",
+	}
+	if a.Service.Swagger {
+		endpoints["swagger"] = "/swagger/index.html"
+	}
+	for name, path := range a.Service.Endpoints {
+		endpoints[name] = path
+	}
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"message":   a.Service.Title + " API",
+			"version":   a.Service.Version,
+			"endpoints": endpoints,
+		})
+	})
+
+	return router
+}
+
+// APIMiddleware returns the middleware of the API routes named route, which
+// identify the API key of the caller, are rate limited and count against
+// their quota, followed by handlers. The key is validated before rate
+// limiting so only genuine keys get a budget of their own.
+func (a *App) APIMiddleware(route string, handlers ...gin.HandlerFunc) []gin.HandlerFunc {
+	chain := []gin.HandlerFunc{apikey.Middleware(a.APIKeys, route)}
+	if a.Limiter != nil {
+		chain = append(chain, middleware.RateLimit(a.Limiter, a.Config.RateLimit.ByAPIKey))
+	}
+	if a.Quotas != nil {
+		chain = append(chain, quota.Middleware(a.Quotas, route))
+	}
+	return append(chain, handlers...)
+}
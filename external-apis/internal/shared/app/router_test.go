@@ -0,0 +1,210 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/apikey"
+	"external-apis/internal/shared/config"
+	"external-apis/internal/shared/healthcheck"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/metrics"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/ratelimit"
+	"external-apis/internal/shared/rbac"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestApp returns an app of the test service set up with the defaults,
+// without loading the configuration or starting anything
+func newTestApp(t *testing.T, svc Service) *App {
+	t.Helper()
+
+	cfg := config.Defaults()
+	a := &App{
+		Service:     svc,
+		Config:      cfg,
+		Health:      healthcheck.NewRegistry(svc.Name, svc.Version, time.Second),
+		Jobs:        jobs.NewManager(jobs.NewFileStore(filepath.Join(t.TempDir(), "jobs.json")), jobs.DefaultOptions()),
+		HTTPMetrics: metrics.NewHTTPMetrics(svc.Name),
+		APIKeys:     apikey.NewManager(apikey.NewMemoryStore()),
+		Roles:       rbac.NewAuthorizer(rbac.NewMemoryStore(), rbac.Config{DefaultRole: cfg.RBAC.DefaultRole}),
+	}
+	a.RepositoryMetrics = metrics.NewRepositoryMetrics(a.HTTPMetrics.Registry(), svc.Name)
+	a.RequireAuth = middleware.NoAuth(a.Roles.Authorize)
+	return a
+}
+
+func request(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestApp_Router(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Lists the shared and service endpoints on the root endpoint", func(t *testing.T) {
+		// Arrange
+		a := newTestApp(t, Service{
+			Name:      "test-service",
+			Title:     "Test Service",
+			Version:   "1.2.3",
+			Endpoints: map[string]string{"widgets": "/api/v1/widgets"},
+		})
+		router := a.Router(nil)
+
+		// Act
+		w := request(router, http.MethodGet, "/")
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Message   string            `json:"message"`
+			Version   string            `json:"version"`
+			Endpoints map[string]string `json:"endpoints"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "Test Service API", body.Message)
+		assert.Equal(t, "1.2.3", body.Version)
+		assert.Equal(t, map[string]string{
+			"health":    "/health",
+			"liveness":  "/health/live",
+			"readiness": "/health/ready",
+			"metrics":   "/metrics",
+			"widgets":   "/api/v1/widgets",
+		}, body.Endpoints)
+	})
+
+	t.Run("Serves the API documentation only when enabled", func(t *testing.T) {
+		// Arrange
+		without := newTestApp(t, Service{Name: "test-service"}).Router(nil)
+		with := newTestApp(t, Service{Name: "test-service", Swagger: true}).Router(nil)
+
+		// Act
+		w := request(with, http.MethodGet, "/")
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, request(without, http.MethodGet, "/swagger/index.html").Code)
+		assert.Contains(t, w.Body.String(), "/swagger/index.html")
+	})
+
+	t.Run("Serves the probes and metrics", func(t *testing.T) {
+		// Arrange
+		router := newTestApp(t, Service{Name: "test-service"}).Router(nil)
+
+		// Act & Assert
+		assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/health/live").Code)
+		assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/health/ready").Code)
+		assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/metrics").Code)
+	})
+
+	t.Run("Serves the service routes behind the shared middleware", func(t *testing.T) {
+		// Arrange
+		a := newTestApp(t, Service{Name: "test-service"})
+		router := a.Router(func(router *gin.Engine, admin *gin.RouterGroup) {
+			router.GET("/widgets", func(c *gin.Context) {
+				c.String(http.StatusOK, "widgets")
+			})
+		})
+
+		// Act
+		w := request(router, http.MethodGet, "/widgets")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "widgets", w.Body.String())
+		assert.NotEmpty(t, w.Header().Get(logger.RequestIDHeader))
+	})
+
+	t.Run("Requires the admin role on the admin routes of the service", func(t *testing.T) {
+		// Arrange
+		a := newTestApp(t, Service{Name: "test-service"})
+		router := a.Router(func(router *gin.Engine, admin *gin.RouterGroup) {
+			admin.GET("/widgets", func(c *gin.Context) {
+				c.String(http.StatusOK, "widgets")
+			})
+		})
+
+		// Act
+		w := request(router, http.MethodGet, "/admin/widgets")
+
+		// Assert
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestApp_APIMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Identifies the API key of the caller before the handlers", func(t *testing.T) {
+		// Arrange
+		a := newTestApp(t, Service{Name: "test-service"})
+
+		// Act
+		chain := a.APIMiddleware("widgets", a.RequireAuth)
+
+		// Assert
+		assert.Len(t, chain, 2)
+	})
+
+	t.Run("Rate limits the routes when a limiter is set", func(t *testing.T) {
+		// Arrange
+		a := newTestApp(t, Service{Name: "test-service"})
+		a.Limiter = ratelimit.NewMemoryLimiter(ratelimit.Config{Rate: 1, Burst: 1})
+		router := gin.New()
+		router.GET("/widgets", a.APIMiddleware("widgets", func(c *gin.Context) {
+			c.String(http.StatusOK, "widgets")
+		})...)
+
+		// Act
+		first := request(router, http.MethodGet, "/widgets")
+		second := request(router, http.MethodGet, "/widgets")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	})
+
+	t.Run("Made-up API keys get no budget of their own", func(t *testing.T) {
+		// Arrange
+		a := newTestApp(t, Service{Name: "test-service"})
+		a.Config.RateLimit.ByAPIKey = true
+		a.Limiter = ratelimit.NewMemoryLimiter(ratelimit.Config{Rate: 1, Burst: 1})
+		_, token, err := a.APIKeys.Create("partner", []string{"widgets:read"})
+		require.NoError(t, err)
+		router := gin.New()
+		router.GET("/widgets", a.APIMiddleware("widgets", func(c *gin.Context) {
+			c.String(http.StatusOK, "widgets")
+		})...)
+		withKey := func(key string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/widgets", nil)
+			req.Header.Set(middleware.APIKeyHeader, key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		// Act
+		anonymous := request(router, http.MethodGet, "/widgets")
+		forged := withKey("forged-key")
+		limited := request(router, http.MethodGet, "/widgets")
+		genuine := withKey(token)
+		again := withKey(token)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, anonymous.Code)
+		assert.Equal(t, http.StatusUnauthorized, forged.Code)
+		assert.Equal(t, http.StatusTooManyRequests, limited.Code, "a forged key does not reset the IP budget")
+		assert.Equal(t, http.StatusOK, genuine.Code, "a genuine key is limited on its own")
+		assert.Equal(t, http.StatusTooManyRequests, again.Code)
+	})
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"external-apis/internal/shared/config"
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/idgen"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/migrations"
+	"external-apis/internal/shared/shutdown"
+	"external-apis/internal/shared/wal"
+)
+
+// OpenDatabase opens the database file of the sqlite storage backend and
+// applies the schema migrations of the service, unless they are applied by
+// hand, and returns it with its read replicas. Their connection pools are
+// watched by the repository metrics. It returns nil for the memory backend.
+func (a *App) OpenDatabase() *database.Cluster {
+	settings := a.Config.Database
+	if settings.Backend != database.BackendSQLite {
+		return nil
+	}
+
+	db, err := database.OpenSQLite(settings.Path, databaseOptions(settings))
+	if err != nil {
+		log.WithError(err).WithField("path", settings.Path).Fatal("Failed to open the database")
+	}
+	a.Hooks.Add("database", shutdown.Closer(db))
+	a.Health.Register("database", db.PingContext)
+	a.RepositoryMetrics.WatchPool(db, database.BackendSQLite)
+
+	if settings.AutoMigrate {
+		if _, err := migrations.Up(context.Background(), db, a.Service.Migrations); err != nil {
+			log.WithError(err).Fatal("Failed to migrate the database")
+		}
+	} else {
+		pending, err := migrations.Pending(context.Background(), db, a.Service.Migrations)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to check the database migrations")
+		}
+		if len(pending) > 0 {
+			log.WithField("pending", len(pending)).Fatal("The database has pending migrations; apply them with the migrate up subcommand")
+		}
+	}
+	log.WithFields(logger.Fields{
+		"path":     settings.Path,
+		"replicas": len(settings.ReplicaPaths),
+	}).Info("Using SQLite storage")
+	return database.NewCluster(db, a.openReplicas(), settings.ReplicaCooldown)
+}
+
+// openReplicas opens the read replicas of the database of the sqlite
+// storage backend. They need not be up yet: their reads go to the primary
+// while they are down.
+func (a *App) openReplicas() []*sql.DB {
+	settings := a.Config.Database
+	replicas := make([]*sql.DB, 0, len(settings.ReplicaPaths))
+	for i, path := range settings.ReplicaPaths {
+		db, err := database.OpenSQLiteReplica(path, databaseOptions(settings))
+		if err != nil {
+			log.WithError(err).WithField("path", path).Fatal("Failed to open a database replica")
+		}
+		a.Hooks.Add(fmt.Sprintf("database-replica-%d", i+1), shutdown.Closer(db))
+		a.RepositoryMetrics.WatchPool(db, fmt.Sprintf("%s-replica-%d", database.BackendSQLite, i+1))
+		if err := db.Ping(); err != nil {
+			log.WithError(err).WithField("path", path).Warn("Database replica is unavailable; reading from the primary until it is up")
+		}
+		replicas = append(replicas, db)
+	}
+	return replicas
+}
+
+// OpenLog opens the write-ahead log of the memory backend with the name in
+// the persistence directory, flushing it every sync interval, and compacts it
+// into its snapshot every snapshot interval and once more on shutdown
+func (a *App) OpenLog(name string) *wal.Log {
+	settings := a.Config.Persistence
+	path := filepath.Join(settings.Dir, name)
+	l, err := wal.Open(path, settings.SyncInterval)
+	if err != nil {
+		log.WithError(err).WithField("path", path).Fatal("Failed to open the write-ahead log")
+	}
+	a.Hooks.Add(name+"-log", shutdown.Closer(l))
+	a.Jobs.Schedule(name+"-snapshot", jobs.Every(settings.SnapshotInterval), func(context.Context) error {
+		return l.Snapshot()
+	})
+	return l
+}
+
+// NewIDGenerator returns the generator of the IDs of new records in the
+// configured format, starting with prefix when prefixed IDs are configured
+func (a *App) NewIDGenerator(prefix string) idgen.Generator {
+	settings := a.Config.IDs
+	if !settings.Prefixed {
+		prefix = ""
+	}
+	ids, err := idgen.New(settings.Format, prefix)
+	if err != nil {
+		log.WithError(err).Fatal("Failed to set up the ID generator")
+	}
+	log.WithFields(logger.Fields{
+		"format": settings.Format,
+		"prefix": prefix,
+	}).Info("Generating IDs")
+	return ids
+}
+
+// IDValidator returns the generator the handlers check the IDs of requests
+// against. Unless strict IDs are configured, it accepts the IDs of records
+// created before the format was configured as well.
+func (a *App) IDValidator(ids idgen.Generator) idgen.Generator {
+	if a.Config.IDs.Strict {
+		return ids
+	}
+	return idgen.Lenient(ids)
+}
+
+// runMigrations carries out the migrate subcommand on the database of the
+// sqlite storage backend and returns the exit code of the service
+func runMigrations(settings config.Database, schema fs.FS, args []string) int {
+	if settings.Backend != database.BackendSQLite {
+		fmt.Fprintln(os.Stderr, "migrations need STORAGE_BACKEND=sqlite")
+		return 2
+	}
+
+	db, err := database.OpenSQLite(settings.Path, databaseOptions(settings))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer db.Close()
+
+	if err := migrations.Run(context.Background(), db, schema, args, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, migrations.ErrUsage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// databaseOptions returns the connection pool options of the database
+func databaseOptions(settings config.Database) database.Options {
+	return database.Options{
+		MaxOpenConns:    settings.MaxOpenConns,
+		MaxIdleConns:    settings.MaxIdleConns,
+		ConnMaxLifetime: settings.ConnMaxLifetime,
+		ConnMaxIdleTime: settings.ConnMaxIdleTime,
+		BusyTimeout:     settings.BusyTimeout,
+	}
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApp_NewIDGenerator(t *testing.T) {
+	t.Run("Prefixes the IDs only when configured", func(t *testing.T) {
+		// Arrange
+		plain := newTestApp(t, Service{Name: "test-service"})
+		prefixed := newTestApp(t, Service{Name: "test-service"})
+		prefixed.Config.IDs.Prefixed = true
+
+		// Act
+		plainID := plain.NewIDGenerator("cus_").New()
+		prefixedID := prefixed.NewIDGenerator("cus_").New()
+
+		// Assert
+		assert.False(t, strings.HasPrefix(plainID, "cus_"))
+		assert.True(t, strings.HasPrefix(prefixedID, "cus_"))
+	})
+
+	t.Run("Validates the IDs of earlier records unless strict", func(t *testing.T) {
+		// Arrange
+		lenient := newTestApp(t, Service{Name: "test-service"})
+		strict := newTestApp(t, Service{Name: "test-service"})
+		strict.Config.IDs.Strict = true
+
+		// Act
+		lenientValid := lenient.IDValidator(lenient.NewIDGenerator("")).Valid("customer-456")
+		strictValid := strict.IDValidator(strict.NewIDGenerator("")).Valid("customer-456")
+
+		// Assert
+		assert.True(t, lenientValid)
+		assert.False(t, strictValid)
+	})
+}
@@ -0,0 +1,96 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Kinds of application errors. Every Error wraps one of them, so callers can
+// classify an error with errors.Is without knowing its message.
+var (
+	// ErrNotFound means the requested entity does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means the request conflicts with the current state of an entity
+	ErrConflict = errors.New("conflict")
+	// ErrValidation means the request itself is invalid
+	ErrValidation = errors.New("validation failed")
+	// ErrUnprocessable means the request is well-formed but refers to entities it cannot be applied to
+	ErrUnprocessable = errors.New("unprocessable")
+	// ErrUnavailable means a dependency needed to serve the request is unavailable
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// kinds maps each kind to its HTTP status and machine-readable code
+var kinds = map[error]struct {
+	status int
+	code   string
+}{
+	ErrNotFound:      {http.StatusNotFound, "not_found"},
+	ErrConflict:      {http.StatusConflict, "conflict"},
+	ErrValidation:    {http.StatusBadRequest, "bad_request"},
+	ErrUnprocessable: {http.StatusUnprocessableEntity, "unprocessable_entity"},
+	ErrUnavailable:   {http.StatusBadGateway, "bad_gateway"},
+}
+
+// Error is an application error with a message that is safe to return to
+// clients. Domain packages declare their errors as package-level values so
+// they can be matched exactly with errors.Is as well as by kind.
+type Error struct {
+	kind    error
+	message string
+}
+
+// NotFound creates an error of kind ErrNotFound
+func NotFound(message string) *Error {
+	return &Error{kind: ErrNotFound, message: message}
+}
+
+// Conflict creates an error of kind ErrConflict
+func Conflict(message string) *Error {
+	return &Error{kind: ErrConflict, message: message}
+}
+
+// Validation creates an error of kind ErrValidation
+func Validation(message string) *Error {
+	return &Error{kind: ErrValidation, message: message}
+}
+
+// Unprocessable creates an error of kind ErrUnprocessable
+func Unprocessable(message string) *Error {
+	return &Error{kind: ErrUnprocessable, message: message}
+}
+
+// Unavailable creates an error of kind ErrUnavailable
+func Unavailable(message string) *Error {
+	return &Error{kind: ErrUnavailable, message: message}
+}
+
+// Error returns the client-facing message
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Unwrap returns the kind of the error
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// Status returns the HTTP status code of the error
+func (e *Error) Status() int {
+	return kinds[e.kind].status
+}
+
+// Code returns the machine-readable error code of the error
+func (e *Error) Code() string {
+	return kinds[e.kind].code
+}
+
+// Status returns the HTTP status code for err: the status of the first Error
+// in its chain, or 500 if there is none
+func Status(err error) int {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Status()
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,67 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *Error
+		kind   error
+		status int
+		code   string
+	}{
+		{"Not found", NotFound("customer not found"), ErrNotFound, http.StatusNotFound, "not_found"},
+		{"Conflict", Conflict("customer already exists"), ErrConflict, http.StatusConflict, "conflict"},
+		{"Validation", Validation("invalid email format"), ErrValidation, http.StatusBadRequest, "bad_request"},
+		{"Unprocessable", Unprocessable("customer is not active"), ErrUnprocessable, http.StatusUnprocessableEntity, "unprocessable_entity"},
+		{"Unavailable", Unavailable("customer service unavailable"), ErrUnavailable, http.StatusBadGateway, "bad_gateway"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Assert
+			assert.ErrorIs(t, tt.err, tt.kind)
+			assert.Equal(t, tt.status, tt.err.Status())
+			assert.Equal(t, tt.code, tt.err.Code())
+		})
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	// Arrange
+	errCustomerNotFound := NotFound("customer not found")
+	errAddressNotFound := NotFound("address not found")
+	wrapped := fmt.Errorf("loading customer: %w", errCustomerNotFound)
+
+	// Assert
+	assert.EqualError(t, wrapped, "loading customer: customer not found")
+	assert.ErrorIs(t, wrapped, errCustomerNotFound)
+	assert.ErrorIs(t, wrapped, ErrNotFound)
+	assert.NotErrorIs(t, wrapped, errAddressNotFound)
+	assert.NotErrorIs(t, wrapped, ErrConflict)
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("Application error", func(t *testing.T) {
+		// Act
+		status := Status(fmt.Errorf("creating customer: %w", Conflict("customer already exists")))
+
+		// Assert
+		assert.Equal(t, http.StatusConflict, status)
+	})
+
+	t.Run("Other error", func(t *testing.T) {
+		// Act
+		status := Status(errors.New("boom"))
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, status)
+	})
+}
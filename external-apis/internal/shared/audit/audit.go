@@ -0,0 +1,170 @@
+// Package audit records who changed what through the API, and when, so
+// mutating calls can be traced back to their caller.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"net/http"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/timerange"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var log = logger.New("shared/audit")
+
+// tenantKey is the Gin context key holding the tenant tenant.Middleware
+// resolved; calls outside tenant routes have none
+const tenantKey = "tenant_id"
+
+// drainLimit is how much of a body its handler left unread, e.g. because
+// it exceeded the body limit, is still read to complete its hash
+const drainLimit = 1 << 20
+
+// Entry records a mutating API call
+type Entry struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Service string    `json:"service"`
+	// Actor is who made the call: "user:<subject>", "api-key:<id>" or
+	// "anonymous"
+	Actor  string `json:"actor"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Route is the route template the call matched, e.g. /api/v1/products/:id
+	Route  string `json:"route,omitempty"`
+	Status int    `json:"status"`
+	// BodySHA256 is the hex SHA-256 of the request body, also of bodies the
+	// handler refused unread; it is empty for bodies too large to read
+	BodySHA256 string `json:"bodySha256,omitempty"`
+	BodySize   int64  `json:"bodySize"`
+	RequestID  string `json:"requestId,omitempty"`
+	Tenant     string `json:"tenant,omitempty"`
+	ClientIP   string `json:"clientIp"`
+}
+
+// Filter selects audit entries; empty fields match everything
+type Filter struct {
+	Actor  string
+	Method string
+	Route  string
+	Time   timerange.Range
+}
+
+// Matches checks if the entry passes the filter
+func (f Filter) Matches(entry *Entry) bool {
+	return (f.Actor == "" || entry.Actor == f.Actor) &&
+		(f.Method == "" || entry.Method == f.Method) &&
+		(f.Route == "" || entry.Route == f.Route) &&
+		f.Time.Contains(entry.Time)
+}
+
+// Sink persists audit entries
+type Sink interface {
+	Write(ctx context.Context, entry *Entry) error
+}
+
+// Querier finds recorded audit entries
+type Querier interface {
+	// Query returns the entries matching filter, newest first
+	Query(filter Filter) ([]*Entry, error)
+}
+
+// Mutating reports whether requests with method change state and so are
+// audited
+func Mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Middleware records every mutating call of service to sink once it was
+// answered, including those refused by authentication. It must precede
+// BodyLimit so the hash covers bodies whose route raised the limit.
+func Middleware(sink Sink, service string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Mutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		body := &hashingBody{hash: sha256.New()}
+		if c.Request.Body != nil {
+			body.ReadCloser = c.Request.Body
+			c.Request.Body = body
+		}
+
+		c.Next()
+
+		// The request is the one the handlers saw last, carrying the actor
+		// they resolved
+		ctx := c.Request.Context()
+		entry := &Entry{
+			ID:        uuid.NewString(),
+			Time:      time.Now().UTC(),
+			Service:   service,
+			Actor:     auth.Actor(ctx),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Route:     c.FullPath(),
+			Status:    c.Writer.Status(),
+			RequestID: logger.RequestID(ctx),
+			Tenant:    c.GetString(tenantKey),
+			ClientIP:  c.ClientIP(),
+		}
+		entry.BodySHA256, entry.BodySize = body.sum()
+
+		if err := sink.Write(context.WithoutCancel(ctx), entry); err != nil {
+			log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+				"actor":  entry.Actor,
+				"method": entry.Method,
+				"path":   entry.Path,
+			}).Error("Failed to record audit entry")
+		}
+	}
+}
+
+// hashingBody hashes a request body as its handler reads it
+type hashingBody struct {
+	io.ReadCloser
+	hash hash.Hash
+	size int64
+	eof  bool
+}
+
+// Read reads from the body, hashing what was read
+func (b *hashingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	b.size += int64(n)
+	if err == io.EOF {
+		b.eof = true
+	}
+	return n, err
+}
+
+// sum reads what the handler left of the body, up to drainLimit, and
+// returns its hash and size. The hash is empty if the body could not be
+// read to the end.
+func (b *hashingBody) sum() (string, int64) {
+	if b.ReadCloser == nil || b.ReadCloser == http.NoBody {
+		return hex.EncodeToString(b.hash.Sum(nil)), 0
+	}
+	if !b.eof {
+		_, _ = io.Copy(io.Discard, io.LimitReader(b, drainLimit))
+	}
+	if !b.eof {
+		return "", b.size
+	}
+	return hex.EncodeToString(b.hash.Sum(nil)), b.size
+}
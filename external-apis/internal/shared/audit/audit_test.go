@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/tenant"
+	"external-apis/internal/shared/timerange"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// digest returns the hex SHA-256 of body
+func digest(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// newAuditRouter returns a router recording calls to sink. Routes under
+// /api resolve the tenant and authenticate callers as alice.
+func newAuditRouter(sink Sink) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware(sink, "product-service"), middleware.BodyLimit(16))
+
+	authenticate := func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), "user:alice"))
+	}
+	api := router.Group("/api", tenant.Middleware(tenant.Config{}), authenticate)
+	api.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/products", func(c *gin.Context) {
+		var body map[string]any
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	api.POST("/imports", middleware.RaiseBodyLimit(1024), func(c *gin.Context) {
+		_, _ = io.ReadAll(c.Request.Body)
+		c.Status(http.StatusAccepted)
+	})
+	api.DELETE("/products/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.POST("/unauthenticated", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	largeBody := `{"name":"` + strings.Repeat("x", 64) + `"}`
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		recorded bool
+		want     Entry
+	}{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			path:   "/api/products",
+			body:   `{"name":"Chair"}`,
+			want: Entry{
+				Actor: "user:alice", Method: http.MethodPost, Path: "/api/products", Route: "/api/products",
+				Status: http.StatusCreated, BodySHA256: digest(`{"name":"Chair"}`), BodySize: 16, Tenant: tenant.Default,
+			},
+			recorded: true,
+		},
+		{
+			name:   "Delete without a body",
+			method: http.MethodDelete,
+			path:   "/api/products/p-1",
+			want: Entry{
+				Actor: "user:alice", Method: http.MethodDelete, Path: "/api/products/p-1", Route: "/api/products/:id",
+				Status: http.StatusNoContent, BodySHA256: digest(""), Tenant: tenant.Default,
+			},
+			recorded: true,
+		},
+		{
+			name:   "Body over the limit",
+			method: http.MethodPost,
+			path:   "/api/products",
+			body:   largeBody,
+			want: Entry{
+				Actor: "user:alice", Method: http.MethodPost, Path: "/api/products", Route: "/api/products",
+				Status: http.StatusRequestEntityTooLarge, BodySHA256: digest(largeBody), BodySize: int64(len(largeBody)), Tenant: tenant.Default,
+			},
+			recorded: true,
+		},
+		{
+			name:   "Route raising the body limit",
+			method: http.MethodPost,
+			path:   "/api/imports",
+			body:   largeBody,
+			want: Entry{
+				Actor: "user:alice", Method: http.MethodPost, Path: "/api/imports", Route: "/api/imports",
+				Status: http.StatusAccepted, BodySHA256: digest(largeBody), BodySize: int64(len(largeBody)), Tenant: tenant.Default,
+			},
+			recorded: true,
+		},
+		{
+			name:   "Refused call",
+			method: http.MethodPost,
+			path:   "/unauthenticated",
+			body:   `{}`,
+			want: Entry{
+				Actor: auth.AnonymousActor, Method: http.MethodPost, Path: "/unauthenticated", Route: "/unauthenticated",
+				Status: http.StatusUnauthorized, BodySHA256: digest(`{}`), BodySize: 2,
+			},
+			recorded: true,
+		},
+		{
+			name:   "Read",
+			method: http.MethodGet,
+			path:   "/api/products",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			sink := NewMemorySink(10)
+			router := newAuditRouter(sink)
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			entries, err := sink.Query(Filter{})
+			require.NoError(t, err)
+			if !tt.recorded {
+				assert.Empty(t, entries)
+				return
+			}
+			require.Len(t, entries, 1)
+			entry := entries[0]
+			assert.NotEmpty(t, entry.ID)
+			assert.WithinDuration(t, time.Now(), entry.Time, time.Minute)
+			assert.Equal(t, "product-service", entry.Service)
+			assert.NotEmpty(t, entry.ClientIP)
+			entry.ID, entry.Time, entry.Service, entry.ClientIP = "", time.Time{}, "", ""
+			assert.Equal(t, tt.want, *entry)
+		})
+	}
+}
+
+func TestMemorySink(t *testing.T) {
+	// Arrange
+	sink := NewMemorySink(3)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		method := http.MethodPost
+		if i%2 == 1 {
+			method = http.MethodDelete
+		}
+		entry := &Entry{ID: string(rune('a' + i)), Method: method, Time: start.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, sink.Write(t.Context(), entry))
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"Latest entries, newest first", Filter{}, []string{"e", "d", "c"}},
+		{"By method", Filter{Method: http.MethodPost}, []string{"e", "c"}},
+		{"By time", Filter{Time: timerange.Range{Before: start.Add(4 * time.Minute)}}, []string{"d", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			entries, err := sink.Query(tt.filter)
+
+			// Assert
+			require.NoError(t, err)
+			ids := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	// Arrange
+	path := t.TempDir() + "/audit.jsonl"
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(t.Context(), &Entry{ID: "first", Actor: "user:alice"}))
+	require.NoError(t, sink.Write(t.Context(), &Entry{ID: "second", Actor: "api-key:key-1"}))
+	require.NoError(t, sink.Close())
+
+	// Act
+	reopened, err := NewFileSink(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	require.NoError(t, reopened.Write(t.Context(), &Entry{ID: "third", Actor: "user:alice"}))
+	entries, err := reopened.Query(Filter{Actor: "user:alice"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "third", entries[0].ID)
+	assert.Equal(t, "first", entries[1].ID)
+}
+
+func TestAdminHandler(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	sink := NewMemorySink(10)
+	require.NoError(t, sink.Write(t.Context(), &Entry{ID: "a", Method: http.MethodPost, Time: time.Now()}))
+	require.NoError(t, sink.Write(t.Context(), &Entry{ID: "b", Method: http.MethodDelete, Time: time.Now()}))
+	router := gin.New()
+	NewAdminHandler(sink).RegisterRoutes(router.Group("/admin"))
+
+	t.Run("Filter by method", func(t *testing.T) {
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit?method=delete", nil))
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []Entry `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "b", resp.Data[0].ID)
+	})
+
+	t.Run("Reject malformed times", func(t *testing.T) {
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit?time_since=yesterday", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"strings"
+
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/response"
+	"external-apis/internal/shared/timerange"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the admin endpoint for querying the audit log
+type AdminHandler struct {
+	querier Querier
+}
+
+// NewAdminHandler creates a new audit admin handler
+func NewAdminHandler(querier Querier) *AdminHandler {
+	return &AdminHandler{querier: querier}
+}
+
+// RegisterRoutes registers the audit admin routes
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/audit", h.ListEntries)
+}
+
+// ListEntries returns a page of audit entries, newest first, optionally
+// filtered by actor, method, route and time
+func (h *AdminHandler) ListEntries(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	times, err := timerange.FromQuery(c, "time")
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	entries, err := h.querier.Query(Filter{
+		Actor:  c.Query("actor"),
+		Method: strings.ToUpper(c.Query("method")),
+		Route:  c.Query("route"),
+		Time:   times,
+	})
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to query audit log")
+		response.InternalServerError(c, "Failed to query audit log")
+		return
+	}
+
+	start, end := page.Bounds(len(entries))
+	response.Paged(c, entries[start:end], page.Meta(len(entries)))
+}
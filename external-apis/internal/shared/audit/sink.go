@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"external-apis/internal/shared/events"
+	"github.com/segmentio/kafka-go"
+)
+
+// maxLineSize bounds the entries FileSink reads back
+const maxLineSize = 64 << 10
+
+// MemorySink keeps the latest entries in memory, dropping the oldest once
+// it holds capacity entries
+type MemorySink struct {
+	entries []*Entry
+	// next is where the next entry goes once the buffer is full
+	next  int
+	mutex sync.RWMutex
+}
+
+// NewMemorySink creates a sink keeping up to capacity entries
+func NewMemorySink(capacity int) *MemorySink {
+	return &MemorySink{entries: make([]*Entry, 0, capacity)}
+}
+
+// Write keeps the entry, replacing the oldest when the sink is full
+func (s *MemorySink) Write(_ context.Context, entry *Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	copied := *entry
+	if len(s.entries) < cap(s.entries) {
+		s.entries = append(s.entries, &copied)
+		return nil
+	}
+	if len(s.entries) == 0 {
+		return nil
+	}
+	s.entries[s.next] = &copied
+	s.next = (s.next + 1) % len(s.entries)
+	return nil
+}
+
+// Query returns the kept entries matching filter, newest first
+func (s *MemorySink) Query(filter Filter) ([]*Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var entries []*Entry
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		entry := s.entries[(s.next+i)%len(s.entries)]
+		if filter.Matches(entry) {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	return entries, nil
+}
+
+// FileSink appends entries to a file as JSON lines, keeping every entry
+// across restarts. Queries read the whole file.
+type FileSink struct {
+	path  string
+	file  *os.File
+	mutex sync.Mutex
+}
+
+// NewFileSink creates a sink appending to the file at path
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+// Write appends the entry to the file
+func (s *FileSink) Write(_ context.Context, entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Query returns the entries in the file matching filter, newest first
+func (s *FileSink) Query(filter Filter) ([]*Entry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line cut short by a crash is skipped
+			continue
+		}
+		if filter.Matches(&entry) {
+			entries = append(entries, &entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
+
+// KafkaSink publishes entries as JSON messages keyed by their actor, for a
+// log collected outside the services
+type KafkaSink struct {
+	writer events.MessageWriter
+}
+
+// NewKafkaSink creates a sink writing to writer
+func NewKafkaSink(writer events.MessageWriter) *KafkaSink {
+	return &KafkaSink{writer: writer}
+}
+
+// Write publishes the entry
+func (s *KafkaSink) Write(ctx context.Context, entry *Entry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.writer.WriteMessages(ctx, kafka.Message{Key: []byte(entry.Actor), Value: value})
+}
+
+// Close flushes and closes the writer
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
+
+// Multi writes entries to every sink
+type Multi []Sink
+
+// Write writes the entry to every sink, even if some fail
+func (m Multi) Write(ctx context.Context, entry *Entry) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Write(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
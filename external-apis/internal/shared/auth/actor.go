@@ -0,0 +1,23 @@
+package auth
+
+import "context"
+
+// AnonymousActor identifies callers that did not authenticate
+const AnonymousActor = "anonymous"
+
+// actorKey is the context key holding the authenticated actor
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor performing a request,
+// such as "user:<subject>" or "api-key:<id>"
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored by WithActor, or AnonymousActor
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
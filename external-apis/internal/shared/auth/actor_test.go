@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActor(t *testing.T) {
+	t.Run("Actor from context", func(t *testing.T) {
+		// Act
+		actor := Actor(WithActor(context.Background(), "user:user-1"))
+
+		// Assert
+		assert.Equal(t, "user:user-1", actor)
+	})
+
+	t.Run("Anonymous without actor", func(t *testing.T) {
+		// Act
+		actor := Actor(context.Background())
+
+		// Assert
+		assert.Equal(t, AnonymousActor, actor)
+	})
+}
@@ -0,0 +1,289 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/auth")
+
+// minRefetchInterval limits how often tokens signed with unknown keys make
+// the key set fetch the keys again, so forged key IDs cannot flood the
+// provider
+const minRefetchInterval = 30 * time.Second
+
+var (
+	// ErrUnknownKey is returned for tokens signed with a key the provider
+	// does not publish
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrNoKeySet is returned when a key set is created without an issuer
+	// or JWKS URL
+	ErrNoKeySet = errors.New("an OIDC issuer or JWKS URL is required")
+)
+
+// KeySetConfig configures fetching the signing keys of an OIDC provider
+type KeySetConfig struct {
+	// Issuer is the URL of the provider; its discovery document at
+	// /.well-known/openid-configuration names the JWKS URL
+	Issuer string
+	// JWKSURL skips discovery when set
+	JWKSURL string
+	// RefreshInterval is how long fetched keys are used before they are
+	// fetched again
+	RefreshInterval time.Duration
+	// Timeout bounds each request to the provider
+	Timeout time.Duration
+}
+
+// keys are the signing keys fetched at one time
+type keys struct {
+	byID      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// KeySet caches the signing keys an OIDC provider publishes as a JWKS.
+// Keys are fetched again once RefreshInterval elapsed, and early when a
+// token names a key the cache lacks, as when the provider rotates its keys.
+// A failed fetch keeps the cached keys in use.
+type KeySet struct {
+	config  KeySetConfig
+	client  *http.Client
+	current atomic.Pointer[keys]
+	// mutex serializes fetches and guards jwksURL and lastFetch
+	mutex     sync.Mutex
+	jwksURL   string
+	lastFetch time.Time
+	now       func() time.Time
+}
+
+// NewKeySet creates a key set for the provider in config. Keys are fetched
+// on first use or by Refresh.
+func NewKeySet(config KeySetConfig) (*KeySet, error) {
+	if config.Issuer == "" && config.JWKSURL == "" {
+		return nil, ErrNoKeySet
+	}
+
+	return &KeySet{
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		jwksURL: config.JWKSURL,
+		now:     time.Now,
+	}, nil
+}
+
+// Refresh fetches the keys of the provider, discovering the JWKS URL first
+// if needed
+func (s *KeySet) Refresh() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.fetch()
+}
+
+// Key returns the key with the given ID, fetching the keys when the cache
+// is stale or lacks it. Tokens without a key ID are accepted while the
+// provider publishes a single key.
+func (s *KeySet) Key(kid string) (interface{}, error) {
+	current := s.current.Load()
+	if current == nil || s.now().Sub(current.fetchedAt) >= s.config.RefreshInterval {
+		current = s.refetch(current)
+	}
+	if key, ok := current.lookup(kid); ok {
+		return key, nil
+	}
+
+	current = s.refetch(current)
+	if key, ok := current.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+// refetch fetches the keys unless another caller replaced seen meanwhile or
+// they were fetched less than minRefetchInterval ago. It returns the keys
+// to use, which are seen if fetching failed.
+func (s *KeySet) refetch(seen *keys) *keys {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if current := s.current.Load(); current != seen {
+		return current
+	}
+	if !s.lastFetch.IsZero() && s.now().Sub(s.lastFetch) < minRefetchInterval {
+		return seen
+	}
+
+	if err := s.fetch(); err != nil {
+		log.WithError(err).Warn("Failed to fetch JWKS, keeping cached keys")
+		return seen
+	}
+	return s.current.Load()
+}
+
+// fetch fetches and caches the keys; the caller holds the mutex
+func (s *KeySet) fetch() error {
+	s.lastFetch = s.now()
+
+	if s.jwksURL == "" {
+		jwksURL, err := s.discover()
+		if err != nil {
+			return err
+		}
+		s.jwksURL = jwksURL
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := s.get(s.jwksURL, &document); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	byID := make(map[string]interface{}, len(document.Keys))
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			log.WithError(err).WithField("kid", key.Kid).Warn("Skipping JWKS key")
+			continue
+		}
+		byID[key.Kid] = publicKey
+	}
+	if len(byID) == 0 {
+		return errors.New("JWKS holds no usable signing key")
+	}
+
+	s.current.Store(&keys{byID: byID, fetchedAt: s.lastFetch})
+	return nil
+}
+
+// discover reads the JWKS URL from the discovery document of the issuer
+func (s *KeySet) discover() (string, error) {
+	var document struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	url := strings.TrimSuffix(s.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := s.get(url, &document); err != nil {
+		return "", fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	if document.Issuer != s.config.Issuer {
+		return "", fmt.Errorf("discovery document is for issuer %q, not %q", document.Issuer, s.config.Issuer)
+	}
+	if document.JWKSURI == "" {
+		return "", errors.New("discovery document has no jwks_uri")
+	}
+	return document.JWKSURI, nil
+}
+
+// get decodes the JSON document at url into v
+func (s *KeySet) get(url string, v interface{}) error {
+	resp, err := s.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// lookup returns the key with the given ID, or the only key for tokens
+// without one
+func (k *keys) lookup(kid string) (interface{}, bool) {
+	if k == nil {
+		return nil, false
+	}
+	if kid == "" && len(k.byID) == 1 {
+		for _, key := range k.byID {
+			return key, true
+		}
+	}
+	key, ok := k.byID[kid]
+	return key, ok
+}
+
+// jwk is a JSON Web Key (RFC 7517) holding an RSA or EC public key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// curves maps the JWK curve names to their curves
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// publicKey decodes the public key of k
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() {
+			return nil, errors.New("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeInt decodes a base64url-encoded big-endian integer
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
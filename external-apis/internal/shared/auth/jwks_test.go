@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// provider is a test OIDC provider serving discovery and a JWKS
+type provider struct {
+	server *httptest.Server
+	// fetches counts the requests for the JWKS
+	fetches atomic.Int32
+	// fail makes the JWKS endpoint fail
+	fail  atomic.Bool
+	mutex sync.Mutex
+	keys  []jwk
+}
+
+// newProvider starts a provider publishing no key
+func newProvider(t *testing.T) *provider {
+	t.Helper()
+	p := &provider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.server.URL,
+			"jwks_uri": p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.fetches.Add(1)
+		if p.fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string][]jwk{"keys": p.keys})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// publish replaces the published keys
+func (p *provider) publish(keys ...jwk) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.keys = keys
+}
+
+// encode base64url-encodes an integer
+func encode(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) jwk {
+	return jwk{Kid: kid, Kty: "RSA", Use: "sig", N: encode(key.N), E: encode(big.NewInt(int64(key.E)))}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) jwk {
+	return jwk{Kid: kid, Kty: "EC", Crv: "P-256", X: encode(key.X), Y: encode(key.Y)}
+}
+
+// signWithKID signs claims with a key ID header
+func signWithKID(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestNewKeySet(t *testing.T) {
+	// Act
+	keySet, err := NewKeySet(KeySetConfig{})
+
+	// Assert
+	assert.Nil(t, keySet)
+	assert.ErrorIs(t, err, ErrNoKeySet)
+}
+
+func TestValidator_ValidateOIDC(t *testing.T) {
+	// Arrange
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	idp := newProvider(t)
+	idp.publish(rsaJWK("rsa-1", rsaKey), ecJWK("ec-1", ecKey), jwk{Kid: "enc-1", Kty: "RSA", Use: "enc"})
+
+	keySet, err := NewKeySet(KeySetConfig{Issuer: idp.server.URL, RefreshInterval: time.Hour, Timeout: time.Second})
+	require.NoError(t, err)
+	validator, err := NewValidator(Config{KeySet: keySet, Issuer: idp.server.URL, Leeway: time.Minute})
+	require.NoError(t, err)
+
+	claims := validClaims()
+	claims.Issuer = idp.server.URL
+
+	t.Run("RSA token", func(t *testing.T) {
+		// Act
+		got, err := validator.Validate(signWithKID(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", got.Subject)
+	})
+
+	t.Run("ECDSA token", func(t *testing.T) {
+		// Act
+		_, err := validator.Validate(signWithKID(t, jwt.SigningMethodES256, "ec-1", ecKey, claims))
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Key of another type", func(t *testing.T) {
+		// Act
+		_, err := validator.Validate(signWithKID(t, jwt.SigningMethodES256, "rsa-1", ecKey, claims))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Clock skew within the leeway", func(t *testing.T) {
+		// Arrange
+		skewed := claims
+		skewed.IssuedAt = jwt.NewNumericDate(time.Now().Add(30 * time.Second))
+
+		// Act
+		_, err := validator.Validate(signWithKID(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, skewed))
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Issued in the future beyond the leeway", func(t *testing.T) {
+		// Arrange
+		skewed := claims
+		skewed.IssuedAt = jwt.NewNumericDate(time.Now().Add(5 * time.Minute))
+
+		// Act
+		_, err := validator.Validate(signWithKID(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, skewed))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Keys are cached", func(t *testing.T) {
+		// Assert
+		assert.Equal(t, int32(1), idp.fetches.Load())
+	})
+}
+
+func TestKeySet_Key(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// setup returns a key set of a provider publishing the old key, with a
+	// clock the test advances
+	setup := func(t *testing.T) (*provider, *KeySet, *time.Time) {
+		idp := newProvider(t)
+		idp.publish(rsaJWK("old", oldKey))
+		keySet, err := NewKeySet(KeySetConfig{JWKSURL: idp.server.URL + "/keys", RefreshInterval: time.Hour, Timeout: time.Second})
+		require.NoError(t, err)
+		now := time.Now()
+		keySet.now = func() time.Time { return now }
+		require.NoError(t, keySet.Refresh())
+		return idp, keySet, &now
+	}
+
+	t.Run("Fetch rotated keys", func(t *testing.T) {
+		// Arrange
+		idp, keySet, now := setup(t)
+		idp.publish(rsaJWK("old", oldKey), rsaJWK("new", newKey))
+		*now = now.Add(minRefetchInterval)
+
+		// Act
+		key, err := keySet.Key("new")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &newKey.PublicKey, key)
+	})
+
+	t.Run("Limit fetches for unknown keys", func(t *testing.T) {
+		// Arrange
+		idp, keySet, _ := setup(t)
+
+		// Act
+		_, first := keySet.Key("forged-1")
+		_, second := keySet.Key("forged-2")
+
+		// Assert
+		assert.ErrorIs(t, first, ErrUnknownKey)
+		assert.ErrorIs(t, second, ErrUnknownKey)
+		assert.Equal(t, int32(1), idp.fetches.Load())
+	})
+
+	t.Run("Keep cached keys while the provider fails", func(t *testing.T) {
+		// Arrange
+		idp, keySet, now := setup(t)
+		idp.fail.Store(true)
+		*now = now.Add(2 * time.Hour)
+
+		// Act
+		key, err := keySet.Key("old")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &oldKey.PublicKey, key)
+		assert.Equal(t, int32(2), idp.fetches.Load())
+	})
+
+	t.Run("Accept tokens without a key ID from a single key", func(t *testing.T) {
+		// Arrange
+		_, keySet, _ := setup(t)
+
+		// Act
+		key, err := keySet.Key("")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &oldKey.PublicKey, key)
+	})
+}
+
+func TestKeySet_Discovery(t *testing.T) {
+	// Arrange
+	idp := newProvider(t)
+	keySet, err := NewKeySet(KeySetConfig{Issuer: idp.server.URL + "/realms/other", Timeout: time.Second})
+	require.NoError(t, err)
+
+	// Act
+	err = keySet.Refresh()
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to discover OIDC provider")
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrNoKeys is returned when a validator is created without any verification key
+	ErrNoKeys = errors.New("no JWT verification key configured")
+	// ErrInvalidToken is returned when a token fails parsing or validation
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Claims are the JWT claims accepted by the services
+type Claims struct {
+	Scope string `json:"scope,omitempty"`
+	jwt.RegisteredClaims
+	// raw holds every claim of the token, including those of the provider
+	raw map[string]interface{}
+}
+
+// UnmarshalJSON decodes the claims, keeping every claim for Values
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	type claims Claims
+	if err := json.Unmarshal(data, (*claims)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.raw)
+}
+
+// Values returns the values of the claim at path, splitting strings on
+// spaces as in the scope claim. Claims nested in objects are reached with
+// dots, e.g. "realm_access.roles" for Keycloak roles; claim names holding
+// dots, such as the namespaced claims of Auth0, are matched whole first.
+func (c *Claims) Values(path string) []string {
+	value, ok := c.raw[path]
+	if !ok {
+		var current interface{} = c.raw
+		for _, name := range strings.Split(path, ".") {
+			object, isObject := current.(map[string]interface{})
+			if !isObject {
+				return nil
+			}
+			current = object[name]
+		}
+		value = current
+	}
+
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if item, ok := item.(string); ok {
+				values = append(values, item)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// Config configures token validation. At least one of HMACSecret,
+// RSAPublicKey or KeySet must be set; tokens are verified with the key
+// matching their signing algorithm.
+type Config struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	// KeySet verifies RSA and ECDSA tokens of an OIDC provider by their key
+	// ID; RSA tokens without one fall back to RSAPublicKey when it is set
+	KeySet   *KeySet
+	Issuer   string
+	Audience string
+	// Leeway tolerates clock skew when checking exp, nbf and iat
+	Leeway time.Duration
+}
+
+// Validator verifies bearer tokens
+type Validator struct {
+	config Config
+	parser *jwt.Parser
+}
+
+// NewValidator creates a new token validator
+func NewValidator(config Config) (*Validator, error) {
+	var methods []string
+	if len(config.HMACSecret) > 0 {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if config.RSAPublicKey != nil || config.KeySet != nil {
+		methods = append(methods, "RS256", "RS384", "RS512")
+	}
+	if config.KeySet != nil {
+		methods = append(methods, "ES256", "ES384", "ES512")
+	}
+	if len(methods) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &Validator{
+		config: config,
+		parser: jwt.NewParser(options...),
+	}, nil
+}
+
+// Validate parses and verifies a token, returning its claims
+func (v *Validator) Validate(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	_, err := v.parser.ParseWithClaims(tokenString, claims, v.key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+// key selects the verification key for the token's signing method
+func (v *Validator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.config.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if v.config.KeySet == nil || (kid == "" && v.config.RSAPublicKey != nil) {
+			return v.config.RSAPublicKey, nil
+		}
+		return v.config.KeySet.Key(kid)
+	case *jwt.SigningMethodECDSA:
+		kid, _ := token.Header["kid"].(string)
+		return v.config.KeySet.Key(kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
+
+// ParseRSAPublicKey parses a PEM-encoded RSA public key
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	return jwt.ParseRSAPublicKeyFromPEM(data)
+}
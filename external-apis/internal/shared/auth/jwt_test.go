@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, claims Claims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() Claims {
+	return Claims{
+		Scope: "catalog:write",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-1",
+			Issuer:    "auth-service",
+			Audience:  jwt.ClaimStrings{"external-apis"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func TestNewValidator(t *testing.T) {
+	// Act
+	validator, err := NewValidator(Config{})
+
+	// Assert
+	assert.Nil(t, validator)
+	assert.ErrorIs(t, err, ErrNoKeys)
+}
+
+func TestValidator_ValidateHMAC(t *testing.T) {
+	// Arrange
+	secret := []byte("test-secret")
+	validator, err := NewValidator(Config{
+		HMACSecret: secret,
+		Issuer:     "auth-service",
+		Audience:   "external-apis",
+	})
+	require.NoError(t, err)
+
+	t.Run("Valid token", func(t *testing.T) {
+		// Act
+		claims, err := validator.Validate(sign(t, jwt.SigningMethodHS256, secret, validClaims()))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+		assert.Equal(t, "catalog:write", claims.Scope)
+	})
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{
+			name: "Wrong secret",
+			token: func() string {
+				return sign(t, jwt.SigningMethodHS256, []byte("other"), validClaims())
+			},
+		},
+		{
+			name: "Expired token",
+			token: func() string {
+				claims := validClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return sign(t, jwt.SigningMethodHS256, secret, claims)
+			},
+		},
+		{
+			name: "Missing expiry",
+			token: func() string {
+				claims := validClaims()
+				claims.ExpiresAt = nil
+				return sign(t, jwt.SigningMethodHS256, secret, claims)
+			},
+		},
+		{
+			name: "Wrong issuer",
+			token: func() string {
+				claims := validClaims()
+				claims.Issuer = "someone-else"
+				return sign(t, jwt.SigningMethodHS256, secret, claims)
+			},
+		},
+		{
+			name: "Wrong audience",
+			token: func() string {
+				claims := validClaims()
+				claims.Audience = jwt.ClaimStrings{"other-api"}
+				return sign(t, jwt.SigningMethodHS256, secret, claims)
+			},
+		},
+		{
+			name:  "Malformed token",
+			token: func() string { return "not-a-token" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			claims, err := validator.Validate(tt.token())
+
+			// Assert
+			assert.Nil(t, claims)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestValidator_ValidateRSA(t *testing.T) {
+	// Arrange
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicKey, err := ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	validator, err := NewValidator(Config{RSAPublicKey: publicKey})
+	require.NoError(t, err)
+
+	t.Run("Valid token", func(t *testing.T) {
+		// Act
+		claims, err := validator.Validate(sign(t, jwt.SigningMethodRS256, privateKey, validClaims()))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+	})
+
+	t.Run("HMAC token rejected without secret", func(t *testing.T) {
+		// Act
+		claims, err := validator.Validate(sign(t, jwt.SigningMethodHS256, []byte("secret"), validClaims()))
+
+		// Assert
+		assert.Nil(t, claims)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestClaims_Values(t *testing.T) {
+	// Arrange
+	var claims Claims
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"sub": "user-1",
+		"scope": "catalog:read catalog:write",
+		"realm_access": {"roles": ["editor", "offline_access"]},
+		"https://example.com/roles": ["admin"],
+		"exp": 1
+	}`), &claims))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"scope", []string{"catalog:read", "catalog:write"}},
+		{"realm_access.roles", []string{"editor", "offline_access"}},
+		{"https://example.com/roles", []string{"admin"}},
+		{"sub", []string{"user-1"}},
+		{"exp", nil},
+		{"missing.claim", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, claims.Values(tt.path))
+		})
+	}
+	assert.Equal(t, "user-1", claims.Subject)
+}
@@ -0,0 +1,64 @@
+package batch
+
+import (
+	"strings"
+
+	"external-apis/internal/shared/apperror"
+)
+
+// MaxIDs is the largest number of IDs accepted in one batch get
+const MaxIDs = 500
+
+// Result statuses reported for each requested ID
+const (
+	StatusFound    = "found"
+	StatusNotFound = "not_found"
+)
+
+// ErrIDsRequired is returned when a batch get names no usable ID
+var ErrIDsRequired = apperror.Validation("at least one id is required")
+
+// Request lists the IDs of the entities to fetch in one round trip
+type Request struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=500"`
+}
+
+// Result reports whether a single requested entity exists and, if it does,
+// carries it in Data
+type Result struct {
+	ID     string      `json:"id"`
+	Status string      `json:"status"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// Response reports every distinct requested ID in the order it was first
+// requested
+type Response struct {
+	Results []Result `json:"results"`
+}
+
+// Found records an entity that exists
+func (r *Response) Found(id string, data interface{}) {
+	r.Results = append(r.Results, Result{ID: id, Status: StatusFound, Data: data})
+}
+
+// NotFound records an entity that does not exist
+func (r *Response) NotFound(id string) {
+	r.Results = append(r.Results, Result{ID: id, Status: StatusNotFound})
+}
+
+// UniqueIDs trims the IDs and drops blank and repeated ones, keeping the
+// order in which they were first requested
+func UniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
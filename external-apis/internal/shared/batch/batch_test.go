@@ -0,0 +1,30 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniqueIDs(t *testing.T) {
+	// Act
+	ids := UniqueIDs([]string{"item-2", " item-1 ", "", "item-2", "item-1", "  "})
+
+	// Assert
+	assert.Equal(t, []string{"item-2", "item-1"}, ids)
+}
+
+func TestResponse_FoundAndNotFound(t *testing.T) {
+	// Arrange
+	response := &Response{}
+
+	// Act
+	response.Found("item-1", map[string]string{"id": "item-1"})
+	response.NotFound("item-2")
+
+	// Assert
+	assert.Equal(t, []Result{
+		{ID: "item-1", Status: StatusFound, Data: map[string]string{"id": "item-1"}},
+		{ID: "item-2", Status: StatusNotFound},
+	}, response.Results)
+}
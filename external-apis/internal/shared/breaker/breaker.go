@@ -0,0 +1,194 @@
+// Package breaker stops calling a dependency that keeps failing, so callers
+// fail fast while it recovers instead of piling up on its timeouts.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/breaker")
+
+// ErrOpen is returned instead of calling the dependency while the breaker is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker
+type State int
+
+// Breaker states
+const (
+	// Closed lets every call through
+	Closed State = iota
+	// Open rejects every call until the cooldown has passed
+	Open
+	// HalfOpen lets a single trial call through, whose outcome closes or
+	// reopens the breaker
+	HalfOpen
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config configures a Breaker
+type Config struct {
+	// FailureThreshold is how many consecutive failures open the breaker
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before a trial call
+	Cooldown time.Duration
+	// IsFailure decides which errors count against the dependency; every
+	// error does when it is nil
+	IsFailure func(err error) bool
+}
+
+// Breaker is a circuit breaker guarding the calls to one dependency
+type Breaker struct {
+	name     string
+	config   Config
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+// New creates a closed breaker. A threshold below one is raised to one.
+func New(name string, config Config) *Breaker {
+	if config.FailureThreshold < 1 {
+		config.FailureThreshold = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(error) bool { return true }
+	}
+
+	return &Breaker{
+		name:   name,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Name returns the name of the guarded dependency
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state of the breaker
+func (b *Breaker) State() State {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == Open && b.cooledDown() {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Execute calls fn unless the breaker is open, in which case it returns
+// ErrOpen. Calls whose context ended are not held against the dependency, as
+// the caller gave up rather than the dependency failing.
+func (b *Breaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := fn(ctx)
+	b.record(err == nil || ctx.Err() != nil || !b.config.IsFailure(err))
+	return err
+}
+
+// allow checks whether a call may go through, claiming the trial call once
+// the cooldown has passed
+func (b *Breaker) allow() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case Closed:
+		return nil
+	case Open:
+		if !b.cooledDown() {
+			return ErrOpen
+		}
+		b.transition(HalfOpen)
+	}
+
+	if b.probing {
+		return ErrOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *Breaker) record(success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == HalfOpen {
+		b.probing = false
+		if success {
+			b.failures = 0
+			b.transition(Closed)
+		} else {
+			b.trip()
+		}
+		return
+	}
+
+	if success {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == Closed && b.failures >= b.config.FailureThreshold {
+		b.trip()
+	}
+}
+
+// trip opens the breaker
+func (b *Breaker) trip() {
+	b.openedAt = b.now()
+	b.transition(Open)
+}
+
+// transition moves the breaker to a new state
+func (b *Breaker) transition(state State) {
+	if b.state == state {
+		return
+	}
+
+	fields := logger.Fields{
+		"breaker": b.name,
+		"from":    b.state.String(),
+		"to":      state.String(),
+	}
+	b.state = state
+
+	if state == Open {
+		log.WithFields(fields).WithField("cooldown", b.config.Cooldown.String()).Warn("Circuit breaker opened")
+		return
+	}
+	log.WithFields(fields).Info("Circuit breaker state changed")
+}
+
+// cooledDown reports whether an open breaker may try a call again
+func (b *Breaker) cooledDown() bool {
+	return !b.now().Before(b.openedAt.Add(b.config.Cooldown))
+}
@@ -0,0 +1,148 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errDown = errors.New("dependency down")
+
+// newTestBreaker creates a breaker with a controllable clock
+func newTestBreaker(config Config) (*Breaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := New("test", config)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func fail(context.Context) error    { return errDown }
+func succeed(context.Context) error { return nil }
+
+func TestBreaker_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Open after consecutive failures", func(t *testing.T) {
+		// Arrange
+		b, _ := newTestBreaker(Config{FailureThreshold: 2, Cooldown: time.Minute})
+
+		// Act
+		first := b.Execute(ctx, fail)
+		second := b.Execute(ctx, fail)
+		called := false
+		third := b.Execute(ctx, func(context.Context) error {
+			called = true
+			return nil
+		})
+
+		// Assert
+		assert.ErrorIs(t, first, errDown)
+		assert.ErrorIs(t, second, errDown)
+		assert.ErrorIs(t, third, ErrOpen)
+		assert.False(t, called)
+		assert.Equal(t, Open, b.State())
+	})
+
+	t.Run("Success resets the failure count", func(t *testing.T) {
+		// Arrange
+		b, _ := newTestBreaker(Config{FailureThreshold: 2, Cooldown: time.Minute})
+
+		// Act
+		_ = b.Execute(ctx, fail)
+		_ = b.Execute(ctx, succeed)
+		_ = b.Execute(ctx, fail)
+
+		// Assert
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Close after a successful trial call", func(t *testing.T) {
+		// Arrange
+		b, now := newTestBreaker(Config{FailureThreshold: 1, Cooldown: time.Minute})
+		_ = b.Execute(ctx, fail)
+		*now = now.Add(time.Minute)
+
+		// Act
+		state := b.State()
+		err := b.Execute(ctx, succeed)
+
+		// Assert
+		assert.Equal(t, HalfOpen, state)
+		assert.NoError(t, err)
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Reopen after a failed trial call", func(t *testing.T) {
+		// Arrange
+		b, now := newTestBreaker(Config{FailureThreshold: 1, Cooldown: time.Minute})
+		_ = b.Execute(ctx, fail)
+		*now = now.Add(time.Minute)
+
+		// Act
+		_ = b.Execute(ctx, fail)
+		err := b.Execute(ctx, succeed)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrOpen)
+		assert.Equal(t, Open, b.State())
+	})
+
+	t.Run("Let a single trial call through", func(t *testing.T) {
+		// Arrange
+		b, now := newTestBreaker(Config{FailureThreshold: 1, Cooldown: time.Minute})
+		_ = b.Execute(ctx, fail)
+		*now = now.Add(time.Minute)
+
+		// Act
+		var concurrent error
+		err := b.Execute(ctx, func(context.Context) error {
+			concurrent = b.Execute(ctx, succeed)
+			return nil
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.ErrorIs(t, concurrent, ErrOpen)
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Ignore errors that are not failures", func(t *testing.T) {
+		// Arrange
+		notFound := errors.New("not found")
+		b, _ := newTestBreaker(Config{
+			FailureThreshold: 1,
+			Cooldown:         time.Minute,
+			IsFailure:        func(err error) bool { return !errors.Is(err, notFound) },
+		})
+
+		// Act
+		err := b.Execute(ctx, func(context.Context) error { return notFound })
+
+		// Assert
+		assert.ErrorIs(t, err, notFound)
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Ignore calls whose context ended", func(t *testing.T) {
+		// Arrange
+		b, _ := newTestBreaker(Config{FailureThreshold: 1, Cooldown: time.Minute})
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		// Act
+		err := b.Execute(cancelled, fail)
+
+		// Assert
+		assert.ErrorIs(t, err, errDown)
+		assert.Equal(t, Closed, b.State())
+	})
+}
+
+func TestState_String(t *testing.T) {
+	assert.Equal(t, "closed", Closed.String())
+	assert.Equal(t, "open", Open.String())
+	assert.Equal(t, "half-open", HalfOpen.String())
+}
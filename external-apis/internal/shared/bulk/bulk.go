@@ -0,0 +1,106 @@
+package bulk
+
+import (
+	"errors"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+)
+
+// Operation types accepted by the bulk endpoints
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Result statuses reported for each operation
+const (
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusRolledBack = "rolled_back"
+)
+
+// MaxOperations is the largest number of operations accepted in one request
+const MaxOperations = 5000
+
+// MaxBodySize is the request body limit of bulk routes, in bytes, leaving
+// room for MaxOperations operations
+const MaxBodySize = 8 << 20
+
+// Timeout is the handler timeout of bulk routes, leaving time for
+// MaxOperations operations
+const Timeout = 30 * time.Second
+
+// ErrRolledBack aborts a bulk transaction after one of its operations failed
+var ErrRolledBack = errors.New("bulk operation rolled back")
+
+// Errors reported for malformed operations
+var (
+	ErrCreatePayloadRequired = apperror.Validation("create payload is required")
+	ErrUpdatePayloadRequired = apperror.Validation("id and update payload are required")
+	ErrIDRequired            = apperror.Validation("id is required")
+	ErrInvalidOperation      = apperror.Validation("invalid bulk operation")
+)
+
+// Result reports the outcome of a single operation in a bulk request
+type Result struct {
+	Index  int         `json:"index"`
+	Op     string      `json:"op"`
+	ID     string      `json:"id,omitempty"`
+	Status string      `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+}
+
+// Response is the per-item report for a bulk request. Bulk requests are
+// all-or-nothing: Committed is false if any operation failed, in which case
+// no operation was applied.
+type Response struct {
+	Committed bool     `json:"committed"`
+	Results   []Result `json:"results"`
+}
+
+// Succeeded records a successful operation
+func (r *Response) Succeeded(index int, op, id string, data interface{}) {
+	r.Results = append(r.Results, Result{
+		Index:  index,
+		Op:     op,
+		ID:     id,
+		Status: StatusSucceeded,
+		Data:   data,
+	})
+}
+
+// Failed records a failed operation
+func (r *Response) Failed(index int, op, id string, err error) {
+	r.Results = append(r.Results, Result{
+		Index:  index,
+		Op:     op,
+		ID:     id,
+		Status: StatusFailed,
+		Error:  err.Error(),
+	})
+}
+
+// HasFailures reports whether any operation failed
+func (r *Response) HasFailures() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// RollBack marks every successful operation as rolled back and drops its
+// data, since none of it was persisted
+func (r *Response) RollBack() {
+	r.Committed = false
+	for i := range r.Results {
+		if r.Results[i].Status == StatusSucceeded {
+			r.Results[i].Status = StatusRolledBack
+			r.Results[i].Data = nil
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package bulk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponse_RollBack(t *testing.T) {
+	// Arrange
+	response := &Response{Committed: true}
+	response.Succeeded(0, OpCreate, "item-1", map[string]string{"id": "item-1"})
+	response.Failed(1, OpDelete, "item-2", errors.New("item not found"))
+
+	// Act
+	hasFailures := response.HasFailures()
+	response.RollBack()
+
+	// Assert
+	assert.True(t, hasFailures)
+	assert.False(t, response.Committed)
+	assert.Equal(t, StatusRolledBack, response.Results[0].Status)
+	assert.Nil(t, response.Results[0].Data)
+	assert.Equal(t, StatusFailed, response.Results[1].Status)
+	assert.Equal(t, "item not found", response.Results[1].Error)
+}
+
+func TestResponse_HasFailures(t *testing.T) {
+	// Arrange
+	response := &Response{}
+	response.Succeeded(0, OpUpdate, "item-1", nil)
+
+	// Act
+	hasFailures := response.HasFailures()
+
+	// Assert
+	assert.False(t, hasFailures)
+}
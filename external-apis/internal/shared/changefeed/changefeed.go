@@ -0,0 +1,189 @@
+package changefeed
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/tenant"
+)
+
+// Operations recorded in the feed
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	// OpDelete changes are tombstones: they carry the ID of the deleted
+	// entity but no data
+	OpDelete = "delete"
+)
+
+// operations maps the lifecycle events to the operation they record. A
+// merged customer is gone, so its merge is the tombstone of its ID.
+var operations = map[string]string{
+	events.CustomerCreated:  OpCreate,
+	events.CustomerUpdated:  OpUpdate,
+	events.CustomerRestored: OpUpdate,
+	events.CustomerVerified: OpUpdate,
+	events.CustomerDeleted:  OpDelete,
+	events.CustomerMerged:   OpDelete,
+
+	events.ProductCreated:      OpCreate,
+	events.ProductUpdated:      OpUpdate,
+	events.ProductRestored:     OpUpdate,
+	events.ProductStockChanged: OpUpdate,
+	events.ProductDeleted:      OpDelete,
+}
+
+var (
+	// ErrInvalidCursor is returned for cursors the feed did not issue
+	ErrInvalidCursor = errors.New("since must be a cursor returned by the feed")
+	// ErrCursorExpired is returned when changes after the cursor were
+	// dropped from the feed, or the cursor is ahead of the feed because it
+	// was issued before the feed restarted its numbering, so resuming from
+	// it would skip changes
+	ErrCursorExpired = errors.New("changes after the cursor are no longer retained, resync with a full export")
+)
+
+// Change is an entry of the feed
+type Change struct {
+	// Cursor resumes the feed after this change
+	Cursor     string    `json:"cursor"`
+	Operation  string    `json:"operation"`
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// Data is the entity after the change; omitted for deletes
+	Data interface{} `json:"data,omitempty"`
+
+	sequence int64
+}
+
+// Page is a page of changes in the order they were recorded
+type Page struct {
+	Changes []Change `json:"changes"`
+	// Cursor is passed as since to read the changes after this page. It is
+	// returned even when the page is empty so clients can poll with it.
+	Cursor  string `json:"cursor"`
+	HasMore bool   `json:"hasMore"`
+}
+
+// Feed records the lifecycle events of one kind of entity as an ordered log
+// of changes per tenant that clients read incrementally with cursors. It is
+// an events.Publisher, so it sees the changes in the order they were
+// published. Each tenant keeps its last retention changes; older ones are
+// dropped, expiring the cursors that point before them.
+type Feed struct {
+	logs      *tenant.Partitions[*changeLog]
+	sequence  atomic.Int64
+	retention int
+}
+
+// New creates a feed keeping the last retention changes of each tenant
+func New(retention int) *Feed {
+	f := &Feed{retention: retention}
+	f.logs = tenant.NewPartitions(func(string) *changeLog { return &changeLog{} })
+	return f
+}
+
+// Publish records the change described by event. Events that do not change
+// an entity are ignored.
+func (f *Feed) Publish(event events.Event) {
+	operation, ok := operations[event.Type]
+	if !ok {
+		return
+	}
+
+	change := Change{
+		Operation:  operation,
+		ID:         event.Subject,
+		Event:      event.Type,
+		OccurredAt: event.OccurredAt,
+	}
+	if operation != OpDelete {
+		change.Data = event.Data
+	}
+
+	owner := event.Tenant
+	if owner == "" {
+		owner = tenant.Default
+	}
+	f.logs.Get(owner).append(change, &f.sequence, f.retention)
+}
+
+// Changes returns the changes of the tenant of ctx recorded after the
+// cursor since, at most limit of them. An empty since reads from the oldest
+// retained change.
+func (f *Feed) Changes(ctx context.Context, since string, limit int) (*Page, error) {
+	after := int64(0)
+	if since != "" {
+		var err error
+		after, err = strconv.ParseInt(since, 10, 64)
+		if err != nil || after < 0 {
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return f.logs.View(ctx).read(after, since != "", limit, &f.sequence)
+}
+
+// changeLog is the log of changes of one tenant
+type changeLog struct {
+	changes []Change
+	// dropped is the sequence of the last change dropped from the log
+	dropped int64
+	mutex   sync.RWMutex
+}
+
+// append records change, numbering it after every change of the feed, and
+// drops the oldest changes beyond retention. Numbering under the log lock
+// keeps each log in sequence order.
+func (l *changeLog) append(change Change, sequence *atomic.Int64, retention int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	change.sequence = sequence.Add(1)
+	change.Cursor = strconv.FormatInt(change.sequence, 10)
+	l.changes = append(l.changes, change)
+
+	if excess := len(l.changes) - retention; excess > 0 {
+		l.dropped = l.changes[excess-1].sequence
+		l.changes = l.changes[excess:]
+	}
+}
+
+// read returns up to limit changes numbered after the sequence after. A
+// resumed read fails when changes after it were dropped, and when after is
+// beyond the sequence: the sequence starts over when the process restarts,
+// so the changes numbered up to the cursor of an earlier process would be
+// skipped.
+func (l *changeLog) read(after int64, resumed bool, limit int, sequence *atomic.Int64) (*Page, error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	head := sequence.Load()
+	if resumed && (after < l.dropped || after > head) {
+		return nil, ErrCursorExpired
+	}
+
+	start := sort.Search(len(l.changes), func(i int) bool { return l.changes[i].sequence > after })
+	end := min(start+limit, len(l.changes))
+	page := &Page{
+		Changes: append([]Change{}, l.changes[start:end]...),
+		HasMore: end < len(l.changes),
+	}
+
+	if page.HasMore {
+		page.Cursor = page.Changes[len(page.Changes)-1].Cursor
+		return page, nil
+	}
+
+	// A drained page resumes from the head of the feed: later changes of
+	// this tenant are numbered after it since they append under this lock
+	page.Cursor = strconv.FormatInt(max(after, head), 10)
+	return page, nil
+}
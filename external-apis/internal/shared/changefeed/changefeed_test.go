@@ -0,0 +1,210 @@
+package changefeed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ids returns the IDs of the entities changed in page, in order
+func ids(page *Page) []string {
+	changed := make([]string, len(page.Changes))
+	for i, change := range page.Changes {
+		changed[i] = change.ID
+	}
+	return changed
+}
+
+func TestFeed_Publish(t *testing.T) {
+	// Arrange
+	feed := New(100)
+	ctx := context.Background()
+
+	// Act
+	feed.Publish(events.New(events.ProductCreated, "product-1", map[string]string{"name": "Laptop"}))
+	feed.Publish(events.New(events.ProductStockChanged, "product-1", map[string]string{"name": "Laptop"}))
+	feed.Publish(events.New(events.ProductDeleted, "product-1", map[string]string{"id": "product-1"}))
+	feed.Publish(events.New(events.CustomerMerged, "customer-2", map[string]string{"survivorId": "customer-1"}))
+	feed.Publish(events.Event{Type: "product.viewed", Subject: "product-1"})
+
+	// Assert
+	page, err := feed.Changes(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Changes, 4, "events that change nothing are ignored")
+	assert.Equal(t, OpCreate, page.Changes[0].Operation)
+	assert.Equal(t, map[string]string{"name": "Laptop"}, page.Changes[0].Data)
+	assert.Equal(t, OpUpdate, page.Changes[1].Operation)
+	assert.Equal(t, events.ProductStockChanged, page.Changes[1].Event)
+	assert.Equal(t, OpDelete, page.Changes[2].Operation)
+	assert.Nil(t, page.Changes[2].Data, "deletes are tombstones")
+	assert.Equal(t, OpDelete, page.Changes[3].Operation, "merged customers are gone")
+	assert.Equal(t, "customer-2", page.Changes[3].ID)
+}
+
+func TestFeed_Changes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Resume after each page", func(t *testing.T) {
+		// Arrange
+		feed := New(100)
+		for i := 1; i <= 5; i++ {
+			feed.Publish(events.New(events.CustomerUpdated, fmt.Sprintf("customer-%d", i), nil))
+		}
+
+		// Act
+		first, err := feed.Changes(ctx, "", 2)
+		require.NoError(t, err)
+		second, err := feed.Changes(ctx, first.Cursor, 2)
+		require.NoError(t, err)
+		last, err := feed.Changes(ctx, second.Cursor, 2)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"customer-1", "customer-2"}, ids(first))
+		assert.True(t, first.HasMore)
+		assert.Equal(t, first.Changes[1].Cursor, first.Cursor)
+		assert.Equal(t, []string{"customer-3", "customer-4"}, ids(second))
+		assert.Equal(t, []string{"customer-5"}, ids(last))
+		assert.False(t, last.HasMore)
+	})
+
+	t.Run("Poll with the cursor of a drained page", func(t *testing.T) {
+		// Arrange
+		feed := New(100)
+		feed.Publish(events.New(events.CustomerCreated, "customer-1", nil))
+		drained, err := feed.Changes(ctx, "", 10)
+		require.NoError(t, err)
+		empty, err := feed.Changes(ctx, drained.Cursor, 10)
+		require.NoError(t, err)
+		feed.Publish(events.New(events.CustomerUpdated, "customer-1", nil))
+
+		// Act
+		page, err := feed.Changes(ctx, empty.Cursor, 10)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, empty.Changes)
+		assert.Equal(t, drained.Cursor, empty.Cursor)
+		assert.Equal(t, []string{"customer-1"}, ids(page))
+		assert.Equal(t, events.CustomerUpdated, page.Changes[0].Event)
+	})
+
+	t.Run("Start from the head of an empty feed", func(t *testing.T) {
+		// Arrange
+		feed := New(100)
+
+		// Act
+		page, err := feed.Changes(ctx, "", 10)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, page.Changes)
+		assert.Equal(t, "0", page.Cursor)
+	})
+
+	t.Run("Keep the changes of tenants apart", func(t *testing.T) {
+		// Arrange
+		feed := New(100)
+		feed.Publish(events.New(events.ProductCreated, "product-a", nil).For("brand-a"))
+		feed.Publish(events.New(events.ProductCreated, "product-default", nil))
+
+		// Act
+		brandA, err := feed.Changes(tenant.WithTenant(ctx, "brand-a"), "", 10)
+		require.NoError(t, err)
+		fallback, err := feed.Changes(ctx, "", 10)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, []string{"product-a"}, ids(brandA))
+		assert.Equal(t, []string{"product-default"}, ids(fallback))
+	})
+
+	t.Run("Reject cursors the feed did not issue", func(t *testing.T) {
+		// Arrange
+		feed := New(100)
+
+		// Act
+		_, err := feed.Changes(ctx, "yesterday", 10)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Expire cursors behind the retained changes", func(t *testing.T) {
+		// Arrange
+		feed := New(2)
+		feed.Publish(events.New(events.ProductCreated, "product-1", nil))
+		stale, err := feed.Changes(ctx, "", 10)
+		require.NoError(t, err)
+		for i := 2; i <= 4; i++ {
+			feed.Publish(events.New(events.ProductCreated, fmt.Sprintf("product-%d", i), nil))
+		}
+
+		// Act
+		_, err = feed.Changes(ctx, stale.Cursor, 10)
+		retained, retainedErr := feed.Changes(ctx, "", 10)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrCursorExpired)
+		require.NoError(t, retainedErr)
+		assert.Equal(t, []string{"product-3", "product-4"}, ids(retained))
+	})
+
+	t.Run("Expire cursors ahead of the feed", func(t *testing.T) {
+		// Arrange: the cursor was issued before a restart numbered the
+		// changes from 1 again
+		feed := New(100)
+		feed.Publish(events.New(events.ProductCreated, "product-1", nil))
+
+		// Act
+		_, err := feed.Changes(ctx, "500", 10)
+		head, headErr := feed.Changes(ctx, "1", 10)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrCursorExpired)
+		require.NoError(t, headErr)
+		assert.Empty(t, head.Changes)
+	})
+}
+
+func TestFeed_ConcurrentPublish(t *testing.T) {
+	// Arrange
+	feed := New(1000)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				feed.Publish(events.New(events.ProductUpdated, fmt.Sprintf("product-%d-%d", writer, j), nil))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	var read []Change
+	cursor := ""
+	for {
+		page, err := feed.Changes(ctx, cursor, 7)
+		require.NoError(t, err)
+		read = append(read, page.Changes...)
+		cursor = page.Cursor
+		if !page.HasMore {
+			break
+		}
+	}
+	require.Len(t, read, 200)
+	for i := 1; i < len(read); i++ {
+		assert.Less(t, read[i-1].sequence, read[i].sequence)
+	}
+}
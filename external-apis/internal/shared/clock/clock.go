@@ -0,0 +1,48 @@
+// Package clock hands out the updated_at times of records. Change data
+// capture pipelines order the versions of a record by it, so it has to grow
+// with every write even when the wall clock stands still or steps back.
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock returns strictly increasing UTC times, truncated to microseconds as
+// SQL databases store them, so no two times it returns compare equal once
+// stored. It is safe for concurrent use.
+type Clock struct {
+	// last is the last time returned, in microseconds since the epoch
+	last atomic.Int64
+	now  func() time.Time
+}
+
+// New creates a clock reading the wall clock
+func New() *Clock {
+	return &Clock{now: time.Now}
+}
+
+// Now returns the current time, or a microsecond after the last time
+// returned if the wall clock has not moved past it
+func (c *Clock) Now() time.Time {
+	return c.next(0)
+}
+
+// After returns Now, or a microsecond after previous if that is later. A
+// record last written at previous, e.g. by another process, is stamped
+// after it.
+func (c *Clock) After(previous time.Time) time.Time {
+	return c.next(previous.Truncate(time.Microsecond).UnixMicro() + 1)
+}
+
+// next returns the current time in microseconds, raised to at least floor
+// and past the last time returned
+func (c *Clock) next(floor int64) time.Time {
+	for {
+		last := c.last.Load()
+		next := max(c.now().UnixMicro(), last+1, floor)
+		if c.last.CompareAndSwap(last, next) {
+			return time.UnixMicro(next).UTC()
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixed returns a clock whose wall clock reads *at
+func fixed(at *time.Time) *Clock {
+	return &Clock{now: func() time.Time { return *at }}
+}
+
+func TestClock_Now(t *testing.T) {
+	t.Run("Truncate to microseconds in UTC", func(t *testing.T) {
+		// Arrange
+		at := time.Date(2026, time.March, 2, 12, 0, 0, 123456789, time.FixedZone("CET", 3600))
+		clock := fixed(&at)
+
+		// Act
+		now := clock.Now()
+
+		// Assert
+		assert.Equal(t, time.UTC, now.Location())
+		assert.True(t, now.Equal(time.Date(2026, time.March, 2, 11, 0, 0, 123456000, time.UTC)))
+	})
+
+	t.Run("Wall clock standing still", func(t *testing.T) {
+		// Arrange
+		at := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+		clock := fixed(&at)
+
+		// Act
+		first := clock.Now()
+		second := clock.Now()
+
+		// Assert
+		assert.Equal(t, time.Microsecond, second.Sub(first))
+	})
+
+	t.Run("Wall clock stepping back", func(t *testing.T) {
+		// Arrange
+		at := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+		clock := fixed(&at)
+		first := clock.Now()
+		at = at.Add(-time.Hour)
+
+		// Act
+		second := clock.Now()
+
+		// Assert
+		assert.True(t, second.After(first))
+	})
+
+	t.Run("Concurrent callers", func(t *testing.T) {
+		// Arrange
+		clock := New()
+		const callers, calls = 8, 1000
+		times := make(chan time.Time, callers*calls)
+		var wg sync.WaitGroup
+
+		// Act
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range calls {
+					times <- clock.Now()
+				}
+			}()
+		}
+		wg.Wait()
+		close(times)
+
+		// Assert
+		seen := make(map[time.Time]bool, callers*calls)
+		for at := range times {
+			require.False(t, seen[at], "%s returned twice", at)
+			seen[at] = true
+		}
+	})
+}
+
+func TestClock_After(t *testing.T) {
+	// Arrange
+	at := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	clock := fixed(&at)
+
+	t.Run("Previous time in the past", func(t *testing.T) {
+		// Act
+		after := clock.After(at.Add(-time.Minute))
+
+		// Assert
+		assert.True(t, after.Equal(at))
+	})
+
+	t.Run("Previous time ahead of the wall clock", func(t *testing.T) {
+		// Arrange
+		previous := at.Add(time.Minute + 500*time.Nanosecond)
+
+		// Act
+		after := clock.After(previous)
+
+		// Assert
+		assert.True(t, after.Equal(at.Add(time.Minute+time.Microsecond)))
+		assert.True(t, clock.Now().After(after))
+	})
+}
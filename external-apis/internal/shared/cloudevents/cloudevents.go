@@ -0,0 +1,103 @@
+// Package cloudevents encodes and decodes events in the structured JSON
+// format of CloudEvents 1.0, the contract the services publish events under
+// to brokers and webhook subscribers.
+package cloudevents
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// SpecVersion is the CloudEvents version events are encoded in
+	SpecVersion = "1.0"
+	// ContentType is the media type of an event in the structured format
+	ContentType = "application/cloudevents+json"
+	// JSONDataContentType is the media type of JSON event data
+	JSONDataContentType = "application/json"
+)
+
+// ErrInvalidEvent is wrapped by the errors of events that are not valid
+// CloudEvents
+var ErrInvalidEvent = errors.New("invalid CloudEvent")
+
+// Event is a CloudEvent. Tenant and Traceparent are extension attributes:
+// the tenant owning the subject and the W3C trace context the event was
+// published in, so consumers can carry on the trace.
+type Event struct {
+	SpecVersion string `json:"specversion"`
+	ID          string `json:"id"`
+	// Source identifies the publishing service, e.g. /product-service
+	Source string `json:"source"`
+	Type   string `json:"type"`
+	// Subject is the ID of the entity the event is about
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype,omitempty"`
+	// DataSchema is the URI of the schema the data follows, if registered
+	DataSchema  string          `json:"dataschema,omitempty"`
+	Tenant      string          `json:"tenant,omitempty"`
+	Traceparent string          `json:"traceparent,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+// Validate checks that the required attributes are set and the spec
+// version is supported
+func (e Event) Validate() error {
+	if e.SpecVersion != SpecVersion {
+		return fmt.Errorf("%w: unsupported specversion %q", ErrInvalidEvent, e.SpecVersion)
+	}
+	switch {
+	case e.ID == "":
+		return fmt.Errorf("%w: id is required", ErrInvalidEvent)
+	case e.Source == "":
+		return fmt.Errorf("%w: source is required", ErrInvalidEvent)
+	case e.Type == "":
+		return fmt.Errorf("%w: type is required", ErrInvalidEvent)
+	}
+	return nil
+}
+
+// Encode encodes an event in the structured JSON format. SpecVersion
+// defaults to the supported version.
+func Encode(event Event) ([]byte, error) {
+	if event.SpecVersion == "" {
+		event.SpecVersion = SpecVersion
+	}
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(event)
+}
+
+// Decode decodes an event in the structured JSON format
+func Decode(value []byte) (Event, error) {
+	var event Event
+	if err := json.Unmarshal(value, &event); err != nil {
+		return Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if err := event.Validate(); err != nil {
+		return Event{}, err
+	}
+	return event, nil
+}
+
+// SchemaRegistry resolves the schema the data of an event type follows.
+// Encoders set it as the dataschema attribute, so consumers can fetch it
+// to validate or decode the data.
+type SchemaRegistry interface {
+	// SchemaURI returns the URI of the schema of eventType, reporting false
+	// when none is registered
+	SchemaURI(eventType string) (string, bool)
+}
+
+// Schemas is a SchemaRegistry mapping event types to schema URIs
+type Schemas map[string]string
+
+// SchemaURI implements SchemaRegistry
+func (s Schemas) SchemaURI(eventType string) (string, bool) {
+	uri, ok := s[eventType]
+	return uri, ok
+}
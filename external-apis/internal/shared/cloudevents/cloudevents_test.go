@@ -0,0 +1,112 @@
+package cloudevents
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	t.Run("Structured JSON format", func(t *testing.T) {
+		// Arrange
+		event := Event{
+			ID:              "event-1",
+			Source:          "/product-service",
+			Type:            "product.created",
+			Subject:         "product-123",
+			Time:            time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+			DataContentType: JSONDataContentType,
+			Tenant:          "brand-a",
+			Traceparent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			Data:            json.RawMessage(`{"name":"Wireless Mouse"}`),
+		}
+
+		// Act
+		value, err := Encode(event)
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"specversion": "1.0",
+			"id": "event-1",
+			"source": "/product-service",
+			"type": "product.created",
+			"subject": "product-123",
+			"time": "2026-03-01T12:00:00Z",
+			"datacontenttype": "application/json",
+			"tenant": "brand-a",
+			"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"data": {"name": "Wireless Mouse"}
+		}`, string(value))
+	})
+
+	t.Run("Missing source", func(t *testing.T) {
+		// Act
+		_, err := Encode(Event{ID: "event-1", Type: "product.created"})
+
+		// Assert
+		require.ErrorIs(t, err, ErrInvalidEvent)
+		assert.Contains(t, err.Error(), "source is required")
+	})
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		// Arrange
+		event := Event{
+			SpecVersion: SpecVersion,
+			ID:          "event-1",
+			Source:      "/customer-service",
+			Type:        "customer.updated",
+			Subject:     "customer-123",
+			Time:        time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC),
+			DataSchema:  "https://schemas.example.com/customer.updated/1",
+			Data:        json.RawMessage(`{"id":"customer-123"}`),
+		}
+		value, err := Encode(event)
+		require.NoError(t, err)
+
+		// Act
+		decoded, err := Decode(value)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, event, decoded)
+	})
+
+	invalid := []struct {
+		name  string
+		value string
+	}{
+		{"Not JSON", `product.created`},
+		{"Unsupported version", `{"specversion":"0.3","id":"event-1","source":"/product-service","type":"product.created"}`},
+		{"Missing type", `{"specversion":"1.0","id":"event-1","source":"/product-service"}`},
+		{"Legacy envelope", `{"id":"event-1","type":"product.created","subject":"product-123","occurredAt":"2026-03-01T12:00:00Z"}`},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := Decode([]byte(tt.value))
+
+			// Assert
+			assert.ErrorIs(t, err, ErrInvalidEvent)
+		})
+	}
+}
+
+func TestSchemas_SchemaURI(t *testing.T) {
+	// Arrange
+	schemas := Schemas{"product.created": "https://schemas.example.com/product.created/1"}
+
+	// Act
+	uri, ok := schemas.SchemaURI("product.created")
+	_, missing := schemas.SchemaURI("product.deleted")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, "https://schemas.example.com/product.created/1", uri)
+	assert.False(t, missing)
+}
@@ -0,0 +1,102 @@
+package conditional
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("shared/conditional")
+
+// ETag returns a strong entity tag for a response body. Equal bodies always
+// get the same tag, so it also matches across instances of a service.
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// OK sends data as a 200 JSON response, enveloped as the request asks,
+// carrying an ETag and, unless lastModified is zero, a Last-Modified header.
+// A GET or HEAD whose If-None-Match or If-Modified-Since header shows the
+// client already holds this version gets 304 Not Modified without a body
+// instead.
+func OK(c *gin.Context, data interface{}, lastModified time.Time) {
+	body, err := json.Marshal(response.Body(c, http.StatusOK, data))
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to encode conditional response")
+		response.InternalServerError(c, "Failed to encode response")
+		return
+	}
+
+	etag := ETag(body)
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if NotModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// NotModified evaluates the validators of a GET or HEAD request against the
+// current version of a resource, following RFC 9110: If-None-Match takes
+// precedence and If-Modified-Since is only consulted without it.
+func NotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return matchesAny(header, etag)
+	}
+
+	header := r.Header.Get("If-Modified-Since")
+	if header == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	// Last-Modified only has second precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// Matches reports whether a request changing a resource may go ahead: it
+// has no If-Match header, or the header lists the ETag a GET of the resource
+// would carry now, where data is its current version. Weak tags match too,
+// since compression weakens the strong tags OK sends.
+func Matches(c *gin.Context, data interface{}) (bool, error) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true, nil
+	}
+
+	body, err := json.Marshal(response.Body(c, http.StatusOK, data))
+	if err != nil {
+		return false, err
+	}
+	return matchesAny(header, ETag(body)), nil
+}
+
+// matchesAny checks if an If-None-Match or If-Match header lists the entity
+// tag, using weak comparison
+func matchesAny(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
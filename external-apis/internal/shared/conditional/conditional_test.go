@@ -0,0 +1,131 @@
+package conditional
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// send serves data through OK for a request with the given headers
+func send(method string, headers map[string]string, data interface{}, lastModified time.Time) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	_, router := gin.CreateTestContext(recorder)
+	router.Handle(method, "/items/1", func(c *gin.Context) {
+		OK(c, data, lastModified)
+	})
+
+	req := httptest.NewRequest(method, "/items/1", nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestOK(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 1, 12, 30, 15, 500, time.UTC)
+	data := map[string]string{"id": "item-1"}
+	etag := ETag([]byte(`{"data":{"id":"item-1"},"message":"OK","code":200}`))
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{name: "Unconditional", method: http.MethodGet, status: http.StatusOK},
+		{name: "Matching ETag", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"other", ` + etag}, status: http.StatusNotModified},
+		{name: "Weak form of the ETag", method: http.MethodGet, headers: map[string]string{"If-None-Match": "W/" + etag}, status: http.StatusNotModified},
+		{name: "Wildcard", method: http.MethodGet, headers: map[string]string{"If-None-Match": "*"}, status: http.StatusNotModified},
+		{name: "Stale ETag", method: http.MethodGet, headers: map[string]string{"If-None-Match": `"stale"`}, status: http.StatusOK},
+		{
+			name:    "ETag takes precedence over date",
+			method:  http.MethodGet,
+			headers: map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": updatedAt.Format(http.TimeFormat)},
+			status:  http.StatusOK,
+		},
+		{name: "Not modified since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": updatedAt.Format(http.TimeFormat)}, status: http.StatusNotModified},
+		{name: "Modified since", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": updatedAt.Add(-time.Second).Format(http.TimeFormat)}, status: http.StatusOK},
+		{name: "Unparsable date", method: http.MethodGet, headers: map[string]string{"If-Modified-Since": "yesterday"}, status: http.StatusOK},
+		{name: "Not a GET", method: http.MethodPut, headers: map[string]string{"If-None-Match": etag}, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			recorder := send(tt.method, tt.headers, data, updatedAt)
+
+			// Assert
+			assert.Equal(t, tt.status, recorder.Code)
+			assert.Equal(t, etag, recorder.Header().Get("ETag"))
+			assert.Equal(t, "Sun, 01 Mar 2026 12:30:15 GMT", recorder.Header().Get("Last-Modified"))
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"data":{"id":"item-1"},"message":"OK","code":200}`, recorder.Body.String())
+			} else {
+				assert.Empty(t, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestOK_WithoutLastModified(t *testing.T) {
+	// Act
+	recorder := send(http.MethodGet, map[string]string{"If-Modified-Since": time.Now().Format(http.TimeFormat)}, []int{1}, time.Time{})
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Last-Modified"))
+}
+
+func TestETag(t *testing.T) {
+	// Act
+	first := ETag([]byte(`{"name":"Laptop"}`))
+	same := ETag([]byte(`{"name":"Laptop"}`))
+	changed := ETag([]byte(`{"name":"Laptop Pro"}`))
+
+	// Assert
+	assert.Equal(t, first, same)
+	assert.NotEqual(t, first, changed)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, first, "a strong tag is quoted without the W/ prefix")
+}
+
+func TestMatches(t *testing.T) {
+	data := map[string]string{"id": "item-1"}
+	etag := ETag([]byte(`{"data":{"id":"item-1"},"message":"OK","code":200}`))
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		matches bool
+	}{
+		{name: "Unconditional", matches: true},
+		{name: "Current ETag", ifMatch: `"other", ` + etag, matches: true},
+		{name: "Weak form of the ETag", ifMatch: "W/" + etag, matches: true},
+		{name: "Wildcard", ifMatch: "*", matches: true},
+		{name: "Stale ETag", ifMatch: `"stale"`, matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPatch, "/items/1", nil)
+			if tt.ifMatch != "" {
+				c.Request.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			// Act
+			matches, err := Matches(c, data)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.matches, matches)
+		})
+	}
+}
@@ -0,0 +1,772 @@
+package config
+
+import (
+	"time"
+
+	"external-apis/internal/shared/currency"
+	"external-apis/internal/shared/money"
+)
+
+// Config is the configuration shared by the services. Every service reads
+// the sections it needs and ignores the others.
+//
+// Each setting has a key in the config file, given by its config tag and the
+// tags of the sections containing it (e.g. http.port), and an environment
+// variable overriding it, given by its env tag (e.g. PORT).
+type Config struct {
+	Logging        Logging        `config:"logging"`
+	HTTP           HTTP           `config:"http"`
+	GRPC           GRPC           `config:"grpc"`
+	TLS            TLS            `config:"tls"`
+	Auth           Auth           `config:"auth"`
+	RBAC           RBAC           `config:"rbac"`
+	Audit          Audit          `config:"audit"`
+	RateLimit      RateLimit      `config:"rate_limit"`
+	Quota          Quota          `config:"quota"`
+	Sandbox        Sandbox        `config:"sandbox"`
+	Tenants        Tenants        `config:"tenants"`
+	IDs            IDs            `config:"ids"`
+	Database       Database       `config:"database"`
+	Persistence    Persistence    `config:"persistence"`
+	Jobs           Jobs           `config:"jobs"`
+	DeadLetters    DeadLetters    `config:"dead_letters"`
+	Webhooks       Webhooks       `config:"webhooks"`
+	Events         Events         `config:"events"`
+	Kafka          Kafka          `config:"kafka"`
+	SchemaRegistry SchemaRegistry `config:"schema_registry"`
+	NATS           NATS           `config:"nats"`
+	RabbitMQ       RabbitMQ       `config:"rabbitmq"`
+	Storage        Storage        `config:"storage"`
+	Search         Search         `config:"search"`
+	Downstream     Downstream     `config:"downstream"`
+	Enrichment     Enrichment     `config:"enrichment"`
+	Orders         Orders         `config:"orders"`
+	Tax            Tax            `config:"tax"`
+	Shipping       Shipping       `config:"shipping"`
+	Invoice        Invoice        `config:"invoice"`
+	Reports        Reports        `config:"reports"`
+	Expand         Expand         `config:"expand"`
+	PII            PII            `config:"pii"`
+	Phone          Phone          `config:"phone"`
+	Verification   Verification   `config:"verification"`
+	Email          Email          `config:"email"`
+	Catalog        Catalog        `config:"catalog"`
+	Currency       Currency       `config:"currency"`
+	Reservations   Reservations   `config:"reservations"`
+	Scheduling     Scheduling     `config:"scheduling"`
+	Changes        Changes        `config:"changes"`
+}
+
+// Logging configures the logger
+type Logging struct {
+	Backend string `config:"backend" env:"LOG_BACKEND" validate:"oneof=logrus zap"`
+	Level   string `config:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn warning error fatal"`
+	// Packages overrides the level of packages as "package=level" entries,
+	// e.g. "customer/service=debug"
+	Packages []string `config:"packages" env:"LOG_LEVELS"`
+}
+
+// HTTP configures the HTTP server and its shutdown
+type HTTP struct {
+	Port    string `config:"port" env:"PORT" validate:"required,numeric"`
+	GinMode string `config:"gin_mode" env:"GIN_MODE" validate:"oneof=debug release test"`
+	// ReadHeaderTimeout bounds how long a client may take to send request headers
+	ReadHeaderTimeout time.Duration `config:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" validate:"gt=0"`
+	// DrainTimeout bounds how long in-flight requests may run once shutdown starts
+	DrainTimeout time.Duration `config:"drain_timeout" env:"HTTP_DRAIN_TIMEOUT" validate:"gte=0"`
+	// ShutdownTimeout bounds the whole shutdown sequence
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"gt=0"`
+	// ReadinessDrainDelay is how long the readiness probe fails before the
+	// server stops accepting connections
+	ReadinessDrainDelay time.Duration `config:"readiness_drain_delay" env:"READINESS_DRAIN_DELAY" validate:"gte=0"`
+	// HealthCheckTimeout bounds each dependency check of the readiness probe
+	HealthCheckTimeout time.Duration `config:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT" validate:"gt=0"`
+	// HandlerTimeout is how long a request may be handled before its context
+	// is cancelled and it is answered with 504; handlers that ignore the
+	// context still run to the end. Imports, exports and uploads raise it;
+	// zero removes it.
+	HandlerTimeout time.Duration `config:"handler_timeout" env:"HTTP_HANDLER_TIMEOUT" validate:"gte=0"`
+	// MaxRequestDeadline caps the budget a caller may grant a request with
+	// the X-Request-Deadline header; zero accepts any budget.
+	MaxRequestDeadline time.Duration `config:"max_request_deadline" env:"HTTP_MAX_REQUEST_DEADLINE" validate:"gte=0"`
+	// MaxBodySize is the largest request body accepted, in bytes. Routes
+	// taking uploads raise it; zero removes the limit.
+	MaxBodySize int `config:"max_body_size" env:"HTTP_MAX_BODY_SIZE" validate:"gte=0"`
+	// ResponseEnvelope wraps success responses in {"data", "message", "code"}.
+	// Clients choose otherwise per request with the X-Response-Envelope header.
+	ResponseEnvelope bool `config:"response_envelope" env:"HTTP_RESPONSE_ENVELOPE"`
+	// RequestIDFormat is the format of the IDs given to requests arriving
+	// without an X-Request-ID header: uuidv7, uuidv4 or random
+	RequestIDFormat string      `config:"request_id_format" env:"REQUEST_ID_FORMAT" validate:"oneof=uuidv7 uuidv4 random"`
+	Compression     Compression `config:"compression"`
+}
+
+// Compression configures gzip and brotli compression of HTTP responses
+type Compression struct {
+	Enabled bool `config:"enabled" env:"HTTP_COMPRESSION"`
+	// MinSize is the smallest response body worth compressing, in bytes
+	MinSize int `config:"min_size" env:"HTTP_COMPRESSION_MIN_SIZE" validate:"gte=0"`
+	// Types lists the content types compressed, e.g. "application/json" or
+	// "text/*". The middleware defaults apply when it is empty.
+	Types []string `config:"types" env:"HTTP_COMPRESSION_TYPES"`
+}
+
+// GRPC configures the gRPC server of the services that expose one
+type GRPC struct {
+	Port string `config:"port" env:"GRPC_PORT" validate:"omitempty,numeric"`
+}
+
+// TLS configures HTTPS and gRPC over TLS for the servers and the calls to
+// other services. The certificate and CAs are read again every
+// ReloadInterval, so rotated files are picked up without a restart.
+type TLS struct {
+	Enabled  bool   `config:"enabled" env:"TLS_ENABLED"`
+	CertFile string `config:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile  string `config:"key_file" env:"TLS_KEY_FILE"`
+	// ClientAuth is how servers treat client certificates: none, verify
+	// those presented, or require one (mTLS)
+	ClientAuth string `config:"client_auth" env:"TLS_CLIENT_AUTH" validate:"oneof=none verify require"`
+	// ClientCAFile holds the CAs client certificates must be signed by
+	ClientCAFile string `config:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	// CAFile holds the CAs the certificates of called services must be
+	// signed by; the system roots are used when it is empty
+	CAFile         string        `config:"ca_file" env:"TLS_CA_FILE"`
+	ReloadInterval time.Duration `config:"reload_interval" env:"TLS_RELOAD_INTERVAL" validate:"gt=0"`
+}
+
+// Auth configures JWT authentication of write routes. It stays disabled
+// while neither an HMAC secret, an RSA public key nor an OIDC provider is set.
+type Auth struct {
+	HMACSecret       string `config:"hmac_secret" env:"JWT_HMAC_SECRET"`
+	RSAPublicKey     string `config:"rsa_public_key" env:"JWT_RSA_PUBLIC_KEY"`
+	RSAPublicKeyFile string `config:"rsa_public_key_file" env:"JWT_RSA_PUBLIC_KEY_FILE"`
+	// Issuer defaults to OIDCIssuer
+	Issuer   string        `config:"issuer" env:"JWT_ISSUER"`
+	Audience string        `config:"audience" env:"JWT_AUDIENCE"`
+	Leeway   time.Duration `config:"leeway" env:"JWT_LEEWAY" validate:"gte=0"`
+	// OIDCIssuer accepts the access tokens of an OIDC provider, such as
+	// Keycloak or Auth0, verified with the keys of its JWKS. OIDCJWKSURL
+	// skips discovery of the JWKS, or names it for providers without one.
+	OIDCIssuer  string `config:"oidc_issuer" env:"OIDC_ISSUER" validate:"omitempty,url"`
+	OIDCJWKSURL string `config:"oidc_jwks_url" env:"OIDC_JWKS_URL" validate:"omitempty,url"`
+	// OIDCRefreshInterval is how long the keys of the provider are cached
+	OIDCRefreshInterval time.Duration `config:"oidc_refresh_interval" env:"OIDC_JWKS_REFRESH_INTERVAL" validate:"gt=0"`
+	OIDCTimeout         time.Duration `config:"oidc_timeout" env:"OIDC_TIMEOUT" validate:"gt=0"`
+}
+
+// RBAC configures the roles callers act with. Principals are given as
+// "user:<subject>" for JWT subjects and "api-key:<id>" for API keys.
+type RBAC struct {
+	// DefaultRole is the role of authenticated callers without an assigned role
+	DefaultRole string `config:"default_role" env:"RBAC_DEFAULT_ROLE" validate:"oneof=viewer editor admin"`
+	// AnonymousRole is the role of callers reaching write routes while JWT
+	// authentication is disabled; empty refuses them
+	AnonymousRole string `config:"anonymous_role" env:"RBAC_ANONYMOUS_ROLE" validate:"omitempty,oneof=viewer editor admin"`
+	// Admins are the principals assigned the admin role at startup
+	Admins []string `config:"admins" env:"RBAC_ADMINS"`
+	// RoleClaims are the token claims, such as "scope" or
+	// "realm_access.roles", whose values RoleMappings maps to roles as
+	// "value=role" entries. Callers without an assigned role take the
+	// highest role their token maps to.
+	RoleClaims   []string `config:"role_claims" env:"RBAC_ROLE_CLAIMS"`
+	RoleMappings []string `config:"role_mappings" env:"RBAC_ROLE_MAPPINGS"`
+}
+
+// Audit configures the audit log of mutating API calls. The memory sink
+// keeps the latest MemoryCapacity entries, the file sink appends every entry
+// to File and the kafka sink publishes them to Topic on KAFKA_BROKERS,
+// keeping the latest in memory for the admin endpoint.
+type Audit struct {
+	Enabled        bool   `config:"enabled" env:"AUDIT_ENABLED"`
+	Sink           string `config:"sink" env:"AUDIT_SINK" validate:"oneof=memory file kafka"`
+	File           string `config:"file" env:"AUDIT_FILE"`
+	Topic          string `config:"topic" env:"AUDIT_KAFKA_TOPIC"`
+	MemoryCapacity int    `config:"memory_capacity" env:"AUDIT_MEMORY_CAPACITY" validate:"gt=0"`
+}
+
+// RateLimit configures the API rate limiter
+type RateLimit struct {
+	Enabled bool `config:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// ByAPIKey gives every API key its own budget instead of every client IP
+	ByAPIKey bool    `config:"by_api_key" env:"RATE_LIMIT_BY_API_KEY"`
+	RPS      float64 `config:"rps" env:"RATE_LIMIT_RPS" validate:"gt=0"`
+	Burst    int     `config:"burst" env:"RATE_LIMIT_BURST" validate:"gt=0"`
+	// RedisURL switches to a limiter shared by all instances
+	RedisURL string `config:"redis_url" env:"RATE_LIMIT_REDIS_URL" validate:"omitempty,url"`
+}
+
+// Quota configures the daily request budgets of API clients, counted per
+// API key, or per IP for requests without one
+type Quota struct {
+	Enabled bool `config:"enabled" env:"QUOTA_ENABLED"`
+	// ReadPerDay and WritePerDay are the daily budgets of each client on each
+	// route; zero leaves the class unlimited
+	ReadPerDay  int `config:"read_per_day" env:"QUOTA_READ_PER_DAY" validate:"gte=0"`
+	WritePerDay int `config:"write_per_day" env:"QUOTA_WRITE_PER_DAY" validate:"gte=0"`
+	// Routes overrides the budgets of routes as "route:class=limit" entries,
+	// e.g. "webhooks:write=100"
+	Routes []string `config:"routes" env:"QUOTA_ROUTES"`
+	// RedisURL switches to counters shared by all instances
+	RedisURL string `config:"redis_url" env:"QUOTA_REDIS_URL" validate:"omitempty,url"`
+}
+
+// Sandbox configures sandbox mode
+type Sandbox struct {
+	Enabled       bool          `config:"enabled" env:"SANDBOX_MODE"`
+	TTL           time.Duration `config:"ttl" env:"SANDBOX_TTL" validate:"gt=0"`
+	PurgeInterval time.Duration `config:"purge_interval" env:"SANDBOX_PURGE_INTERVAL" validate:"gt=0"`
+}
+
+// Tenants configures how requests select their tenant
+type Tenants struct {
+	Required bool `config:"required" env:"TENANT_REQUIRED"`
+	// Allowed restricts the served tenants; any valid tenant is served when empty
+	Allowed []string `config:"allowed" env:"TENANTS"`
+}
+
+// IDs configures the IDs given to new customers, products and orders.
+// Prefixed IDs start with the type of the record, e.g. "cus_". Unless Strict
+// is set, the API still accepts the IDs of records created before, such as
+// UUIDv4s and the IDs of the sample data; otherwise it rejects IDs of any
+// other format.
+type IDs struct {
+	Format   string `config:"format" env:"ID_FORMAT" validate:"oneof=uuidv7 ulid uuidv4"`
+	Prefixed bool   `config:"prefixed" env:"ID_PREFIXED"`
+	Strict   bool   `config:"strict" env:"ID_STRICT"`
+}
+
+// Database configures where the customers, products and orders of a
+// service are stored. The sqlite backend keeps them in an embedded database
+// file so they outlive restarts; the other repositories of the services stay
+// in memory.
+type Database struct {
+	Backend string `config:"backend" env:"STORAGE_BACKEND" validate:"oneof=memory sqlite"`
+	// Path is the database file of the sqlite backend. Each service sets its
+	// default.
+	Path string `config:"path" env:"SQLITE_PATH"`
+	// ReplicaPaths are read-only copies of Path kept up to date by
+	// replication tooling. Reads that tolerate staleness are spread over
+	// them; a replica that fails is skipped for ReplicaCooldown while its
+	// reads go to Path.
+	ReplicaPaths    []string      `config:"replica_paths" env:"SQLITE_REPLICA_PATHS"`
+	ReplicaCooldown time.Duration `config:"replica_cooldown" env:"DATABASE_REPLICA_COOLDOWN" validate:"gt=0"`
+	// AutoMigrate applies pending schema migrations on startup. Without it
+	// the service refuses to start until they are applied with its migrate
+	// subcommand.
+	AutoMigrate bool `config:"auto_migrate" env:"DATABASE_AUTO_MIGRATE"`
+	// MaxOpenConns caps the open connections of the pool; 0 means no limit
+	MaxOpenConns int `config:"max_open_conns" env:"DATABASE_MAX_OPEN_CONNS" validate:"gte=0"`
+	// MaxIdleConns is how many unused connections the pool keeps
+	MaxIdleConns    int           `config:"max_idle_conns" env:"DATABASE_MAX_IDLE_CONNS" validate:"gte=0"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DATABASE_CONN_MAX_LIFETIME" validate:"gte=0"`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" env:"DATABASE_CONN_MAX_IDLE_TIME" validate:"gte=0"`
+	// BusyTimeout is how long a statement waits for the write lock held by
+	// another connection
+	BusyTimeout time.Duration `config:"busy_timeout" env:"DATABASE_BUSY_TIMEOUT" validate:"gte=0"`
+}
+
+// Persistence configures the write-ahead log of the memory backend. When
+// enabled, the customers, products and orders of a service are logged to
+// files in Dir and compacted into a snapshot every SnapshotInterval, so
+// demo environments keep their data across restarts.
+type Persistence struct {
+	Enabled          bool          `config:"enabled" env:"PERSISTENCE_ENABLED"`
+	Dir              string        `config:"dir" env:"PERSISTENCE_DIR" validate:"required"`
+	SnapshotInterval time.Duration `config:"snapshot_interval" env:"PERSISTENCE_SNAPSHOT_INTERVAL" validate:"gt=0"`
+	// SyncInterval groups the flushes of the logs to disk. At zero every
+	// write is flushed before it is acknowledged; otherwise writes
+	// acknowledged within the last SyncInterval are lost if the machine
+	// crashes, though not if only the service does.
+	SyncInterval time.Duration `config:"sync_interval" env:"PERSISTENCE_SYNC_INTERVAL" validate:"gte=0"`
+}
+
+// Jobs configures the background job manager
+type Jobs struct {
+	StateFile string `config:"state_file" env:"JOBS_STATE_FILE" validate:"required"`
+}
+
+// DeadLetters configures where events whose delivery failed for good are parked
+type DeadLetters struct {
+	// StateFile persists parked events across restarts; they are only kept
+	// in memory when it is empty
+	StateFile string `config:"state_file" env:"DEAD_LETTERS_STATE_FILE"`
+}
+
+// Webhooks configures webhook delivery
+type Webhooks struct {
+	MaxAttempts    int           `config:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" validate:"gt=0"`
+	InitialBackoff time.Duration `config:"initial_backoff" env:"WEBHOOK_INITIAL_BACKOFF" validate:"gt=0"`
+	MaxBackoff     time.Duration `config:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" validate:"gtefield=InitialBackoff"`
+	Timeout        time.Duration `config:"timeout" env:"WEBHOOK_TIMEOUT" validate:"gt=0"`
+}
+
+// Events selects the broker lifecycle events are published to and consumed
+// from, kafka or nats, how they are identified and how consumed events are
+// retried
+type Events struct {
+	Broker string `config:"broker" env:"EVENT_BROKER" validate:"oneof=kafka nats"`
+	// Source identifies the service in the events it publishes, as their
+	// CloudEvents source attribute
+	Source string `config:"source" env:"EVENT_SOURCE" validate:"required"`
+	// MaxDeliveries bounds how many times a consumed event is handed to its
+	// handler before it is skipped
+	MaxDeliveries int `config:"max_deliveries" env:"EVENT_MAX_DELIVERIES" validate:"gt=0"`
+	// RedeliveryDelay is the wait before an event whose handler failed is
+	// delivered again, growing with every attempt
+	RedeliveryDelay time.Duration `config:"redelivery_delay" env:"EVENT_REDELIVERY_DELAY" validate:"gte=0"`
+}
+
+// Kafka configures publishing lifecycle events to Kafka. Events are only
+// published to Kafka when it is the event broker and brokers are set.
+type Kafka struct {
+	Brokers      []string      `config:"brokers" env:"KAFKA_BROKERS"`
+	Topic        string        `config:"topic" env:"KAFKA_TOPIC" validate:"required_with=Brokers"`
+	BatchTimeout time.Duration `config:"batch_timeout" env:"KAFKA_BATCH_TIMEOUT" validate:"gte=0"`
+	// Format is how events are serialized: json for structured CloudEvents,
+	// or avro or protobuf for their data against the schemas registered in
+	// the schema registry
+	Format string `config:"format" env:"KAFKA_FORMAT" validate:"oneof=json avro protobuf"`
+	// Tombstones follows the events of deleted entities with a tombstone
+	// of their key, so log compaction and CDC pipelines drop them
+	Tombstones bool `config:"tombstones" env:"KAFKA_TOMBSTONES"`
+}
+
+// SchemaRegistry configures the schema registry the schemas of events
+// published in Avro or protobuf are registered with
+type SchemaRegistry struct {
+	URL      string        `config:"url" env:"SCHEMA_REGISTRY_URL" validate:"omitempty,url"`
+	Username string        `config:"username" env:"SCHEMA_REGISTRY_USERNAME"`
+	Password string        `config:"password" env:"SCHEMA_REGISTRY_PASSWORD"`
+	Timeout  time.Duration `config:"timeout" env:"SCHEMA_REGISTRY_TIMEOUT" validate:"gt=0"`
+}
+
+// NATS configures publishing lifecycle events to NATS JetStream. Events are
+// only published to NATS when it is the event broker and URL is set.
+type NATS struct {
+	URL string `config:"url" env:"NATS_URL" validate:"omitempty,url"`
+	// Stream is the JetStream stream keeping the events, created when missing
+	Stream string `config:"stream" env:"NATS_STREAM" validate:"required_with=URL"`
+	// Subject prefixes the subjects events are published on, followed by
+	// their type, e.g. product-events.product.created
+	Subject string `config:"subject" env:"NATS_SUBJECT" validate:"required_with=URL"`
+	// AckWait bounds how long a consumer may take to handle an event before
+	// it is delivered again
+	AckWait time.Duration `config:"ack_wait" env:"NATS_ACK_WAIT" validate:"gt=0"`
+}
+
+// RabbitMQ configures the consumer of the catalog updates suppliers send to
+// the product service. Updates are only consumed when URL is set.
+type RabbitMQ struct {
+	URL string `config:"url" env:"RABBITMQ_URL" validate:"omitempty,url"`
+	// Queue is the durable queue consumed, declared when missing
+	Queue string `config:"queue" env:"RABBITMQ_QUEUE" validate:"required_with=URL"`
+	// Prefetch bounds how many unacknowledged updates are sent ahead
+	Prefetch int `config:"prefetch" env:"RABBITMQ_PREFETCH" validate:"gt=0"`
+	// ReconnectDelay is the wait between attempts to reconnect
+	ReconnectDelay time.Duration `config:"reconnect_delay" env:"RABBITMQ_RECONNECT_DELAY" validate:"gt=0"`
+	// RedeliveryDelay is the wait before an update is returned to the queue
+	// because a dependency was unavailable
+	RedeliveryDelay time.Duration `config:"redelivery_delay" env:"RABBITMQ_REDELIVERY_DELAY" validate:"gte=0"`
+}
+
+// Storage configures where product images are stored
+type Storage struct {
+	Backend string `config:"backend" env:"IMAGE_STORAGE" validate:"oneof=local s3"`
+	// Dir is the directory of the local backend
+	Dir string `config:"dir" env:"IMAGE_DIR"`
+	// BaseURL is the URL the local backend serves images under. It defaults
+	// to the /media route of the service itself.
+	BaseURL string `config:"base_url" env:"IMAGE_BASE_URL" validate:"omitempty,url"`
+	// MaxImageSize is the largest accepted upload in bytes. The product
+	// service sets its default.
+	MaxImageSize int `config:"max_image_size" env:"MAX_IMAGE_SIZE" validate:"gte=0"`
+	S3           S3  `config:"s3"`
+}
+
+// S3 configures the S3 image storage backend
+type S3 struct {
+	Endpoint        string        `config:"endpoint" env:"S3_ENDPOINT" validate:"omitempty,url"`
+	Region          string        `config:"region" env:"S3_REGION"`
+	Bucket          string        `config:"bucket" env:"S3_BUCKET"`
+	AccessKeyID     string        `config:"access_key_id" env:"S3_ACCESS_KEY_ID"`
+	SecretAccessKey string        `config:"secret_access_key" env:"S3_SECRET_ACCESS_KEY"`
+	PublicURL       string        `config:"public_url" env:"S3_PUBLIC_URL" validate:"omitempty,url"`
+	Timeout         time.Duration `config:"timeout" env:"S3_TIMEOUT" validate:"gt=0"`
+}
+
+// Search configures the Elasticsearch/OpenSearch product search backend. The
+// in-memory index is used while no URL is set.
+type Search struct {
+	URL      string        `config:"url" env:"ELASTICSEARCH_URL" validate:"omitempty,url"`
+	Index    string        `config:"index" env:"ELASTICSEARCH_INDEX" validate:"required_with=URL"`
+	Username string        `config:"username" env:"ELASTICSEARCH_USERNAME"`
+	Password string        `config:"password" env:"ELASTICSEARCH_PASSWORD"`
+	Timeout  time.Duration `config:"timeout" env:"ELASTICSEARCH_TIMEOUT" validate:"gt=0"`
+}
+
+// Downstream configures the services the order service enriches orders from.
+// Calls to a service stop for BreakerCooldown after BreakerThreshold
+// consecutive failures, zero disabling the circuit breakers.
+type Downstream struct {
+	CustomerURL      string        `config:"customer_url" env:"CUSTOMER_SERVICE_URL" validate:"required,url"`
+	ProductURL       string        `config:"product_url" env:"PRODUCT_SERVICE_URL" validate:"required,url"`
+	Timeout          time.Duration `config:"timeout" env:"DOWNSTREAM_TIMEOUT" validate:"gt=0"`
+	BreakerThreshold int           `config:"breaker_threshold" env:"DOWNSTREAM_BREAKER_THRESHOLD" validate:"gte=0"`
+	BreakerCooldown  time.Duration `config:"breaker_cooldown" env:"DOWNSTREAM_BREAKER_COOLDOWN" validate:"gt=0"`
+}
+
+// Enrichment configures orders placed while the customer or product service
+// is unavailable. With fallback they are accepted partially enriched and
+// completed in the background, retrying up to MaxAttempts times; without it
+// they are rejected. Orders still partially enriched after MaxAttempts are
+// swept up every SweepInterval and retried, backing off up to MaxBackoff.
+type Enrichment struct {
+	Fallback       bool          `config:"fallback" env:"ENRICHMENT_FALLBACK"`
+	MaxAttempts    int           `config:"max_attempts" env:"ENRICHMENT_MAX_ATTEMPTS" validate:"gt=0"`
+	InitialBackoff time.Duration `config:"initial_backoff" env:"ENRICHMENT_INITIAL_BACKOFF" validate:"gt=0"`
+	MaxBackoff     time.Duration `config:"max_backoff" env:"ENRICHMENT_MAX_BACKOFF" validate:"gtefield=InitialBackoff"`
+	SweepInterval  time.Duration `config:"sweep_interval" env:"ENRICHMENT_SWEEP_INTERVAL" validate:"gt=0"`
+}
+
+// Orders configures order creation. With ReserveStock, orders hold the stock
+// of their products through reservations in the product service, which is
+// then needed to place an order, so the enrichment fallback only defers the
+// customer. With ApplyPromotions, orders are priced through the promotions
+// of the product service and may carry a coupon code; without it they are
+// placed at list prices. SagaRetention is how many finished creation sagas
+// are kept for inspection.
+type Orders struct {
+	ReserveStock    bool `config:"reserve_stock" env:"ORDER_RESERVE_STOCK"`
+	ApplyPromotions bool `config:"apply_promotions" env:"ORDER_APPLY_PROMOTIONS"`
+	SagaRetention   int  `config:"saga_retention" env:"ORDER_SAGA_RETENTION" validate:"gt=0"`
+}
+
+// Tax configures the tax the order service charges orders where the
+// customer's preferred address is. Provider none charges no tax; flat charges
+// Rates, "jurisdiction=percent" entries such as "DE=19" or "US-CA=7.25",
+// where a region's rate adds to its country's; api asks the tax API at
+// APIURL, authenticating with APIKey and waiting up to Timeout.
+type Tax struct {
+	Provider string        `config:"provider" env:"TAX_PROVIDER" validate:"oneof=none flat api"`
+	Rates    []string      `config:"rates" env:"TAX_RATES"`
+	APIURL   string        `config:"api_url" env:"TAX_API_URL" validate:"omitempty,url"`
+	APIKey   string        `config:"api_key" env:"TAX_API_KEY"`
+	Timeout  time.Duration `config:"timeout" env:"TAX_API_TIMEOUT" validate:"gt=0"`
+}
+
+// Shipping configures the shipping options the order service quotes.
+// Rates are "carrier/level=base+perkg/days" entries such as
+// "ups/ground=4.99+1/5", optionally limited to countries with a suffix
+// like "@DE|AT"; without rates no shipping is quoted. The costs of the
+// rates are amounts of Currency.
+type Shipping struct {
+	Rates    []string `config:"rates" env:"SHIPPING_RATES"`
+	Currency string   `config:"currency" env:"SHIPPING_CURRENCY" validate:"currency"`
+}
+
+// Invoice configures the invoices and receipts of the order service. They
+// are branded with the company details, amounts printed in the currency of
+// the order, or in Currency for orders without one. HTML
+// documents are rendered with the html/template in Template, or a built-in
+// one without it. Documents generated in the background are written to Dir
+// and served under BaseURL, which defaults to the /documents route of the
+// service itself.
+type Invoice struct {
+	Template       string   `config:"template" env:"INVOICE_TEMPLATE"`
+	CompanyName    string   `config:"company_name" env:"INVOICE_COMPANY_NAME"`
+	CompanyAddress []string `config:"company_address" env:"INVOICE_COMPANY_ADDRESS"`
+	CompanyEmail   string   `config:"company_email" env:"INVOICE_COMPANY_EMAIL" validate:"omitempty,email"`
+	TaxID          string   `config:"tax_id" env:"INVOICE_TAX_ID"`
+	LogoURL        string   `config:"logo_url" env:"INVOICE_LOGO_URL" validate:"omitempty,url"`
+	AccentColor    string   `config:"accent_color" env:"INVOICE_ACCENT_COLOR" validate:"omitempty,hexcolor,len=7"`
+	Currency       string   `config:"currency" env:"INVOICE_CURRENCY" validate:"currency"`
+	Footer         string   `config:"footer" env:"INVOICE_FOOTER"`
+	Dir            string   `config:"dir" env:"INVOICE_DIR"`
+	BaseURL        string   `config:"base_url" env:"INVOICE_BASE_URL" validate:"omitempty,url"`
+}
+
+// Reports configures the daily sales report tables of the order service.
+// Every RefreshInterval the orders of the last Lookback are aggregated
+// again, so orders cancelled within it drop out of the report.
+type Reports struct {
+	RefreshInterval time.Duration `config:"refresh_interval" env:"REPORTS_REFRESH_INTERVAL" validate:"gt=0"`
+	Lookback        time.Duration `config:"lookback" env:"REPORTS_LOOKBACK" validate:"gt=0"`
+}
+
+// Expand configures how the order service inlines customers and products
+// requested with ?expand. Each expansion caches what it fetches for its TTL,
+// zero disabling the cache, and on_error decides whether a downstream
+// failure fails the read or leaves the expansion out.
+type Expand struct {
+	CustomerCacheTTL time.Duration `config:"customer_cache_ttl" env:"EXPAND_CUSTOMER_CACHE_TTL" validate:"gte=0"`
+	CustomerOnError  string        `config:"customer_on_error" env:"EXPAND_CUSTOMER_ON_ERROR" validate:"oneof=fail partial"`
+	ProductsCacheTTL time.Duration `config:"products_cache_ttl" env:"EXPAND_PRODUCTS_CACHE_TTL" validate:"gte=0"`
+	ProductsOnError  string        `config:"products_on_error" env:"EXPAND_PRODUCTS_ON_ERROR" validate:"oneof=fail partial"`
+}
+
+// PII configures encryption of customer PII at rest. Customer email and
+// phone are stored in plaintext while no key is set.
+type PII struct {
+	// Keys lists "<id>:<base64 key>" pairs, the primary key first
+	Keys string `config:"keys" env:"CUSTOMER_PII_KEYS"`
+	// IndexKey is the base64 key deriving the blind index of emails
+	IndexKey string `config:"index_key" env:"CUSTOMER_PII_INDEX_KEY" validate:"required_with=Keys"`
+}
+
+// Phone configures the parsing of customer phone numbers, which are stored
+// in E.164 format. Numbers without a country calling code are taken to be in
+// DefaultRegion, an ISO 3166-1 alpha-2 code; without a default region they
+// are rejected.
+type Phone struct {
+	DefaultRegion string `config:"default_region" env:"PHONE_DEFAULT_REGION"`
+}
+
+// Verification configures customer email verification. New customers stay
+// PENDING, unable to order, until they confirm their email with the token
+// sent to them, which is valid for TokenTTL. A new token can be requested
+// once every ResendInterval. URL is the page the email links to with the
+// token; without it the email only carries the token.
+type Verification struct {
+	Enabled        bool          `config:"enabled" env:"CUSTOMER_VERIFICATION_ENABLED"`
+	TokenTTL       time.Duration `config:"token_ttl" env:"CUSTOMER_VERIFICATION_TOKEN_TTL" validate:"gt=0"`
+	ResendInterval time.Duration `config:"resend_interval" env:"CUSTOMER_VERIFICATION_RESEND_INTERVAL" validate:"gte=0"`
+	URL            string        `config:"url" env:"CUSTOMER_VERIFICATION_URL" validate:"omitempty,url"`
+}
+
+// Email configures outgoing email. The log backend only logs messages, for
+// development; the smtp backend submits them to SMTP_HOST.
+type Email struct {
+	Backend string    `config:"backend" env:"EMAIL_BACKEND" validate:"oneof=log smtp"`
+	From    string    `config:"from" env:"EMAIL_FROM" validate:"required"`
+	SMTP    EmailSMTP `config:"smtp"`
+}
+
+// EmailSMTP configures the SMTP email backend. Username and Password are
+// only sent over TLS; no auth is used without a username.
+type EmailSMTP struct {
+	Host     string `config:"host" env:"SMTP_HOST"`
+	Port     string `config:"port" env:"SMTP_PORT" validate:"numeric"`
+	Username string `config:"username" env:"SMTP_USERNAME"`
+	Password string `config:"password" env:"SMTP_PASSWORD"`
+}
+
+// Catalog configures product defaults
+type Catalog struct {
+	DefaultCurrency string `config:"default_currency" env:"DEFAULT_CURRENCY" validate:"currency"`
+}
+
+// Currency configures the exchange rates product prices are converted at.
+// Provider static converts at Rates, "currency=rate" entries such as
+// "EUR=0.92" against Base; ecb at the daily reference rates of the European
+// Central Bank from ECBURL; api at the rates against Base of the exchange
+// rate API at APIURL, authenticating with APIKey. Fetched rates are kept for
+// RefreshInterval, and fetches wait up to Timeout.
+type Currency struct {
+	Provider        string        `config:"provider" env:"CURRENCY_PROVIDER" validate:"oneof=static ecb api"`
+	Base            string        `config:"base" env:"CURRENCY_BASE" validate:"currency"`
+	Rates           []string      `config:"rates" env:"CURRENCY_RATES"`
+	ECBURL          string        `config:"ecb_url" env:"CURRENCY_ECB_URL" validate:"url"`
+	APIURL          string        `config:"api_url" env:"CURRENCY_API_URL" validate:"omitempty,url"`
+	APIKey          string        `config:"api_key" env:"CURRENCY_API_KEY"`
+	Timeout         time.Duration `config:"timeout" env:"CURRENCY_TIMEOUT" validate:"gt=0"`
+	RefreshInterval time.Duration `config:"refresh_interval" env:"CURRENCY_REFRESH_INTERVAL" validate:"gt=0"`
+}
+
+// Reservations configures stock reservations. Unconfirmed reservations hold
+// stock for TTL unless they ask for another TTL of at most MaxTTL; expired
+// ones are released every ReleaseInterval.
+type Reservations struct {
+	TTL             time.Duration `config:"ttl" env:"RESERVATION_TTL" validate:"gt=0"`
+	MaxTTL          time.Duration `config:"max_ttl" env:"RESERVATION_MAX_TTL" validate:"gtefield=TTL"`
+	ReleaseInterval time.Duration `config:"release_interval" env:"RESERVATION_RELEASE_INTERVAL" validate:"gt=0"`
+}
+
+// Scheduling configures scheduled product changes. Due changes are applied
+// every ApplyInterval, so they take effect at most that late.
+type Scheduling struct {
+	ApplyInterval time.Duration `config:"apply_interval" env:"SCHEDULED_CHANGE_APPLY_INTERVAL" validate:"gt=0"`
+}
+
+// Changes configures the change feeds of customers and products. Each tenant
+// keeps its last Retention changes; clients whose cursor falls behind them
+// must resync with a full export.
+type Changes struct {
+	Retention int `config:"retention" env:"CHANGE_FEED_RETENTION" validate:"gt=0"`
+}
+
+// Defaults returns the defaults shared by the services. Services override
+// the settings that differ, such as their ports, before calling Load.
+func Defaults() Config {
+	return Config{
+		Logging: Logging{
+			Backend: "logrus",
+			Level:   "info",
+		},
+		HTTP: HTTP{
+			GinMode:            "debug",
+			ReadHeaderTimeout:  10 * time.Second,
+			DrainTimeout:       15 * time.Second,
+			ShutdownTimeout:    30 * time.Second,
+			HealthCheckTimeout: 2 * time.Second,
+			HandlerTimeout:     5 * time.Second,
+			MaxRequestDeadline: 30 * time.Second,
+			MaxBodySize:        1 << 20,
+			ResponseEnvelope:   true,
+			RequestIDFormat:    "uuidv7",
+			Compression: Compression{
+				Enabled: true,
+				MinSize: 1024,
+			},
+		},
+		TLS: TLS{
+			ClientAuth:     "none",
+			ReloadInterval: time.Minute,
+		},
+		Auth: Auth{
+			Leeway:              30 * time.Second,
+			OIDCRefreshInterval: time.Hour,
+			OIDCTimeout:         5 * time.Second,
+		},
+		RBAC: RBAC{
+			DefaultRole:   "editor",
+			AnonymousRole: "editor",
+			RoleClaims:    []string{"scope"},
+		},
+		Audit: Audit{
+			Enabled:        true,
+			Sink:           "memory",
+			Topic:          "audit-log",
+			MemoryCapacity: 10000,
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			RPS:     100,
+			Burst:   200,
+		},
+		Quota: Quota{
+			ReadPerDay:  100000,
+			WritePerDay: 10000,
+		},
+		Sandbox: Sandbox{
+			TTL:           time.Hour,
+			PurgeInterval: time.Minute,
+		},
+		IDs: IDs{Format: "uuidv7"},
+		Database: Database{
+			Backend:         "memory",
+			ReplicaCooldown: 30 * time.Second,
+			AutoMigrate:     true,
+			MaxOpenConns:    16,
+			MaxIdleConns:    4,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			BusyTimeout:     5 * time.Second,
+		},
+		Persistence: Persistence{
+			Dir:              "data",
+			SnapshotInterval: 5 * time.Minute,
+		},
+		Webhooks: Webhooks{
+			MaxAttempts:    5,
+			InitialBackoff: time.Second,
+			MaxBackoff:     time.Minute,
+			Timeout:        10 * time.Second,
+		},
+		Events: Events{
+			Broker:          "kafka",
+			Source:          "/external-apis",
+			MaxDeliveries:   5,
+			RedeliveryDelay: time.Second,
+		},
+		Kafka: Kafka{
+			BatchTimeout: 10 * time.Millisecond,
+			Format:       "json",
+			Tombstones:   true,
+		},
+		SchemaRegistry: SchemaRegistry{Timeout: 5 * time.Second},
+		NATS:           NATS{AckWait: 30 * time.Second},
+		RabbitMQ: RabbitMQ{
+			Prefetch:        10,
+			ReconnectDelay:  5 * time.Second,
+			RedeliveryDelay: 5 * time.Second,
+		},
+		Storage: Storage{
+			Backend: "local",
+			Dir:     "media",
+			S3: S3{
+				Region:  "us-east-1",
+				Bucket:  "product-images",
+				Timeout: 30 * time.Second,
+			},
+		},
+		Search: Search{
+			Index:   "products",
+			Timeout: 5 * time.Second,
+		},
+		Downstream: Downstream{
+			CustomerURL:      "http://localhost:3002",
+			ProductURL:       "http://localhost:3001",
+			Timeout:          2 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Enrichment: Enrichment{
+			Fallback:       true,
+			MaxAttempts:    10,
+			InitialBackoff: 10 * time.Second,
+			MaxBackoff:     5 * time.Minute,
+			SweepInterval:  time.Minute,
+		},
+		Orders: Orders{
+			ReserveStock:    true,
+			ApplyPromotions: true,
+			SagaRetention:   1000,
+		},
+		Tax: Tax{
+			Provider: "none",
+			Timeout:  2 * time.Second,
+		},
+		Shipping: Shipping{Currency: money.DefaultCurrency},
+		Invoice: Invoice{
+			CompanyName: "External APIs",
+			Currency:    "USD",
+			Dir:         "documents",
+		},
+		Reports: Reports{
+			RefreshInterval: 5 * time.Minute,
+			Lookback:        7 * 24 * time.Hour,
+		},
+		Expand: Expand{
+			CustomerCacheTTL: 30 * time.Second,
+			CustomerOnError:  "fail",
+			ProductsCacheTTL: 30 * time.Second,
+			ProductsOnError:  "fail",
+		},
+		Phone: Phone{DefaultRegion: "US"},
+		Verification: Verification{
+			Enabled:        true,
+			TokenTTL:       24 * time.Hour,
+			ResendInterval: time.Minute,
+		},
+		Email: Email{
+			Backend: "log",
+			From:    "no-reply@localhost",
+			SMTP:    EmailSMTP{Port: "587"},
+		},
+		Catalog: Catalog{DefaultCurrency: money.DefaultCurrency},
+		Currency: Currency{
+			Provider:        "static",
+			Base:            money.DefaultCurrency,
+			ECBURL:          currency.DefaultECBURL,
+			Timeout:         5 * time.Second,
+			RefreshInterval: time.Hour,
+		},
+		Reservations: Reservations{
+			TTL:             15 * time.Minute,
+			MaxTTL:          time.Hour,
+			ReleaseInterval: 30 * time.Second,
+		},
+		Scheduling: Scheduling{ApplyInterval: 30 * time.Second},
+		Changes:    Changes{Retention: 10000},
+	}
+}
@@ -0,0 +1,360 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/phone"
+	"external-apis/internal/shared/quota"
+	"external-apis/internal/shared/rbac"
+	"github.com/go-playground/validator/v10"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable holding the path of the config
+// file. Services run on defaults and environment variables alone while it is
+// unset.
+const FileEnv = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load builds the configuration of a service. Settings start from defaults,
+// are overridden by the config file named by CONFIG_FILE, if any, and then
+// by environment variables. Empty environment variables are ignored, so they
+// cannot clear a setting. The result is validated before it is returned.
+//
+// The file is YAML (.yaml, .yml) or TOML (.toml) and nests settings by
+// section, e.g.
+//
+//	http:
+//	  port: 3002
+//	  read_header_timeout: 10s
+func Load(defaults Config) (Config, error) {
+	cfg := defaults
+
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := loadFile(path, &cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := applyEnv(reflect.ValueOf(&cfg).Elem()); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks that the settings are complete and consistent. Errors name
+// settings by their environment variable.
+func (c Config) Validate() error {
+	var problems []string
+
+	var validationErrors validator.ValidationErrors
+	if err := newValidator().Struct(c); errors.As(err, &validationErrors) {
+		for _, fe := range validationErrors {
+			problems = append(problems, describe(fe))
+		}
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := logger.ParseOverrides(c.Logging.Packages); err != nil {
+		problems = append(problems, "LOG_LEVELS: "+err.Error())
+	}
+	if _, err := quota.ParseRoutes(c.Quota.Routes); err != nil {
+		problems = append(problems, "QUOTA_ROUTES: "+err.Error())
+	}
+	if err := phone.ValidateRegion(c.Phone.DefaultRegion); err != nil {
+		problems = append(problems, "PHONE_DEFAULT_REGION: "+err.Error())
+	}
+	if c.Database.Backend == "sqlite" && c.Database.Path == "" {
+		problems = append(problems, "SQLITE_PATH is required when STORAGE_BACKEND is sqlite")
+	}
+	if c.Database.Backend == "sqlite" && c.Sandbox.Enabled {
+		problems = append(problems, "SANDBOX_MODE cannot be enabled when STORAGE_BACKEND is sqlite")
+	}
+	if len(c.Database.ReplicaPaths) > 0 && c.Database.Backend != "sqlite" {
+		problems = append(problems, "SQLITE_REPLICA_PATHS requires STORAGE_BACKEND sqlite")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		problems = append(problems, "DATABASE_MAX_IDLE_CONNS cannot exceed DATABASE_MAX_OPEN_CONNS")
+	}
+	if c.Persistence.Enabled && c.Database.Backend != "memory" {
+		problems = append(problems, "PERSISTENCE_ENABLED requires STORAGE_BACKEND memory")
+	}
+	if c.Persistence.Enabled && c.Sandbox.Enabled {
+		problems = append(problems, "SANDBOX_MODE cannot be enabled when PERSISTENCE_ENABLED is set")
+	}
+	if c.Storage.Backend == "s3" && c.Storage.S3.Bucket == "" {
+		problems = append(problems, "S3_BUCKET is required when IMAGE_STORAGE is s3")
+	}
+	if c.Email.Backend == "smtp" && c.Email.SMTP.Host == "" {
+		problems = append(problems, "SMTP_HOST is required when EMAIL_BACKEND is smtp")
+	}
+	if c.TLS.Enabled && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		problems = append(problems, "TLS_CERT_FILE and TLS_KEY_FILE are required when TLS_ENABLED is set")
+	}
+	if c.TLS.Enabled && c.TLS.ClientAuth != "none" && c.TLS.ClientCAFile == "" {
+		problems = append(problems, "TLS_CLIENT_CA_FILE is required when TLS_CLIENT_AUTH is "+c.TLS.ClientAuth)
+	}
+	if c.Tax.Provider == "api" && c.Tax.APIURL == "" {
+		problems = append(problems, "TAX_API_URL is required when TAX_PROVIDER is api")
+	}
+	if c.Currency.Provider == "api" && c.Currency.APIURL == "" {
+		problems = append(problems, "CURRENCY_API_URL is required when CURRENCY_PROVIDER is api")
+	}
+	if c.Audit.Enabled && c.Audit.Sink == "file" && c.Audit.File == "" {
+		problems = append(problems, "AUDIT_FILE is required when AUDIT_SINK is file")
+	}
+	if c.Audit.Enabled && c.Audit.Sink == "kafka" && (len(c.Kafka.Brokers) == 0 || c.Audit.Topic == "") {
+		problems = append(problems, "KAFKA_BROKERS and AUDIT_KAFKA_TOPIC are required when AUDIT_SINK is kafka")
+	}
+	if c.Kafka.Format != "json" && c.SchemaRegistry.URL == "" {
+		problems = append(problems, "SCHEMA_REGISTRY_URL is required when KAFKA_FORMAT is "+c.Kafka.Format)
+	}
+	if c.Auth.RSAPublicKey != "" && c.Auth.RSAPublicKeyFile != "" {
+		problems = append(problems, "JWT_RSA_PUBLIC_KEY and JWT_RSA_PUBLIC_KEY_FILE are mutually exclusive")
+	}
+	if _, err := rbac.ParseClaimRoles(c.RBAC.RoleMappings); err != nil {
+		problems = append(problems, "RBAC_ROLE_MAPPINGS: "+err.Error())
+	}
+	for _, principal := range c.RBAC.Admins {
+		if err := rbac.ValidatePrincipal(principal); err != nil {
+			problems = append(problems, "RBAC_ADMINS: "+err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// newValidator returns a validator reporting fields by their environment
+// variable and knowing the custom currency rule
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if env := field.Tag.Get("env"); env != "" {
+			return env
+		}
+		return field.Name
+	})
+	// The rule is static and valid, so registration cannot fail
+	_ = validate.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return money.IsValidCurrency(fl.Field().String())
+	})
+	return validate
+}
+
+// describe explains a failed rule in terms of the environment variable
+func describe(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " is required"
+	case "required_with":
+		return fe.Field() + " is required when " + envName(fe) + " is set"
+	case "numeric":
+		return fe.Field() + " must be a number"
+	case "url":
+		return fe.Field() + " must be a URL"
+	case "oneof":
+		return fe.Field() + " must be one of: " + fe.Param()
+	case "currency":
+		return fe.Field() + " must be a supported ISO 4217 currency code"
+	case "gt":
+		return fe.Field() + " must be greater than " + fe.Param()
+	case "gte":
+		return fe.Field() + " must be at least " + fe.Param()
+	case "gtefield":
+		return fe.Field() + " must be at least " + envName(fe)
+	default:
+		return fe.Field() + " failed the " + fe.Tag() + " rule"
+	}
+}
+
+// envName resolves the environment variable of the sibling field a
+// cross-field rule refers to
+func envName(fe validator.FieldError) string {
+	path := strings.Split(fe.StructNamespace(), ".")
+	typ := reflect.TypeOf(Config{})
+	for _, name := range path[1 : len(path)-1] {
+		field, ok := typ.FieldByName(name)
+		if !ok {
+			return fe.Param()
+		}
+		typ = field.Type
+	}
+	if field, ok := typ.FieldByName(fe.Param()); ok {
+		if env := field.Tag.Get("env"); env != "" {
+			return env
+		}
+	}
+	return fe.Param()
+}
+
+// loadFile applies the settings of a YAML or TOML config file
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("unsupported config file format %q: use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return applyFile(reflect.ValueOf(cfg).Elem(), values, "")
+}
+
+// applyFile copies the values of a parsed config file section into a struct,
+// rejecting keys the struct has no setting for
+func applyFile(section reflect.Value, values map[string]any, prefix string) error {
+	fields := map[string]reflect.Value{}
+	for i := 0; i < section.NumField(); i++ {
+		fields[section.Type().Field(i).Tag.Get("config")] = section.Field(i)
+	}
+
+	for key, value := range values {
+		name := prefix + key
+		field, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("unknown config key %q", name)
+		}
+
+		if field.Kind() == reflect.Struct {
+			nested, ok := value.(map[string]any)
+			if !ok {
+				return fmt.Errorf("config key %q must be a section", name)
+			}
+			if err := applyFile(field, nested, name+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := setValue(field, value); err != nil {
+			return fmt.Errorf("config key %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// setValue stores a value parsed from a config file in a setting
+func setValue(field reflect.Value, value any) error {
+	switch v := value.(type) {
+	case string:
+		return setString(field, v)
+	case []any:
+		if field.Kind() != reflect.Slice {
+			return errors.New("must not be a list")
+		}
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		field.Set(reflect.ValueOf(items))
+		return nil
+	case bool, int, int64, uint64, float64:
+		if field.Type() == durationType {
+			return errors.New(`must be a duration such as "30s"`)
+		}
+		return setString(field, fmt.Sprint(v))
+	default:
+		return fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+// applyEnv overrides the settings of a section with the environment
+// variables that are set
+func applyEnv(section reflect.Value) error {
+	for i := 0; i < section.NumField(); i++ {
+		field := section.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env := section.Type().Field(i).Tag.Get("env")
+		value := os.Getenv(env)
+		if env == "" || value == "" {
+			continue
+		}
+		if err := setString(field, value); err != nil {
+			return fmt.Errorf("%s: %w", env, err)
+		}
+	}
+	return nil
+}
+
+// setString parses a setting from its text form. Lists are comma-separated.
+func setString(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf(`%q is not a duration such as "30s"`, value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		field.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
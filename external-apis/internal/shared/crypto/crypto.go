@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefix marks values encrypted by a Keyring. The full format is
+// "enc:v1:<key id>:<base64 of nonce and ciphertext>".
+const prefix = "enc:v1:"
+
+// KeySize is the length of encryption and index keys in bytes (AES-256)
+const KeySize = 32
+
+var (
+	// ErrNoKeys is returned when a keyring is created without encryption keys
+	ErrNoKeys = errors.New("crypto: no encryption keys configured")
+	// ErrInvalidKey is returned for keys of the wrong size or with a malformed ID
+	ErrInvalidKey = errors.New("crypto: invalid key")
+	// ErrUnknownKey is returned when a value was encrypted with a key that is not in the keyring
+	ErrUnknownKey = errors.New("crypto: unknown key")
+	// ErrInvalidCiphertext is returned when an encrypted value is malformed or was tampered with
+	ErrInvalidCiphertext = errors.New("crypto: invalid ciphertext")
+)
+
+// Key is an encryption key together with the ID stored next to every value
+// encrypted with it
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring encrypts values with its primary key and decrypts values encrypted
+// with any of its keys, so keys can be rotated without re-encrypting
+// everything at once. It also derives blind indexes, which let encrypted
+// values be looked up by equality.
+type Keyring struct {
+	primary  string
+	ciphers  map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring creates a keyring whose primary key is the first of keys. The
+// index key is used for blind indexes only; changing it invalidates every
+// index derived with it.
+func NewKeyring(keys []Key, indexKey []byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("%w: index key must be %d bytes", ErrInvalidKey, KeySize)
+	}
+
+	keyring := &Keyring{
+		primary:  keys[0].ID,
+		ciphers:  make(map[string]cipher.AEAD, len(keys)),
+		indexKey: indexKey,
+	}
+	for _, key := range keys {
+		if key.ID == "" || strings.Contains(key.ID, ":") {
+			return nil, fmt.Errorf("%w: key ID %q must be non-empty and must not contain ':'", ErrInvalidKey, key.ID)
+		}
+		if len(key.Secret) != KeySize {
+			return nil, fmt.Errorf("%w: key %q must be %d bytes", ErrInvalidKey, key.ID, KeySize)
+		}
+		if _, duplicate := keyring.ciphers[key.ID]; duplicate {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", ErrInvalidKey, key.ID)
+		}
+
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		keyring.ciphers[key.ID] = aead
+	}
+
+	return keyring, nil
+}
+
+// ParseKeys parses a comma-separated list of "<id>:<base64 key>" pairs, the
+// primary key first
+func ParseKeys(spec string) ([]Key, error) {
+	var keys []Key
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: expected <id>:<base64 key>, got %q", ErrInvalidKey, entry)
+		}
+		secret, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %q is not valid base64", ErrInvalidKey, id)
+		}
+		keys = append(keys, Key{ID: id, Secret: secret})
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	return keys, nil
+}
+
+// Encrypt encrypts plaintext with the primary key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.ciphers[k.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.primary))
+	return prefix + k.primary + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt with any key of the keyring.
+// Values that are not encrypted are returned unchanged, so data written
+// before encryption was enabled stays readable.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", ErrInvalidCiphertext
+	}
+	aead, ok := k.ciphers[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether value is not encrypted with the primary key,
+// either because it is plaintext or because it uses an older key
+func (k *Keyring) NeedsRotation(value string) bool {
+	return !strings.HasPrefix(value, prefix+k.primary+":")
+}
+
+// BlindIndex derives a deterministic, non-reversible token for value. Equal
+// values have equal indexes, so an encrypted field can be looked up by the
+// index of the value searched for.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsEncrypted reports whether value was produced by a Keyring
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
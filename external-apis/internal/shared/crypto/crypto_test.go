@@ -0,0 +1,153 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(id string, fill byte) Key {
+	return Key{ID: id, Secret: bytes.Repeat([]byte{fill}, KeySize)}
+}
+
+func newTestKeyring(t *testing.T, keys ...Key) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(keys, bytes.Repeat([]byte{0xff}, KeySize))
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	// Arrange
+	keyring := newTestKeyring(t, testKey("k1", 1))
+
+	// Act
+	first, err := keyring.Encrypt("john.doe@example.com")
+	require.NoError(t, err)
+	second, err := keyring.Encrypt("john.doe@example.com")
+	require.NoError(t, err)
+	plaintext, err := keyring.Decrypt(first)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", plaintext)
+	assert.True(t, IsEncrypted(first))
+	assert.True(t, strings.HasPrefix(first, "enc:v1:k1:"))
+	assert.NotContains(t, first, "john.doe")
+	assert.NotEqual(t, first, second, "every encryption uses a fresh nonce")
+}
+
+func TestKeyring_Decrypt(t *testing.T) {
+	// Arrange
+	old := newTestKeyring(t, testKey("k1", 1))
+	rotated := newTestKeyring(t, testKey("k2", 2), testKey("k1", 1))
+	encrypted, err := old.Encrypt("+15550123")
+	require.NoError(t, err)
+
+	t.Run("Older key stays readable after rotation", func(t *testing.T) {
+		// Act
+		plaintext, err := rotated.Decrypt(encrypted)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "+15550123", plaintext)
+		assert.True(t, rotated.NeedsRotation(encrypted))
+		assert.False(t, old.NeedsRotation(encrypted))
+	})
+
+	t.Run("Plaintext is returned unchanged", func(t *testing.T) {
+		// Act
+		plaintext, err := rotated.Decrypt("+15550123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "+15550123", plaintext)
+		assert.True(t, rotated.NeedsRotation("+15550123"))
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		// Act
+		_, err := newTestKeyring(t, testKey("k3", 3)).Decrypt(encrypted)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("Tampered ciphertext", func(t *testing.T) {
+		// Arrange
+		tampered := []byte(encrypted)
+		i := len(tampered) - 5
+		if tampered[i] == 'A' {
+			tampered[i] = 'B'
+		} else {
+			tampered[i] = 'A'
+		}
+
+		// Act
+		_, err := old.Decrypt(string(tampered))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	// Arrange
+	keyring := newTestKeyring(t, testKey("k1", 1))
+	rotated := newTestKeyring(t, testKey("k2", 2), testKey("k1", 1))
+
+	// Act
+	index := keyring.BlindIndex("john.doe@example.com")
+
+	// Assert
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, rotated.BlindIndex("john.doe@example.com"), "rotating encryption keys keeps indexes")
+	assert.NotEqual(t, index, keyring.BlindIndex("jane.smith@example.com"))
+}
+
+func TestNewKeyring_InvalidKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []Key
+		err  error
+	}{
+		{name: "No keys", keys: nil, err: ErrNoKeys},
+		{name: "Short key", keys: []Key{{ID: "k1", Secret: []byte("short")}}, err: ErrInvalidKey},
+		{name: "ID with separator", keys: []Key{testKey("k:1", 1)}, err: ErrInvalidKey},
+		{name: "Duplicate ID", keys: []Key{testKey("k1", 1), testKey("k1", 2)}, err: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			keyring, err := NewKeyring(tt.keys, bytes.Repeat([]byte{0xff}, KeySize))
+
+			// Assert
+			assert.Nil(t, keyring)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	// Arrange
+	secret := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))
+
+	// Act
+	keys, err := ParseKeys(" k2:" + secret + ", k1:" + secret)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "k2", keys[0].ID)
+	assert.Equal(t, "k1", keys[1].ID)
+
+	_, err = ParseKeys("k1")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = ParseKeys("")
+	assert.ErrorIs(t, err, ErrNoKeys)
+}
@@ -0,0 +1,95 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/deadline"
+	"external-apis/internal/shared/money"
+)
+
+// API provides the rates of an external exchange rate API. It gets
+// <baseURL>/latest?base=<base>, authenticated with a bearer API key, and
+// expects the rates against base with the Unix time they were published:
+//
+//	{"base": "USD", "timestamp": 1704196800, "rates": {"EUR": 0.9127, "GBP": 0.7871}}
+//
+// Currencies money does not support are left out.
+type API struct {
+	baseURL    string
+	apiKey     string
+	base       string
+	httpClient *http.Client
+}
+
+// apiResponse is the body the exchange rate API answers with. Rates are
+// decoded as numbers to keep them exact.
+type apiResponse struct {
+	Base      string                 `json:"base"`
+	Timestamp int64                  `json:"timestamp"`
+	Rates     map[string]json.Number `json:"rates"`
+}
+
+// NewAPI creates a provider of the rates against base from the API at
+// baseURL, sending its requests through transport, or the default transport
+// if it is nil
+func NewAPI(baseURL, apiKey, base string, timeout time.Duration, transport http.RoundTripper) *API {
+	return &API{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		base:    base,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: deadline.NewTransport(transport),
+		},
+	}
+}
+
+// Rates fetches the latest rates
+func (a *API) Rates(ctx context.Context) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/latest?base="+url.QueryEscape(a.base), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var body apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: invalid response body: %v", ErrUnavailable, err)
+	}
+	if body.Base != a.base {
+		return nil, fmt.Errorf("%w: rates against %s, not %s", ErrUnavailable, body.Base, a.base)
+	}
+
+	rates := &Rates{Base: body.Base, Rates: make(map[string]*big.Rat, len(body.Rates)), AsOf: time.Unix(body.Timestamp, 0).UTC(), Source: "api"}
+	for currency, value := range body.Rates {
+		if !money.IsValidCurrency(currency) {
+			continue
+		}
+		rate, ok := new(big.Rat).SetString(value.String())
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: invalid rate %q for %s", ErrUnavailable, value, currency)
+		}
+		rates.Rates[currency] = rate
+	}
+	return rates, nil
+}
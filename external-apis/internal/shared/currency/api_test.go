@@ -0,0 +1,62 @@
+package currency
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_Rates(t *testing.T) {
+	// Arrange
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/latest", r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("base") {
+		case "USD":
+			w.Write([]byte(`{"base":"USD","timestamp":1791979200,"rates":{"EUR":0.9127,"GBP":0.7871,"XAU":0.0004}}`))
+		case "GBP":
+			w.Write([]byte(`{"base":"USD","timestamp":1791979200,"rates":{"EUR":0.9127}}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Latest rates", func(t *testing.T) {
+		// Act
+		rates, err := NewAPI(server.URL+"/", "secret", "USD", time.Second, nil).Rates(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "USD", rates.Base)
+		assert.Equal(t, time.Unix(1791979200, 0).UTC(), rates.AsOf)
+		assert.Equal(t, big.NewRat(9127, 10000), rates.Rates["EUR"])
+		assert.NotContains(t, rates.Rates, "XAU")
+		assert.Equal(t, "Bearer secret", authorization)
+	})
+
+	t.Run("Rates against another base", func(t *testing.T) {
+		// Act
+		_, err := NewAPI(server.URL, "secret", "GBP", time.Second, nil).Rates(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("Request refused", func(t *testing.T) {
+		// Act
+		_, err := NewAPI(server.URL, "", "EUR", time.Second, nil).Rates(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
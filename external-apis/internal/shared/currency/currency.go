@@ -0,0 +1,179 @@
+// Package currency converts amounts of money between currencies at the
+// exchange rates of an ExchangeRateProvider: a static table (Static), the
+// European Central Bank reference rates (ECB) or an external exchange rate
+// API (API).
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/money"
+)
+
+var log = logger.New("shared/currency")
+
+var (
+	// ErrUnavailable is returned when the exchange rates cannot be fetched
+	ErrUnavailable = errors.New("exchange rates unavailable")
+	// ErrNoRate is returned for currencies the exchange rates do not cover
+	ErrNoRate = errors.New("no exchange rate for currency")
+)
+
+// ExchangeRateProvider fetches the latest exchange rates. Errors wrap
+// ErrUnavailable.
+type ExchangeRateProvider interface {
+	Rates(ctx context.Context) (*Rates, error)
+}
+
+// Rates is a table of exchange rates against a base currency
+type Rates struct {
+	Base string
+	// Rates holds how many units of each currency one unit of Base buys
+	Rates map[string]*big.Rat
+	// AsOf is when the provider published the rates
+	AsOf time.Time
+	// Source names the provider, e.g. ecb
+	Source string
+}
+
+// Rate is the exchange rate a conversion used
+type Rate struct {
+	From  string
+	To    string
+	Value *big.Rat
+	AsOf  time.Time
+	// Source names the provider of the rate
+	Source string
+}
+
+// Rate returns the rate from one currency to another, crossed through the
+// base currency when neither is the base
+func (r *Rates) Rate(from, to string) (Rate, error) {
+	fromRate, err := r.against(from)
+	if err != nil {
+		return Rate{}, err
+	}
+	toRate, err := r.against(to)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{
+		From:   from,
+		To:     to,
+		Value:  new(big.Rat).Quo(toRate, fromRate),
+		AsOf:   r.AsOf,
+		Source: r.Source,
+	}, nil
+}
+
+// against returns how many units of currency one unit of the base buys
+func (r *Rates) against(currency string) (*big.Rat, error) {
+	if currency == r.Base {
+		return big.NewRat(1, 1), nil
+	}
+	rate, ok := r.Rates[currency]
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoRate, currency)
+	}
+	return rate, nil
+}
+
+// Convert converts an amount in the From currency, rounded half away from
+// zero to the minor unit of the To currency
+func (r Rate) Convert(amount money.Money) (money.Money, error) {
+	if amount.Currency != r.From {
+		return money.Money{}, money.ErrCurrencyMismatch
+	}
+	return money.Round(new(big.Rat).Mul(amount.Rat(), r.Value), r.To)
+}
+
+// Number returns the rate as a JSON number with up to six decimal places
+func (r Rate) Number() json.Number {
+	value := r.Value.FloatString(6)
+	value = strings.TrimRight(strings.TrimRight(value, "0"), ".")
+	return json.Number(value)
+}
+
+// Converter converts amounts at the rates of a provider, which it keeps for
+// a refresh interval. When a refresh fails, it keeps converting at the rates
+// it has, however old, and only fails while it has none.
+type Converter struct {
+	provider ExchangeRateProvider
+	refresh  time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	rates     *Rates
+	fetchedAt time.Time
+}
+
+// NewConverter creates a converter fetching the rates of provider at most
+// once per refresh interval
+func NewConverter(provider ExchangeRateProvider, refresh time.Duration) *Converter {
+	return &Converter{provider: provider, refresh: refresh, now: time.Now}
+}
+
+// Rate returns the rate from one currency to another. Currency codes must be
+// normalized.
+func (c *Converter) Rate(ctx context.Context, from, to string) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Value: big.NewRat(1, 1), AsOf: c.now(), Source: "identity"}, nil
+	}
+
+	rates, err := c.current(ctx)
+	if err != nil {
+		return Rate{}, err
+	}
+	return rates.Rate(from, to)
+}
+
+// Convert converts an amount into a currency, returning the rate it used
+func (c *Converter) Convert(ctx context.Context, amount money.Money, to string) (money.Money, Rate, error) {
+	rate, err := c.Rate(ctx, amount.Currency, to)
+	if err != nil {
+		return money.Money{}, Rate{}, err
+	}
+	converted, err := rate.Convert(amount)
+	if err != nil {
+		return money.Money{}, Rate{}, err
+	}
+	return converted, rate, nil
+}
+
+// current returns the rates, fetching them again once they are older than
+// the refresh interval
+func (c *Converter) current(ctx context.Context) (*Rates, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rates != nil && c.now().Sub(c.fetchedAt) < c.refresh {
+		return c.rates, nil
+	}
+
+	rates, err := c.provider.Rates(ctx)
+	if err != nil {
+		if c.rates == nil {
+			return nil, err
+		}
+		log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+			"as_of":  c.rates.AsOf,
+			"source": c.rates.Source,
+		}).Warn("Failed to refresh exchange rates, converting at the previous ones")
+		// Retry at the next refresh rather than on every conversion
+		c.fetchedAt = c.now()
+		return c.rates, nil
+	}
+
+	c.rates = rates
+	c.fetchedAt = c.now()
+	return rates, nil
+}
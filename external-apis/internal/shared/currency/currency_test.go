@@ -0,0 +1,189 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProvider returns its rates, or fails once failing is set
+type stubProvider struct {
+	rates   *Rates
+	failing bool
+	calls   int
+}
+
+func (p *stubProvider) Rates(ctx context.Context) (*Rates, error) {
+	p.calls++
+	if p.failing {
+		return nil, ErrUnavailable
+	}
+	return p.rates, nil
+}
+
+func euroRates() *Rates {
+	return &Rates{
+		Base:   "EUR",
+		Rates:  map[string]*big.Rat{"USD": big.NewRat(11, 10), "JPY": big.NewRat(160, 1)},
+		AsOf:   time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		Source: "ecb",
+	}
+}
+
+func TestRates_Rate(t *testing.T) {
+	rates := euroRates()
+
+	t.Run("From the base", func(t *testing.T) {
+		// Act
+		rate, err := rates.Rate("EUR", "USD")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "1.1", string(rate.Number()))
+		assert.Equal(t, rates.AsOf, rate.AsOf)
+		assert.Equal(t, "ecb", rate.Source)
+	})
+
+	t.Run("Cross rate", func(t *testing.T) {
+		// Act
+		rate, err := rates.Rate("USD", "JPY")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "145.454545", string(rate.Number()))
+	})
+
+	t.Run("Currency without a rate", func(t *testing.T) {
+		// Act
+		_, err := rates.Rate("USD", "GBP")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNoRate)
+	})
+}
+
+func TestRate_Convert(t *testing.T) {
+	rate, err := euroRates().Rate("USD", "EUR")
+	require.NoError(t, err)
+
+	t.Run("Rounded to the minor unit", func(t *testing.T) {
+		// Act
+		converted, err := rate.Convert(money.New(2999, "USD"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, money.New(2726, "EUR"), converted)
+	})
+
+	t.Run("Amount in another currency", func(t *testing.T) {
+		// Act
+		_, err := rate.Convert(money.New(2999, "GBP"))
+
+		// Assert
+		assert.ErrorIs(t, err, money.ErrCurrencyMismatch)
+	})
+}
+
+func TestConverter_Convert(t *testing.T) {
+	t.Run("Keep the rates for the refresh interval", func(t *testing.T) {
+		// Arrange
+		provider := &stubProvider{rates: euroRates()}
+		converter := NewConverter(provider, time.Hour)
+
+		// Act
+		converted, rate, err := converter.Convert(context.Background(), money.New(1000, "EUR"), "JPY")
+		_, _, again := converter.Convert(context.Background(), money.New(1000, "EUR"), "USD")
+
+		// Assert
+		require.NoError(t, err)
+		require.NoError(t, again)
+		assert.Equal(t, money.New(1600, "JPY"), converted)
+		assert.Equal(t, "160", string(rate.Number()))
+		assert.Equal(t, 1, provider.calls)
+	})
+
+	t.Run("Same currency", func(t *testing.T) {
+		// Arrange
+		provider := &stubProvider{failing: true}
+		converter := NewConverter(provider, time.Hour)
+
+		// Act
+		converted, rate, err := converter.Convert(context.Background(), money.New(2999, "USD"), "USD")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, money.New(2999, "USD"), converted)
+		assert.Equal(t, "1", string(rate.Number()))
+		assert.Zero(t, provider.calls)
+	})
+
+	t.Run("Convert at the previous rates when a refresh fails", func(t *testing.T) {
+		// Arrange
+		provider := &stubProvider{rates: euroRates()}
+		converter := NewConverter(provider, time.Hour)
+		now := time.Now()
+		converter.now = func() time.Time { return now }
+		_, _, err := converter.Convert(context.Background(), money.New(1000, "EUR"), "USD")
+		require.NoError(t, err)
+
+		provider.failing = true
+		now = now.Add(2 * time.Hour)
+
+		// Act
+		converted, _, err := converter.Convert(context.Background(), money.New(1000, "EUR"), "USD")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, money.New(1100, "USD"), converted)
+		assert.Equal(t, 2, provider.calls)
+	})
+
+	t.Run("Unavailable without rates", func(t *testing.T) {
+		// Arrange
+		converter := NewConverter(&stubProvider{failing: true}, time.Hour)
+
+		// Act
+		_, _, err := converter.Convert(context.Background(), money.New(1000, "EUR"), "USD")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrUnavailable))
+	})
+}
+
+func TestParseRates(t *testing.T) {
+	t.Run("Rates", func(t *testing.T) {
+		// Act
+		rates, err := ParseRates([]string{"eur=0.92", " JPY = 149.5"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, big.NewRat(23, 25), rates["EUR"])
+		assert.Equal(t, big.NewRat(299, 2), rates["JPY"])
+	})
+
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{name: "Missing rate", entry: "EUR"},
+		{name: "Unknown currency", entry: "XYZ=1"},
+		{name: "Not a number", entry: "EUR=abc"},
+		{name: "Zero rate", entry: "EUR=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := ParseRates([]string{tt.entry})
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
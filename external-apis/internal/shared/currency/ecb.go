@@ -0,0 +1,94 @@
+package currency
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"external-apis/internal/shared/deadline"
+	"external-apis/internal/shared/money"
+)
+
+// DefaultECBURL is the daily euro foreign exchange reference rates feed of
+// the European Central Bank
+const DefaultECBURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// ECB provides the euro reference rates the European Central Bank publishes
+// every working day. The rates are against EUR and as of the day they were
+// published; currencies money does not support are left out.
+type ECB struct {
+	url        string
+	httpClient *http.Client
+}
+
+// ecbEnvelope is the body of the ECB feed:
+//
+//	<Cube><Cube time="2024-01-02"><Cube currency="USD" rate="1.0956"/></Cube></Cube>
+type ecbEnvelope struct {
+	Cube struct {
+		Day struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// NewECB creates a provider fetching the feed at url, sending its requests
+// through transport, or the default transport if it is nil
+func NewECB(url string, timeout time.Duration, transport http.RoundTripper) *ECB {
+	return &ECB{
+		url: url,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: deadline.NewTransport(transport),
+		},
+	}
+}
+
+// Rates fetches the latest reference rates
+func (e *ECB) Rates(ctx context.Context) (*Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, e.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("%w: invalid feed: %v", ErrUnavailable, err)
+	}
+	day := envelope.Cube.Day
+	asOf, err := time.Parse(time.DateOnly, day.Time)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid feed date %q", ErrUnavailable, day.Time)
+	}
+
+	rates := &Rates{Base: "EUR", Rates: make(map[string]*big.Rat, len(day.Rates)), AsOf: asOf, Source: "ecb"}
+	for _, entry := range day.Rates {
+		if !money.IsValidCurrency(entry.Currency) {
+			continue
+		}
+		rate, ok := new(big.Rat).SetString(entry.Rate)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: invalid rate %q for %s", ErrUnavailable, entry.Rate, entry.Currency)
+		}
+		rates.Rates[entry.Currency] = rate
+	}
+	return rates, nil
+}
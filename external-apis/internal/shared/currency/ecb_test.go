@@ -0,0 +1,75 @@
+package currency
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ecbFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2026-10-14">
+			<Cube currency="USD" rate="1.0956"/>
+			<Cube currency="JPY" rate="160.25"/>
+			<Cube currency="RON" rate="4.9725"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestECB_Rates(t *testing.T) {
+	t.Run("Reference rates", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(ecbFeed))
+		}))
+		defer server.Close()
+
+		// Act
+		rates, err := NewECB(server.URL, time.Second, nil).Rates(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", rates.Base)
+		assert.Equal(t, "ecb", rates.Source)
+		assert.Equal(t, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC), rates.AsOf)
+		assert.Equal(t, big.NewRat(10956, 10000), rates.Rates["USD"])
+		assert.Len(t, rates.Rates, 2, "RON is not supported")
+	})
+
+	t.Run("Feed unavailable", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		// Act
+		_, err := NewECB(server.URL, time.Second, nil).Rates(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("Invalid feed", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<html>maintenance</html>`))
+		}))
+		defer server.Close()
+
+		// Act
+		_, err := NewECB(server.URL, time.Second, nil).Rates(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
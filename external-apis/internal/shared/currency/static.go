@@ -0,0 +1,48 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/money"
+)
+
+// Static provides a fixed table of exchange rates, e.g. from configuration
+type Static struct {
+	rates *Rates
+}
+
+// NewStatic creates a provider of rates against base, as of now
+func NewStatic(base string, rates map[string]*big.Rat) *Static {
+	return &Static{rates: &Rates{Base: base, Rates: rates, AsOf: time.Now().UTC(), Source: "static"}}
+}
+
+// ParseRates parses "<currency>=<rate>" entries, such as "EUR=0.92", into
+// the rates of a Static provider
+func ParseRates(entries []string) (map[string]*big.Rat, error) {
+	rates := make(map[string]*big.Rat, len(entries))
+	for _, entry := range entries {
+		code, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q, use currency=rate", entry)
+		}
+		currency, err := money.NormalizeCurrency(code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate %q: %w", entry, err)
+		}
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %q: the rate must be a positive number", entry)
+		}
+		rates[currency] = rate
+	}
+	return rates, nil
+}
+
+// Rates returns the table
+func (s *Static) Rates(ctx context.Context) (*Rates, error) {
+	return s.rates, nil
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/logger"
+)
+
+// staleReadsKey is the context key marking calls that tolerate stale reads
+type staleReadsKey struct{}
+
+// WithStaleReads marks the calls made with ctx as tolerating reads from a
+// replica that lags behind the primary
+func WithStaleReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, staleReadsKey{}, true)
+}
+
+// StaleReadsAllowed reports whether ctx was marked by WithStaleReads
+func StaleReadsAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(staleReadsKey{}).(bool)
+	return allowed
+}
+
+// Cluster is a primary database, which takes the writes, and its read
+// replicas. Reads that tolerate staleness are spread over the replicas; a
+// replica whose read fails is skipped for a cooldown and the read is
+// retried on the primary, so reads keep working while replicas are down.
+type Cluster struct {
+	primary  *sql.DB
+	replicas []*replica
+	cooldown time.Duration
+	next     atomic.Uint64
+}
+
+// replica is a read replica of a cluster
+type replica struct {
+	db   *sql.DB
+	name string
+	// downUntil is when, in Unix nanoseconds, the replica is tried again
+	// after a failed read
+	downUntil atomic.Int64
+}
+
+// NewCluster creates a cluster of primary and replicas, skipping a replica
+// for cooldown after it fails. Without replicas every read goes to primary.
+func NewCluster(primary *sql.DB, replicas []*sql.DB, cooldown time.Duration) *Cluster {
+	c := &Cluster{primary: primary, cooldown: cooldown}
+	for i, db := range replicas {
+		c.replicas = append(c.replicas, &replica{db: db, name: fmt.Sprintf("replica-%d", i+1)})
+	}
+	return c
+}
+
+// Primary returns the database that takes the writes
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+// Read runs fn on the next replica that is up when ctx tolerates stale
+// reads, and on the primary otherwise. When fn fails on the replica for a
+// reason other than the data it read, such as a missing entity, it runs
+// again on the primary.
+func (c *Cluster) Read(ctx context.Context, fn func(q Querier) error) error {
+	if !StaleReadsAllowed(ctx) {
+		return fn(c.primary)
+	}
+	replica := c.pick()
+	if replica == nil {
+		return fn(c.primary)
+	}
+
+	err := fn(replica.db)
+	if !failed(ctx, err) {
+		return err
+	}
+	replica.downUntil.Store(time.Now().Add(c.cooldown).UnixNano())
+	log.Ctx(ctx).WithError(err).WithFields(logger.Fields{
+		"replica":  replica.name,
+		"cooldown": c.cooldown.String(),
+	}).Warn("Read replica failed; reading from the primary")
+	return fn(c.primary)
+}
+
+// pick returns the next replica in turn that is up, or nil if there is none
+func (c *Cluster) pick() *replica {
+	now := time.Now().UnixNano()
+	for range c.replicas {
+		replica := c.replicas[(c.next.Add(1)-1)%uint64(len(c.replicas))]
+		if replica.downUntil.Load() <= now {
+			return replica
+		}
+	}
+	return nil
+}
+
+// failed reports whether err is a failure of the database a read ran on
+// rather than an outcome of the read, like a missing row, or of ctx ending
+func failed(ctx context.Context, err error) bool {
+	var appErr *apperror.Error
+	return err != nil && ctx.Err() == nil && !errors.Is(err, sql.ErrNoRows) && !errors.As(err, &appErr)
+}
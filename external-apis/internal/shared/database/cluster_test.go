@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestReplica creates a database holding one item with name and opens it
+// read-only as a replica
+func openTestReplica(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name+".db")
+	db, err := OpenSQLite(path, DefaultOptions())
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE items (name TEXT)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO items (name) VALUES (?)`, name)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	replica, err := OpenSQLiteReplica(path, DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { replica.Close() })
+	return replica
+}
+
+// readName reads the name of the item of the database c routes a read to
+func readName(t *testing.T, ctx context.Context, c *Cluster) string {
+	t.Helper()
+	var name string
+	require.NoError(t, c.Read(ctx, func(q Querier) error {
+		return q.QueryRowContext(ctx, `SELECT name FROM items`).Scan(&name)
+	}))
+	return name
+}
+
+func TestCluster_Read(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	stale := WithStaleReads(ctx)
+	primary := openTestDB(t)
+	_, err := primary.Exec(`CREATE TABLE items (name TEXT)`)
+	require.NoError(t, err)
+	_, err = primary.Exec(`INSERT INTO items (name) VALUES ('primary')`)
+	require.NoError(t, err)
+
+	t.Run("Read fresh data from the primary", func(t *testing.T) {
+		// Arrange
+		c := NewCluster(primary, []*sql.DB{openTestReplica(t, "replica")}, time.Minute)
+
+		// Act & Assert
+		assert.Equal(t, "primary", readName(t, ctx, c))
+	})
+
+	t.Run("Spread stale reads over the replicas", func(t *testing.T) {
+		// Arrange
+		c := NewCluster(primary, []*sql.DB{openTestReplica(t, "replica-a"), openTestReplica(t, "replica-b")}, time.Minute)
+
+		// Act
+		names := []string{readName(t, stale, c), readName(t, stale, c), readName(t, stale, c)}
+
+		// Assert
+		assert.Equal(t, []string{"replica-a", "replica-b", "replica-a"}, names)
+	})
+
+	t.Run("Read from the primary without replicas", func(t *testing.T) {
+		// Arrange
+		c := NewCluster(primary, nil, time.Minute)
+
+		// Act & Assert
+		assert.Equal(t, "primary", readName(t, stale, c))
+	})
+
+	t.Run("Fall back to the primary while a replica is down", func(t *testing.T) {
+		// Arrange
+		down, err := OpenSQLiteReplica(filepath.Join(t.TempDir(), "missing.db"), DefaultOptions())
+		require.NoError(t, err)
+		defer down.Close()
+		c := NewCluster(primary, []*sql.DB{down, openTestReplica(t, "replica")}, time.Minute)
+
+		// Act
+		names := []string{readName(t, stale, c), readName(t, stale, c), readName(t, stale, c)}
+
+		// Assert
+		assert.Equal(t, []string{"primary", "replica", "replica"}, names)
+	})
+
+	t.Run("Keep the outcome of a read", func(t *testing.T) {
+		// Arrange
+		c := NewCluster(primary, []*sql.DB{openTestReplica(t, "replica")}, time.Minute)
+		reads := 0
+
+		// Act
+		err := c.Read(stale, func(q Querier) error {
+			reads++
+			return q.QueryRowContext(stale, `SELECT name FROM items WHERE name = 'missing'`).Scan(new(string))
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Equal(t, 1, reads)
+	})
+}
+
+func TestOpenSQLiteReplica(t *testing.T) {
+	// Arrange
+	replica := openTestReplica(t, "replica")
+
+	// Act
+	_, err := replica.Exec(`INSERT INTO items (name) VALUES ('written')`)
+
+	// Assert
+	assert.Error(t, err)
+}
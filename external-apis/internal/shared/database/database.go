@@ -0,0 +1,150 @@
+// Package database opens the SQL databases the repositories of a service
+// can be stored in. Their schemas are managed by package migrations.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	_ "modernc.org/sqlite"
+)
+
+var log = logger.New("shared/database")
+
+// Storage backends of the repositories of a service
+const (
+	// BackendMemory keeps the data in memory; it is lost on restart
+	BackendMemory = "memory"
+	// BackendSQLite keeps the data in an embedded SQLite database file
+	BackendSQLite = "sqlite"
+)
+
+// TimeFormat is how times are stored in text columns: UTC with a fixed
+// number of fractional digits, so they sort as text in time order
+const TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// Options configures the connection pool of a database
+type Options struct {
+	// MaxOpenConns caps the connections open at once; 0 means no limit
+	MaxOpenConns int
+	// MaxIdleConns is how many unused connections are kept for reuse
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections once they are this old; 0 keeps them
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections unused for this long; 0 keeps them
+	ConnMaxIdleTime time.Duration
+	// BusyTimeout is how long a statement waits for the write lock of
+	// another connection before failing
+	BusyTimeout time.Duration
+}
+
+// DefaultOptions returns the default pool options
+func DefaultOptions() Options {
+	return Options{
+		MaxOpenConns:    16,
+		MaxIdleConns:    4,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+		BusyTimeout:     5 * time.Second,
+	}
+}
+
+// Querier runs statements on a database or in one of its transactions
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// OpenSQLite opens the SQLite database file at path, creating it and its
+// directory if needed. The database is journaled with a write-ahead log so
+// reads do not wait for writes, and transactions take the write lock when
+// they begin, so those reading before they write cannot deadlock. Its
+// connection pool is sized by opts.
+func OpenSQLite(path string, opts Options) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_txlock=immediate&_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)",
+		path, opts.BusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	configure(db, opts)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenSQLiteReplica opens the SQLite database file at path, a read replica
+// of a primary kept up to date by replication tooling, in read-only mode.
+// Unlike OpenSQLite it does not connect yet, so a replica that is missing
+// at startup does not keep the service from starting.
+func OpenSQLiteReplica(path string, opts Options) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?mode=ro&_pragma=busy_timeout(%d)&_pragma=query_only(1)",
+		path, opts.BusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	configure(db, opts)
+	return db, nil
+}
+
+// configure sizes the connection pool of db
+func configure(db *sql.DB, opts Options) {
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+}
+
+// Transaction runs fn in a transaction of db, committing it if fn returns
+// nil and ctx is not done, and rolling it back otherwise
+func Transaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	// Do not commit writes the caller has given up on
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FormatTime formats a time for a text column
+func FormatTime(t time.Time) string {
+	return t.UTC().Format(TimeFormat)
+}
+
+// FormatNullTime formats an optional time for a text column, NULL when nil
+func FormatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: FormatTime(*t), Valid: true}
+}
+
+// IsUniqueViolation reports whether err is the violation of a unique
+// constraint or primary key
+func IsUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
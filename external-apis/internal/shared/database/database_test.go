@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "data", "test.db"), DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestOpenSQLite(t *testing.T) {
+	// Arrange
+	opts := DefaultOptions()
+	opts.MaxOpenConns = 3
+
+	// Act
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"), opts)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Assert
+	assert.Equal(t, 3, db.Stats().MaxOpenConnections)
+	var busyTimeout int
+	require.NoError(t, db.QueryRow(`PRAGMA busy_timeout`).Scan(&busyTimeout))
+	assert.Equal(t, int(opts.BusyTimeout.Milliseconds()), busyTimeout)
+}
+
+func TestTransaction(t *testing.T) {
+	// Arrange
+	db := openTestDB(t)
+	_, err := db.Exec(`CREATE TABLE items (id TEXT PRIMARY KEY)`)
+	require.NoError(t, err)
+	insert := func(id string) func(tx *sql.Tx) error {
+		return func(tx *sql.Tx) error {
+			_, err := tx.Exec(`INSERT INTO items (id) VALUES (?)`, id)
+			return err
+		}
+	}
+	count := func() int {
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&count))
+		return count
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		// Act
+		err := Transaction(context.Background(), db, insert("item-1"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("Roll back on error", func(t *testing.T) {
+		// Arrange
+		failure := errors.New("failed")
+
+		// Act
+		err := Transaction(context.Background(), db, func(tx *sql.Tx) error {
+			if err := insert("item-2")(tx); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, failure)
+		assert.Equal(t, 1, count())
+	})
+
+	t.Run("Report unique violations", func(t *testing.T) {
+		// Act
+		err := Transaction(context.Background(), db, insert("item-1"))
+
+		// Assert
+		assert.True(t, IsUniqueViolation(err))
+		assert.False(t, IsUniqueViolation(errors.New("failed")))
+	})
+}
+
+func TestFormatTime(t *testing.T) {
+	// Arrange
+	earlier := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	later := earlier.Add(500 * time.Millisecond)
+
+	// Act
+	formatted := FormatTime(earlier)
+
+	// Assert
+	assert.Equal(t, "2024-01-01T11:00:00.000000Z", formatted)
+	assert.Less(t, formatted, FormatTime(later), "formatted times sort in time order")
+	assert.False(t, FormatNullTime(nil).Valid)
+}
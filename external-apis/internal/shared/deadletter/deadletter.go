@@ -0,0 +1,218 @@
+// Package deadletter parks events whose delivery failed for good, so they can
+// be inspected and replayed instead of being lost.
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"github.com/google/uuid"
+)
+
+var log = logger.New("shared/deadletter")
+
+// Sources of parked events
+const (
+	SourceWebhook  = "webhook"
+	SourceKafka    = "kafka"
+	SourceNATS     = "nats"
+	SourceRabbitMQ = "rabbitmq"
+)
+
+var (
+	// ErrEntryNotFound is returned when no parked event has the given ID
+	ErrEntryNotFound = errors.New("dead letter not found")
+	// ErrNoReplayer is returned when events of a source cannot be replayed
+	ErrNoReplayer = errors.New("no replayer registered for dead letter source")
+	// ErrNotReplayable is wrapped by replayers when an event can no longer be
+	// delivered, e.g. because its destination was removed
+	ErrNotReplayable = errors.New("dead letter can no longer be replayed")
+)
+
+// Entry is an event parked after its delivery failed
+type Entry struct {
+	ID string `json:"id"`
+	// Source is the delivery mechanism that gave up, e.g. webhook or kafka
+	Source string `json:"source"`
+	// Destination is where the event was going: a webhook subscription ID, a
+	// Kafka topic, a NATS subject or the RabbitMQ queue it was consumed from
+	Destination string `json:"destination"`
+	EventID     string `json:"eventId"`
+	EventType   string `json:"eventType"`
+	Subject     string `json:"subject,omitempty"`
+	// Event is the encoded event exactly as it was to be delivered
+	Event    json.RawMessage `json:"event"`
+	Error    string          `json:"error"`
+	Attempts int             `json:"attempts"`
+	FailedAt time.Time       `json:"failedAt"`
+}
+
+// Filter selects parked events; empty fields match everything
+type Filter struct {
+	Source    string
+	EventType string
+}
+
+// Matches checks if the entry passes the filter
+func (f Filter) Matches(entry *Entry) bool {
+	return (f.Source == "" || entry.Source == f.Source) &&
+		(f.EventType == "" || entry.EventType == f.EventType)
+}
+
+// Replayer sends a parked event back through its delivery mechanism
+type Replayer interface {
+	Replay(ctx context.Context, entry *Entry) error
+}
+
+// ReplayFunc adapts a function to Replayer
+type ReplayFunc func(ctx context.Context, entry *Entry) error
+
+// Replay implements Replayer
+func (f ReplayFunc) Replay(ctx context.Context, entry *Entry) error {
+	return f(ctx, entry)
+}
+
+// ReplayResult reports the outcome of replaying several parked events
+type ReplayResult struct {
+	Replayed int `json:"replayed"`
+	// Failed holds the error of every event that stayed parked, by entry ID
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// Queue parks failed events in a Store and replays them through the
+// replayer registered for their source
+type Queue struct {
+	store     Store
+	replayers map[string]Replayer
+	mutex     sync.RWMutex
+	now       func() time.Time
+}
+
+// NewQueue creates a dead letter queue backed by the given store
+func NewQueue(store Store) *Queue {
+	return &Queue{
+		store:     store,
+		replayers: make(map[string]Replayer),
+		now:       time.Now,
+	}
+}
+
+// Register sets the replayer of a source
+func (q *Queue) Register(source string, replayer Replayer) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.replayers[source] = replayer
+}
+
+// Park stores a failed event. Like publishing, parking never fails the
+// caller; a store error is logged with the event so it can be recovered.
+func (q *Queue) Park(entry Entry) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.FailedAt.IsZero() {
+		entry.FailedAt = q.now().UTC()
+	}
+
+	fields := logger.Fields{
+		"dead_letter_id": entry.ID,
+		"source":         entry.Source,
+		"destination":    entry.Destination,
+		"event_id":       entry.EventID,
+		"event_type":     entry.EventType,
+	}
+
+	if err := q.store.Add(&entry); err != nil {
+		log.WithError(err).WithFields(fields).WithField("event", string(entry.Event)).Error("Failed to park event, event lost")
+		return
+	}
+
+	log.WithFields(fields).WithField("error", entry.Error).Warn("Parked undeliverable event")
+}
+
+// List returns the parked events matching the filter, newest first
+func (q *Queue) List(filter Filter) ([]*Entry, error) {
+	entries, err := q.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make([]*Entry, 0, len(entries))
+	for _, entry := range entries {
+		if filter.Matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	return matching, nil
+}
+
+// Get returns a parked event by ID
+func (q *Queue) Get(id string) (*Entry, error) {
+	return q.store.Get(id)
+}
+
+// Replay hands a parked event back to its source and removes it once the
+// source accepted it. If delivery fails again the event is parked anew.
+func (q *Queue) Replay(ctx context.Context, id string) error {
+	entry, err := q.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.RLock()
+	replayer, exists := q.replayers[entry.Source]
+	q.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNoReplayer, entry.Source)
+	}
+
+	if err := replayer.Replay(ctx, entry); err != nil {
+		return fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	if err := q.store.Delete(id); err != nil && !errors.Is(err, ErrEntryNotFound) {
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"dead_letter_id": entry.ID,
+		"source":         entry.Source,
+		"event_id":       entry.EventID,
+	}).Info("Replayed dead letter")
+	return nil
+}
+
+// ReplayAll replays every parked event matching the filter, oldest first so
+// consumers see events in their original order. Events that cannot be
+// replayed stay parked and are reported.
+func (q *Queue) ReplayAll(ctx context.Context, filter Filter) (ReplayResult, error) {
+	entries, err := q.List(filter)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+
+	result := ReplayResult{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := q.Replay(ctx, entries[i].ID); err != nil {
+			if result.Failed == nil {
+				result.Failed = make(map[string]string)
+			}
+			result.Failed[entries[i].ID] = err.Error()
+			continue
+		}
+		result.Replayed++
+	}
+
+	return result, nil
+}
+
+// Discard removes a parked event without replaying it
+func (q *Queue) Discard(id string) error {
+	return q.store.Delete(id)
+}
@@ -0,0 +1,204 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingReplayer records the entries replayed through it
+type recordingReplayer struct {
+	replayed []string
+	err      error
+}
+
+func (r *recordingReplayer) Replay(ctx context.Context, entry *Entry) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.replayed = append(r.replayed, entry.EventID)
+	return nil
+}
+
+// parkAt parks an event that failed at the given time and returns its entry ID
+func parkAt(t *testing.T, queue *Queue, source, eventID string, failedAt time.Time) string {
+	queue.Park(Entry{
+		Source:    source,
+		EventID:   eventID,
+		EventType: "product.updated",
+		Event:     json.RawMessage(`{"id":"` + eventID + `"}`),
+		Error:     "subscriber responded with status 503",
+		FailedAt:  failedAt,
+	})
+
+	entries, err := queue.List(Filter{})
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if entry.EventID == eventID {
+			return entry.ID
+		}
+	}
+	t.Fatalf("event %s was not parked", eventID)
+	return ""
+}
+
+func TestQueue_Park(t *testing.T) {
+	// Arrange
+	queue := NewQueue(NewMemoryStore())
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	parkAt(t, queue, SourceWebhook, "event-1", now)
+	parkAt(t, queue, SourceKafka, "event-2", now.Add(time.Minute))
+	parkAt(t, queue, SourceWebhook, "event-3", now.Add(2*time.Minute))
+
+	// Assert
+	all, err := queue.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "event-3", all[0].EventID)
+	assert.Equal(t, "event-1", all[2].EventID)
+
+	webhooks, err := queue.List(Filter{Source: SourceWebhook})
+	require.NoError(t, err)
+	assert.Len(t, webhooks, 2)
+
+	entry, err := queue.Get(all[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, SourceKafka, entry.Source)
+	assert.JSONEq(t, `{"id":"event-2"}`, string(entry.Event))
+}
+
+func TestQueue_Replay(t *testing.T) {
+	t.Run("Remove replayed events", func(t *testing.T) {
+		// Arrange
+		queue := NewQueue(NewMemoryStore())
+		replayer := &recordingReplayer{}
+		queue.Register(SourceWebhook, replayer)
+		id := parkAt(t, queue, SourceWebhook, "event-1", time.Now())
+
+		// Act
+		err := queue.Replay(context.Background(), id)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"event-1"}, replayer.replayed)
+		_, err = queue.Get(id)
+		assert.ErrorIs(t, err, ErrEntryNotFound)
+	})
+
+	t.Run("Keep events that fail to replay", func(t *testing.T) {
+		// Arrange
+		queue := NewQueue(NewMemoryStore())
+		queue.Register(SourceWebhook, &recordingReplayer{err: ErrNotReplayable})
+		id := parkAt(t, queue, SourceWebhook, "event-1", time.Now())
+
+		// Act
+		err := queue.Replay(context.Background(), id)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNotReplayable)
+		_, err = queue.Get(id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Source without replayer", func(t *testing.T) {
+		// Arrange
+		queue := NewQueue(NewMemoryStore())
+		id := parkAt(t, queue, SourceKafka, "event-1", time.Now())
+
+		// Act
+		err := queue.Replay(context.Background(), id)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNoReplayer)
+	})
+
+	t.Run("Unknown entry", func(t *testing.T) {
+		// Act
+		err := NewQueue(NewMemoryStore()).Replay(context.Background(), "missing")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrEntryNotFound)
+	})
+}
+
+func TestQueue_ReplayAll(t *testing.T) {
+	// Arrange
+	queue := NewQueue(NewMemoryStore())
+	webhooks := &recordingReplayer{}
+	queue.Register(SourceWebhook, webhooks)
+	queue.Register(SourceKafka, &recordingReplayer{err: errors.New("broker unavailable")})
+
+	now := time.Now()
+	parkAt(t, queue, SourceWebhook, "event-2", now.Add(time.Second))
+	parkAt(t, queue, SourceWebhook, "event-1", now)
+	kafkaID := parkAt(t, queue, SourceKafka, "event-3", now)
+
+	// Act
+	result, err := queue.ReplayAll(context.Background(), Filter{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Replayed)
+	assert.Equal(t, []string{"event-1", "event-2"}, webhooks.replayed)
+	require.Contains(t, result.Failed, kafkaID)
+	assert.Contains(t, result.Failed[kafkaID], "broker unavailable")
+
+	remaining, err := queue.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, kafkaID, remaining[0].ID)
+}
+
+func TestQueue_Discard(t *testing.T) {
+	// Arrange
+	queue := NewQueue(NewMemoryStore())
+	id := parkAt(t, queue, SourceWebhook, "event-1", time.Now())
+
+	// Act
+	err := queue.Discard(id)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, queue.Discard(id), ErrEntryNotFound)
+}
+
+func TestFileStore(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "deadletters.json")
+	store, err := NewFileStore(path)
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, store.Add(&Entry{ID: "first", EventID: "event-1", FailedAt: time.Now()}))
+	require.NoError(t, store.Add(&Entry{ID: "second", EventID: "event-2", FailedAt: time.Now()}))
+	require.NoError(t, store.Delete("first"))
+
+	// Assert
+	reloaded, err := NewFileStore(path)
+	require.NoError(t, err)
+	entries, err := reloaded.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "event-2", entries[0].EventID)
+
+	t.Run("Remove the file once empty", func(t *testing.T) {
+		// Act
+		require.NoError(t, reloaded.Delete("second"))
+
+		// Assert
+		empty, err := NewFileStore(path)
+		require.NoError(t, err)
+		entries, err := empty.List()
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.NoFileExists(t, path)
+	})
+}
@@ -0,0 +1,119 @@
+package deadletter
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the admin endpoints for inspecting and replaying
+// parked events
+type AdminHandler struct {
+	queue *Queue
+}
+
+// NewAdminHandler creates a new dead letter admin handler
+func NewAdminHandler(queue *Queue) *AdminHandler {
+	return &AdminHandler{queue: queue}
+}
+
+// RegisterRoutes registers the dead letter admin routes
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	deadLetters := router.Group("/dead-letters")
+	{
+		deadLetters.GET("", h.ListEntries)
+		deadLetters.POST("/replay", h.ReplayEntries)
+		deadLetters.GET("/:id", h.GetEntry)
+		deadLetters.POST("/:id/replay", h.ReplayEntry)
+		deadLetters.DELETE("/:id", h.DiscardEntry)
+	}
+}
+
+// ListEntries returns a page of parked events, newest first, optionally
+// filtered by source and event type
+func (h *AdminHandler) ListEntries(c *gin.Context) {
+	page, err := pagination.FromQuery(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	entries, err := h.queue.List(filterFromQuery(c))
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to list dead letters")
+		response.InternalServerError(c, "Failed to list dead letters")
+		return
+	}
+
+	start, end := page.Bounds(len(entries))
+	response.Paged(c, entries[start:end], page.Meta(len(entries)))
+}
+
+// GetEntry returns a parked event with its failure
+func (h *AdminHandler) GetEntry(c *gin.Context) {
+	entry, err := h.queue.Get(c.Param("id"))
+	if err != nil {
+		h.entryError(c, err)
+		return
+	}
+
+	response.OK(c, entry)
+}
+
+// ReplayEntry hands a parked event back to its delivery mechanism
+func (h *AdminHandler) ReplayEntry(c *gin.Context) {
+	if err := h.queue.Replay(c.Request.Context(), c.Param("id")); err != nil {
+		h.entryError(c, err)
+		return
+	}
+
+	response.Message(c, http.StatusAccepted, "Dead letter replayed")
+}
+
+// ReplayEntries replays every parked event matching the source and event
+// type filters, oldest first
+func (h *AdminHandler) ReplayEntries(c *gin.Context) {
+	result, err := h.queue.ReplayAll(c.Request.Context(), filterFromQuery(c))
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to replay dead letters")
+		response.InternalServerError(c, "Failed to replay dead letters")
+		return
+	}
+
+	response.Accepted(c, result)
+}
+
+// DiscardEntry removes a parked event without replaying it
+func (h *AdminHandler) DiscardEntry(c *gin.Context) {
+	if err := h.queue.Discard(c.Param("id")); err != nil {
+		h.entryError(c, err)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithField("dead_letter_id", c.Param("id")).Info("Discarded dead letter")
+	c.Status(http.StatusNoContent)
+}
+
+// entryError maps dead letter errors to responses
+func (h *AdminHandler) entryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrEntryNotFound):
+		response.NotFound(c, "Dead letter not found")
+	case errors.Is(err, ErrNoReplayer), errors.Is(err, ErrNotReplayable):
+		response.Conflict(c, err.Error())
+	default:
+		log.Ctx(c.Request.Context()).WithError(err).WithField("dead_letter_id", c.Param("id")).Error("Failed to process dead letter")
+		response.BadGateway(c, err.Error())
+	}
+}
+
+// filterFromQuery reads the source and eventType query parameters
+func filterFromQuery(c *gin.Context) Filter {
+	return Filter{
+		Source:    c.Query("source"),
+		EventType: c.Query("eventType"),
+	}
+}
@@ -0,0 +1,179 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists parked events
+type Store interface {
+	Add(entry *Entry) error
+	Get(id string) (*Entry, error)
+	List() ([]*Entry, error)
+	Delete(id string) error
+}
+
+// MemoryStore keeps parked events in memory
+type MemoryStore struct {
+	entries map[string]*Entry
+	mutex   sync.RWMutex
+}
+
+// NewMemoryStore creates a new in-memory dead letter store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*Entry),
+	}
+}
+
+// Add stores a parked event
+func (s *MemoryStore) Add(entry *Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	copied := *entry
+	s.entries[entry.ID] = &copied
+	return nil
+}
+
+// Get returns the parked event with the given ID
+func (s *MemoryStore) Get(id string) (*Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, exists := s.entries[id]
+	if !exists {
+		return nil, ErrEntryNotFound
+	}
+
+	copied := *entry
+	return &copied, nil
+}
+
+// List returns all parked events, newest first
+func (s *MemoryStore) List() ([]*Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries := make([]*Entry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Delete removes a parked event
+func (s *MemoryStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.entries[id]; !exists {
+		return ErrEntryNotFound
+	}
+
+	delete(s.entries, id)
+	return nil
+}
+
+// FileStore keeps parked events in memory and writes them to a JSON file on
+// every change, so they survive a restart
+type FileStore struct {
+	*MemoryStore
+	path  string
+	write sync.Mutex
+}
+
+// NewFileStore creates a dead letter store backed by the file at path,
+// loading the events parked by a previous run
+func NewFileStore(path string) (*FileStore, error) {
+	store := &FileStore{MemoryStore: NewMemoryStore(), path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		store.entries[entry.ID] = entry
+	}
+
+	return store, nil
+}
+
+// Add stores a parked event and persists the store
+func (s *FileStore) Add(entry *Entry) error {
+	if err := s.MemoryStore.Add(entry); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// Delete removes a parked event and persists the store
+func (s *FileStore) Delete(id string) error {
+	if err := s.MemoryStore.Delete(id); err != nil {
+		return err
+	}
+	return s.save()
+}
+
+// save writes every parked event to disk, removing the file when there is
+// nothing left to persist
+func (s *FileStore) save() error {
+	s.write.Lock()
+	defer s.write.Unlock()
+
+	entries, err := s.MemoryStore.List()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file
+	tmp := s.path + ".partial"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// sortEntries orders entries newest first
+func sortEntries(entries []*Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].FailedAt.Equal(entries[j].FailedAt) {
+			return entries[i].FailedAt.After(entries[j].FailedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+}
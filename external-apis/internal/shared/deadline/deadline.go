@@ -0,0 +1,96 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header carries the remaining request budget in milliseconds between services
+const Header = "X-Request-Deadline"
+
+var (
+	// ErrInvalidBudget is returned when the deadline header cannot be parsed
+	ErrInvalidBudget = errors.New("invalid request deadline")
+	// ErrBudgetExhausted is returned when no time is left to perform a call
+	ErrBudgetExhausted = errors.New("request deadline budget exhausted")
+)
+
+// Parse parses a deadline header value expressed in milliseconds
+func Parse(value string) (time.Duration, error) {
+	ms, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidBudget
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Format formats a budget as a deadline header value, rounding down to whole milliseconds
+func Format(budget time.Duration) string {
+	if budget < 0 {
+		budget = 0
+	}
+	return strconv.FormatInt(budget.Milliseconds(), 10)
+}
+
+// WithBudget derives a context that expires after budget. An earlier deadline
+// already present on ctx is kept, so a hop can never extend its caller's budget.
+func WithBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, budget)
+}
+
+// Remaining returns the time left before the context deadline
+func Remaining(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
+
+// Inject writes the remaining budget of ctx into the outbound headers
+func Inject(ctx context.Context, header http.Header) error {
+	remaining, ok := Remaining(ctx)
+	if !ok {
+		return nil
+	}
+	if remaining <= 0 {
+		return ErrBudgetExhausted
+	}
+
+	header.Set(Header, Format(remaining))
+	return nil
+}
+
+// Transport is an http.RoundTripper that propagates the remaining budget of
+// the request context on every outbound call
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// NewTransport wraps base (or http.DefaultTransport when nil) with budget propagation
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Deadline(); !ok {
+		return t.Base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	outbound := req.Clone(req.Context())
+	if err := Inject(req.Context(), outbound.Header); err != nil {
+		return nil, err
+	}
+
+	return t.Base.RoundTrip(outbound)
+}
@@ -0,0 +1,90 @@
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Valid milliseconds", func(t *testing.T) {
+		budget, err := Parse(" 2000 ")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, budget)
+	})
+
+	t.Run("Invalid value", func(t *testing.T) {
+		_, err := Parse("2s")
+
+		assert.ErrorIs(t, err, ErrInvalidBudget)
+	})
+}
+
+func TestWithBudget_KeepsEarlierDeadline(t *testing.T) {
+	// Arrange
+	parent, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Act
+	ctx, cancelBudget := WithBudget(parent, time.Hour)
+	defer cancelBudget()
+
+	// Assert
+	remaining, ok := Remaining(ctx)
+	require.True(t, ok)
+	assert.LessOrEqual(t, remaining, 100*time.Millisecond)
+}
+
+func TestTransport_PropagatesRemainingBudget(t *testing.T) {
+	// Arrange
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(Header)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+	ctx, cancel := WithBudget(context.Background(), 2*time.Second)
+	defer cancel()
+
+	time.Sleep(20 * time.Millisecond)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	// Act
+	resp, err := client.Do(req)
+
+	// Assert
+	require.NoError(t, err)
+	resp.Body.Close()
+	budget, err := Parse(received)
+	require.NoError(t, err)
+	assert.Less(t, budget, 2*time.Second-10*time.Millisecond)
+	assert.Greater(t, budget, time.Duration(0))
+	assert.Empty(t, req.Header.Get(Header))
+}
+
+func TestTransport_NoDeadline(t *testing.T) {
+	// Arrange
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(Header)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	// Act
+	resp, err := client.Get(server.URL)
+
+	// Assert
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, received)
+}
@@ -0,0 +1,156 @@
+// Package email sends transactional email such as verification messages,
+// either through an SMTP server (SMTP) or, for development, to the log (Log).
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/email")
+
+// ErrInvalidHeader is returned for messages whose recipient or subject would
+// inject headers
+var ErrInvalidHeader = errors.New("invalid email header")
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// validate rejects line breaks in the fields that end up in headers
+func (m Message) validate() error {
+	if m.To == "" || strings.ContainsAny(m.To, "\r\n") || strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidHeader
+	}
+	return nil
+}
+
+// Sender sends email
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Log implements Sender by logging messages instead of sending them. It is
+// meant for development: the whole message is logged, tokens included.
+type Log struct{}
+
+// Send logs the message
+func (Log) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+	}).Info("Email not sent, logged instead")
+	return nil
+}
+
+// SMTPConfig configures an SMTP sender
+type SMTPConfig struct {
+	Host string
+	Port string
+	// Username and Password authenticate with PLAIN auth, which net/smtp
+	// only allows over TLS or to localhost; no auth is used without a
+	// username
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP implements Sender with an SMTP server, upgrading the connection with
+// STARTTLS when the server offers it
+type SMTP struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTP creates a sender submitting messages to the configured server
+func NewSMTP(cfg SMTPConfig) *SMTP {
+	sender := &SMTP{
+		addr: net.JoinHostPort(cfg.Host, cfg.Port),
+		from: cfg.From,
+	}
+	if cfg.Username != "" {
+		sender.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return sender
+}
+
+// Send submits the message to the server. net/smtp does not support
+// cancellation, so ctx is only checked before connecting.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, format(s.from, msg, time.Now())); err != nil {
+		return fmt.Errorf("send email to %s: %w", s.addr, err)
+	}
+	return nil
+}
+
+// format renders the message with its headers, normalizing line endings to
+// CRLF as SMTP requires
+func format(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// Memory implements Sender by keeping the messages, for tests
+type Memory struct {
+	messages []Message
+	mutex    sync.Mutex
+}
+
+// NewMemory creates a sender keeping every message
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+// Send keeps the message
+func (m *Memory) Send(_ context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first
+func (m *Memory) Messages() []Message {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]Message(nil), m.messages...)
+}
@@ -0,0 +1,69 @@
+package email
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	// Arrange
+	date := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	msg := Message{To: "jane@example.com", Subject: "Verify your email", Body: "Hello\nToken: abc\r\n"}
+
+	// Act
+	raw := string(format("no-reply@example.com", msg, date))
+
+	// Assert
+	assert.Equal(t, "From: no-reply@example.com\r\n"+
+		"To: jane@example.com\r\n"+
+		"Subject: Verify your email\r\n"+
+		"Date: Fri, 02 Jan 2026 15:04:05 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=UTF-8\r\n"+
+		"\r\n"+
+		"Hello\r\nToken: abc\r\n", raw)
+}
+
+func TestSenders_RejectHeaderInjection(t *testing.T) {
+	messages := map[string]Message{
+		"Missing recipient":     {Subject: "Hi"},
+		"Line break in to":      {To: "jane@example.com\r\nBcc: eve@example.com", Subject: "Hi"},
+		"Line break in subject": {To: "jane@example.com", Subject: "Hi\nBcc: eve@example.com"},
+	}
+	senders := map[string]Sender{
+		"Log":    Log{},
+		"SMTP":   NewSMTP(SMTPConfig{Host: "localhost", Port: "0", From: "no-reply@example.com"}),
+		"Memory": NewMemory(),
+	}
+
+	for senderName, sender := range senders {
+		for name, msg := range messages {
+			t.Run(senderName+" "+name, func(t *testing.T) {
+				// Act
+				err := sender.Send(context.Background(), msg)
+
+				// Assert
+				assert.ErrorIs(t, err, ErrInvalidHeader)
+			})
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	// Arrange
+	sender := NewMemory()
+
+	// Act
+	require.NoError(t, sender.Send(context.Background(), Message{To: "jane@example.com", Subject: "First"}))
+	require.NoError(t, sender.Send(context.Background(), Message{To: "john@example.com", Subject: "Second"}))
+
+	// Assert
+	messages := sender.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "First", messages[0].Subject)
+	assert.Equal(t, "john@example.com", messages[1].To)
+}
@@ -0,0 +1,297 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	eventsv1 "external-apis/api/events/v1"
+	"external-apis/internal/shared/schemaregistry"
+	"github.com/hamba/avro/v2"
+)
+
+// avroSchema is a record of api/events/v1/avro, parsed and as written
+type avroSchema struct {
+	schema *avro.RecordSchema
+	text   string
+}
+
+// AvroCodec serializes event data in Avro against the records of
+// api/events/v1/avro. Unions may only make a type nullable.
+type AvroCodec struct {
+	registry schemaregistry.Registry
+	schemas  map[string]avroSchema
+
+	mutex   sync.Mutex
+	writers map[int]avro.Schema
+}
+
+// NewAvroCodec creates a codec registering its schemas with registry
+func NewAvroCodec(registry schemaregistry.Registry) (*AvroCodec, error) {
+	files, err := fs.Glob(eventsv1.Avro, "avro/*.avsc")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]avroSchema, len(files))
+	for _, file := range files {
+		text, err := fs.ReadFile(eventsv1.Avro, file)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := avro.Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		recordSchema, ok := schema.(*avro.RecordSchema)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a record", file)
+		}
+		schemas[recordSchema.Name()] = avroSchema{schema: recordSchema, text: string(text)}
+	}
+
+	return &AvroCodec{
+		registry: registry,
+		schemas:  schemas,
+		writers:  make(map[int]avro.Schema),
+	}, nil
+}
+
+// ContentType implements DataCodec
+func (c *AvroCodec) ContentType() string {
+	return "application/avro"
+}
+
+// Marshal implements DataCodec. Fields missing from data take their
+// default.
+func (c *AvroCodec) Marshal(ctx context.Context, topic, eventType string, data []byte) ([]byte, error) {
+	name, err := record(eventType)
+	if err != nil {
+		return nil, err
+	}
+	schema, ok := c.schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q: no Avro record %s", ErrNoSchema, eventType, name)
+	}
+
+	document, err := decodeData(data)
+	if err != nil {
+		return nil, err
+	}
+	native, err := avroNative(schema.schema, document)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", eventType, err)
+	}
+	payload, err := avro.Marshal(schema.schema, native)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	id, err := c.registry.Register(ctx, schemaregistry.Subject(topic, schema.schema.FullName()), schemaregistry.Schema{
+		Type:   schemaregistry.TypeAvro,
+		Schema: schema.text,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemaregistry.Frame(id, payload), nil
+}
+
+// Unmarshal implements DataCodec. The data is read with the schema it was
+// written with, fetched from the registry.
+func (c *AvroCodec) Unmarshal(ctx context.Context, eventType string, value []byte) (json.RawMessage, error) {
+	id, payload, err := schemaregistry.Unframe(value)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := c.writer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var native interface{}
+	if err := avro.Unmarshal(schema, payload, &native); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return json.Marshal(avroData(schema, native))
+}
+
+// writer returns the schema registered under id, parsed
+func (c *AvroCodec) writer(ctx context.Context, id int) (avro.Schema, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if schema, ok := c.writers[id]; ok {
+		return schema, nil
+	}
+	registered, err := c.registry.Schema(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := avro.Parse(registered.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema %d: %w", id, err)
+	}
+	c.writers[id] = schema
+	return schema, nil
+}
+
+// avroNative converts JSON data decoded with UseNumber into the native
+// values schema is encoded from
+func avroNative(schema avro.Schema, value interface{}) (interface{}, error) {
+	switch schema := schema.(type) {
+	case *avro.RecordSchema:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: expected an object for %s, got %T", ErrInvalidData, schema.Name(), value)
+		}
+		record := make(map[string]interface{}, len(schema.Fields()))
+		for _, field := range schema.Fields() {
+			fieldValue, ok := fields[field.Name()]
+			if !ok && field.HasDefault() {
+				record[field.Name()] = field.Default()
+				continue
+			}
+			if !ok {
+				return nil, fmt.Errorf("%w: %s is missing", ErrInvalidData, field.Name())
+			}
+			native, err := avroNative(field.Type(), fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name(), err)
+			}
+			record[field.Name()] = native
+		}
+		return record, nil
+
+	case *avro.UnionSchema:
+		if value == nil && schema.Nullable() {
+			return nil, nil
+		}
+		for _, member := range schema.Types() {
+			if member.Type() != avro.Null {
+				return avroNative(member, value)
+			}
+		}
+		return nil, fmt.Errorf("%w: unexpected null", ErrInvalidData)
+
+	case *avro.ArraySchema:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: expected an array, got %T", ErrInvalidData, value)
+		}
+		natives := make([]interface{}, len(items))
+		for i, item := range items {
+			native, err := avroNative(schema.Items(), item)
+			if err != nil {
+				return nil, err
+			}
+			natives[i] = native
+		}
+		return natives, nil
+
+	case *avro.PrimitiveSchema:
+		if logical := schema.Logical(); logical != nil && logical.Type() == avro.TimestampMillis {
+			text, err := jsonString(value)
+			if err != nil {
+				return nil, err
+			}
+			at, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+			}
+			return at, nil
+		}
+		return avroPrimitive(schema.Type(), value)
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported Avro type %s", ErrInvalidData, schema.Type())
+	}
+}
+
+// avroPrimitive converts a JSON value into the native value of a primitive
+// Avro type
+func avroPrimitive(avroType avro.Type, value interface{}) (interface{}, error) {
+	switch avroType {
+	case avro.String:
+		return jsonString(value)
+	case avro.Boolean:
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected a boolean, got %T", ErrInvalidData, value)
+		}
+		return boolean, nil
+	case avro.Int, avro.Long:
+		number, err := jsonNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		integer, err := number.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		if avroType == avro.Int {
+			return int(integer), nil
+		}
+		return integer, nil
+	case avro.Double:
+		number, err := jsonNumber(value)
+		if err != nil {
+			return nil, err
+		}
+		float, err := number.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		return float, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported Avro type %s", ErrInvalidData, avroType)
+	}
+}
+
+// avroData converts the native values decoded with schema back into JSON
+// data, unwrapping the values of unions
+func avroData(schema avro.Schema, native interface{}) interface{} {
+	switch schema := schema.(type) {
+	case *avro.RecordSchema:
+		fields, ok := native.(map[string]interface{})
+		if !ok {
+			return native
+		}
+		for _, field := range schema.Fields() {
+			if value, ok := fields[field.Name()]; ok {
+				fields[field.Name()] = avroData(field.Type(), value)
+			}
+		}
+		return fields
+
+	case *avro.UnionSchema:
+		wrapped, ok := native.(map[string]interface{})
+		if !ok || len(wrapped) != 1 {
+			return native
+		}
+		for _, member := range schema.Types() {
+			if member.Type() != avro.Null {
+				for _, value := range wrapped {
+					return avroData(member, value)
+				}
+			}
+		}
+		return native
+
+	case *avro.ArraySchema:
+		items, ok := native.([]interface{})
+		if !ok {
+			return native
+		}
+		for i, item := range items {
+			items[i] = avroData(schema.Items(), item)
+		}
+		return items
+
+	default:
+		return native
+	}
+}
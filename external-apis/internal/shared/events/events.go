@@ -0,0 +1,244 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+	"github.com/google/uuid"
+)
+
+// DefaultSource is the CloudEvents source of events encoded without one
+const DefaultSource = "/external-apis"
+
+// Entity lifecycle event types
+const (
+	CustomerCreated  = "customer.created"
+	CustomerUpdated  = "customer.updated"
+	CustomerDeleted  = "customer.deleted"
+	CustomerRestored = "customer.restored"
+	CustomerMerged   = "customer.merged"
+	CustomerVerified = "customer.verified"
+
+	ProductCreated      = "product.created"
+	ProductUpdated      = "product.updated"
+	ProductDeleted      = "product.deleted"
+	ProductRestored     = "product.restored"
+	ProductStockChanged = "product.stock_changed"
+
+	OrderCreated       = "order.created"
+	OrderStatusChanged = "order.status_changed"
+)
+
+// Notification event types
+const (
+	// ProductBackInStock tells a subscriber that a product is back in stock;
+	// its subject is the product
+	ProductBackInStock = "product.back_in_stock"
+)
+
+// CustomerEvents lists the events published by the customer service
+var CustomerEvents = []string{
+	CustomerCreated,
+	CustomerUpdated,
+	CustomerDeleted,
+	CustomerRestored,
+	CustomerMerged,
+	CustomerVerified,
+}
+
+// ProductEvents lists the events published by the product service
+var ProductEvents = []string{
+	ProductCreated,
+	ProductUpdated,
+	ProductDeleted,
+	ProductRestored,
+	ProductStockChanged,
+	ProductBackInStock,
+}
+
+// OrderEvents lists the order lifecycle events
+var OrderEvents = []string{
+	OrderCreated,
+	OrderStatusChanged,
+}
+
+// Deletions lists the events after which their subject is gone. A merged
+// customer is gone too: its ID only resolves to the survivor.
+var Deletions = []string{
+	CustomerDeleted,
+	CustomerMerged,
+	ProductDeleted,
+}
+
+// IsDeletion reports whether events of eventType remove their subject
+func IsDeletion(eventType string) bool {
+	return slices.Contains(Deletions, eventType)
+}
+
+// Event describes a change to an entity
+type Event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Subject is the ID of the entity the event is about
+	Subject    string    `json:"subject"`
+	OccurredAt time.Time `json:"occurredAt"`
+	// Tenant is the tenant that owns the entity
+	Tenant string `json:"tenant,omitempty"`
+	// Traceparent is the W3C trace context the event was published in
+	Traceparent string      `json:"traceparent,omitempty"`
+	Data        interface{} `json:"data"`
+}
+
+// New creates an event with a fresh ID
+func New(eventType string, subject string, data interface{}) Event {
+	return Event{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Subject:    subject,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Decode decodes a CloudEvent consumed from a broker. Its data is left
+// encoded, as a json.RawMessage, for the handler to decode into the type it
+// expects.
+func Decode(value []byte) (Event, error) {
+	cloudEvent, err := cloudevents.Decode(value)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          cloudEvent.ID,
+		Type:        cloudEvent.Type,
+		Subject:     cloudEvent.Subject,
+		OccurredAt:  cloudEvent.Time,
+		Tenant:      cloudEvent.Tenant,
+		Traceparent: cloudEvent.Traceparent,
+		Data:        cloudEvent.Data,
+	}, nil
+}
+
+// For returns a copy of the event owned by tenant
+func (e Event) For(tenant string) Event {
+	e.Tenant = tenant
+	return e
+}
+
+// In returns a copy of the event owned by the tenant of ctx and carrying its
+// trace, so consumers carry on the trace of the request that caused it
+func (e Event) In(ctx context.Context) Event {
+	e.Tenant = tenant.FromContext(ctx)
+	if trace, ok := logger.TraceFromContext(ctx); ok {
+		e.Traceparent = trace.Header
+	}
+	return e
+}
+
+// Context returns a copy of ctx carrying the trace of a consumed event, so
+// the handler logs under it
+func (e Event) Context(ctx context.Context) context.Context {
+	if trace, ok := logger.ParseTraceparent(e.Traceparent); ok {
+		return logger.WithTrace(ctx, trace)
+	}
+	return ctx
+}
+
+// Encoder encodes events as CloudEvents in the structured JSON format, the
+// format events are published in to brokers and webhook subscribers
+type Encoder struct {
+	// Source identifies the publishing service, e.g. /product-service; it
+	// defaults to DefaultSource
+	Source string
+	// Schemas sets the dataschema of events whose type has a registered
+	// schema; it may be nil
+	Schemas cloudevents.SchemaRegistry
+}
+
+// Encode encodes an event
+func (e Encoder) Encode(event Event) ([]byte, error) {
+	cloudEvent, err := e.cloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	return cloudevents.Encode(cloudEvent)
+}
+
+// cloudEvent converts an event into a CloudEvent with JSON data
+func (e Encoder) cloudEvent(event Event) (cloudevents.Event, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return cloudevents.Event{}, err
+	}
+
+	source := e.Source
+	if source == "" {
+		source = DefaultSource
+	}
+	cloudEvent := cloudevents.Event{
+		SpecVersion:     cloudevents.SpecVersion,
+		ID:              event.ID,
+		Source:          source,
+		Type:            event.Type,
+		Subject:         event.Subject,
+		Time:            event.OccurredAt,
+		DataContentType: cloudevents.JSONDataContentType,
+		Tenant:          event.Tenant,
+		Traceparent:     event.Traceparent,
+		Data:            data,
+	}
+	if e.Schemas != nil {
+		cloudEvent.DataSchema, _ = e.Schemas.SchemaURI(event.Type)
+	}
+	return cloudEvent, nil
+}
+
+// Publisher publishes entity change events. Publishing never blocks on or
+// fails because of downstream consumers; delivery errors are logged.
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Multi fans every event out to each of its publishers
+type Multi []Publisher
+
+// Publish sends the event to every publisher
+func (m Multi) Publish(event Event) {
+	for _, publisher := range m {
+		publisher.Publish(event)
+	}
+}
+
+// Handler processes an event consumed from a broker. An event whose handler
+// fails is delivered again, up to the broker's maximum number of deliveries.
+type Handler func(ctx context.Context, event Event) error
+
+// Subscriber consumes events through durable subscriptions: a subscription
+// resumes where it left off after a restart, and the instances subscribing
+// under the same name share its events. Subscribe returns once consuming
+// has started; it stops when ctx is done or the subscriber is closed.
+type Subscriber interface {
+	Subscribe(ctx context.Context, name string, handler Handler) error
+}
+
+// Broker is a message broker events are published to and consumed from.
+// Kafka and NATS JetStream implement it, so the code publishing and handling
+// events does not depend on the broker a deployment runs.
+type Broker interface {
+	Publisher
+	Subscriber
+	deadletter.Replayer
+	// Name identifies the broker in logs, health checks and dead letters
+	Name() string
+	// Ping checks that the broker is reachable
+	Ping(ctx context.Context) error
+	// Close stops the subscriptions and flushes buffered events
+	Close() error
+}
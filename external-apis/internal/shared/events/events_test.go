@@ -0,0 +1,532 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWriter records the messages written to it
+type fakeWriter struct {
+	messages []kafka.Message
+	err      error
+	closed   bool
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+// fakeReader hands out queued messages and records the committed ones
+type fakeReader struct {
+	messages  chan kafka.Message
+	mutex     sync.Mutex
+	committed []int64
+	closed    bool
+}
+
+func newFakeReader(messages ...kafka.Message) *fakeReader {
+	reader := &fakeReader{messages: make(chan kafka.Message, len(messages))}
+	for _, message := range messages {
+		reader.messages <- message
+	}
+	return reader
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case message := <-r.messages:
+		return message, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, message := range msgs {
+		r.committed = append(r.committed, message.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) committedOffsets() []int64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]int64(nil), r.committed...)
+}
+
+// eventMessage encodes an event into a message at an offset
+func eventMessage(t *testing.T, offset int64, event Event) kafka.Message {
+	t.Helper()
+	value, err := Encoder{}.Encode(event)
+	require.NoError(t, err)
+	return kafka.Message{Offset: offset, Key: []byte(event.Subject), Value: value}
+}
+
+func TestNew(t *testing.T) {
+	// Act
+	first := New(ProductCreated, "product-123", nil)
+	second := New(ProductCreated, "product-123", nil)
+
+	// Assert
+	assert.NotEmpty(t, first.ID)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Equal(t, ProductCreated, first.Type)
+	assert.Equal(t, "product-123", first.Subject)
+	assert.False(t, first.OccurredAt.IsZero())
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Keep the data encoded", func(t *testing.T) {
+		// Arrange
+		event := New(CustomerCreated, "customer-123", map[string]string{"name": "John Doe"}).For("brand-a")
+		event.Traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		value, err := Encoder{Source: "/customer-service"}.Encode(event)
+		require.NoError(t, err)
+
+		// Act
+		decoded, err := Decode(value)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, event.ID, decoded.ID)
+		assert.Equal(t, CustomerCreated, decoded.Type)
+		assert.Equal(t, "customer-123", decoded.Subject)
+		assert.Equal(t, "brand-a", decoded.Tenant)
+		assert.Equal(t, event.Traceparent, decoded.Traceparent)
+		assert.True(t, event.OccurredAt.Equal(decoded.OccurredAt))
+		assert.JSONEq(t, `{"name":"John Doe"}`, string(decoded.Data.(json.RawMessage)))
+	})
+
+	t.Run("Malformed event", func(t *testing.T) {
+		// Act
+		_, err := Decode([]byte(`{"id":`))
+
+		// Assert
+		assert.ErrorIs(t, err, cloudevents.ErrInvalidEvent)
+	})
+
+	t.Run("Event that is not a CloudEvent", func(t *testing.T) {
+		// Act
+		_, err := Decode([]byte(`{"id":"event-1","type":"customer.created","subject":"customer-123","data":null}`))
+
+		// Assert
+		assert.ErrorIs(t, err, cloudevents.ErrInvalidEvent)
+	})
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	t.Run("CloudEvent with schema", func(t *testing.T) {
+		// Arrange
+		encoder := Encoder{
+			Source:  "/product-service",
+			Schemas: cloudevents.Schemas{ProductCreated: "https://schemas.example.com/product.created/1"},
+		}
+		event := New(ProductCreated, "product-123", map[string]string{"name": "Wireless Mouse"}).For("brand-a")
+		event.OccurredAt = time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+		// Act
+		value, err := encoder.Encode(event)
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"specversion": "1.0",
+			"id": "`+event.ID+`",
+			"source": "/product-service",
+			"type": "product.created",
+			"subject": "product-123",
+			"time": "2026-03-01T12:00:00Z",
+			"datacontenttype": "application/json",
+			"dataschema": "https://schemas.example.com/product.created/1",
+			"tenant": "brand-a",
+			"data": {"name": "Wireless Mouse"}
+		}`, string(value))
+	})
+
+	t.Run("Default source", func(t *testing.T) {
+		// Act
+		value, err := Encoder{}.Encode(New(ProductDeleted, "product-123", nil))
+
+		// Assert
+		require.NoError(t, err)
+		decoded, err := cloudevents.Decode(value)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultSource, decoded.Source)
+		assert.Empty(t, decoded.DataSchema)
+	})
+}
+
+func TestEvent_In(t *testing.T) {
+	// Arrange
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	trace, ok := logger.ParseTraceparent(traceparent)
+	require.True(t, ok)
+	ctx := logger.WithTrace(tenant.WithTenant(context.Background(), "brand-a"), trace)
+
+	// Act
+	event := New(ProductUpdated, "product-123", nil).In(ctx)
+
+	// Assert
+	assert.Equal(t, "brand-a", event.Tenant)
+	assert.Equal(t, traceparent, event.Traceparent)
+
+	consumed, ok := logger.TraceFromContext(event.Context(context.Background()))
+	require.True(t, ok, "handlers carry on the trace of the event")
+	assert.Equal(t, trace.TraceID, consumed.TraceID)
+}
+
+func TestIsDeletion(t *testing.T) {
+	assert.True(t, IsDeletion(CustomerDeleted))
+	assert.True(t, IsDeletion(CustomerMerged))
+	assert.True(t, IsDeletion(ProductDeleted))
+	assert.False(t, IsDeletion(ProductRestored))
+	assert.False(t, IsDeletion(OrderStatusChanged))
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	// Arrange
+	publisher := NewMemoryPublisher()
+
+	// Act
+	publisher.Publish(New(CustomerCreated, "customer-123", nil))
+	publisher.Publish(New(CustomerDeleted, "customer-123", nil))
+
+	// Assert
+	assert.Equal(t, []string{CustomerCreated, CustomerDeleted}, publisher.Types())
+	assert.Equal(t, "customer-123", publisher.Events()[1].Subject)
+
+	publisher.Reset()
+	assert.Empty(t, publisher.Events())
+}
+
+func TestMulti(t *testing.T) {
+	// Arrange
+	first := NewMemoryPublisher()
+	second := NewMemoryPublisher()
+	event := New(ProductUpdated, "product-123", nil)
+
+	// Act
+	Multi{first, second}.Publish(event)
+
+	// Assert
+	assert.Equal(t, []Event{event}, first.Events())
+	assert.Equal(t, []Event{event}, second.Events())
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	t.Run("Write event keyed by subject", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{Encoder: Encoder{Source: "/product-service"}})
+		event := New(ProductDeleted, "product-123", map[string]string{"id": "product-123"})
+
+		// Act
+		publisher.Publish(event)
+
+		// Assert
+		require.Len(t, writer.messages, 1)
+		message := writer.messages[0]
+		assert.Equal(t, "product-123", string(message.Key))
+		assert.Equal(t, []kafka.Header{
+			{Key: HeaderEventType, Value: []byte(ProductDeleted)},
+			{Key: HeaderContentType, Value: []byte(cloudevents.ContentType)},
+		}, message.Headers)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(message.Value, &decoded))
+		assert.Equal(t, "1.0", decoded["specversion"])
+		assert.Equal(t, event.ID, decoded["id"])
+		assert.Equal(t, "/product-service", decoded["source"])
+		assert.Equal(t, ProductDeleted, decoded["type"])
+		assert.Equal(t, map[string]interface{}{"id": "product-123"}, decoded["data"])
+	})
+
+	t.Run("Swallow write errors", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{err: errors.New("broker unavailable")}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{})
+
+		// Act & Assert
+		assert.NotPanics(t, func() {
+			publisher.Publish(New(ProductCreated, "product-123", nil))
+		})
+	})
+
+	t.Run("Dead-letter write errors", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{err: errors.New("broker unavailable")}
+		deadLetters := deadletter.NewQueue(deadletter.NewMemoryStore())
+		publisher := NewKafkaPublisher(writer, KafkaConfig{Topic: "product-events", DeadLetters: deadLetters})
+		event := New(ProductCreated, "product-123", nil)
+
+		// Act
+		publisher.Publish(event)
+
+		// Assert
+		parked, err := deadLetters.List(deadletter.Filter{})
+		require.NoError(t, err)
+		require.Len(t, parked, 1)
+		assert.Equal(t, deadletter.SourceKafka, parked[0].Source)
+		assert.Equal(t, "product-events", parked[0].Destination)
+		assert.Equal(t, event.ID, parked[0].EventID)
+		assert.Equal(t, ProductCreated, parked[0].EventType)
+		assert.Equal(t, "product-123", parked[0].Subject)
+		assert.Equal(t, "broker unavailable", parked[0].Error)
+	})
+
+	t.Run("Replay parked event", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{})
+		entry := &deadletter.Entry{
+			EventType: ProductUpdated,
+			Subject:   "product-123",
+			Event:     json.RawMessage(`{"id":"event-1"}`),
+		}
+
+		// Act
+		err := publisher.Replay(context.Background(), entry)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, writer.messages, 1)
+		assert.Equal(t, "product-123", string(writer.messages[0].Key))
+		assert.JSONEq(t, `{"id":"event-1"}`, string(writer.messages[0].Value))
+		assert.Equal(t, []kafka.Header{
+			{Key: HeaderEventType, Value: []byte(ProductUpdated)},
+			{Key: HeaderContentType, Value: []byte(cloudevents.ContentType)},
+		}, writer.messages[0].Headers)
+	})
+
+	t.Run("Follow deletions with a tombstone", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{Tombstones: true})
+		event := New(CustomerMerged, "customer-2", map[string]string{"survivorId": "customer-1", "mergedId": "customer-2"})
+
+		// Act
+		publisher.Publish(event)
+
+		// Assert
+		require.Len(t, writer.messages, 2)
+		tombstone := writer.messages[1]
+		assert.Equal(t, "customer-2", string(tombstone.Key))
+		assert.Nil(t, tombstone.Value)
+		assert.Equal(t, writer.messages[0].Time, tombstone.Time)
+		assert.Equal(t, []kafka.Header{{Key: HeaderEventType, Value: []byte(CustomerMerged)}}, tombstone.Headers)
+	})
+
+	t.Run("No tombstones for other events", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{Tombstones: true})
+
+		// Act
+		publisher.Publish(New(ProductRestored, "product-123", nil))
+
+		// Assert
+		require.Len(t, writer.messages, 1)
+		assert.NotNil(t, writer.messages[0].Value)
+	})
+
+	t.Run("Dead-letter the deletion but not its tombstone", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{err: errors.New("broker unavailable")}
+		deadLetters := deadletter.NewQueue(deadletter.NewMemoryStore())
+		publisher := NewKafkaPublisher(writer, KafkaConfig{Topic: "product-events", DeadLetters: deadLetters, Tombstones: true})
+		event := New(ProductDeleted, "product-123", map[string]string{"id": "product-123"})
+
+		// Act
+		publisher.Publish(event)
+
+		// Assert
+		parked, err := deadLetters.List(deadletter.Filter{})
+		require.NoError(t, err)
+		require.Len(t, parked, 1)
+		assert.Equal(t, event.ID, parked[0].EventID)
+	})
+
+	t.Run("Replay a parked deletion with its tombstone", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{Tombstones: true})
+		entry := &deadletter.Entry{
+			EventType: ProductDeleted,
+			Subject:   "product-123",
+			Event:     json.RawMessage(`{"id":"event-1"}`),
+		}
+
+		// Act
+		err := publisher.Replay(context.Background(), entry)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, writer.messages, 2)
+		assert.Equal(t, "product-123", string(writer.messages[1].Key))
+		assert.Nil(t, writer.messages[1].Value)
+	})
+
+	t.Run("Close the writer", func(t *testing.T) {
+		// Arrange
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{})
+
+		// Act
+		err := publisher.Close()
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, writer.closed)
+	})
+}
+
+func TestKafkaBroker_Subscribe(t *testing.T) {
+	newBroker := func(reader *fakeReader, groups *[]string) *KafkaBroker {
+		broker := NewKafkaBroker(KafkaConfig{Topic: "product-events", MaxDeliveries: 3, RedeliveryDelay: time.Millisecond})
+		broker.KafkaPublisher = NewKafkaPublisher(&fakeWriter{}, KafkaConfig{})
+		broker.newReader = func(group string) MessageReader {
+			*groups = append(*groups, group)
+			return reader
+		}
+		return broker
+	}
+
+	t.Run("Handle and commit events in the consumer group", func(t *testing.T) {
+		// Arrange
+		first, second := New(ProductCreated, "product-123", nil), New(ProductUpdated, "product-123", nil)
+		reader := newFakeReader(eventMessage(t, 1, first), eventMessage(t, 2, second))
+		var groups []string
+		broker := newBroker(reader, &groups)
+		got := &received{}
+
+		// Act
+		err := broker.Subscribe(context.Background(), "search-index", got.handle)
+
+		// Assert
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(reader.committedOffsets()) == 2 }, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"search-index"}, groups)
+		assert.Equal(t, []string{first.ID, second.ID}, got.ids())
+		assert.Equal(t, []int64{1, 2}, reader.committedOffsets())
+
+		require.NoError(t, broker.Close())
+		assert.True(t, reader.closed)
+	})
+
+	t.Run("Retry failed events up to the maximum", func(t *testing.T) {
+		// Arrange
+		reader := newFakeReader(eventMessage(t, 7, New(ProductDeleted, "product-123", nil)))
+		var groups []string
+		broker := newBroker(reader, &groups)
+		var mutex sync.Mutex
+		attempts := 0
+
+		// Act
+		err := broker.Subscribe(context.Background(), "search-index", func(ctx context.Context, event Event) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			attempts++
+			return errors.New("search index down")
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(reader.committedOffsets()) == 1 }, time.Second, time.Millisecond)
+		mutex.Lock()
+		assert.Equal(t, 3, attempts)
+		mutex.Unlock()
+		require.NoError(t, broker.Close())
+	})
+
+	t.Run("Skip undecodable events", func(t *testing.T) {
+		// Arrange
+		reader := newFakeReader(kafka.Message{Offset: 3, Value: []byte("not json")})
+		var groups []string
+		broker := newBroker(reader, &groups)
+		got := &received{}
+
+		// Act
+		err := broker.Subscribe(context.Background(), "search-index", got.handle)
+
+		// Assert
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(reader.committedOffsets()) == 1 }, time.Second, time.Millisecond)
+		assert.Empty(t, got.ids())
+		require.NoError(t, broker.Close())
+	})
+
+	t.Run("Commit tombstones without handling them", func(t *testing.T) {
+		// Arrange
+		deleted := New(ProductDeleted, "product-123", nil)
+		tombstone := kafka.Message{Offset: 2, Key: []byte("product-123"), Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte(ProductDeleted)}}}
+		reader := newFakeReader(eventMessage(t, 1, deleted), tombstone)
+		var groups []string
+		broker := newBroker(reader, &groups)
+		got := &received{}
+
+		// Act
+		err := broker.Subscribe(context.Background(), "search-index", got.handle)
+
+		// Assert
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(reader.committedOffsets()) == 2 }, time.Second, time.Millisecond)
+		assert.Equal(t, []string{deleted.ID}, got.ids())
+		require.NoError(t, broker.Close())
+	})
+
+	t.Run("Leave the event uncommitted when stopped while handling it", func(t *testing.T) {
+		// Arrange
+		reader := newFakeReader(eventMessage(t, 5, New(ProductUpdated, "product-123", nil)))
+		var groups []string
+		broker := newBroker(reader, &groups)
+		ctx, cancel := context.WithCancel(context.Background())
+		handling := make(chan struct{})
+
+		// Act
+		err := broker.Subscribe(ctx, "search-index", func(ctx context.Context, event Event) error {
+			close(handling)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		require.NoError(t, err)
+		<-handling
+		cancel()
+
+		// Assert
+		require.NoError(t, broker.Close())
+		assert.Empty(t, reader.committedOffsets())
+	})
+}
@@ -0,0 +1,421 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/logger"
+	"github.com/segmentio/kafka-go"
+)
+
+var log = logger.New("shared/events")
+
+// Headers of published messages
+const (
+	// HeaderEventType carries the event type
+	HeaderEventType = "event-type"
+	// HeaderContentType carries the media type of the message: a CloudEvent
+	// in the structured format, or its data in the binary content mode
+	HeaderContentType = "content-type"
+)
+
+// kafkaMaxAttempts is how many times the writer tries to deliver a batch
+// before its events are dead-lettered
+const kafkaMaxAttempts = 10
+
+// KafkaConfig configures the Kafka writer
+type KafkaConfig struct {
+	Brokers []string
+	Topic   string
+	// BatchTimeout bounds how long events are buffered before being sent
+	BatchTimeout time.Duration
+	// Encoder encodes the published events as CloudEvents
+	Encoder Encoder
+	// Serializer writes the events into messages; when it is nil they are
+	// structured CloudEvents encoded by Encoder
+	Serializer Serializer
+	// DeadLetters parks events the writer gave up on so they can be
+	// replayed; they are only logged when it is nil
+	DeadLetters *deadletter.Queue
+	// Tombstones follows the events of Deletions with a tombstone: a
+	// message keyed by their subject without a value, so compacted topics
+	// and change data capture pipelines drop the entity
+	Tombstones bool
+	// MaxDeliveries bounds how many times a consumed event is handed to its
+	// handler before it is skipped, and RedeliveryDelay is the wait before
+	// the first retry, growing with every attempt
+	MaxDeliveries   int
+	RedeliveryDelay time.Duration
+}
+
+// MessageWriter is the subset of *kafka.Writer used by KafkaPublisher
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// NewKafkaWriter creates an asynchronous writer that partitions messages by
+// key, so all events about one entity stay in order. Events that cannot be
+// written after retries are logged and dead-lettered.
+func NewKafkaWriter(config KafkaConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(config.Brokers...),
+		Topic:                  config.Topic,
+		Balancer:               &kafka.Hash{},
+		BatchTimeout:           config.BatchTimeout,
+		RequiredAcks:           kafka.RequireOne,
+		MaxAttempts:            kafkaMaxAttempts,
+		AllowAutoTopicCreation: true,
+		Async:                  true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				log.WithError(err).WithFields(logger.Fields{
+					"topic":    config.Topic,
+					"messages": len(messages),
+				}).Error("Failed to publish events to Kafka")
+				parkMessages(config, messages, kafkaMaxAttempts, err)
+			}
+		},
+	}
+}
+
+// serializer returns the serializer of the messages of the topic
+func (c KafkaConfig) serializer() Serializer {
+	if c.Serializer == nil {
+		return StructuredSerializer{Encoder: c.Encoder}
+	}
+	return c.Serializer
+}
+
+// KafkaPublisher publishes events as CloudEvents keyed by their subject
+type KafkaPublisher struct {
+	writer MessageWriter
+	config KafkaConfig
+}
+
+// NewKafkaPublisher creates a publisher writing to the given writer. Events
+// the writer rejects are dead-lettered to config.DeadLetters.
+func NewKafkaPublisher(writer MessageWriter, config KafkaConfig) *KafkaPublisher {
+	return &KafkaPublisher{writer: writer, config: config}
+}
+
+// Publish writes the event to Kafka. Events that cannot be serialized, e.g.
+// while the schema registry is unavailable, are dead-lettered.
+func (p *KafkaPublisher) Publish(event Event) {
+	fields := logger.Fields{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+	}
+	message, err := p.config.serializer().Serialize(context.Background(), p.config.Topic, event)
+	if err != nil {
+		log.WithError(err).WithFields(fields).Error("Failed to serialize event")
+		parkEvent(p.config, event, err)
+		return
+	}
+
+	messages := p.withTombstone(message)
+	if err := p.writer.WriteMessages(context.Background(), messages...); err != nil {
+		log.WithError(err).WithFields(fields).Error("Failed to publish event to Kafka")
+		parkMessages(p.config, messages, 1, err)
+	}
+}
+
+// Replay writes a parked event to Kafka again. Events are parked as
+// structured CloudEvents, written as they are unless the topic takes
+// another format. It implements deadletter.Replayer.
+func (p *KafkaPublisher) Replay(ctx context.Context, entry *deadletter.Entry) error {
+	serializer := p.config.serializer()
+	if _, ok := serializer.(StructuredSerializer); ok {
+		return p.writer.WriteMessages(ctx, p.withTombstone(newMessage(entry.Subject, entry.EventType, entry.Event, time.Now().UTC()))...)
+	}
+
+	event, err := Decode(entry.Event)
+	if err != nil {
+		return err
+	}
+	message, err := serializer.Serialize(ctx, p.config.Topic, event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, p.withTombstone(message)...)
+}
+
+// withTombstone returns the messages to write for the message of an event:
+// the message, followed by a tombstone of its key if the event is a
+// deletion and tombstones are enabled. Both share the key, so they land in
+// the same partition in that order.
+func (p *KafkaPublisher) withTombstone(message kafka.Message) []kafka.Message {
+	eventType := header(message, HeaderEventType)
+	if !p.config.Tombstones || !IsDeletion(eventType) {
+		return []kafka.Message{message}
+	}
+	return []kafka.Message{message, {
+		Key:     message.Key,
+		Time:    message.Time,
+		Headers: []kafka.Header{{Key: HeaderEventType, Value: []byte(eventType)}},
+	}}
+}
+
+// Close flushes buffered events and closes the writer
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// newMessage builds the message of an encoded event
+func newMessage(subject, eventType string, value []byte, at time.Time) kafka.Message {
+	return kafka.Message{
+		Key:   []byte(subject),
+		Value: value,
+		Time:  at,
+		Headers: []kafka.Header{
+			{Key: HeaderEventType, Value: []byte(eventType)},
+			{Key: HeaderContentType, Value: []byte(cloudevents.ContentType)},
+		},
+	}
+}
+
+// header returns the value of the header of message with key
+func header(message kafka.Message, key string) string {
+	for _, header := range message.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// parkMessages dead-letters the events of messages that could not be
+// written. Events are parked as structured CloudEvents whatever the format
+// they were serialized in, so they can be read and replayed in any.
+// Tombstones are skipped; they are written again with their event.
+func parkMessages(config KafkaConfig, messages []kafka.Message, attempts int, err error) {
+	if config.DeadLetters == nil {
+		return
+	}
+
+	for _, message := range messages {
+		if message.Value == nil {
+			continue
+		}
+		entry := deadletter.Entry{
+			Source:      deadletter.SourceKafka,
+			Destination: config.Topic,
+			Subject:     string(message.Key),
+			Event:       message.Value,
+			EventType:   header(message, HeaderEventType),
+			Error:       err.Error(),
+			Attempts:    attempts,
+		}
+
+		if event, decodeErr := config.serializer().Deserialize(context.Background(), message); decodeErr == nil {
+			entry.EventID = event.ID
+			if value, encodeErr := config.Encoder.Encode(event); encodeErr == nil {
+				entry.Event = value
+			}
+		}
+		if !json.Valid(entry.Event) {
+			entry.Event, _ = json.Marshal(message.Value)
+		}
+		config.DeadLetters.Park(entry)
+	}
+}
+
+// parkEvent dead-letters an event that could not be serialized
+func parkEvent(config KafkaConfig, event Event, err error) {
+	if config.DeadLetters == nil {
+		return
+	}
+
+	value, encodeErr := config.Encoder.Encode(event)
+	if encodeErr != nil {
+		return
+	}
+	config.DeadLetters.Park(deadletter.Entry{
+		Source:      deadletter.SourceKafka,
+		Destination: config.Topic,
+		EventID:     event.ID,
+		EventType:   event.Type,
+		Subject:     event.Subject,
+		Event:       value,
+		Error:       err.Error(),
+		Attempts:    1,
+	})
+}
+
+// MessageReader is the subset of *kafka.Reader used by KafkaBroker
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// KafkaBroker publishes events to a Kafka topic and consumes them through
+// consumer groups, one per subscription name
+type KafkaBroker struct {
+	*KafkaPublisher
+	config    KafkaConfig
+	newReader func(group string) MessageReader
+
+	mutex     sync.Mutex
+	cancels   []context.CancelFunc
+	consumers sync.WaitGroup
+}
+
+// NewKafkaBroker creates a broker on the topic of config
+func NewKafkaBroker(config KafkaConfig) *KafkaBroker {
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = 1
+	}
+	return &KafkaBroker{
+		KafkaPublisher: NewKafkaPublisher(NewKafkaWriter(config), config),
+		config:         config,
+		newReader: func(group string) MessageReader {
+			return kafka.NewReader(kafka.ReaderConfig{
+				Brokers: config.Brokers,
+				Topic:   config.Topic,
+				GroupID: group,
+			})
+		},
+	}
+}
+
+// Name identifies the broker
+func (b *KafkaBroker) Name() string {
+	return deadletter.SourceKafka
+}
+
+// Ping checks that one of the brokers accepts connections
+func (b *KafkaBroker) Ping(ctx context.Context) error {
+	return PingKafka(ctx, b.config.Brokers)
+}
+
+// Subscribe consumes the events of the topic in the consumer group name.
+// Offsets are committed once an event is handled, so a restarted
+// subscription resumes after the last handled event.
+func (b *KafkaBroker) Subscribe(ctx context.Context, name string, handler Handler) error {
+	ctx, cancel := context.WithCancel(ctx)
+	reader := b.newReader(name)
+
+	b.mutex.Lock()
+	b.cancels = append(b.cancels, cancel)
+	b.consumers.Add(1)
+	b.mutex.Unlock()
+
+	go func() {
+		defer b.consumers.Done()
+		defer reader.Close()
+		b.consume(ctx, reader, name, handler)
+	}()
+	return nil
+}
+
+// Close stops the subscriptions, then flushes buffered events and closes
+// the writer
+func (b *KafkaBroker) Close() error {
+	b.mutex.Lock()
+	for _, cancel := range b.cancels {
+		cancel()
+	}
+	b.cancels = nil
+	b.mutex.Unlock()
+
+	b.consumers.Wait()
+	return b.KafkaPublisher.Close()
+}
+
+// consume hands the messages of reader to handler until ctx is done
+func (b *KafkaBroker) consume(ctx context.Context, reader MessageReader, name string, handler Handler) {
+	for {
+		message, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.WithError(err).WithField("subscription", name).Error("Failed to fetch event from Kafka")
+			if !sleep(ctx, b.config.RedeliveryDelay) {
+				return
+			}
+			continue
+		}
+
+		if !b.deliver(ctx, name, message, handler) {
+			return
+		}
+		if err := reader.CommitMessages(ctx, message); err != nil && ctx.Err() == nil {
+			log.WithError(err).WithField("subscription", name).Error("Failed to commit Kafka offset")
+		}
+	}
+}
+
+// deliver hands a message to handler until it succeeds or MaxDeliveries is
+// reached. It returns false if ctx was done before the message was handled,
+// which leaves it for the next consumer of the group.
+func (b *KafkaBroker) deliver(ctx context.Context, name string, message kafka.Message, handler Handler) bool {
+	// Tombstones only follow the event of a deletion, which was handled
+	if message.Value == nil {
+		return true
+	}
+
+	event, err := b.config.serializer().Deserialize(ctx, message)
+	if err != nil {
+		log.WithError(err).WithFields(logger.Fields{
+			"subscription": name,
+			"offset":       message.Offset,
+		}).Error("Skipping undecodable event")
+		return true
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := handler(event.Context(ctx), event)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+
+		fields := logger.Fields{
+			"subscription": name,
+			"event_id":     event.ID,
+			"event_type":   event.Type,
+			"attempt":      attempt,
+		}
+		if attempt >= b.config.MaxDeliveries {
+			log.WithError(err).WithFields(fields).Error("Giving up on event")
+			return true
+		}
+		log.WithError(err).WithFields(fields).Warn("Failed to handle event, retrying")
+		if !sleep(ctx, b.config.RedeliveryDelay*time.Duration(attempt)) {
+			return false
+		}
+	}
+}
+
+// sleep waits for d, returning false if ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// PingKafka checks that at least one of the brokers accepts connections
+func PingKafka(ctx context.Context, brokers []string) error {
+	var err error
+	for _, broker := range brokers {
+		var conn *kafka.Conn
+		if conn, err = kafka.DialContext(ctx, "tcp", broker); err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
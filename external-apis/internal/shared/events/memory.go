@@ -0,0 +1,51 @@
+package events
+
+import "sync"
+
+// MemoryPublisher records published events in memory. It is intended for
+// tests and local runs without a broker.
+type MemoryPublisher struct {
+	events []Event
+	mutex  sync.Mutex
+}
+
+// NewMemoryPublisher creates a new in-memory publisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish records the event
+func (p *MemoryPublisher) Publish(event Event) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.events = append(p.events, event)
+}
+
+// Events returns the recorded events in publish order
+func (p *MemoryPublisher) Events() []Event {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]Event(nil), p.events...)
+}
+
+// Types returns the types of the recorded events in publish order
+func (p *MemoryPublisher) Types() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	types := make([]string, len(p.events))
+	for i, event := range p.events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+// Reset discards the recorded events
+func (p *MemoryPublisher) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.events = nil
+}
@@ -0,0 +1,252 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsFlushTimeout bounds how long Close waits for the acknowledgements of
+// events still being published
+const natsFlushTimeout = 5 * time.Second
+
+// NATSConfig configures the NATS JetStream broker
+type NATSConfig struct {
+	URL string
+	// Stream is the JetStream stream keeping the events; it is created when
+	// missing
+	Stream string
+	// Subject prefixes the subjects events are published on, followed by
+	// their type, e.g. product-events.product.created
+	Subject string
+	// AckWait bounds how long a handler may take before its event is
+	// delivered again
+	AckWait time.Duration
+	// Encoder encodes the published events as CloudEvents
+	Encoder Encoder
+	// DeadLetters parks events the broker did not accept so they can be
+	// replayed; they are only logged when it is nil
+	DeadLetters *deadletter.Queue
+	// MaxDeliveries bounds how many times a consumed event is handed to its
+	// handler before it is skipped, and RedeliveryDelay is the wait before
+	// the first retry, growing with every attempt
+	MaxDeliveries   int
+	RedeliveryDelay time.Duration
+}
+
+// NATSBroker publishes events to a JetStream stream and consumes them through
+// durable consumers, one per subscription name. Events are published on
+// the subject of their type and deduplicated by ID.
+type NATSBroker struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	config NATSConfig
+
+	mutex     sync.Mutex
+	consumers []jetstream.ConsumeContext
+}
+
+// NewNATSBroker connects to the NATS server of config and creates the
+// stream if it is missing. The connection is re-established in the
+// background whenever it drops.
+func NewNATSBroker(ctx context.Context, config NATSConfig) (*NATSBroker, error) {
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = 1
+	}
+
+	conn, err := nats.Connect(config.URL, nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+
+	broker := &NATSBroker{conn: conn, config: config}
+	broker.js, err = jetstream.New(conn, jetstream.WithPublishAsyncErrHandler(broker.publishFailed))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	_, err = broker.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     config.Stream,
+		Subjects: []string{config.Subject + ".>"},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return broker, nil
+}
+
+// Name identifies the broker
+func (b *NATSBroker) Name() string {
+	return deadletter.SourceNATS
+}
+
+// Publish sends the event to the stream without waiting for its
+// acknowledgement; events the stream rejects are dead-lettered
+func (b *NATSBroker) Publish(event Event) {
+	value, err := b.config.Encoder.Encode(event)
+	if err != nil {
+		log.WithError(err).WithField("event_type", event.Type).Error("Failed to encode event")
+		return
+	}
+
+	message := b.newMessage(event.Type, value)
+	if _, err := b.js.PublishMsgAsync(message, jetstream.WithMsgID(event.ID)); err != nil {
+		b.publishFailed(b.js, message, err)
+	}
+}
+
+// Replay publishes a parked event again. It implements deadletter.Replayer.
+func (b *NATSBroker) Replay(ctx context.Context, entry *deadletter.Entry) error {
+	_, err := b.js.PublishMsg(ctx, b.newMessage(entry.EventType, entry.Event), jetstream.WithMsgID(entry.EventID))
+	return err
+}
+
+// Ping checks that JetStream answers
+func (b *NATSBroker) Ping(ctx context.Context) error {
+	_, err := b.js.AccountInfo(ctx)
+	return err
+}
+
+// Subscribe consumes the events of the stream through the durable consumer
+// name. Events are acknowledged once handled; failed ones are delivered
+// again after RedeliveryDelay, to any instance of the subscription, until
+// MaxDeliveries is reached.
+func (b *NATSBroker) Subscribe(ctx context.Context, name string, handler Handler) error {
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.config.Stream, jetstream.ConsumerConfig{
+		Durable:       name,
+		FilterSubject: b.config.Subject + ".>",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       b.config.AckWait,
+		MaxDeliver:    b.config.MaxDeliveries,
+	})
+	if err != nil {
+		return err
+	}
+
+	consume, err := consumer.Consume(func(message jetstream.Msg) {
+		b.deliver(ctx, name, message, handler)
+	})
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	b.consumers = append(b.consumers, consume)
+	b.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		consume.Stop()
+	}()
+	return nil
+}
+
+// Close stops the subscriptions, waits for the events being published and
+// closes the connection
+func (b *NATSBroker) Close() error {
+	b.mutex.Lock()
+	for _, consume := range b.consumers {
+		consume.Stop()
+	}
+	b.consumers = nil
+	b.mutex.Unlock()
+
+	var err error
+	select {
+	case <-b.js.PublishAsyncComplete():
+	case <-time.After(natsFlushTimeout):
+		err = errors.New("timed out waiting for NATS to acknowledge events")
+	}
+
+	b.conn.Close()
+	return err
+}
+
+// deliver hands a message to handler and acknowledges it, or asks for it to
+// be delivered again if the handler failed
+func (b *NATSBroker) deliver(ctx context.Context, name string, message jetstream.Msg, handler Handler) {
+	event, err := Decode(message.Data())
+	if err != nil {
+		log.WithError(err).WithFields(logger.Fields{
+			"subscription": name,
+			"subject":      message.Subject(),
+		}).Error("Skipping undecodable event")
+		_ = message.Term()
+		return
+	}
+
+	err = handler(event.Context(ctx), event)
+	if err == nil {
+		_ = message.Ack()
+		return
+	}
+
+	attempt := 1
+	if metadata, metadataErr := message.Metadata(); metadataErr == nil {
+		attempt = int(metadata.NumDelivered)
+	}
+	fields := logger.Fields{
+		"subscription": name,
+		"event_id":     event.ID,
+		"event_type":   event.Type,
+		"attempt":      attempt,
+	}
+	if attempt >= b.config.MaxDeliveries {
+		log.WithError(err).WithFields(fields).Error("Giving up on event")
+		_ = message.Term()
+		return
+	}
+	log.WithError(err).WithFields(fields).Warn("Failed to handle event, retrying")
+	_ = message.NakWithDelay(b.config.RedeliveryDelay * time.Duration(attempt))
+}
+
+// newMessage builds the message of an encoded event, on the subject of its
+// type
+func (b *NATSBroker) newMessage(eventType string, value []byte) *nats.Msg {
+	message := nats.NewMsg(b.config.Subject + "." + eventType)
+	message.Data = value
+	message.Header.Set(HeaderEventType, eventType)
+	message.Header.Set(HeaderContentType, cloudevents.ContentType)
+	return message
+}
+
+// publishFailed logs and dead-letters an event the stream did not accept
+func (b *NATSBroker) publishFailed(_ jetstream.JetStream, message *nats.Msg, err error) {
+	eventType := message.Header.Get(HeaderEventType)
+	log.WithError(err).WithFields(logger.Fields{
+		"subject":    message.Subject,
+		"event_type": eventType,
+	}).Error("Failed to publish event to NATS")
+
+	if b.config.DeadLetters == nil {
+		return
+	}
+
+	var event struct {
+		ID      string `json:"id"`
+		Subject string `json:"subject"`
+	}
+	_ = json.Unmarshal(message.Data, &event)
+
+	b.config.DeadLetters.Park(deadletter.Entry{
+		Source:      deadletter.SourceNATS,
+		Destination: message.Subject,
+		EventID:     event.ID,
+		EventType:   eventType,
+		Subject:     event.Subject,
+		Event:       message.Data,
+		Error:       err.Error(),
+		Attempts:    1,
+	})
+}
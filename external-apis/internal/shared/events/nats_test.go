@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/deadletter"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startNATS runs an in-process NATS server with JetStream enabled
+func startNATS(t *testing.T) string {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+	require.True(t, srv.ReadyForConnections(5*time.Second), "NATS server did not start")
+	t.Cleanup(srv.Shutdown)
+	return srv.ClientURL()
+}
+
+// newTestNATSBroker connects a broker to the server at url
+func newTestNATSBroker(t *testing.T, url string, maxDeliveries int) *NATSBroker {
+	t.Helper()
+	broker, err := NewNATSBroker(context.Background(), NATSConfig{
+		URL:             url,
+		Stream:          "product-events",
+		Subject:         "product-events",
+		AckWait:         time.Second,
+		MaxDeliveries:   maxDeliveries,
+		RedeliveryDelay: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = broker.Close() })
+	return broker
+}
+
+// received collects the events handed to a handler
+type received struct {
+	events []Event
+	mutex  sync.Mutex
+}
+
+func (r *received) handle(ctx context.Context, event Event) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *received) ids() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ids := make([]string, len(r.events))
+	for i, event := range r.events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func TestNATSBroker(t *testing.T) {
+	url := startNATS(t)
+
+	t.Run("Publish and consume events", func(t *testing.T) {
+		// Arrange
+		broker := newTestNATSBroker(t, url, 3)
+		got := &received{}
+		require.NoError(t, broker.Subscribe(context.Background(), "search-index", got.handle))
+		event := New(ProductCreated, "product-123", map[string]string{"name": "Wireless Mouse"}).For("brand-a")
+
+		// Act
+		broker.Publish(event)
+
+		// Assert
+		require.Eventually(t, func() bool { return len(got.ids()) == 1 }, 5*time.Second, 10*time.Millisecond)
+		consumed := got.events[0]
+		assert.Equal(t, event.ID, consumed.ID)
+		assert.Equal(t, ProductCreated, consumed.Type)
+		assert.Equal(t, "product-123", consumed.Subject)
+		assert.Equal(t, "brand-a", consumed.Tenant)
+		assert.JSONEq(t, `{"name":"Wireless Mouse"}`, string(consumed.Data.(json.RawMessage)))
+	})
+
+	t.Run("Durable subscriptions resume where they left off", func(t *testing.T) {
+		// Arrange
+		broker := newTestNATSBroker(t, url, 3)
+		first := &received{}
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, broker.Subscribe(ctx, "resume", first.handle))
+		handled := New(ProductUpdated, "product-123", nil)
+		broker.Publish(handled)
+		require.Eventually(t, func() bool { return len(first.ids()) > 0 }, 5*time.Second, 10*time.Millisecond)
+		cancel()
+
+		// Act
+		missed := New(ProductDeleted, "product-123", nil)
+		broker.Publish(missed)
+		<-broker.js.PublishAsyncComplete()
+		second := &received{}
+		require.NoError(t, broker.Subscribe(context.Background(), "resume", second.handle))
+
+		// Assert
+		require.Eventually(t, func() bool { return len(second.ids()) > 0 }, 5*time.Second, 10*time.Millisecond)
+		assert.NotContains(t, second.ids(), handled.ID, "handled events are not delivered again")
+		assert.Contains(t, second.ids(), missed.ID)
+	})
+
+	t.Run("Redeliver failed events up to the maximum", func(t *testing.T) {
+		// Arrange
+		broker := newTestNATSBroker(t, url, 3)
+		var mutex sync.Mutex
+		attempts := 0
+		require.NoError(t, broker.Subscribe(context.Background(), "failing", func(ctx context.Context, event Event) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			if event.Type == ProductRestored {
+				attempts++
+			}
+			return errors.New("search index down")
+		}))
+
+		// Act
+		broker.Publish(New(ProductRestored, "product-123", nil))
+
+		// Assert
+		require.Eventually(t, func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return attempts == 3
+		}, 5*time.Second, 10*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		mutex.Lock()
+		assert.Equal(t, 3, attempts, "the event is not delivered after the last attempt")
+		mutex.Unlock()
+	})
+
+	t.Run("Replay parked event", func(t *testing.T) {
+		// Arrange
+		broker := newTestNATSBroker(t, url, 3)
+		got := &received{}
+		require.NoError(t, broker.Subscribe(context.Background(), "replay", got.handle))
+		event := New(ProductStockChanged, "product-123", nil)
+		value, err := Encoder{}.Encode(event)
+		require.NoError(t, err)
+
+		// Act
+		err = broker.Replay(context.Background(), &deadletter.Entry{
+			EventID:   event.ID,
+			EventType: event.Type,
+			Subject:   event.Subject,
+			Event:     value,
+		})
+
+		// Assert
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			for _, id := range got.ids() {
+				if id == event.ID {
+					return true
+				}
+			}
+			return false
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		// Arrange
+		broker := newTestNATSBroker(t, url, 3)
+
+		// Act
+		err := broker.Ping(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Server unreachable", func(t *testing.T) {
+		// Act
+		_, err := NewNATSBroker(context.Background(), NATSConfig{
+			URL:     "nats://127.0.0.1:1",
+			Stream:  "product-events",
+			Subject: "product-events",
+		})
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
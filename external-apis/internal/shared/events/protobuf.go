@@ -0,0 +1,246 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	eventsv1 "external-apis/api/events/v1"
+	"external-apis/internal/shared/schemaregistry"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// timestampName is the full name of the messages holding times
+const timestampName = "google.protobuf.Timestamp"
+
+// ProtobufCodec serializes event data in protobuf as the messages of
+// api/events/v1. The .proto file of a message is registered as its schema.
+type ProtobufCodec struct {
+	registry schemaregistry.Registry
+}
+
+// NewProtobufCodec creates a codec registering its schemas with registry
+func NewProtobufCodec(registry schemaregistry.Registry) *ProtobufCodec {
+	return &ProtobufCodec{registry: registry}
+}
+
+// ContentType implements DataCodec
+func (c *ProtobufCodec) ContentType() string {
+	return "application/protobuf"
+}
+
+// Marshal implements DataCodec. Fields of data the message does not have
+// are left out.
+func (c *ProtobufCodec) Marshal(ctx context.Context, topic, eventType string, data []byte) ([]byte, error) {
+	messageType, err := protoMessageType(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := decodeData(data)
+	if err != nil {
+		return nil, err
+	}
+	message := messageType.New()
+	if err := protoFromJSON(message, document); err != nil {
+		return nil, fmt.Errorf("%s: %w", eventType, err)
+	}
+	payload, err := proto.Marshal(message.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	descriptor := messageType.Descriptor()
+	text, err := fs.ReadFile(eventsv1.Proto, path.Base(descriptor.ParentFile().Path()))
+	if err != nil {
+		return nil, err
+	}
+	id, err := c.registry.Register(ctx, schemaregistry.Subject(topic, string(descriptor.FullName())), schemaregistry.Schema{
+		Type:   schemaregistry.TypeProtobuf,
+		Schema: string(text),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemaregistry.Frame(id, append(messageIndexes(descriptor), payload...)), nil
+}
+
+// Unmarshal implements DataCodec. Protobuf messages are read with the
+// message of the event type, which reads the data of earlier and later
+// versions of it alike.
+func (c *ProtobufCodec) Unmarshal(ctx context.Context, eventType string, value []byte) (json.RawMessage, error) {
+	messageType, err := protoMessageType(eventType)
+	if err != nil {
+		return nil, err
+	}
+	_, framed, err := schemaregistry.Unframe(value)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := skipMessageIndexes(framed)
+	if err != nil {
+		return nil, err
+	}
+
+	message := messageType.New().Interface()
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(message)
+}
+
+// protoMessageType returns the message the data of eventType follows
+func protoMessageType(eventType string) (protoreflect.MessageType, error) {
+	name, err := record(eventType)
+	if err != nil {
+		return nil, err
+	}
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(schemaNamespace + "." + name))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrNoSchema, eventType, err)
+	}
+	return messageType, nil
+}
+
+// messageIndexes encodes the path to a message in its file, which follows
+// the schema ID in the wire format: the count and indexes as zigzag
+// varints, or a single 0 for the first message of the file
+func messageIndexes(descriptor protoreflect.MessageDescriptor) []byte {
+	var indexes []int
+	for d := protoreflect.Descriptor(descriptor); d != nil; d = d.Parent() {
+		if message, ok := d.(protoreflect.MessageDescriptor); ok {
+			indexes = append([]int{message.Index()}, indexes...)
+		}
+	}
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return []byte{0}
+	}
+
+	encoded := protowire.AppendVarint(nil, protowire.EncodeZigZag(int64(len(indexes))))
+	for _, index := range indexes {
+		encoded = protowire.AppendVarint(encoded, protowire.EncodeZigZag(int64(index)))
+	}
+	return encoded
+}
+
+// skipMessageIndexes returns the payload following the message indexes
+func skipMessageIndexes(value []byte) ([]byte, error) {
+	count, n := protowire.ConsumeVarint(value)
+	if n < 0 {
+		return nil, schemaregistry.ErrInvalidFrame
+	}
+	value = value[n:]
+	for i := int64(0); i < protowire.DecodeZigZag(count); i++ {
+		if _, n = protowire.ConsumeVarint(value); n < 0 {
+			return nil, schemaregistry.ErrInvalidFrame
+		}
+		value = value[n:]
+	}
+	return value, nil
+}
+
+// protoFromJSON sets the fields of message from JSON data decoded with
+// UseNumber, matching them by their JSON name. Nulls are left unset.
+func protoFromJSON(message protoreflect.Message, value interface{}) error {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: expected an object for %s, got %T", ErrInvalidData, message.Descriptor().Name(), value)
+	}
+
+	descriptors := message.Descriptor().Fields()
+	for i := 0; i < descriptors.Len(); i++ {
+		field := descriptors.Get(i)
+		fieldValue, ok := fields[field.JSONName()]
+		if !ok || fieldValue == nil {
+			continue
+		}
+
+		if field.IsList() {
+			items, ok := fieldValue.([]interface{})
+			if !ok {
+				return fmt.Errorf("%w: %s: expected an array, got %T", ErrInvalidData, field.JSONName(), fieldValue)
+			}
+			list := message.Mutable(field).List()
+			for _, item := range items {
+				element, err := protoValue(field, item, list.NewElement())
+				if err != nil {
+					return fmt.Errorf("%s: %w", field.JSONName(), err)
+				}
+				list.Append(element)
+			}
+			continue
+		}
+
+		converted, err := protoValue(field, fieldValue, message.NewField(field))
+		if err != nil {
+			return fmt.Errorf("%s: %w", field.JSONName(), err)
+		}
+		message.Set(field, converted)
+	}
+	return nil
+}
+
+// protoValue converts a JSON value into the value of field. empty is a new
+// value of the field, filled in for messages.
+func protoValue(field protoreflect.FieldDescriptor, value interface{}, empty protoreflect.Value) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		text, err := jsonString(value)
+		return protoreflect.ValueOfString(text), err
+	case protoreflect.BoolKind:
+		boolean, ok := value.(bool)
+		if !ok {
+			return protoreflect.Value{}, fmt.Errorf("%w: expected a boolean, got %T", ErrInvalidData, value)
+		}
+		return protoreflect.ValueOfBool(boolean), nil
+	case protoreflect.Int32Kind, protoreflect.Int64Kind:
+		number, err := jsonNumber(value)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		integer, err := number.Int64()
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		if field.Kind() == protoreflect.Int32Kind {
+			return protoreflect.ValueOfInt32(int32(integer)), nil
+		}
+		return protoreflect.ValueOfInt64(integer), nil
+	case protoreflect.DoubleKind:
+		number, err := jsonNumber(value)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		float, err := number.Float64()
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		return protoreflect.ValueOfFloat64(float), nil
+	case protoreflect.MessageKind:
+		if field.Message().FullName() == timestampName {
+			text, err := jsonString(value)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			at, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return protoreflect.Value{}, fmt.Errorf("%w: %v", ErrInvalidData, err)
+			}
+			return protoreflect.ValueOfMessage(timestamppb.New(at).ProtoReflect()), nil
+		}
+		if err := protoFromJSON(empty.Message(), value); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return empty, nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("%w: unsupported protobuf kind %s", ErrInvalidData, field.Kind())
+	}
+}
@@ -0,0 +1,235 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"github.com/segmentio/kafka-go"
+)
+
+// Formats events are published to Kafka in
+const (
+	// FormatJSON publishes CloudEvents in the structured JSON format
+	FormatJSON = "json"
+	// FormatAvro and FormatProtobuf publish CloudEvents in the binary
+	// content mode, with data serialized against a registered schema
+	FormatAvro     = "avro"
+	FormatProtobuf = "protobuf"
+)
+
+// headerPrefix prefixes the headers carrying the attributes of an event in
+// the binary content mode
+const headerPrefix = "ce_"
+
+// schemaNamespace is the Avro namespace and protobuf package of the records
+// of api/events/v1
+const schemaNamespace = "events.v1"
+
+// Records maps the event types to the record their data follows, named
+// alike in the Avro and protobuf schemas of api/events/v1
+var Records = map[string]string{
+	CustomerCreated:  "Customer",
+	CustomerUpdated:  "Customer",
+	CustomerDeleted:  "CustomerDeleted",
+	CustomerRestored: "Customer",
+	CustomerMerged:   "CustomerMerged",
+	CustomerVerified: "Customer",
+
+	ProductCreated:      "Product",
+	ProductUpdated:      "Product",
+	ProductDeleted:      "ProductDeleted",
+	ProductRestored:     "Product",
+	ProductStockChanged: "Product",
+	ProductBackInStock:  "BackInStock",
+
+	OrderCreated:       "Order",
+	OrderStatusChanged: "Order",
+}
+
+var (
+	// ErrNoSchema is returned for events whose type has no record
+	ErrNoSchema = errors.New("no schema for event type")
+	// ErrInvalidData is returned for event data that does not follow the
+	// schema of its record
+	ErrInvalidData = errors.New("event data does not follow its schema")
+)
+
+// Serializer turns events into the messages of a Kafka topic and back
+type Serializer interface {
+	// Serialize builds the message of an event published to topic, keyed by
+	// its subject
+	Serialize(ctx context.Context, topic string, event Event) (kafka.Message, error)
+	// Deserialize decodes the event of a consumed message; its data is left
+	// as JSON, a json.RawMessage
+	Deserialize(ctx context.Context, message kafka.Message) (Event, error)
+}
+
+// StructuredSerializer publishes events as CloudEvents in the structured
+// JSON format
+type StructuredSerializer struct {
+	Encoder Encoder
+}
+
+// Serialize implements Serializer
+func (s StructuredSerializer) Serialize(ctx context.Context, topic string, event Event) (kafka.Message, error) {
+	value, err := s.Encoder.Encode(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	return newMessage(event.Subject, event.Type, value, event.OccurredAt), nil
+}
+
+// Deserialize implements Serializer
+func (s StructuredSerializer) Deserialize(ctx context.Context, message kafka.Message) (Event, error) {
+	return Decode(message.Value)
+}
+
+// DataCodec serializes the data of events against the schema of their
+// record, framed with its ID in a schema registry
+type DataCodec interface {
+	// ContentType is the media type of the serialized data
+	ContentType() string
+	// Marshal serializes the JSON data of an event of a type published to
+	// topic, registering its schema under the topic
+	Marshal(ctx context.Context, topic, eventType string, data []byte) ([]byte, error)
+	// Unmarshal deserializes data back into JSON
+	Unmarshal(ctx context.Context, eventType string, value []byte) (json.RawMessage, error)
+}
+
+// BinarySerializer publishes events in the binary content mode of the
+// CloudEvents Kafka binding: their attributes are ce_ headers and their
+// data, serialized by Codec, is the value of the message. Consumers read
+// the data with the schema the registry keeps under the ID it is framed
+// with.
+type BinarySerializer struct {
+	Encoder Encoder
+	Codec   DataCodec
+}
+
+// Serialize implements Serializer
+func (s BinarySerializer) Serialize(ctx context.Context, topic string, event Event) (kafka.Message, error) {
+	cloudEvent, err := s.Encoder.cloudEvent(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	if err := cloudEvent.Validate(); err != nil {
+		return kafka.Message{}, err
+	}
+	value, err := s.Codec.Marshal(ctx, topic, event.Type, cloudEvent.Data)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	message := kafka.Message{
+		Key:   []byte(event.Subject),
+		Value: value,
+		Time:  event.OccurredAt,
+		Headers: []kafka.Header{
+			{Key: HeaderEventType, Value: []byte(event.Type)},
+			{Key: HeaderContentType, Value: []byte(s.Codec.ContentType())},
+		},
+	}
+	for _, attribute := range [][2]string{
+		{"specversion", cloudEvent.SpecVersion},
+		{"id", cloudEvent.ID},
+		{"source", cloudEvent.Source},
+		{"type", cloudEvent.Type},
+		{"subject", cloudEvent.Subject},
+		{"time", cloudEvent.Time.Format(time.RFC3339Nano)},
+		{"dataschema", cloudEvent.DataSchema},
+		{"tenant", cloudEvent.Tenant},
+		{"traceparent", cloudEvent.Traceparent},
+	} {
+		if attribute[1] != "" {
+			message.Headers = append(message.Headers, kafka.Header{Key: headerPrefix + attribute[0], Value: []byte(attribute[1])})
+		}
+	}
+	return message, nil
+}
+
+// Deserialize implements Serializer
+func (s BinarySerializer) Deserialize(ctx context.Context, message kafka.Message) (Event, error) {
+	attributes := make(map[string]string)
+	for _, header := range message.Headers {
+		attributes[header.Key] = string(header.Value)
+	}
+
+	cloudEvent := cloudevents.Event{
+		SpecVersion: attributes[headerPrefix+"specversion"],
+		ID:          attributes[headerPrefix+"id"],
+		Source:      attributes[headerPrefix+"source"],
+		Type:        attributes[headerPrefix+"type"],
+		Subject:     attributes[headerPrefix+"subject"],
+		Tenant:      attributes[headerPrefix+"tenant"],
+		Traceparent: attributes[headerPrefix+"traceparent"],
+	}
+	if err := cloudEvent.Validate(); err != nil {
+		return Event{}, err
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, attributes[headerPrefix+"time"])
+	if err != nil {
+		return Event{}, fmt.Errorf("%w: invalid time: %v", cloudevents.ErrInvalidEvent, err)
+	}
+	data, err := s.Codec.Unmarshal(ctx, cloudEvent.Type, message.Value)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          cloudEvent.ID,
+		Type:        cloudEvent.Type,
+		Subject:     cloudEvent.Subject,
+		OccurredAt:  occurredAt,
+		Tenant:      cloudEvent.Tenant,
+		Traceparent: cloudEvent.Traceparent,
+		Data:        data,
+	}, nil
+}
+
+// record returns the name of the record the data of eventType follows
+func record(eventType string) (string, error) {
+	name, ok := Records[eventType]
+	if !ok {
+		return "", fmt.Errorf("%w %q", ErrNoSchema, eventType)
+	}
+	return name, nil
+}
+
+// jsonNumber converts a number decoded with UseNumber
+func jsonNumber(value interface{}) (json.Number, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return "", fmt.Errorf("%w: expected a number, got %T", ErrInvalidData, value)
+	}
+	return number, nil
+}
+
+// jsonString converts a string, or a number kept as its decimal text, e.g.
+// a price
+func jsonString(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	default:
+		return "", fmt.Errorf("%w: expected a string, got %T", ErrInvalidData, value)
+	}
+}
+
+// decodeData decodes JSON data keeping its numbers exact
+func decodeData(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	return document, nil
+}
@@ -0,0 +1,296 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/schemaregistry"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry keeps registered schemas in memory, numbering them from 1
+type fakeRegistry struct {
+	mutex    sync.Mutex
+	err      error
+	schemas  []schemaregistry.Schema
+	subjects map[string]string
+}
+
+func (r *fakeRegistry) Register(ctx context.Context, subject string, schema schemaregistry.Schema) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.subjects == nil {
+		r.subjects = make(map[string]string)
+	}
+	r.subjects[subject] = schema.Type
+	for i, registered := range r.schemas {
+		if registered == schema {
+			return i + 1, nil
+		}
+	}
+	r.schemas = append(r.schemas, schema)
+	return len(r.schemas), nil
+}
+
+func (r *fakeRegistry) Schema(ctx context.Context, id int) (schemaregistry.Schema, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if id < 1 || id > len(r.schemas) {
+		return schemaregistry.Schema{}, schemaregistry.ErrNotFound
+	}
+	return r.schemas[id-1], nil
+}
+
+// productData is the data of a product event as the product service
+// publishes it, with fields the schemas leave out
+const productData = `{
+	"id": "product-123",
+	"sku": "MOUSE-1",
+	"name": "Wireless Mouse",
+	"description": "Ergonomic mouse",
+	"price": 19.99,
+	"currency": "USD",
+	"categoryId": "category-1",
+	"category": "Accessories",
+	"active": true,
+	"stockQuantity": 10,
+	"reservedQuantity": 2,
+	"availableQuantity": 8,
+	"averageRating": 4.5,
+	"reviewCount": 2,
+	"weightGrams": 90,
+	"tags": ["wireless"],
+	"attributes": [{"name": "color", "value": "black"}],
+	"images": [],
+	"variants": [],
+	"createdAt": "2026-03-01T12:00:00Z",
+	"updatedAt": "2026-03-02T12:00:00.5Z"
+}`
+
+func TestRecords(t *testing.T) {
+	// Arrange
+	avroCodec, err := NewAvroCodec(&fakeRegistry{})
+	require.NoError(t, err)
+
+	for _, eventType := range append(append(append([]string{}, CustomerEvents...), ProductEvents...), OrderEvents...) {
+		t.Run(eventType, func(t *testing.T) {
+			// Act
+			name, err := record(eventType)
+			require.NoError(t, err)
+			_, protoErr := protoMessageType(eventType)
+
+			// Assert
+			assert.Contains(t, avroCodec.schemas, name)
+			assert.NoError(t, protoErr)
+		})
+	}
+}
+
+func TestBinarySerializer(t *testing.T) {
+	ctx := context.Background()
+	occurredAt := time.Date(2026, time.March, 2, 12, 0, 0, 500000000, time.UTC)
+	event := Event{
+		ID:          "event-1",
+		Type:        ProductUpdated,
+		Subject:     "product-123",
+		OccurredAt:  occurredAt,
+		Tenant:      "brand-a",
+		Traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		Data:        json.RawMessage(productData),
+	}
+
+	avroCodec, err := NewAvroCodec(&fakeRegistry{})
+	require.NoError(t, err)
+
+	codecs := []struct {
+		name        string
+		codec       DataCodec
+		contentType string
+		data        string
+	}{
+		{"Avro", avroCodec, "application/avro", `{
+			"id": "product-123", "sku": "MOUSE-1", "gtin": "", "name": "Wireless Mouse", "description": "Ergonomic mouse",
+			"price": "19.99", "currency": "USD", "categoryId": "category-1", "category": "Accessories", "active": true,
+			"stockQuantity": 10, "reservedQuantity": 2, "availableQuantity": 8, "averageRating": 4.5, "reviewCount": 2,
+			"weightGrams": 90, "tags": ["wireless"],
+			"createdAt": "2026-03-01T12:00:00Z", "updatedAt": "2026-03-02T12:00:00.5Z", "deletedAt": null
+		}`},
+		{"Protobuf", NewProtobufCodec(&fakeRegistry{}), "application/protobuf", `{
+			"id": "product-123", "sku": "MOUSE-1", "gtin": "", "name": "Wireless Mouse", "description": "Ergonomic mouse",
+			"price": "19.99", "currency": "USD", "categoryId": "category-1", "category": "Accessories", "active": true,
+			"stockQuantity": 10, "reservedQuantity": 2, "availableQuantity": 8, "averageRating": 4.5, "reviewCount": 2,
+			"weightGrams": 90, "tags": ["wireless"],
+			"createdAt": "2026-03-01T12:00:00Z", "updatedAt": "2026-03-02T12:00:00.500Z", "deletedAt": null
+		}`},
+	}
+	for _, tt := range codecs {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			serializer := BinarySerializer{Encoder: Encoder{Source: "/product-service"}, Codec: tt.codec}
+
+			// Act
+			message, err := serializer.Serialize(ctx, "product-events", event)
+			require.NoError(t, err)
+			decoded, err := serializer.Deserialize(ctx, message)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, "product-123", string(message.Key))
+			assert.Contains(t, message.Headers, kafka.Header{Key: HeaderContentType, Value: []byte(tt.contentType)})
+			assert.Contains(t, message.Headers, kafka.Header{Key: "ce_source", Value: []byte("/product-service")})
+			assert.Contains(t, message.Headers, kafka.Header{Key: "ce_time", Value: []byte("2026-03-02T12:00:00.5Z")})
+			id, _, err := schemaregistry.Unframe(message.Value)
+			require.NoError(t, err)
+			assert.Equal(t, 1, id)
+
+			assert.Equal(t, event.ID, decoded.ID)
+			assert.Equal(t, ProductUpdated, decoded.Type)
+			assert.Equal(t, "product-123", decoded.Subject)
+			assert.Equal(t, "brand-a", decoded.Tenant)
+			assert.Equal(t, event.Traceparent, decoded.Traceparent)
+			assert.True(t, occurredAt.Equal(decoded.OccurredAt))
+			assert.JSONEq(t, tt.data, string(decoded.Data.(json.RawMessage)))
+		})
+	}
+
+	t.Run("Register under the topic and record", func(t *testing.T) {
+		// Arrange
+		registry := &fakeRegistry{}
+		codec, err := NewAvroCodec(registry)
+		require.NoError(t, err)
+		serializer := BinarySerializer{Codec: codec}
+
+		// Act
+		_, err = serializer.Serialize(ctx, "customer-events", New(CustomerMerged, "customer-2", map[string]string{
+			"survivorId": "customer-1",
+			"mergedId":   "customer-2",
+		}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"customer-events-events.v1.CustomerMerged": schemaregistry.TypeAvro}, registry.subjects)
+	})
+
+	t.Run("Missing field in Avro", func(t *testing.T) {
+		// Act
+		_, err := BinarySerializer{Codec: avroCodec}.Serialize(ctx, "customer-events", New(CustomerDeleted, "customer-1", map[string]string{}))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	invalid := []struct {
+		name  string
+		event Event
+		err   error
+	}{
+		{"Wrong type", New(CustomerDeleted, "customer-1", map[string]bool{"id": true}), ErrInvalidData},
+		{"Unknown event type", New("customer.viewed", "customer-1", map[string]string{"id": "customer-1"}), ErrNoSchema},
+	}
+	for _, tt := range invalid {
+		for _, codec := range codecs {
+			t.Run(tt.name+" in "+codec.name, func(t *testing.T) {
+				// Act
+				_, err := BinarySerializer{Codec: codec.codec}.Serialize(ctx, "customer-events", tt.event)
+
+				// Assert
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+	}
+
+	t.Run("Message without attributes", func(t *testing.T) {
+		// Act
+		_, err := BinarySerializer{Codec: avroCodec}.Deserialize(ctx, kafka.Message{Value: []byte(`{}`)})
+
+		// Assert
+		assert.ErrorIs(t, err, cloudevents.ErrInvalidEvent)
+	})
+}
+
+func TestKafkaPublisher_Binary(t *testing.T) {
+	t.Run("Dead-letter as structured CloudEvents", func(t *testing.T) {
+		// Arrange
+		codec, err := NewAvroCodec(&fakeRegistry{})
+		require.NoError(t, err)
+		encoder := Encoder{Source: "/product-service"}
+		deadLetters := deadletter.NewQueue(deadletter.NewMemoryStore())
+		writer := &fakeWriter{err: errors.New("broker unavailable")}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{
+			Topic:       "product-events",
+			Encoder:     encoder,
+			Serializer:  BinarySerializer{Encoder: encoder, Codec: codec},
+			DeadLetters: deadLetters,
+		})
+		event := New(ProductDeleted, "product-123", map[string]string{"id": "product-123"})
+
+		// Act
+		publisher.Publish(event)
+
+		// Assert
+		parked, err := deadLetters.List(deadletter.Filter{})
+		require.NoError(t, err)
+		require.Len(t, parked, 1)
+		assert.Equal(t, event.ID, parked[0].EventID)
+		decoded, err := Decode(parked[0].Event)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":"product-123"}`, string(decoded.Data.(json.RawMessage)))
+	})
+
+	t.Run("Dead-letter while the registry is unavailable", func(t *testing.T) {
+		// Arrange
+		codec := NewProtobufCodec(&fakeRegistry{err: schemaregistry.ErrUnavailable})
+		deadLetters := deadletter.NewQueue(deadletter.NewMemoryStore())
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{
+			Topic:       "product-events",
+			Serializer:  BinarySerializer{Codec: codec},
+			DeadLetters: deadLetters,
+		})
+		event := New(ProductDeleted, "product-123", map[string]string{"id": "product-123"})
+
+		// Act
+		publisher.Publish(event)
+
+		// Assert
+		assert.Empty(t, writer.messages)
+		parked, err := deadLetters.List(deadletter.Filter{})
+		require.NoError(t, err)
+		require.Len(t, parked, 1)
+		assert.Equal(t, ProductDeleted, parked[0].EventType)
+		assert.Contains(t, parked[0].Error, "schema registry unavailable")
+	})
+
+	t.Run("Replay in the format of the topic", func(t *testing.T) {
+		// Arrange
+		codec := NewProtobufCodec(&fakeRegistry{})
+		writer := &fakeWriter{}
+		publisher := NewKafkaPublisher(writer, KafkaConfig{
+			Topic:      "product-events",
+			Serializer: BinarySerializer{Codec: codec},
+		})
+		value, err := Encoder{}.Encode(New(ProductDeleted, "product-123", map[string]string{"id": "product-123"}))
+		require.NoError(t, err)
+
+		// Act
+		err = publisher.Replay(context.Background(), &deadletter.Entry{EventType: ProductDeleted, Subject: "product-123", Event: value})
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, writer.messages, 1)
+		assert.Contains(t, writer.messages[0].Headers, kafka.Header{Key: HeaderContentType, Value: []byte("application/protobuf")})
+	})
+}
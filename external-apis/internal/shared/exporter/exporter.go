@@ -0,0 +1,221 @@
+package exporter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+// Formats of export files
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// FlushEvery is the number of rows buffered before they are sent to the
+// client as a chunk
+const FlushEvery = 100
+
+// ErrUnsupportedFormat is returned for an export format other than csv or
+// ndjson
+var ErrUnsupportedFormat = apperror.Validation("export format must be csv or ndjson")
+
+// Record is an item that can be exported. NDJSON lines hold the item as
+// JSON; CSV rows hold the cells returned by CSVRecord, in the order of the
+// header the writer was created with.
+type Record interface {
+	CSVRecord() []string
+}
+
+// contentTypes maps each format to the content type of its files
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// ParseFormat validates an export format, defaulting to CSV
+func ParseFormat(format string) (string, error) {
+	if format == "" {
+		return FormatCSV, nil
+	}
+
+	format = strings.ToLower(format)
+	if _, ok := contentTypes[format]; !ok {
+		return "", ErrUnsupportedFormat
+	}
+	return format, nil
+}
+
+// Writer streams an export as the body of a response. Nothing is sent until
+// the first row is written, so a failure before that can still be answered
+// with an ordinary error response. Rows are flushed every FlushEvery rows,
+// which makes the server send the body with chunked transfer encoding.
+type Writer struct {
+	c        *gin.Context
+	format   string
+	filename string
+	header   []string
+	gzip     bool
+
+	out     *bufio.Writer
+	zipper  *gzip.Writer
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+	pending int
+	rows    int
+}
+
+// NewWriter creates a writer for an export of the given format. The file is
+// named after name and the current date, and is gzipped when the client
+// accepts it. header holds the CSV column names.
+func NewWriter(c *gin.Context, format, name string, header []string) *Writer {
+	return &Writer{
+		c:        c,
+		format:   format,
+		filename: fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format),
+		header:   header,
+		gzip:     AcceptsGzip(c.GetHeader("Accept-Encoding")),
+	}
+}
+
+// AcceptsGzip checks if an Accept-Encoding header allows gzip
+func AcceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		return !found || strings.Trim(q, "0.") != ""
+	}
+	return false
+}
+
+// Started reports whether the response headers have been sent
+func (w *Writer) Started() bool {
+	return w.started
+}
+
+// Rows returns the number of rows written
+func (w *Writer) Rows() int {
+	return w.rows
+}
+
+// Write appends a row to the export
+func (w *Writer) Write(record Record) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	var err error
+	if w.format == FormatCSV {
+		err = w.csv.Write(record.CSVRecord())
+	} else {
+		err = w.json.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	w.pending++
+	if w.pending >= FlushEvery {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close completes the export, sending the headers first if no row was
+// written
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if w.zipper != nil {
+		return w.zipper.Close()
+	}
+	return nil
+}
+
+// Abort ends an export that failed after it started. The connection is
+// closed without completing the body, so the client sees a truncated
+// transfer instead of a file that looks complete.
+func (w *Writer) Abort() {
+	if !w.started {
+		return
+	}
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+	w.out.Flush()
+	w.c.Abort()
+
+	conn, _, err := w.c.Writer.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// start sends the response headers and, for CSV, the header row
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+
+	header := w.c.Writer.Header()
+	header.Set("Content-Type", contentTypes[w.format])
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", w.filename))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Add("Vary", "Accept-Encoding")
+	var body io.Writer = w.c.Writer
+	if w.gzip {
+		header.Set("Content-Encoding", "gzip")
+		w.zipper = gzip.NewWriter(w.c.Writer)
+		body = w.zipper
+	}
+	w.out = bufio.NewWriter(body)
+	w.c.Status(http.StatusOK)
+
+	if w.format == FormatNDJSON {
+		w.json = json.NewEncoder(w.out)
+		return nil
+	}
+	w.csv = csv.NewWriter(w.out)
+	return w.csv.Write(w.header)
+}
+
+// flush sends the buffered rows to the client
+func (w *Writer) flush() error {
+	w.pending = 0
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := w.out.Flush(); err != nil {
+		return err
+	}
+	if w.zipper != nil {
+		if err := w.zipper.Flush(); err != nil {
+			return err
+		}
+	}
+	w.c.Writer.Flush()
+	return nil
+}
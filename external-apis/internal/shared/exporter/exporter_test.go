@@ -0,0 +1,146 @@
+package exporter
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// item is the record type exported in the tests
+type item struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+func (i item) CSVRecord() []string {
+	return []string{i.SKU, strings.Repeat("x", i.Quantity)}
+}
+
+// export writes the items through a writer and returns the recorded response
+func export(t *testing.T, format, acceptEncoding string, items ...item) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("GET", "/items/export", nil)
+	c.Request.Header.Set("Accept-Encoding", acceptEncoding)
+
+	writer := NewWriter(c, format, "items", []string{"sku", "quantity"})
+	for _, i := range items {
+		require.NoError(t, writer.Write(i))
+	}
+	require.NoError(t, writer.Close())
+	assert.Equal(t, len(items), writer.Rows())
+	return recorder
+}
+
+func TestWriter_CSV(t *testing.T) {
+	// Act
+	recorder := export(t, FormatCSV, "", item{SKU: "SKU-1", Quantity: 2}, item{SKU: "SKU,2", Quantity: 0})
+
+	// Assert
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="items-\d{8}\.csv"$`, recorder.Header().Get("Content-Disposition"))
+	assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "sku,quantity\nSKU-1,xx\n\"SKU,2\",\n", recorder.Body.String())
+}
+
+func TestWriter_NDJSONGzip(t *testing.T) {
+	// Act
+	recorder := export(t, FormatNDJSON, "br, gzip;q=0.8", item{SKU: "SKU-1", Quantity: 2}, item{SKU: "SKU-2", Quantity: 5})
+
+	// Assert
+	assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+
+	reader, err := gzip.NewReader(recorder.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"sku\":\"SKU-1\",\"quantity\":2}\n{\"sku\":\"SKU-2\",\"quantity\":5}\n", string(body))
+}
+
+func TestWriter_Empty(t *testing.T) {
+	// Act
+	recorder := export(t, FormatCSV, "")
+
+	// Assert
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "sku,quantity\n", recorder.Body.String(), "an empty export still has its header row")
+}
+
+func TestWriter_FlushesInChunks(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("GET", "/items/export", nil)
+	writer := NewWriter(c, FormatNDJSON, "items", nil)
+
+	// Act
+	for i := 0; i < FlushEvery-1; i++ {
+		require.NoError(t, writer.Write(item{SKU: "SKU"}))
+	}
+	beforeFlush := recorder.Body.Len()
+	require.NoError(t, writer.Write(item{SKU: "SKU"}))
+
+	// Assert
+	assert.True(t, writer.Started())
+	assert.Zero(t, beforeFlush, "rows are buffered until a chunk is full")
+	assert.Equal(t, FlushEvery, strings.Count(recorder.Body.String(), "\n"))
+	assert.True(t, recorder.Flushed)
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+		err    error
+	}{
+		{name: "Default", format: "", want: FormatCSV},
+		{name: "NDJSON", format: "NDJSON", want: FormatNDJSON},
+		{name: "Unsupported", format: "xlsx", err: ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			format, err := ParseFormat(tt.format)
+
+			// Assert
+			assert.Equal(t, tt.want, format)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: "", want: false},
+		{header: "gzip", want: true},
+		{header: "deflate, GZIP;q=0.5", want: true},
+		{header: "gzip;q=0", want: false},
+		{header: "gzip; q=0.000, br", want: false},
+		{header: "br", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, AcceptsGzip(tt.header))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/tenant"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+var log = logger.New("shared/grpcserver")
+
+// New creates a gRPC server with the shared interceptor chain, which runs
+// before interceptors passed in opts. Deadlines set by gRPC callers
+// propagate natively through the request context.
+func New(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(
+		CorrelationInterceptor(),
+		RecoveryInterceptor(),
+		LoggingInterceptor(),
+	)}, opts...)
+
+	server := grpc.NewServer(opts...)
+	reflection.Register(server)
+
+	return server
+}
+
+// CorrelationInterceptor stores the x-request-id and traceparent metadata in
+// the call context, the gRPC counterpart of middleware.RequestID. Calls
+// without a request ID get a new one, returned in the response header.
+func CorrelationInterceptor() grpc.UnaryServerInterceptor {
+	requestIDKey := strings.ToLower(logger.RequestIDHeader)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var requestID, traceparent string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(requestIDKey); len(values) > 0 {
+				requestID = values[0]
+			}
+			if values := md.Get(logger.TraceparentHeader); len(values) > 0 {
+				traceparent = values[0]
+			}
+		}
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+		ctx = logger.WithRequestID(ctx, requestID)
+		if trace, ok := logger.ParseTraceparent(traceparent); ok {
+			ctx = logger.WithTrace(ctx, trace)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// LoggingInterceptor logs every unary call with its status code and latency
+func LoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		log.Ctx(ctx).WithFields(logger.Fields{
+			"method":  info.FullMethod,
+			"code":    status.Code(err).String(),
+			"latency": time.Since(start),
+		}).Info("gRPC Request")
+
+		return resp, err
+	}
+}
+
+// TenantInterceptor scopes the call context to the tenant named by the
+// x-tenant-id metadata, the gRPC counterpart of tenant.Middleware
+func TenantInterceptor(config tenant.Config) grpc.UnaryServerInterceptor {
+	key := strings.ToLower(tenant.Header)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var value string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(key); len(values) > 0 {
+				value = values[0]
+			}
+		}
+
+		id, err := config.Resolve(value)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid "+key+" metadata: "+err.Error())
+		}
+		return handler(tenant.WithTenant(ctx, id), req)
+	}
+}
+
+// RecoveryInterceptor converts panics into Internal errors
+func RecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Ctx(ctx).WithFields(logger.Fields{
+					"method": info.FullMethod,
+					"panic":  recovered,
+				}).Error("Panic recovered")
+				err = status.Error(codes.Internal, "Internal server error occurred")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// Shutdown stops the server gracefully, waiting for in-flight calls until ctx
+// is done and then cancelling whatever is still running
+func Shutdown(ctx context.Context, server *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,183 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("shared/healthcheck")
+
+// Check reports whether a dependency can serve requests. It should return
+// once the dependency has answered or the context is done.
+type Check func(ctx context.Context) error
+
+// Status values reported by the readiness probe
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report aggregates the results of every registered check
+type Report struct {
+	Ready  bool              `json:"-"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Registry holds the dependency checks that decide whether the service is
+// ready to receive traffic. Liveness never depends on them, so an outage of a
+// dependency takes the instance out of rotation without restarting it.
+type Registry struct {
+	service string
+	version string
+	timeout time.Duration
+
+	mutex    sync.RWMutex
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+// NewRegistry creates a registry that gives each check at most timeout to
+// complete
+func NewRegistry(service, version string, timeout time.Duration) *Registry {
+	return &Registry{
+		service: service,
+		version: version,
+		timeout: timeout,
+	}
+}
+
+// Register adds a dependency check
+func (r *Registry) Register(name string, check Check) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.checks = append(r.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes the service report not ready from now on, so load balancers
+// stop routing new requests to it while in-flight requests complete
+func (r *Registry) Drain() {
+	r.draining.Store(true)
+}
+
+// Run executes every check concurrently and reports the service ready only
+// if all of them pass
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mutex.RLock()
+	checks := append([]namedCheck(nil), r.checks...)
+	r.mutex.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Ready: !r.draining.Load(), Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusUp {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+// run executes a single check within the per-check timeout
+func (r *Registry) run(ctx context.Context, c namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.check(ctx)
+	result := Result{Status: StatusUp, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		log.Ctx(ctx).WithError(err).WithField("check", c.name).Warn("Readiness check failed")
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// RegisterRoutes registers the probes on the given group: /live answers as
+// long as the process can serve HTTP, /ready runs the dependency checks. The
+// group root is kept as an alias of /live for existing clients.
+func (r *Registry) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", r.handleLive)
+	router.GET("/live", r.handleLive)
+	router.GET("/ready", r.handleReady)
+}
+
+// handleLive reports that the process is running
+func (r *Registry) handleLive(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "healthy",
+		"service": r.service,
+		"version": r.version,
+	})
+}
+
+// handleReady reports whether every dependency is available
+func (r *Registry) handleReady(c *gin.Context) {
+	report := r.Run(c.Request.Context())
+
+	status, code := "ready", http.StatusOK
+	if !report.Ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, gin.H{
+		"status":  status,
+		"service": r.service,
+		"version": r.version,
+		"checks":  report.Checks,
+	})
+}
+
+// HTTP checks a downstream service by requesting url and expecting a 2xx
+// response, typically the liveness probe of that service
+func HTTP(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(ctx context.Context) error {
+	return nil
+}
+
+func TestRegistry_Run(t *testing.T) {
+	t.Run("Ready when every check passes", func(t *testing.T) {
+		// Arrange
+		registry := NewRegistry("test-service", "1.0.0", time.Second)
+		registry.Register("redis", up)
+		registry.Register("kafka", up)
+
+		// Act
+		report := registry.Run(context.Background())
+
+		// Assert
+		assert.True(t, report.Ready)
+		assert.Len(t, report.Checks, 2)
+		assert.Equal(t, StatusUp, report.Checks["redis"].Status)
+		assert.Equal(t, StatusUp, report.Checks["kafka"].Status)
+	})
+
+	t.Run("Not ready when a check fails", func(t *testing.T) {
+		// Arrange
+		registry := NewRegistry("test-service", "1.0.0", time.Second)
+		registry.Register("redis", up)
+		registry.Register("kafka", func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})
+
+		// Act
+		report := registry.Run(context.Background())
+
+		// Assert
+		assert.False(t, report.Ready)
+		assert.Equal(t, StatusUp, report.Checks["redis"].Status)
+		assert.Equal(t, StatusDown, report.Checks["kafka"].Status)
+		assert.Equal(t, "connection refused", report.Checks["kafka"].Error)
+	})
+
+	t.Run("Bounds slow checks by the timeout", func(t *testing.T) {
+		// Arrange
+		registry := NewRegistry("test-service", "1.0.0", 20*time.Millisecond)
+		registry.Register("product-service", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		// Act
+		report := registry.Run(context.Background())
+
+		// Assert
+		assert.False(t, report.Ready)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["product-service"].Error)
+	})
+
+	t.Run("Not ready once draining", func(t *testing.T) {
+		// Arrange
+		registry := NewRegistry("test-service", "1.0.0", time.Second)
+		registry.Register("redis", up)
+
+		// Act
+		registry.Drain()
+		report := registry.Run(context.Background())
+
+		// Assert
+		assert.False(t, report.Ready)
+		assert.Equal(t, StatusUp, report.Checks["redis"].Status)
+	})
+}
+
+func TestRegistry_Routes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	failing := false
+	registry := NewRegistry("test-service", "1.0.0", time.Second)
+	registry.Register("redis", func(ctx context.Context) error {
+		if failing {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	router := gin.New()
+	registry.RegisterRoutes(router.Group("/health"))
+
+	serve := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		// Act
+		code, body := serve("/health/ready")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ready", body["status"])
+		assert.Equal(t, "test-service", body["service"])
+	})
+
+	t.Run("Not ready", func(t *testing.T) {
+		// Arrange
+		failing = true
+		defer func() { failing = false }()
+
+		// Act
+		code, body := serve("/health/ready")
+
+		// Assert
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "not_ready", body["status"])
+		checks := body["checks"].(map[string]interface{})
+		assert.Equal(t, "connection refused", checks["redis"].(map[string]interface{})["error"])
+	})
+
+	t.Run("Live regardless of dependencies", func(t *testing.T) {
+		// Arrange
+		failing = true
+		defer func() { failing = false }()
+
+		for _, path := range []string{"/health", "/health/live"} {
+			// Act
+			code, body := serve(path)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "healthy", body["status"])
+		}
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health/live" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("Healthy service", func(t *testing.T) {
+		// Act
+		err := HTTP(server.Client(), server.URL+"/health/live")(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Unhealthy service", func(t *testing.T) {
+		// Act
+		err := HTTP(server.Client(), server.URL+"/other")(context.Background())
+
+		// Assert
+		assert.EqualError(t, err, "unexpected status 503")
+	})
+}
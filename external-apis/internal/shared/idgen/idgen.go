@@ -0,0 +1,140 @@
+// Package idgen generates the IDs of new records. Generators also recognise
+// the IDs they generate, so the API can reject malformed IDs before looking
+// them up.
+package idgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Formats of the generated IDs
+const (
+	// FormatUUIDv7 IDs sort by the time they were generated
+	FormatUUIDv7 = "uuidv7"
+	// FormatULID IDs sort by the time they were generated too and are
+	// shorter, e.g. 01HQ3Z8X5K7V2M4N6P8R0T2W4Y
+	FormatULID = "ulid"
+	// FormatUUIDv4 IDs are fully random
+	FormatUUIDv4 = "uuidv4"
+)
+
+// Generator generates the IDs of new records. It is safe for concurrent use.
+type Generator interface {
+	// New returns a new ID
+	New() string
+	// Valid reports whether id has the format of the IDs New returns
+	Valid(id string) bool
+}
+
+// New returns the generator of IDs of the given format, starting with prefix
+func New(format, prefix string) (Generator, error) {
+	var generator Generator
+	switch format {
+	case FormatUUIDv7:
+		generator = UUIDv7()
+	case FormatULID:
+		generator = ULID()
+	case FormatUUIDv4:
+		generator = UUIDv4()
+	default:
+		return nil, fmt.Errorf("unknown ID format %q, use %s, %s or %s", format, FormatUUIDv7, FormatULID, FormatUUIDv4)
+	}
+
+	if prefix == "" {
+		return generator, nil
+	}
+	return Prefixed(prefix, generator), nil
+}
+
+// Default returns the generator of the repositories no generator is given
+// to, which generates UUIDv7s
+func Default() Generator {
+	return UUIDv7()
+}
+
+// uuidGenerator generates UUIDs of a version
+type uuidGenerator struct {
+	version uuid.Version
+	new     func() (uuid.UUID, error)
+}
+
+// UUIDv7 returns the generator of UUIDv7s, which sort by the millisecond
+// they were generated in and, within it, by the order they were generated in
+func UUIDv7() Generator {
+	return uuidGenerator{version: 7, new: uuid.NewV7}
+}
+
+// UUIDv4 returns the generator of random UUIDv4s
+func UUIDv4() Generator {
+	return uuidGenerator{version: 4, new: uuid.NewRandom}
+}
+
+// New returns a new UUID in its canonical lowercase form
+func (g uuidGenerator) New() string {
+	return uuid.Must(g.new()).String()
+}
+
+// Valid reports whether id is a UUID of the version in its canonical form
+func (g uuidGenerator) Valid(id string) bool {
+	parsed, err := uuid.Parse(id)
+	return err == nil && parsed.Version() == g.version && parsed.String() == id
+}
+
+// prefixed generates IDs starting with a prefix naming the type of record
+type prefixed struct {
+	prefix    string
+	generator Generator
+}
+
+// Prefixed returns the generator of the IDs of generator starting with
+// prefix, e.g. "cus_" for customers
+func Prefixed(prefix string, generator Generator) Generator {
+	return prefixed{prefix: prefix, generator: generator}
+}
+
+// New returns a new ID starting with the prefix
+func (g prefixed) New() string {
+	return g.prefix + g.generator.New()
+}
+
+// Valid reports whether id is the prefix followed by an ID of the generator
+func (g prefixed) Valid(id string) bool {
+	rest, ok := strings.CutPrefix(id, g.prefix)
+	return ok && g.generator.Valid(rest)
+}
+
+// legacyID matches the hand-written IDs of the sample data, e.g.
+// customer-456 or category-electronics
+var legacyID = regexp.MustCompile(`^[a-z]+(-[a-z0-9]+)+$`)
+
+// lenient accepts the IDs records were given before the generator
+type lenient struct {
+	Generator
+}
+
+// Lenient returns generator accepting as valid the IDs of records created
+// before it was configured as well: UUIDs of any version and ULIDs, with or
+// without a prefix of its own, and the hand-written IDs of the sample data
+func Lenient(generator Generator) Generator {
+	return lenient{Generator: generator}
+}
+
+// Valid reports whether id has the format of the IDs of the generator or of
+// earlier records
+func (g lenient) Valid(id string) bool {
+	if g.Generator.Valid(id) {
+		return true
+	}
+	if p, ok := g.Generator.(prefixed); ok {
+		id = strings.TrimPrefix(id, p.prefix)
+	}
+
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.String() == id
+	}
+	return validULID(id) || legacyID.MatchString(id)
+}
@@ -0,0 +1,213 @@
+package idgen
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedULID returns a ULID generator whose wall clock reads *at
+func fixedULID(at *time.Time) *ulidGenerator {
+	return &ulidGenerator{now: func() time.Time { return *at }}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Generate IDs of the format", func(t *testing.T) {
+		for format, version := range map[string]uuid.Version{FormatUUIDv7: 7, FormatUUIDv4: 4} {
+			// Arrange
+			generator, err := New(format, "")
+			require.NoError(t, err)
+
+			// Act
+			id := generator.New()
+
+			// Assert
+			assert.Equal(t, version, uuid.MustParse(id).Version(), format)
+			assert.True(t, generator.Valid(id), format)
+		}
+	})
+
+	t.Run("Prefix the IDs", func(t *testing.T) {
+		// Arrange
+		generator, err := New(FormatULID, "cus_")
+		require.NoError(t, err)
+
+		// Act
+		id := generator.New()
+
+		// Assert
+		assert.True(t, strings.HasPrefix(id, "cus_"))
+		assert.Len(t, id, len("cus_")+ulidLength)
+		assert.True(t, generator.Valid(id))
+	})
+
+	t.Run("Reject unknown formats", func(t *testing.T) {
+		// Act
+		_, err := New("snowflake", "")
+
+		// Assert
+		assert.ErrorContains(t, err, `unknown ID format "snowflake"`)
+	})
+}
+
+func TestUUIDv7(t *testing.T) {
+	t.Run("Sort in the order generated", func(t *testing.T) {
+		// Arrange
+		generator := UUIDv7()
+		ids := make([]string, 1000)
+
+		// Act
+		for i := range ids {
+			ids[i] = generator.New()
+		}
+
+		// Assert
+		assert.True(t, slices.IsSorted(ids))
+	})
+
+	t.Run("Accept only canonical UUIDv7s", func(t *testing.T) {
+		// Arrange
+		generator := UUIDv7()
+		id := generator.New()
+
+		// Act & Assert
+		assert.True(t, generator.Valid(id))
+		assert.False(t, generator.Valid(strings.ToUpper(id)))
+		assert.False(t, generator.Valid("{"+id+"}"))
+		assert.False(t, generator.Valid(uuid.NewString()))
+		assert.False(t, generator.Valid("customer-456"))
+		assert.False(t, generator.Valid(""))
+	})
+}
+
+func TestULID(t *testing.T) {
+	t.Run("Encode the timestamp first", func(t *testing.T) {
+		// Arrange
+		at := time.UnixMilli(1469918176385)
+		generator := fixedULID(&at)
+
+		// Act
+		id := generator.New()
+
+		// Assert
+		assert.Equal(t, "01ARYZ6S41", id[:10])
+		assert.True(t, generator.Valid(id))
+	})
+
+	t.Run("Increment within a millisecond", func(t *testing.T) {
+		// Arrange
+		at := time.UnixMilli(1469918176385)
+		generator := fixedULID(&at)
+		first := generator.New()
+
+		// Act
+		second := generator.New()
+
+		// Assert
+		assert.Equal(t, first[:10], second[:10])
+		assert.Less(t, first, second)
+	})
+
+	t.Run("Wall clock stepping back", func(t *testing.T) {
+		// Arrange
+		at := time.UnixMilli(1469918176385)
+		generator := fixedULID(&at)
+		first := generator.New()
+		at = at.Add(-time.Hour)
+
+		// Act
+		second := generator.New()
+
+		// Assert
+		assert.Less(t, first, second)
+	})
+
+	t.Run("Move to the next millisecond when the random bits run out", func(t *testing.T) {
+		// Arrange
+		at := time.UnixMilli(1469918176385)
+		generator := fixedULID(&at)
+		generator.New()
+		for i := 6; i < 16; i++ {
+			generator.last[i] = 0xff
+		}
+
+		// Act
+		id := generator.New()
+
+		// Assert
+		assert.Equal(t, uint64(1469918176386), timestamp(generator.last))
+		assert.True(t, generator.Valid(id))
+	})
+
+	t.Run("Concurrent callers", func(t *testing.T) {
+		// Arrange
+		generator := ULID()
+		var wg sync.WaitGroup
+		var mutex sync.Mutex
+		seen := make(map[string]bool)
+
+		// Act
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 500 {
+					id := generator.New()
+					mutex.Lock()
+					seen[id] = true
+					mutex.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		// Assert
+		assert.Len(t, seen, 8*500)
+	})
+
+	t.Run("Accept only upper case ULIDs", func(t *testing.T) {
+		// Arrange
+		generator := ULID()
+		id := generator.New()
+
+		// Act & Assert
+		assert.True(t, generator.Valid(id))
+		assert.False(t, generator.Valid(strings.ToLower(id)))
+		assert.False(t, generator.Valid("8"+id[1:]))
+		assert.False(t, generator.Valid(id[1:]))
+		assert.False(t, generator.Valid("01ARYZ6S41TSV4RRFFQ69G5FAU"))
+	})
+}
+
+func TestLenient(t *testing.T) {
+	t.Run("Accept the IDs of earlier records", func(t *testing.T) {
+		// Arrange
+		generator := Lenient(Prefixed("cus_", UUIDv7()))
+
+		// Act & Assert
+		assert.True(t, generator.Valid(generator.New()))
+		assert.True(t, generator.Valid(uuid.NewString()))
+		assert.True(t, generator.Valid("cus_"+uuid.NewString()))
+		assert.True(t, generator.Valid(ULID().New()))
+		assert.True(t, generator.Valid("customer-456"))
+		assert.True(t, generator.Valid("category-electronics"))
+	})
+
+	t.Run("Reject malformed IDs", func(t *testing.T) {
+		// Arrange
+		generator := Lenient(Prefixed("cus_", UUIDv7()))
+
+		// Act & Assert
+		assert.False(t, generator.Valid(""))
+		assert.False(t, generator.Valid("prod_"+UUIDv7().New()))
+		assert.False(t, generator.Valid(strings.ToUpper(uuid.NewString())))
+		assert.False(t, generator.Valid("customer 456"))
+		assert.False(t, generator.Valid("../customers"))
+	})
+}
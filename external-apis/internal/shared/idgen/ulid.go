@@ -0,0 +1,115 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet ULIDs are encoded in
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidLength is the length of an encoded ULID
+const ulidLength = 26
+
+// ulidGenerator generates ULIDs: a 48-bit millisecond timestamp followed by
+// 80 random bits. ULIDs generated in the same millisecond increment the
+// random bits of the last one, so they sort in the order they were generated.
+type ulidGenerator struct {
+	mutex sync.Mutex
+	last  [16]byte
+	now   func() time.Time
+}
+
+// ULID returns the generator of ULIDs
+func ULID() Generator {
+	return &ulidGenerator{now: time.Now}
+}
+
+// New returns a new ULID
+func (g *ulidGenerator) New() string {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	ms := uint64(g.now().UnixMilli())
+	if last := timestamp(g.last); ms <= last {
+		// Within the millisecond of the last ULID, or the wall clock stepped
+		// back: follow the last ULID, moving to the next millisecond once the
+		// random bits run out
+		if increment(g.last[6:]) {
+			setTimestamp(&g.last, last)
+			return encode(g.last)
+		}
+		ms = last + 1
+	}
+
+	setTimestamp(&g.last, ms)
+	_, _ = rand.Read(g.last[6:])
+	return encode(g.last)
+}
+
+// Valid reports whether id is an encoded ULID in upper case
+func (g *ulidGenerator) Valid(id string) bool {
+	return validULID(id)
+}
+
+// validULID reports whether id is an encoded ULID in upper case. The first
+// character holds the top 3 bits only.
+func validULID(id string) bool {
+	if len(id) != ulidLength || id[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(crockford, id[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// timestamp returns the millisecond timestamp of the ULID
+func timestamp(ulid [16]byte) uint64 {
+	var ms uint64
+	for _, b := range ulid[:6] {
+		ms = ms<<8 | uint64(b)
+	}
+	return ms
+}
+
+// setTimestamp sets the millisecond timestamp of the ULID
+func setTimestamp(ulid *[16]byte, ms uint64) {
+	for i := 5; i >= 0; i-- {
+		ulid[i] = byte(ms)
+		ms >>= 8
+	}
+}
+
+// increment adds one to the big-endian number in b and reports whether it
+// did not overflow
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode encodes the 128 bits of the ULID as 26 Crockford base32
+// characters of 5 bits each, the first of which holds the top 3 bits only
+func encode(ulid [16]byte) string {
+	var out [ulidLength]byte
+	for i := range out {
+		var value byte
+		for bit := 5*i - 2; bit < 5*i+3; bit++ {
+			value <<= 1
+			if bit >= 0 && ulid[bit/8]&(0x80>>(bit%8)) != 0 {
+				value |= 1
+			}
+		}
+		out[i] = crockford[value]
+	}
+	return string(out[:])
+}
@@ -0,0 +1,347 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/validation"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Formats of import files
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Modes of an import
+const (
+	// ModeCreate creates a new item for every row
+	ModeCreate = "create"
+	// ModeUpsert updates the item with the key of a row, creating it if
+	// there is none
+	ModeUpsert = "upsert"
+)
+
+// Results of an imported row
+const (
+	ResultCreated = "created"
+	ResultUpdated = "updated"
+)
+
+// MaxLineSize is the longest NDJSON line accepted, in bytes
+const MaxLineSize = 1 << 20
+
+// MaxErrors caps the row errors listed in a report. Further failed rows are
+// only counted.
+const MaxErrors = 1000
+
+// Errors returned for import files that cannot be processed at all
+var (
+	ErrUnsupportedFormat = apperror.Validation("import format must be csv or ndjson")
+	ErrInvalidMode       = apperror.Validation("import mode must be create or upsert")
+	ErrMissingHeader     = apperror.Validation("CSV import file must start with a header row")
+	ErrUnreadableFile    = apperror.Validation("import file could not be read")
+)
+
+// IsValidMode checks if the import mode is supported
+func IsValidMode(mode string) bool {
+	return mode == ModeCreate || mode == ModeUpsert
+}
+
+// DetectFormat works out the format of an uploaded file from its name,
+// falling back to its content type
+func DetectFormat(filename, contentType string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON, nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// RowError explains why a row was rejected. Line is the line of the file the
+// row starts on; Fields lists the rejected fields where the error concerns
+// particular ones.
+type RowError struct {
+	Line    int                     `json:"line"`
+	Message string                  `json:"error"`
+	Fields  []validation.FieldError `json:"fields,omitempty"`
+}
+
+// Error implements error
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Report summarizes an import. Rows counts every row read, including the
+// failed ones.
+type Report struct {
+	Rows    int        `json:"rows"`
+	Created int        `json:"created"`
+	Updated int        `json:"updated"`
+	Failed  int        `json:"failed"`
+	Errors  []RowError `json:"errors"`
+}
+
+// NewReport creates an empty report
+func NewReport() *Report {
+	return &Report{Errors: []RowError{}}
+}
+
+// Succeeded records a row that was imported with the given result
+func (r *Report) Succeeded(result string) {
+	r.Rows++
+	switch result {
+	case ResultCreated:
+		r.Created++
+	case ResultUpdated:
+		r.Updated++
+	}
+}
+
+// Fail records a rejected row
+func (r *Report) Fail(line int, err error) {
+	r.Rows++
+	r.Failed++
+	if len(r.Errors) >= MaxErrors {
+		return
+	}
+
+	var rowErr *RowError
+	if errors.As(err, &rowErr) {
+		r.Errors = append(r.Errors, *rowErr)
+		return
+	}
+	r.Errors = append(r.Errors, RowError{Line: line, Message: err.Error()})
+}
+
+// Row is a single record of an import file
+type Row struct {
+	Line int
+	// columns holds the cells of a CSV row by column name
+	columns map[string]string
+	// object holds an NDJSON line
+	object []byte
+}
+
+// Decode stores the row in the struct dst points to and validates it with
+// its binding rules. Fields are matched by their JSON names; CSV columns
+// match regardless of case and empty cells are left unset. Failures are
+// returned as *RowError.
+func (r Row) Decode(dst interface{}) error {
+	if r.object != nil {
+		decoder := json.NewDecoder(bytes.NewReader(r.object))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(dst); err != nil {
+			return r.fail(err)
+		}
+	} else if err := r.setColumns(reflect.ValueOf(dst).Elem()); err != nil {
+		return err
+	}
+
+	if err := binding.Validator.ValidateStruct(dst); err != nil {
+		return r.fail(err)
+	}
+	return nil
+}
+
+// setColumns copies the CSV cells into the fields of a struct
+func (r Row) setColumns(target reflect.Value) error {
+	for i := 0; i < target.NumField(); i++ {
+		name := jsonName(target.Type().Field(i))
+		value := strings.TrimSpace(r.columns[strings.ToLower(name)])
+		if name == "" || value == "" {
+			continue
+		}
+
+		if err := setCell(target.Field(i), value); err != nil {
+			return &RowError{
+				Line:    r.Line,
+				Message: "Row validation failed",
+				Fields:  []validation.FieldError{{Field: name, Rule: "type", Message: err.Error()}},
+			}
+		}
+	}
+	return nil
+}
+
+// fail converts a decoding or validation error into a RowError
+func (r Row) fail(err error) *RowError {
+	fields := validation.Translate(err)
+	if len(fields) == 0 {
+		return &RowError{Line: r.Line, Message: "Invalid row: " + err.Error()}
+	}
+	return &RowError{Line: r.Line, Message: "Row validation failed", Fields: fields}
+}
+
+// setCell parses a CSV cell into a string, integer or boolean field, or a
+// pointer to one
+func setCell(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		field.SetBool(b)
+	default:
+		return errors.New("cannot be imported from CSV")
+	}
+	return nil
+}
+
+// CheckColumns rejects CSV header columns the struct dst has no field for
+func CheckColumns(header []string, dst interface{}) error {
+	known := map[string]bool{}
+	typ := reflect.TypeOf(dst)
+	for i := 0; i < typ.NumField(); i++ {
+		if name := jsonName(typ.Field(i)); name != "" {
+			known[strings.ToLower(name)] = true
+		}
+	}
+
+	for _, column := range header {
+		if !known[strings.ToLower(column)] {
+			return apperror.Validation(fmt.Sprintf("unknown column %q", column))
+		}
+	}
+	return nil
+}
+
+// jsonName returns the JSON key of a struct field, or "" if it has none
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// Reader streams the rows of a CSV or NDJSON import file without holding the
+// file in memory
+type Reader struct {
+	csv     *csv.Reader
+	header  []string
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader creates a reader for a file in the given format. CSV files must
+// start with a header row naming the columns.
+func NewReader(format string, file io.Reader) (*Reader, error) {
+	switch format {
+	case FormatCSV:
+		reader := csv.NewReader(file)
+		header, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, ErrMissingHeader
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+		}
+		for i, column := range header {
+			// Spreadsheet exports often start with a byte order mark
+			header[i] = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		}
+		reader.FieldsPerRecord = len(header)
+		return &Reader{csv: reader, header: header}, nil
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+		return &Reader{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// Header returns the columns of a CSV file, or nil for NDJSON
+func (r *Reader) Header() []string {
+	return r.header
+}
+
+// Next returns the next row. It returns io.EOF after the last row and a
+// *RowError for a malformed row, after which reading may continue. Other
+// errors mean the rest of the file cannot be read.
+func (r *Reader) Next() (Row, error) {
+	if r.csv != nil {
+		return r.nextCSV()
+	}
+	return r.nextNDJSON()
+}
+
+// nextCSV reads the next CSV record
+func (r *Reader) nextCSV() (Row, error) {
+	record, err := r.csv.Read()
+	if errors.Is(err, io.EOF) {
+		return Row{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Row{}, &RowError{Line: parseErr.StartLine, Message: "Malformed CSV row: " + parseErr.Err.Error()}
+	}
+	if err != nil {
+		return Row{}, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	columns := make(map[string]string, len(record))
+	for i, value := range record {
+		columns[strings.ToLower(r.header[i])] = value
+	}
+	return Row{Line: line, columns: columns}, nil
+}
+
+// nextNDJSON reads the next non-blank NDJSON line
+func (r *Reader) nextNDJSON() (Row, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '{' {
+			return Row{}, &RowError{Line: r.line, Message: "Malformed NDJSON row: each line must be a JSON object"}
+		}
+		return Row{Line: r.line, object: bytes.Clone(line)}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Row{}, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+	}
+	return Row{}, io.EOF
+}
@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"external-apis/internal/shared/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// item is the row type decoded in the tests
+type item struct {
+	SKU      string `json:"sku" binding:"required"`
+	Quantity *int   `json:"quantity" binding:"omitempty,gte=0"`
+	Active   bool   `json:"active"`
+}
+
+// readAll decodes every row of a file, collecting the items and the errors
+func readAll(t *testing.T, format, content string) ([]item, []*RowError) {
+	t.Helper()
+	validation.Register()
+
+	reader, err := NewReader(format, strings.NewReader(content))
+	require.NoError(t, err)
+
+	var items []item
+	var rowErrors []*RowError
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return items, rowErrors
+		}
+
+		var rowErr *RowError
+		if errors.As(err, &rowErr) {
+			rowErrors = append(rowErrors, rowErr)
+			continue
+		}
+		require.NoError(t, err)
+
+		var decoded item
+		if err := row.Decode(&decoded); err != nil {
+			require.ErrorAs(t, err, &rowErr)
+			rowErrors = append(rowErrors, rowErr)
+			continue
+		}
+		items = append(items, decoded)
+	}
+}
+
+func TestReader_CSV(t *testing.T) {
+	// Arrange
+	content := "\ufeffSKU,Quantity,active\n" +
+		"SKU-1,3,true\n" +
+		"SKU-2,,\n" +
+		"SKU-3,lots,false\n" +
+		"SKU-4,1\n" +
+		",2,true\n"
+
+	// Act
+	items, rowErrors := readAll(t, FormatCSV, content)
+
+	// Assert
+	require.Len(t, items, 2)
+	assert.Equal(t, "SKU-1", items[0].SKU)
+	assert.Equal(t, 3, *items[0].Quantity)
+	assert.True(t, items[0].Active)
+	assert.Nil(t, items[1].Quantity, "empty cells are left unset")
+
+	require.Len(t, rowErrors, 3)
+	assert.Equal(t, 4, rowErrors[0].Line)
+	assert.Equal(t, []validation.FieldError{{Field: "quantity", Rule: "type", Message: "must be a whole number"}}, rowErrors[0].Fields)
+	assert.Equal(t, 5, rowErrors[1].Line)
+	assert.Contains(t, rowErrors[1].Message, "Malformed CSV row")
+	assert.Equal(t, 6, rowErrors[2].Line)
+	assert.Equal(t, "sku", rowErrors[2].Fields[0].Field)
+	assert.Equal(t, "required", rowErrors[2].Fields[0].Rule)
+}
+
+func TestReader_NDJSON(t *testing.T) {
+	// Arrange
+	content := `{"sku":"SKU-1","quantity":3}` + "\n" +
+		"\n" +
+		`{"sku":"SKU-2","colour":"red"}` + "\n" +
+		`["SKU-3"]` + "\n" +
+		`{"sku":"SKU-4","quantity":-1}` + "\n" +
+		`{"sku":"SKU-5","quantity":"many"}`
+
+	// Act
+	items, rowErrors := readAll(t, FormatNDJSON, content)
+
+	// Assert
+	require.Len(t, items, 1)
+	assert.Equal(t, "SKU-1", items[0].SKU)
+
+	require.Len(t, rowErrors, 4)
+	assert.Equal(t, 3, rowErrors[0].Line, "blank lines are counted")
+	assert.Contains(t, rowErrors[0].Message, `unknown field "colour"`)
+	assert.Equal(t, 4, rowErrors[1].Line)
+	assert.Equal(t, "gte", rowErrors[2].Fields[0].Rule)
+	assert.Equal(t, []validation.FieldError{{Field: "quantity", Rule: "type", Message: "must be a number"}}, rowErrors[3].Fields)
+}
+
+func TestNewReader(t *testing.T) {
+	// Act
+	_, emptyErr := NewReader(FormatCSV, strings.NewReader(""))
+	_, formatErr := NewReader("xlsx", strings.NewReader(""))
+
+	// Assert
+	assert.ErrorIs(t, emptyErr, ErrMissingHeader)
+	assert.ErrorIs(t, formatErr, ErrUnsupportedFormat)
+}
+
+func TestCheckColumns(t *testing.T) {
+	// Act
+	known := CheckColumns([]string{"SKU", "quantity"}, item{})
+	unknown := CheckColumns([]string{"sku", "colour"}, item{})
+
+	// Assert
+	assert.NoError(t, known)
+	require.Error(t, unknown)
+	assert.Contains(t, unknown.Error(), `unknown column "colour"`)
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		want        string
+	}{
+		{name: "CSV extension", filename: "catalog.CSV", want: FormatCSV},
+		{name: "JSON Lines extension", filename: "catalog.jsonl", want: FormatNDJSON},
+		{name: "Content type", filename: "catalog", contentType: "application/x-ndjson; charset=utf-8", want: FormatNDJSON},
+		{name: "Unknown", filename: "catalog.xlsx", contentType: "application/octet-stream", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			format, err := DetectFormat(tt.filename, tt.contentType)
+
+			// Assert
+			assert.Equal(t, tt.want, format)
+			if tt.want == "" {
+				assert.ErrorIs(t, err, ErrUnsupportedFormat)
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	// Arrange
+	report := NewReport()
+
+	// Act
+	report.Succeeded(ResultCreated)
+	report.Succeeded(ResultUpdated)
+	report.Fail(3, &RowError{Line: 3, Message: "Row validation failed"})
+	for i := 0; i < MaxErrors; i++ {
+		report.Fail(4+i, errors.New("SKU is already in use"))
+	}
+
+	// Assert
+	assert.Equal(t, MaxErrors+3, report.Rows)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, MaxErrors+1, report.Failed)
+	assert.Len(t, report.Errors, MaxErrors)
+	assert.Equal(t, RowError{Line: 4, Message: "SKU is already in use"}, report.Errors[1])
+}
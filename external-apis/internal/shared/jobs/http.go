@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the admin endpoint reporting background job health
+type AdminHandler struct {
+	manager *Manager
+}
+
+// NewAdminHandler creates a new job admin handler
+func NewAdminHandler(manager *Manager) *AdminHandler {
+	return &AdminHandler{manager: manager}
+}
+
+// RegisterRoutes registers the job admin routes
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/jobs", h.GetStats)
+}
+
+// GetStats returns the job outcomes per kind, the scheduled task runs and the
+// most recent failures
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	response.OK(c, h.manager.Stats())
+}
@@ -0,0 +1,455 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"github.com/google/uuid"
+)
+
+var log = logger.New("shared/jobs")
+
+var (
+	// ErrDraining is returned when work is submitted after shutdown has started
+	ErrDraining = errors.New("job manager is draining")
+	// ErrQueueFull is returned when the job queue has no free capacity
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrUnknownKind is returned when no handler is registered for a job kind
+	ErrUnknownKind = errors.New("no handler registered for job kind")
+)
+
+// Handler processes the payload of a single job
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Job represents a unit of background work
+type Job struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	Payload    json.RawMessage `json:"payload"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	// RunAt delays the job until the given time; a zero RunAt runs it as
+	// soon as a worker is free
+	RunAt time.Time `json:"run_at,omitzero"`
+	// Attempt counts the failed attempts that came before this run
+	Attempt int `json:"attempt,omitempty"`
+}
+
+// Options configures a Manager
+type Options struct {
+	Workers   int
+	QueueSize int
+	// GracePeriod is how long to wait for handlers to return after their
+	// context is cancelled because the drain deadline was exceeded
+	GracePeriod time.Duration
+}
+
+// DefaultOptions returns the default manager options
+func DefaultOptions() Options {
+	return Options{
+		Workers:     4,
+		QueueSize:   1024,
+		GracePeriod: time.Second,
+	}
+}
+
+// requeueDelay is how long a delayed job that found the queue full waits
+// before trying again
+const requeueDelay = 100 * time.Millisecond
+
+// Manager tracks background jobs, scheduled tasks and long-running loops so
+// they can be drained on shutdown. Jobs still pending when the drain deadline
+// expires, including delayed jobs and retries waiting for their backoff, are
+// persisted to the Store and resubmitted on the next Start.
+type Manager struct {
+	opts      Options
+	store     Store
+	handlers  map[string]Handler
+	retries   map[string]RetryPolicy
+	pending   map[string]Job
+	timers    map[string]*time.Timer
+	queue     chan Job
+	counts    map[string]KindStats
+	schedules map[string]*ScheduleStats
+	failures  []Failure
+
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	loopCtx    context.Context
+	stopLoops  context.CancelFunc
+
+	workers sync.WaitGroup
+	loops   sync.WaitGroup
+	mutex   sync.Mutex
+	started bool
+	closed  bool
+}
+
+// NewManager creates a new job manager backed by the given store
+func NewManager(store Store, opts Options) *Manager {
+	defaults := DefaultOptions()
+	if opts.Workers <= 0 {
+		opts.Workers = defaults.Workers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaults.QueueSize
+	}
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = defaults.GracePeriod
+	}
+	if store == nil {
+		store = NopStore{}
+	}
+
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	loopCtx, stopLoops := context.WithCancel(context.Background())
+
+	return &Manager{
+		opts:       opts,
+		store:      store,
+		handlers:   make(map[string]Handler),
+		retries:    make(map[string]RetryPolicy),
+		pending:    make(map[string]Job),
+		timers:     make(map[string]*time.Timer),
+		queue:      make(chan Job, opts.QueueSize),
+		counts:     make(map[string]KindStats),
+		schedules:  make(map[string]*ScheduleStats),
+		jobCtx:     jobCtx,
+		cancelJobs: cancelJobs,
+		loopCtx:    loopCtx,
+		stopLoops:  stopLoops,
+	}
+}
+
+// Register registers the handler for a job kind whose failed jobs are not
+// retried. Handlers must be registered before Start so persisted jobs can be
+// resumed.
+func (m *Manager) Register(kind string, handler Handler) {
+	m.RegisterWithRetry(kind, handler, NoRetry)
+}
+
+// RegisterWithRetry registers the handler for a job kind whose failed jobs
+// are retried with the policy unless they fail with a Permanent error
+func (m *Manager) RegisterWithRetry(kind string, handler Handler, policy RetryPolicy) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.handlers[kind] = handler
+	m.retries[kind] = policy
+}
+
+// Start launches the worker pool and resubmits jobs persisted by a previous
+// drain. Persisted delayed jobs keep waiting for their time.
+func (m *Manager) Start() error {
+	m.mutex.Lock()
+	if m.started {
+		m.mutex.Unlock()
+		return nil
+	}
+	m.started = true
+	m.mutex.Unlock()
+
+	for i := 0; i < m.opts.Workers; i++ {
+		m.workers.Add(1)
+		go m.work()
+	}
+
+	persisted, err := m.store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load persisted jobs: %w", err)
+	}
+
+	for _, job := range persisted {
+		if err := m.enqueue(job); err != nil {
+			log.WithError(err).WithFields(logger.Fields{
+				"job_id":   job.ID,
+				"job_kind": job.Kind,
+			}).Error("Failed to resume persisted job")
+		}
+	}
+
+	if len(persisted) > 0 {
+		log.WithField("count", len(persisted)).Info("Resumed persisted background jobs")
+	}
+
+	return nil
+}
+
+// Submit enqueues a job of the given kind with a JSON-encoded payload
+func (m *Manager) Submit(kind string, payload interface{}) (string, error) {
+	return m.SubmitAfter(kind, payload, 0)
+}
+
+// SubmitAfter enqueues a job that runs once delay has passed. Delayed jobs
+// are pending from the start, so a drain persists them instead of losing them.
+func (m *Manager) SubmitAfter(kind string, payload interface{}, delay time.Duration) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	job := Job{
+		ID:         uuid.New().String(),
+		Kind:       kind,
+		Payload:    data,
+		EnqueuedAt: now,
+	}
+	if delay > 0 {
+		job.RunAt = now.Add(delay)
+	}
+
+	if err := m.enqueue(job); err != nil {
+		return "", err
+	}
+
+	return job.ID, nil
+}
+
+// Go runs a long-lived loop (e.g. a scheduler or relay) tracked by the manager.
+// The context passed to fn is cancelled as soon as draining starts.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		log.WithField("loop", name).Warn("Ignoring background loop started during drain")
+		return
+	}
+
+	m.loops.Add(1)
+	go func() {
+		defer m.loops.Done()
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.WithFields(logger.Fields{
+					"loop":  name,
+					"panic": recovered,
+				}).Error("Background loop panicked")
+			}
+		}()
+
+		fn(m.loopCtx)
+	}()
+}
+
+// Pending returns the number of jobs that are delayed, queued or running
+func (m *Manager) Pending() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return len(m.pending)
+}
+
+// Drain stops accepting work and waits up to timeout for queued and running
+// jobs to finish. Jobs that are still pending when the deadline expires are
+// cancelled and persisted so they resume on the next start.
+func (m *Manager) Drain(timeout time.Duration) error {
+	m.mutex.Lock()
+	if m.closed {
+		m.mutex.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.queue)
+	// Delayed jobs stay pending and are persisted below
+	for id, timer := range m.timers {
+		timer.Stop()
+		delete(m.timers, id)
+	}
+	m.mutex.Unlock()
+
+	m.stopLoops()
+
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		m.loops.Wait()
+		close(done)
+	}()
+
+	var drainErr error
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		drainErr = fmt.Errorf("drain deadline of %s exceeded", timeout)
+		m.cancelJobs()
+
+		select {
+		case <-done:
+		case <-time.After(m.opts.GracePeriod):
+			log.Warn("Background jobs did not stop within grace period")
+		}
+	}
+	m.cancelJobs()
+
+	unfinished := m.snapshotPending()
+	if err := m.store.Save(unfinished); err != nil {
+		return fmt.Errorf("failed to persist unfinished jobs: %w", err)
+	}
+
+	if len(unfinished) > 0 {
+		log.WithField("count", len(unfinished)).Warn("Persisted unfinished background jobs")
+	}
+
+	return drainErr
+}
+
+// enqueue adds a job to the pending set and either the queue or, when it is
+// not due yet, a timer that queues it later
+func (m *Manager) enqueue(job Job) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.closed {
+		return ErrDraining
+	}
+
+	if _, exists := m.handlers[job.Kind]; !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownKind, job.Kind)
+	}
+
+	if delay := time.Until(job.RunAt); delay > 0 {
+		m.pending[job.ID] = job
+		m.delay(job.ID, delay)
+		return nil
+	}
+
+	select {
+	case m.queue <- job:
+		m.pending[job.ID] = job
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// delay queues the pending job with the given ID once delay has passed. The
+// caller must hold the mutex.
+func (m *Manager) delay(id string, delay time.Duration) {
+	m.timers[id] = time.AfterFunc(delay, func() {
+		m.release(id)
+	})
+}
+
+// release moves a delayed job that is due to the queue, trying again shortly
+// if the queue is full. Once draining has started the job stays pending so it
+// is persisted.
+func (m *Manager) release(id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.timers, id)
+	job, exists := m.pending[id]
+	if !exists || m.closed {
+		return
+	}
+
+	select {
+	case m.queue <- job:
+	default:
+		m.delay(id, requeueDelay)
+	}
+}
+
+// work consumes jobs from the queue until it is closed
+func (m *Manager) work() {
+	defer m.workers.Done()
+
+	for job := range m.queue {
+		// Leave the job pending so it is persisted instead of started
+		if m.jobCtx.Err() != nil {
+			continue
+		}
+
+		m.run(job)
+	}
+}
+
+// run executes a single job. A failed job is rescheduled as a delayed job
+// while its retry policy allows; otherwise it leaves the pending set unless it
+// was interrupted by the drain deadline.
+func (m *Manager) run(job Job) {
+	m.mutex.Lock()
+	handler := m.handlers[job.Kind]
+	policy := m.retries[job.Kind]
+	m.mutex.Unlock()
+
+	attempt := job.Attempt + 1
+	entry := log.WithFields(logger.Fields{
+		"job_id":   job.ID,
+		"job_kind": job.Kind,
+		"attempt":  attempt,
+	})
+
+	err := m.safeCall(context.WithValue(m.jobCtx, attemptKey{}, attempt), handler, job)
+	if err != nil && m.jobCtx.Err() != nil {
+		entry.WithError(err).Warn("Background job interrupted by shutdown")
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	counts := m.counts[job.Kind]
+	defer func() { m.counts[job.Kind] = counts }()
+
+	switch {
+	case err == nil:
+		counts.Succeeded++
+		delete(m.pending, job.ID)
+		entry.Debug("Background job completed")
+	case !IsPermanent(err) && attempt < policy.MaxAttempts:
+		counts.Retried++
+		backoff := policy.Backoff(attempt)
+		retry := job
+		retry.Attempt = attempt
+		retry.RunAt = time.Now().UTC().Add(backoff)
+		m.pending[job.ID] = retry
+		// During a drain the retry stays pending and is persisted
+		if !m.closed {
+			m.delay(job.ID, backoff)
+		}
+		entry.WithError(err).WithField("retry_after", backoff.String()).Warn("Background job failed, retrying")
+	default:
+		counts.Failed++
+		delete(m.pending, job.ID)
+		m.recordFailure(Failure{
+			JobID:    job.ID,
+			Kind:     job.Kind,
+			Attempts: attempt,
+			Error:    err.Error(),
+			FailedAt: time.Now().UTC(),
+		})
+		entry.WithError(err).Error("Background job failed")
+	}
+}
+
+// safeCall invokes the handler and converts panics into errors
+func (m *Manager) safeCall(ctx context.Context, handler Handler, job Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	return handler(ctx, job.Payload)
+}
+
+// snapshotPending returns the pending jobs ordered by enqueue time
+func (m *Manager) snapshotPending() []Job {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	jobs := make([]Job, 0, len(m.pending))
+	for _, job := range m.pending {
+		jobs = append(jobs, job)
+	}
+
+	sortJobs(jobs)
+	return jobs
+}
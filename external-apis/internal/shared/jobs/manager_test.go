@@ -0,0 +1,309 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_SubmitAndDrain(t *testing.T) {
+	// Arrange
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+	manager := NewManager(store, Options{Workers: 2})
+
+	var processed int32
+	manager.Register("count", func(ctx context.Context, payload json.RawMessage) error {
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	require.NoError(t, manager.Start())
+
+	// Act
+	for i := 0; i < 10; i++ {
+		_, err := manager.Submit("count", i)
+		require.NoError(t, err)
+	}
+	err := manager.Drain(time.Second)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), atomic.LoadInt32(&processed))
+
+	persisted, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, persisted)
+}
+
+func TestManager_SubmitAfterDrain(t *testing.T) {
+	// Arrange
+	manager := NewManager(nil, Options{})
+	manager.Register("noop", func(ctx context.Context, payload json.RawMessage) error { return nil })
+	require.NoError(t, manager.Start())
+	require.NoError(t, manager.Drain(time.Second))
+
+	// Act
+	_, err := manager.Submit("noop", nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrDraining)
+}
+
+func TestManager_SubmitUnknownKind(t *testing.T) {
+	// Arrange
+	manager := NewManager(nil, Options{})
+	require.NoError(t, manager.Start())
+	defer manager.Drain(time.Second)
+
+	// Act
+	_, err := manager.Submit("missing", nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrUnknownKind)
+}
+
+func TestManager_PersistsAndResumesUnfinishedJobs(t *testing.T) {
+	// Arrange
+	store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+	manager := NewManager(store, Options{Workers: 1, GracePeriod: 100 * time.Millisecond})
+
+	started := make(chan struct{}, 1)
+	manager.Register("slow", func(ctx context.Context, payload json.RawMessage) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, manager.Start())
+
+	_, err := manager.Submit("slow", map[string]string{"step": "first"})
+	require.NoError(t, err)
+	_, err = manager.Submit("slow", map[string]string{"step": "second"})
+	require.NoError(t, err)
+	<-started
+
+	// Act
+	err = manager.Drain(50 * time.Millisecond)
+
+	// Assert
+	assert.Error(t, err)
+	persisted, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, persisted, 2)
+	assert.JSONEq(t, `{"step":"first"}`, string(persisted[0].Payload))
+	assert.JSONEq(t, `{"step":"second"}`, string(persisted[1].Payload))
+
+	t.Run("Resume on next start", func(t *testing.T) {
+		// Arrange
+		resumed := NewManager(store, Options{Workers: 1})
+		var processed int32
+		resumed.Register("slow", func(ctx context.Context, payload json.RawMessage) error {
+			atomic.AddInt32(&processed, 1)
+			return nil
+		})
+
+		// Act
+		require.NoError(t, resumed.Start())
+		require.NoError(t, resumed.Drain(time.Second))
+
+		// Assert
+		assert.Equal(t, int32(2), atomic.LoadInt32(&processed))
+		remaining, err := store.Load()
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+	})
+}
+
+func TestManager_GoLoopStopsOnDrain(t *testing.T) {
+	// Arrange
+	manager := NewManager(nil, Options{})
+	require.NoError(t, manager.Start())
+
+	var stopped int32
+	manager.Go("ticker", func(ctx context.Context) {
+		<-ctx.Done()
+		atomic.StoreInt32(&stopped, 1)
+	})
+
+	// Act
+	err := manager.Drain(time.Second)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stopped))
+}
+
+func TestManager_SubmitAfter(t *testing.T) {
+	t.Run("Run once the delay has passed", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(nil, Options{Workers: 1})
+		ran := make(chan time.Time, 1)
+		manager.Register("delayed", func(ctx context.Context, payload json.RawMessage) error {
+			ran <- time.Now()
+			return nil
+		})
+		require.NoError(t, manager.Start())
+		defer manager.Drain(time.Second)
+
+		// Act
+		submitted := time.Now()
+		_, err := manager.SubmitAfter("delayed", nil, 50*time.Millisecond)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 1, manager.Pending())
+		select {
+		case at := <-ran:
+			assert.GreaterOrEqual(t, at.Sub(submitted), 50*time.Millisecond)
+		case <-time.After(2 * time.Second):
+			t.Fatal("delayed job did not run")
+		}
+	})
+
+	t.Run("Persist delayed jobs on drain", func(t *testing.T) {
+		// Arrange
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		manager := NewManager(store, Options{Workers: 1})
+		var processed int32
+		manager.Register("delayed", func(ctx context.Context, payload json.RawMessage) error {
+			atomic.AddInt32(&processed, 1)
+			return nil
+		})
+		require.NoError(t, manager.Start())
+		_, err := manager.SubmitAfter("delayed", map[string]string{"step": "later"}, time.Hour)
+		require.NoError(t, err)
+
+		// Act
+		err = manager.Drain(time.Second)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), atomic.LoadInt32(&processed))
+		persisted, err := store.Load()
+		require.NoError(t, err)
+		require.Len(t, persisted, 1)
+		assert.JSONEq(t, `{"step":"later"}`, string(persisted[0].Payload))
+		assert.WithinDuration(t, time.Now().Add(time.Hour), persisted[0].RunAt, time.Minute)
+	})
+}
+
+func TestManager_RegisterWithRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+	t.Run("Retry until the job succeeds", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(nil, Options{Workers: 1})
+		attempts := make(chan int, 3)
+		manager.RegisterWithRetry("flaky", func(ctx context.Context, payload json.RawMessage) error {
+			attempts <- Attempt(ctx)
+			if Attempt(ctx) < 3 {
+				return errors.New("temporarily unavailable")
+			}
+			return nil
+		}, policy)
+		require.NoError(t, manager.Start())
+
+		// Act
+		_, err := manager.Submit("flaky", nil)
+		require.NoError(t, err)
+		for i := 1; i <= 3; i++ {
+			select {
+			case attempt := <-attempts:
+				assert.Equal(t, i, attempt)
+			case <-time.After(2 * time.Second):
+				t.Fatal("job was not retried")
+			}
+		}
+		require.NoError(t, manager.Drain(time.Second))
+
+		// Assert
+		stats := manager.Stats()
+		require.Len(t, stats.Kinds, 1)
+		assert.Equal(t, KindStats{Kind: "flaky", Succeeded: 1, Retried: 2}, stats.Kinds[0])
+		assert.Empty(t, stats.Failures)
+	})
+
+	t.Run("Report jobs that run out of attempts", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(nil, Options{Workers: 1})
+		var attempts int32
+		done := make(chan struct{})
+		manager.RegisterWithRetry("broken", func(ctx context.Context, payload json.RawMessage) error {
+			if atomic.AddInt32(&attempts, 1) == 3 {
+				defer close(done)
+			}
+			return errors.New("still broken")
+		}, policy)
+		require.NoError(t, manager.Start())
+
+		// Act
+		id, err := manager.Submit("broken", nil)
+		require.NoError(t, err)
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("job was not retried")
+		}
+		require.NoError(t, manager.Drain(time.Second))
+
+		// Assert
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+		stats := manager.Stats()
+		assert.Equal(t, KindStats{Kind: "broken", Retried: 2, Failed: 1}, stats.Kinds[0])
+		require.Len(t, stats.Failures, 1)
+		assert.Equal(t, id, stats.Failures[0].JobID)
+		assert.Equal(t, 3, stats.Failures[0].Attempts)
+		assert.Equal(t, "still broken", stats.Failures[0].Error)
+	})
+
+	t.Run("Do not retry permanent errors", func(t *testing.T) {
+		// Arrange
+		manager := NewManager(nil, Options{Workers: 1})
+		var attempts int32
+		manager.RegisterWithRetry("invalid", func(ctx context.Context, payload json.RawMessage) error {
+			atomic.AddInt32(&attempts, 1)
+			return Permanent(errors.New("malformed payload"))
+		}, policy)
+		require.NoError(t, manager.Start())
+
+		// Act
+		_, err := manager.Submit("invalid", nil)
+		require.NoError(t, err)
+		require.NoError(t, manager.Drain(time.Second))
+
+		// Assert
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+		assert.Equal(t, KindStats{Kind: "invalid", Failed: 1}, manager.Stats().Kinds[0])
+	})
+
+	t.Run("Persist retries waiting for their backoff", func(t *testing.T) {
+		// Arrange
+		store := NewFileStore(filepath.Join(t.TempDir(), "jobs.json"))
+		manager := NewManager(store, Options{Workers: 1})
+		failed := make(chan struct{})
+		manager.RegisterWithRetry("slow-retry", func(ctx context.Context, payload json.RawMessage) error {
+			close(failed)
+			return errors.New("try again later")
+		}, RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour})
+		require.NoError(t, manager.Start())
+		_, err := manager.Submit("slow-retry", nil)
+		require.NoError(t, err)
+		<-failed
+
+		// Act
+		err = manager.Drain(time.Second)
+
+		// Assert
+		require.NoError(t, err)
+		persisted, err := store.Load()
+		require.NoError(t, err)
+		require.Len(t, persisted, 1)
+		assert.Equal(t, 1, persisted[0].Attempt)
+		assert.True(t, persisted[0].RunAt.After(time.Now()))
+	})
+}
@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy configures how failed jobs of a kind are retried. Each retry is
+// a delayed job of its own, so retries waiting for their backoff survive a
+// restart like any other pending job.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts; one or less disables retries
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles after
+	// every failed attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// NoRetry is the policy of kinds registered without one
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// Backoff returns the delay before the given retry, counting from one
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+// permanentError marks a job failure that retrying will not fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the job fails without being retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent checks if err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+type attemptKey struct{}
+
+// Attempt returns the attempt number of the job running with ctx, counting
+// from one. It is zero outside of a job.
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
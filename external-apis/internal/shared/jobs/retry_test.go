@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		retry    int
+		expected time.Duration
+	}{
+		{retry: 1, expected: time.Second},
+		{retry: 2, expected: 2 * time.Second},
+		{retry: 3, expected: 4 * time.Second},
+		{retry: 4, expected: 5 * time.Second},
+		{retry: 60, expected: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Retry %d", tt.retry), func(t *testing.T) {
+			// Act
+			backoff := policy.Backoff(tt.retry)
+
+			// Assert
+			assert.Equal(t, tt.expected, backoff)
+		})
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	// Arrange
+	cause := errors.New("bad request")
+
+	// Act
+	err := fmt.Errorf("delivery failed: %w", Permanent(cause))
+
+	// Assert
+	assert.True(t, IsPermanent(err))
+	assert.ErrorIs(t, err, cause)
+	assert.False(t, IsPermanent(cause))
+	assert.NoError(t, Permanent(nil))
+}
+
+func TestAttempt(t *testing.T) {
+	assert.Equal(t, 0, Attempt(context.Background()))
+	assert.Equal(t, 2, Attempt(context.WithValue(context.Background(), attemptKey{}, 2)))
+}
@@ -0,0 +1,297 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/logger"
+)
+
+// ErrInvalidSchedule is returned when a cron expression cannot be parsed
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule decides when a scheduled task runs next
+type Schedule interface {
+	// Next returns the first run time after the given time, or the zero time
+	// if the schedule never runs again
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// interval runs a fixed duration after the previous run finished
+type interval time.Duration
+
+// Every returns a schedule that runs every d, measured from the end of the
+// previous run so slow runs never overlap
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+// Next implements Schedule
+func (i interval) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(i))
+}
+
+func (i interval) String() string {
+	return "@every " + time.Duration(i).String()
+}
+
+// cron is a parsed five-field cron expression; each field is a bitset of the
+// values it matches
+type cron struct {
+	spec   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	anyDom bool
+	anyDow bool
+}
+
+// cronHorizon bounds the search for the next run of a cron expression that
+// can never match, such as February 30th
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+// cronField describes the range of a cron expression field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 6},
+}
+
+// cronDescriptors are the shorthand expressions accepted by ParseCron
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseCron parses a standard five-field cron expression
+// ("minute hour day-of-month month day-of-week") in the time zone of the times
+// it is given. Fields accept *, values, ranges (a-b), lists (a,b) and steps
+// (*/n, a-b/n); Sunday is 0. When both day fields are restricted, a day
+// matching either runs, as in cron. The descriptors @hourly, @daily,
+// @midnight, @weekly, @monthly and "@every <duration>" are also accepted.
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if value, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q needs a positive duration", ErrInvalidSchedule, spec)
+		}
+		return Every(d), nil
+	}
+
+	expression := spec
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q must have %d fields", ErrInvalidSchedule, spec, len(cronFields))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSchedule, spec, err)
+		}
+		sets[i] = set
+	}
+
+	return &cron{
+		spec:   spec,
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: strings.HasPrefix(fields[2], "*"),
+		anyDow: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField turns one field of a cron expression into a bitset
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, bounds.name)
+			}
+			step = parsed
+		}
+
+		low, high := bounds.min, bounds.max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s", from, bounds.name)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s", to, bounds.name)
+				}
+			} else if hasStep {
+				// "a/n" runs from a to the end of the range
+				high = bounds.max
+			}
+		}
+
+		if low < bounds.min || high > bounds.max || low > high {
+			return 0, fmt.Errorf("%s must be between %d and %d", bounds.name, bounds.min, bounds.max)
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << uint(value)
+		}
+	}
+
+	return set, nil
+}
+
+// Next implements Schedule. It skips whole months, days and hours that cannot
+// match, so it stays cheap even for rare schedules.
+func (c *cron) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronHorizon)
+
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchesDay checks the day fields; restricting both matches either of them
+func (c *cron) matchesDay(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if !c.anyDom && !c.anyDow {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func (c *cron) String() string {
+	return c.spec
+}
+
+// Schedule runs fn on the schedule until the manager drains. Runs never
+// overlap: the next run time is computed once the previous run finished.
+// Failed and panicking runs are logged and counted in Stats.
+func (m *Manager) Schedule(name string, schedule Schedule, fn func(ctx context.Context) error) {
+	m.mutex.Lock()
+	m.schedules[name] = &ScheduleStats{Name: name, Schedule: schedule.String()}
+	m.mutex.Unlock()
+
+	m.Go(name, func(ctx context.Context) {
+		for {
+			next := schedule.Next(time.Now())
+			if next.IsZero() {
+				log.WithField("schedule", name).Warn("Schedule has no future runs")
+				return
+			}
+			m.recordSchedule(name, func(stats *ScheduleStats) {
+				stats.NextRun = next.UTC()
+			})
+
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			m.runScheduled(ctx, name, fn)
+		}
+	})
+}
+
+// runScheduled makes one run of a scheduled task and records its outcome
+func (m *Manager) runScheduled(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	started := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("scheduled task panicked: %v", recovered)
+			}
+		}()
+		return fn(ctx)
+	}()
+
+	entry := log.WithFields(logger.Fields{
+		"schedule": name,
+		"duration": time.Since(started).String(),
+	})
+	if err != nil && ctx.Err() != nil {
+		entry.WithError(err).Warn("Scheduled task interrupted by shutdown")
+		return
+	}
+
+	m.recordSchedule(name, func(stats *ScheduleStats) {
+		stats.Runs++
+		stats.LastRun = started.UTC()
+		stats.LastError = ""
+		if err != nil {
+			stats.Failures++
+			stats.LastError = err.Error()
+		}
+	})
+
+	if err != nil {
+		entry.WithError(err).Error("Scheduled task failed")
+	} else {
+		entry.Debug("Scheduled task completed")
+	}
+}
+
+// recordSchedule updates the stats of a schedule
+func (m *Manager) recordSchedule(name string, update func(stats *ScheduleStats)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if stats, exists := m.schedules[name]; exists {
+		update(stats)
+	}
+}
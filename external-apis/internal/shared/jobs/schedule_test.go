@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	// Wednesday
+	after := time.Date(2026, time.January, 14, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		spec     string
+		expected time.Time
+	}{
+		{name: "Every minute", spec: "* * * * *", expected: time.Date(2026, time.January, 14, 10, 31, 0, 0, time.UTC)},
+		{name: "Step", spec: "*/20 * * * *", expected: time.Date(2026, time.January, 14, 10, 40, 0, 0, time.UTC)},
+		{name: "Next hour", spec: "15 * * * *", expected: time.Date(2026, time.January, 14, 11, 15, 0, 0, time.UTC)},
+		{name: "Range and list", spec: "0 9-17/4,22 * * *", expected: time.Date(2026, time.January, 14, 13, 0, 0, 0, time.UTC)},
+		{name: "Day of week", spec: "0 3 * * 0", expected: time.Date(2026, time.January, 18, 3, 0, 0, 0, time.UTC)},
+		{name: "Either restricted day", spec: "0 0 20 * 5", expected: time.Date(2026, time.January, 16, 0, 0, 0, 0, time.UTC)},
+		{name: "Next year", spec: "0 0 1 1 *", expected: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Descriptor", spec: "@daily", expected: time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{name: "Interval", spec: "@every 90s", expected: time.Date(2026, time.January, 14, 10, 31, 45, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			schedule, err := ParseCron(tt.spec)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(after))
+		})
+	}
+
+	t.Run("Never matching date", func(t *testing.T) {
+		// Act
+		schedule, err := ParseCron("0 0 30 2 *")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, schedule.Next(after).IsZero())
+	})
+
+	t.Run("Reject invalid expressions", func(t *testing.T) {
+		for _, spec := range []string{"", "* * * *", "60 * * * *", "* 5-2 * * *", "*/0 * * * *", "a * * * *", "* * 0 * *", "@every -1s", "@yearly"} {
+			_, err := ParseCron(spec)
+			assert.ErrorIs(t, err, ErrInvalidSchedule, spec)
+		}
+	})
+}
+
+func TestManager_Schedule(t *testing.T) {
+	// Arrange
+	manager := NewManager(nil, Options{})
+	require.NoError(t, manager.Start())
+
+	runs := make(chan struct{}, 10)
+	calls := 0
+	manager.Schedule("flaky-task", Every(10*time.Millisecond), func(ctx context.Context) error {
+		calls++
+		runs <- struct{}{}
+		if calls == 2 {
+			return errors.New("task failed")
+		}
+		if calls == 3 {
+			panic("task panicked")
+		}
+		return nil
+	})
+
+	// Act
+	for i := 0; i < 4; i++ {
+		select {
+		case <-runs:
+		case <-time.After(2 * time.Second):
+			t.Fatal("scheduled task did not run")
+		}
+	}
+	require.NoError(t, manager.Drain(time.Second))
+
+	// Assert
+	stats := manager.Stats()
+	require.Len(t, stats.Schedules, 1)
+	schedule := stats.Schedules[0]
+	assert.Equal(t, "flaky-task", schedule.Name)
+	assert.Equal(t, "@every 10ms", schedule.Schedule)
+	assert.GreaterOrEqual(t, schedule.Runs, 4)
+	assert.Equal(t, 2, schedule.Failures)
+	assert.False(t, schedule.LastRun.IsZero())
+}
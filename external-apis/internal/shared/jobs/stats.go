@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"sort"
+	"time"
+)
+
+// maxFailures bounds the recent failures kept for Stats
+const maxFailures = 50
+
+// KindStats counts the outcomes of the jobs of one kind since the manager started
+type KindStats struct {
+	Kind      string `json:"kind"`
+	Pending   int    `json:"pending"`
+	Succeeded int    `json:"succeeded"`
+	Retried   int    `json:"retried"`
+	Failed    int    `json:"failed"`
+}
+
+// ScheduleStats reports the runs of a scheduled task since the manager started
+type ScheduleStats struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	Runs      int       `json:"runs"`
+	Failures  int       `json:"failures"`
+	LastRun   time.Time `json:"lastRun,omitzero"`
+	LastError string    `json:"lastError,omitempty"`
+	NextRun   time.Time `json:"nextRun,omitzero"`
+}
+
+// Failure is a job that failed for good: it ran out of attempts or failed
+// permanently
+type Failure struct {
+	JobID    string    `json:"jobId"`
+	Kind     string    `json:"kind"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// Stats is a snapshot of the manager's jobs and schedules
+type Stats struct {
+	Kinds     []KindStats     `json:"kinds"`
+	Schedules []ScheduleStats `json:"schedules"`
+	// Failures holds the most recent failures, newest first
+	Failures []Failure `json:"failures"`
+}
+
+// Stats returns a snapshot of the job outcomes, schedule runs and recent
+// failures, ordered by kind and schedule name
+func (m *Manager) Stats() Stats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	pending := make(map[string]int)
+	for _, job := range m.pending {
+		pending[job.Kind]++
+	}
+
+	stats := Stats{
+		Kinds:     make([]KindStats, 0, len(m.handlers)),
+		Schedules: make([]ScheduleStats, 0, len(m.schedules)),
+		Failures:  make([]Failure, 0, len(m.failures)),
+	}
+	for kind := range m.handlers {
+		counts := m.counts[kind]
+		counts.Kind = kind
+		counts.Pending = pending[kind]
+		stats.Kinds = append(stats.Kinds, counts)
+	}
+	for _, schedule := range m.schedules {
+		stats.Schedules = append(stats.Schedules, *schedule)
+	}
+	for i := len(m.failures) - 1; i >= 0; i-- {
+		stats.Failures = append(stats.Failures, m.failures[i])
+	}
+
+	sort.Slice(stats.Kinds, func(i, j int) bool { return stats.Kinds[i].Kind < stats.Kinds[j].Kind })
+	sort.Slice(stats.Schedules, func(i, j int) bool { return stats.Schedules[i].Name < stats.Schedules[j].Name })
+	return stats
+}
+
+// recordFailure keeps a failed job in the bounded list of recent failures.
+// The caller must hold the mutex.
+func (m *Manager) recordFailure(failure Failure) {
+	m.failures = append(m.failures, failure)
+	if len(m.failures) > maxFailures {
+		m.failures = m.failures[len(m.failures)-maxFailures:]
+	}
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store persists jobs that were not finished when the manager drained
+type Store interface {
+	Save(jobs []Job) error
+	Load() ([]Job, error)
+}
+
+// NopStore discards unfinished jobs
+type NopStore struct{}
+
+// Save implements Store
+func (NopStore) Save(jobs []Job) error { return nil }
+
+// Load implements Store
+func (NopStore) Load() ([]Job, error) { return nil, nil }
+
+// FileStore persists unfinished jobs as a JSON file
+type FileStore struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileStore creates a new file-backed job store
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Save writes the jobs to disk, removing the file when there is nothing to persist
+func (s *FileStore) Save(jobs []Job) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(jobs) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	// Write to a temporary file first so a crash never leaves a partial file
+	tmp := s.path + ".partial"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// Load reads previously persisted jobs from disk
+func (s *FileStore) Load() ([]Job, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, err
+	}
+
+	sortJobs(jobs)
+	return jobs, nil
+}
+
+// sortJobs orders jobs by enqueue time so resumed work keeps its original order
+func sortJobs(jobs []Job) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].EnqueuedAt.Before(jobs[j].EnqueuedAt)
+	})
+}
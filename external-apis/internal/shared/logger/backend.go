@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"context"
+	"io"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logrusBackend writes JSON entries through logrus
+type logrusBackend struct {
+	logger *logrus.Logger
+}
+
+// NewLogrusBackend returns a backend writing JSON entries to out through
+// logrus. Level checks happen before entries reach it.
+func NewLogrusBackend(out io.Writer) Backend {
+	logger := logrus.New()
+	logger.SetOutput(out)
+	logger.SetFormatter(&logrus.JSONFormatter{
+		TimestampFormat: "2006-01-02 15:04:05",
+	})
+	logger.SetLevel(logrus.TraceLevel)
+	logger.AddHook(ContextHook{})
+	return &logrusBackend{logger: logger}
+}
+
+func (b *logrusBackend) Write(ctx context.Context, level Level, msg string, fields Fields) {
+	var logrusLevel logrus.Level
+	switch level {
+	case DebugLevel:
+		logrusLevel = logrus.DebugLevel
+	case InfoLevel:
+		logrusLevel = logrus.InfoLevel
+	case WarnLevel:
+		logrusLevel = logrus.WarnLevel
+	case ErrorLevel:
+		logrusLevel = logrus.ErrorLevel
+	default:
+		// Entry.Log only writes fatal entries; the Logger exits itself
+		logrusLevel = logrus.FatalLevel
+	}
+	b.logger.WithContext(ctx).WithFields(logrus.Fields(fields)).Log(logrusLevel, msg)
+}
+
+func (b *logrusBackend) Sync() error {
+	return nil
+}
+
+// ContextHook adds the correlation fields of the context of a logrus entry,
+// e.g. the request ID, to the entry. Fields set on the entry itself win.
+// Logrus loggers outside this package can add it too, so their entries
+// logged WithContext are correlated like those of the Logger.
+type ContextHook struct{}
+
+// Levels returns the levels the hook fires for, all of them
+func (ContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire adds the correlation fields of the entry's context
+func (ContextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	for key, value := range CorrelationFields(entry.Context) {
+		if _, ok := entry.Data[key]; !ok {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
+
+// zapBackend writes JSON entries through zap
+type zapBackend struct {
+	logger *zap.Logger
+}
+
+// NewZapBackend returns a backend writing JSON entries to out through zap.
+// Level checks happen before entries reach it.
+func NewZapBackend(out io.Writer) Backend {
+	encoder := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "time",
+		LevelKey:       "level",
+		MessageKey:     "msg",
+		EncodeTime:     zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05"),
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	core := zapcore.NewCore(encoder, zapcore.AddSync(out), zapcore.DebugLevel)
+	// The Logger exits itself after fatal entries, once they are flushed
+	return &zapBackend{logger: zap.New(core, zap.WithFatalHook(noExit{}))}
+}
+
+func (b *zapBackend) Write(ctx context.Context, level Level, msg string, fields Fields) {
+	var zapLevel zapcore.Level
+	switch level {
+	case DebugLevel:
+		zapLevel = zapcore.DebugLevel
+	case InfoLevel:
+		zapLevel = zapcore.InfoLevel
+	case WarnLevel:
+		zapLevel = zapcore.WarnLevel
+	case ErrorLevel:
+		zapLevel = zapcore.ErrorLevel
+	default:
+		zapLevel = zapcore.FatalLevel
+	}
+
+	correlation := CorrelationFields(ctx)
+	for key, value := range fields {
+		correlation[key] = value
+	}
+	fields = correlation
+
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, key := range sortedKeys(fields) {
+		zapFields = append(zapFields, zap.Any(key, fields[key]))
+	}
+	b.logger.Log(zapLevel, msg, zapFields...)
+}
+
+func (b *zapBackend) Sync() error {
+	return b.logger.Sync()
+}
+
+// noExit keeps zap from exiting the process after a fatal entry
+type noExit struct{}
+
+func (noExit) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
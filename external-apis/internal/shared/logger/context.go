@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"external-apis/internal/shared/tenant"
+)
+
+const (
+	// RequestIDHeader carries the ID correlating the log entries of a request
+	RequestIDHeader = "X-Request-ID"
+	// TraceparentHeader carries the W3C trace context of a request
+	TraceparentHeader = "traceparent"
+)
+
+type requestIDKey struct{}
+
+type traceKey struct{}
+
+// traceparentPattern matches a version 00 W3C traceparent header:
+// version-traceid-spanid-flags
+var traceparentPattern = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}$`)
+
+// Trace identifies the distributed trace and span a request belongs to
+type Trace struct {
+	TraceID string
+	SpanID  string
+	// Header is the traceparent header the trace was parsed from, forwarded
+	// unchanged to downstream services
+	Header string
+}
+
+// ParseTraceparent parses a W3C traceparent header. It reports false for a
+// missing or malformed header, or one with an all-zero trace or span ID.
+func ParseTraceparent(value string) (Trace, bool) {
+	match := traceparentPattern.FindStringSubmatch(value)
+	if match == nil || match[1] == "00000000000000000000000000000000" || match[2] == "0000000000000000" {
+		return Trace{}, false
+	}
+	return Trace{TraceID: match[1], SpanID: match[2], Header: value}, true
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTrace returns a copy of ctx carrying the trace
+func WithTrace(ctx context.Context, trace Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFromContext returns the trace carried by ctx
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	trace, ok := ctx.Value(traceKey{}).(Trace)
+	return trace, ok
+}
+
+// Inject sets the correlation headers of ctx on an outbound request, so the
+// downstream service logs under the same request and trace IDs
+func Inject(ctx context.Context, header http.Header) {
+	if id := RequestID(ctx); id != "" {
+		header.Set(RequestIDHeader, id)
+	}
+	if trace, ok := TraceFromContext(ctx); ok {
+		header.Set(TraceparentHeader, trace.Header)
+	}
+}
+
+// CorrelationFields returns the fields correlating the log entries written
+// with ctx: the tenant, the request ID and the trace and span IDs. Fields
+// missing from ctx are left out.
+func CorrelationFields(ctx context.Context) Fields {
+	fields := Fields{}
+	if id, ok := tenant.Lookup(ctx); ok {
+		fields["tenant"] = id
+	}
+	if id := RequestID(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if trace, ok := TraceFromContext(ctx); ok {
+		fields["trace_id"] = trace.TraceID
+		fields["span_id"] = trace.SpanID
+	}
+	return fields
+}
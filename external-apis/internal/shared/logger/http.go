@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = New("shared/logger")
+
+// SetLevelRequest represents the request to change a log level
+type SetLevelRequest struct {
+	// Package is the package to override; empty changes the default level
+	Package string `json:"package"`
+	Level   string `json:"level" binding:"required"`
+}
+
+// Handler serves the admin endpoints for changing log levels at runtime
+type Handler struct{}
+
+// NewHandler creates a new log level admin handler
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// RegisterRoutes registers the log level admin routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	levels := router.Group("/log-levels")
+	{
+		levels.GET("", h.ListLevels)
+		levels.PUT("", h.SetLevel)
+		// Package names contain slashes, so they are matched as a wildcard
+		levels.DELETE("/*package", h.ResetLevel)
+	}
+}
+
+// ListLevels returns the default level and the per-package overrides
+func (h *Handler) ListLevels(c *gin.Context) {
+	response.OK(c, CurrentLevels())
+}
+
+// SetLevel changes the default level or the level of a package
+func (h *Handler) SetLevel(c *gin.Context) {
+	var req SetLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	pkg := strings.Trim(req.Package, "/")
+	SetLevel(pkg, level)
+	log.Ctx(c.Request.Context()).WithFields(Fields{
+		"target": pkg,
+		"level":  level.String(),
+	}).Info("Log level changed")
+
+	response.OK(c, CurrentLevels())
+}
+
+// ResetLevel removes the override of a package
+func (h *Handler) ResetLevel(c *gin.Context) {
+	pkg := strings.Trim(c.Param("package"), "/")
+	if !ResetLevel(pkg) {
+		response.NotFound(c, "No log level override for package "+pkg)
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithField("target", pkg).Info("Log level override removed")
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// levelSet holds the default level and the per-package overrides. An
+// override applies to the package and its subpackages, so "customer" also
+// covers "customer/service"; the most specific override wins.
+type levelSet struct {
+	mu       sync.RWMutex
+	base     Level
+	packages map[string]Level
+}
+
+func newLevels(base Level) *levelSet {
+	return &levelSet{base: base, packages: map[string]Level{}}
+}
+
+// of returns the level in effect for a package
+func (s *levelSet) of(pkg string) Level {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for name := pkg; name != ""; {
+		if level, ok := s.packages[name]; ok {
+			return level
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return s.base
+}
+
+// replace swaps the default level and all overrides
+func (s *levelSet) replace(base Level, packages map[string]Level) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.base = base
+	s.packages = make(map[string]Level, len(packages))
+	for pkg, level := range packages {
+		s.packages[pkg] = level
+	}
+}
+
+// Levels is a snapshot of the levels in effect
+type Levels struct {
+	Level    Level            `json:"level"`
+	Packages map[string]Level `json:"packages"`
+}
+
+// CurrentLevels returns the default level and the per-package overrides
+func CurrentLevels() Levels {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	packages := make(map[string]Level, len(levels.packages))
+	for pkg, level := range levels.packages {
+		packages[pkg] = level
+	}
+	return Levels{Level: levels.base, Packages: packages}
+}
+
+// SetLevel changes the level of a package and its subpackages at runtime.
+// An empty package changes the default level.
+func SetLevel(pkg string, level Level) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if pkg == "" {
+		levels.base = level
+		return
+	}
+	levels.packages[pkg] = level
+}
+
+// ResetLevel removes the override of a package, which falls back to the
+// override of its parent or the default level. It reports whether the
+// package had an override.
+func ResetLevel(pkg string) bool {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	_, ok := levels.packages[pkg]
+	delete(levels.packages, pkg)
+	return ok
+}
+
+// ParseOverrides parses per-package levels written as "package=level", such
+// as "customer/service=debug"
+func ParseOverrides(entries []string) (map[string]Level, error) {
+	overrides := make(map[string]Level, len(entries))
+	for _, entry := range entries {
+		pkg, name, ok := strings.Cut(entry, "=")
+		pkg = strings.Trim(strings.TrimSpace(pkg), "/")
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid log level override %q, use package=level", entry)
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level override %q: %w", entry, err)
+		}
+		overrides[pkg] = level
+	}
+	return overrides, nil
+}
@@ -0,0 +1,245 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Fields are the structured fields attached to a log entry
+type Fields map[string]interface{}
+
+// Level is the severity of a log entry
+type Level int8
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+// ParseLevel parses a level name such as "debug" or "warn"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	case "fatal":
+		return FatalLevel, nil
+	default:
+		return InfoLevel, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return fmt.Sprintf("level(%d)", l)
+	}
+}
+
+// MarshalText encodes the level by its name
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes a level from its name
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Backend writes log entries that passed the level check. The context of
+// the entry, context.Background() unless the logger was given one with Ctx,
+// supplies its correlation fields.
+type Backend interface {
+	Write(ctx context.Context, level Level, msg string, fields Fields)
+	Sync() error
+}
+
+// Config configures the logger of the process
+type Config struct {
+	// Backend is "logrus" (the default) or "zap"
+	Backend string
+	// Level applies to every package without an override
+	Level Level
+	// Packages overrides the level of packages and their subpackages
+	Packages map[string]Level
+}
+
+var (
+	mu      sync.RWMutex
+	backend Backend = NewLogrusBackend(os.Stderr)
+	levels          = newLevels(InfoLevel)
+
+	// exit terminates the process after a fatal entry; tests replace it
+	exit = os.Exit
+)
+
+// Configure selects the backend and levels of the process. It is called once
+// at startup; levels can still be changed afterwards with SetLevel.
+func Configure(config Config) error {
+	var selected Backend
+	switch config.Backend {
+	case "", "logrus":
+		selected = NewLogrusBackend(os.Stderr)
+	case "zap":
+		selected = NewZapBackend(os.Stderr)
+	default:
+		return fmt.Errorf("unknown log backend %q, use logrus or zap", config.Backend)
+	}
+
+	SetBackend(selected)
+	levels.replace(config.Level, config.Packages)
+	return nil
+}
+
+// SetBackend replaces the backend entries are written to
+func SetBackend(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	backend = b
+}
+
+// Sync flushes entries buffered by the backend
+func Sync() error {
+	mu.RLock()
+	defer mu.RUnlock()
+	return backend.Sync()
+}
+
+// Logger writes structured log entries on behalf of a package. Loggers are
+// immutable: the With methods return a copy carrying the extra fields.
+type Logger struct {
+	pkg    string
+	fields Fields
+	ctx    context.Context
+}
+
+// New returns the logger of a package. The package name selects level
+// overrides and is attached to every entry; it mirrors the directory of the
+// package below internal, e.g. "customer/service".
+func New(pkg string) *Logger {
+	return &Logger{pkg: pkg}
+}
+
+// Ctx returns a logger whose entries carry the correlation fields of ctx:
+// the request ID, the tenant and the trace and span IDs. Fields missing from
+// ctx are left out.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	return &Logger{pkg: l.pkg, fields: l.fields, ctx: ctx}
+}
+
+// WithField returns a logger carrying an extra field
+func (l *Logger) WithField(key string, value interface{}) *Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+// WithFields returns a logger carrying extra fields
+func (l *Logger) WithFields(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return &Logger{pkg: l.pkg, fields: merged, ctx: l.ctx}
+}
+
+// WithError returns a logger carrying err in the error field
+func (l *Logger) WithError(err error) *Logger {
+	if err == nil {
+		return l
+	}
+	return l.WithField("error", err.Error())
+}
+
+// Enabled reports whether entries of the level are written for the package
+func (l *Logger) Enabled(level Level) bool {
+	return level >= levels.of(l.pkg)
+}
+
+// Debug writes a debug entry
+func (l *Logger) Debug(msg string) {
+	l.write(DebugLevel, msg)
+}
+
+// Info writes an info entry
+func (l *Logger) Info(msg string) {
+	l.write(InfoLevel, msg)
+}
+
+// Warn writes a warning entry
+func (l *Logger) Warn(msg string) {
+	l.write(WarnLevel, msg)
+}
+
+// Error writes an error entry
+func (l *Logger) Error(msg string) {
+	l.write(ErrorLevel, msg)
+}
+
+// Fatal writes a fatal entry and exits the process
+func (l *Logger) Fatal(msg string) {
+	l.write(FatalLevel, msg)
+	_ = Sync()
+	exit(1)
+}
+
+// write passes the entry to the backend if its level is enabled
+func (l *Logger) write(level Level, msg string) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := make(Fields, len(l.fields)+1)
+	for key, value := range l.fields {
+		fields[key] = value
+	}
+	fields["package"] = l.pkg
+
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	backend.Write(ctx, level, msg, fields)
+}
+
+// sortedKeys returns the field names in a stable order
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
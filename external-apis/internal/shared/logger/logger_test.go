@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"external-apis/internal/shared/tenant"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// entry is a log entry captured by recorder
+type entry struct {
+	ctx    context.Context
+	level  Level
+	msg    string
+	fields Fields
+}
+
+// recorder is a backend capturing the entries it receives
+type recorder struct {
+	entries []entry
+}
+
+func (r *recorder) Write(ctx context.Context, level Level, msg string, fields Fields) {
+	r.entries = append(r.entries, entry{ctx: ctx, level: level, msg: msg, fields: fields})
+}
+
+func (r *recorder) Sync() error {
+	return nil
+}
+
+// record routes entries to a recorder and resets the levels for the test
+func record(t *testing.T, config Config) *recorder {
+	t.Helper()
+	rec := &recorder{}
+	require.NoError(t, Configure(config))
+	SetBackend(rec)
+	t.Cleanup(func() {
+		require.NoError(t, Configure(Config{Level: InfoLevel}))
+	})
+	return rec
+}
+
+func TestLogger_Ctx(t *testing.T) {
+	// Arrange
+	rec := record(t, Config{Level: InfoLevel})
+	trace, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	ctx := WithTrace(WithRequestID(tenant.WithTenant(context.Background(), "brand-a"), "req-123"), trace)
+
+	// Act
+	New("customer/service").Ctx(ctx).WithField("customer_id", "customer-1").WithError(errors.New("boom")).Error("Failed to get customer")
+	New("customer/service").Ctx(context.Background()).Info("Background work")
+
+	// Assert
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, ErrorLevel, rec.entries[0].level)
+	assert.Equal(t, Fields{
+		"package":     "customer/service",
+		"customer_id": "customer-1",
+		"error":       "boom",
+	}, rec.entries[0].fields)
+	assert.Equal(t, Fields{
+		"request_id": "req-123",
+		"tenant":     "brand-a",
+		"trace_id":   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":    "00f067aa0ba902b7",
+	}, CorrelationFields(rec.entries[0].ctx))
+	assert.Empty(t, CorrelationFields(rec.entries[1].ctx), "contexts without correlation fields add none")
+}
+
+func TestLogger_PackageLevels(t *testing.T) {
+	// Arrange
+	rec := record(t, Config{
+		Level:    WarnLevel,
+		Packages: map[string]Level{"customer": DebugLevel, "customer/handler": ErrorLevel},
+	})
+
+	// Act
+	New("customer/service").Debug("subpackage inherits the override")
+	New("customer/handler").Warn("more specific override wins")
+	New("product/service").Info("default level applies")
+	New("product/service").Warn("default level passes")
+
+	// Assert
+	require.Len(t, rec.entries, 2)
+	assert.Equal(t, "subpackage inherits the override", rec.entries[0].msg)
+	assert.Equal(t, "default level passes", rec.entries[1].msg)
+}
+
+func TestSetLevel(t *testing.T) {
+	// Arrange
+	rec := record(t, Config{Level: InfoLevel})
+	log := New("shared/jobs")
+
+	// Act
+	log.Debug("hidden")
+	SetLevel("shared/jobs", DebugLevel)
+	log.Debug("shown")
+	removed := ResetLevel("shared/jobs")
+	log.Debug("hidden again")
+	SetLevel("", ErrorLevel)
+	log.Warn("hidden by the default level")
+
+	// Assert
+	require.Len(t, rec.entries, 1)
+	assert.Equal(t, "shown", rec.entries[0].msg)
+	assert.True(t, removed)
+	assert.False(t, ResetLevel("shared/jobs"))
+	assert.Equal(t, Levels{Level: ErrorLevel, Packages: map[string]Level{}}, CurrentLevels())
+}
+
+func TestLogger_Fatal(t *testing.T) {
+	// Arrange
+	rec := record(t, Config{Level: InfoLevel})
+	exited := 0
+	exit = func(code int) { exited = code }
+	t.Cleanup(func() { exit = os.Exit })
+
+	// Act
+	New("main").Fatal("Failed to start")
+
+	// Assert
+	require.Len(t, rec.entries, 1)
+	assert.Equal(t, FatalLevel, rec.entries[0].level)
+	assert.Equal(t, 1, exited)
+}
+
+func TestBackends(t *testing.T) {
+	backends := map[string]func(*bytes.Buffer) Backend{
+		"logrus": func(out *bytes.Buffer) Backend { return NewLogrusBackend(out) },
+		"zap":    func(out *bytes.Buffer) Backend { return NewZapBackend(out) },
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			var out bytes.Buffer
+			backend := newBackend(&out)
+
+			ctx := WithRequestID(tenant.WithTenant(context.Background(), "brand-a"), "req-123")
+
+			// Act
+			backend.Write(ctx, WarnLevel, "Rate limiter unavailable", Fields{"count": 2, "tenant": "brand-b"})
+			require.NoError(t, backend.Sync())
+
+			// Assert
+			var written map[string]interface{}
+			require.NoError(t, json.Unmarshal(out.Bytes(), &written))
+			assert.Equal(t, "Rate limiter unavailable", written["msg"])
+			assert.Contains(t, []string{"warn", "warning"}, written["level"])
+			assert.Equal(t, "req-123", written["request_id"])
+			assert.Equal(t, "brand-b", written["tenant"], "fields of the entry win over those of the context")
+			assert.Equal(t, float64(2), written["count"])
+			assert.NotEmpty(t, written["time"])
+		})
+	}
+}
+
+func TestContextHook(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&out)
+	log.SetFormatter(&logrus.JSONFormatter{})
+	log.AddHook(ContextHook{})
+
+	// Act
+	log.WithContext(WithRequestID(context.Background(), "req-123")).Info("Cache warmed")
+	log.Info("Without context")
+
+	// Assert
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var withContext, withoutContext map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &withContext))
+	require.NoError(t, json.Unmarshal(lines[1], &withoutContext))
+	assert.Equal(t, "req-123", withContext["request_id"])
+	assert.NotContains(t, withoutContext, "request_id")
+}
+
+func TestParseOverrides(t *testing.T) {
+	// Act
+	overrides, err := ParseOverrides([]string{"customer/service=debug", " /shared/jobs/ = warning "})
+	_, invalidLevel := ParseOverrides([]string{"customer=verbose"})
+	_, missingPackage := ParseOverrides([]string{"debug"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Level{"customer/service": DebugLevel, "shared/jobs": WarnLevel}, overrides)
+	assert.Error(t, invalidLevel)
+	assert.Error(t, missingPackage)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		valid  bool
+	}{
+		{name: "Valid", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: true},
+		{name: "Missing", header: "", valid: false},
+		{name: "Unknown version", header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", valid: false},
+		{name: "Zero trace ID", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", valid: false},
+		{name: "Upper case", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			trace, ok := ParseTraceparent(tt.header)
+
+			// Assert
+			assert.Equal(t, tt.valid, ok)
+			if tt.valid {
+				assert.Equal(t, tt.header, trace.Header)
+			}
+		})
+	}
+}
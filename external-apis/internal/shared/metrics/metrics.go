@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// UnmatchedPath is used as the path label for requests that matched no route,
+// so arbitrary URLs cannot blow up label cardinality
+const UnmatchedPath = "unmatched"
+
+// HTTPMetrics holds the Prometheus collectors for HTTP traffic
+type HTTPMetrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewHTTPMetrics creates HTTP collectors for a service and registers them,
+// together with the Go runtime and process collectors, on a dedicated registry
+func NewHTTPMetrics(service string) *HTTPMetrics {
+	constLabels := prometheus.Labels{"service": service}
+
+	m := &HTTPMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "http_requests_total",
+			Help:        "Total number of HTTP requests processed.",
+			ConstLabels: constLabels,
+		}, []string{"method", "path", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "http_request_duration_seconds",
+			Help:        "HTTP request latency in seconds.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"method", "path", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "http_requests_in_flight",
+			Help:        "Number of HTTP requests currently being processed.",
+			ConstLabels: constLabels,
+		}, []string{"method", "path"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.latency,
+		m.inFlight,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Registry returns the registry backing these metrics so other packages can
+// register additional collectors
+func (m *HTTPMetrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Started marks a request as in flight and returns a function that records
+// its status and latency once it completes
+func (m *HTTPMetrics) Started(method, path string) func(status int) {
+	start := time.Now()
+	gauge := m.inFlight.WithLabelValues(method, path)
+	gauge.Inc()
+
+	return func(status int) {
+		gauge.Dec()
+
+		code := strconv.Itoa(status)
+		m.requests.WithLabelValues(method, path, code).Inc()
+		m.latency.WithLabelValues(method, path, code).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler returns a Gin handler serving the metrics in the Prometheus text format
+func (m *HTTPMetrics) Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// ConsumerMetrics holds the Prometheus collectors for messages consumed from
+// queues
+type ConsumerMetrics struct {
+	messages *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewConsumerMetrics creates message collectors for a service and registers
+// them on registry
+func NewConsumerMetrics(registry prometheus.Registerer, service string) *ConsumerMetrics {
+	constLabels := prometheus.Labels{"service": service}
+
+	m := &ConsumerMetrics{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "consumer_messages_total",
+			Help:        "Total number of consumed messages, by outcome.",
+			ConstLabels: constLabels,
+		}, []string{"queue", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "consumer_message_duration_seconds",
+			Help:        "Time taken to process a consumed message in seconds.",
+			ConstLabels: constLabels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"queue", "outcome"}),
+	}
+
+	registry.MustRegister(m.messages, m.duration)
+	return m
+}
+
+// Observe records a message of queue processed with the given outcome. It
+// does nothing on nil metrics, so consumers can run without them.
+func (m *ConsumerMetrics) Observe(queue, outcome string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.messages.WithLabelValues(queue, outcome).Inc()
+	m.duration.WithLabelValues(queue, outcome).Observe(duration.Seconds())
+}
+
+// Outcomes of repository operations
+const (
+	// OutcomeOK means the operation succeeded
+	OutcomeOK = "ok"
+	// OutcomeRejected means the operation failed with an application error,
+	// such as a missing entity, and the storage worked as intended
+	OutcomeRejected = "rejected"
+	// OutcomeError means the storage failed, e.g. a query or connection error
+	OutcomeError = "error"
+)
+
+// RepositoryMetrics holds the Prometheus collectors for the operations of
+// repositories, so the latency and error rate of a storage backend can be
+// watched per operation
+type RepositoryMetrics struct {
+	registry   prometheus.Registerer
+	service    string
+	operations *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+// NewRepositoryMetrics creates repository collectors for a service and
+// registers them on registry
+func NewRepositoryMetrics(registry prometheus.Registerer, service string) *RepositoryMetrics {
+	constLabels := prometheus.Labels{"service": service}
+
+	m := &RepositoryMetrics{
+		registry: registry,
+		service:  service,
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "repository_operations_total",
+			Help:        "Total number of repository operations, by outcome.",
+			ConstLabels: constLabels,
+		}, []string{"repository", "operation", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "repository_operation_duration_seconds",
+			Help:        "Repository operation latency in seconds.",
+			ConstLabels: constLabels,
+			Buckets:     []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "operation"}),
+	}
+
+	registry.MustRegister(m.operations, m.duration)
+	return m
+}
+
+// Started returns a function that records an operation of repository once
+// it completes with err. It does nothing on nil metrics, so repositories can
+// run without them.
+func (m *RepositoryMetrics) Started(repository, operation string) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+
+	start := time.Now()
+	return func(err error) {
+		m.duration.WithLabelValues(repository, operation).Observe(time.Since(start).Seconds())
+		m.operations.WithLabelValues(repository, operation, Outcome(err)).Inc()
+	}
+}
+
+// WatchPool registers collectors for the connection pool of db, such as the
+// connections in use and idle and the time spent waiting for one, labelled
+// with the name of the database
+func (m *RepositoryMetrics) WatchPool(db *sql.DB, name string) {
+	if m == nil {
+		return
+	}
+
+	prometheus.WrapRegistererWith(prometheus.Labels{"service": m.service}, m.registry).
+		MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Outcome classifies the error a repository operation returned
+func Outcome(err error) string {
+	var appErr *apperror.Error
+	switch {
+	case err == nil:
+		return OutcomeOK
+	case errors.As(err, &appErr):
+		return OutcomeRejected
+	default:
+		return OutcomeError
+	}
+}
@@ -0,0 +1,149 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/database"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetrics_Started(t *testing.T) {
+	// Arrange
+	m := NewHTTPMetrics("test-service")
+
+	// Act
+	done := m.Started("GET", "/api/products/:id")
+	inFlight := testutil.ToFloat64(m.inFlight.WithLabelValues("GET", "/api/products/:id"))
+	done(http.StatusOK)
+
+	// Assert
+	assert.Equal(t, float64(1), inFlight)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.inFlight.WithLabelValues("GET", "/api/products/:id")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("GET", "/api/products/:id", "200")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.latency, "http_request_duration_seconds"))
+}
+
+func TestHTTPMetrics_Handler(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	m := NewHTTPMetrics("test-service")
+	m.Started("POST", "/api/customers")(http.StatusCreated)
+
+	router := gin.New()
+	router.GET("/metrics", m.Handler())
+
+	// Act
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="POST",path="/api/customers",service="test-service",status="201"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestConsumerMetrics_Observe(t *testing.T) {
+	t.Run("Count messages by outcome", func(t *testing.T) {
+		// Arrange
+		m := NewHTTPMetrics("test-service")
+		consumer := NewConsumerMetrics(m.Registry(), "test-service")
+
+		// Act
+		consumer.Observe("catalog-updates", "processed", 10*time.Millisecond)
+		consumer.Observe("catalog-updates", "processed", 20*time.Millisecond)
+		consumer.Observe("catalog-updates", "malformed", time.Millisecond)
+
+		// Assert
+		assert.Equal(t, float64(2), testutil.ToFloat64(consumer.messages.WithLabelValues("catalog-updates", "processed")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(consumer.messages.WithLabelValues("catalog-updates", "malformed")))
+		assert.Equal(t, 2, testutil.CollectAndCount(consumer.duration, "consumer_message_duration_seconds"))
+	})
+
+	t.Run("Nil metrics", func(t *testing.T) {
+		// Arrange
+		var consumer *ConsumerMetrics
+
+		// Act & Assert
+		assert.NotPanics(t, func() { consumer.Observe("catalog-updates", "processed", time.Millisecond) })
+	})
+}
+
+func TestRepositoryMetrics_Started(t *testing.T) {
+	t.Run("Count operations by outcome", func(t *testing.T) {
+		// Arrange
+		m := NewHTTPMetrics("test-service")
+		repository := NewRepositoryMetrics(m.Registry(), "test-service")
+
+		// Act
+		repository.Started("customers", "GetByID")(nil)
+		repository.Started("customers", "GetByID")(apperror.NotFound("customer not found"))
+		repository.Started("customers", "GetByID")(errors.New("database is locked"))
+
+		// Assert
+		for _, outcome := range []string{OutcomeOK, OutcomeRejected, OutcomeError} {
+			assert.Equal(t, float64(1), testutil.ToFloat64(repository.operations.WithLabelValues("customers", "GetByID", outcome)), outcome)
+		}
+		assert.Equal(t, 1, testutil.CollectAndCount(repository.duration, "repository_operation_duration_seconds"))
+	})
+
+	t.Run("Nil metrics", func(t *testing.T) {
+		// Arrange
+		var repository *RepositoryMetrics
+
+		// Act & Assert
+		assert.NotPanics(t, func() { repository.Started("customers", "GetByID")(nil) })
+	})
+}
+
+func TestRepositoryMetrics_WatchPool(t *testing.T) {
+	// Arrange
+	m := NewHTTPMetrics("test-service")
+	repository := NewRepositoryMetrics(m.Registry(), "test-service")
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "test.db"), database.DefaultOptions())
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Act
+	repository.WatchPool(db, "sqlite")
+
+	// Assert
+	families, err := m.Registry().Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	assert.True(t, names["go_sql_in_use_connections"])
+	assert.True(t, names["go_sql_idle_connections"])
+}
+
+func TestOutcome(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"No error", nil, OutcomeOK},
+		{"Application error", fmt.Errorf("loading: %w", apperror.Conflict("customer already exists")), OutcomeRejected},
+		{"Storage error", sql.ErrConnDone, OutcomeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Assert
+			assert.Equal(t, tt.want, Outcome(tt.err))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"external-apis/internal/shared/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ClaimsKey is the Gin context key holding the authenticated *auth.Claims
+	ClaimsKey = "auth_claims"
+	// AuthenticatedKey is set to true by any middleware that has already
+	// authenticated the request, such as API key authentication
+	AuthenticatedKey = "authenticated"
+	// APIKeyIDKey is the Gin context key holding the ID of the API key that
+	// authenticated the request
+	APIKeyIDKey = "api_key_id"
+)
+
+// Authorizer decides whether the authenticated caller of a request may
+// perform it, aborting the request when it may not
+type Authorizer func(c *gin.Context) bool
+
+// JWTAuth middleware requires a valid Bearer token and injects its claims
+// into the Gin context and its subject, as the actor, into the request
+// context. Handlers attach it only to the routes they protect. Requests
+// already authenticated by another scheme are let through. The caller must
+// then pass every authorizer.
+func JWTAuth(validator *auth.Validator, authorizers ...Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(AuthenticatedKey) {
+			header := c.GetHeader("Authorization")
+			scheme, token, found := strings.Cut(header, " ")
+			if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				unauthorized(c, "Missing bearer token")
+				return
+			}
+
+			claims, err := validator.Validate(strings.TrimSpace(token))
+			if err != nil {
+				log.Ctx(c.Request.Context()).WithError(err).Debug("Rejected bearer token")
+				unauthorized(c, "Invalid or expired token")
+				return
+			}
+
+			c.Set(ClaimsKey, claims)
+			c.Set(AuthenticatedKey, true)
+			c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), "user:"+claims.Subject))
+		}
+
+		if authorize(c, authorizers) {
+			c.Next()
+		}
+	}
+}
+
+// NoAuth middleware lets every request through to the authorizers. It stands
+// in for JWTAuth when no verification key is configured.
+func NoAuth(authorizers ...Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authorize(c, authorizers) {
+			c.Next()
+		}
+	}
+}
+
+// authorize reports whether the request passes every authorizer
+func authorize(c *gin.Context, authorizers []Authorizer) bool {
+	for _, authorizer := range authorizers {
+		if !authorizer(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Claims returns the claims injected by JWTAuth, if any
+func Claims(c *gin.Context) (*auth.Claims, bool) {
+	value, exists := c.Get(ClaimsKey)
+	if !exists {
+		return nil, false
+	}
+
+	claims, ok := value.(*auth.Claims)
+	return claims, ok
+}
+
+// unauthorized aborts the request with a 401 response
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="external-apis"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error":   "unauthorized",
+		"message": message,
+		"code":    http.StatusUnauthorized,
+	})
+}
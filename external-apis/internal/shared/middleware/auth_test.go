@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("test-secret")
+
+// signToken signs an HMAC token for subject with secret
+func signToken(t *testing.T, secret []byte, subject string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(secret)
+	require.NoError(t, err)
+	return token
+}
+
+// serveAuth sends a request with the authorization header, when not empty,
+// through JWTAuth and returns the actor and subject seen by the handler
+func serveAuth(t *testing.T, authorization string) (*httptest.ResponseRecorder, string, string) {
+	t.Helper()
+	validator, err := auth.NewValidator(auth.Config{HMACSecret: testSecret})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var actor, subject string
+	router.POST("/items", JWTAuth(validator), func(c *gin.Context) {
+		actor = auth.Actor(c.Request.Context())
+		if claims, ok := Claims(c); ok {
+			subject = claims.Subject
+		}
+		c.Status(http.StatusCreated)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/items", nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder, actor, subject
+}
+
+func TestJWTAuth(t *testing.T) {
+	t.Run("Missing token", func(t *testing.T) {
+		// Act
+		recorder, _, _ := serveAuth(t, "")
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, `Bearer realm="external-apis"`, recorder.Header().Get("WWW-Authenticate"))
+		assert.JSONEq(t, `{"error":"unauthorized","message":"Missing bearer token","code":401}`, recorder.Body.String())
+	})
+
+	t.Run("Bad token", func(t *testing.T) {
+		// Arrange
+		token := signToken(t, []byte("other-secret"), "user-1")
+
+		// Act
+		recorder, _, _ := serveAuth(t, "Bearer "+token)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.JSONEq(t, `{"error":"unauthorized","message":"Invalid or expired token","code":401}`, recorder.Body.String())
+	})
+
+	t.Run("Valid token", func(t *testing.T) {
+		// Arrange
+		token := signToken(t, testSecret, "user-1")
+
+		// Act
+		recorder, actor, subject := serveAuth(t, "Bearer "+token)
+
+		// Assert
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "user-1", subject)
+		assert.Equal(t, "user:user-1", actor)
+	})
+}
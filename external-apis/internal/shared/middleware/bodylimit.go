@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// originalBodyKey is the Gin context key holding the request body before
+// BodyLimit wrapped it
+const originalBodyKey = "original_body"
+
+// BodyLimit middleware caps request bodies at limit bytes. Reading past the
+// limit fails with *http.MaxBytesError, which response.InvalidRequest turns
+// into 413 Payload Too Large; a body whose Content-Length is over the limit
+// fails on the first read, before any of it is consumed. A limit of zero or
+// less leaves bodies unlimited.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit > 0 && c.Request.Body != nil {
+			c.Set(originalBodyKey, c.Request.Body)
+			limitBody(c, limit)
+		}
+		c.Next()
+	}
+}
+
+// RaiseBodyLimit middleware replaces the limit BodyLimit set for a route,
+// letting uploads and imports exceed the API default. A limit of zero or
+// less removes it.
+func RaiseBodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if original, ok := c.Get(originalBodyKey); ok {
+			c.Request.Body = original.(io.ReadCloser)
+		}
+		if limit > 0 && c.Request.Body != nil {
+			limitBody(c, limit)
+		}
+		c.Next()
+	}
+}
+
+// limitBody caps the request body at limit bytes
+func limitBody(c *gin.Context, limit int64) {
+	if c.Request.ContentLength > limit {
+		log.Ctx(c.Request.Context()).WithField("content_length", c.Request.ContentLength).Warn("Request body too large")
+		c.Request.Body = oversizedBody{ReadCloser: c.Request.Body, limit: limit}
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+}
+
+// oversizedBody stands in for a body declared larger than the limit
+type oversizedBody struct {
+	io.ReadCloser
+	limit int64
+}
+
+// Read fails without reading the body
+func (b oversizedBody) Read([]byte) (int, error) {
+	return 0, &http.MaxBytesError{Limit: b.limit}
+}
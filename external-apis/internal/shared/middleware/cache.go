@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachePolicyKey is the Gin context key holding the cache policy Cache set
+// for a route
+const cachePolicyKey = "cache_policy"
+
+// CachePolicy declares whether and for how long caches may keep the
+// successful responses of a route
+type CachePolicy struct {
+	// MaxAge is how long caches may serve a response without revalidating
+	// it; zero forbids storing it at all
+	MaxAge time.Duration
+	// Private keeps shared caches, such as a CDN, from storing the response
+	// while still letting the client cache it
+	Private bool
+	// Vary lists the request headers that change the response, so caches
+	// keep a copy per value. Vary headers of other middleware are kept.
+	Vary []string
+}
+
+// NoStore is the policy of routes that did not declare one
+var NoStore = CachePolicy{}
+
+// cacheableStatuses are the statuses whose responses a policy applies to.
+// Errors are never stored so a CDN does not keep serving a 404 or 503 after
+// the cause is gone.
+var cacheableStatuses = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusPartialContent:       true,
+	http.StatusNotModified:          true,
+}
+
+// CacheControl middleware sets the Cache-Control and Vary headers of
+// responses from the policy their route declared with Cache, or NoStore if it
+// declared none. Policies only apply to successful GET and HEAD responses;
+// anything else is sent with no-store. Handlers that set Cache-Control
+// themselves keep theirs.
+func CacheControl() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &cacheWriter{ResponseWriter: c.Writer, c: c}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+
+		c.Next()
+
+		// Responses without a body, such as 304, are only written once the
+		// handlers returned
+		writer.apply()
+	}
+}
+
+// Cache middleware declares the cache policy of a route. It takes effect
+// under CacheControl.
+func Cache(policy CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(cachePolicyKey, policy)
+		c.Next()
+	}
+}
+
+// String returns the Cache-Control header value of the policy
+func (p CachePolicy) String() string {
+	if p.MaxAge <= 0 {
+		return "no-store"
+	}
+
+	scope := "public"
+	if p.Private {
+		scope = "private"
+	}
+	return scope + ", max-age=" + strconv.FormatInt(int64(p.MaxAge/time.Second), 10)
+}
+
+// cacheWriter sets the caching headers of a response right before its
+// headers are written, once its status is known
+type cacheWriter struct {
+	gin.ResponseWriter
+	c       *gin.Context
+	applied bool
+}
+
+// apply sets the caching headers from the policy of the route unless they
+// were set already
+func (w *cacheWriter) apply() {
+	if w.applied {
+		return
+	}
+	w.applied = true
+
+	header := w.ResponseWriter.Header()
+	if header.Get("Cache-Control") != "" {
+		return
+	}
+
+	policy := NoStore
+	if value, ok := w.c.Get(cachePolicyKey); ok {
+		policy = value.(CachePolicy)
+	}
+	method := w.c.Request.Method
+	if (method != http.MethodGet && method != http.MethodHead) || !cacheableStatuses[w.ResponseWriter.Status()] {
+		policy = NoStore
+	}
+
+	header.Set("Cache-Control", policy.String())
+	if len(policy.Vary) > 0 {
+		header.Add("Vary", strings.Join(policy.Vary, ", "))
+	}
+}
+
+func (w *cacheWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCacheRouter returns a router whose /catalog routes are cacheable for a
+// minute per tenant and whose /customers routes declare no policy. Requests
+// are compressed too, whose Vary header must survive.
+func newCacheRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CacheControl(), Compress(0, nil))
+
+	catalog := CachePolicy{MaxAge: time.Minute, Vary: []string{"X-Tenant-ID"}}
+	router.GET("/catalog", Cache(catalog), handler)
+	router.POST("/catalog", Cache(catalog), handler)
+	router.GET("/customers", handler)
+	return router
+}
+
+func TestCacheControl(t *testing.T) {
+	respond := func(status int) gin.HandlerFunc {
+		return func(c *gin.Context) { c.JSON(status, gin.H{"id": "item-1"}) }
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		handler      gin.HandlerFunc
+		cacheControl string
+		vary         []string
+	}{
+		{
+			name:         "Cacheable route",
+			method:       http.MethodGet,
+			path:         "/catalog",
+			handler:      respond(http.StatusOK),
+			cacheControl: "public, max-age=60",
+			vary:         []string{"Accept-Encoding", "X-Tenant-ID"},
+		},
+		{
+			name:         "Not modified",
+			method:       http.MethodGet,
+			path:         "/catalog",
+			handler:      func(c *gin.Context) { c.Status(http.StatusNotModified) },
+			cacheControl: "public, max-age=60",
+			vary:         []string{"X-Tenant-ID"},
+		},
+		{
+			name:         "Error of a cacheable route",
+			method:       http.MethodGet,
+			path:         "/catalog",
+			handler:      respond(http.StatusNotFound),
+			cacheControl: "no-store",
+			vary:         []string{"Accept-Encoding"},
+		},
+		{
+			name:         "Write to a cacheable route",
+			method:       http.MethodPost,
+			path:         "/catalog",
+			handler:      respond(http.StatusCreated),
+			cacheControl: "no-store",
+			vary:         []string{"Accept-Encoding"},
+		},
+		{
+			name:         "Route without a policy",
+			method:       http.MethodGet,
+			path:         "/customers",
+			handler:      respond(http.StatusOK),
+			cacheControl: "no-store",
+			vary:         []string{"Accept-Encoding"},
+		},
+		{
+			name:   "Policy set by the handler",
+			method: http.MethodGet,
+			path:   "/customers",
+			handler: func(c *gin.Context) {
+				c.Header("Cache-Control", "private, max-age=5")
+				c.JSON(http.StatusOK, gin.H{"id": "item-1"})
+			},
+			cacheControl: "private, max-age=5",
+			vary:         []string{"Accept-Encoding"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := newCacheRouter(tt.handler)
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+
+			// Act
+			router.ServeHTTP(recorder, req)
+
+			// Assert
+			assert.Equal(t, tt.cacheControl, recorder.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.vary, recorder.Header().Values("Vary"))
+		})
+	}
+}
+
+func TestCachePolicy_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy CachePolicy
+		want   string
+	}{
+		{name: "No store", policy: NoStore, want: "no-store"},
+		{name: "Public", policy: CachePolicy{MaxAge: 90 * time.Second}, want: "public, max-age=90"},
+		{name: "Private", policy: CachePolicy{MaxAge: time.Hour, Private: true}, want: "private, max-age=3600"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, tt.policy.String())
+		})
+	}
+}
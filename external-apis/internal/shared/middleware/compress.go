@@ -0,0 +1,257 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings the compression middleware produces
+const (
+	EncodingBrotli = "br"
+	EncodingGzip   = "gzip"
+)
+
+// DefaultCompressTypes are the content types compressed when no list is
+// configured. Images, archives and other already compressed formats are
+// left out since compressing them again only costs CPU.
+var DefaultCompressTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/x-ndjson",
+	"application/javascript",
+	"application/xml",
+	"image/svg+xml",
+	"text/*",
+}
+
+// Compress middleware compresses response bodies with brotli or gzip,
+// whichever the client prefers in Accept-Encoding, favouring brotli on a
+// tie. Only bodies of the allowed content types and at least minSize bytes
+// long are compressed; types ending in /* allow every subtype. Responses
+// that already set a Content-Encoding, such as gzipped exports, are passed
+// through untouched. Strong ETags are weakened on compressed responses since
+// the bytes sent no longer match them.
+func Compress(minSize int, types []string) gin.HandlerFunc {
+	if len(types) == 0 {
+		types = DefaultCompressTypes
+	}
+
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{
+			ResponseWriter: c.Writer,
+			encoding:       encoding,
+			minSize:        minSize,
+			types:          types,
+		}
+		c.Writer = writer
+		defer func() {
+			if err := writer.finish(); err != nil {
+				log.Ctx(c.Request.Context()).WithError(err).Warn("Failed to complete compressed response")
+			}
+			c.Writer = writer.ResponseWriter
+		}()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the coding to compress with from an
+// Accept-Encoding header, or "" if the client accepts neither
+func negotiateEncoding(acceptEncoding string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != EncodingBrotli && coding != EncodingGzip {
+			continue
+		}
+
+		q := 1.0
+		if value, found := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		if q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && coding == EncodingBrotli) {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response body until it knows
+// whether compressing it is worthwhile, then writes it either through an
+// encoder or as it is
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	types    []string
+
+	buffer  bytes.Buffer
+	decided bool
+	encoder io.WriteCloser
+}
+
+// Write buffers data until the response reaches minSize bytes
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+
+	w.buffer.Write(data)
+	if w.buffer.Len() < w.minSize {
+		return len(data), nil
+	}
+	if err := w.decide(); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteString implements gin.ResponseWriter
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow sends the headers of a response without a body, which is
+// never compressed
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided && w.buffer.Len() == 0 {
+		w.decided = true
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush sends what has been written so far. A streamed response is
+// compressed regardless of minSize since its length is not known yet.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.minSize = 0
+		if err := w.decide(); err != nil {
+			return
+		}
+	}
+
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// write sends data through the encoder, if compressing, or as it is
+func (w *compressWriter) write(data []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// decide chooses whether to compress the response and writes out the
+// buffered start of the body
+func (w *compressWriter) decide() error {
+	w.decided = true
+	if w.shouldCompress() {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		if w.encoding == EncodingBrotli {
+			w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotli.DefaultCompression)
+		} else {
+			w.encoder = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+	_, err := w.write(w.buffer.Bytes())
+	w.buffer.Reset()
+	return err
+}
+
+// shouldCompress checks if the response is worth compressing
+func (w *compressWriter) shouldCompress() bool {
+	// Bodiless responses are left alone, and so is partial content, which
+	// must stay byte-for-byte what the range asked for
+	status := w.Status()
+	if w.buffer.Len() < w.minSize || status < http.StatusOK || status == http.StatusNoContent ||
+		status == http.StatusPartialContent || status == http.StatusNotModified {
+		return false
+	}
+
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buffer.Bytes())
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return allowedType(mediaType, w.types)
+}
+
+// finish writes out a body still buffered when the handler returns and
+// completes the compressed stream
+func (w *compressWriter) finish() error {
+	if !w.decided {
+		if w.buffer.Len() == 0 {
+			w.decided = true
+			return nil
+		}
+		if err := w.decide(); err != nil {
+			return err
+		}
+	}
+	if w.encoder != nil {
+		return w.encoder.Close()
+	}
+	return nil
+}
+
+// allowedType checks if a media type is in the allowlist
+func allowedType(mediaType string, types []string) bool {
+	for _, allowed := range types {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if prefix, wildcard := strings.CutSuffix(allowed, "/*"); wildcard {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
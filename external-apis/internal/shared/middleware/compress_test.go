@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve sends a request through the compression and body limit middleware
+// to handler
+func serve(method, acceptEncoding string, body io.Reader, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimit(16), Compress(64, nil))
+	router.Handle(method, "/items", handler)
+	router.POST("/uploads", RaiseBodyLimit(0), handler)
+
+	path := "/items"
+	if method == "UPLOAD" {
+		method, path = http.MethodPost, "/uploads"
+	}
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("compressible ", 20)
+	sendJSON := func(c *gin.Context) {
+		c.Header("ETag", `"abc"`)
+		c.JSON(http.StatusOK, gin.H{"text": large})
+	}
+
+	t.Run("Gzip", func(t *testing.T) {
+		// Act
+		recorder := serve(http.MethodGet, "gzip", nil, sendJSON)
+
+		// Assert
+		assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+		assert.Equal(t, `W/"abc"`, recorder.Header().Get("ETag"), "a strong ETag no longer matches the bytes sent")
+		reader, err := gzip.NewReader(recorder.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"text":"`+large+`"}`, string(body))
+	})
+
+	t.Run("Brotli preferred on a tie", func(t *testing.T) {
+		// Act
+		recorder := serve(http.MethodGet, "gzip, deflate, br", nil, sendJSON)
+
+		// Assert
+		assert.Equal(t, "br", recorder.Header().Get("Content-Encoding"))
+		body, err := io.ReadAll(brotli.NewReader(recorder.Body))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"text":"`+large+`"}`, string(body))
+	})
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		handler        gin.HandlerFunc
+	}{
+		{name: "Not accepted", acceptEncoding: "identity", handler: sendJSON},
+		{name: "Refused with q=0", acceptEncoding: "gzip;q=0, br;q=0", handler: sendJSON},
+		{
+			name:           "Below the minimum size",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"id": 1})
+			},
+		},
+		{
+			name:           "Content type not allowed",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Data(http.StatusOK, "image/png", []byte(large))
+			},
+		},
+		{
+			name:           "Already encoded",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Encoding", "gzip")
+				c.Data(http.StatusOK, "text/csv", []byte(large))
+			},
+		},
+		{
+			name:           "Not modified",
+			acceptEncoding: "gzip",
+			handler: func(c *gin.Context) {
+				c.Status(http.StatusNotModified)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			recorder := serve(http.MethodGet, tt.acceptEncoding, nil, tt.handler)
+
+			// Assert
+			if tt.name != "Already encoded" {
+				assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+			}
+			assert.NotContains(t, recorder.Header().Values("Vary"), "Accept-Encoding")
+		})
+	}
+
+	t.Run("Streamed response", func(t *testing.T) {
+		// Act
+		recorder := serve(http.MethodGet, "gzip", nil, func(c *gin.Context) {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Writer.WriteString("{\"id\":1}\n")
+			c.Writer.Flush()
+			c.Writer.WriteString("{\"id\":2}\n")
+		})
+
+		// Assert
+		assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"), "a flushed response is compressed whatever its size")
+		reader, err := gzip.NewReader(recorder.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", string(body))
+	})
+}
+
+func TestBodyLimit(t *testing.T) {
+	readBody := func(c *gin.Context) {
+		var body struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			var tooLarge *http.MaxBytesError
+			if assert.ErrorAs(t, err, &tooLarge) {
+				c.Status(http.StatusRequestEntityTooLarge)
+			}
+			return
+		}
+		c.String(http.StatusOK, body.Name)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   io.Reader
+		status int
+	}{
+		{name: "Within the limit", method: http.MethodPost, body: strings.NewReader(`{"name":"ok"}`), status: http.StatusOK},
+		{name: "Declared length over the limit", method: http.MethodPost, body: strings.NewReader(`{"name":"far too long"}`), status: http.StatusRequestEntityTooLarge},
+		{name: "Streamed body over the limit", method: http.MethodPost, body: io.MultiReader(strings.NewReader(`{"name":"far too long"}`)), status: http.StatusRequestEntityTooLarge},
+		{name: "Raised limit", method: "UPLOAD", body: strings.NewReader(`{"name":"far too long"}`), status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			recorder := serve(tt.method, "", tt.body, readBody)
+
+			// Assert
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"external-apis/internal/shared/deadline"
+	"external-apis/internal/shared/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Deadline middleware honors the X-Request-Deadline budget sent by the caller.
+// The remaining budget is attached to the request context so downstream calls
+// made with that context (see deadline.Transport) inherit it instead of
+// starting a fresh timeout on every hop. Budgets above maxBudget are clamped
+// to it, so a caller cannot hold the request open longer; zero leaves them as
+// sent.
+func Deadline(maxBudget time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(deadline.Header)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		budget, err := deadline.Parse(value)
+		if err != nil {
+			log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+				"value":      value,
+				"request_id": c.GetString("request_id"),
+			}).Warn("Ignoring invalid request deadline header")
+			c.Next()
+			return
+		}
+
+		if budget <= 0 {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"error":   "deadline_exceeded",
+				"message": "Request deadline budget exhausted",
+				"code":    http.StatusGatewayTimeout,
+			})
+			return
+		}
+		if maxBudget > 0 && budget > maxBudget {
+			budget = maxBudget
+		}
+
+		ctx, cancel := deadline.WithBudget(c.Request.Context(), budget)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
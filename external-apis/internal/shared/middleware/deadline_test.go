@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/deadline"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveDeadline sends a request with the deadline header set to value, when
+// not empty, through the deadline middleware and returns the budget left to
+// the handler
+func serveDeadline(value string, maxBudget time.Duration) (*httptest.ResponseRecorder, time.Duration, bool) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Deadline(maxBudget))
+
+	var remaining time.Duration
+	var bounded bool
+	router.GET("/items", func(c *gin.Context) {
+		remaining, bounded = deadline.Remaining(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if value != "" {
+		request.Header.Set(deadline.Header, value)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder, remaining, bounded
+}
+
+func TestDeadline(t *testing.T) {
+	t.Run("No header", func(t *testing.T) {
+		// Act
+		recorder, _, bounded := serveDeadline("", time.Minute)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.False(t, bounded)
+	})
+
+	t.Run("Attach the budget of the header", func(t *testing.T) {
+		// Act
+		recorder, remaining, bounded := serveDeadline("2000", time.Minute)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		require.True(t, bounded)
+		assert.LessOrEqual(t, remaining, 2*time.Second)
+		assert.Greater(t, remaining, time.Second)
+	})
+
+	t.Run("Ignore an invalid header", func(t *testing.T) {
+		// Act
+		recorder, _, bounded := serveDeadline("2s", time.Minute)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.False(t, bounded)
+	})
+
+	t.Run("Clamp the budget to the maximum", func(t *testing.T) {
+		// Act
+		recorder, remaining, bounded := serveDeadline("3600000", time.Second)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		require.True(t, bounded)
+		assert.LessOrEqual(t, remaining, time.Second)
+	})
+
+	t.Run("Keep the budget without a maximum", func(t *testing.T) {
+		// Act
+		recorder, remaining, bounded := serveDeadline("3600000", 0)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		require.True(t, bounded)
+		assert.Greater(t, remaining, 59*time.Minute)
+	})
+
+	t.Run("Answer an exhausted budget with 504", func(t *testing.T) {
+		// Act
+		recorder, _, _ := serveDeadline("0", time.Minute)
+
+		// Assert
+		assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+		assert.JSONEq(t, `{"error":"deadline_exceeded","message":"Request deadline budget exhausted","code":504}`, recorder.Body.String())
+	})
+}
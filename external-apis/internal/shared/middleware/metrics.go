@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"external-apis/internal/shared/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics middleware records request count, latency and in-flight requests.
+// Requests are labelled with the matched route template rather than the raw
+// URL so IDs in the path do not create a new series per entity.
+func Metrics(recorder *metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			path = metrics.UnmatchedPath
+		}
+
+		done := recorder.Started(c.Request.Method, path)
+		defer func() {
+			done(c.Writer.Status())
+		}()
+
+		c.Next()
+	}
+}
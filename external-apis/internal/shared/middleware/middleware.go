@@ -3,14 +3,16 @@ package middleware
 import (
 	"time"
 
+	"external-apis/internal/shared/logger"
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
 )
 
+var log = logger.New("shared/middleware")
+
 // Logger middleware for request logging
 func Logger() gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		logrus.WithFields(logrus.Fields{
+		log.Ctx(param.Request.Context()).WithFields(logger.Fields{
 			"client_ip":   param.ClientIP,
 			"timestamp":   param.TimeStamp.Format(time.RFC3339),
 			"method":      param.Method,
@@ -29,9 +31,9 @@ func Logger() gin.HandlerFunc {
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token")
-		c.Header("Access-Control-Expose-Headers", "Link")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-API-Key, X-CSRF-Token, X-Request-Deadline, X-Request-ID, X-Response-Envelope, X-Tenant-ID, traceparent")
+		c.Header("Access-Control-Expose-Headers", "API-Version, Deprecation, Link, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "300")
 
@@ -44,16 +46,26 @@ func CORS() gin.HandlerFunc {
 	}
 }
 
-// RequestID middleware adds a unique request ID to each request
-func RequestID() gin.HandlerFunc {
+// RequestID middleware adds a unique request ID to each request. The ID and
+// the W3C trace context of the traceparent header, if any, are stored in the
+// request context so log entries written with it are correlated. Requests
+// arriving without an ID get one of the given format, see RequestIDUUIDv7.
+func RequestID(format string) gin.HandlerFunc {
+	generateRequestID := newRequestIDGenerator(format)
 	return func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
+		requestID := c.GetHeader(logger.RequestIDHeader)
 		if requestID == "" {
 			requestID = generateRequestID()
 		}
 
-		c.Header("X-Request-ID", requestID)
+		c.Header(logger.RequestIDHeader, requestID)
 		c.Set("request_id", requestID)
+
+		ctx := logger.WithRequestID(c.Request.Context(), requestID)
+		if trace, ok := logger.ParseTraceparent(c.GetHeader(logger.TraceparentHeader)); ok {
+			ctx = logger.WithTrace(ctx, trace)
+		}
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
@@ -61,7 +73,7 @@ func RequestID() gin.HandlerFunc {
 // Recovery middleware for panic recovery
 func Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		logrus.WithField("panic", recovered).Error("Panic recovered")
+		log.Ctx(c.Request.Context()).WithField("panic", recovered).Error("Panic recovered")
 		c.JSON(500, gin.H{
 			"error":   "internal_server_error",
 			"message": "Internal server error occurred",
@@ -69,26 +81,3 @@ func Recovery() gin.HandlerFunc {
 		})
 	})
 }
-
-// Rate limiting middleware (simple implementation)
-func RateLimit() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Simple rate limiting - in production, use Redis-based rate limiting
-		c.Next()
-	}
-}
-
-// generateRequestID generates a unique request ID
-func generateRequestID() string {
-	return time.Now().Format("20060102150405") + "-" + randomString(8)
-}
-
-// randomString generates a random string of specified length
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(b)
-}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"external-apis/internal/shared/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the header carrying the API key of a client
+const APIKeyHeader = "X-API-Key"
+
+// RateLimit middleware enforces a token-bucket limit per client IP. When
+// byAPIKey is set, requests authenticated by an API key are limited per key
+// ID instead, so clients behind a shared NAT do not starve each other. It
+// must run after API key authentication: the raw header is never trusted, so
+// made-up keys cannot buy a fresh budget. Limiter errors fail open: an
+// unavailable Redis must not take the API down.
+func RateLimit(limiter ratelimit.Limiter, byAPIKey bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "ip:" + c.ClientIP()
+		if id := c.GetString(APIKeyIDKey); byAPIKey && id != "" {
+			key = "key:" + id
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			log.Ctx(c.Request.Context()).WithError(err).Warn("Rate limiter unavailable, allowing request")
+			c.Next()
+			return
+		}
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.Header("X-RateLimit-Remaining", "0")
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "too_many_requests",
+				"message": "Rate limit exceeded, retry later",
+				"code":    http.StatusTooManyRequests,
+			})
+			return
+		}
+
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Next()
+	}
+}
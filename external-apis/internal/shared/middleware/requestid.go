@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Formats of the request IDs generated for requests arriving without one
+const (
+	// RequestIDUUIDv7 IDs sort by the time they were generated
+	RequestIDUUIDv7 = "uuidv7"
+	// RequestIDUUIDv4 IDs are fully random
+	RequestIDUUIDv4 = "uuidv4"
+	// RequestIDRandom IDs are a timestamp followed by 26 random base32
+	// characters, e.g. 20240115103000-PLVXMQ2XK6ZRBXQ5D2R3WWG3JQ
+	RequestIDRandom = "random"
+)
+
+// newRequestIDGenerator returns the generator of request IDs of the given
+// format. Unknown formats generate UUIDv7s.
+func newRequestIDGenerator(format string) func() string {
+	switch format {
+	case RequestIDUUIDv4:
+		return func() string { return uuid.NewString() }
+	case RequestIDRandom:
+		return func() string { return time.Now().Format("20060102150405") + "-" + rand.Text() }
+	default:
+		return func() string { return uuid.Must(uuid.NewV7()).String() }
+	}
+}
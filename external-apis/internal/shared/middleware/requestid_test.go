@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"external-apis/internal/shared/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveRequestID sends a request with the given X-Request-ID header through
+// the request ID middleware and returns the response and the ID the handler
+// found in the request context
+func serveRequestID(format, header string) (*httptest.ResponseRecorder, string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(format))
+
+	var seen string
+	router.GET("/items", func(c *gin.Context) {
+		seen = logger.RequestID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if header != "" {
+		req.Header.Set(logger.RequestIDHeader, header)
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder, seen
+}
+
+func TestRequestID(t *testing.T) {
+	t.Run("Generate IDs of the configured format", func(t *testing.T) {
+		tests := []struct {
+			format  string
+			version uuid.Version
+		}{
+			{format: RequestIDUUIDv7, version: 7},
+			{format: RequestIDUUIDv4, version: 4},
+			{format: "", version: 7},
+		}
+
+		for _, tt := range tests {
+			// Act
+			recorder, seen := serveRequestID(tt.format, "")
+
+			// Assert
+			id, err := uuid.Parse(recorder.Header().Get(logger.RequestIDHeader))
+			require.NoError(t, err, tt.format)
+			assert.Equal(t, tt.version, id.Version(), tt.format)
+			assert.Equal(t, id.String(), seen, tt.format)
+		}
+	})
+
+	t.Run("Generate random IDs after a timestamp", func(t *testing.T) {
+		// Act
+		recorder, _ := serveRequestID(RequestIDRandom, "")
+
+		// Assert
+		assert.Regexp(t, regexp.MustCompile(`^\d{14}-[A-Z2-7]{26}$`), recorder.Header().Get(logger.RequestIDHeader))
+	})
+
+	t.Run("Generate a different ID for every request", func(t *testing.T) {
+		for _, format := range []string{RequestIDUUIDv7, RequestIDUUIDv4, RequestIDRandom} {
+			// Arrange
+			generate := newRequestIDGenerator(format)
+			seen := make(map[string]bool)
+
+			// Act
+			for i := 0; i < 1000; i++ {
+				seen[generate()] = true
+			}
+
+			// Assert
+			assert.Len(t, seen, 1000, format)
+		}
+	})
+
+	t.Run("Keep the ID sent by the client", func(t *testing.T) {
+		// Act
+		recorder, seen := serveRequestID(RequestIDUUIDv7, "req-123")
+
+		// Assert
+		assert.Equal(t, "req-123", recorder.Header().Get(logger.RequestIDHeader))
+		assert.Equal(t, "req-123", seen)
+	})
+}
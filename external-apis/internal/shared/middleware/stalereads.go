@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"external-apis/internal/shared/database"
+	"github.com/gin-gonic/gin"
+)
+
+// StaleReads middleware lets the repositories serve GET and HEAD requests
+// from read replicas that may lag behind the primary database. Clients that
+// must read their own writes opt out per request with Cache-Control:
+// no-cache, which sends the reads to the primary.
+func StaleReads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		if (method == http.MethodGet || method == http.MethodHead) &&
+			!strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") {
+			c.Request = c.Request.WithContext(database.WithStaleReads(c.Request.Context()))
+		}
+		c.Next()
+	}
+}
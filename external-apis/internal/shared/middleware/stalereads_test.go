@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"external-apis/internal/shared/database"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaleReads(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		cacheControl string
+		want         bool
+	}{
+		{"Reads tolerate staleness", http.MethodGet, "", true},
+		{"Clients ask for fresh reads", http.MethodGet, "no-cache", false},
+		{"Writes read fresh data", http.MethodPost, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(StaleReads())
+			var allowed bool
+			router.Handle(tt.method, "/items", func(c *gin.Context) {
+				allowed = database.StaleReadsAllowed(c.Request.Context())
+				c.Status(http.StatusNoContent)
+			})
+			req := httptest.NewRequest(tt.method, "/items", nil)
+			if tt.cacheControl != "" {
+				req.Header.Set("Cache-Control", tt.cacheControl)
+			}
+
+			// Act
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.want, allowed)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// originalContextKey is the Gin context key holding the request context
+// before Timeout shortened it
+const originalContextKey = "original_context"
+
+// Timeout middleware bounds how long handlers may take. The request context
+// is cancelled after timeout, so repository and downstream calls made with it
+// give up; a response that has not started by then is replaced with 504
+// Gateway Timeout, whatever the handler makes of the cancellation. A shorter
+// deadline set by the caller (see Deadline) still applies. A timeout of zero
+// or less leaves handlers unbounded.
+//
+// Handlers run on the request goroutine, so the timeout cannot stop one that
+// ignores the context: it runs to completion, holding the connection, and is
+// answered with 504 when it did not respond in time.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(originalContextKey, c.Request.Context())
+		defer applyTimeout(c, timeout)()
+
+		c.Next()
+	}
+}
+
+// RaiseTimeout middleware replaces the timeout Timeout set for a route,
+// letting imports, exports and uploads outlive the API default. A timeout of
+// zero or less removes it.
+func RaiseTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if original, ok := c.Get(originalContextKey); ok {
+			c.Request = c.Request.WithContext(original.(context.Context))
+		}
+		defer applyTimeout(c, timeout)()
+
+		c.Next()
+	}
+}
+
+// applyTimeout sets the deadline of the request context and makes sure a
+// timed out request is answered with 504. The returned function must run
+// once the handlers returned.
+func applyTimeout(c *gin.Context, timeout time.Duration) func() {
+	writer, wrapped := c.Writer.(*timeoutWriter)
+	if !wrapped {
+		writer = &timeoutWriter{ResponseWriter: c.Writer, c: c}
+		c.Writer = writer
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(c.Request.Context(), timeout)
+		c.Request = c.Request.WithContext(ctx)
+	}
+
+	return func() {
+		// Handlers that returned without responding are answered as well
+		writer.expired()
+		cancel()
+	}
+}
+
+// timeoutWriter replaces the response of a request whose deadline passed
+// before the handler started writing it. It records the start of the
+// response itself since the writers it wraps, such as the compression one,
+// may hold the response back.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	c        *gin.Context
+	started  bool
+	timedOut bool
+}
+
+// expired reports whether the response was replaced, replacing it if the
+// request deadline has passed and nothing was written yet
+func (w *timeoutWriter) expired() bool {
+	if w.timedOut {
+		return true
+	}
+	if w.started || !errors.Is(w.c.Request.Context().Err(), context.DeadlineExceeded) {
+		return false
+	}
+	w.timedOut = true
+
+	log.Ctx(w.c.Request.Context()).WithFields(logger.Fields{
+		"method":     w.c.Request.Method,
+		"path":       w.c.FullPath(),
+		"request_id": w.c.GetString("request_id"),
+	}).Warn("Request timed out")
+
+	w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	_ = json.NewEncoder(w.ResponseWriter).Encode(response.ErrorResponse{
+		Error:   "deadline_exceeded",
+		Message: "Request timed out",
+		Code:    http.StatusGatewayTimeout,
+	})
+	return true
+}
+
+// WriteHeader drops the handler's status once the request timed out
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.expired() {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// WriteHeaderNow drops the handler's headers once the request timed out
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.expired() {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Write drops the handler's body once the request timed out
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.started = true
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString drops the handler's body once the request timed out
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.expired() {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.started = true
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush drops the handler's flushes once the request timed out
+func (w *timeoutWriter) Flush() {
+	if w.expired() {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.Flush()
+}
+
+// Written reports whether the handler started its response, even if a
+// wrapped writer still holds it back
+func (w *timeoutWriter) Written() bool {
+	return w.started || w.ResponseWriter.Written()
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveTimeout sends a request through the timeout middleware to handler.
+// /slow raises the timeout and /unbounded removes it.
+func serveTimeout(path string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Timeout(20 * time.Millisecond))
+	router.GET("/items", handler)
+	router.GET("/slow", RaiseTimeout(time.Second), handler)
+	router.GET("/unbounded", RaiseTimeout(0), handler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder
+}
+
+// waitFor blocks until the request context is done or d has passed
+func waitFor(c *gin.Context, d time.Duration) {
+	select {
+	case <-c.Request.Context().Done():
+	case <-time.After(d):
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	t.Run("Fast handler", func(t *testing.T) {
+		// Act
+		recorder := serveTimeout("/items", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"ok":true}`, recorder.Body.String())
+	})
+
+	t.Run("Replace the error of a cancelled handler", func(t *testing.T) {
+		// Act
+		recorder := serveTimeout("/items", func(c *gin.Context) {
+			waitFor(c, time.Second)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": c.Request.Context().Err().Error()})
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+		assert.JSONEq(t, `{"error":"deadline_exceeded","message":"Request timed out","code":504}`, recorder.Body.String())
+	})
+
+	t.Run("Answer a handler that did not respond", func(t *testing.T) {
+		// Act
+		recorder := serveTimeout("/items", func(c *gin.Context) {
+			waitFor(c, time.Second)
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+	})
+
+	t.Run("Keep a response started in time", func(t *testing.T) {
+		// Act
+		recorder := serveTimeout("/items", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+			c.Writer.WriteHeaderNow()
+			waitFor(c, time.Second)
+			_, _ = c.Writer.WriteString("partial")
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "partial", recorder.Body.String())
+	})
+
+	t.Run("Keep a response held back by compression", func(t *testing.T) {
+		// Arrange
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(Compress(1024, []string{"application/json"}), Timeout(20*time.Millisecond))
+		router.GET("/items", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+			time.Sleep(40 * time.Millisecond)
+		})
+		request := httptest.NewRequest(http.MethodGet, "/items", nil)
+		request.Header.Set("Accept-Encoding", "gzip")
+		recorder := httptest.NewRecorder()
+
+		// Act
+		router.ServeHTTP(recorder, request)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"ok":true}`, recorder.Body.String())
+	})
+
+	t.Run("Let a handler ignoring the context run to completion", func(t *testing.T) {
+		// Arrange
+		start := time.Now()
+
+		// Act
+		recorder := serveTimeout("/items", func(c *gin.Context) {
+			time.Sleep(60 * time.Millisecond)
+			c.JSON(http.StatusOK, gin.H{"ok": true})
+		})
+
+		// Assert: the handler is not cut short, only its late response replaced
+		assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+		assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+		assert.JSONEq(t, `{"error":"deadline_exceeded","message":"Request timed out","code":504}`, recorder.Body.String())
+	})
+
+	t.Run("Raised timeout", func(t *testing.T) {
+		// Act
+		recorder := serveTimeout("/slow", func(c *gin.Context) {
+			waitFor(c, 50*time.Millisecond)
+			require.NoError(t, c.Request.Context().Err())
+			c.Status(http.StatusNoContent)
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+
+	t.Run("Removed timeout", func(t *testing.T) {
+		// Act
+		var hasDeadline bool
+		recorder := serveTimeout("/unbounded", func(c *gin.Context) {
+			_, hasDeadline = c.Request.Context().Deadline()
+			c.Status(http.StatusNoContent)
+		})
+
+		// Assert
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.False(t, hasDeadline)
+	})
+
+	t.Run("Shorter caller deadline", func(t *testing.T) {
+		// Arrange
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(Timeout(time.Minute))
+		var deadline time.Time
+		router.GET("/items", func(c *gin.Context) {
+			deadline, _ = c.Request.Context().Deadline()
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		// Act
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+
+		// Assert
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+	})
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ValidID middleware rejects requests whose path parameters name a malformed
+// ID with 400 Bad Request before the handlers look them up. Routes without
+// the parameters pass through.
+func ValidID(valid func(id string) bool, params ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, param := range params {
+			if id, ok := c.Params.Get(param); ok && !valid(id) {
+				log.Ctx(c.Request.Context()).WithField(param, id).Debug("Malformed ID")
+				response.BadRequest(c, "Malformed "+param)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"external-apis/internal/shared/idgen"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidID(t *testing.T) {
+	ids := idgen.Prefixed("cus_", idgen.UUIDv7())
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"Well-formed ID", "/customers/" + ids.New(), http.StatusNoContent},
+		{"Malformed ID", "/customers/customer-456", http.StatusBadRequest},
+		{"ID of another type", "/customers/prod_" + idgen.UUIDv7().New(), http.StatusBadRequest},
+		{"Route without an ID", "/customers", http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			customers := router.Group("/customers", ValidID(ids.Valid, "id"))
+			handled := false
+			handler := func(c *gin.Context) {
+				handled = true
+				c.Status(http.StatusNoContent)
+			}
+			customers.GET("", handler)
+			customers.GET("/:id", handler)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.want == http.StatusNoContent, handled)
+		})
+	}
+}
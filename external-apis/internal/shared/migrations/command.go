@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Usage describes the arguments of the migrate subcommand
+const Usage = `usage: migrate <command>
+
+commands:
+  up          apply the pending migrations
+  down [n]    roll back the last n applied migrations, 1 by default
+  status      list the migrations and when they were applied`
+
+// ErrUsage is returned by Run for arguments it does not understand
+var ErrUsage = errors.New(Usage)
+
+// Run carries out the migrate subcommand of a service with args, the
+// arguments following "migrate", and reports the outcome to out
+func Run(ctx context.Context, db *sql.DB, fsys fs.FS, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "up":
+		if len(args) > 1 {
+			return ErrUsage
+		}
+		applied, err := Up(ctx, db, fsys)
+		fmt.Fprintf(out, "Migrations applied: %d\n", applied)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return ErrUsage
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return ErrUsage
+			}
+			steps = n
+		}
+		rolledBack, err := Down(ctx, db, fsys, steps)
+		fmt.Fprintf(out, "Migrations rolled back: %d\n", rolledBack)
+		return err
+
+	case "status":
+		if len(args) > 1 {
+			return ErrUsage
+		}
+		statuses, err := List(ctx, db, fsys)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return ErrUsage
+	}
+}
@@ -0,0 +1,297 @@
+// Package migrations applies and rolls back the versioned schema migrations
+// of the SQL-backed repositories. A migration is a pair of SQL files,
+// <version>_<description>.up.sql and the optional .down.sql undoing it,
+// usually embedded in the binary of the service. The migrations applied to a
+// database are recorded in its schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/database"
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/migrations")
+
+var (
+	// ErrMigration is returned when a migration cannot be applied or rolled back
+	ErrMigration = errors.New("migration failed")
+	// ErrIrreversible is returned when rolling back a migration without a
+	// down script
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+	// ErrInvalidFile is returned for migration files that are not named
+	// <version>_<description>.up.sql or .down.sql, or that reuse a version
+	ErrInvalidFile = errors.New("invalid migration file")
+)
+
+// fileName matches the names of migration files
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change
+type Migration struct {
+	// Version orders the migrations; it is the number their files start with
+	Version int
+	// Name identifies the migration in schema_migrations: the file name
+	// without .up.sql
+	Name string
+	Up   string
+	// Down undoes Up; it is empty for migrations that cannot be rolled back
+	Down string
+}
+
+// Status tells whether a migration has been applied to a database
+type Status struct {
+	Migration
+	// AppliedAt is when the migration was applied, nil while it is pending
+	AppliedAt *time.Time
+}
+
+// Load reads the migrations in fsys, ordered by version. Files not ending in
+// .sql are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		match := fileName.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, name)
+		}
+		version, _ := strconv.Atoi(match[1])
+		stem := strings.TrimSuffix(name, "."+match[3]+".sql")
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: stem}
+			byVersion[version] = migration
+		} else if migration.Name != stem {
+			return nil, fmt.Errorf("%w: %s reuses version %d of %s", ErrInvalidFile, name, version, migration.Name)
+		}
+
+		script, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: %s has no up script", ErrInvalidFile, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// Up applies the migrations in fsys that were not applied to db yet, in
+// version order, each in a transaction of its own, and returns how many it
+// applied. Several migrating instances do not apply a migration twice.
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS) (int, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return 0, err
+	}
+	if err := createTable(ctx, db); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range migrations {
+		done, err := apply(ctx, db, migration)
+		if err != nil {
+			return applied, fmt.Errorf("%w: %s: %v", ErrMigration, migration.Name, err)
+		}
+		if done {
+			log.WithField("migration", migration.Name).Info("Applied migration")
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// Down rolls back the last steps migrations in fsys applied to db, newest
+// first, and returns how many it rolled back. It stops at the first
+// migration without a down script.
+func Down(ctx context.Context, db *sql.DB, fsys fs.FS, steps int) (int, error) {
+	statuses, err := List(ctx, db, fsys)
+	if err != nil {
+		return 0, err
+	}
+
+	rolledBack := 0
+	for i := len(statuses) - 1; i >= 0 && rolledBack < steps; i-- {
+		migration := statuses[i]
+		if migration.AppliedAt == nil {
+			continue
+		}
+		if migration.Down == "" {
+			return rolledBack, fmt.Errorf("%w: %s", ErrIrreversible, migration.Name)
+		}
+
+		err := database.Transaction(ctx, db, func(tx *sql.Tx) error {
+			if err := exec(ctx, tx, migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE name IN (?, ?)`, migration.Name, legacyName(migration.Name))
+			return err
+		})
+		if err != nil {
+			return rolledBack, fmt.Errorf("%w: %s: %v", ErrMigration, migration.Name, err)
+		}
+		log.WithField("migration", migration.Name).Info("Rolled back migration")
+		rolledBack++
+	}
+	return rolledBack, nil
+}
+
+// List returns the migrations in fsys, in version order, with when they
+// were applied to db
+func List(ctx context.Context, db *sql.DB, fsys fs.FS) ([]Status, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if err := createTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT name, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]time.Time)
+	for rows.Next() {
+		var name, appliedAt string
+		if err := rows.Scan(&name, &appliedAt); err != nil {
+			return nil, err
+		}
+		at, err := time.Parse(database.TimeFormat, appliedAt)
+		if err != nil {
+			return nil, err
+		}
+		applied[strings.TrimSuffix(name, ".sql")] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = Status{Migration: migration}
+		if at, ok := applied[migration.Name]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations in fsys not applied to db yet
+func Pending(ctx context.Context, db *sql.DB, fsys fs.FS) ([]Migration, error) {
+	statuses, err := List(ctx, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// createTable creates schema_migrations if needed
+func createTable(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("%w: creating schema_migrations: %v", ErrMigration, err)
+	}
+	return nil
+}
+
+// apply runs the up script of a migration and records it, unless it was
+// applied already. It reports whether the migration was applied.
+func apply(ctx context.Context, db *sql.DB, migration Migration) (bool, error) {
+	applied := false
+	err := database.Transaction(ctx, db, func(tx *sql.Tx) error {
+		// Checked within the transaction, which holds the write lock, so a
+		// concurrent instance cannot apply the migration in between
+		var count int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE name IN (?, ?)`,
+			migration.Name, legacyName(migration.Name)).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := exec(ctx, tx, migration.Up); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)`,
+			migration.Name, database.FormatTime(time.Now())); err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// legacyName is the name a migration was recorded under before migrations
+// had down scripts: its file name, e.g. 0001_create_customers.sql
+func legacyName(name string) string {
+	return name + ".sql"
+}
+
+// exec runs the statements of a script
+func exec(ctx context.Context, tx *sql.Tx, script string) error {
+	for _, statement := range statements(script) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statements splits a script into its statements. Comment lines are dropped
+// first so they may contain semicolons.
+func statements(script string) []string {
+	lines := strings.Split(script, "\n")
+	lines = slices.DeleteFunc(lines, func(line string) bool {
+		return strings.HasPrefix(strings.TrimSpace(line), "--")
+	})
+
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
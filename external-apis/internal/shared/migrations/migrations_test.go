@@ -0,0 +1,231 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"external-apis/internal/shared/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = fstest.MapFS{
+	"0001_create_items.up.sql":   {Data: []byte("-- items; one per row\nCREATE TABLE items (id TEXT PRIMARY KEY);\nCREATE INDEX items_id ON items (id);\n")},
+	"0001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+	"0002_add_name.up.sql":       {Data: []byte("ALTER TABLE items ADD COLUMN name TEXT NOT NULL DEFAULT '';")},
+	"0002_add_name.down.sql":     {Data: []byte("ALTER TABLE items DROP COLUMN name;")},
+	"README.md":                  {Data: []byte("Not a migration")},
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "data", "test.db"), database.DefaultOptions())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count))
+	return count > 0
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Pair the scripts by version", func(t *testing.T) {
+		// Act
+		migrations, err := Load(testMigrations)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.Equal(t, 1, migrations[0].Version)
+		assert.Equal(t, "0001_create_items", migrations[0].Name)
+		assert.Equal(t, "DROP TABLE items;", migrations[0].Down)
+		assert.Equal(t, "0002_add_name", migrations[1].Name)
+	})
+
+	t.Run("Reject invalid files", func(t *testing.T) {
+		for name, fsys := range map[string]fstest.MapFS{
+			"Unversioned":     {"create_items.up.sql": {Data: []byte("SELECT 1;")}},
+			"Reused version":  {"0001_a.up.sql": {Data: []byte("SELECT 1;")}, "0001_b.up.sql": {Data: []byte("SELECT 1;")}},
+			"Down without up": {"0001_a.down.sql": {Data: []byte("SELECT 1;")}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				// Act
+				_, err := Load(fsys)
+
+				// Assert
+				assert.ErrorIs(t, err, ErrInvalidFile)
+			})
+		}
+	})
+}
+
+func TestUp(t *testing.T) {
+	t.Run("Apply the migrations in order", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+
+		// Act
+		applied, err := Up(context.Background(), db, testMigrations)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, applied)
+		_, err = db.Exec(`INSERT INTO items (id, name) VALUES ('item-1', 'Item')`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Skip applied migrations", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+		_, err := Up(context.Background(), db, testMigrations)
+		require.NoError(t, err)
+
+		// Act
+		applied, err := Up(context.Background(), db, testMigrations)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+		pending, err := Pending(context.Background(), db, testMigrations)
+		require.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("Recognize migrations recorded by file name", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+		_, err := Up(context.Background(), db, fstest.MapFS{"0001_create_items.up.sql": testMigrations["0001_create_items.up.sql"]})
+		require.NoError(t, err)
+		_, err = db.Exec(`UPDATE schema_migrations SET name = '0001_create_items.sql'`)
+		require.NoError(t, err)
+
+		// Act
+		applied, err := Up(context.Background(), db, testMigrations)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+	})
+
+	t.Run("Roll back a failing migration", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+		broken := fstest.MapFS{
+			"0001_broken.up.sql": {Data: []byte("CREATE TABLE items (id TEXT PRIMARY KEY);\nCREATE TABLE items (id TEXT);")},
+		}
+
+		// Act
+		_, err := Up(context.Background(), db, broken)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrMigration)
+		assert.False(t, tableExists(t, db, "items"))
+	})
+}
+
+func TestDown(t *testing.T) {
+	t.Run("Roll back the newest migrations", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+		_, err := Up(context.Background(), db, testMigrations)
+		require.NoError(t, err)
+
+		// Act
+		rolledBack, err := Down(context.Background(), db, testMigrations, 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, rolledBack)
+		statuses, err := List(context.Background(), db, testMigrations)
+		require.NoError(t, err)
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Nil(t, statuses[1].AppliedAt)
+		_, err = db.Exec(`INSERT INTO items (id, name) VALUES ('item-1', 'Item')`)
+		assert.Error(t, err, "the name column is gone")
+	})
+
+	t.Run("Roll back everything", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+		_, err := Up(context.Background(), db, testMigrations)
+		require.NoError(t, err)
+
+		// Act
+		rolledBack, err := Down(context.Background(), db, testMigrations, 5)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, rolledBack)
+		assert.False(t, tableExists(t, db, "items"))
+	})
+
+	t.Run("Stop at an irreversible migration", func(t *testing.T) {
+		// Arrange
+		db := openTestDB(t)
+		irreversible := fstest.MapFS{"0001_create_items.up.sql": testMigrations["0001_create_items.up.sql"]}
+		_, err := Up(context.Background(), db, irreversible)
+		require.NoError(t, err)
+
+		// Act
+		_, err = Down(context.Background(), db, irreversible, 1)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrIrreversible)
+		assert.True(t, tableExists(t, db, "items"))
+	})
+}
+
+func TestRun(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	t.Run("Up", func(t *testing.T) {
+		// Act
+		var out bytes.Buffer
+		err := Run(ctx, db, testMigrations, []string{"up"}, &out)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Migrations applied: 2\n", out.String())
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		// Act
+		var out bytes.Buffer
+		err := Run(ctx, db, testMigrations, []string{"down"}, &out)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "Migrations rolled back: 1\n", out.String())
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		// Act
+		var out bytes.Buffer
+		err := Run(ctx, db, testMigrations, []string{"status"}, &out)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "0001_create_items")
+		assert.Contains(t, out.String(), "0002_add_name      pending")
+	})
+
+	t.Run("Reject unknown arguments", func(t *testing.T) {
+		for _, args := range [][]string{nil, {"sideways"}, {"down", "zero"}, {"down", "0"}, {"up", "1"}} {
+			// Act
+			err := Run(ctx, db, testMigrations, args, &bytes.Buffer{})
+
+			// Assert
+			assert.ErrorIs(t, err, ErrUsage, "%v", args)
+		}
+	})
+}
@@ -0,0 +1,259 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency is used when neither the request nor the configuration names a currency
+const DefaultCurrency = "USD"
+
+var (
+	// ErrInvalidAmount is returned when an amount is not a plain decimal number
+	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrTooPrecise is returned when an amount has more decimal places than its currency allows
+	ErrTooPrecise = errors.New("amount has more decimal places than the currency allows")
+	// ErrOutOfRange is returned when an amount does not fit in 64-bit minor units
+	ErrOutOfRange = errors.New("amount is out of range")
+	// ErrUnknownCurrency is returned for currency codes that are not supported ISO 4217 codes
+	ErrUnknownCurrency = errors.New("unknown currency")
+	// ErrCurrencyMismatch is returned when combining amounts in different currencies
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// minorUnits lists the supported ISO 4217 currencies and their number of decimal places
+var minorUnits = map[string]int{
+	"ARS": 2, "AUD": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CLP": 0,
+	"CNY": 2, "COP": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2,
+	"HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "ISK": 0, "JPY": 0, "KRW": 0,
+	"KWD": 3, "MXN": 2, "NOK": 2, "NZD": 2, "PEN": 2, "PLN": 2, "SEK": 2,
+	"SGD": 2, "TRY": 2, "USD": 2, "UYU": 2, "ZAR": 2,
+}
+
+// Money is an exact monetary amount held as an integer number of minor units
+// (e.g. cents) of an ISO 4217 currency
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New creates an amount from minor units
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// NormalizeCurrency upper-cases a currency code and checks that it is supported
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := minorUnits[code]; !ok {
+		return "", ErrUnknownCurrency
+	}
+	return code, nil
+}
+
+// IsValidCurrency checks if the currency code is supported
+func IsValidCurrency(code string) bool {
+	_, err := NormalizeCurrency(code)
+	return err == nil
+}
+
+// MinorUnits returns the number of decimal places of a supported currency
+func MinorUnits(currency string) int {
+	return minorUnits[currency]
+}
+
+// Parse converts a decimal string such as "29.99" into an amount of the
+// currency. Amounts with more decimal places than the currency allows are
+// rejected rather than rounded.
+func Parse(amount string, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+	if amount == "" || strings.ContainsAny(amount, "eE/") {
+		return Money{}, ErrInvalidAmount
+	}
+
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return Money{}, ErrInvalidAmount
+	}
+
+	value.Mul(value, new(big.Rat).SetInt(scale(currency)))
+	if !value.IsInt() {
+		return Money{}, ErrTooPrecise
+	}
+	if !value.Num().IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+
+	return Money{Amount: value.Num().Int64(), Currency: currency}, nil
+}
+
+// ParseRounded converts a decimal string such as "29.99" into an amount of the
+// currency like Parse, but rounds amounts with more decimal places than the
+// currency allows half away from zero instead of rejecting them. Amounts
+// that were stored as binary floats, e.g. "1019.9799999999999", read back
+// exactly.
+func ParseRounded(amount string, currency string) (Money, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok || strings.Contains(amount, "/") {
+		return Money{}, ErrInvalidAmount
+	}
+	return Round(value, currency)
+}
+
+// Round converts a value in major units into an amount of the currency,
+// rounded half away from zero to its minor unit
+func Round(value *big.Rat, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	scaled := new(big.Rat).Mul(value, new(big.Rat).SetInt(scale(currency)))
+	// (2 * |num| + den) / (2 * den) rounds the magnitude half up
+	amount := new(big.Int).Lsh(new(big.Int).Abs(scaled.Num()), 1)
+	amount.Add(amount, scaled.Denom())
+	amount.Quo(amount, new(big.Int).Lsh(scaled.Denom(), 1))
+	if scaled.Sign() < 0 {
+		amount.Neg(amount)
+	}
+	if !amount.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+
+	return Money{Amount: amount.Int64(), Currency: currency}, nil
+}
+
+// Decimal formats the amount as a plain decimal string, e.g. "29.99"
+func (m Money) Decimal() string {
+	digits := MinorUnits(m.Currency)
+
+	magnitude := new(big.Int).Abs(big.NewInt(m.Amount)).String()
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	if digits == 0 {
+		return sign + magnitude
+	}
+
+	if len(magnitude) <= digits {
+		magnitude = strings.Repeat("0", digits-len(magnitude)+1) + magnitude
+	}
+	split := len(magnitude) - digits
+	return sign + magnitude[:split] + "." + magnitude[split:]
+}
+
+// Number returns the amount as an exact JSON number
+func (m Money) Number() json.Number {
+	return json.Number(m.Decimal())
+}
+
+// Float64 returns the amount in major units as the nearest float64, for
+// APIs that carry amounts as floating-point numbers
+func (m Money) Float64() float64 {
+	value, _ := m.Rat().Float64()
+	return value
+}
+
+// Rat returns the amount in major units as a rational number
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), scale(m.Currency))
+}
+
+// String formats the amount with its currency, e.g. "29.99 USD"
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount
+func (m Money) Sign() int {
+	switch {
+	case m.Amount < 0:
+		return -1
+	case m.Amount > 0:
+		return 1
+	}
+	return 0
+}
+
+// IsPositive checks if the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns the sum of two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := new(big.Int).Add(big.NewInt(m.Amount), big.NewInt(other.Amount))
+	if !sum.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: sum.Int64(), Currency: m.Currency}, nil
+}
+
+// Multiply returns the amount multiplied by a whole quantity
+func (m Money) Multiply(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrOutOfRange
+	}
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// Scale returns the amount multiplied by a factor, such as a tax rate or a
+// share of a total, rounded half away from zero to the minor unit
+func (m Money) Scale(factor *big.Rat) (Money, error) {
+	return Round(new(big.Rat).Mul(m.Rat(), factor), m.Currency)
+}
+
+// Cmp compares the values of two amounts in major units, returning -1, 0 or +1.
+// It does not convert between currencies.
+func (m Money) Cmp(other Money) int {
+	return m.Rat().Cmp(other.Rat())
+}
+
+// MarshalJSON encodes the amount as {"amount":"29.99","currency":"USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.Decimal(),
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON decodes an amount written by MarshalJSON. The amount may be a
+// JSON string or number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(aux.Amount.String(), aux.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// scale returns 10^digits for the currency
+func scale(currency string) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(MinorUnits(currency))), nil)
+}
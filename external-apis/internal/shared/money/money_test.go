@@ -0,0 +1,305 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		expected Money
+		err      error
+	}{
+		{name: "Two decimal places", amount: "29.99", currency: "USD", expected: New(2999, "USD")},
+		{name: "Whole amount", amount: "999", currency: "EUR", expected: New(99900, "EUR")},
+		{name: "Single decimal place", amount: "0.1", currency: "USD", expected: New(10, "USD")},
+		{name: "Lower-case currency", amount: "5", currency: "gbp", expected: New(500, "GBP")},
+		{name: "Zero-decimal currency", amount: "1500", currency: "JPY", expected: New(1500, "JPY")},
+		{name: "Three-decimal currency", amount: "1.234", currency: "KWD", expected: New(1234, "KWD")},
+		{name: "Negative amount", amount: "-4.50", currency: "USD", expected: New(-450, "USD")},
+		{name: "Too many decimal places", amount: "29.999", currency: "USD", err: ErrTooPrecise},
+		{name: "Fraction of a yen", amount: "10.5", currency: "JPY", err: ErrTooPrecise},
+		{name: "Exponent notation", amount: "1e2", currency: "USD", err: ErrInvalidAmount},
+		{name: "Not a number", amount: "abc", currency: "USD", err: ErrInvalidAmount},
+		{name: "Empty amount", amount: "", currency: "USD", err: ErrInvalidAmount},
+		{name: "Out of range", amount: "100000000000000000000", currency: "USD", err: ErrOutOfRange},
+		{name: "Unknown currency", amount: "1", currency: "XYZ", err: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result, err := Parse(tt.amount, tt.currency)
+
+			// Assert
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		expected Money
+		err      error
+	}{
+		{name: "Exact amount", value: "29.99", currency: "USD", expected: New(2999, "USD")},
+		{name: "Round down", value: "27.5908", currency: "EUR", expected: New(2759, "EUR")},
+		{name: "Half rounds up", value: "0.125", currency: "USD", expected: New(13, "USD")},
+		{name: "Half rounds away from zero", value: "-0.125", currency: "USD", expected: New(-13, "USD")},
+		{name: "Zero-decimal currency", value: "4499.5", currency: "JPY", expected: New(4500, "JPY")},
+		{name: "Fraction", value: "1/3", currency: "KWD", expected: New(333, "KWD")},
+		{name: "Out of range", value: "100000000000000000000", currency: "USD", err: ErrOutOfRange},
+		{name: "Unknown currency", value: "1", currency: "XYZ", err: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			value, ok := new(big.Rat).SetString(tt.value)
+			require.True(t, ok)
+
+			// Act
+			result, err := Round(value, tt.currency)
+
+			// Assert
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseRounded(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		expected Money
+		err      error
+	}{
+		{name: "Exact amount", amount: "29.99", currency: "USD", expected: New(2999, "USD")},
+		{name: "Float artifact", amount: "1019.9799999999999", currency: "USD", expected: New(101998, "USD")},
+		{name: "Exponent notation", amount: "1e2", currency: "JPY", expected: New(100, "JPY")},
+		{name: "Fraction of a yen", amount: "10.5", currency: "JPY", expected: New(11, "JPY")},
+		{name: "Fraction notation", amount: "1/3", currency: "USD", err: ErrInvalidAmount},
+		{name: "Not a number", amount: "abc", currency: "USD", err: ErrInvalidAmount},
+		{name: "Unknown currency", amount: "1", currency: "XYZ", err: ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result, err := ParseRounded(tt.amount, tt.currency)
+
+			// Assert
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		expected string
+	}{
+		{name: "Two decimal places", money: New(2999, "USD"), expected: "29.99"},
+		{name: "Less than one", money: New(5, "USD"), expected: "0.05"},
+		{name: "Zero", money: New(0, "EUR"), expected: "0.00"},
+		{name: "Negative", money: New(-450, "USD"), expected: "-4.50"},
+		{name: "Zero-decimal currency", money: New(1500, "JPY"), expected: "1500"},
+		{name: "Three-decimal currency", money: New(1234, "KWD"), expected: "1.234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := tt.money.Decimal()
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	t.Run("Add same currency", func(t *testing.T) {
+		// Act
+		sum, err := New(2999, "USD").Add(New(1, "USD"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, New(3000, "USD"), sum)
+	})
+
+	t.Run("Add different currencies", func(t *testing.T) {
+		// Act
+		_, err := New(2999, "USD").Add(New(1, "EUR"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	})
+
+	t.Run("Multiply by quantity", func(t *testing.T) {
+		// Act
+		total, err := New(2999, "USD").Multiply(3)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "89.97", total.Decimal())
+	})
+
+	t.Run("Add up to the largest amount", func(t *testing.T) {
+		// Act
+		sum, err := New(math.MaxInt64-1, "USD").Add(New(1, "USD"))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, New(math.MaxInt64, "USD"), sum)
+	})
+
+	t.Run("Add beyond the range", func(t *testing.T) {
+		tests := []struct {
+			name string
+			a, b int64
+		}{
+			{"Above the largest amount", math.MaxInt64, 1},
+			{"Below the smallest amount", math.MinInt64, -1},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Act
+				_, err := New(tt.a, "USD").Add(New(tt.b, "USD"))
+
+				// Assert
+				assert.ErrorIs(t, err, ErrOutOfRange)
+			})
+		}
+	})
+
+	t.Run("Multiply up to the largest amount", func(t *testing.T) {
+		// Act
+		total, err := New(math.MaxInt64/7, "USD").Multiply(7)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, New(math.MaxInt64/7*7, "USD"), total)
+	})
+
+	t.Run("Multiply beyond the range", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			amount   int64
+			quantity int64
+		}{
+			{"Large quantity of a large price", math.MaxInt64/7 + 1, 7},
+			{"Largest amount doubled", math.MaxInt64, 2},
+			{"Smallest amount negated", math.MinInt64, -1},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Act
+				_, err := New(tt.amount, "USD").Multiply(tt.quantity)
+
+				// Assert
+				assert.ErrorIs(t, err, ErrOutOfRange)
+			})
+		}
+	})
+
+	t.Run("Scale by a rate", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			amount Money
+			factor *big.Rat
+			want   Money
+		}{
+			{"Rounded half away from zero", New(15998, "USD"), big.NewRat(725, 10000), New(1160, "USD")},
+			{"Exact half", New(50, "USD"), big.NewRat(1, 100), New(1, "USD")},
+			{"Negative amount", New(-50, "USD"), big.NewRat(1, 100), New(-1, "USD")},
+			{"Currency without minor units", New(1999, "JPY"), big.NewRat(725, 10000), New(145, "JPY")},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Act
+				scaled, err := tt.amount.Scale(tt.factor)
+
+				// Assert
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, scaled)
+			})
+		}
+	})
+
+	t.Run("Compare across minor units", func(t *testing.T) {
+		// Act & Assert
+		assert.Equal(t, 0, New(100, "USD").Cmp(New(1, "JPY")))
+		assert.Equal(t, 1, New(101, "USD").Cmp(New(1, "JPY")))
+		assert.Equal(t, -1, New(1000, "KWD").Cmp(New(101, "USD")))
+	})
+}
+
+func TestMoney_JSON(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		// Arrange
+		original := New(12999, "EUR")
+
+		// Act
+		data, err := json.Marshal(original)
+		require.NoError(t, err)
+
+		var decoded Money
+		err = json.Unmarshal(data, &decoded)
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"amount":"129.99","currency":"EUR"}`, string(data))
+		assert.Equal(t, original, decoded)
+	})
+
+	t.Run("Numeric amount", func(t *testing.T) {
+		// Act
+		var decoded Money
+		err := json.Unmarshal([]byte(`{"amount":0.3,"currency":"USD"}`), &decoded)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, New(30, "USD"), decoded)
+	})
+
+	t.Run("Invalid currency", func(t *testing.T) {
+		// Act
+		var decoded Money
+		err := json.Unmarshal([]byte(`{"amount":"1.00","currency":"???"}`), &decoded)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnknownCurrency)
+	})
+}
@@ -0,0 +1,97 @@
+package pagination
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultLimit is the page size used when the caller does not provide one
+	DefaultLimit = 50
+	// MaxLimit is the largest page size a caller may request
+	MaxLimit = 500
+)
+
+var (
+	// ErrInvalidLimit is returned when the limit query parameter is invalid
+	ErrInvalidLimit = errors.New("limit must be an integer between 1 and 500")
+	// ErrInvalidOffset is returned when the offset query parameter is invalid
+	ErrInvalidOffset = errors.New("offset must be a non-negative integer")
+)
+
+// Params represents a limit/offset page request
+type Params struct {
+	Limit  int
+	Offset int
+}
+
+// Meta describes the page returned to the client
+type Meta struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// DefaultParams returns the first page with the default limit
+func DefaultParams() Params {
+	return Params{Limit: DefaultLimit}
+}
+
+// FromQuery parses the limit and offset query parameters
+func FromQuery(c *gin.Context) (Params, error) {
+	params := DefaultParams()
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return Params{}, ErrInvalidLimit
+		}
+		params.Limit = limit
+	}
+
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return Params{}, ErrInvalidOffset
+		}
+		params.Offset = offset
+	}
+
+	return params, nil
+}
+
+// Bounds returns the slice bounds of the page within a result set of size total
+func (p Params) Bounds(total int) (start, end int) {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	start = p.Offset
+	if start > total {
+		start = total
+	}
+
+	end = start + limit
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
+
+// Meta builds the page metadata for a result set of size total
+func (p Params) Meta(total int) Meta {
+	limit := p.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	return Meta{
+		Total:  total,
+		Limit:  limit,
+		Offset: p.Offset,
+	}
+}
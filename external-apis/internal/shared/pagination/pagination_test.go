@@ -0,0 +1,68 @@
+package pagination
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+rawQuery, nil)
+	return c
+}
+
+func TestFromQuery(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		params, err := FromQuery(newContext(""))
+
+		require.NoError(t, err)
+		assert.Equal(t, Params{Limit: DefaultLimit}, params)
+	})
+
+	t.Run("Explicit limit and offset", func(t *testing.T) {
+		params, err := FromQuery(newContext("limit=10&offset=20"))
+
+		require.NoError(t, err)
+		assert.Equal(t, Params{Limit: 10, Offset: 20}, params)
+	})
+
+	t.Run("Limit above maximum", func(t *testing.T) {
+		_, err := FromQuery(newContext("limit=1000"))
+
+		assert.ErrorIs(t, err, ErrInvalidLimit)
+	})
+
+	t.Run("Negative offset", func(t *testing.T) {
+		_, err := FromQuery(newContext("offset=-1"))
+
+		assert.ErrorIs(t, err, ErrInvalidOffset)
+	})
+}
+
+func TestParams_Bounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    Params
+		total     int
+		wantStart int
+		wantEnd   int
+	}{
+		{"First page", Params{Limit: 2}, 5, 0, 2},
+		{"Last partial page", Params{Limit: 2, Offset: 4}, 5, 4, 5},
+		{"Offset past end", Params{Limit: 2, Offset: 10}, 5, 5, 5},
+		{"Zero limit uses default", Params{}, 5, 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := tt.params.Bounds(tt.total)
+
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"external-apis/internal/shared/apperror"
+)
+
+// Operation is an operation of a JSON Patch. Path and From are RFC 6901 JSON
+// Pointers, e.g. /tags/0; Value is nil when the operation carries none.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// JSONPatch is an RFC 6902 JSON Patch: operations applied in order, all or
+// none of them
+type JSONPatch []Operation
+
+// validate checks the operations are well-formed before any is applied
+func (p JSONPatch) validate() error {
+	for i, operation := range p {
+		switch operation.Op {
+		case "add", "replace", "test":
+			if operation.Value == nil {
+				return operationError(i, "value is required")
+			}
+		case "move", "copy":
+			if _, err := parsePointer(operation.From); err != nil {
+				return operationError(i, err.Error())
+			}
+		case "remove":
+		default:
+			return operationError(i, fmt.Sprintf("unknown op %q, expected add, remove, replace, move, copy or test", operation.Op))
+		}
+		if _, err := parsePointer(operation.Path); err != nil {
+			return operationError(i, err.Error())
+		}
+		if operation.Value != nil {
+			if _, err := decode(operation.Value); err != nil {
+				return operationError(i, "value is not valid JSON")
+			}
+		}
+	}
+	return nil
+}
+
+// Apply returns the document with the operations applied
+func (p JSONPatch) Apply(doc []byte) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	root, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, operation := range p {
+		if root, err = operation.apply(root); err != nil {
+			if errors.Is(err, ErrTestFailed) {
+				return nil, err
+			}
+			return nil, apperror.Unprocessable(fmt.Sprintf("patch operation %d: %s", i, err))
+		}
+	}
+	return json.Marshal(root)
+}
+
+// apply applies the operation to the document root and returns the new root
+func (o Operation) apply(root interface{}) (interface{}, error) {
+	path, _ := parsePointer(o.Path)
+	var value interface{}
+	if o.Value != nil {
+		value, _ = decode(o.Value)
+	}
+
+	switch o.Op {
+	case "add":
+		return update(root, path, value, add)
+	case "remove":
+		if len(path) == 0 {
+			return nil, fmt.Errorf("the document cannot be removed")
+		}
+		return update(root, path, nil, remove)
+	case "replace":
+		return update(root, path, value, replace)
+	case "move":
+		from, _ := parsePointer(o.From)
+		if o.Path != o.From && strings.HasPrefix(o.Path+"/", o.From+"/") {
+			return nil, fmt.Errorf("%s cannot be moved into itself", o.From)
+		}
+		moved, err := get(root, from)
+		if err != nil {
+			return nil, err
+		}
+		if len(from) > 0 {
+			if root, err = update(root, from, nil, remove); err != nil {
+				return nil, err
+			}
+		}
+		return update(root, path, moved, add)
+	case "copy":
+		from, _ := parsePointer(o.From)
+		copied, err := get(root, from)
+		if err != nil {
+			return nil, err
+		}
+		return update(root, path, deepCopy(copied), add)
+	default: // test
+		current, err := get(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, ErrTestFailed
+		}
+		return root, nil
+	}
+}
+
+// operationError reports a malformed operation at index i
+func operationError(i int, message string) error {
+	return apperror.Validation(fmt.Sprintf("patch operation %d: %s", i, message))
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into its unescaped reference
+// tokens; the empty pointer refers to the whole document
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("path %q must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// containerOp changes the member or element token of container and returns
+// the container changed
+type containerOp func(container interface{}, token string, value interface{}) (interface{}, error)
+
+// update applies op to the container the last token of path is in and
+// returns the new root. Arrays that grow or shrink are put back into their
+// parents.
+func update(node interface{}, path []string, value interface{}, op containerOp) (interface{}, error) {
+	if len(path) == 0 {
+		// Adding or replacing the whole document
+		return value, nil
+	}
+	if len(path) == 1 {
+		return op(node, path[0], value)
+	}
+
+	child, err := get(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = update(child, path[1:], value, op); err != nil {
+		return nil, err
+	}
+	return replace(node, path[0], child)
+}
+
+// get returns the value at path in node
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q does not exist", token)
+			}
+			node = value
+		case []interface{}:
+			i, err := index(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[i]
+		default:
+			return nil, fmt.Errorf("%q is not in an object or array", token)
+		}
+	}
+	return node, nil
+}
+
+// add adds value as member token of an object, replacing any member of the
+// name, or inserts it before element token of an array, "-" appending it
+func add(container interface{}, token string, value interface{}) (interface{}, error) {
+	switch container := container.(type) {
+	case map[string]interface{}:
+		container[token] = value
+		return container, nil
+	case []interface{}:
+		if token == "-" {
+			return append(container, value), nil
+		}
+		i, err := index(token, len(container))
+		if err != nil {
+			return nil, err
+		}
+		container = append(container, nil)
+		copy(container[i+1:], container[i:])
+		container[i] = value
+		return container, nil
+	default:
+		return nil, fmt.Errorf("%q is not in an object or array", token)
+	}
+}
+
+// remove removes member or element token, which must exist
+func remove(container interface{}, token string, _ interface{}) (interface{}, error) {
+	switch container := container.(type) {
+	case map[string]interface{}:
+		if _, ok := container[token]; !ok {
+			return nil, fmt.Errorf("member %q does not exist", token)
+		}
+		delete(container, token)
+		return container, nil
+	case []interface{}:
+		i, err := index(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		return append(container[:i], container[i+1:]...), nil
+	default:
+		return nil, fmt.Errorf("%q is not in an object or array", token)
+	}
+}
+
+// replace replaces the value of member or element token, which must exist
+func replace(container interface{}, token string, value interface{}) (interface{}, error) {
+	switch container := container.(type) {
+	case map[string]interface{}:
+		if _, ok := container[token]; !ok {
+			return nil, fmt.Errorf("member %q does not exist", token)
+		}
+		container[token] = value
+		return container, nil
+	case []interface{}:
+		i, err := index(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		container[i] = value
+		return container, nil
+	default:
+		return nil, fmt.Errorf("%q is not in an object or array", token)
+	}
+}
+
+// index parses an array index token, which must be at most max and have no
+// leading zeros
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || strconv.Itoa(i) != token {
+		return 0, fmt.Errorf("%q is not an array index", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d is out of bounds", i)
+	}
+	return i, nil
+}
+
+// deepCopy copies a decoded JSON value so it can be changed independently
+func deepCopy(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for name, member := range value {
+			object[name] = deepCopy(member)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(value))
+		for i, element := range value {
+			array[i] = deepCopy(element)
+		}
+		return array
+	default:
+		return value
+	}
+}
+
+// equal reports whether two decoded JSON values are equal, comparing numbers
+// by value so 1 equals 1.0
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		object, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(object) {
+			return false
+		}
+		for name, member := range a {
+			other, ok := object[name]
+			if !ok || !equal(member, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		array, ok := b.([]interface{})
+		if !ok || len(a) != len(array) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], array[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		number, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okX := new(big.Rat).SetString(a.String())
+		y, okY := new(big.Rat).SetString(number.String())
+		return okX && okY && x.Cmp(y) == 0
+	default:
+		return a == b
+	}
+}
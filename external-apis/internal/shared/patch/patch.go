@@ -0,0 +1,152 @@
+// Package patch applies PATCH request bodies to JSON documents: RFC 7386
+// merge patches, which set the members they carry and remove those set to
+// null, and RFC 6902 JSON Patches, which list add, remove, replace, move,
+// copy and test operations.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"external-apis/internal/shared/apperror"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Media types of the patch documents
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// Patch errors
+var (
+	// ErrUnsupportedMediaType is returned for a body of a media type other
+	// than the patch ones and application/json
+	ErrUnsupportedMediaType = errors.New("patch media type must be " + MergePatchType + " or " + JSONPatchType)
+	ErrInvalidJSON          = apperror.Validation("patch is not valid JSON")
+	ErrTestFailed           = apperror.Conflict("patch test operation failed")
+	// ErrInvalidDocument wraps the errors of patched documents breaking the
+	// rules of their fields, which response.InvalidRequest reports field by
+	// field
+	ErrInvalidDocument = errors.New("patched document is invalid")
+)
+
+// Patch changes a JSON document
+type Patch interface {
+	// Apply returns the document patched
+	Apply(doc []byte) ([]byte, error)
+}
+
+// Parse returns the patch in body of the media type contentType. Plain
+// application/json bodies are taken for merge patches.
+func Parse(contentType string, body []byte) (Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	switch mediaType {
+	case MergePatchType, "application/json":
+		if _, err := decode(body); err != nil {
+			return nil, err
+		}
+		return MergePatch(body), nil
+	case JSONPatchType:
+		var operations JSONPatch
+		if err := json.Unmarshal(body, &operations); err != nil {
+			return nil, apperror.Validation("JSON Patch must be an array of operations")
+		}
+		if err := operations.validate(); err != nil {
+			return nil, err
+		}
+		return operations, nil
+	default:
+		return nil, ErrUnsupportedMediaType
+	}
+}
+
+// ApplyTo patches the JSON encoding of current, decodes the result into
+// patched and validates it against its binding rules. Members of the result
+// patched has no field for are rejected, so clients cannot patch read-only
+// fields such as id; a field the patch removed is left nil, so a binding
+// rule of required keeps it from being removed.
+func ApplyTo(p Patch, current, patched interface{}) error {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	if doc, err = p.Apply(doc); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patched); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return apperror.Validation(fmt.Sprintf("field %s cannot be patched", field))
+		}
+		return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	if err := binding.Validator.ValidateStruct(patched); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	return nil
+}
+
+// MergePatch is an RFC 7386 merge patch. An object sets the members it
+// carries, merging objects recursively, and removes those set to null; any
+// other value replaces the document.
+type MergePatch []byte
+
+// Apply returns the document merged with the patch
+func (p MergePatch) Apply(doc []byte) ([]byte, error) {
+	target, err := decode(doc)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := decode(p)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merge(target, patch))
+}
+
+// merge merges patch into target as RFC 7386 describes
+func merge(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{}, len(members))
+	}
+
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = merge(object[name], value)
+	}
+	return object
+}
+
+// decode decodes a JSON document, keeping numbers exact
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, ErrInvalidJSON
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, ErrInvalidJSON
+	}
+	return value, nil
+}
@@ -0,0 +1,231 @@
+package patch
+
+import (
+	"testing"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        Patch
+		wantErr     error
+	}{
+		{"Merge patch", MergePatchType, `{"name":"Ann"}`, MergePatch(`{"name":"Ann"}`), nil},
+		{"Plain JSON as a merge patch", "application/json; charset=utf-8", `{"name":"Ann"}`, MergePatch(`{"name":"Ann"}`), nil},
+		{"JSON Patch", JSONPatchType, `[{"op":"remove","path":"/name"}]`, JSONPatch{{Op: "remove", Path: "/name"}}, nil},
+		{"Malformed merge patch", MergePatchType, `{"name":`, nil, ErrInvalidJSON},
+		{"JSON Patch that is not an array", JSONPatchType, `{"op":"remove"}`, nil, apperror.ErrValidation},
+		{"Unknown operation", JSONPatchType, `[{"op":"delete","path":"/name"}]`, nil, apperror.ErrValidation},
+		{"Operation without a value", JSONPatchType, `[{"op":"add","path":"/name"}]`, nil, apperror.ErrValidation},
+		{"Path not a JSON Pointer", JSONPatchType, `[{"op":"remove","path":"name"}]`, nil, apperror.ErrValidation},
+		{"Other media type", "text/plain", `name=Ann`, nil, ErrUnsupportedMediaType},
+		{"No media type", "", `{"name":"Ann"}`, nil, ErrUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := Parse(tt.contentType, []byte(tt.body))
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7386, appendix A
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			// Act
+			got, err := MergePatch(tt.patch).Apply([]byte(tt.target))
+
+			// Assert
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("Keep numbers exact", func(t *testing.T) {
+		// Act
+		got, err := MergePatch(`{"name":"Mug"}`).Apply([]byte(`{"price":12345678901234567.89}`))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, `{"name":"Mug","price":12345678901234567.89}`, string(got))
+	})
+}
+
+func TestJSONPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"Add a member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"foo":"bar","baz":"qux"}`},
+		{"Insert an element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"Append an element", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"baz"}]`, `{"foo":["bar","baz"]}`},
+		{"Set a member to null", `{"foo":"bar"}`, `[{"op":"replace","path":"/foo","value":null}]`, `{"foo":null}`},
+		{"Remove a member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"Remove an element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"Replace a value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"Move a value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"Move an element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"Copy a value", `{"foo":{"bar":1}}`, `[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`, `{"foo":{"bar":1},"baz":{"bar":2}}`},
+		{"Escaped member names", `{"a/b":1,"m~n":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":3}]`, `{"m~n":3}`},
+		{"Pass a test", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"Replace the document", `{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":"qux"}}]`, `{"baz":"qux"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p, err := Parse(JSONPatchType, []byte(tt.patch))
+			require.NoError(t, err)
+
+			// Act
+			got, err := p.Apply([]byte(tt.target))
+
+			// Assert
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	failures := []struct {
+		name    string
+		target  string
+		patch   string
+		wantErr error
+	}{
+		{"Fail a test", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrTestFailed},
+		{"Remove a missing member", `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, apperror.ErrUnprocessable},
+		{"Replace a missing member", `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, apperror.ErrUnprocessable},
+		{"Add into a missing parent", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, apperror.ErrUnprocessable},
+		{"Index out of bounds", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`, apperror.ErrUnprocessable},
+		{"Index with leading zeros", `{"foo":["bar","baz"]}`, `[{"op":"remove","path":"/foo/01"}]`, apperror.ErrUnprocessable},
+		{"Move a value into itself", `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, apperror.ErrUnprocessable},
+	}
+
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p, err := Parse(JSONPatchType, []byte(tt.patch))
+			require.NoError(t, err)
+
+			// Act
+			_, err = p.Apply([]byte(tt.target))
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("Apply all operations or none", func(t *testing.T) {
+		// Arrange
+		target := []byte(`{"foo":"bar"}`)
+		p, err := Parse(JSONPatchType, []byte(`[{"op":"add","path":"/baz","value":1},{"op":"remove","path":"/missing"}]`))
+		require.NoError(t, err)
+
+		// Act
+		_, err = p.Apply(target)
+
+		// Assert
+		assert.ErrorIs(t, err, apperror.ErrUnprocessable)
+		assert.Equal(t, `{"foo":"bar"}`, string(target))
+	})
+}
+
+func TestApplyTo(t *testing.T) {
+	validation.Register()
+	type document struct {
+		Name  *string `json:"name" binding:"required"`
+		Phone *string `json:"phone" binding:"omitempty,max=16"`
+	}
+	name, phone := "Ann", "+12025550123"
+	current := document{Name: &name, Phone: &phone}
+
+	t.Run("Tell removed fields from omitted ones", func(t *testing.T) {
+		// Arrange
+		var patched document
+
+		// Act
+		err := ApplyTo(MergePatch(`{"phone":null}`), current, &patched)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, &name, patched.Name)
+		assert.Nil(t, patched.Phone)
+	})
+
+	t.Run("Reject removing required fields", func(t *testing.T) {
+		// Arrange
+		var patched document
+
+		// Act
+		err := ApplyTo(JSONPatch{{Op: "remove", Path: "/name"}}, current, &patched)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidDocument)
+		assert.Equal(t, []validation.FieldError{{Field: "name", Rule: "required", Message: "is required"}}, validation.Translate(err))
+	})
+
+	t.Run("Reject fields the document does not have", func(t *testing.T) {
+		// Arrange
+		var patched document
+
+		// Act
+		err := ApplyTo(MergePatch(`{"id":"customer-1"}`), current, &patched)
+
+		// Assert
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		assert.EqualError(t, err, `field "id" cannot be patched`)
+	})
+
+	t.Run("Reject values of the wrong type", func(t *testing.T) {
+		// Arrange
+		var patched document
+
+		// Act
+		err := ApplyTo(JSONPatch{{Op: "replace", Path: "/name", Value: []byte(`42`)}}, current, &patched)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidDocument)
+		assert.Equal(t, []validation.FieldError{{Field: "name", Rule: "type", Message: "must be a string"}}, validation.Translate(err))
+	})
+}
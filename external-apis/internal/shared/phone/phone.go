@@ -0,0 +1,123 @@
+// Package phone parses phone numbers written in common formats, e.g. with
+// spaces, dashes or parentheses, and normalizes them to E.164 using the
+// numbering plans of libphonenumber.
+package phone
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+var (
+	// ErrInvalid is returned for strings that are not a valid phone number
+	// of their region
+	ErrInvalid = errors.New("invalid phone number")
+	// ErrInvalidCountryCode is returned for numbers whose country calling
+	// code does not exist, or that lack one without a default region
+	ErrInvalidCountryCode = errors.New("invalid phone country calling code")
+	// ErrInvalidRegion is returned for regions without a numbering plan
+	ErrInvalidRegion = errors.New("invalid phone region")
+)
+
+// nonGeographic is the region libphonenumber reports for numbers that belong
+// to no region, such as satellite phones
+const nonGeographic = "001"
+
+var (
+	// defaultRegion is the region of numbers given without a country
+	// calling code
+	defaultRegion string
+	regionMutex   sync.RWMutex
+)
+
+// Number is a parsed phone number
+type Number struct {
+	// E164 is the normalized form, e.g. +12025550123
+	E164 string
+	// Region is the ISO 3166-1 alpha-2 code of the region the number belongs
+	// to, e.g. US; it is empty for non-geographic numbers
+	Region string
+}
+
+// ValidateRegion checks that region is an ISO 3166-1 alpha-2 code with a
+// numbering plan. The empty region is valid and stands for none.
+func ValidateRegion(region string) error {
+	if region == "" || phonenumbers.GetSupportedRegions()[strings.ToUpper(region)] {
+		return nil
+	}
+	return ErrInvalidRegion
+}
+
+// SetDefaultRegion sets the region numbers without a country calling code
+// are parsed in. Without a default region such numbers are rejected.
+func SetDefaultRegion(region string) error {
+	if err := ValidateRegion(region); err != nil {
+		return err
+	}
+
+	regionMutex.Lock()
+	defer regionMutex.Unlock()
+
+	defaultRegion = strings.ToUpper(region)
+	return nil
+}
+
+// DefaultRegion returns the region numbers without a country calling code
+// are parsed in
+func DefaultRegion() string {
+	regionMutex.RLock()
+	defer regionMutex.RUnlock()
+
+	return defaultRegion
+}
+
+// Parse parses a phone number, which belongs to the default region unless it
+// starts with a country calling code
+func Parse(raw string) (Number, error) {
+	return ParseIn(raw, DefaultRegion())
+}
+
+// ParseIn parses a phone number, which belongs to region unless it starts
+// with a country calling code. The number must be valid for the numbering
+// plan of its region, not merely have a plausible length.
+func ParseIn(raw, region string) (Number, error) {
+	if strings.TrimSpace(raw) == "" {
+		return Number{}, ErrInvalid
+	}
+
+	number, err := phonenumbers.Parse(raw, strings.ToUpper(region))
+	if errors.Is(err, phonenumbers.ErrInvalidCountryCode) {
+		return Number{}, ErrInvalidCountryCode
+	}
+	if err != nil || !phonenumbers.IsValidNumber(number) {
+		return Number{}, ErrInvalid
+	}
+
+	parsed := Number{E164: phonenumbers.Format(number, phonenumbers.E164)}
+	if code := phonenumbers.GetRegionCodeForNumber(number); code != nonGeographic {
+		parsed.Region = code
+	}
+	return parsed, nil
+}
+
+// Normalize returns the E.164 form of a phone number in the default region
+func Normalize(raw string) (string, error) {
+	number, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return number.E164, nil
+}
+
+// Region returns the region of a phone number in the default region, or ""
+// if it is not a valid number
+func Region(raw string) string {
+	number, err := Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return number.Region
+}
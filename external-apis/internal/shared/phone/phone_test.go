@@ -0,0 +1,73 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIn(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		region  string
+		want    Number
+		wantErr error
+	}{
+		{"E.164", "+12025550123", "", Number{E164: "+12025550123", Region: "US"}, nil},
+		{"Spaces and dashes", "+1 202-555-0123", "", Number{E164: "+12025550123", Region: "US"}, nil},
+		{"National format in the region", "(202) 555-0123", "us", Number{E164: "+12025550123", Region: "US"}, nil},
+		{"International number in another region", "+44 20 7946 0958", "US", Number{E164: "+442079460958", Region: "GB"}, nil},
+		{"International prefix of the region", "011 44 20 7946 0958", "US", Number{E164: "+442079460958", Region: "GB"}, nil},
+		{"Trunk prefix", "020 7946 0958", "GB", Number{E164: "+442079460958", Region: "GB"}, nil},
+		{"Non-geographic number", "+881 6 1234 5678", "", Number{E164: "+881612345678"}, nil},
+		{"National format without a region", "202 555 0123", "", Number{}, ErrInvalidCountryCode},
+		{"Unknown country calling code", "+999 1234 5678", "", Number{}, ErrInvalidCountryCode},
+		{"Too short for the region", "+1 555 0123", "", Number{}, ErrInvalid},
+		{"Letters", "call me", "US", Number{}, ErrInvalid},
+		{"Empty", " ", "US", Number{}, ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			number, err := ParseIn(tt.raw, tt.region)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, number)
+		})
+	}
+}
+
+func TestSetDefaultRegion(t *testing.T) {
+	t.Cleanup(func() { _ = SetDefaultRegion("") })
+
+	t.Run("Parse national numbers in the default region", func(t *testing.T) {
+		// Arrange
+		require.NoError(t, SetDefaultRegion("gb"))
+
+		// Act
+		normalized, err := Normalize("020 7946 0958")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "GB", DefaultRegion())
+		assert.Equal(t, "+442079460958", normalized)
+		assert.Equal(t, "GB", Region("020 7946 0958"))
+	})
+
+	t.Run("Reject unknown regions", func(t *testing.T) {
+		// Act
+		err := SetDefaultRegion("XX")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidRegion)
+		assert.Equal(t, "GB", DefaultRegion())
+	})
+}
@@ -0,0 +1,122 @@
+package quota
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("shared/quota")
+
+// Headers reporting the budget a request was counted against
+const (
+	LimitHeader     = "X-RateLimit-Limit"
+	RemainingHeader = "X-RateLimit-Remaining"
+	// ResetHeader holds when the budget is renewed, in Unix seconds
+	ResetHeader = "X-RateLimit-Reset"
+)
+
+// Middleware counts requests to route against the daily read (safe methods)
+// or write budget of their client. Clients are identified by the API key
+// authenticated before it, or by IP. The headers it sets replace the
+// X-RateLimit-Remaining of the rate limiter, the daily budget being the one
+// clients plan against. Store errors fail open like the rate limiter.
+func Middleware(manager *Manager, route string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		class := ClassWrite
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			class = ClassRead
+		}
+
+		client := auth.Actor(c.Request.Context())
+		if client == auth.AnonymousActor {
+			client = "ip:" + c.ClientIP()
+		}
+
+		result, err := manager.Consume(c.Request.Context(), client, route, class)
+		if err != nil {
+			log.Ctx(c.Request.Context()).WithError(err).Warn("Quota store unavailable, allowing request")
+			c.Next()
+			return
+		}
+		if result.Limit == 0 {
+			c.Next()
+			return
+		}
+
+		c.Header(LimitHeader, strconv.FormatInt(result.Limit, 10))
+		c.Header(RemainingHeader, strconv.FormatInt(result.Remaining, 10))
+		c.Header(ResetHeader, strconv.FormatInt(result.Reset.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(time.Until(result.Reset).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(max(1, retryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, response.ErrorResponse{
+				Error:   "quota_exceeded",
+				Message: "Daily " + class + " quota of " + route + " exceeded, retry after the reset",
+				Code:    http.StatusTooManyRequests,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// AdminHandler serves the admin endpoints inspecting and resetting quotas
+type AdminHandler struct {
+	manager *Manager
+}
+
+// NewAdminHandler creates a new quota admin handler
+func NewAdminHandler(manager *Manager) *AdminHandler {
+	return &AdminHandler{manager: manager}
+}
+
+// RegisterRoutes registers the quota admin routes. Clients are named as the
+// middleware counts them, e.g. "api-key:<id>" or "ip:<address>".
+func (h *AdminHandler) RegisterRoutes(router *gin.RouterGroup) {
+	quotas := router.Group("/quotas")
+	{
+		quotas.GET("/:client", h.GetUsage)
+		quotas.DELETE("/:client", h.ResetUsage)
+	}
+}
+
+// GetUsage returns the budgets a client drew on today
+func (h *AdminHandler) GetUsage(c *gin.Context) {
+	usage, err := h.manager.Usage(c.Request.Context(), c.Param("client"))
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to read quota usage")
+		response.InternalServerError(c, "Failed to read quota usage")
+		return
+	}
+
+	response.OK(c, usage)
+}
+
+// ResetUsage renews every budget of a client
+func (h *AdminHandler) ResetUsage(c *gin.Context) {
+	client := c.Param("client")
+
+	reset, err := h.manager.Reset(c.Request.Context(), client)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to reset quotas")
+		response.InternalServerError(c, "Failed to reset quotas")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"client":   client,
+		"counters": reset,
+	}).Info("Quotas reset")
+
+	c.Status(http.StatusNoContent)
+}
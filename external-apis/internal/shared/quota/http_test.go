@@ -0,0 +1,162 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore is a Store whose backend is unavailable
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, int64, time.Time) (int64, bool, error) {
+	return 0, false, errors.New("connection refused")
+}
+
+func (failingStore) Counters(context.Context, string) (map[string]int64, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingStore) Delete(context.Context, string) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+// newQuotaRouter returns a router counting the requests to /products against
+// manager. Requests carrying an X-Actor header are made by that actor.
+func newQuotaRouter(manager *Manager) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if actor := c.GetHeader("X-Actor"); actor != "" {
+			c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), actor))
+		}
+	})
+
+	products := router.Group("/products", Middleware(manager, "products"))
+	products.GET("", func(c *gin.Context) { c.Status(http.StatusOK) })
+	products.POST("", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	NewAdminHandler(manager).RegisterRoutes(router.Group("/admin"))
+	return router
+}
+
+// send sends a request through router on behalf of actor
+func send(router *gin.Engine, method, path, actor string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	if actor != "" {
+		req.Header.Set("X-Actor", actor)
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("Report the remaining budget", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Read: 2, Write: 1})
+		router := newQuotaRouter(manager)
+
+		// Act
+		recorder := send(router, http.MethodGet, "/products", "api-key:key-1")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "2", recorder.Header().Get(LimitHeader))
+		assert.Equal(t, "1", recorder.Header().Get(RemainingHeader))
+		assert.Equal(t, "1705363200", recorder.Header().Get(ResetHeader))
+	})
+
+	t.Run("Refuse requests over the budget", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Read: 2, Write: 1})
+		router := newQuotaRouter(manager)
+		send(router, http.MethodPost, "/products", "api-key:key-1")
+
+		// Act
+		recorder := send(router, http.MethodPost, "/products", "api-key:key-1")
+
+		// Assert
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, "0", recorder.Header().Get(RemainingHeader))
+		assert.NotEmpty(t, recorder.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error":"quota_exceeded","message":"Daily write quota of products exceeded, retry after the reset","code":429}`, recorder.Body.String())
+		assert.Equal(t, http.StatusOK, send(router, http.MethodGet, "/products", "api-key:key-1").Code, "reads have their own budget")
+	})
+
+	t.Run("Count anonymous clients by IP", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Read: 2})
+		router := newQuotaRouter(manager)
+
+		// Act
+		send(router, http.MethodGet, "/products", "")
+
+		// Assert
+		usage, err := manager.Usage(context.Background(), "ip:192.0.2.1")
+		require.NoError(t, err)
+		assert.Len(t, usage.Quotas, 1)
+	})
+
+	t.Run("Allow requests when the store is unavailable", func(t *testing.T) {
+		// Arrange
+		router := newQuotaRouter(NewManager(failingStore{}, Config{Read: 1}))
+
+		// Act
+		recorder := send(router, http.MethodGet, "/products", "api-key:key-1")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, recorder.Header().Get(RemainingHeader))
+	})
+}
+
+func TestAdminHandler(t *testing.T) {
+	// Arrange
+	manager, _ := newTestManager(Config{Read: 10, Write: 5})
+	router := newQuotaRouter(manager)
+	send(router, http.MethodGet, "/products", "api-key:key-1")
+
+	t.Run("Inspect the quotas of a client", func(t *testing.T) {
+		// Act
+		recorder := send(router, http.MethodGet, "/admin/quotas/api-key:key-1", "")
+
+		// Assert
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var body struct {
+			Data ClientUsage `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Equal(t, []Usage{{Route: "products", Class: ClassRead, Used: 1, Limit: 10, Remaining: 9}}, body.Data.Quotas)
+	})
+
+	t.Run("Reset the quotas of a client", func(t *testing.T) {
+		// Act
+		recorder := send(router, http.MethodDelete, "/admin/quotas/api-key:key-1", "")
+
+		// Assert
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		usage, err := manager.Usage(context.Background(), "api-key:key-1")
+		require.NoError(t, err)
+		assert.Empty(t, usage.Quotas)
+	})
+
+	t.Run("Report store failures", func(t *testing.T) {
+		// Arrange
+		router := newQuotaRouter(NewManager(failingStore{}, Config{Read: 1}))
+
+		// Act
+		recorder := send(router, http.MethodGet, "/admin/quotas/api-key:key-1", "")
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+}
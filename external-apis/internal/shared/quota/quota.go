@@ -0,0 +1,268 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Classes of requests, each with its own daily budget
+const (
+	ClassRead  = "read"
+	ClassWrite = "write"
+)
+
+// Config configures the daily request budgets of clients. A limit of zero
+// leaves the requests of its class unlimited and uncounted.
+type Config struct {
+	// Read is the daily budget of reads of each client on each route
+	Read int64
+	// Write is the daily budget of writes of each client on each route
+	Write int64
+	// Routes overrides the budgets of routes, keyed by "<route>:<class>"
+	Routes map[string]int64
+}
+
+// Limit returns the daily budget of a class of requests to route
+func (c Config) Limit(route, class string) int64 {
+	if limit, ok := c.Routes[route+":"+class]; ok {
+		return limit
+	}
+	if class == ClassWrite {
+		return c.Write
+	}
+	return c.Read
+}
+
+// ParseRoutes parses "<route>:<class>=<limit>" entries, such as
+// "webhooks:write=100", into the Routes of a Config
+func ParseRoutes(entries []string) (map[string]int64, error) {
+	routes := make(map[string]int64, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		route, class, _ := strings.Cut(strings.TrimSpace(name), ":")
+		if !ok || route == "" || (class != ClassRead && class != ClassWrite) {
+			return nil, fmt.Errorf("invalid quota %q, use route:read=limit or route:write=limit", entry)
+		}
+		limit, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid quota %q: the limit must be a whole number of requests", entry)
+		}
+		routes[route+":"+class] = limit
+	}
+	return routes, nil
+}
+
+// Result is the outcome of counting a request against its budget
+type Result struct {
+	Allowed bool
+	// Limit is the daily budget; zero when the request is unlimited
+	Limit     int64
+	Remaining int64
+	// Reset is when the budget is renewed
+	Reset time.Time
+}
+
+// Usage is how much of a budget a client used today
+type Usage struct {
+	Route     string `json:"route"`
+	Class     string `json:"class"`
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+// ClientUsage is the usage of every budget a client drew on today
+type ClientUsage struct {
+	Client string    `json:"client"`
+	Reset  time.Time `json:"reset"`
+	Quotas []Usage   `json:"quotas"`
+}
+
+// Store keeps the request counters of the budgets
+type Store interface {
+	// Take adds one to the counter of key unless it reached limit. A new
+	// counter expires at expiresAt. It returns the counter and whether the
+	// request was counted.
+	Take(ctx context.Context, key string, limit int64, expiresAt time.Time) (int64, bool, error)
+	// Counters returns the counters whose keys start with prefix
+	Counters(ctx context.Context, prefix string) (map[string]int64, error)
+	// Delete removes the counters whose keys start with prefix and returns
+	// how many there were
+	Delete(ctx context.Context, prefix string) (int, error)
+}
+
+// Manager counts the requests of clients against their daily budgets.
+// Budgets are renewed at midnight UTC.
+type Manager struct {
+	store  Store
+	config Config
+	now    func() time.Time
+}
+
+// NewManager creates a quota manager keeping its counters in store
+func NewManager(store Store, config Config) *Manager {
+	return &Manager{store: store, config: config, now: time.Now}
+}
+
+// Consume counts a request of client to route against the budget of class.
+// Requests over the budget are refused and not counted.
+func (m *Manager) Consume(ctx context.Context, client, route, class string) (Result, error) {
+	limit := m.config.Limit(route, class)
+	if limit == 0 {
+		return Result{Allowed: true}, nil
+	}
+
+	day, reset := m.window()
+	used, allowed, err := m.store.Take(ctx, counterKey(client, day, route, class), limit, reset)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(0, limit-used),
+		Reset:     reset,
+	}, nil
+}
+
+// Usage returns the budgets client drew on today, sorted by route and class
+func (m *Manager) Usage(ctx context.Context, client string) (*ClientUsage, error) {
+	day, reset := m.window()
+	prefix := counterKey(client, day, "", "")
+	counters, err := m.store.Counters(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &ClientUsage{Client: client, Reset: reset, Quotas: []Usage{}}
+	for key, used := range counters {
+		route, class, _ := strings.Cut(strings.TrimPrefix(key, prefix), "/")
+		limit := m.config.Limit(route, class)
+		usage.Quotas = append(usage.Quotas, Usage{
+			Route:     route,
+			Class:     class,
+			Used:      used,
+			Limit:     limit,
+			Remaining: max(0, limit-used),
+		})
+	}
+	sort.Slice(usage.Quotas, func(i, j int) bool {
+		if usage.Quotas[i].Route != usage.Quotas[j].Route {
+			return usage.Quotas[i].Route < usage.Quotas[j].Route
+		}
+		return usage.Quotas[i].Class < usage.Quotas[j].Class
+	})
+	return usage, nil
+}
+
+// Reset renews every budget of client and returns how many it had drawn on
+func (m *Manager) Reset(ctx context.Context, client string) (int, error) {
+	return m.store.Delete(ctx, client+"/")
+}
+
+// window returns the current day and when it ends
+func (m *Manager) window() (string, time.Time) {
+	now := m.now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return start.Format("20060102"), start.AddDate(0, 0, 1)
+}
+
+// counterKey returns the key of the counter of a client's requests to route
+// of class on day. Keys start with the client so its counters share a prefix.
+func counterKey(client, day, route, class string) string {
+	if route == "" {
+		return client + "/" + day + "/"
+	}
+	return client + "/" + day + "/" + route + "/" + class
+}
+
+// counter is the in-memory state of a single budget
+type counter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the counters in process memory. It is suitable for
+// single-instance deployments; use RedisStore when several instances must
+// share the budgets.
+type MemoryStore struct {
+	counters map[string]*counter
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory counter store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counter), now: time.Now}
+}
+
+// Take adds one to the counter of key unless it reached limit
+func (s *MemoryStore) Take(ctx context.Context, key string, limit int64, expiresAt time.Time) (int64, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	c, exists := s.counters[key]
+	if !exists || !s.now().Before(c.expiresAt) {
+		c = &counter{expiresAt: expiresAt}
+		s.counters[key] = c
+	}
+
+	if c.value >= limit {
+		return c.value, false, nil
+	}
+	c.value++
+	return c.value, true, nil
+}
+
+// Counters returns the live counters whose keys start with prefix
+func (s *MemoryStore) Counters(ctx context.Context, prefix string) (map[string]int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	counters := make(map[string]int64)
+	for key, c := range s.counters {
+		if strings.HasPrefix(key, prefix) && now.Before(c.expiresAt) {
+			counters[key] = c.value
+		}
+	}
+	return counters, nil
+}
+
+// Delete removes the counters whose keys start with prefix
+func (s *MemoryStore) Delete(ctx context.Context, prefix string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	deleted := 0
+	for key, c := range s.counters {
+		if strings.HasPrefix(key, prefix) {
+			if now.Before(c.expiresAt) {
+				deleted++
+			}
+			delete(s.counters, key)
+		}
+	}
+	return deleted, nil
+}
+
+// Cleanup removes expired counters, keeping memory bounded by the number of
+// clients active today
+func (s *MemoryStore) Cleanup() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	for key, c := range s.counters {
+		if !now.Before(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock for deterministic day boundaries
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.current }
+
+func (c *fakeClock) advance(d time.Duration) { c.current = c.current.Add(d) }
+
+// newTestManager returns a manager over a memory store whose clock starts at
+// 2024-01-15 23:00 UTC
+func newTestManager(config Config) (*Manager, *fakeClock) {
+	clock := &fakeClock{current: time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.now
+	manager := NewManager(store, config)
+	manager.now = clock.now
+	return manager, clock
+}
+
+func TestParseRoutes(t *testing.T) {
+	t.Run("Parse route overrides", func(t *testing.T) {
+		// Act
+		routes, err := ParseRoutes([]string{"webhooks:write=100", " products:read = 0"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"webhooks:write": 100, "products:read": 0}, routes)
+	})
+
+	t.Run("Reject malformed overrides", func(t *testing.T) {
+		for _, entry := range []string{"webhooks=100", "webhooks:delete=100", ":read=1", "webhooks:read=-1", "webhooks:read=many"} {
+			// Act
+			_, err := ParseRoutes([]string{entry})
+
+			// Assert
+			assert.Error(t, err, entry)
+		}
+	})
+}
+
+func TestManager_Consume(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Count reads and writes against separate budgets", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Read: 2, Write: 1})
+
+		// Act
+		firstRead, err := manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+		require.NoError(t, err)
+		write, err := manager.Consume(ctx, "api-key:key-1", "products", ClassWrite)
+		require.NoError(t, err)
+		secondRead, err := manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+		require.NoError(t, err)
+		thirdRead, err := manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, firstRead.Allowed)
+		assert.Equal(t, int64(2), firstRead.Limit)
+		assert.Equal(t, int64(1), firstRead.Remaining)
+		assert.True(t, write.Allowed)
+		assert.Equal(t, int64(0), write.Remaining)
+		assert.True(t, secondRead.Allowed)
+		assert.False(t, thirdRead.Allowed)
+		assert.Equal(t, int64(0), thirdRead.Remaining)
+		assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), thirdRead.Reset)
+	})
+
+	t.Run("Clients and routes have independent budgets", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Read: 1, Write: 1})
+		_, _ = manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+
+		// Act
+		otherClient, err := manager.Consume(ctx, "api-key:key-2", "products", ClassRead)
+		require.NoError(t, err)
+		otherRoute, err := manager.Consume(ctx, "api-key:key-1", "webhooks", ClassRead)
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, otherClient.Allowed)
+		assert.True(t, otherRoute.Allowed)
+	})
+
+	t.Run("Apply the budgets of routes", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Read: 100, Write: 100, Routes: map[string]int64{"webhooks:write": 5}})
+
+		// Act
+		result, err := manager.Consume(ctx, "api-key:key-1", "webhooks", ClassWrite)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), result.Limit)
+	})
+
+	t.Run("Leave unlimited classes uncounted", func(t *testing.T) {
+		// Arrange
+		manager, _ := newTestManager(Config{Write: 1})
+
+		// Act
+		result, err := manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+		require.NoError(t, err)
+		usage, err := manager.Usage(ctx, "api-key:key-1")
+		require.NoError(t, err)
+
+		// Assert
+		assert.True(t, result.Allowed)
+		assert.Zero(t, result.Limit)
+		assert.Empty(t, usage.Quotas)
+	})
+
+	t.Run("Renew the budgets at midnight UTC", func(t *testing.T) {
+		// Arrange
+		manager, clock := newTestManager(Config{Read: 1})
+		_, _ = manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+		clock.advance(time.Hour)
+
+		// Act
+		result, err := manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC), result.Reset)
+	})
+}
+
+func TestManager_UsageAndReset(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	manager, _ := newTestManager(Config{Read: 10, Write: 5})
+	for i := 0; i < 3; i++ {
+		_, _ = manager.Consume(ctx, "api-key:key-1", "products", ClassRead)
+	}
+	_, _ = manager.Consume(ctx, "api-key:key-1", "products", ClassWrite)
+	_, _ = manager.Consume(ctx, "api-key:key-10", "products", ClassWrite)
+
+	t.Run("Report the budgets a client drew on", func(t *testing.T) {
+		// Act
+		usage, err := manager.Usage(ctx, "api-key:key-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "api-key:key-1", usage.Client)
+		assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), usage.Reset)
+		assert.Equal(t, []Usage{
+			{Route: "products", Class: ClassRead, Used: 3, Limit: 10, Remaining: 7},
+			{Route: "products", Class: ClassWrite, Used: 1, Limit: 5, Remaining: 4},
+		}, usage.Quotas)
+	})
+
+	t.Run("Reset the budgets of a client only", func(t *testing.T) {
+		// Act
+		reset, err := manager.Reset(ctx, "api-key:key-1")
+		require.NoError(t, err)
+		usage, err := manager.Usage(ctx, "api-key:key-1")
+		require.NoError(t, err)
+		other, err := manager.Usage(ctx, "api-key:key-10")
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 2, reset)
+		assert.Empty(t, usage.Quotas)
+		assert.Len(t, other.Quotas, 1)
+	})
+}
+
+func TestMemoryStore_Cleanup(t *testing.T) {
+	// Arrange
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	store := NewMemoryStore()
+	store.now = clock.now
+	ctx := context.Background()
+	_, _, _ = store.Take(ctx, "expired", 10, clock.current.Add(time.Minute))
+	_, _, _ = store.Take(ctx, "live", 10, clock.current.Add(time.Hour))
+	clock.advance(time.Minute)
+
+	// Act
+	store.Cleanup()
+
+	// Assert
+	assert.NotContains(t, store.counters, "expired")
+	assert.Contains(t, store.counters, "live")
+}
+
+func TestRedisStore(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewRedisStore(client, "quota:")
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("Count up to the limit", func(t *testing.T) {
+		// Act
+		first, firstCounted, err := store.Take(ctx, "ip:10.0.0.1/20240115/products/read", 2, expiresAt)
+		require.NoError(t, err)
+		_, _, err = store.Take(ctx, "ip:10.0.0.1/20240115/products/read", 2, expiresAt)
+		require.NoError(t, err)
+		third, thirdCounted, err := store.Take(ctx, "ip:10.0.0.1/20240115/products/read", 2, expiresAt)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, int64(1), first)
+		assert.True(t, firstCounted)
+		assert.Equal(t, int64(2), third)
+		assert.False(t, thirdCounted)
+		assert.True(t, server.Exists("quota:ip:10.0.0.1/20240115/products/read"))
+		assert.InDelta(t, time.Hour.Seconds(), server.TTL("quota:ip:10.0.0.1/20240115/products/read").Seconds(), 5)
+	})
+
+	t.Run("List and delete the counters of a prefix", func(t *testing.T) {
+		// Arrange
+		_, _, err := store.Take(ctx, "ip:10.0.0.1/20240115/products/write", 2, expiresAt)
+		require.NoError(t, err)
+		_, _, err = store.Take(ctx, "ip:10.0.0.12/20240115/products/write", 2, expiresAt)
+		require.NoError(t, err)
+
+		// Act
+		counters, err := store.Counters(ctx, "ip:10.0.0.1/")
+		require.NoError(t, err)
+		deleted, err := store.Delete(ctx, "ip:10.0.0.1/")
+		require.NoError(t, err)
+		remaining, err := store.Counters(ctx, "ip:")
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, map[string]int64{
+			"ip:10.0.0.1/20240115/products/read":  2,
+			"ip:10.0.0.1/20240115/products/write": 1,
+		}, counters)
+		assert.Equal(t, 2, deleted)
+		assert.Equal(t, map[string]int64{"ip:10.0.0.12/20240115/products/write": 1}, remaining)
+	})
+
+	t.Run("Match clients literally", func(t *testing.T) {
+		// Arrange
+		_, _, err := store.Take(ctx, "api-key:key-1/20240115/products/read", 2, expiresAt)
+		require.NoError(t, err)
+
+		// Act
+		counters, err := store.Counters(ctx, "api-key:*/")
+		require.NoError(t, err)
+
+		// Assert
+		assert.Empty(t, counters)
+	})
+}
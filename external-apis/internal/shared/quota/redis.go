@@ -0,0 +1,124 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript adds one to a counter unless it reached the limit, setting the
+// expiry of new counters. Returns {counter, counted}.
+var takeScript = redis.NewScript(`
+local used = tonumber(redis.call("GET", KEYS[1]) or "0")
+if used >= tonumber(ARGV[1]) then
+  return {used, 0}
+end
+
+used = redis.call("INCR", KEYS[1])
+if used == 1 then
+  redis.call("PEXPIREAT", KEYS[1], ARGV[2])
+end
+return {used, 1}
+`)
+
+// scanCount is the number of keys asked for per SCAN call
+const scanCount = 100
+
+// RedisStore keeps the counters in Redis so all instances of a service share
+// the same budgets
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore creates a new Redis-backed counter store whose keys start
+// with prefix
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Take adds one to the shared counter of key unless it reached limit
+func (s *RedisStore) Take(ctx context.Context, key string, limit int64, expiresAt time.Time) (int64, bool, error) {
+	values, err := takeScript.Run(ctx, s.client, []string{s.prefix + key}, limit, expiresAt.UnixMilli()).Int64Slice()
+	if err != nil {
+		return 0, false, fmt.Errorf("quota script failed: %w", err)
+	}
+
+	if len(values) != 2 {
+		return 0, false, fmt.Errorf("quota script returned %d values", len(values))
+	}
+
+	return values[0], values[1] == 1, nil
+}
+
+// Counters returns the counters whose keys start with prefix
+func (s *RedisStore) Counters(ctx context.Context, prefix string) (map[string]int64, error) {
+	keys, err := s.scan(ctx, prefix)
+	if err != nil || len(keys) == 0 {
+		return map[string]int64{}, err
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota counters: %w", err)
+	}
+
+	counters := make(map[string]int64, len(keys))
+	for i, value := range values {
+		// Counters expiring between SCAN and MGET are nil
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		used, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quota counter %s: %w", keys[i], err)
+		}
+		counters[strings.TrimPrefix(keys[i], s.prefix)] = used
+	}
+	return counters, nil
+}
+
+// Delete removes the counters whose keys start with prefix
+func (s *RedisStore) Delete(ctx context.Context, prefix string) (int, error) {
+	keys, err := s.scan(ctx, prefix)
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	deleted, err := s.client.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete quota counters: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// scan returns the Redis keys of the counters whose keys start with prefix
+func (s *RedisStore) scan(ctx context.Context, prefix string) ([]string, error) {
+	match := escapePattern(s.prefix+prefix) + "*"
+
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := s.client.Scan(ctx, cursor, match, scanCount).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quota counters: %w", err)
+		}
+		keys = append(keys, batch...)
+		if cursor = next; cursor == 0 {
+			return keys, nil
+		}
+	}
+}
+
+// patternEscaper escapes the characters special to SCAN MATCH patterns
+var patternEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// escapePattern makes s match itself literally in a SCAN MATCH pattern
+func escapePattern(s string) string {
+	return patternEscaper.Replace(s)
+}
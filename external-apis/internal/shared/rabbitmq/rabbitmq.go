@@ -0,0 +1,376 @@
+// Package rabbitmq consumes messages from a RabbitMQ queue, reconnecting
+// whenever the connection drops. Messages that can never be processed are
+// parked in the dead letter queue instead of being redelivered forever.
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/metrics"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var log = logger.New("shared/rabbitmq")
+
+// Outcomes of a consumed message, as reported to the metrics
+const (
+	// OutcomeProcessed means the handler succeeded and the message was acknowledged
+	OutcomeProcessed = "processed"
+	// OutcomeMalformed means the message could not be decoded and was parked
+	OutcomeMalformed = "malformed"
+	// OutcomeFailed means the handler rejected the message and it was parked
+	OutcomeFailed = "failed"
+	// OutcomeRequeued means the message was returned to the queue, because a
+	// dependency was unavailable or the consumer is stopping
+	OutcomeRequeued = "requeued"
+)
+
+var (
+	// ErrMalformed is wrapped by handlers for messages that cannot be decoded
+	// or do not follow their schema
+	ErrMalformed = errors.New("malformed message")
+	// ErrNotConnected is returned while the consumer has no connection
+	ErrNotConnected = errors.New("not connected to RabbitMQ")
+)
+
+// Config configures a consumer
+type Config struct {
+	URL string
+	// Queue is the durable queue consumed; it is declared when missing
+	Queue string
+	// Prefetch bounds how many unacknowledged messages the broker sends ahead
+	Prefetch int
+	// ReconnectDelay is the wait between connection attempts
+	ReconnectDelay time.Duration
+	// RedeliveryDelay is the wait before a message is returned to the queue
+	// because a dependency of its handler was unavailable
+	RedeliveryDelay time.Duration
+	// DeadLetters parks messages that were malformed or rejected by the
+	// handler so they can be inspected and replayed; they are only logged
+	// when it is nil
+	DeadLetters *deadletter.Queue
+	// Metrics counts the consumed messages by outcome; it may be nil
+	Metrics *metrics.ConsumerMetrics
+}
+
+// Message is a message consumed from the queue
+type Message struct {
+	ID      string
+	Type    string
+	Body    []byte
+	Headers amqp.Table
+}
+
+// Handler processes a consumed message. Errors wrapping ErrMalformed or
+// apperror.ErrUnavailable are respectively parked as malformed and
+// requeued; any other error parks the message as failed.
+type Handler func(ctx context.Context, message Message) error
+
+// Connection is the subset of *amqp.Connection used by Consumer
+type Connection interface {
+	Channel() (Channel, error)
+	IsClosed() bool
+	Close() error
+}
+
+// Channel is the subset of *amqp.Channel used by Consumer
+type Channel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	Close() error
+}
+
+// amqpConnection adapts *amqp.Connection to Connection
+type amqpConnection struct {
+	*amqp.Connection
+}
+
+// Channel opens a channel on the connection
+func (c amqpConnection) Channel() (Channel, error) {
+	return c.Connection.Channel()
+}
+
+// Dial connects to the RabbitMQ server at url
+func Dial(url string) (Connection, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return amqpConnection{conn}, nil
+}
+
+// Consumer hands the messages of a queue to a handler one at a time. A
+// message is acknowledged once handled, so messages being processed when
+// the consumer stops or the connection drops are delivered again.
+type Consumer struct {
+	config Config
+	dial   func(url string) (Connection, error)
+
+	mutex  sync.Mutex
+	conn   Connection
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConsumer creates a consumer of the queue of config. It does not
+// connect until it is started.
+func NewConsumer(config Config) *Consumer {
+	return newConsumer(config, Dial)
+}
+
+// newConsumer creates a consumer connecting through dial
+func newConsumer(config Config, dial func(url string) (Connection, error)) *Consumer {
+	if config.Prefetch <= 0 {
+		config.Prefetch = 1
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = time.Second
+	}
+
+	return &Consumer{config: config, dial: dial}
+}
+
+// Name identifies the consumer
+func (c *Consumer) Name() string {
+	return deadletter.SourceRabbitMQ
+}
+
+// Start consumes the queue in the background until Close is called
+func (c *Consumer) Start(handler Handler) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c.mutex.Lock()
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	done := c.done
+	c.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+		c.run(ctx, handler)
+	}()
+}
+
+// Close stops consuming, waiting for the message being handled
+func (c *Consumer) Close() error {
+	c.mutex.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mutex.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// Ping checks that the consumer is connected
+func (c *Consumer) Ping(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.conn == nil || c.conn.IsClosed() {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+// Replay publishes a parked message back to the queue. It implements
+// deadletter.Replayer.
+func (c *Consumer) Replay(ctx context.Context, entry *deadletter.Entry) error {
+	c.mutex.Lock()
+	conn := c.conn
+	c.mutex.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer channel.Close()
+
+	return channel.PublishWithContext(ctx, "", entry.Destination, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    entry.EventID,
+		Type:         entry.EventType,
+		Body:         entry.Event,
+	})
+}
+
+// run consumes the queue, connecting again whenever the connection drops,
+// until ctx is cancelled
+func (c *Consumer) run(ctx context.Context, handler Handler) {
+	for {
+		err := c.consume(ctx, handler)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).WithField("queue", c.config.Queue).Warn("RabbitMQ consumer disconnected, reconnecting")
+		if !sleep(ctx, c.config.ReconnectDelay) {
+			return
+		}
+	}
+}
+
+// consume connects and handles the messages of the queue until the
+// connection drops or ctx is cancelled
+func (c *Consumer) consume(ctx context.Context, handler Handler) error {
+	conn, err := c.dial(c.config.URL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	channel, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer channel.Close()
+
+	if err := channel.Qos(c.config.Prefetch, 0, false); err != nil {
+		return err
+	}
+	if _, err := channel.QueueDeclare(c.config.Queue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	deliveries, err := channel.Consume(c.config.Queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	c.setConn(conn)
+	defer c.setConn(nil)
+	log.WithField("queue", c.config.Queue).Info("Consuming RabbitMQ queue")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return errors.New("delivery channel closed")
+			}
+			c.deliver(ctx, delivery, handler)
+		}
+	}
+}
+
+// setConn records the connection the consumer is using
+func (c *Consumer) setConn(conn Connection) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.conn = conn
+}
+
+// deliver hands a delivery to handler and settles it according to the outcome
+func (c *Consumer) deliver(ctx context.Context, delivery amqp.Delivery, handler Handler) {
+	start := time.Now()
+	message := Message{
+		ID:      delivery.MessageId,
+		Type:    delivery.Type,
+		Body:    delivery.Body,
+		Headers: delivery.Headers,
+	}
+
+	outcome := c.settle(ctx, delivery, message, handler(ctx, message))
+	c.config.Metrics.Observe(c.config.Queue, outcome, time.Since(start))
+}
+
+// settle acknowledges, requeues or parks a delivery depending on the error
+// of its handler, and returns the outcome
+func (c *Consumer) settle(ctx context.Context, delivery amqp.Delivery, message Message, err error) string {
+	fields := logger.Fields{
+		"queue":      c.config.Queue,
+		"message_id": message.ID,
+	}
+
+	switch {
+	case err == nil:
+		c.ack(delivery)
+		return OutcomeProcessed
+	case ctx.Err() != nil:
+		c.requeue(delivery)
+		return OutcomeRequeued
+	case errors.Is(err, apperror.ErrUnavailable):
+		log.WithError(err).WithFields(fields).Warn("Failed to handle message, requeueing")
+		// The delay keeps the message from cycling while the dependency is down
+		sleep(ctx, c.config.RedeliveryDelay)
+		c.requeue(delivery)
+		return OutcomeRequeued
+	case errors.Is(err, ErrMalformed):
+		log.WithError(err).WithFields(fields).Error("Parking malformed message")
+		c.park(message, err)
+		c.ack(delivery)
+		return OutcomeMalformed
+	default:
+		log.WithError(err).WithFields(fields).Error("Parking rejected message")
+		c.park(message, err)
+		c.ack(delivery)
+		return OutcomeFailed
+	}
+}
+
+// park stores a message in the dead letter queue. Bodies that are not JSON
+// are kept as a JSON string.
+func (c *Consumer) park(message Message, err error) {
+	if c.config.DeadLetters == nil {
+		return
+	}
+
+	body := json.RawMessage(message.Body)
+	if !json.Valid(body) {
+		body, _ = json.Marshal(string(message.Body))
+	}
+
+	c.config.DeadLetters.Park(deadletter.Entry{
+		Source:      deadletter.SourceRabbitMQ,
+		Destination: c.config.Queue,
+		EventID:     message.ID,
+		EventType:   message.Type,
+		Event:       body,
+		Error:       err.Error(),
+		Attempts:    1,
+	})
+}
+
+// ack acknowledges a delivery
+func (c *Consumer) ack(delivery amqp.Delivery) {
+	if err := delivery.Ack(false); err != nil {
+		log.WithError(err).WithField("queue", c.config.Queue).Warn("Failed to acknowledge message")
+	}
+}
+
+// requeue returns a delivery to the queue
+func (c *Consumer) requeue(delivery amqp.Delivery) {
+	if err := delivery.Nack(false, true); err != nil {
+		log.WithError(err).WithField("queue", c.config.Queue).Warn("Failed to requeue message")
+	}
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,329 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/apperror"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// settlements records how deliveries were settled. It implements
+// amqp.Acknowledger.
+type settlements struct {
+	mutex    sync.Mutex
+	acked    []uint64
+	requeued []uint64
+}
+
+func (s *settlements) Ack(tag uint64, multiple bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.acked = append(s.acked, tag)
+	return nil
+}
+
+func (s *settlements) Nack(tag uint64, multiple, requeue bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if requeue {
+		s.requeued = append(s.requeued, tag)
+	}
+	return nil
+}
+
+func (s *settlements) Reject(tag uint64, requeue bool) error {
+	return s.Nack(tag, false, requeue)
+}
+
+func (s *settlements) settled() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.acked) + len(s.requeued)
+}
+
+// fakeChannel serves the deliveries of a Go channel and records publishings
+type fakeChannel struct {
+	deliveries chan amqp.Delivery
+	mutex      sync.Mutex
+	published  []amqp.Publishing
+	keys       []string
+}
+
+func (c *fakeChannel) Qos(prefetchCount, prefetchSize int, global bool) error {
+	return nil
+}
+
+func (c *fakeChannel) QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error) {
+	return amqp.Queue{Name: name}, nil
+}
+
+func (c *fakeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error) {
+	return c.deliveries, nil
+}
+
+func (c *fakeChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.published = append(c.published, msg)
+	c.keys = append(c.keys, key)
+	return nil
+}
+
+func (c *fakeChannel) Close() error {
+	return nil
+}
+
+// fakeConnection opens channels sharing one fakeChannel
+type fakeConnection struct {
+	channel *fakeChannel
+	mutex   sync.Mutex
+	closed  bool
+}
+
+func (c *fakeConnection) Channel() (Channel, error) {
+	return c.channel, nil
+}
+
+func (c *fakeConnection) IsClosed() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.closed
+}
+
+func (c *fakeConnection) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// newTestConsumer creates a consumer dialing conns in turn, failing once
+// they are exhausted
+func newTestConsumer(t *testing.T, config Config, conns ...*fakeConnection) (*Consumer, *int) {
+	t.Helper()
+	var mutex sync.Mutex
+	dials := 0
+	consumer := newConsumer(config, func(url string) (Connection, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if dials >= len(conns) {
+			return nil, errors.New("connection refused")
+		}
+		dials++
+		return conns[dials-1], nil
+	})
+	t.Cleanup(func() { _ = consumer.Close() })
+	return consumer, &dials
+}
+
+// delivery builds a delivery settled through acks
+func delivery(acks *settlements, tag uint64, body string) amqp.Delivery {
+	return amqp.Delivery{
+		Acknowledger: acks,
+		DeliveryTag:  tag,
+		MessageId:    fmt.Sprintf("message-%d", tag),
+		Type:         "catalog.update",
+		Body:         []byte(body),
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	t.Run("Acknowledge handled messages", func(t *testing.T) {
+		// Arrange
+		channel := &fakeChannel{deliveries: make(chan amqp.Delivery, 1)}
+		consumer, _ := newTestConsumer(t, Config{Queue: "catalog-updates"}, &fakeConnection{channel: channel})
+		acks := &settlements{}
+		var handled []Message
+		var mutex sync.Mutex
+		consumer.Start(func(ctx context.Context, message Message) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			handled = append(handled, message)
+			return nil
+		})
+
+		// Act
+		channel.deliveries <- delivery(acks, 1, `{"sku":"MOUSE-1"}`)
+
+		// Assert
+		require.Eventually(t, func() bool { return acks.settled() == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, []uint64{1}, acks.acked)
+		mutex.Lock()
+		defer mutex.Unlock()
+		require.Len(t, handled, 1)
+		assert.Equal(t, "message-1", handled[0].ID)
+		assert.Equal(t, "catalog.update", handled[0].Type)
+		assert.JSONEq(t, `{"sku":"MOUSE-1"}`, string(handled[0].Body))
+	})
+
+	t.Run("Park malformed and rejected messages", func(t *testing.T) {
+		// Arrange
+		channel := &fakeChannel{deliveries: make(chan amqp.Delivery, 2)}
+		deadLetters := deadletter.NewQueue(deadletter.NewMemoryStore())
+		registry := prometheus.NewRegistry()
+		consumerMetrics := metrics.NewConsumerMetrics(registry, "test-service")
+		consumer, _ := newTestConsumer(t, Config{
+			Queue:       "catalog-updates",
+			DeadLetters: deadLetters,
+			Metrics:     consumerMetrics,
+		}, &fakeConnection{channel: channel})
+		acks := &settlements{}
+		consumer.Start(func(ctx context.Context, message Message) error {
+			if message.ID == "message-1" {
+				return fmt.Errorf("%w: invalid character", ErrMalformed)
+			}
+			return apperror.Validation("category does not exist")
+		})
+
+		// Act
+		channel.deliveries <- delivery(acks, 1, `not json`)
+		channel.deliveries <- delivery(acks, 2, `{"sku":"MOUSE-1"}`)
+
+		// Assert
+		require.Eventually(t, func() bool { return acks.settled() == 2 }, time.Second, time.Millisecond)
+		assert.Equal(t, []uint64{1, 2}, acks.acked, "parked messages are removed from the queue")
+
+		entries, err := deadLetters.List(deadletter.Filter{Source: deadletter.SourceRabbitMQ})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		byID := map[string]*deadletter.Entry{entries[0].EventID: entries[0], entries[1].EventID: entries[1]}
+		assert.JSONEq(t, `"not json"`, string(byID["message-1"].Event), "bodies that are not JSON are kept as a string")
+		assert.Equal(t, "malformed message: invalid character", byID["message-1"].Error)
+		assert.Equal(t, "catalog-updates", byID["message-2"].Destination)
+		assert.JSONEq(t, `{"sku":"MOUSE-1"}`, string(byID["message-2"].Event))
+		assert.Equal(t, "category does not exist", byID["message-2"].Error)
+
+		require.Eventually(t, func() bool {
+			series, err := testutil.GatherAndCount(registry, "consumer_messages_total")
+			return err == nil && series == 2
+		}, time.Second, time.Millisecond, "one series per outcome")
+	})
+
+	t.Run("Requeue messages while a dependency is unavailable", func(t *testing.T) {
+		// Arrange
+		channel := &fakeChannel{deliveries: make(chan amqp.Delivery, 1)}
+		consumer, _ := newTestConsumer(t, Config{
+			Queue:           "catalog-updates",
+			RedeliveryDelay: 10 * time.Millisecond,
+		}, &fakeConnection{channel: channel})
+		acks := &settlements{}
+		consumer.Start(func(ctx context.Context, message Message) error {
+			return apperror.Unavailable("search index unavailable")
+		})
+
+		// Act
+		channel.deliveries <- delivery(acks, 1, `{"sku":"MOUSE-1"}`)
+
+		// Assert
+		require.Eventually(t, func() bool { return acks.settled() == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, []uint64{1}, acks.requeued)
+		assert.Empty(t, acks.acked)
+	})
+
+	t.Run("Requeue the message being handled when closed", func(t *testing.T) {
+		// Arrange
+		channel := &fakeChannel{deliveries: make(chan amqp.Delivery, 1)}
+		consumer, _ := newTestConsumer(t, Config{Queue: "catalog-updates"}, &fakeConnection{channel: channel})
+		acks := &settlements{}
+		started := make(chan struct{})
+		consumer.Start(func(ctx context.Context, message Message) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		channel.deliveries <- delivery(acks, 1, `{"sku":"MOUSE-1"}`)
+		<-started
+
+		// Act
+		err := consumer.Close()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1}, acks.requeued)
+	})
+
+	t.Run("Reconnect after the connection drops", func(t *testing.T) {
+		// Arrange
+		dropped := &fakeChannel{deliveries: make(chan amqp.Delivery)}
+		close(dropped.deliveries)
+		channel := &fakeChannel{deliveries: make(chan amqp.Delivery, 1)}
+		consumer, dials := newTestConsumer(t, Config{
+			Queue:          "catalog-updates",
+			ReconnectDelay: time.Millisecond,
+		}, &fakeConnection{channel: dropped}, &fakeConnection{channel: channel})
+		acks := &settlements{}
+		consumer.Start(func(ctx context.Context, message Message) error {
+			return nil
+		})
+
+		// Act
+		channel.deliveries <- delivery(acks, 1, `{"sku":"MOUSE-1"}`)
+
+		// Assert
+		require.Eventually(t, func() bool { return acks.settled() == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, 2, *dials)
+		assert.NoError(t, consumer.Ping(context.Background()))
+	})
+
+	t.Run("Replay parked message", func(t *testing.T) {
+		// Arrange
+		channel := &fakeChannel{deliveries: make(chan amqp.Delivery)}
+		consumer, _ := newTestConsumer(t, Config{Queue: "catalog-updates"}, &fakeConnection{channel: channel})
+		consumer.Start(func(ctx context.Context, message Message) error {
+			return nil
+		})
+		require.Eventually(t, func() bool { return consumer.Ping(context.Background()) == nil }, time.Second, time.Millisecond)
+
+		// Act
+		err := consumer.Replay(context.Background(), &deadletter.Entry{
+			Destination: "catalog-updates",
+			EventID:     "message-1",
+			EventType:   "catalog.update",
+			Event:       []byte(`{"sku":"MOUSE-1"}`),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		channel.mutex.Lock()
+		defer channel.mutex.Unlock()
+		require.Len(t, channel.published, 1)
+		assert.Equal(t, []string{"catalog-updates"}, channel.keys)
+		assert.Equal(t, "message-1", channel.published[0].MessageId)
+		assert.Equal(t, amqp.Persistent, channel.published[0].DeliveryMode)
+		assert.JSONEq(t, `{"sku":"MOUSE-1"}`, string(channel.published[0].Body))
+	})
+
+	t.Run("Not connected", func(t *testing.T) {
+		// Arrange
+		consumer, _ := newTestConsumer(t, Config{Queue: "catalog-updates", ReconnectDelay: time.Millisecond})
+		consumer.Start(func(ctx context.Context, message Message) error {
+			return nil
+		})
+
+		// Act
+		pingErr := consumer.Ping(context.Background())
+		replayErr := consumer.Replay(context.Background(), &deadletter.Entry{Destination: "catalog-updates"})
+
+		// Assert
+		assert.ErrorIs(t, pingErr, ErrNotConnected)
+		assert.ErrorIs(t, replayErr, ErrNotConnected)
+	})
+}
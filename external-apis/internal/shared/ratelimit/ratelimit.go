@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Config configures a token-bucket limiter
+type Config struct {
+	// Rate is the number of tokens added to each bucket per second
+	Rate float64
+	// Burst is the bucket capacity, i.e. the largest burst allowed at once
+	Burst int
+}
+
+// Result is the outcome of a single limiter check
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long the caller should wait before the next token
+	// becomes available. It is zero when the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether a request identified by key may proceed
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// bucket is the in-memory state for a single key
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiter is a token-bucket limiter holding its state in process memory.
+// It is suitable for single-instance deployments; use RedisLimiter when several
+// instances must share a budget.
+type MemoryLimiter struct {
+	config  Config
+	buckets map[string]*bucket
+	mutex   sync.Mutex
+	now     func() time.Time
+}
+
+// NewMemoryLimiter creates a new in-memory token-bucket limiter
+func NewMemoryLimiter(config Config) *MemoryLimiter {
+	return &MemoryLimiter{
+		config:  config,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket for key if one is available
+func (l *MemoryLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	capacity := float64(l.config.Burst)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*l.config.Rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+	}
+
+	return Result{RetryAfter: retryAfter(b.tokens, l.config.Rate)}, nil
+}
+
+// Cleanup removes buckets that have been idle long enough to be full again,
+// keeping memory bounded by the number of recently active clients
+func (l *MemoryLimiter) Cleanup() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		refilled := b.tokens + now.Sub(b.last).Seconds()*l.config.Rate
+		if refilled >= float64(l.config.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// retryAfter returns how long it takes for the bucket to hold a whole token
+func retryAfter(tokens, rate float64) time.Duration {
+	if rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration((1 - tokens) / rate * float64(time.Second))
+}
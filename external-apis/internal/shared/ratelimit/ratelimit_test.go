@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock for deterministic refills
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.current }
+
+func (c *fakeClock) advance(d time.Duration) { c.current = c.current.Add(d) }
+
+func TestMemoryLimiter_Allow(t *testing.T) {
+	// Arrange
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	limiter := NewMemoryLimiter(Config{Rate: 2, Burst: 3})
+	limiter.now = clock.now
+	ctx := context.Background()
+
+	t.Run("Allows up to burst", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			// Act
+			result, err := limiter.Allow(ctx, "client-a")
+
+			// Assert
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 2-i, result.Remaining)
+		}
+	})
+
+	t.Run("Rejects when bucket is empty", func(t *testing.T) {
+		// Act
+		result, err := limiter.Allow(ctx, "client-a")
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	})
+
+	t.Run("Keys have independent buckets", func(t *testing.T) {
+		// Act
+		result, err := limiter.Allow(ctx, "client-b")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("Refills over time", func(t *testing.T) {
+		// Arrange
+		clock.advance(500 * time.Millisecond)
+
+		// Act
+		result, err := limiter.Allow(ctx, "client-a")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
+
+func TestMemoryLimiter_Cleanup(t *testing.T) {
+	// Arrange
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	limiter := NewMemoryLimiter(Config{Rate: 1, Burst: 2})
+	limiter.now = clock.now
+
+	_, _ = limiter.Allow(context.Background(), "idle")
+	_, _ = limiter.Allow(context.Background(), "busy")
+	_, _ = limiter.Allow(context.Background(), "busy")
+	clock.advance(time.Second)
+
+	// Act
+	limiter.Cleanup()
+
+	// Assert
+	assert.NotContains(t, limiter.buckets, "idle")
+	assert.Contains(t, limiter.buckets, "busy")
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	clock := &fakeClock{current: time.Unix(1700000000, 0)}
+	limiter := NewRedisLimiter(client, Config{Rate: 1, Burst: 2}, "ratelimit:")
+	limiter.now = clock.now
+	ctx := context.Background()
+
+	// Act
+	first, err := limiter.Allow(ctx, "client-a")
+	require.NoError(t, err)
+	second, err := limiter.Allow(ctx, "client-a")
+	require.NoError(t, err)
+	third, err := limiter.Allow(ctx, "client-a")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 1, first.Remaining)
+	assert.True(t, second.Allowed)
+	assert.False(t, third.Allowed)
+	assert.Equal(t, time.Second, third.RetryAfter)
+	assert.True(t, server.Exists("ratelimit:client-a"))
+
+	t.Run("Refills over time", func(t *testing.T) {
+		// Arrange
+		clock.advance(time.Second)
+
+		// Act
+		result, err := limiter.Allow(ctx, "client-a")
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes from a bucket atomically. The bucket is
+// stored as a hash of the remaining tokens and the last refill time in
+// milliseconds, and expires once it would be full again.
+//
+// Returns {allowed, remaining tokens * 1000, retry after in milliseconds}.
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", key, "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  ts = now
+end
+
+local elapsed = math.max(0, now - ts) / 1000
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call("HSET", key, "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", key, math.ceil(burst / rate * 1000) + 1000)
+
+return {allowed, math.floor(tokens * 1000), retry}
+`)
+
+// RedisLimiter is a token-bucket limiter whose state lives in Redis so all
+// instances of a service share the same budget per key
+type RedisLimiter struct {
+	client redis.Scripter
+	config Config
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisLimiter creates a new Redis-backed token-bucket limiter
+func NewRedisLimiter(client redis.Scripter, config Config, prefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		config: config,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token from the shared bucket for key if one is available
+func (l *RedisLimiter) Allow(ctx context.Context, key string) (Result, error) {
+	if l.config.Rate <= 0 {
+		return Result{RetryAfter: time.Hour}, nil
+	}
+
+	values, err := tokenBucketScript.Run(ctx, l.client,
+		[]string{l.prefix + key},
+		l.config.Rate, l.config.Burst, l.now().UnixMilli(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script failed: %w", err)
+	}
+
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("rate limit script returned %d values", len(values))
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(math.Floor(float64(values[1]) / 1000)),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package rbac
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// AssignRoleRequest represents the request to assign a role to a principal
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// RoleResponse is the role a principal acts with. Bound is false for
+// principals falling back to the default role.
+type RoleResponse struct {
+	Principal string `json:"principal"`
+	Role      string `json:"role"`
+	Bound     bool   `json:"bound"`
+}
+
+// Handler serves the admin endpoints for managing roles
+type Handler struct {
+	authorizer *Authorizer
+}
+
+// NewHandler creates a new role admin handler
+func NewHandler(authorizer *Authorizer) *Handler {
+	return &Handler{authorizer: authorizer}
+}
+
+// RegisterRoutes registers the role admin routes. Principals are given as
+// "user:<subject>" or "api-key:<id>".
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	roles := router.Group("/roles")
+	{
+		roles.GET("", h.ListBindings)
+		roles.GET("/:principal", h.GetRole)
+		roles.PUT("/:principal", h.AssignRole)
+		roles.DELETE("/:principal", h.UnassignRole)
+	}
+}
+
+// ListBindings returns the roles assigned to principals
+func (h *Handler) ListBindings(c *gin.Context) {
+	bindings, err := h.authorizer.List()
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to list role bindings")
+		response.InternalServerError(c, "Failed to list roles")
+		return
+	}
+
+	response.OK(c, bindings)
+}
+
+// GetRole returns the role a principal acts with
+func (h *Handler) GetRole(c *gin.Context) {
+	principal := c.Param("principal")
+	if err := ValidatePrincipal(principal); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	role, err := h.authorizer.RoleOf(principal)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to resolve role")
+		response.InternalServerError(c, "Failed to get role")
+		return
+	}
+	_, err = h.authorizer.store.Get(principal)
+
+	response.OK(c, RoleResponse{Principal: principal, Role: role, Bound: err == nil})
+}
+
+// AssignRole gives a principal a role, replacing its previous one
+func (h *Handler) AssignRole(c *gin.Context) {
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	assignedBy := auth.Actor(c.Request.Context())
+	binding, err := h.authorizer.Assign(c.Param("principal"), req.Role, assignedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidPrincipal), errors.Is(err, ErrInvalidRole):
+			response.BadRequest(c, err.Error())
+		default:
+			log.Ctx(c.Request.Context()).WithError(err).Error("Failed to assign role")
+			response.InternalServerError(c, "Failed to assign role")
+		}
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"principal":   binding.Principal,
+		"role":        binding.Role,
+		"assigned_by": assignedBy,
+	}).Info("Role assigned")
+
+	response.OK(c, binding)
+}
+
+// UnassignRole removes the role of a principal, which falls back to the
+// default role
+func (h *Handler) UnassignRole(c *gin.Context) {
+	principal := c.Param("principal")
+
+	if err := h.authorizer.Unassign(principal); err != nil {
+		if errors.Is(err, ErrBindingNotFound) {
+			response.NotFound(c, "Role binding not found")
+			return
+		}
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to unassign role")
+		response.InternalServerError(c, "Failed to unassign role")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithField("principal", principal).Info("Role unassigned")
+
+	c.Status(http.StatusNoContent)
+}
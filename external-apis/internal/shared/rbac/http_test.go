@@ -0,0 +1,97 @@
+package rbac
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	authorizer := NewAuthorizer(NewMemoryStore(), Config{DefaultRole: RoleEditor})
+	router := gin.New()
+	NewHandler(authorizer).RegisterRoutes(router.Group("/admin"))
+
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Assign a role", func(t *testing.T) {
+		// Act
+		w := send(http.MethodPut, "/admin/roles/api-key:key-1", `{"role":"admin"}`)
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data Binding `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "api-key:key-1", resp.Data.Principal)
+		assert.Equal(t, RoleAdmin, resp.Data.Role)
+		assert.Equal(t, "anonymous", resp.Data.AssignedBy)
+	})
+
+	t.Run("Reject unknown roles and principals", func(t *testing.T) {
+		// Act & Assert
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/admin/roles/api-key:key-1", `{"role":"owner"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/admin/roles/alice", `{"role":"viewer"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPut, "/admin/roles/user:alice", `{}`).Code)
+	})
+
+	t.Run("Get the role of bound and unbound principals", func(t *testing.T) {
+		// Act
+		bound := send(http.MethodGet, "/admin/roles/api-key:key-1", "")
+		unbound := send(http.MethodGet, "/admin/roles/user:bob", "")
+
+		// Assert
+		require.Equal(t, http.StatusOK, bound.Code)
+		assert.JSONEq(t, `{"principal":"api-key:key-1","role":"admin","bound":true}`, dataOf(t, bound))
+		require.Equal(t, http.StatusOK, unbound.Code)
+		assert.JSONEq(t, `{"principal":"user:bob","role":"editor","bound":false}`, dataOf(t, unbound))
+	})
+
+	t.Run("List bindings", func(t *testing.T) {
+		// Act
+		w := send(http.MethodGet, "/admin/roles", "")
+
+		// Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []Binding `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data, 1)
+		assert.Equal(t, "api-key:key-1", resp.Data[0].Principal)
+	})
+
+	t.Run("Unassign a role", func(t *testing.T) {
+		// Act
+		first := send(http.MethodDelete, "/admin/roles/api-key:key-1", "")
+		second := send(http.MethodDelete, "/admin/roles/api-key:key-1", "")
+
+		// Assert
+		assert.Equal(t, http.StatusNoContent, first.Code)
+		assert.Equal(t, http.StatusNotFound, second.Code)
+	})
+}
+
+// dataOf returns the data of an enveloped response
+func dataOf(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return string(resp.Data)
+}
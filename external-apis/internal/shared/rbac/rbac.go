@@ -0,0 +1,296 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/middleware"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("shared/rbac")
+
+// Roles callers act with, from least to most privileged
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// Permissions routes require
+const (
+	// PermissionRead reads resources
+	PermissionRead = "read"
+	// PermissionWrite creates and changes resources
+	PermissionWrite = "write"
+	// PermissionDelete deletes resources, or changes many at once
+	PermissionDelete = "delete"
+	// PermissionAdmin manages the service: keys, roles, quotas and jobs
+	PermissionAdmin = "admin"
+)
+
+// grants are the permissions of each role
+var grants = map[string][]string{
+	RoleViewer: {PermissionRead},
+	RoleEditor: {PermissionRead, PermissionWrite},
+	RoleAdmin:  {PermissionRead, PermissionWrite, PermissionDelete, PermissionAdmin},
+}
+
+// ranks orders the roles by privilege
+var ranks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3}
+
+// roleKey is the Gin context key holding the role of the caller, set once
+// the request was authorized
+const roleKey = "rbac_role"
+
+var (
+	// ErrInvalidRole is returned for roles other than viewer, editor and admin
+	ErrInvalidRole = errors.New("role must be one of viewer, editor, admin")
+	// ErrInvalidPrincipal is returned for principals that are not of the form
+	// "user:<subject>" or "api-key:<id>", or anonymous
+	ErrInvalidPrincipal = errors.New("principal must be user:<subject>, api-key:<id> or anonymous")
+)
+
+// Binding assigns a role to a principal, the actor a caller authenticates as
+type Binding struct {
+	Principal  string    `json:"principal"`
+	Role       string    `json:"role"`
+	AssignedBy string    `json:"assignedBy"`
+	AssignedAt time.Time `json:"assignedAt"`
+}
+
+// Config configures the roles of principals without a binding
+type Config struct {
+	// DefaultRole is the role of authenticated principals without a binding
+	DefaultRole string
+	// AnonymousRole is the role of callers that did not authenticate, which
+	// only reach protected routes while JWT authentication is disabled.
+	// Empty denies them every protected route.
+	AnonymousRole string
+	// RoleClaims are the token claims, such as "scope" or
+	// "realm_access.roles", whose values ClaimRoles maps to roles. Callers
+	// without an assigned role act with the highest role their token maps
+	// to, or the default role if it maps to none.
+	RoleClaims []string
+	ClaimRoles map[string]string
+}
+
+// Authorizer checks the role of callers against the permissions of routes
+// and manages the roles assigned to principals
+type Authorizer struct {
+	store  Store
+	config Config
+	now    func() time.Time
+}
+
+// NewAuthorizer creates an authorizer over the bindings of store
+func NewAuthorizer(store Store, config Config) *Authorizer {
+	return &Authorizer{
+		store:  store,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Grants reports whether role holds permission
+func Grants(role, permission string) bool {
+	for _, granted := range grants[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodPermission is the permission a route requires unless it declares
+// another with Require: reading for safe methods, deleting for DELETE and
+// writing otherwise
+func MethodPermission(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return PermissionRead
+	case http.MethodDelete:
+		return PermissionDelete
+	default:
+		return PermissionWrite
+	}
+}
+
+// ValidateRole checks that role is a known role
+func ValidateRole(role string) error {
+	if _, ok := grants[role]; !ok {
+		return fmt.Errorf("%w, got %q", ErrInvalidRole, role)
+	}
+	return nil
+}
+
+// ValidatePrincipal checks that principal names an actor callers can
+// authenticate as
+func ValidatePrincipal(principal string) error {
+	if principal == auth.AnonymousActor {
+		return nil
+	}
+	for _, prefix := range []string{"user:", "api-key:"} {
+		if id, ok := strings.CutPrefix(principal, prefix); ok && id != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w, got %q", ErrInvalidPrincipal, principal)
+}
+
+// ParseClaimRoles parses "<claim value>=<role>" entries, such as
+// "catalog:admin=admin", into the ClaimRoles of a Config
+func ParseClaimRoles(entries []string) (map[string]string, error) {
+	claimRoles := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		value, role, ok := strings.Cut(entry, "=")
+		value, role = strings.TrimSpace(value), strings.TrimSpace(role)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid role mapping %q, use value=role", entry)
+		}
+		if err := ValidateRole(role); err != nil {
+			return nil, fmt.Errorf("invalid role mapping %q: %w", entry, err)
+		}
+		claimRoles[value] = role
+	}
+	return claimRoles, nil
+}
+
+// RoleOf returns the role of principal: its binding, or the default role
+// of authenticated or anonymous callers
+func (a *Authorizer) RoleOf(principal string) (string, error) {
+	return a.role(principal, nil)
+}
+
+// role returns the role of principal: its binding, else the highest role
+// the claims of its token map to, else the default role
+func (a *Authorizer) role(principal string, claims *auth.Claims) (string, error) {
+	binding, err := a.store.Get(principal)
+	if err == nil {
+		return binding.Role, nil
+	}
+	if !errors.Is(err, ErrBindingNotFound) {
+		return "", err
+	}
+
+	if claims != nil {
+		if mapped := a.claimRole(claims); mapped != "" {
+			return mapped, nil
+		}
+	}
+	if principal == auth.AnonymousActor {
+		return a.config.AnonymousRole, nil
+	}
+	return a.config.DefaultRole, nil
+}
+
+// Assign gives principal role, replacing its previous role
+func (a *Authorizer) Assign(principal, role, assignedBy string) (*Binding, error) {
+	if err := ValidatePrincipal(principal); err != nil {
+		return nil, err
+	}
+	if err := ValidateRole(role); err != nil {
+		return nil, err
+	}
+
+	binding := &Binding{
+		Principal:  principal,
+		Role:       role,
+		AssignedBy: assignedBy,
+		AssignedAt: a.now().UTC(),
+	}
+	if err := a.store.Put(binding); err != nil {
+		return nil, fmt.Errorf("failed to store role binding: %w", err)
+	}
+	return binding, nil
+}
+
+// Unassign removes the binding of principal, which falls back to the
+// default role
+func (a *Authorizer) Unassign(principal string) error {
+	return a.store.Delete(principal)
+}
+
+// List returns every binding ordered by principal
+func (a *Authorizer) List() ([]*Binding, error) {
+	return a.store.GetAll()
+}
+
+// claimRole returns the highest role the claims of a token map to, or ""
+func (a *Authorizer) claimRole(claims *auth.Claims) string {
+	var role string
+	for _, path := range a.config.RoleClaims {
+		for _, value := range claims.Values(path) {
+			if mapped := a.config.ClaimRoles[value]; ranks[mapped] > ranks[role] {
+				role = mapped
+			}
+		}
+	}
+	return role
+}
+
+// Authorize is a middleware.Authorizer: it resolves the role of the
+// authenticated caller and requires it to hold the permission of the
+// request method. Routes requiring more declare it with Require.
+func (a *Authorizer) Authorize(c *gin.Context) bool {
+	principal := auth.Actor(c.Request.Context())
+	claims, _ := middleware.Claims(c)
+	role, err := a.role(principal, claims)
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to resolve role")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response.ErrorResponse{
+			Error:   "internal_server_error",
+			Message: "Failed to authorize request",
+			Code:    http.StatusInternalServerError,
+		})
+		return false
+	}
+
+	c.Set(roleKey, role)
+	return check(c, principal, role, MethodPermission(c.Request.Method))
+}
+
+// Require middleware declares that a route requires permission beyond that
+// of its method, e.g. deleting for a bulk endpoint that may delete. It must
+// follow the authentication middleware of the route, whose Authorize
+// resolved the role of the caller; requests it did not authorize are
+// refused.
+func Require(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(roleKey)
+		if check(c, auth.Actor(c.Request.Context()), role, permission) {
+			c.Next()
+		}
+	}
+}
+
+// check aborts the request with 403 unless role grants permission
+func check(c *gin.Context, principal, role, permission string) bool {
+	if Grants(role, permission) {
+		return true
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"principal":  principal,
+		"role":       role,
+		"permission": permission,
+		"path":       c.FullPath(),
+	}).Warn("Request denied by role")
+
+	message := "The " + permission + " permission is required"
+	if role != "" {
+		message += ", role " + role + " does not grant it"
+	}
+	c.AbortWithStatusJSON(http.StatusForbidden, response.ErrorResponse{
+		Error:   "forbidden",
+		Message: message,
+		Code:    http.StatusForbidden,
+	})
+	return false
+}
@@ -0,0 +1,280 @@
+package rbac
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/auth"
+	"external-apis/internal/shared/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// actorHeader names the actor of test requests, standing in for the
+// authentication middleware
+const actorHeader = "X-Test-Actor"
+
+// newRouter returns a router whose /products routes are protected by
+// authorizer, with bulk requiring the delete permission
+func newRouter(authorizer *Authorizer) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if actor := c.GetHeader(actorHeader); actor != "" {
+			c.Request = c.Request.WithContext(auth.WithActor(c.Request.Context(), actor))
+		}
+	})
+
+	requireAuth := middleware.NoAuth(authorizer.Authorize)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/products", requireAuth, ok)
+	router.POST("/products", requireAuth, ok)
+	router.DELETE("/products/:id", requireAuth, ok)
+	router.POST("/products/bulk", requireAuth, Require(PermissionDelete), ok)
+	router.POST("/unprotected", Require(PermissionWrite), ok)
+	return router
+}
+
+func TestGrants(t *testing.T) {
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{RoleViewer, PermissionRead, true},
+		{RoleViewer, PermissionWrite, false},
+		{RoleEditor, PermissionWrite, true},
+		{RoleEditor, PermissionDelete, false},
+		{RoleAdmin, PermissionDelete, true},
+		{RoleAdmin, PermissionAdmin, true},
+		{"", PermissionRead, false},
+		{"owner", PermissionRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.permission, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, Grants(tt.role, tt.permission))
+		})
+	}
+}
+
+func TestMethodPermission(t *testing.T) {
+	assert.Equal(t, PermissionRead, MethodPermission(http.MethodGet))
+	assert.Equal(t, PermissionRead, MethodPermission(http.MethodHead))
+	assert.Equal(t, PermissionWrite, MethodPermission(http.MethodPost))
+	assert.Equal(t, PermissionWrite, MethodPermission(http.MethodPatch))
+	assert.Equal(t, PermissionDelete, MethodPermission(http.MethodDelete))
+}
+
+func TestValidatePrincipal(t *testing.T) {
+	for _, principal := range []string{"user:alice", "api-key:key-1", auth.AnonymousActor} {
+		assert.NoError(t, ValidatePrincipal(principal), principal)
+	}
+	for _, principal := range []string{"", "alice", "user:", "group:ops"} {
+		assert.ErrorIs(t, ValidatePrincipal(principal), ErrInvalidPrincipal, principal)
+	}
+}
+
+func TestAuthorizer_RoleOf(t *testing.T) {
+	// Arrange
+	authorizer := NewAuthorizer(NewMemoryStore(), Config{DefaultRole: RoleViewer, AnonymousRole: RoleEditor})
+	_, err := authorizer.Assign("user:alice", RoleAdmin, "config")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		principal string
+		want      string
+	}{
+		{"Assigned role", "user:alice", RoleAdmin},
+		{"Default role", "user:bob", RoleViewer},
+		{"Anonymous role", auth.AnonymousActor, RoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			role, err := authorizer.RoleOf(tt.principal)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, role)
+		})
+	}
+}
+
+func TestAuthorizer_Assign(t *testing.T) {
+	t.Run("Replace the previous role", func(t *testing.T) {
+		// Arrange
+		authorizer := NewAuthorizer(NewMemoryStore(), Config{DefaultRole: RoleViewer})
+		_, err := authorizer.Assign("api-key:key-1", RoleAdmin, "user:alice")
+		require.NoError(t, err)
+
+		// Act
+		binding, err := authorizer.Assign("api-key:key-1", RoleEditor, "user:bob")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "user:bob", binding.AssignedBy)
+		bindings, err := authorizer.List()
+		require.NoError(t, err)
+		require.Len(t, bindings, 1)
+		assert.Equal(t, RoleEditor, bindings[0].Role)
+	})
+
+	t.Run("Reject unknown roles", func(t *testing.T) {
+		// Arrange
+		authorizer := NewAuthorizer(NewMemoryStore(), Config{DefaultRole: RoleViewer})
+
+		// Act
+		_, err := authorizer.Assign("user:alice", "owner", "config")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("Fall back to the default role once unassigned", func(t *testing.T) {
+		// Arrange
+		authorizer := NewAuthorizer(NewMemoryStore(), Config{DefaultRole: RoleViewer})
+		_, err := authorizer.Assign("user:alice", RoleAdmin, "config")
+		require.NoError(t, err)
+
+		// Act
+		err = authorizer.Unassign("user:alice")
+
+		// Assert
+		require.NoError(t, err)
+		role, err := authorizer.RoleOf("user:alice")
+		require.NoError(t, err)
+		assert.Equal(t, RoleViewer, role)
+		assert.ErrorIs(t, authorizer.Unassign("user:alice"), ErrBindingNotFound)
+	})
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	// Arrange
+	authorizer := NewAuthorizer(NewMemoryStore(), Config{DefaultRole: RoleEditor})
+	for principal, role := range map[string]string{"user:viewer": RoleViewer, "user:admin": RoleAdmin} {
+		_, err := authorizer.Assign(principal, role, "config")
+		require.NoError(t, err)
+	}
+	router := newRouter(authorizer)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		actor        string
+		expectedCode int
+	}{
+		{"Viewer reads", http.MethodGet, "/products", "user:viewer", http.StatusOK},
+		{"Viewer cannot write", http.MethodPost, "/products", "user:viewer", http.StatusForbidden},
+		{"Default role writes", http.MethodPost, "/products", "user:editor", http.StatusOK},
+		{"Editor cannot delete", http.MethodDelete, "/products/1", "user:editor", http.StatusForbidden},
+		{"Editor cannot reach routes requiring delete", http.MethodPost, "/products/bulk", "user:editor", http.StatusForbidden},
+		{"Admin deletes", http.MethodDelete, "/products/1", "user:admin", http.StatusOK},
+		{"Admin reaches routes requiring delete", http.MethodPost, "/products/bulk", "user:admin", http.StatusOK},
+		{"Anonymous callers are refused without a role", http.MethodGet, "/products", "", http.StatusForbidden},
+		{"Requests not authorized are refused", http.MethodPost, "/unprotected", "user:admin", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.actor != "" {
+				req.Header.Set(actorHeader, tt.actor)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestParseClaimRoles(t *testing.T) {
+	t.Run("Valid mappings", func(t *testing.T) {
+		// Act
+		claimRoles, err := ParseClaimRoles([]string{"catalog:read=viewer", " catalog-admins = admin "})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"catalog:read": RoleViewer, "catalog-admins": RoleAdmin}, claimRoles)
+	})
+
+	for _, entry := range []string{"catalog:read", "=viewer", "catalog:read=owner"} {
+		t.Run("Invalid "+entry, func(t *testing.T) {
+			// Act
+			_, err := ParseClaimRoles([]string{entry})
+
+			// Assert
+			assert.ErrorContains(t, err, "invalid role mapping")
+		})
+	}
+}
+
+func TestAuthorizer_ClaimRoles(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	validator, err := auth.NewValidator(auth.Config{HMACSecret: secret})
+	require.NoError(t, err)
+	authorizer := NewAuthorizer(NewMemoryStore(), Config{
+		DefaultRole: RoleEditor,
+		RoleClaims:  []string{"scope", "realm_access.roles"},
+		ClaimRoles:  map[string]string{"catalog:read": RoleViewer, "catalog-admins": RoleAdmin},
+	})
+	_, err = authorizer.Assign("user:pinned", RoleViewer, "config")
+	require.NoError(t, err)
+
+	router := gin.New()
+	requireAuth := middleware.JWTAuth(validator, authorizer.Authorize)
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.POST("/products", requireAuth, ok)
+	router.DELETE("/products/:id", requireAuth, ok)
+
+	token := func(subject string, claims jwt.MapClaims) string {
+		claims["sub"] = subject
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		token        string
+		expectedCode int
+	}{
+		{"Highest mapped role", http.MethodDelete, "/products/1", token("alice", jwt.MapClaims{"scope": "catalog:read", "realm_access": map[string]any{"roles": []string{"catalog-admins"}}}), http.StatusNoContent},
+		{"Lower mapped role than the default", http.MethodPost, "/products", token("bob", jwt.MapClaims{"scope": "catalog:read"}), http.StatusForbidden},
+		{"Default role without a mapped claim", http.MethodPost, "/products", token("carol", jwt.MapClaims{"scope": "openid"}), http.StatusNoContent},
+		{"Assigned role over the claims", http.MethodDelete, "/products/1", token("pinned", jwt.MapClaims{"realm_access": map[string]any{"roles": []string{"catalog-admins"}}}), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package rbac
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrBindingNotFound is returned when a principal has no role binding
+var ErrBindingNotFound = errors.New("role binding not found")
+
+// Store persists role bindings
+type Store interface {
+	Get(principal string) (*Binding, error)
+	GetAll() ([]*Binding, error)
+	Put(binding *Binding) error
+	Delete(principal string) error
+}
+
+// MemoryStore keeps role bindings in memory
+type MemoryStore struct {
+	bindings map[string]Binding
+	mutex    sync.RWMutex
+}
+
+// NewMemoryStore creates a new in-memory binding store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{bindings: make(map[string]Binding)}
+}
+
+// Get returns the binding of principal
+func (s *MemoryStore) Get(principal string) (*Binding, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	binding, exists := s.bindings[principal]
+	if !exists {
+		return nil, ErrBindingNotFound
+	}
+	return &binding, nil
+}
+
+// GetAll returns every binding ordered by principal
+func (s *MemoryStore) GetAll() ([]*Binding, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	bindings := make([]*Binding, 0, len(s.bindings))
+	for _, binding := range s.bindings {
+		binding := binding
+		bindings = append(bindings, &binding)
+	}
+
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].Principal < bindings[j].Principal
+	})
+	return bindings, nil
+}
+
+// Put stores binding, replacing the previous binding of its principal
+func (s *MemoryStore) Put(binding *Binding) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.bindings[binding.Principal] = *binding
+	return nil
+}
+
+// Delete removes the binding of principal
+func (s *MemoryStore) Delete(principal string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.bindings[principal]; !exists {
+		return ErrBindingNotFound
+	}
+	delete(s.bindings, principal)
+	return nil
+}
@@ -0,0 +1,156 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FieldsParam is the query parameter selecting the fields of a response,
+// e.g. ?fields=id,name,price
+const FieldsParam = "fields"
+
+// ParseFields reads the comma-separated fields query parameter. Every name
+// must be a top-level JSON field of the response type of sample. It returns
+// nil when the parameter is absent or empty, meaning whole responses.
+func ParseFields(c *gin.Context, sample interface{}) ([]string, error) {
+	value := c.Query(FieldsParam)
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	known := jsonFields(reflect.TypeOf(sample))
+	var fields []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q in %s", name, FieldsParam)
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// Project reduces a response to the given top-level fields, in the order
+// they are listed. data is a struct, a pointer to one or a slice of them;
+// fields left out of an item by omitempty stay left out. With no fields the
+// data is returned as it is.
+func Project(data interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return data
+	}
+	return projection{data: data, fields: fields}
+}
+
+// projection is data whose JSON encoding only keeps some fields
+type projection struct {
+	data   interface{}
+	fields []string
+}
+
+// MarshalJSON implements json.Marshaler
+func (p projection) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal(p.data)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(encoded)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return p.object(trimmed)
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.WriteByte('[')
+	for i, item := range items {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		projected, err := p.object(item)
+		if err != nil {
+			return nil, err
+		}
+		out.Write(projected)
+	}
+	out.WriteByte(']')
+	return out.Bytes(), nil
+}
+
+// object keeps the selected fields of an encoded JSON object
+func (p projection) object(encoded []byte) ([]byte, error) {
+	if bytes.Equal(encoded, []byte("null")) {
+		return encoded, nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &values); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for _, field := range p.fields {
+		value, ok := values[field]
+		if !ok {
+			continue
+		}
+		if out.Len() > 1 {
+			out.WriteByte(',')
+		}
+		name, _ := json.Marshal(field)
+		out.Write(name)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
+// jsonFields returns the top-level JSON field names of a struct type,
+// including those of embedded structs
+func jsonFields(typ reflect.Type) map[string]bool {
+	for typ != nil && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice) {
+		typ = typ.Elem()
+	}
+
+	fields := map[string]bool{}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			for embedded := range jsonFields(field.Type) {
+				fields[embedded] = true
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
@@ -0,0 +1,112 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Price    float64  `json:"price"`
+	Tags     []string `json:"tags,omitempty"`
+	Internal string   `json:"-"`
+	internal string
+}
+
+type testResult struct {
+	testItem
+	Score float64 `json:"score"`
+}
+
+func fieldsContext(query string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items"+query, nil)
+	return c
+}
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		sample  interface{}
+		want    []string
+		wantErr bool
+	}{
+		{name: "Absent", query: "", sample: testItem{}, want: nil},
+		{name: "Empty", query: "?fields=", sample: testItem{}, want: nil},
+		{name: "Listed fields", query: "?fields=id,%20price,id,,name", sample: testItem{}, want: []string{"id", "price", "name"}},
+		{name: "Embedded struct fields", query: "?fields=id,score", sample: testResult{}, want: []string{"id", "score"}},
+		{name: "Unknown field", query: "?fields=id,cost", sample: testItem{}, wantErr: true},
+		{name: "Ignored field", query: "?fields=Internal", sample: testItem{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			c := fieldsContext(tt.query)
+
+			// Act
+			fields, err := ParseFields(c, tt.sample)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, fields)
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	item := &testItem{ID: "item-1", Name: "Widget", Price: 9.5, Tags: []string{"new"}}
+
+	t.Run("Object keeps fields in the requested order", func(t *testing.T) {
+		// Act
+		body, err := json.Marshal(Project(item, []string{"price", "id"}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, `{"price":9.5,"id":"item-1"}`, string(body))
+	})
+
+	t.Run("Slice projects every item", func(t *testing.T) {
+		// Arrange
+		items := []testResult{
+			{testItem: testItem{ID: "item-1", Name: "Widget"}, Score: 2},
+			{testItem: testItem{ID: "item-2", Name: "Gadget", Tags: []string{"sale"}}, Score: 1},
+		}
+
+		// Act
+		body, err := json.Marshal(Project(items, []string{"id", "tags", "score"}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, `[{"id":"item-1","score":2},{"id":"item-2","tags":["sale"],"score":1}]`, string(body))
+	})
+
+	t.Run("Empty slice", func(t *testing.T) {
+		// Act
+		body, err := json.Marshal(Project([]testItem{}, []string{"id"}))
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, `[]`, string(body))
+	})
+
+	t.Run("No fields returns the data as it is", func(t *testing.T) {
+		// Act
+		projected := Project(item, nil)
+
+		// Assert
+		assert.Same(t, item, projected)
+	})
+}
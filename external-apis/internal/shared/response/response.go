@@ -1,25 +1,93 @@
 package response
 
 import (
-	"github.com/gin-gonic/gin"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+
+	"external-apis/internal/shared/pagination"
+	"external-apis/internal/shared/validation"
+	"github.com/gin-gonic/gin"
 )
 
-// ErrorResponse represents an error response
+// ErrorResponse represents an error response. Details lists the rejected
+// fields of an invalid request body.
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+	Error   string                  `json:"error"`
+	Message string                  `json:"message"`
+	Code    int                     `json:"code"`
+	Details []validation.FieldError `json:"details,omitempty"`
 }
 
-// SuccessResponse represents a success response
+// SuccessResponse represents a success response. Clients that opted out of
+// the envelope get the data alone.
 type SuccessResponse struct {
 	Data    interface{} `json:"data"`
 	Message string      `json:"message"`
 	Code    int         `json:"code"`
 }
 
-// JSON sends a JSON response with raw data
+// PagedResponse represents a paginated list response. The message and code
+// are left out for clients that opted out of the envelope.
+type PagedResponse struct {
+	Data       interface{}     `json:"data"`
+	Pagination pagination.Meta `json:"pagination"`
+	Message    string          `json:"message,omitempty"`
+	Code       int             `json:"code,omitempty"`
+}
+
+// EnvelopeHeader lets a client choose the format of the success responses
+// to its request: "true" wraps them in a SuccessResponse, "false" sends the
+// raw data as the API did before the envelope existed
+const EnvelopeHeader = "X-Response-Envelope"
+
+// envelopeKey is the gin context key holding whether the request is answered
+// with enveloped responses
+const envelopeKey = "response_envelope"
+
+// Envelope middleware decides whether the success responses of a request are
+// wrapped in a SuccessResponse: by the EnvelopeHeader of the request when it
+// has one, by enabled otherwise. Responses of routers without it are
+// enveloped.
+func Envelope(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		envelope := enabled
+		if value, err := strconv.ParseBool(c.GetHeader(EnvelopeHeader)); err == nil {
+			envelope = value
+		}
+
+		c.Set(envelopeKey, envelope)
+		c.Writer.Header().Add("Vary", EnvelopeHeader)
+		c.Next()
+	}
+}
+
+// Enveloped reports whether the success responses of the request are wrapped
+// in a SuccessResponse
+func Enveloped(c *gin.Context) bool {
+	envelope, ok := c.Get(envelopeKey)
+	if !ok {
+		return true
+	}
+	return envelope.(bool)
+}
+
+// Body returns the body of a success response with the given status and data,
+// enveloped or raw as the request asks
+func Body(c *gin.Context, code int, data interface{}) interface{} {
+	if !Enveloped(c) {
+		return data
+	}
+	return SuccessResponse{
+		Data:    data,
+		Message: http.StatusText(code),
+		Code:    code,
+	}
+}
+
+// JSON sends a JSON response with raw data, never enveloped. Success
+// responses of the API go through Success instead.
 func JSON(c *gin.Context, code int, data interface{}) {
 	c.JSON(code, data)
 }
@@ -38,6 +106,41 @@ func BadRequest(c *gin.Context, message string) {
 	Error(c, http.StatusBadRequest, "bad_request", message)
 }
 
+// InvalidRequest sends a 400 Bad Request response for a request body that
+// could not be bound, with the rejected fields in Details where the error
+// concerns particular fields. A body cut off by the body size limit gets 413
+// Payload Too Large instead.
+func InvalidRequest(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		PayloadTooLarge(c, fmt.Sprintf("Request body exceeds the limit of %d bytes", tooLarge.Limit))
+		return
+	}
+
+	details := validation.Translate(err)
+	if len(details) == 0 {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "bad_request",
+		Message: "Request validation failed",
+		Code:    http.StatusBadRequest,
+		Details: details,
+	})
+}
+
+// PayloadTooLarge sends a 413 Payload Too Large response
+func PayloadTooLarge(c *gin.Context, message string) {
+	Error(c, http.StatusRequestEntityTooLarge, "payload_too_large", message)
+}
+
+// UnsupportedMediaType sends a 415 Unsupported Media Type response
+func UnsupportedMediaType(c *gin.Context, message string) {
+	Error(c, http.StatusUnsupportedMediaType, "unsupported_media_type", message)
+}
+
 // NotFound sends a 404 Not Found response
 func NotFound(c *gin.Context, message string) {
 	Error(c, http.StatusNotFound, "not_found", message)
@@ -53,12 +156,65 @@ func Conflict(c *gin.Context, message string) {
 	Error(c, http.StatusConflict, "conflict", message)
 }
 
+// PreconditionFailed sends a 412 Precondition Failed response
+func PreconditionFailed(c *gin.Context, message string) {
+	Error(c, http.StatusPreconditionFailed, "precondition_failed", message)
+}
+
+// UnprocessableEntity sends a 422 Unprocessable Entity response
+func UnprocessableEntity(c *gin.Context, message string) {
+	Error(c, http.StatusUnprocessableEntity, "unprocessable_entity", message)
+}
+
+// BadGateway sends a 502 Bad Gateway response
+func BadGateway(c *gin.Context, message string) {
+	Error(c, http.StatusBadGateway, "bad_gateway", message)
+}
+
+// GatewayTimeout sends a 504 Gateway Timeout response
+func GatewayTimeout(c *gin.Context, message string) {
+	Error(c, http.StatusGatewayTimeout, "deadline_exceeded", message)
+}
+
+// Success sends a success response with the given status and data
+func Success(c *gin.Context, code int, data interface{}) {
+	c.JSON(code, Body(c, code, data))
+}
+
 // Created sends a 201 Created response
 func Created(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusCreated, data)
+	Success(c, http.StatusCreated, data)
 }
 
 // OK sends a 200 OK response
 func OK(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, data)
+	Success(c, http.StatusOK, data)
+}
+
+// Accepted sends a 202 Accepted response
+func Accepted(c *gin.Context, data interface{}) {
+	Success(c, http.StatusAccepted, data)
+}
+
+// Message sends a success response carrying only a message, e.g. after a
+// delete. Without the envelope the body is {"message": message}.
+func Message(c *gin.Context, code int, message string) {
+	if !Enveloped(c) {
+		c.JSON(code, gin.H{"message": message})
+		return
+	}
+	c.JSON(code, SuccessResponse{Message: message, Code: code})
+}
+
+// Paged sends a 200 OK response with a page of data and its pagination metadata
+func Paged(c *gin.Context, data interface{}, meta pagination.Meta) {
+	body := PagedResponse{
+		Data:       data,
+		Pagination: meta,
+	}
+	if Enveloped(c) {
+		body.Message = http.StatusText(http.StatusOK)
+		body.Code = http.StatusOK
+	}
+	c.JSON(http.StatusOK, body)
 }
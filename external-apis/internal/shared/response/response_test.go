@@ -0,0 +1,111 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"external-apis/internal/shared/pagination"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve answers a request with the given envelope header through a router
+// whose services envelope responses by default when enabled is set
+func serve(enabled bool, header string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Envelope(enabled))
+	router.GET("/items", handler)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	if header != "" {
+		req.Header.Set(EnvelopeHeader, header)
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestEnvelope(t *testing.T) {
+	item := testItem{ID: "item-1", Name: "Mouse", Price: 29.99}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		header  string
+		handler gin.HandlerFunc
+		want    string
+	}{
+		{
+			name:    "Envelope the data",
+			enabled: true,
+			handler: func(c *gin.Context) { OK(c, item) },
+			want:    `{"data":{"id":"item-1","name":"Mouse","price":29.99},"message":"OK","code":200}`,
+		},
+		{
+			name:    "Send the raw data to clients opting out",
+			enabled: true,
+			header:  "false",
+			handler: func(c *gin.Context) { Created(c, item) },
+			want:    `{"id":"item-1","name":"Mouse","price":29.99}`,
+		},
+		{
+			name:    "Send the raw data when the service does not envelope",
+			handler: func(c *gin.Context) { OK(c, item) },
+			want:    `{"id":"item-1","name":"Mouse","price":29.99}`,
+		},
+		{
+			name:    "Envelope for clients opting in",
+			header:  "true",
+			handler: func(c *gin.Context) { Accepted(c, item) },
+			want:    `{"data":{"id":"item-1","name":"Mouse","price":29.99},"message":"Accepted","code":202}`,
+		},
+		{
+			name:    "Envelope a message",
+			enabled: true,
+			handler: func(c *gin.Context) { Message(c, http.StatusOK, "Item deleted successfully") },
+			want:    `{"data":null,"message":"Item deleted successfully","code":200}`,
+		},
+		{
+			name:    "Send a raw message",
+			handler: func(c *gin.Context) { Message(c, http.StatusOK, "Item deleted successfully") },
+			want:    `{"message":"Item deleted successfully"}`,
+		},
+		{
+			name:    "Envelope a page",
+			enabled: true,
+			handler: func(c *gin.Context) { Paged(c, []testItem{item}, pagination.Meta{Total: 1, Limit: 50}) },
+			want:    `{"data":[{"id":"item-1","name":"Mouse","price":29.99}],"pagination":{"total":1,"limit":50,"offset":0},"message":"OK","code":200}`,
+		},
+		{
+			name:    "Send a raw page",
+			handler: func(c *gin.Context) { Paged(c, []testItem{item}, pagination.Meta{Total: 1, Limit: 50}) },
+			want:    `{"data":[{"id":"item-1","name":"Mouse","price":29.99}],"pagination":{"total":1,"limit":50,"offset":0}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			recorder := serve(tt.enabled, tt.header, tt.handler)
+
+			// Assert
+			assert.JSONEq(t, tt.want, recorder.Body.String())
+			assert.Contains(t, recorder.Header().Values("Vary"), EnvelopeHeader)
+		})
+	}
+
+	t.Run("Envelope without the middleware", func(t *testing.T) {
+		// Arrange
+		gin.SetMode(gin.TestMode)
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+
+		// Act
+		OK(c, item)
+
+		// Assert
+		assert.JSONEq(t, `{"data":{"id":"item-1","name":"Mouse","price":29.99},"message":"OK","code":200}`, recorder.Body.String())
+	})
+}
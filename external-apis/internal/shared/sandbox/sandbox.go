@@ -0,0 +1,125 @@
+package sandbox
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+var log = logger.New("shared/sandbox")
+
+// ExpiresHeader tells clients when a sandbox write will be purged
+const ExpiresHeader = "X-Sandbox-Expires-At"
+
+// Purgeable is implemented by repositories that can roll back to their seed data
+type Purgeable interface {
+	// PurgeExpired reverts every entity written before cutoff to its seed
+	// state (or removes it when it was not seeded) and returns how many were reverted
+	PurgeExpired(cutoff time.Time) int
+	// Reset restores the full seed data set
+	Reset()
+}
+
+// Config configures sandbox mode
+type Config struct {
+	Enabled       bool
+	TTL           time.Duration
+	PurgeInterval time.Duration
+}
+
+// Sandbox periodically purges expired writes from the registered repositories
+type Sandbox struct {
+	config  Config
+	targets map[string]Purgeable
+}
+
+// New creates a new sandbox for the given repositories keyed by name
+func New(config Config, targets map[string]Purgeable) *Sandbox {
+	return &Sandbox{
+		config:  config,
+		targets: targets,
+	}
+}
+
+// Enabled reports whether sandbox mode is active
+func (s *Sandbox) Enabled() bool {
+	return s.config.Enabled
+}
+
+// Start schedules the periodic purge on the job manager
+func (s *Sandbox) Start(manager *jobs.Manager) {
+	if !s.config.Enabled {
+		return
+	}
+
+	log.WithFields(logger.Fields{
+		"ttl":            s.config.TTL,
+		"purge_interval": s.config.PurgeInterval,
+	}).Warn("Sandbox mode enabled, writes will expire")
+
+	manager.Schedule("sandbox-purge", jobs.Every(s.config.PurgeInterval), func(ctx context.Context) error {
+		s.Purge(time.Now())
+		return nil
+	})
+}
+
+// Purge reverts writes older than the configured TTL
+func (s *Sandbox) Purge(now time.Time) int {
+	cutoff := now.Add(-s.config.TTL)
+
+	total := 0
+	for name, target := range s.targets {
+		purged := target.PurgeExpired(cutoff)
+		if purged > 0 {
+			log.WithFields(logger.Fields{
+				"repository": name,
+				"purged":     purged,
+			}).Info("Purged expired sandbox writes")
+		}
+		total += purged
+	}
+
+	return total
+}
+
+// Reset restores the seed data in every registered repository
+func (s *Sandbox) Reset() {
+	for name, target := range s.targets {
+		target.Reset()
+		log.WithField("repository", name).Info("Sandbox repository reset to seed data")
+	}
+}
+
+// Middleware tags every mutating response with the time its write expires
+func (s *Sandbox) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.config.Enabled && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			expiresAt := time.Now().Add(s.config.TTL).UTC()
+			c.Header(ExpiresHeader, expiresAt.Format(time.RFC3339))
+		}
+		c.Next()
+	}
+}
+
+// RegisterRoutes registers the sandbox admin routes
+func (s *Sandbox) RegisterRoutes(router *gin.RouterGroup) {
+	if !s.config.Enabled {
+		return
+	}
+
+	router.POST("/sandbox/reset", s.handleReset)
+}
+
+// handleReset restores all repositories to their seed data
+func (s *Sandbox) handleReset(c *gin.Context) {
+	log.Ctx(c.Request.Context()).Info("Resetting sandbox")
+
+	s.Reset()
+
+	response.Message(c, http.StatusOK, "Sandbox reset to seed data")
+}
@@ -0,0 +1,96 @@
+package sandbox
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePurgeable struct {
+	cutoff time.Time
+	purged int
+	resets int
+}
+
+func (f *fakePurgeable) PurgeExpired(cutoff time.Time) int {
+	f.cutoff = cutoff
+	return f.purged
+}
+
+func (f *fakePurgeable) Reset() {
+	f.resets++
+}
+
+func TestSandbox_Purge(t *testing.T) {
+	// Arrange
+	target := &fakePurgeable{purged: 3}
+	sb := New(Config{Enabled: true, TTL: time.Hour}, map[string]Purgeable{"test": target})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Act
+	purged := sb.Purge(now)
+
+	// Assert
+	assert.Equal(t, 3, purged)
+	assert.Equal(t, now.Add(-time.Hour), target.cutoff)
+}
+
+func TestSandbox_ResetEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Reset when enabled", func(t *testing.T) {
+		// Arrange
+		target := &fakePurgeable{}
+		sb := New(Config{Enabled: true, TTL: time.Hour}, map[string]Purgeable{"test": target})
+		router := gin.New()
+		sb.RegisterRoutes(router.Group("/admin"))
+
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/sandbox/reset", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, target.resets)
+	})
+
+	t.Run("Not registered when disabled", func(t *testing.T) {
+		// Arrange
+		target := &fakePurgeable{}
+		sb := New(Config{}, map[string]Purgeable{"test": target})
+		router := gin.New()
+		sb.RegisterRoutes(router.Group("/admin"))
+
+		// Act
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/sandbox/reset", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, 0, target.resets)
+	})
+}
+
+func TestSandbox_MiddlewareTagsWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Arrange
+	sb := New(Config{Enabled: true, TTL: time.Hour}, nil)
+	router := gin.New()
+	router.Use(sb.Middleware())
+	router.Any("/items", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Act
+	write := httptest.NewRecorder()
+	router.ServeHTTP(write, httptest.NewRequest(http.MethodPost, "/items", nil))
+	read := httptest.NewRecorder()
+	router.ServeHTTP(read, httptest.NewRequest(http.MethodGet, "/items", nil))
+
+	// Assert
+	assert.NotEmpty(t, write.Header().Get(ExpiresHeader))
+	assert.Empty(t, read.Header().Get(ExpiresHeader))
+}
@@ -0,0 +1,222 @@
+// Package schemaregistry registers and looks up schemas in a registry
+// speaking the REST API of Confluent Schema Registry, and frames serialized
+// values with the ID of their schema in the Confluent wire format, so
+// consumers fetch the schema a value was written with to read it.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/deadline"
+)
+
+// Schema types
+const (
+	TypeAvro     = "AVRO"
+	TypeProtobuf = "PROTOBUF"
+)
+
+// contentType is the media type of the registry API
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// magicByte opens every value in the wire format
+const magicByte = 0
+
+var (
+	// ErrUnavailable is returned when the registry cannot be reached or
+	// answers unexpectedly
+	ErrUnavailable = errors.New("schema registry unavailable")
+	// ErrNotFound is returned for a schema ID the registry does not know
+	ErrNotFound = errors.New("schema not found")
+	// ErrRejected is returned for a schema the registry refuses, because it
+	// is invalid or breaks the compatibility rules of its subject
+	ErrRejected = errors.New("schema rejected")
+	// ErrInvalidFrame is returned for values not in the wire format
+	ErrInvalidFrame = errors.New("value is not framed with a schema ID")
+)
+
+// Schema is a schema as kept by the registry. Type is empty for Avro.
+type Schema struct {
+	Type   string `json:"schemaType,omitempty"`
+	Schema string `json:"schema"`
+}
+
+// Registry registers schemas under subjects and looks them up by ID
+type Registry interface {
+	// Register registers schema under subject, unless it already is, and
+	// returns its ID
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+	// Schema returns the schema with an ID
+	Schema(ctx context.Context, id int) (Schema, error)
+}
+
+// Config configures the client
+type Config struct {
+	URL string
+	// Username and Password authenticate with basic auth when set
+	Username string
+	Password string
+	Timeout  time.Duration
+}
+
+// Client is a Registry over HTTP. Schemas never change once registered, so
+// the IDs it registered and the schemas it fetched are cached.
+type Client struct {
+	config     Config
+	httpClient *http.Client
+
+	mutex   sync.RWMutex
+	ids     map[string]int
+	schemas map[int]Schema
+}
+
+// NewClient creates a client of the registry at config.URL, sending its
+// requests through transport, or the default transport if it is nil
+func NewClient(config Config, transport http.RoundTripper) *Client {
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: deadline.NewTransport(transport),
+		},
+		ids:     make(map[string]int),
+		schemas: make(map[int]Schema),
+	}
+}
+
+// Register implements Registry. Registering a schema the subject already
+// has returns its existing ID.
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	key := subject + "\x00" + schema.Type + "\x00" + schema.Schema
+	c.mutex.RLock()
+	id, ok := c.ids[key]
+	c.mutex.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	body, err := json.Marshal(schema)
+	if err != nil {
+		return 0, err
+	}
+	var registered struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", body, &registered); err != nil {
+		return 0, fmt.Errorf("registering schema under %s: %w", subject, err)
+	}
+
+	c.mutex.Lock()
+	c.ids[key] = registered.ID
+	c.schemas[registered.ID] = schema
+	c.mutex.Unlock()
+	return registered.ID, nil
+}
+
+// Schema implements Registry
+func (c *Client) Schema(ctx context.Context, id int) (Schema, error) {
+	c.mutex.RLock()
+	schema, ok := c.schemas[id]
+	c.mutex.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &schema); err != nil {
+		return Schema{}, fmt.Errorf("fetching schema %d: %w", id, err)
+	}
+
+	c.mutex.Lock()
+	c.schemas[id] = schema
+	c.mutex.Unlock()
+	return schema, nil
+}
+
+// Ping checks that the registry answers
+func (c *Client) Ping(ctx context.Context) error {
+	var subjects []string
+	return c.do(ctx, http.MethodGet, "/subjects", nil, &subjects)
+}
+
+// do sends a request to the registry and decodes its answer into result
+func (c *Client) do(ctx context.Context, method, path string, body []byte, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", ErrNotFound, failure.Message)
+		case http.StatusConflict, http.StatusUnprocessableEntity:
+			return fmt.Errorf("%w: %s", ErrRejected, failure.Message)
+		default:
+			return fmt.Errorf("%w: unexpected status %d", ErrUnavailable, resp.StatusCode)
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: invalid response body: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+// Subject names the subject of a record published to a topic, following
+// the topic record name strategy: a topic carries several event types, each
+// with the schema of its own record, e.g. product-events-events.v1.Product
+func Subject(topic, record string) string {
+	return topic + "-" + record
+}
+
+// Frame prefixes a serialized value with the magic byte and the ID of its
+// schema, the Confluent wire format
+func Frame(id int, payload []byte) []byte {
+	value := make([]byte, 5, 5+len(payload))
+	value[0] = magicByte
+	binary.BigEndian.PutUint32(value[1:5], uint32(id))
+	return append(value, payload...)
+}
+
+// Unframe splits a value in the wire format into the ID of its schema and
+// the serialized value
+func Unframe(value []byte) (int, []byte, error) {
+	if len(value) < 5 || value[0] != magicByte {
+		return 0, nil, ErrInvalidFrame
+	}
+	return int(binary.BigEndian.Uint32(value[1:5])), value[5:], nil
+}
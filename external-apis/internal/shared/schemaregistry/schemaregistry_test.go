@@ -0,0 +1,179 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry answers like a registry holding one schema, ID 7, under the
+// subject product-events-events.v1.Product
+func fakeRegistry(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", contentType)
+
+		if username, password, ok := r.BasicAuth(); !ok || username != "data" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error_code":401,"message":"Unauthorized"}`))
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/subjects/product-events-events.v1.Product/versions":
+			var schema Schema
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&schema))
+			require.Equal(t, contentType, r.Header.Get("Content-Type"))
+			if strings.Contains(schema.Schema, "breaking") {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"error_code":409,"message":"Schema being registered is incompatible with an earlier schema"}`))
+				return
+			}
+			w.Write([]byte(`{"id":7}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/schemas/ids/7":
+			w.Write([]byte(`{"schemaType":"PROTOBUF","schema":"syntax = \"proto3\";"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/subjects":
+			w.Write([]byte(`["product-events-events.v1.Product"]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Register(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Register once and cache the ID", func(t *testing.T) {
+		// Arrange
+		var requests atomic.Int32
+		client := NewClient(Config{URL: fakeRegistry(t, &requests).URL + "/", Username: "data", Password: "secret", Timeout: time.Second}, nil)
+		schema := Schema{Type: TypeAvro, Schema: `{"type":"record","name":"Product","fields":[]}`}
+
+		// Act
+		first, err := client.Register(ctx, "product-events-events.v1.Product", schema)
+		require.NoError(t, err)
+		second, err := client.Register(ctx, "product-events-events.v1.Product", schema)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, 7, first)
+		assert.Equal(t, 7, second)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Incompatible schema", func(t *testing.T) {
+		// Arrange
+		var requests atomic.Int32
+		client := NewClient(Config{URL: fakeRegistry(t, &requests).URL, Username: "data", Password: "secret", Timeout: time.Second}, nil)
+
+		// Act
+		_, err := client.Register(ctx, "product-events-events.v1.Product", Schema{Schema: "breaking"})
+
+		// Assert
+		require.ErrorIs(t, err, ErrRejected)
+		assert.Contains(t, err.Error(), "incompatible with an earlier schema")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		// Arrange
+		var requests atomic.Int32
+		client := NewClient(Config{URL: fakeRegistry(t, &requests).URL, Timeout: time.Second}, nil)
+
+		// Act
+		_, err := client.Register(ctx, "product-events-events.v1.Product", Schema{Schema: "{}"})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("Registry down", func(t *testing.T) {
+		// Arrange
+		var requests atomic.Int32
+		server := fakeRegistry(t, &requests)
+		server.Close()
+		client := NewClient(Config{URL: server.URL, Timeout: time.Second}, nil)
+
+		// Act
+		_, err := client.Register(ctx, "product-events-events.v1.Product", Schema{Schema: "{}"})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
+
+func TestClient_Schema(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	var requests atomic.Int32
+	client := NewClient(Config{URL: fakeRegistry(t, &requests).URL, Username: "data", Password: "secret", Timeout: time.Second}, nil)
+
+	t.Run("Fetch once and cache the schema", func(t *testing.T) {
+		// Act
+		first, err := client.Schema(ctx, 7)
+		require.NoError(t, err)
+		second, err := client.Schema(ctx, 7)
+		require.NoError(t, err)
+
+		// Assert
+		assert.Equal(t, Schema{Type: TypeProtobuf, Schema: `syntax = "proto3";`}, first)
+		assert.Equal(t, first, second)
+		assert.Equal(t, int32(1), requests.Load())
+	})
+
+	t.Run("Unknown ID", func(t *testing.T) {
+		// Act
+		_, err := client.Schema(ctx, 8)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestClient_Ping(t *testing.T) {
+	// Arrange
+	var requests atomic.Int32
+	client := NewClient(Config{URL: fakeRegistry(t, &requests).URL, Username: "data", Password: "secret", Timeout: time.Second}, nil)
+
+	// Act & Assert
+	assert.NoError(t, client.Ping(context.Background()))
+}
+
+func TestFrame(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		// Arrange
+		value := Frame(258, []byte("payload"))
+
+		// Act
+		id, payload, err := Unframe(value)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0, 0, 0, 1, 2}, value[:5])
+		assert.Equal(t, 258, id)
+		assert.Equal(t, []byte("payload"), payload)
+	})
+
+	t.Run("Value without a schema ID", func(t *testing.T) {
+		// Act
+		_, _, err := Unframe([]byte(`{"id":"event-1"}`))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrInvalidFrame)
+	})
+}
+
+func TestSubject(t *testing.T) {
+	assert.Equal(t, "customer-events-events.v1.Customer", Subject("customer-events", "events.v1.Customer"))
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Field is a piece of document text whose term matches are scaled by Weight,
+// e.g. so a match in a name outranks a match in a description
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Hit is a document matching a query together with its relevance score
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index is an in-memory inverted index that ranks documents by TF-IDF.
+// It is not safe for concurrent use; callers guard it with their own lock.
+type Index struct {
+	// postings maps a term to the weighted frequency of the term in each document
+	postings map[string]map[string]float64
+	// terms maps a document to the terms it was indexed under, for removal
+	terms map[string][]string
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]float64),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Tokenize lower-cases text and splits it into terms on every character that
+// is not a letter or digit
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Add indexes a document, replacing any previous version with the same ID
+func (i *Index) Add(id string, fields ...Field) {
+	i.Remove(id)
+
+	frequencies := make(map[string]float64)
+	for _, field := range fields {
+		for _, term := range Tokenize(field.Text) {
+			frequencies[term] += field.Weight
+		}
+	}
+	if len(frequencies) == 0 {
+		return
+	}
+
+	terms := make([]string, 0, len(frequencies))
+	for term, frequency := range frequencies {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[string]float64)
+		}
+		i.postings[term][id] = frequency
+		terms = append(terms, term)
+	}
+	i.terms[id] = terms
+}
+
+// Remove drops a document from the index
+func (i *Index) Remove(id string) {
+	for _, term := range i.terms[id] {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.terms, id)
+}
+
+// Len returns the number of indexed documents
+func (i *Index) Len() int {
+	return len(i.terms)
+}
+
+// Search returns every document containing at least one query term, best
+// match first. A document scores the sum of weighted term frequency times
+// inverse document frequency over the matched terms, scaled by the share of
+// query terms it matches so documents matching all terms rank first.
+func (i *Index) Search(query string) []Hit {
+	terms := unique(Tokenize(query))
+	if len(terms) == 0 || len(i.terms) == 0 {
+		return []Hit{}
+	}
+
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+	documents := float64(len(i.terms))
+	for _, term := range terms {
+		postings := i.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		idf := 1 + math.Log(documents/float64(len(postings)))
+		for id, frequency := range postings {
+			scores[id] += frequency * idf
+			matched[id]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		coverage := float64(matched[id]) / float64(len(terms))
+		hits = append(hits, Hit{ID: id, Score: score * coverage})
+	}
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ID < hits[b].ID
+	})
+
+	return hits
+}
+
+// unique removes duplicate terms, keeping the first occurrence
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := terms[:0]
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "Lower-case words", text: "Wireless Mouse", expected: []string{"wireless", "mouse"}},
+		{name: "Punctuation", text: "USB-C hub, with HDMI!", expected: []string{"usb", "c", "hub", "with", "hdmi"}},
+		{name: "Digits", text: "27-inch 4K monitor", expected: []string{"27", "inch", "4k", "monitor"}},
+		{name: "Non-ASCII letters", text: "Café Crème", expected: []string{"café", "crème"}},
+		{name: "Only separators", text: " - , ", expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := Tokenize(tt.text)
+
+			// Assert
+			assert.ElementsMatch(t, tt.expected, result)
+		})
+	}
+}
+
+func newTestIndex() *Index {
+	index := NewIndex()
+	index.Add("mouse", Field{Text: "Wireless Mouse", Weight: 3}, Field{Text: "Ergonomic wireless mouse", Weight: 1})
+	index.Add("headphones", Field{Text: "Bluetooth Headphones", Weight: 3}, Field{Text: "Noise-cancelling wireless headphones", Weight: 1})
+	index.Add("keyboard", Field{Text: "Mechanical Keyboard", Weight: 3}, Field{Text: "RGB mechanical keyboard", Weight: 1})
+	return index
+}
+
+func TestIndex_Search(t *testing.T) {
+	t.Run("Rank name matches above description matches", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		hits := index.Search("wireless")
+
+		// Assert
+		require.Len(t, hits, 2)
+		assert.Equal(t, "mouse", hits[0].ID)
+		assert.Equal(t, "headphones", hits[1].ID)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("Rank documents matching every term first", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		hits := index.Search("wireless headphones")
+
+		// Assert
+		require.Len(t, hits, 2)
+		assert.Equal(t, "headphones", hits[0].ID)
+	})
+
+	t.Run("Match case-insensitively", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		hits := index.Search("KEYBOARD")
+
+		// Assert
+		require.Len(t, hits, 1)
+		assert.Equal(t, "keyboard", hits[0].ID)
+	})
+
+	t.Run("Return no hits for unknown terms", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		hits := index.Search("laptop")
+
+		// Assert
+		assert.Empty(t, hits)
+	})
+
+	t.Run("Return no hits for an empty query", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		hits := index.Search("  ")
+
+		// Assert
+		assert.Empty(t, hits)
+	})
+}
+
+func TestIndex_AddAndRemove(t *testing.T) {
+	t.Run("Replace a document on re-add", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		index.Add("mouse", Field{Text: "Trackball", Weight: 3})
+
+		// Assert
+		assert.Equal(t, 3, index.Len())
+		assert.Empty(t, index.Search("ergonomic"))
+		require.Len(t, index.Search("trackball"), 1)
+	})
+
+	t.Run("Remove a document", func(t *testing.T) {
+		// Arrange
+		index := newTestIndex()
+
+		// Act
+		index.Remove("keyboard")
+
+		// Assert
+		assert.Equal(t, 2, index.Len())
+		assert.Empty(t, index.Search("keyboard"))
+		assert.Empty(t, index.postings["mechanical"])
+	})
+}
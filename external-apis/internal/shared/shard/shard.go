@@ -0,0 +1,22 @@
+package shard
+
+// Count is the number of shards the memory repositories spread their records
+// over. Writes to records in different shards do not wait for each other.
+const Count = 32
+
+// FNV-1a parameters of the 32-bit hash
+const (
+	offset32 = 2166136261
+	prime32  = 16777619
+)
+
+// Of returns the shard of a record key in [0, Count). Keys are hashed with
+// FNV-1a so sequential IDs spread evenly, without allocating per call.
+func Of(key string) int {
+	hash := uint32(offset32)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= prime32
+	}
+	return int(hash % Count)
+}
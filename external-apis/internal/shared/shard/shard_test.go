@@ -0,0 +1,41 @@
+package shard
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	t.Run("Match FNV-1a", func(t *testing.T) {
+		for _, key := range []string{"", "product-789", "customer-001", "7c9e6679-7425-40de-944b-e07fc1f90ae7"} {
+			// Arrange
+			hash := fnv.New32a()
+			_, _ = hash.Write([]byte(key))
+
+			// Act
+			index := Of(key)
+
+			// Assert
+			assert.Equal(t, int(hash.Sum32()%Count), index, key)
+		}
+	})
+
+	t.Run("Spread sequential keys over every shard", func(t *testing.T) {
+		// Arrange
+		used := make(map[int]int)
+
+		// Act
+		for i := 0; i < 1000; i++ {
+			used[Of(fmt.Sprintf("order-%d", i))]++
+		}
+
+		// Assert
+		assert.Len(t, used, Count)
+		for index, keys := range used {
+			assert.Less(t, keys, 100, "shard %d", index)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/shutdown")
+
+// Hook releases a resource on shutdown. It should return once the resource is
+// closed or the context is done, whichever comes first.
+type Hook func(ctx context.Context) error
+
+// Closer adapts a resource without a context-aware close method
+func Closer(closer io.Closer) Hook {
+	return func(ctx context.Context) error {
+		return closer.Close()
+	}
+}
+
+type namedHook struct {
+	name string
+	hook Hook
+}
+
+// Coordinator runs the registered hooks when the process stops. Like deferred
+// calls they run in reverse registration order, so servers registered last
+// stop accepting work before the resources they depend on are closed.
+type Coordinator struct {
+	timeout time.Duration
+
+	mutex sync.Mutex
+	hooks []namedHook
+	once  sync.Once
+	err   error
+}
+
+// New creates a coordinator that gives all hooks together at most timeout to
+// complete
+func New(timeout time.Duration) *Coordinator {
+	return &Coordinator{timeout: timeout}
+}
+
+// Add registers a hook
+func (c *Coordinator) Add(name string, hook Hook) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.hooks = append(c.hooks, namedHook{name: name, hook: hook})
+}
+
+// Shutdown runs every hook once, even if earlier hooks fail or the deadline
+// has passed, and returns the joined errors. Later calls return the result of
+// the first.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.once.Do(func() {
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		c.mutex.Lock()
+		hooks := append([]namedHook(nil), c.hooks...)
+		c.mutex.Unlock()
+
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			h := hooks[i]
+			start := time.Now()
+			if err := h.hook(ctx); err != nil {
+				log.WithError(err).WithField("hook", h.name).Warn("Shutdown hook failed")
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+				continue
+			}
+			log.WithFields(logger.Fields{
+				"hook":     h.name,
+				"duration": time.Since(start),
+			}).Debug("Shutdown hook completed")
+		}
+		c.err = errors.Join(errs...)
+	})
+
+	return c.err
+}
+
+// Wait blocks until the process receives SIGINT or SIGTERM, then shuts down
+func (c *Coordinator) Wait() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	<-signals
+	log.Info("Received shutdown signal, shutting down gracefully...")
+
+	return c.Shutdown(context.Background())
+}
+
+// Remaining returns the time left before the context's deadline, or fallback
+// if it has none. Hooks wrapping timeout-based APIs use it to share the
+// shutdown budget.
+func Remaining(ctx context.Context, fallback time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return fallback
+	}
+	if remaining := time.Until(deadline); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCloser struct {
+	closed int
+	err    error
+}
+
+func (f *fakeCloser) Close() error {
+	f.closed++
+	return f.err
+}
+
+func TestCoordinator_Shutdown(t *testing.T) {
+	t.Run("Runs hooks in reverse registration order", func(t *testing.T) {
+		// Arrange
+		coordinator := New(time.Second)
+		var order []string
+		for _, name := range []string{"kafka", "jobs", "http"} {
+			name := name
+			coordinator.Add(name, func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			})
+		}
+
+		// Act
+		err := coordinator.Shutdown(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []string{"http", "jobs", "kafka"}, order)
+	})
+
+	t.Run("Keeps going after a failing hook", func(t *testing.T) {
+		// Arrange
+		coordinator := New(time.Second)
+		redis := &fakeCloser{}
+		coordinator.Add("redis", Closer(redis))
+		coordinator.Add("http", func(ctx context.Context) error {
+			return errors.New("connections still open")
+		})
+
+		// Act
+		err := coordinator.Shutdown(context.Background())
+
+		// Assert
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "http: connections still open")
+		assert.Equal(t, 1, redis.closed)
+	})
+
+	t.Run("Hooks share the shutdown deadline", func(t *testing.T) {
+		// Arrange
+		coordinator := New(50 * time.Millisecond)
+		var remaining time.Duration
+		coordinator.Add("jobs", func(ctx context.Context) error {
+			remaining = Remaining(ctx, time.Hour)
+			return nil
+		})
+		coordinator.Add("http", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		// Act
+		err := coordinator.Shutdown(context.Background())
+
+		// Assert
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, time.Duration(0), remaining)
+	})
+
+	t.Run("Runs hooks only once", func(t *testing.T) {
+		// Arrange
+		coordinator := New(time.Second)
+		closer := &fakeCloser{err: errors.New("already closed")}
+		coordinator.Add("kafka", Closer(closer))
+
+		// Act
+		first := coordinator.Shutdown(context.Background())
+		second := coordinator.Shutdown(context.Background())
+
+		// Assert
+		assert.Equal(t, 1, closer.closed)
+		assert.Equal(t, first, second)
+	})
+}
+
+func TestRemaining(t *testing.T) {
+	t.Run("Without deadline", func(t *testing.T) {
+		// Act & Assert
+		assert.Equal(t, time.Minute, Remaining(context.Background(), time.Minute))
+	})
+
+	t.Run("With deadline", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		// Act
+		remaining := Remaining(ctx, time.Hour)
+
+		// Assert
+		assert.Greater(t, remaining, 59*time.Second)
+		assert.LessOrEqual(t, remaining, time.Minute)
+	})
+}
@@ -0,0 +1,121 @@
+// Package storage stores uploaded media such as product images. Objects are
+// addressed by slash-separated keys and served from a public URL, either by
+// the service itself (Local) or by an S3-compatible bucket (S3).
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey is returned for keys that are empty or escape the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage stores objects under keys
+type Storage interface {
+	// Put stores data under key, replacing any existing object
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Delete removes the object stored under key. Deleting a missing object
+	// is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the object stored under key
+	URL(key string) string
+}
+
+// Local implements Storage on the local file system. The service serves the
+// directory itself under baseURL.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocal creates a local storage rooted at dir, creating the directory if
+// needed. Objects are served from baseURL.
+func NewLocal(dir, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Local{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Dir returns the directory the objects are stored in
+func (s *Local) Dir() string {
+	return s.dir
+}
+
+// Put writes data to the file of key. The data is written to a temporary file
+// first so readers never see a partial object.
+func (s *Local) Put(ctx context.Context, key, contentType string, data []byte) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// Delete removes the file of key
+func (s *Local) Delete(ctx context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL returns the URL the file of key is served from
+func (s *Local) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path maps a key to its file, rejecting keys outside the storage directory
+func (s *Local) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// validKey checks that key is a clean relative path
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && key != "." && key != ".." && !strings.HasPrefix(key, "../")
+}
@@ -0,0 +1,330 @@
+// Package store holds the records of the memory repositories. A Store keeps
+// records by ID in shards, lists them in ID order, files them in unique
+// indexes and remembers the seed data sandbox mode reverts them to, so a
+// repository only adds what is particular to its entity.
+package store
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/shard"
+)
+
+// Store holds records of type T, a pointer to an entity, by the ID the
+// record reports. It stores the records it is given and returns the stored
+// ones, so repositories clone a record before they change it or hand it
+// out.
+//
+// Records are spread over shards by ID, each with its own lock, so record
+// level writes to records in different shards run in parallel. What spans
+// shards, the sorted IDs and the unique indexes, is guarded by the lock of
+// the repository using the store:
+//
+//   - Get and Update lock the shard of the record only and need no
+//     repository lock. Update may not change the unique keys of a record.
+//   - All and Lookup need the repository lock for reading.
+//   - Every other method changing the store needs the repository lock for
+//     writing.
+type Store[T any] struct {
+	shards [shard.Count]*recordShard[T]
+	// ids lists the IDs of the records in ascending order so listings need
+	// not sort them per request
+	ids     []string
+	seed    map[string]T
+	uniques map[*Unique[T]]map[string]string
+	id      func(record T) string
+	clone   func(record T) T
+}
+
+// recordShard holds the records whose IDs hash to it and when they were
+// last written
+type recordShard[T any] struct {
+	records map[string]T
+	touched map[string]time.Time
+	mutex   sync.RWMutex
+}
+
+// Unique is a unique index of a store: it maps the keys a record is filed
+// under to the ID of the record, so uniqueness checks and lookups by key
+// need not scan the records. The same Unique can index any number of
+// stores.
+type Unique[T any] struct {
+	keys func(record T) []string
+}
+
+// NewUnique creates a unique index filing each record under the keys
+// returned for it. A record returning no keys, such as a deleted one that
+// gives up its email, is left out.
+func NewUnique[T any](keys func(record T) []string) *Unique[T] {
+	return &Unique[T]{keys: keys}
+}
+
+// New creates an empty store of records identified by id and copied by
+// clone, maintaining the given unique indexes
+func New[T any](id func(record T) string, clone func(record T) T, uniques ...*Unique[T]) *Store[T] {
+	s := &Store[T]{
+		seed:    make(map[string]T),
+		uniques: make(map[*Unique[T]]map[string]string, len(uniques)),
+		id:      id,
+		clone:   clone,
+	}
+	for i := range s.shards {
+		s.shards[i] = &recordShard[T]{
+			records: make(map[string]T),
+			touched: make(map[string]time.Time),
+		}
+	}
+	for _, u := range uniques {
+		s.uniques[u] = make(map[string]string)
+	}
+	return s
+}
+
+// Get returns the record stored under id under the read lock of its shard
+func (s *Store[T]) Get(id string) (T, bool) {
+	sh := s.shardOf(id)
+	sh.mutex.RLock()
+	defer sh.mutex.RUnlock()
+
+	record, exists := sh.records[id]
+	return record, exists
+}
+
+// Len returns the number of records
+func (s *Store[T]) Len() int {
+	return len(s.ids)
+}
+
+// All iterates over the records in ascending ID order, reading each under
+// the read lock of its shard
+func (s *Store[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, id := range s.ids {
+			if record, exists := s.Get(id); exists && !yield(record) {
+				return
+			}
+		}
+	}
+}
+
+// Lookup returns the ID of the record filed under key in a unique index
+func (s *Store[T]) Lookup(u *Unique[T], key string) (string, bool) {
+	id, exists := s.uniques[u][key]
+	return id, exists
+}
+
+// Put stores a written record in place of the one with the same ID, files
+// it in the unique indexes and records when it was written
+func (s *Store[T]) Put(record T) {
+	s.store(record, true)
+}
+
+// Load stores a record like Put without recording a write, so the record
+// counts as part of the data the store was recovered from rather than as a
+// sandbox write
+func (s *Store[T]) Load(record T) {
+	s.store(record, false)
+}
+
+// Seed stores records as the seed data that Reset and PurgeExpired revert
+// to. The store keeps copies of them.
+func (s *Store[T]) Seed(records ...T) {
+	for _, record := range records {
+		s.seed[s.id(record)] = s.clone(record)
+		s.store(record, false)
+	}
+}
+
+// Update replaces the record stored under id by the one apply returns,
+// under the lock of its shard only, and records when it was written. apply
+// is passed the stored record, or false if there is none, and the record is
+// left alone if it fails. It may not change the unique keys of the record
+// nor look at other records.
+func (s *Store[T]) Update(id string, apply func(current T, exists bool) (T, error)) (T, error) {
+	sh := s.shardOf(id)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	current, exists := sh.records[id]
+	record, err := apply(current, exists)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	sh.records[id] = record
+	sh.touched[id] = time.Now()
+	return record, nil
+}
+
+// Remove drops the record stored under id
+func (s *Store[T]) Remove(id string) {
+	sh := s.shardOf(id)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	previous, exists := sh.records[id]
+	if !exists {
+		return
+	}
+	s.unindex(previous)
+	delete(sh.records, id)
+	delete(sh.touched, id)
+	if i, found := slices.BinarySearch(s.ids, id); found {
+		s.ids = slices.Delete(s.ids, i, i+1)
+	}
+}
+
+// PurgeExpired reverts the records written before cutoff to the record
+// revert makes of their seed version, removing records that are not part
+// of the seed data, and returns how many it purged
+func (s *Store[T]) PurgeExpired(cutoff time.Time, revert func(seeded, current T) T) int {
+	var expired []string
+	for _, sh := range s.shards {
+		sh.mutex.RLock()
+		for id, writtenAt := range sh.touched {
+			if writtenAt.Before(cutoff) {
+				expired = append(expired, id)
+			}
+		}
+		sh.mutex.RUnlock()
+	}
+
+	for _, id := range expired {
+		seeded, exists := s.seed[id]
+		if !exists {
+			s.Remove(id)
+			continue
+		}
+		current, _ := s.Get(id)
+		s.store(revert(seeded, current), false)
+		s.untouch(id)
+	}
+	return len(expired)
+}
+
+// Reset restores the store to the records revert makes of the seed data.
+// current is the zero value of T if the seeded record was purged.
+func (s *Store[T]) Reset(revert func(seeded, current T) T) {
+	records := make([]T, 0, len(s.seed))
+	for id, seeded := range s.seed {
+		current, _ := s.Get(id)
+		records = append(records, revert(seeded, current))
+	}
+
+	for _, sh := range s.shards {
+		sh.mutex.Lock()
+		sh.records = make(map[string]T)
+		sh.touched = make(map[string]time.Time)
+		sh.mutex.Unlock()
+	}
+	s.ids = nil
+	for u := range s.uniques {
+		s.uniques[u] = make(map[string]string)
+	}
+	for _, record := range records {
+		s.store(record, false)
+	}
+}
+
+// Clone returns a copy of the store, records included, that can be changed
+// independently, such as by a transaction. The seed data is shared.
+func (s *Store[T]) Clone() *Store[T] {
+	c := &Store[T]{
+		ids:     slices.Clone(s.ids),
+		seed:    s.seed,
+		uniques: make(map[*Unique[T]]map[string]string, len(s.uniques)),
+		id:      s.id,
+		clone:   s.clone,
+	}
+	for i, sh := range s.shards {
+		sh.mutex.RLock()
+		c.shards[i] = &recordShard[T]{
+			records: make(map[string]T, len(sh.records)),
+			touched: maps.Clone(sh.touched),
+		}
+		for id, record := range sh.records {
+			c.shards[i].records[id] = s.clone(record)
+		}
+		sh.mutex.RUnlock()
+	}
+	for u, keys := range s.uniques {
+		c.uniques[u] = maps.Clone(keys)
+	}
+	return c
+}
+
+// Commit replaces the contents of the store by those of c, a clone of it
+// that has been written to
+func (s *Store[T]) Commit(c *Store[T]) {
+	for i, sh := range s.shards {
+		sh.mutex.Lock()
+		sh.records = c.shards[i].records
+		sh.touched = c.shards[i].touched
+		sh.mutex.Unlock()
+	}
+	s.ids = c.ids
+	s.uniques = c.uniques
+}
+
+// store stores a record under the lock of its shard, re-indexing it and
+// adding its ID, and records the write if touch is set
+func (s *Store[T]) store(record T, touch bool) {
+	id := s.id(record)
+	sh := s.shardOf(id)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	previous, exists := sh.records[id]
+	if exists {
+		s.unindex(previous)
+	} else if i, found := slices.BinarySearch(s.ids, id); !found {
+		s.ids = slices.Insert(s.ids, i, id)
+	}
+	s.index(record)
+	sh.records[id] = record
+	if touch {
+		sh.touched[id] = time.Now()
+	}
+}
+
+// untouch forgets when the record stored under id was written
+func (s *Store[T]) untouch(id string) {
+	sh := s.shardOf(id)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	delete(sh.touched, id)
+}
+
+// index files a record in the unique indexes
+func (s *Store[T]) index(record T) {
+	id := s.id(record)
+	for u, keys := range s.uniques {
+		for _, key := range u.keys(record) {
+			keys[key] = id
+		}
+	}
+}
+
+// unindex drops the entries of a record from the unique indexes, leaving
+// keys another record has since been filed under
+func (s *Store[T]) unindex(record T) {
+	id := s.id(record)
+	for u, keys := range s.uniques {
+		for _, key := range u.keys(record) {
+			if keys[key] == id {
+				delete(keys, key)
+			}
+		}
+	}
+}
+
+// shardOf returns the shard holding the record with the ID
+func (s *Store[T]) shardOf(id string) *recordShard[T] {
+	return s.shards[shard.Of(id)]
+}
@@ -0,0 +1,282 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// item is the record type of the test stores
+type item struct {
+	ID      string
+	Name    string
+	Deleted bool
+}
+
+// names indexes the items that are not deleted by name
+var names = NewUnique(func(i *item) []string {
+	if i.Deleted {
+		return nil
+	}
+	return []string{i.Name}
+})
+
+// newTestStore creates a store of items indexed by name
+func newTestStore() *Store[*item] {
+	return New(
+		func(i *item) string { return i.ID },
+		func(i *item) *item { c := *i; return &c },
+		names,
+	)
+}
+
+// ids lists the IDs of the items of s in the order All yields them
+func ids(s *Store[*item]) []string {
+	var ids []string
+	for i := range s.All() {
+		ids = append(ids, i.ID)
+	}
+	return ids
+}
+
+// renamed reverts a seeded item, marking the copy so tests can tell it apart
+func renamed(seeded, current *item) *item {
+	reverted := *seeded
+	reverted.Name += " (reverted)"
+	return &reverted
+}
+
+func TestStore_Put(t *testing.T) {
+	t.Run("List records in ID order", func(t *testing.T) {
+		// Arrange
+		s := newTestStore()
+
+		// Act
+		s.Put(&item{ID: "c", Name: "Carol"})
+		s.Put(&item{ID: "a", Name: "Ann"})
+		s.Put(&item{ID: "b", Name: "Bob"})
+		s.Put(&item{ID: "a", Name: "Anna"})
+
+		// Assert
+		assert.Equal(t, []string{"a", "b", "c"}, ids(s))
+		assert.Equal(t, 3, s.Len())
+		stored, exists := s.Get("a")
+		require.True(t, exists)
+		assert.Equal(t, "Anna", stored.Name)
+	})
+
+	t.Run("Follow the keys of a record", func(t *testing.T) {
+		// Arrange
+		s := newTestStore()
+		s.Put(&item{ID: "a", Name: "Ann"})
+
+		// Act
+		s.Put(&item{ID: "a", Name: "Anna"})
+
+		// Assert
+		_, exists := s.Lookup(names, "Ann")
+		assert.False(t, exists)
+		id, exists := s.Lookup(names, "Anna")
+		assert.True(t, exists)
+		assert.Equal(t, "a", id)
+	})
+
+	t.Run("Leave out records without keys", func(t *testing.T) {
+		// Arrange
+		s := newTestStore()
+		s.Put(&item{ID: "a", Name: "Ann"})
+
+		// Act
+		s.Put(&item{ID: "a", Name: "Ann", Deleted: true})
+
+		// Assert
+		_, exists := s.Lookup(names, "Ann")
+		assert.False(t, exists)
+	})
+
+	t.Run("Keep a key another record took", func(t *testing.T) {
+		// Arrange
+		s := newTestStore()
+		s.Put(&item{ID: "a", Name: "Ann"})
+		s.Load(&item{ID: "b", Name: "Ann"})
+
+		// Act
+		s.Put(&item{ID: "a", Name: "Anna"})
+
+		// Assert
+		id, exists := s.Lookup(names, "Ann")
+		assert.True(t, exists)
+		assert.Equal(t, "b", id)
+	})
+}
+
+func TestStore_All(t *testing.T) {
+	// Arrange
+	s := newTestStore()
+	for _, id := range []string{"c", "a", "b"} {
+		s.Put(&item{ID: id, Name: id})
+	}
+
+	// Act
+	var seen []string
+	for i := range s.All() {
+		seen = append(seen, i.ID)
+		if len(seen) == 2 {
+			break
+		}
+	}
+
+	// Assert
+	assert.Equal(t, []string{"a", "b"}, seen)
+}
+
+func TestStore_Update(t *testing.T) {
+	// Arrange
+	s := newTestStore()
+	s.Put(&item{ID: "a", Name: "Ann"})
+	errRejected := errors.New("rejected")
+
+	t.Run("Store the updated record", func(t *testing.T) {
+		// Act
+		updated, err := s.Update("a", func(current *item, exists bool) (*item, error) {
+			require.True(t, exists)
+			return &item{ID: current.ID, Name: current.Name, Deleted: true}, nil
+		})
+
+		// Assert
+		require.NoError(t, err)
+		stored, _ := s.Get("a")
+		assert.Same(t, updated, stored)
+	})
+
+	t.Run("Leave the record alone when apply fails", func(t *testing.T) {
+		// Arrange
+		before, _ := s.Get("a")
+
+		// Act
+		_, err := s.Update("a", func(current *item, exists bool) (*item, error) {
+			return nil, errRejected
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, errRejected)
+		after, _ := s.Get("a")
+		assert.Same(t, before, after)
+	})
+
+	t.Run("Pass missing records as such", func(t *testing.T) {
+		// Act
+		_, err := s.Update("missing", func(current *item, exists bool) (*item, error) {
+			assert.False(t, exists)
+			assert.Nil(t, current)
+			return nil, errRejected
+		})
+
+		// Assert
+		assert.ErrorIs(t, err, errRejected)
+	})
+}
+
+func TestStore_Remove(t *testing.T) {
+	// Arrange
+	s := newTestStore()
+	s.Put(&item{ID: "a", Name: "Ann"})
+	s.Put(&item{ID: "b", Name: "Bob"})
+
+	// Act
+	s.Remove("a")
+	s.Remove("missing")
+
+	// Assert
+	assert.Equal(t, []string{"b"}, ids(s))
+	_, exists := s.Get("a")
+	assert.False(t, exists)
+	_, exists = s.Lookup(names, "Ann")
+	assert.False(t, exists)
+}
+
+func TestStore_PurgeExpired(t *testing.T) {
+	// Arrange
+	s := newTestStore()
+	s.Seed(&item{ID: "a", Name: "Ann"}, &item{ID: "b", Name: "Bob"})
+	s.Put(&item{ID: "a", Name: "Anna"})
+	s.Put(&item{ID: "c", Name: "Carol"})
+	s.Load(&item{ID: "d", Name: "Dave"})
+	cutoff := time.Now().Add(time.Second)
+
+	// Act
+	purged := s.PurgeExpired(cutoff, renamed)
+
+	// Assert
+	assert.Equal(t, 2, purged)
+	assert.Equal(t, []string{"a", "b", "d"}, ids(s))
+	reverted, _ := s.Get("a")
+	assert.Equal(t, "Ann (reverted)", reverted.Name)
+	untouched, _ := s.Get("b")
+	assert.Equal(t, "Bob", untouched.Name)
+	_, exists := s.Lookup(names, "Carol")
+	assert.False(t, exists)
+	assert.Zero(t, s.PurgeExpired(cutoff, renamed), "purged records count as unwritten")
+}
+
+func TestStore_Reset(t *testing.T) {
+	// Arrange
+	s := newTestStore()
+	seeded := &item{ID: "a", Name: "Ann"}
+	s.Seed(seeded)
+	seeded.Name = "changed after seeding"
+	s.Put(&item{ID: "a", Name: "Anna"})
+	s.Put(&item{ID: "b", Name: "Bob"})
+
+	// Act
+	s.Reset(renamed)
+
+	// Assert
+	assert.Equal(t, []string{"a"}, ids(s))
+	id, exists := s.Lookup(names, "Ann (reverted)")
+	assert.True(t, exists)
+	assert.Equal(t, "a", id)
+	_, exists = s.Lookup(names, "Bob")
+	assert.False(t, exists)
+	assert.Zero(t, s.PurgeExpired(time.Now().Add(time.Second), renamed))
+}
+
+func TestStore_Clone(t *testing.T) {
+	// Arrange
+	s := newTestStore()
+	s.Put(&item{ID: "a", Name: "Ann"})
+
+	t.Run("Write to the clone only", func(t *testing.T) {
+		// Act
+		c := s.Clone()
+		c.Put(&item{ID: "b", Name: "Bob"})
+		stored, _ := c.Get("a")
+		stored.Name = "changed in the clone"
+
+		// Assert
+		assert.Equal(t, []string{"a"}, ids(s))
+		original, _ := s.Get("a")
+		assert.Equal(t, "Ann", original.Name)
+		_, exists := s.Lookup(names, "Bob")
+		assert.False(t, exists)
+	})
+
+	t.Run("Commit the writes to the clone", func(t *testing.T) {
+		// Arrange
+		c := s.Clone()
+		c.Put(&item{ID: "b", Name: "Bob"})
+		c.Remove("a")
+
+		// Act
+		s.Commit(c)
+
+		// Assert
+		assert.Equal(t, []string{"b"}, ids(s))
+		id, exists := s.Lookup(names, "Bob")
+		assert.True(t, exists)
+		assert.Equal(t, "b", id)
+	})
+}
@@ -0,0 +1,120 @@
+package tenant
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// purgeable is implemented by stores that support sandbox mode. It mirrors
+// sandbox.Purgeable, which this package cannot import because the logger
+// reads the tenant from the context.
+type purgeable interface {
+	PurgeExpired(cutoff time.Time) int
+	Reset()
+}
+
+// Lister is implemented by stores that can list the tenants they hold data
+// of, so background jobs can run for each of them
+type Lister interface {
+	Tenants() []string
+}
+
+// Partitions keeps a separate store per tenant, created on its first write.
+// Memory repositories are partitioned with it so tenants never share a map,
+// which makes leaking an entity across tenants impossible rather than merely
+// filtered out. Reads never create a store, so naming made-up tenants in
+// requests cannot grow the partitions.
+type Partitions[T any] struct {
+	create func(tenant string) T
+	parts  map[string]T
+	mutex  sync.Mutex
+}
+
+// NewPartitions creates partitions whose stores are built by create
+func NewPartitions[T any](create func(tenant string) T) *Partitions[T] {
+	return &Partitions[T]{
+		create: create,
+		parts:  make(map[string]T),
+	}
+}
+
+// For returns the store of the tenant of ctx, creating it if needed. It is
+// meant for writes; reads go through View.
+func (p *Partitions[T]) For(ctx context.Context) T {
+	return p.Get(FromContext(ctx))
+}
+
+// View returns the store of the tenant of ctx for reading. Tenants without a
+// store read from an empty one that is not kept, so they find nothing. The
+// default tenant owns the seed data and gets its store as with For.
+func (p *Partitions[T]) View(ctx context.Context) T {
+	id := FromContext(ctx)
+	if id == Default {
+		return p.Get(id)
+	}
+	if part, exists := p.Lookup(id); exists {
+		return part
+	}
+	return p.create(id)
+}
+
+// Lookup returns the store of a tenant, reporting false when it has none
+func (p *Partitions[T]) Lookup(tenant string) (T, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	part, exists := p.parts[tenant]
+	return part, exists
+}
+
+// Get returns the store of a tenant, creating it if needed
+func (p *Partitions[T]) Get(tenant string) T {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	part, exists := p.parts[tenant]
+	if !exists {
+		part = p.create(tenant)
+		p.parts[tenant] = part
+	}
+	return part
+}
+
+// Tenants returns the tenants that have a store, sorted
+func (p *Partitions[T]) Tenants() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	tenants := make([]string, 0, len(p.parts))
+	for tenant := range p.parts {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return tenants
+}
+
+// PurgeExpired purges the expired writes of every tenant whose store
+// supports sandbox mode
+func (p *Partitions[T]) PurgeExpired(cutoff time.Time) int {
+	purged := 0
+	for _, tenant := range p.Tenants() {
+		part, _ := p.Lookup(tenant)
+		if part, ok := any(part).(purgeable); ok {
+			purged += part.PurgeExpired(cutoff)
+		}
+	}
+	return purged
+}
+
+// Reset restores the seed data of every tenant whose store supports sandbox
+// mode
+func (p *Partitions[T]) Reset() {
+	for _, tenant := range p.Tenants() {
+		part, _ := p.Lookup(tenant)
+		if part, ok := any(part).(purgeable); ok {
+			part.Reset()
+		}
+	}
+}
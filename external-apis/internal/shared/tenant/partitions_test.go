@@ -0,0 +1,74 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore counts the purges and resets it receives
+type fakeStore struct {
+	tenant string
+	purged int
+	resets int
+}
+
+func (s *fakeStore) PurgeExpired(cutoff time.Time) int {
+	s.purged++
+	return 1
+}
+
+func (s *fakeStore) Reset() {
+	s.resets++
+}
+
+func TestPartitions(t *testing.T) {
+	// Arrange
+	created := 0
+	partitions := NewPartitions(func(id string) *fakeStore {
+		created++
+		return &fakeStore{tenant: id}
+	})
+	brandA := WithTenant(context.Background(), "brand-a")
+
+	// Act
+	first := partitions.For(brandA)
+	second := partitions.For(brandA)
+	fallback := partitions.For(context.Background())
+
+	// Assert
+	assert.Same(t, first, second)
+	assert.Equal(t, "brand-a", first.tenant)
+	assert.Equal(t, Default, fallback.tenant)
+	assert.Equal(t, 2, created)
+	assert.Equal(t, []string{"brand-a", Default}, partitions.Tenants())
+
+	assert.Equal(t, 2, partitions.PurgeExpired(time.Now()))
+	partitions.Reset()
+	assert.Equal(t, 1, first.resets)
+	assert.Equal(t, 1, fallback.resets)
+}
+
+func TestPartitions_View(t *testing.T) {
+	// Arrange
+	partitions := NewPartitions(func(id string) *fakeStore {
+		return &fakeStore{tenant: id}
+	})
+	brandA := WithTenant(context.Background(), "brand-a")
+
+	// Act
+	unknown := partitions.View(brandA)
+	fallback := partitions.View(context.Background())
+
+	// Assert
+	assert.Equal(t, "brand-a", unknown.tenant)
+	assert.Equal(t, []string{Default}, partitions.Tenants(), "reading a tenant does not create its store")
+	assert.Same(t, fallback, partitions.View(context.Background()))
+
+	written := partitions.For(brandA)
+	assert.Same(t, written, partitions.View(brandA))
+	_, exists := partitions.Lookup("brand-b")
+	assert.False(t, exists)
+}
@@ -0,0 +1,109 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Header selects the tenant a request is served for
+const Header = "X-Tenant-ID"
+
+// Default is the tenant of requests that do not name one. It owns the seed data.
+const Default = "default"
+
+var (
+	// ErrTenantRequired is returned when a request names no tenant and one is required
+	ErrTenantRequired = errors.New("tenant is required")
+	// ErrInvalidTenant is returned for tenant IDs that are not lower-case slugs
+	ErrInvalidTenant = errors.New("tenant must be 1-63 lower-case letters, digits or hyphens")
+	// ErrUnknownTenant is returned for tenants that are not in the allowed list
+	ErrUnknownTenant = errors.New("unknown tenant")
+)
+
+// idPattern matches valid tenant IDs
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// tenantKey is the context key holding the tenant of a request
+type tenantKey struct{}
+
+// Config configures how the tenant of a request is resolved
+type Config struct {
+	// Required rejects requests without a tenant instead of serving them for Default
+	Required bool
+	// Allowed lists the tenants that may be served. Any valid ID is accepted
+	// when it is empty; every tenant gets its own storage on its first write.
+	Allowed []string
+}
+
+// Resolve returns the tenant named by a header value
+func (c Config) Resolve(value string) (string, error) {
+	id := strings.TrimSpace(value)
+	if id == "" {
+		if c.Required {
+			return "", ErrTenantRequired
+		}
+		id = Default
+	}
+
+	if !idPattern.MatchString(id) {
+		return "", ErrInvalidTenant
+	}
+	if len(c.Allowed) > 0 && id != Default && !slices.Contains(c.Allowed, id) {
+		return "", ErrUnknownTenant
+	}
+	return id, nil
+}
+
+// WithTenant returns a copy of ctx scoped to the tenant
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns the tenant stored by WithTenant, or Default
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+// Lookup returns the tenant carried by ctx, reporting false for contexts
+// that were never scoped to a tenant, such as background jobs
+func Lookup(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Inject writes the tenant of ctx into the headers of an outbound call, so
+// the called service serves it for the same tenant
+func Inject(ctx context.Context, header http.Header) {
+	header.Set(Header, FromContext(ctx))
+}
+
+// Middleware scopes the request context to the tenant named by the
+// X-Tenant-ID header. Repositories read it from the context, so every
+// handler below only sees data of that tenant.
+func Middleware(config Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := config.Resolve(c.GetHeader(Header))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, response.ErrorResponse{
+				Error:   "bad_request",
+				Message: "Invalid " + Header + " header: " + err.Error(),
+				Code:    http.StatusBadRequest,
+			})
+			return
+		}
+
+		c.Set("tenant_id", id)
+		c.Request = c.Request.WithContext(WithTenant(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,105 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_Resolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		value  string
+		want   string
+		err    error
+	}{
+		{name: "Named tenant", value: "brand-a", want: "brand-a"},
+		{name: "Surrounding spaces are ignored", value: " brand-a ", want: "brand-a"},
+		{name: "Missing tenant falls back to default", value: "", want: Default},
+		{name: "Missing tenant when required", config: Config{Required: true}, value: "", err: ErrTenantRequired},
+		{name: "Upper-case tenant", value: "Brand-A", err: ErrInvalidTenant},
+		{name: "Tenant with a separator", value: "brand:a", err: ErrInvalidTenant},
+		{name: "Allowed tenant", config: Config{Allowed: []string{"brand-a"}}, value: "brand-a", want: "brand-a"},
+		{name: "Tenant outside the allowed list", config: Config{Allowed: []string{"brand-a"}}, value: "brand-b", err: ErrUnknownTenant},
+		{name: "Default is always allowed", config: Config{Allowed: []string{"brand-a"}}, value: "", want: Default},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			id, err := tt.config.Resolve(tt.value)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, "brand-a", FromContext(WithTenant(context.Background(), "brand-a")))
+	assert.Equal(t, Default, FromContext(context.Background()))
+}
+
+func TestMiddleware(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/whoami", Middleware(Config{Allowed: []string{"brand-a"}}), func(c *gin.Context) {
+		c.String(http.StatusOK, FromContext(c.Request.Context()))
+	})
+
+	serve := func(value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		if value != "" {
+			req.Header.Set(Header, value)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("Scope the request to the tenant", func(t *testing.T) {
+		// Act
+		rec := serve("brand-a")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "brand-a", rec.Body.String())
+	})
+
+	t.Run("Serve the default tenant without header", func(t *testing.T) {
+		// Act
+		rec := serve("")
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, Default, rec.Body.String())
+	})
+
+	t.Run("Reject an unknown tenant", func(t *testing.T) {
+		// Act
+		rec := serve("brand-b")
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unknown tenant")
+	})
+}
+
+func TestInject(t *testing.T) {
+	// Arrange
+	header := http.Header{}
+
+	// Act
+	Inject(WithTenant(context.Background(), "brand-a"), header)
+
+	// Assert
+	assert.Equal(t, "brand-a", header.Get(Header))
+}
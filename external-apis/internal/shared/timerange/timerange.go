@@ -0,0 +1,59 @@
+package timerange
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Range selects the times in [Since, Before). A zero bound leaves its side
+// open, so a Range with only Since gives "changed since" semantics.
+type Range struct {
+	Since  time.Time
+	Before time.Time
+}
+
+// IsZero reports whether the range has no bounds and so selects every time
+func (r Range) IsZero() bool {
+	return r.Since.IsZero() && r.Before.IsZero()
+}
+
+// Contains checks if t falls within the range
+func (r Range) Contains(t time.Time) bool {
+	if !r.Since.IsZero() && t.Before(r.Since) {
+		return false
+	}
+	if !r.Before.IsZero() && !t.Before(r.Before) {
+		return false
+	}
+	return true
+}
+
+// FromQuery parses the <name>_since and <name>_before query parameters, e.g.
+// updated_since and updated_before, as RFC 3339 timestamps
+func FromQuery(c *gin.Context, name string) (Range, error) {
+	var r Range
+
+	if value := c.Query(name + "_since"); value != "" {
+		since, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Range{}, fmt.Errorf("%s_since must be an RFC 3339 timestamp", name)
+		}
+		r.Since = since
+	}
+
+	if value := c.Query(name + "_before"); value != "" {
+		before, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return Range{}, fmt.Errorf("%s_before must be an RFC 3339 timestamp", name)
+		}
+		r.Before = before
+	}
+
+	if !r.Since.IsZero() && !r.Before.IsZero() && !r.Since.Before(r.Before) {
+		return Range{}, fmt.Errorf("%s_since must be before %s_before", name, name)
+	}
+
+	return r, nil
+}
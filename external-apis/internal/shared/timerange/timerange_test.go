@@ -0,0 +1,78 @@
+package timerange
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContext(rawQuery string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/items?"+rawQuery, nil)
+	return c
+}
+
+func TestFromQuery(t *testing.T) {
+	t.Run("No bounds", func(t *testing.T) {
+		r, err := FromQuery(newContext(""), "updated")
+
+		require.NoError(t, err)
+		assert.True(t, r.IsZero())
+	})
+
+	t.Run("Both bounds", func(t *testing.T) {
+		r, err := FromQuery(newContext("updated_since=2024-01-15T10:00:00Z&updated_before=2024-01-16T10:00:00%2B02:00"), "updated")
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), r.Since)
+		assert.True(t, r.Before.Equal(time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("Only the parameters of the name", func(t *testing.T) {
+		r, err := FromQuery(newContext("updated_since=2024-01-15T10:00:00Z"), "created")
+
+		require.NoError(t, err)
+		assert.True(t, r.IsZero())
+	})
+
+	t.Run("Invalid timestamp", func(t *testing.T) {
+		_, err := FromQuery(newContext("created_before=yesterday"), "created")
+
+		assert.EqualError(t, err, "created_before must be an RFC 3339 timestamp")
+	})
+
+	t.Run("Empty range", func(t *testing.T) {
+		_, err := FromQuery(newContext("updated_since=2024-01-15T10:00:00Z&updated_before=2024-01-15T10:00:00Z"), "updated")
+
+		assert.EqualError(t, err, "updated_since must be before updated_before")
+	})
+}
+
+func TestRange_Contains(t *testing.T) {
+	since := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	before := since.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		r    Range
+		t    time.Time
+		want bool
+	}{
+		{"Unbounded", Range{}, since, true},
+		{"Since is inclusive", Range{Since: since}, since, true},
+		{"Earlier than since", Range{Since: since}, since.Add(-time.Second), false},
+		{"Before is exclusive", Range{Before: before}, before, false},
+		{"Earlier than before", Range{Before: before}, before.Add(-time.Second), true},
+		{"Within both bounds", Range{Since: since, Before: before}, since.Add(time.Minute), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.r.Contains(tt.t))
+		})
+	}
+}
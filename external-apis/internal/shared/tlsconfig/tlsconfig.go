@@ -0,0 +1,229 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/tlsconfig")
+
+// Client certificate modes of servers
+const (
+	// ClientAuthNone serves clients without asking for a certificate
+	ClientAuthNone = "none"
+	// ClientAuthVerify verifies the certificates clients present but still
+	// serves clients without one, so public and service-to-service traffic
+	// can share a port
+	ClientAuthVerify = "verify"
+	// ClientAuthRequire refuses clients without a valid certificate (mTLS)
+	ClientAuthRequire = "require"
+)
+
+// clientAuthTypes maps the client certificate modes to their TLS setting
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	ClientAuthNone:    tls.NoClientCert,
+	ClientAuthVerify:  tls.VerifyClientCertIfGiven,
+	ClientAuthRequire: tls.RequireAndVerifyClientCert,
+}
+
+// Config names the PEM files TLS is set up from
+type Config struct {
+	// CertFile and KeyFile are the certificate chain and private key this
+	// service presents, as a server and as a client of other services
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the CAs client certificates must be signed by; it
+	// is required unless ClientAuth is ClientAuthNone
+	ClientCAFile string
+	// ClientAuth is ClientAuthNone, ClientAuthVerify or ClientAuthRequire
+	ClientAuth string
+	// CAFile holds the CAs the certificates of called services must be
+	// signed by; the system roots are used when it is empty
+	CAFile string
+}
+
+// material is the certificate and CAs loaded from the files at one time
+type material struct {
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	rootCAs     *x509.CertPool
+	// digest identifies the contents of the files they were loaded from
+	digest []byte
+}
+
+// Reloader serves the certificate and CAs loaded from the configured files
+// to TLS servers and clients. Reload picks up rotated files: connections
+// established afterwards use them, existing ones keep their certificate.
+type Reloader struct {
+	config     Config
+	clientAuth tls.ClientAuthType
+	current    atomic.Pointer[material]
+	// mutex serializes reloads
+	mutex sync.Mutex
+}
+
+// New loads the files named by config, failing if any is missing or invalid
+func New(config Config) (*Reloader, error) {
+	if config.ClientAuth == "" {
+		config.ClientAuth = ClientAuthNone
+	}
+	clientAuth, ok := clientAuthTypes[config.ClientAuth]
+	if !ok {
+		return nil, fmt.Errorf("unknown client auth %q", config.ClientAuth)
+	}
+	if clientAuth != tls.NoClientCert && config.ClientCAFile == "" {
+		return nil, errors.New("verifying client certificates requires a client CA file")
+	}
+
+	r := &Reloader{config: config, clientAuth: clientAuth}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the files again and, if their contents changed, serves what
+// they now hold. Files that fail to load, e.g. a certificate replaced before
+// its key, leave the previous certificate in use until a later reload
+// succeeds. It reports whether anything changed.
+func (r *Reloader) Reload() (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	files, digest, err := r.read()
+	if err != nil {
+		return false, err
+	}
+	if current := r.current.Load(); current != nil && bytes.Equal(current.digest, digest) {
+		return false, nil
+	}
+
+	loaded, err := parse(files)
+	if err != nil {
+		return false, err
+	}
+	loaded.digest = digest
+
+	reloaded := r.current.Load() != nil
+	r.current.Store(loaded)
+	if reloaded {
+		log.WithField("cert_file", r.config.CertFile).Info("TLS certificate reloaded")
+	}
+	return true, nil
+}
+
+// pemFiles are the contents of the configured files; files that are not
+// configured are empty
+type pemFiles struct {
+	cert, key, clientCAs, rootCAs []byte
+}
+
+// read reads the configured files and digests their contents
+func (r *Reloader) read() (pemFiles, []byte, error) {
+	var files pemFiles
+	hash := sha256.New()
+	for _, file := range []struct {
+		path     string
+		contents *[]byte
+	}{
+		{r.config.CertFile, &files.cert},
+		{r.config.KeyFile, &files.key},
+		{r.config.ClientCAFile, &files.clientCAs},
+		{r.config.CAFile, &files.rootCAs},
+	} {
+		if file.path == "" {
+			hash.Write([]byte{0})
+			continue
+		}
+
+		contents, err := os.ReadFile(file.path)
+		if err != nil {
+			return pemFiles{}, nil, err
+		}
+		*file.contents = contents
+		fmt.Fprintf(hash, "%d:", len(contents))
+		hash.Write(contents)
+	}
+	return files, hash.Sum(nil), nil
+}
+
+// parse parses the certificate, key and CAs of files
+func parse(files pemFiles) (*material, error) {
+	certificate, err := tls.X509KeyPair(files.cert, files.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	loaded := &material{certificate: &certificate}
+	if files.clientCAs != nil {
+		if loaded.clientCAs, err = certPool(files.clientCAs); err != nil {
+			return nil, fmt.Errorf("failed to load client CAs: %w", err)
+		}
+	}
+	if files.rootCAs != nil {
+		if loaded.rootCAs, err = certPool(files.rootCAs); err != nil {
+			return nil, fmt.Errorf("failed to load CAs: %w", err)
+		}
+	}
+	return loaded, nil
+}
+
+// certPool returns a pool of the certificates in a PEM bundle
+func certPool(bundle []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, errors.New("no certificates found")
+	}
+	return pool, nil
+}
+
+// ServerConfig returns the TLS configuration of a server negotiating the
+// given application protocols, e.g. "h2" and "http/1.1". gRPC requires
+// "h2". Every handshake uses the certificate and client CAs loaded last.
+func (r *Reloader) ServerConfig(protocols ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: protocols,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			current := r.current.Load()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   protocols,
+				Certificates: []tls.Certificate{*current.certificate},
+				ClientAuth:   r.clientAuth,
+				ClientCAs:    current.clientCAs,
+			}, nil
+		},
+	}
+}
+
+// ClientConfig returns the TLS configuration of a client of other services,
+// which presents the certificate loaded last when a server asks for one.
+// The CAs trusted for servers are those loaded when it is called.
+func (r *Reloader) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    r.current.Load().rootCAs,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.current.Load().certificate, nil
+		},
+	}
+}
+
+// Transport returns an HTTP transport calling other services with
+// ClientConfig
+func (r *Reloader) Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = r.ClientConfig()
+	return transport
+}
@@ -0,0 +1,279 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authority is a test CA issuing certificates for localhost
+type authority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+// newAuthority creates a self-signed CA
+func newAuthority(t *testing.T) *authority {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &authority{certificate: certificate, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the PEM certificate and key of a leaf for localhost with the
+// given serial number
+func (a *authority) issue(t *testing.T, serial int64) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.certificate, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFiles writes the certificate, key and CA of a service to dir and
+// returns the config naming them
+func writeFiles(t *testing.T, dir string, ca *authority, certPEM, keyPEM []byte, clientAuth string) Config {
+	t.Helper()
+	config := Config{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		CAFile:       filepath.Join(dir, "ca.crt"),
+		ClientAuth:   clientAuth,
+	}
+	require.NoError(t, os.WriteFile(config.CertFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(config.KeyFile, keyPEM, 0o600))
+	require.NoError(t, os.WriteFile(config.CAFile, ca.pem, 0o600))
+	return config
+}
+
+// handshake connects a client with clientConfig to a server with
+// serverConfig and returns the certificate the server presented
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+		// Give the client time to read the outcome of the handshake
+		_, _ = conn.Read(make([]byte, 1))
+	}()
+
+	clientConfig.ServerName = "localhost"
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// TLS 1.3 servers reject client certificates after the client finished
+	// its handshake, so the failure surfaces on the first read
+	_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		if netErr, ok := err.(net.Error); !ok || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestNew(t *testing.T) {
+	ca := newAuthority(t)
+	certPEM, keyPEM := ca.issue(t, 10)
+
+	t.Run("Load the configured files", func(t *testing.T) {
+		// Arrange
+		config := writeFiles(t, t.TempDir(), ca, certPEM, keyPEM, ClientAuthRequire)
+
+		// Act
+		reloader, err := New(config)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotNil(t, reloader.current.Load().clientCAs)
+	})
+
+	t.Run("Reject a key that does not match the certificate", func(t *testing.T) {
+		// Arrange
+		_, otherKey := ca.issue(t, 11)
+		config := writeFiles(t, t.TempDir(), ca, certPEM, otherKey, ClientAuthNone)
+
+		// Act
+		_, err := New(config)
+
+		// Assert
+		assert.ErrorContains(t, err, "failed to load certificate")
+	})
+
+	t.Run("Require client CAs to verify clients", func(t *testing.T) {
+		// Arrange
+		config := writeFiles(t, t.TempDir(), ca, certPEM, keyPEM, ClientAuthVerify)
+		config.ClientCAFile = ""
+
+		// Act
+		_, err := New(config)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestReloader_MutualTLS(t *testing.T) {
+	// Arrange
+	ca := newAuthority(t)
+	serverCert, serverKey := ca.issue(t, 20)
+	server, err := New(writeFiles(t, t.TempDir(), ca, serverCert, serverKey, ClientAuthRequire))
+	require.NoError(t, err)
+	clientCert, clientKey := ca.issue(t, 21)
+	client, err := New(writeFiles(t, t.TempDir(), ca, clientCert, clientKey, ClientAuthNone))
+	require.NoError(t, err)
+
+	t.Run("Accept clients presenting a certificate of the CA", func(t *testing.T) {
+		// Act
+		peer, err := handshake(t, server.ServerConfig(), client.ClientConfig())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(20), peer.SerialNumber.Int64())
+	})
+
+	t.Run("Refuse clients without a certificate", func(t *testing.T) {
+		// Arrange
+		anonymous := &tls.Config{RootCAs: client.ClientConfig().RootCAs}
+
+		// Act
+		_, err := handshake(t, server.ServerConfig(), anonymous)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("Refuse servers outside the CA", func(t *testing.T) {
+		// Arrange
+		other := newAuthority(t)
+		otherCert, otherKey := other.issue(t, 30)
+		impostor, err := New(writeFiles(t, t.TempDir(), other, otherCert, otherKey, ClientAuthNone))
+		require.NoError(t, err)
+
+		// Act
+		_, err = handshake(t, impostor.ServerConfig(), client.ClientConfig())
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestReloader_Reload(t *testing.T) {
+	// Arrange
+	ca := newAuthority(t)
+	certPEM, keyPEM := ca.issue(t, 40)
+	config := writeFiles(t, t.TempDir(), ca, certPEM, keyPEM, ClientAuthVerify)
+	reloader, err := New(config)
+	require.NoError(t, err)
+	client := &tls.Config{RootCAs: reloader.ClientConfig().RootCAs}
+
+	t.Run("Keep unchanged files", func(t *testing.T) {
+		// Act
+		changed, err := reloader.Reload()
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("Keep the certificate while the rotation is incomplete", func(t *testing.T) {
+		// Arrange
+		rotatedCert, _ := ca.issue(t, 41)
+		require.NoError(t, os.WriteFile(config.CertFile, rotatedCert, 0o600))
+
+		// Act
+		changed, err := reloader.Reload()
+		peer, handshakeErr := handshake(t, reloader.ServerConfig(), client.Clone())
+
+		// Assert
+		assert.Error(t, err)
+		assert.False(t, changed)
+		require.NoError(t, handshakeErr)
+		assert.Equal(t, int64(40), peer.SerialNumber.Int64())
+	})
+
+	t.Run("Serve the rotated certificate", func(t *testing.T) {
+		// Arrange
+		rotatedCert, rotatedKey := ca.issue(t, 42)
+		require.NoError(t, os.WriteFile(config.CertFile, rotatedCert, 0o600))
+		require.NoError(t, os.WriteFile(config.KeyFile, rotatedKey, 0o600))
+
+		// Act
+		changed, err := reloader.Reload()
+		peer, handshakeErr := handshake(t, reloader.ServerConfig(), client.Clone())
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, changed)
+		require.NoError(t, handshakeErr)
+		assert.Equal(t, int64(42), peer.SerialNumber.Int64())
+	})
+}
+
+func TestReloader_ServerConfig(t *testing.T) {
+	// Arrange
+	ca := newAuthority(t)
+	certPEM, keyPEM := ca.issue(t, 50)
+	reloader, err := New(writeFiles(t, t.TempDir(), ca, certPEM, keyPEM, ClientAuthNone))
+	require.NoError(t, err)
+
+	// Act
+	config, err := reloader.ServerConfig("h2").GetConfigForClient(&tls.ClientHelloInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"h2"}, config.NextProtos, "gRPC clients require the h2 protocol")
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+}
@@ -0,0 +1,193 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"external-apis/internal/shared/money"
+	"external-apis/internal/shared/phone"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes why a single field of a request body was rejected.
+// Field is the JSON path of the field, e.g. "operations[2].create.email".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Enum is implemented by types with a fixed set of valid values, such as
+// customer statuses. Fields of such types are checked with the "enum" rule.
+type Enum interface {
+	IsValid() bool
+}
+
+var registerOnce sync.Once
+
+// Register teaches Gin's validator the custom rules and makes it report
+// fields by their JSON names. It must run before the first request is bound;
+// calling it again has no effect.
+//
+// Custom rules:
+//   - phone: a valid phone number, with its country calling code unless it
+//     is in the default region of the phone package
+//   - currency: a supported ISO 4217 currency code, in any case
+//   - enum: a value whose type implements Enum and reports itself valid
+func Register() {
+	registerOnce.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		engine.RegisterTagNameFunc(jsonName)
+		// The rules are static and valid, so registration cannot fail
+		_ = engine.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			_, err := phone.Parse(fl.Field().String())
+			return err == nil
+		})
+		_ = engine.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+			return money.IsValidCurrency(fl.Field().String())
+		})
+		_ = engine.RegisterValidation("enum", func(fl validator.FieldLevel) bool {
+			enum, ok := fl.Field().Interface().(Enum)
+			return ok && enum.IsValid()
+		})
+	})
+}
+
+// Translate converts an error returned while binding a request body into
+// field errors. It returns nil if the error does not concern particular
+// fields, e.g. for malformed JSON.
+func Translate(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+		for _, fe := range validationErrors {
+			fields = append(fields, FieldError{
+				Field:   fieldPath(fe.Namespace()),
+				Rule:    fe.Tag(),
+				Message: message(fe),
+			})
+		}
+		return fields
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		return []FieldError{{
+			Field:   decodePath(typeError.Field),
+			Rule:    "type",
+			Message: "must be " + jsonType(typeError.Type),
+		}}
+	}
+
+	return nil
+}
+
+// jsonName names a struct field after its JSON key, falling back to the Go
+// field name for fields without one
+func jsonName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// fieldPath drops the name of the request type from a validator namespace
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// decodePath writes the array indices of a JSON decoder field path, such as
+// "items.0.count", the way validator namespaces do: "items[0].count"
+func decodePath(path string) string {
+	segments := strings.Split(path, ".")
+	var b strings.Builder
+	for i, segment := range segments {
+		if _, err := strconv.Atoi(segment); err == nil && i > 0 {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// message explains a failed rule to the client
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "phone":
+		return "must be a valid phone number"
+	case "currency":
+		return "must be a supported ISO 4217 currency code"
+	case "enum":
+		return fmt.Sprintf("must be a valid value, got %v", fe.Value())
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "len":
+		return "must " + size(fe.Kind(), "contain exactly", "be exactly", fe.Param())
+	case "min":
+		return "must " + size(fe.Kind(), "contain at least", "be at least", fe.Param())
+	case "max":
+		return "must " + size(fe.Kind(), "contain at most", "be at most", fe.Param())
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "gte":
+		return "must be at least " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " rule"
+	}
+}
+
+// size phrases a length or value bound depending on the kind of the field
+func size(kind reflect.Kind, collection, scalar, param string) string {
+	switch kind {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return collection + " " + param + " items"
+	case reflect.String:
+		return scalar + " " + param + " characters long"
+	default:
+		return scalar + " " + param
+	}
+}
+
+// jsonType names the JSON type that decodes into t
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
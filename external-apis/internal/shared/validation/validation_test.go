@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type color string
+
+func (c color) IsValid() bool {
+	return c == "RED" || c == "BLUE"
+}
+
+type item struct {
+	Color *color `json:"color,omitempty" binding:"omitempty,enum"`
+	Count int    `json:"count" binding:"gte=1"`
+}
+
+type request struct {
+	Name     string   `json:"name" binding:"required"`
+	Phone    string   `json:"phone" binding:"required,phone"`
+	Currency string   `json:"currency,omitempty" binding:"omitempty,currency"`
+	Tags     []string `json:"tags" binding:"max=2"`
+	Items    []item   `json:"items" binding:"dive"`
+}
+
+func bind(t *testing.T, body string) error {
+	t.Helper()
+	Register()
+
+	var req request
+	return binding.JSON.BindBody([]byte(body), &req)
+}
+
+func TestTranslate(t *testing.T) {
+	t.Run("Valid request", func(t *testing.T) {
+		// Act
+		err := bind(t, `{"name":"Jane","phone":"+1 202-555-0123","currency":"usd","items":[{"color":"RED","count":1}]}`)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Report every rejected field by its JSON path", func(t *testing.T) {
+		// Act
+		err := bind(t, `{"phone":"call me","currency":"XXX","tags":["a","b","c"],"items":[{"count":1},{"color":"GREEN","count":0}]}`)
+
+		// Assert
+		require.Error(t, err)
+		assert.Equal(t, []FieldError{
+			{Field: "name", Rule: "required", Message: "is required"},
+			{Field: "phone", Rule: "phone", Message: "must be a valid phone number"},
+			{Field: "currency", Rule: "currency", Message: "must be a supported ISO 4217 currency code"},
+			{Field: "tags", Rule: "max", Message: "must contain at most 2 items"},
+			{Field: "items[1].color", Rule: "enum", Message: "must be a valid value, got GREEN"},
+			{Field: "items[1].count", Rule: "gte", Message: "must be at least 1"},
+		}, Translate(err))
+	})
+
+	t.Run("Report a field of the wrong JSON type", func(t *testing.T) {
+		// Act
+		err := bind(t, `{"name":"Jane","phone":"+1 202-555-0123","items":[{"count":"many"}]}`)
+
+		// Assert
+		require.Error(t, err)
+		assert.Equal(t, []FieldError{
+			{Field: "items[0].count", Rule: "type", Message: "must be a number"},
+		}, Translate(err))
+	})
+
+	t.Run("Malformed JSON concerns no field", func(t *testing.T) {
+		// Act
+		err := bind(t, `{"name":`)
+
+		// Assert
+		require.Error(t, err)
+		assert.Nil(t, Translate(err))
+	})
+}
@@ -0,0 +1,88 @@
+package versioning
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Version is a major version of the public API. Breaking changes to requests
+// or responses ship as a new version served side by side with the old ones.
+type Version int
+
+const (
+	V1 Version = 1
+)
+
+// Supported lists the versions served under /api/v<N>, oldest first
+var Supported = []Version{V1}
+
+// Legacy is the version served by the deprecated unversioned /api routes
+const Legacy = V1
+
+// Header tells clients which version served the response
+const Header = "API-Version"
+
+// contextKey is the Gin context key holding the negotiated version
+const contextKey = "api_version"
+
+// String formats the version as it appears in paths, e.g. "v1"
+func (v Version) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// AtLeast reports whether v is other or a later version
+func (v Version) AtLeast(other Version) bool {
+	return v >= other
+}
+
+// Groups creates a route group under base for every supported version, e.g.
+// /api/v1, followed by base itself as a deprecated alias of Legacy. Handlers
+// run in every group after the version is negotiated. Callers register the
+// same routes on each group and branch on FromContext where versions differ.
+func Groups(router gin.IRouter, base string, handlers ...gin.HandlerFunc) []*gin.RouterGroup {
+	base = strings.TrimRight(base, "/")
+
+	groups := make([]*gin.RouterGroup, 0, len(Supported)+1)
+	for _, version := range Supported {
+		group := router.Group(base+"/"+version.String(), Use(version))
+		group.Use(handlers...)
+		groups = append(groups, group)
+	}
+
+	legacy := router.Group(base, Use(Legacy), Deprecated(base, base+"/"+Legacy.String()))
+	legacy.Use(handlers...)
+	return append(groups, legacy)
+}
+
+// Use records version as the negotiated version of the request and reports
+// it in the API-Version response header
+func Use(version Version) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextKey, version)
+		c.Header(Header, version.String())
+		c.Next()
+	}
+}
+
+// Deprecated marks responses of the routes under prefix as deprecated and
+// links to the same route under successor, following the Deprecation header
+// draft (draft-ietf-httpapi-deprecation-header)
+func Deprecated(prefix, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := successor + strings.TrimPrefix(c.Request.URL.Path, prefix)
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+path+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+// FromContext returns the version negotiated for the request, or Legacy for
+// routes outside the versioned groups
+func FromContext(c *gin.Context) Version {
+	if version, ok := c.Get(contextKey); ok {
+		return version.(Version)
+	}
+	return Legacy
+}
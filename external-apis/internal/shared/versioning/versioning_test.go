@@ -0,0 +1,63 @@
+package versioning
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroups(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	calls := 0
+	for _, api := range Groups(router, "/api", func(c *gin.Context) { calls++ }) {
+		api.GET("/products/:id", func(c *gin.Context) {
+			c.String(http.StatusOK, FromContext(c).String())
+		})
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		deprecation string
+		link        string
+	}{
+		{"Versioned route", "/api/v1/products/product-1", "", ""},
+		{"Legacy alias is deprecated", "/api/products/product-1", "true", `</api/v1/products/product-1>; rel="successor-version"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "v1", w.Body.String())
+			assert.Equal(t, "v1", w.Header().Get(Header))
+			assert.Equal(t, tt.deprecation, w.Header().Get("Deprecation"))
+			assert.Equal(t, tt.link, w.Header().Get("Link"))
+		})
+	}
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestFromContext(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	// Act
+	version := FromContext(c)
+
+	// Assert
+	assert.Equal(t, Legacy, version)
+	assert.True(t, version.AtLeast(V1))
+	assert.False(t, version.AtLeast(V1+1))
+}
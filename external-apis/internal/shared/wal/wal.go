@@ -0,0 +1,380 @@
+// Package wal persists the state of in-memory repositories to disk as an
+// append-only log of entity states, compacted into a JSON snapshot from time
+// to time, so it can be recovered when the service starts again.
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"external-apis/internal/shared/logger"
+)
+
+var log = logger.New("shared/wal")
+
+// ErrClosed is returned when writing to a closed log
+var ErrClosed = errors.New("write-ahead log closed")
+
+// Entry is a record of the log: the state of an entity of a tenant after a
+// write, or its removal when Value is empty
+type Entry struct {
+	Tenant string          `json:"tenant"`
+	ID     string          `json:"id"`
+	Value  json.RawMessage `json:"value,omitempty"`
+}
+
+// Log keeps the latest state of every entity written to it in a log file
+// and a snapshot file next to each other, <path>.log and
+// <path>.snapshot.json. Entries hold the whole state of an entity, so
+// replaying the log over the snapshot yields the latest states however
+// often either was written, and a crash between writing a snapshot and
+// truncating the log loses nothing.
+//
+// Appended entries are flushed to disk before Append returns, unless the log
+// is opened with a sync interval: they are then flushed together every
+// interval, trading a window of that length in which a machine crash loses
+// acknowledged writes for fewer flushes. A crash of the service alone loses
+// nothing either way.
+type Log struct {
+	path string
+	// states holds the latest value of every entity by tenant and ID, the
+	// content of the next snapshot
+	states map[string]map[string]json.RawMessage
+	file   *os.File
+	// appended counts the entries written since the last snapshot
+	appended int
+	// syncInterval is how often entries are flushed, or zero to flush every
+	// append; unsynced is set while written entries are not flushed yet
+	syncInterval time.Duration
+	unsynced     bool
+	// stop ends the flushes of a sync interval, which close stopped when done
+	stop    chan struct{}
+	stopped chan struct{}
+	mutex   sync.Mutex
+}
+
+// snapshot is the content of the snapshot file
+type snapshot struct {
+	States map[string]map[string]json.RawMessage `json:"states"`
+}
+
+// Open reads the snapshot and log at path and opens the log for appending,
+// creating the directory and files if needed. A truncated last entry, left
+// behind by a crash in the middle of a write, is dropped. Appends are flushed
+// to disk every syncInterval, or each on its own when it is zero.
+func Open(path string, syncInterval time.Duration) (*Log, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	l := &Log{path: path, states: make(map[string]map[string]json.RawMessage), syncInterval: syncInterval}
+	if err := l.readSnapshot(); err != nil {
+		return nil, err
+	}
+	valid, err := l.readLog()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(l.logPath(), os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	// Cut off a torn entry so the next one starts on a line of its own
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	l.file = file
+
+	if syncInterval > 0 {
+		l.stop, l.stopped = make(chan struct{}), make(chan struct{})
+		go l.syncEvery(syncInterval, l.stop)
+	}
+	return l, nil
+}
+
+// Tenants returns the tenants that have entities, sorted
+func (l *Log) Tenants() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return slices.Sorted(maps.Keys(l.states))
+}
+
+// States returns the latest value of every entity of a tenant by ID
+func (l *Log) States(tenant string) map[string]json.RawMessage {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return maps.Clone(l.states[tenant])
+}
+
+// Put appends the state of an entity to the log
+func (l *Log) Put(tenant, id string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return l.Append(Entry{Tenant: tenant, ID: id, Value: data})
+}
+
+// Remove appends the removal of an entity to the log
+func (l *Log) Remove(tenant, id string) error {
+	return l.Append(Entry{Tenant: tenant, ID: id})
+}
+
+// Append writes entries to the log in one write, so either all of them are
+// recovered or, if the write is torn, none after the first torn one. Without
+// a sync interval they are on disk when it returns.
+func (l *Log) Append(entries ...Entry) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return ErrClosed
+	}
+	if _, err := l.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if l.syncInterval > 0 {
+		l.unsynced = true
+	} else if err := l.file.Sync(); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		l.apply(entry)
+	}
+	l.appended += len(entries)
+	return nil
+}
+
+// Sync flushes the entries appended since the last flush to disk
+func (l *Log) Sync() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return ErrClosed
+	}
+	if !l.unsynced {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.unsynced = false
+	return nil
+}
+
+// syncEvery flushes the appended entries every interval until stop is
+// closed
+func (l *Log) syncEvery(interval time.Duration, stop <-chan struct{}) {
+	defer close(l.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := l.Sync(); err != nil {
+				log.WithError(err).WithField("path", l.logPath()).Error("Failed to flush write-ahead log")
+			}
+		}
+	}
+}
+
+// Snapshot writes the latest states to the snapshot file and empties the
+// log. It does nothing when no entry was appended since the last snapshot.
+func (l *Log) Snapshot() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return ErrClosed
+	}
+	if l.appended == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(snapshot{States: l.states})
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a partial file
+	tmp := l.snapshotPath() + ".partial"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.snapshotPath()); err != nil {
+		return err
+	}
+
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	l.unsynced = false
+	log.WithFields(logger.Fields{
+		"path":    l.path,
+		"entries": l.appended,
+	}).Debug("Compacted write-ahead log into snapshot")
+	l.appended = 0
+	return nil
+}
+
+// Close writes a last snapshot and closes the log
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	stop := l.stop
+	l.stop = nil
+	l.mutex.Unlock()
+	if stop != nil {
+		close(stop)
+		<-l.stopped
+	}
+
+	snapshotErr := l.Snapshot()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	var syncErr error
+	if l.unsynced {
+		syncErr = l.file.Sync()
+	}
+	err := l.file.Close()
+	l.file = nil
+	return errors.Join(snapshotErr, syncErr, err)
+}
+
+// readSnapshot loads the states of the snapshot file, if there is one
+func (l *Log) readSnapshot() error {
+	data, err := os.ReadFile(l.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("reading snapshot %s: %w", l.snapshotPath(), err)
+	}
+	for tenant, states := range s.States {
+		l.states[tenant] = states
+	}
+	return nil
+}
+
+// readLog applies the entries of the log file over the snapshot and returns
+// the length of the log up to the end of its last complete entry
+func (l *Log) readLog() (int64, error) {
+	file, err := os.Open(l.logPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var valid int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				log.WithField("path", l.logPath()).Warn("Dropping truncated write-ahead log entry")
+			}
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			log.WithError(err).WithField("path", l.logPath()).Warn("Dropping unreadable write-ahead log entries")
+			break
+		}
+		l.apply(entry)
+		l.appended++
+		valid += int64(len(line))
+	}
+	return valid, nil
+}
+
+// apply folds an entry into the states (the caller holds the lock or owns
+// the log)
+func (l *Log) apply(entry Entry) {
+	if len(entry.Value) == 0 {
+		delete(l.states[entry.Tenant], entry.ID)
+		if len(l.states[entry.Tenant]) == 0 {
+			delete(l.states, entry.Tenant)
+		}
+		return
+	}
+
+	if l.states[entry.Tenant] == nil {
+		l.states[entry.Tenant] = make(map[string]json.RawMessage)
+	}
+	l.states[entry.Tenant][entry.ID] = entry.Value
+}
+
+func (l *Log) logPath() string {
+	return l.path + ".log"
+}
+
+func (l *Log) snapshotPath() string {
+	return l.path + ".snapshot.json"
+}
+
+// writeFileSync writes a file and flushes it to disk
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
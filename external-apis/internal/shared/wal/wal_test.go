@@ -0,0 +1,188 @@
+package wal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	Name string `json:"name"`
+}
+
+func openTestLog(t *testing.T, path string) *Log {
+	t.Helper()
+	l, err := Open(path, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestLog_Recovery(t *testing.T) {
+	t.Run("Replay the log", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "data", "items")
+		l := openTestLog(t, path)
+		require.NoError(t, l.Put("", "item-1", item{Name: "First"}))
+		require.NoError(t, l.Put("", "item-2", item{Name: "Second"}))
+		require.NoError(t, l.Put("brand-a", "item-1", item{Name: "Other"}))
+		require.NoError(t, l.Put("", "item-1", item{Name: "Renamed"}))
+		require.NoError(t, l.Remove("", "item-2"))
+
+		// Act
+		reopened := openTestLog(t, path)
+
+		// Assert
+		assert.Equal(t, []string{"", "brand-a"}, reopened.Tenants())
+		states := reopened.States("")
+		require.Len(t, states, 1)
+		assert.JSONEq(t, `{"name":"Renamed"}`, string(states["item-1"]))
+	})
+
+	t.Run("Replay the log over the snapshot", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "items")
+		l := openTestLog(t, path)
+		require.NoError(t, l.Put("", "item-1", item{Name: "First"}))
+		require.NoError(t, l.Put("", "item-2", item{Name: "Second"}))
+		require.NoError(t, l.Snapshot())
+		require.NoError(t, l.Remove("", "item-1"))
+
+		// Act
+		reopened := openTestLog(t, path)
+
+		// Assert
+		states := reopened.States("")
+		require.Len(t, states, 1)
+		assert.Contains(t, states, "item-2")
+	})
+
+	t.Run("Drop a truncated entry", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "items")
+		l := openTestLog(t, path)
+		require.NoError(t, l.Put("", "item-1", item{Name: "First"}))
+		file, err := os.OpenFile(path+".log", os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = file.WriteString(`{"tenant":"","id":"item-2","val`)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		// Act
+		reopened := openTestLog(t, path)
+		require.NoError(t, reopened.Put("", "item-3", item{Name: "Third"}))
+
+		// Assert
+		again := openTestLog(t, path)
+		states := again.States("")
+		assert.Len(t, states, 2)
+		assert.Contains(t, states, "item-1")
+		assert.Contains(t, states, "item-3")
+	})
+}
+
+func TestLog_Snapshot(t *testing.T) {
+	t.Run("Compact the log", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "items")
+		l := openTestLog(t, path)
+		require.NoError(t, l.Put("", "item-1", item{Name: "First"}))
+
+		// Act
+		err := l.Snapshot()
+
+		// Assert
+		require.NoError(t, err)
+		info, err := os.Stat(path + ".log")
+		require.NoError(t, err)
+		assert.Zero(t, info.Size())
+		data, err := os.ReadFile(path + ".snapshot.json")
+		require.NoError(t, err)
+		var s snapshot
+		require.NoError(t, json.Unmarshal(data, &s))
+		assert.Contains(t, s.States[""], "item-1")
+	})
+
+	t.Run("Skip when nothing was appended", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "items")
+		l := openTestLog(t, path)
+
+		// Act
+		err := l.Snapshot()
+
+		// Assert
+		require.NoError(t, err)
+		_, err = os.Stat(path + ".snapshot.json")
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Reject writes after close", func(t *testing.T) {
+		// Arrange
+		l := openTestLog(t, filepath.Join(t.TempDir(), "items"))
+		require.NoError(t, l.Close())
+
+		// Act
+		err := l.Put("", "item-1", item{Name: "First"})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrClosed)
+	})
+}
+
+func TestLog_Sync(t *testing.T) {
+	t.Run("Flush every append without a sync interval", func(t *testing.T) {
+		// Arrange
+		l := openTestLog(t, filepath.Join(t.TempDir(), "items"))
+
+		// Act
+		err := l.Put("", "item-1", item{Name: "First"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.False(t, l.unsynced)
+	})
+
+	t.Run("Flush appends together every sync interval", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "items")
+		l, err := Open(path, 10*time.Millisecond)
+		require.NoError(t, err)
+		t.Cleanup(func() { l.Close() })
+
+		// Act
+		require.NoError(t, l.Put("", "item-1", item{Name: "First"}))
+		require.NoError(t, l.Put("", "item-2", item{Name: "Second"}))
+
+		// Assert
+		assert.Eventually(t, func() bool {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			return !l.unsynced
+		}, time.Second, 5*time.Millisecond)
+		reopened := openTestLog(t, path)
+		assert.Len(t, reopened.States(""), 2)
+	})
+
+	t.Run("Flush the rest on close", func(t *testing.T) {
+		// Arrange
+		path := filepath.Join(t.TempDir(), "items")
+		l, err := Open(path, time.Hour)
+		require.NoError(t, err)
+		require.NoError(t, l.Put("", "item-1", item{Name: "First"}))
+
+		// Act
+		err = l.Close()
+
+		// Assert
+		require.NoError(t, err)
+		assert.NoError(t, l.Close())
+		reopened := openTestLog(t, path)
+		assert.Len(t, reopened.States(""), 1)
+	})
+}
@@ -0,0 +1,323 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"external-apis/internal/shared/logger"
+	"github.com/google/uuid"
+)
+
+var log = logger.New("shared/webhook")
+
+// DeliveryJobKind is the background job kind used for webhook deliveries
+const DeliveryJobKind = "webhook.delivery"
+
+// Headers sent with every delivery
+const (
+	HeaderEventID   = "X-Webhook-ID"
+	HeaderEventType = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Config configures webhook delivery
+type Config struct {
+	// MaxAttempts is the number of delivery attempts before an event is dropped
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles after
+	// every failed attempt up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout bounds a single delivery attempt
+	Timeout time.Duration
+	// Encoder encodes the delivered events as CloudEvents
+	Encoder events.Encoder
+	// DeadLetters parks deliveries that failed for good so they can be
+	// replayed; they are dropped when it is nil
+	DeadLetters *deadletter.Queue
+}
+
+// DefaultConfig returns the default delivery configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        10 * time.Second,
+	}
+}
+
+// Dispatcher manages subscriptions and delivers signed events to them, as
+// CloudEvents in the structured format, in background jobs. Failed deliveries are retried with exponential backoff by
+// the job manager, so pending retries survive a restart. It implements
+// events.Publisher.
+type Dispatcher struct {
+	store  Store
+	jobs   *jobs.Manager
+	events map[string]bool
+	config Config
+	client *http.Client
+	now    func() time.Time
+}
+
+// delivery is the job payload for a single event sent to a single subscription.
+// The event is encoded once so every attempt sends the same bytes.
+type delivery struct {
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Body           json.RawMessage `json:"body"`
+}
+
+// NewDispatcher creates a dispatcher for the given event types and registers
+// its delivery handler with the job manager. It must be created before the
+// manager is started so persisted deliveries can be resumed.
+func NewDispatcher(store Store, manager *jobs.Manager, config Config, events []string) *Dispatcher {
+	defaults := DefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	supported := make(map[string]bool, len(events))
+	for _, event := range events {
+		supported[event] = true
+	}
+
+	d := &Dispatcher{
+		store:  store,
+		jobs:   manager,
+		events: supported,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+	}
+	manager.RegisterWithRetry(DeliveryJobKind, d.handleDelivery, jobs.RetryPolicy{
+		MaxAttempts:    config.MaxAttempts,
+		InitialBackoff: config.InitialBackoff,
+		MaxBackoff:     config.MaxBackoff,
+	})
+
+	return d
+}
+
+// Subscribe registers a callback URL for the given event types and returns the
+// subscription together with its signing secret
+func (d *Dispatcher) Subscribe(callbackURL string, events []string) (*Subscription, error) {
+	parsed, err := url.Parse(callbackURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidURL
+	}
+	if len(events) == 0 {
+		return nil, ErrEventsRequired
+	}
+	for _, event := range events {
+		if event != EventAll && !d.events[event] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, event)
+		}
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &Subscription{
+		ID:        uuid.New().String(),
+		URL:       parsed.String(),
+		Events:    append([]string(nil), events...),
+		Secret:    secret,
+		CreatedAt: d.now().UTC(),
+	}
+
+	if err := d.store.Create(subscription); err != nil {
+		return nil, fmt.Errorf("failed to store webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// Subscriptions returns all subscriptions without their secrets
+func (d *Dispatcher) Subscriptions() ([]*Subscription, error) {
+	return d.store.GetAll()
+}
+
+// Unsubscribe removes a subscription. Deliveries already queued for it are dropped.
+func (d *Dispatcher) Unsubscribe(id string) error {
+	return d.store.Delete(id)
+}
+
+// Publish queues a delivery of the event to every matching subscription
+func (d *Dispatcher) Publish(event events.Event) {
+	subscriptions, err := d.store.GetAll()
+	if err != nil {
+		log.WithError(err).WithField("event_type", event.Type).Error("Failed to load webhook subscriptions")
+		return
+	}
+
+	var body []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) {
+			continue
+		}
+
+		if body == nil {
+			if body, err = d.config.Encoder.Encode(event); err != nil {
+				log.WithError(err).WithField("event_type", event.Type).Error("Failed to encode webhook event")
+				return
+			}
+		}
+
+		_, err := d.jobs.Submit(DeliveryJobKind, delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Body:           body,
+		})
+		if err != nil {
+			log.WithError(err).WithFields(logger.Fields{
+				"event_type":      event.Type,
+				"subscription_id": subscription.ID,
+			}).Error("Failed to queue webhook delivery")
+		}
+	}
+}
+
+// handleDelivery makes one attempt to send an event to a subscription. The
+// job manager retries failures unless they are permanent.
+func (d *Dispatcher) handleDelivery(ctx context.Context, payload json.RawMessage) error {
+	var job delivery
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid webhook delivery payload: %w", err))
+	}
+
+	subscription, err := d.store.GetByID(job.SubscriptionID)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := d.deliver(ctx, subscription, job); err != nil {
+		err = fmt.Errorf("webhook delivery to subscription %s failed: %w", subscription.ID, err)
+		// Deliveries interrupted by shutdown are resumed, not given up on
+		if ctx.Err() == nil && (jobs.IsPermanent(err) || jobs.Attempt(ctx) >= d.config.MaxAttempts) {
+			d.park(ctx, job, err)
+		}
+		return err
+	}
+
+	log.Ctx(ctx).WithFields(logger.Fields{
+		"event_id":        job.EventID,
+		"event_type":      job.EventType,
+		"subscription_id": subscription.ID,
+		"attempt":         jobs.Attempt(ctx),
+	}).Debug("Webhook delivered")
+	return nil
+}
+
+// park hands a delivery that failed for good to the dead letter queue
+func (d *Dispatcher) park(ctx context.Context, job delivery, err error) {
+	if d.config.DeadLetters == nil {
+		return
+	}
+
+	d.config.DeadLetters.Park(deadletter.Entry{
+		Source:      deadletter.SourceWebhook,
+		Destination: job.SubscriptionID,
+		EventID:     job.EventID,
+		EventType:   job.EventType,
+		Event:       job.Body,
+		Error:       err.Error(),
+		Attempts:    jobs.Attempt(ctx),
+	})
+}
+
+// Replay queues a new delivery of a parked event to its subscription. It
+// implements deadletter.Replayer.
+func (d *Dispatcher) Replay(ctx context.Context, entry *deadletter.Entry) error {
+	_, err := d.store.GetByID(entry.Destination)
+	if errors.Is(err, ErrSubscriptionNotFound) {
+		return fmt.Errorf("%w: webhook subscription %s was removed", deadletter.ErrNotReplayable, entry.Destination)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = d.jobs.Submit(DeliveryJobKind, delivery{
+		SubscriptionID: entry.Destination,
+		EventID:        entry.EventID,
+		EventType:      entry.EventType,
+		Body:           entry.Event,
+	})
+	return err
+}
+
+// deliver makes a single signed delivery attempt
+func (d *Dispatcher) deliver(ctx context.Context, subscription *Subscription, job delivery) error {
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(job.Body))
+	if err != nil {
+		return jobs.Permanent(err)
+	}
+	req.Header.Set("Content-Type", cloudevents.ContentType)
+	req.Header.Set("User-Agent", "external-apis-webhooks/1.0")
+	req.Header.Set(HeaderEventID, job.EventID)
+	req.Header.Set(HeaderEventType, job.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, job.Body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("subscriber responded with status %d", resp.StatusCode)
+	default:
+		return jobs.Permanent(fmt.Errorf("subscriber responded with status %d", resp.StatusCode))
+	}
+}
+
+// Sign computes the X-Webhook-Signature header value: the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret, prefixed with "sha256="
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign in constant time
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"external-apis/internal/shared/cloudevents"
+	"external-apis/internal/shared/deadletter"
+	"external-apis/internal/shared/events"
+	"external-apis/internal/shared/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig retries quickly so tests do not wait on real backoff
+func testConfig() Config {
+	return Config{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Timeout:        time.Second,
+	}
+}
+
+// newTestDispatcher creates a started dispatcher that drains on cleanup
+func newTestDispatcher(t *testing.T) *Dispatcher {
+	manager := jobs.NewManager(nil, jobs.DefaultOptions())
+	dispatcher := NewDispatcher(NewMemoryStore(), manager, testConfig(), events.ProductEvents)
+	require.NoError(t, manager.Start())
+	t.Cleanup(func() {
+		_ = manager.Drain(time.Second)
+	})
+	return dispatcher
+}
+
+type receivedDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+func TestDispatcher_Subscribe(t *testing.T) {
+	t.Run("Create valid subscription", func(t *testing.T) {
+		// Arrange
+		dispatcher := newTestDispatcher(t)
+
+		// Act
+		subscription, err := dispatcher.Subscribe("https://example.com/hooks", []string{events.ProductCreated})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(subscription.Secret, SecretPrefix))
+		subscriptions, err := dispatcher.Subscriptions()
+		require.NoError(t, err)
+		assert.Len(t, subscriptions, 1)
+	})
+
+	tests := []struct {
+		name   string
+		url    string
+		events []string
+		err    error
+	}{
+		{"Relative URL", "/hooks", []string{events.ProductCreated}, ErrInvalidURL},
+		{"Unsupported scheme", "ftp://example.com/hooks", []string{events.ProductCreated}, ErrInvalidURL},
+		{"Missing events", "https://example.com/hooks", nil, ErrEventsRequired},
+		{"Event of another service", "https://example.com/hooks", []string{events.CustomerCreated}, ErrUnknownEvent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dispatcher := newTestDispatcher(t)
+
+			// Act
+			subscription, err := dispatcher.Subscribe(tt.url, tt.events)
+
+			// Assert
+			assert.Nil(t, subscription)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestDispatcher_Publish(t *testing.T) {
+	t.Run("Deliver signed event to matching subscriptions", func(t *testing.T) {
+		// Arrange
+		received := make(chan receivedDelivery, 10)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- receivedDelivery{header: r.Header.Clone(), body: body}
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t)
+		subscription, err := dispatcher.Subscribe(server.URL, []string{events.ProductCreated})
+		require.NoError(t, err)
+		_, err = dispatcher.Subscribe(server.URL, []string{events.ProductDeleted})
+		require.NoError(t, err)
+
+		// Act
+		dispatcher.Publish(events.New(events.ProductCreated, "product-123", map[string]string{"id": "product-123"}))
+
+		// Assert
+		var delivered receivedDelivery
+		select {
+		case delivered = <-received:
+		case <-time.After(2 * time.Second):
+			t.Fatal("webhook was not delivered")
+		}
+
+		timestamp, err := strconv.ParseInt(delivered.header.Get(HeaderTimestamp), 10, 64)
+		require.NoError(t, err)
+		assert.True(t, Verify(subscription.Secret, timestamp, delivered.body, delivered.header.Get(HeaderSignature)))
+		assert.Equal(t, events.ProductCreated, delivered.header.Get(HeaderEventType))
+		assert.Equal(t, cloudevents.ContentType, delivered.header.Get("Content-Type"))
+
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal(delivered.body, &event))
+		assert.Equal(t, "1.0", event["specversion"])
+		assert.Equal(t, events.DefaultSource, event["source"])
+		assert.Equal(t, events.ProductCreated, event["type"])
+		assert.Equal(t, delivered.header.Get(HeaderEventID), event["id"])
+		assert.Equal(t, map[string]interface{}{"id": "product-123"}, event["data"])
+
+		select {
+		case <-received:
+			t.Fatal("event was delivered to a subscription that does not match")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("Retry failed deliveries", func(t *testing.T) {
+		// Arrange
+		var attempts int32
+		delivered := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			close(delivered)
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t)
+		_, err := dispatcher.Subscribe(server.URL, []string{EventAll})
+		require.NoError(t, err)
+
+		// Act
+		dispatcher.Publish(events.New(events.ProductUpdated, "product-123", nil))
+
+		// Assert
+		select {
+		case <-delivered:
+		case <-time.After(2 * time.Second):
+			t.Fatal("webhook was not retried")
+		}
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("Do not retry client errors", func(t *testing.T) {
+		// Arrange
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		manager := jobs.NewManager(nil, jobs.DefaultOptions())
+		dispatcher := NewDispatcher(NewMemoryStore(), manager, testConfig(), events.ProductEvents)
+		require.NoError(t, manager.Start())
+		_, err := dispatcher.Subscribe(server.URL, []string{events.ProductDeleted})
+		require.NoError(t, err)
+
+		// Act
+		dispatcher.Publish(events.New(events.ProductDeleted, "product-123", nil))
+		require.NoError(t, manager.Drain(time.Second))
+
+		// Assert
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+		stats := manager.Stats()
+		require.Len(t, stats.Kinds, 1)
+		assert.Equal(t, 0, stats.Kinds[0].Retried)
+		assert.Equal(t, 1, stats.Kinds[0].Failed)
+		require.Len(t, stats.Failures, 1)
+		assert.Equal(t, DeliveryJobKind, stats.Failures[0].Kind)
+	})
+
+	t.Run("Park deliveries that run out of attempts", func(t *testing.T) {
+		// Arrange
+		var attempts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		manager := jobs.NewManager(nil, jobs.DefaultOptions())
+		deadLetters := deadletter.NewQueue(deadletter.NewMemoryStore())
+		config := testConfig()
+		config.DeadLetters = deadLetters
+		dispatcher := NewDispatcher(NewMemoryStore(), manager, config, events.ProductEvents)
+		require.NoError(t, manager.Start())
+		subscription, err := dispatcher.Subscribe(server.URL, []string{EventAll})
+		require.NoError(t, err)
+		event := events.New(events.ProductUpdated, "product-123", nil)
+
+		// Act
+		dispatcher.Publish(event)
+
+		// Assert
+		require.Eventually(t, func() bool {
+			parked, err := deadLetters.List(deadletter.Filter{})
+			return err == nil && len(parked) == 1
+		}, 2*time.Second, 5*time.Millisecond)
+		require.NoError(t, manager.Drain(time.Second))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+
+		parked, err := deadLetters.List(deadletter.Filter{})
+		require.NoError(t, err)
+		assert.Equal(t, deadletter.SourceWebhook, parked[0].Source)
+		assert.Equal(t, subscription.ID, parked[0].Destination)
+		assert.Equal(t, event.ID, parked[0].EventID)
+		assert.Equal(t, 3, parked[0].Attempts)
+		assert.Contains(t, parked[0].Error, "status 502")
+	})
+}
+
+func TestDispatcher_Replay(t *testing.T) {
+	t.Run("Deliver the parked event again", func(t *testing.T) {
+		// Arrange
+		received := make(chan []byte, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- body
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t)
+		subscription, err := dispatcher.Subscribe(server.URL, []string{EventAll})
+		require.NoError(t, err)
+
+		// Act
+		err = dispatcher.Replay(context.Background(), &deadletter.Entry{
+			Destination: subscription.ID,
+			EventID:     "event-1",
+			EventType:   events.ProductUpdated,
+			Event:       json.RawMessage(`{"id":"event-1"}`),
+		})
+
+		// Assert
+		require.NoError(t, err)
+		select {
+		case body := <-received:
+			assert.JSONEq(t, `{"id":"event-1"}`, string(body))
+		case <-time.After(2 * time.Second):
+			t.Fatal("parked event was not delivered")
+		}
+	})
+
+	t.Run("Removed subscription", func(t *testing.T) {
+		// Arrange
+		dispatcher := newTestDispatcher(t)
+
+		// Act
+		err := dispatcher.Replay(context.Background(), &deadletter.Entry{Destination: "missing"})
+
+		// Assert
+		assert.ErrorIs(t, err, deadletter.ErrNotReplayable)
+	})
+}
+
+func TestSign(t *testing.T) {
+	// Arrange
+	body := []byte(`{"id":"evt"}`)
+
+	// Act
+	signature := Sign("whsec_test", 1700000000, body)
+
+	// Assert
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	assert.True(t, Verify("whsec_test", 1700000000, body, signature))
+	assert.False(t, Verify("whsec_other", 1700000000, body, signature))
+	assert.False(t, Verify("whsec_test", 1700000001, body, signature))
+}
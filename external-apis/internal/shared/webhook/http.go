@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"external-apis/internal/shared/logger"
+	"external-apis/internal/shared/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSubscriptionRequest represents the request to register a webhook
+type CreateSubscriptionRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required,min=1"`
+}
+
+// CreateSubscriptionResponse is returned once when a subscription is created.
+// It is the only response that ever contains the signing secret.
+type CreateSubscriptionResponse struct {
+	*Subscription
+	Secret string `json:"secret"`
+}
+
+// Handler serves the endpoints for managing webhook subscriptions
+type Handler struct {
+	dispatcher *Dispatcher
+}
+
+// NewHandler creates a new webhook subscription handler
+func NewHandler(dispatcher *Dispatcher) *Handler {
+	return &Handler{dispatcher: dispatcher}
+}
+
+// RegisterRoutes registers the webhook subscription routes
+func (h *Handler) RegisterRoutes(router *gin.RouterGroup) {
+	webhooks := router.Group("/webhooks")
+	{
+		webhooks.GET("", h.ListSubscriptions)
+		webhooks.POST("", h.CreateSubscription)
+		webhooks.DELETE("/:id", h.DeleteSubscription)
+	}
+}
+
+// ListSubscriptions returns all subscriptions without their secrets
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.dispatcher.Subscriptions()
+	if err != nil {
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to list webhook subscriptions")
+		response.InternalServerError(c, "Failed to list webhook subscriptions")
+		return
+	}
+
+	response.OK(c, subscriptions)
+}
+
+// CreateSubscription registers a callback URL for a set of events
+func (h *Handler) CreateSubscription(c *gin.Context) {
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidRequest(c, err)
+		return
+	}
+
+	subscription, err := h.dispatcher.Subscribe(req.URL, req.Events)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrEventsRequired), errors.Is(err, ErrUnknownEvent):
+			response.BadRequest(c, err.Error())
+		default:
+			log.Ctx(c.Request.Context()).WithError(err).Error("Failed to create webhook subscription")
+			response.InternalServerError(c, "Failed to create webhook subscription")
+		}
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithFields(logger.Fields{
+		"subscription_id": subscription.ID,
+		"url":             subscription.URL,
+		"events":          subscription.Events,
+	}).Info("Webhook subscription created")
+
+	response.Created(c, CreateSubscriptionResponse{Subscription: subscription, Secret: subscription.Secret})
+}
+
+// DeleteSubscription removes a webhook subscription
+func (h *Handler) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.dispatcher.Unsubscribe(id); err != nil {
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			response.NotFound(c, "Webhook subscription not found")
+			return
+		}
+		log.Ctx(c.Request.Context()).WithError(err).Error("Failed to delete webhook subscription")
+		response.InternalServerError(c, "Failed to delete webhook subscription")
+		return
+	}
+
+	log.Ctx(c.Request.Context()).WithField("subscription_id", id).Info("Webhook subscription deleted")
+
+	c.Status(http.StatusNoContent)
+}
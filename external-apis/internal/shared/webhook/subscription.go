@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SecretPrefix marks webhook signing secrets
+const SecretPrefix = "whsec_"
+
+// EventAll subscribes to every event the service publishes
+const EventAll = "*"
+
+var (
+	// ErrSubscriptionNotFound is returned when no subscription matches the lookup
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	// ErrInvalidURL is returned for callback URLs that are not absolute http(s) URLs
+	ErrInvalidURL = errors.New("callback url must be an absolute http or https url")
+	// ErrEventsRequired is returned when a subscription names no events
+	ErrEventsRequired = errors.New("at least one event is required")
+	// ErrUnknownEvent is returned for event types the service does not publish
+	ErrUnknownEvent = errors.New("unknown event type")
+)
+
+// Subscription registers a callback URL for a set of event types
+type Subscription struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret signs deliveries. It is only returned when the subscription is created.
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Matches checks if the subscription wants events of the given type
+func (s *Subscription) Matches(eventType string) bool {
+	for _, event := range s.Events {
+		if event == EventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Store persists webhook subscriptions
+type Store interface {
+	Create(subscription *Subscription) error
+	GetByID(id string) (*Subscription, error)
+	GetAll() ([]*Subscription, error)
+	Delete(id string) error
+}
+
+// MemoryStore keeps webhook subscriptions in memory
+type MemoryStore struct {
+	subscriptions map[string]*Subscription
+	mutex         sync.RWMutex
+}
+
+// NewMemoryStore creates a new in-memory subscription store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subscriptions: make(map[string]*Subscription),
+	}
+}
+
+// Create stores a new subscription
+func (s *MemoryStore) Create(subscription *Subscription) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.subscriptions[subscription.ID] = copySubscription(subscription)
+	return nil
+}
+
+// GetByID returns the subscription with the given ID
+func (s *MemoryStore) GetByID(id string) (*Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	subscription, exists := s.subscriptions[id]
+	if !exists {
+		return nil, ErrSubscriptionNotFound
+	}
+	return copySubscription(subscription), nil
+}
+
+// GetAll returns all subscriptions ordered by creation time
+func (s *MemoryStore) GetAll() ([]*Subscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	subscriptions := make([]*Subscription, 0, len(s.subscriptions))
+	for _, subscription := range s.subscriptions {
+		subscriptions = append(subscriptions, copySubscription(subscription))
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+	})
+	return subscriptions, nil
+}
+
+// Delete removes a subscription
+func (s *MemoryStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.subscriptions[id]; !exists {
+		return ErrSubscriptionNotFound
+	}
+	delete(s.subscriptions, id)
+	return nil
+}
+
+// copySubscription returns a copy that does not share the events slice
+func copySubscription(subscription *Subscription) *Subscription {
+	clone := *subscription
+	clone.Events = append([]string(nil), subscription.Events...)
+	return &clone
+}
+
+// generateSecret creates a random signing secret
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return SecretPrefix + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,483 @@
+// Package client is the Go SDK for the customer, product and order REST APIs.
+//
+// Each service has its own typed client, created from a Config naming the
+// base URL of the service:
+//
+//	customers, err := client.NewCustomerClient(client.Config{
+//		BaseURL: "http://localhost:3002",
+//		APIKey:  os.Getenv("API_KEY"),
+//	})
+//	customer, err := customers.Get(ctx, "customer-123")
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+//
+// Requests that are safe to repeat are retried on network errors, 429 Too
+// Many Requests and 502, 503 and 504 responses. The request ID, trace context
+// and tenant set on the context with WithRequestID, WithTraceparent and
+// WithTenant are sent along with every request.
+//
+// The package only depends on the standard library, so services outside this
+// repository can use it without pulling in its dependencies.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent along with every request
+const (
+	APIKeyHeader      = "X-API-Key"
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+	TenantHeader      = "X-Tenant-ID"
+	EnvelopeHeader    = "X-Response-Envelope"
+)
+
+// DefaultTimeout bounds a single attempt when the Config has no HTTP client
+const DefaultTimeout = 10 * time.Second
+
+// DefaultUserAgent identifies the SDK when the Config sets no user agent
+const DefaultUserAgent = "external-apis-go-client"
+
+// RetryPolicy controls how requests that are safe to repeat are retried. The
+// backoff doubles after every attempt, from InitialBackoff up to MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt; 1 disables retries
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when the Config sets none
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+	}
+}
+
+// Config configures a client of one of the services
+type Config struct {
+	// BaseURL of the service, e.g. http://localhost:3001
+	BaseURL string
+	// HTTPClient sends the requests; a client with DefaultTimeout is used
+	// when it is nil
+	HTTPClient *http.Client
+	// APIKey is sent in the X-API-Key header when it is set
+	APIKey string
+	// Token is sent as a Bearer token when it is set. Creating, updating and
+	// deleting entities requires one.
+	Token string
+	// Tenant is sent in the X-Tenant-ID header unless the context names
+	// another tenant
+	Tenant string
+	// UserAgent defaults to DefaultUserAgent
+	UserAgent string
+	// Retry defaults to DefaultRetryPolicy when MaxAttempts is zero
+	Retry RetryPolicy
+	// Propagate, when set, adds headers to every request from its context,
+	// e.g. to forward trace context kept by a tracing library
+	Propagate func(ctx context.Context, header http.Header)
+}
+
+// Page is a page of a paginated list
+type Page[T any] struct {
+	Data       []T        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// Pagination describes where a page lies in the complete list
+type Pagination struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// HasMore reports whether the list continues after the page
+func (p Pagination) HasMore() bool {
+	return p.Offset+p.Limit < p.Total
+}
+
+// ListOptions selects a page of a list and its order
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Sort   string
+}
+
+// values returns the options as query parameters
+func (o ListOptions) values() url.Values {
+	query := url.Values{}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Sort != "" {
+		query.Set("sort", o.Sort)
+	}
+	return query
+}
+
+// TimeFilter selects the entries created or updated within time ranges,
+// e.g. UpdatedSince for the entries changed since the last sync. Since
+// bounds are inclusive, Before bounds exclusive and zero times unbounded.
+type TimeFilter struct {
+	CreatedSince  time.Time
+	CreatedBefore time.Time
+	UpdatedSince  time.Time
+	UpdatedBefore time.Time
+}
+
+// setValues adds the set bounds to query
+func (f TimeFilter) setValues(query url.Values) {
+	for key, value := range map[string]time.Time{
+		"created_since":  f.CreatedSince,
+		"created_before": f.CreatedBefore,
+		"updated_since":  f.UpdatedSince,
+		"updated_before": f.UpdatedBefore,
+	} {
+		if !value.IsZero() {
+			query.Set(key, value.Format(time.RFC3339Nano))
+		}
+	}
+}
+
+// restClient sends requests to one service and decodes its responses
+type restClient struct {
+	baseURL    string
+	httpClient *http.Client
+	config     Config
+	retry      RetryPolicy
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// newRESTClient validates the config and fills in its defaults
+func newRESTClient(config Config) (*restClient, error) {
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("client: invalid base URL %q", config.BaseURL)
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	retry := config.Retry
+	if retry.MaxAttempts <= 0 {
+		retry = DefaultRetryPolicy()
+	}
+
+	return &restClient{
+		baseURL:    strings.TrimRight(config.BaseURL, "/"),
+		httpClient: httpClient,
+		config:     config,
+		retry:      retry,
+		sleep:      sleep,
+	}, nil
+}
+
+// request describes a call to the API
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   interface{}
+	// idempotent requests are retried; GET, PUT and DELETE always are
+	idempotent bool
+	// paged requests are answered with a page, whose data and pagination are
+	// both part of the envelope
+	paged bool
+}
+
+// do sends the request, retrying it while the policy allows, and decodes the
+// JSON response into out unless out is nil
+func (c *restClient) do(ctx context.Context, r request, out interface{}) error {
+	var payload []byte
+	if r.body != nil {
+		var err error
+		if payload, err = json.Marshal(r.body); err != nil {
+			return fmt.Errorf("client: encode request body: %w", err)
+		}
+	}
+
+	attempts := 1
+	if r.idempotent || r.method == http.MethodGet || r.method == http.MethodPut || r.method == http.MethodDelete {
+		attempts = c.retry.MaxAttempts
+	}
+
+	backoff := c.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		wait, err := c.send(ctx, r, payload, out)
+		if err == nil || attempt >= attempts || !retryable(err) {
+			return err
+		}
+
+		// The server knows best when it can take the request again, so its
+		// Retry-After wins over the backoff even beyond MaxBackoff
+		if sleepErr := c.sleep(ctx, max(wait, backoff)); sleepErr != nil {
+			return err
+		}
+		backoff *= 2
+		if c.retry.MaxBackoff > 0 {
+			backoff = min(backoff, c.retry.MaxBackoff)
+		}
+	}
+}
+
+// send makes a single attempt. It returns how long the server asked to wait
+// before trying again, if it did.
+func (c *restClient) send(ctx context.Context, r request, payload []byte, out interface{}) (time.Duration, error) {
+	target := c.baseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, target, body)
+	if err != nil {
+		return 0, fmt.Errorf("client: build request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setHeaders(ctx, req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, fmt.Errorf("%w: %s %s: %w", ErrUnavailable, r.method, r.path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := decodeError(resp)
+		return apiErr.RetryAfter, apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return 0, nil
+	}
+	if err := decodeBody(resp.Body, r.paged, out); err != nil {
+		return 0, fmt.Errorf("client: decode response of %s %s: %w", r.method, r.path, err)
+	}
+	return 0, nil
+}
+
+// decodeBody decodes the data of an enveloped response into out
+func decodeBody(body io.Reader, paged bool, out interface{}) error {
+	if paged {
+		return json.NewDecoder(body).Decode(out)
+	}
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(body).Decode(&envelope); err != nil {
+		return err
+	}
+	if len(envelope.Data) == 0 {
+		return errors.New("response has no data")
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// setHeaders sets the authentication, tenant and tracing headers
+func (c *restClient) setHeaders(ctx context.Context, header http.Header) {
+	header.Set("Accept", "application/json")
+	header.Set(EnvelopeHeader, "true")
+
+	userAgent := c.config.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	header.Set("User-Agent", userAgent)
+
+	if c.config.APIKey != "" {
+		header.Set(APIKeyHeader, c.config.APIKey)
+	}
+	if c.config.Token != "" {
+		header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+
+	tenant := c.config.Tenant
+	if value, ok := ctx.Value(tenantKey).(string); ok {
+		tenant = value
+	}
+	if tenant != "" {
+		header.Set(TenantHeader, tenant)
+	}
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+		header.Set(RequestIDHeader, requestID)
+	}
+	if traceparent, ok := ctx.Value(traceparentKey).(string); ok {
+		header.Set(TraceparentHeader, traceparent)
+	}
+
+	if c.config.Propagate != nil {
+		c.config.Propagate(ctx, header)
+	}
+}
+
+// retryable reports whether an attempt that failed with err may succeed when
+// it is repeated
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, ErrUnavailable)
+}
+
+// sleep waits for d unless the context ends first
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// contextKey is the type of the context keys of the package
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	traceparentKey
+	tenantKey
+)
+
+// WithRequestID returns a context whose requests carry the request ID, so
+// the calls can be correlated with the request that caused them
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithTraceparent returns a context whose requests carry the W3C trace
+// context, so the services join the trace of the caller
+func WithTraceparent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceparentKey, traceparent)
+}
+
+// WithTenant returns a context whose requests act on behalf of the tenant,
+// overriding the tenant of the Config
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+// pathf builds a request path, escaping the segments filled into format
+func pathf(format string, segments ...string) string {
+	escaped := make([]interface{}, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf(format, escaped...)
+}
+
+// MaxBatchIDs is the largest number of IDs the services look up in one batch
+// get; BatchGet methods split longer lists into several requests
+const MaxBatchIDs = 500
+
+// batchResponse mirrors the response of the batch get endpoints
+type batchResponse[T any] struct {
+	Results []struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Data   *T     `json:"data"`
+	} `json:"results"`
+}
+
+// Operations of change feed entries
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeDelete = "delete"
+)
+
+// Change is an entry of a change feed. Data is the entity after the change
+// and nil for deletes.
+type Change[T any] struct {
+	Cursor     string    `json:"cursor"`
+	Operation  string    `json:"operation"`
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       *T        `json:"data,omitempty"`
+}
+
+// ChangePage is a page of a change feed. Cursor is passed as since to read
+// the changes after it, including when the page is empty.
+type ChangePage[T any] struct {
+	Changes []Change[T] `json:"changes"`
+	Cursor  string      `json:"cursor"`
+	HasMore bool        `json:"hasMore"`
+}
+
+// changes reads the page of the change feed at path after the cursor since
+func changes[T any](ctx context.Context, c *restClient, path, since string, limit int) (*ChangePage[T], error) {
+	query := url.Values{}
+	if since != "" {
+		query.Set("since", since)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var page ChangePage[T]
+	if err := c.do(ctx, request{method: http.MethodGet, path: path, query: query}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// batchGet looks up the entities with the given IDs through a batch get
+// endpoint. Entities that do not exist are left out of the result.
+func batchGet[T any](ctx context.Context, c *restClient, path string, ids []string) (map[string]*T, error) {
+	found := make(map[string]*T, len(ids))
+
+	for start := 0; start < len(ids); start += MaxBatchIDs {
+		end := min(start+MaxBatchIDs, len(ids))
+
+		var resp batchResponse[T]
+		body := map[string][]string{"ids": ids[start:end]}
+		if err := c.do(ctx, request{method: http.MethodPost, path: path, body: body, idempotent: true}, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, result := range resp.Results {
+			if result.Status == "found" && result.Data != nil {
+				found[result.ID] = result.Data
+			}
+		}
+	}
+
+	return found, nil
+}
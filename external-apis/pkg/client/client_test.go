@@ -0,0 +1,279 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a customer client of server whose retries record
+// their waits instead of sleeping
+func newTestClient(t *testing.T, server *httptest.Server, config Config) (*CustomerClient, *[]time.Duration) {
+	t.Helper()
+
+	config.BaseURL = server.URL
+	customers, err := NewCustomerClient(config)
+	require.NoError(t, err)
+
+	var waits []time.Duration
+	customers.rest.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return customers, &waits
+}
+
+// envelope wraps data in the envelope of success responses
+func envelope(data string) string {
+	return `{"data":` + data + `,"message":"OK","code":200}`
+}
+
+func TestNewCustomerClient(t *testing.T) {
+	t.Run("Reject invalid base URLs", func(t *testing.T) {
+		for _, baseURL := range []string{"", "localhost:3002", "http://"} {
+			// Act
+			_, err := NewCustomerClient(Config{BaseURL: baseURL})
+
+			// Assert
+			assert.Error(t, err, baseURL)
+		}
+	})
+}
+
+func TestClient_Headers(t *testing.T) {
+	t.Run("Send credentials, tenant and trace context", func(t *testing.T) {
+		// Arrange
+		var header http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			_, _ = w.Write([]byte(envelope(`{"id":"customer-123"}`)))
+		}))
+		defer server.Close()
+
+		customers, _ := newTestClient(t, server, Config{
+			APIKey:    "key-1",
+			Token:     "token-1",
+			Tenant:    "acme",
+			UserAgent: "billing/1.0",
+			Propagate: func(ctx context.Context, header http.Header) {
+				header.Set("Baggage", "team=billing")
+			},
+		})
+		ctx := WithRequestID(context.Background(), "req-1")
+		ctx = WithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		ctx = WithTenant(ctx, "globex")
+
+		// Act
+		_, err := customers.Get(ctx, "customer-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", header.Get(APIKeyHeader))
+		assert.Equal(t, "Bearer token-1", header.Get("Authorization"))
+		assert.Equal(t, "globex", header.Get(TenantHeader))
+		assert.Equal(t, "req-1", header.Get(RequestIDHeader))
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", header.Get(TraceparentHeader))
+		assert.Equal(t, "billing/1.0", header.Get("User-Agent"))
+		assert.Equal(t, "team=billing", header.Get("Baggage"))
+		assert.Equal(t, "true", header.Get(EnvelopeHeader))
+	})
+}
+
+func TestClient_Errors(t *testing.T) {
+	t.Run("Decode the error response", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(RequestIDHeader, "req-9")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"bad_request","message":"Request validation failed","code":400,"details":[{"field":"email","rule":"email","message":"email must be a valid email"}]}`))
+		}))
+		defer server.Close()
+		customers, _ := newTestClient(t, server, Config{})
+
+		// Act
+		_, err := customers.Create(context.Background(), CreateCustomerRequest{Name: "John Doe", Email: "john"})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrBadRequest)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, "bad_request", apiErr.Code)
+		assert.Equal(t, "Request validation failed", apiErr.Message)
+		assert.Equal(t, "req-9", apiErr.RequestID)
+		assert.Equal(t, []FieldError{{Field: "email", Rule: "email", Message: "email must be a valid email"}}, apiErr.Details)
+	})
+
+	t.Run("Match the sentinel of the status", func(t *testing.T) {
+		tests := map[int]error{
+			http.StatusUnauthorized:        ErrUnauthorized,
+			http.StatusForbidden:           ErrForbidden,
+			http.StatusNotFound:            ErrNotFound,
+			http.StatusConflict:            ErrConflict,
+			http.StatusPreconditionFailed:  ErrConflict,
+			http.StatusGone:                ErrCursorExpired,
+			http.StatusUnprocessableEntity: ErrUnprocessable,
+			http.StatusInternalServerError: ErrUnavailable,
+		}
+		for status, sentinel := range tests {
+			// Arrange
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			customers, _ := newTestClient(t, server, Config{Retry: RetryPolicy{MaxAttempts: 1}})
+
+			// Act
+			_, err := customers.Get(context.Background(), "customer-123")
+			server.Close()
+
+			// Assert
+			assert.ErrorIs(t, err, sentinel, status)
+		}
+	})
+
+	t.Run("Unreachable services are unavailable", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.NotFoundHandler())
+		customers, _ := newTestClient(t, server, Config{Retry: RetryPolicy{MaxAttempts: 1}})
+		server.Close()
+
+		// Act
+		_, err := customers.Get(context.Background(), "customer-123")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+}
+
+func TestClient_Retry(t *testing.T) {
+	t.Run("Retry reads until they succeed", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(envelope(`{"id":"customer-123","name":"John Doe"}`)))
+		}))
+		defer server.Close()
+		customers, waits := newTestClient(t, server, Config{
+			Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second},
+		})
+
+		// Act
+		customer, err := customers.Get(context.Background(), "customer-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", customer.Name)
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+	})
+
+	t.Run("Wait as long as Retry-After asks", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "5")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			_, _ = w.Write([]byte(envelope(`{"id":"customer-123"}`)))
+		}))
+		defer server.Close()
+		customers, waits := newTestClient(t, server, Config{
+			Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second},
+		})
+
+		// Act
+		_, err := customers.Get(context.Background(), "customer-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{5 * time.Second}, *waits)
+	})
+
+	t.Run("Give up after the last attempt", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		customers, _ := newTestClient(t, server, Config{Retry: RetryPolicy{MaxAttempts: 2}})
+
+		// Act
+		_, err := customers.Get(context.Background(), "customer-123")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Do not retry requests that are not safe to repeat", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		customers, _ := newTestClient(t, server, Config{Retry: RetryPolicy{MaxAttempts: 3}})
+
+		// Act
+		_, err := customers.Create(context.Background(), CreateCustomerRequest{Name: "John Doe"})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Do not retry client errors", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		customers, _ := newTestClient(t, server, Config{Retry: RetryPolicy{MaxAttempts: 3}})
+
+		// Act
+		_, err := customers.Get(context.Background(), "customer-123")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrNotFound)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Stop waiting when the context ends", func(t *testing.T) {
+		// Arrange
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		customers, _ := newTestClient(t, server, Config{Retry: RetryPolicy{MaxAttempts: 5}})
+		ctx, cancel := context.WithCancel(context.Background())
+		customers.rest.sleep = func(context.Context, time.Duration) error {
+			cancel()
+			return context.Canceled
+		}
+
+		// Act
+		_, err := customers.Get(ctx, "customer-123")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUnavailable)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Customer statuses
+const (
+	CustomerActive   = "ACTIVE"
+	CustomerInactive = "INACTIVE"
+	CustomerBlocked  = "BLOCKED"
+	CustomerPending  = "PENDING"
+)
+
+// Customer is a customer as returned by the customer service
+type Customer struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email"`
+	Phone     string     `json:"phone"`
+	Active    bool       `json:"active"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// CreateCustomerRequest is the request to create a customer
+type CreateCustomerRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// UpdateCustomerRequest is the request to update a customer; only the fields
+// that are set change
+type UpdateCustomerRequest struct {
+	Name   *string `json:"name,omitempty"`
+	Email  *string `json:"email,omitempty"`
+	Phone  *string `json:"phone,omitempty"`
+	Active *bool   `json:"active,omitempty"`
+	Status *string `json:"status,omitempty"`
+}
+
+// CustomerStats counts the customers that are not deleted
+type CustomerStats struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"byStatus"`
+}
+
+// CustomerListOptions filters the list of customers
+type CustomerListOptions struct {
+	ListOptions
+	TimeFilter
+	Status         string
+	Active         *bool
+	IncludeDeleted bool
+}
+
+// CustomerClient calls the customer API
+type CustomerClient struct {
+	rest *restClient
+}
+
+// NewCustomerClient creates a client of the customer service
+func NewCustomerClient(config Config) (*CustomerClient, error) {
+	rest, err := newRESTClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &CustomerClient{rest: rest}, nil
+}
+
+// List returns a page of customers
+func (c *CustomerClient) List(ctx context.Context, options CustomerListOptions) (*Page[Customer], error) {
+	query := options.values()
+	if options.Status != "" {
+		query.Set("status", options.Status)
+	}
+	if options.Active != nil {
+		query.Set("active", strconv.FormatBool(*options.Active))
+	}
+	if options.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+	options.setValues(query)
+
+	var page Page[Customer]
+	if err := c.rest.do(ctx, request{method: http.MethodGet, path: "/api/v1/customers", query: query, paged: true}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Changes returns the customers changed after the cursor since, at most
+// limit of them; an empty since starts from the oldest retained change. It
+// fails with ErrCursorExpired once the cursor is no longer retained.
+func (c *CustomerClient) Changes(ctx context.Context, since string, limit int) (*ChangePage[Customer], error) {
+	return changes[Customer](ctx, c.rest, "/api/v1/customers/changes", since, limit)
+}
+
+// Stats returns the counts of customers by status
+func (c *CustomerClient) Stats(ctx context.Context) (*CustomerStats, error) {
+	var stats CustomerStats
+	if err := c.rest.do(ctx, request{method: http.MethodGet, path: "/api/v1/customers/stats"}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Get returns the customer with the given ID
+func (c *CustomerClient) Get(ctx context.Context, id string) (*Customer, error) {
+	return c.customer(ctx, request{method: http.MethodGet, path: pathf("/api/v1/customers/%s", id)})
+}
+
+// GetByEmail returns the customer with the given email
+func (c *CustomerClient) GetByEmail(ctx context.Context, email string) (*Customer, error) {
+	return c.customer(ctx, request{method: http.MethodGet, path: pathf("/api/v1/customers/email/%s", email)})
+}
+
+// BatchGet returns the customers with the given IDs by ID. Customers that do
+// not exist are left out.
+func (c *CustomerClient) BatchGet(ctx context.Context, ids []string) (map[string]*Customer, error) {
+	return batchGet[Customer](ctx, c.rest, "/api/v1/customers/batch-get", ids)
+}
+
+// Create creates a customer
+func (c *CustomerClient) Create(ctx context.Context, req CreateCustomerRequest) (*Customer, error) {
+	return c.customer(ctx, request{method: http.MethodPost, path: "/api/v1/customers", body: req})
+}
+
+// Update changes the fields of the customer that are set in req
+func (c *CustomerClient) Update(ctx context.Context, id string, req UpdateCustomerRequest) (*Customer, error) {
+	return c.customer(ctx, request{method: http.MethodPut, path: pathf("/api/v1/customers/%s", id), body: req})
+}
+
+// Delete soft deletes the customer
+func (c *CustomerClient) Delete(ctx context.Context, id string) error {
+	return c.rest.do(ctx, request{method: http.MethodDelete, path: pathf("/api/v1/customers/%s", id)}, nil)
+}
+
+// Restore brings back a deleted customer
+func (c *CustomerClient) Restore(ctx context.Context, id string) (*Customer, error) {
+	return c.customer(ctx, request{method: http.MethodPost, path: pathf("/api/v1/customers/%s/restore", id), idempotent: true})
+}
+
+// customer sends a request answered with a customer
+func (c *CustomerClient) customer(ctx context.Context, r request) (*Customer, error) {
+	var customer Customer
+	if err := c.rest.do(ctx, r, &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
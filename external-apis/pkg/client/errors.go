@@ -0,0 +1,123 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrBadRequest is matched by 400 responses, e.g. for invalid input
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is matched by 401 responses
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched by 403 responses
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is matched by 404 responses
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by 409 and 412 responses, e.g. for a duplicate
+	// email or a lack of stock
+	ErrConflict = errors.New("conflict")
+	// ErrUnprocessable is matched by 422 responses, e.g. for an order of an
+	// inactive customer
+	ErrUnprocessable = errors.New("unprocessable")
+	// ErrCursorExpired is matched by 410 responses of change feeds whose
+	// cursor fell behind the retained changes; resync with a full export
+	ErrCursorExpired = errors.New("cursor expired")
+	// ErrRateLimited is matched by 429 responses
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnavailable is matched by 5xx responses and returned when the
+	// service cannot be reached
+	ErrUnavailable = errors.New("service unavailable")
+)
+
+// FieldError describes why a field of the request was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// APIError is returned for responses with an error status. It matches the
+// sentinel error of its status with errors.Is.
+type APIError struct {
+	StatusCode int
+	// Code is the machine readable error, e.g. not_found
+	Code    string
+	Message string
+	// Details lists the rejected fields of an invalid request
+	Details []FieldError
+	// RequestID identifies the request in the logs of the service
+	RequestID string
+	// RetryAfter is how long the service asked to wait before retrying
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is reports whether target is the sentinel error of the status
+func (e *APIError) Is(target error) bool {
+	return target != nil && statusError(e.StatusCode) == target
+}
+
+// statusError returns the sentinel error of an error status
+func statusError(status int) error {
+	switch {
+	case status == http.StatusBadRequest:
+		return ErrBadRequest
+	case status == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case status == http.StatusForbidden:
+		return ErrForbidden
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusConflict, status == http.StatusPreconditionFailed:
+		return ErrConflict
+	case status == http.StatusGone:
+		return ErrCursorExpired
+	case status == http.StatusUnprocessableEntity:
+		return ErrUnprocessable
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrUnavailable
+	default:
+		return nil
+	}
+}
+
+// decodeError reads the error response of the service. Bodies that are not
+// the JSON error of the services, e.g. from a proxy, leave the message empty.
+func decodeError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(RequestIDHeader),
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var body struct {
+		Error   string       `json:"error"`
+		Message string       `json:"message"`
+		Details []FieldError `json:"details"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err == nil {
+		apiErr.Code = body.Error
+		apiErr.Message = body.Message
+		apiErr.Details = body.Details
+	}
+
+	return apiErr
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Relationships an order can be expanded with
+const (
+	ExpandCustomer = "customer"
+	ExpandProducts = "products"
+)
+
+// Order is an order as returned by the order service. The customer and
+// products are only filled in when they are expanded.
+type Order struct {
+	ID         string         `json:"id"`
+	CustomerID string         `json:"customerId"`
+	ProductIDs []string       `json:"productIds"`
+	Customer   *OrderCustomer `json:"customer,omitempty"`
+	Products   []OrderProduct `json:"products,omitempty"`
+	Total      float64        `json:"total"`
+	// Currency is the ISO 4217 code of Total, empty until the products of
+	// the order are enriched
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Enrichment is set while the order waits for its customer or products
+	// to be fetched from a service that was unavailable when it was placed
+	Enrichment *OrderEnrichment `json:"enrichment,omitempty"`
+	// ExpandErrors explains, by expansion, why a requested relationship is
+	// missing or incomplete
+	ExpandErrors map[string]string `json:"expandErrors,omitempty"`
+}
+
+// OrderCustomer is the customer of an order
+type OrderCustomer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// OrderProduct is a product of an order
+type OrderProduct struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	Currency    string  `json:"currency"`
+	Category    string  `json:"category"`
+}
+
+// OrderEnrichment records what is missing from a partially enriched order
+type OrderEnrichment struct {
+	// Status is partial while the order waits, failed when it cannot complete
+	Status  string   `json:"status"`
+	Missing []string `json:"missing"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// OrderSaga is the saga that placed an order, step by step
+type OrderSaga struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Status    string          `json:"status"`
+	Steps     []OrderSagaStep `json:"steps"`
+	Error     string          `json:"error,omitempty"`
+	StartedAt time.Time       `json:"startedAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// OrderSagaStep is a step of an order saga
+type OrderSagaStep struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateOrderRequest is the request to place an order. A product ordered
+// several times is listed several times.
+type CreateOrderRequest struct {
+	CustomerID string   `json:"customerId"`
+	ProductIDs []string `json:"productIds"`
+}
+
+// ReassignCustomerResult reports the orders moved to another customer
+type ReassignCustomerResult struct {
+	FromCustomerID string `json:"fromCustomerId"`
+	ToCustomerID   string `json:"toCustomerId"`
+	OrdersMoved    int    `json:"ordersMoved"`
+}
+
+// OrderClient calls the order API
+type OrderClient struct {
+	rest *restClient
+}
+
+// NewOrderClient creates a client of the order service
+func NewOrderClient(config Config) (*OrderClient, error) {
+	rest, err := newRESTClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderClient{rest: rest}, nil
+}
+
+// List returns all orders, with the given relationships expanded
+func (c *OrderClient) List(ctx context.Context, expand ...string) ([]Order, error) {
+	return c.orders(ctx, request{method: http.MethodGet, path: "/api/v1/orders", query: expandQuery(expand)})
+}
+
+// ListByCustomer returns the orders of a customer, with the given
+// relationships expanded
+func (c *OrderClient) ListByCustomer(ctx context.Context, customerID string, expand ...string) ([]Order, error) {
+	return c.orders(ctx, request{method: http.MethodGet, path: pathf("/api/v1/orders/customer/%s", customerID), query: expandQuery(expand)})
+}
+
+// Get returns the order with the given ID, with the given relationships
+// expanded
+func (c *OrderClient) Get(ctx context.Context, id string, expand ...string) (*Order, error) {
+	var order Order
+	if err := c.rest.do(ctx, request{method: http.MethodGet, path: pathf("/api/v1/orders/%s", id), query: expandQuery(expand)}, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetSaga returns the saga that placed the order
+func (c *OrderClient) GetSaga(ctx context.Context, id string) (*OrderSaga, error) {
+	var saga OrderSaga
+	if err := c.rest.do(ctx, request{method: http.MethodGet, path: pathf("/api/v1/orders/%s/saga", id)}, &saga); err != nil {
+		return nil, err
+	}
+	return &saga, nil
+}
+
+// Create places an order. It fails with ErrUnprocessable when the customer
+// or a product cannot be ordered and with ErrConflict when stock is lacking.
+// It is not retried, so a retry cannot place the order twice.
+func (c *OrderClient) Create(ctx context.Context, req CreateOrderRequest) (*Order, error) {
+	var order Order
+	if err := c.rest.do(ctx, request{method: http.MethodPost, path: "/api/v1/orders", body: req}, &order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ReassignCustomer moves the orders of a customer to another
+func (c *OrderClient) ReassignCustomer(ctx context.Context, fromCustomerID, toCustomerID string) (*ReassignCustomerResult, error) {
+	body := map[string]string{"fromCustomerId": fromCustomerID, "toCustomerId": toCustomerID}
+
+	var result ReassignCustomerResult
+	if err := c.rest.do(ctx, request{method: http.MethodPost, path: "/api/v1/orders/reassign-customer", body: body, idempotent: true}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Delete deletes the order
+func (c *OrderClient) Delete(ctx context.Context, id string) error {
+	return c.rest.do(ctx, request{method: http.MethodDelete, path: pathf("/api/v1/orders/%s", id)}, nil)
+}
+
+// orders sends a request answered with a list of orders
+func (c *OrderClient) orders(ctx context.Context, r request) ([]Order, error) {
+	var orders []Order
+	if err := c.rest.do(ctx, r, &orders); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// expandQuery returns the query parameters expanding the relationships
+func expandQuery(expand []string) url.Values {
+	if len(expand) == 0 {
+		return nil
+	}
+	return url.Values{"expand": {strings.Join(expand, ",")}}
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Product is a product as returned by the product service. Prices are exact
+// decimal amounts in the currency of the product.
+type Product struct {
+	ID                string           `json:"id"`
+	SKU               string           `json:"sku,omitempty"`
+	Name              string           `json:"name"`
+	Description       string           `json:"description"`
+	Price             json.Number      `json:"price"`
+	Currency          string           `json:"currency"`
+	CategoryID        string           `json:"categoryId"`
+	Category          string           `json:"category"`
+	Active            bool             `json:"active"`
+	StockQuantity     int              `json:"stockQuantity"`
+	ReservedQuantity  int              `json:"reservedQuantity"`
+	AvailableQuantity int              `json:"availableQuantity"`
+	Images            []ProductImage   `json:"images"`
+	Variants          []ProductVariant `json:"variants"`
+	CreatedAt         time.Time        `json:"createdAt"`
+	UpdatedAt         time.Time        `json:"updatedAt"`
+	DeletedAt         *time.Time       `json:"deletedAt,omitempty"`
+}
+
+// ProductImage is an image of a product
+type ProductImage struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ProductVariant is a variant of a product, e.g. a size or color
+type ProductVariant struct {
+	ID            string            `json:"id"`
+	SKU           string            `json:"sku"`
+	Attributes    map[string]string `json:"attributes"`
+	Price         json.Number       `json:"price"`
+	Currency      string            `json:"currency"`
+	PriceOverride bool              `json:"priceOverride"`
+	StockQuantity int               `json:"stockQuantity"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+}
+
+// ProductStats summarizes the products that are not deleted
+type ProductStats struct {
+	Total      int             `json:"total"`
+	Active     int             `json:"active"`
+	Inactive   int             `json:"inactive"`
+	Categories []CategoryCount `json:"categories"`
+	Prices     []PriceStats    `json:"prices"`
+}
+
+// CategoryCount is the number of products in a category
+type CategoryCount struct {
+	CategoryID string `json:"categoryId"`
+	Category   string `json:"category"`
+	Count      int    `json:"count"`
+}
+
+// PriceStats summarizes the prices of the products in a currency
+type PriceStats struct {
+	Currency string      `json:"currency"`
+	Count    int         `json:"count"`
+	Min      json.Number `json:"min"`
+	Average  json.Number `json:"average"`
+	Max      json.Number `json:"max"`
+}
+
+// CreateProductRequest is the request to create a product
+type CreateProductRequest struct {
+	SKU           string      `json:"sku,omitempty"`
+	Name          string      `json:"name"`
+	Description   string      `json:"description"`
+	Price         json.Number `json:"price"`
+	Currency      string      `json:"currency,omitempty"`
+	CategoryID    string      `json:"categoryId"`
+	StockQuantity int         `json:"stockQuantity"`
+}
+
+// UpdateProductRequest is the request to update a product; only the fields
+// that are set change
+type UpdateProductRequest struct {
+	SKU         *string      `json:"sku,omitempty"`
+	Name        *string      `json:"name,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	Price       *json.Number `json:"price,omitempty"`
+	Currency    *string      `json:"currency,omitempty"`
+	CategoryID  *string      `json:"categoryId,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+}
+
+// ProductListOptions filters the list of products. Prices are decimal
+// amounts such as "19.99".
+type ProductListOptions struct {
+	ListOptions
+	TimeFilter
+	Category       string
+	CategoryID     string
+	Currency       string
+	MinPrice       string
+	MaxPrice       string
+	Active         *bool
+	IncludeDeleted bool
+}
+
+// Reservation statuses
+const (
+	ReservationActive    = "ACTIVE"
+	ReservationConfirmed = "CONFIRMED"
+	ReservationReleased  = "RELEASED"
+	ReservationExpired   = "EXPIRED"
+)
+
+// Reservation holds units of stock of a product for an order until it is
+// confirmed, released or expires
+type Reservation struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"productId"`
+	OrderID   string    `json:"orderId"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CreateReservationRequest is the request to reserve stock for an order
+type CreateReservationRequest struct {
+	OrderID  string `json:"orderId"`
+	Quantity int    `json:"quantity"`
+	// TTLSeconds defaults to the reservation TTL of the service when it is zero
+	TTLSeconds int `json:"ttlSeconds,omitempty"`
+}
+
+// ProductClient calls the product API
+type ProductClient struct {
+	rest *restClient
+}
+
+// NewProductClient creates a client of the product service
+func NewProductClient(config Config) (*ProductClient, error) {
+	rest, err := newRESTClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &ProductClient{rest: rest}, nil
+}
+
+// List returns a page of products
+func (c *ProductClient) List(ctx context.Context, options ProductListOptions) (*Page[Product], error) {
+	query := options.values()
+	for key, value := range map[string]string{
+		"category":    options.Category,
+		"category_id": options.CategoryID,
+		"currency":    options.Currency,
+		"min_price":   options.MinPrice,
+		"max_price":   options.MaxPrice,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if options.Active != nil {
+		query.Set("active", strconv.FormatBool(*options.Active))
+	}
+	if options.IncludeDeleted {
+		query.Set("include_deleted", "true")
+	}
+	options.setValues(query)
+
+	var page Page[Product]
+	if err := c.rest.do(ctx, request{method: http.MethodGet, path: "/api/v1/products", query: query, paged: true}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Changes returns the products changed after the cursor since, at most
+// limit of them; an empty since starts from the oldest retained change. It
+// fails with ErrCursorExpired once the cursor is no longer retained.
+func (c *ProductClient) Changes(ctx context.Context, since string, limit int) (*ChangePage[Product], error) {
+	return changes[Product](ctx, c.rest, "/api/v1/products/changes", since, limit)
+}
+
+// Stats returns the counts of products by category and state and the range
+// of their prices in each currency
+func (c *ProductClient) Stats(ctx context.Context) (*ProductStats, error) {
+	var stats ProductStats
+	if err := c.rest.do(ctx, request{method: http.MethodGet, path: "/api/v1/products/stats"}, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Get returns the product with the given ID
+func (c *ProductClient) Get(ctx context.Context, id string) (*Product, error) {
+	return c.product(ctx, request{method: http.MethodGet, path: pathf("/api/v1/products/%s", id)})
+}
+
+// BatchGet returns the products with the given IDs by ID. Products that do
+// not exist are left out.
+func (c *ProductClient) BatchGet(ctx context.Context, ids []string) (map[string]*Product, error) {
+	return batchGet[Product](ctx, c.rest, "/api/v1/products/batch-get", ids)
+}
+
+// Create creates a product
+func (c *ProductClient) Create(ctx context.Context, req CreateProductRequest) (*Product, error) {
+	return c.product(ctx, request{method: http.MethodPost, path: "/api/v1/products", body: req})
+}
+
+// Update changes the fields of the product that are set in req
+func (c *ProductClient) Update(ctx context.Context, id string, req UpdateProductRequest) (*Product, error) {
+	return c.product(ctx, request{method: http.MethodPut, path: pathf("/api/v1/products/%s", id), body: req})
+}
+
+// Delete soft deletes the product
+func (c *ProductClient) Delete(ctx context.Context, id string) error {
+	return c.rest.do(ctx, request{method: http.MethodDelete, path: pathf("/api/v1/products/%s", id)}, nil)
+}
+
+// Restore brings back a deleted product
+func (c *ProductClient) Restore(ctx context.Context, id string) (*Product, error) {
+	return c.product(ctx, request{method: http.MethodPost, path: pathf("/api/v1/products/%s/restore", id), idempotent: true})
+}
+
+// AdjustStock corrects the units on hand by delta, e.g. after a delivery or a
+// stock count. It is not retried, as a repeated adjustment would count twice.
+func (c *ProductClient) AdjustStock(ctx context.Context, id string, delta int, reason string) (*Product, error) {
+	body := map[string]interface{}{"delta": delta, "reason": reason}
+	return c.product(ctx, request{method: http.MethodPost, path: pathf("/api/v1/products/%s/stock/adjust", id), body: body})
+}
+
+// Reserve reserves units of stock of the product for an order. It fails with
+// ErrConflict when not enough stock is available.
+func (c *ProductClient) Reserve(ctx context.Context, productID string, req CreateReservationRequest) (*Reservation, error) {
+	return c.reservation(ctx, request{method: http.MethodPost, path: pathf("/api/v1/products/%s/reservations", productID), body: req})
+}
+
+// GetReservation returns the reservation with the given ID
+func (c *ProductClient) GetReservation(ctx context.Context, id string) (*Reservation, error) {
+	return c.reservation(ctx, request{method: http.MethodGet, path: pathf("/api/v1/reservations/%s", id)})
+}
+
+// ConfirmReservation keeps the reserved stock for good
+func (c *ProductClient) ConfirmReservation(ctx context.Context, id string) (*Reservation, error) {
+	return c.reservation(ctx, request{method: http.MethodPost, path: pathf("/api/v1/reservations/%s/confirm", id)})
+}
+
+// ReleaseReservation gives the reserved stock back
+func (c *ProductClient) ReleaseReservation(ctx context.Context, id string) (*Reservation, error) {
+	return c.reservation(ctx, request{method: http.MethodPost, path: pathf("/api/v1/reservations/%s/release", id)})
+}
+
+// product sends a request answered with a product
+func (c *ProductClient) product(ctx context.Context, r request) (*Product, error) {
+	var product Product
+	if err := c.rest.do(ctx, r, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// reservation sends a request answered with a reservation
+func (c *ProductClient) reservation(ctx context.Context, r request) (*Reservation, error) {
+	var reservation Reservation
+	if err := c.rest.do(ctx, r, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedRequest is a request received by a test server
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   string
+}
+
+// newRecordingServer returns a server answering every request with status
+// and body, recording the requests it receives
+func newRecordingServer(t *testing.T, status int, body string) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		requests = append(requests, recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.EscapedPath(),
+			Query:  r.URL.RawQuery,
+			Body:   string(payload),
+		})
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestCustomerClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("List a filtered page of customers", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK,
+			`{"data":[{"id":"customer-123","name":"John Doe","status":"ACTIVE"}],"pagination":{"total":3,"limit":1,"offset":1},"message":"OK","code":200}`)
+		customers, err := NewCustomerClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+		active := true
+
+		// Act
+		page, err := customers.List(ctx, CustomerListOptions{
+			ListOptions: ListOptions{Limit: 1, Offset: 1, Sort: "name"},
+			Status:      CustomerActive,
+			Active:      &active,
+			TimeFilter:  TimeFilter{CreatedBefore: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/customers", (*requests)[0].Path)
+		assert.Equal(t, "active=true&created_before=2024-02-01T00%3A00%3A00Z&limit=1&offset=1&sort=name&status=ACTIVE", (*requests)[0].Query)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, "John Doe", page.Data[0].Name)
+		assert.True(t, page.Pagination.HasMore())
+	})
+
+	t.Run("Read the changes after a cursor", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK, envelope(`{"changes":[`+
+			`{"cursor":"42","operation":"update","id":"customer-123","event":"customer.updated","data":{"id":"customer-123","name":"Jane Doe"}},`+
+			`{"cursor":"43","operation":"delete","id":"customer-456","event":"customer.deleted"}],"cursor":"43","hasMore":false}`))
+		customers, err := NewCustomerClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		page, err := customers.Changes(ctx, "41", 100)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/customers/changes", (*requests)[0].Path)
+		assert.Equal(t, "limit=100&since=41", (*requests)[0].Query)
+		require.Len(t, page.Changes, 2)
+		assert.Equal(t, "Jane Doe", page.Changes[0].Data.Name)
+		assert.Equal(t, ChangeDelete, page.Changes[1].Operation)
+		assert.Nil(t, page.Changes[1].Data)
+		assert.Equal(t, "43", page.Cursor)
+	})
+
+	t.Run("Count the customers by status", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK, envelope(`{"total":3,"byStatus":{"ACTIVE":2,"BLOCKED":1}}`))
+		customers, err := NewCustomerClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		stats, err := customers.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/customers/stats", (*requests)[0].Path)
+		assert.Equal(t, 3, stats.Total)
+		assert.Equal(t, map[string]int{CustomerActive: 2, CustomerBlocked: 1}, stats.ByStatus)
+	})
+
+	t.Run("Find a customer by email", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK, envelope(`{"id":"customer-123"}`))
+		customers, err := NewCustomerClient(Config{BaseURL: server.URL + "/"})
+		require.NoError(t, err)
+
+		// Act
+		_, err = customers.GetByEmail(ctx, "john+1@example.com")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/customers/email/john+1@example.com", (*requests)[0].Path)
+	})
+
+	t.Run("Leave out customers that do not exist", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK,
+			envelope(`{"results":[{"id":"customer-123","status":"found","data":{"id":"customer-123"}},{"id":"missing","status":"not_found"}]}`))
+		customers, err := NewCustomerClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		found, err := customers.BatchGet(ctx, []string{"customer-123", "missing"})
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"ids":["customer-123","missing"]}`, (*requests)[0].Body)
+		assert.Len(t, found, 1)
+		assert.Contains(t, found, "customer-123")
+	})
+
+	t.Run("Update only the fields that are set", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK, envelope(`{"id":"customer-123","name":"Jane Doe"}`))
+		customers, err := NewCustomerClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+		name := "Jane Doe"
+
+		// Act
+		customer, err := customers.Update(ctx, "customer-123", UpdateCustomerRequest{Name: &name})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPut, (*requests)[0].Method)
+		assert.JSONEq(t, `{"name":"Jane Doe"}`, (*requests)[0].Body)
+		assert.Equal(t, "Jane Doe", customer.Name)
+	})
+}
+
+func TestProductClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("List the products changed since a time", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK,
+			`{"data":[{"id":"product-001","createdAt":"2024-01-01T09:00:00Z","updatedAt":"2024-01-15T10:30:00Z"}],"pagination":{"total":1,"limit":50,"offset":0},"message":"OK","code":200}`)
+		products, err := NewProductClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		page, err := products.List(ctx, ProductListOptions{
+			TimeFilter: TimeFilter{UpdatedSince: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "updated_since=2024-01-15T10%3A00%3A00Z", (*requests)[0].Query)
+		require.Len(t, page.Data, 1)
+		assert.Equal(t, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), page.Data[0].CreatedAt)
+	})
+
+	t.Run("Keep prices exact", func(t *testing.T) {
+		// Arrange
+		server, _ := newRecordingServer(t, http.StatusOK, envelope(`{"id":"product-001","price":19.99,"currency":"EUR"}`))
+		products, err := NewProductClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		product, err := products.Get(ctx, "product-001")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("19.99"), product.Price)
+	})
+
+	t.Run("Summarize the catalog", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK, envelope(`{"total":2,"active":1,"inactive":1,`+
+			`"categories":[{"categoryId":"category-books","category":"Books","count":2}],`+
+			`"prices":[{"currency":"USD","count":2,"min":10.00,"average":15.005,"max":20.01}]}`))
+		products, err := NewProductClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		stats, err := products.Stats(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/products/stats", (*requests)[0].Path)
+		assert.Equal(t, []CategoryCount{{CategoryID: "category-books", Category: "Books", Count: 2}}, stats.Categories)
+		assert.Equal(t, json.Number("10.00"), stats.Prices[0].Min, "prices stay exact")
+		assert.Equal(t, json.Number("15.005"), stats.Prices[0].Average)
+	})
+
+	t.Run("Reserve stock for an order", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusCreated,
+			envelope(`{"id":"reservation-1","productId":"product-001","orderId":"order-123","quantity":2,"status":"ACTIVE"}`))
+		products, err := NewProductClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		reservation, err := products.Reserve(ctx, "product-001", CreateReservationRequest{OrderID: "order-123", Quantity: 2})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/products/product-001/reservations", (*requests)[0].Path)
+		assert.JSONEq(t, `{"orderId":"order-123","quantity":2}`, (*requests)[0].Body)
+		assert.Equal(t, ReservationActive, reservation.Status)
+	})
+
+	t.Run("Report a lack of stock as a conflict", func(t *testing.T) {
+		// Arrange
+		server, _ := newRecordingServer(t, http.StatusConflict, `{"error":"conflict","message":"insufficient stock","code":409}`)
+		products, err := NewProductClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		_, err = products.Reserve(ctx, "product-001", CreateReservationRequest{OrderID: "order-123", Quantity: 200})
+
+		// Assert
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.EqualError(t, err, "409 Conflict: insufficient stock")
+	})
+}
+
+func TestOrderClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Expand the relationships of orders", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK,
+			envelope(`[{"id":"order-123","customerId":"customer-456","customer":{"id":"customer-456","name":"John Doe"},"total":29.99}]`))
+		orders, err := NewOrderClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		result, err := orders.ListByCustomer(ctx, "customer-456", ExpandCustomer, ExpandProducts)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/orders/customer/customer-456", (*requests)[0].Path)
+		assert.Equal(t, "expand=customer%2Cproducts", (*requests)[0].Query)
+		require.Len(t, result, 1)
+		assert.Equal(t, "John Doe", result[0].Customer.Name)
+	})
+
+	t.Run("Place an order", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusCreated,
+			envelope(`{"id":"order-123","customerId":"customer-456","productIds":["product-001"],"total":29.99,"enrichment":{"status":"partial","missing":["customer"]}}`))
+		orders, err := NewOrderClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		order, err := orders.Create(ctx, CreateOrderRequest{CustomerID: "customer-456", ProductIDs: []string{"product-001"}})
+
+		// Assert
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"customerId":"customer-456","productIds":["product-001"]}`, (*requests)[0].Body)
+		require.NotNil(t, order.Enrichment)
+		assert.Equal(t, []string{"customer"}, order.Enrichment.Missing)
+	})
+
+	t.Run("Delete an order", func(t *testing.T) {
+		// Arrange
+		server, requests := newRecordingServer(t, http.StatusOK, `{"data":null,"message":"Order deleted successfully","code":200}`)
+		orders, err := NewOrderClient(Config{BaseURL: server.URL})
+		require.NoError(t, err)
+
+		// Act
+		err = orders.Delete(ctx, "order-123")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, (*requests)[0].Method)
+		assert.Equal(t, "/api/v1/orders/order-123", (*requests)[0].Path)
+	})
+}
@@ -17,6 +17,9 @@ public class ExternalApiService {
 
     private static final Logger logger = LoggerFactory.getLogger(ExternalApiService.class);
 
+    // The response models mirror the raw data, without the response envelope
+    private static final String RESPONSE_ENVELOPE_HEADER = "X-Response-Envelope";
+
     private final WebClient productServiceClient;
     private final WebClient customerServiceClient;
 
@@ -28,11 +31,13 @@ public class ExternalApiService {
 
         this.productServiceClient = webClientBuilder
                 .baseUrl(productServiceUrl)
+                .defaultHeader(RESPONSE_ENVELOPE_HEADER, "false")
                 .codecs(configurer -> configurer.defaultCodecs().maxInMemorySize(1024 * 1024))
                 .build();
 
         this.customerServiceClient = webClientBuilder
                 .baseUrl(customerServiceUrl)
+                .defaultHeader(RESPONSE_ENVELOPE_HEADER, "false")
                 .codecs(configurer -> configurer.defaultCodecs().maxInMemorySize(1024 * 1024))
                 .build();
     }
@@ -44,7 +49,7 @@ public class ExternalApiService {
 
         return productServiceClient
                 .get()
-                .uri("/api/products/{id}", productId)
+                .uri("/api/v1/products/{id}", productId)
                 .retrieve()
                 .bodyToMono(ExternalApiModels.ProductResponse.class)
                 .doOnSuccess(product -> logger.debug("Successfully fetched product: {}", productId))
@@ -59,7 +64,7 @@ public class ExternalApiService {
 
         return customerServiceClient
                 .get()
-                .uri("/api/customers/{id}", customerId)
+                .uri("/api/v1/customers/{id}", customerId)
                 .retrieve()
                 .bodyToMono(ExternalApiModels.CustomerResponse.class)
                 .doOnSuccess(customer -> logger.debug("Successfully fetched customer: {}", customerId))